@@ -0,0 +1,191 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+
+	"github.com/tutu-network/tutu/internal/domain"
+	"github.com/tutu-network/tutu/internal/infra/audit"
+)
+
+// ─── Admin API ──────────────────────────────────────────────────────────────
+// Privileged endpoints live under /api/admin. Every call to them is written
+// to the hash-chained audit log before the handler runs.
+//
+// GET /api/admin/audit         — query entries (?category=&actor=&since=&until=&limit=)
+// GET /api/admin/audit/export  — export entries as JSON Lines (same filters)
+// GET /api/admin/audit/verify  — verify chain integrity
+
+// SetAuditLog enables the admin API backed by the given audit log.
+func (s *Server) SetAuditLog(l *audit.Log) { s.audit = l }
+
+// mountAdmin registers the /api/admin routes.
+func (s *Server) mountAdmin(r chi.Router) {
+	r.Route("/api/admin", func(r chi.Router) {
+		r.Use(s.auditMiddleware)
+		r.Get("/audit", s.handleAuditQuery)
+		r.Get("/audit/export", s.handleAuditExport)
+		r.Get("/audit/verify", s.handleAuditVerify)
+		if s.network != nil {
+			s.mountNetwork(r)
+		}
+		if s.tunables != nil {
+			s.mountParams(r)
+		}
+		if s.diagnostics != nil {
+			r.Get("/diagnostics", s.handleDiagnostics)
+		}
+		if s.journal != nil {
+			s.mountJournal(r)
+		}
+		if s.idlePolicy != nil {
+			s.mountIdlePolicy(r)
+		}
+		if s.hardware != nil {
+			s.mountHardwareAdmin(r)
+		}
+		if s.tenants != nil {
+			s.mountNamespaces(r)
+		}
+		if s.inviter != nil {
+			r.Post("/federation/invites", s.handleFederationInvite)
+		}
+		if s.housekeeping != nil {
+			s.mountHousekeeping(r)
+		}
+		if s.benchmarks != nil {
+			s.mountBenchmarkAdmin(r)
+		}
+		if s.retirements != nil {
+			s.mountRetirements(r)
+		}
+	})
+}
+
+// auditMiddleware records every admin API call. Audit failures never block
+// the request.
+//
+// The actor is the credential the call was authenticated with: the node's
+// API key, or "local" when none is set (namespace keys cannot reach the
+// admin API). The address is the TCP peer captured before RealIP rewrote
+// it from forwarding headers, which any client can send; a forwarded
+// address is recorded separately.
+func (s *Server) auditMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		actor := "local"
+		if s.auth != nil {
+			actor = "api_key"
+		}
+		peer := peerAddr(r)
+		details := []string{"peer=" + peer}
+		if r.RemoteAddr != peer {
+			details = append(details, "forwarded_for="+r.RemoteAddr)
+		}
+		if id := middleware.GetReqID(r.Context()); id != "" {
+			details = append(details, "request_id="+id)
+		}
+		_, _ = s.audit.Record(domain.AuditAdminAPI, actor, r.Method, r.URL.Path, strings.Join(details, " "))
+		next.ServeHTTP(w, r)
+	})
+}
+
+// peerAddrKey is the context key of the connection's peer address.
+type peerAddrKey struct{}
+
+// capturePeerAddr keeps the connection's own peer address, before RealIP
+// replaces r.RemoteAddr with one taken from request headers.
+func capturePeerAddr(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), peerAddrKey{}, r.RemoteAddr)))
+	})
+}
+
+// peerAddr returns the connection's peer address captured by
+// capturePeerAddr, falling back to r.RemoteAddr.
+func peerAddr(r *http.Request) string {
+	if addr, ok := r.Context().Value(peerAddrKey{}).(string); ok {
+		return addr
+	}
+	return r.RemoteAddr
+}
+
+func (s *Server) handleAuditQuery(w http.ResponseWriter, r *http.Request) {
+	q, err := parseAuditQuery(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	entries, err := s.audit.Query(q)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if entries == nil {
+		entries = []domain.AuditEntry{}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"entries": entries,
+		"count":   len(entries),
+	})
+}
+
+func (s *Server) handleAuditExport(w http.ResponseWriter, r *http.Request) {
+	q, err := parseAuditQuery(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Content-Disposition", `attachment; filename="audit.jsonl"`)
+	_ = s.audit.Export(w, q)
+}
+
+func (s *Server) handleAuditVerify(w http.ResponseWriter, r *http.Request) {
+	res, err := s.audit.Verify()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	status := http.StatusOK
+	if !res.Valid {
+		status = http.StatusConflict
+	}
+	writeJSON(w, status, res)
+}
+
+// parseAuditQuery builds an audit.Query from URL parameters. Times are
+// RFC 3339.
+func parseAuditQuery(r *http.Request) (audit.Query, error) {
+	v := r.URL.Query()
+	q := audit.Query{
+		Category: domain.AuditCategory(strings.ToUpper(v.Get("category"))),
+		Actor:    v.Get("actor"),
+	}
+	for _, p := range []struct {
+		name string
+		dst  *time.Time
+	}{{"since", &q.Since}, {"until", &q.Until}} {
+		if raw := v.Get(p.name); raw != "" {
+			t, err := time.Parse(time.RFC3339, raw)
+			if err != nil {
+				return audit.Query{}, fmt.Errorf("invalid %s: %w", p.name, err)
+			}
+			*p.dst = t
+		}
+	}
+	if raw := v.Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			return audit.Query{}, fmt.Errorf("invalid limit %q", raw)
+		}
+		q.Limit = n
+	}
+	return q, nil
+}
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/tutu-network/tutu/internal/infra/scheduler"
+)
+
+// ─── Back-Pressure Admission ────────────────────────────────────────────────
+// GET /api/scheduler/backpressure — current level, signals, counters and
+//                                   recent transitions (?limit=)
+//
+// Inference endpoints are admitted as HIGH priority: deferred requests get
+// 429 with Retry-After, shed requests 503. The controller's latency signal
+// is the engine's time to first token, fed by the daemon from every token
+// stream — a request's full duration depends on how much the client asked
+// for and how fast it reads.
+
+// interactivePriority is the class of local API inference requests.
+const interactivePriority = scheduler.P1High
+
+// SetAdmission enables back-pressure admission for inference endpoints.
+func (s *Server) SetAdmission(a *scheduler.Admission) { s.admission = a }
+
+// admitInference applies admission control to an inference handler.
+func (s *Server) admitInference(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.admission == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		d := s.admission.Admit(interactivePriority)
+		switch d.Action {
+		case scheduler.ActionDefer:
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(d.RetryAfter.Seconds()))))
+			writeError(w, http.StatusTooManyRequests, "back-pressure "+d.Level.String()+": retry later")
+			return
+		case scheduler.ActionShed:
+			writeError(w, http.StatusServiceUnavailable, "back-pressure "+d.Level.String()+": request shed")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) handleBackPressure(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"admission":   s.admission.Stats(),
+		"transitions": s.admission.Transitions(queryLimit(r, 20)),
+	})
+}
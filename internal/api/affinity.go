@@ -0,0 +1,59 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/tutu-network/tutu/internal/infra/metrics"
+	"github.com/tutu-network/tutu/internal/infra/scheduler"
+)
+
+// ─── Request Affinity ───────────────────────────────────────────────────────
+// GET /api/scheduler/affinity — affinity outcomes and warm-hit rates with
+//                               and without a routing hint
+//
+// A chat completion may carry a routing hint, "session_id" or "cache_key"
+// in the body, so that a conversation's follow-ups go to the node that
+// already has the model and its KV cache warm. The outcome is returned in
+// the X-TuTu-Affinity header and counted in tutu_affinity_routes_total;
+// tutu_inference_warm_total shows the warm-hit rate the hints buy.
+
+// affinityHeader reports a hinted request's affinity outcome.
+const affinityHeader = "X-TuTu-Affinity"
+
+// SetAffinity enables routing hints on chat completions. nodeID is the
+// node inference is served on.
+func (s *Server) SetAffinity(t *scheduler.AffinityTable, nodeID string) {
+	s.affinity = t
+	s.nodeID = nodeID
+}
+
+// routeAffinity applies a chat request's routing hint. Requests are served
+// on this node, so it is the only candidate: a hint last served elsewhere
+// counts as moved and is re-pinned here.
+func (s *Server) routeAffinity(w http.ResponseWriter, hint, model string) {
+	warm := s.pool.IsLoaded(model)
+	metrics.InferenceWarm.WithLabelValues(strconv.FormatBool(hint != ""), warmLabel(warm)).Inc()
+	if s.affinity == nil {
+		return
+	}
+	s.affinity.Observe(hint != "", warm)
+	if hint == "" {
+		return
+	}
+	_, outcome := s.affinity.Prefer(hint, model, []scheduler.NodeCandidate{{NodeID: s.nodeID, HasModelHot: warm}})
+	s.affinity.Record(hint, model, s.nodeID)
+	metrics.AffinityRoutes.WithLabelValues(string(outcome)).Inc()
+	w.Header().Set(affinityHeader, string(outcome))
+}
+
+func warmLabel(warm bool) string {
+	if warm {
+		return "warm"
+	}
+	return "cold"
+}
+
+func (s *Server) handleAffinityStats(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.affinity.Stats())
+}
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/tutu-network/tutu/internal/infra/agent"
+)
+
+// ─── Agent API ──────────────────────────────────────────────────────────────
+// Multi-step agent runs (task type AGENT):
+//
+// GET  /api/agent/tools             — tools plans can call
+// POST /api/agent/runs              — create and start a run (202)
+// GET  /api/agent/runs              — runs, newest first (?limit=)
+// GET  /api/agent/runs/{id}         — run with per-step checkpoints
+// POST /api/agent/runs/{id}/cancel  — stop a run
+
+// AgentOps bundles the components behind the agent API.
+type AgentOps struct {
+	Orchestrator *agent.Orchestrator
+
+	// Submit starts a created run, normally as an AGENT executor task. ctx
+	// is the request's and carries its namespace.
+	Submit func(ctx context.Context, runID string) error
+}
+
+// SetAgents enables the agent API.
+func (s *Server) SetAgents(a *AgentOps) { s.agents = a }
+
+// mountAgent registers the /api/agent routes.
+func (s *Server) mountAgent(r chi.Router) {
+	r.Route("/api/agent", func(r chi.Router) {
+		r.Get("/tools", s.handleAgentTools)
+		r.Post("/runs", s.handleAgentCreate)
+		r.Get("/runs", s.handleAgentList)
+		r.Get("/runs/{id}", s.handleAgentGet)
+		r.Post("/runs/{id}/cancel", s.handleAgentCancel)
+	})
+}
+
+// AgentRunRequest is the body of POST /api/agent/runs.
+type AgentRunRequest struct {
+	Name   string           `json:"name"`
+	Budget int64            `json:"budget_credits"` // 0 = unlimited
+	Steps  []agent.PlanStep `json:"steps"`
+}
+
+func (s *Server) handleAgentTools(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{"tools": s.agents.Orchestrator.Tools()})
+}
+
+func (s *Server) handleAgentCreate(w http.ResponseWriter, r *http.Request) {
+	var req AgentRunRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	run, err := s.agents.Orchestrator.Create(req.Name, req.Budget, req.Steps)
+	if err != nil {
+		writeDomainError(w, err)
+		return
+	}
+	if err := s.agents.Submit(r.Context(), run.ID); err != nil {
+		_ = s.agents.Orchestrator.Cancel(run.ID)
+		writeError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusAccepted, run)
+}
+
+func (s *Server) handleAgentList(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{"runs": s.agents.Orchestrator.List(queryLimit(r, 50))})
+}
+
+func (s *Server) handleAgentGet(w http.ResponseWriter, r *http.Request) {
+	run, err := s.agents.Orchestrator.Get(chi.URLParam(r, "id"))
+	if err != nil {
+		writeDomainError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, run)
+}
+
+func (s *Server) handleAgentCancel(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if err := s.agents.Orchestrator.Cancel(id); err != nil {
+		writeDomainError(w, err)
+		return
+	}
+	run, _ := s.agents.Orchestrator.Get(id)
+	writeJSON(w, http.StatusOK, run)
+}
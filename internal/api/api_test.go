@@ -1,19 +1,47 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"os"
 	"path/filepath"
 
+	"github.com/tutu-network/tutu/internal/app/credit"
+	"github.com/tutu-network/tutu/internal/app/executor"
+	"github.com/tutu-network/tutu/internal/domain"
+	"github.com/tutu-network/tutu/internal/infra/agent"
+	"github.com/tutu-network/tutu/internal/infra/audit"
+	"github.com/tutu-network/tutu/internal/infra/embedding"
 	"github.com/tutu-network/tutu/internal/infra/engine"
+	"github.com/tutu-network/tutu/internal/infra/federation"
+	"github.com/tutu-network/tutu/internal/infra/gossip"
+	"github.com/tutu-network/tutu/internal/infra/governance"
+	"github.com/tutu-network/tutu/internal/infra/housekeeping"
+	"github.com/tutu-network/tutu/internal/infra/intelligence"
+	"github.com/tutu-network/tutu/internal/infra/journal"
+	"github.com/tutu-network/tutu/internal/infra/marketplace"
+	"github.com/tutu-network/tutu/internal/infra/mlscheduler"
+	"github.com/tutu-network/tutu/internal/infra/onboarding"
+	"github.com/tutu-network/tutu/internal/infra/params"
+	"github.com/tutu-network/tutu/internal/infra/passive"
+	"github.com/tutu-network/tutu/internal/infra/redundancy"
+	"github.com/tutu-network/tutu/internal/infra/region"
 	"github.com/tutu-network/tutu/internal/infra/registry"
+	"github.com/tutu-network/tutu/internal/infra/reputation"
+	"github.com/tutu-network/tutu/internal/infra/resource"
+	"github.com/tutu-network/tutu/internal/infra/scheduler"
+	"github.com/tutu-network/tutu/internal/infra/selfheal"
 	"github.com/tutu-network/tutu/internal/infra/sqlite"
+	"github.com/tutu-network/tutu/internal/infra/tenant"
 )
 
 func newTestServer(t *testing.T) (*Server, func()) {
@@ -169,6 +197,39 @@ func TestAPI_ListModels_WithModels(t *testing.T) {
 	}
 }
 
+func TestAPI_ModelMetadata(t *testing.T) {
+	mgr, db := newTestMgr(t)
+	defer db.Close()
+	setupModel(t, mgr, "mistral")
+
+	pool := engine.NewPool(engine.NewMockBackend(), 1024*1024*1024, mgr.Resolve)
+	defer pool.UnloadAll()
+	h := NewServer(pool, mgr).Handler()
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/v1/models", nil))
+	var list modelList
+	if err := json.NewDecoder(w.Body).Decode(&list); err != nil || len(list.Data) != 1 {
+		t.Fatalf("/v1/models = %+v, %v", list, err)
+	}
+	if md := list.Data[0].Metadata; md == nil || md.ContextLength != 8192 || !md.ToolCalling || md.License != "apache-2.0" {
+		t.Errorf("metadata = %+v", md)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("POST", "/api/show", strings.NewReader(`{"name":"mistral"}`)))
+	var show ollamaShowResponse
+	if err := json.NewDecoder(w.Body).Decode(&show); err != nil {
+		t.Fatalf("decode /api/show: %v", err)
+	}
+	if strings.Join(show.Capabilities, ",") != "completion,tools" || show.License != "apache-2.0" {
+		t.Errorf("capabilities = %v, license = %q", show.Capabilities, show.License)
+	}
+	if show.ModelInfo["mistral.context_length"] != float64(8192) {
+		t.Errorf("model_info = %v", show.ModelInfo)
+	}
+}
+
 // ─── OpenAI /v1/chat/completions ────────────────────────────────────────────
 
 func TestAPI_ChatCompletions_NonStreaming(t *testing.T) {
@@ -211,6 +272,47 @@ func TestAPI_ChatCompletions_NonStreaming(t *testing.T) {
 	}
 }
 
+func TestAPI_ChatCompletions_Affinity(t *testing.T) {
+	mgr, db := newTestMgr(t)
+	defer db.Close()
+	setupModel(t, mgr, "test-model")
+
+	pool := engine.NewPool(engine.NewMockBackend(), 1024*1024*1024, mgr.Resolve)
+	defer pool.UnloadAll()
+	srv := NewServer(pool, mgr)
+	srv.SetAffinity(scheduler.NewAffinityTable(scheduler.AffinityConfig{}), "node-a")
+	h := srv.Handler()
+
+	chat := func(hint string) string {
+		body := `{"model":"test-model","messages":[{"role":"user","content":"Hi"}]` + hint + `}`
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("POST", "/v1/chat/completions", strings.NewReader(body)))
+		if w.Code != http.StatusOK {
+			t.Fatalf("chat = %d %s", w.Code, w.Body.String())
+		}
+		return w.Header().Get(affinityHeader)
+	}
+	if got := chat(`,"session_id":"conv-1"`); got != "new" {
+		t.Errorf("first turn affinity = %q, want new", got)
+	}
+	if got := chat(`,"session_id":"conv-1"`); got != "hit" {
+		t.Errorf("follow-up affinity = %q, want hit", got)
+	}
+	if got := chat(""); got != "" {
+		t.Errorf("unhinted request has affinity header %q", got)
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/api/scheduler/affinity", nil))
+	var st scheduler.AffinityStats
+	if err := json.NewDecoder(w.Body).Decode(&st); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if st.Hits != 1 || st.New != 1 || st.HintedRequests != 2 || st.HintedWarm != 1 || st.UnhintedWarm != 1 {
+		t.Errorf("stats = %+v", st)
+	}
+}
+
 func TestAPI_ChatCompletions_MissingModel(t *testing.T) {
 	srv, cleanup := newTestServer(t)
 	defer cleanup()
@@ -296,6 +398,52 @@ func TestAPI_Embeddings(t *testing.T) {
 	}
 }
 
+func TestAPI_Embeddings_Batched(t *testing.T) {
+	mgr, db := newTestMgr(t)
+	defer db.Close()
+	setupModel(t, mgr, "test-model")
+
+	pool := engine.NewPool(engine.NewMockBackend(), 1024*1024*1024, mgr.Resolve)
+	defer pool.UnloadAll()
+
+	srv := NewServer(pool, mgr)
+	batcher := engine.NewBatcher(pool, engine.BatchConfig{MaxBatchSize: 2, MaxDelay: time.Second})
+	srv.SetBatcher(batcher)
+	h := srv.Handler()
+
+	var wg sync.WaitGroup
+	codes := make([]int, 2)
+	for i := range codes {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			body := `{"model": "test-model", "input": ["a", "b"]}`
+			req := httptest.NewRequest("POST", "/v1/embeddings", strings.NewReader(body))
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+			codes[i] = w.Code
+		}(i)
+	}
+	wg.Wait()
+
+	for i, c := range codes {
+		if c != http.StatusOK {
+			t.Errorf("request %d status = %d, want 200", i, c)
+		}
+	}
+	if s := batcher.Stats(); s.Batches != 1 || s.Requests != 2 {
+		t.Errorf("batch stats = %+v, want 2 requests in 1 batch", s)
+	}
+
+	// Unknown models still surface as client errors
+	req := httptest.NewRequest("POST", "/v1/embeddings", strings.NewReader(`{"model": "missing", "input": "x"}`))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("missing model status = %d, want 400", w.Code)
+	}
+}
+
 // ─── Ollama /api/tags ───────────────────────────────────────────────────────
 
 func TestAPI_OllamaTags(t *testing.T) {
@@ -355,6 +503,53 @@ func TestAPI_OllamaPull(t *testing.T) {
 
 // ─── CORS ───────────────────────────────────────────────────────────────────
 
+// ─── Storage & Pinning ──────────────────────────────────────────────────────
+
+func TestAPI_StorageAndPinning(t *testing.T) {
+	mgr, db := newTestMgr(t)
+	defer db.Close()
+	setupModel(t, mgr, "tinyllama")
+	pool := engine.NewPool(engine.NewMockBackend(), 1024*1024*1024, mgr.Resolve)
+	defer pool.UnloadAll()
+	mgr.SetStorageBudget(1 << 30)
+	h := NewServer(pool, mgr).Handler()
+
+	tests := []struct {
+		path   string
+		body   string
+		status int
+		pinned bool
+	}{
+		{"/api/pin", `{"name":"tinyllama"}`, http.StatusOK, true},
+		{"/api/unpin", `{"name":"tinyllama"}`, http.StatusOK, false},
+		{"/api/pin", `{"name":"ghost"}`, http.StatusNotFound, false},
+		{"/api/pin", `{}`, http.StatusBadRequest, false},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("POST", tt.path, strings.NewReader(tt.body)))
+		if w.Code != tt.status {
+			t.Fatalf("%s %s: status = %d, want %d", tt.path, tt.body, w.Code, tt.status)
+		}
+		if tt.status != http.StatusOK {
+			continue
+		}
+
+		w = httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", "/api/storage", nil))
+		var u registry.StorageUsage
+		if err := json.NewDecoder(w.Body).Decode(&u); err != nil {
+			t.Fatalf("decode storage: %v", err)
+		}
+		if u.BudgetBytes != 1<<30 || len(u.Models) != 1 {
+			t.Fatalf("storage = %+v", u)
+		}
+		if u.Models[0].Pinned != tt.pinned || (u.PinnedBytes > 0) != tt.pinned {
+			t.Errorf("after %s: pinned = %v (%d bytes), want %v", tt.path, u.Models[0].Pinned, u.PinnedBytes, tt.pinned)
+		}
+	}
+}
+
 func TestAPI_CORS(t *testing.T) {
 	srv, cleanup := newTestServer(t)
 	defer cleanup()
@@ -393,3 +588,1709 @@ func TestBuildPrompt(t *testing.T) {
 // Ensure unused import of os is used
 var _ = os.TempDir
 var _ = io.Discard
+
+// ─── API Key Auth ───────────────────────────────────────────────────────────
+
+func TestAPI_Auth_DisabledByDefault(t *testing.T) {
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+
+	req := httptest.NewRequest("GET", "/v1/models", nil)
+	w := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+}
+
+func TestAPI_Auth_RequiresBearerToken(t *testing.T) {
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+	srv.SetAuth(func(token string) bool { return token == "s3cret-key" })
+
+	tests := []struct {
+		name   string
+		path   string
+		header string
+		want   int
+	}{
+		{"missing", "/v1/models", "", http.StatusUnauthorized},
+		{"wrong", "/v1/models", "Bearer nope", http.StatusUnauthorized},
+		{"valid", "/v1/models", "Bearer s3cret-key", http.StatusOK},
+		{"health is public", "/health", "", http.StatusOK},
+		{"version is public", "/api/version", "", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			w := httptest.NewRecorder()
+			srv.Handler().ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestAPI_Admin_AuditLog(t *testing.T) {
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+
+	log, err := audit.NewLog(nil)
+	if err != nil {
+		t.Fatalf("NewLog: %v", err)
+	}
+	log.Record(domain.AuditGovernance, "node-a", "proposal.execute", "prop-1", "")
+	srv.SetAuditLog(log)
+	h := srv.Handler()
+
+	// Query — the call itself is audited before the handler runs.
+	req := httptest.NewRequest("GET", "/api/admin/audit?category=admin_api", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("query status = %d, body = %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Count   int                 `json:"count"`
+		Entries []domain.AuditEntry `json:"entries"`
+	}
+	json.NewDecoder(w.Body).Decode(&resp)
+	if resp.Count != 1 || resp.Entries[0].Target != "/api/admin/audit" {
+		t.Fatalf("admin entries = %+v, want the query call itself", resp.Entries)
+	}
+	if e := resp.Entries[0]; e.Actor != "local" || !strings.Contains(e.Details, "peer=192.0.2.1:1234") {
+		t.Errorf("admin entry actor %q details %q, want local from the TCP peer", e.Actor, e.Details)
+	}
+
+	// A forwarded address is recorded beside the real peer, not instead of it
+	req = httptest.NewRequest("GET", "/api/admin/audit?actor=local&limit=1", nil)
+	req.Header.Set("X-Forwarded-For", "10.9.9.9")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	json.NewDecoder(w.Body).Decode(&resp)
+	if e := resp.Entries[0]; !strings.Contains(e.Details, "peer=192.0.2.1:1234 forwarded_for=10.9.9.9") {
+		t.Errorf("forwarded entry details %q, want the peer and the forwarded address", e.Details)
+	}
+
+	// Verify
+	req = httptest.NewRequest("GET", "/api/admin/audit/verify", nil)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	var res audit.VerifyResult
+	json.NewDecoder(w.Body).Decode(&res)
+	if w.Code != http.StatusOK || !res.Valid || res.Entries != 4 {
+		t.Errorf("verify = %d %+v, want valid with 4 entries", w.Code, res)
+	}
+
+	// Export
+	req = httptest.NewRequest("GET", "/api/admin/audit/export", nil)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if lines := strings.Count(w.Body.String(), "\n"); lines != 5 {
+		t.Errorf("export lines = %d, want 5", lines)
+	}
+
+	// Bad filter
+	req = httptest.NewRequest("GET", "/api/admin/audit?since=yesterday", nil)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("bad since status = %d, want 400", w.Code)
+	}
+}
+
+func TestAPI_Admin_Network(t *testing.T) {
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+
+	log, err := audit.NewLog(nil)
+	if err != nil {
+		t.Fatalf("NewLog: %v", err)
+	}
+	srv.SetAuditLog(log)
+
+	rep := reputation.NewTracker(reputation.DefaultTrackerConfig())
+	rep.Register("node-a")
+	_ = rep.RecordAvailability("node-a", reputation.AvailabilityCheck{WasOnline: true, FederationID: "fed-1"})
+	mesh := selfheal.NewMesh(selfheal.DefaultConfig())
+	mesh.Detect("node-b", selfheal.FailCPUOverload)
+	gov := governance.NewEngine(governance.DefaultEngineConfig())
+	if _, err := gov.CreateProposal("cheaper batch", "", governance.CatSLAPricing, "node-a", 500, "", ""); err != nil {
+		t.Fatalf("CreateProposal: %v", err)
+	}
+	clusters := gossip.NewHierarchy("node-a", gossip.HierarchyConfig{Region: "us-east", Zone: "a", Cluster: "c1"})
+	clusters.Apply(gossip.ClusterSummary{Region: "eu-west", Zone: "b", Cluster: "c7", Issuer: "node-z", Seq: 1, Alive: 12})
+	srv.SetNetworkOps(&NetworkOps{
+		Peers: func() []domain.Peer {
+			return []domain.Peer{{NodeID: "node-a", State: domain.PeerAlive}}
+		},
+		Hierarchy:  clusters,
+		Executor:   executor.New(executor.DefaultConfig(), nil, nil),
+		Reputation: rep,
+		SelfHeal:   mesh,
+		Governance: gov,
+		SLA: func() []scheduler.SLAClassStats {
+			return []scheduler.SLAClassStats{{Class: domain.SLABatch, Met: 3, Missed: 1, MissRate: 0.25}}
+		},
+	})
+	h := srv.Handler()
+
+	tests := []struct {
+		path   string
+		status int
+		want   string
+	}{
+		{"/api/admin/network/peers", http.StatusOK, `"node-a"`},
+		{"/api/admin/network/clusters?region=eu-west", http.StatusOK, `"cluster":"c7"`},
+		{"/api/admin/network/clusters/eu-west/b/c7", http.StatusOK, `"alive":12`},
+		{"/api/admin/network/clusters/eu-west/b/c8", http.StatusNotFound, ""},
+		{"/api/admin/network/tasks", http.StatusOK, `"max_slots":4`},
+		{"/api/admin/network/reputation?limit=5", http.StatusOK, `"tier"`},
+		{"/api/admin/network/reputation?federation=fed-1", http.StatusOK, `"federation":"fed-1"`},
+		{"/api/admin/network/reputation?federation=fed-2", http.StatusOK, `"nodes":[]`},
+		{"/api/admin/network/reputation/export?format=csv", http.StatusOK, "node_id,overall,tier"},
+		{"/api/admin/network/reputation/export?anonymize=true&salt=s", http.StatusOK, `"node_id":"anon-`},
+		{"/api/admin/network/reputation/export?format=xml", http.StatusBadRequest, ""},
+		{"/api/admin/network/reputation/summary?bins=4", http.StatusOK, `"LOW":1`},
+		{"/api/admin/network/incidents", http.StatusOK, `"CPU_OVERLOAD"`},
+		{"/api/admin/network/votes?status=active", http.StatusOK, `"proposals"`},
+		{"/api/admin/network/votes", http.StatusOK, `"miss_rate":0.25`},
+		{"/api/admin/network/votes?status=bogus", http.StatusBadRequest, ""},
+		{"/api/admin/network/votes?category=sla_pricing&q=batch&sort=expiry&order=asc", http.StatusOK, `"cheaper batch"`},
+		{"/api/admin/network/votes?sort=bogus", http.StatusBadRequest, ""},
+		{"/api/admin/network/votes?cursor=bogus", http.StatusBadRequest, ""},
+		{"/api/admin/network/placements", http.StatusServiceUnavailable, ""},
+		{"/api/admin/network/autoscale", http.StatusServiceUnavailable, ""},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", tt.path, nil))
+		if w.Code != tt.status {
+			t.Errorf("%s: status = %d, want %d (body %s)", tt.path, w.Code, tt.status, w.Body.String())
+			continue
+		}
+		if tt.want != "" && !strings.Contains(w.Body.String(), tt.want) {
+			t.Errorf("%s: body %s missing %s", tt.path, w.Body.String(), tt.want)
+		}
+	}
+
+	// Every network call is audited like any other admin call.
+	if entries, _ := log.Query(audit.Query{Category: domain.AuditAdminAPI}); len(entries) != len(tests) {
+		t.Errorf("audited admin calls = %d, want %d", len(entries), len(tests))
+	}
+}
+
+func TestAPI_Admin_NetworkPlacementAck(t *testing.T) {
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+
+	opt := intelligence.NewOptimizer(intelligence.DefaultConfig())
+	for i := 0; i < 20; i++ {
+		opt.RecordRequest("llama-3", "node-A", 20, true)
+		opt.RecordRequest("llama-3", "node-B", 300, false)
+	}
+	recs := opt.Optimize()
+	if len(recs) == 0 {
+		t.Fatal("no recommendation to acknowledge")
+	}
+	log, err := audit.NewLog(nil)
+	if err != nil {
+		t.Fatalf("NewLog: %v", err)
+	}
+	srv.SetAuditLog(log)
+	srv.SetNetworkOps(&NetworkOps{Intelligence: opt})
+	h := srv.Handler()
+
+	ack := func(id, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("POST", "/api/admin/network/placements/"+id+"/ack", strings.NewReader(body)))
+		return w
+	}
+	tests := []struct {
+		id, body string
+		status   int
+		want     string
+	}{
+		{"nope", `{"status":"ACCEPTED"}`, http.StatusNotFound, ""},
+		{recs[0].ID, `{`, http.StatusBadRequest, ""},
+		{recs[0].ID, `{"status":"PENDING"}`, http.StatusBadRequest, ""},
+		{recs[0].ID, `{"status":"failed","detail":"disk full"}`, http.StatusOK, `"status":"FAILED"`},
+		{recs[0].ID, `{"status":"COMPLETED"}`, http.StatusConflict, ""},
+	}
+	for _, tt := range tests {
+		w := ack(tt.id, tt.body)
+		if w.Code != tt.status {
+			t.Errorf("ack %s %s: status = %d, want %d (body %s)", tt.id, tt.body, w.Code, tt.status, w.Body.String())
+			continue
+		}
+		if tt.want != "" && !strings.Contains(w.Body.String(), tt.want) {
+			t.Errorf("ack %s %s: body %s missing %s", tt.id, tt.body, w.Body.String(), tt.want)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/api/admin/network/placements", nil))
+	if !strings.Contains(w.Body.String(), `"detail":"disk full"`) {
+		t.Errorf("placements = %s, want the reported outcome", w.Body.String())
+	}
+}
+
+func TestAPI_Admin_Params(t *testing.T) {
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+
+	log, err := audit.NewLog(nil)
+	if err != nil {
+		t.Fatalf("NewLog: %v", err)
+	}
+	srv.SetAuditLog(log)
+
+	days := 30
+	svc := params.NewService(params.DefaultConfig())
+	svc.Register(params.Int("intelligence.retirement_days", "", 1, 365,
+		func() int { return days },
+		func(n int) error { days = n; return nil }))
+	srv.SetParams(svc)
+	h := srv.Handler()
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return w
+	}
+
+	tests := []struct {
+		method, path, body string
+		status             int
+	}{
+		{"PUT", "/api/admin/params/intelligence.retirement_days", `{"value":"7"}`, http.StatusOK},
+		{"PUT", "/api/admin/params/intelligence.retirement_days", `{"value":"0"}`, http.StatusBadRequest},
+		{"PUT", "/api/admin/params/nope", `{"value":"1"}`, http.StatusNotFound},
+		{"POST", "/api/admin/params/history/42/revert", "", http.StatusNotFound},
+		{"POST", "/api/admin/params/history/1/revert", "", http.StatusOK},
+	}
+	for _, tt := range tests {
+		if w := do(tt.method, tt.path, tt.body); w.Code != tt.status {
+			t.Errorf("%s %s: status = %d, want %d (body %s)", tt.method, tt.path, w.Code, tt.status, w.Body.String())
+		}
+	}
+	if days != 30 {
+		t.Errorf("days = %d after set + revert, want 30", days)
+	}
+
+	w := do("GET", "/api/admin/params/history", "")
+	var hist struct {
+		Changes []params.Change `json:"changes"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &hist)
+	if len(hist.Changes) != 2 || hist.Changes[0].Source != "revert:1" {
+		t.Errorf("history = %+v, want set + revert", hist.Changes)
+	}
+	if w := do("GET", "/api/admin/params", ""); !strings.Contains(w.Body.String(), `"value":"30"`) {
+		t.Errorf("list = %s", w.Body.String())
+	}
+}
+
+func TestAPI_Admin_IdlePolicy(t *testing.T) {
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+
+	log, err := audit.NewLog(nil)
+	if err != nil {
+		t.Fatalf("NewLog: %v", err)
+	}
+	srv.SetAuditLog(log)
+
+	sample := resource.Sample{CPUPercent: 50, Idle: domain.IdleDeep, BatteryPct: 100}
+	pe, err := resource.NewPolicyEngine(resource.DefaultPolicyConfig(), resource.DefaultIdlePolicy(),
+		func() resource.Sample { return sample })
+	if err != nil {
+		t.Fatalf("NewPolicyEngine: %v", err)
+	}
+	srv.SetIdlePolicy(pe)
+	h := srv.Handler()
+
+	do := func(method, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(method, "/api/admin/idle-policy", strings.NewReader(body)))
+		return w
+	}
+
+	if w := do("PUT", `{"min_idle":"asleep"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("PUT invalid policy: status = %d, want 400", w.Code)
+	}
+	w := do("PUT", `{"enabled":true}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("PUT: status = %d, body %s", w.Code, w.Body.String())
+	}
+	var st resource.PolicyStatus
+	json.Unmarshal(w.Body.Bytes(), &st)
+	// Only the fields sent change; the default CPU limit now blocks work
+	if !st.Policy.Enabled || st.Policy.MaxCPUPercent != 30 || st.Decision.Accept || len(st.Decision.Reasons) != 1 {
+		t.Errorf("status after PUT = %+v", st)
+	}
+	if w := do("GET", ""); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"idle":"deep"`) {
+		t.Errorf("GET: status = %d, body %s", w.Code, w.Body.String())
+	}
+}
+
+func TestAPI_Hardware(t *testing.T) {
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+
+	log, err := audit.NewLog(nil)
+	if err != nil {
+		t.Fatalf("NewLog: %v", err)
+	}
+	srv.SetAuditLog(log)
+
+	hw := passive.Hardware{CPUCores: 8, RAMBytes: 16 << 30, GPUs: []passive.GPU{{Name: "RTX 3060", VRAMBytes: 12 << 30}}}
+	p := passive.NewProfiler(passive.DefaultProfilerConfig(), func() passive.Hardware { return hw },
+		func(context.Context) (float64, error) { return 250, nil })
+	srv.SetHardwareProfiler(p)
+	h := srv.Handler()
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/api/hardware", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("GET before benchmark: status = %d, want 503", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("POST", "/api/admin/hardware/benchmark", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("POST benchmark: status = %d, body %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/api/hardware", nil))
+	var prof passive.Profile
+	json.Unmarshal(w.Body.Bytes(), &prof)
+	if w.Code != http.StatusOK || prof.TierName != "high" || prof.BenchmarkTPS != 250 || prof.Hardware.CPUCores != 8 {
+		t.Errorf("GET: status = %d, profile %+v", w.Code, prof)
+	}
+}
+
+func TestAPI_ModelBenchmarks(t *testing.T) {
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+	setupModel(t, srv.models, "tinyllama")
+
+	log, err := audit.NewLog(nil)
+	if err != nil {
+		t.Fatalf("NewLog: %v", err)
+	}
+	srv.SetAuditLog(log)
+
+	var stored []domain.ModelBenchmark
+	srv.SetModelBenchmarks(&BenchmarkOps{
+		List: func() ([]domain.ModelBenchmark, error) { return stored, nil },
+		Run: func(ctx context.Context, model string) (domain.ModelBenchmark, error) {
+			b, err := srv.pool.Benchmark(ctx, model, engine.BenchmarkConfig{MaxTokens: 2, Runs: 1})
+			if err == nil {
+				stored = append(stored, b)
+			}
+			return b, err
+		},
+	})
+	h := srv.Handler()
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("POST", "/api/admin/benchmarks/missing", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("POST unknown model: status = %d, want 404", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("POST", "/api/admin/benchmarks/tinyllama", nil))
+	var b domain.ModelBenchmark
+	json.Unmarshal(w.Body.Bytes(), &b)
+	if w.Code != http.StatusOK || b.TokensPerSec <= 0 || b.Runs != 1 {
+		t.Fatalf("POST benchmark: status = %d, body %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/api/benchmarks", nil))
+	var list struct {
+		Benchmarks []domain.ModelBenchmark `json:"benchmarks"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &list)
+	if w.Code != http.StatusOK || len(list.Benchmarks) != 1 || list.Benchmarks[0].Model != b.Model {
+		t.Errorf("GET: status = %d, body %s", w.Code, w.Body.String())
+	}
+}
+
+func TestAPI_Retirements(t *testing.T) {
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+	setupModel(t, srv.models, "tinyllama")
+
+	log, err := audit.NewLog(nil)
+	if err != nil {
+		t.Fatalf("NewLog: %v", err)
+	}
+	srv.SetAuditLog(log)
+	srv.SetRetirements(&RetirementOps{
+		Optimizer: intelligence.NewOptimizer(intelligence.DefaultConfig()),
+		Retirer: intelligence.NewRetirer(intelligence.RetirementConfig{MinReplicas: -1}, intelligence.RetirementHooks{
+			InFlight: srv.pool.Refs,
+			Remove:   srv.models.Retire,
+		}),
+	})
+	h := srv.Handler()
+
+	// A request holding the model blocks its retirement
+	handle, err := srv.pool.Acquire("tinyllama", defaultLoadOpts())
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("POST", "/api/admin/retirements/tinyllama", nil))
+	if w.Code != http.StatusConflict {
+		t.Errorf("in flight: status = %d, want 409 (%s)", w.Code, w.Body.String())
+	}
+	handle.Release()
+
+	// A veto blocks it until lifted
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("POST", "/api/admin/retirements/tinyllama/veto", strings.NewReader(`{"by":"owner:alice"}`)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("veto: status = %d, body %s", w.Code, w.Body.String())
+	}
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("POST", "/api/admin/retirements/tinyllama", nil))
+	if w.Code != http.StatusConflict {
+		t.Errorf("vetoed: status = %d, want 409 (%s)", w.Code, w.Body.String())
+	}
+	for _, want := range []int{http.StatusOK, http.StatusNotFound} {
+		w = httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("DELETE", "/api/admin/retirements/tinyllama/veto", nil))
+		if w.Code != want {
+			t.Errorf("lift veto: status = %d, want %d (%s)", w.Code, want, w.Body.String())
+		}
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("POST", "/api/admin/retirements/tinyllama", strings.NewReader(`{"reason":"unused"}`)))
+	var out intelligence.RetirementOutcome
+	json.Unmarshal(w.Body.Bytes(), &out)
+	if w.Code != http.StatusOK || !out.Executed || out.Reason != "unused" {
+		t.Fatalf("retire: status = %d, body %s", w.Code, w.Body.String())
+	}
+	if _, err := srv.models.Show("tinyllama"); err == nil {
+		t.Error("model still present after retirement")
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/api/admin/retirements", nil))
+	var list retirementList
+	json.Unmarshal(w.Body.Bytes(), &list)
+	if w.Code != http.StatusOK || len(list.Outcomes) != 3 || !list.Outcomes[0].Executed || list.Outcomes[1].VetoedBy != "owner:alice" ||
+		list.Outcomes[2].InFlight != 1 || len(list.Workflow) != 0 {
+		t.Errorf("GET: status = %d, body %s", w.Code, w.Body.String())
+	}
+}
+
+func TestAPI_OpenAPI(t *testing.T) {
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+
+	w := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/api/openapi.json", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d", w.Code)
+	}
+	var doc OpenAPIDoc
+	if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if doc.OpenAPI != "3.0.3" || doc.Info.Version != apiVersion {
+		t.Errorf("header = %s %+v", doc.OpenAPI, doc.Info)
+	}
+
+	// Every route of the base server is documented
+	for path, ops := range doc.Paths {
+		for method, op := range ops {
+			if op.Summary == "" {
+				t.Errorf("%s %s is not in endpointDocs", strings.ToUpper(method), path)
+			}
+		}
+	}
+	if _, ok := doc.Paths["/"]; ok {
+		t.Error("website routes should not be listed")
+	}
+	if op := doc.Paths["/health"]["get"]; op == nil || op.Security == nil || len(*op.Security) != 0 {
+		t.Errorf("/health should be public: %+v", op)
+	}
+
+	chat := doc.Components.Schemas["ChatRequest"]
+	if chat == nil || chat.Properties["messages"].Items.Ref != "#/components/schemas/ChatMessage" {
+		t.Fatalf("ChatRequest = %+v", chat)
+	}
+	if temp := chat.Properties["temperature"]; temp.Type != "number" || !temp.Nullable {
+		t.Errorf("temperature = %+v, want nullable number", temp)
+	}
+	if strings.Join(chat.Required, ",") != "messages,model,stream" {
+		t.Errorf("required = %v", chat.Required)
+	}
+}
+
+func TestAPI_OpenAPI_FollowsMountedRoutes(t *testing.T) {
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+
+	doc, err := srv.OpenAPI()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := doc.Paths["/api/benchmarks"]; ok {
+		t.Error("benchmarks listed while disabled")
+	}
+
+	log, err := audit.NewLog(nil)
+	if err != nil {
+		t.Fatalf("NewLog: %v", err)
+	}
+	srv.SetAuditLog(log)
+	srv.SetModelBenchmarks(&BenchmarkOps{})
+	doc, err = srv.OpenAPI()
+	if err != nil {
+		t.Fatal(err)
+	}
+	run := doc.Paths["/api/admin/benchmarks/{model}"]["post"]
+	if run == nil || run.OperationID != "postApiAdminBenchmarksModel" || run.Tags[0] != "admin" {
+		t.Fatalf("run benchmark = %+v", run)
+	}
+	if len(run.Parameters) != 1 || run.Parameters[0].Name != "model" || run.Parameters[0].In != "path" {
+		t.Errorf("parameters = %+v", run.Parameters)
+	}
+	if ref := run.Responses["200"].Content["application/json"].Schema.Ref; ref != "#/components/schemas/ModelBenchmark" {
+		t.Errorf("response schema = %q", ref)
+	}
+	bench := doc.Components.Schemas["ModelBenchmark"]
+	if bench == nil || bench.Properties["measured_at"].Format != "date-time" || bench.Properties["tokens_per_sec"].Type != "number" {
+		t.Errorf("ModelBenchmark = %+v", bench)
+	}
+}
+
+func TestWriteDomainError(t *testing.T) {
+	for _, tt := range []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{fmt.Errorf("%w: fed-1", domain.ErrFederationNotFound), http.StatusNotFound, "not_found"},
+		{domain.ErrAlreadyVoted, http.StatusConflict, "conflict"},
+		{domain.ErrTooManyActiveProposals, http.StatusTooManyRequests, "quota_exceeded"},
+		{domain.ErrReputationTooLow, http.StatusForbidden, "not_eligible"},
+		{domain.ErrInvalidProposal, http.StatusBadRequest, "invalid"},
+		{fmt.Errorf("disk on fire"), http.StatusInternalServerError, ""},
+	} {
+		w := httptest.NewRecorder()
+		writeDomainError(w, tt.err)
+		var body struct {
+			Error struct {
+				Message string `json:"message"`
+				Code    string `json:"code"`
+			} `json:"error"`
+		}
+		json.NewDecoder(w.Body).Decode(&body)
+		if w.Code != tt.status || body.Error.Code != tt.code || body.Error.Message != tt.err.Error() {
+			t.Errorf("%v: status %d code %q, want %d %q", tt.err, w.Code, body.Error.Code, tt.status, tt.code)
+		}
+	}
+}
+
+func TestAPI_Namespaces(t *testing.T) {
+	mgr, db := newTestMgr(t)
+	defer db.Close()
+	setupModel(t, mgr, "test-model")
+	pool := engine.NewPool(engine.NewMockBackend(), 1024*1024*1024, mgr.Resolve)
+	defer pool.UnloadAll()
+
+	srv := NewServer(pool, mgr)
+	srv.SetAuth(func(token string) bool { return token == "node-key" })
+	log, err := audit.NewLog(nil)
+	if err != nil {
+		t.Fatalf("NewLog: %v", err)
+	}
+	srv.SetAuditLog(log)
+	tenants, _ := tenant.NewRegistry(tenant.Config{}, nil)
+	srv.SetTenants(tenants)
+	h := srv.Handler()
+
+	do := func(method, path, key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+key)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do("POST", "/api/admin/namespaces", "node-key", `{"name":"search","models":["test-model"],"rate_limit_rpm":3}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: status = %d, body %s", w.Code, w.Body.String())
+	}
+	if w := do("POST", "/api/admin/namespaces", "node-key", `{"name":"search"}`); w.Code != http.StatusConflict {
+		t.Errorf("duplicate create: status = %d, want 409", w.Code)
+	}
+	w = do("POST", "/api/admin/namespaces/search/keys", "node-key", "")
+	var issued struct{ ID, Key string }
+	json.Unmarshal(w.Body.Bytes(), &issued)
+	if w.Code != http.StatusCreated || issued.Key == "" {
+		t.Fatalf("issue key: status = %d, body %s", w.Code, w.Body.String())
+	}
+
+	// Namespace keys never reach the admin API
+	if w := do("GET", "/api/admin/namespaces", issued.Key, ""); w.Code != http.StatusForbidden {
+		t.Errorf("admin with namespace key: status = %d, want 403", w.Code)
+	}
+	chat := `{"model":"%s","messages":[{"role":"user","content":"Hi"}]}`
+	if w := do("POST", "/v1/chat/completions", issued.Key, fmt.Sprintf(chat, "other-model")); w.Code != http.StatusForbidden {
+		t.Errorf("model outside allowlist: status = %d, want 403", w.Code)
+	}
+	if w := do("POST", "/v1/chat/completions", issued.Key, fmt.Sprintf(chat, "test-model")); w.Code != http.StatusOK {
+		t.Errorf("allowed model: status = %d, body %s", w.Code, w.Body.String())
+	}
+
+	w = do("GET", "/api/usage", issued.Key, "")
+	var usage tenant.Usage
+	json.Unmarshal(w.Body.Bytes(), &usage)
+	if w.Code != http.StatusOK || usage.Namespace != "search" || usage.Requests != 1 || usage.ModelDenied != 1 {
+		t.Errorf("usage: status = %d, %+v", w.Code, usage)
+	}
+
+	// The fourth request in the minute is over the limit of 3
+	w = do("GET", "/api/usage", issued.Key, "")
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") == "" {
+		t.Errorf("over limit: status = %d, Retry-After %q", w.Code, w.Header().Get("Retry-After"))
+	}
+	// The node's own key is in the default namespace, which has no limit
+	if w := do("GET", "/api/usage", "node-key", ""); w.Code != http.StatusOK {
+		t.Errorf("node key: status = %d", w.Code)
+	}
+
+	if w := do("DELETE", "/api/admin/namespaces/search/keys/"+issued.ID, "node-key", ""); w.Code != http.StatusNoContent {
+		t.Errorf("revoke: status = %d", w.Code)
+	}
+	if w := do("GET", "/api/usage", issued.Key, ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("revoked key: status = %d, want 401", w.Code)
+	}
+}
+
+func TestAPI_Namespaces_NeedNodeKey(t *testing.T) {
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+	log, _ := audit.NewLog(nil)
+	srv.SetAuditLog(log)
+	tenants, _ := tenant.NewRegistry(tenant.Config{}, nil)
+	srv.SetTenants(tenants)
+	h := srv.Handler()
+
+	do := func(method, path, key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if key != "" {
+			req.Header.Set("Authorization", "Bearer "+key)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := do("POST", "/api/admin/namespaces", "", `{"name":"search"}`); w.Code != http.StatusConflict || !strings.Contains(w.Body.String(), "api_key") {
+		t.Errorf("create without a node key: status = %d %s, want 409", w.Code, w.Body.String())
+	}
+	if w := do("GET", "/api/tags", "", ""); w.Code != http.StatusOK {
+		t.Errorf("no namespaces, no node key: status = %d, want 200", w.Code)
+	}
+
+	// Namespaces left behind after the node key was removed
+	if _, err := tenants.Create(domain.Namespace{Name: "search"}); err != nil {
+		t.Fatal(err)
+	}
+	if w := do("POST", "/api/admin/namespaces/search/keys", "", ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("issue key without a node key: status = %d, want 401", w.Code)
+	}
+	key, _, err := tenants.IssueKey("search")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w := do("GET", "/api/tags", "", ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("no key with namespaces: status = %d, want 401", w.Code)
+	}
+	if w := do("GET", "/api/usage", key, ""); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "search") {
+		t.Errorf("namespace key: status = %d %s, want 200 for search", w.Code, w.Body.String())
+	}
+}
+
+func TestAPI_Admin_TaskJournal(t *testing.T) {
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+
+	log, err := audit.NewLog(nil)
+	if err != nil {
+		t.Fatalf("NewLog: %v", err)
+	}
+	srv.SetAuditLog(log)
+
+	db, err := sqlite.Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Open db: %v", err)
+	}
+	defer db.Close()
+	j, err := journal.New(journal.DefaultConfig(), db)
+	if err != nil {
+		t.Fatalf("journal.New: %v", err)
+	}
+	start := time.Now()
+	for i, typ := range []domain.TaskEventType{domain.TaskEventSubmitted, domain.TaskEventScheduled, domain.TaskEventCompleted} {
+		j.Record(domain.TaskEvent{TaskID: "t1", Type: typ, Timestamp: start.Add(time.Duration(i) * time.Second)})
+	}
+	j.Record(domain.TaskEvent{TaskID: "t2", Type: domain.TaskEventSubmitted})
+	srv.SetTaskJournal(j)
+	h := srv.Handler()
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w
+	}
+
+	tests := []struct {
+		path   string
+		status int
+		want   string
+	}{
+		{"/api/admin/tasks/journal?after=1&limit=2", http.StatusOK, `"next":3`},
+		{"/api/admin/tasks/journal?after=x", http.StatusBadRequest, ""},
+		{"/api/admin/tasks/journal/stats", http.StatusOK, `"COMPLETED":{"count":1`},
+		{"/api/admin/tasks/in-flight", http.StatusOK, `"task_id":"t2"`},
+		{"/api/admin/tasks/t1/events", http.StatusOK, `"total_ms":2000`},
+		{"/api/admin/tasks/nope/events", http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		w := get(tt.path)
+		if w.Code != tt.status || !strings.Contains(w.Body.String(), tt.want) {
+			t.Errorf("GET %s = %d %s, want %d containing %s", tt.path, w.Code, w.Body.String(), tt.status, tt.want)
+		}
+	}
+}
+
+func TestAPI_Admin_TaskResults(t *testing.T) {
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+
+	log, err := audit.NewLog(nil)
+	if err != nil {
+		t.Fatalf("NewLog: %v", err)
+	}
+	srv.SetAuditLog(log)
+	db, err := sqlite.Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Open db: %v", err)
+	}
+	defer db.Close()
+	j, err := journal.New(journal.DefaultConfig(), db)
+	if err != nil {
+		t.Fatalf("journal.New: %v", err)
+	}
+	srv.SetTaskJournal(j)
+
+	var accepted domain.Task
+	srv.SetTaskResults(&TaskResultOps{
+		Accept: func(task domain.Task) error {
+			if task.ID == "stray" {
+				return domain.ErrTaskNotAssigned
+			}
+			accepted = task
+			return nil
+		},
+		Dispute: func(taskID, reason string) (*sqlite.AttestationRecord, error) {
+			if taskID == "twice" {
+				return nil, domain.ErrAttestationDisputed
+			}
+			return &sqlite.AttestationRecord{Disputed: true, DisputeReason: reason}, nil
+		},
+	})
+	h := srv.Handler()
+
+	post := func(path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("POST", path, strings.NewReader(body)))
+		return w
+	}
+
+	tests := []struct {
+		path, body string
+		status     int
+		want       string
+	}{
+		{"/api/admin/tasks/t1/result", `{"credits":5,"attestation":{"task_id":"t1","node_id":"exec"}}`, http.StatusOK, `"paid":5`},
+		{"/api/admin/tasks/stray/result", `{"credits":5}`, http.StatusNotFound, ""},
+		{"/api/admin/tasks/t1/result", `not json`, http.StatusBadRequest, ""},
+		{"/api/admin/tasks/t1/dispute", `{"reason":"wrong answer"}`, http.StatusOK, `"dispute_reason":"wrong answer"`},
+		{"/api/admin/tasks/twice/dispute", `{"reason":"again"}`, http.StatusConflict, ""},
+		{"/api/admin/tasks/t1/dispute", `{}`, http.StatusBadRequest, ""},
+	}
+	for _, tt := range tests {
+		w := post(tt.path, tt.body)
+		if w.Code != tt.status || !strings.Contains(w.Body.String(), tt.want) {
+			t.Errorf("POST %s = %d %s, want %d containing %s", tt.path, w.Code, w.Body.String(), tt.status, tt.want)
+		}
+	}
+	if accepted.ID != "t1" || accepted.Attestation == nil || accepted.Attestation.NodeID != "exec" {
+		t.Errorf("accepted task = %+v, want t1 with its attestation", accepted)
+	}
+}
+
+func TestAPI_Admin_Housekeeping(t *testing.T) {
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+
+	log, err := audit.NewLog(nil)
+	if err != nil {
+		t.Fatalf("NewLog: %v", err)
+	}
+	srv.SetAuditLog(log)
+
+	hk := housekeeping.New(housekeeping.DefaultConfig())
+	hk.Register(housekeeping.Job{Name: "reputation_decay", Interval: time.Hour, Enabled: true,
+		Run: func(context.Context) (string, error) { return "decayed 4 nodes", nil }})
+	srv.SetHousekeeping(hk)
+	h := srv.Handler()
+
+	do := func(method, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		return w
+	}
+
+	tests := []struct {
+		method, path string
+		status       int
+		want         string
+	}{
+		{"GET", "/api/admin/housekeeping", http.StatusOK, `"name":"reputation_decay","enabled":true`},
+		{"POST", "/api/admin/housekeeping/reputation_decay/run", http.StatusOK, `"last_result":"decayed 4 nodes"`},
+		{"POST", "/api/admin/housekeeping/reputation_decay/disable", http.StatusOK, `"enabled":false`},
+		{"GET", "/api/admin/housekeeping", http.StatusOK, `"runs":1`},
+		{"POST", "/api/admin/housekeeping/reputation_decay/enable", http.StatusOK, `"enabled":true`},
+		{"POST", "/api/admin/housekeeping/defrag/run", http.StatusNotFound, "unknown job"},
+	}
+	for _, tt := range tests {
+		w := do(tt.method, tt.path)
+		if w.Code != tt.status || !strings.Contains(w.Body.String(), tt.want) {
+			t.Errorf("%s %s = %d %s, want %d containing %s", tt.method, tt.path, w.Code, w.Body.String(), tt.status, tt.want)
+		}
+	}
+}
+
+func TestAPI_Dashboard(t *testing.T) {
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+
+	get := func(etag string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/api/dashboard", nil)
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		w := httptest.NewRecorder()
+		srv.Handler().ServeHTTP(w, req)
+		return w
+	}
+	if w := get(""); w.Code != http.StatusNotFound {
+		t.Errorf("status without dashboard = %d, want 404", w.Code)
+	}
+
+	db, err := sqlite.Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Open db: %v", err)
+	}
+	defer db.Close()
+	svc := credit.NewService(db)
+	_ = svc.Earn(12, "task-1", "inference")
+	srv.SetDashboard(&DashboardOps{NodeID: "node-1", Credit: svc})
+
+	w := get("")
+	etag := w.Header().Get("ETag")
+	if w.Code != http.StatusOK || etag == "" {
+		t.Fatalf("status = %d, ETag %q", w.Code, etag)
+	}
+	var view DashboardView
+	if err := json.Unmarshal(w.Body.Bytes(), &view); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if view.Node.ID != "node-1" || view.Earnings == nil || view.Earnings.Today != 12 || view.Tasks != nil {
+		t.Errorf("dashboard = %+v", view)
+	}
+
+	if w := get(etag); w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+		t.Errorf("matching If-None-Match: status %d, %d body bytes; want 304, empty", w.Code, w.Body.Len())
+	}
+	if w := get(`"other", W/` + etag); w.Code != http.StatusNotModified {
+		t.Errorf("weak match in list: status %d, want 304", w.Code)
+	}
+
+	_ = svc.Earn(3, "task-2", "inference")
+	if w := get(etag); w.Code != http.StatusOK || w.Header().Get("ETag") == etag {
+		t.Errorf("after earning: status %d, ETag unchanged %v", w.Code, w.Header().Get("ETag") == etag)
+	}
+}
+
+func TestAPI_Admin_Diagnostics(t *testing.T) {
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+
+	log, err := audit.NewLog(nil)
+	if err != nil {
+		t.Fatalf("NewLog: %v", err)
+	}
+	srv.SetAuditLog(log)
+
+	h := srv.Handler()
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/api/admin/diagnostics", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status without diagnostics = %d, want 404", w.Code)
+	}
+
+	srv.SetDiagnostics(func(out io.Writer) error {
+		_, err := out.Write([]byte("bundle"))
+		return err
+	})
+	h = srv.Handler()
+
+	// Without an API key only loopback clients get the bundle
+	local := func() *http.Request {
+		req := httptest.NewRequest("GET", "/api/admin/diagnostics", nil)
+		req.RemoteAddr = "127.0.0.1:51234"
+		return req
+	}
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/api/admin/diagnostics", nil))
+	if w.Code != http.StatusForbidden {
+		t.Errorf("remote status without API key = %d, want 403", w.Code)
+	}
+	proxied := local()
+	proxied.Header.Set("X-Forwarded-For", "127.0.0.1")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, proxied)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("proxied status without API key = %d, want 403", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, local())
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	if origin := w.Header().Get("Access-Control-Allow-Origin"); origin != "" {
+		t.Errorf("Access-Control-Allow-Origin = %q, want none", origin)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/gzip" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	if cd := w.Header().Get("Content-Disposition"); !strings.Contains(cd, "tutu-diagnostics-") {
+		t.Errorf("Content-Disposition = %q", cd)
+	}
+	if w.Body.String() != "bundle" {
+		t.Errorf("body = %q", w.Body.String())
+	}
+
+	srv.SetAuth(func(token string) bool { return token == "secret" })
+	h = srv.Handler()
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, local())
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("status without token = %d, want 401", w.Code)
+	}
+	remote := httptest.NewRequest("GET", "/api/admin/diagnostics", nil)
+	remote.Header.Set("Authorization", "Bearer secret")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, remote)
+	if w.Code != http.StatusOK {
+		t.Errorf("remote status with token = %d, want 200", w.Code)
+	}
+}
+
+func TestAPI_GenerationParams_Validation(t *testing.T) {
+	mgr, db := newTestMgr(t)
+	defer db.Close()
+	setupModel(t, mgr, "test-model")
+
+	pool := engine.NewPool(engine.NewMockBackend(), 1024*1024*1024, mgr.Resolve)
+	defer pool.UnloadAll()
+	h := NewServer(pool, mgr).Handler()
+
+	tests := []struct {
+		name      string
+		path      string
+		body      string
+		want      int
+		wantParam string
+	}{
+		{"openai temperature", "/v1/chat/completions",
+			`{"model":"test-model","messages":[{"role":"user","content":"hi"}],"temperature":3}`,
+			http.StatusBadRequest, "temperature"},
+		{"openai logit_bias", "/v1/chat/completions",
+			`{"model":"test-model","messages":[{"role":"user","content":"hi"}],"logit_bias":{"x":1}}`,
+			http.StatusBadRequest, "logit_bias"},
+		{"ollama num_predict", "/api/generate",
+			`{"model":"test-model","prompt":"hi","options":{"num_predict":-5}}`,
+			http.StatusBadRequest, "max_tokens"},
+		{"ollama unlimited num_predict", "/api/generate",
+			`{"model":"test-model","prompt":"hi","stream":false,"options":{"num_predict":-1}}`,
+			http.StatusOK, ""},
+		{"ollama fill-context num_predict", "/api/generate",
+			`{"model":"test-model","prompt":"hi","stream":false,"options":{"num_predict":-2}}`,
+			http.StatusOK, ""},
+		{"ollama greedy with top_p", "/api/generate",
+			`{"model":"test-model","prompt":"hi","stream":false,"options":{"temperature":0,"top_p":0.5}}`,
+			http.StatusOK, ""},
+		{"openai valid seed", "/v1/chat/completions",
+			`{"model":"test-model","messages":[{"role":"user","content":"hi"}],"seed":42,"max_tokens":3}`,
+			http.StatusOK, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", tt.path, strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d, body: %s", w.Code, tt.want, w.Body.String())
+			}
+			if tt.wantParam == "" {
+				return
+			}
+			var resp struct {
+				Error struct {
+					Type  string `json:"type"`
+					Param string `json:"param"`
+				} `json:"error"`
+			}
+			json.NewDecoder(w.Body).Decode(&resp)
+			if resp.Error.Param != tt.wantParam || resp.Error.Type != "invalid_request_error" {
+				t.Errorf("error = %+v, want param %q", resp.Error, tt.wantParam)
+			}
+		})
+	}
+}
+
+func TestAPI_OllamaNumPredictSentinels(t *testing.T) {
+	mgr, db := newTestMgr(t)
+	defer db.Close()
+	setupModel(t, mgr, "test-model")
+	srv := NewServer(engine.NewPool(engine.NewMockBackend(), 1<<30, mgr.Resolve), mgr)
+	srv.params.SetModelLimits("test-model", engine.ParamLimits{MaxTokens: 1024})
+
+	n := func(v int) *ollamaOptions { return &ollamaOptions{NumPredict: &v} }
+	maxTokens := func(o *ollamaOptions) int {
+		t.Helper()
+		req := srv.ollamaParamRequest("test-model", o)
+		if req.MaxTokens == nil {
+			return 0
+		}
+		return *req.MaxTokens
+	}
+
+	if got := maxTokens(n(-1)); got != 1024 {
+		t.Errorf("num_predict -1 = %d, want the model limit 1024", got)
+	}
+	if got := maxTokens(n(-2)); got != 1024 {
+		t.Errorf("num_predict -2 without a known context = %d, want the model limit 1024", got)
+	}
+	if err := db.UpsertModelMetadata(domain.ModelMetadata{Model: "test-model", ContextLength: 512}); err != nil {
+		t.Fatal(err)
+	}
+	if got := maxTokens(n(-2)); got != 512 {
+		t.Errorf("num_predict -2 = %d, want the 512-token context window", got)
+	}
+	if got := maxTokens(n(64)); got != 64 {
+		t.Errorf("num_predict 64 = %d, want it passed through", got)
+	}
+	if got := maxTokens(nil); got != 0 {
+		t.Errorf("no options = %d, want the server default", got)
+	}
+}
+
+func TestAPI_AgentRuns(t *testing.T) {
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+
+	orch := agent.New(agent.DefaultConfig(), nil, nil)
+	orch.RegisterTool(agent.Tool{Name: "echo", Cost: 1, Run: func(_ context.Context, args json.RawMessage) (json.RawMessage, int64, error) {
+		return args, 0, nil
+	}})
+	done := make(chan struct{})
+	srv.SetAgents(&AgentOps{Orchestrator: orch, Submit: func(_ context.Context, id string) error {
+		go func() {
+			orch.Run(context.Background(), id)
+			close(done)
+		}()
+		return nil
+	}})
+	h := srv.Handler()
+
+	tests := []struct {
+		name string
+		body string
+		want int
+	}{
+		{"unknown tool", `{"steps":[{"id":"a","tool":"nope"}]}`, http.StatusBadRequest},
+		{"empty plan", `{"steps":[]}`, http.StatusBadRequest},
+		{"bad body", `{`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest("POST", "/api/agent/runs", strings.NewReader(tt.body)))
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d, body: %s", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("POST", "/api/agent/runs",
+		strings.NewReader(`{"name":"demo","budget_credits":5,"steps":[{"id":"a","tool":"echo","args":{"x":1}}]}`)))
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("create status = %d, body: %s", w.Code, w.Body.String())
+	}
+	var created domain.AgentRun
+	json.NewDecoder(w.Body).Decode(&created)
+	<-done
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/api/agent/runs/"+created.ID, nil))
+	var got domain.AgentRun
+	json.NewDecoder(w.Body).Decode(&got)
+	if w.Code != http.StatusOK || got.Status != domain.AgentRunCompleted || got.Spent != 1 {
+		t.Errorf("get = %d %s spent=%d, want 200 COMPLETED 1", w.Code, got.Status, got.Spent)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("POST", "/api/agent/runs/"+created.ID+"/cancel", nil))
+	if w.Code != http.StatusConflict {
+		t.Errorf("cancel finished run = %d, want 409", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/api/agent/runs/missing", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("get missing = %d, want 404", w.Code)
+	}
+}
+
+func TestAPI_EmbedBatch_StreamAndQuery(t *testing.T) {
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+
+	vs, _ := embedding.NewVectorStore(nil)
+	// Vector = [len(text), 1]
+	p := embedding.NewPipeline(embedding.DefaultConfig(), func(_ context.Context, _ string, inputs []string) ([][]float32, error) {
+		out := make([][]float32, len(inputs))
+		for i, in := range inputs {
+			out[i] = []float32{float32(len(in)), 1}
+		}
+		return out, nil
+	}, vs)
+	srv.SetEmbedBatch(p)
+	h := srv.Handler()
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("POST", "/api/embeddings/batch", strings.NewReader(`{"model":"m"}`)))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("empty batch status = %d, want 400", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("POST", "/api/embeddings/batch",
+		strings.NewReader(`{"model":"m","collection":"docs","items":[{"id":"s","text":"a"},{"id":"l","text":"aaaaaaaa"},{"id":"s2","text":"a"}]}`)))
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("submit status = %d, body: %s", w.Code, w.Body.String())
+	}
+	var job domain.EmbedJob
+	json.NewDecoder(w.Body).Decode(&job)
+	if job.Total != 3 || job.Unique != 2 {
+		t.Errorf("job = %+v, want 3 items / 2 unique", job)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/api/embeddings/batch/"+job.ID+"/results", nil))
+	if ct := w.Header().Get("Content-Type"); ct != "application/x-ndjson" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("results = %d lines, want 3: %s", len(lines), w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("POST", "/api/vectors/docs/query", strings.NewReader(`{"text":"aaaaaaa","k":1}`)))
+	var q struct {
+		Matches []domain.VectorMatch `json:"matches"`
+	}
+	json.NewDecoder(w.Body).Decode(&q)
+	if w.Code != http.StatusOK || len(q.Matches) != 1 || q.Matches[0].ID != "l" {
+		t.Errorf("query = %d %+v, want top match l", w.Code, q.Matches)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("POST", "/api/vectors/missing/query", strings.NewReader(`{"vector":[1,0]}`)))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("missing collection status = %d, want 404", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("DELETE", "/api/vectors/docs", nil))
+	if w.Code != http.StatusOK || len(vs.Collections()) != 0 {
+		t.Errorf("delete status = %d, collections = %+v", w.Code, vs.Collections())
+	}
+}
+
+func TestAPI_VerifyInference(t *testing.T) {
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+
+	outputs := map[string]string{"a": "4", "b": "4", "c": "5"}
+	srv.SetRedundancy(redundancy.New(redundancy.DefaultConfig(), redundancy.Hooks{
+		Nodes: func(n int) []string { return []string{"a", "b", "c"} },
+		Execute: func(_ context.Context, node string, _ redundancy.Request) (string, error) {
+			return outputs[node], nil
+		},
+	}))
+	h := srv.Handler()
+
+	tests := []struct {
+		name          string
+		body          string
+		want          int
+		wantConsensus bool
+	}{
+		{"two of three agree", `{"model":"m","prompt":"2+2"}`, http.StatusOK, true},
+		{"unanimity required", `{"model":"m","prompt":"2+2","replicas":3,"quorum":3}`, http.StatusOK, false},
+		{"quorum not a majority", `{"model":"m","replicas":3,"quorum":1}`, http.StatusBadRequest, false},
+		{"too many replicas", `{"model":"m","replicas":5,"quorum":3}`, http.StatusServiceUnavailable, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest("POST", "/api/inference/verify", strings.NewReader(tt.body)))
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d, body: %s", w.Code, tt.want, w.Body.String())
+			}
+			if w.Code != http.StatusOK {
+				return
+			}
+			var v domain.RedundancyVerdict
+			json.NewDecoder(w.Body).Decode(&v)
+			if v.Consensus != tt.wantConsensus || len(v.Replicas) != 3 {
+				t.Errorf("verdict = %+v", v)
+			}
+		})
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/api/inference/verify", nil))
+	var list struct {
+		Verdicts []domain.RedundancyVerdict `json:"verdicts"`
+	}
+	json.NewDecoder(w.Body).Decode(&list)
+	if len(list.Verdicts) != 2 {
+		t.Errorf("verdicts = %d, want 2", len(list.Verdicts))
+	}
+}
+
+func TestAPI_WorkStealing(t *testing.T) {
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+
+	sched := scheduler.NewScheduler(scheduler.DefaultConfig())
+	for _, id := range []string{"t1", "t2", "t3", "t4"} {
+		sched.Enqueue(domain.Task{ID: id, Priority: scheduler.P2Normal}, domain.TaskRouting{})
+	}
+	st := scheduler.NewStealer(scheduler.StealConfig{SelfID: "victim"}, sched)
+	srv.SetStealer(st)
+	h := srv.Handler()
+
+	// Unauthenticated callers cannot take tasks off the queue
+	for _, path := range []string{"/api/scheduler/steal/grant", "/api/scheduler/steal/confirm"} {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("POST", path, strings.NewReader(`{"thief":"peer","max":2}`)))
+		if w.Code == http.StatusOK {
+			t.Errorf("POST %s status = %d, want not served", path, w.Code)
+		}
+	}
+	if sched.QueueDepth() != 4 {
+		t.Fatalf("queue depth = %d, want 4", sched.QueueDepth())
+	}
+
+	grant, err := st.Grant(scheduler.StealRequest{Thief: "peer", Max: 2})
+	if err != nil {
+		t.Fatalf("Grant() error: %v", err)
+	}
+	if err := st.Confirm(scheduler.StealConfirm{GrantID: grant.ID, Thief: "peer",
+		Accepted: []string{grant.Tasks[0].Task.ID, grant.Tasks[1].Task.ID}}); err != nil {
+		t.Fatalf("Confirm() error: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/api/scheduler/steal", nil))
+	var stats scheduler.StealStats
+	json.NewDecoder(w.Body).Decode(&stats)
+	if stats.Granted != 2 || stats.HandedOff != 2 || stats.PendingGrants != 0 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestAPI_BackPressureAdmission(t *testing.T) {
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+
+	sig := scheduler.Signals{}
+	cfg := scheduler.DefaultAdmissionConfig()
+	cfg.QueueDepth = [3]int{10, 50, 100}
+	a := scheduler.NewAdmission(cfg, func() scheduler.Signals { return sig })
+	srv.SetAdmission(a)
+	h := srv.Handler()
+
+	tests := []struct {
+		name       string
+		depth      int
+		want       int
+		retryAfter string
+	}{
+		{"medium defers interactive requests", 60, http.StatusTooManyRequests, "4"},
+		{"hard sheds them", 150, http.StatusServiceUnavailable, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sig.QueueDepth = tt.depth
+			a.Evaluate()
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest("POST", "/api/generate", strings.NewReader(`{"model":"m","prompt":"hi"}`)))
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d, body: %s", w.Code, tt.want, w.Body.String())
+			}
+			if got := w.Header().Get("Retry-After"); got != tt.retryAfter {
+				t.Errorf("Retry-After = %q, want %q", got, tt.retryAfter)
+			}
+		})
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/api/scheduler/backpressure", nil))
+	var body struct {
+		Admission   scheduler.AdmissionStats `json:"admission"`
+		Transitions []scheduler.Transition   `json:"transitions"`
+	}
+	json.NewDecoder(w.Body).Decode(&body)
+	if body.Admission.LevelName != "HARD" || len(body.Transitions) != 2 || body.Admission.Shed != 1 {
+		t.Errorf("backpressure = %+v", body)
+	}
+}
+
+func TestAPI_SchedulerTrends(t *testing.T) {
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+
+	var gotSince time.Time
+	var gotBucket time.Duration
+	srv.SetSchedulerTrends(func(since time.Time, bucket time.Duration) ([]sqlite.SchedulerTrendPoint, error) {
+		gotSince, gotBucket = since, bucket
+		return []sqlite.SchedulerTrendPoint{{Snapshots: 3, Completed: 120, CompletionRate: 2}}, nil
+	})
+	h := srv.Handler()
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/api/scheduler/trends?window=6h&bucket=15m", nil))
+	var body schedulerTrends
+	json.NewDecoder(w.Body).Decode(&body)
+	if w.Code != http.StatusOK || len(body.Points) != 1 || body.Points[0].Completed != 120 {
+		t.Fatalf("trends = %d %+v", w.Code, body)
+	}
+	if gotBucket != 15*time.Minute || time.Since(gotSince) < 6*time.Hour || time.Since(gotSince) > 7*time.Hour {
+		t.Errorf("queried since %v, bucket %v; want 6h ago, 15m", gotSince, gotBucket)
+	}
+
+	for _, q := range []string{"?bucket=0s", "?window=soon", "?window=720h&bucket=1m"} {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", "/api/scheduler/trends"+q, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("GET %s: status = %d, want 400", q, w.Code)
+		}
+	}
+}
+
+func TestAPI_MLScheduler(t *testing.T) {
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+
+	cfg := mlscheduler.DefaultConfig()
+	cfg.PriorWeight = 0
+	ml := mlscheduler.NewScheduler(cfg)
+	fast := mlscheduler.Features{NodeID: "n1", TaskType: "INFERENCE", NodeLoad: 0.1, GPUAvailable: true}
+	_, arm := ml.SelectNode([]mlscheduler.Features{fast})
+	ml.RecordOutcome(arm, "n1", 40, 5)
+	ml.RecordOutcome(arm, "n1", 60, 5)
+	srv.SetMLScheduler(ml)
+	h := srv.Handler()
+
+	get := func(path string, v any) {
+		t.Helper()
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("GET %s: status = %d, body: %s", path, w.Code, w.Body.String())
+		}
+		json.NewDecoder(w.Body).Decode(v)
+	}
+
+	var arms mlArmList
+	get("/api/scheduler/ml/arms", &arms)
+	if len(arms.Arms) != 1 || arms.Arms[0].Key != arm || arms.Arms[0].Pulls != 2 {
+		t.Errorf("arms = %+v", arms)
+	}
+
+	var obs mlObservationList
+	get("/api/scheduler/ml/observations?limit=1", &obs)
+	if len(obs.Observations) != 1 || obs.Observations[0].LatencyMs != 60 || obs.Observations[0].ArmKey != arm {
+		t.Errorf("observations = %+v, want the newest one", obs)
+	}
+
+	var stats mlStats
+	get("/api/scheduler/ml/stats", &stats)
+	if stats.Stats.TotalObservations != 2 || stats.Stats.MLAvgLatencyMs != 50 {
+		t.Errorf("stats = %+v", stats.Stats)
+	}
+}
+
+func TestAPI_RegionRouting(t *testing.T) {
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+
+	router := region.NewRouter(region.DefaultConfig()) // local us-east
+	router.Sync([]domain.RegionStatus{
+		{Region: domain.RegionUSEast, Healthy: false},
+		{Region: domain.RegionEUWest, Healthy: true, NodeCount: 4},
+		{Region: domain.RegionAPSouth, Healthy: true, NodeCount: 4},
+	})
+	router.SetAllowedRegions(func(fedID string) ([]domain.RegionID, error) {
+		if fedID != "fed-1" {
+			return nil, domain.ErrFederationNotFound
+		}
+		return []domain.RegionID{domain.RegionAPSouth}, nil
+	})
+	srv.SetRegionRouter(router)
+	h := srv.Handler()
+
+	tests := []struct {
+		name string
+		body string
+		want int
+		resp string
+	}{
+		{"fails over to nearest neighbour", `{"nodes":[{"node_id":"n1","region":"ap-south"},{"node_id":"n2","region":"eu-west"}]}`,
+			http.StatusOK, `"target_region":"eu-west","source_region":"us-east","latency_penalty_ms":85,"reason":"failover","nodes":[{"node_id":"n2"`},
+		{"federation limits regions", `{"federation_id":"fed-1"}`, http.StatusOK, `"target_region":"ap-south"`},
+		{"residency outside federation", `{"federation_id":"fed-1","routing":{"data_residency":"eu-west"}}`, http.StatusConflict, "outside"},
+		{"unknown federation", `{"federation_id":"nope"}`, http.StatusNotFound, "not found"},
+		{"bad body", `{`, http.StatusBadRequest, "invalid"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest("POST", "/api/regions/route", strings.NewReader(tt.body)))
+			if w.Code != tt.want || !strings.Contains(w.Body.String(), tt.resp) {
+				t.Errorf("status = %d body = %s, want %d containing %s", w.Code, w.Body.String(), tt.want, tt.resp)
+			}
+		})
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/api/regions?limit=2", nil))
+	var body struct {
+		Router    region.Stats      `json:"router"`
+		Decisions []region.Decision `json:"decisions"`
+	}
+	json.NewDecoder(w.Body).Decode(&body)
+	if body.Router.Decisions["failover"] != 2 || body.Router.Decisions["rejected"] != 1 || len(body.Decisions) != 2 {
+		t.Errorf("regions = %+v", body)
+	}
+
+	heat := region.NewHeatmap(region.HeatmapConfig{})
+	for range 5 {
+		heat.Observe(domain.RegionUSEast, domain.RegionEUWest, 40*time.Millisecond)
+	}
+	router.SetHeatmap(heat)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/api/regions/latency", nil))
+	var matrix region.LatencyMatrix
+	json.NewDecoder(w.Body).Decode(&matrix)
+	if c, _ := matrix.Cell(domain.RegionEUWest, domain.RegionUSEast); c.Estimated || c.P50Ms != 40 || len(matrix.Cells) != 9 {
+		t.Errorf("latency matrix = %+v", matrix)
+	}
+}
+
+func TestAPI_MarketplaceLineage(t *testing.T) {
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+
+	store := marketplace.NewStore(marketplace.DefaultStoreConfig())
+	store.Publish(marketplace.Listing{ID: "base-ft", ModelName: "Coder", BaseModel: "llama3", Creator: "a", Price: 1,
+		FineTuneJobID: "job-1", DatasetDigest: "sha256:aaaa", Digest: "d1"})
+	store.Publish(marketplace.Listing{ID: "derived", ModelName: "Coder Py", Creator: "b", Price: 1, ParentID: "base-ft"})
+	srv.SetMarketplace(store)
+	h := srv.Handler()
+
+	tests := []struct {
+		path   string
+		status int
+		want   string
+	}{
+		{"/api/marketplace/listings/derived/lineage", http.StatusOK, `"ancestors":[{"id":"base-ft"`},
+		{"/api/marketplace/listings/derived/lineage", http.StatusOK, `"gaps":["finetune_job_id","dataset_digest","digest"]`},
+		{"/api/marketplace/listings/derived/lineage/graph", http.StatusOK, `{"from":"listing:derived","to":"listing:base-ft","kind":"derived_from"}`},
+		{"/api/marketplace/listings/derived/lineage/graph?format=dot", http.StatusOK, `"listing:base-ft" -> "base_model:llama3"`},
+		{"/api/marketplace/listings/derived/lineage/graph?format=svg", http.StatusBadRequest, "format"},
+		{"/api/marketplace/listings/nope/lineage", http.StatusNotFound, "not found"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", tt.path, nil))
+		if w.Code != tt.status || !strings.Contains(w.Body.String(), tt.want) {
+			t.Errorf("GET %s = %d %s, want %d containing %s", tt.path, w.Code, w.Body.String(), tt.status, tt.want)
+		}
+	}
+}
+
+func TestAPI_BillingWebhook(t *testing.T) {
+	mgr, db := newTestMgr(t)
+	defer db.Close()
+	srv := NewServer(engine.NewPool(engine.NewMockBackend(), 1024*1024*1024, mgr.Resolve), mgr)
+	srv.SetAuth(func(token string) bool { return token == "node-key" })
+	tenants, _ := tenant.NewRegistry(tenant.Config{}, nil)
+	tenants.Create(domain.Namespace{Name: "search"})
+	searchKey, _, _ := tenants.IssueKey("search")
+	srv.SetTenants(tenants)
+	srv.SetBilling(credit.NewBilling(credit.NewService(db), credit.BillingConfig{
+		Secrets: func() []string { return []string{"whsec"} },
+	}))
+	h := srv.Handler()
+
+	deliver := func(secret, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/api/billing/webhook", strings.NewReader(body))
+		req.Header.Set(credit.SignatureHeader, credit.SignWebhook(secret, time.Now(), []byte(body)))
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+	get := func(path, key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("Authorization", "Bearer "+key)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+
+	// The webhook needs no API key, only a valid signature
+	paid := `{"id":"evt_1","provider":"stripe","type":"payment.succeeded","namespace":"search","amount_minor":5000,"currency":"usd","credits":500}`
+	if w := deliver("wrong", paid); w.Code != http.StatusForbidden {
+		t.Errorf("bad signature: status = %d, want 403", w.Code)
+	}
+	if w := deliver("whsec", paid); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"id":"inv-evt_1"`) {
+		t.Fatalf("webhook = %d %s", w.Code, w.Body.String())
+	}
+	if w := deliver("whsec", paid); !strings.Contains(w.Body.String(), `"duplicate":true`) {
+		t.Errorf("redelivery = %s, want duplicate", w.Body.String())
+	}
+	deliver("whsec", `{"id":"evt_2","type":"payment.succeeded","amount_minor":100,"currency":"USD","credits":10}`)
+
+	tests := []struct {
+		path, key string
+		status    int
+		want      string
+	}{
+		{"/api/billing/invoices", "node-key", http.StatusOK, `"id":"inv-evt_2"`},
+		{"/api/billing/invoices?namespace=search", "node-key", http.StatusOK, `[{"id":"inv-evt_1"`},
+		{"/api/billing/invoices", searchKey, http.StatusOK, `[{"id":"inv-evt_1"`},
+		{"/api/billing/invoices/inv-evt_1", searchKey, http.StatusOK, `"currency":"USD"`},
+		{"/api/billing/invoices/inv-evt_2", searchKey, http.StatusNotFound, "invoice not found"},
+		{"/api/billing/invoices/inv-nope", "node-key", http.StatusNotFound, "invoice not found"},
+	}
+	for _, tt := range tests {
+		if w := get(tt.path, tt.key); w.Code != tt.status || !strings.Contains(w.Body.String(), tt.want) {
+			t.Errorf("GET %s = %d %s, want %d containing %s", tt.path, w.Code, w.Body.String(), tt.status, tt.want)
+		}
+	}
+	if w := get("/api/billing/invoices", searchKey); strings.Contains(w.Body.String(), "inv-evt_2") {
+		t.Errorf("namespace key sees other namespaces' invoices: %s", w.Body.String())
+	}
+}
+
+func TestAPI_Onboarding(t *testing.T) {
+	srv, cleanup := newTestServer(t)
+	defer cleanup()
+
+	hw := passive.Hardware{CPUCores: 8, RAMBytes: 16 << 30}
+	var applied resource.IdlePolicy
+	wiz, _ := onboarding.New(onboarding.Config{}, onboarding.Hooks{
+		Detect: func() passive.Hardware { return hw },
+		Benchmark: func(context.Context) (passive.Profile, error) {
+			return passive.Profile{Hardware: hw, Tier: passive.TierBasic, TierName: "basic"}, nil
+		},
+		ApplyEarnings:  func(p resource.IdlePolicy) error { applied = p; return nil },
+		InstallModels:  func([]string) error { return nil },
+		JoinFederation: func(string) (string, error) { return "", domain.ErrInviteInvalid },
+	})
+	srv.SetOnboarding(wiz)
+	srv.SetFederationInviter(func(time.Duration) (federation.Invite, error) {
+		return federation.Invite{}, domain.ErrNotFederated
+	})
+	log, _ := audit.NewLog(nil)
+	srv.SetAuditLog(log)
+	h := srv.Handler()
+
+	tests := []struct {
+		method, path, body string
+		status             int
+		want               string
+	}{
+		{"POST", "/api/onboarding/models", `{"pack":"lite"}`, http.StatusConflict, "not reached"},
+		{"POST", "/api/onboarding/hardware", "", http.StatusOK, `"step":"benchmark"`},
+		{"POST", "/api/onboarding/benchmark", "", http.StatusOK, `"recommended_pack":"lite"`},
+		{"GET", "/api/onboarding/packs", "", http.StatusOK, `"recommended":"lite"`},
+		{"POST", "/api/onboarding/earnings", "", http.StatusOK, `"step":"models"`},
+		{"POST", "/api/onboarding/models", `{"pack":"lite"}`, http.StatusOK, `"models":["smollm2","tinyllama","qwen2.5"]`},
+		{"POST", "/api/onboarding/federation", `{"invite_code":"NOPE"}`, http.StatusBadRequest, "invalid or expired federation invite"},
+		{"POST", "/api/onboarding/federation", `{"skip":true}`, http.StatusOK, `"step":"done"`},
+		{"GET", "/api/onboarding", "", http.StatusOK, `"federation_skipped":true`},
+		{"POST", "/api/admin/federation/invites", `{"ttl":"bogus"}`, http.StatusBadRequest, "ttl"},
+		{"POST", "/api/admin/federation/invites", "", http.StatusNotFound, "not a member"},
+		{"POST", "/api/onboarding/reset", "", http.StatusOK, `"step":"hardware"`},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+		req.RemoteAddr = "127.0.0.1:40000"
+		h.ServeHTTP(w, req)
+		if w.Code != tt.status || !strings.Contains(w.Body.String(), tt.want) {
+			t.Errorf("%s %s = %d %s, want %d containing %s", tt.method, tt.path, w.Code, w.Body.String(), tt.status, tt.want)
+		}
+	}
+
+	// Without an API key, only this machine may mint invites
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("POST", "/api/admin/federation/invites", nil))
+	if w.Code != http.StatusForbidden {
+		t.Errorf("remote invite without a key = %d, want 403", w.Code)
+	}
+	if !applied.Enabled || !applied.RequireACPower {
+		t.Errorf("applied policy = %+v, want the basic-tier suggestion", applied)
+	}
+}
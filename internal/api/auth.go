@@ -0,0 +1,102 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/tutu-network/tutu/internal/domain"
+)
+
+// ─── API Authentication ─────────────────────────────────────────────────────
+// Bearer-token auth is opt-in: when no verifier is configured (the default
+// for local single-user installs) every request passes through. Once an
+// operator sets an API key (`tutu secrets set api_key`), all /v1, /api and
+// /mcp routes require `Authorization: Bearer <key>`. Namespace keys (see
+// namespace.go) are accepted alongside it. Namespaces can only be created
+// with a node key set; if it is removed later, requests without a namespace
+// key are refused rather than let through outside every namespace's limits.
+
+// TokenVerifier reports whether a bearer token is valid.
+type TokenVerifier func(token string) bool
+
+// SetAuth enables bearer-token authentication. Passing nil disables it.
+func (s *Server) SetAuth(v TokenVerifier) { s.auth = v }
+
+// authPublicPaths are reachable without credentials so load balancers and
+// install scripts keep working when auth is enabled. The billing webhook
+// authenticates each delivery by its HMAC signature instead.
+var authPublicPaths = map[string]bool{
+	"/health":              true,
+	"/api/status":          true,
+	"/api/version":         true,
+	"/api/billing/webhook": true,
+}
+
+// authMiddleware rejects unauthenticated requests to protected routes and
+// assigns each request its namespace: the one a namespace key belongs to,
+// or the default namespace for the node's own key.
+func (s *Server) authMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !requiresAuth(r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		namespace := domain.DefaultNamespace
+		token := bearerToken(r)
+		if name, ok := s.resolveNamespace(token); ok {
+			if nodeKeyOnly(r.URL.Path) {
+				writeError(w, http.StatusForbidden, "namespace keys cannot use the admin API")
+				return
+			}
+			namespace = name
+		} else if s.auth != nil && (token == "" || !s.auth(token)) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="tutu"`)
+			writeError(w, http.StatusUnauthorized, "invalid or missing API key")
+			return
+		} else if s.auth == nil && s.tenants != nil && s.tenants.Len() > 0 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="tutu"`)
+			writeError(w, http.StatusUnauthorized, "namespaces exist but the node has no API key: use a namespace key or set one with tutu secrets set api_key")
+			return
+		}
+		r, ok := s.admitNamespace(w, r, namespace)
+		if !ok {
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// nodeKeyOnly reports whether a path manages the node itself, so only the
+// node's own key may call it: the admin API and first-run onboarding.
+func nodeKeyOnly(path string) bool {
+	return strings.HasPrefix(path, "/api/admin/") ||
+		path == "/api/onboarding" || strings.HasPrefix(path, "/api/onboarding/")
+}
+
+// resolveNamespace returns the namespace a namespace API key belongs to.
+func (s *Server) resolveNamespace(token string) (string, bool) {
+	if s.tenants == nil {
+		return "", false
+	}
+	return s.tenants.Resolve(token)
+}
+
+// requiresAuth reports whether a path is protected by API-key auth.
+func requiresAuth(path string) bool {
+	if authPublicPaths[path] {
+		return false
+	}
+	return path == "/mcp" ||
+		strings.HasPrefix(path, "/v1/") ||
+		strings.HasPrefix(path, "/api/")
+}
+
+// bearerToken extracts the token from an `Authorization: Bearer` header.
+func bearerToken(r *http.Request) string {
+	h := r.Header.Get("Authorization")
+	const prefix = "Bearer "
+	if len(h) <= len(prefix) || !strings.EqualFold(h[:len(prefix)], prefix) {
+		return ""
+	}
+	return strings.TrimSpace(h[len(prefix):])
+}
@@ -0,0 +1,60 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/tutu-network/tutu/internal/domain"
+)
+
+// ─── Model Benchmark API ────────────────────────────────────────────────────
+// GET  /api/benchmarks                — stored per-model benchmarks
+//                                       (tokens/sec, time to first token)
+// POST /api/admin/benchmarks/{model}  — benchmark a model now (audited)
+
+// BenchmarkOps bundles the model benchmark operations.
+type BenchmarkOps struct {
+	List func() ([]domain.ModelBenchmark, error)
+	Run  func(ctx context.Context, model string) (domain.ModelBenchmark, error)
+}
+
+// benchmarkList is the GET /api/benchmarks response.
+type benchmarkList struct {
+	Benchmarks []domain.ModelBenchmark `json:"benchmarks"`
+}
+
+// SetModelBenchmarks enables the model benchmark endpoints.
+func (s *Server) SetModelBenchmarks(b *BenchmarkOps) { s.benchmarks = b }
+
+// mountBenchmarkAdmin registers the admin benchmark route.
+func (s *Server) mountBenchmarkAdmin(r chi.Router) {
+	r.Post("/benchmarks/{model}", s.handleRunBenchmark)
+}
+
+func (s *Server) handleListBenchmarks(w http.ResponseWriter, r *http.Request) {
+	list, err := s.benchmarks.List()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if list == nil {
+		list = []domain.ModelBenchmark{}
+	}
+	writeJSON(w, http.StatusOK, benchmarkList{Benchmarks: list})
+}
+
+func (s *Server) handleRunBenchmark(w http.ResponseWriter, r *http.Request) {
+	info, err := s.models.Show(chi.URLParam(r, "model"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	b, err := s.benchmarks.Run(r.Context(), info.Name)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, b)
+}
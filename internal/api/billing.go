@@ -0,0 +1,87 @@
+package api
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/tutu-network/tutu/internal/app/credit"
+	"github.com/tutu-network/tutu/internal/domain"
+	"github.com/tutu-network/tutu/internal/infra/tenant"
+)
+
+// ─── Billing API ────────────────────────────────────────────────────────────
+// External billing providers post payment events to the webhook, which is
+// authenticated by its HMAC signature instead of the API key. Invoices are
+// scoped to the caller's namespace; the node's own key sees every namespace
+// and may filter with ?namespace=.
+//
+// POST /api/billing/webhook          — ingest a signed payment event
+// GET  /api/billing/invoices         — invoices, newest first (?limit=)
+// GET  /api/billing/invoices/{id}    — one invoice
+
+// maxWebhookBody caps the size of a webhook delivery.
+const maxWebhookBody = 1 << 20
+
+// SetBilling enables payment webhooks and the invoice store.
+func (s *Server) SetBilling(b *credit.Billing) { s.billing = b }
+
+// mountBilling registers the billing routes.
+func (s *Server) mountBilling(r chi.Router) {
+	r.Route("/api/billing", func(r chi.Router) {
+		r.Post("/webhook", s.handleBillingWebhook)
+		r.Get("/invoices", s.handleInvoiceList)
+		r.Get("/invoices/{id}", s.handleInvoiceGet)
+	})
+}
+
+func (s *Server) handleBillingWebhook(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookBody))
+	if err != nil {
+		writeError(w, http.StatusRequestEntityTooLarge, "webhook body too large")
+		return
+	}
+	inv, duplicate, err := s.billing.Ingest(body, r.Header.Get(credit.SignatureHeader))
+	if err != nil {
+		writeDomainError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"invoice":   inv,
+		"duplicate": duplicate,
+	})
+}
+
+func (s *Server) handleInvoiceList(w http.ResponseWriter, r *http.Request) {
+	ns := tenant.FromContext(r.Context())
+	if ns == domain.DefaultNamespace {
+		ns = r.URL.Query().Get("namespace")
+	}
+	invoices, err := s.billing.Invoices(ns, queryLimit(r, 100))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if invoices == nil {
+		invoices = []domain.Invoice{}
+	}
+	writeJSON(w, http.StatusOK, invoices)
+}
+
+func (s *Server) handleInvoiceGet(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	inv, err := s.billing.Invoice(id)
+	if err == nil {
+		// Other namespaces' invoices do not exist as far as the caller knows
+		if ns := tenant.FromContext(r.Context()); ns != domain.DefaultNamespace && ns != inv.Namespace {
+			err = fmt.Errorf("%w: %s", domain.ErrInvoiceNotFound, id)
+		}
+	}
+	if err != nil {
+		writeDomainError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, inv)
+}
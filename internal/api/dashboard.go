@@ -0,0 +1,202 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/app/credit"
+	"github.com/tutu-network/tutu/internal/app/executor"
+	"github.com/tutu-network/tutu/internal/domain"
+	"github.com/tutu-network/tutu/internal/infra/autoscale"
+	"github.com/tutu-network/tutu/internal/infra/selfheal"
+)
+
+// ─── Dashboard API ──────────────────────────────────────────────────────────
+// GET /api/dashboard — everything the desktop home screen shows in one
+//                      response: node status, today's earnings, active
+//                      tasks, streak and level, model cache, open incidents
+//                      and scale state
+//
+// The response carries an ETag over its body. A request whose If-None-Match
+// matches gets 304 with no body, so the UI can poll cheaply.
+
+// dashboardDecisions is how many recent scaling decisions are included.
+const dashboardDecisions = 3
+
+// DashboardOps bundles the components behind the dashboard. Sections whose
+// component is nil are omitted.
+type DashboardOps struct {
+	NodeID     string
+	Region     string
+	StartedAt  time.Time
+	Executor   *executor.Executor
+	Credit     *credit.Service
+	AutoScaler *autoscale.Scaler
+	SelfHeal   *selfheal.Mesh
+	Now        func() time.Time // clock for "today" (default: time.Now)
+}
+
+// SetDashboard enables the dashboard endpoint.
+func (s *Server) SetDashboard(d *DashboardOps) { s.dashboard = d }
+
+// DashboardView is the aggregated home screen state.
+type DashboardView struct {
+	Node       DashboardNode        `json:"node"`
+	Earnings   *DashboardEarnings   `json:"earnings,omitempty"`
+	Tasks      *executor.Stats      `json:"tasks,omitempty"`
+	Engagement *DashboardEngagement `json:"engagement,omitempty"`
+	Cache      DashboardCache       `json:"cache"`
+	Incidents  []IncidentView       `json:"incidents,omitempty"`
+	Scale      *AutoscaleView       `json:"scale,omitempty"`
+}
+
+// DashboardNode identifies the node and its load state.
+type DashboardNode struct {
+	ID           string    `json:"id,omitempty"`
+	Region       string    `json:"region,omitempty"`
+	StartedAt    time.Time `json:"started_at"`
+	BackPressure string    `json:"back_pressure"`
+}
+
+// DashboardEarnings is the node's credit position.
+type DashboardEarnings struct {
+	Today   int64 `json:"today"`
+	Balance int64 `json:"balance"`
+}
+
+// DashboardEngagement is the streak and level summary.
+type DashboardEngagement struct {
+	StreakDays  int     `json:"streak_days"`
+	Multiplier  float64 `json:"multiplier"`
+	Level       int     `json:"level"`
+	XP          int64   `json:"xp"`
+	ProgressPct float64 `json:"progress_pct"`
+}
+
+// DashboardCache is the state of loaded models and model storage.
+type DashboardCache struct {
+	Loaded       []domain.LoadedModel `json:"loaded"`
+	MemoryUsed   uint64               `json:"memory_used_bytes"`
+	MemoryMax    uint64               `json:"memory_max_bytes"`
+	StorageUsed  int64                `json:"storage_used_bytes"`
+	StorageFree  int64                `json:"storage_free_bytes"`
+	StorageLimit int64                `json:"storage_budget_bytes"` // 0 = unlimited
+	PinnedBytes  int64                `json:"pinned_bytes"`
+}
+
+func (s *Server) handleDashboard(w http.ResponseWriter, r *http.Request) {
+	view, err := s.dashboardView()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	body, err := json.Marshal(view)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "private, no-cache")
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
+
+// dashboardView gathers every section. Sections are built so that an
+// unchanged node produces byte-identical output, which keeps the ETag stable.
+func (s *Server) dashboardView() (DashboardView, error) {
+	d := s.dashboard
+	view := DashboardView{
+		Node: DashboardNode{ID: d.NodeID, Region: d.Region, StartedAt: d.StartedAt, BackPressure: "NONE"},
+	}
+	if s.admission != nil {
+		view.Node.BackPressure = s.admission.Level().String()
+	}
+
+	if d.Credit != nil {
+		now := time.Now()
+		if d.Now != nil {
+			now = d.Now()
+		}
+		midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		today, err := d.Credit.EarnedSince(midnight)
+		if err != nil {
+			return view, err
+		}
+		balance, err := d.Credit.Balance()
+		if err != nil {
+			return view, err
+		}
+		view.Earnings = &DashboardEarnings{Today: today, Balance: balance}
+	}
+	if d.Executor != nil {
+		st := d.Executor.Stats()
+		view.Tasks = &st
+	}
+	if e := s.engagement; e != nil && e.Streak != nil && e.Level != nil {
+		streak, err := e.Streak.CurrentStreak()
+		if err != nil {
+			return view, err
+		}
+		lvl, err := e.Level.CurrentLevel()
+		if err != nil {
+			return view, err
+		}
+		pct, _ := e.Level.ProgressPct()
+		view.Engagement = &DashboardEngagement{
+			StreakDays:  streak.CurrentDays,
+			Multiplier:  e.Streak.CreditMultiplier(),
+			Level:       lvl.Level,
+			XP:          lvl.CurrentXP,
+			ProgressPct: pct,
+		}
+	}
+
+	// Pool and incident order is map order; sort so the ETag does not flap
+	loaded := s.pool.LoadedModels()
+	sort.Slice(loaded, func(i, j int) bool { return loaded[i].Name < loaded[j].Name })
+	view.Cache.Loaded = loaded
+	view.Cache.MemoryUsed, view.Cache.MemoryMax = s.pool.MemoryUsage()
+	usage, err := s.models.Usage()
+	if err != nil {
+		return view, err
+	}
+	view.Cache.StorageUsed = usage.UsedBytes
+	view.Cache.StorageFree = usage.FreeBytes
+	view.Cache.StorageLimit = usage.BudgetBytes
+	view.Cache.PinnedBytes = usage.PinnedBytes
+
+	if d.SelfHeal != nil {
+		view.Incidents = incidentViews(d.SelfHeal.ActiveIncidents())
+		sort.Slice(view.Incidents, func(i, j int) bool { return view.Incidents[i].ID < view.Incidents[j].ID })
+	}
+	if d.AutoScaler != nil {
+		scale := autoscaleView(d.AutoScaler, dashboardDecisions)
+		view.Scale = &scale
+	}
+	return view, nil
+}
+
+// etagMatches reports whether an If-None-Match header lists etag. Weak
+// validators match their strong form, as RFC 9110 requires for GET.
+func etagMatches(header, etag string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == etag || tag == "*" {
+			return true
+		}
+	}
+	return false
+}
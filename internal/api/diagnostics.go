@@ -0,0 +1,61 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ─── Diagnostics API ────────────────────────────────────────────────────────
+// GET /api/admin/diagnostics — download a support bundle (tar.gz) holding
+// recent logs, spans, subsystem stats, database integrity, gossip membership
+// and redacted config. Calls are audited. With an API key configured the
+// route requires it like every admin route; without one only loopback
+// clients may fetch the bundle. The response never carries CORS headers, so
+// a web page open in the user's browser cannot read it.
+
+// SetDiagnostics enables the diagnostics endpoint. fn writes the bundle.
+func (s *Server) SetDiagnostics(fn func(io.Writer) error) { s.diagnostics = fn }
+
+func (s *Server) handleDiagnostics(w http.ResponseWriter, r *http.Request) {
+	for _, h := range []string{"Access-Control-Allow-Origin", "Access-Control-Allow-Methods", "Access-Control-Allow-Headers"} {
+		w.Header().Del(h)
+	}
+	if s.auth == nil && !loopbackRequest(r) {
+		writeError(w, http.StatusForbidden, "diagnostics from another host need an API key (tutu secrets set api_key)")
+		return
+	}
+	// Buffer the bundle so a collection failure can still be a clean 500.
+	var buf bytes.Buffer
+	if err := s.diagnostics(&buf); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	name := fmt.Sprintf("tutu-diagnostics-%s.tar.gz", time.Now().UTC().Format("20060102-150405"))
+	w.Header().Set("Content-Type", "application/gzip")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+name+`"`)
+	w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+	w.WriteHeader(http.StatusOK)
+	_, _ = buf.WriteTo(w)
+}
+
+// loopbackRequest reports whether r came straight from this machine. A
+// request carrying forwarding headers came through a proxy, whatever
+// address it arrived from.
+func loopbackRequest(r *http.Request) bool {
+	for _, h := range []string{"X-Forwarded-For", "X-Real-IP", "True-Client-IP", "Forwarded"} {
+		if r.Header.Get(h) != "" {
+			return false
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,171 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/tutu-network/tutu/internal/domain"
+	"github.com/tutu-network/tutu/internal/infra/embedding"
+)
+
+// ─── Embedding Batch + Vector API ───────────────────────────────────────────
+// Batch embedding jobs and the local vector store:
+//
+// POST   /api/embeddings/batch              — submit a corpus (202)
+// GET    /api/embeddings/batch              — retained jobs, newest first
+// GET    /api/embeddings/batch/{id}         — job progress
+// GET    /api/embeddings/batch/{id}/results — per-item results as NDJSON,
+//                                             streamed as shards finish
+// POST   /api/embeddings/batch/{id}/cancel  — stop a job
+// GET    /api/vectors                       — stored collections
+// POST   /api/vectors/{collection}/query    — cosine search by text or vector
+// DELETE /api/vectors/{collection}          — drop a collection
+
+// SetEmbedBatch enables the embedding batch and vector endpoints.
+func (s *Server) SetEmbedBatch(p *embedding.Pipeline) { s.embedBatch = p }
+
+// mountEmbedBatch registers the batch and vector routes.
+func (s *Server) mountEmbedBatch(r chi.Router) {
+	r.Route("/api/embeddings/batch", func(r chi.Router) {
+		r.Post("/", s.handleEmbedBatchSubmit)
+		r.Get("/", s.handleEmbedBatchList)
+		r.Get("/{id}", s.handleEmbedBatchGet)
+		r.Get("/{id}/results", s.handleEmbedBatchResults)
+		r.Post("/{id}/cancel", s.handleEmbedBatchCancel)
+	})
+	if s.embedBatch.Vectors() != nil {
+		r.Route("/api/vectors", func(r chi.Router) {
+			r.Get("/", s.handleVectorCollections)
+			r.Post("/{collection}/query", s.handleVectorQuery)
+			r.Delete("/{collection}", s.handleVectorDelete)
+		})
+	}
+}
+
+// EmbedBatchRequest is the body of POST /api/embeddings/batch. Give either
+// inputs (IDs are their indexes) or items with caller-chosen IDs.
+type EmbedBatchRequest struct {
+	Model      string             `json:"model"`
+	Collection string             `json:"collection,omitempty"` // store vectors here
+	Inputs     []string           `json:"inputs,omitempty"`
+	Items      []domain.EmbedItem `json:"items,omitempty"`
+}
+
+// VectorQueryRequest is the body of POST /api/vectors/{collection}/query.
+// With text, the query is embedded with the collection's model.
+type VectorQueryRequest struct {
+	Text   string    `json:"text,omitempty"`
+	Vector []float32 `json:"vector,omitempty"`
+	K      int       `json:"k,omitempty"` // default 10
+}
+
+func (s *Server) handleEmbedBatchSubmit(w http.ResponseWriter, r *http.Request) {
+	var req EmbedBatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	items := req.Items
+	for _, in := range req.Inputs {
+		items = append(items, domain.EmbedItem{Text: in})
+	}
+	job, err := s.embedBatch.Submit(req.Model, req.Collection, items)
+	if err != nil {
+		writeDomainError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, job)
+}
+
+func (s *Server) handleEmbedBatchList(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{"jobs": s.embedBatch.List()})
+}
+
+func (s *Server) handleEmbedBatchGet(w http.ResponseWriter, r *http.Request) {
+	job, err := s.embedBatch.Get(chi.URLParam(r, "id"))
+	if err != nil {
+		writeDomainError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, job)
+}
+
+func (s *Server) handleEmbedBatchResults(w http.ResponseWriter, r *http.Request) {
+	results, err := s.embedBatch.Stream(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		writeDomainError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+
+	enc := json.NewEncoder(w)
+	for res := range results {
+		enc.Encode(res)
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+}
+
+func (s *Server) handleEmbedBatchCancel(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	if err := s.embedBatch.Cancel(id); err != nil {
+		writeDomainError(w, err)
+		return
+	}
+	job, _ := s.embedBatch.Get(id)
+	writeJSON(w, http.StatusOK, job)
+}
+
+func (s *Server) handleVectorCollections(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{"collections": s.embedBatch.Vectors().Collections()})
+}
+
+func (s *Server) handleVectorQuery(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "collection")
+	var req VectorQueryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if (req.Text == "") == (len(req.Vector) == 0) {
+		writeError(w, http.StatusBadRequest, "exactly one of text or vector is required")
+		return
+	}
+
+	vs := s.embedBatch.Vectors()
+	coll, err := vs.Collection(name)
+	if err != nil {
+		writeDomainError(w, err)
+		return
+	}
+	vec := req.Vector
+	if req.Text != "" {
+		vec, err = s.embedBatch.Embed(r.Context(), coll.Model, req.Text)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+	matches, err := vs.Query(name, vec, req.K)
+	if err != nil {
+		writeDomainError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"collection": name, "model": coll.Model, "matches": matches})
+}
+
+func (s *Server) handleVectorDelete(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "collection")
+	n, err := s.embedBatch.Vectors().Delete(name)
+	if err != nil {
+		writeDomainError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"collection": name, "deleted": n})
+}
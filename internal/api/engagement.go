@@ -2,12 +2,16 @@ package api
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/tutu-network/tutu/internal/app/engagement"
 	"github.com/tutu-network/tutu/internal/domain"
+	"github.com/tutu-network/tutu/internal/infra/sqlite"
 )
 
 // ─── Engagement API ─────────────────────────────────────────────────────────
@@ -21,6 +25,7 @@ import (
 // GET /api/engagement/notifications — pending notifications
 // POST /api/engagement/notifications/{id}/shown — mark notification shown
 // GET /api/engagement/summary      — full engagement dashboard snapshot
+// GET /api/earnings/reports        — daily earnings reports, newest first
 
 // EngagementAPI holds references to all engagement services.
 type EngagementAPI struct {
@@ -29,6 +34,7 @@ type EngagementAPI struct {
 	Achievement  *engagement.AchievementService
 	Quest        *engagement.QuestService
 	Notification *engagement.NotificationService
+	Reports      *engagement.ReportService
 }
 
 // HandleStreak returns the current streak data.
@@ -223,6 +229,28 @@ func (e *EngagementAPI) HandleNotifications(w http.ResponseWriter, r *http.Reque
 	})
 }
 
+// HandleEarningsReports returns the saved daily earnings reports.
+// GET /api/earnings/reports?limit=
+func (e *EngagementAPI) HandleEarningsReports(w http.ResponseWriter, r *http.Request) {
+	if e.Reports == nil {
+		writeError(w, http.StatusServiceUnavailable, "earnings reports not initialized")
+		return
+	}
+
+	reports, err := e.Reports.Reports(queryLimit(r, 30))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if reports == nil {
+		reports = []sqlite.EarningsReportRecord{}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"reports": reports,
+	})
+}
+
 // HandleNotificationShown marks a notification as shown.
 // POST /api/engagement/notifications/{id}/shown
 func (e *EngagementAPI) HandleNotificationShown(w http.ResponseWriter, r *http.Request) {
@@ -343,16 +371,67 @@ func splitPath(path string) []string {
 // ─── Live Earnings WebSocket ────────────────────────────────────────────────
 // Architecture Part XIII #5: Live earnings feed ("The Mining Screen").
 // Delivered via WebSocket: {type: "credit_earned", amount: 2.4, task_type: "inference"}
+//
+// Every event carries an SSE id, and the hub keeps the last ReplaySize
+// events. A client that reconnects with Last-Event-ID (EventSource sends it
+// automatically) first receives the events it missed, as far back as the
+// buffer reaches. IDs are "<epoch>-<seq>", where epoch identifies this hub:
+// after a daemon restart a client's old ID is unknown, and it receives the
+// whole buffer. A comment line every Heartbeat keeps idle connections alive
+// through proxies.
+
+// EarningsHubConfig configures the earnings feed.
+type EarningsHubConfig struct {
+	ReplaySize int           // events kept for reconnecting clients (default 256)
+	Heartbeat  time.Duration // idle keep-alive comment interval (default 15s)
+}
+
+// DefaultEarningsHubConfig returns earnings feed defaults.
+func DefaultEarningsHubConfig() EarningsHubConfig {
+	return EarningsHubConfig{ReplaySize: 256, Heartbeat: 15 * time.Second}
+}
+
+// earningsRetryMs is the reconnect delay suggested to EventSource clients.
+const earningsRetryMs = 3000
+
+// earningsEntry is one buffered event, already framed for SSE.
+type earningsEntry struct {
+	seq   uint64
+	frame []byte
+}
 
 // EarningsHub manages WebSocket connections for live earnings feed.
 type EarningsHub struct {
-	clients map[chan []byte]struct{}
+	cfg   EarningsHubConfig
+	epoch string
+
+	mu      sync.Mutex
+	clients map[chan []byte]bool // → receives SSE frames rather than bare JSON
+	seq     uint64
+	replay  []earningsEntry // ring of the last cfg.ReplaySize events
+	next    int             // ring write position
 }
 
 // NewEarningsHub creates a new earnings broadcast hub.
 func NewEarningsHub() *EarningsHub {
+	return NewEarningsHubWithConfig(DefaultEarningsHubConfig())
+}
+
+// NewEarningsHubWithConfig creates an earnings hub. Zero config fields take
+// their defaults.
+func NewEarningsHubWithConfig(cfg EarningsHubConfig) *EarningsHub {
+	def := DefaultEarningsHubConfig()
+	if cfg.ReplaySize <= 0 {
+		cfg.ReplaySize = def.ReplaySize
+	}
+	if cfg.Heartbeat <= 0 {
+		cfg.Heartbeat = def.Heartbeat
+	}
 	return &EarningsHub{
-		clients: make(map[chan []byte]struct{}),
+		cfg:     cfg,
+		epoch:   strconv.FormatInt(time.Now().UnixNano(), 36),
+		clients: make(map[chan []byte]bool),
+		replay:  make([]earningsEntry, 0, cfg.ReplaySize),
 	}
 }
 
@@ -362,9 +441,25 @@ func (h *EarningsHub) Broadcast(event EarningsEvent) {
 	if err != nil {
 		return
 	}
-	for ch := range h.clients {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.seq++
+	frame := []byte(fmt.Sprintf("id: %s-%d\ndata: %s\n\n", h.epoch, h.seq, data))
+	entry := earningsEntry{seq: h.seq, frame: frame}
+	if len(h.replay) < h.cfg.ReplaySize {
+		h.replay = append(h.replay, entry)
+	} else {
+		h.replay[h.next] = entry
+	}
+	h.next = (h.next + 1) % h.cfg.ReplaySize
+
+	for ch, framed := range h.clients {
+		msg := data
+		if framed {
+			msg = frame
+		}
 		select {
-		case ch <- data:
+		case ch <- msg:
 		default:
 			// Client too slow — drop message
 		}
@@ -373,9 +468,36 @@ func (h *EarningsHub) Broadcast(event EarningsEvent) {
 
 // Subscribe registers a new client. Returns the channel and an unsubscribe func.
 func (h *EarningsHub) Subscribe() (chan []byte, func()) {
+	ch, _, unsub := h.subscribe(false, "")
+	return ch, unsub
+}
+
+// subscribe registers a client and returns the buffered SSE frames after
+// lastEventID, oldest first. Both happen under one lock, so no event is
+// missed or delivered twice.
+func (h *EarningsHub) subscribe(framed bool, lastEventID string) (chan []byte, [][]byte, func()) {
 	ch := make(chan []byte, 32)
-	h.clients[ch] = struct{}{}
-	return ch, func() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.clients[ch] = framed
+
+	var missed [][]byte
+	if lastEventID != "" {
+		after := uint64(0) // unknown epoch: replay everything
+		if epoch, seq, ok := strings.Cut(lastEventID, "-"); ok && epoch == h.epoch {
+			after, _ = strconv.ParseUint(seq, 10, 64)
+		}
+		n := len(h.replay)
+		for i := 0; i < n; i++ {
+			e := h.replay[(h.next+i)%n]
+			if e.seq > after {
+				missed = append(missed, e.frame)
+			}
+		}
+	}
+	return ch, missed, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
 		delete(h.clients, ch)
 		close(ch)
 	}
@@ -383,6 +505,8 @@ func (h *EarningsHub) Subscribe() (chan []byte, func()) {
 
 // ClientCount returns the number of connected clients.
 func (h *EarningsHub) ClientCount() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
 	return len(h.clients)
 }
 
@@ -398,6 +522,8 @@ type EarningsEvent struct {
 // HandleEarningsSSE serves the live earnings feed via Server-Sent Events.
 // GET /api/earnings/live
 // Uses SSE instead of WebSocket for simplicity and HTTP/2 compatibility.
+// Reconnecting clients send Last-Event-ID (or ?last_event_id= where the
+// header can't be set) to receive the events they missed.
 func (h *EarningsHub) HandleEarningsSSE(w http.ResponseWriter, r *http.Request) {
 	flusher, ok := w.(http.Flusher)
 	if !ok {
@@ -409,19 +535,31 @@ func (h *EarningsHub) HandleEarningsSSE(w http.ResponseWriter, r *http.Request)
 	w.Header().Set("Cache-Control", "no-cache")
 	w.Header().Set("Connection", "keep-alive")
 	w.Header().Set("Access-Control-Allow-Origin", "*")
-	flusher.Flush()
 
-	ch, unsub := h.Subscribe()
+	lastID := r.Header.Get("Last-Event-ID")
+	if lastID == "" {
+		lastID = r.URL.Query().Get("last_event_id")
+	}
+	ch, missed, unsub := h.subscribe(true, lastID)
 	defer unsub()
 
+	fmt.Fprintf(w, "retry: %d\n\n", earningsRetryMs)
+	for _, frame := range missed {
+		w.Write(frame)
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(h.cfg.Heartbeat)
+	defer heartbeat.Stop()
 	for {
 		select {
 		case <-r.Context().Done():
 			return
-		case data := <-ch:
-			w.Write([]byte("data: "))
-			w.Write(data)
-			w.Write([]byte("\n\n"))
+		case frame := <-ch:
+			w.Write(frame)
+			flusher.Flush()
+		case <-heartbeat.C:
+			w.Write([]byte(": heartbeat\n\n"))
 			flusher.Flush()
 		}
 	}
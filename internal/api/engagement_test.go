@@ -1,10 +1,12 @@
 package api
 
 import (
+	"bufio"
 	"encoding/json"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -28,6 +30,7 @@ func setupEngagementAPI(t *testing.T) (*EngagementAPI, *sqlite.DB) {
 		Achievement:  engagement.NewAchievementService(db),
 		Quest:        engagement.NewQuestService(db),
 		Notification: engagement.NewNotificationService(db),
+		Reports:      engagement.NewReportService(db, nil, 0, nil),
 	}, db
 }
 
@@ -341,6 +344,110 @@ func TestEarningsHub_SSE_Endpoint(t *testing.T) {
 	}
 }
 
+// readSSE reads SSE lines until one satisfies stop, returning all lines.
+func readSSE(t *testing.T, r *bufio.Reader, stop func(string) bool) []string {
+	t.Helper()
+	var lines []string
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("read: %v (after %q)", err, lines)
+		}
+		line = strings.TrimRight(line, "\n")
+		lines = append(lines, line)
+		if stop(line) {
+			return lines
+		}
+	}
+}
+
+func TestEarningsHub_SSE_ReplayAfterReconnect(t *testing.T) {
+	tests := []struct {
+		name   string
+		lastID string
+		want   []string // replayed ids
+	}{
+		{"missed one", "e-2", []string{"e-3"}},
+		{"older than the buffer", "e-0", []string{"e-2", "e-3"}},
+		{"unknown epoch", "restarted-9", []string{"e-2", "e-3"}},
+		{"up to date", "e-3", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hub := NewEarningsHubWithConfig(EarningsHubConfig{ReplaySize: 2})
+			hub.epoch = "e"
+			for _, amount := range []float64{1, 2, 3} {
+				hub.Broadcast(EarningsEvent{Type: "credit_earned", Amount: amount})
+			}
+			server := httptest.NewServer(http.HandlerFunc(hub.HandleEarningsSSE))
+			defer server.Close()
+
+			req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+			req.Header.Set("Last-Event-ID", tt.lastID)
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("connect: %v", err)
+			}
+			defer resp.Body.Close()
+
+			// A live event marks the end of the replay.
+			go hub.Broadcast(EarningsEvent{Type: "marker"})
+			var ids []string
+			for _, line := range readSSE(t, bufio.NewReader(resp.Body), func(l string) bool { return strings.Contains(l, `"marker"`) }) {
+				if id, ok := strings.CutPrefix(line, "id: "); ok {
+					ids = append(ids, id)
+				}
+			}
+			ids = ids[:len(ids)-1] // the marker's
+			if strings.Join(ids, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("replayed %v, want %v", ids, tt.want)
+			}
+		})
+	}
+}
+
+func TestEarningsHub_SSE_Heartbeat(t *testing.T) {
+	hub := NewEarningsHubWithConfig(EarningsHubConfig{Heartbeat: 10 * time.Millisecond})
+	server := httptest.NewServer(http.HandlerFunc(hub.HandleEarningsSSE))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer resp.Body.Close()
+	readSSE(t, bufio.NewReader(resp.Body), func(l string) bool { return l == ": heartbeat" })
+}
+
+func TestEngagementAPI_EarningsReports(t *testing.T) {
+	api, db := setupEngagementAPI(t)
+
+	day := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		start := day.AddDate(0, 0, i)
+		if _, err := db.InsertEarningsReport(start, start.AddDate(0, 0, 1), int64(100*(i+1)), i, 24, 2, ""); err != nil {
+			t.Fatalf("insert report: %v", err)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/earnings/reports?limit=2", nil)
+	w := httptest.NewRecorder()
+	api.HandleEarningsReports(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	var resp struct {
+		Reports []sqlite.EarningsReportRecord `json:"reports"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(resp.Reports) != 2 || resp.Reports[0].CreditsEarned != 300 {
+		t.Errorf("expected the 2 newest reports, got %+v", resp.Reports)
+	}
+}
+
 // ─── Path Extraction Tests ──────────────────────────────────────────────────
 
 func TestExtractPathParam(t *testing.T) {
@@ -0,0 +1,40 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/tutu-network/tutu/internal/infra/passive"
+)
+
+// ─── Hardware Profile API ───────────────────────────────────────────────────
+// GET  /api/hardware                  — detected hardware, benchmark result
+//                                       and tier (503 until first benchmark)
+// POST /api/admin/hardware/benchmark  — re-run the benchmark now (audited)
+
+// SetHardwareProfiler enables the hardware profile endpoints.
+func (s *Server) SetHardwareProfiler(p *passive.Profiler) { s.hardware = p }
+
+// mountHardwareAdmin registers the admin benchmark route.
+func (s *Server) mountHardwareAdmin(r chi.Router) {
+	r.Post("/hardware/benchmark", s.handleHardwareBenchmark)
+}
+
+func (s *Server) handleHardware(w http.ResponseWriter, r *http.Request) {
+	p, ok := s.hardware.Profile()
+	if !ok {
+		writeError(w, http.StatusServiceUnavailable, "hardware not benchmarked yet")
+		return
+	}
+	writeJSON(w, http.StatusOK, p)
+}
+
+func (s *Server) handleHardwareBenchmark(w http.ResponseWriter, r *http.Request) {
+	p, err := s.hardware.Rebenchmark(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, p)
+}
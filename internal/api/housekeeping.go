@@ -0,0 +1,64 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/tutu-network/tutu/internal/infra/housekeeping"
+)
+
+// ─── Housekeeping API ───────────────────────────────────────────────────────
+// Periodic maintenance jobs, mounted under the audited admin API:
+//
+// GET  /api/admin/housekeeping                — every job's schedule and last run
+// POST /api/admin/housekeeping/{job}/run      — run a job now
+// POST /api/admin/housekeeping/{job}/enable   — resume a job's schedule
+// POST /api/admin/housekeeping/{job}/disable  — stop running a job on schedule
+
+// SetHousekeeping enables the housekeeping endpoints.
+func (s *Server) SetHousekeeping(c *housekeeping.Coordinator) { s.housekeeping = c }
+
+// mountHousekeeping registers the /housekeeping routes inside the admin
+// router.
+func (s *Server) mountHousekeeping(r chi.Router) {
+	r.Route("/housekeeping", func(r chi.Router) {
+		r.Get("/", s.handleHousekeepingStatus)
+		r.Post("/{job}/run", s.handleHousekeepingRun)
+		r.Post("/{job}/enable", s.handleHousekeepingToggle(true))
+		r.Post("/{job}/disable", s.handleHousekeepingToggle(false))
+	})
+}
+
+func (s *Server) handleHousekeepingStatus(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{"jobs": s.housekeeping.Status()})
+}
+
+func (s *Server) handleHousekeepingRun(w http.ResponseWriter, r *http.Request) {
+	st, err := s.housekeeping.RunNow(r.Context(), chi.URLParam(r, "job"))
+	if err != nil {
+		writeHousekeepingError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, st)
+}
+
+func (s *Server) handleHousekeepingToggle(enabled bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		st, err := s.housekeeping.SetEnabled(chi.URLParam(r, "job"), enabled)
+		if err != nil {
+			writeHousekeepingError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, st)
+	}
+}
+
+func writeHousekeepingError(w http.ResponseWriter, err error) {
+	if errors.Is(err, housekeeping.ErrUnknownJob) {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	writeError(w, http.StatusInternalServerError, err.Error())
+}
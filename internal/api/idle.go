@@ -0,0 +1,50 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/tutu-network/tutu/internal/domain"
+	"github.com/tutu-network/tutu/internal/infra/resource"
+)
+
+// ─── Idle Compute Policy API ────────────────────────────────────────────────
+// When this node may work for the network, mounted under the audited admin
+// API:
+//
+// GET /api/admin/idle-policy — policy, latest machine sample and decision
+// PUT /api/admin/idle-policy — change the policy; fields left out keep
+//                              their value. Applied immediately.
+
+// SetIdlePolicy enables the idle compute policy endpoints.
+func (s *Server) SetIdlePolicy(e *resource.PolicyEngine) { s.idlePolicy = e }
+
+// mountIdlePolicy registers the /idle-policy routes inside the admin router.
+func (s *Server) mountIdlePolicy(r chi.Router) {
+	r.Get("/idle-policy", s.handleIdlePolicyGet)
+	r.Put("/idle-policy", s.handleIdlePolicySet)
+}
+
+func (s *Server) handleIdlePolicyGet(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.idlePolicy.Status())
+}
+
+func (s *Server) handleIdlePolicySet(w http.ResponseWriter, r *http.Request) {
+	policy := s.idlePolicy.Policy()
+	if err := json.NewDecoder(r.Body).Decode(&policy); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if _, err := s.idlePolicy.SetPolicy(policy); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, domain.ErrInvalidIdlePolicy) {
+			status = http.StatusBadRequest
+		}
+		writeError(w, status, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, s.idlePolicy.Status())
+}
@@ -0,0 +1,148 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/tutu-network/tutu/internal/domain"
+	"github.com/tutu-network/tutu/internal/infra/journal"
+	"github.com/tutu-network/tutu/internal/infra/sqlite"
+)
+
+// ─── Task Journal API ───────────────────────────────────────────────────────
+// Event-sourced task history, mounted under the audited admin API:
+//
+// GET /api/admin/tasks/journal      — replay events in order (?after=<seq>&limit=)
+// GET /api/admin/tasks/journal/stats — event counts and per-stage latency
+// GET /api/admin/tasks/in-flight    — tasks accepted but not yet finished
+// GET /api/admin/tasks/{id}/events  — one task's events and latency breakdown
+//
+// With task results enabled:
+//
+// POST /api/admin/tasks/{id}/result  — completed task with its attestation;
+//                                      verified, then the executor is paid
+// POST /api/admin/tasks/{id}/dispute — {reason} dispute a paid result
+
+// TaskResultOps settles attested results of tasks this node requested.
+type TaskResultOps struct {
+	Accept  func(task domain.Task) error
+	Dispute func(taskID, reason string) (*sqlite.AttestationRecord, error)
+}
+
+// disputeRequest is the POST /api/admin/tasks/{id}/dispute body.
+type disputeRequest struct {
+	Reason string `json:"reason"`
+}
+
+// SetTaskJournal enables the task journal endpoints.
+func (s *Server) SetTaskJournal(j *journal.Journal) { s.journal = j }
+
+// SetTaskResults enables the task result endpoints (requires the journal).
+func (s *Server) SetTaskResults(o *TaskResultOps) { s.taskResults = o }
+
+// mountJournal registers the /tasks routes inside the admin router.
+func (s *Server) mountJournal(r chi.Router) {
+	r.Route("/tasks", func(r chi.Router) {
+		r.Get("/journal", s.handleJournalReplay)
+		r.Get("/journal/stats", s.handleJournalStats)
+		r.Get("/in-flight", s.handleJournalInFlight)
+		r.Get("/{id}/events", s.handleTaskEvents)
+		if s.taskResults != nil {
+			r.Post("/{id}/result", s.handleTaskResult)
+			r.Post("/{id}/dispute", s.handleTaskDispute)
+		}
+	})
+}
+
+func (s *Server) handleJournalReplay(w http.ResponseWriter, r *http.Request) {
+	var after int64
+	if v := r.URL.Query().Get("after"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n < 0 {
+			writeError(w, http.StatusBadRequest, "invalid after sequence")
+			return
+		}
+		after = n
+	}
+	events, err := s.journal.Replay(after, queryLimit(r, 100))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if events == nil {
+		events = []domain.TaskEvent{}
+	}
+	next := after
+	if len(events) > 0 {
+		next = events[len(events)-1].Seq
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"events": events,
+		"next":   next,
+	})
+}
+
+func (s *Server) handleJournalStats(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.journal.Stats())
+}
+
+func (s *Server) handleJournalInFlight(w http.ResponseWriter, r *http.Request) {
+	tasks := s.journal.InFlight()
+	if tasks == nil {
+		tasks = []journal.TaskState{}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"tasks": tasks})
+}
+
+func (s *Server) handleTaskEvents(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	events, err := s.journal.History(id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if len(events) == 0 {
+		writeError(w, http.StatusNotFound, "no events for task "+id)
+		return
+	}
+	breakdown, err := s.journal.Breakdown(id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"events":    events,
+		"breakdown": breakdown,
+	})
+}
+
+func (s *Server) handleTaskResult(w http.ResponseWriter, r *http.Request) {
+	var task domain.Task
+	if err := json.NewDecoder(r.Body).Decode(&task); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid task result: "+err.Error())
+		return
+	}
+	task.ID = chi.URLParam(r, "id")
+	if err := s.taskResults.Accept(task); err != nil {
+		writeDomainError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"task_id": task.ID, "paid": task.Credits})
+}
+
+func (s *Server) handleTaskDispute(w http.ResponseWriter, r *http.Request) {
+	var req disputeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Reason == "" {
+		writeError(w, http.StatusBadRequest, "a dispute needs a reason")
+		return
+	}
+	rec, err := s.taskResults.Dispute(chi.URLParam(r, "id"), req.Reason)
+	if err != nil {
+		writeDomainError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, rec)
+}
@@ -0,0 +1,55 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/tutu-network/tutu/internal/infra/marketplace"
+)
+
+// ─── Marketplace Lineage API ────────────────────────────────────────────────
+// GET /api/marketplace/listings/{id}/lineage        — ancestors, descendants
+//                                                     and provenance gaps
+// GET /api/marketplace/listings/{id}/lineage/graph  — the listing's whole
+//                                                     family as nodes and
+//                                                     edges (?format=dot for
+//                                                     Graphviz)
+
+// SetMarketplace enables the marketplace lineage endpoints.
+func (s *Server) SetMarketplace(m *marketplace.Store) { s.marketplace = m }
+
+// mountMarketplace registers the marketplace routes.
+func (s *Server) mountMarketplace(r chi.Router) {
+	r.Route("/api/marketplace/listings/{id}/lineage", func(r chi.Router) {
+		r.Get("/", s.handleLineage)
+		r.Get("/graph", s.handleLineageGraph)
+	})
+}
+
+func (s *Server) handleLineage(w http.ResponseWriter, r *http.Request) {
+	lin, err := s.marketplace.Lineage(chi.URLParam(r, "id"))
+	if errors.Is(err, marketplace.ErrListingNotFound) {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, lin)
+}
+
+func (s *Server) handleLineageGraph(w http.ResponseWriter, r *http.Request) {
+	g, err := s.marketplace.LineageGraph(chi.URLParam(r, "id"))
+	if errors.Is(err, marketplace.ErrListingNotFound) {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	switch r.URL.Query().Get("format") {
+	case "", "json":
+		writeJSON(w, http.StatusOK, g)
+	case "dot":
+		w.Header().Set("Content-Type", "text/vnd.graphviz; charset=utf-8")
+		_, _ = w.Write([]byte(g.DOT()))
+	default:
+		writeError(w, http.StatusBadRequest, "format must be json or dot")
+	}
+}
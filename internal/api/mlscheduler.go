@@ -0,0 +1,91 @@
+package api
+
+import (
+	"math"
+	"net/http"
+	"sort"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/tutu-network/tutu/internal/infra/mlscheduler"
+)
+
+// ─── ML Scheduler Inspection ────────────────────────────────────────────────
+// GET /api/scheduler/ml/arms         — bandit arms, most pulled first (?limit=)
+// GET /api/scheduler/ml/observations — recent outcomes, newest first (?limit=)
+// GET /api/scheduler/ml/stats        — ML vs heuristic latency, fairness and
+//                                      the shadow-mode comparison
+//
+// Lets the desktop UI show why a node was chosen: the observation names the
+// arm, and the arm's means, prior, UCB score or posterior are what
+// SelectNode weighed.
+
+// mlArm is one arm in the GET /api/scheduler/ml/arms response. An arm with
+// no data yet has an infinite UCB score, which JSON cannot carry; it is
+// reported as unexplored with a score of 0.
+type mlArm struct {
+	mlscheduler.ArmInfo
+	Unexplored bool `json:"unexplored,omitempty"`
+}
+
+// mlArmList is the GET /api/scheduler/ml/arms response.
+type mlArmList struct {
+	Arms []mlArm `json:"arms"`
+}
+
+// mlObservationList is the GET /api/scheduler/ml/observations response.
+type mlObservationList struct {
+	Observations []mlscheduler.Observation `json:"observations"`
+}
+
+// mlStats is the GET /api/scheduler/ml/stats response.
+type mlStats struct {
+	Stats  mlscheduler.Stats        `json:"stats"`
+	Shadow mlscheduler.ShadowReport `json:"shadow"`
+}
+
+// SetMLScheduler enables the ML scheduler inspection endpoints.
+func (s *Server) SetMLScheduler(m *mlscheduler.Scheduler) { s.mlScheduler = m }
+
+// mountMLScheduler registers the /api/scheduler/ml routes.
+func (s *Server) mountMLScheduler(r chi.Router) {
+	r.Route("/api/scheduler/ml", func(r chi.Router) {
+		r.Get("/arms", s.handleMLArms)
+		r.Get("/observations", s.handleMLObservations)
+		r.Get("/stats", s.handleMLStats)
+	})
+}
+
+func (s *Server) handleMLArms(w http.ResponseWriter, r *http.Request) {
+	infos := s.mlScheduler.Arms()
+	sort.Slice(infos, func(i, j int) bool {
+		if infos[i].Pulls != infos[j].Pulls {
+			return infos[i].Pulls > infos[j].Pulls
+		}
+		return infos[i].Key < infos[j].Key
+	})
+	if limit := queryLimit(r, 100); len(infos) > limit {
+		infos = infos[:limit]
+	}
+	arms := make([]mlArm, len(infos))
+	for i, a := range infos {
+		arms[i] = mlArm{ArmInfo: a}
+		if math.IsInf(a.UCBScore, 0) {
+			arms[i].UCBScore = 0
+			arms[i].Unexplored = true
+		}
+	}
+	writeJSON(w, http.StatusOK, mlArmList{Arms: arms})
+}
+
+func (s *Server) handleMLObservations(w http.ResponseWriter, r *http.Request) {
+	obs := s.mlScheduler.Observations(queryLimit(r, 100))
+	if obs == nil {
+		obs = []mlscheduler.Observation{}
+	}
+	writeJSON(w, http.StatusOK, mlObservationList{Observations: obs})
+}
+
+func (s *Server) handleMLStats(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, mlStats{Stats: s.mlScheduler.Stats(), Shadow: s.mlScheduler.ShadowReport()})
+}
@@ -0,0 +1,199 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/tutu-network/tutu/internal/domain"
+	"github.com/tutu-network/tutu/internal/infra/engine"
+	"github.com/tutu-network/tutu/internal/infra/tenant"
+)
+
+// ─── Namespaces API ─────────────────────────────────────────────────────────
+// Teams sharing the node authenticate with their namespace's API key. Their
+// requests are rate limited, restricted to the namespace's models and
+// accounted to it; namespace keys cannot reach the admin API. Requests made
+// with the node's own key belong to the "default" namespace. Namespaces and
+// their keys can only be created once the node has an API key: without one,
+// a request with no key at all would get the default namespace, with none
+// of a namespace's limits.
+//
+// GET    /api/usage                                 — the caller's namespace usage
+// GET    /api/admin/namespaces                      — namespaces with keys and usage
+// POST   /api/admin/namespaces                      — create {name, models, rate_limit_rpm}
+// GET    /api/admin/namespaces/{name}               — one namespace
+// PUT    /api/admin/namespaces/{name}               — replace its models and rate limit
+// DELETE /api/admin/namespaces/{name}               — delete it and revoke its keys
+// POST   /api/admin/namespaces/{name}/keys          — issue a key (shown only once)
+// DELETE /api/admin/namespaces/{name}/keys/{id}     — revoke a key
+
+// SetTenants enables namespaces.
+func (s *Server) SetTenants(r *tenant.Registry) { s.tenants = r }
+
+// mountNamespaces registers the /namespaces routes inside the admin router.
+func (s *Server) mountNamespaces(r chi.Router) {
+	r.Route("/namespaces", func(r chi.Router) {
+		r.Get("/", s.handleNamespaceList)
+		r.Post("/", s.handleNamespaceCreate)
+		r.Get("/{name}", s.handleNamespaceGet)
+		r.Put("/{name}", s.handleNamespaceUpdate)
+		r.Delete("/{name}", s.handleNamespaceDelete)
+		r.Post("/{name}/keys", s.handleNamespaceKeyIssue)
+		r.Delete("/{name}/keys/{id}", s.handleNamespaceKeyRevoke)
+	})
+}
+
+// namespaceRequest is the body of namespace create and update calls.
+type namespaceRequest struct {
+	Name         string   `json:"name"`
+	Models       []string `json:"models"`
+	RateLimitRPM int      `json:"rate_limit_rpm"`
+}
+
+func (s *Server) handleUsage(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.tenants.Usage(tenant.FromContext(r.Context())))
+}
+
+func (s *Server) handleNamespaceList(w http.ResponseWriter, r *http.Request) {
+	list := s.tenants.List()
+	writeJSON(w, http.StatusOK, map[string]any{
+		"namespaces": list,
+		"count":      len(list),
+	})
+}
+
+func (s *Server) handleNamespaceCreate(w http.ResponseWriter, r *http.Request) {
+	if s.auth == nil {
+		writeDomainError(w, domain.ErrNamespaceAuthOff)
+		return
+	}
+	var req namespaceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	ns, err := s.tenants.Create(domain.Namespace{Name: req.Name, Models: req.Models, RateLimitRPM: req.RateLimitRPM})
+	if err != nil {
+		writeDomainError(w, err)
+		return
+	}
+	st, _ := s.tenants.Status(ns.Name)
+	writeJSON(w, http.StatusCreated, st)
+}
+
+func (s *Server) handleNamespaceGet(w http.ResponseWriter, r *http.Request) {
+	st, err := s.tenants.Status(chi.URLParam(r, "name"))
+	if err != nil {
+		writeDomainError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, st)
+}
+
+func (s *Server) handleNamespaceUpdate(w http.ResponseWriter, r *http.Request) {
+	var req namespaceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	name := chi.URLParam(r, "name")
+	if _, err := s.tenants.Update(domain.Namespace{Name: name, Models: req.Models, RateLimitRPM: req.RateLimitRPM}); err != nil {
+		writeDomainError(w, err)
+		return
+	}
+	st, _ := s.tenants.Status(name)
+	writeJSON(w, http.StatusOK, st)
+}
+
+func (s *Server) handleNamespaceDelete(w http.ResponseWriter, r *http.Request) {
+	if err := s.tenants.Delete(chi.URLParam(r, "name")); err != nil {
+		writeDomainError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleNamespaceKeyIssue(w http.ResponseWriter, r *http.Request) {
+	if s.auth == nil {
+		writeDomainError(w, domain.ErrNamespaceAuthOff)
+		return
+	}
+	key, rec, err := s.tenants.IssueKey(chi.URLParam(r, "name"))
+	if err != nil {
+		writeDomainError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, map[string]any{
+		"id":         rec.ID,
+		"namespace":  rec.Namespace,
+		"key":        key,
+		"created_at": rec.CreatedAt,
+	})
+}
+
+func (s *Server) handleNamespaceKeyRevoke(w http.ResponseWriter, r *http.Request) {
+	name, id := chi.URLParam(r, "name"), chi.URLParam(r, "id")
+	st, err := s.tenants.Status(name)
+	if err != nil {
+		writeDomainError(w, err)
+		return
+	}
+	for _, k := range st.Keys {
+		if k.ID == id {
+			if err := s.tenants.RevokeKey(id); err != nil {
+				writeDomainError(w, err)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+	writeDomainError(w, fmt.Errorf("%w: %s", domain.ErrNamespaceKeyNotFound, id))
+}
+
+// ─── Request Accounting ─────────────────────────────────────────────────────
+
+// admitNamespace applies the namespace's rate limit and tags the request
+// with it. It writes a 429 and returns false when the limit is reached.
+func (s *Server) admitNamespace(w http.ResponseWriter, r *http.Request, name string) (*http.Request, bool) {
+	if s.tenants == nil {
+		return r, true
+	}
+	if ok, retry := s.tenants.Allow(name); !ok {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retry.Seconds()))))
+		writeDomainError(w, fmt.Errorf("%w: %s", domain.ErrRateLimited, name))
+		return r, false
+	}
+	return r.WithContext(tenant.WithNamespace(r.Context(), name)), true
+}
+
+// allowModel checks that the caller's namespace may use model and counts
+// the request against it. It writes a 403 and returns false otherwise.
+func (s *Server) allowModel(w http.ResponseWriter, r *http.Request, model string) bool {
+	if s.tenants == nil {
+		return true
+	}
+	if !s.tenants.AllowModel(tenant.FromContext(r.Context()), model) {
+		writeDomainError(w, fmt.Errorf("%w: %s", domain.ErrModelNotAllowed, model))
+		return false
+	}
+	return true
+}
+
+// meterTokens counts the tokens a stream generates against the caller's
+// namespace once it ends.
+func (s *Server) meterTokens(ctx context.Context, model string, tokens *engine.TokenStream) {
+	if s.tenants == nil {
+		return
+	}
+	name := tenant.FromContext(ctx)
+	go func() {
+		s.tenants.RecordTokens(name, model, tokens.Wait().Delivered)
+	}()
+}
@@ -0,0 +1,515 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/tutu-network/tutu/internal/app/executor"
+	"github.com/tutu-network/tutu/internal/domain"
+	"github.com/tutu-network/tutu/internal/infra/autoscale"
+	"github.com/tutu-network/tutu/internal/infra/gossip"
+	"github.com/tutu-network/tutu/internal/infra/governance"
+	"github.com/tutu-network/tutu/internal/infra/intelligence"
+	"github.com/tutu-network/tutu/internal/infra/reputation"
+	"github.com/tutu-network/tutu/internal/infra/scheduler"
+	"github.com/tutu-network/tutu/internal/infra/selfheal"
+)
+
+// ─── Network Operations API ─────────────────────────────────────────────────
+// Operator views of the distributed network, mounted under the audited
+// admin API and consumed by `tutu network`:
+//
+// GET /api/admin/network/peers        — SWIM membership
+// GET /api/admin/network/clusters     — cluster summaries from tier-2 gossip (?region=)
+// GET /api/admin/network/clusters/{region}/{zone}/{cluster}
+//                                     — one cluster's summary
+// GET /api/admin/network/tasks        — local task executor slots
+// GET /api/admin/network/reputation   — top nodes by reputation (?limit=,
+//                                        ?federation= scores from inside it)
+// GET /api/admin/network/reputation/export
+//                                     — every node as JSON Lines or CSV (?format=json|csv
+//                                       &anonymize=true&salt=)
+// GET /api/admin/network/reputation/summary
+//                                     — score histogram, tier counts, component means (?bins=)
+// GET /api/admin/network/placements   — recent placement recommendations (?limit=)
+// POST /api/admin/network/placements/{id}/ack
+//                                     — report a recommendation ACCEPTED, REJECTED,
+//                                       COMPLETED or FAILED
+// GET /api/admin/network/autoscale    — scaler state + recent decisions (?limit=)
+// GET /api/admin/network/incidents    — active + recently resolved incidents (?limit=)
+// GET /api/admin/network/votes        — governance proposals with tallies (?status=
+//                                       &category=&author=&q=&sort=created|expiry
+//                                       &order=asc|desc&limit=&cursor=), paged by
+//                                       next_cursor; SLA_PRICING proposals carry
+//                                       SLA miss rates
+//
+// Components left nil in NetworkOps answer 503.
+
+// NetworkOps bundles the components behind the network operations API.
+type NetworkOps struct {
+	Peers        func() []domain.Peer
+	Hierarchy    *gossip.Hierarchy // nil when gossip is flat
+	Executor     *executor.Executor
+	Reputation   *reputation.Tracker
+	Intelligence *intelligence.Optimizer
+	AutoScaler   *autoscale.Scaler
+	SelfHeal     *selfheal.Mesh
+	Governance   *governance.Engine
+	SLA          func() []scheduler.SLAClassStats // deadline accounting for SLA_PRICING proposals
+}
+
+// SetNetworkOps enables the network operations endpoints.
+func (s *Server) SetNetworkOps(n *NetworkOps) { s.network = n }
+
+// mountNetwork registers the /network routes inside the admin router.
+func (s *Server) mountNetwork(r chi.Router) {
+	r.Route("/network", func(r chi.Router) {
+		r.Get("/peers", s.handleNetworkPeers)
+		r.Get("/clusters", s.handleNetworkClusters)
+		r.Get("/clusters/{region}/{zone}/{cluster}", s.handleNetworkCluster)
+		r.Get("/tasks", s.handleNetworkTasks)
+		r.Get("/reputation", s.handleNetworkReputation)
+		r.Get("/reputation/export", s.handleNetworkReputationExport)
+		r.Get("/reputation/summary", s.handleNetworkReputationSummary)
+		r.Get("/placements", s.handleNetworkPlacements)
+		r.Post("/placements/{id}/ack", s.handleNetworkPlacementAck)
+		r.Get("/autoscale", s.handleNetworkAutoscale)
+		r.Get("/incidents", s.handleNetworkIncidents)
+		r.Get("/votes", s.handleNetworkVotes)
+	})
+}
+
+// ─── Response Views ─────────────────────────────────────────────────────────
+
+// ReputationView is one node in the reputation leaderboard.
+type ReputationView struct {
+	NodeID     string    `json:"node_id"`
+	Overall    float64   `json:"overall"`
+	Tier       string    `json:"tier"`
+	TaskCount  int       `json:"task_count"`
+	DaysActive int       `json:"days_active"`
+	Penalties  float64   `json:"penalties"`
+	LastUpdate time.Time `json:"last_update"`
+}
+
+// PlacementView is a placement recommendation and its outcome.
+type PlacementView struct {
+	ID        string    `json:"id"`
+	Status    string    `json:"status"`
+	Detail    string    `json:"detail,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Type      string    `json:"type"`
+	Model     string    `json:"model"`
+	FromNode  string    `json:"from_node,omitempty"`
+	ToNode    string    `json:"to_node,omitempty"`
+	Score     float64   `json:"score"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
+
+	SLOTargetMs   float64 `json:"slo_target_ms,omitempty"`  // model's latency SLO
+	SLOCompliance float64 `json:"slo_compliance,omitempty"` // expected share of requests meeting it
+}
+
+// AutoscaleView is the predictive scaler's state.
+type AutoscaleView struct {
+	Capacity     int                     `json:"capacity"`
+	Observations int                     `json:"observations"`
+	Confidence   float64                 `json:"confidence"`
+	ProactivePct float64                 `json:"proactive_pct"`
+	Decisions    []AutoscaleDecisionView `json:"decisions"`
+}
+
+// AutoscaleDecisionView is one scaling decision.
+type AutoscaleDecisionView struct {
+	Direction      string    `json:"direction"`
+	Current        int       `json:"current"`
+	Target         int       `json:"target"`
+	ForecastDemand float64   `json:"forecast_demand"`
+	Proactive      bool      `json:"proactive"`
+	Reason         string    `json:"reason"`
+	DecidedAt      time.Time `json:"decided_at"`
+}
+
+// IncidentView is a self-healing incident.
+type IncidentView struct {
+	ID          string    `json:"id"`
+	NodeID      string    `json:"node_id"`
+	FailureType string    `json:"failure_type"`
+	State       string    `json:"state"`
+	Attempts    int       `json:"attempts"`
+	DetectedAt  time.Time `json:"detected_at"`
+	ResolvedAt  time.Time `json:"resolved_at,omitempty"`
+	Error       string    `json:"error,omitempty"`
+}
+
+// ProposalView is a governance proposal with its current tally.
+type ProposalView struct {
+	ID         string                    `json:"id"`
+	Title      string                    `json:"title"`
+	Category   string                    `json:"category"`
+	Status     string                    `json:"status"`
+	ParamKey   string                    `json:"param_key,omitempty"`
+	ParamValue string                    `json:"param_value,omitempty"`
+	ExpiresAt  time.Time                 `json:"expires_at"`
+	Tally      *governance.VoteTally     `json:"tally,omitempty"`
+	SLA        []scheduler.SLAClassStats `json:"sla,omitempty"` // SLA_PRICING only: current miss rates
+}
+
+// ─── Handlers ───────────────────────────────────────────────────────────────
+
+func (s *Server) handleNetworkPeers(w http.ResponseWriter, r *http.Request) {
+	if s.network.Peers == nil {
+		writeError(w, http.StatusServiceUnavailable, "network fabric not running")
+		return
+	}
+	peers := s.network.Peers()
+	if peers == nil {
+		peers = []domain.Peer{}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"peers": peers,
+		"count": len(peers),
+	})
+}
+
+func (s *Server) handleNetworkClusters(w http.ResponseWriter, r *http.Request) {
+	h := s.network.Hierarchy
+	if h == nil {
+		writeError(w, http.StatusServiceUnavailable, "gossip hierarchy not configured")
+		return
+	}
+	region := r.URL.Query().Get("region")
+	clusters := []gossip.ClusterView{}
+	for _, c := range h.Clusters() {
+		if region == "" || c.Region == region {
+			clusters = append(clusters, c)
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"local":           h.LocalCluster(),
+		"representatives": h.Representatives(),
+		"clusters":        clusters,
+	})
+}
+
+func (s *Server) handleNetworkCluster(w http.ResponseWriter, r *http.Request) {
+	h := s.network.Hierarchy
+	if h == nil {
+		writeError(w, http.StatusServiceUnavailable, "gossip hierarchy not configured")
+		return
+	}
+	key := gossip.ClusterKey(chi.URLParam(r, "region"), chi.URLParam(r, "zone"), chi.URLParam(r, "cluster"))
+	for _, c := range h.Clusters() {
+		if c.Key() == key {
+			writeJSON(w, http.StatusOK, c)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "unknown cluster "+key)
+}
+
+func (s *Server) handleNetworkTasks(w http.ResponseWriter, r *http.Request) {
+	if s.network.Executor == nil {
+		writeError(w, http.StatusServiceUnavailable, "task executor not configured")
+		return
+	}
+	writeJSON(w, http.StatusOK, s.network.Executor.Stats())
+}
+
+func (s *Server) handleNetworkReputation(w http.ResponseWriter, r *http.Request) {
+	if s.network.Reputation == nil {
+		writeError(w, http.StatusServiceUnavailable, "reputation tracker not configured")
+		return
+	}
+	fedID := r.URL.Query().Get("federation")
+	var nodes []*reputation.NodeReputation
+	if fedID != "" {
+		nodes = s.network.Reputation.TopNodesWithin(fedID, queryLimit(r, 10))
+	} else {
+		nodes = s.network.Reputation.TopNodes(queryLimit(r, 10))
+	}
+	out := make([]ReputationView, len(nodes))
+	for i, n := range nodes {
+		out[i] = ReputationView{
+			NodeID:     n.NodeID,
+			Overall:    n.Overall(),
+			Tier:       n.TrustTier(),
+			TaskCount:  n.TaskCount,
+			DaysActive: n.DaysActive,
+			Penalties:  n.Penalties,
+			LastUpdate: n.LastUpdate,
+		}
+	}
+	resp := map[string]interface{}{"nodes": out}
+	if fedID != "" {
+		resp["federation"] = fedID
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) handleNetworkReputationExport(w http.ResponseWriter, r *http.Request) {
+	if s.network.Reputation == nil {
+		writeError(w, http.StatusServiceUnavailable, "reputation tracker not configured")
+		return
+	}
+	q := r.URL.Query()
+	opts := reputation.ExportOptions{
+		Format:    reputation.ExportFormat(strings.ToLower(q.Get("format"))),
+		Anonymize: q.Get("anonymize") == "true",
+		Salt:      q.Get("salt"),
+	}
+	contentType, name := "application/x-ndjson", "reputation.jsonl"
+	switch opts.Format {
+	case "", reputation.FormatJSON:
+	case reputation.FormatCSV:
+		contentType, name = "text/csv", "reputation.csv"
+	default:
+		writeError(w, http.StatusBadRequest, "unknown format "+q.Get("format"))
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", `attachment; filename="`+name+`"`)
+	_, _ = s.network.Reputation.ExportAll(w, opts)
+}
+
+func (s *Server) handleNetworkReputationSummary(w http.ResponseWriter, r *http.Request) {
+	if s.network.Reputation == nil {
+		writeError(w, http.StatusServiceUnavailable, "reputation tracker not configured")
+		return
+	}
+	bins, _ := strconv.Atoi(r.URL.Query().Get("bins"))
+	if bins > 100 {
+		bins = 100
+	}
+	writeJSON(w, http.StatusOK, s.network.Reputation.Summary(bins))
+}
+
+func (s *Server) handleNetworkPlacements(w http.ResponseWriter, r *http.Request) {
+	if s.network.Intelligence == nil {
+		writeError(w, http.StatusServiceUnavailable, "intelligence optimizer not configured")
+		return
+	}
+	recs := s.network.Intelligence.RecentRecommendations(queryLimit(r, 20))
+	out := make([]PlacementView, len(recs))
+	for i, rec := range recs {
+		out[i] = placementView(rec)
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"recommendations": out})
+}
+
+// PlacementAckRequest reports what happened to a recommendation.
+type PlacementAckRequest struct {
+	Status string `json:"status"` // ACCEPTED, REJECTED, COMPLETED or FAILED
+	Detail string `json:"detail,omitempty"`
+}
+
+func (s *Server) handleNetworkPlacementAck(w http.ResponseWriter, r *http.Request) {
+	if s.network.Intelligence == nil {
+		writeError(w, http.StatusServiceUnavailable, "intelligence optimizer not configured")
+		return
+	}
+	var req PlacementAckRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	status := intelligence.AckStatus(strings.ToUpper(req.Status))
+	rec, err := s.network.Intelligence.Acknowledge(chi.URLParam(r, "id"), status, req.Detail)
+	if err != nil {
+		writeDomainError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, placementView(rec))
+}
+
+func placementView(rec intelligence.Recommendation) PlacementView {
+	return PlacementView{
+		ID:        rec.ID,
+		Status:    string(rec.Status),
+		Detail:    rec.Detail,
+		UpdatedAt: rec.UpdatedAt,
+		Type:      rec.Type.String(),
+		Model:     rec.ModelName,
+		FromNode:  rec.FromNode,
+		ToNode:    rec.ToNode,
+		Score:     rec.Score,
+		Reason:    rec.Reason,
+		CreatedAt: rec.CreatedAt,
+
+		SLOTargetMs:   rec.SLOTargetMs,
+		SLOCompliance: rec.SLOCompliance,
+	}
+}
+
+func (s *Server) handleNetworkAutoscale(w http.ResponseWriter, r *http.Request) {
+	if s.network.AutoScaler == nil {
+		writeError(w, http.StatusServiceUnavailable, "autoscaler not configured")
+		return
+	}
+	writeJSON(w, http.StatusOK, autoscaleView(s.network.AutoScaler, queryLimit(r, 10)))
+}
+
+// autoscaleView renders the scaler's state with its most recent decisions.
+func autoscaleView(sc *autoscale.Scaler, limit int) AutoscaleView {
+	st := sc.Stats()
+	view := AutoscaleView{
+		Capacity:     st.CurrentCapacity,
+		Observations: st.Observations,
+		Confidence:   st.Confidence,
+		ProactivePct: st.ProactivePct,
+		Decisions:    []AutoscaleDecisionView{},
+	}
+	for _, d := range sc.RecentDecisions(limit) {
+		view.Decisions = append(view.Decisions, AutoscaleDecisionView{
+			Direction:      d.Direction.String(),
+			Current:        d.CurrentCapacity,
+			Target:         d.TargetCapacity,
+			ForecastDemand: d.ForecastDemand,
+			Proactive:      d.Proactive,
+			Reason:         d.Reason,
+			DecidedAt:      d.DecidedAt,
+		})
+	}
+	return view
+}
+
+func (s *Server) handleNetworkIncidents(w http.ResponseWriter, r *http.Request) {
+	if s.network.SelfHeal == nil {
+		writeError(w, http.StatusServiceUnavailable, "self-healing mesh not configured")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"active":   incidentViews(s.network.SelfHeal.ActiveIncidents()),
+		"resolved": incidentViews(s.network.SelfHeal.ResolvedIncidents(queryLimit(r, 10))),
+	})
+}
+
+// incidentViews renders self-healing incidents.
+func incidentViews(incs []*selfheal.Incident) []IncidentView {
+	out := make([]IncidentView, len(incs))
+	for i, inc := range incs {
+		out[i] = IncidentView{
+			ID:          inc.ID,
+			NodeID:      inc.NodeID,
+			FailureType: string(inc.FailureType),
+			State:       inc.State.String(),
+			Attempts:    inc.Attempts,
+			DetectedAt:  inc.DetectedAt,
+			ResolvedAt:  inc.ResolvedAt,
+			Error:       inc.Error,
+		}
+	}
+	return out
+}
+
+func (s *Server) handleNetworkVotes(w http.ResponseWriter, r *http.Request) {
+	if s.network.Governance == nil {
+		writeError(w, http.StatusServiceUnavailable, "governance engine not configured")
+		return
+	}
+	params := r.URL.Query()
+	query := governance.ProposalQuery{
+		Author:    params.Get("author"),
+		Text:      params.Get("q"),
+		Ascending: params.Get("order") == "asc",
+		Limit:     queryLimit(r, governance.DefaultQueryLimit),
+		Cursor:    params.Get("cursor"),
+	}
+	if q := params.Get("status"); q != "" {
+		st, ok := parseProposalStatus(q)
+		if !ok {
+			writeError(w, http.StatusBadRequest, "unknown status "+q)
+			return
+		}
+		query.Status = &st
+	}
+	if q := params.Get("category"); q != "" {
+		cat, ok := parseProposalCategory(q)
+		if !ok {
+			writeError(w, http.StatusBadRequest, "unknown category "+q)
+			return
+		}
+		query.Category = &cat
+	}
+	switch params.Get("sort") {
+	case "", "created":
+	case "expiry":
+		query.Sort = governance.SortByExpiry
+	default:
+		writeError(w, http.StatusBadRequest, "unknown sort "+params.Get("sort"))
+		return
+	}
+	switch params.Get("order") {
+	case "", "asc", "desc":
+	default:
+		writeError(w, http.StatusBadRequest, "unknown order "+params.Get("order"))
+		return
+	}
+
+	page, err := s.network.Governance.QueryProposals(query)
+	if err != nil {
+		writeDomainError(w, err)
+		return
+	}
+	props := page.Proposals
+	var sla []scheduler.SLAClassStats
+	if s.network.SLA != nil {
+		sla = s.network.SLA()
+	}
+	out := make([]ProposalView, len(props))
+	for i, p := range props {
+		out[i] = ProposalView{
+			ID:         p.ID,
+			Title:      p.Title,
+			Category:   p.Category.String(),
+			Status:     p.Status.String(),
+			ParamKey:   p.ParamKey,
+			ParamValue: p.ParamValue,
+			ExpiresAt:  p.ExpiresAt,
+		}
+		if t, err := s.network.Governance.Tally(p.ID); err == nil {
+			out[i].Tally = t
+		}
+		if p.Category == governance.CatSLAPricing {
+			out[i].SLA = sla
+		}
+	}
+	resp := map[string]interface{}{"proposals": out}
+	if page.NextCursor != "" {
+		resp["next_cursor"] = page.NextCursor
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// parseProposalStatus accepts status names case-insensitively ("active", "PASSED").
+func parseProposalStatus(s string) (governance.ProposalStatus, bool) {
+	for st := governance.PropDraft; st <= governance.PropCancelled; st++ {
+		if strings.EqualFold(st.String(), s) {
+			return st, true
+		}
+	}
+	return 0, false
+}
+
+// parseProposalCategory accepts category names case-insensitively ("security").
+func parseProposalCategory(s string) (governance.ProposalCategory, bool) {
+	for c := governance.CatEarningRate; c <= governance.CatSecurity; c++ {
+		if strings.EqualFold(c.String(), s) {
+			return c, true
+		}
+	}
+	return 0, false
+}
+
+// queryLimit reads a positive ?limit= parameter, falling back to def.
+func queryLimit(r *http.Request, def int) int {
+	if n, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && n > 0 {
+		return n
+	}
+	return def
+}
@@ -0,0 +1,174 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/tutu-network/tutu/internal/infra/federation"
+	"github.com/tutu-network/tutu/internal/infra/onboarding"
+	"github.com/tutu-network/tutu/internal/infra/resource"
+)
+
+// ─── Onboarding API ─────────────────────────────────────────────────────────
+// The first-run wizard, driven step by step by the desktop UI or
+// `tutu onboard`. Every call returns the wizard state; a step that has not
+// been reached yet is rejected with 409. Node key only.
+//
+// GET  /api/onboarding              — progress
+// GET  /api/onboarding/packs        — starter packs, with the recommended one
+// POST /api/onboarding/hardware     — detect hardware
+// POST /api/onboarding/benchmark    — benchmark and suggest earning settings
+// POST /api/onboarding/earnings     — {idle_policy} (no body = accept suggestion)
+// POST /api/onboarding/models       — {pack} or {models: [...]}
+// POST /api/onboarding/federation   — {invite_code} or {skip: true}
+// POST /api/onboarding/reset        — start over
+//
+// POST /api/admin/federation/invites — {ttl} issue a signed invite code for
+//                                      this node's federation (admin only)
+
+// FederationInviter issues invite codes for the node's federation.
+type FederationInviter func(ttl time.Duration) (federation.Invite, error)
+
+// SetOnboarding enables the onboarding wizard endpoints.
+func (s *Server) SetOnboarding(w *onboarding.Wizard) { s.onboarding = w }
+
+// SetFederationInviter enables issuing federation invite codes.
+func (s *Server) SetFederationInviter(fn FederationInviter) { s.inviter = fn }
+
+// mountOnboarding registers the onboarding routes.
+func (s *Server) mountOnboarding(r chi.Router) {
+	r.Route("/api/onboarding", func(r chi.Router) {
+		r.Get("/", s.handleOnboardingState)
+		r.Get("/packs", s.handleOnboardingPacks)
+		r.Post("/hardware", s.handleOnboardingHardware)
+		r.Post("/benchmark", s.handleOnboardingBenchmark)
+		r.Post("/earnings", s.handleOnboardingEarnings)
+		r.Post("/models", s.handleOnboardingModels)
+		r.Post("/federation", s.handleOnboardingFederation)
+		r.Post("/reset", s.handleOnboardingReset)
+	})
+}
+
+// writeOnboarding writes the state after a step, or the step's error.
+func writeOnboarding(w http.ResponseWriter, st onboarding.State, err error) {
+	if err != nil {
+		writeDomainError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, st)
+}
+
+// decodeOptional decodes a JSON body into v, accepting an empty body.
+func decodeOptional(r *http.Request, v any) error {
+	err := json.NewDecoder(r.Body).Decode(v)
+	if err == io.EOF {
+		return nil
+	}
+	return err
+}
+
+func (s *Server) handleOnboardingState(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.onboarding.State())
+}
+
+func (s *Server) handleOnboardingPacks(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"packs":       onboarding.Packs,
+		"recommended": s.onboarding.State().RecommendedPack,
+	})
+}
+
+func (s *Server) handleOnboardingHardware(w http.ResponseWriter, r *http.Request) {
+	st, err := s.onboarding.DetectHardware()
+	writeOnboarding(w, st, err)
+}
+
+func (s *Server) handleOnboardingBenchmark(w http.ResponseWriter, r *http.Request) {
+	st, err := s.onboarding.RunBenchmark(r.Context())
+	writeOnboarding(w, st, err)
+}
+
+func (s *Server) handleOnboardingEarnings(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		IdlePolicy *resource.IdlePolicy `json:"idle_policy"`
+	}
+	if err := decodeOptional(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON: "+err.Error())
+		return
+	}
+	st, err := s.onboarding.SetEarnings(req.IdlePolicy)
+	writeOnboarding(w, st, err)
+}
+
+func (s *Server) handleOnboardingModels(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Pack   string   `json:"pack"`
+		Models []string `json:"models"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON: "+err.Error())
+		return
+	}
+	st, err := s.onboarding.SelectModels(req.Pack, req.Models)
+	writeOnboarding(w, st, err)
+}
+
+func (s *Server) handleOnboardingFederation(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		InviteCode string `json:"invite_code"`
+		Skip       bool   `json:"skip"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON: "+err.Error())
+		return
+	}
+	if req.Skip {
+		st, err := s.onboarding.SkipFederation()
+		writeOnboarding(w, st, err)
+		return
+	}
+	st, err := s.onboarding.JoinFederation(req.InviteCode)
+	writeOnboarding(w, st, err)
+}
+
+func (s *Server) handleOnboardingReset(w http.ResponseWriter, r *http.Request) {
+	st, err := s.onboarding.Reset()
+	writeOnboarding(w, st, err)
+}
+
+// ─── Federation Invites ─────────────────────────────────────────────────────
+// An invite code admits its bearer to the node's federation, so without an
+// API key (every route open) only this machine may mint one.
+
+func (s *Server) handleFederationInvite(w http.ResponseWriter, r *http.Request) {
+	if s.auth == nil && !loopbackRequest(r) {
+		writeError(w, http.StatusForbidden, "federation invites from another host need an API key (tutu secrets set api_key)")
+		return
+	}
+	var req struct {
+		TTL string `json:"ttl"` // Go duration (default 72h)
+	}
+	if err := decodeOptional(r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON: "+err.Error())
+		return
+	}
+	ttl := 72 * time.Hour
+	if req.TTL != "" {
+		d, err := time.ParseDuration(req.TTL)
+		if err != nil || d <= 0 {
+			writeError(w, http.StatusBadRequest, "ttl must be a positive duration such as 24h")
+			return
+		}
+		ttl = d
+	}
+	inv, err := s.inviter(ttl)
+	if err != nil {
+		writeDomainError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, inv)
+}
@@ -4,12 +4,14 @@ import (
 	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
 
 	"github.com/google/uuid"
 
+	"github.com/tutu-network/tutu/internal/domain"
 	"github.com/tutu-network/tutu/internal/infra/engine"
 )
 
@@ -26,28 +28,56 @@ func (s *Server) handleListModels(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	data := make([]map[string]interface{}, 0, len(models))
+	data := make([]openAIModel, 0, len(models))
 	for _, m := range models {
 		data = append(data, modelToOpenAI(m))
 	}
 
-	writeJSON(w, http.StatusOK, map[string]interface{}{
-		"object": "list",
-		"data":   data,
-	})
+	writeJSON(w, http.StatusOK, modelList{Object: "list", Data: data})
+}
+
+// modelList is the /v1/models response.
+type modelList struct {
+	Object string        `json:"object"`
+	Data   []openAIModel `json:"data"`
+}
+
+type openAIModel struct {
+	ID      string `json:"id"`
+	Object  string `json:"object"`
+	Created int64  `json:"created"`
+	OwnedBy string `json:"owned_by"`
+
+	Metadata *domain.ModelMetadata `json:"metadata,omitempty"` // context window, modalities, tool calling, license
 }
 
 // --- /v1/chat/completions ---
 
 // chatRequest is the OpenAI chat completions request body.
 type chatRequest struct {
-	Model       string        `json:"model"`
-	Messages    []chatMessage `json:"messages"`
-	Temperature *float32      `json:"temperature,omitempty"`
-	TopP        *float32      `json:"top_p,omitempty"`
-	MaxTokens   *int          `json:"max_tokens,omitempty"`
-	Stream      bool          `json:"stream"`
-	Stop        []string      `json:"stop,omitempty"`
+	Model       string             `json:"model"`
+	Messages    []chatMessage      `json:"messages"`
+	Temperature *float32           `json:"temperature,omitempty"`
+	TopP        *float32           `json:"top_p,omitempty"`
+	MaxTokens   *int               `json:"max_tokens,omitempty"`
+	Stream      bool               `json:"stream"`
+	Stop        []string           `json:"stop,omitempty"`
+	Seed        *int64             `json:"seed,omitempty"`
+	LogitBias   map[string]float32 `json:"logit_bias,omitempty"`
+
+	// Routing hints (TuTu extension): follow-ups with the same hint go to
+	// the node that served the previous request, and to the KV-cache slot
+	// holding its prompt. CacheKey wins if both are set.
+	SessionID string `json:"session_id,omitempty"`
+	CacheKey  string `json:"cache_key,omitempty"`
+}
+
+// routingHint returns the request's affinity hint ("" = none).
+func (r chatRequest) routingHint() string {
+	if r.CacheKey != "" {
+		return r.CacheKey
+	}
+	return r.SessionID
 }
 
 type chatMessage struct {
@@ -55,6 +85,47 @@ type chatMessage struct {
 	Content string `json:"content"`
 }
 
+// chatCompletion is the non-streaming chat completions response.
+type chatCompletion struct {
+	ID      string       `json:"id"`
+	Object  string       `json:"object"`
+	Created int64        `json:"created"`
+	Model   string       `json:"model"`
+	Choices []chatChoice `json:"choices"`
+	Usage   chatUsage    `json:"usage"`
+}
+
+type chatChoice struct {
+	Index        int         `json:"index"`
+	Message      chatMessage `json:"message"`
+	FinishReason string      `json:"finish_reason"`
+}
+
+type chatUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// chatChunk is one server-sent event of a streaming chat completion.
+type chatChunk struct {
+	ID      string        `json:"id"`
+	Object  string        `json:"object"`
+	Created int64         `json:"created"`
+	Model   string        `json:"model"`
+	Choices []chunkChoice `json:"choices"`
+}
+
+type chunkChoice struct {
+	Index        int       `json:"index"`
+	Delta        chatDelta `json:"delta"`
+	FinishReason *string   `json:"finish_reason"` // null until the last chunk
+}
+
+type chatDelta struct {
+	Content string `json:"content,omitempty"`
+}
+
 func (s *Server) handleChatCompletions(w http.ResponseWriter, r *http.Request) {
 	var req chatRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -66,9 +137,28 @@ func (s *Server) handleChatCompletions(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "model is required")
 		return
 	}
+	if !s.allowModel(w, r, req.Model) {
+		return
+	}
+
+	// Validate sampling parameters before loading anything
+	params, ok := s.resolveParams(w, req.Model, engine.ParamRequest{
+		Temperature: req.Temperature,
+		TopP:        req.TopP,
+		MaxTokens:   req.MaxTokens,
+		Stop:        req.Stop,
+		Seed:        req.Seed,
+		LogitBias:   req.LogitBias,
+	})
+	if !ok {
+		return
+	}
+
+	s.routeAffinity(w, req.routingHint(), req.Model)
+	params.SessionID = req.routingHint()
 
 	// Acquire model from pool
-	handle, err := s.pool.Acquire(req.Model, defaultLoadOpts())
+	handle, err := s.pool.Acquire(req.Model, s.loadOpts(req.Model))
 	if err != nil {
 		writeError(w, http.StatusBadRequest, "model error: "+err.Error())
 		return
@@ -81,21 +171,6 @@ func (s *Server) handleChatCompletions(w http.ResponseWriter, r *http.Request) {
 		chatMsgs[i] = engine.ChatMessage{Role: m.Role, Content: m.Content}
 	}
 
-	// Set generation params
-	params := defaultGenParams()
-	if req.Temperature != nil {
-		params.Temperature = *req.Temperature
-	}
-	if req.TopP != nil {
-		params.TopP = *req.TopP
-	}
-	if req.MaxTokens != nil {
-		params.MaxTokens = *req.MaxTokens
-	}
-	if len(req.Stop) > 0 {
-		params.Stop = req.Stop
-	}
-
 	completionID := "chatcmpl-" + uuid.New().String()[:8]
 
 	if req.Stream {
@@ -106,11 +181,12 @@ func (s *Server) handleChatCompletions(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) nonStreamChatResponse(w http.ResponseWriter, ctx context.Context, handle *engine.PoolHandle, messages []engine.ChatMessage, params engine.GenerateParams, model, completionID string) {
-	tokenCh, err := handle.Model().Chat(ctx, messages, params)
+	tokens, err := handle.Chat(ctx, messages, params)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
+	s.meterTokens(ctx, model, tokens)
 
 	// Collect all tokens
 	var content string
@@ -122,40 +198,39 @@ func (s *Server) nonStreamChatResponse(w http.ResponseWriter, ctx context.Contex
 	promptTokens := promptChars / 4
 	completionTokens := 0
 
-	for tok := range tokenCh {
+	for tok := range tokens.Tokens() {
 		content += tok.Text
 		completionTokens++
 	}
+	if err := tokens.Err(); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
 
-	writeJSON(w, http.StatusOK, map[string]interface{}{
-		"id":      completionID,
-		"object":  "chat.completion",
-		"created": time.Now().Unix(),
-		"model":   model,
-		"choices": []map[string]interface{}{
-			{
-				"index": 0,
-				"message": map[string]interface{}{
-					"role":    "assistant",
-					"content": content,
-				},
-				"finish_reason": "stop",
-			},
-		},
-		"usage": map[string]interface{}{
-			"prompt_tokens":     promptTokens,
-			"completion_tokens": completionTokens,
-			"total_tokens":      promptTokens + completionTokens,
+	writeJSON(w, http.StatusOK, chatCompletion{
+		ID:      completionID,
+		Object:  "chat.completion",
+		Created: time.Now().Unix(),
+		Model:   model,
+		Choices: []chatChoice{{
+			Message:      chatMessage{Role: "assistant", Content: content},
+			FinishReason: "stop",
+		}},
+		Usage: chatUsage{
+			PromptTokens:     promptTokens,
+			CompletionTokens: completionTokens,
+			TotalTokens:      promptTokens + completionTokens,
 		},
 	})
 }
 
 func (s *Server) streamChatResponse(w http.ResponseWriter, ctx context.Context, handle *engine.PoolHandle, messages []engine.ChatMessage, params engine.GenerateParams, model, completionID string) {
-	tokenCh, err := handle.Model().Chat(ctx, messages, params)
+	tokens, err := handle.Chat(ctx, messages, params)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
+	s.meterTokens(ctx, model, tokens)
 
 	// Set SSE headers
 	w.Header().Set("Content-Type", "text/event-stream")
@@ -171,21 +246,13 @@ func (s *Server) streamChatResponse(w http.ResponseWriter, ctx context.Context,
 
 	writer := bufio.NewWriter(w)
 
-	for tok := range tokenCh {
-		chunk := map[string]interface{}{
-			"id":      completionID,
-			"object":  "chat.completion.chunk",
-			"created": time.Now().Unix(),
-			"model":   model,
-			"choices": []map[string]interface{}{
-				{
-					"index": 0,
-					"delta": map[string]interface{}{
-						"content": tok.Text,
-					},
-					"finish_reason": nil,
-				},
-			},
+	for tok := range tokens.Tokens() {
+		chunk := chatChunk{
+			ID:      completionID,
+			Object:  "chat.completion.chunk",
+			Created: time.Now().Unix(),
+			Model:   model,
+			Choices: []chunkChoice{{Delta: chatDelta{Content: tok.Text}}},
 		}
 
 		data, _ := json.Marshal(chunk)
@@ -193,20 +260,18 @@ func (s *Server) streamChatResponse(w http.ResponseWriter, ctx context.Context,
 		writer.Flush()
 		flusher.Flush()
 	}
+	if tokens.Err() != nil {
+		return // client gone or stream aborted — never report finish_reason
+	}
 
 	// Send final chunk with finish_reason
-	finalChunk := map[string]interface{}{
-		"id":      completionID,
-		"object":  "chat.completion.chunk",
-		"created": time.Now().Unix(),
-		"model":   model,
-		"choices": []map[string]interface{}{
-			{
-				"index":         0,
-				"delta":         map[string]interface{}{},
-				"finish_reason": "stop",
-			},
-		},
+	stop := "stop"
+	finalChunk := chatChunk{
+		ID:      completionID,
+		Object:  "chat.completion.chunk",
+		Created: time.Now().Unix(),
+		Model:   model,
+		Choices: []chunkChoice{{FinishReason: &stop}},
 	}
 
 	data, _ := json.Marshal(finalChunk)
@@ -223,6 +288,25 @@ type embeddingRequest struct {
 	Input interface{} `json:"input"` // string or []string
 }
 
+// embeddingList is the /v1/embeddings response.
+type embeddingList struct {
+	Object string          `json:"object"`
+	Data   []embeddingData `json:"data"`
+	Model  string          `json:"model"`
+	Usage  embeddingUsage  `json:"usage"`
+}
+
+type embeddingData struct {
+	Object    string    `json:"object"`
+	Embedding []float32 `json:"embedding"`
+	Index     int       `json:"index"`
+}
+
+type embeddingUsage struct {
+	PromptTokens int `json:"prompt_tokens"`
+	TotalTokens  int `json:"total_tokens"`
+}
+
 func (s *Server) handleEmbeddings(w http.ResponseWriter, r *http.Request) {
 	var req embeddingRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -234,6 +318,9 @@ func (s *Server) handleEmbeddings(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "model is required")
 		return
 	}
+	if !s.allowModel(w, r, req.Model) {
+		return
+	}
 
 	// Normalize input to []string
 	var inputs []string
@@ -251,41 +338,54 @@ func (s *Server) handleEmbeddings(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	handle, err := s.pool.Acquire(req.Model, defaultLoadOpts())
-	if err != nil {
-		writeError(w, http.StatusBadRequest, "model error: "+err.Error())
-		return
-	}
-	defer handle.Release()
-
-	embeddings, err := handle.Model().Embed(r.Context(), inputs)
+	embeddings, status, err := s.embed(r.Context(), req.Model, inputs)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeError(w, status, err.Error())
 		return
 	}
 
-	data := make([]map[string]interface{}, len(embeddings))
+	data := make([]embeddingData, len(embeddings))
 	for i, emb := range embeddings {
-		data[i] = map[string]interface{}{
-			"object":    "embedding",
-			"embedding": emb,
-			"index":     i,
-		}
+		data[i] = embeddingData{Object: "embedding", Embedding: emb, Index: i}
 	}
 
-	writeJSON(w, http.StatusOK, map[string]interface{}{
-		"object": "list",
-		"data":   data,
-		"model":  req.Model,
-		"usage": map[string]interface{}{
-			"prompt_tokens": len(inputs),
-			"total_tokens":  len(inputs),
-		},
+	writeJSON(w, http.StatusOK, embeddingList{
+		Object: "list",
+		Data:   data,
+		Model:  req.Model,
+		Usage:  embeddingUsage{PromptTokens: len(inputs), TotalTokens: len(inputs)},
 	})
 }
 
 // ─── Helpers ────────────────────────────────────────────────────────────────
 
+// embed computes embeddings, coalescing with concurrent requests when a
+// batcher is configured. Returns the HTTP status to use on error.
+func (s *Server) embed(ctx context.Context, model string, inputs []string) ([][]float32, int, error) {
+	if s.batcher != nil {
+		vecs, err := s.batcher.Embed(ctx, model, s.loadOpts(model), inputs)
+		if errors.Is(err, domain.ErrModelNotFound) || errors.Is(err, domain.ErrModelCorrupted) {
+			return nil, http.StatusBadRequest, fmt.Errorf("model error: %w", err)
+		}
+		if err != nil {
+			return nil, http.StatusInternalServerError, err
+		}
+		return vecs, http.StatusOK, nil
+	}
+
+	handle, err := s.pool.Acquire(model, s.loadOpts(model))
+	if err != nil {
+		return nil, http.StatusBadRequest, fmt.Errorf("model error: %w", err)
+	}
+	defer handle.Release()
+
+	vecs, err := handle.Model().Embed(ctx, inputs)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	return vecs, http.StatusOK, nil
+}
+
 // buildPrompt concatenates chat messages into a single prompt string.
 // In a real implementation, this would use chat templates per model.
 func buildPrompt(messages []chatMessage) string {
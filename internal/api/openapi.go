@@ -0,0 +1,475 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/tutu-network/tutu/internal/domain"
+	"github.com/tutu-network/tutu/internal/infra/intelligence"
+	"github.com/tutu-network/tutu/internal/infra/passive"
+	"github.com/tutu-network/tutu/internal/infra/region"
+	"github.com/tutu-network/tutu/internal/infra/registry"
+	"github.com/tutu-network/tutu/internal/infra/scheduler"
+)
+
+// ─── OpenAPI ────────────────────────────────────────────────────────────────
+// GET /api/openapi.json — OpenAPI 3 description of this server's routes
+//
+// The document is built from the router itself, so it lists exactly the
+// API routes (/v1, /api and the documented top-level ones; not the website)
+// this node has mounted: a feature that is off is absent, and a new route
+// shows up without anyone editing a spec. Routes in endpointDocs also
+// get a summary and request/response schemas reflected from the structs
+// their handlers decode and encode; the rest are listed with a generic JSON
+// response until they are documented.
+
+// apiVersion is reported by /api/version and in the OpenAPI info block.
+const apiVersion = "0.1.0"
+
+// statusResponse is the body of simple status replies.
+type statusResponse struct {
+	Status string `json:"status"`
+}
+
+type versionResponse struct {
+	Version string `json:"version"`
+}
+
+// endpointDoc describes one route. Request and Response are zero values of
+// the types the handler decodes and encodes (nil = no body).
+type endpointDoc struct {
+	Summary  string
+	Request  any
+	Response any
+	Stream   string // media type of a streamed response, besides JSON
+}
+
+// endpointDocs documents routes by "METHOD /pattern".
+var endpointDocs = map[string]endpointDoc{
+	"GET /health":           {Summary: "Liveness check", Response: statusResponse{}},
+	"GET /api/status":       {Summary: "Server status", Response: statusResponse{}},
+	"GET /api/version":      {Summary: "API version", Response: versionResponse{}},
+	"GET /api/openapi.json": {Summary: "This document", Response: map[string]any{}},
+	"GET /metrics":          {Summary: "Prometheus metrics"},
+	"POST /mcp":             {Summary: "MCP Streamable HTTP endpoint"},
+
+	"GET /v1/models":            {Summary: "List local models (OpenAI)", Response: modelList{}},
+	"POST /v1/chat/completions": {Summary: "Chat completion (OpenAI)", Request: chatRequest{}, Response: chatCompletion{}, Stream: "text/event-stream"},
+	"POST /v1/embeddings":       {Summary: "Embed text (OpenAI)", Request: embeddingRequest{}, Response: embeddingList{}},
+
+	"POST /api/generate": {Summary: "Generate text (Ollama)", Request: ollamaGenerateRequest{}, Response: ollamaGenerateResponse{}, Stream: "application/x-ndjson"},
+	"POST /api/chat":     {Summary: "Chat (Ollama)", Request: ollamaChatRequest{}, Response: ollamaChatResponse{}, Stream: "application/x-ndjson"},
+	"GET /api/tags":      {Summary: "List local models (Ollama)", Response: ollamaTagsResponse{}},
+	"POST /api/show":     {Summary: "Model details (Ollama)", Request: ollamaShowRequest{}, Response: ollamaShowResponse{}},
+	"POST /api/pull":     {Summary: "Download a model (Ollama)", Request: ollamaPullRequest{}, Response: statusResponse{}},
+	"DELETE /api/delete": {Summary: "Remove a model (Ollama)", Request: ollamaDeleteRequest{}},
+	"GET /api/ps":        {Summary: "Loaded models (Ollama)", Response: ollamaPsResponse{}},
+	"GET /api/storage":   {Summary: "Storage budget and per-model sizes", Response: registry.StorageUsage{}},
+	"POST /api/pin":      {Summary: "Exempt a model from eviction", Request: pinRequest{}, Response: pinResponse{}},
+	"POST /api/unpin":    {Summary: "Make a model evictable again", Request: pinRequest{}, Response: pinResponse{}},
+
+	"GET /api/hardware":                          {Summary: "Hardware profile and tier", Response: passive.Profile{}},
+	"POST /api/admin/hardware/benchmark":         {Summary: "Re-run the hardware benchmark", Response: passive.Profile{}},
+	"GET /api/benchmarks":                        {Summary: "Per-model benchmarks", Response: benchmarkList{}},
+	"POST /api/admin/benchmarks/{model}":         {Summary: "Benchmark a model now", Response: domain.ModelBenchmark{}},
+	"GET /api/dashboard":                         {Summary: "Desktop home screen snapshot", Response: DashboardView{}},
+	"GET /api/scheduler/trends":                  {Summary: "Queue depth and completion rate over time", Response: schedulerTrends{}},
+	"GET /api/scheduler/ml/arms":                 {Summary: "ML scheduler bandit arms", Response: mlArmList{}},
+	"GET /api/scheduler/ml/observations":         {Summary: "Recent ML scheduler outcomes", Response: mlObservationList{}},
+	"GET /api/scheduler/ml/stats":                {Summary: "ML scheduler statistics and shadow comparison", Response: mlStats{}},
+	"GET /api/scheduler/affinity":                {Summary: "Routing-hint affinity outcomes and warm-hit rates", Response: scheduler.AffinityStats{}},
+	"GET /api/regions/latency":                   {Summary: "Region-to-region latency percentiles and trends", Response: region.LatencyMatrix{}},
+	"GET /api/admin/retirements":                 {Summary: "Retirement candidates and recent outcomes", Response: retirementList{}},
+	"POST /api/admin/retirements/{model}":        {Summary: "Retire a model after the safety checks", Request: retirementRequest{}, Response: intelligence.RetirementOutcome{}},
+	"POST /api/admin/retirements/{model}/veto":   {Summary: "Veto a model's retirement", Request: vetoRequest{}, Response: intelligence.RetirementStatus{}},
+	"DELETE /api/admin/retirements/{model}/veto": {Summary: "Lift a retirement veto", Response: intelligence.RetirementStatus{}},
+}
+
+// routeMethods are the methods described. A catch-all route (r.Handle) is
+// registered for every method; only its documented ones are listed.
+var routeMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE"}
+
+// routeParam matches a chi URL parameter, with or without a regexp.
+var routeParam = regexp.MustCompile(`\{([^}:]+)(:[^}]*)?\}`)
+
+func (s *Server) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	doc, err := s.OpenAPI()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, doc)
+}
+
+// OpenAPI builds the OpenAPI document for the routes currently mounted.
+func (s *Server) OpenAPI() (*OpenAPIDoc, error) {
+	routes, ok := s.Handler().(chi.Routes)
+	if !ok {
+		return nil, errNotChi
+	}
+
+	registered := make(map[string]map[string]bool) // pattern → methods
+	err := chi.Walk(routes, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		if strings.Contains(route, "*") {
+			return nil // mounted sub-handlers describe themselves
+		}
+		if route != "/" {
+			route = strings.TrimSuffix(route, "/")
+		}
+		if registered[route] == nil {
+			registered[route] = make(map[string]bool)
+		}
+		registered[route][method] = true
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	b := &schemaBuilder{names: make(map[reflect.Type]string), taken: make(map[string]bool)}
+	doc := &OpenAPIDoc{
+		OpenAPI: "3.0.3",
+		Info: OpenAPIInfo{
+			Title:       "TuTu API",
+			Version:     apiVersion,
+			Description: "Local inference (OpenAI- and Ollama-compatible) and node management.",
+		},
+		Paths:    make(map[string]map[string]*Operation),
+		Security: []map[string][]string{{"bearerAuth": {}}},
+		Components: OpenAPIComponents{
+			Schemas: make(map[string]*Schema),
+			SecuritySchemes: map[string]SecurityScheme{
+				"bearerAuth": {Type: "http", Scheme: "bearer"},
+			},
+		},
+	}
+	for route, methods := range registered {
+		catchAll := methods["CONNECT"] && methods["TRACE"]
+		path := routeParam.ReplaceAllString(route, "{$1}")
+		for _, method := range routeMethods {
+			key := method + " " + path
+			ed, documented := endpointDocs[key]
+			if !methods[method] || (catchAll && !documented) || (!documented && !isAPIPath(path)) {
+				continue
+			}
+			if doc.Paths[path] == nil {
+				doc.Paths[path] = make(map[string]*Operation)
+			}
+			doc.Paths[path][strings.ToLower(method)] = b.operation(method, path, ed)
+		}
+	}
+	for name, schema := range b.schemas() {
+		doc.Components.Schemas[name] = schema
+	}
+	return doc, nil
+}
+
+// isAPIPath reports whether path belongs to the API rather than the website.
+func isAPIPath(path string) bool {
+	return strings.HasPrefix(path, "/v1/") || strings.HasPrefix(path, "/api/")
+}
+
+// errNotChi is returned if Handler is ever wrapped in something that cannot
+// be walked.
+var errNotChi = errors.New("api: handler is not a chi router")
+
+// operation describes one method on one path.
+func (b *schemaBuilder) operation(method, path string, ed endpointDoc) *Operation {
+	op := &Operation{
+		OperationID: operationID(method, path),
+		Summary:     ed.Summary,
+		Tags:        []string{routeTag(path)},
+		Responses:   make(map[string]*Response),
+	}
+	for _, m := range routeParam.FindAllStringSubmatch(path, -1) {
+		op.Parameters = append(op.Parameters, Parameter{
+			Name: m[1], In: "path", Required: true, Schema: &Schema{Type: "string"},
+		})
+	}
+	if ed.Request != nil {
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content:  map[string]MediaType{"application/json": {Schema: b.schemaOf(reflect.TypeOf(ed.Request))}},
+		}
+	}
+	ok := &Response{Description: "OK"}
+	if ed.Response != nil {
+		body := b.schemaOf(reflect.TypeOf(ed.Response))
+		ok.Content = map[string]MediaType{"application/json": {Schema: body}}
+		if ed.Stream != "" {
+			ok.Content[ed.Stream] = MediaType{Schema: body}
+		}
+	} else if ed.Request == nil && ed.Summary == "" {
+		ok.Content = map[string]MediaType{"application/json": {Schema: &Schema{}}}
+	}
+	op.Responses["200"] = ok
+	op.Responses["default"] = &Response{
+		Description: "Error",
+		Content:     map[string]MediaType{"application/json": {Schema: b.schemaOf(reflect.TypeOf(errorResponse{}))}},
+	}
+	if !requiresAuth(path) {
+		op.Security = &[]map[string][]string{}
+	}
+	return op
+}
+
+// operationID derives a stable camelCase ID, e.g. "POST /api/admin/
+// benchmarks/{model}" → "postApiAdminBenchmarksModel".
+func operationID(method, path string) string {
+	var sb strings.Builder
+	sb.WriteString(strings.ToLower(method))
+	upper := true
+	for _, r := range path {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// routeTag groups routes for SDK generators: "openai" for /v1, "admin" for
+// the admin API, otherwise the first segment under /api.
+func routeTag(path string) string {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	switch {
+	case parts[0] == "v1":
+		return "openai"
+	case parts[0] != "api" || len(parts) < 2:
+		return "node"
+	case parts[1] == "admin":
+		return "admin"
+	case len(parts) == 2:
+		return "api"
+	}
+	return parts[1]
+}
+
+// ─── Document Types ─────────────────────────────────────────────────────────
+
+// OpenAPIDoc is an OpenAPI 3 document.
+type OpenAPIDoc struct {
+	OpenAPI    string                           `json:"openapi"`
+	Info       OpenAPIInfo                      `json:"info"`
+	Paths      map[string]map[string]*Operation `json:"paths"`
+	Components OpenAPIComponents                `json:"components"`
+	Security   []map[string][]string            `json:"security,omitempty"`
+}
+
+// OpenAPIInfo is the document's info block.
+type OpenAPIInfo struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// OpenAPIComponents holds the named schemas and security schemes.
+type OpenAPIComponents struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes"`
+}
+
+// SecurityScheme describes how requests authenticate.
+type SecurityScheme struct {
+	Type   string `json:"type"`
+	Scheme string `json:"scheme"`
+}
+
+// Operation is one method on a path. Security is set (to an empty list)
+// only on public routes, overriding the document's bearer auth.
+type Operation struct {
+	OperationID string                 `json:"operationId"`
+	Summary     string                 `json:"summary,omitempty"`
+	Tags        []string               `json:"tags,omitempty"`
+	Parameters  []Parameter            `json:"parameters,omitempty"`
+	RequestBody *RequestBody           `json:"requestBody,omitempty"`
+	Responses   map[string]*Response   `json:"responses"`
+	Security    *[]map[string][]string `json:"security,omitempty"`
+}
+
+// Parameter is a path or query parameter.
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required"`
+	Schema   *Schema `json:"schema"`
+}
+
+// RequestBody is an operation's request body.
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response is one response of an operation.
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType is a body in one content type.
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Schema is the subset of JSON Schema reflected from Go types.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+// ─── Schema Reflection ──────────────────────────────────────────────────────
+// Named structs become components referenced by $ref (which also handles
+// recursive types); everything else is inlined. Field names and optionality
+// come from the json tags, exactly as encoding/json sees them.
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+type schemaBuilder struct {
+	names      map[reflect.Type]string
+	taken      map[string]bool
+	components map[string]*Schema
+}
+
+// schemas returns the named schemas built so far.
+func (b *schemaBuilder) schemas() map[string]*Schema { return b.components }
+
+func (b *schemaBuilder) schemaOf(t reflect.Type) *Schema {
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case durationType:
+		return &Schema{Type: "integer", Format: "int64"} // nanoseconds
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return b.schemaOf(t.Elem())
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: b.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: b.schemaOf(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return b.structSchema(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + b.component(t)}
+	}
+	return &Schema{} // interface{}: any value
+}
+
+// component registers a named struct and returns its component name.
+func (b *schemaBuilder) component(t reflect.Type) string {
+	if name, ok := b.names[t]; ok {
+		return name
+	}
+	name := exportedName(t.Name())
+	if b.taken[name] {
+		pkg := t.PkgPath()
+		name = exportedName(pkg[strings.LastIndex(pkg, "/")+1:]) + name
+	}
+	b.names[t] = name
+	b.taken[name] = true
+	if b.components == nil {
+		b.components = make(map[string]*Schema)
+	}
+	b.components[name] = &Schema{} // placeholder while recursing
+	*b.components[name] = *b.structSchema(t)
+	return name
+}
+
+// structSchema reflects a struct's JSON fields, flattening embedded structs
+// the way encoding/json does.
+func (b *schemaBuilder) structSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" || (!f.IsExported() && !f.Anonymous) {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		ft := f.Type
+		if f.Anonymous && name == "" {
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				embedded := b.structSchema(ft)
+				for k, v := range embedded.Properties {
+					s.Properties[k] = v
+				}
+				s.Required = append(s.Required, embedded.Required...)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		prop := b.schemaOf(ft)
+		if ft.Kind() == reflect.Pointer && prop.Ref == "" {
+			prop.Nullable = true
+		}
+		s.Properties[name] = prop
+		if !strings.Contains(opts, "omitempty") && ft.Kind() != reflect.Pointer {
+			s.Required = append(s.Required, name)
+		}
+	}
+	sort.Strings(s.Required)
+	return s
+}
+
+// exportedName capitalizes a Go type name for use as a schema name.
+func exportedName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return '_'
+	}, name)
+	if name == "" {
+		return name
+	}
+	r := []rune(name)
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/tutu-network/tutu/internal/domain"
+	"github.com/tutu-network/tutu/internal/infra/params"
+)
+
+// ─── Runtime Parameters API ─────────────────────────────────────────────────
+// Hot-reload of tunable subsystem settings, mounted under the audited admin
+// API:
+//
+// GET  /api/admin/params                     — every parameter, live value, default
+// PUT  /api/admin/params/{key}               — {"value": "2.0"} apply immediately
+// GET  /api/admin/params/history             — applied changes, newest first (?limit=)
+// POST /api/admin/params/history/{id}/revert — restore the value a change replaced
+
+// SetParams enables the runtime parameters endpoints.
+func (s *Server) SetParams(p *params.Service) { s.tunables = p }
+
+type paramRequest struct {
+	Value string `json:"value"`
+}
+
+// mountParams registers the /params routes inside the admin router.
+func (s *Server) mountParams(r chi.Router) {
+	r.Route("/params", func(r chi.Router) {
+		r.Get("/", s.handleParamsList)
+		r.Put("/{key}", s.handleParamSet)
+		r.Get("/history", s.handleParamsHistory)
+		r.Post("/history/{id}/revert", s.handleParamRevert)
+	})
+}
+
+func (s *Server) handleParamsList(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{"params": s.tunables.List()})
+}
+
+func (s *Server) handleParamSet(w http.ResponseWriter, r *http.Request) {
+	var req paramRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	ch, err := s.tunables.Set(chi.URLParam(r, "key"), req.Value, "admin")
+	if err != nil {
+		writeError(w, paramErrorStatus(err), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, ch)
+}
+
+func (s *Server) handleParamsHistory(w http.ResponseWriter, r *http.Request) {
+	changes := s.tunables.History(queryLimit(r, 50))
+	writeJSON(w, http.StatusOK, map[string]interface{}{"changes": changes})
+}
+
+func (s *Server) handleParamRevert(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid change id")
+		return
+	}
+	ch, err := s.tunables.Revert(id)
+	if err != nil {
+		writeError(w, paramErrorStatus(err), err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, ch)
+}
+
+func paramErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrUnknownParam), errors.Is(err, domain.ErrParamChangeNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrInvalidParamValue):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
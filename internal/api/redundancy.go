@@ -0,0 +1,49 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/tutu-network/tutu/internal/domain"
+	"github.com/tutu-network/tutu/internal/infra/redundancy"
+)
+
+// ─── Redundant Verification API ─────────────────────────────────────────────
+// POST /api/inference/verify — run a request N-of-M and return the verdict
+//                              (consensus=false when replicas disagree)
+// GET  /api/inference/verify — recent verdicts, newest first (?limit=), and
+//                              the verification policy per task type
+
+// SetRedundancy enables the redundant verification endpoints.
+func (s *Server) SetRedundancy(c *redundancy.Corrector) { s.redundancy = c }
+
+func (s *Server) handleVerifyInference(w http.ResponseWriter, r *http.Request) {
+	var req redundancy.Request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.Model != "" && !s.allowModel(w, r, req.Model) {
+		return
+	}
+	verdict, err := s.redundancy.Verify(r.Context(), req)
+	switch {
+	case err == nil, errors.Is(err, domain.ErrNoConsensus):
+		writeJSON(w, http.StatusOK, verdict)
+	case errors.Is(err, domain.ErrRedundancyInvalid):
+		writeError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, domain.ErrNotEnoughReplicas):
+		writeError(w, http.StatusServiceUnavailable, err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+func (s *Server) handleVerifyList(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"verdicts": s.redundancy.Recent(queryLimit(r, 20)),
+		"stats":    s.redundancy.Stats(),
+		"policies": s.redundancy.Policies().Policies(),
+	})
+}
@@ -0,0 +1,44 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/tutu-network/tutu/internal/infra/region"
+)
+
+// ─── Region Routing API ─────────────────────────────────────────────────────
+// GET  /api/regions         — region status, decision counters and recent
+//                             decisions (?limit=)
+// GET  /api/regions/latency — from/to latency matrix: measured percentiles
+//                             and trend per region pair (the network map)
+// POST /api/regions/route   — route a task: target region and ranked nodes
+
+// SetRegionRouter enables the region routing endpoints.
+func (s *Server) SetRegionRouter(r *region.Router) { s.regions = r }
+
+func (s *Server) handleRegions(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"router":    s.regions.Stats(),
+		"regions":   s.regions.AllRegionStatuses(),
+		"decisions": s.regions.Decisions(queryLimit(r, 20)),
+	})
+}
+
+func (s *Server) handleRegionLatency(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.regions.LatencyMatrix())
+}
+
+func (s *Server) handleRegionRoute(w http.ResponseWriter, r *http.Request) {
+	var req region.Request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	res, err := s.regions.RouteTask(req)
+	if err != nil {
+		writeDomainError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, res)
+}
@@ -0,0 +1,137 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/tutu-network/tutu/internal/infra/intelligence"
+)
+
+// ─── Model Retirement API ───────────────────────────────────────────────────
+// GET    /api/admin/retirements              — candidates from the last scan,
+//                                              the retirement workflow and
+//                                              recent outcomes (?limit=)
+// POST   /api/admin/retirements/{model}      — {"reason": "..."} (optional)
+//                                              retire a model if it passes
+//                                              the safety checks; 409 names
+//                                              the check that failed (audited)
+// POST   /api/admin/retirements/{model}/veto — {"by", "reason", "until"} (all
+//                                              optional) block the model's
+//                                              retirement (audited)
+// DELETE /api/admin/retirements/{model}/veto — lift the veto; 404 if none
+
+// RetirementOps bundles the retirement scan and executor.
+type RetirementOps struct {
+	Optimizer *intelligence.Optimizer
+	Retirer   *intelligence.Retirer
+}
+
+// retirementRequest is the optional POST /api/admin/retirements/{model} body.
+type retirementRequest struct {
+	Reason string `json:"reason"`
+}
+
+// vetoRequest is the optional POST /api/admin/retirements/{model}/veto body.
+type vetoRequest struct {
+	By     string    `json:"by"` // default "operator"
+	Reason string    `json:"reason"`
+	Until  time.Time `json:"until"` // zero = until lifted
+}
+
+// retirementList is the GET /api/admin/retirements response.
+type retirementList struct {
+	Candidates []intelligence.RetirementCandidate `json:"candidates"`
+	Workflow   []intelligence.RetirementStatus    `json:"workflow"`
+	Outcomes   []intelligence.RetirementOutcome   `json:"outcomes"`
+}
+
+// SetRetirements enables the model retirement endpoints.
+func (s *Server) SetRetirements(o *RetirementOps) { s.retirements = o }
+
+// mountRetirements registers the retirement routes inside the admin router.
+func (s *Server) mountRetirements(r chi.Router) {
+	r.Get("/retirements", s.handleListRetirements)
+	r.Post("/retirements/{model}", s.handleExecuteRetirement)
+	r.Post("/retirements/{model}/veto", s.handleVetoRetirement)
+	r.Delete("/retirements/{model}/veto", s.handleLiftRetirementVeto)
+}
+
+func (s *Server) handleListRetirements(w http.ResponseWriter, r *http.Request) {
+	out := retirementList{
+		Candidates: s.retirements.Optimizer.RetirementCandidates(),
+		Workflow:   s.retirements.Retirer.Workflow(),
+		Outcomes:   s.retirements.Retirer.RecentRetirements(queryLimit(r, 20)),
+	}
+	if out.Outcomes == nil {
+		out.Outcomes = []intelligence.RetirementOutcome{}
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *Server) handleExecuteRetirement(w http.ResponseWriter, r *http.Request) {
+	var req retirementRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	info, err := s.models.Show(chi.URLParam(r, "model"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if req.Reason == "" {
+		req.Reason = "operator request"
+		for _, c := range s.retirements.Optimizer.RetirementCandidates() {
+			if c.ModelName == info.Name {
+				req.Reason = c.Reason
+				break
+			}
+		}
+	}
+	out, err := s.retirements.Retirer.ExecuteRetirement(info.Name, req.Reason)
+	if err != nil {
+		writeDomainError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *Server) handleVetoRetirement(w http.ResponseWriter, r *http.Request) {
+	var req vetoRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	info, err := s.models.Show(chi.URLParam(r, "model"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if req.By == "" {
+		req.By = "operator"
+	}
+	st := s.retirements.Retirer.Veto(info.Name, intelligence.RetirementVeto{
+		By:     req.By,
+		Reason: req.Reason,
+		Until:  req.Until,
+	})
+	writeJSON(w, http.StatusOK, st)
+}
+
+func (s *Server) handleLiftRetirementVeto(w http.ResponseWriter, r *http.Request) {
+	model := chi.URLParam(r, "model")
+	if info, err := s.models.Show(model); err == nil {
+		model = info.Name
+	}
+	st, err := s.retirements.Retirer.LiftVeto(model)
+	if err != nil {
+		writeDomainError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, st)
+}
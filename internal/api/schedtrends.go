@@ -0,0 +1,70 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/infra/sqlite"
+)
+
+// ─── Scheduler Load Trends ──────────────────────────────────────────────────
+// GET /api/scheduler/trends — queue depth and completion rate over time,
+//                             from the persisted scheduler snapshots
+//                             (?window=24h&bucket=1h)
+//
+// Feeds the desktop UI's network load chart.
+
+// maxTrendPoints bounds window / bucket.
+const maxTrendPoints = 1000
+
+// SchedulerTrendsFunc returns the trend points since a time, bucketed.
+type SchedulerTrendsFunc func(since time.Time, bucket time.Duration) ([]sqlite.SchedulerTrendPoint, error)
+
+// schedulerTrends is the GET /api/scheduler/trends response.
+type schedulerTrends struct {
+	Window string                       `json:"window"`
+	Bucket string                       `json:"bucket"`
+	Points []sqlite.SchedulerTrendPoint `json:"points"`
+}
+
+// SetSchedulerTrends enables the scheduler load trend endpoint.
+func (s *Server) SetSchedulerTrends(fn SchedulerTrendsFunc) { s.schedTrends = fn }
+
+func (s *Server) handleSchedulerTrends(w http.ResponseWriter, r *http.Request) {
+	window, ok := queryDuration(w, r, "window", 24*time.Hour)
+	if !ok {
+		return
+	}
+	bucket, ok := queryDuration(w, r, "bucket", time.Hour)
+	if !ok {
+		return
+	}
+	if window/bucket > maxTrendPoints {
+		writeError(w, http.StatusBadRequest, "window / bucket exceeds 1000 points")
+		return
+	}
+	points, err := s.schedTrends(time.Now().Add(-window), bucket)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if points == nil {
+		points = []sqlite.SchedulerTrendPoint{}
+	}
+	writeJSON(w, http.StatusOK, schedulerTrends{Window: window.String(), Bucket: bucket.String(), Points: points})
+}
+
+// queryDuration reads a positive duration parameter, falling back to def.
+// On a bad value it writes a 400 and returns false.
+func queryDuration(w http.ResponseWriter, r *http.Request, name string, def time.Duration) (time.Duration, bool) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return def, true
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		writeError(w, http.StatusBadRequest, name+" must be a positive duration, got "+v)
+		return 0, false
+	}
+	return d, true
+}
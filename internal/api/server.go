@@ -4,6 +4,8 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -14,26 +16,77 @@ import (
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 
+	"github.com/tutu-network/tutu/internal/app/credit"
 	"github.com/tutu-network/tutu/internal/domain"
+	"github.com/tutu-network/tutu/internal/infra/audit"
+	"github.com/tutu-network/tutu/internal/infra/embedding"
 	"github.com/tutu-network/tutu/internal/infra/engine"
+	"github.com/tutu-network/tutu/internal/infra/housekeeping"
+	"github.com/tutu-network/tutu/internal/infra/journal"
+	"github.com/tutu-network/tutu/internal/infra/marketplace"
+	"github.com/tutu-network/tutu/internal/infra/mlscheduler"
+	"github.com/tutu-network/tutu/internal/infra/onboarding"
+	"github.com/tutu-network/tutu/internal/infra/params"
+	"github.com/tutu-network/tutu/internal/infra/passive"
+	"github.com/tutu-network/tutu/internal/infra/redundancy"
+	"github.com/tutu-network/tutu/internal/infra/region"
 	"github.com/tutu-network/tutu/internal/infra/registry"
+	"github.com/tutu-network/tutu/internal/infra/resource"
+	"github.com/tutu-network/tutu/internal/infra/scheduler"
+	"github.com/tutu-network/tutu/internal/infra/tenant"
 )
 
 // Server is the TuTu HTTP API server.
 type Server struct {
 	pool           *engine.Pool
+	batcher        *engine.Batcher    // Dynamic request batching (nil = direct pool calls)
+	params         *engine.ParamGuard // Generation parameter defaults + limits
 	models         *registry.Manager
 	metricsEnabled bool
-	mcpHandler     http.Handler   // Phase 2: MCP transport handler (nil if not set)
-	engagement     *EngagementAPI // Phase 2: Engagement REST API
-	earningsHub    *EarningsHub   // Phase 2: Live earnings SSE feed
+	mcpHandler     http.Handler              // Phase 2: MCP transport handler (nil if not set)
+	engagement     *EngagementAPI            // Phase 2: Engagement REST API
+	earningsHub    *EarningsHub              // Phase 2: Live earnings SSE feed
+	auth           TokenVerifier             // Bearer-token auth (nil = disabled)
+	audit          *audit.Log                // Admin API audit log (nil = admin API disabled)
+	network        *NetworkOps               // Network operations views under /api/admin (nil = disabled)
+	tunables       *params.Service           // Runtime parameters under /api/admin (nil = disabled)
+	diagnostics    func(io.Writer) error     // Support bundle writer under /api/admin (nil = disabled)
+	journal        *journal.Journal          // Task event journal under /api/admin (nil = disabled)
+	taskResults    *TaskResultOps            // Attested task results under /api/admin (nil = disabled)
+	agents         *AgentOps                 // Multi-step agent runs (nil = disabled)
+	embedBatch     *embedding.Pipeline       // Embedding batch jobs + vector store (nil = disabled)
+	redundancy     *redundancy.Corrector     // N-of-M verified inference (nil = disabled)
+	stealer        *scheduler.Stealer        // Work stealing between node queues (nil = disabled)
+	admission      *scheduler.Admission      // Back-pressure admission for inference (nil = admit all)
+	regions        *region.Router            // Region-aware task routing (nil = disabled)
+	dashboard      *DashboardOps             // Aggregated desktop home screen (nil = disabled)
+	idlePolicy     *resource.PolicyEngine    // Idle compute policy under /api/admin (nil = disabled)
+	hardware       *passive.Profiler         // Hardware profile and benchmark (nil = disabled)
+	tenants        *tenant.Registry          // Namespaces: per-team keys, limits and usage (nil = disabled)
+	marketplace    *marketplace.Store        // Marketplace listing lineage (nil = disabled)
+	billing        *credit.Billing           // Payment webhooks and invoices (nil = disabled)
+	onboarding     *onboarding.Wizard        // First-run setup wizard (nil = disabled)
+	inviter        FederationInviter         // Federation invite codes under /api/admin (nil = disabled)
+	housekeeping   *housekeeping.Coordinator // Maintenance job status under /api/admin (nil = disabled)
+	benchmarks     *BenchmarkOps             // Per-model throughput benchmarks (nil = disabled)
+	retirements    *RetirementOps            // Safe model retirement under /api/admin (nil = disabled)
+	schedTrends    SchedulerTrendsFunc       // Scheduler load trends from snapshots (nil = disabled)
+	mlScheduler    *mlscheduler.Scheduler    // ML scheduler arms, observations and stats (nil = disabled)
+	affinity       *scheduler.AffinityTable  // Routing-hint affinity for chat completions (nil = disabled)
+	nodeID         string                    // Node inference is served on (with affinity)
 }
 
 // NewServer creates a new API server.
 func NewServer(pool *engine.Pool, models *registry.Manager) *Server {
-	return &Server{pool: pool, models: models}
+	return &Server{pool: pool, models: models, params: engine.DefaultParamGuard()}
 }
 
+// SetBatcher routes embedding requests through a dynamic batcher.
+func (s *Server) SetBatcher(b *engine.Batcher) { s.batcher = b }
+
+// SetParamGuard replaces the generation parameter defaults and limits.
+func (s *Server) SetParamGuard(g *engine.ParamGuard) { s.params = g }
+
 // EnableMetrics enables the /metrics Prometheus endpoint.
 func (s *Server) EnableMetrics() { s.metricsEnabled = true }
 
@@ -55,47 +108,51 @@ func (s *Server) Handler() http.Handler {
 
 	// Middleware
 	r.Use(middleware.RequestID)
+	r.Use(capturePeerAddr)
 	r.Use(middleware.RealIP)
 	r.Use(middleware.Recoverer)
 	r.Use(middleware.Timeout(5 * time.Minute))
 	r.Use(corsMiddleware)
+	r.Use(s.authMiddleware)
 
 	// Health check for Railway/Render
 	r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
-		writeJSON(w, http.StatusOK, map[string]string{
-			"status": "ok",
-		})
+		writeJSON(w, http.StatusOK, statusResponse{Status: "ok"})
 	})
 
 	// API status endpoint
 	r.Get("/api/status", func(w http.ResponseWriter, r *http.Request) {
-		writeJSON(w, http.StatusOK, map[string]string{
-			"status": "TuTu is running",
-		})
+		writeJSON(w, http.StatusOK, statusResponse{Status: "TuTu is running"})
 	})
 
 	r.Get("/api/version", func(w http.ResponseWriter, r *http.Request) {
-		writeJSON(w, http.StatusOK, map[string]string{
-			"version": "0.1.0",
-		})
+		writeJSON(w, http.StatusOK, versionResponse{Version: apiVersion})
 	})
 
+	// Machine-readable description of every route mounted below
+	r.Get("/api/openapi.json", s.handleOpenAPI)
+
 	// OpenAI-compatible endpoints (Phase 0)
 	r.Route("/v1", func(r chi.Router) {
 		r.Get("/models", s.handleListModels)
-		r.Post("/chat/completions", s.handleChatCompletions)
-		r.Post("/embeddings", s.handleEmbeddings)
+		r.With(s.admitInference).Post("/chat/completions", s.handleChatCompletions)
+		r.With(s.admitInference).Post("/embeddings", s.handleEmbeddings)
 	})
 
 	// Ollama-compatible endpoints
 	r.Route("/api", func(r chi.Router) {
-		r.Post("/generate", s.handleOllamaGenerate)
-		r.Post("/chat", s.handleOllamaChat)
+		r.With(s.admitInference).Post("/generate", s.handleOllamaGenerate)
+		r.With(s.admitInference).Post("/chat", s.handleOllamaChat)
 		r.Get("/tags", s.handleOllamaTags)
 		r.Post("/show", s.handleOllamaShow)
 		r.Post("/pull", s.handleOllamaPull)
 		r.Delete("/delete", s.handleOllamaDelete)
 		r.Get("/ps", s.handleOllamaPs)
+
+		// Local storage budget and model pinning
+		r.Get("/storage", s.handleStorage)
+		r.Post("/pin", s.handlePin)
+		r.Post("/unpin", s.handleUnpin)
 	})
 
 	// Prometheus metrics endpoint (Phase 1 — observability)
@@ -119,6 +176,7 @@ func (s *Server) Handler() http.Handler {
 			r.Post("/notifications/{id}/shown", s.engagement.HandleNotificationShown)
 			r.Get("/summary", s.engagement.HandleSummary)
 		})
+		r.Get("/api/earnings/reports", s.engagement.HandleEarningsReports)
 	}
 
 	// Live earnings SSE feed (Phase 2 — Architecture Part XIII #5)
@@ -126,6 +184,94 @@ func (s *Server) Handler() http.Handler {
 		r.Get("/api/earnings/live", s.earningsHub.HandleEarningsSSE)
 	}
 
+	// Embedding batch jobs and the local vector store
+	if s.embedBatch != nil {
+		s.mountEmbedBatch(r)
+	}
+
+	// Redundant verification — N-of-M execution for high-value requests
+	if s.redundancy != nil {
+		r.Post("/api/inference/verify", s.handleVerifyInference)
+		r.Get("/api/inference/verify", s.handleVerifyList)
+	}
+
+	// Back-pressure level, signals and transitions
+	if s.admission != nil {
+		r.Get("/api/scheduler/backpressure", s.handleBackPressure)
+	}
+
+	// Scheduler load trends — queue depth and completion rate over time
+	if s.schedTrends != nil {
+		r.Get("/api/scheduler/trends", s.handleSchedulerTrends)
+	}
+
+	// ML scheduler — bandit arms, outcomes and gate statistics
+	if s.mlScheduler != nil {
+		s.mountMLScheduler(r)
+	}
+
+	// Request affinity — routing-hint outcomes and warm-hit rates
+	if s.affinity != nil {
+		r.Get("/api/scheduler/affinity", s.handleAffinityStats)
+	}
+
+	// Work stealing — queue handoff between nodes
+	if s.stealer != nil {
+		s.mountSteal(r)
+	}
+
+	// Region routing — persisted region status and routing decisions
+	if s.regions != nil {
+		r.Get("/api/regions", s.handleRegions)
+		r.Get("/api/regions/latency", s.handleRegionLatency)
+		r.Post("/api/regions/route", s.handleRegionRoute)
+	}
+
+	// Agent runs — multi-step tool-calling plans (task type AGENT)
+	if s.agents != nil {
+		s.mountAgent(r)
+	}
+
+	// Hardware profile — detected hardware, benchmark and tier
+	if s.hardware != nil {
+		r.Get("/api/hardware", s.handleHardware)
+	}
+
+	// Model benchmarks — tokens/sec and time to first token per model
+	if s.benchmarks != nil {
+		r.Get("/api/benchmarks", s.handleListBenchmarks)
+	}
+
+	// Namespace usage — what the caller's namespace has used
+	if s.tenants != nil {
+		r.Get("/api/usage", s.handleUsage)
+	}
+
+	// Desktop dashboard — one cacheable snapshot of the home screen
+	if s.dashboard != nil {
+		r.Get("/api/dashboard", s.handleDashboard)
+	}
+
+	// Marketplace lineage — provenance of derived models
+	if s.marketplace != nil {
+		s.mountMarketplace(r)
+	}
+
+	// Billing — signed payment webhooks top up credits and issue invoices
+	if s.billing != nil {
+		s.mountBilling(r)
+	}
+
+	// Onboarding — the first-run wizard shared by the desktop UI and CLI
+	if s.onboarding != nil {
+		s.mountOnboarding(r)
+	}
+
+	// Admin API — every call is recorded in the tamper-evident audit log
+	if s.audit != nil {
+		s.mountAdmin(r)
+	}
+
 	// Root route - serve API status for backend subdomain, website for main domain
 	websiteDir := findWebsiteDir()
 
@@ -157,17 +303,13 @@ func (s *Server) Handler() http.Handler {
 		host := req.Host
 		if isBackendDomain(host) {
 			// Backend subdomain: serve API status
-			writeJSON(w, http.StatusOK, map[string]string{
-				"status": "TuTu is running",
-			})
+			writeJSON(w, http.StatusOK, statusResponse{Status: "TuTu is running"})
 		} else if websiteDir != "" {
 			// Main domain: serve website
 			http.ServeFile(w, req, filepath.Join(websiteDir, "index.html"))
 		} else {
 			// Fallback if website not found
-			writeJSON(w, http.StatusOK, map[string]string{
-				"status": "TuTu is running",
-			})
+			writeJSON(w, http.StatusOK, statusResponse{Status: "TuTu is running"})
 		}
 	})
 
@@ -232,14 +374,44 @@ func writeJSON(w http.ResponseWriter, status int, v interface{}) {
 	json.NewEncoder(w).Encode(v)
 }
 
+// errorResponse is the body of every JSON error.
+type errorResponse struct {
+	Error apiError `json:"error"`
+}
+
+type apiError struct {
+	Message string `json:"message"`
+	Type    string `json:"type"`
+	Param   string `json:"param,omitempty"`
+	Code    string `json:"code,omitempty"`
+}
+
 // writeError writes a JSON error response.
 func writeError(w http.ResponseWriter, status int, msg string) {
-	writeJSON(w, status, map[string]interface{}{
-		"error": map[string]interface{}{
-			"message": msg,
-			"type":    "error",
-		},
-	})
+	writeJSON(w, status, errorResponse{Error: apiError{Message: msg, Type: "error"}})
+}
+
+// codeStatus maps domain error codes to HTTP statuses.
+var codeStatus = map[domain.ErrorCode]int{
+	domain.CodeNotFound:      http.StatusNotFound,
+	domain.CodeConflict:      http.StatusConflict,
+	domain.CodeQuotaExceeded: http.StatusTooManyRequests,
+	domain.CodeNotEligible:   http.StatusForbidden,
+	domain.CodeInvalid:       http.StatusBadRequest,
+	domain.CodeUnavailable:   http.StatusServiceUnavailable,
+}
+
+// writeDomainError writes err with the HTTP status of its domain error code
+// and includes the code so clients can branch on it. Uncoded errors are a
+// 500.
+func writeDomainError(w http.ResponseWriter, err error) {
+	code := domain.CodeOf(err)
+	status, ok := codeStatus[code]
+	if !ok {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, status, errorResponse{Error: apiError{Message: err.Error(), Type: "error", Code: string(code)}})
 }
 
 // corsMiddleware adds CORS headers for local development.
@@ -267,20 +439,45 @@ func defaultLoadOpts() engine.LoadOptions {
 	}
 }
 
-func defaultGenParams() engine.GenerateParams {
-	return engine.GenerateParams{
-		Temperature: 0.7,
-		TopP:        0.9,
-		MaxTokens:   2048,
+// loadOpts returns the load options for model: the defaults, with the
+// context window capped at the one the model was trained for.
+func (s *Server) loadOpts(model string) engine.LoadOptions {
+	opts := defaultLoadOpts()
+	if info, err := s.models.Show(model); err == nil && info.Metadata != nil {
+		if n := info.Metadata.ContextLength; n > 0 && n < opts.NumCtx {
+			opts.NumCtx = n
+		}
+	}
+	return opts
+}
+
+// resolveParams validates caller parameters for a model. On failure it
+// writes an OpenAI-style invalid_request_error and returns false.
+func (s *Server) resolveParams(w http.ResponseWriter, model string, req engine.ParamRequest) (engine.GenerateParams, bool) {
+	params, err := s.params.Resolve(model, req)
+	if err != nil {
+		writeParamError(w, err)
+		return engine.GenerateParams{}, false
 	}
+	return params, true
 }
 
-// modelToOpenAI converts a domain.ModelInfo to OpenAI model list entry.
-func modelToOpenAI(m domain.ModelInfo) map[string]interface{} {
-	return map[string]interface{}{
-		"id":       m.Name,
-		"object":   "model",
-		"created":  m.PulledAt.Unix(),
-		"owned_by": "tutu",
+// writeParamError writes a structured 400 naming the offending parameter.
+func writeParamError(w http.ResponseWriter, err error) {
+	var pe *engine.ParamError
+	if !errors.As(err, &pe) {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
 	}
+	writeJSON(w, http.StatusBadRequest, errorResponse{Error: apiError{
+		Message: pe.Error(),
+		Type:    "invalid_request_error",
+		Param:   pe.Param,
+		Code:    "invalid_parameter",
+	}})
+}
+
+// modelToOpenAI converts a domain.ModelInfo to OpenAI model list entry.
+func modelToOpenAI(m domain.ModelInfo) openAIModel {
+	return openAIModel{ID: m.Name, Object: "model", Created: m.PulledAt.Unix(), OwnedBy: "tutu", Metadata: m.Metadata}
 }
@@ -0,0 +1,28 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/tutu-network/tutu/internal/infra/scheduler"
+)
+
+// ─── Work Stealing API ──────────────────────────────────────────────────────
+// GET  /api/scheduler/steal — steal counters for both protocol sides
+//
+// Grants and confirmations are not served over HTTP: the thief's node ID
+// cannot be authenticated here, and any caller could dequeue local tasks
+// and hand them off to a node that never runs them. They will travel over
+// a signed peer-to-peer channel instead.
+
+// SetStealer enables the work-stealing endpoints.
+func (s *Server) SetStealer(st *scheduler.Stealer) { s.stealer = st }
+
+func (s *Server) mountSteal(r chi.Router) {
+	r.Get("/api/scheduler/steal", s.handleStealStats)
+}
+
+func (s *Server) handleStealStats(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.stealer.Stats())
+}
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/tutu-network/tutu/internal/domain"
+)
+
+// ─── Storage & Pinning API ──────────────────────────────────────────────────
+// GET  /api/storage  — usage against the storage budget, per-model sizes
+// POST /api/pin      — {"name": "llama3"} never auto-evict this model
+// POST /api/unpin    — {"name": "llama3"} make it evictable again
+
+type pinRequest struct {
+	Name string `json:"name"`
+}
+
+type pinResponse struct {
+	Name   string `json:"name"`
+	Pinned bool   `json:"pinned"`
+}
+
+func (s *Server) handleStorage(w http.ResponseWriter, r *http.Request) {
+	u, err := s.models.Usage()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, u)
+}
+
+func (s *Server) handlePin(w http.ResponseWriter, r *http.Request) {
+	s.setPinned(w, r, true)
+}
+
+func (s *Server) handleUnpin(w http.ResponseWriter, r *http.Request) {
+	s.setPinned(w, r, false)
+}
+
+func (s *Server) setPinned(w http.ResponseWriter, r *http.Request, pinned bool) {
+	var req pinRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if req.Name == "" {
+		writeError(w, http.StatusBadRequest, "name is required")
+		return
+	}
+	if err := s.models.SetPinned(req.Name, pinned); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, domain.ErrModelNotFound) {
+			status = http.StatusNotFound
+		}
+		writeError(w, status, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, pinResponse{Name: req.Name, Pinned: pinned})
+}
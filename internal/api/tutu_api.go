@@ -22,13 +22,6 @@ func (s *Server) handleOllamaTags(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	type ollamaModel struct {
-		Name       string    `json:"name"`
-		ModifiedAt time.Time `json:"modified_at"`
-		Size       int64     `json:"size"`
-		Digest     string    `json:"digest"`
-	}
-
 	out := make([]ollamaModel, len(models))
 	for i, m := range models {
 		out[i] = ollamaModel{
@@ -39,9 +32,19 @@ func (s *Server) handleOllamaTags(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	writeJSON(w, http.StatusOK, map[string]interface{}{
-		"models": out,
-	})
+	writeJSON(w, http.StatusOK, ollamaTagsResponse{Models: out})
+}
+
+// ollamaTagsResponse is the /api/tags response.
+type ollamaTagsResponse struct {
+	Models []ollamaModel `json:"models"`
+}
+
+type ollamaModel struct {
+	Name       string    `json:"name"`
+	ModifiedAt time.Time `json:"modified_at"`
+	Size       int64     `json:"size"`
+	Digest     string    `json:"digest"`
 }
 
 // --- /api/show (model details) ---
@@ -50,6 +53,23 @@ type ollamaShowRequest struct {
 	Name string `json:"name"`
 }
 
+type ollamaShowResponse struct {
+	Modelfile    string             `json:"modelfile"`
+	Parameters   string             `json:"parameters"`
+	Template     string             `json:"template"`
+	License      string             `json:"license,omitempty"`
+	Details      ollamaModelDetails `json:"details"`
+	ModelInfo    map[string]any     `json:"model_info,omitempty"`   // e.g. "llama.context_length"
+	Capabilities []string           `json:"capabilities,omitempty"` // "completion", "tools", "vision"
+}
+
+type ollamaModelDetails struct {
+	Format            string `json:"format"`
+	Family            string `json:"family"`
+	ParameterSize     string `json:"parameter_size"`
+	QuantizationLevel string `json:"quantization_level"`
+}
+
 func (s *Server) handleOllamaShow(w http.ResponseWriter, r *http.Request) {
 	var req ollamaShowRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -63,25 +83,106 @@ func (s *Server) handleOllamaShow(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	writeJSON(w, http.StatusOK, map[string]interface{}{
-		"modelfile":  "",
-		"parameters": "",
-		"template":   "",
-		"details": map[string]interface{}{
-			"format":             info.Format,
-			"family":             info.Family,
-			"parameter_size":     info.Parameters,
-			"quantization_level": info.Quantization,
-		},
-	})
+	resp := ollamaShowResponse{Details: ollamaModelDetails{
+		Format:            info.Format,
+		Family:            info.Family,
+		ParameterSize:     info.Parameters,
+		QuantizationLevel: info.Quantization,
+	}}
+	if md := info.Metadata; md != nil {
+		resp.License = md.License
+		resp.Capabilities = ollamaCapabilities(*md)
+		resp.ModelInfo = map[string]any{"general.architecture": info.Family}
+		if md.ContextLength > 0 {
+			resp.ModelInfo[info.Family+".context_length"] = md.ContextLength
+		}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// ollamaCapabilities lists a model's capabilities the way Ollama does.
+func ollamaCapabilities(md domain.ModelMetadata) []string {
+	caps := []string{"completion"}
+	if md.ToolCalling {
+		caps = append(caps, "tools")
+	}
+	for _, m := range md.Modalities {
+		if m == domain.ModalityImage {
+			caps = append(caps, "vision")
+		}
+	}
+	return caps
 }
 
 // --- /api/generate (text generation) ---
 
 type ollamaGenerateRequest struct {
-	Model  string `json:"model"`
-	Prompt string `json:"prompt"`
-	Stream *bool  `json:"stream,omitempty"`
+	Model   string         `json:"model"`
+	Prompt  string         `json:"prompt"`
+	Stream  *bool          `json:"stream,omitempty"`
+	Options *ollamaOptions `json:"options,omitempty"`
+}
+
+// ollamaGenerateResponse is one line of a streamed generation, or the whole
+// response when stream is false.
+type ollamaGenerateResponse struct {
+	Model     string `json:"model"`
+	CreatedAt string `json:"created_at"`
+	Response  string `json:"response"`
+	Done      bool   `json:"done"`
+}
+
+// ollamaOptions is the subset of Ollama's runtime options TuTu honors.
+type ollamaOptions struct {
+	Temperature *float32 `json:"temperature,omitempty"`
+	TopP        *float32 `json:"top_p,omitempty"`
+	NumPredict  *int     `json:"num_predict,omitempty"`
+	Stop        []string `json:"stop,omitempty"`
+	Seed        *int64   `json:"seed,omitempty"`
+}
+
+// Ollama's num_predict sentinels.
+const (
+	numPredictUnlimited   = -1 // generate until a stop condition
+	numPredictFillContext = -2 // generate until the context window is full
+)
+
+// paramRequest converts Ollama options to an engine.ParamRequest.
+func (o *ollamaOptions) paramRequest() engine.ParamRequest {
+	if o == nil {
+		return engine.ParamRequest{}
+	}
+	return engine.ParamRequest{
+		Temperature: o.Temperature,
+		TopP:        o.TopP,
+		MaxTokens:   o.NumPredict,
+		Stop:        o.Stop,
+		Seed:        o.Seed,
+	}
+}
+
+// ollamaParamRequest converts o for model, replacing the num_predict
+// sentinels with limits TuTu enforces: -1 becomes the model's max_tokens
+// limit and -2 its context window within that limit. Without either the
+// server default applies.
+func (s *Server) ollamaParamRequest(model string, o *ollamaOptions) engine.ParamRequest {
+	req := o.paramRequest()
+	if req.MaxTokens == nil || (*req.MaxTokens != numPredictUnlimited && *req.MaxTokens != numPredictFillContext) {
+		return req
+	}
+	n := s.params.LimitsFor(model).MaxTokens
+	if *req.MaxTokens == numPredictFillContext {
+		if info, err := s.models.Show(model); err == nil && info.Metadata != nil && info.Metadata.ContextLength > 0 {
+			if ctx := info.Metadata.ContextLength; n <= 0 || ctx < n {
+				n = ctx
+			}
+		}
+	}
+	req.MaxTokens = nil
+	if n > 0 {
+		req.MaxTokens = &n
+	}
+	return req
 }
 
 func (s *Server) handleOllamaGenerate(w http.ResponseWriter, r *http.Request) {
@@ -91,78 +192,101 @@ func (s *Server) handleOllamaGenerate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	handle, err := s.pool.Acquire(req.Model, defaultLoadOpts())
+	if !s.allowModel(w, r, req.Model) {
+		return
+	}
+	params, ok := s.resolveParams(w, req.Model, s.ollamaParamRequest(req.Model, req.Options))
+	if !ok {
+		return
+	}
+
+	handle, err := s.pool.Acquire(req.Model, s.loadOpts(req.Model))
 	if err != nil {
 		writeError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 	defer handle.Release()
 
-	params := defaultGenParams()
-	tokenCh, err := handle.Model().Generate(r.Context(), req.Prompt, params)
+	tokens, err := handle.Generate(r.Context(), req.Prompt, params)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
+	s.meterTokens(r.Context(), req.Model, tokens)
 
 	stream := req.Stream == nil || *req.Stream
 
 	if stream {
-		s.streamOllamaGenerate(w, tokenCh, req.Model)
+		s.streamOllamaGenerate(w, tokens, req.Model)
 	} else {
-		s.nonStreamOllamaGenerate(w, tokenCh, req.Model)
+		s.nonStreamOllamaGenerate(w, tokens, req.Model)
 	}
 }
 
-func (s *Server) streamOllamaGenerate(w http.ResponseWriter, tokenCh <-chan domain.Token, model string) {
+func (s *Server) streamOllamaGenerate(w http.ResponseWriter, tokens *engine.TokenStream, model string) {
 	w.Header().Set("Content-Type", "application/x-ndjson")
 	w.WriteHeader(http.StatusOK)
 	flusher, _ := w.(http.Flusher)
 
 	enc := json.NewEncoder(w)
-	for tok := range tokenCh {
-		enc.Encode(map[string]interface{}{
-			"model":      model,
-			"created_at": time.Now().Format(time.RFC3339Nano),
-			"response":   tok.Text,
-			"done":       false,
+	for tok := range tokens.Tokens() {
+		enc.Encode(ollamaGenerateResponse{
+			Model:     model,
+			CreatedAt: time.Now().Format(time.RFC3339Nano),
+			Response:  tok.Text,
 		})
 		if flusher != nil {
 			flusher.Flush()
 		}
 	}
+	if tokens.Err() != nil {
+		return // client gone or stream aborted — never report done
+	}
 
 	// Final
-	enc.Encode(map[string]interface{}{
-		"model":      model,
-		"created_at": time.Now().Format(time.RFC3339Nano),
-		"response":   "",
-		"done":       true,
+	enc.Encode(ollamaGenerateResponse{
+		Model:     model,
+		CreatedAt: time.Now().Format(time.RFC3339Nano),
+		Done:      true,
 	})
 	if flusher != nil {
 		flusher.Flush()
 	}
 }
 
-func (s *Server) nonStreamOllamaGenerate(w http.ResponseWriter, tokenCh <-chan domain.Token, model string) {
+func (s *Server) nonStreamOllamaGenerate(w http.ResponseWriter, tokens *engine.TokenStream, model string) {
 	var response string
-	for tok := range tokenCh {
+	for tok := range tokens.Tokens() {
 		response += tok.Text
 	}
-	writeJSON(w, http.StatusOK, map[string]interface{}{
-		"model":      model,
-		"created_at": time.Now().Format(time.RFC3339Nano),
-		"response":   response,
-		"done":       true,
+	if err := tokens.Err(); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, ollamaGenerateResponse{
+		Model:     model,
+		CreatedAt: time.Now().Format(time.RFC3339Nano),
+		Response:  response,
+		Done:      true,
 	})
 }
 
 // --- /api/chat (chat generation) ---
 
 type ollamaChatRequest struct {
-	Model    string        `json:"model"`
-	Messages []chatMessage `json:"messages"`
-	Stream   *bool         `json:"stream,omitempty"`
+	Model    string         `json:"model"`
+	Messages []chatMessage  `json:"messages"`
+	Stream   *bool          `json:"stream,omitempty"`
+	Options  *ollamaOptions `json:"options,omitempty"`
+}
+
+// ollamaChatResponse is one line of a streamed chat, or the whole response
+// when stream is false.
+type ollamaChatResponse struct {
+	Model     string      `json:"model"`
+	CreatedAt string      `json:"created_at"`
+	Message   chatMessage `json:"message"`
+	Done      bool        `json:"done"`
 }
 
 func (s *Server) handleOllamaChat(w http.ResponseWriter, r *http.Request) {
@@ -172,7 +296,15 @@ func (s *Server) handleOllamaChat(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	handle, err := s.pool.Acquire(req.Model, defaultLoadOpts())
+	if !s.allowModel(w, r, req.Model) {
+		return
+	}
+	params, ok := s.resolveParams(w, req.Model, s.ollamaParamRequest(req.Model, req.Options))
+	if !ok {
+		return
+	}
+
+	handle, err := s.pool.Acquire(req.Model, s.loadOpts(req.Model))
 	if err != nil {
 		writeError(w, http.StatusBadRequest, err.Error())
 		return
@@ -183,70 +315,67 @@ func (s *Server) handleOllamaChat(w http.ResponseWriter, r *http.Request) {
 	for i, m := range req.Messages {
 		chatMsgs[i] = engine.ChatMessage{Role: m.Role, Content: m.Content}
 	}
-	params := defaultGenParams()
-	tokenCh, err := handle.Model().Chat(r.Context(), chatMsgs, params)
+	tokens, err := handle.Chat(r.Context(), chatMsgs, params)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
+	s.meterTokens(r.Context(), req.Model, tokens)
 
 	stream := req.Stream == nil || *req.Stream
 
 	if stream {
-		s.streamOllamaChat(w, tokenCh, req.Model)
+		s.streamOllamaChat(w, tokens, req.Model)
 	} else {
-		s.nonStreamOllamaChat(w, tokenCh, req.Model)
+		s.nonStreamOllamaChat(w, tokens, req.Model)
 	}
 }
 
-func (s *Server) streamOllamaChat(w http.ResponseWriter, tokenCh <-chan domain.Token, model string) {
+func (s *Server) streamOllamaChat(w http.ResponseWriter, tokens *engine.TokenStream, model string) {
 	w.Header().Set("Content-Type", "application/x-ndjson")
 	w.WriteHeader(http.StatusOK)
 	flusher, _ := w.(http.Flusher)
 
 	enc := json.NewEncoder(w)
-	for tok := range tokenCh {
-		enc.Encode(map[string]interface{}{
-			"model":      model,
-			"created_at": time.Now().Format(time.RFC3339Nano),
-			"message": map[string]interface{}{
-				"role":    "assistant",
-				"content": tok.Text,
-			},
-			"done": false,
+	for tok := range tokens.Tokens() {
+		enc.Encode(ollamaChatResponse{
+			Model:     model,
+			CreatedAt: time.Now().Format(time.RFC3339Nano),
+			Message:   chatMessage{Role: "assistant", Content: tok.Text},
 		})
 		if flusher != nil {
 			flusher.Flush()
 		}
 	}
+	if tokens.Err() != nil {
+		return // client gone or stream aborted — never report done
+	}
 
-	enc.Encode(map[string]interface{}{
-		"model":      model,
-		"created_at": time.Now().Format(time.RFC3339Nano),
-		"message": map[string]interface{}{
-			"role":    "assistant",
-			"content": "",
-		},
-		"done": true,
+	enc.Encode(ollamaChatResponse{
+		Model:     model,
+		CreatedAt: time.Now().Format(time.RFC3339Nano),
+		Message:   chatMessage{Role: "assistant"},
+		Done:      true,
 	})
 	if flusher != nil {
 		flusher.Flush()
 	}
 }
 
-func (s *Server) nonStreamOllamaChat(w http.ResponseWriter, tokenCh <-chan domain.Token, model string) {
+func (s *Server) nonStreamOllamaChat(w http.ResponseWriter, tokens *engine.TokenStream, model string) {
 	var content string
-	for tok := range tokenCh {
+	for tok := range tokens.Tokens() {
 		content += tok.Text
 	}
-	writeJSON(w, http.StatusOK, map[string]interface{}{
-		"model":      model,
-		"created_at": time.Now().Format(time.RFC3339Nano),
-		"message": map[string]interface{}{
-			"role":    "assistant",
-			"content": content,
-		},
-		"done": true,
+	if err := tokens.Err(); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, ollamaChatResponse{
+		Model:     model,
+		CreatedAt: time.Now().Format(time.RFC3339Nano),
+		Message:   chatMessage{Role: "assistant", Content: content},
+		Done:      true,
 	})
 }
 
@@ -272,9 +401,7 @@ func (s *Server) handleOllamaPull(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	writeJSON(w, http.StatusOK, map[string]interface{}{
-		"status": "success",
-	})
+	writeJSON(w, http.StatusOK, statusResponse{Status: "success"})
 }
 
 // --- /api/delete ---
@@ -303,13 +430,6 @@ func (s *Server) handleOllamaDelete(w http.ResponseWriter, r *http.Request) {
 func (s *Server) handleOllamaPs(w http.ResponseWriter, r *http.Request) {
 	loaded := s.pool.LoadedModels()
 
-	type ollamaPs struct {
-		Name      string    `json:"name"`
-		Size      int64     `json:"size"`
-		Processor string    `json:"processor"`
-		ExpiresAt time.Time `json:"expires_at"`
-	}
-
 	models := make([]ollamaPs, len(loaded))
 	for i, m := range loaded {
 		models[i] = ollamaPs{
@@ -320,7 +440,17 @@ func (s *Server) handleOllamaPs(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	writeJSON(w, http.StatusOK, map[string]interface{}{
-		"models": models,
-	})
+	writeJSON(w, http.StatusOK, ollamaPsResponse{Models: models})
+}
+
+// ollamaPsResponse is the /api/ps response.
+type ollamaPsResponse struct {
+	Models []ollamaPs `json:"models"`
+}
+
+type ollamaPs struct {
+	Name      string    `json:"name"`
+	Size      int64     `json:"size"`
+	Processor string    `json:"processor"`
+	ExpiresAt time.Time `json:"expires_at"`
 }
@@ -0,0 +1,327 @@
+package credit
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
+)
+
+// ─── Billing ────────────────────────────────────────────────────────────────
+// External billing providers report fiat payments through a signed webhook.
+// A successful payment tops up the ledger — DEBIT billing, CREDIT
+// node_balance, labelled with the buyer's namespace — and issues an invoice;
+// a refund reverses the entries and marks the invoice refunded.
+//
+// Deliveries are idempotent on the provider's event ID. Each step checks
+// whether it already happened, so a delivery interrupted half way is
+// completed by the provider's retry rather than applied twice.
+
+// SignatureHeader carries the webhook signature:
+//
+//	X-Tutu-Signature: t=<unix seconds>,v1=<hex HMAC-SHA256 of "<t>.<body>">
+//
+// Several v1 values may be sent while the provider rotates its secret.
+const SignatureHeader = "X-Tutu-Signature"
+
+// BillingConfig configures webhook ingestion.
+type BillingConfig struct {
+	// Secrets returns the accepted signing secrets: the current one and,
+	// during a rotation, the previous one. None disables the webhook.
+	Secrets   func() []string
+	Tolerance time.Duration // max clock skew of a signature timestamp (default 5m)
+	Now       func() time.Time
+}
+
+// Billing ingests payment webhooks into the ledger and invoice store.
+// Thread-safe.
+type Billing struct {
+	mu     sync.Mutex // serializes deliveries so retries cannot race
+	svc    *Service
+	config BillingConfig
+}
+
+// NewBilling creates webhook ingestion on top of a credit service.
+func NewBilling(svc *Service, cfg BillingConfig) *Billing {
+	if cfg.Tolerance <= 0 {
+		cfg.Tolerance = 5 * time.Minute
+	}
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+	return &Billing{svc: svc, config: cfg}
+}
+
+// SignWebhook returns the signature header value for body signed at t.
+func SignWebhook(secret string, t time.Time, body []byte) string {
+	ts := strconv.FormatInt(t.Unix(), 10)
+	return "t=" + ts + ",v1=" + webhookMAC(secret, ts, body)
+}
+
+func webhookMAC(secret, ts string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature checks a signature header against body.
+func (b *Billing) VerifySignature(header string, body []byte) error {
+	var secrets []string
+	if b.config.Secrets != nil {
+		secrets = b.config.Secrets()
+	}
+	if len(secrets) == 0 {
+		return domain.ErrBillingDisabled
+	}
+
+	var ts string
+	var sigs []string
+	for _, part := range strings.Split(header, ",") {
+		k, v, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch k {
+		case "t":
+			ts = v
+		case "v1":
+			sigs = append(sigs, v)
+		}
+	}
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil || len(sigs) == 0 {
+		return fmt.Errorf("%w: malformed %s header", domain.ErrWebhookSignature, SignatureHeader)
+	}
+	skew := b.config.Now().Sub(time.Unix(unix, 0))
+	if skew > b.config.Tolerance || skew < -b.config.Tolerance {
+		return fmt.Errorf("%w: timestamp outside the %s tolerance", domain.ErrWebhookSignature, b.config.Tolerance)
+	}
+	for _, secret := range secrets {
+		want := webhookMAC(secret, ts, body)
+		for _, sig := range sigs {
+			if hmac.Equal([]byte(sig), []byte(want)) {
+				return nil
+			}
+		}
+	}
+	return domain.ErrWebhookSignature
+}
+
+// Ingest verifies and applies one webhook delivery. It returns the invoice
+// the event created or refunded; duplicate is true when the event had
+// already been processed, in which case nothing changes.
+func (b *Billing) Ingest(body []byte, signature string) (inv *domain.Invoice, duplicate bool, err error) {
+	if err := b.VerifySignature(signature, body); err != nil {
+		return nil, false, err
+	}
+	var e domain.PaymentEvent
+	if err := json.Unmarshal(body, &e); err != nil {
+		return nil, false, fmt.Errorf("%w: %v", domain.ErrPaymentEventInvalid, err)
+	}
+	if err := validatePaymentEvent(&e); err != nil {
+		return nil, false, err
+	}
+	e.ReceivedAt = b.config.Now()
+	if e.OccurredAt.IsZero() {
+		e.OccurredAt = e.ReceivedAt
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	db := b.svc.db
+	done, err := db.HasPaymentEvent(e.ID)
+	if err != nil {
+		return nil, false, fmt.Errorf("billing: lookup event: %w", err)
+	}
+	invoiceID := domain.InvoiceID(e.ID)
+	if e.Type == domain.PaymentRefunded {
+		invoiceID = domain.InvoiceID(e.PaymentID)
+	}
+	if done {
+		inv, err := db.GetInvoice(invoiceID)
+		return inv, true, err
+	}
+
+	switch e.Type {
+	case domain.PaymentSucceeded:
+		inv, err = b.applyPaymentLocked(e)
+	case domain.PaymentRefunded:
+		inv, err = b.applyRefundLocked(e)
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	if _, err := db.InsertPaymentEvent(e); err != nil {
+		return nil, false, fmt.Errorf("billing: record event: %w", err)
+	}
+	return inv, false, nil
+}
+
+// applyPaymentLocked tops up the ledger and issues the invoice. Caller must
+// hold b.mu.
+func (b *Billing) applyPaymentLocked(e domain.PaymentEvent) (*domain.Invoice, error) {
+	inv := domain.Invoice{
+		ID:          domain.InvoiceID(e.ID),
+		EventID:     e.ID,
+		Provider:    e.Provider,
+		Namespace:   e.Namespace,
+		Customer:    e.Customer,
+		AmountMinor: e.AmountMinor,
+		Currency:    e.Currency,
+		Credits:     e.Credits,
+		Reference:   e.Reference,
+		Status:      domain.InvoicePaid,
+		IssuedAt:    e.OccurredAt,
+	}
+	paid, err := b.svc.db.LedgerHasTask(inv.ID, domain.TxTopUp)
+	if err != nil {
+		return nil, fmt.Errorf("billing: check ledger: %w", err)
+	}
+	if !paid {
+		reason := fmt.Sprintf("%s payment %s", e.Provider, e.ID)
+		if err := b.svc.TopUp(e.Namespace, e.Credits, inv.ID, reason); err != nil {
+			return nil, err
+		}
+	}
+	if err := b.svc.db.UpsertInvoice(inv); err != nil {
+		return nil, fmt.Errorf("billing: store invoice: %w", err)
+	}
+	return &inv, nil
+}
+
+// applyRefundLocked takes a refunded purchase's credits back. Caller must
+// hold b.mu.
+func (b *Billing) applyRefundLocked(e domain.PaymentEvent) (*domain.Invoice, error) {
+	inv, err := b.svc.db.GetInvoice(domain.InvoiceID(e.PaymentID))
+	if err != nil {
+		return nil, fmt.Errorf("billing: lookup invoice: %w", err)
+	}
+	if inv == nil {
+		return nil, fmt.Errorf("%w: no invoice for payment %s", domain.ErrInvoiceNotFound, e.PaymentID)
+	}
+	refunded, err := b.svc.db.LedgerHasTask(inv.ID, domain.TxRefund)
+	if err != nil {
+		return nil, fmt.Errorf("billing: check ledger: %w", err)
+	}
+	if inv.Status == domain.InvoiceRefunded && refunded {
+		return nil, fmt.Errorf("%w: %s", domain.ErrInvoiceRefunded, inv.ID)
+	}
+	if !refunded {
+		reason := fmt.Sprintf("%s refund %s", e.Provider, e.ID)
+		if err := b.svc.Refund(inv.Namespace, inv.Credits, inv.ID, reason); err != nil {
+			return nil, err
+		}
+	}
+	inv.Status = domain.InvoiceRefunded
+	inv.RefundedAt = e.OccurredAt
+	if err := b.svc.db.UpsertInvoice(*inv); err != nil {
+		return nil, fmt.Errorf("billing: store invoice: %w", err)
+	}
+	return inv, nil
+}
+
+// validatePaymentEvent checks a decoded event and fills in defaults.
+func validatePaymentEvent(e *domain.PaymentEvent) error {
+	if e.ID == "" {
+		return fmt.Errorf("%w: id is required", domain.ErrPaymentEventInvalid)
+	}
+	if e.Provider == "" {
+		e.Provider = "external"
+	}
+	switch e.Type {
+	case domain.PaymentSucceeded:
+		if e.Credits <= 0 {
+			return fmt.Errorf("%w: credits must be positive, got %d", domain.ErrPaymentEventInvalid, e.Credits)
+		}
+		if e.AmountMinor < 0 || e.Currency == "" {
+			return fmt.Errorf("%w: amount_minor and currency are required", domain.ErrPaymentEventInvalid)
+		}
+		e.Currency = strings.ToUpper(e.Currency)
+		if e.Namespace == "" {
+			e.Namespace = domain.DefaultNamespace
+		}
+	case domain.PaymentRefunded:
+		if e.PaymentID == "" {
+			return fmt.Errorf("%w: payment_id is required for refunds", domain.ErrPaymentEventInvalid)
+		}
+	default:
+		return fmt.Errorf("%w: unknown type %q", domain.ErrPaymentEventInvalid, e.Type)
+	}
+	return nil
+}
+
+// Invoice returns one invoice.
+func (b *Billing) Invoice(id string) (*domain.Invoice, error) {
+	inv, err := b.svc.db.GetInvoice(id)
+	if err != nil {
+		return nil, err
+	}
+	if inv == nil {
+		return nil, fmt.Errorf("%w: %s", domain.ErrInvoiceNotFound, id)
+	}
+	return inv, nil
+}
+
+// Invoices returns a namespace's invoices, newest first. An empty namespace
+// lists all of them.
+func (b *Billing) Invoices(namespace string, limit int) ([]domain.Invoice, error) {
+	return b.svc.db.ListInvoices(namespace, limit)
+}
+
+// ─── Ledger Entries ─────────────────────────────────────────────────────────
+
+// TopUp credits the node with purchased credits: DEBIT billing, CREDIT
+// node_balance. ref identifies the purchase and is stored as the entries'
+// task ID so the top-up can be found again.
+func (s *Service) TopUp(namespace string, amount int64, ref, reason string) error {
+	if amount <= 0 {
+		return fmt.Errorf("top-up amount must be positive, got %d", amount)
+	}
+	return s.transfer(domain.TxTopUp, "billing", "node_balance", namespace, amount, ref, reason)
+}
+
+// Refund reverses a top-up: DEBIT node_balance, CREDIT billing. The fiat is
+// already returned, so the credits are taken back even if that leaves the
+// balance negative.
+func (s *Service) Refund(namespace string, amount int64, ref, reason string) error {
+	if amount <= 0 {
+		return fmt.Errorf("refund amount must be positive, got %d", amount)
+	}
+	return s.transfer(domain.TxRefund, "node_balance", "billing", namespace, amount, ref, reason)
+}
+
+// transfer writes a matched DEBIT/CREDIT pair moving amount from one
+// account to another.
+func (s *Service) transfer(tx domain.TransactionType, from, to, namespace string, amount int64, ref, reason string) error {
+	fromBal, err := s.db.CreditBalance(from)
+	if err != nil {
+		return fmt.Errorf("get %s balance: %w", from, err)
+	}
+	toBal, err := s.db.CreditBalance(to)
+	if err != nil {
+		return fmt.Errorf("get %s balance: %w", to, err)
+	}
+	now := time.Now()
+	for _, e := range []domain.LedgerEntry{
+		{EntryType: domain.EntryDebit, Account: from, Balance: fromBal - amount},
+		{EntryType: domain.EntryCredit, Account: to, Balance: toBal + amount},
+	} {
+		e.Timestamp = now
+		e.Type = tx
+		e.Amount = amount
+		e.TaskID = ref
+		e.Description = reason
+		e.Namespace = namespace
+		if _, err := s.db.InsertLedgerEntry(e); err != nil {
+			return fmt.Errorf("%s %s: %w", strings.ToLower(string(e.EntryType)), e.Account, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package credit
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
+)
+
+func newTestBilling(t *testing.T) (*Billing, *Service) {
+	t.Helper()
+	svc := NewService(newTestDB(t))
+	return NewBilling(svc, BillingConfig{Secrets: func() []string { return []string{"new", "old"} }}), svc
+}
+
+func ingest(b *Billing, secret, body string) (*domain.Invoice, bool, error) {
+	return b.Ingest([]byte(body), SignWebhook(secret, time.Now(), []byte(body)))
+}
+
+const paymentBody = `{"id":"evt_1","provider":"stripe","type":"payment.succeeded","namespace":"search",
+	"customer":"cus_9","amount_minor":2500,"currency":"eur","credits":250,"reference":"in_77"}`
+
+func TestBilling_VerifySignature(t *testing.T) {
+	b, _ := newTestBilling(t)
+	body := []byte(`{}`)
+	now := time.Now()
+
+	tests := []struct {
+		name   string
+		header string
+		ok     bool
+	}{
+		{"current secret", SignWebhook("new", now, body), true},
+		{"previous secret", SignWebhook("old", now, body), true},
+		{"any of several signatures", SignWebhook("x", now, body) + ",v1=" + strings.Split(SignWebhook("new", now, body), "v1=")[1], true},
+		{"wrong secret", SignWebhook("x", now, body), false},
+		{"stale timestamp", SignWebhook("new", now.Add(-10*time.Minute), body), false},
+		{"malformed", "v1=abc", false},
+		{"missing", "", false},
+	}
+	for _, tt := range tests {
+		err := b.VerifySignature(tt.header, body)
+		if (err == nil) != tt.ok {
+			t.Errorf("%s: err = %v, want ok=%v", tt.name, err, tt.ok)
+		}
+		if err != nil && !errors.Is(err, domain.ErrWebhookSignature) {
+			t.Errorf("%s: err = %v, want ErrWebhookSignature", tt.name, err)
+		}
+	}
+	if err := b.VerifySignature(SignWebhook("new", now, []byte(`{"x":1}`)), body); err == nil {
+		t.Error("signature over another body accepted")
+	}
+
+	disabled := NewBilling(b.svc, BillingConfig{})
+	if err := disabled.VerifySignature(SignWebhook("new", now, body), body); !errors.Is(err, domain.ErrBillingDisabled) {
+		t.Errorf("no secret: err = %v, want ErrBillingDisabled", err)
+	}
+}
+
+func TestBilling_PaymentTopsUpOnce(t *testing.T) {
+	b, svc := newTestBilling(t)
+
+	inv, dup, err := ingest(b, "new", paymentBody)
+	if err != nil || dup {
+		t.Fatalf("Ingest = %v, dup %v", err, dup)
+	}
+	if inv.ID != "inv-evt_1" || inv.Status != domain.InvoicePaid || inv.Currency != "EUR" ||
+		inv.Credits != 250 || inv.Namespace != "search" || inv.Reference != "in_77" {
+		t.Errorf("invoice = %+v", inv)
+	}
+
+	// Redelivery changes nothing
+	inv, dup, err = ingest(b, "new", paymentBody)
+	if err != nil || !dup || inv == nil || inv.ID != "inv-evt_1" {
+		t.Fatalf("redelivery = %+v, dup %v, %v", inv, dup, err)
+	}
+	if bal, _ := svc.Balance(); bal != 250 {
+		t.Errorf("balance = %d, want 250", bal)
+	}
+	entries, _ := svc.History(10)
+	if len(entries) != 1 || entries[0].Type != domain.TxTopUp || entries[0].Namespace != "search" ||
+		entries[0].TaskID != "inv-evt_1" {
+		t.Errorf("ledger = %+v", entries)
+	}
+}
+
+func TestBilling_CompletesInterruptedDelivery(t *testing.T) {
+	b, svc := newTestBilling(t)
+	// The credits landed but the node stopped before issuing the invoice
+	if err := svc.TopUp("search", 250, "inv-evt_1", "stripe payment evt_1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, dup, err := ingest(b, "new", paymentBody); err != nil || dup {
+		t.Fatalf("retry = dup %v, %v", dup, err)
+	}
+	if bal, _ := svc.Balance(); bal != 250 {
+		t.Errorf("balance = %d, want 250 (top-up not repeated)", bal)
+	}
+	if _, err := b.Invoice("inv-evt_1"); err != nil {
+		t.Errorf("Invoice: %v", err)
+	}
+}
+
+func TestBilling_Refund(t *testing.T) {
+	b, svc := newTestBilling(t)
+	ingest(b, "new", paymentBody)
+
+	refund := `{"id":"evt_2","provider":"stripe","type":"payment.refunded","payment_id":"evt_1"}`
+	inv, _, err := ingest(b, "new", refund)
+	if err != nil {
+		t.Fatalf("refund: %v", err)
+	}
+	if inv.Status != domain.InvoiceRefunded || inv.RefundedAt.IsZero() {
+		t.Errorf("invoice = %+v", inv)
+	}
+	if bal, _ := svc.Balance(); bal != 0 {
+		t.Errorf("balance = %d, want 0", bal)
+	}
+
+	// A second refund of the same payment is a conflict; a redelivery is not
+	if _, dup, err := ingest(b, "new", refund); err != nil || !dup {
+		t.Errorf("refund redelivery = dup %v, %v", dup, err)
+	}
+	again := `{"id":"evt_3","type":"payment.refunded","payment_id":"evt_1"}`
+	if _, _, err := ingest(b, "new", again); !errors.Is(err, domain.ErrInvoiceRefunded) {
+		t.Errorf("second refund: err = %v, want ErrInvoiceRefunded", err)
+	}
+	unknown := `{"id":"evt_4","type":"payment.refunded","payment_id":"evt_nope"}`
+	if _, _, err := ingest(b, "new", unknown); !errors.Is(err, domain.ErrInvoiceNotFound) {
+		t.Errorf("unknown payment: err = %v, want ErrInvoiceNotFound", err)
+	}
+}
+
+func TestBilling_RejectsInvalidEvents(t *testing.T) {
+	b, _ := newTestBilling(t)
+	for _, body := range []string{
+		`not json`,
+		`{"type":"payment.succeeded","credits":1,"currency":"USD"}`,
+		`{"id":"e","type":"payment.succeeded","credits":0,"currency":"USD"}`,
+		`{"id":"e","type":"payment.succeeded","credits":5}`,
+		`{"id":"e","type":"payment.refunded"}`,
+		`{"id":"e","type":"charge.disputed"}`,
+	} {
+		if _, _, err := ingest(b, "new", body); !errors.Is(err, domain.ErrPaymentEventInvalid) {
+			t.Errorf("%s: err = %v, want ErrPaymentEventInvalid", body, err)
+		}
+	}
+}
@@ -4,17 +4,23 @@
 package credit
 
 import (
+	"errors"
 	"fmt"
 	"math"
 	"time"
 
 	"github.com/tutu-network/tutu/internal/domain"
+	"github.com/tutu-network/tutu/internal/infra/journal"
+	"github.com/tutu-network/tutu/internal/infra/reputation"
 	"github.com/tutu-network/tutu/internal/infra/sqlite"
+	"github.com/tutu-network/tutu/internal/security"
 )
 
 // Service manages the credit economy.
 type Service struct {
-	db *sqlite.DB
+	db         *sqlite.DB
+	journal    *journal.Journal    // settles attested payments exactly once (nil = unguarded)
+	reputation *reputation.Tracker // charged for disputed results (nil = not tracked)
 }
 
 // NewService creates a credit service.
@@ -22,11 +28,28 @@ func NewService(db *sqlite.DB) *Service {
 	return &Service{db: db}
 }
 
+// SetJournal routes attested payments through the task journal, so each
+// task is verified and paid at most once.
+func (s *Service) SetJournal(j *journal.Journal) {
+	s.journal = j
+}
+
+// SetReputation charges disputed attestations against the executor's
+// reputation.
+func (s *Service) SetReputation(t *reputation.Tracker) {
+	s.reputation = t
+}
+
 // Balance returns the current node balance.
 func (s *Service) Balance() (int64, error) {
 	return s.db.CreditBalance("node_balance")
 }
 
+// EarnedSince returns the credits the node earned at or after since.
+func (s *Service) EarnedSince(since time.Time) (int64, error) {
+	return s.db.CreditedSince("node_balance", since)
+}
+
 // Earn records credits earned from completing a task.
 // Creates matched DEBIT (system_pool) and CREDIT (node_balance) entries.
 func (s *Service) Earn(amount int64, taskID, reason string) error {
@@ -35,6 +58,7 @@ func (s *Service) Earn(amount int64, taskID, reason string) error {
 	}
 
 	now := time.Now()
+	namespace := s.taskNamespace(taskID)
 
 	// Get current balances
 	poolBal, err := s.db.CreditBalance("system_pool")
@@ -56,6 +80,7 @@ func (s *Service) Earn(amount int64, taskID, reason string) error {
 		TaskID:      taskID,
 		Description: reason,
 		Balance:     poolBal - amount,
+		Namespace:   namespace,
 	})
 	if err != nil {
 		return fmt.Errorf("debit system_pool: %w", err)
@@ -71,6 +96,7 @@ func (s *Service) Earn(amount int64, taskID, reason string) error {
 		TaskID:      taskID,
 		Description: reason,
 		Balance:     nodeBal + amount,
+		Namespace:   namespace,
 	})
 	if err != nil {
 		return fmt.Errorf("credit node_balance: %w", err)
@@ -79,8 +105,14 @@ func (s *Service) Earn(amount int64, taskID, reason string) error {
 	return nil
 }
 
-// Spend records credits spent for consuming a service.
+// Spend records credits spent for consuming a service. The entries are
+// labelled with the namespace of the task, if the node knows it.
 func (s *Service) Spend(amount int64, taskID, reason string) error {
+	return s.SpendIn(s.taskNamespace(taskID), amount, taskID, reason)
+}
+
+// SpendIn records credits spent on behalf of a namespace.
+func (s *Service) SpendIn(namespace string, amount int64, taskID, reason string) error {
 	if amount <= 0 {
 		return fmt.Errorf("spend amount must be positive, got %d", amount)
 	}
@@ -106,6 +138,7 @@ func (s *Service) Spend(amount int64, taskID, reason string) error {
 		TaskID:      taskID,
 		Description: reason,
 		Balance:     nodeBal - amount,
+		Namespace:   namespace,
 	})
 	if err != nil {
 		return err
@@ -121,10 +154,84 @@ func (s *Service) Spend(amount int64, taskID, reason string) error {
 		TaskID:      taskID,
 		Description: reason,
 		Balance:     poolBal + amount,
+		Namespace:   namespace,
 	})
 	return err
 }
 
+// taskNamespace returns the namespace a task was submitted in ("" for
+// network work and tasks the node has no record of).
+func (s *Service) taskNamespace(taskID string) string {
+	if taskID == "" {
+		return ""
+	}
+	task, err := s.db.GetTask(taskID)
+	if err != nil || task == nil {
+		return ""
+	}
+	return task.Namespace
+}
+
+// ─── Attested Payments ──────────────────────────────────────────────────────
+
+// PayAttested verifies the executor's signed attestation before paying for a
+// task. executor is the hex public key of the node the task was assigned
+// to: the attestation must name it and be signed with its key, so a node
+// cannot attest to work it was never given. Only attestations that pass are
+// persisted and paid; the stored record is the evidence used if the result
+// is later disputed.
+//
+// With a journal attached, the task is journaled as VERIFIED and settled
+// exactly once: paying again returns domain.ErrTaskAlreadyPaid, and a
+// payment interrupted before it was journaled is not repeated.
+func (s *Service) PayAttested(att domain.Attestation, executor string, amount int64) error {
+	if att.NodeID != executor {
+		return fmt.Errorf("verify attestation: task %s signed by %s, assigned to %s: %w",
+			att.TaskID, att.NodeID, executor, domain.ErrAttestationMismatch)
+	}
+	if err := security.VerifyAttestation(att); err != nil {
+		return fmt.Errorf("verify attestation: %w", err)
+	}
+	if err := s.db.InsertAttestation(att, true); err != nil {
+		return fmt.Errorf("record attestation: %w", err)
+	}
+	reason := "attested task " + att.TaskID
+	if s.journal == nil {
+		return s.Spend(amount, att.TaskID, reason)
+	}
+	// A retried payment was verified the first time round
+	if err := s.journal.Verify(att.TaskID, att.NodeID, att.OutputDigest); err != nil &&
+		!errors.Is(err, domain.ErrTaskEventOutOfOrder) {
+		return err
+	}
+	return s.journal.Settle(att.TaskID, att.NodeID, amount, func() error {
+		paid, err := s.db.LedgerHasTask(att.TaskID, domain.TxSpend)
+		if err != nil || paid {
+			return err
+		}
+		return s.Spend(amount, att.TaskID, reason)
+	})
+}
+
+// DisputeAttested flags a paid task's attestation as disputed and returns the
+// signed record. A task can be disputed once; a second dispute returns
+// domain.ErrAttestationDisputed. With a reputation tracker attached, the
+// disputed result counts as a failed, inaccurate task against the executor.
+func (s *Service) DisputeAttested(taskID, reason string) (*sqlite.AttestationRecord, error) {
+	if err := s.db.MarkAttestationDisputed(taskID, reason); err != nil {
+		return nil, fmt.Errorf("dispute attestation: %w", err)
+	}
+	rec, err := s.db.GetAttestation(taskID)
+	if err != nil || rec == nil || s.reputation == nil {
+		return rec, err
+	}
+	s.reputation.GetOrRegister(rec.NodeID)
+	if err := s.reputation.RecordTask(rec.NodeID, reputation.TaskOutcome{Disputed: true}); err != nil {
+		return rec, fmt.Errorf("dispute attestation: %w", err)
+	}
+	return rec, nil
+}
+
 // History returns recent ledger entries for the node.
 func (s *Service) History(limit int) ([]domain.LedgerEntry, error) {
 	return s.db.LedgerEntries("node_balance", limit)
@@ -165,5 +272,23 @@ func EarningAmount(taskType domain.TaskType, tokensProcessed int, streakDays int
 	return int64(result)
 }
 
+// SeedingCreditsPerGiB is the base reward for bandwidth contributed to P2P
+// model distribution.
+const SeedingCreditsPerGiB = 2.0
+
+// SeedingEarningAmount computes credits earned for uploading bytes to peers.
+// Contributions below one credit earn nothing and should be carried over.
+func SeedingEarningAmount(bytesUploaded int64, reputation float64) int64 {
+	if bytesUploaded <= 0 {
+		return 0
+	}
+	repBonus := 1.0 + (reputation - 0.5)
+	if repBonus < 0.5 {
+		repBonus = 0.5
+	}
+	gib := float64(bytesUploaded) / (1 << 30)
+	return int64(gib * SeedingCreditsPerGiB * repBonus)
+}
+
 // MaxHourlyEarning is the anti-fraud earning cap per node per hour.
 const MaxHourlyEarning int64 = 100
@@ -1,10 +1,15 @@
 package credit
 
 import (
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/tutu-network/tutu/internal/domain"
+	"github.com/tutu-network/tutu/internal/infra/journal"
+	"github.com/tutu-network/tutu/internal/infra/reputation"
 	"github.com/tutu-network/tutu/internal/infra/sqlite"
+	"github.com/tutu-network/tutu/internal/security"
 )
 
 func newTestDB(t *testing.T) *sqlite.DB {
@@ -102,6 +107,30 @@ func TestService_Spend(t *testing.T) {
 	}
 }
 
+func TestService_SpendLabelsNamespace(t *testing.T) {
+	db := newTestDB(t)
+	svc := NewService(db)
+	svc.Earn(100, "t1", "earn first")
+
+	// Spending for a known task takes the task's namespace
+	db.InsertTask(domain.Task{ID: "agent-1", Type: domain.TaskAgent, Status: domain.TaskCompleted,
+		CreatedAt: time.Now(), Namespace: "search"})
+	if err := svc.Spend(10, "agent-1", "agent run"); err != nil {
+		t.Fatalf("Spend() error: %v", err)
+	}
+	if err := svc.SpendIn("ads", 5, "verify-1", "redundant result"); err != nil {
+		t.Fatalf("SpendIn() error: %v", err)
+	}
+
+	spent, err := db.SpentByNamespace()
+	if err != nil {
+		t.Fatalf("SpentByNamespace() error: %v", err)
+	}
+	if spent["search"] != 10 || spent["ads"] != 5 {
+		t.Errorf("SpentByNamespace = %v, want search:10 ads:5", spent)
+	}
+}
+
 func TestService_SpendInsufficientFunds(t *testing.T) {
 	db := newTestDB(t)
 	svc := NewService(db)
@@ -218,8 +247,184 @@ func TestEarningAmount_MinimumCredits(t *testing.T) {
 	}
 }
 
+func TestSeedingEarningAmount(t *testing.T) {
+	tests := []struct {
+		name  string
+		bytes int64
+		rep   float64
+		want  int64
+	}{
+		{"nothing uploaded", 0, 0.9, 0},
+		{"below one credit", 100 << 20, 0.5, 0},
+		{"one GiB neutral", 1 << 30, 0.5, 2},
+		{"ten GiB trusted", 10 << 30, 1.0, 30},
+		{"low reputation floor", 10 << 30, 0.0, 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SeedingEarningAmount(tt.bytes, tt.rep); got != tt.want {
+				t.Errorf("SeedingEarningAmount = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestMaxHourlyEarning(t *testing.T) {
 	if MaxHourlyEarning != 100 {
 		t.Errorf("MaxHourlyEarning = %d, want 100", MaxHourlyEarning)
 	}
 }
+
+// ─── Attested Payment Tests ─────────────────────────────────────────────────
+
+func newSignedAttestation(t *testing.T, kp *security.Keypair, taskID string) domain.Attestation {
+	t.Helper()
+	start := time.Now().Add(-2 * time.Second)
+	att := domain.Attestation{
+		TaskID:       taskID,
+		InputDigest:  security.Digest([]byte("prompt")),
+		OutputDigest: security.Digest([]byte("answer")),
+		StartedAt:    start,
+		CompletedAt:  start.Add(1500 * time.Millisecond),
+	}
+	kp.SignAttestation(&att)
+	return att
+}
+
+func TestService_PayAttested(t *testing.T) {
+	db := newTestDB(t)
+	svc := NewService(db)
+	_ = svc.Earn(50, "seed", "initial balance")
+
+	kp, _ := security.GenerateKeypair()
+	att := newSignedAttestation(t, kp, "task-attest-1")
+
+	if err := svc.PayAttested(att, kp.PublicKeyHex(), 10); err != nil {
+		t.Fatalf("PayAttested() error: %v", err)
+	}
+	bal, _ := svc.Balance()
+	if bal != 40 {
+		t.Errorf("balance = %d, want 40", bal)
+	}
+
+	rec, err := db.GetAttestation("task-attest-1")
+	if err != nil || rec == nil {
+		t.Fatalf("GetAttestation() = %v, %v", rec, err)
+	}
+	if !rec.Verified || rec.NodeID != kp.PublicKeyHex() {
+		t.Errorf("record = %+v, want verified from signer", rec)
+	}
+}
+
+func TestService_PayAttested_ExactlyOnce(t *testing.T) {
+	db := newTestDB(t)
+	svc := NewService(db)
+	j, err := journal.New(journal.DefaultConfig(), db)
+	if err != nil {
+		t.Fatalf("journal.New() error: %v", err)
+	}
+	svc.SetJournal(j)
+	_ = svc.Earn(50, "seed", "initial balance")
+
+	kp, _ := security.GenerateKeypair()
+	att := newSignedAttestation(t, kp, "task-once")
+	if err := svc.PayAttested(att, kp.PublicKeyHex(), 10); err != nil {
+		t.Fatalf("PayAttested() error: %v", err)
+	}
+	if err := svc.PayAttested(att, kp.PublicKeyHex(), 10); !errors.Is(err, domain.ErrTaskAlreadyPaid) {
+		t.Errorf("second PayAttested() = %v, want ErrTaskAlreadyPaid", err)
+	}
+	if bal, _ := svc.Balance(); bal != 40 {
+		t.Errorf("balance = %d, want 40 (paid once)", bal)
+	}
+
+	// Paid but not journaled (crash before PAID was written): settling
+	// again records the payment without spending twice
+	att2 := newSignedAttestation(t, kp, "task-crash")
+	_ = svc.Spend(10, "task-crash", "attested task task-crash")
+	if err := svc.PayAttested(att2, kp.PublicKeyHex(), 10); err != nil {
+		t.Fatalf("PayAttested() after crash: %v", err)
+	}
+	if bal, _ := svc.Balance(); bal != 30 {
+		t.Errorf("balance = %d, want 30", bal)
+	}
+	if st, _ := j.Task("task-crash"); !st.Paid {
+		t.Errorf("task-crash = %+v, want paid", st)
+	}
+}
+
+func TestService_PayAttested_RejectsForgery(t *testing.T) {
+	db := newTestDB(t)
+	svc := NewService(db)
+	_ = svc.Earn(50, "seed", "initial balance")
+
+	kp, _ := security.GenerateKeypair()
+	att := newSignedAttestation(t, kp, "task-forged")
+	att.OutputDigest = security.Digest([]byte("tampered"))
+
+	err := svc.PayAttested(att, kp.PublicKeyHex(), 10)
+	if !errors.Is(err, domain.ErrAttestationInvalid) {
+		t.Fatalf("PayAttested() error = %v, want ErrAttestationInvalid", err)
+	}
+	bal, _ := svc.Balance()
+	if bal != 50 {
+		t.Errorf("balance = %d, want 50 (no payment on forgery)", bal)
+	}
+	if rec, _ := db.GetAttestation("task-forged"); rec != nil {
+		t.Error("forged attestation should not be persisted")
+	}
+
+	// A valid signature from a node the task was not assigned to
+	other, _ := security.GenerateKeypair()
+	self := newSignedAttestation(t, other, "task-self-attested")
+	err = svc.PayAttested(self, kp.PublicKeyHex(), 10)
+	if !errors.Is(err, domain.ErrAttestationMismatch) {
+		t.Fatalf("PayAttested() by unassigned node = %v, want ErrAttestationMismatch", err)
+	}
+	if bal, _ := svc.Balance(); bal != 50 {
+		t.Errorf("balance = %d, want 50 (no payment to unassigned node)", bal)
+	}
+}
+
+func TestService_DisputeAttested(t *testing.T) {
+	db := newTestDB(t)
+	svc := NewService(db)
+	_ = svc.Earn(50, "seed", "initial balance")
+
+	rep := reputation.NewTracker(reputation.DefaultTrackerConfig())
+	svc.SetReputation(rep)
+
+	kp, _ := security.GenerateKeypair()
+	att := newSignedAttestation(t, kp, "task-disputed")
+	_ = svc.PayAttested(att, kp.PublicKeyHex(), 5)
+	before := rep.GetOrRegister(kp.PublicKeyHex()).Components
+
+	rec, err := svc.DisputeAttested("task-disputed", "output failed redundancy check")
+	if err != nil {
+		t.Fatalf("DisputeAttested() error: %v", err)
+	}
+	if !rec.Disputed || rec.DisputeReason == "" {
+		t.Errorf("record = %+v, want disputed with reason", rec)
+	}
+	if err := security.VerifyAttestation(rec.Attestation); err != nil {
+		t.Errorf("stored evidence should still verify: %v", err)
+	}
+	after := rep.Get(kp.PublicKeyHex()).Components
+	if after.Accuracy >= before.Accuracy {
+		t.Errorf("executor accuracy = %v after dispute, want below %v", after.Accuracy, before.Accuracy)
+	}
+	if after.Reliability >= before.Reliability {
+		t.Errorf("executor reliability = %v after dispute, want below %v", after.Reliability, before.Reliability)
+	}
+
+	if _, err := svc.DisputeAttested("task-disputed", "again"); !errors.Is(err, domain.ErrAttestationDisputed) {
+		t.Errorf("second DisputeAttested() = %v, want ErrAttestationDisputed", err)
+	}
+	if again := rep.Get(kp.PublicKeyHex()).Components; again.Accuracy != after.Accuracy {
+		t.Errorf("second dispute moved accuracy %v -> %v", after.Accuracy, again.Accuracy)
+	}
+
+	if _, err := svc.DisputeAttested("no-such-task", "x"); err == nil {
+		t.Error("DisputeAttested() on unknown task should fail")
+	}
+}
@@ -7,6 +7,9 @@ import (
 
 	"github.com/tutu-network/tutu/internal/app/engagement"
 	"github.com/tutu-network/tutu/internal/domain"
+	"github.com/tutu-network/tutu/internal/infra/governance"
+	"github.com/tutu-network/tutu/internal/infra/passive"
+	"github.com/tutu-network/tutu/internal/infra/selfheal"
 	"github.com/tutu-network/tutu/internal/infra/sqlite"
 )
 
@@ -538,6 +541,50 @@ func TestQuest_ActiveQuests(t *testing.T) {
 	}
 }
 
+func TestNetworkQuests_Progress(t *testing.T) {
+	db := testDB(t)
+	svc := engagement.NewQuestService(db)
+	expires := time.Now().Add(24 * time.Hour)
+	for _, q := range []domain.Quest{
+		{ID: "q-seed", Type: domain.QuestSeed, Target: 2, ExpiresAt: expires},
+		{ID: "q-incident", Type: domain.QuestIncident, Target: 1, ExpiresAt: expires},
+		{ID: "q-vote", Type: domain.QuestGovernance, Target: 2, ExpiresAt: expires},
+	} {
+		if err := db.InsertQuest(q); err != nil {
+			t.Fatalf("insert %s: %v", q.ID, err)
+		}
+	}
+
+	var completed []string
+	nq := engagement.NewNetworkQuests(svc, "node-a", func(q domain.Quest) {
+		completed = append(completed, q.ID)
+	})
+
+	// Partial MB uploads carry over.
+	nq.ChunksSeeded(1 << 19)
+	nq.ChunksSeeded(1 << 19)
+	nq.ChunksSeeded(1 << 20)
+
+	// Other nodes' votes don't count.
+	nq.VoteCast(governance.Vote{ProposalID: "p1", NodeID: "node-b"})
+	nq.VoteCast(governance.Vote{ProposalID: "p1", NodeID: "node-a"})
+
+	// Only resolved incidents count.
+	nq.IncidentResolved(selfheal.Incident{ID: "i1", State: selfheal.StateEscalated})
+	nq.IncidentResolved(selfheal.Incident{ID: "i2", State: selfheal.StateResolved})
+
+	if len(completed) != 2 || completed[0] != "q-seed" || completed[1] != "q-incident" {
+		t.Fatalf("completed = %v, want [q-seed q-incident]", completed)
+	}
+	vote, err := db.GetQuest("q-vote")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if vote.Progress != 1 || vote.Completed {
+		t.Errorf("vote quest progress = %d (completed %v), want 1", vote.Progress, vote.Completed)
+	}
+}
+
 func TestQuest_ProgressPct(t *testing.T) {
 	q := domain.Quest{Target: 100, Progress: 75}
 	pct := q.ProgressPct()
@@ -766,3 +813,55 @@ func TestStreak_Multiplier(t *testing.T) {
 func TestMain(m *testing.M) {
 	os.Exit(m.Run())
 }
+
+// ═══════════════════════════════════════════════════════════════════════════
+// Earnings Report Tests
+// ═══════════════════════════════════════════════════════════════════════════
+
+func TestReport_GenerateDue(t *testing.T) {
+	db := testDB(t)
+	notify := engagement.NewNotificationService(db)
+	var uptimeFrom time.Time
+	svc := engagement.NewReportService(db, notify, passive.TierHigh, func(start, end time.Time) float64 {
+		uptimeFrom = start
+		return end.Sub(start).Hours() / 2
+	})
+
+	yesterday := time.Date(2025, 7, 1, 15, 0, 0, 0, time.UTC)
+	for _, e := range []domain.LedgerEntry{
+		{Timestamp: yesterday, Type: domain.TxEarn, EntryType: domain.EntryCredit, Account: "node_balance", Amount: 400},
+		{Timestamp: yesterday.Add(time.Hour), Type: domain.TxEarn, EntryType: domain.EntryCredit, Account: "node_balance", Amount: 12},
+		{Timestamp: yesterday.Add(24 * time.Hour), Type: domain.TxEarn, EntryType: domain.EntryCredit, Account: "node_balance", Amount: 99}, // today
+	} {
+		db.InsertLedgerEntry(e)
+	}
+
+	early := time.Date(2025, 7, 2, 6, 0, 0, 0, time.UTC)
+	if created, err := svc.GenerateDue(early); err != nil || created {
+		t.Fatalf("GenerateDue(06:00) = %v, %v; want nothing before the report hour", created, err)
+	}
+	morning := time.Date(2025, 7, 2, 9, 0, 0, 0, time.UTC)
+	if created, err := svc.GenerateDue(morning); err != nil || !created {
+		t.Fatalf("GenerateDue(09:00) = %v, %v; want a report", created, err)
+	}
+	if created, _ := svc.GenerateDue(morning.Add(time.Hour)); created {
+		t.Error("second GenerateDue created a duplicate report")
+	}
+
+	reports, err := svc.Reports(10)
+	if err != nil || len(reports) != 1 {
+		t.Fatalf("Reports() = %+v, %v", reports, err)
+	}
+	r := reports[0]
+	if r.CreditsEarned != 412 || r.UptimeHours != 12 || r.HardwareTier != int(passive.TierHigh) {
+		t.Errorf("report = %+v, want 412 credits over 12h", r)
+	}
+	if !uptimeFrom.Equal(time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("uptime asked from %v, want midnight", uptimeFrom)
+	}
+
+	pending, _ := notify.Pending(10)
+	if len(pending) != 1 || pending[0].Body != "You earned 412 credits yesterday" {
+		t.Errorf("notifications = %+v", pending)
+	}
+}
@@ -0,0 +1,96 @@
+package engagement
+
+import (
+	"log"
+	"sync"
+
+	"github.com/tutu-network/tutu/internal/domain"
+	"github.com/tutu-network/tutu/internal/infra/governance"
+	"github.com/tutu-network/tutu/internal/infra/p2p"
+	"github.com/tutu-network/tutu/internal/infra/selfheal"
+)
+
+// ─── Network Quests ─────────────────────────────────────────────────────────
+// Quests for what a node gives the network: seeding model chunks to peers,
+// incidents the self-healing mesh resolves, votes on governance proposals.
+// Each source package exposes an event hook; NetworkQuests adapts those
+// events into QuestService progress. Attaching a source also offers its
+// quest type in the weekly draw, so a node never gets a quest it has no way
+// to progress.
+
+// bytesPerMB converts seeded bytes to QuestSeed progress.
+const bytesPerMB = 1 << 20
+
+// NetworkQuests feeds network events into quest progress.
+type NetworkQuests struct {
+	quests     *QuestService
+	nodeID     string
+	onComplete func(domain.Quest)
+
+	mu     sync.Mutex
+	seeded int64 // bytes not yet counted toward a whole MB
+}
+
+// NewNetworkQuests creates the adapter. Votes count only when cast by
+// nodeID. onComplete (may be nil) receives each quest the events complete.
+func NewNetworkQuests(quests *QuestService, nodeID string, onComplete func(domain.Quest)) *NetworkQuests {
+	return &NetworkQuests{quests: quests, nodeID: nodeID, onComplete: onComplete}
+}
+
+// AttachSeeder counts chunk uploads toward seeding quests.
+func (n *NetworkQuests) AttachSeeder(s *p2p.Seeder) {
+	s.OnUpload(func(_, _ string, bytes int64) { n.ChunksSeeded(bytes) })
+	n.quests.Offer(domain.QuestSeed)
+}
+
+// AttachSelfHeal counts resolved incidents toward incident quests.
+func (n *NetworkQuests) AttachSelfHeal(m *selfheal.Mesh) {
+	m.OnResolved(n.IncidentResolved)
+	n.quests.Offer(domain.QuestIncident)
+}
+
+// AttachGovernance counts this node's votes toward governance quests.
+func (n *NetworkQuests) AttachGovernance(e *governance.Engine) {
+	e.SetVoteHook(n.VoteCast)
+	n.quests.Offer(domain.QuestGovernance)
+}
+
+// ChunksSeeded records bytes uploaded to peers, in whole MB.
+func (n *NetworkQuests) ChunksSeeded(bytes int64) {
+	n.mu.Lock()
+	n.seeded += bytes
+	mb := n.seeded / bytesPerMB
+	n.seeded %= bytesPerMB
+	n.mu.Unlock()
+	if mb > 0 {
+		n.progress(domain.QuestSeed, int(mb))
+	}
+}
+
+// IncidentResolved records an incident resolved by remediation.
+func (n *NetworkQuests) IncidentResolved(inc selfheal.Incident) {
+	if inc.State == selfheal.StateResolved {
+		n.progress(domain.QuestIncident, 1)
+	}
+}
+
+// VoteCast records a first vote on a proposal.
+func (n *NetworkQuests) VoteCast(v governance.Vote) {
+	if v.NodeID == n.nodeID {
+		n.progress(domain.QuestGovernance, 1)
+	}
+}
+
+func (n *NetworkQuests) progress(t domain.QuestType, delta int) {
+	completed, err := n.quests.RecordProgress(t, delta)
+	if err != nil {
+		log.Printf("[quests] %s progress: %v", t, err)
+		return
+	}
+	if n.onComplete == nil {
+		return
+	}
+	for _, q := range completed {
+		n.onComplete(q)
+	}
+}
@@ -3,6 +3,7 @@ package engagement
 import (
 	"fmt"
 	"math/rand"
+	"sync"
 	"time"
 
 	"github.com/tutu-network/tutu/internal/domain"
@@ -14,11 +15,38 @@ import (
 // 3 new quests generated every Monday, expire the following Monday.
 type QuestService struct {
 	db *sqlite.DB
+
+	mu      sync.Mutex
+	offered map[domain.QuestType]bool // network quest types with an event source
 }
 
 // NewQuestService creates a quest service.
 func NewQuestService(db *sqlite.DB) *QuestService {
-	return &QuestService{db: db}
+	return &QuestService{db: db, offered: make(map[domain.QuestType]bool)}
+}
+
+// Offer adds network quests of the given types to the weekly draw. They
+// are left out until something reports their progress (see NetworkQuests).
+func (q *QuestService) Offer(types ...domain.QuestType) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, t := range types {
+		q.offered[t] = true
+	}
+}
+
+// pool returns the templates to draw from: the local pool plus offered
+// network quests.
+func (q *QuestService) pool() []domain.QuestTemplate {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	pool := append([]domain.QuestTemplate(nil), questPool...)
+	for _, tmpl := range networkQuestPool {
+		if q.offered[tmpl.Type] {
+			pool = append(pool, tmpl)
+		}
+	}
+	return pool
 }
 
 // questPool is the set of possible quest templates.
@@ -38,6 +66,14 @@ var questPool = []domain.QuestTemplate{
 	{Type: domain.QuestUptime, Target: 120, Description: "Keep node online for 5 straight days", RewardXP: 150, RewardCr: 100},
 }
 
+// networkQuestPool rewards contributing to the network rather than using
+// the node. Seed targets are in MB.
+var networkQuestPool = []domain.QuestTemplate{
+	{Type: domain.QuestSeed, Target: 10 * 1024, Description: "Seed 10 GB of model chunks", RewardXP: 300, RewardCr: 60},
+	{Type: domain.QuestIncident, Target: 1, Description: "Resolve an incident proactively", RewardXP: 200, RewardCr: 40},
+	{Type: domain.QuestGovernance, Target: 2, Description: "Vote on 2 governance proposals", RewardXP: 150, RewardCr: 20},
+}
+
 // GenerateWeeklyQuests creates 3 random quests for the current week.
 // Quests expire next Monday at 00:00 UTC.
 // If quests already exist for this week, returns existing ones.
@@ -60,7 +96,7 @@ func (q *QuestService) GenerateWeeklyQuestsAt(now time.Time) ([]domain.Quest, er
 	expiry := nextMonday(now)
 
 	// Pick 3 unique templates (no duplicate types)
-	selected := pickUniqueQuests(questPool, 3, now.UnixNano())
+	selected := pickUniqueQuests(q.pool(), 3, now.UnixNano())
 
 	var quests []domain.Quest
 	for i, tmpl := range selected {
@@ -0,0 +1,120 @@
+package engagement
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
+	"github.com/tutu-network/tutu/internal/infra/passive"
+	"github.com/tutu-network/tutu/internal/infra/sqlite"
+)
+
+// DefaultReportHour is the local hour after which yesterday's report is
+// generated. It matches the end of the default notification quiet hours.
+const DefaultReportHour = 8
+
+// ReportService generates the morning earnings report.
+// Architecture Part XIII §10: once a day, the previous day's ledger earnings,
+// completed tasks and uptime are saved as one earnings_reports row and
+// announced with a daily summary notification.
+type ReportService struct {
+	mu     sync.Mutex
+	db     *sqlite.DB
+	notify *NotificationService // nil = reports are saved silently
+	tier   passive.HardwareTier
+	uptime func(start, end time.Time) float64 // hours online in [start, end)
+	hour   int
+}
+
+// NewReportService creates a report generator. uptime reports the hours the
+// node was online in a period (nil = 0).
+func NewReportService(db *sqlite.DB, notify *NotificationService, tier passive.HardwareTier, uptime func(start, end time.Time) float64) *ReportService {
+	return &ReportService{
+		db:     db,
+		notify: notify,
+		tier:   tier,
+		uptime: uptime,
+		hour:   DefaultReportHour,
+	}
+}
+
+// SetTier sets the hardware tier recorded on future reports.
+func (r *ReportService) SetTier(tier passive.HardwareTier) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tier = tier
+}
+
+// Generate saves the report for the local day containing day, unless one
+// exists. It returns the report and whether it was created.
+func (r *ReportService) Generate(day time.Time) (passive.EarningsReport, bool, error) {
+	return r.generate(day, time.Now())
+}
+
+// generate builds, saves and announces a day's report at time now.
+func (r *ReportService) generate(day, now time.Time) (passive.EarningsReport, bool, error) {
+	start := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+	end := start.AddDate(0, 0, 1)
+
+	exists, err := r.db.EarningsReportExists(start)
+	if err != nil {
+		return passive.EarningsReport{}, false, fmt.Errorf("check report: %w", err)
+	}
+	if exists {
+		return passive.EarningsReport{}, false, nil
+	}
+
+	credits, err := r.db.CreditedBetween("node_balance", start, end)
+	if err != nil {
+		return passive.EarningsReport{}, false, fmt.Errorf("sum earnings: %w", err)
+	}
+	tasks, err := r.db.CountTasksCompleted(start, end)
+	if err != nil {
+		return passive.EarningsReport{}, false, fmt.Errorf("count tasks: %w", err)
+	}
+	var uptime float64
+	if r.uptime != nil {
+		uptime = r.uptime(start, end)
+	}
+	// Model popularity is cumulative, so this is the all-time favourite
+	var topModel string
+	if top, err := r.db.TopRequestedModels(1); err == nil && len(top) > 0 {
+		topModel = top[0].ModelName
+	}
+
+	r.mu.Lock()
+	tier := r.tier
+	r.mu.Unlock()
+
+	report := passive.GenerateReport(start, end, credits, tasks, uptime, tier, topModel)
+	if _, err := r.db.InsertEarningsReport(start, end, credits, tasks, uptime, int(tier), topModel); err != nil {
+		return report, false, fmt.Errorf("save report: %w", err)
+	}
+
+	if r.notify != nil && credits > 0 {
+		_, _ = r.notify.Create(domain.Notification{
+			Type:      domain.NotifyDailySummary,
+			Title:     "Morning report",
+			Body:      fmt.Sprintf("You earned %d credits yesterday", credits),
+			CreatedAt: now,
+		})
+	}
+	return report, true, nil
+}
+
+// GenerateDue generates yesterday's report once the report hour has passed
+// at now. It is safe to call repeatedly; it returns whether a report was
+// created.
+func (r *ReportService) GenerateDue(now time.Time) (bool, error) {
+	if now.Hour() < r.hour {
+		return false, nil
+	}
+	_, created, err := r.generate(now.AddDate(0, 0, -1), now)
+	return created, err
+}
+
+// Reports returns the most recent reports, newest first.
+func (r *ReportService) Reports(limit int) ([]sqlite.EarningsReportRecord, error) {
+	return r.db.ListEarningsReports(limit)
+}
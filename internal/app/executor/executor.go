@@ -6,21 +6,28 @@
 //  2. Creates a constrained execution context (CPU/mem/timeout)
 //  3. Routes to the appropriate backend (inference, embedding, etc.)
 //  4. Hashes the result (SHA-256) for verification
-//  5. Reports completion with credits
+//  5. Signs an attestation of the result, if a keypair is attached
+//  6. Reports the completed task, with its attestation, to the requester
+//
+// With a journal attached, every stage transition is also journaled so
+// in-flight tasks can be resumed after a restart.
 package executor
 
 import (
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"log"
 	"sync"
 	"time"
 
 	"github.com/tutu-network/tutu/internal/domain"
+	"github.com/tutu-network/tutu/internal/infra/journal"
 	"github.com/tutu-network/tutu/internal/infra/resource"
 	"github.com/tutu-network/tutu/internal/infra/sqlite"
+	"github.com/tutu-network/tutu/internal/security"
 )
 
 // Backend represents a computation backend (inference, embedding, etc.)
@@ -49,7 +56,13 @@ type Executor struct {
 	governor  *resource.Governor
 	db        *sqlite.DB
 	backends  map[domain.TaskType]Backend
-	sem       chan struct{} // Concurrency semaphore
+	admit     func(domain.Task) error // back-pressure admission (nil = admit all)
+	journal   *journal.Journal        // lifecycle journal (nil = not journaled)
+	selfID    string                  // node ID recorded on ASSIGNED events
+	signer    *security.Keypair       // signs result attestations (nil = unattested)
+	onResult  func(domain.Task)       // delivers completed tasks to the requester (nil = local only)
+	sem       chan struct{}           // Concurrency semaphore
+	running   map[string]runningTask  // executing tasks by ID, for Drain
 	active    int
 	completed int64
 	failed    int64
@@ -63,9 +76,16 @@ func New(cfg Config, gov *resource.Governor, db *sqlite.DB) *Executor {
 		db:       db,
 		backends: make(map[domain.TaskType]Backend),
 		sem:      make(chan struct{}, cfg.MaxConcurrent),
+		running:  make(map[string]runningTask),
 	}
 }
 
+// runningTask is an executing task and the cancel func that drains it.
+type runningTask struct {
+	task   domain.Task
+	cancel context.CancelCauseFunc
+}
+
 // RegisterBackend registers a computation backend for a task type.
 func (e *Executor) RegisterBackend(taskType domain.TaskType, backend Backend) {
 	e.mu.Lock()
@@ -73,11 +93,68 @@ func (e *Executor) RegisterBackend(taskType domain.TaskType, backend Backend) {
 	e.mu.Unlock()
 }
 
+// SetAdmission installs a back-pressure check run before any other
+// acceptance test. A non-nil error rejects the task unchanged.
+func (e *Executor) SetAdmission(admit func(domain.Task) error) {
+	e.mu.Lock()
+	e.admit = admit
+	e.mu.Unlock()
+}
+
+// SetJournal records every task's lifecycle in j. selfID is recorded as the
+// assignee of tasks this executor runs.
+func (e *Executor) SetJournal(j *journal.Journal, selfID string) {
+	e.mu.Lock()
+	e.journal = j
+	e.selfID = selfID
+	e.mu.Unlock()
+}
+
+// SetSigner signs an attestation of every completed task with kp and
+// stores it, so the requester can verify the result before paying.
+func (e *Executor) SetSigner(kp *security.Keypair) {
+	e.mu.Lock()
+	e.signer = kp
+	e.mu.Unlock()
+}
+
+// SetResultHandler passes every completed task to fn, with its result hash
+// and (when a signer is attached) its attestation filled in, so the result
+// can be delivered to the requester for verification and payment.
+func (e *Executor) SetResultHandler(fn func(domain.Task)) {
+	e.mu.Lock()
+	e.onResult = fn
+	e.mu.Unlock()
+}
+
+// record journals a lifecycle event if a journal is attached.
+func (e *Executor) record(ev domain.TaskEvent) {
+	e.mu.RLock()
+	j := e.journal
+	e.mu.RUnlock()
+	if j == nil {
+		return
+	}
+	if _, err := j.Record(ev); err != nil {
+		log.Printf("[executor] %v", err)
+	}
+}
+
 // Submit submits a task for execution. Returns immediately.
 // The task is persisted and executed asynchronously.
 // Local tasks only require CPU budget > 0. Distributed tasks
 // additionally require AllowDistributed.
 func (e *Executor) Submit(ctx context.Context, task domain.Task) error {
+	// Back-pressure — shed or defer before touching any resources
+	e.mu.RLock()
+	admit := e.admit
+	e.mu.RUnlock()
+	if admit != nil {
+		if err := admit(task); err != nil {
+			return err
+		}
+	}
+
 	// Check governor budget — local tasks need CPU > 0
 	budget := e.governor.Budget()
 	if budget.MaxCPUPercent <= 0 {
@@ -100,6 +177,8 @@ func (e *Executor) Submit(ctx context.Context, task domain.Task) error {
 		<-e.sem // Release slot
 		return fmt.Errorf("persist task: %w", err)
 	}
+	e.record(domain.TaskEvent{TaskID: task.ID, Type: domain.TaskEventSubmitted, TaskType: task.Type, TraceParent: task.TraceParent})
+	e.record(domain.TaskEvent{TaskID: task.ID, Type: domain.TaskEventScheduled})
 
 	// Execute asynchronously
 	go e.execute(ctx, task)
@@ -107,6 +186,24 @@ func (e *Executor) Submit(ctx context.Context, task domain.Task) error {
 	return nil
 }
 
+// Resume re-runs a task that was in flight when the node last stopped. The
+// task record already exists, so it is reset to QUEUED rather than inserted,
+// and admission is skipped: the work was accepted before the restart.
+// Resume waits for a free slot until ctx is done.
+func (e *Executor) Resume(ctx context.Context, task domain.Task) error {
+	select {
+	case e.sem <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	if err := e.db.UpdateTaskStatus(task.ID, domain.TaskQueued); err != nil {
+		<-e.sem
+		return fmt.Errorf("reset task: %w", err)
+	}
+	go e.execute(ctx, task)
+	return nil
+}
+
 // execute runs a task through the full lifecycle.
 func (e *Executor) execute(ctx context.Context, task domain.Task) {
 	defer func() { <-e.sem }() // Release concurrency slot
@@ -124,13 +221,35 @@ func (e *Executor) execute(ctx context.Context, task domain.Task) {
 	// Transition: QUEUED → ASSIGNED → EXECUTING
 	_ = e.db.UpdateTaskStatus(task.ID, domain.TaskAssigned)
 	_ = e.db.UpdateTaskStatus(task.ID, domain.TaskExecuting)
+	e.mu.RLock()
+	selfID := e.selfID
+	e.mu.RUnlock()
+	e.record(domain.TaskEvent{TaskID: task.ID, Type: domain.TaskEventAssigned, NodeID: selfID})
+	e.record(domain.TaskEvent{TaskID: task.ID, Type: domain.TaskEventStarted})
+	startedAt := time.Now()
 
 	log.Printf("[executor] executing task %s type=%s", task.ID, task.Type)
 
-	// Create timeout context
+	// Create timeout context; Drain cancels it with ErrTaskDrained
+	drainCtx, drain := context.WithCancelCause(ctx)
+	defer drain(nil)
+	e.mu.Lock()
+	e.running[task.ID] = runningTask{task: task, cancel: drain}
+	e.mu.Unlock()
+	defer func() {
+		e.mu.Lock()
+		delete(e.running, task.ID)
+		e.mu.Unlock()
+	}()
+
 	timeout := e.config.DefaultTimeout
-	execCtx, cancel := context.WithTimeout(ctx, timeout)
+	execCtx, cancel := context.WithTimeout(drainCtx, timeout)
 	defer cancel()
+	if task.HasDeadline() {
+		// A task past its deadline is worthless — don't run beyond it
+		execCtx, cancel = context.WithDeadline(execCtx, task.Deadline)
+		defer cancel()
+	}
 
 	// Get backend
 	e.mu.RLock()
@@ -144,6 +263,10 @@ func (e *Executor) execute(ctx context.Context, task domain.Task) {
 
 	// Execute
 	result, err := backend.Execute(execCtx, task)
+	if cause := context.Cause(drainCtx); errors.Is(cause, domain.ErrTaskDrained) {
+		e.failTask(task.ID, cause.Error())
+		return
+	}
 	if err != nil {
 		e.failTask(task.ID, err.Error())
 		return
@@ -155,22 +278,53 @@ func (e *Executor) execute(ctx context.Context, task domain.Task) {
 
 	// Complete the task
 	_ = e.db.UpdateTaskStatus(task.ID, domain.TaskCompleted)
+	e.record(domain.TaskEvent{TaskID: task.ID, Type: domain.TaskEventCompleted, Detail: resultHash})
 
 	log.Printf("[executor] task %s completed, hash=%s", task.ID, resultHash[:16])
+	task.Status = domain.TaskCompleted
+	task.StartedAt = startedAt
+	task.CompletedAt = time.Now()
+	task.ResultHash = resultHash
+	task.Attestation = e.attest(task, result, startedAt)
 
 	e.mu.Lock()
 	e.completed++
+	onResult := e.onResult
 	e.mu.Unlock()
 
-	// Update task with result hash
-	// Note: UpdateTaskStatus doesn't set hash — we'd need a dedicated method.
-	// For Phase 1, we log it. Full implementation in Phase 2.
-	_ = resultHash
+	if onResult != nil {
+		onResult(task)
+	}
+}
+
+// attest signs and stores the attestation of a completed task, returning it
+// (nil without a signer). Tasks carry no payload of their own, so the input
+// digest covers the task's identity.
+func (e *Executor) attest(task domain.Task, result []byte, startedAt time.Time) *domain.Attestation {
+	e.mu.RLock()
+	kp := e.signer
+	e.mu.RUnlock()
+	if kp == nil {
+		return nil
+	}
+	att := domain.Attestation{
+		TaskID:       task.ID,
+		InputDigest:  security.Digest([]byte(string(task.Type) + "\n" + task.ID)),
+		OutputDigest: security.Digest(result),
+		StartedAt:    startedAt,
+		CompletedAt:  time.Now(),
+	}
+	kp.SignAttestation(&att)
+	if err := e.db.InsertAttestation(att, true); err != nil {
+		log.Printf("[executor] task %s: record attestation: %v", task.ID, err)
+	}
+	return &att
 }
 
 // failTask marks a task as failed with an error message.
 func (e *Executor) failTask(taskID, errMsg string) {
 	e.db.UpdateTaskStatus(taskID, domain.TaskFailed)
+	e.record(domain.TaskEvent{TaskID: taskID, Type: domain.TaskEventFailed, Detail: errMsg})
 	log.Printf("[executor] task %s failed: %s", taskID, errMsg)
 
 	e.mu.Lock()
@@ -178,6 +332,23 @@ func (e *Executor) failTask(taskID, errMsg string) {
 	e.mu.Unlock()
 }
 
+// Drain stops every executing task for which match returns true (nil =
+// all), failing it with domain.ErrTaskDrained and reason. It returns how
+// many tasks were stopped; backends observe the cancellation through their
+// context.
+func (e *Executor) Drain(reason string, match func(domain.Task) bool) int {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	n := 0
+	for _, rt := range e.running {
+		if match == nil || match(rt.task) {
+			rt.cancel(fmt.Errorf("%w: %s", domain.ErrTaskDrained, reason))
+			n++
+		}
+	}
+	return n
+}
+
 // Stats returns executor statistics.
 type Stats struct {
 	Active    int   `json:"active"`
@@ -2,13 +2,16 @@ package executor
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"testing"
 	"time"
 
 	"github.com/tutu-network/tutu/internal/domain"
+	"github.com/tutu-network/tutu/internal/infra/journal"
 	"github.com/tutu-network/tutu/internal/infra/resource"
 	"github.com/tutu-network/tutu/internal/infra/sqlite"
+	"github.com/tutu-network/tutu/internal/security"
 )
 
 // mockBackend implements Backend for testing.
@@ -194,6 +197,136 @@ func TestSubmit_ConcurrencyLimit(t *testing.T) {
 	}
 }
 
+func TestSubmit_AdmissionRejects(t *testing.T) {
+	e := newTestExecutor(t)
+	e.RegisterBackend(domain.TaskInference, &mockBackend{result: []byte("ok")})
+	e.SetAdmission(func(task domain.Task) error {
+		if task.Priority >= 3 {
+			return domain.ErrBackPressureSoft
+		}
+		return nil
+	})
+
+	err := e.Submit(context.Background(), domain.Task{ID: "spot", Type: domain.TaskInference, Priority: 4})
+	if !errors.Is(err, domain.ErrBackPressureSoft) {
+		t.Fatalf("Submit(spot) = %v, want ErrBackPressureSoft", err)
+	}
+	if got, _ := e.db.GetTask("spot"); got != nil {
+		t.Error("shed task was persisted")
+	}
+	if err := e.Submit(context.Background(), domain.Task{ID: "normal", Type: domain.TaskInference, Priority: 2}); err != nil {
+		t.Fatalf("Submit(normal) = %v", err)
+	}
+}
+
+func TestSubmit_Journaled(t *testing.T) {
+	e := newTestExecutor(t)
+	j, err := journal.New(journal.DefaultConfig(), e.db)
+	if err != nil {
+		t.Fatalf("journal.New() error: %v", err)
+	}
+	e.SetJournal(j, "node-self")
+	e.RegisterBackend(domain.TaskInference, &mockBackend{result: []byte("ok")})
+
+	if err := e.Submit(context.Background(), domain.Task{ID: "j1", Type: domain.TaskInference}); err != nil {
+		t.Fatalf("Submit() error: %v", err)
+	}
+	time.Sleep(300 * time.Millisecond)
+
+	events, _ := j.History("j1")
+	want := []domain.TaskEventType{
+		domain.TaskEventSubmitted, domain.TaskEventScheduled, domain.TaskEventAssigned,
+		domain.TaskEventStarted, domain.TaskEventCompleted,
+	}
+	if len(events) != len(want) {
+		t.Fatalf("History(j1) = %+v, want %v", events, want)
+	}
+	for i, ev := range events {
+		if ev.Type != want[i] {
+			t.Errorf("event %d = %s, want %s", i, ev.Type, want[i])
+		}
+	}
+	if events[2].NodeID != "node-self" || events[4].Detail == "" {
+		t.Errorf("ASSIGNED node %q, COMPLETED detail %q", events[2].NodeID, events[4].Detail)
+	}
+}
+
+func TestSubmit_Attested(t *testing.T) {
+	e := newTestExecutor(t)
+	kp, _ := security.GenerateKeypair()
+	e.SetSigner(kp)
+	e.RegisterBackend(domain.TaskInference, &mockBackend{result: []byte("answer")})
+	delivered := make(chan domain.Task, 1)
+	e.SetResultHandler(func(task domain.Task) { delivered <- task })
+
+	if err := e.Submit(context.Background(), domain.Task{ID: "a1", Type: domain.TaskInference}); err != nil {
+		t.Fatalf("Submit() error: %v", err)
+	}
+	var task domain.Task
+	select {
+	case task = <-delivered:
+	case <-time.After(2 * time.Second):
+		t.Fatal("completed task was not delivered")
+	}
+	if task.Status != domain.TaskCompleted || task.ResultHash == "" || task.Attestation == nil {
+		t.Fatalf("delivered task = %+v, want completed with hash and attestation", task)
+	}
+	if err := security.VerifyAttestationResult(*task.Attestation, []byte("INFERENCE\na1"), []byte("answer")); err != nil {
+		t.Errorf("delivered attestation does not verify: %v", err)
+	}
+
+	rec, err := e.db.GetAttestation("a1")
+	if err != nil || rec == nil {
+		t.Fatalf("GetAttestation(a1) = %v, %v", rec, err)
+	}
+	if err := security.VerifyAttestationResult(rec.Attestation, []byte("INFERENCE\na1"), []byte("answer")); err != nil {
+		t.Errorf("attestation does not verify: %v", err)
+	}
+	if rec.NodeID != kp.PublicKeyHex() {
+		t.Errorf("attestation signed by %s, want %s", rec.NodeID, kp.PublicKeyHex())
+	}
+}
+
+func TestDrain(t *testing.T) {
+	e := newTestExecutor(t)
+	e.RegisterBackend(domain.TaskInference, &mockBackend{result: []byte("ok"), delay: time.Second})
+	e.RegisterBackend(domain.TaskAgent, &mockBackend{result: []byte("ok"), delay: 200 * time.Millisecond})
+	_ = e.Submit(context.Background(), domain.Task{ID: "network", Type: domain.TaskInference})
+	_ = e.Submit(context.Background(), domain.Task{ID: "local", Type: domain.TaskAgent})
+	time.Sleep(50 * time.Millisecond)
+
+	n := e.Drain("user is active", func(t domain.Task) bool { return t.Type != domain.TaskAgent })
+	if n != 1 {
+		t.Fatalf("Drain() = %d, want 1", n)
+	}
+	time.Sleep(300 * time.Millisecond)
+
+	if got, _ := e.db.GetTask("network"); got == nil || got.Status != domain.TaskFailed {
+		t.Errorf("drained task = %+v, want FAILED", got)
+	}
+	if got, _ := e.db.GetTask("local"); got == nil || got.Status != domain.TaskCompleted {
+		t.Errorf("kept task = %+v, want COMPLETED", got)
+	}
+}
+
+func TestResume(t *testing.T) {
+	e := newTestExecutor(t)
+	e.RegisterBackend(domain.TaskInference, &mockBackend{result: []byte("ok")})
+	task := domain.Task{ID: "r1", Type: domain.TaskInference, Status: domain.TaskExecuting}
+	if err := e.db.InsertTask(task); err != nil {
+		t.Fatalf("InsertTask() error: %v", err)
+	}
+
+	if err := e.Resume(context.Background(), task); err != nil {
+		t.Fatalf("Resume() error: %v", err)
+	}
+	time.Sleep(300 * time.Millisecond)
+
+	if got, _ := e.db.GetTask("r1"); got == nil || got.Status != domain.TaskCompleted {
+		t.Errorf("resumed task = %+v, want COMPLETED", got)
+	}
+}
+
 func TestStats(t *testing.T) {
 	e := newTestExecutor(t)
 	stats := e.Stats()
@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/BurntSushi/toml"
+	"github.com/spf13/cobra"
+
+	"github.com/tutu-network/tutu/internal/daemon"
+)
+
+func init() {
+	configCmd.AddCommand(configValidateCmd)
+	configCmd.AddCommand(configShowCmd)
+	configCmd.AddCommand(configDefaultsCmd)
+	rootCmd.AddCommand(configCmd)
+}
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Validate and inspect the node configuration",
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate [file]",
+	Short: "Check a config file (plus TUTU_* env overrides) before starting the node",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := daemon.ConfigFile()
+		if len(args) == 1 {
+			path = args[0]
+		}
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			fmt.Printf("%s does not exist; defaults will be used.\n", path)
+		}
+		if _, err := daemon.ValidateFile(path); err != nil {
+			return fmt.Errorf("%s is invalid:\n%w", path, err)
+		}
+		fmt.Printf("%s is valid.\n", path)
+		return nil
+	},
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print the effective configuration (file + env overrides)",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := daemon.LoadConfig()
+		if err != nil {
+			return err
+		}
+		return toml.NewEncoder(os.Stdout).Encode(cfg)
+	},
+}
+
+var configDefaultsCmd = &cobra.Command{
+	Use:   "defaults",
+	Short: "Print every config key with its default and env variable",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return daemon.WriteDefaults(os.Stdout)
+	},
+}
@@ -0,0 +1,329 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/tutu-network/tutu/internal/api"
+	"github.com/tutu-network/tutu/internal/app/executor"
+	"github.com/tutu-network/tutu/internal/domain"
+	"github.com/tutu-network/tutu/internal/infra/registry"
+)
+
+// ─── Dashboard ("The Mining Screen") ────────────────────────────────────────
+// A full-screen terminal view of a running node for operators on headless
+// boxes over SSH. Earnings stream in over the SSE feed (/api/earnings/live);
+// everything else is polled every --interval:
+//
+//	tasks      /api/admin/network/tasks
+//	models     /api/ps + /api/storage
+//	streak     /api/engagement/summary
+//	incidents  /api/admin/network/incidents
+//
+// Plain ANSI only (clear + home), so it works in any SSH terminal without a
+// TUI library. Sections the daemon does not serve show as unavailable.
+
+const dashboardRecentEarnings = 5
+
+func init() {
+	dashboardCmd.Flags().String("addr", "", "Daemon address (default: from config)")
+	dashboardCmd.Flags().Duration("interval", 2*time.Second, "Refresh interval")
+	dashboardCmd.Flags().Bool("once", false, "Render a single frame without clearing the screen")
+	rootCmd.AddCommand(dashboardCmd)
+}
+
+var dashboardCmd = &cobra.Command{
+	Use:     "dashboard",
+	Aliases: []string{"top"},
+	Short:   "Live node dashboard: earnings, tasks, models, streak and incidents",
+	RunE:    runDashboard,
+}
+
+// dashboard holds the latest view of the node. The earnings feed goroutine
+// and the poll loop both write to it; render reads it.
+type dashboard struct {
+	mu      sync.Mutex
+	addr    string
+	started time.Time
+
+	feedUp  bool
+	feedErr error
+	earned  float64
+	recent  []api.EarningsEvent // newest first
+
+	tasks     *executor.Stats
+	loaded    []loadedModel
+	storage   *registry.StorageUsage
+	summary   *engagementSummary
+	incidents []api.IncidentView
+	errs      map[string]error
+}
+
+type loadedModel struct {
+	Name      string    `json:"name"`
+	Size      int64     `json:"size"`
+	Processor string    `json:"processor"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type engagementSummary struct {
+	Streak *struct {
+		CurrentDays int     `json:"current_days"`
+		LongestDays int     `json:"longest_days"`
+		Multiplier  float64 `json:"multiplier"`
+	} `json:"streak"`
+	Level *struct {
+		Level       int     `json:"level"`
+		CurrentXP   int64   `json:"current_xp"`
+		ProgressPct float64 `json:"progress_pct"`
+	} `json:"level"`
+	ActiveQuests int `json:"active_quests"`
+}
+
+func runDashboard(cmd *cobra.Command, args []string) error {
+	addr, _ := cmd.Flags().GetString("addr")
+	interval, _ := cmd.Flags().GetDuration("interval")
+	once, _ := cmd.Flags().GetBool("once")
+	if interval <= 0 {
+		interval = 2 * time.Second
+	}
+
+	d := &dashboard{addr: addr, started: time.Now()}
+	if once {
+		d.poll()
+		_, err := os.Stdout.Write(d.render())
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go d.streamEarnings(ctx)
+
+	fmt.Print("\033[?25l")       // hide cursor
+	defer fmt.Print("\033[?25h") // restore on exit
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		d.poll()
+		os.Stdout.Write(append([]byte("\033[H\033[2J"), d.render()...))
+
+		select {
+		case <-ctx.Done():
+			fmt.Println()
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll refreshes every polled section. A failed section keeps its error
+// for display; the others still update.
+func (d *dashboard) poll() {
+	var (
+		tasks     executor.Stats
+		ps        struct{ Models []loadedModel }
+		storage   registry.StorageUsage
+		summary   engagementSummary
+		incidents struct {
+			Active   []api.IncidentView `json:"active"`
+			Resolved []api.IncidentView `json:"resolved"`
+		}
+	)
+	errs := map[string]error{
+		"tasks":     d.fetch("/api/admin/network/tasks", &tasks),
+		"models":    d.fetch("/api/ps", &ps),
+		"storage":   d.fetch("/api/storage", &storage),
+		"streak":    d.fetch("/api/engagement/summary", &summary),
+		"incidents": d.fetch("/api/admin/network/incidents?limit=3", &incidents),
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.errs = errs
+	d.tasks, d.storage, d.summary = nil, nil, nil
+	if errs["tasks"] == nil {
+		d.tasks = &tasks
+	}
+	d.loaded = ps.Models
+	if errs["storage"] == nil {
+		d.storage = &storage
+	}
+	if errs["streak"] == nil {
+		d.summary = &summary
+	}
+	d.incidents = append(incidents.Active, incidents.Resolved...)
+}
+
+func (d *dashboard) fetch(path string, out any) error {
+	body, err := daemonGet(d.addr, path, nil)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, out)
+}
+
+// streamEarnings follows the SSE feed until ctx ends, reconnecting after
+// a short pause whenever the stream drops.
+func (d *dashboard) streamEarnings(ctx context.Context) {
+	for {
+		err := d.followFeed(ctx)
+		d.mu.Lock()
+		d.feedUp = false
+		d.feedErr = err
+		d.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(3 * time.Second):
+		}
+	}
+}
+
+func (d *dashboard) followFeed(ctx context.Context) error {
+	req, err := newDaemonRequest(ctx, d.addr, "/api/earnings/live", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("earnings feed: %s", resp.Status)
+	}
+
+	d.mu.Lock()
+	d.feedUp, d.feedErr = true, nil
+	d.mu.Unlock()
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+		if !ok {
+			continue
+		}
+		var ev api.EarningsEvent
+		if json.Unmarshal([]byte(data), &ev) != nil {
+			continue
+		}
+		d.mu.Lock()
+		d.earned += ev.Amount
+		d.recent = append([]api.EarningsEvent{ev}, d.recent...)
+		if len(d.recent) > dashboardRecentEarnings {
+			d.recent = d.recent[:dashboardRecentEarnings]
+		}
+		d.mu.Unlock()
+	}
+	return scanner.Err()
+}
+
+// render draws one frame.
+func (d *dashboard) render() []byte {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "TuTu node dashboard — %s (Ctrl-C to quit)\n", time.Now().Format("15:04:05"))
+
+	section(w, "EARNINGS")
+	uptime := time.Since(d.started)
+	rate := 0.0
+	if h := uptime.Hours(); h > 0 {
+		rate = d.earned / h
+	}
+	feed := "live"
+	if !d.feedUp {
+		feed = "connecting"
+		if d.feedErr != nil {
+			feed = "offline: " + d.feedErr.Error()
+		}
+	}
+	fmt.Fprintf(w, "Session:\t%.2f credits over %s (%.2f/h)\tfeed %s\n", d.earned, uptime.Round(time.Second), rate, feed)
+	for _, ev := range d.recent {
+		fmt.Fprintf(w, "  +%.2f\t%s\t%s\t%s\n", ev.Amount, ev.TaskType, ev.Model, time.Unix(ev.Timestamp, 0).Format("15:04:05"))
+	}
+
+	section(w, "TASKS")
+	if t := d.tasks; t != nil {
+		fmt.Fprintf(w, "Active:\t%d/%d slots\tcompleted %d, failed %d\n", t.Active, t.MaxSlots, t.Completed, t.Failed)
+	} else {
+		unavailable(w, d.errs["tasks"])
+	}
+
+	section(w, "MODELS")
+	if u := d.storage; u != nil {
+		budget := "unlimited"
+		if u.BudgetBytes > 0 {
+			budget = domain.HumanSize(u.BudgetBytes)
+		}
+		fmt.Fprintf(w, "Cache:\t%d models, %s of %s\tpinned %s\n",
+			len(u.Models), domain.HumanSize(u.UsedBytes), budget, domain.HumanSize(u.PinnedBytes))
+	} else {
+		unavailable(w, d.errs["storage"])
+	}
+	if err := d.errs["models"]; err != nil {
+		unavailable(w, err)
+	} else if len(d.loaded) == 0 {
+		fmt.Fprintln(w, "Loaded:\tnone")
+	}
+	for _, m := range d.loaded {
+		fmt.Fprintf(w, "  %s\t%s\t%s\texpires in %s\n",
+			m.Name, domain.HumanSize(m.Size), m.Processor, time.Until(m.ExpiresAt).Round(time.Second))
+	}
+
+	section(w, "STREAK")
+	if s := d.summary; s != nil {
+		if s.Streak != nil {
+			fmt.Fprintf(w, "Streak:\t%d days (best %d)\t%.2fx credits\n", s.Streak.CurrentDays, s.Streak.LongestDays, s.Streak.Multiplier)
+		}
+		if s.Level != nil {
+			fmt.Fprintf(w, "Level:\t%d (%d XP)\t%.0f%% to next\n", s.Level.Level, s.Level.CurrentXP, s.Level.ProgressPct)
+		}
+		fmt.Fprintf(w, "Quests:\t%d active\n", s.ActiveQuests)
+	} else {
+		unavailable(w, d.errs["streak"])
+	}
+
+	section(w, "INCIDENTS")
+	if err := d.errs["incidents"]; err != nil {
+		unavailable(w, err)
+	} else if len(d.incidents) == 0 {
+		fmt.Fprintln(w, "None")
+	}
+	for _, inc := range d.incidents {
+		fmt.Fprintf(w, "  %s\t%s\t%s\t%s\n", shortID(inc.NodeID), inc.FailureType, inc.State, ago(inc.DetectedAt))
+	}
+
+	w.Flush()
+	return buf.Bytes()
+}
+
+func section(w io.Writer, title string) {
+	fmt.Fprintf(w, "\n── %s\n", title)
+}
+
+func unavailable(w io.Writer, err error) {
+	if err == nil {
+		fmt.Fprintln(w, "unavailable")
+		return
+	}
+	fmt.Fprintf(w, "unavailable: %v\n", err)
+}
@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/tutu-network/tutu/internal/daemon"
+)
+
+func init() {
+	diagnosticsCmd.Flags().StringP("output", "o", "", "Bundle path (default: tutu-diagnostics-<time>.tar.gz)")
+	diagnosticsCmd.Flags().String("addr", "", "Daemon address (default: from config)")
+	diagnosticsCmd.Flags().Bool("offline", false, "Collect locally without contacting the daemon")
+	rootCmd.AddCommand(diagnosticsCmd)
+}
+
+var diagnosticsCmd = &cobra.Command{
+	Use:   "diagnostics",
+	Short: "Collect logs, stats and config into a support bundle",
+	Long: `Collect a support bundle (tar.gz) from the node: recent logs, trace spans,
+subsystem stats, SQLite integrity check, gossip membership and the effective
+config. Stored secrets and URL credentials are redacted.
+
+The bundle is fetched from the running daemon. If the daemon is not running
+(or with --offline) a reduced bundle is collected locally from the config,
+log file and database.`,
+	RunE: runDiagnostics,
+}
+
+func runDiagnostics(cmd *cobra.Command, args []string) error {
+	out, _ := cmd.Flags().GetString("output")
+	if out == "" {
+		out = fmt.Sprintf("tutu-diagnostics-%s.tar.gz", time.Now().Format("20060102-150405"))
+	}
+	offline, _ := cmd.Flags().GetBool("offline")
+
+	var bundle []byte
+	if !offline {
+		addr, _ := cmd.Flags().GetString("addr")
+		body, err := daemonGet(addr, "/api/admin/diagnostics", nil)
+		var unreachable *url.Error
+		switch {
+		case err == nil:
+			bundle = body
+		case errors.As(err, &unreachable):
+			fmt.Fprintf(os.Stderr, "Daemon not reachable; collecting offline bundle.\n")
+			offline = true
+		default:
+			return err
+		}
+	}
+	if offline {
+		var buf bytes.Buffer
+		if err := daemon.OfflineDiagnostics(&buf); err != nil {
+			return err
+		}
+		bundle = buf.Bytes()
+	}
+
+	if err := os.WriteFile(out, bundle, 0600); err != nil {
+		return fmt.Errorf("write bundle: %w", err)
+	}
+	fmt.Printf("Diagnostics bundle written to %s (%d KB)\n", out, len(bundle)/1024)
+	return nil
+}
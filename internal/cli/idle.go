@@ -0,0 +1,165 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/tutu-network/tutu/internal/infra/resource"
+)
+
+// ─── Idle Compute CLI ───────────────────────────────────────────────────────
+// Show and change when the running node may work for the network
+// (/api/admin/idle-policy). Changes apply immediately and last until the
+// daemon restarts; [idle_compute] in the config file sets the policy it
+// starts with.
+
+func init() {
+	rootCmd.AddCommand(idleCmd)
+	idleCmd.AddCommand(idleSetCmd)
+
+	idleCmd.PersistentFlags().String("addr", "", "Daemon address (default: from config)")
+	idleCmd.Flags().Bool("json", false, "Print the raw JSON response")
+
+	f := idleSetCmd.Flags()
+	f.Bool("enabled", false, "Only accept network work within the policy")
+	f.Int("max-cpu", 0, "Block work while your CPU use is above this % (0 = no limit)")
+	f.Int("max-gpu", 0, "Block work while GPU use is above this % (0 = no limit)")
+	f.String("min-idle", "", "How idle you must be: active, light, deep or locked")
+	f.StringSlice("window", nil, "Allowed hours, e.g. 22:00-07:00 (repeatable; \"\" = any time)")
+	f.Int("min-battery", 0, "On battery, block work below this charge %")
+	f.Bool("ac-only", false, "Never work on battery power")
+	f.Int("max-temp", 0, "Drain work when CPU or GPU is hotter than this °C (0 = no limit)")
+}
+
+var idleCmd = &cobra.Command{
+	Use:   "idle",
+	Short: "Show when this machine works for the network",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		addr, _ := cmd.Flags().GetString("addr")
+		body, err := daemonGet(addr, "/api/admin/idle-policy", nil)
+		if err != nil {
+			return err
+		}
+		if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+			_, err := os.Stdout.Write(body)
+			return err
+		}
+		return printIdleStatus(body)
+	},
+}
+
+var idleSetCmd = &cobra.Command{
+	Use:   "set",
+	Short: "Change the idle compute policy on the running node",
+	Example: `  tutu idle set --enabled --window 22:00-07:00 --max-cpu 20
+  tutu idle set --ac-only --max-temp 80
+  tutu idle set --enabled=false`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Only flags given on the command line are sent; the daemon keeps
+		// the rest of the policy
+		change := make(map[string]interface{})
+		f := cmd.Flags()
+		for flag, field := range map[string]string{
+			"enabled": "enabled", "ac-only": "require_ac_power",
+		} {
+			if f.Changed(flag) {
+				change[field], _ = f.GetBool(flag)
+			}
+		}
+		for flag, field := range map[string]string{
+			"max-cpu": "max_cpu_percent", "max-gpu": "max_gpu_percent",
+			"min-battery": "min_battery_pct", "max-temp": "max_temp_c",
+		} {
+			if f.Changed(flag) {
+				change[field], _ = f.GetInt(flag)
+			}
+		}
+		if f.Changed("min-idle") {
+			change["min_idle"], _ = f.GetString("min-idle")
+		}
+		if f.Changed("window") {
+			windows := []string{}
+			raw, _ := f.GetStringSlice("window")
+			for _, w := range raw {
+				if w = strings.TrimSpace(w); w != "" {
+					windows = append(windows, w)
+				}
+			}
+			change["windows"] = windows
+		}
+		if len(change) == 0 {
+			return fmt.Errorf("nothing to change; see 'tutu idle set --help'")
+		}
+
+		payload, err := json.Marshal(change)
+		if err != nil {
+			return err
+		}
+		addr, _ := cmd.Flags().GetString("addr")
+		body, err := daemonPut(addr, "/api/admin/idle-policy", payload)
+		if err != nil {
+			return err
+		}
+		return printIdleStatus(body)
+	},
+}
+
+func printIdleStatus(body []byte) error {
+	var st resource.PolicyStatus
+	if err := json.Unmarshal(body, &st); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	p := st.Policy
+	if !p.Enabled {
+		fmt.Println("Policy:    off (network work runs whenever the node has capacity)")
+	} else {
+		hours := "any time"
+		if len(p.Windows) > 0 {
+			hours = strings.Join(p.Windows, ", ")
+		}
+		fmt.Printf("Policy:    on, %s, when you are at least %s idle\n", hours, p.MinIdle)
+		fmt.Printf("Limits:    CPU %s  GPU %s  temp %s\n",
+			idleLimit(p.MaxCPUPercent, "%"), idleLimit(p.MaxGPUPercent, "%"), idleLimit(p.MaxTempC, "°C"))
+		battery := fmt.Sprintf("on battery above %d%%", p.MinBatteryPct)
+		if p.RequireACPower {
+			battery = "AC power only"
+		}
+		fmt.Printf("Power:     %s\n", battery)
+	}
+
+	s := st.Sample
+	fmt.Printf("\nMachine:   %s, CPU %s, GPU %s", s.IdleName, idleUsage(s.CPUPercent), idleUsage(s.GPUPercent))
+	if s.OnBattery {
+		fmt.Printf(", battery %d%%", s.BatteryPct)
+	}
+	fmt.Println()
+
+	if st.Decision.Accept {
+		fmt.Println("Status:    accepting network work")
+	} else {
+		fmt.Println("Status:    paused")
+		for _, r := range st.Decision.Reasons {
+			fmt.Printf("           - %s\n", r)
+		}
+	}
+	fmt.Printf("Rejected:  %d tasks, drained %d times\n", st.Rejected, st.Drains)
+	return nil
+}
+
+func idleLimit(n int, unit string) string {
+	if n <= 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%d%s", n, unit)
+}
+
+func idleUsage(pct float64) string {
+	if pct < 0 {
+		return "?"
+	}
+	return fmt.Sprintf("%.0f%%", pct)
+}
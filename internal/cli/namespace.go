@@ -0,0 +1,219 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/tutu-network/tutu/internal/infra/tenant"
+)
+
+// ─── Namespace CLI ──────────────────────────────────────────────────────────
+// Share the running node with other teams (/api/admin/namespaces). Each
+// namespace gets its own API keys, model allowlist and rate limit; its
+// usage is shown per model.
+
+func init() {
+	rootCmd.AddCommand(namespaceCmd)
+	namespaceCmd.AddCommand(namespaceCreateCmd, namespaceSetCmd, namespaceDeleteCmd,
+		namespaceKeyCmd, namespaceRevokeCmd)
+
+	namespaceCmd.PersistentFlags().String("addr", "", "Daemon address (default: from config)")
+	namespaceCmd.Flags().Bool("json", false, "Print the raw JSON response")
+
+	for _, c := range []*cobra.Command{namespaceCreateCmd, namespaceSetCmd} {
+		c.Flags().StringSlice("model", nil, "Allowed model, e.g. llama3 or llama3:70b (repeatable; none = every model)")
+		c.Flags().Int("rpm", 0, "Requests per minute (0 = unlimited)")
+	}
+}
+
+var namespaceCmd = &cobra.Command{
+	Use:     "namespace [name]",
+	Aliases: []string{"ns"},
+	Short:   "List the namespaces sharing this node, or show one",
+	Args:    cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		addr, _ := cmd.Flags().GetString("addr")
+		asJSON, _ := cmd.Flags().GetBool("json")
+		if len(args) == 1 {
+			body, err := daemonGet(addr, "/api/admin/namespaces/"+args[0], nil)
+			if err != nil {
+				return err
+			}
+			if asJSON {
+				_, err := os.Stdout.Write(body)
+				return err
+			}
+			return printNamespace(body)
+		}
+
+		body, err := daemonGet(addr, "/api/admin/namespaces", nil)
+		if err != nil {
+			return err
+		}
+		if asJSON {
+			_, err := os.Stdout.Write(body)
+			return err
+		}
+		var resp struct {
+			Namespaces []tenant.Status `json:"namespaces"`
+		}
+		if err := json.Unmarshal(body, &resp); err != nil {
+			return fmt.Errorf("decode response: %w", err)
+		}
+		if len(resp.Namespaces) == 0 {
+			fmt.Println("No namespaces. Create one with 'tutu namespace create <name>'.")
+			return nil
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tMODELS\tRPM\tKEYS\tREQUESTS\tTOKENS\tCREDITS")
+		for _, ns := range resp.Namespaces {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t%d\t%d\n", ns.Name, namespaceModels(ns.Models),
+				namespaceRPM(ns.RateLimitRPM), len(ns.Keys), ns.Usage.Requests, ns.Usage.Tokens, ns.Usage.CreditsSpent)
+		}
+		return w.Flush()
+	},
+}
+
+var namespaceCreateCmd = &cobra.Command{
+	Use:     "create <name>",
+	Short:   "Create a namespace",
+	Example: `  tutu namespace create search --model llama3 --model nomic-embed-text --rpm 120`,
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return sendNamespace(cmd, "POST", "/api/admin/namespaces", args[0])
+	},
+}
+
+var namespaceSetCmd = &cobra.Command{
+	Use:   "set <name>",
+	Short: "Replace a namespace's model allowlist and rate limit",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return sendNamespace(cmd, "PUT", "/api/admin/namespaces/"+args[0], args[0])
+	},
+}
+
+var namespaceDeleteCmd = &cobra.Command{
+	Use:   "delete <name>",
+	Short: "Delete a namespace and revoke its keys",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		addr, _ := cmd.Flags().GetString("addr")
+		if _, err := daemonSend(addr, "DELETE", "/api/admin/namespaces/"+args[0], nil); err != nil {
+			return err
+		}
+		fmt.Printf("Deleted namespace %s\n", args[0])
+		return nil
+	},
+}
+
+var namespaceKeyCmd = &cobra.Command{
+	Use:   "key <name>",
+	Short: "Issue an API key for a namespace",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		addr, _ := cmd.Flags().GetString("addr")
+		body, err := daemonSend(addr, "POST", "/api/admin/namespaces/"+args[0]+"/keys", nil)
+		if err != nil {
+			return err
+		}
+		var issued struct {
+			ID  string `json:"id"`
+			Key string `json:"key"`
+		}
+		if err := json.Unmarshal(body, &issued); err != nil {
+			return fmt.Errorf("decode response: %w", err)
+		}
+		fmt.Printf("Key %s for namespace %s:\n\n  %s\n\n", issued.ID, args[0], issued.Key)
+		fmt.Println("Store it now; it is not shown again.")
+		return nil
+	},
+}
+
+var namespaceRevokeCmd = &cobra.Command{
+	Use:   "revoke <name> <key-id>",
+	Short: "Revoke a namespace API key",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		addr, _ := cmd.Flags().GetString("addr")
+		if _, err := daemonSend(addr, "DELETE", "/api/admin/namespaces/"+args[0]+"/keys/"+args[1], nil); err != nil {
+			return err
+		}
+		fmt.Printf("Revoked key %s\n", args[1])
+		return nil
+	},
+}
+
+// sendNamespace creates or updates a namespace from the command's flags.
+func sendNamespace(cmd *cobra.Command, method, path, name string) error {
+	models, _ := cmd.Flags().GetStringSlice("model")
+	rpm, _ := cmd.Flags().GetInt("rpm")
+	payload, err := json.Marshal(map[string]interface{}{
+		"name":           name,
+		"models":         models,
+		"rate_limit_rpm": rpm,
+	})
+	if err != nil {
+		return err
+	}
+	addr, _ := cmd.Flags().GetString("addr")
+	body, err := daemonSend(addr, method, path, payload)
+	if err != nil {
+		return err
+	}
+	return printNamespace(body)
+}
+
+func printNamespace(body []byte) error {
+	var ns tenant.Status
+	if err := json.Unmarshal(body, &ns); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	u := ns.Usage
+	fmt.Printf("Namespace: %s\n", ns.Name)
+	fmt.Printf("Models:    %s\n", namespaceModels(ns.Models))
+	fmt.Printf("Limit:     %s requests/minute\n", namespaceRPM(ns.RateLimitRPM))
+	fmt.Printf("Usage:     %d requests, %d tokens, %d credits\n", u.Requests, u.Tokens, u.CreditsSpent)
+	fmt.Printf("Rejected:  %d over the rate limit, %d for a model not allowed\n", u.RateLimited, u.ModelDenied)
+	if len(ns.Keys) > 0 {
+		fmt.Println("\nKeys:")
+		for _, k := range ns.Keys {
+			fmt.Printf("  %s  created %s\n", k.ID, k.CreatedAt.Format("2006-01-02 15:04"))
+		}
+	}
+	if len(u.Models) > 0 {
+		fmt.Println("\nBy model:")
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		models := make([]string, 0, len(u.Models))
+		for m := range u.Models {
+			models = append(models, m)
+		}
+		sort.Strings(models)
+		for _, m := range models {
+			mu := u.Models[m]
+			fmt.Fprintf(w, "  %s\t%d requests\t%d tokens\n", m, mu.Requests, mu.Tokens)
+		}
+		w.Flush()
+	}
+	return nil
+}
+
+func namespaceModels(models []string) string {
+	if len(models) == 0 {
+		return "all"
+	}
+	return strings.Join(models, ", ")
+}
+
+func namespaceRPM(rpm int) string {
+	if rpm <= 0 {
+		return "unlimited"
+	}
+	return fmt.Sprint(rpm)
+}
@@ -0,0 +1,365 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/tutu-network/tutu/internal/api"
+	"github.com/tutu-network/tutu/internal/daemon"
+	"github.com/tutu-network/tutu/internal/domain"
+	"github.com/tutu-network/tutu/internal/infra/gossip"
+)
+
+// ─── Network CLI ────────────────────────────────────────────────────────────
+// Day-to-day network operations against a running `tutu serve`. Every
+// subcommand reads the audited admin API (/api/admin/network/*), so the
+// daemon must be running. Output is a table by default, or the raw API
+// response with --json. `tutu network ack` reports what happened to a
+// placement recommendation.
+
+func init() {
+	rootCmd.AddCommand(networkCmd)
+	networkCmd.AddCommand(networkPeersCmd)
+	networkCmd.AddCommand(networkClustersCmd)
+	networkCmd.AddCommand(networkReputationCmd)
+	networkCmd.AddCommand(networkPlacementsCmd)
+	networkCmd.AddCommand(networkAckCmd)
+	networkCmd.AddCommand(networkAutoscaleCmd)
+	networkCmd.AddCommand(networkIncidentsCmd)
+	networkCmd.AddCommand(networkVotesCmd)
+
+	networkCmd.PersistentFlags().Bool("json", false, "Print the raw JSON response")
+	networkCmd.PersistentFlags().String("addr", "", "Daemon address (default: from config)")
+	networkCmd.PersistentFlags().Int("limit", 0, "Maximum rows (default: server-side)")
+	networkVotesCmd.Flags().String("status", "", "Filter by status (active, passed, rejected, ...)")
+	networkClustersCmd.Flags().String("region", "", "Only clusters in this region")
+	networkAckCmd.Flags().String("detail", "", "Why, or what happened")
+}
+
+var networkCmd = &cobra.Command{
+	Use:   "network",
+	Short: "Inspect the distributed network (peers, reputation, incidents, ...)",
+}
+
+// ─── network peers ──────────────────────────────────────────────────────────
+
+var networkPeersCmd = &cobra.Command{
+	Use:   "peers",
+	Short: "List gossip peers",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var resp struct {
+			Peers []domain.Peer `json:"peers"`
+		}
+		return networkQuery(cmd, "peers", nil, &resp, func(w io.Writer) {
+			fmt.Fprintln(w, "NODE\tSTATE\tENDPOINT\tLAST SEEN")
+			for _, p := range resp.Peers {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", shortID(p.NodeID), p.State, p.Endpoint, ago(p.LastSeen))
+			}
+		})
+	},
+}
+
+// ─── network clusters ───────────────────────────────────────────────────────
+
+var networkClustersCmd = &cobra.Command{
+	Use:   "clusters",
+	Short: "Show cluster summaries gossiped between cluster representatives",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		params := url.Values{}
+		if region, _ := cmd.Flags().GetString("region"); region != "" {
+			params.Set("region", region)
+		}
+		var resp struct {
+			Local    string               `json:"local"`
+			Clusters []gossip.ClusterView `json:"clusters"`
+		}
+		return networkQuery(cmd, "clusters", params, &resp, func(w io.Writer) {
+			fmt.Fprintln(w, "CLUSTER	ALIVE	LOAD	QUEUE	HOT MODELS	UPDATED")
+			for _, c := range resp.Clusters {
+				name := c.Key()
+				if c.Local {
+					name += " (local)"
+				}
+				updated := ago(c.ReceivedAt)
+				if c.Stale {
+					updated += " (stale)"
+				}
+				fmt.Fprintf(w, "%s\t%d/%d\t%.0f%%\t%d\t%d\t%s\n",
+					name, c.Alive, c.Members, c.Load*100, c.QueueDepth, len(c.HotModels), updated)
+			}
+		})
+	},
+}
+
+// ─── network reputation ─────────────────────────────────────────────────────
+
+var networkReputationCmd = &cobra.Command{
+	Use:   "reputation",
+	Short: "Show the top nodes by reputation",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var resp struct {
+			Nodes []api.ReputationView `json:"nodes"`
+		}
+		return networkQuery(cmd, "reputation", nil, &resp, func(w io.Writer) {
+			fmt.Fprintln(w, "NODE\tSCORE\tTIER\tTASKS\tDAYS\tPENALTIES")
+			for _, n := range resp.Nodes {
+				fmt.Fprintf(w, "%s\t%.3f\t%s\t%d\t%d\t%.2f\n",
+					shortID(n.NodeID), n.Overall, n.Tier, n.TaskCount, n.DaysActive, n.Penalties)
+			}
+		})
+	},
+}
+
+// ─── network placements ─────────────────────────────────────────────────────
+
+var networkPlacementsCmd = &cobra.Command{
+	Use:   "placements",
+	Short: "Show recent model placement recommendations",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var resp struct {
+			Recommendations []api.PlacementView `json:"recommendations"`
+		}
+		return networkQuery(cmd, "placements", nil, &resp, func(w io.Writer) {
+			fmt.Fprintln(w, "ID\tTYPE\tMODEL\tFROM\tTO\tSCORE\tSTATUS\tREASON")
+			for _, p := range resp.Recommendations {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%.2f\t%s\t%s\n",
+					p.ID, p.Type, p.Model, shortID(p.FromNode), shortID(p.ToNode), p.Score, p.Status, p.Reason)
+			}
+		})
+	},
+}
+
+var networkAckCmd = &cobra.Command{
+	Use:   "ack <id> <accepted|rejected|completed|failed>",
+	Short: "Report what happened to a placement recommendation",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		detail, _ := cmd.Flags().GetString("detail")
+		payload, err := json.Marshal(api.PlacementAckRequest{Status: args[1], Detail: detail})
+		if err != nil {
+			return err
+		}
+		addr, _ := cmd.Flags().GetString("addr")
+		body, err := daemonSend(addr, http.MethodPost, "/api/admin/network/placements/"+args[0]+"/ack", payload)
+		if err != nil {
+			return err
+		}
+		var p api.PlacementView
+		if err := json.Unmarshal(body, &p); err != nil {
+			return fmt.Errorf("decode response: %w", err)
+		}
+		fmt.Printf("%s %s %s: %s\n", p.ID, p.Type, p.Model, p.Status)
+		return nil
+	},
+}
+
+// ─── network autoscale ──────────────────────────────────────────────────────
+
+var networkAutoscaleCmd = &cobra.Command{
+	Use:   "autoscale",
+	Short: "Show predictive autoscaler state and recent decisions",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var resp api.AutoscaleView
+		return networkQuery(cmd, "autoscale", nil, &resp, func(w io.Writer) {
+			fmt.Fprintf(w, "Capacity:\t%d\n", resp.Capacity)
+			fmt.Fprintf(w, "Observations:\t%d\n", resp.Observations)
+			fmt.Fprintf(w, "Confidence:\t%.0f%%\n", resp.Confidence*100)
+			fmt.Fprintf(w, "Proactive:\t%.1f%%\n\n", resp.ProactivePct)
+			fmt.Fprintln(w, "DECIDED\tDIRECTION\tCAPACITY\tFORECAST\tREASON")
+			for _, d := range resp.Decisions {
+				fmt.Fprintf(w, "%s\t%s\t%d → %d\t%.1f\t%s\n",
+					ago(d.DecidedAt), d.Direction, d.Current, d.Target, d.ForecastDemand, d.Reason)
+			}
+		})
+	},
+}
+
+// ─── network incidents ──────────────────────────────────────────────────────
+
+var networkIncidentsCmd = &cobra.Command{
+	Use:   "incidents",
+	Short: "Show active and recently resolved self-healing incidents",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var resp struct {
+			Active   []api.IncidentView `json:"active"`
+			Resolved []api.IncidentView `json:"resolved"`
+		}
+		return networkQuery(cmd, "incidents", nil, &resp, func(w io.Writer) {
+			fmt.Fprintln(w, "ID\tNODE\tFAILURE\tSTATE\tATTEMPTS\tDETECTED")
+			for _, inc := range append(resp.Active, resp.Resolved...) {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%s\n",
+					inc.ID, shortID(inc.NodeID), inc.FailureType, inc.State, inc.Attempts, ago(inc.DetectedAt))
+			}
+		})
+	},
+}
+
+// ─── network votes ──────────────────────────────────────────────────────────
+
+var networkVotesCmd = &cobra.Command{
+	Use:   "votes",
+	Short: "Show governance proposals and their vote tallies",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		params := url.Values{}
+		if status, _ := cmd.Flags().GetString("status"); status != "" {
+			params.Set("status", status)
+		}
+		var resp struct {
+			Proposals []api.ProposalView `json:"proposals"`
+		}
+		return networkQuery(cmd, "votes", params, &resp, func(w io.Writer) {
+			fmt.Fprintln(w, "ID\tSTATUS\tCATEGORY\tTITLE\tAPPROVAL\tQUORUM")
+			for _, p := range resp.Proposals {
+				approval, quorum := "-", "-"
+				if p.Tally != nil {
+					approval = fmt.Sprintf("%.1f%%", p.Tally.ApprovalPct)
+					quorum = fmt.Sprintf("%d/%d", p.Tally.TotalWeight, p.Tally.QuorumWeight)
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", p.ID, p.Status, p.Category, p.Title, approval, quorum)
+			}
+		})
+	},
+}
+
+// ─── Helpers ────────────────────────────────────────────────────────────────
+
+// networkQuery fetches /api/admin/network/<path> and prints it either as
+// raw JSON (--json) or through table after decoding into out.
+func networkQuery(cmd *cobra.Command, path string, params url.Values, out any, table func(w io.Writer)) error {
+	if params == nil {
+		params = url.Values{}
+	}
+	if limit, _ := cmd.Flags().GetInt("limit"); limit > 0 {
+		params.Set("limit", strconv.Itoa(limit))
+	}
+	addr, _ := cmd.Flags().GetString("addr")
+
+	body, err := daemonGet(addr, "/api/admin/network/"+path, params)
+	if err != nil {
+		return err
+	}
+
+	if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+		_, err := os.Stdout.Write(body)
+		return err
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	table(w)
+	return w.Flush()
+}
+
+// daemonGet performs an authenticated GET against the local daemon.
+func daemonGet(addr, path string, params url.Values) ([]byte, error) {
+	req, err := newDaemonRequest(context.Background(), addr, path, params)
+	if err != nil {
+		return nil, err
+	}
+	return daemonDo(req)
+}
+
+// daemonPut performs an authenticated PUT of a JSON body against the local
+// daemon.
+func daemonPut(addr, path string, body []byte) ([]byte, error) {
+	return daemonSend(addr, http.MethodPut, path, body)
+}
+
+// daemonSend performs an authenticated request with an optional JSON body
+// against the local daemon.
+func daemonSend(addr, method, path string, body []byte) ([]byte, error) {
+	req, err := newDaemonRequest(context.Background(), addr, path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Method = method
+	if body != nil {
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		req.ContentLength = int64(len(body))
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return daemonDo(req)
+}
+
+// daemonDo sends req and returns the body of a 2xx response, or the API
+// error message.
+func daemonDo(req *http.Request) ([]byte, error) {
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("contact daemon at %s (is 'tutu serve' running?): %w", req.URL.Host, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+	if resp.StatusCode/100 != 2 {
+		var apiErr struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if json.Unmarshal(body, &apiErr) == nil && apiErr.Error.Message != "" {
+			return nil, fmt.Errorf("%s: %s", resp.Status, apiErr.Error.Message)
+		}
+		return nil, fmt.Errorf("%s", resp.Status)
+	}
+	return body, nil
+}
+
+// newDaemonRequest builds a GET for the daemon at addr (from config when
+// empty), carrying the stored api_key as a bearer token.
+func newDaemonRequest(ctx context.Context, addr, path string, params url.Values) (*http.Request, error) {
+	if addr == "" {
+		cfg, err := daemon.LoadConfig()
+		if err != nil {
+			return nil, err
+		}
+		addr = fmt.Sprintf("%s:%d", cfg.API.Host, cfg.API.Port)
+	}
+	u := "http://" + addr + path
+	if len(params) > 0 {
+		u += "?" + params.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	if store, err := openSecrets(); err == nil {
+		if key, err := store.Get("api_key"); err == nil && key != "" {
+			req.Header.Set("Authorization", "Bearer "+key)
+		}
+	}
+	return req, nil
+}
+
+// shortID trims long node IDs (public-key hex) for table output.
+func shortID(id string) string {
+	if len(id) > 16 {
+		return id[:16]
+	}
+	return id
+}
+
+// ago formats a timestamp relative to now ("-" for zero).
+func ago(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return time.Since(t).Round(time.Second).String() + " ago"
+}
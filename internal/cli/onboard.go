@@ -0,0 +1,151 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/tutu-network/tutu/internal/infra/onboarding"
+)
+
+// ─── Onboarding CLI ─────────────────────────────────────────────────────────
+// Walk the running node through first-run setup (/api/onboarding). Each
+// remaining step runs in order and the progress is shared with the desktop
+// app, so setup started in one can be finished in the other.
+
+func init() {
+	rootCmd.AddCommand(onboardCmd)
+
+	f := onboardCmd.Flags()
+	f.String("addr", "", "Daemon address (default: from config)")
+	f.String("pack", "", "Starter pack: lite, standard or power (default: recommended)")
+	f.StringSlice("models", nil, "Install these catalog models instead of a pack")
+	f.String("invite", "", "Join a federation with this invite code")
+	f.Bool("status", false, "Only show onboarding progress")
+	f.Bool("reset", false, "Start onboarding over")
+	f.Bool("json", false, "Print the raw JSON state")
+}
+
+var onboardCmd = &cobra.Command{
+	Use:   "onboard",
+	Short: "Set up this node: hardware, earnings, models and federation",
+	Example: `  tutu onboard
+  tutu onboard --pack lite --invite tutu-invite-v1.eyJpZCI6...
+  tutu onboard --status`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		f := cmd.Flags()
+		addr, _ := f.GetString("addr")
+		asJSON, _ := f.GetBool("json")
+
+		post := func(step string, body any) (onboarding.State, error) {
+			var payload []byte
+			if body != nil {
+				payload, _ = json.Marshal(body)
+			}
+			raw, err := daemonSend(addr, "POST", "/api/onboarding/"+step, payload)
+			if err != nil {
+				return onboarding.State{}, fmt.Errorf("%s: %w", step, err)
+			}
+			var st onboarding.State
+			return st, json.Unmarshal(raw, &st)
+		}
+
+		var st onboarding.State
+		raw, err := daemonGet(addr, "/api/onboarding", nil)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(raw, &st); err != nil {
+			return fmt.Errorf("decode response: %w", err)
+		}
+		if reset, _ := f.GetBool("reset"); reset {
+			if st, err = post("reset", nil); err != nil {
+				return err
+			}
+		}
+		if status, _ := f.GetBool("status"); !status {
+			for !st.Done() {
+				switch st.Step {
+				case onboarding.StepHardware:
+					st, err = post("hardware", nil)
+				case onboarding.StepBenchmark:
+					fmt.Println("Benchmarking...")
+					st, err = post("benchmark", nil)
+				case onboarding.StepEarnings:
+					st, err = post("earnings", nil)
+				case onboarding.StepModels:
+					pack, _ := f.GetString("pack")
+					models, _ := f.GetStringSlice("models")
+					if pack == "" && len(models) == 0 {
+						pack = st.RecommendedPack
+					}
+					st, err = post("models", map[string]any{"pack": pack, "models": models})
+				case onboarding.StepFederation:
+					if code, _ := f.GetString("invite"); code != "" {
+						st, err = post("federation", map[string]any{"invite_code": code})
+					} else {
+						st, err = post("federation", map[string]any{"skip": true})
+					}
+				default:
+					return fmt.Errorf("unknown onboarding step %q", st.Step)
+				}
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		if asJSON {
+			return json.NewEncoder(os.Stdout).Encode(st)
+		}
+		printOnboarding(st)
+		return nil
+	},
+}
+
+func printOnboarding(st onboarding.State) {
+	if hw := st.Hardware; hw != nil {
+		gpus := "none"
+		if len(hw.GPUs) > 0 {
+			names := make([]string, len(hw.GPUs))
+			for i, g := range hw.GPUs {
+				names[i] = g.Name
+			}
+			gpus = fmt.Sprintf("%s (%.0f GB VRAM)", strings.Join(names, ", "), hw.VRAMGB())
+		}
+		fmt.Printf("Hardware:   %d cores, %.0f GB RAM, GPU %s\n", hw.CPUCores, hw.RAMGB(), gpus)
+	}
+	if p := st.Profile; p != nil {
+		fmt.Printf("Benchmark:  %.0f tok/s, tier %s\n", p.BenchmarkTPS, p.TierName)
+	}
+	if e := st.Earnings; e != nil {
+		mode := "off"
+		if e.IdlePolicy.Enabled {
+			mode = "when you are at least " + e.IdlePolicy.MinIdle + " idle"
+		}
+		fmt.Printf("Earnings:   %s, about %d credits/hour while working\n", mode, e.EstimatedHourlyCredits)
+	}
+	if len(st.Models) > 0 {
+		pack := "custom"
+		if st.Pack != "" {
+			pack = st.Pack + " pack"
+		}
+		fmt.Printf("Models:     %s (%s), downloading in the background\n", strings.Join(st.Models, ", "), pack)
+	}
+	switch {
+	case st.FederationID != "":
+		fmt.Printf("Federation: joined %s\n", st.FederationID)
+	case st.FederationSkipped:
+		fmt.Println("Federation: none (public network)")
+	}
+
+	if st.Done() {
+		fmt.Println("\nSetup complete. Adjust when you earn with 'tutu idle set'.")
+		return
+	}
+	fmt.Printf("\nNext step: %s (%d of %d done). Run 'tutu onboard' to continue.\n",
+		st.Step, len(st.Completed), len(onboarding.Steps))
+}
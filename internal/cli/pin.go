@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/tutu-network/tutu/internal/daemon"
+)
+
+func init() {
+	rootCmd.AddCommand(pinCmd)
+	rootCmd.AddCommand(unpinCmd)
+}
+
+var pinCmd = &cobra.Command{
+	Use:   "pin MODEL",
+	Short: "Protect a model from automatic eviction",
+	Args:  cobra.ExactArgs(1),
+	RunE:  func(cmd *cobra.Command, args []string) error { return setPinned(args[0], true) },
+}
+
+var unpinCmd = &cobra.Command{
+	Use:   "unpin MODEL",
+	Short: "Allow a pinned model to be evicted again",
+	Args:  cobra.ExactArgs(1),
+	RunE:  func(cmd *cobra.Command, args []string) error { return setPinned(args[0], false) },
+}
+
+func setPinned(modelName string, pinned bool) error {
+	d, err := daemon.New()
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+
+	if err := d.Models.SetPinned(modelName, pinned); err != nil {
+		return err
+	}
+
+	if pinned {
+		fmt.Printf("Pinned %s\n", modelName)
+	} else {
+		fmt.Printf("Unpinned %s\n", modelName)
+	}
+	return nil
+}
@@ -7,6 +7,8 @@ import (
 	"os"
 
 	"github.com/spf13/cobra"
+
+	"github.com/tutu-network/tutu/internal/daemon"
 )
 
 var rootCmd = &cobra.Command{
@@ -18,6 +20,17 @@ Run large language models on your machine with zero network, zero accounts.
 Phase 0 (Spark): Single node, full inference, OpenAI-compatible API.`,
 	SilenceUsage:  true,
 	SilenceErrors: true,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		if configPath != "" {
+			daemon.SetConfigFile(configPath)
+		}
+	},
+}
+
+var configPath string
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&configPath, "config", "", "Config file (default: $TUTU_CONFIG or ~/.tutu/config.toml)")
 }
 
 // Execute runs the root command. Called from main.go.
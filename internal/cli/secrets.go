@@ -0,0 +1,164 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/tutu-network/tutu/internal/daemon"
+	"github.com/tutu-network/tutu/internal/security"
+)
+
+// ─── Secrets CLI ────────────────────────────────────────────────────────────
+// Manage the encrypted secrets store (API keys, webhook tokens, marketplace
+// credentials). Values are never printed except when freshly generated.
+
+func init() {
+	rootCmd.AddCommand(secretsCmd)
+	secretsCmd.AddCommand(secretsSetCmd)
+	secretsCmd.AddCommand(secretsRotateCmd)
+	secretsCmd.AddCommand(secretsListCmd)
+	secretsCmd.AddCommand(secretsRemoveCmd)
+}
+
+var secretsCmd = &cobra.Command{
+	Use:   "secrets",
+	Short: "Manage API keys, webhook tokens and other credentials",
+	Long: `Manage credentials stored in TuTu's encrypted secrets file.
+Secrets are encrypted with a key derived from this node's identity and are
+automatically redacted from log output.
+
+Well-known names:
+  api_key          Bearer token required by the HTTP API when set
+  webhook_token    Signs outbound webhooks (health insight reports)
+  billing_webhook  Verifies inbound payment webhooks`,
+}
+
+var secretsSetCmd = &cobra.Command{
+	Use:   "set NAME [VALUE]",
+	Short: "Store a secret (reads VALUE from stdin if omitted)",
+	Args:  cobra.RangeArgs(1, 2),
+	RunE:  runSecretsSet,
+}
+
+func runSecretsSet(cmd *cobra.Command, args []string) error {
+	store, err := openSecrets()
+	if err != nil {
+		return err
+	}
+
+	value := ""
+	if len(args) == 2 {
+		value = args[1]
+	} else {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("read secret from stdin: %w", err)
+		}
+		value = strings.TrimSpace(string(data))
+	}
+	if value == "" {
+		return fmt.Errorf("secret value must not be empty")
+	}
+
+	if err := store.Set(args[0], value); err != nil {
+		return err
+	}
+	fmt.Printf("Stored secret %s\n", args[0])
+	return nil
+}
+
+var secretsRotateCmd = &cobra.Command{
+	Use:   "rotate NAME [VALUE]",
+	Short: "Rotate a secret (generates a random value if omitted)",
+	Long: `Rotate a secret. The previous value stays valid until the next rotation
+so clients can be updated without downtime.`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runSecretsRotate,
+}
+
+func runSecretsRotate(cmd *cobra.Command, args []string) error {
+	store, err := openSecrets()
+	if err != nil {
+		return err
+	}
+
+	value := ""
+	if len(args) == 2 {
+		value = args[1]
+	}
+	newValue, err := store.Rotate(args[0], value)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Rotated secret %s\n", args[0])
+	if value == "" {
+		fmt.Printf("New value: %s\n", newValue)
+	}
+	return nil
+}
+
+var secretsListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List stored secrets (names only)",
+	RunE:    runSecretsList,
+}
+
+func runSecretsList(cmd *cobra.Command, args []string) error {
+	store, err := openSecrets()
+	if err != nil {
+		return err
+	}
+
+	secrets := store.List()
+	if len(secrets) == 0 {
+		fmt.Println("No secrets stored. Run 'tutu secrets set <name>' to add one.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tVERSION\tCREATED\tROTATED")
+	for _, s := range secrets {
+		rotated := "-"
+		if !s.RotatedAt.IsZero() {
+			rotated = s.RotatedAt.Format("2006-01-02 15:04")
+		}
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\n",
+			s.Name, s.Version, s.CreatedAt.Format("2006-01-02 15:04"), rotated)
+	}
+	return w.Flush()
+}
+
+var secretsRemoveCmd = &cobra.Command{
+	Use:   "rm NAME",
+	Short: "Delete a secret",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runSecretsRemove,
+}
+
+func runSecretsRemove(cmd *cobra.Command, args []string) error {
+	store, err := openSecrets()
+	if err != nil {
+		return err
+	}
+	if err := store.Delete(args[0]); err != nil {
+		return err
+	}
+	fmt.Printf("Removed secret %s\n", args[0])
+	return nil
+}
+
+// openSecrets opens the secrets store without starting the full daemon.
+func openSecrets() (*security.SecretStore, error) {
+	home := daemon.TutuHome()
+	kp, err := security.LoadOrCreateKeypair(home)
+	if err != nil {
+		return nil, fmt.Errorf("load node identity: %w", err)
+	}
+	return security.OpenSecretStore(home, kp)
+}
@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/tutu-network/tutu/internal/infra/simulator"
+)
+
+// ─── Simulation CLI ─────────────────────────────────────────────────────────
+// Run the scheduling stack against a virtual fleet and print the Phase 6
+// gate checks. Runs locally; the daemon is not involved.
+
+func init() {
+	rootCmd.AddCommand(simulateCmd)
+
+	d := simulator.DefaultConfig()
+	f := simulateCmd.Flags()
+	f.Int64("seed", 0, "Random seed (the same seed reproduces a run)")
+	f.Int("nodes", d.Nodes, "Virtual nodes in the fleet")
+	f.Duration("duration", d.Duration, "Simulated time to run")
+	f.Duration("step", d.Step, "Simulated time per step")
+	f.Float64("tasks", d.TasksPerStep, "Average tasks arriving per step")
+	f.Float64("latency", d.LatencyMeanMs, "Mean network latency to a node (ms)")
+	f.Float64("failure-rate", d.FailureRate, "Mean per-task failure probability")
+	f.Float64("gpu-fraction", d.GPUFraction, "Share of nodes with a GPU")
+	f.Float64("spike-factor", d.SpikeFactor, "Demand multiplier during the daily surge")
+	f.Bool("json", false, "Print the report as JSON")
+	f.Bool("strict", false, "Exit non-zero unless every gate passes")
+}
+
+var simulateCmd = &cobra.Command{
+	Use:   "simulate",
+	Short: "Check the scheduler, auto-scaler and optimizer gates on a virtual fleet",
+	Example: `  tutu simulate
+  tutu simulate --nodes 500 --duration 168h --seed 42
+  tutu simulate --failure-rate 0.1 --strict`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		f := cmd.Flags()
+		cfg := simulator.DefaultConfig()
+		cfg.Seed, _ = f.GetInt64("seed")
+		cfg.Nodes, _ = f.GetInt("nodes")
+		cfg.Duration, _ = f.GetDuration("duration")
+		cfg.Step, _ = f.GetDuration("step")
+		cfg.TasksPerStep, _ = f.GetFloat64("tasks")
+		cfg.LatencyMeanMs, _ = f.GetFloat64("latency")
+		cfg.FailureRate, _ = f.GetFloat64("failure-rate")
+		cfg.GPUFraction, _ = f.GetFloat64("gpu-fraction")
+		cfg.SpikeFactor, _ = f.GetFloat64("spike-factor")
+
+		report, err := simulator.Run(cfg)
+		if err != nil {
+			return err
+		}
+		if asJSON, _ := f.GetBool("json"); asJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(report); err != nil {
+				return err
+			}
+		} else if err := report.WriteText(os.Stdout); err != nil {
+			return err
+		}
+		if strict, _ := f.GetBool("strict"); strict && !report.Passed {
+			return fmt.Errorf("simulation gates failed")
+		}
+		return nil
+	},
+}
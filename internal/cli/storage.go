@@ -0,0 +1,73 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/tutu-network/tutu/internal/daemon"
+	"github.com/tutu-network/tutu/internal/domain"
+)
+
+func init() {
+	storageCmd.Flags().Bool("enforce", false, "Evict unpinned models until usage is within budget")
+	rootCmd.AddCommand(storageCmd)
+}
+
+var storageCmd = &cobra.Command{
+	Use:   "storage",
+	Short: "Show model storage usage against the budget",
+	RunE:  runStorage,
+}
+
+func runStorage(cmd *cobra.Command, args []string) error {
+	d, err := daemon.New()
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+
+	if enforce, _ := cmd.Flags().GetBool("enforce"); enforce {
+		evicted, err := d.Models.EnforceQuota()
+		for _, name := range evicted {
+			fmt.Printf("Evicted %s\n", name)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	u, err := d.Models.Usage()
+	if err != nil {
+		return err
+	}
+
+	budget := "unlimited"
+	if u.BudgetBytes > 0 {
+		budget = domain.HumanSize(u.BudgetBytes)
+	}
+	fmt.Printf("Used:   %s of %s (%s free)\n", domain.HumanSize(u.UsedBytes), budget, domain.HumanSize(u.FreeBytes))
+	fmt.Printf("Pinned: %s\n\n", domain.HumanSize(u.PinnedBytes))
+
+	if len(u.Models) == 0 {
+		fmt.Println("No models installed.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tSIZE\tPINNED\tLAST USED")
+	for _, m := range u.Models {
+		pinned := ""
+		if m.Pinned {
+			pinned = "yes"
+		}
+		lastUsed := "never"
+		if !m.LastUsed.IsZero() {
+			lastUsed = m.LastUsed.Format("2006-01-02 15:04")
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", m.Name, domain.HumanSize(m.SizeBytes), pinned, lastUsed)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,14 @@
+package daemon
+
+import "github.com/tutu-network/tutu/internal/infra/scheduler"
+
+// admissionSignals samples the load inputs for back-pressure admission:
+// scheduler queue depth and the share of the model pool's memory budget in
+// use. Engine latency is observed by the API on each inference request.
+func (d *Daemon) admissionSignals() scheduler.Signals {
+	sig := scheduler.Signals{QueueDepth: d.Scheduler.QueueDepth()}
+	if used, budget := d.Pool.MemoryUsage(); budget > 0 {
+		sig.MemoryUsed = float64(used) / float64(budget)
+	}
+	return sig
+}
@@ -0,0 +1,103 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/tutu-network/tutu/internal/domain"
+	"github.com/tutu-network/tutu/internal/infra/agent"
+	"github.com/tutu-network/tutu/internal/infra/metrics"
+	"github.com/tutu-network/tutu/internal/infra/tenant"
+)
+
+// ─── Agent Runtime ──────────────────────────────────────────────────────────
+// Agent runs execute as AGENT tasks in executor slots. Steps call the tools
+// registered here; every step is checkpointed to SQLite and traced.
+
+// agentInferenceArgs are the args of the "inference" agent tool.
+type agentInferenceArgs struct {
+	Model     string `json:"model"`
+	Prompt    string `json:"prompt"`
+	MaxTokens int    `json:"max_tokens,omitempty"`
+}
+
+// agentEmbedArgs are the args of the "embed" agent tool.
+type agentEmbedArgs struct {
+	Model  string   `json:"model"`
+	Inputs []string `json:"inputs"`
+}
+
+// registerAgentTools exposes this node's inference and embeddings to agent
+// plans. Costs match the credit pricing of local work: 1 credit per call
+// plus 1 per 1,000 generated tokens.
+func (d *Daemon) registerAgentTools() error {
+	tools := []agent.Tool{
+		{
+			Name:        "inference",
+			Description: "Run a completion. Args: {model, prompt, max_tokens}.",
+			Cost:        1,
+			Run: func(ctx context.Context, raw json.RawMessage) (json.RawMessage, int64, error) {
+				var args agentInferenceArgs
+				if err := json.Unmarshal(raw, &args); err != nil || args.Model == "" {
+					return nil, 0, fmt.Errorf("inference: model and prompt are required")
+				}
+				text, tokens, err := d.mcpGenerate(ctx, args.Model, args.Prompt, args.MaxTokens)
+				if err != nil {
+					return nil, 0, err
+				}
+				out, err := json.Marshal(text)
+				return out, 1 + int64(tokens)/1000, err
+			},
+		},
+		{
+			Name:        "embed",
+			Description: "Compute embeddings. Args: {model, inputs}.",
+			Cost:        1,
+			Run: func(ctx context.Context, raw json.RawMessage) (json.RawMessage, int64, error) {
+				var args agentEmbedArgs
+				if err := json.Unmarshal(raw, &args); err != nil || args.Model == "" || len(args.Inputs) == 0 {
+					return nil, 0, fmt.Errorf("embed: model and inputs are required")
+				}
+				vecs, err := d.Batcher.Embed(ctx, args.Model, mcpLoadOpts(), args.Inputs)
+				if err != nil {
+					return nil, 0, err
+				}
+				out, err := json.Marshal(vecs)
+				return out, 1, err
+			},
+		},
+	}
+	for _, t := range tools {
+		if err := d.Agents.RegisterTool(t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// submitAgentRun starts a created run as an AGENT executor task, labelled
+// with the namespace of the request that created it.
+func (d *Daemon) submitAgentRun(ctx context.Context, id string) error {
+	task := domain.Task{ID: id, Type: domain.TaskAgent, Namespace: tenant.FromContext(ctx)}
+	if err := d.Executor.Submit(context.Background(), task); err != nil {
+		return err
+	}
+	metrics.NamespaceTasks.WithLabelValues(task.Namespace, string(task.Type)).Inc()
+	return nil
+}
+
+// resumeAgentRuns continues runs that were in progress at the last
+// shutdown, from their last checkpoint. They already have executor task
+// records, so they run directly rather than being resubmitted.
+func (d *Daemon) resumeAgentRuns(ctx context.Context, ids []string) {
+	for _, id := range ids {
+		run, err := d.Agents.Run(ctx, id)
+		if err != nil {
+			log.Printf("[agent] resume %s: %v", id, err)
+			continue
+		}
+		log.Printf("[agent] resumed run %s: %s", id, run.Status)
+	}
+}
@@ -0,0 +1,51 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
+)
+
+// ─── Attested Task Results ──────────────────────────────────────────────────
+// The executor signs every completed task with the node key. Network work is
+// reported back with its attestation; the requester pays only after
+// verifying it against the node the journal shows the task was assigned to.
+// Local agent runs stay on this node and are not reported.
+
+// resultDeliveryTimeout bounds reporting one completed task.
+const resultDeliveryTimeout = 30 * time.Second
+
+// deliverTaskResult reports a completed network task, with its attestation,
+// to the requester.
+func (d *Daemon) deliverTaskResult(task domain.Task) {
+	if !isNetworkWork(task) || d.Fabric == nil || !d.Config.Network.Enabled {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), resultDeliveryTimeout)
+	defer cancel()
+	if err := d.Fabric.SubmitTaskResult(ctx, task); err != nil {
+		log.Printf("[executor] task %s: report result: %v", task.ID, err)
+	}
+}
+
+// acceptTaskResult verifies the attestation delivered with a task this node
+// requested and pays the executor the task's credits. The executor is the
+// node journaled as the task's assignee, so a result signed by any other key
+// is rejected without payment.
+func (d *Daemon) acceptTaskResult(task domain.Task) error {
+	if task.Attestation == nil {
+		return fmt.Errorf("task %s: unattested result: %w", task.ID, domain.ErrAttestationInvalid)
+	}
+	if task.Attestation.TaskID != task.ID {
+		return fmt.Errorf("task %s: attestation is for task %s: %w",
+			task.ID, task.Attestation.TaskID, domain.ErrAttestationMismatch)
+	}
+	st, ok := d.Journal.Task(task.ID)
+	if !ok || st.NodeID == "" {
+		return fmt.Errorf("task %s: %w", task.ID, domain.ErrTaskNotAssigned)
+	}
+	return d.Credit.PayAttested(*task.Attestation, st.NodeID, task.Credits)
+}
@@ -0,0 +1,39 @@
+package daemon
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/infra/autoscale"
+)
+
+// ─── Autoscale Backfill ─────────────────────────────────────────────────────
+// The scaler learns its seasonal profile from demand samples, which are
+// lost on restart. With [autoscale] prometheus_url set, the last backfill
+// window of demand is read back from Prometheus in one batch at startup.
+
+// backfillTimeout bounds the startup range query.
+const backfillTimeout = 30 * time.Second
+
+// backfillDemand replays recent demand from Prometheus into the scaler.
+func (d *Daemon) backfillDemand(ctx context.Context) {
+	cfg := d.Config.Autoscale
+	window := parseDuration(cfg.Backfill, 24*time.Hour)
+	ctx, cancel := context.WithTimeout(ctx, backfillTimeout)
+	defer cancel()
+
+	end := time.Now()
+	n, err := d.AutoScaler.IngestPrometheus(ctx, &http.Client{Timeout: backfillTimeout}, autoscale.PromRange{
+		URL:   cfg.PrometheusURL,
+		Query: cfg.PrometheusQuery,
+		Start: end.Add(-window),
+		End:   end,
+	})
+	if err != nil {
+		log.Printf("[autoscale] backfill failed: %v", err)
+		return
+	}
+	log.Printf("[autoscale] backfilled %d demand samples from the last %s", n, window)
+}
@@ -0,0 +1,96 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
+	"github.com/tutu-network/tutu/internal/infra/engine"
+	"github.com/tutu-network/tutu/internal/infra/gossip"
+	"github.com/tutu-network/tutu/internal/infra/registry"
+	"github.com/tutu-network/tutu/internal/infra/sqlite"
+)
+
+// ─── Model Benchmarks ───────────────────────────────────────────────────────
+// The model_benchmark housekeeping job measures one hosted model at a time —
+// tokens/sec and time to first token on this node's hardware — while no
+// network task is running. Results are stored, announced with the model in
+// availability gossip, added to a peer's network latency when the
+// scheduler estimates how long a task will take there, and used as the
+// baseline for the model's speculative decoding speedup.
+
+// benchmarkMaxAge is how long a benchmark stands before it is re-measured.
+const benchmarkMaxAge = 7 * 24 * time.Hour
+
+// benchmarkModel measures model and stores the result.
+func (d *Daemon) benchmarkModel(ctx context.Context, model string) (domain.ModelBenchmark, error) {
+	b, err := d.Pool.Benchmark(ctx, model, engine.DefaultBenchmarkConfig())
+	if err != nil {
+		return domain.ModelBenchmark{}, err
+	}
+	if err := d.DB.UpsertModelBenchmark(b); err != nil {
+		return domain.ModelBenchmark{}, fmt.Errorf("store benchmark: %w", err)
+	}
+	d.Pool.Speculative().SetBaseline(b.Model, b.TokensPerSec)
+	log.Printf("[benchmark] %s: %.1f tok/s, first token in %.0f ms", b.Model, b.TokensPerSec, b.TTFTMs)
+	return b, nil
+}
+
+// benchmarkNext measures the first hosted model that has no benchmark or a
+// stale one. It does nothing while tasks are running, since they would
+// skew the measurement and the benchmark would slow them down.
+func (d *Daemon) benchmarkNext(ctx context.Context) (string, error) {
+	if st := d.Executor.Stats(); st.Active > 0 {
+		return fmt.Sprintf("skipped: %d tasks running", st.Active), nil
+	}
+	infos, err := d.Models.List()
+	if err != nil {
+		return "", fmt.Errorf("list models: %w", err)
+	}
+	existing, err := d.DB.ListModelBenchmarks()
+	if err != nil {
+		return "", fmt.Errorf("list benchmarks: %w", err)
+	}
+	measured := make(map[string]time.Time, len(existing))
+	for _, b := range existing {
+		measured[b.Model] = b.MeasuredAt
+	}
+	for _, m := range infos {
+		if at, ok := measured[m.Name]; ok && time.Since(at) < benchmarkMaxAge {
+			continue
+		}
+		b, err := d.benchmarkModel(ctx, m.Name)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s: %.1f tok/s, %.0f ms to first token", b.Model, b.TokensPerSec, b.TTFTMs), nil
+	}
+	return "all models measured", nil
+}
+
+// hostedModels lists locally stored models for availability announcements,
+// with their benchmarks where measured.
+func hostedModels(mgr *registry.Manager, db *sqlite.DB) []gossip.ModelVersion {
+	infos, err := mgr.List()
+	if err != nil {
+		return nil
+	}
+	benchmarks := make(map[string]domain.ModelBenchmark)
+	if list, err := db.ListModelBenchmarks(); err == nil {
+		for _, b := range list {
+			benchmarks[b.Model] = b
+		}
+	}
+	models := make([]gossip.ModelVersion, 0, len(infos))
+	for _, m := range infos {
+		v := gossip.ModelVersion{Name: m.Name, Digest: m.Digest}
+		if b, ok := benchmarks[m.Name]; ok {
+			v.TokensPerSec = b.TokensPerSec
+			v.TTFTMs = b.TTFTMs
+		}
+		models = append(models, v)
+	}
+	return models
+}
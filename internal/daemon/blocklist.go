@@ -0,0 +1,183 @@
+package daemon
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/infra/gossip"
+	"github.com/tutu-network/tutu/internal/infra/governance"
+	"github.com/tutu-network/tutu/internal/infra/sqlite"
+)
+
+// ─── Blocklist ──────────────────────────────────────────────────────────────
+// A passed SECURITY proposal with one of the keys below bans a node or flags
+// a model digest as malware. This node signs the entry and gossips it; peers
+// that trust its key ([security] blocklist_signers) enforce it too. Entries
+// are persisted and reloaded at startup. Enforcement points:
+//
+//   - the ML scheduler excludes blocked nodes, and work is never stolen
+//     from them
+//   - the marketplace hides blocked models and their creators' listings
+//     and refuses to download them
+//   - the p2p downloader refuses blocked digests and skips blocked peers
+//     (DownloadConfig.ModelBlocked / PeerBlocked)
+//
+// Node bans end after max_quarantine_ban_days; model flags hold until
+// revoked. Either can be appealed for blockAppealWindow through a
+// blocklist.revoke proposal.
+
+// Proposal keys that update the blocklist. The value is the node ID or
+// model digest — for blocklist.revoke, "node:<id>" or "model:<digest>".
+const (
+	proposalBlockNode   = "blocklist.node"
+	proposalBlockModel  = "blocklist.model"
+	proposalBlockRevoke = "blocklist.revoke"
+)
+
+// blockAppealWindow is how long a block can be appealed after it is issued.
+const blockAppealWindow = 14 * 24 * time.Hour
+
+// defaultBanDays bounds node bans when the democracy parameter is missing.
+const defaultBanDays = 30
+
+// executeBlocklistProposals issues the blocklist entries decided by passed
+// SECURITY proposals. Proposals that cannot be applied are logged once and
+// left unexecuted.
+func (d *Daemon) executeBlocklistProposals() {
+	if d.Fabric == nil || d.Keypair == nil {
+		return
+	}
+	if d.blockFailed == nil {
+		d.blockFailed = make(map[string]bool)
+	}
+	passed := governance.PropPassed
+	for _, p := range d.Governance.ListProposals(&passed) {
+		if p.Category != governance.CatSecurity || d.blockFailed[p.ID] {
+			continue
+		}
+		e, ok, err := d.blockEntryFor(p)
+		if !ok {
+			continue
+		}
+		if err == nil {
+			e.Sign(d.Keypair)
+			err = d.Fabric.PublishBlock(e)
+		}
+		if err != nil {
+			d.blockFailed[p.ID] = true
+			log.Printf("[blocklist] proposal %s: %v", p.ID, err)
+			continue
+		}
+		if err := d.Governance.MarkExecuted(p.ID); err != nil {
+			log.Printf("[blocklist] proposal %s: %v", p.ID, err)
+		}
+		action := "blocked"
+		if e.Revoked {
+			action = "revoked"
+		}
+		log.Printf("[blocklist] %s %s (proposal %s)", action, e.Key(), p.ID)
+	}
+}
+
+// blockEntryFor builds the unsigned entry a proposal decides. ok is false
+// for proposals that do not target the blocklist.
+func (d *Daemon) blockEntryFor(p *governance.Proposal) (gossip.BlockEntry, bool, error) {
+	now := time.Now()
+	e := gossip.BlockEntry{
+		Reason:     p.Title,
+		ProposalID: p.ID,
+		IssuedAt:   now,
+	}
+	value := strings.TrimSpace(p.ParamValue)
+	switch p.ParamKey {
+	case proposalBlockNode:
+		e.Kind, e.Subject = gossip.BlockNode, value
+		e.Until = now.AddDate(0, 0, d.maxBanDays())
+	case proposalBlockModel:
+		e.Kind, e.Subject = gossip.BlockModel, normalizeDigest(value)
+	case proposalBlockRevoke:
+		kind, subject, _ := strings.Cut(value, ":")
+		e.Kind, e.Subject, e.Revoked = gossip.BlockKind(kind), subject, true
+		if e.Kind == gossip.BlockModel {
+			e.Subject = normalizeDigest(subject)
+		}
+		if e.Kind != gossip.BlockNode && e.Kind != gossip.BlockModel {
+			return e, true, fmt.Errorf("revoke %q: want node:<id> or model:<digest>", value)
+		}
+	default:
+		return e, false, nil
+	}
+	if e.Subject == "" {
+		return e, true, fmt.Errorf("%s needs a node ID or model digest", p.ParamKey)
+	}
+	if !e.Revoked {
+		e.AppealUntil = now.Add(blockAppealWindow)
+		e.AppealVia = fmt.Sprintf("governance: SECURITY proposal %s=%s", proposalBlockRevoke, e.Key())
+	}
+	return e, true, nil
+}
+
+// maxBanDays is the democracy-governed ceiling on ban length.
+func (d *Daemon) maxBanDays() int {
+	if d.Democracy != nil {
+		if p, err := d.Democracy.GetParam("max_quarantine_ban_days"); err == nil {
+			if n, err := strconv.Atoi(p.CurrentValue); err == nil && n > 0 {
+				return n
+			}
+		}
+	}
+	return defaultBanDays
+}
+
+// normalizeDigest lowercases a model digest and strips its "sha256:" prefix.
+func normalizeDigest(digest string) string {
+	return strings.TrimPrefix(strings.ToLower(digest), "sha256:")
+}
+
+// restoreBlocklist reloads persisted entries and persists every entry
+// accepted from now on.
+func (d *Daemon) restoreBlocklist() error {
+	bl := d.Fabric.Blocklist()
+	now := time.Now()
+	if _, err := d.DB.PruneBlocklistEntries(now); err != nil {
+		return fmt.Errorf("prune blocklist: %w", err)
+	}
+	recs, err := d.DB.ListBlocklistEntries(now)
+	if err != nil {
+		return fmt.Errorf("load blocklist: %w", err)
+	}
+	for _, r := range recs {
+		var e gossip.BlockEntry
+		if err := json.Unmarshal(r.Entry, &e); err != nil {
+			log.Printf("[blocklist] skipping %s: %v", r.Key, err)
+			continue
+		}
+		if _, err := bl.Apply(e); err != nil {
+			log.Printf("[blocklist] skipping %s: %v", r.Key, err)
+		}
+	}
+	bl.OnChange(func(e gossip.BlockEntry) {
+		raw, err := json.Marshal(e)
+		if err == nil {
+			err = d.DB.UpsertBlocklistEntry(sqlite.BlocklistRecord{
+				Key:       e.Key(),
+				Entry:     raw,
+				IssuedAt:  e.IssuedAt,
+				ExpiresAt: bl.Expiry(e),
+			})
+		}
+		if err != nil {
+			log.Printf("[blocklist] persist %s: %v", e.Key(), err)
+		}
+	})
+	return nil
+}
+
+// isBlocked reports whether a node is on the blocklist.
+func (d *Daemon) isBlocked(nodeID string) bool {
+	return d.Fabric != nil && d.Fabric.Blocklist().NodeBlocked(nodeID)
+}
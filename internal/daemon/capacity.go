@@ -0,0 +1,45 @@
+package daemon
+
+import (
+	"log"
+
+	"github.com/tutu-network/tutu/internal/infra/engine"
+	"github.com/tutu-network/tutu/internal/infra/intelligence"
+	"github.com/tutu-network/tutu/internal/infra/registry"
+)
+
+// ─── Placement Capacity ─────────────────────────────────────────────────────
+// The optimizer drops or down-ranks recommendations that would not fit on
+// their destination. This node registers its model storage budget and
+// total VRAM, and the footprint of each local model: its size on disk and,
+// until a device placement measures it, that size again as the VRAM it
+// needs to load fully.
+
+// bytesPerGB converts byte counts to the optimizer's VRAM unit (GiB).
+const bytesPerGB = 1 << 30
+
+// registerCapacity records this node's budgets and its models' footprints.
+func (d *Daemon) registerCapacity(nodeID string, mgr *registry.Manager, diskBytes int64, vramGB float64) {
+	d.Intelligence.SetNodeCapacity(nodeID, diskBytes, vramGB)
+	models, err := mgr.List()
+	if err != nil {
+		log.Printf("[intelligence] model footprints: %v", err)
+		return
+	}
+	for _, m := range models {
+		d.Intelligence.SetModelFootprint(m.Name, intelligence.ModelFootprint{
+			DiskBytes: m.SizeBytes,
+			VRAMGB:    float64(m.SizeBytes) / bytesPerGB,
+		})
+	}
+}
+
+// placementFootprint records the VRAM a device placement reserved for a
+// model.
+func (d *Daemon) placementFootprint(mgr *registry.Manager, model string, p engine.Placement) {
+	f := intelligence.ModelFootprint{VRAMGB: float64(p.Bytes) / bytesPerGB}
+	if info, err := mgr.Show(model); err == nil {
+		f.DiskBytes = info.SizeBytes
+	}
+	d.Intelligence.SetModelFootprint(model, f)
+}
@@ -6,8 +6,19 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"time"
 
 	"github.com/BurntSushi/toml"
+
+	"github.com/tutu-network/tutu/internal/infra/autoscale"
+	"github.com/tutu-network/tutu/internal/infra/gossip"
+	"github.com/tutu-network/tutu/internal/infra/housekeeping"
+	"github.com/tutu-network/tutu/internal/infra/intelligence"
+	"github.com/tutu-network/tutu/internal/infra/logship"
+	"github.com/tutu-network/tutu/internal/infra/nat"
+	"github.com/tutu-network/tutu/internal/infra/netprobe"
+	"github.com/tutu-network/tutu/internal/infra/resource"
+	"github.com/tutu-network/tutu/internal/infra/scheduler"
 )
 
 // Config holds all daemon configuration.
@@ -17,12 +28,23 @@ type Config struct {
 	Models    ModelsConfig    `toml:"models"`
 	Inference InferenceConfig `toml:"inference"`
 	Logging   LoggingConfig   `toml:"logging"`
+	LogShip   LogShipConfig   `toml:"log_ship"`
 	Network   NetworkConfig   `toml:"network"`
 	Resources ResourcesConfig `toml:"resources"`
 	Security  SecurityConfig  `toml:"security"`
 	Telemetry TelemetryConfig `toml:"telemetry"`
 	MCP       MCPConfig       `toml:"mcp"`
 	Agent     AgentConfig     `toml:"agent"`
+
+	// Distributed subsystems — previously hard-coded at construction
+	Gossip       GossipConfig       `toml:"gossip"`
+	Scheduler    SchedulerConfig    `toml:"scheduler"`
+	Autoscale    AutoscaleConfig    `toml:"autoscale"`
+	Intelligence IntelligenceConfig `toml:"intelligence"`
+	NAT          NATConfig          `toml:"nat"`
+	Hierarchy    HierarchyConfig    `toml:"hierarchy"`
+	IdleCompute  IdleComputeConfig  `toml:"idle_compute"`
+	Housekeeping HousekeepingConfig `toml:"housekeeping"`
 }
 
 // NodeConfig identifies this node.
@@ -53,6 +75,32 @@ type InferenceConfig struct {
 	ContextLength int `toml:"context_length"`
 	BatchSize     int `toml:"batch_size"`
 	Threads       int `toml:"threads"`
+
+	// Dynamic batching of embedding requests (engine.Batcher)
+	MaxBatchRequests int `toml:"max_batch_requests"` // embedding requests coalesced per backend call
+	BatchWindowMS    int `toml:"batch_window_ms"`    // max wait for companions, milliseconds
+
+	// Generation guardrails — per-model max_tokens caps (default: context_length)
+	ModelMaxTokens map[string]int `toml:"model_max_tokens"`
+
+	// Token streaming — buffer ahead of slow clients and what to do when full
+	StreamBuffer       int    `toml:"stream_buffer"`        // tokens buffered per stream
+	Backpressure       string `toml:"backpressure"`         // "block", "drop" or "abort"
+	StreamStallSeconds int    `toml:"stream_stall_seconds"` // block policy: abort after this long
+
+	// Speculative decoding — target model → draft model pairing
+	Speculative map[string]SpeculativeConfig `toml:"speculative"`
+
+	// KV-cache reuse — chat sessions pinned to slots, evicted caches saved to disk
+	KVSlots       int    `toml:"kv_slots"`        // parallel sequences per model (1 = no pinning)
+	KVCacheBudget string `toml:"kv_cache_budget"` // disk budget for saved caches, per model
+}
+
+// SpeculativeConfig pairs a large target model with a small draft model.
+type SpeculativeConfig struct {
+	DraftModel  string `toml:"draft_model"`
+	DraftTokens int    `toml:"draft_tokens"` // max tokens per verification pass (default 16)
+	MinDraft    int    `toml:"min_draft"`    // default 1
 }
 
 // LoggingConfig controls logging behavior.
@@ -63,11 +111,35 @@ type LoggingConfig struct {
 	MaxFiles  int    `toml:"max_files"`
 }
 
+// LogShipConfig forwards log lines to a central collector (logship.Shipper).
+// Entries are buffered under tutuHome/logship while the collector is down.
+type LogShipConfig struct {
+	Enabled       bool              `toml:"enabled"`
+	Endpoint      string            `toml:"endpoint"`
+	Format        string            `toml:"format"` // "json" or "otlp"
+	Headers       map[string]string `toml:"headers"`
+	BatchSize     int               `toml:"batch_size"`
+	FlushInterval string            `toml:"flush_interval"`
+	MaxBufferMB   int               `toml:"max_buffer_mb"` // disk buffer cap
+	Redact        []string          `toml:"redact"`        // extra regexps replaced with [REDACTED]
+}
+
 // NetworkConfig controls distributed network participation (Phase 1).
 type NetworkConfig struct {
 	Enabled           bool   `toml:"enabled"`
 	CloudCore         string `toml:"cloud_core"`
 	HeartbeatInterval string `toml:"heartbeat_interval"`
+
+	// Active peer probes: bytes spent per minute, and how often a peer's
+	// availability is checked — new, flappy or low-reputation peers every
+	// probe_min_interval, stable trusted ones up to probe_max_interval.
+	ProbeBudget      string `toml:"probe_budget"`
+	ProbeMinInterval string `toml:"probe_min_interval"`
+	ProbeMaxInterval string `toml:"probe_max_interval"`
+
+	// SeedBindAddr is the TCP socket peers fetch model chunks from ("" =
+	// this node does not serve chunks).
+	SeedBindAddr string `toml:"seed_bind_addr"`
 }
 
 // ResourcesConfig controls the resource governor (Phase 1).
@@ -84,6 +156,10 @@ type SecurityConfig struct {
 	Sandbox        string `toml:"sandbox"`
 	RequireSigning bool   `toml:"require_signing"`
 	TLS            bool   `toml:"tls"`
+
+	// BlocklistSigners are public keys (hex) whose blocklist entries and
+	// quarantine notices this node accepts besides its own.
+	BlocklistSigners []string `toml:"blocklist_signers"`
 }
 
 // TelemetryConfig controls observability (Phase 1).
@@ -91,6 +167,10 @@ type TelemetryConfig struct {
 	Enabled        bool `toml:"enabled"`
 	Prometheus     bool `toml:"prometheus"`
 	PrometheusPort int  `toml:"prometheus_port"`
+
+	// OTLPEndpoint is an OpenTelemetry collector's OTLP/HTTP base URL, e.g.
+	// http://localhost:4318. Empty keeps spans in the local ring buffer only.
+	OTLPEndpoint string `toml:"otlp_endpoint"`
 }
 
 // MCPConfig controls the MCP enterprise gateway (Phase 2).
@@ -110,6 +190,160 @@ type AgentConfig struct {
 	AgentsDir   string `toml:"agents_dir"`   // Directory for agent YAML definitions
 }
 
+// GossipConfig tunes SWIM membership (gossip.Config).
+type GossipConfig struct {
+	BindAddr       string `toml:"bind_addr"`
+	PingTimeout    string `toml:"ping_timeout"`    // ACK timeout
+	Interval       string `toml:"interval"`        // probe cycle
+	SuspectTTL     string `toml:"suspect_ttl"`     // SUSPECT → DEAD
+	IndirectProbes int    `toml:"indirect_probes"` // K
+	Retransmit     int    `toml:"retransmit"`      // piggyback factor λ
+
+	// Replay protection: messages stamped further than max_clock_skew from
+	// the local clock, state updates older than max_update_age and sequence
+	// numbers behind a sender's replay_window are dropped
+	MaxClockSkew string `toml:"max_clock_skew"`
+	MaxUpdateAge string `toml:"max_update_age"`
+	ReplayWindow int    `toml:"replay_window"`
+
+	// Membership snapshots let a fresh node learn the whole cluster from a
+	// seed over TCP instead of waiting for piggybacked updates
+	SnapshotAddr  string   `toml:"snapshot_addr"`  // serve snapshots here ("" = off)
+	SnapshotSeeds []string `toml:"snapshot_seeds"` // seeds' snapshot addresses
+}
+
+// SchedulerConfig tunes the work-stealing task scheduler (scheduler.Config).
+type SchedulerConfig struct {
+	MaxQueueDepth      int    `toml:"max_queue_depth"`
+	BackPressureSoft   int    `toml:"backpressure_soft"`   // reject low priority
+	BackPressureMedium int    `toml:"backpressure_medium"` // reject all but realtime
+	BackPressureHard   int    `toml:"backpressure_hard"`   // reject everything
+	StealBatchSize     int    `toml:"steal_batch_size"`    // 0 = half of peer's queue
+	StarvationInterval string `toml:"starvation_interval"`
+	Preemption         bool   `toml:"preemption"`
+}
+
+// AutoscaleConfig tunes the predictive auto-scaler (autoscale.Config).
+type AutoscaleConfig struct {
+	Alpha              float64 `toml:"alpha"`
+	SeasonalPeriod     int     `toml:"seasonal_period"`
+	SeasonalAlpha      float64 `toml:"seasonal_alpha"`
+	ScaleUpThreshold   float64 `toml:"scale_up_threshold"`
+	ScaleDownThreshold float64 `toml:"scale_down_threshold"`
+	MinCapacity        int     `toml:"min_capacity"`
+	MaxCapacity        int     `toml:"max_capacity"`
+	PreWarmLeadTime    string  `toml:"prewarm_lead_time"`
+	Cooldown           string  `toml:"cooldown"`
+
+	// With PrometheusURL set, the scaler is backfilled at startup with the
+	// last Backfill of demand from a range query (default: tasks completed
+	// per minute, autoscale.DefaultPromQuery).
+	PrometheusURL   string `toml:"prometheus_url"`
+	PrometheusQuery string `toml:"prometheus_query"`
+	Backfill        string `toml:"backfill"`
+}
+
+// IntelligenceConfig tunes placement and retirement (intelligence.Config).
+type IntelligenceConfig struct {
+	RetirementDays          int    `toml:"retirement_days"`
+	PlacementInterval       string `toml:"placement_interval"`
+	MinRequestsForPlacement int64  `toml:"min_requests_for_placement"`
+	MaxRecommendations      int    `toml:"max_recommendations"`
+	MaxRetirementCandidates int    `toml:"max_retirement_candidates"`
+	HealthHistorySize       int    `toml:"health_history_size"`
+	HealthMaxAge            string `toml:"health_max_age"` // patterns older than this drop out of the insights
+
+	// Differential privacy for reported health patterns: Laplace noise with
+	// this budget per report (0 = off), and no per-org reports at all
+	HealthEpsilon       float64 `toml:"health_epsilon"`
+	HealthAggregateOnly bool    `toml:"health_aggregate_only"`
+
+	// PLACE popular models on high-scoring nodes; EVICT where affinity is
+	// very low or VRAM is under pressure
+	PlaceTopModels    int     `toml:"place_top_models"`     // most-requested models eligible for PLACE
+	PlaceMinNodeScore float64 `toml:"place_min_node_score"` // 0..1 mean affinity a node needs (above 1 = never PLACE)
+	EvictMaxAffinity  float64 `toml:"evict_max_affinity"`   // 0..1
+	EvictVRAMPressure float64 `toml:"evict_vram_pressure"`  // 0..1 VRAM fit
+
+	// Recommendations not acknowledged within AckTimeout count as ignored;
+	// rejected, failed or ignored ones wait RetryCooldown (doubling per
+	// repeat) before they are suggested again
+	AckTimeout    string `toml:"ack_timeout"`
+	RetryCooldown string `toml:"retry_cooldown"`
+
+	// RetirementMinReplicas is how many other nodes must still host a model
+	// before this node deletes it (0 = no replica check)
+	RetirementMinReplicas int `toml:"retirement_min_replicas"`
+
+	// A retirement candidate is announced, deleted no sooner than
+	// RetirementNotice + RetirementGrace later, and can be vetoed until then
+	RetirementNotice string `toml:"retirement_notice"`
+	RetirementGrace  string `toml:"retirement_grace"`
+
+	// InsightWebhooks maps org (federation) IDs to URLs that receive their
+	// health comparison report as JSON, signed with the webhook_token secret
+	InsightWebhooks map[string]string `toml:"insight_webhooks"`
+
+	// Latency SLOs — model → target latency ("300ms"); placement weighs
+	// latency more heavily for these models
+	LatencySLOs map[string]string `toml:"latency_slos"`
+}
+
+// NATConfig controls NAT traversal between peers (nat.Traverser). It only
+// takes effect with the network enabled.
+type NATConfig struct {
+	BindAddr      string   `toml:"bind_addr"`    // punch socket
+	STUNServers   []string `toml:"stun_servers"` // address discovery
+	Relay         bool     `toml:"relay"`        // volunteer as a relay for peers
+	RelayBindAddr string   `toml:"relay_bind_addr"`
+}
+
+// HierarchyConfig places the node in a cluster for two-tier gossip
+// (gossip.Hierarchy). The region comes from [node]; with no cluster set,
+// gossip stays flat.
+type HierarchyConfig struct {
+	Zone            string   `toml:"zone"`
+	Cluster         string   `toml:"cluster"`
+	Representatives int      `toml:"representatives"` // per cluster
+	Interval        string   `toml:"interval"`        // summary cadence
+	TTL             string   `toml:"ttl"`             // summary staleness
+	Fanout          int      `toml:"fanout"`          // remote clusters per round
+	Seeds           []string `toml:"seeds"`           // gossip addresses in other clusters
+}
+
+// IdleComputeConfig is the user's budget for lending compute to the network
+// (resource.IdlePolicy). It can be changed on a running node with
+// `tutu idle set`; the file sets the policy the node starts with.
+type IdleComputeConfig struct {
+	Enabled        bool     `toml:"enabled"`
+	MaxCPUPercent  int      `toml:"max_cpu_percent"` // user CPU load ceiling
+	MaxGPUPercent  int      `toml:"max_gpu_percent"`
+	MinIdle        string   `toml:"min_idle"` // active, light, deep, locked
+	Windows        []string `toml:"windows"`  // "22:00-07:00"; empty = any time
+	MinBatteryPct  int      `toml:"min_battery_pct"`
+	RequireACPower bool     `toml:"require_ac_power"`
+	MaxTempC       int      `toml:"max_temp_c"`
+	Interval       string   `toml:"interval"` // re-evaluation period
+}
+
+// HousekeepingConfig schedules the periodic maintenance jobs
+// (housekeeping.Coordinator). Each job has its own interval; jobs listed in
+// Disabled do not run on schedule but can still be run from the admin API.
+type HousekeepingConfig struct {
+	Jitter          float64  `toml:"jitter"`           // ± fraction of each interval
+	Disabled        []string `toml:"disabled"`         // job names
+	ReputationDecay string   `toml:"reputation_decay"` // interval per job
+	AnomalyCleanup  string   `toml:"anomaly_cleanup"`
+	RetentionPrune  string   `toml:"retention_prune"`
+	RetirementScan  string   `toml:"retirement_scan"`
+	ScalerEvaluate  string   `toml:"scaler_evaluate"`
+	ModelBenchmark  string   `toml:"model_benchmark"`
+	HealthInsights  string   `toml:"health_insights"`
+	SchedSnapshot   string   `toml:"scheduler_snapshot"`
+	IntelSnapshot   string   `toml:"intelligence_snapshot"`
+	SeedingSettle   string   `toml:"seeding_settle"`
+}
+
 // DefaultConfig returns a sensible default configuration.
 func DefaultConfig() Config {
 	homeDir := tutuHome()
@@ -134,6 +368,16 @@ func DefaultConfig() Config {
 			ContextLength: 4096,
 			BatchSize:     512,
 			Threads:       0, // auto = runtime.NumCPU() - 2
+
+			MaxBatchRequests: 8,
+			BatchWindowMS:    5,
+
+			StreamBuffer:       64,
+			Backpressure:       "block",
+			StreamStallSeconds: 30,
+
+			KVSlots:       4,
+			KVCacheBudget: "2GB",
 		},
 		Logging: LoggingConfig{
 			Level:     "info",
@@ -141,10 +385,22 @@ func DefaultConfig() Config {
 			MaxSizeMB: 50,
 			MaxFiles:  5,
 		},
+		LogShip: LogShipConfig{
+			Enabled:       false, // Opt-in: needs a collector endpoint
+			Format:        string(logship.FormatJSON),
+			BatchSize:     500,
+			FlushInterval: "5s",
+			MaxBufferMB:   64,
+			Redact:        []string{},
+		},
 		Network: NetworkConfig{
 			Enabled:           false, // Off by default — opt-in
 			CloudCore:         "https://api.tutu.network",
 			HeartbeatInterval: "10s",
+			ProbeBudget:       "4MB",
+			ProbeMinInterval:  "1m",
+			ProbeMaxInterval:  "30m",
+			SeedBindAddr:      ":7948",
 		},
 		Resources: ResourcesConfig{
 			MaxCPUPercent:    80,
@@ -154,9 +410,10 @@ func DefaultConfig() Config {
 			IdleDetection:    true,
 		},
 		Security: SecurityConfig{
-			Sandbox:        "process", // "gvisor" when available
-			RequireSigning: true,
-			TLS:            true,
+			Sandbox:          "process", // "gvisor" when available
+			RequireSigning:   true,
+			TLS:              true,
+			BlocklistSigners: []string{},
 		},
 		Telemetry: TelemetryConfig{
 			Enabled:        true,
@@ -176,19 +433,129 @@ func DefaultConfig() Config {
 			MaxAgents:   4,
 			AgentsDir:   filepath.Join(homeDir, "agents"),
 		},
+		Gossip: GossipConfig{
+			BindAddr:       ":7946",
+			PingTimeout:    "500ms",
+			Interval:       "1s",
+			SuspectTTL:     "5s",
+			IndirectProbes: 3,
+			Retransmit:     3,
+			SnapshotSeeds:  []string{},
+			MaxClockSkew:   "30s",
+			MaxUpdateAge:   "2m",
+			ReplayWindow:   1024,
+		},
+		Scheduler: SchedulerConfig{
+			MaxQueueDepth:      10_000,
+			BackPressureSoft:   1_000,
+			BackPressureMedium: 5_000,
+			BackPressureHard:   10_000,
+			StarvationInterval: "60s",
+			Preemption:         true,
+		},
+		Autoscale: AutoscaleConfig{
+			Alpha:              0.3,
+			SeasonalPeriod:     24,
+			SeasonalAlpha:      0.1,
+			ScaleUpThreshold:   0.8,
+			ScaleDownThreshold: 0.3,
+			MinCapacity:        1,
+			MaxCapacity:        1000,
+			PreWarmLeadTime:    "10m",
+			Cooldown:           "5m",
+			Backfill:           "24h",
+		},
+		Intelligence: IntelligenceConfig{
+			RetirementDays:          30,
+			PlacementInterval:       "168h", // weekly
+			MinRequestsForPlacement: 10,
+			MaxRecommendations:      50,
+			MaxRetirementCandidates: 100,
+			HealthHistorySize:       10_000,
+			HealthMaxAge:            "720h",
+			PlaceTopModels:          10,
+			PlaceMinNodeScore:       0.7,
+			EvictMaxAffinity:        0.1,
+			EvictVRAMPressure:       0.95,
+			AckTimeout:              "168h", // one placement cycle
+			RetryCooldown:           "672h", // four cycles
+			RetirementMinReplicas:   1,
+			RetirementNotice:        "24h",
+			RetirementGrace:         "168h",
+		},
+		NAT: NATConfig{
+			BindAddr:      ":7947",
+			STUNServers:   []string{"stun.tutu.network:3478"},
+			Relay:         false, // Opt-in: forwards other peers' traffic
+			RelayBindAddr: ":3478",
+		},
+		Hierarchy: HierarchyConfig{
+			Representatives: 2,
+			Interval:        "5s",
+			TTL:             "30s",
+			Fanout:          3,
+			Seeds:           []string{},
+		},
+		IdleCompute: IdleComputeConfig{
+			Enabled:       false, // Opt-in: accept network work only when idle
+			MaxCPUPercent: 30,
+			MaxGPUPercent: 30,
+			MinIdle:       "light",
+			Windows:       []string{},
+			MinBatteryPct: 50,
+			MaxTempC:      85,
+			Interval:      "5s",
+		},
+		Housekeeping: HousekeepingConfig{
+			Jitter:          0.1,
+			Disabled:        []string{},
+			ReputationDecay: "1h",
+			AnomalyCleanup:  "1h",
+			RetentionPrune:  "1h",
+			RetirementScan:  "6h",
+			ScalerEvaluate:  "1m",
+			ModelBenchmark:  "30m",
+			HealthInsights:  "24h",
+			SchedSnapshot:   "1m",
+			IntelSnapshot:   "5m",
+			SeedingSettle:   "1h",
+		},
 	}
 }
 
-// LoadConfig reads config from ~/.tutu/config.toml, falling back to defaults.
+// configFile is set by the --config flag; see ConfigFile.
+var configFile string
+
+// SetConfigFile points LoadConfig and SaveConfig at an explicit file.
+func SetConfigFile(path string) { configFile = path }
+
+// ConfigFile returns the config path in effect: --config, then $TUTU_CONFIG,
+// then ~/.tutu/config.toml.
+func ConfigFile() string {
+	if configFile != "" {
+		return configFile
+	}
+	if env := os.Getenv("TUTU_CONFIG"); env != "" {
+		return env
+	}
+	return filepath.Join(tutuHome(), "config.toml")
+}
+
+// LoadConfig reads config from ConfigFile(), falling back to defaults.
 // Environment variables override config file values (cloud-native friendly).
 func LoadConfig() (Config, error) {
-	cfg := DefaultConfig()
-	path := filepath.Join(tutuHome(), "config.toml")
+	return LoadConfigFile(ConfigFile())
+}
 
-	if _, err := os.Stat(path); os.IsNotExist(err) {
-		// No config file — use defaults
-	} else if _, err := toml.DecodeFile(path, &cfg); err != nil {
-		return cfg, fmt.Errorf("parse config: %w", err)
+// LoadConfigFile layers defaults, the TOML file at path (if it exists) and
+// environment overrides. Values are not validated; see Config.Validate.
+func LoadConfigFile(path string) (Config, error) {
+	cfg, _, err := decodeConfigFile(path)
+	if err != nil {
+		return cfg, err
+	}
+	if err := applyEnv(&cfg, os.LookupEnv); err != nil {
+		return cfg, err
 	}
 
 	// Apply auto-detection
@@ -214,9 +581,27 @@ func LoadConfig() (Config, error) {
 	return cfg, nil
 }
 
-// SaveConfig writes the config to ~/.tutu/config.toml.
+// decodeConfigFile decodes path over the defaults and returns any keys the
+// file set that Config does not know about.
+func decodeConfigFile(path string) (Config, []string, error) {
+	cfg := DefaultConfig()
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return cfg, nil, nil // No config file — use defaults
+	}
+	md, err := toml.DecodeFile(path, &cfg)
+	if err != nil {
+		return cfg, nil, fmt.Errorf("parse config: %w", err)
+	}
+	var unknown []string
+	for _, key := range md.Undecoded() {
+		unknown = append(unknown, key.String())
+	}
+	return cfg, unknown, nil
+}
+
+// SaveConfig writes the config to ConfigFile().
 func SaveConfig(cfg Config) error {
-	path := filepath.Join(tutuHome(), "config.toml")
+	path := ConfigFile()
 	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
 		return err
 	}
@@ -244,3 +629,214 @@ func tutuHome() string {
 func TutuHome() string {
 	return tutuHome()
 }
+
+// ─── Subsystem Configs ──────────────────────────────────────────────────────
+// Durations are strings in the file ("500ms", "10m"); Validate rejects bad
+// ones, so the fallbacks below only matter for unvalidated configs.
+
+// SWIM returns the gossip config for this section.
+func (g GossipConfig) SWIM() gossip.Config {
+	def := gossip.DefaultConfig()
+	return gossip.Config{
+		BindAddr:    g.BindAddr,
+		PingTimeout: parseDuration(g.PingTimeout, def.PingTimeout),
+		Interval:    parseDuration(g.Interval, def.Interval),
+		SuspectTTL:  parseDuration(g.SuspectTTL, def.SuspectTTL),
+		K:           g.IndirectProbes,
+		Lambda:      g.Retransmit,
+
+		MaxClockSkew: parseDuration(g.MaxClockSkew, def.MaxClockSkew),
+		MaxUpdateAge: parseDuration(g.MaxUpdateAge, def.MaxUpdateAge),
+		ReplayWindow: g.ReplayWindow,
+	}
+}
+
+// Scheduler returns the scheduler config for this section.
+func (c SchedulerConfig) Scheduler() scheduler.Config {
+	def := scheduler.DefaultConfig()
+	return scheduler.Config{
+		MaxQueueDepth:      c.MaxQueueDepth,
+		BackPressureSoft:   c.BackPressureSoft,
+		BackPressureMedium: c.BackPressureMedium,
+		BackPressureHard:   c.BackPressureHard,
+		StealBatchSize:     c.StealBatchSize,
+		StarvationInterval: parseDuration(c.StarvationInterval, def.StarvationInterval),
+		PreemptionEnabled:  c.Preemption,
+		SLABudgets:         def.SLABudgets,
+		Workers:            def.Workers,
+	}
+}
+
+// Scaler returns the auto-scaler config for this section.
+func (c AutoscaleConfig) Scaler() autoscale.Config {
+	cfg := autoscale.DefaultConfig()
+	cfg.Alpha = c.Alpha
+	cfg.SeasonalPeriod = c.SeasonalPeriod
+	cfg.SeasonalAlpha = c.SeasonalAlpha
+	cfg.ScaleUpThreshold = c.ScaleUpThreshold
+	cfg.ScaleDownThreshold = c.ScaleDownThreshold
+	cfg.MinCapacity = c.MinCapacity
+	cfg.MaxCapacity = c.MaxCapacity
+	cfg.PreWarmLeadTime = parseDuration(c.PreWarmLeadTime, cfg.PreWarmLeadTime)
+	cfg.CooldownPeriod = parseDuration(c.Cooldown, cfg.CooldownPeriod)
+	return cfg
+}
+
+// Prober returns the network profile config for this section.
+func (c NetworkConfig) Prober() netprobe.Config {
+	cfg := netprobe.DefaultConfig()
+	if c.ProbeBudget != "" {
+		cfg.BudgetBytes = int64(parseStorageSize(c.ProbeBudget))
+	}
+	cfg.MinCheckInterval = parseDuration(c.ProbeMinInterval, cfg.MinCheckInterval)
+	cfg.MaxCheckInterval = parseDuration(c.ProbeMaxInterval, cfg.MaxCheckInterval)
+	return cfg
+}
+
+// Optimizer returns the intelligence config for this section.
+func (c IntelligenceConfig) Optimizer() intelligence.Config {
+	cfg := intelligence.DefaultConfig()
+	cfg.RetirementDays = c.RetirementDays
+	cfg.PlacementInterval = parseDuration(c.PlacementInterval, cfg.PlacementInterval)
+	cfg.MinRequestsForPlacement = c.MinRequestsForPlacement
+	cfg.MaxRecommendations = c.MaxRecommendations
+	cfg.MaxRetirementCandidates = c.MaxRetirementCandidates
+	cfg.HealthHistorySize = c.HealthHistorySize
+	cfg.HealthMaxAge = parseDuration(c.HealthMaxAge, cfg.HealthMaxAge)
+	cfg.HealthPrivacy.Epsilon = c.HealthEpsilon
+	cfg.HealthPrivacy.AggregateOnly = c.HealthAggregateOnly
+	cfg.PlaceTopModels = c.PlaceTopModels
+	cfg.PlaceMinNodeScore = c.PlaceMinNodeScore
+	cfg.EvictMaxAffinity = c.EvictMaxAffinity
+	cfg.EvictVRAMPressure = c.EvictVRAMPressure
+	cfg.AckTimeout = parseDuration(c.AckTimeout, cfg.AckTimeout)
+	cfg.RetryCooldown = parseDuration(c.RetryCooldown, cfg.RetryCooldown)
+	return cfg
+}
+
+// Retirement returns the retirement executor config for this section.
+func (c IntelligenceConfig) Retirement() intelligence.RetirementConfig {
+	cfg := intelligence.DefaultRetirementConfig()
+	cfg.MinReplicas = c.RetirementMinReplicas
+	if cfg.MinReplicas == 0 {
+		cfg.MinReplicas = -1 // NewRetirer would read 0 as "default"
+	}
+	cfg.NoticePeriod = parseDuration(c.RetirementNotice, cfg.NoticePeriod)
+	cfg.GracePeriod = parseDuration(c.RetirementGrace, cfg.GracePeriod)
+	return cfg
+}
+
+// SLOs returns the per-model latency objectives for this section.
+func (c IntelligenceConfig) SLOs() map[string]intelligence.ModelSLO {
+	slos := make(map[string]intelligence.ModelSLO, len(c.LatencySLOs))
+	for model, target := range c.LatencySLOs {
+		if d := parseDuration(target, 0); d > 0 {
+			slos[model] = intelligence.ModelSLO{TargetLatencyMs: d.Seconds() * 1000}
+		}
+	}
+	return slos
+}
+
+// Shipper returns the log shipper config for this section.
+func (c LogShipConfig) Shipper() logship.Config {
+	cfg := logship.DefaultConfig()
+	cfg.Endpoint = c.Endpoint
+	cfg.Format = logship.Format(c.Format)
+	cfg.Headers = c.Headers
+	cfg.BatchSize = c.BatchSize
+	cfg.FlushInterval = parseDuration(c.FlushInterval, cfg.FlushInterval)
+	cfg.BufferDir = filepath.Join(tutuHome(), "logship")
+	if c.MaxBufferMB > 0 {
+		cfg.MaxBufferBytes = int64(c.MaxBufferMB) << 20
+	}
+	cfg.Rules = append([]logship.Rule(nil), logship.DefaultRules...)
+	for _, pattern := range c.Redact {
+		cfg.Rules = append(cfg.Rules, logship.Rule{Pattern: pattern, Replace: "[REDACTED]"})
+	}
+	return cfg
+}
+
+// Traverser returns the NAT traversal config for this section.
+func (c NATConfig) Traverser() nat.TraverserConfig {
+	cfg := nat.DefaultTraverserConfig()
+	if len(c.STUNServers) > 0 {
+		cfg.STUNServers = c.STUNServers
+	}
+	return cfg
+}
+
+// Gossip returns the hierarchy config for this section. The caller sets
+// Region.
+func (c HierarchyConfig) Gossip() gossip.HierarchyConfig {
+	cfg := gossip.DefaultHierarchyConfig()
+	cfg.Zone = c.Zone
+	cfg.Cluster = c.Cluster
+	cfg.Representatives = c.Representatives
+	cfg.Interval = parseDuration(c.Interval, cfg.Interval)
+	cfg.TTL = parseDuration(c.TTL, cfg.TTL)
+	cfg.Fanout = c.Fanout
+	if len(c.Seeds) > 0 {
+		cfg.Seeds = c.Seeds
+	}
+	return cfg
+}
+
+// Policy returns the idle compute policy for this section.
+func (c IdleComputeConfig) Policy() resource.IdlePolicy {
+	p := resource.IdlePolicy{
+		Enabled:        c.Enabled,
+		MaxCPUPercent:  c.MaxCPUPercent,
+		MaxGPUPercent:  c.MaxGPUPercent,
+		MinIdle:        c.MinIdle,
+		MinBatteryPct:  c.MinBatteryPct,
+		RequireACPower: c.RequireACPower,
+		MaxTempC:       c.MaxTempC,
+	}
+	if len(c.Windows) > 0 {
+		p.Windows = c.Windows
+	}
+	return p
+}
+
+// Engine returns the policy engine config for this section.
+func (c IdleComputeConfig) Engine() resource.PolicyConfig {
+	cfg := resource.DefaultPolicyConfig()
+	cfg.Interval = parseDuration(c.Interval, cfg.Interval)
+	return cfg
+}
+
+// Coordinator returns the housekeeping coordinator config for this section.
+func (c HousekeepingConfig) Coordinator() housekeeping.Config {
+	cfg := housekeeping.DefaultConfig()
+	cfg.Jitter = c.Jitter
+	if cfg.Jitter == 0 {
+		cfg.Jitter = -1 // explicit 0 means no jitter
+	}
+	return cfg
+}
+
+// Intervals returns each job's interval, by job name.
+func (c HousekeepingConfig) Intervals() map[string]time.Duration {
+	return map[string]time.Duration{
+		jobReputationDecay: parseDuration(c.ReputationDecay, time.Hour),
+		jobAnomalyCleanup:  parseDuration(c.AnomalyCleanup, time.Hour),
+		jobRetentionPrune:  parseDuration(c.RetentionPrune, time.Hour),
+		jobRetirementScan:  parseDuration(c.RetirementScan, 6*time.Hour),
+		jobScalerEvaluate:  parseDuration(c.ScalerEvaluate, time.Minute),
+		jobModelBenchmark:  parseDuration(c.ModelBenchmark, 30*time.Minute),
+		jobHealthInsights:  parseDuration(c.HealthInsights, 24*time.Hour),
+		jobSchedSnapshot:   parseDuration(c.SchedSnapshot, time.Minute),
+		jobIntelSnapshot:   parseDuration(c.IntelSnapshot, 5*time.Minute),
+		jobSeedingSettle:   parseDuration(c.SeedingSettle, time.Hour),
+	}
+}
+
+// Enabled reports whether a job runs on schedule.
+func (c HousekeepingConfig) Enabled(job string) bool {
+	for _, name := range c.Disabled {
+		if name == job {
+			return false
+		}
+	}
+	return true
+}
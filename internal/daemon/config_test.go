@@ -1,7 +1,21 @@
 package daemon
 
 import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
+
+	"github.com/BurntSushi/toml"
+
+	"github.com/tutu-network/tutu/internal/infra/autoscale"
+	"github.com/tutu-network/tutu/internal/infra/gossip"
+	"github.com/tutu-network/tutu/internal/infra/intelligence"
+	"github.com/tutu-network/tutu/internal/infra/nat"
+	"github.com/tutu-network/tutu/internal/infra/resource"
+	"github.com/tutu-network/tutu/internal/infra/scheduler"
 )
 
 func TestDefaultConfig(t *testing.T) {
@@ -63,3 +77,154 @@ func TestParseStorageSize(t *testing.T) {
 		})
 	}
 }
+
+func TestConfig_Validate(t *testing.T) {
+	if err := DefaultConfig().Validate(); err != nil {
+		t.Fatalf("DefaultConfig().Validate() = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(*Config)
+		want   string
+	}{
+		{"port", func(c *Config) { c.API.Port = 70000 }, "api.port"},
+		{"storage", func(c *Config) { c.Models.MaxStorage = "lots" }, "models.max_storage"},
+		{"backpressure", func(c *Config) { c.Inference.Backpressure = "yolo" }, "inference.backpressure"},
+		{"thermal order", func(c *Config) { c.Resources.ThermalThrottle = 99 }, "resources.thermal_throttle"},
+		{"gossip duration", func(c *Config) { c.Gossip.Interval = "soon" }, "gossip.interval"},
+		{"ping vs interval", func(c *Config) { c.Gossip.PingTimeout = "2s" }, "gossip.ping_timeout"},
+		{"snapshot seed", func(c *Config) { c.Gossip.SnapshotSeeds = []string{"seed-1"} }, "gossip.snapshot_seeds"},
+		{"replay window", func(c *Config) { c.Gossip.ReplayWindow = 8 }, "gossip.replay_window"},
+		{"backpressure order", func(c *Config) { c.Scheduler.BackPressureHard = 100 }, "scheduler.backpressure_hard"},
+		{"alpha", func(c *Config) { c.Autoscale.Alpha = 0 }, "autoscale.alpha"},
+		{"capacity", func(c *Config) { c.Autoscale.MaxCapacity = 0 }, "autoscale.max_capacity"},
+		{"retirement", func(c *Config) { c.Intelligence.RetirementDays = 0 }, "intelligence.retirement_days"},
+		{"latency slo", func(c *Config) { c.Intelligence.LatencySLOs = map[string]string{"whisper": "fast"} }, "intelligence.latency_slos.whisper"},
+		{"insight webhook", func(c *Config) { c.Intelligence.InsightWebhooks = map[string]string{"fed-1": "ftp://x"} }, "intelligence.insight_webhooks.fed-1"},
+		{"relay addr", func(c *Config) { c.NAT.Relay, c.NAT.RelayBindAddr = true, "" }, "nat.relay_bind_addr"},
+		{"summary ttl", func(c *Config) { c.Hierarchy.TTL = "1s" }, "hierarchy.ttl"},
+		{"idle window", func(c *Config) { c.IdleCompute.Windows = []string{"night"} }, "idle_compute"},
+		{"housekeeping jitter", func(c *Config) { c.Housekeeping.Jitter = 1.5 }, "housekeeping.jitter"},
+		{"housekeeping job", func(c *Config) { c.Housekeeping.Disabled = []string{"defrag"} }, "housekeeping.disabled"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			tt.mutate(&cfg)
+			err := cfg.Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Validate() = %v, want error mentioning %s", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyEnv(t *testing.T) {
+	env := map[string]string{
+		"TUTU_API_PORT":             "8080",
+		"TUTU_API_CORS_ORIGINS":     "https://a.com, https://b.com",
+		"TUTU_GOSSIP_INTERVAL":      "2s",
+		"TUTU_SCHEDULER_PREEMPTION": "false",
+		"TUTU_AUTOSCALE_ALPHA":      "0.5",
+	}
+	cfg := DefaultConfig()
+	if err := applyEnv(&cfg, func(k string) (string, bool) { v, ok := env[k]; return v, ok }); err != nil {
+		t.Fatalf("applyEnv: %v", err)
+	}
+	if cfg.API.Port != 8080 || cfg.Gossip.Interval != "2s" || cfg.Scheduler.Preemption || cfg.Autoscale.Alpha != 0.5 {
+		t.Errorf("overrides not applied: %+v %+v %+v %+v", cfg.API, cfg.Gossip, cfg.Scheduler, cfg.Autoscale)
+	}
+	if !reflect.DeepEqual(cfg.API.CORSOrigins, []string{"https://a.com", "https://b.com"}) {
+		t.Errorf("CORSOrigins = %v", cfg.API.CORSOrigins)
+	}
+
+	bad := func(k string) (string, bool) { return "many", k == "TUTU_API_PORT" }
+	if err := applyEnv(&cfg, bad); err == nil || !strings.Contains(err.Error(), "TUTU_API_PORT") {
+		t.Errorf("applyEnv(bad int) = %v, want error naming TUTU_API_PORT", err)
+	}
+}
+
+// The [gossip], [scheduler], [autoscale], [intelligence], [nat] and
+// [hierarchy] and [idle_compute] defaults must match the subsystems' own defaults, so an empty
+// config changes nothing.
+func TestSubsystemDefaults(t *testing.T) {
+	cfg := DefaultConfig()
+	if got := cfg.Gossip.SWIM(); got != gossip.DefaultConfig() {
+		t.Errorf("SWIM() = %+v, want %+v", got, gossip.DefaultConfig())
+	}
+	if got := cfg.Scheduler.Scheduler(); !reflect.DeepEqual(got, scheduler.DefaultConfig()) {
+		t.Errorf("Scheduler() = %+v, want %+v", got, scheduler.DefaultConfig())
+	}
+	as, wantAS := cfg.Autoscale.Scaler(), autoscale.DefaultConfig()
+	as.Now, wantAS.Now = nil, nil
+	if !reflect.DeepEqual(as, wantAS) {
+		t.Errorf("Scaler() = %+v, want %+v", as, wantAS)
+	}
+	in, wantIn := cfg.Intelligence.Optimizer(), intelligence.DefaultConfig()
+	in.Now, wantIn.Now = nil, nil
+	if !reflect.DeepEqual(in, wantIn) {
+		t.Errorf("Optimizer() = %+v, want %+v", in, wantIn)
+	}
+	if got := cfg.NAT.Traverser(); !reflect.DeepEqual(got, nat.DefaultTraverserConfig()) {
+		t.Errorf("Traverser() = %+v, want %+v", got, nat.DefaultTraverserConfig())
+	}
+	if got := cfg.Hierarchy.Gossip(); !reflect.DeepEqual(got, gossip.DefaultHierarchyConfig()) {
+		t.Errorf("Gossip() = %+v, want %+v", got, gossip.DefaultHierarchyConfig())
+	}
+	if got := cfg.IdleCompute.Policy(); !reflect.DeepEqual(got, resource.DefaultIdlePolicy()) {
+		t.Errorf("Policy() = %+v, want %+v", got, resource.DefaultIdlePolicy())
+	}
+	if got := cfg.IdleCompute.Engine(); got.Interval != resource.DefaultPolicyConfig().Interval {
+		t.Errorf("Engine().Interval = %s, want %s", got.Interval, resource.DefaultPolicyConfig().Interval)
+	}
+}
+
+func TestWriteDefaults_RoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteDefaults(&buf); err != nil {
+		t.Fatalf("WriteDefaults: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, buf.Bytes(), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var got Config
+	if _, err := toml.DecodeFile(path, &got); err != nil {
+		t.Fatalf("decode generated defaults: %v", err)
+	}
+	if !reflect.DeepEqual(got, DefaultConfig()) {
+		t.Errorf("generated defaults do not decode to DefaultConfig()")
+	}
+}
+
+func TestValidateFile_UnknownKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte("[gossip]\nintervall = \"2s\"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	_, err := ValidateFile(path)
+	if err == nil || !strings.Contains(err.Error(), "gossip.intervall: unknown key") {
+		t.Errorf("ValidateFile = %v, want unknown key error", err)
+	}
+}
+
+func TestRedactConfig_LogShipHeaders(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.LogShip.Headers = map[string]string{"Authorization": "Bearer collector-token"}
+
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(redactConfig(cfg)); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "collector-token") {
+		t.Errorf("log_ship header value in redacted config:\n%s", buf.String())
+	}
+	if !strings.Contains(buf.String(), "Authorization") {
+		t.Error("log_ship header name dropped from redacted config")
+	}
+	if cfg.LogShip.Headers["Authorization"] != "Bearer collector-token" {
+		t.Error("redactConfig modified the live config")
+	}
+}
@@ -2,8 +2,11 @@ package daemon
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -17,9 +20,13 @@ import (
 	"github.com/tutu-network/tutu/internal/app/executor"
 	"github.com/tutu-network/tutu/internal/domain"
 	"github.com/tutu-network/tutu/internal/health"
+	"github.com/tutu-network/tutu/internal/infra/agent"
 	"github.com/tutu-network/tutu/internal/infra/anomaly"
+	"github.com/tutu-network/tutu/internal/infra/audit"
 	"github.com/tutu-network/tutu/internal/infra/autoscale"
 	"github.com/tutu-network/tutu/internal/infra/democracy"
+	"github.com/tutu-network/tutu/internal/infra/diagnostics"
+	"github.com/tutu-network/tutu/internal/infra/embedding"
 	"github.com/tutu-network/tutu/internal/infra/engine"
 	"github.com/tutu-network/tutu/internal/infra/federation"
 	"github.com/tutu-network/tutu/internal/infra/finetune"
@@ -27,14 +34,24 @@ import (
 	"github.com/tutu-network/tutu/internal/infra/gossip"
 	"github.com/tutu-network/tutu/internal/infra/governance"
 	"github.com/tutu-network/tutu/internal/infra/healing"
+	"github.com/tutu-network/tutu/internal/infra/housekeeping"
 	"github.com/tutu-network/tutu/internal/infra/intelligence"
+	"github.com/tutu-network/tutu/internal/infra/journal"
+	"github.com/tutu-network/tutu/internal/infra/logship"
 	"github.com/tutu-network/tutu/internal/infra/marketplace"
+	"github.com/tutu-network/tutu/internal/infra/metrics"
 	_ "github.com/tutu-network/tutu/internal/infra/metrics" // Register Prometheus metrics
 	"github.com/tutu-network/tutu/internal/infra/mlscheduler"
+	"github.com/tutu-network/tutu/internal/infra/nat"
+	"github.com/tutu-network/tutu/internal/infra/netprobe"
 	"github.com/tutu-network/tutu/internal/infra/network"
 	"github.com/tutu-network/tutu/internal/infra/observability"
+	"github.com/tutu-network/tutu/internal/infra/onboarding"
+	"github.com/tutu-network/tutu/internal/infra/p2p"
+	"github.com/tutu-network/tutu/internal/infra/params"
 	"github.com/tutu-network/tutu/internal/infra/passive"
 	"github.com/tutu-network/tutu/internal/infra/planetary"
+	"github.com/tutu-network/tutu/internal/infra/redundancy"
 	"github.com/tutu-network/tutu/internal/infra/region"
 	"github.com/tutu-network/tutu/internal/infra/registry"
 	"github.com/tutu-network/tutu/internal/infra/reputation"
@@ -42,6 +59,8 @@ import (
 	"github.com/tutu-network/tutu/internal/infra/scheduler"
 	"github.com/tutu-network/tutu/internal/infra/selfheal"
 	"github.com/tutu-network/tutu/internal/infra/sqlite"
+	"github.com/tutu-network/tutu/internal/infra/telemetry"
+	"github.com/tutu-network/tutu/internal/infra/tenant"
 	"github.com/tutu-network/tutu/internal/infra/universal"
 	"github.com/tutu-network/tutu/internal/mcp"
 	"github.com/tutu-network/tutu/internal/security"
@@ -49,22 +68,34 @@ import (
 
 // Daemon is the core TuTu runtime. It wires together all services.
 type Daemon struct {
-	Config Config
-	DB     *sqlite.DB
-	Models *registry.Manager
-	Pool   *engine.Pool
-	Server *api.Server
-	cancel context.CancelFunc
+	Config  Config
+	DB      *sqlite.DB
+	Models  *registry.Manager
+	Pool    *engine.Pool
+	Batcher *engine.Batcher
+	Devices *engine.DeviceManager
+	Server  *api.Server
+	cancel  context.CancelFunc
+	started time.Time
 
 	// Phase 1 components
-	Idle     *resource.IdleDetector
-	Governor *resource.Governor
-	Gossip   *gossip.SWIM
-	Fabric   *network.Fabric
-	Executor *executor.Executor
-	Health   *health.Checker
-	Credit   *credit.Service
-	Keypair  *security.Keypair
+	Idle      *resource.IdleDetector
+	Governor  *resource.Governor
+	Gossip    *gossip.SWIM
+	Fabric    *network.Fabric
+	NAT       *nat.Traverser // nil unless the network is enabled
+	Relay     *nat.Relay     // nil unless volunteering as a relay
+	NetProbe  *netprobe.Prober
+	natConn   net.PacketConn // NAT punch socket
+	relayConn net.PacketConn // relay socket
+	chunkLn   net.Listener   // chunk server socket (nil = not seeding)
+	nodeID    string         // this node's identity
+	Executor  *executor.Executor
+	Journal   *journal.Journal
+	Health    *health.Checker
+	Credit    *credit.Service
+	Keypair   *security.Keypair
+	Secrets   *security.SecretStore
 
 	// Phase 2 components
 	Streak       *engagement.StreakService
@@ -72,6 +103,7 @@ type Daemon struct {
 	Achievement  *engagement.AchievementService
 	Quest        *engagement.QuestService
 	Notification *engagement.NotificationService
+	Reports      *engagement.ReportService
 	MCPGateway   *mcp.Gateway
 	MCPTransport *mcp.Transport
 	MCPMeter     *mcp.Meter
@@ -79,11 +111,14 @@ type Daemon struct {
 
 	// Phase 3 components — multi-region, scheduling, self-healing, observability
 	Router     *region.Router
+	Heatmap    *region.Heatmap
 	Scheduler  *scheduler.Scheduler
 	Tracer     *observability.Tracer
+	TaskTraces *observability.TaskTracer
 	Breaker    *healing.CircuitBreaker
 	Quarantine *healing.QuarantineManager
 	Capacity   *passive.CapacityAdvertiser
+	Profiler   *passive.Profiler
 	Prefetcher *passive.Prefetcher
 
 	// Phase 4 components — planet scale, marketplace, fine-tuning
@@ -92,6 +127,7 @@ type Daemon struct {
 
 	// Phase 5 components — federation, governance, reputation, anomaly
 	Federation *federation.Registry
+	Onboarding *onboarding.Wizard
 	Governance *governance.Engine
 	Reputation *reputation.Tracker
 	Anomaly    *anomaly.Detector
@@ -101,12 +137,64 @@ type Daemon struct {
 	AutoScaler   *autoscale.Scaler
 	SelfHeal     *selfheal.Mesh
 	Intelligence *intelligence.Optimizer
+	Retirer      *intelligence.Retirer
 
 	// Phase 7 components — event horizon: world's largest
 	Planetary *planetary.TopologyManager
 	Access    *universal.AccessManager
 	Flywheel  *flywheel.Tracker
 	Democracy *democracy.Engine
+	Audit     *audit.Log
+
+	// Runtime-tunable parameters (admin API + governance execution)
+	Params *params.Service
+
+	// When network work may run on this machine ([idle_compute])
+	IdlePolicy *resource.PolicyEngine
+
+	// Host temperature and power draw; sustained heat throttles the node
+	Telemetry *telemetry.Monitor
+
+	// Namespaces: teams sharing this node with their own keys and limits
+	Tenants *tenant.Registry
+
+	// Recent log lines for diagnostics bundles
+	Logs *diagnostics.LogRing
+
+	// Log forwarding to a central collector (nil = not configured)
+	LogShipper *logship.Shipper
+
+	// Periodic maintenance jobs on one schedule ([housekeeping])
+	Housekeeping *housekeeping.Coordinator
+
+	// Chunk upload limits and choking; settled into credits by seeding_settle
+	Seeder *p2p.Seeder
+
+	// Multi-step agent runs (task type AGENT)
+	Agents      *agent.Orchestrator
+	agentResume []string // runs interrupted by the last shutdown
+
+	// Embedding batch jobs + local vector store
+	Embeddings *embedding.Pipeline
+
+	// N-of-M redundant verification for high-value requests
+	Redundancy *redundancy.Corrector
+
+	// Work stealing between node queues
+	Stealer *scheduler.Stealer
+
+	// Back-pressure admission control
+	Admission *scheduler.Admission
+
+	// Routing-hint affinity for conversation follow-ups
+	Affinity *scheduler.AffinityTable
+
+	// Persisted circuit breakers; peer HTTP calls go through one per host
+	Breakers *healing.Registry
+	PeerHTTP *http.Client
+
+	// Blocklist proposals that could not be applied; not retried
+	blockFailed map[string]bool
 }
 
 // New creates and initializes a Daemon with all services wired.
@@ -115,6 +203,9 @@ func New() (*Daemon, error) {
 	if err != nil {
 		return nil, fmt.Errorf("load config: %w", err)
 	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config %s: %w", ConfigFile(), err)
+	}
 
 	return NewWithConfig(cfg)
 }
@@ -133,6 +224,7 @@ func NewWithConfig(cfg Config) (*Daemon, error) {
 		modelsDir = filepath.Join(tutuHome(), "models")
 	}
 	mgr := registry.NewManager(modelsDir, db)
+	mgr.SetStorageBudget(int64(parseStorageSize(cfg.Models.MaxStorage)))
 
 	// Initialize inference engine
 	// Try real llama-server subprocess backend first
@@ -177,8 +269,106 @@ func NewWithConfig(cfg Config) (*Daemon, error) {
 
 	pool := engine.NewPool(backend, parseStorageSize(cfg.Models.MaxStorage), mgr.Resolve)
 
+	// GPU placement — enumerate devices and track per-device VRAM
+	gpus, err := engine.EnumerateGPUs()
+	if err != nil {
+		log.Printf("[daemon] WARNING: GPU enumeration failed: %v (placement disabled)", err)
+	}
+	devices := engine.NewDeviceManager(gpus)
+	pool.SetDeviceManager(devices)
+
+	// Token streaming — cancellation on disconnect, backpressure on slow clients.
+	// Time to first token feeds back-pressure admission, set up further down
+	var admission *scheduler.Admission
+	pool.SetStreamConfig(engine.StreamConfig{
+		Buffer:       cfg.Inference.StreamBuffer,
+		Policy:       engine.BackpressurePolicy(cfg.Inference.Backpressure),
+		StallTimeout: time.Duration(cfg.Inference.StreamStallSeconds) * time.Second,
+		Observe: func(model string, st engine.StreamStats) {
+			if st.FirstToken > 0 && admission != nil {
+				admission.ObserveLatency(st.FirstToken)
+			}
+			if st.Dropped > 0 {
+				metrics.InferenceTokensDropped.WithLabelValues(model).Add(float64(st.Dropped))
+			}
+			switch {
+			case errors.Is(st.Err, domain.ErrConsumerTooSlow):
+				metrics.InferenceStreamsAborted.WithLabelValues(model, "slow_consumer").Inc()
+			case errors.Is(st.Err, domain.ErrStreamTruncated):
+				metrics.InferenceStreamsAborted.WithLabelValues(model, "backend_eof").Inc()
+			case st.Err != nil:
+				metrics.InferenceStreamsAborted.WithLabelValues(model, "cancelled").Inc()
+			}
+		},
+	})
+
+	// Speculative decoding — draft/target pairs from config
+	for target, sc := range cfg.Inference.Speculative {
+		if err := pool.SetSpeculative(target, engine.SpeculativeConfig{
+			DraftModel:  sc.DraftModel,
+			DraftTokens: sc.DraftTokens,
+			MinDraft:    sc.MinDraft,
+		}); err != nil {
+			log.Printf("[daemon] WARNING: %v (speculative decoding disabled for %s)", err, target)
+		}
+	}
+	pool.Speculative().OnRecord(func(model string, g engine.GenerationTimings) {
+		if g.DraftTokens == 0 {
+			return
+		}
+		metrics.SpeculativeDrafted.WithLabelValues(model).Add(float64(g.DraftTokens))
+		metrics.SpeculativeAccepted.WithLabelValues(model).Add(float64(g.AcceptedTokens))
+		metrics.SpeculativeSpeedup.WithLabelValues(model).Set(pool.Speculative().Stats(model).Speedup)
+	})
+	// Speedup baselines — benchmarks decode without the draft model
+	if list, err := db.ListModelBenchmarks(); err == nil {
+		for _, b := range list {
+			pool.Speculative().SetBaseline(b.Model, b.TokensPerSec)
+		}
+	}
+
+	// KV-cache reuse — pin chat sessions to slots, save evicted caches
+	pool.SetKVCache(engine.KVCacheConfig{
+		Slots:       cfg.Inference.KVSlots,
+		SaveDir:     filepath.Join(tutuHome(), "kvcache"),
+		BudgetBytes: int64(parseStorageSize(cfg.Inference.KVCacheBudget)),
+	})
+	pool.KVCache().OnRecord(func(model string, g engine.GenerationTimings) {
+		metrics.KVCacheTokens.WithLabelValues(model, "evaluated").Add(float64(g.PromptTokens))
+		metrics.KVCacheTokens.WithLabelValues(model, "cached").Add(float64(g.CachedTokens))
+		if g.KVOutcome != "" {
+			metrics.KVCacheSessions.WithLabelValues(model, string(g.KVOutcome)).Inc()
+		}
+		if g.PromptTokens > 0 {
+			saved := g.PromptElapsed.Seconds() / float64(g.PromptTokens) * float64(g.CachedTokens)
+			metrics.KVCacheSavedSeconds.WithLabelValues(model).Add(saved)
+		}
+	})
+
+	// Dynamic batching — coalesce concurrent embedding requests per model
+	batcher := engine.NewBatcher(pool, engine.BatchConfig{
+		MaxBatchSize: cfg.Inference.MaxBatchRequests,
+		MaxDelay:     time.Duration(cfg.Inference.BatchWindowMS) * time.Millisecond,
+		Observe: func(model string, size int, wait time.Duration) {
+			metrics.InferenceBatchSize.WithLabelValues(model).Observe(float64(size))
+			metrics.InferenceBatchWait.WithLabelValues(model).Observe(wait.Seconds())
+		},
+	})
+
 	// Initialize API server
 	srv := api.NewServer(pool, mgr)
+	srv.SetBatcher(batcher)
+
+	// Generation guardrails — server defaults, context-bounded max_tokens
+	limits := engine.DefaultParamLimits()
+	if cfg.Inference.ContextLength > 0 {
+		limits.MaxTokens = cfg.Inference.ContextLength
+	}
+	guard := engine.NewParamGuard(engine.DefaultParamGuard().Defaults(), limits)
+	for model, maxTokens := range cfg.Inference.ModelMaxTokens {
+		guard.SetModelLimits(model, engine.ParamLimits{MaxTokens: maxTokens})
+	}
+	srv.SetParamGuard(guard)
 
 	// Enable Prometheus /metrics if configured
 	if cfg.Telemetry.Prometheus {
@@ -186,11 +376,32 @@ func NewWithConfig(cfg Config) (*Daemon, error) {
 	}
 
 	d := &Daemon{
-		Config: cfg,
-		DB:     db,
-		Models: mgr,
-		Pool:   pool,
-		Server: srv,
+		Config:  cfg,
+		DB:      db,
+		Models:  mgr,
+		Pool:    pool,
+		Batcher: batcher,
+		Devices: devices,
+		Server:  srv,
+		started: time.Now(),
+	}
+
+	// Keep recent log lines in memory for `tutu diagnostics`
+	d.Logs = diagnostics.NewLogRing(1000)
+	logOut := io.MultiWriter(os.Stderr, d.Logs)
+	log.SetOutput(logOut)
+
+	// Ship log lines off headless nodes (secrets are redacted below, before
+	// the shipper's own rules run)
+	if cfg.LogShip.Enabled {
+		shipper, err := logship.New(cfg.LogShip.Shipper())
+		if err != nil {
+			log.Printf("[daemon] WARNING: log shipping disabled: %v", err)
+		} else {
+			d.LogShipper = shipper
+			logOut = io.MultiWriter(logOut, shipper)
+			log.SetOutput(logOut)
+		}
 	}
 
 	// ─── Phase 1 components ────────────────────────────────────────────
@@ -202,6 +413,22 @@ func NewWithConfig(cfg Config) (*Daemon, error) {
 	}
 	d.Keypair = kp
 
+	// Encrypted secrets store (API keys, webhook tokens, marketplace credentials)
+	if kp != nil {
+		secrets, err := security.OpenSecretStore(tutuHome(), kp)
+		if err != nil {
+			log.Printf("[daemon] WARNING: failed to open secrets store: %v", err)
+		} else {
+			d.Secrets = secrets
+			log.SetOutput(security.NewRedactingWriter(logOut, secrets))
+			if secrets.Has(security.SecretAPIKey) {
+				srv.SetAuth(func(token string) bool {
+					return secrets.Matches(security.SecretAPIKey, token)
+				})
+			}
+		}
+	}
+
 	// Derive node ID from public key (first 16 hex chars) if not configured
 	nodeID := cfg.Node.ID
 	if nodeID == "" && kp != nil {
@@ -213,7 +440,10 @@ func NewWithConfig(cfg Config) (*Daemon, error) {
 	if nodeID == "" {
 		nodeID = "node-local"
 	}
-	_ = nodeID // TODO: wire into peer identity in Phase 2
+	if d.LogShipper != nil {
+		d.LogShipper.SetNode(nodeID)
+	}
+	d.nodeID = nodeID
 
 	// Idle detector
 	d.Idle = resource.NewIdleDetector()
@@ -227,11 +457,37 @@ func NewWithConfig(cfg Config) (*Daemon, error) {
 	}
 	d.Governor = resource.NewGovernor(govCfg)
 
+	// Idle compute policy — when network work may run on this machine
+	d.IdlePolicy, err = resource.NewPolicyEngine(cfg.IdleCompute.Engine(), cfg.IdleCompute.Policy(), d.idleSampler())
+	if err != nil {
+		return nil, err
+	}
+
+	// Thermal telemetry — sustained heat keeps heavy work off this node
+	d.Telemetry = telemetry.NewMonitor(telemetry.DefaultConfig(), telemetryReader())
+
 	// Credit service
 	d.Credit = credit.NewService(db)
 
+	// Billing — payment webhooks signed with the billing_webhook secret
+	srv.SetBilling(credit.NewBilling(d.Credit, credit.BillingConfig{
+		Secrets: func() []string {
+			if d.Secrets == nil {
+				return nil
+			}
+			return d.Secrets.Values(security.SecretBillingWebhook)
+		},
+	}))
+
+	// Namespaces — per-team API keys, model allowlists and rate limits
+	d.Tenants, err = tenant.NewRegistry(tenant.Config{Observe: observeNamespace}, db)
+	if err != nil {
+		return nil, err
+	}
+	srv.SetTenants(d.Tenants)
+
 	// SWIM gossip (created by fabric internally, but kept for direct access)
-	gossipCfg := gossip.DefaultConfig()
+	gossipCfg := cfg.Gossip.SWIM()
 
 	// Network fabric
 	fabricCfg := network.FabricConfig{
@@ -240,10 +496,42 @@ func NewWithConfig(cfg Config) (*Daemon, error) {
 		HeartbeatInterval: parseDuration(cfg.Network.HeartbeatInterval, 10*time.Second),
 		Region:            cfg.Node.Region,
 		GossipConfig:      gossipCfg,
+		Availability:      gossip.DefaultAvailabilityConfig(),
 	}
+	// Announce locally stored models on every availability refresh
+	fabricCfg.Availability.Local = func() []gossip.ModelVersion {
+		return hostedModels(mgr, d.DB)
+	}
+	// Advertise queue depth so idle peers can steal work
+	fabricCfg.Load = gossip.DefaultLoadConfig()
+	fabricCfg.Load.Local = func() int {
+		return d.Scheduler.QueueDepth()
+	}
+	// Heartbeat load, free VRAM and loaded models for peers' placement
+	fabricCfg.Heartbeat = gossip.DefaultHeartbeatConfig()
+	fabricCfg.Heartbeat.Local = d.localHeartbeat
+	// Two-tier gossip when this node is placed in a cluster
+	fabricCfg.Hierarchy = cfg.Hierarchy.Gossip()
+	fabricCfg.Hierarchy.Region = cfg.Node.Region
+	// Fast join — fetch full membership from a seed, serve ours to others
+	fabricCfg.SnapshotAddr = cfg.Gossip.SnapshotAddr
+	fabricCfg.SnapshotSeeds = cfg.Gossip.SnapshotSeeds
+	// Blocklist entries are accepted from this node and trusted signers
+	fabricCfg.Blocklist = gossip.DefaultBlocklistConfig()
+	fabricCfg.Blocklist.Signers = cfg.Security.BlocklistSigners
 	if kp != nil {
 		d.Fabric = network.NewFabric(fabricCfg, kp, d.Governor)
 	}
+	// NAT traversal — STUN, gossip-signalled hole punching, volunteer relays
+	if d.Fabric != nil && cfg.Network.Enabled {
+		d.setupNAT(cfg.NAT)
+	}
+
+	// Network profile — RTT and bandwidth per peer, probed within a budget
+	d.NetProbe = netprobe.New(cfg.Network.Prober(), d.netProbeHooks())
+	if d.Fabric != nil && cfg.Network.Enabled {
+		d.Fabric.OnRTT(d.observeRTT)
+	}
 
 	// Task executor
 	execCfg := executor.Config{
@@ -253,6 +541,25 @@ func NewWithConfig(cfg Config) (*Daemon, error) {
 		execCfg.MaxConcurrent = 4
 	}
 	d.Executor = executor.New(execCfg, d.Governor, db)
+	// Completed tasks are attested with the node key for the requester
+	if kp != nil {
+		d.Executor.SetSigner(kp)
+	}
+
+	// Task journal — event-sourced lifecycle, replayed for crash recovery
+	// and exactly-once settlement of attested payments
+	d.Journal, err = journal.New(journal.DefaultConfig(), db)
+	if err != nil {
+		return nil, err
+	}
+	d.Executor.SetJournal(d.Journal, nodeID)
+	d.Credit.SetJournal(d.Journal)
+	srv.SetTaskJournal(d.Journal)
+	d.Executor.SetResultHandler(d.deliverTaskResult)
+	srv.SetTaskResults(&api.TaskResultOps{
+		Accept:  d.acceptTaskResult,
+		Dispute: d.Credit.DisputeAttested,
+	})
 
 	// Health checker
 	d.Health = health.NewChecker(db, modelsDir)
@@ -299,40 +606,87 @@ func NewWithConfig(cfg Config) (*Daemon, error) {
 	routerCfg := region.DefaultConfig()
 	routerCfg.LocalRegion = localRegion
 	d.Router = region.NewRouter(routerCfg)
+	d.Heatmap = region.NewHeatmap(region.DefaultHeatmapConfig())
+	d.Router.SetHeatmap(d.Heatmap)
 
-	// Advanced scheduler — work stealing, back-pressure, preemption
-	d.Scheduler = scheduler.NewScheduler(scheduler.DefaultConfig())
+	// Advanced scheduler — work stealing, back-pressure, preemption,
+	// deadlines (feasibility assumes the executor's parallelism)
+	schedulerCfg := cfg.Scheduler.Scheduler()
+	schedulerCfg.Workers = execCfg.MaxConcurrent
+	d.Scheduler = scheduler.NewScheduler(schedulerCfg)
+	d.restoreSchedulerCounters()
 
 	// Distributed tracing (ring buffer)
 	d.Tracer = observability.NewTracer(observability.DefaultTracerConfig())
 
-	// Self-healing — circuit breaker for Cloud Core calls
-	d.Breaker = healing.NewCircuitBreaker("cloud-core", healing.DefaultCircuitBreakerConfig())
+	// Task traces — one span per lifecycle stage, from the journal, with
+	// the trace context stamped on outgoing task messages
+	d.TaskTraces = observability.NewTaskTracer(d.Tracer, observability.DefaultTaskTraceConfig())
+	d.Journal.OnEvent(d.TaskTraces.Observe)
+	if d.Fabric != nil {
+		d.Fabric.SetTraceParent(d.TaskTraces.TraceParent)
+	}
+
+	// Self-healing — persisted circuit breakers for Cloud Core, peer HTTP
+	// calls and engine invocations
+	d.Breakers = healing.NewRegistry(healing.DefaultCircuitBreakerConfig(), db)
+	if err := d.Breakers.Restore(); err != nil {
+		log.Printf("[daemon] WARNING: %v", err)
+	}
+	d.Breaker = d.Breakers.Breaker("cloud-core")
+	d.PeerHTTP = &http.Client{Timeout: 30 * time.Second, Transport: d.Breakers.Transport(nil)}
+	d.Pool.SetGuard(d.Breakers.Guard("engine:"))
 	d.Quarantine = healing.NewQuarantineManager(healing.DefaultQuarantineConfig())
+	d.Quarantine.SetStore(db)
+	if err := d.Quarantine.Restore(); err != nil {
+		log.Printf("[daemon] WARNING: %v", err)
+	}
 
 	// Passive income — advertise capacity when idle
-	hwTier := passive.ClassifyHardware(0, 0) // Detect at startup; re-classified when sensors report
+	hw := detectHardware()
+	hwTier := passive.ClassifyHardware(hw.CPUCores, hw.VRAMGB()) // confirmed by the profiler's benchmark
 	d.Capacity = passive.NewCapacityAdvertiser(hwTier)
+	d.Profiler = passive.NewProfiler(passive.DefaultProfilerConfig(), detectHardware, nil)
 	d.Prefetcher = passive.NewPrefetcher(5) // Pre-cache top 5 models
 
+	// Morning earnings report — yesterday's credits, tasks and uptime
+	d.Reports = engagement.NewReportService(db, d.Notification, hwTier, d.uptimeHours)
+	engAPI.Reports = d.Reports
+	d.Profiler.OnChange(d.hardwareChanged)
+
 	// ─── Phase 4 components ────────────────────────────────────────────
 
 	// Distributed fine-tuning coordinator
 	d.FineTuneCoordinator = finetune.NewCoordinator(finetune.DefaultCoordinatorConfig())
+	d.FineTuneCoordinator.SetThrottled(d.nodeThrottled(nodeID))
 
 	// Model marketplace
 	d.Marketplace = marketplace.NewStore(marketplace.DefaultStoreConfig())
+	d.Marketplace.SetJobLookup(func(jobID string) (string, bool) {
+		job, err := d.FineTuneCoordinator.GetJob(jobID)
+		if err != nil {
+			return "", false
+		}
+		return job.BaseModel, true
+	})
+	srv.SetMarketplace(d.Marketplace)
 
 	// ─── Phase 5 components ────────────────────────────────────────────
 
 	// Federation registry — private sub-networks for organizations
-	d.Federation = federation.NewRegistry(federation.DefaultRegistryConfig())
+	fedCfg := federation.DefaultRegistryConfig()
+	fedCfg.InvitePath = filepath.Join(tutuHome(), "federation_invites.json")
+	d.Federation = federation.NewRegistry(fedCfg)
+	if err := d.Federation.LoadInvites(); err != nil {
+		log.Printf("[daemon] WARNING: %v", err)
+	}
 
 	// Governance engine — credit-weighted voting on network parameters
 	d.Governance = governance.NewEngine(governance.DefaultEngineConfig())
 
 	// Reputation tracker — EMA-based trust scoring for nodes
 	d.Reputation = reputation.NewTracker(reputation.DefaultTrackerConfig())
+	d.Credit.SetReputation(d.Reputation)
 
 	// Anomaly detector — behavioral profiling + statistical outlier detection
 	d.Anomaly = anomaly.NewDetector(anomaly.DefaultDetectorConfig())
@@ -341,15 +695,65 @@ func NewWithConfig(cfg Config) (*Daemon, error) {
 
 	// ML-driven scheduler — UCB1 multi-armed bandit for optimal node assignment
 	d.MLScheduler = mlscheduler.NewScheduler(mlscheduler.DefaultConfig())
+	if d.Fabric != nil && cfg.Network.Enabled {
+		d.Fabric.SetLearning(d.learningExchange())
+	}
+	d.MLScheduler.SetExclusion(d.schedulerExclusion())
 
 	// Predictive auto-scaler — exponential smoothing + seasonal forecasting
-	d.AutoScaler = autoscale.NewScaler(autoscale.DefaultConfig())
+	d.AutoScaler = autoscale.NewScaler(cfg.Autoscale.Scaler())
 
 	// Self-healing mesh — autonomous incident response with runbooks
 	d.SelfHeal = selfheal.NewMesh(selfheal.DefaultConfig())
 
+	// Corrupt model weights raise a MODEL_CORRUPT incident (runbook: re-pull)
+	mgr.SetCorruptionHook(func(v domain.ModelVerification) {
+		log.Printf("[integrity] model %s failed verification: %s", v.Model, v.Error)
+		d.SelfHeal.Detect(nodeID, selfheal.FailModelCorrupt)
+	})
+
 	// Network intelligence — model placement optimization + retirement
-	d.Intelligence = intelligence.NewOptimizer(intelligence.DefaultConfig())
+	d.Intelligence = intelligence.NewOptimizer(cfg.Intelligence.Optimizer())
+	for model, slo := range cfg.Intelligence.SLOs() {
+		d.Intelligence.SetModelSLO(model, slo)
+	}
+
+	// Learned popularity, affinity and the placement cycle count, and the
+	// recommendation outcomes, survive restarts; outcomes keep suppressing
+	// refused moves
+	d.restoreOptimizerState()
+	d.restorePlacements()
+	d.Intelligence.SetRecommendationHook(d.persistPlacement)
+
+	// Placement only considers nodes that actually host a model
+	if d.Fabric != nil && cfg.Network.Enabled {
+		d.Intelligence.SetAvailability(d.Fabric.Availability())
+	}
+
+	// Retirement candidates are announced, given a grace period in which
+	// they can be vetoed, and deleted only after the safety checks pass
+	networked := d.Fabric != nil && cfg.Network.Enabled
+	retireCfg := cfg.Intelligence.Retirement()
+	if !networked {
+		retireCfg.MinReplicas = -1
+	}
+	d.Retirer = intelligence.NewRetirer(retireCfg, d.retirementHooks(nodeID, networked))
+	d.restoreRetirements()
+
+	// Storage quota evicts models in their retirement grace period first
+	mgr.SetEvictionCandidates(d.Retirer.InGracePeriod)
+
+	// Federated health comparisons go back to the orgs that contributed
+	d.Intelligence.SetHealthReportHook(d.healthReportHook(nodeID, cfg.Intelligence.InsightWebhooks))
+
+	// Recommendations must fit the destination's disk and VRAM
+	d.registerCapacity(nodeID, mgr, int64(parseStorageSize(cfg.Models.MaxStorage)), hw.VRAMGB())
+
+	// Real VRAM fit from device placement drives placement affinity
+	d.Devices.OnPlacement(func(model string, p engine.Placement) {
+		d.Intelligence.SetVRAMFit(nodeID, model, p.VRAMFit)
+		d.placementFootprint(mgr, model, p)
+	})
 
 	// ─── Phase 7 components ────────────────────────────────────────────
 
@@ -365,6 +769,180 @@ func NewWithConfig(cfg Config) (*Daemon, error) {
 	// AI democracy — community governance for all network parameters
 	d.Democracy = democracy.NewEngine(democracy.DefaultConfig())
 
+	// Audit log — hash-chained record of privileged operations
+	d.Audit, err = audit.NewLog(db)
+	if err != nil {
+		return nil, fmt.Errorf("open audit log: %w", err)
+	}
+	d.Governance.SetAuditHook(d.Audit.Hook(domain.AuditGovernance, nodeID))
+	d.Federation.SetAuditHook(d.Audit.Hook(domain.AuditFederation, nodeID))
+	d.Quarantine.SetAuditHook(d.Audit.Hook(domain.AuditQuarantine, nodeID))
+
+	// Network quests — seeded chunks, resolved incidents and governance
+	// votes progress the weekly quests
+	d.Seeder = p2p.NewSeeder(p2p.DefaultSeedConfig())
+	if d.Fabric != nil && cfg.Network.Enabled && cfg.Network.SeedBindAddr != "" {
+		d.setupChunkServer(cfg.Network.SeedBindAddr)
+	}
+	if d.Fabric != nil && cfg.Network.Enabled {
+		d.Models.SetSwarm(d.swarmFetch)
+	}
+	netQuests := engagement.NewNetworkQuests(d.Quest, nodeID, d.questCompleted)
+	netQuests.AttachSeeder(d.Seeder)
+	netQuests.AttachSelfHeal(d.SelfHeal)
+	netQuests.AttachGovernance(d.Governance)
+
+	// Quarantine — triggered by anomaly, reputation and incidents, gossiped
+	// to peers, released on probation once the triggers clear
+	d.Quarantine.SetTriggers(d.quarantineTriggers(nodeID))
+	d.Quarantine.SetProbe(d.probeQuarantined)
+	d.Quarantine.OnChange(d.publishQuarantine)
+
+	// Blocklist — governance bans gossiped with signatures, persisted and
+	// enforced by the scheduler, work stealing and the marketplace
+	if d.Fabric != nil {
+		if err := d.restoreBlocklist(); err != nil {
+			log.Printf("[daemon] WARNING: %v", err)
+		}
+		d.Marketplace.SetBlocklist(d.Fabric.Blocklist())
+	}
+	srv.SetAuditLog(d.Audit)
+
+	// Runtime parameters — hot-reloadable without a restart
+	d.Params = params.NewService(params.DefaultConfig())
+	d.Params.SetStore(db)
+	d.Params.SetAuditHook(d.Audit.Hook(domain.AuditParams, nodeID))
+	if err := d.registerParams(slaEngine); err != nil {
+		return nil, fmt.Errorf("register runtime params: %w", err)
+	}
+	srv.SetParams(d.Params)
+	srv.SetDiagnostics(d.Diagnostics)
+
+	// Network operations views (`tutu network ...`)
+	netOps := &api.NetworkOps{
+		Executor:     d.Executor,
+		Reputation:   d.Reputation,
+		Intelligence: d.Intelligence,
+		AutoScaler:   d.AutoScaler,
+		SelfHeal:     d.SelfHeal,
+		Governance:   d.Governance,
+		SLA:          d.Scheduler.SLAReport,
+	}
+	if d.Fabric != nil && cfg.Network.Enabled {
+		netOps.Peers = d.Fabric.Peers
+		netOps.Hierarchy = d.Fabric.Hierarchy()
+	}
+	srv.SetNetworkOps(netOps)
+
+	// Desktop dashboard — aggregated, ETag-cached home screen
+	srv.SetDashboard(&api.DashboardOps{
+		NodeID:     nodeID,
+		Region:     cfg.Node.Region,
+		StartedAt:  d.started,
+		Executor:   d.Executor,
+		Credit:     d.Credit,
+		AutoScaler: d.AutoScaler,
+		SelfHeal:   d.SelfHeal,
+	})
+
+	// MCP node tools — models, inference, embeddings, status, earnings, marketplace
+	d.MCPGateway.SetBackend(d.mcpBackend(nodeID))
+
+	// Agent runtime — multi-step plans checkpointed to SQLite, traced per step
+	agentCfg := agent.DefaultConfig()
+	agentCfg.SelfID = nodeID
+	d.Agents = agent.New(agentCfg, db, d.Tracer)
+	if err := d.registerAgentTools(); err != nil {
+		return nil, fmt.Errorf("register agent tools: %w", err)
+	}
+	d.agentResume, err = d.Agents.Load()
+	if err != nil {
+		return nil, err
+	}
+	d.Executor.RegisterBackend(domain.TaskAgent, d.Agents)
+	srv.SetAgents(&api.AgentOps{Orchestrator: d.Agents, Submit: d.submitAgentRun})
+
+	// Embedding batches — deduped, sharded corpora with optional vector storage
+	vectors, err := embedding.NewVectorStore(db)
+	if err != nil {
+		return nil, err
+	}
+	embedCfg := embedding.DefaultConfig()
+	embedCfg.SelfID = nodeID
+	d.Embeddings = embedding.NewPipeline(embedCfg, func(ctx context.Context, model string, inputs []string) ([][]float32, error) {
+		return d.Batcher.Embed(ctx, model, mcpLoadOpts(), inputs)
+	}, vectors)
+	srv.SetEmbedBatch(d.Embeddings)
+
+	// Redundant verification (redundancyHooks) is not started until peers
+	// can be asked to run inference: with only this node able to execute,
+	// every verification would fall short of its replicas
+
+	// Work stealing — idle nodes take queued tasks from the busiest peers
+	stealCfg := scheduler.DefaultStealConfig()
+	stealCfg.SelfID = nodeID
+	stealCfg.BatchSize = cfg.Scheduler.StealBatchSize
+	d.Stealer = scheduler.NewStealer(stealCfg, d.Scheduler)
+	if d.Fabric != nil && cfg.Network.Enabled {
+		d.Stealer.SetHooks(d.stealHooks())
+	}
+	srv.SetStealer(d.Stealer)
+
+	// Back-pressure — admit, defer or shed by queue depth, memory and latency
+	admissionCfg := scheduler.DefaultAdmissionConfig()
+	admissionCfg.QueueDepth = [3]int{schedulerCfg.BackPressureSoft, schedulerCfg.BackPressureMedium, schedulerCfg.BackPressureHard}
+	d.Admission = scheduler.NewAdmission(admissionCfg, d.admissionSignals)
+	admission = d.Admission
+	d.Admission.OnTransition(func(t scheduler.Transition) {
+		log.Printf("[scheduler] back-pressure %s → %s (%s)", t.From, t.To, t.Cause)
+	})
+	d.Executor.SetAdmission(func(task domain.Task) error {
+		if err := d.admitIdle(task); err != nil {
+			return err
+		}
+		if err := d.admitThermal(task); err != nil {
+			return err
+		}
+		return d.Admission.Check(task.Priority)
+	})
+	srv.SetAdmission(d.Admission)
+	d.IdlePolicy.OnDrain(d.drainIdle)
+	srv.SetIdlePolicy(d.IdlePolicy)
+	srv.SetHardwareProfiler(d.Profiler)
+	srv.SetModelBenchmarks(&api.BenchmarkOps{
+		List: d.DB.ListModelBenchmarks,
+		Run:  d.benchmarkModel,
+	})
+	srv.SetRetirements(&api.RetirementOps{Optimizer: d.Intelligence, Retirer: d.Retirer})
+	srv.SetSchedulerTrends(d.DB.SchedulerTrends)
+	srv.SetMLScheduler(d.MLScheduler)
+	d.Affinity = scheduler.NewAffinityTable(scheduler.DefaultAffinityConfig())
+	srv.SetAffinity(d.Affinity, nodeID)
+	d.Telemetry.OnChange(d.thermalChange(nodeID))
+
+	// Onboarding — first-run wizard and federation invite codes
+	d.Onboarding, err = onboarding.New(onboarding.Config{
+		Path: filepath.Join(tutuHome(), "onboarding.json"),
+	}, d.onboardingHooks())
+	if err != nil {
+		return nil, err
+	}
+	srv.SetOnboarding(d.Onboarding)
+	srv.SetFederationInviter(d.inviteToFederation)
+
+	// Region routing — persisted region status, federation region limits
+	d.Router.SetAllowedRegions(d.federationRegions)
+	srv.SetRegionRouter(d.Router)
+
+	// Housekeeping — decay, cleanup, pruning, retirement scans, scaler
+	d.Housekeeping = housekeeping.New(cfg.Housekeeping.Coordinator())
+	for _, job := range d.housekeepingJobs(cfg.Housekeeping) {
+		if err := d.Housekeeping.Register(job); err != nil {
+			return nil, err
+		}
+	}
+	srv.SetHousekeeping(d.Housekeeping)
+
 	return d, nil
 }
 
@@ -381,6 +959,57 @@ func (d *Daemon) Serve(ctx context.Context) error {
 	// Health checker (always runs)
 	go d.Health.Run(ctx)
 
+	// Governance — apply passed parameter proposals
+	go d.executeProposals(ctx)
+
+	// MCP — push updates for subscribed live resources
+	go d.MCPTransport.WatchResources(ctx, mcpWatchInterval)
+
+	// Task journal — resume in-flight tasks and agent runs
+	go d.recoverTasks(ctx)
+
+	// Idle compute policy — re-sample the machine, drain when the user returns
+	go d.IdlePolicy.Run(ctx)
+
+	// Thermal telemetry — sample sensors, flag sustained throttle
+	go d.Telemetry.Run(ctx)
+
+	// Earnings reports — generate yesterday's report each morning
+	go d.reportLoop(ctx)
+
+	// Hardware profile — benchmark now, again when the hardware changes
+	go d.Profiler.Run(ctx)
+
+	// Work stealing — reclaim expired grants, steal while idle
+	go d.Stealer.Run(ctx)
+
+	// Back-pressure — re-evaluate the admission level
+	go d.Admission.Run(ctx)
+
+	// Region routing — publish and reload region status
+	go d.regionLoop(ctx)
+
+	// Quarantine — probation checks, auto-release and triggers
+	go d.Quarantine.Run(ctx)
+
+	// Autoscale — relearn recent demand from Prometheus
+	if d.Config.Autoscale.PrometheusURL != "" {
+		go d.backfillDemand(ctx)
+	}
+
+	// Housekeeping — maintenance jobs, incl. scaler evaluation and pre-warm
+	go d.Housekeeping.Run(ctx)
+
+	// Log shipping — batch log lines to the collector, buffer on disk
+	if d.LogShipper != nil {
+		go d.LogShipper.Run(ctx)
+	}
+
+	// Trace export — push new spans to the OTLP collector
+	if d.Config.Telemetry.OTLPEndpoint != "" {
+		go d.traceExportLoop(ctx)
+	}
+
 	// Network fabric (if enabled)
 	if d.Config.Network.Enabled {
 		go func() {
@@ -388,6 +1017,15 @@ func (d *Daemon) Serve(ctx context.Context) error {
 				log.Printf("[daemon] fabric start error: %v", err)
 			}
 		}()
+		go d.capacityLoop(ctx)
+		go d.Seeder.RunRechoke(ctx)
+		if d.chunkLn != nil {
+			go d.serveChunks(ctx)
+		}
+		if d.NAT != nil {
+			go d.natLoop(ctx)
+		}
+		go d.NetProbe.Run(ctx)
 	}
 
 	addr := fmt.Sprintf("%s:%d", d.Config.API.Host, d.Config.API.Port)
@@ -445,9 +1083,23 @@ func (d *Daemon) Close() {
 	if d.Fabric != nil {
 		d.Fabric.Stop()
 	}
+	if d.natConn != nil {
+		_ = d.natConn.Close()
+	}
+	if d.relayConn != nil {
+		_ = d.relayConn.Close()
+	}
+	if d.chunkLn != nil {
+		_ = d.chunkLn.Close()
+	}
 	if d.Pool != nil {
 		_ = d.Pool.UnloadAll()
 	}
+	if d.Intelligence != nil && d.DB != nil {
+		if err := d.saveOptimizerState(); err != nil {
+			log.Printf("[intelligence] save state: %v", err)
+		}
+	}
 	if d.DB != nil {
 		_ = d.DB.Close()
 	}
@@ -484,3 +1136,162 @@ func parseDuration(s string, fallback time.Duration) time.Duration {
 	}
 	return d
 }
+
+// registerParams exposes live subsystem settings to the params service.
+func (d *Daemon) registerParams(sla *mcp.SLAEngine) error {
+	specs := []params.Spec{
+		params.Float("mlscheduler.exploration_factor", "UCB1 exploration constant", 0.01, 10,
+			d.MLScheduler.ExplorationFactor,
+			func(c float64) error { d.MLScheduler.SetExplorationFactor(c); return nil }).
+			WithHealth(d.schedulerHealth),
+		params.Float("autoscale.scale_up_threshold", "Scale up when forecast exceeds capacity × this", 0.01, 1,
+			func() float64 { up, _ := d.AutoScaler.Thresholds(); return up },
+			func(up float64) error {
+				_, down := d.AutoScaler.Thresholds()
+				if !d.AutoScaler.SetThresholds(up, down) {
+					return fmt.Errorf("must exceed scale_down_threshold %g: %w", down, domain.ErrInvalidParamValue)
+				}
+				return nil
+			}).WithHealth(d.autoscaleHealth),
+		params.Float("autoscale.scale_down_threshold", "Scale down when forecast is below capacity × this", 0.01, 1,
+			func() float64 { _, down := d.AutoScaler.Thresholds(); return down },
+			func(down float64) error {
+				up, _ := d.AutoScaler.Thresholds()
+				if !d.AutoScaler.SetThresholds(up, down) {
+					return fmt.Errorf("must be below scale_up_threshold %g: %w", up, domain.ErrInvalidParamValue)
+				}
+				return nil
+			}).WithHealth(d.autoscaleHealth),
+		params.Int("intelligence.retirement_days", "Idle days before a model is a retirement candidate", 1, 3650,
+			d.Intelligence.RetirementDays,
+			func(days int) error { d.Intelligence.SetRetirementDays(days); return nil }),
+	}
+	// Setting one reward weight rescales the other two to keep their ratio
+	for _, obj := range []string{"latency", "cost", "fairness"} {
+		specs = append(specs, params.Float("mlscheduler.reward_weight."+obj, "Share of the scheduler reward given to "+obj, 0, 1,
+			func() float64 { return rewardWeight(d.MLScheduler.RewardWeights(), obj) },
+			func(v float64) error {
+				w, ok := rebalanceRewardWeights(d.MLScheduler.RewardWeights(), obj, v)
+				if !ok || !d.MLScheduler.SetRewardWeights(w) {
+					return fmt.Errorf("other weights are zero, %s must be 1: %w", obj, domain.ErrInvalidParamValue)
+				}
+				return nil
+			}).WithHealth(d.schedulerHealth))
+	}
+	for _, tier := range []domain.SLATier{domain.SLARealtime, domain.SLAStandard, domain.SLABatch, domain.SLASpot} {
+		specs = append(specs, params.Int("mcp.rate_limit_rpm."+string(tier), "MCP requests per minute for the "+string(tier)+" tier", 0, 1_000_000,
+			func() int { return sla.ConfigFor(tier).RateLimitRPM },
+			func(rpm int) error { sla.SetRateLimit(tier, rpm); return nil }))
+	}
+
+	for _, spec := range specs {
+		if err := d.Params.Register(spec); err != nil {
+			return err
+		}
+	}
+
+	// Values changed at runtime (admin API, executed proposals) outlive a
+	// restart: a proposal is executed once and never applied again
+	replayed, errs := d.Params.Replay()
+	for _, err := range errs {
+		log.Printf("[params] WARNING: %v", err)
+	}
+	if len(replayed) > 0 {
+		log.Printf("[params] restored %d runtime parameter values", len(replayed))
+	}
+	return nil
+}
+
+// rewardWeight returns one objective's weight.
+func rewardWeight(w mlscheduler.RewardWeights, obj string) float64 {
+	switch obj {
+	case "latency":
+		return w.Latency
+	case "cost":
+		return w.Cost
+	default:
+		return w.Fairness
+	}
+}
+
+// rebalanceRewardWeights sets one objective's weight to v and scales the
+// other two to share 1-v in their current ratio. It fails if they are
+// both zero and v < 1.
+func rebalanceRewardWeights(w mlscheduler.RewardWeights, obj string, v float64) (mlscheduler.RewardWeights, bool) {
+	rest := 1 - rewardWeight(w, obj)
+	if rest <= 0 {
+		return w, v == 1
+	}
+	scale := (1 - v) / rest
+	w.Latency *= scale
+	w.Cost *= scale
+	w.Fairness *= scale
+	switch obj {
+	case "latency":
+		w.Latency = v
+	case "cost":
+		w.Cost = v
+	default:
+		w.Fairness = v
+	}
+	return w, true
+}
+
+// healthWindow is how many recent scheduling outcomes judge a change to
+// the scheduler's parameters.
+const healthWindow = 500
+
+// schedulerHealth is the negated mean latency of recent ML-scheduled
+// tasks. Rewards are not comparable across reward-weight changes, so
+// latency is the yardstick.
+func (d *Daemon) schedulerHealth() (float64, bool) {
+	obs := d.MLScheduler.Observations(healthWindow)
+	if len(obs) < healthWindow/10 {
+		return 0, false
+	}
+	var sum float64
+	for _, o := range obs {
+		sum += o.LatencyMs
+	}
+	return -sum / float64(len(obs)), true
+}
+
+// autoscaleHealth is the share of demand spikes the auto-scaler handled
+// proactively.
+func (d *Daemon) autoscaleHealth() (float64, bool) {
+	st := d.AutoScaler.Stats()
+	if st.TotalSpikes < 5 {
+		return 0, false
+	}
+	return st.ProactivePct, true
+}
+
+// executeProposals periodically applies passed governance proposals that
+// target runtime parameters, the blocklist or model retirements.
+func (d *Daemon) executeProposals(ctx context.Context) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			changes, errs := d.Params.ExecuteProposals(d.Governance)
+			for _, ch := range changes {
+				log.Printf("[params] %s: %s → %s (%s)", ch.Key, ch.OldValue, ch.NewValue, ch.Source)
+			}
+			for _, err := range errs {
+				log.Printf("[params] governance execution: %v", err)
+			}
+			d.executeBlocklistProposals()
+			d.executeRetirementVetoes()
+			rollbacks, errs := d.Params.CheckProbation()
+			for _, ch := range rollbacks {
+				log.Printf("[params] %s: rolled back to %s after a regression (%s)", ch.Key, ch.NewValue, ch.Source)
+			}
+			for _, err := range errs {
+				log.Printf("[params] rollback: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package daemon
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ─── Environment Overrides ──────────────────────────────────────────────────
+// Every scalar config key can be overridden with TUTU_<SECTION>_<KEY>, e.g.
+//
+//	[api] port            → TUTU_API_PORT=8080
+//	[gossip] interval     → TUTU_GOSSIP_INTERVAL=2s
+//	[api] cors_origins    → TUTU_API_CORS_ORIGINS=https://a.com,https://b.com
+//
+// Precedence: defaults < config file < environment < command-line flags.
+// Map-valued keys (inference.model_max_tokens, inference.speculative,
+// log_ship.headers) are file-only.
+
+// EnvVar returns the environment variable that overrides section.key.
+func EnvVar(section, key string) string {
+	return "TUTU_" + strings.ToUpper(section) + "_" + strings.ToUpper(key)
+}
+
+// applyEnv overrides cfg from the environment. lookup is os.LookupEnv
+// outside tests.
+func applyEnv(cfg *Config, lookup func(string) (string, bool)) error {
+	return walkConfig(cfg, func(section, key string, v reflect.Value) error {
+		raw, ok := lookup(EnvVar(section, key))
+		if !ok {
+			return nil
+		}
+		if err := setFromString(v, raw); err != nil {
+			return fmt.Errorf("%s: %w", EnvVar(section, key), err)
+		}
+		return nil
+	})
+}
+
+// walkConfig calls fn for every scalar or []string key of cfg, by TOML
+// section and key name. Map-valued keys are skipped.
+func walkConfig(cfg *Config, fn func(section, key string, v reflect.Value) error) error {
+	root := reflect.ValueOf(cfg).Elem()
+	for i := 0; i < root.NumField(); i++ {
+		section := root.Type().Field(i).Tag.Get("toml")
+		sv := root.Field(i)
+		for j := 0; j < sv.NumField(); j++ {
+			key := sv.Type().Field(j).Tag.Get("toml")
+			fv := sv.Field(j)
+			if fv.Kind() == reflect.Map {
+				continue
+			}
+			if err := fn(section, key, fv); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func setFromString(v reflect.Value, raw string) error {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("invalid bool %q", raw)
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", raw)
+		}
+		v.SetInt(n)
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", raw)
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// ─── Defaults Documentation ─────────────────────────────────────────────────
+
+// WriteDefaults writes the default configuration as an annotated TOML file:
+// every key with its default value and the environment variable that
+// overrides it. The output is itself a valid config file.
+func WriteDefaults(w io.Writer) error {
+	cfg := DefaultConfig()
+	fmt.Fprintf(w, "# TuTu configuration defaults.\n")
+	fmt.Fprintf(w, "# Precedence: defaults < config file < TUTU_<SECTION>_<KEY> env < flags.\n")
+
+	section := ""
+	err := walkConfig(&cfg, func(sec, key string, v reflect.Value) error {
+		if sec != section {
+			section = sec
+			fmt.Fprintf(w, "\n[%s]\n", sec)
+		}
+		fmt.Fprintf(w, "%s = %s  # %s\n", key, tomlValue(v), EnvVar(sec, key))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "\n# File-only tables:\n")
+	fmt.Fprintf(w, "# [inference.model_max_tokens]\n# \"llama3\" = 2048\n")
+	fmt.Fprintf(w, "# [inference.speculative.\"llama3:70b\"]\n# draft_model = \"llama3:8b\"\n# draft_tokens = 16\n# min_draft = 1\n")
+	fmt.Fprintf(w, "# [log_ship.headers]\n# Authorization = \"Bearer <token>\"\n")
+	return nil
+}
+
+func tomlValue(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.String:
+		return strconv.Quote(v.String())
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64)
+	case reflect.Slice:
+		items := make([]string, v.Len())
+		for i := range items {
+			items[i] = strconv.Quote(v.Index(i).String())
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
@@ -0,0 +1,43 @@
+package daemon
+
+import (
+	"log"
+	"runtime"
+
+	"github.com/tutu-network/tutu/internal/infra/engine"
+	"github.com/tutu-network/tutu/internal/infra/passive"
+	"github.com/tutu-network/tutu/internal/infra/resource"
+)
+
+// ─── Hardware Profile ───────────────────────────────────────────────────────
+// The node's hardware tier is detected at startup from CPU, RAM and GPUs,
+// then confirmed by the profiler's benchmark in the background and again
+// whenever the hardware changes. The tier is recorded on earnings reports,
+// advertised with capacity and gossiped in heartbeats for peers' pricing
+// and scheduling.
+
+// detectHardware reads the host's CPU cores, RAM and GPUs.
+func detectHardware() passive.Hardware {
+	hw := passive.Hardware{CPUCores: runtime.NumCPU(), RAMBytes: resource.TotalMemory()}
+	gpus, _ := engine.EnumerateGPUs()
+	for _, g := range gpus {
+		hw.GPUs = append(hw.GPUs, passive.GPU{Name: g.Name, VRAMBytes: g.VRAMTotal})
+	}
+	return hw
+}
+
+// hardwareChanged applies a new hardware profile.
+func (d *Daemon) hardwareChanged(p passive.Profile) {
+	log.Printf("[hardware] tier %s: %d cores, %.0f GB RAM, %.0f GB VRAM, benchmark %.0f tok/s",
+		p.TierName, p.Hardware.CPUCores, p.Hardware.RAMGB(), p.Hardware.VRAMGB(), p.BenchmarkTPS)
+	d.Capacity.SetTier(p.Tier)
+	d.Reports.SetTier(p.Tier)
+}
+
+// localTier returns the tier gossiped in heartbeats ("" until benchmarked).
+func (d *Daemon) localTier() string {
+	if p, ok := d.Profiler.Profile(); ok {
+		return p.TierName
+	}
+	return ""
+}
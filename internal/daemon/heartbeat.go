@@ -0,0 +1,85 @@
+package daemon
+
+import (
+	"context"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
+	"github.com/tutu-network/tutu/internal/infra/gossip"
+	"github.com/tutu-network/tutu/internal/infra/mlscheduler"
+)
+
+// ─── Load Heartbeats ────────────────────────────────────────────────────────
+// Peers' gossiped heartbeats are the scheduler's view of the network: they
+// fill mlscheduler.Features for placement and give the auto-scaler its
+// current capacity, without any per-task RPC.
+
+// capacitySyncInterval is how often the auto-scaler's capacity is updated
+// from live heartbeats.
+const capacitySyncInterval = 5 * time.Second
+
+// localHeartbeat describes this node for its outgoing heartbeats.
+func (d *Daemon) localHeartbeat() gossip.Heartbeat {
+	st := d.Executor.Stats()
+	h := gossip.Heartbeat{QueueDepth: d.Scheduler.QueueDepth()}
+	if st.MaxSlots > 0 {
+		h.Load = float64(st.Active) / float64(st.MaxSlots)
+	}
+	for _, u := range d.Devices.Usage() {
+		h.FreeVRAM += u.Free()
+	}
+	for _, m := range d.Pool.LoadedModels() {
+		h.HotModels = append(h.HotModels, m.Name)
+	}
+	h.Relay = d.relayAddr()
+	h.ChunkPort = d.chunkPort()
+	h.Throttled = d.Telemetry.Throttled()
+	h.Tier = d.localTier()
+	return h
+}
+
+// peerFeatures returns the scheduler features of running model on a peer.
+// A peer without a fresh heartbeat is scored as fully loaded; latency comes
+// from the network profile plus the peer's published time to first token
+// for model, when it has benchmarked it.
+func (d *Daemon) peerFeatures(p domain.Peer, taskType domain.TaskType, model string) mlscheduler.Features {
+	f := mlscheduler.Features{
+		NodeID:     p.NodeID,
+		TaskType:   string(taskType),
+		NodeLoad:   1,
+		Reputation: p.Reputation,
+	}
+	if d.NetProbe != nil {
+		f.LatencyMs = d.NetProbe.LatencyMs(p.NodeID)
+	}
+	if d.Fabric == nil {
+		return f
+	}
+	if v, ok := d.Fabric.Availability().Model(p.NodeID, model); ok {
+		f.LatencyMs += v.TTFTMs
+	}
+	if h, ok := d.Fabric.Heartbeats().Get(p.NodeID); ok {
+		f.NodeLoad = h.Load
+		f.QueueDepth = h.QueueDepth
+		f.HasModelHot = model != "" && h.IsHot(model)
+		f.GPUAvailable = h.FreeVRAM > 0
+		f.VRAMGB = float64(h.FreeVRAM) / (1 << 30)
+		f.Throttled = h.Throttled
+	}
+	return f
+}
+
+// capacityLoop keeps the auto-scaler's capacity at the number of nodes
+// heartbeating — this one plus every peer with a fresh heartbeat.
+func (d *Daemon) capacityLoop(ctx context.Context) {
+	ticker := time.NewTicker(capacitySyncInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			d.AutoScaler.SetCapacity(1 + len(d.Fabric.Heartbeats().Fresh()))
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/infra/housekeeping"
+)
+
+// ─── Housekeeping ───────────────────────────────────────────────────────────
+// The periodic maintenance jobs run from one coordinator rather than a
+// ticker goroutine apiece, so the ones that write to SQLite never overlap.
+// Intervals and disabled jobs come from [housekeeping]; the admin API shows
+// each job's last run and can run one on demand.
+
+// Housekeeping job names, as used in [housekeeping] and the admin API.
+const (
+	jobReputationDecay = "reputation_decay"
+	jobAnomalyCleanup  = "anomaly_cleanup"
+	jobRetentionPrune  = "retention_prune"
+	jobRetirementScan  = "retirement_scan"
+	jobScalerEvaluate  = "scaler_evaluate"
+	jobModelBenchmark  = "model_benchmark"
+	jobHealthInsights  = "health_insights"
+	jobSchedSnapshot   = "scheduler_snapshot"
+	jobIntelSnapshot   = "intelligence_snapshot"
+	jobSeedingSettle   = "seeding_settle"
+)
+
+// housekeepingJobs returns the maintenance jobs, configured from cfg.
+func (d *Daemon) housekeepingJobs(cfg HousekeepingConfig) []housekeeping.Job {
+	jobs := []housekeeping.Job{
+		{Name: jobReputationDecay, Run: func(context.Context) (string, error) {
+			return fmt.Sprintf("decayed %d nodes", d.Reputation.ApplyDecay()), nil
+		}},
+		{Name: jobAnomalyCleanup, Run: func(context.Context) (string, error) {
+			return fmt.Sprintf("removed %d stale profiles", d.Anomaly.CleanupStaleProfiles()), nil
+		}},
+		{Name: jobRetentionPrune, Run: func(context.Context) (string, error) {
+			d.Journal.Prune()
+			n, err := d.Quest.CleanupExpired()
+			if err != nil {
+				return "", fmt.Errorf("expired quests: %w", err)
+			}
+			snaps, err := d.DB.PruneSchedulerSnapshots(time.Now().Add(-schedulerSnapshotRetention))
+			if err != nil {
+				return "", fmt.Errorf("scheduler snapshots: %w", err)
+			}
+			return fmt.Sprintf("pruned journal, removed %d expired quests and %d scheduler snapshots", n, snaps), nil
+		}},
+		{Name: jobRetirementScan, Run: func(context.Context) (string, error) {
+			candidates := d.Intelligence.ScanRetirements()
+			if len(candidates) > 0 {
+				log.Printf("[housekeeping] %d models are retirement candidates", len(candidates))
+			}
+			changed := d.advanceRetirements(candidates)
+			for _, st := range changed {
+				log.Printf("[housekeeping] retirement of %s: %s", st.Model, st.State)
+			}
+			return fmt.Sprintf("%d retirement candidates, %d workflow changes", len(candidates), len(changed)), nil
+		}},
+		{Name: jobScalerEvaluate, Run: func(context.Context) (string, error) {
+			dec := d.AutoScaler.Evaluate()
+			return fmt.Sprintf("%s, pre-warming %d models", dec.Direction, d.prewarm(dec)), nil
+		}},
+		{Name: jobModelBenchmark, Run: d.benchmarkNext},
+		{Name: jobHealthInsights, Run: d.publishHealthInsights},
+		{Name: jobSchedSnapshot, Run: d.snapshotScheduler},
+		{Name: jobIntelSnapshot, Run: d.snapshotIntelligence},
+		{Name: jobSeedingSettle, Run: d.settleSeeding},
+	}
+	intervals := cfg.Intervals()
+	for i := range jobs {
+		jobs[i].Interval = intervals[jobs[i].Name]
+		jobs[i].Enabled = cfg.Enabled(jobs[i].Name)
+	}
+	return jobs
+}
@@ -0,0 +1,57 @@
+package daemon
+
+import (
+	"log"
+
+	"github.com/tutu-network/tutu/internal/domain"
+	"github.com/tutu-network/tutu/internal/infra/resource"
+)
+
+// ─── Idle Compute Policy ────────────────────────────────────────────────────
+// The [idle_compute] policy gates work taken for the network: the executor
+// refuses it while the policy does not accept, and drains what is running
+// when the user comes back. Agent runs are the user's own work and are
+// never gated or drained.
+
+// idleSampler reads the machine state the policy is evaluated against.
+func (d *Daemon) idleSampler() func() resource.Sample {
+	usage := resource.NewUsageMonitor()
+	thermal := resource.NewThermalMonitor()
+	battery := resource.NewBatteryMonitor()
+	return func() resource.Sample {
+		d.Idle.Update()
+		s := resource.Sample{
+			CPUPercent: usage.CPUPercent(),
+			GPUPercent: usage.GPUPercent(),
+			Idle:       d.Idle.Level(),
+			BatteryPct: 100,
+			CPUTempC:   thermal.CPUTemp(),
+			GPUTempC:   thermal.GPUTemp(),
+		}
+		if battery.IsPresent() {
+			s.OnBattery = !battery.IsCharging()
+			s.BatteryPct = battery.Percentage()
+		}
+		return s
+	}
+}
+
+// admitIdle applies the idle policy to a task submitted to the executor.
+func (d *Daemon) admitIdle(task domain.Task) error {
+	if !isNetworkWork(task) {
+		return nil
+	}
+	return d.IdlePolicy.Admit(task)
+}
+
+// drainIdle stops running network work when the policy says so.
+func (d *Daemon) drainIdle(reason string) {
+	if n := d.Executor.Drain(reason, isNetworkWork); n > 0 {
+		log.Printf("[idle] drained %d tasks: %s", n, reason)
+	}
+}
+
+// isNetworkWork reports whether a task is subject to the idle policy.
+func isNetworkWork(task domain.Task) bool {
+	return task.Type != domain.TaskAgent
+}
@@ -0,0 +1,113 @@
+package daemon
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/app/credit"
+	"github.com/tutu-network/tutu/internal/domain"
+	"github.com/tutu-network/tutu/internal/infra/intelligence"
+	"github.com/tutu-network/tutu/internal/security"
+)
+
+// ─── Health Insight Push ────────────────────────────────────────────────────
+// The health_insights housekeeping job publishes the federated health
+// insights. Each participating org's comparison report goes back to it:
+//
+//   - the org this node belongs to (its federation) gets a notification
+//   - orgs listed in [intelligence] insight_webhooks get the report POSTed
+//     as JSON to their URL
+//
+// Each webhook delivery is signed like a billing webhook: an X-Tutu-Signature
+// header carrying an HMAC-SHA256 of the timestamp and body, keyed with the
+// webhook_token secret (`tutu secrets set webhook_token`), so the org can
+// reject forged or replayed reports. Without the secret no report is sent.
+// A failed delivery is logged and not retried; the next run sends a new
+// report.
+//
+// With [intelligence] health_aggregate_only set no org reports are produced,
+// and health_epsilon adds differential privacy noise to every reported pattern.
+
+// insightClient posts reports to org webhooks.
+var insightClient = &http.Client{Timeout: 10 * time.Second}
+
+// healthReportHook delivers each org's report.
+func (d *Daemon) healthReportHook(nodeID string, webhooks map[string]string) func(intelligence.HealthInsight, []intelligence.OrgHealthReport) {
+	return func(_ intelligence.HealthInsight, reports []intelligence.OrgHealthReport) {
+		localOrg, _ := d.Federation.NodeFederation(nodeID)
+		for _, r := range reports {
+			if r.OrgID == localOrg {
+				_, _ = d.Notification.Create(domain.Notification{
+					Type:      domain.NotifyHealthInsight,
+					Title:     "How your organization compares",
+					Body:      r.Summary,
+					CreatedAt: time.Now(),
+				})
+			}
+			if url := webhooks[r.OrgID]; url != "" {
+				if err := postHealthReport(url, d.webhookToken(), r); err != nil {
+					log.Printf("[insights] %s: %v", r.OrgID, err)
+				}
+			}
+		}
+	}
+}
+
+// webhookToken returns the key outbound webhooks are signed with ("" when
+// it is not set).
+func (d *Daemon) webhookToken() string {
+	if d.Secrets == nil {
+		return ""
+	}
+	token, _ := d.Secrets.Get(security.SecretWebhookToken)
+	return token
+}
+
+// postHealthReport POSTs r as JSON to url, signed with secret.
+func postHealthReport(url, secret string, r intelligence.OrgHealthReport) error {
+	if secret == "" {
+		return fmt.Errorf("report not sent: set the %s secret to sign webhook deliveries", security.SecretWebhookToken)
+	}
+	body, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), insightClient.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(credit.SignatureHeader, credit.SignWebhook(secret, time.Now(), body))
+	resp, err := insightClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook: %s", resp.Status)
+	}
+	return nil
+}
+
+// publishHealthInsights is the health_insights job.
+func (d *Daemon) publishHealthInsights(context.Context) (string, error) {
+	insight, reports := d.Intelligence.PublishHealthInsights()
+	switch {
+	case insight.Withheld:
+		return fmt.Sprintf("%d patterns from fewer than %d orgs; aggregates withheld", insight.OrgCount, intelligence.MinInsightOrgs), nil
+	case d.Config.Intelligence.HealthAggregateOnly:
+		return fmt.Sprintf("aggregates only; network failure rate %.1f%% (%.1f%% weighted by tasks)",
+			insight.AvgFailureRate*100, insight.WeightedFailureRate*100), nil
+	case len(reports) == 0:
+		return fmt.Sprintf("%d orgs reporting, need %d to compare", insight.OrgCount, intelligence.MinInsightOrgs), nil
+	}
+	return fmt.Sprintf("sent %d org reports; network failure rate %.1f%% (%.1f%% weighted by tasks)",
+		len(reports), insight.AvgFailureRate*100, insight.WeightedFailureRate*100), nil
+}
@@ -0,0 +1,47 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/tutu-network/tutu/internal/infra/journal"
+)
+
+// ─── Task Journal ───────────────────────────────────────────────────────────
+// Every executor task's lifecycle is journaled to SQLite. On start, tasks
+// that were in flight at the last shutdown are replayed from the journal and
+// handed back to the executor; agent runs that predate the journal still
+// resume from their checkpoints directly. Finished tasks are pruned by the
+// retention_prune housekeeping job.
+
+// recoverTasks resumes the tasks the journal shows in flight, then any
+// interrupted agent run the journal did not cover.
+func (d *Daemon) recoverTasks(ctx context.Context) {
+	resumed := make(map[string]bool)
+	n := d.Journal.Recover(func(st journal.TaskState) error {
+		task, err := d.DB.GetTask(st.TaskID)
+		if err != nil {
+			return err
+		}
+		if task == nil {
+			return errors.New("no task record")
+		}
+		if err := d.Executor.Resume(ctx, *task); err != nil {
+			return err
+		}
+		resumed[st.TaskID] = true
+		return nil
+	})
+	if n > 0 {
+		log.Printf("[journal] resumed %d in-flight tasks", n)
+	}
+
+	var runs []string
+	for _, id := range d.agentResume {
+		if !resumed[id] {
+			runs = append(runs, id)
+		}
+	}
+	d.resumeAgentRuns(ctx, runs)
+}
@@ -0,0 +1,46 @@
+package daemon
+
+import (
+	"encoding/json"
+
+	"github.com/tutu-network/tutu/internal/infra/gossip"
+	"github.com/tutu-network/tutu/internal/infra/mlscheduler"
+)
+
+// ─── Fleet Learning ─────────────────────────────────────────────────────────
+// The ML scheduler's arm statistics are exchanged over gossip, so a new
+// node starts from the fleet's experience rather than from scratch. A
+// peer's summary is weighted by its reputation; quarantined peers and known
+// threats contribute nothing, and what they shared before is forgotten.
+
+// learningExchange connects the ML scheduler to the gossip exchange.
+func (d *Daemon) learningExchange() gossip.LearningConfig {
+	return gossip.LearningConfig{
+		Local: func() []byte {
+			sums := d.MLScheduler.Summaries()
+			if len(sums) == 0 {
+				return nil
+			}
+			payload, err := json.Marshal(sums)
+			if err != nil {
+				return nil
+			}
+			return payload
+		},
+		Apply: func(from string, payload []byte) {
+			var sums []mlscheduler.ArmSummary
+			if err := json.Unmarshal(payload, &sums); err != nil {
+				return
+			}
+			d.MLScheduler.MergeRemote(from, sums, d.learningTrust(from))
+		},
+	}
+}
+
+// learningTrust is how much of a peer's shared experience counts, 0..1.
+func (d *Daemon) learningTrust(nodeID string) float64 {
+	if d.Anomaly.IsKnownThreat(nodeID) || d.isQuarantined(nodeID) {
+		return 0
+	}
+	return d.Reputation.GetOrRegister(nodeID).Overall()
+}
@@ -0,0 +1,105 @@
+package daemon
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/infra/engine"
+	"github.com/tutu-network/tutu/internal/infra/marketplace"
+	"github.com/tutu-network/tutu/internal/mcp"
+)
+
+// ─── MCP Node Backend ───────────────────────────────────────────────────────
+// Connects the MCP gateway's tools and live resources to this node's pool,
+// registry, ledger and marketplace so agents can drive the node over MCP.
+
+// mcpWatchInterval is how often subscribed MCP resources are checked for
+// changes.
+const mcpWatchInterval = 5 * time.Second
+
+// mcpDefaultMaxTokens applies when a tutu_inference call omits max_tokens.
+const mcpDefaultMaxTokens = 2048
+
+// mcpBackend builds the gateway backend for this node.
+func (d *Daemon) mcpBackend(nodeID string) mcp.Backend {
+	b := mcp.Backend{
+		Models:   d.Models.List,
+		Generate: d.mcpGenerate,
+		Embed: func(ctx context.Context, model string, inputs []string) ([][]float32, error) {
+			return d.Batcher.Embed(ctx, model, mcpLoadOpts(), inputs)
+		},
+		Status: func() (any, error) {
+			status := map[string]any{
+				"node_id":        nodeID,
+				"loaded_models":  d.Pool.LoadedModels(),
+				"tasks":          d.Executor.Stats(),
+				"network":        d.Config.Network.Enabled,
+				"gpu_devices":    d.Devices.Usage(),
+				"idle_detection": d.Config.Resources.IdleDetection,
+			}
+			if d.Fabric != nil && d.Config.Network.Enabled {
+				status["peers"] = len(d.Fabric.Peers())
+			}
+			return status, nil
+		},
+		Tasks: func() (any, error) {
+			return map[string]any{
+				"executor":  d.Executor.Stats(),
+				"scheduler": d.Scheduler.Stats(),
+			}, nil
+		},
+		Earnings: func(limit int) (any, error) {
+			balance, err := d.Credit.Balance()
+			if err != nil {
+				return nil, err
+			}
+			history, err := d.Credit.History(limit)
+			if err != nil {
+				return nil, err
+			}
+			return map[string]any{"balance": balance, "recent": history}, nil
+		},
+	}
+	if d.Marketplace != nil {
+		b.SearchMarketplace = func(category, query string, limit int) (any, error) {
+			listings := d.Marketplace.Search(marketplace.Category(category), query)
+			if len(listings) > limit {
+				listings = listings[:limit]
+			}
+			return listings, nil
+		}
+	}
+	return b
+}
+
+// mcpGenerate runs a non-streaming completion for tutu_inference.
+func (d *Daemon) mcpGenerate(ctx context.Context, model, prompt string, maxTokens int) (string, int, error) {
+	if maxTokens <= 0 {
+		maxTokens = mcpDefaultMaxTokens
+	}
+	handle, err := d.Pool.Acquire(model, mcpLoadOpts())
+	if err != nil {
+		return "", 0, err
+	}
+	defer handle.Release()
+
+	stream, err := handle.Generate(ctx, prompt, engine.GenerateParams{MaxTokens: maxTokens})
+	if err != nil {
+		return "", 0, err
+	}
+	var (
+		out    strings.Builder
+		tokens int
+	)
+	for tok := range stream.Tokens() {
+		out.WriteString(tok.Text)
+		tokens++
+	}
+	return out.String(), tokens, stream.Err()
+}
+
+// mcpLoadOpts matches the HTTP API's model load defaults.
+func mcpLoadOpts() engine.LoadOptions {
+	return engine.LoadOptions{NumGPULayers: -1, NumCtx: 4096}
+}
@@ -0,0 +1,24 @@
+package daemon
+
+import (
+	"github.com/tutu-network/tutu/internal/infra/metrics"
+	"github.com/tutu-network/tutu/internal/infra/tenant"
+)
+
+// ─── Namespaces ─────────────────────────────────────────────────────────────
+// Teams sharing this node use their namespace's API key. The API enforces
+// each namespace's model allowlist and rate limit; the namespace follows the
+// request into agent tasks and the credits spent for it, and every metered
+// event is exported with a namespace label.
+
+// observeNamespace exports a namespace event as Prometheus metrics.
+func observeNamespace(ev tenant.Event) {
+	switch ev.Kind {
+	case tenant.EventRequest:
+		metrics.NamespaceRequests.WithLabelValues(ev.Namespace, ev.Model).Inc()
+	case tenant.EventTokens:
+		metrics.NamespaceTokens.WithLabelValues(ev.Namespace, ev.Model).Add(float64(ev.Tokens))
+	case tenant.EventRateLimited, tenant.EventModelDenied:
+		metrics.NamespaceRejected.WithLabelValues(ev.Namespace, string(ev.Kind)).Inc()
+	}
+}
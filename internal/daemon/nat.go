@@ -0,0 +1,151 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net"
+	"sort"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/infra/gossip"
+	"github.com/tutu-network/tutu/internal/infra/nat"
+)
+
+// ─── NAT Traversal ──────────────────────────────────────────────────────────
+// Peers behind NATs are reached with the best strategy that works: hole
+// punching signalled over gossip, then a relay run by a volunteer peer
+// (advertised in its heartbeat), then the Cloud Core path. Paths to live
+// peers are established in the background, so the first task to a peer
+// does not pay for the handshake.
+
+// natConnectInterval is how often paths to newly seen peers are set up.
+const natConnectInterval = 30 * time.Second
+
+// setupNAT opens the traversal socket and, when volunteering, the relay
+// socket. A socket that cannot be bound disables that part with a warning.
+func (d *Daemon) setupNAT(cfg NATConfig) {
+	conn, err := net.ListenPacket("udp4", cfg.BindAddr)
+	if err != nil {
+		log.Printf("[nat] traversal disabled: %v", err)
+		return
+	}
+	d.NAT = nat.NewTraverser(d.Fabric.NodeID(), conn, cfg.Traverser(), nat.Hooks{
+		Signal: d.natSignal,
+		Relays: d.natRelays,
+		Sign:   d.Keypair.Sign,
+	})
+	d.natConn = conn
+
+	if !cfg.Relay {
+		return
+	}
+	relayConn, err := net.ListenPacket("udp4", cfg.RelayBindAddr)
+	if err != nil {
+		log.Printf("[nat] relay disabled: %v", err)
+		return
+	}
+	d.Relay = nat.NewRelay(nat.DefaultRelayConfig())
+	d.relayConn = relayConn
+}
+
+// natLoop discovers the public endpoint, then serves punch traffic, the relay
+// (if volunteering) and background path setup until ctx is done.
+func (d *Daemon) natLoop(ctx context.Context) {
+	defer d.natConn.Close()
+	if err := d.NAT.Discover(ctx); err != nil {
+		log.Printf("[nat] STUN discovery failed, using local address: %v", err)
+	} else {
+		local := d.NAT.Local()
+		log.Printf("[nat] public endpoint %s (%s)", local.Addr, local.NAT)
+	}
+
+	d.Fabric.OnRendezvous(func(from string, payload []byte) {
+		var req nat.PunchRequest
+		if err := json.Unmarshal(payload, &req); err != nil || req.From != from {
+			return
+		}
+		go d.NAT.HandlePunch(ctx, req)
+	})
+	if d.Relay != nil {
+		go func() {
+			defer d.relayConn.Close()
+			if err := d.Relay.Serve(ctx, d.relayConn); err != nil {
+				log.Printf("[nat] relay: %v", err)
+			}
+		}()
+	}
+	go d.natConnectLoop(ctx)
+
+	if err := d.NAT.Run(ctx); err != nil {
+		log.Printf("[nat] %v", err)
+	}
+}
+
+// natConnectLoop sets up a path to every live peer that has none yet.
+func (d *Daemon) natConnectLoop(ctx context.Context) {
+	ticker := time.NewTicker(natConnectInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for _, p := range d.Fabric.Peers() {
+				if _, ok := d.NAT.Path(p.NodeID); ok || d.isQuarantined(p.NodeID) {
+					continue
+				}
+				res := d.NAT.Connect(ctx, p.NodeID)
+				log.Printf("[nat] %s reachable via %s", p.NodeID, res.Strategy)
+			}
+		}
+	}
+}
+
+// natSignal delivers a punch request to a peer over gossip.
+func (d *Daemon) natSignal(_ context.Context, peerID string, req nat.PunchRequest) error {
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	return d.Fabric.SendRendezvous(peerID, payload)
+}
+
+// natRelays lists the relays advertised in fresh heartbeats, least loaded
+// first. Quarantined volunteers are skipped.
+func (d *Daemon) natRelays() []string {
+	var volunteers []gossip.Heartbeat
+	for _, h := range d.Fabric.Heartbeats().Fresh() {
+		if h.Relay != "" && !d.isQuarantined(h.NodeID) {
+			volunteers = append(volunteers, h)
+		}
+	}
+	sort.SliceStable(volunteers, func(i, j int) bool { return volunteers[i].Load < volunteers[j].Load })
+	out := make([]string, len(volunteers))
+	for i, h := range volunteers {
+		out[i] = h.Relay
+	}
+	return out
+}
+
+// relayAddr is the address peers use to reach this node's relay: the public
+// IP from STUN with the relay socket's port. Only nodes without a NAT, or
+// behind a full-cone NAT, can relay for peers they have never sent to.
+func (d *Daemon) relayAddr() string {
+	if d.Relay == nil || d.NAT == nil {
+		return ""
+	}
+	local := d.NAT.Local()
+	if local.NAT != nat.NATNone && local.NAT != nat.NATFullCone {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(local.Addr)
+	if err != nil {
+		return ""
+	}
+	_, port, err := net.SplitHostPort(d.relayConn.LocalAddr().String())
+	if err != nil {
+		return ""
+	}
+	return net.JoinHostPort(host, port)
+}
@@ -0,0 +1,64 @@
+package daemon
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
+	"github.com/tutu-network/tutu/internal/infra/netprobe"
+	"github.com/tutu-network/tutu/internal/infra/reputation"
+)
+
+// ─── Network Profile ────────────────────────────────────────────────────────
+// Every acked gossip probe is an RTT sample; peers whose availability check
+// is due are pinged actively within the probe budget, as often as their
+// reputation and history call for ([network] probe_min_interval and
+// probe_max_interval). Each check updates the peer's availability
+// reputation. The profile feeds the ML scheduler's LatencyMs feature, and
+// swarm downloads (swarmFetch) use Bandwidth as p2p.DownloadConfig's
+// Bandwidth and report every chunk to ObserveTransfer from its OnTransfer
+// hook. Bandwidth is learned from those transfers only; there are no
+// active bandwidth probes.
+
+// netProbeHooks connects the prober to gossip. Without the network it only
+// holds what is observed passively.
+func (d *Daemon) netProbeHooks() netprobe.Hooks {
+	if d.Fabric == nil || !d.Config.Network.Enabled {
+		return netprobe.Hooks{}
+	}
+	return netprobe.Hooks{
+		Peers: func() []string {
+			var out []string
+			for _, p := range d.Fabric.Peers() {
+				if p.State == domain.PeerAlive && !d.isQuarantined(p.NodeID) {
+					out = append(out, p.NodeID)
+				}
+			}
+			return out
+		},
+		Ping: func(ctx context.Context, nodeID string) (time.Duration, error) {
+			rtt, err := d.Fabric.Ping(ctx, nodeID)
+			if err == nil {
+				d.observeRegionRTT(nodeID, rtt)
+			}
+			return rtt, err
+		},
+		Reputation: func(nodeID string) float64 {
+			if rep := d.Reputation.Get(nodeID); rep != nil {
+				return rep.Overall()
+			}
+			return reputation.DefaultReputation
+		},
+		OnAvailability: d.recordAvailability,
+	}
+}
+
+// recordAvailability folds an availability check into the peer's
+// reputation.
+func (d *Daemon) recordAvailability(nodeID string, online bool) {
+	d.Reputation.GetOrRegister(nodeID)
+	if err := d.Reputation.RecordAvailability(nodeID, reputation.AvailabilityCheck{WasOnline: online}); err != nil {
+		log.Printf("[netprobe] availability of %s: %v", nodeID, err)
+	}
+}
@@ -0,0 +1,61 @@
+package daemon
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
+	"github.com/tutu-network/tutu/internal/infra/federation"
+	"github.com/tutu-network/tutu/internal/infra/onboarding"
+	"github.com/tutu-network/tutu/internal/infra/resource"
+)
+
+// ─── Onboarding ─────────────────────────────────────────────────────────────
+// The first-run wizard runs against the live node: the profiler detects and
+// benchmarks, the idle policy engine takes the earning settings, the model
+// manager pulls the starter pack in the background, and invite codes are
+// redeemed against the federation registry.
+
+// onboardingHooks connects the wizard to the daemon's components.
+func (d *Daemon) onboardingHooks() onboarding.Hooks {
+	return onboarding.Hooks{
+		Detect:    detectHardware,
+		Benchmark: d.Profiler.Rebenchmark,
+		ApplyEarnings: func(p resource.IdlePolicy) error {
+			_, err := d.IdlePolicy.SetPolicy(p)
+			return err
+		},
+		InstallModels: func(models []string) error {
+			go d.pullStarterModels(models)
+			return nil
+		},
+		JoinFederation: func(code string) (string, error) {
+			return d.Federation.RedeemInvite(code, d.nodeID)
+		},
+	}
+}
+
+// pullStarterModels downloads the chosen starter models one at a time.
+// Models already on disk are skipped by Pull.
+func (d *Daemon) pullStarterModels(models []string) {
+	for _, m := range models {
+		log.Printf("[onboarding] pulling %s", m)
+		if err := d.Models.Pull(m, nil); err != nil {
+			log.Printf("[onboarding] pull %s: %v", m, err)
+		}
+	}
+}
+
+// inviteToFederation issues an invite code for the federation this node
+// administers, signed with the node key.
+func (d *Daemon) inviteToFederation(ttl time.Duration) (federation.Invite, error) {
+	if d.Keypair == nil {
+		return federation.Invite{}, fmt.Errorf("%w: node has no signing key", domain.ErrOnboardingDisabled)
+	}
+	fedID, ok := d.Federation.NodeFederation(d.nodeID)
+	if !ok {
+		return federation.Invite{}, fmt.Errorf("%w: node %s", domain.ErrNotFederated, d.nodeID)
+	}
+	return d.Federation.CreateInvite(fedID, d.nodeID, d.Keypair, ttl)
+}
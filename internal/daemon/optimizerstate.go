@@ -0,0 +1,61 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/tutu-network/tutu/internal/infra/intelligence"
+	"github.com/tutu-network/tutu/internal/infra/sqlite"
+)
+
+// ─── Optimizer State ────────────────────────────────────────────────────────
+// The intelligence_snapshot housekeeping job saves what the placement
+// optimizer has learned — model popularity, {node, model} affinity and the
+// placement cycle count — to model_popularity_detail, node_model_affinity
+// and optimizer_cycles; Close saves it once more. On start it is restored
+// before any traffic is recorded, so weeks of learning and the weekly
+// placement gate survive a restart.
+
+// restoreOptimizerState reloads the saved optimizer state.
+func (d *Daemon) restoreOptimizerState() {
+	saved, err := d.DB.LoadOptimizerState()
+	if err != nil {
+		log.Printf("[intelligence] restore state: %v", err)
+		return
+	}
+	st := intelligence.State{LastOptimization: saved.LastRun, Optimizations: saved.Optimizations}
+	for _, m := range saved.Models {
+		st.Models = append(st.Models, intelligence.ModelState(m))
+	}
+	for _, a := range saved.Affinities {
+		st.Affinities = append(st.Affinities, intelligence.AffinityState(a))
+	}
+	d.Intelligence.RestoreState(st)
+	if len(st.Models) > 0 {
+		log.Printf("[intelligence] restored %d models, %d affinities, %d placement cycles",
+			len(st.Models), len(st.Affinities), st.Optimizations)
+	}
+}
+
+// saveOptimizerState writes the optimizer's learned state.
+func (d *Daemon) saveOptimizerState() error {
+	st := d.Intelligence.ExportState()
+	out := sqlite.OptimizerState{LastRun: st.LastOptimization, Optimizations: st.Optimizations}
+	for _, m := range st.Models {
+		out.Models = append(out.Models, sqlite.ModelPopularityDetail(m))
+	}
+	for _, a := range st.Affinities {
+		out.Affinities = append(out.Affinities, sqlite.NodeModelAffinityRecord(a))
+	}
+	return d.DB.SaveOptimizerState(out)
+}
+
+// snapshotIntelligence is the intelligence_snapshot job.
+func (d *Daemon) snapshotIntelligence(context.Context) (string, error) {
+	if err := d.saveOptimizerState(); err != nil {
+		return "", fmt.Errorf("optimizer state: %w", err)
+	}
+	st := d.Intelligence.Stats()
+	return fmt.Sprintf("saved %d models on %d nodes", st.TrackedModels, st.TrackedNodes), nil
+}
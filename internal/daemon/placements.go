@@ -0,0 +1,69 @@
+package daemon
+
+import (
+	"log"
+
+	"github.com/tutu-network/tutu/internal/infra/intelligence"
+	"github.com/tutu-network/tutu/internal/infra/sqlite"
+)
+
+// ─── Placement Outcomes ─────────────────────────────────────────────────────
+// Every placement recommendation and each status reported for it
+// (POST /api/admin/network/placements/{id}/ack) is written to
+// model_placements. On start the most recent ones are restored, so the
+// optimizer keeps suppressing moves that were rejected or failed instead of
+// re-suggesting them after a restart.
+
+// placementHistory is how many recommendations are restored on start; it
+// matches the optimizer's history.
+const placementHistory = 1000
+
+// restorePlacements reloads persisted recommendations into the optimizer.
+func (d *Daemon) restorePlacements() {
+	recs, err := d.DB.ListPlacements(placementHistory)
+	if err != nil {
+		log.Printf("[intelligence] restore placements: %v", err)
+		return
+	}
+	out := make([]intelligence.Recommendation, 0, len(recs))
+	for _, r := range recs {
+		typ, ok := intelligence.ParseRecommendationType(r.Type)
+		if !ok {
+			continue
+		}
+		out = append(out, intelligence.Recommendation{
+			ID:        r.RecID,
+			Type:      typ,
+			ModelName: r.ModelName,
+			FromNode:  r.FromNode,
+			ToNode:    r.ToNode,
+			Score:     r.Score,
+			Reason:    r.Reason,
+			Status:    intelligence.AckStatus(r.Status),
+			Detail:    r.Detail,
+			CreatedAt: r.CreatedAt,
+			UpdatedAt: r.UpdatedAt,
+		})
+	}
+	d.Intelligence.RestoreRecommendations(out)
+}
+
+// persistPlacement is the optimizer's recommendation hook.
+func (d *Daemon) persistPlacement(rec intelligence.Recommendation) {
+	err := d.DB.UpsertPlacement(sqlite.PlacementRecord{
+		RecID:     rec.ID,
+		Type:      rec.Type.String(),
+		ModelName: rec.ModelName,
+		FromNode:  rec.FromNode,
+		ToNode:    rec.ToNode,
+		Score:     rec.Score,
+		Reason:    rec.Reason,
+		Status:    string(rec.Status),
+		Detail:    rec.Detail,
+		CreatedAt: rec.CreatedAt,
+		UpdatedAt: rec.UpdatedAt,
+	})
+	if err != nil {
+		log.Printf("[intelligence] persist placement %s: %v", rec.ID, err)
+	}
+}
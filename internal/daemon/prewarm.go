@@ -0,0 +1,49 @@
+package daemon
+
+import (
+	"log"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/infra/autoscale"
+	"github.com/tutu-network/tutu/internal/infra/intelligence"
+)
+
+// ─── Forecast Pre-Warm ──────────────────────────────────────────────────────
+// The auto-scaler is evaluated by the scaler_evaluate housekeeping job,
+// every minute by default. When it decides to pre-warm, the placement
+// optimizer plans which models the coming window will want and which nodes
+// should hold them. Every node runs the same job and loads the models
+// planned for itself; there is no channel to instruct a peer's engine pool
+// directly.
+
+// prewarm loads the models planned for this node by a pre-warm decision.
+// It returns how many loads were started.
+func (d *Daemon) prewarm(dec autoscale.Decision) int {
+	if dec.Direction != autoscale.PreWarm {
+		return 0
+	}
+	loaded := make(map[string]bool)
+	for _, m := range d.Pool.LoadedModels() {
+		loaded[m.Name] = true
+	}
+	started := 0
+	plan := d.Intelligence.PlanPrewarm(dec.DecidedAt, dec.WarmBy, intelligence.DefaultPrewarmConfig())
+	for _, in := range plan {
+		if in.NodeID != d.nodeID || loaded[in.ModelName] {
+			continue
+		}
+		loaded[in.ModelName] = true
+		started++
+		go func(in intelligence.PreloadInstruction) {
+			handle, err := d.Pool.Acquire(in.ModelName, mcpLoadOpts())
+			if err != nil {
+				log.Printf("[prewarm] load %s: %v", in.ModelName, err)
+				return
+			}
+			handle.Release()
+			log.Printf("[prewarm] loaded %s (%.0f%% of forecast requests by %s)",
+				in.ModelName, in.Share*100, in.WarmBy.Format(time.Kitchen))
+		}(in)
+	}
+	return started
+}
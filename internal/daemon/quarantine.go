@@ -0,0 +1,117 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/infra/gossip"
+	"github.com/tutu-network/tutu/internal/infra/healing"
+	"github.com/tutu-network/tutu/internal/infra/metrics"
+	"github.com/tutu-network/tutu/internal/infra/mlscheduler"
+)
+
+// ─── Quarantine ─────────────────────────────────────────────────────────────
+// The quarantine manager polls anomaly, reputation and self-healing for
+// nodes to quarantine, persists every decision and gossips it, so peers stop
+// assigning work too. Expired quarantines must pass the same checks before
+// the node is released on probation.
+
+// quarantineReputationFloor is the reputation below which a node is
+// quarantined.
+const quarantineReputationFloor = 0.2
+
+// quarantineTriggers returns the trigger source for the quarantine manager.
+// The local node is never quarantined.
+func (d *Daemon) quarantineTriggers(nodeID string) func() []healing.QuarantineTrigger {
+	return func() []healing.QuarantineTrigger {
+		var out []healing.QuarantineTrigger
+		for _, t := range d.Anomaly.ThreatFeed() {
+			out = append(out, healing.QuarantineTrigger{NodeID: t.NodeID, Reason: healing.QuarantineAnomaly})
+		}
+		for _, rep := range d.Reputation.TopNodes(0) {
+			if rep.Overall() < quarantineReputationFloor {
+				out = append(out, healing.QuarantineTrigger{NodeID: rep.NodeID, Reason: healing.QuarantineReputation})
+			}
+		}
+		for _, inc := range d.SelfHeal.ActiveIncidents() {
+			out = append(out, healing.QuarantineTrigger{NodeID: inc.NodeID, Reason: healing.QuarantineIncident})
+		}
+
+		filtered := out[:0]
+		for _, t := range out {
+			if t.NodeID != nodeID && (d.Fabric == nil || t.NodeID != d.Fabric.NodeID()) {
+				filtered = append(filtered, t)
+			}
+		}
+		return filtered
+	}
+}
+
+// probeQuarantined is the probation check: a node is only released once
+// none of the quarantine triggers hold for it any more.
+func (d *Daemon) probeQuarantined(_ context.Context, nodeID string) error {
+	if d.Anomaly.IsKnownThreat(nodeID) {
+		return fmt.Errorf("%s is a known threat", nodeID)
+	}
+	if rep := d.Reputation.Get(nodeID); rep != nil && rep.Overall() < quarantineReputationFloor {
+		return fmt.Errorf("%s reputation %.2f below %.2f", nodeID, rep.Overall(), quarantineReputationFloor)
+	}
+	if d.SelfHeal.NodeHasActiveIncident(nodeID) {
+		return fmt.Errorf("%s has an active incident", nodeID)
+	}
+	return nil
+}
+
+// publishQuarantine gossips a quarantine or release.
+func (d *Daemon) publishQuarantine(rec healing.QuarantineRecord) {
+	if d.Fabric == nil || !d.Config.Network.Enabled {
+		return
+	}
+	issuedAt := rec.StartedAt
+	if rec.Released {
+		issuedAt = time.Now()
+	}
+	err := d.Fabric.PublishQuarantine(gossip.QuarantineNotice{
+		NodeID:   rec.NodeID,
+		Reason:   string(rec.Reason),
+		IssuedAt: issuedAt,
+		Until:    rec.ExpiresAt,
+		Released: rec.Released,
+	})
+	if err != nil {
+		log.Printf("[quarantine] gossip %s: %v", rec.NodeID, err)
+	}
+}
+
+// isQuarantined reports whether a peer is quarantined by this node or by
+// any node whose notice reached us.
+func (d *Daemon) isQuarantined(nodeID string) bool {
+	if d.Quarantine.IsQuarantined(nodeID) {
+		return true
+	}
+	return d.Fabric != nil && d.Fabric.Quarantine().Quarantined(nodeID)
+}
+
+// schedulerExclusion keeps the ML scheduler off nodes that are mid-incident,
+// quarantined or blocklisted, whatever the bandit has learned about them.
+func (d *Daemon) schedulerExclusion() mlscheduler.Exclusion {
+	return mlscheduler.Exclusion{
+		Excluded: func(nodeID string) (string, bool) {
+			if d.SelfHeal.NodeHasActiveIncident(nodeID) {
+				return "incident", true
+			}
+			if d.isQuarantined(nodeID) {
+				return "quarantined", true
+			}
+			if d.isBlocked(nodeID) {
+				return "blocklisted", true
+			}
+			return "", false
+		},
+		OnReroute: func(_, reason string) {
+			metrics.SchedulerRerouted.WithLabelValues(reason).Inc()
+		},
+	}
+}
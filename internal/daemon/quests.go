@@ -0,0 +1,33 @@
+package daemon
+
+import (
+	"log"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
+)
+
+// ─── Quest Rewards ──────────────────────────────────────────────────────────
+// Network subsystems progress the weekly quests through
+// engagement.NetworkQuests; a quest they complete pays its XP and credits
+// and leaves a notification.
+
+// questCompleted rewards a completed quest.
+func (d *Daemon) questCompleted(q domain.Quest) {
+	if q.RewardXP > 0 {
+		if _, _, err := d.Level.AddXP(q.RewardXP, domain.XPQuestCompleted); err != nil {
+			log.Printf("[quests] %s: award XP: %v", q.ID, err)
+		}
+	}
+	if q.RewardCredits > 0 {
+		if err := d.Credit.Earn(q.RewardCredits, q.ID, "quest: "+q.Description); err != nil {
+			log.Printf("[quests] %s: award credits: %v", q.ID, err)
+		}
+	}
+	_, _ = d.Notification.Create(domain.Notification{
+		Type:      domain.NotifyQuestComplete,
+		Title:     "Quest complete",
+		Body:      q.Description,
+		CreatedAt: time.Now(),
+	})
+}
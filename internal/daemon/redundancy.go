@@ -0,0 +1,115 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
+	"github.com/tutu-network/tutu/internal/infra/mlscheduler"
+	"github.com/tutu-network/tutu/internal/infra/redundancy"
+	"github.com/tutu-network/tutu/internal/infra/reputation"
+	"github.com/tutu-network/tutu/internal/infra/tenant"
+)
+
+// ─── Redundant Verification ─────────────────────────────────────────────────
+// N-of-M execution for high-value requests. Replicas run on this node and
+// the best-scoring reachable peers (reputation and heartbeat load); agreeing
+// peers are paid from this node's balance, dissenters lose accuracy and are
+// reported as threats. Each task type is verified by its policy in the
+// corrector's registry, and an agreeing replica's accuracy is credited by
+// how closely it matched the consensus.
+//
+// Peers cannot be asked to run inference yet, so the daemon does not start
+// the corrector: every verification would pick peers it cannot reach.
+// Requests for them fail with redundancy.ErrNotDispatched and are not held
+// against the peer.
+
+// dissentPenalty is the reputation penalty for disagreeing with consensus.
+const dissentPenalty = 0.5
+
+// redundancyHooks connects the corrector to this node.
+func (d *Daemon) redundancyHooks(nodeID string) redundancy.Hooks {
+	return redundancy.Hooks{
+		Nodes: func(n int) []string {
+			nodes := []string{nodeID}
+			if d.Fabric == nil || !d.Config.Network.Enabled {
+				return nodes
+			}
+			peers := d.Fabric.Peers()
+			score := make(map[string]float64, len(peers))
+			for _, p := range peers {
+				score[p.NodeID] = mlscheduler.HeuristicScore(d.peerFeatures(p, domain.TaskInference, ""))
+			}
+			sort.SliceStable(peers, func(i, j int) bool { return score[peers[i].NodeID] > score[peers[j].NodeID] })
+			for _, p := range peers {
+				if len(nodes) == n {
+					break
+				}
+				if p.IsReachable() && !d.Anomaly.IsKnownThreat(p.NodeID) && !d.isQuarantined(p.NodeID) {
+					nodes = append(nodes, p.NodeID)
+				}
+			}
+			return nodes
+		},
+		Execute: func(ctx context.Context, node string, req redundancy.Request) (string, error) {
+			if node != nodeID {
+				// Peers are selected, but there is no inter-node inference RPC yet
+				return "", fmt.Errorf("remote execution on %s: %w", node, redundancy.ErrNotDispatched)
+			}
+			text, _, err := d.mcpGenerate(ctx, req.Model, req.Prompt, req.MaxTokens)
+			return text, err
+		},
+		Embed: func(ctx context.Context, model string, texts []string) ([][]float32, error) {
+			return d.Batcher.Embed(ctx, model, mcpLoadOpts(), texts)
+		},
+		Pay: func(ctx context.Context, node string, amount int64, taskID string) error {
+			return d.Credit.SpendIn(tenant.FromContext(ctx), amount, taskID, "redundant result from "+node)
+		},
+		Outcome: func(node string, outcome domain.ReplicaOutcome, agreement float64, taskID string, latency time.Duration) {
+			if outcome == domain.ReplicaUndecided {
+				return // no consensus: nothing to hold against anyone
+			}
+			d.Reputation.GetOrRegister(node)
+			fedID := d.sharedFederation(nodeID, node)
+			_ = d.Reputation.RecordTask(node, reputation.TaskOutcome{
+				Successful:     outcome != domain.ReplicaFailed,
+				ResultVerified: outcome == domain.ReplicaAgreed,
+				Accuracy:       agreement,
+				ActualTime:     latency,
+				FederationID:   fedID,
+			})
+			if outcome == domain.ReplicaDissented {
+				_ = d.Reputation.RecordPenalty(node, reputation.PenaltyEvent{
+					Severity:     dissentPenalty,
+					Reason:       "result disagreed with consensus on " + taskID,
+					FederationID: fedID,
+				})
+				d.Anomaly.ReportThreat(node, "redundant result disagreed with consensus", nodeID)
+			}
+			if node != nodeID {
+				switch outcome {
+				case domain.ReplicaDissented:
+					d.Quarantine.RecordVerificationFailure(node)
+				case domain.ReplicaFailed:
+					d.Quarantine.RecordFailure(node)
+				}
+			}
+		},
+	}
+}
+
+// sharedFederation returns the federation both nodes belong to, or "" when
+// they are not members of the same one. Outcomes between federation peers
+// count toward reputation within that federation.
+func (d *Daemon) sharedFederation(self, peer string) string {
+	fedID, ok := d.Federation.NodeFederation(self)
+	if !ok {
+		return ""
+	}
+	if peerFed, ok := d.Federation.NodeFederation(peer); !ok || peerFed != fedID {
+		return ""
+	}
+	return fedID
+}
@@ -0,0 +1,113 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
+)
+
+// ─── Region Routing ─────────────────────────────────────────────────────────
+// Each node publishes its view of its own region to region_status and
+// routes on every region's persisted row, so failover follows the shared
+// view rather than the router's startup defaults.
+
+// Round trips to peers — gossip probes and the network profile's pings —
+// are also recorded in a latency heatmap against the peer's region, so the
+// router picks paths on measured latency rather than the static table.
+
+// regionSyncInterval is how often region status is published and reloaded.
+const regionSyncInterval = 30 * time.Second
+
+// regionLoop syncs region status until ctx is done.
+func (d *Daemon) regionLoop(ctx context.Context) {
+	if err := d.syncRegions(); err != nil {
+		log.Printf("[region] sync: %v", err)
+	}
+	ticker := time.NewTicker(regionSyncInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := d.syncRegions(); err != nil {
+				log.Printf("[region] sync: %v", err)
+			}
+		}
+	}
+}
+
+// syncRegions writes the local region's status — this node plus the alive
+// peers gossiping the same region — and loads every region into the router.
+func (d *Daemon) syncRegions() error {
+	local := d.Router.Stats().LocalRegion
+	nodes := 1
+	if d.Fabric != nil {
+		for _, p := range d.Fabric.Peers() {
+			if p.IsReachable() && domain.RegionID(p.Region) == local {
+				nodes++
+			}
+		}
+	}
+	latencyMs := float64(d.Admission.Stats().Signals.Latency) / float64(time.Millisecond)
+	if err := d.DB.UpsertRegionStatus(string(local), true, nodes,
+		d.Executor.ActiveCount(), d.Scheduler.QueueDepth(), latencyMs); err != nil {
+		return fmt.Errorf("publish %s: %w", local, err)
+	}
+
+	rows, err := d.DB.ListRegionStatuses()
+	if err != nil {
+		return fmt.Errorf("load region status: %w", err)
+	}
+	statuses := make([]domain.RegionStatus, 0, len(rows))
+	for _, row := range rows {
+		statuses = append(statuses, domain.RegionStatus{
+			Region:       domain.RegionID(row.Region),
+			Healthy:      row.Healthy,
+			NodeCount:    row.NodeCount,
+			ActiveTasks:  row.ActiveTasks,
+			QueueDepth:   row.QueueDepth,
+			AvgLatencyMs: row.AvgLatencyMs,
+			UpdatedAt:    row.UpdatedAt,
+		})
+	}
+	d.Router.Sync(statuses)
+	return nil
+}
+
+// federationRegions returns a federation's AllowedRegions for the router.
+func (d *Daemon) federationRegions(fedID string) ([]domain.RegionID, error) {
+	fed, err := d.Federation.GetFederation(fedID)
+	if err != nil {
+		return nil, err
+	}
+	regions := make([]domain.RegionID, 0, len(fed.AllowedRegions))
+	for _, r := range fed.AllowedRegions {
+		regions = append(regions, domain.RegionID(r))
+	}
+	return regions, nil
+}
+
+// observeRTT records a round trip to a peer in the network profile and in
+// the latency heatmap.
+func (d *Daemon) observeRTT(nodeID string, rtt time.Duration) {
+	d.NetProbe.ObserveRTT(nodeID, rtt)
+	d.observeRegionRTT(nodeID, rtt)
+}
+
+// observeRegionRTT records a round trip to a peer against its region.
+// Peers that have not gossiped a known region are skipped.
+func (d *Daemon) observeRegionRTT(nodeID string, rtt time.Duration) {
+	if d.Heatmap == nil || d.Router == nil || d.Fabric == nil {
+		return
+	}
+	for _, p := range d.Fabric.Peers() {
+		if p.NodeID == nodeID {
+			d.Heatmap.Observe(d.Router.Stats().LocalRegion, domain.RegionID(p.Region), rtt)
+			return
+		}
+	}
+}
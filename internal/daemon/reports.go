@@ -0,0 +1,48 @@
+package daemon
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// ─── Earnings Reports ───────────────────────────────────────────────────────
+// Each morning the previous day's earnings, completed tasks and uptime are
+// saved as an earnings report and announced with a notification. The loop
+// checks hourly, so a node started after the report hour still catches up.
+
+// reportCheckInterval is how often the daemon checks for a due report.
+const reportCheckInterval = time.Hour
+
+// reportLoop generates yesterday's report once it is due.
+func (d *Daemon) reportLoop(ctx context.Context) {
+	ticker := time.NewTicker(reportCheckInterval)
+	defer ticker.Stop()
+	for {
+		if created, err := d.Reports.GenerateDue(time.Now()); err != nil {
+			log.Printf("[reports] WARNING: %v", err)
+		} else if created {
+			log.Printf("[reports] saved yesterday's earnings report")
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// uptimeHours is the time this process has been running within
+// [start, end). Uptime from earlier runs is not tracked.
+func (d *Daemon) uptimeHours(start, end time.Time) float64 {
+	if d.started.After(start) {
+		start = d.started
+	}
+	if now := time.Now(); now.Before(end) {
+		end = now
+	}
+	if !end.After(start) {
+		return 0
+	}
+	return end.Sub(start).Hours()
+}
@@ -0,0 +1,158 @@
+package daemon
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
+	"github.com/tutu-network/tutu/internal/infra/governance"
+	"github.com/tutu-network/tutu/internal/infra/intelligence"
+	"github.com/tutu-network/tutu/internal/infra/registry"
+	"github.com/tutu-network/tutu/internal/infra/sqlite"
+)
+
+// ─── Model Retirement ───────────────────────────────────────────────────────
+// The retirer deletes a retirement candidate only once it is safe to:
+// no request holds it in the pool, the operator has not pinned it, and
+// enough peers in the availability index still host it. Without gossip
+// there is no index to consult, so the replica check is off.
+//
+// Candidates go through the retirement workflow first: each scan advances
+// it, the announcement is a notification to this node's owner, and the
+// workflow is persisted so grace periods and vetoes survive a restart. A
+// passed MODEL_POLICY proposal with key retirement.veto (value: the model
+// name) vetoes a retirement on the network's behalf.
+
+// proposalRetirementVeto is the proposal key that vetoes a retirement.
+const proposalRetirementVeto = "retirement.veto"
+
+// retirementHooks connects the retirer to the pool, registry and gossip.
+func (d *Daemon) retirementHooks(nodeID string, networked bool) intelligence.RetirementHooks {
+	hooks := intelligence.RetirementHooks{
+		InFlight: d.modelRefs,
+		Pins:     []intelligence.PinSource{d.operatorPin},
+		Remove:   d.Models.Retire,
+		Announce: d.announceRetirement,
+	}
+	if networked {
+		hooks.Replicas = intelligence.OtherHosts(nodeID, d.Fabric.Availability().Hosts)
+	}
+	return hooks
+}
+
+// modelRefs counts requests holding model under any of the names it was
+// loaded by ("llama3" and "llama3:latest" are the same model).
+func (d *Daemon) modelRefs(model string) int {
+	want := registry.ParseRef(model).String()
+	n := 0
+	for _, m := range d.Pool.LoadedModels() {
+		if registry.ParseRef(m.Name).String() == want {
+			n += d.Pool.Refs(m.Name)
+		}
+	}
+	return n
+}
+
+// operatorPin reports models pinned with `tutu pin`.
+func (d *Daemon) operatorPin(model string) string {
+	if info, err := d.Models.Show(model); err == nil && info.Pinned {
+		return "operator"
+	}
+	return ""
+}
+
+// announceRetirement tells the node's owner that a model is going to be
+// retired and how to stop it.
+func (d *Daemon) announceRetirement(st intelligence.RetirementStatus) error {
+	_, err := d.Notification.Create(domain.Notification{
+		Type:  domain.NotifyRetirement,
+		Title: fmt.Sprintf("%s will be retired", st.Model),
+		Body: fmt.Sprintf("%s (%s). It will be removed after a grace period unless you veto it: "+
+			"POST /api/admin/retirements/%s/veto, or pin it with `tutu pin`.", st.Model, st.Reason, st.Model),
+		CreatedAt: time.Now(),
+	})
+	return err
+}
+
+// restoreRetirements reloads the persisted retirement workflow and
+// persists every change from now on.
+func (d *Daemon) restoreRetirements() {
+	recs, err := d.DB.ListRetirementWorkflow()
+	if err != nil {
+		log.Printf("[intelligence] restore retirement workflow: %v", err)
+	}
+	var statuses []intelligence.RetirementStatus
+	for _, r := range recs {
+		var st intelligence.RetirementStatus
+		if err := json.Unmarshal(r.Status, &st); err != nil {
+			log.Printf("[intelligence] skipping retirement of %s: %v", r.ModelName, err)
+			continue
+		}
+		statuses = append(statuses, st)
+	}
+	d.Retirer.RestoreWorkflow(statuses)
+	d.Retirer.SetWorkflowHook(d.persistRetirement)
+}
+
+// persistRetirement is the retirer's workflow hook.
+func (d *Daemon) persistRetirement(st intelligence.RetirementStatus) {
+	var err error
+	if st.State.Terminal() {
+		err = d.DB.DeleteRetirementWorkflow(st.Model)
+	} else {
+		var raw []byte
+		if raw, err = json.Marshal(st); err == nil {
+			err = d.DB.UpsertRetirementWorkflow(sqlite.RetirementWorkflowRecord{
+				ModelName: st.Model,
+				State:     string(st.State),
+				Status:    raw,
+				UpdatedAt: st.UpdatedAt,
+			})
+		}
+	}
+	if err != nil {
+		log.Printf("[intelligence] persist retirement of %s: %v", st.Model, err)
+	}
+}
+
+// advanceRetirements moves the retirement workflow along with the latest
+// scan's candidates, under the names the registry knows them by.
+func (d *Daemon) advanceRetirements(candidates []intelligence.RetirementCandidate) []intelligence.RetirementStatus {
+	named := make([]intelligence.RetirementCandidate, len(candidates))
+	for i, c := range candidates {
+		c.ModelName = d.canonicalModel(c.ModelName)
+		named[i] = c
+	}
+	return d.Retirer.Advance(named)
+}
+
+// canonicalModel returns the registry's name for model ("llama3" →
+// "llama3:latest"), or model itself if it is not installed.
+func (d *Daemon) canonicalModel(model string) string {
+	if info, err := d.Models.Show(model); err == nil {
+		return info.Name
+	}
+	return model
+}
+
+// executeRetirementVetoes applies passed retirement.veto proposals.
+func (d *Daemon) executeRetirementVetoes() {
+	passed := governance.PropPassed
+	for _, p := range d.Governance.ListProposals(&passed) {
+		if p.Category != governance.CatModelPolicy || p.ParamKey != proposalRetirementVeto {
+			continue
+		}
+		model := d.canonicalModel(strings.TrimSpace(p.ParamValue))
+		if model == "" {
+			continue
+		}
+		d.Retirer.Veto(model, intelligence.RetirementVeto{By: "governance:" + p.ID, Reason: p.Title})
+		if err := d.Governance.MarkExecuted(p.ID); err != nil {
+			log.Printf("[intelligence] proposal %s: %v", p.ID, err)
+		}
+		log.Printf("[intelligence] retirement of %s vetoed by proposal %s", model, p.ID)
+	}
+}
@@ -0,0 +1,44 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+// ─── Scheduler Snapshots ────────────────────────────────────────────────────
+// The scheduler_snapshot housekeeping job persists the scheduler's queue
+// depth, back-pressure level and cumulative counters. The snapshots feed
+// the network load trends (GET /api/scheduler/trends), and on start the
+// latest one restores the counters, so totals and completion rates do not
+// drop to zero with every restart. The retention_prune job deletes
+// snapshots older than schedulerSnapshotRetention.
+
+// schedulerSnapshotRetention is how long scheduler snapshots are kept.
+const schedulerSnapshotRetention = 30 * 24 * time.Hour
+
+// restoreSchedulerCounters continues the scheduler's counters from the
+// latest snapshot.
+func (d *Daemon) restoreSchedulerCounters() {
+	snap, err := d.DB.LatestSchedulerSnapshot()
+	if err != nil {
+		log.Printf("[scheduler] restore counters: %v", err)
+		return
+	}
+	if snap == nil {
+		return
+	}
+	d.Scheduler.RestoreCounters(snap.TotalEnqueued, snap.TotalCompleted, snap.TotalRejected, snap.TotalStolen, snap.TotalPreempted)
+	log.Printf("[scheduler] restored counters from %s snapshot", snap.At.Format(time.RFC3339))
+}
+
+// snapshotScheduler is the scheduler_snapshot job.
+func (d *Daemon) snapshotScheduler(context.Context) (string, error) {
+	st := d.Scheduler.Stats()
+	if err := d.DB.InsertSchedulerSnapshot(st.QueueDepth, int(st.BackPressure),
+		st.TotalEnqueued, st.TotalCompleted, st.TotalRejected, st.TotalStolen, st.TotalPreempted); err != nil {
+		return "", fmt.Errorf("scheduler snapshot: %w", err)
+	}
+	return fmt.Sprintf("queue depth %d, back-pressure %s", st.QueueDepth, st.BackPressure), nil
+}
@@ -0,0 +1,227 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/app/credit"
+	"github.com/tutu-network/tutu/internal/domain"
+	"github.com/tutu-network/tutu/internal/infra/p2p"
+	"github.com/tutu-network/tutu/internal/infra/reputation"
+)
+
+// ─── Chunk Serving ──────────────────────────────────────────────────────────
+// With the network enabled, the models this node hosts are served to peers
+// from [network] seed_bind_addr (p2p.ChunkServer), and the port is
+// advertised in the heartbeat. Every chunk goes through the Seeder, which
+// limits upload bandwidth and decides which peers are unchoked.
+
+// setupChunkServer binds the chunk server socket. A socket that cannot be
+// bound disables serving with a warning.
+func (d *Daemon) setupChunkServer(addr string) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		log.Printf("[seeding] chunk server disabled: %v", err)
+		return
+	}
+	d.chunkLn = ln
+}
+
+// serveChunks serves model chunks to peers until ctx is done.
+func (d *Daemon) serveChunks(ctx context.Context) {
+	cfg := p2p.DefaultChunkServerConfig()
+	cfg.PeerBlocked = func(peer string) bool { return d.isBlocked(peer) || d.isQuarantined(peer) }
+	cfg.ModelBlocked = d.Fabric.Blocklist().ModelBlocked
+	srv := &http.Server{
+		Handler:     p2p.NewChunkServer(d.Keypair, d.Seeder, d.hostedModelFile, cfg).Handler(),
+		ReadTimeout: 30 * time.Second,
+		IdleTimeout: 2 * time.Minute,
+	}
+	go func() {
+		<-ctx.Done()
+		_ = srv.Close()
+	}()
+	if err := srv.Serve(d.chunkLn); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Printf("[seeding] chunk server: %v", err)
+	}
+}
+
+// chunkPort is the chunk server's port for the heartbeat (0 = not serving).
+func (d *Daemon) chunkPort() int {
+	if d.chunkLn == nil {
+		return 0
+	}
+	if addr, ok := d.chunkLn.Addr().(*net.TCPAddr); ok {
+		return addr.Port
+	}
+	return 0
+}
+
+// hostedModelFile resolves a model digest (SHA-256 hex) to the local blob.
+func (d *Daemon) hostedModelFile(digest string) (p2p.LocalModel, bool) {
+	infos, err := d.Models.List()
+	if err != nil {
+		return p2p.LocalModel{}, false
+	}
+	for _, m := range infos {
+		if strings.TrimPrefix(m.Digest, "sha256:") == digest {
+			return p2p.LocalModel{Name: m.Name, Path: d.Models.BlobPath(m.Digest)}, true
+		}
+	}
+	return p2p.LocalModel{}, false
+}
+
+// ─── Swarm Downloads ────────────────────────────────────────────────────────
+// Pulls try the peers that host a model before its origin
+// (registry.SetSwarm). The sources are the peers announcing the model at
+// the digest the origin published, with a chunk port in their heartbeat;
+// blocked and quarantined peers are skipped. A p2p.Downloader fetches from
+// them through a p2p.ChunkClient, weighting peers by reputation and
+// network-profile bandwidth, recording transfers in chunk_transfers so an
+// interrupted pull resumes, and counting every byte received toward the
+// Seeder's reciprocation. The content-addressed p2p.ChunkStore is not used
+// as a local chunk source: the registry keeps whole blobs, and a chunked
+// copy beside them would double the disk every model takes.
+
+// swarmHTTPTimeout bounds one chunk request, including the transfer.
+const swarmHTTPTimeout = 2 * time.Minute
+
+// swarmFetch is the registry's swarm download path.
+func (d *Daemon) swarmFetch(model, digest, dest string, progress func(status string, pct float64)) error {
+	addrs := d.chunkAddrs(model, digest)
+	if len(addrs) == 0 {
+		return p2p.ErrNoPeersAvailable
+	}
+	// 503 is the chunk server's flow control, not a fault, so the peer
+	// circuit breakers (PeerHTTP) stay out of it
+	client := p2p.NewChunkClient(d.Keypair, &http.Client{Timeout: swarmHTTPTimeout}, func(peer string) (string, bool) {
+		addr, ok := addrs[peer]
+		return addr, ok
+	}, p2p.DefaultChunkClientConfig())
+
+	ctx := context.Background() // Pull has no caller context
+	var (
+		manifest *p2p.ChunkManifest
+		lastErr  error
+	)
+	for peer := range addrs {
+		m, err := client.FetchManifest(ctx, peer, digest)
+		if err == nil {
+			manifest = m
+			break
+		}
+		lastErr = err
+	}
+	if manifest == nil {
+		return fmt.Errorf("fetch manifest: %w", lastErr)
+	}
+
+	// Hosts of one digest chunk it identically, so each holds every chunk
+	chunks := make([]p2p.ChunkDigest, len(manifest.Chunks))
+	for i, c := range manifest.Chunks {
+		chunks[i] = c.Digest
+	}
+	swarm := p2p.NewPeerChunkMap()
+	for peer := range addrs {
+		swarm.RegisterPeer(peer, chunks)
+	}
+
+	cfg := p2p.DefaultDownloadConfig()
+	cfg.Reputation = func(peer string) float64 {
+		if r := d.Reputation.Get(peer); r != nil {
+			return r.Overall()
+		}
+		return reputation.DefaultReputation
+	}
+	cfg.ModelBlocked = d.Fabric.Blocklist().ModelBlocked
+	cfg.PeerBlocked = func(peer string) bool { return d.isBlocked(peer) || d.isQuarantined(peer) }
+	cfg.OnTransfer = func(peer string, n int, elapsed time.Duration) {
+		if d.NetProbe != nil {
+			d.NetProbe.ObserveTransfer(peer, n, elapsed)
+		}
+		d.Seeder.RecordDownload(peer, int64(n))
+	}
+	if d.NetProbe != nil {
+		cfg.Bandwidth = d.NetProbe.Bandwidth
+	}
+	if progress != nil {
+		cfg.OnProgress = func(ev p2p.DownloadEvent) {
+			progress(fmt.Sprintf("downloading %s / %s from %d peers",
+				domain.HumanSize(ev.BytesDone), domain.HumanSize(ev.TotalBytes), len(addrs)), ev.Percent)
+		}
+	}
+	_, err := p2p.NewDownloader(d.Fabric.NodeID(), swarm, client, d.DB, cfg).Download(ctx, manifest, dest)
+	return err
+}
+
+// chunkAddrs maps the peers that can serve model at digest (SHA-256 hex)
+// to their chunk server addresses: the gossip endpoint's host with the
+// heartbeat's chunk port.
+func (d *Daemon) chunkAddrs(model, digest string) map[string]string {
+	hosts := d.Fabric.Availability().HostsVersion(model, "sha256:"+digest)
+	if len(hosts) == 0 {
+		return nil
+	}
+	ports := make(map[string]int)
+	for _, h := range d.Fabric.Heartbeats().Snapshot() {
+		if h.ChunkPort > 0 {
+			ports[h.NodeID] = h.ChunkPort
+		}
+	}
+	endpoints := make(map[string]string)
+	for _, p := range d.Fabric.Peers() {
+		if p.State == domain.PeerAlive {
+			endpoints[p.NodeID] = p.Endpoint
+		}
+	}
+
+	addrs := make(map[string]string)
+	for _, peer := range hosts {
+		if peer == d.Fabric.NodeID() || d.isBlocked(peer) || d.isQuarantined(peer) {
+			continue
+		}
+		host, _, err := net.SplitHostPort(endpoints[peer])
+		if err != nil || ports[peer] == 0 {
+			continue
+		}
+		addrs[peer] = net.JoinHostPort(host, strconv.Itoa(ports[peer]))
+	}
+	return addrs
+}
+
+// ─── Seeding Rewards ────────────────────────────────────────────────────────
+// Chunk uploads to peers pass through the Seeder. The seeding_settle job
+// pays for them at credit.SeedingEarningAmount, scaled by this node's
+// reputation; bytes that do not add up to a whole credit, or whose
+// payment failed, stay with the Seeder until the next settlement.
+
+// settleSeeding is the seeding_settle job.
+func (d *Daemon) settleSeeding(context.Context) (string, error) {
+	rep := reputation.DefaultReputation
+	if r := d.Reputation.Get(d.nodeID); r != nil {
+		rep = r.Overall()
+	}
+	c, err := d.Seeder.SettleContribution(func(bytes int64) int64 {
+		return credit.SeedingEarningAmount(bytes, rep)
+	}, func(c p2p.Contribution) error {
+		if c.Credits <= 0 {
+			return nil
+		}
+		reason := fmt.Sprintf("seeding: %d bytes to peers", c.Bytes)
+		if err := d.Credit.Earn(c.Credits, "seeding-"+c.Until.UTC().Format("20060102T150405"), reason); err != nil {
+			return fmt.Errorf("earn %d credits: %w", c.Credits, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("earned %d credits for %d bytes, %d bytes carried", c.Credits, c.Bytes, c.Carried), nil
+}
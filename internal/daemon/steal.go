@@ -0,0 +1,27 @@
+package daemon
+
+import (
+	"github.com/tutu-network/tutu/internal/infra/scheduler"
+)
+
+// ─── Work Stealing ──────────────────────────────────────────────────────────
+// Queue depth is gossiped with SWIM; an idle node picks the busiest trusted
+// peer as its victim. Grants and confirmations need a signed peer-to-peer
+// request channel that does not exist yet, so Request and Confirm are left
+// unset: the stealer never dequeues work for a peer and never asks one,
+// rather than failing every attempt.
+
+// stealHooks connects the stealer to gossiped peer load.
+func (d *Daemon) stealHooks() scheduler.StealHooks {
+	return scheduler.StealHooks{
+		Victims: func(minDepth int) []string {
+			var out []string
+			for _, n := range d.Fabric.Load().Busiest(minDepth) {
+				if !d.Anomaly.IsKnownThreat(n.NodeID) && !d.isQuarantined(n.NodeID) && !d.isBlocked(n.NodeID) {
+					out = append(out, n.NodeID)
+				}
+			}
+			return out
+		},
+	}
+}
@@ -0,0 +1,97 @@
+package daemon
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/tutu-network/tutu/internal/domain"
+	"github.com/tutu-network/tutu/internal/infra/resource"
+	"github.com/tutu-network/tutu/internal/infra/selfheal"
+	"github.com/tutu-network/tutu/internal/infra/telemetry"
+)
+
+// ─── Thermal Telemetry ──────────────────────────────────────────────────────
+// The telemetry monitor samples CPU and GPU temperature and power draw.
+// Sustained heat marks the node throttled: the flag rides on heartbeats so
+// peers' schedulers penalise it, running fine-tunes are drained, new ones
+// are refused, and a THERMAL_THROTTLE incident stays open until the machine
+// cools down.
+
+// telemetryReader reads the host sensors for the telemetry monitor.
+func telemetryReader() func() telemetry.Reading {
+	thermal := resource.NewThermalMonitor()
+	power := resource.NewPowerMonitor()
+	return func() telemetry.Reading {
+		return telemetry.Reading{
+			CPUTempC:   thermal.CPUTemp(),
+			GPUTempC:   thermal.GPUTemp(),
+			PowerWatts: power.Watts(),
+		}
+	}
+}
+
+// thermalChange opens a THERMAL_THROTTLE incident for this node when it
+// becomes throttled and resolves it once the node has cooled.
+func (d *Daemon) thermalChange(nodeID string) func(bool, telemetry.Stats) {
+	return func(throttled bool, st telemetry.Stats) {
+		if !throttled {
+			log.Printf("[thermal] cooled down (peak %d°C in window)", st.PeakTempC)
+			for _, inc := range d.SelfHeal.ActiveIncidents() {
+				if inc.NodeID == nodeID && inc.FailureType == selfheal.FailThermalThrottle {
+					_ = d.SelfHeal.Verify(inc.ID, true)
+				}
+			}
+			return
+		}
+
+		reason := fmt.Sprintf("sustained %d°C", st.Latest.Hottest())
+		log.Printf("[thermal] throttled: %s, %.0f%% of readings over the limit", reason, 100*st.HotFraction)
+		inc, created := d.SelfHeal.Detect(nodeID, selfheal.FailThermalThrottle)
+		if !created {
+			return
+		}
+		drained := d.Executor.Drain(reason, isFineTune)
+		if err := d.SelfHeal.Isolate(inc.ID, drained); err != nil {
+			return
+		}
+		// The runbook's actions are what this callback and the heartbeat
+		// already do; the incident then waits in REMEDIATING for the cooldown
+		actions, err := d.SelfHeal.Remediate(inc.ID)
+		if err != nil {
+			return
+		}
+		for _, a := range actions {
+			if a.Name != "wait_cooldown" {
+				_ = d.SelfHeal.RecordActionComplete(inc.ID, a.Name)
+			}
+		}
+	}
+}
+
+// admitThermal refuses fine-tune work while this node is throttled.
+func (d *Daemon) admitThermal(task domain.Task) error {
+	if isFineTune(task) && d.Telemetry.Throttled() {
+		return fmt.Errorf("%w: not accepting fine-tune shards", domain.ErrThermalThrottled)
+	}
+	return nil
+}
+
+// nodeThrottled reports whether a node is in sustained thermal throttle:
+// this node from telemetry, peers from their heartbeats.
+func (d *Daemon) nodeThrottled(localID string) func(string) bool {
+	return func(nodeID string) bool {
+		if nodeID == localID || (d.Fabric != nil && nodeID == d.Fabric.NodeID()) {
+			return d.Telemetry.Throttled()
+		}
+		if d.Fabric == nil {
+			return false
+		}
+		h, ok := d.Fabric.Heartbeats().Get(nodeID)
+		return ok && h.Throttled
+	}
+}
+
+// isFineTune reports whether a task is a fine-tune shard.
+func isFineTune(task domain.Task) bool {
+	return task.Type == domain.TaskFineTune
+}
@@ -0,0 +1,45 @@
+package daemon
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/infra/observability"
+)
+
+// ─── Trace Export ───────────────────────────────────────────────────────────
+// Spans stay in the Tracer's ring buffer for /api/diagnostics; with an OTLP
+// endpoint configured, every span recorded since the last push is also sent
+// to the collector. A failed push is logged and retried with the next batch;
+// spans that fall out of the ring buffer meanwhile are lost.
+
+// traceExportInterval is how often new spans are pushed.
+const traceExportInterval = 10 * time.Second
+
+// traceExportLoop pushes new spans to the OTLP collector until ctx is done.
+func (d *Daemon) traceExportLoop(ctx context.Context) {
+	cfg := observability.OTLPConfig{
+		Endpoint: d.Config.Telemetry.OTLPEndpoint,
+		NodeID:   d.nodeID,
+	}
+	ticker := time.NewTicker(traceExportInterval)
+	defer ticker.Stop()
+	var cursor uint64
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			spans, next := d.Tracer.SpansSince(cursor)
+			if len(spans) == 0 {
+				continue
+			}
+			if err := observability.ExportOTLP(ctx, cfg, spans); err != nil {
+				log.Printf("[daemon] trace export: %v", err)
+				continue
+			}
+			cursor = next
+		}
+	}
+}
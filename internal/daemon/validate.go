@@ -0,0 +1,237 @@
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"slices"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/infra/engine"
+)
+
+// ─── Config Validation ──────────────────────────────────────────────────────
+// Validate reports every bad value at once, each prefixed with its TOML
+// path ("gossip.interval: ..."), so an operator can fix a config in one
+// pass. The daemon refuses to start on an invalid config; `tutu config
+// validate` runs the same checks without starting anything.
+
+var sizePattern = regexp.MustCompile(`^[0-9]+(KB|MB|GB|TB)?$`)
+
+// Validate checks the configuration for values the daemon cannot run with.
+func (c Config) Validate() error {
+	v := &validator{}
+
+	v.check(c.API.Port >= 1 && c.API.Port <= 65535, "api.port", "must be between 1 and 65535, got %d", c.API.Port)
+	v.check(c.API.MaxConcurrent >= 1, "api.max_concurrent", "must be at least 1, got %d", c.API.MaxConcurrent)
+
+	v.check(sizePattern.MatchString(c.Models.MaxStorage), "models.max_storage", "must look like 50GB, got %q", c.Models.MaxStorage)
+
+	v.check(c.Inference.ContextLength > 0, "inference.context_length", "must be positive, got %d", c.Inference.ContextLength)
+	v.check(c.Inference.BatchSize > 0, "inference.batch_size", "must be positive, got %d", c.Inference.BatchSize)
+	v.check(c.Inference.Threads >= 0, "inference.threads", "must not be negative, got %d", c.Inference.Threads)
+	v.check(c.Inference.MaxBatchRequests >= 1, "inference.max_batch_requests", "must be at least 1, got %d", c.Inference.MaxBatchRequests)
+	v.check(c.Inference.BatchWindowMS >= 0, "inference.batch_window_ms", "must not be negative, got %d", c.Inference.BatchWindowMS)
+	v.check(c.Inference.StreamBuffer >= 0, "inference.stream_buffer", "must not be negative, got %d", c.Inference.StreamBuffer)
+	v.oneOf(c.Inference.Backpressure, "inference.backpressure",
+		string(engine.BackpressureBlock), string(engine.BackpressureDrop), string(engine.BackpressureAbort))
+	v.check(c.Inference.StreamStallSeconds >= 0, "inference.stream_stall_seconds", "must not be negative, got %d", c.Inference.StreamStallSeconds)
+	for model, n := range c.Inference.ModelMaxTokens {
+		v.check(n > 0, "inference.model_max_tokens."+model, "must be positive, got %d", n)
+	}
+	for target, sc := range c.Inference.Speculative {
+		v.check(sc.DraftModel != "", "inference.speculative."+target+".draft_model", "is required")
+		v.check(sc.DraftTokens >= 0, "inference.speculative."+target+".draft_tokens", "must not be negative, got %d", sc.DraftTokens)
+	}
+	v.check(c.Inference.KVSlots >= 1, "inference.kv_slots", "must be at least 1, got %d", c.Inference.KVSlots)
+	v.check(sizePattern.MatchString(c.Inference.KVCacheBudget), "inference.kv_cache_budget", "must look like 2GB, got %q", c.Inference.KVCacheBudget)
+
+	v.oneOf(c.Logging.Level, "logging.level", "debug", "info", "warn", "error")
+
+	v.duration(c.Network.HeartbeatInterval, "network.heartbeat_interval")
+	v.check(sizePattern.MatchString(c.Network.ProbeBudget), "network.probe_budget", "must look like 4MB, got %q", c.Network.ProbeBudget)
+	probeMin := v.duration(c.Network.ProbeMinInterval, "network.probe_min_interval")
+	probeMax := v.duration(c.Network.ProbeMaxInterval, "network.probe_max_interval")
+	if probeMin > 0 && probeMax > 0 {
+		v.check(probeMax >= probeMin, "network.probe_max_interval", "must not be shorter than network.probe_min_interval (%s), got %s", probeMin, probeMax)
+	}
+
+	v.percent(c.Resources.MaxCPUPercent, "resources.max_cpu_percent")
+	v.percent(c.Resources.MaxMemoryPercent, "resources.max_memory_percent")
+	v.check(c.Resources.ThermalThrottle < c.Resources.ThermalShutdown, "resources.thermal_throttle",
+		"must be below thermal_shutdown (%d), got %d", c.Resources.ThermalShutdown, c.Resources.ThermalThrottle)
+
+	if c.Telemetry.Prometheus {
+		v.check(c.Telemetry.PrometheusPort >= 1 && c.Telemetry.PrometheusPort <= 65535,
+			"telemetry.prometheus_port", "must be between 1 and 65535, got %d", c.Telemetry.PrometheusPort)
+	}
+
+	v.check(c.MCP.RateLimitRPM >= 0, "mcp.rate_limit_rpm", "must not be negative, got %d", c.MCP.RateLimitRPM)
+	v.check(sizePattern.MatchString(c.MCP.MaxRequestSize), "mcp.max_request_size", "must look like 1MB, got %q", c.MCP.MaxRequestSize)
+
+	v.duration(c.Agent.IdleTimeout, "agent.idle_timeout")
+	v.check(c.Agent.MaxAgents >= 1, "agent.max_agents", "must be at least 1, got %d", c.Agent.MaxAgents)
+
+	ping := v.duration(c.Gossip.PingTimeout, "gossip.ping_timeout")
+	interval := v.duration(c.Gossip.Interval, "gossip.interval")
+	v.duration(c.Gossip.SuspectTTL, "gossip.suspect_ttl")
+	if ping > 0 && interval > 0 {
+		v.check(ping < interval, "gossip.ping_timeout", "must be shorter than gossip.interval (%s), got %s", interval, ping)
+	}
+	v.check(c.Gossip.IndirectProbes >= 0, "gossip.indirect_probes", "must not be negative, got %d", c.Gossip.IndirectProbes)
+	v.check(c.Gossip.Retransmit >= 1, "gossip.retransmit", "must be at least 1, got %d", c.Gossip.Retransmit)
+	v.duration(c.Gossip.MaxClockSkew, "gossip.max_clock_skew")
+	v.duration(c.Gossip.MaxUpdateAge, "gossip.max_update_age")
+	v.check(c.Gossip.ReplayWindow >= 64, "gossip.replay_window", "must be at least 64, got %d", c.Gossip.ReplayWindow)
+	for _, seed := range c.Gossip.SnapshotSeeds {
+		_, _, err := net.SplitHostPort(seed)
+		v.check(err == nil, "gossip.snapshot_seeds", "must be host:port, got %q", seed)
+	}
+
+	s := c.Scheduler
+	v.check(s.BackPressureSoft > 0, "scheduler.backpressure_soft", "must be positive, got %d", s.BackPressureSoft)
+	v.check(s.BackPressureSoft <= s.BackPressureMedium, "scheduler.backpressure_medium",
+		"must be at least backpressure_soft (%d), got %d", s.BackPressureSoft, s.BackPressureMedium)
+	v.check(s.BackPressureMedium <= s.BackPressureHard, "scheduler.backpressure_hard",
+		"must be at least backpressure_medium (%d), got %d", s.BackPressureMedium, s.BackPressureHard)
+	v.check(s.BackPressureHard <= s.MaxQueueDepth, "scheduler.max_queue_depth",
+		"must be at least backpressure_hard (%d), got %d", s.BackPressureHard, s.MaxQueueDepth)
+	v.check(s.StealBatchSize >= 0, "scheduler.steal_batch_size", "must not be negative, got %d", s.StealBatchSize)
+	v.duration(s.StarvationInterval, "scheduler.starvation_interval")
+
+	a := c.Autoscale
+	v.check(a.Alpha > 0 && a.Alpha <= 1, "autoscale.alpha", "must be in (0, 1], got %g", a.Alpha)
+	v.check(a.SeasonalAlpha > 0 && a.SeasonalAlpha <= 1, "autoscale.seasonal_alpha", "must be in (0, 1], got %g", a.SeasonalAlpha)
+	v.check(a.SeasonalPeriod >= 1, "autoscale.seasonal_period", "must be at least 1, got %d", a.SeasonalPeriod)
+	v.check(a.ScaleDownThreshold > 0 && a.ScaleDownThreshold < a.ScaleUpThreshold, "autoscale.scale_down_threshold",
+		"must be positive and below scale_up_threshold (%g), got %g", a.ScaleUpThreshold, a.ScaleDownThreshold)
+	v.check(a.MinCapacity >= 0, "autoscale.min_capacity", "must not be negative, got %d", a.MinCapacity)
+	v.check(a.MaxCapacity >= a.MinCapacity && a.MaxCapacity > 0, "autoscale.max_capacity",
+		"must be positive and at least min_capacity (%d), got %d", a.MinCapacity, a.MaxCapacity)
+	v.duration(a.PreWarmLeadTime, "autoscale.prewarm_lead_time")
+	v.duration(a.Cooldown, "autoscale.cooldown")
+	if a.PrometheusURL != "" {
+		v.duration(a.Backfill, "autoscale.backfill")
+	}
+
+	in := c.Intelligence
+	v.check(in.RetirementDays >= 1, "intelligence.retirement_days", "must be at least 1, got %d", in.RetirementDays)
+	v.duration(in.PlacementInterval, "intelligence.placement_interval")
+	v.check(in.MinRequestsForPlacement >= 0, "intelligence.min_requests_for_placement", "must not be negative, got %d", in.MinRequestsForPlacement)
+	v.check(in.MaxRecommendations >= 1, "intelligence.max_recommendations", "must be at least 1, got %d", in.MaxRecommendations)
+	v.check(in.MaxRetirementCandidates >= 1, "intelligence.max_retirement_candidates", "must be at least 1, got %d", in.MaxRetirementCandidates)
+	v.check(in.HealthHistorySize >= 1, "intelligence.health_history_size", "must be at least 1, got %d", in.HealthHistorySize)
+	v.check(in.HealthEpsilon >= 0, "intelligence.health_epsilon", "must not be negative, got %g", in.HealthEpsilon)
+	v.duration(in.HealthMaxAge, "intelligence.health_max_age")
+	v.check(in.PlaceTopModels >= 1, "intelligence.place_top_models", "must be at least 1, got %d", in.PlaceTopModels)
+	v.check(in.PlaceMinNodeScore > 0, "intelligence.place_min_node_score", "must be positive, got %g", in.PlaceMinNodeScore)
+	v.check(in.EvictMaxAffinity > 0 && in.EvictMaxAffinity <= 1, "intelligence.evict_max_affinity", "must be in (0, 1], got %g", in.EvictMaxAffinity)
+	v.check(in.EvictVRAMPressure > 0 && in.EvictVRAMPressure <= 1, "intelligence.evict_vram_pressure", "must be in (0, 1], got %g", in.EvictVRAMPressure)
+	v.duration(in.AckTimeout, "intelligence.ack_timeout")
+	v.duration(in.RetryCooldown, "intelligence.retry_cooldown")
+	v.check(in.RetirementMinReplicas >= 0, "intelligence.retirement_min_replicas", "must not be negative, got %d", in.RetirementMinReplicas)
+	v.duration(in.RetirementNotice, "intelligence.retirement_notice")
+	v.duration(in.RetirementGrace, "intelligence.retirement_grace")
+	for org, raw := range in.InsightWebhooks {
+		u, err := url.Parse(raw)
+		v.check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "",
+			"intelligence.insight_webhooks."+org, "must be an http(s) URL, got %q", raw)
+	}
+	for model, target := range in.LatencySLOs {
+		v.duration(target, "intelligence.latency_slos."+model)
+	}
+
+	v.check(c.NAT.BindAddr != "", "nat.bind_addr", "is required")
+	if c.NAT.Relay {
+		v.check(c.NAT.RelayBindAddr != "", "nat.relay_bind_addr", "is required when relay is enabled")
+	}
+
+	hi := c.Hierarchy
+	v.check(hi.Representatives >= 1, "hierarchy.representatives", "must be at least 1, got %d", hi.Representatives)
+	v.check(hi.Fanout >= 1, "hierarchy.fanout", "must be at least 1, got %d", hi.Fanout)
+	summaryEvery := v.duration(hi.Interval, "hierarchy.interval")
+	summaryTTL := v.duration(hi.TTL, "hierarchy.ttl")
+	if summaryEvery > 0 && summaryTTL > 0 {
+		v.check(summaryTTL > summaryEvery, "hierarchy.ttl", "must be longer than hierarchy.interval (%s), got %s", summaryEvery, summaryTTL)
+	}
+	if hi.Cluster == "" {
+		v.check(len(hi.Seeds) == 0, "hierarchy.seeds", "require hierarchy.cluster")
+	}
+
+	if err := c.IdleCompute.Policy().Validate(); err != nil {
+		v.errs = append(v.errs, fmt.Errorf("idle_compute: %w", err))
+	}
+	v.duration(c.IdleCompute.Interval, "idle_compute.interval")
+
+	hk := c.Housekeeping
+	v.check(hk.Jitter >= 0 && hk.Jitter < 1, "housekeeping.jitter", "must be in [0, 1), got %g", hk.Jitter)
+	v.duration(hk.ReputationDecay, "housekeeping.reputation_decay")
+	v.duration(hk.AnomalyCleanup, "housekeeping.anomaly_cleanup")
+	v.duration(hk.RetentionPrune, "housekeeping.retention_prune")
+	v.duration(hk.RetirementScan, "housekeeping.retirement_scan")
+	v.duration(hk.ScalerEvaluate, "housekeeping.scaler_evaluate")
+	v.duration(hk.ModelBenchmark, "housekeeping.model_benchmark")
+	v.duration(hk.HealthInsights, "housekeeping.health_insights")
+	v.duration(hk.SchedSnapshot, "housekeeping.scheduler_snapshot")
+	v.duration(hk.IntelSnapshot, "housekeeping.intelligence_snapshot")
+	v.duration(hk.SeedingSettle, "housekeeping.seeding_settle")
+	for _, job := range hk.Disabled {
+		_, ok := hk.Intervals()[job]
+		v.check(ok, "housekeeping.disabled", "unknown job %q", job)
+	}
+
+	return errors.Join(v.errs...)
+}
+
+// ValidateFile loads path with environment overrides applied and validates
+// it. Unlike LoadConfig it also rejects keys the daemon does not know —
+// usually a typo that would otherwise be silently ignored.
+func ValidateFile(path string) (Config, error) {
+	_, unknown, err := decodeConfigFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+	cfg, err := LoadConfigFile(path)
+	if err != nil {
+		return cfg, err
+	}
+
+	var errs []error
+	for _, key := range unknown {
+		errs = append(errs, fmt.Errorf("%s: unknown key", key))
+	}
+	if err := cfg.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	return cfg, errors.Join(errs...)
+}
+
+type validator struct {
+	errs []error
+}
+
+func (v *validator) check(ok bool, key, format string, args ...any) {
+	if !ok {
+		v.errs = append(v.errs, fmt.Errorf("%s: "+format, append([]any{key}, args...)...))
+	}
+}
+
+func (v *validator) oneOf(got, key string, allowed ...string) {
+	v.check(slices.Contains(allowed, got), key, "must be one of %v, got %q", allowed, got)
+}
+
+func (v *validator) percent(got int, key string) {
+	v.check(got >= 1 && got <= 100, key, "must be between 1 and 100, got %d", got)
+}
+
+// duration validates a positive duration string and returns it (0 if bad).
+func (v *validator) duration(s, key string) time.Duration {
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		v.errs = append(v.errs, fmt.Errorf("%s: must be a positive duration like 10s, got %q", key, s))
+		return 0
+	}
+	return d
+}
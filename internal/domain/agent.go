@@ -0,0 +1,76 @@
+// Package domain — agent orchestration types.
+// An agent run (task type AGENT) is a multi-step plan: each step calls a
+// tool, possibly on another node, and may use earlier steps' outputs. Runs
+// are checkpointed after every step, capped by a credit budget, and traced
+// step by step.
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AgentRunStatus tracks an agent run's lifecycle.
+type AgentRunStatus string
+
+const (
+	AgentRunPending   AgentRunStatus = "PENDING"
+	AgentRunRunning   AgentRunStatus = "RUNNING"
+	AgentRunCompleted AgentRunStatus = "COMPLETED"
+	AgentRunFailed    AgentRunStatus = "FAILED"
+	AgentRunCancelled AgentRunStatus = "CANCELLED"
+)
+
+// AgentStepStatus tracks a single step.
+type AgentStepStatus string
+
+const (
+	AgentStepPending AgentStepStatus = "PENDING"
+	AgentStepDone    AgentStepStatus = "DONE"
+	AgentStepFailed  AgentStepStatus = "FAILED"
+)
+
+// AgentStep is one tool call in a plan. Args may reference the output of an
+// earlier step as {{steps.<id>}}.
+type AgentStep struct {
+	ID          string          `json:"id"`
+	Tool        string          `json:"tool"`
+	Args        json.RawMessage `json:"args,omitempty"`
+	Status      AgentStepStatus `json:"status"`
+	Node        string          `json:"node,omitempty"` // node that ran the tool
+	Output      json.RawMessage `json:"output,omitempty"`
+	Credits     int64           `json:"credits"`
+	Error       string          `json:"error,omitempty"`
+	SpanID      string          `json:"span_id,omitempty"`
+	StartedAt   time.Time       `json:"started_at,omitempty"`
+	CompletedAt time.Time       `json:"completed_at,omitempty"`
+}
+
+// AgentRun is a multi-step agent plan and its progress.
+type AgentRun struct {
+	ID        string         `json:"id"`
+	Name      string         `json:"name"`
+	Status    AgentRunStatus `json:"status"`
+	Budget    int64          `json:"budget_credits"` // 0 = unlimited
+	Spent     int64          `json:"spent_credits"`
+	Steps     []AgentStep    `json:"steps"`
+	Error     string         `json:"error,omitempty"`
+	TraceID   string         `json:"trace_id,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+// IsTerminal returns true if the run has reached a final state.
+func (r *AgentRun) IsTerminal() bool {
+	return r.Status == AgentRunCompleted || r.Status == AgentRunFailed || r.Status == AgentRunCancelled
+}
+
+// NextStep returns the index of the first step not yet done, or -1.
+func (r *AgentRun) NextStep() int {
+	for i, s := range r.Steps {
+		if s.Status != AgentStepDone {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,43 @@
+// Package domain — task result attestation types.
+// The executing node signs (task ID, input digest, output digest, timing);
+// the requester verifies the signature before paying, and attestations are
+// persisted as cryptographic evidence for accuracy disputes.
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// Attestation is a signed statement by an executing node about a task result.
+type Attestation struct {
+	TaskID       string    `json:"task_id"`
+	NodeID       string    `json:"node_id"`       // Hex-encoded Ed25519 public key of the executor
+	InputDigest  string    `json:"input_digest"`  // "sha256:<hex>" of the task input
+	OutputDigest string    `json:"output_digest"` // "sha256:<hex>" of the task output
+	StartedAt    time.Time `json:"started_at"`
+	CompletedAt  time.Time `json:"completed_at"`
+	Signature    []byte    `json:"signature,omitempty"`
+}
+
+// SigningPayload returns the canonical byte string covered by the signature.
+// Timestamps are encoded at nanosecond precision in UTC so signer and
+// verifier always derive identical bytes.
+func (a Attestation) SigningPayload() []byte {
+	return []byte(fmt.Sprintf("tutu-attestation-v1\n%s\n%s\n%s\n%s\n%d\n%d",
+		a.TaskID, a.NodeID, a.InputDigest, a.OutputDigest,
+		a.StartedAt.UTC().UnixNano(), a.CompletedAt.UTC().UnixNano()))
+}
+
+// Duration returns the attested execution time.
+func (a Attestation) Duration() time.Duration {
+	if a.StartedAt.IsZero() || a.CompletedAt.IsZero() {
+		return 0
+	}
+	return a.CompletedAt.Sub(a.StartedAt)
+}
+
+// IsSigned reports whether the attestation carries a signature.
+func (a Attestation) IsSigned() bool {
+	return len(a.Signature) > 0
+}
@@ -0,0 +1,33 @@
+// Package domain — audit log types.
+// Privileged operations (admin API calls, governance executions, federation
+// policy changes, quarantine actions, runtime parameter changes) are
+// recorded as hash-chained entries: each entry's Hash covers its own fields
+// plus the previous entry's Hash, so any edit or deletion breaks the chain
+// from that point onward.
+package domain
+
+import "time"
+
+// AuditCategory groups audit entries by the subsystem that produced them.
+type AuditCategory string
+
+const (
+	AuditAdminAPI   AuditCategory = "ADMIN_API"
+	AuditGovernance AuditCategory = "GOVERNANCE"
+	AuditFederation AuditCategory = "FEDERATION"
+	AuditQuarantine AuditCategory = "QUARANTINE"
+	AuditParams     AuditCategory = "PARAMS"
+)
+
+// AuditEntry is a single tamper-evident audit record.
+type AuditEntry struct {
+	Seq       int64         `json:"seq"`
+	Timestamp time.Time     `json:"timestamp"`
+	Category  AuditCategory `json:"category"`
+	Actor     string        `json:"actor"`
+	Action    string        `json:"action"`
+	Target    string        `json:"target"`
+	Details   string        `json:"details,omitempty"`
+	PrevHash  string        `json:"prev_hash"`
+	Hash      string        `json:"hash"`
+}
@@ -24,6 +24,8 @@ const (
 	TxRelease TransactionType = "RELEASE"
 	TxPenalty TransactionType = "PENALTY"
 	TxBonus   TransactionType = "BONUS"
+	TxTopUp   TransactionType = "TOPUP"  // credits bought through an external payment
+	TxRefund  TransactionType = "REFUND" // a refunded purchase taken back
 )
 
 // LedgerEntry is a single row in the double-entry credit ledger.
@@ -37,4 +39,63 @@ type LedgerEntry struct {
 	TaskID      string          `json:"task_id,omitempty"`
 	Description string          `json:"description,omitempty"`
 	Balance     int64           `json:"balance"`
+	Namespace   string          `json:"namespace,omitempty"` // tenant the entry is accounted to
+}
+
+// ─── Billing ────────────────────────────────────────────────────────────────
+// Credits bought with fiat arrive as payment events from an external billing
+// provider. Each successful payment tops up the ledger and issues an invoice
+// the buyer can reconcile against; a refund takes the credits back.
+
+// PaymentEventType is the kind of an external payment event.
+type PaymentEventType string
+
+const (
+	PaymentSucceeded PaymentEventType = "payment.succeeded"
+	PaymentRefunded  PaymentEventType = "payment.refunded"
+)
+
+// PaymentEvent is one webhook delivery from a billing provider.
+type PaymentEvent struct {
+	ID          string           `json:"id"` // provider event ID; deliveries are idempotent on it
+	Provider    string           `json:"provider"`
+	Type        PaymentEventType `json:"type"`
+	Namespace   string           `json:"namespace,omitempty"` // tenant the credits belong to (default namespace if empty)
+	Customer    string           `json:"customer,omitempty"`  // provider's customer reference
+	AmountMinor int64            `json:"amount_minor"`        // fiat amount in minor units (cents)
+	Currency    string           `json:"currency"`
+	Credits     int64            `json:"credits"`
+	Reference   string           `json:"reference,omitempty"`  // provider's payment or invoice reference
+	PaymentID   string           `json:"payment_id,omitempty"` // refunds: the event ID of the payment refunded
+	OccurredAt  time.Time        `json:"occurred_at"`
+	ReceivedAt  time.Time        `json:"received_at"`
+}
+
+// InvoiceStatus is the state of an invoice.
+type InvoiceStatus string
+
+const (
+	InvoicePaid     InvoiceStatus = "PAID"
+	InvoiceRefunded InvoiceStatus = "REFUNDED"
+)
+
+// Invoice is the receipt of one credit purchase.
+type Invoice struct {
+	ID          string        `json:"id"`
+	EventID     string        `json:"event_id"` // the payment event that created it
+	Provider    string        `json:"provider"`
+	Namespace   string        `json:"namespace"`
+	Customer    string        `json:"customer,omitempty"`
+	AmountMinor int64         `json:"amount_minor"`
+	Currency    string        `json:"currency"`
+	Credits     int64         `json:"credits"`
+	Reference   string        `json:"reference,omitempty"`
+	Status      InvoiceStatus `json:"status"`
+	IssuedAt    time.Time     `json:"issued_at"`
+	RefundedAt  time.Time     `json:"refunded_at,omitempty"`
+}
+
+// InvoiceID returns the ID of the invoice issued for a payment event.
+func InvoiceID(eventID string) string {
+	return "inv-" + eventID
 }
@@ -1,6 +1,8 @@
 package domain
 
 import (
+	"errors"
+	"fmt"
 	"testing"
 	"time"
 )
@@ -166,6 +168,28 @@ func TestSentinelErrors(t *testing.T) {
 	}
 }
 
+func TestCodeOf(t *testing.T) {
+	wrapped := fmt.Errorf("%w: fed-1", ErrFederationNotFound)
+	if got := CodeOf(wrapped); got != CodeNotFound {
+		t.Errorf("CodeOf(wrapped) = %q, want %q", got, CodeNotFound)
+	}
+	if !errors.Is(wrapped, ErrFederationNotFound) {
+		t.Error("wrapped coded sentinel lost its identity")
+	}
+	if errors.Is(wrapped, ErrProposalNotFound) {
+		t.Error("sentinels with the same code compare equal")
+	}
+	if got := CodeOf(fmt.Errorf("outer: %w", ErrTooManyActiveProposals)); got != CodeQuotaExceeded {
+		t.Errorf("CodeOf = %q, want %q", got, CodeQuotaExceeded)
+	}
+	if got := CodeOf(ErrModelCorrupted); got != "" {
+		t.Errorf("CodeOf(uncoded) = %q, want empty", got)
+	}
+	if got := CodeOf(nil); got != "" {
+		t.Errorf("CodeOf(nil) = %q, want empty", got)
+	}
+}
+
 // ─── Credit Type Tests (Phase 1 prep) ───────────────────────────────────────
 
 func TestEntryTypes(t *testing.T) {
@@ -211,3 +235,37 @@ func TestLedgerEntry(t *testing.T) {
 		t.Errorf("expected TxEarn, got %s", entry.Type)
 	}
 }
+
+// ─── Failure Taxonomy Tests ─────────────────────────────────────────────────
+
+func TestParseFailureType(t *testing.T) {
+	tests := []struct {
+		in   string
+		want FailureType
+	}{
+		{"DISK_FULL", FailureDiskFull},
+		{" gpu_error ", FailureGPUError},
+		{"HIGH_FAIL_RATE", FailureHighErrorRate}, // legacy anomaly name
+		{"", FailureUnknown},
+		{"COSMIC_RAY", FailureUnknown},
+	}
+	for _, tt := range tests {
+		if got := ParseFailureType(tt.in); got != tt.want {
+			t.Errorf("ParseFailureType(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFailureType_Category(t *testing.T) {
+	for _, ft := range AllFailureTypes() {
+		if !ft.IsValid() || ft.Category() == FailureCategoryUnknown {
+			t.Errorf("%s has no category", ft)
+		}
+	}
+	if FailureUnknown.IsValid() || FailureUnknown.Category() != FailureCategoryUnknown {
+		t.Error("FailureUnknown should be outside the taxonomy")
+	}
+	if FailureHeartbeatLost.Category() != FailureCategoryNetwork {
+		t.Errorf("HEARTBEAT_LOST category = %s", FailureHeartbeatLost.Category())
+	}
+}
@@ -0,0 +1,72 @@
+// Package domain — embedding batch and vector store types.
+// A batch job embeds a corpus: identical inputs are embedded once, unique
+// inputs are sharded across nodes, and per-item results stream back as
+// shards finish. Vectors can be kept in a named collection and queried by
+// cosine similarity.
+package domain
+
+import "time"
+
+// EmbedJobStatus tracks a batch job's lifecycle.
+type EmbedJobStatus string
+
+const (
+	EmbedJobRunning   EmbedJobStatus = "RUNNING"
+	EmbedJobCompleted EmbedJobStatus = "COMPLETED" // every item has a result (some may have errors)
+	EmbedJobCancelled EmbedJobStatus = "CANCELLED"
+)
+
+// EmbedItem is one input of a batch.
+type EmbedItem struct {
+	ID   string `json:"id,omitempty"` // caller's ID; defaults to the input index
+	Text string `json:"text"`
+}
+
+// EmbedJob is an embedding batch and its progress.
+type EmbedJob struct {
+	ID          string         `json:"id"`
+	Model       string         `json:"model"`
+	Collection  string         `json:"collection,omitempty"` // vectors are stored here when set
+	Status      EmbedJobStatus `json:"status"`
+	Total       int            `json:"total"`  // items submitted
+	Unique      int            `json:"unique"` // distinct inputs actually embedded
+	Shards      int            `json:"shards"`
+	Done        int            `json:"done"`   // items with a vector
+	Failed      int            `json:"failed"` // items with an error
+	CreatedAt   time.Time      `json:"created_at"`
+	CompletedAt time.Time      `json:"completed_at,omitempty"`
+}
+
+// EmbedItemResult is the outcome for one item of a batch.
+type EmbedItemResult struct {
+	Index     int       `json:"index"`
+	ID        string    `json:"id"`
+	Embedding []float32 `json:"embedding,omitempty"`
+	Node      string    `json:"node,omitempty"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// VectorRecord is a stored embedding.
+type VectorRecord struct {
+	Collection string    `json:"collection"`
+	ID         string    `json:"id"`
+	Model      string    `json:"model"`
+	Text       string    `json:"text"`
+	Vector     []float32 `json:"vector"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// VectorMatch is a similarity search hit.
+type VectorMatch struct {
+	ID    string  `json:"id"`
+	Text  string  `json:"text"`
+	Score float64 `json:"score"` // cosine similarity, -1..1
+}
+
+// VectorCollection summarizes a stored collection.
+type VectorCollection struct {
+	Name  string `json:"name"`
+	Model string `json:"model"`
+	Dim   int    `json:"dim"`
+	Count int    `json:"count"`
+}
@@ -110,6 +110,11 @@ const (
 	QuestRAG       QuestType = "rag"
 	QuestRefer     QuestType = "refer"
 	QuestSuccess   QuestType = "success_rate"
+
+	// Network contributions, progressed by network subsystem events
+	QuestSeed       QuestType = "seed"       // progress in MB of model chunks uploaded to peers
+	QuestIncident   QuestType = "incident"   // incidents the self-healing mesh resolved
+	QuestGovernance QuestType = "governance" // governance proposals voted on
 )
 
 // Quest represents a weekly challenge with progress tracking.
@@ -162,6 +167,8 @@ const (
 	NotifyDailySummary  NotificationType = "daily_summary"
 	NotifyQuestComplete NotificationType = "quest_complete"
 	NotifyMilestone     NotificationType = "milestone"
+	NotifyHealthInsight NotificationType = "health_insight"
+	NotifyRetirement    NotificationType = "model_retirement"
 )
 
 // Notification is a user-facing message.
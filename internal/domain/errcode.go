@@ -0,0 +1,44 @@
+package domain
+
+import "errors"
+
+// ─── Error Codes ────────────────────────────────────────────────────────────
+// Sentinel errors carry a code so callers can handle a whole class of
+// failure without matching every sentinel; the API maps codes to HTTP
+// statuses. Wrap sentinels with fmt.Errorf("%w: ...") to add detail —
+// errors.Is and CodeOf both see through the wrapping.
+
+// ErrorCode classifies a domain error.
+type ErrorCode string
+
+const (
+	CodeNotFound      ErrorCode = "not_found"      // the addressed entity does not exist
+	CodeConflict      ErrorCode = "conflict"       // the entity exists or is in the wrong state
+	CodeQuotaExceeded ErrorCode = "quota_exceeded" // a count, budget or rate limit is exhausted
+	CodeNotEligible   ErrorCode = "not_eligible"   // the caller may not perform the operation
+	CodeInvalid       ErrorCode = "invalid"        // the request itself is malformed
+	CodeUnavailable   ErrorCode = "unavailable"    // a dependency is down; retrying may succeed
+)
+
+// Error is a sentinel error with a code.
+type Error struct {
+	Code ErrorCode
+	msg  string
+}
+
+// NewError returns a coded sentinel error.
+func NewError(code ErrorCode, msg string) error {
+	return &Error{Code: code, msg: msg}
+}
+
+func (e *Error) Error() string { return e.msg }
+
+// CodeOf returns the code of the first coded error in err's chain, or ""
+// when there is none.
+func CodeOf(err error) ErrorCode {
+	var de *Error
+	if errors.As(err, &de) {
+		return de.Code
+	}
+	return ""
+}
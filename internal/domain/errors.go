@@ -3,12 +3,13 @@ package domain
 import "errors"
 
 // ─── Sentinel Errors ────────────────────────────────────────────────────────
-// Domain errors are pure — no infrastructure dependency.
+// Domain errors are pure — no infrastructure dependency. Errors built with
+// NewError carry an ErrorCode (see errcode.go) that the API maps to a status.
 
 var (
 	// Model errors
-	ErrModelNotFound  = errors.New("model not found")
-	ErrModelExists    = errors.New("model already exists")
+	ErrModelNotFound  = NewError(CodeNotFound, "model not found")
+	ErrModelExists    = NewError(CodeConflict, "model already exists")
 	ErrModelCorrupted = errors.New("model integrity check failed")
 	ErrModelTooLarge  = errors.New("insufficient storage for model")
 
@@ -16,6 +17,9 @@ var (
 	ErrInferenceTimeout = errors.New("inference request timed out")
 	ErrModelNotLoaded   = errors.New("model not loaded in memory")
 	ErrContextExceeded  = errors.New("context length exceeded")
+	ErrInvalidParameter = errors.New("invalid generation parameter")
+	ErrConsumerTooSlow  = errors.New("stream consumer too slow — generation aborted")
+	ErrStreamTruncated  = errors.New("backend ended the stream before completion")
 
 	// TuTufile errors
 	ErrNoFromDirective  = errors.New("TuTufile must include FROM directive")
@@ -26,6 +30,12 @@ var (
 	ErrOffline      = errors.New("no internet connection available")
 	ErrRegistryDown = errors.New("model registry is unreachable")
 
+	// Attestation errors
+	ErrAttestationInvalid  = NewError(CodeInvalid, "task attestation signature invalid")
+	ErrAttestationMismatch = NewError(CodeConflict, "task attestation does not match expected result")
+	ErrAttestationNotFound = NewError(CodeNotFound, "task attestation not found")
+	ErrAttestationDisputed = NewError(CodeConflict, "task attestation already disputed")
+
 	// Pool errors
 	ErrPoolExhausted = errors.New("model pool memory exhausted — all models in use")
 
@@ -33,6 +43,17 @@ var (
 	ErrBackPressureSoft   = errors.New("back-pressure: soft limit — spot tasks rejected")
 	ErrBackPressureMedium = errors.New("back-pressure: medium limit — only realtime accepted")
 	ErrBackPressureHard   = errors.New("back-pressure: hard limit — all tasks rejected")
+	ErrAdmissionDeferred  = errors.New("back-pressure: task deferred — retry later")
+	ErrDeadlineUnmeetable = errors.New("task cannot meet its deadline at current queue depth")
+
+	// Work stealing errors
+	ErrNothingToSteal     = NewError(CodeConflict, "no transferable tasks in queue")
+	ErrStealInvalid       = NewError(CodeInvalid, "invalid work-stealing request")
+	ErrStealGrantNotFound = NewError(CodeNotFound, "steal grant not found")
+	ErrStealGrantExpired  = NewError(CodeConflict, "steal grant expired — tasks reclaimed by owner")
+
+	// Phase 3: Region routing errors
+	ErrRegionNotAllowed = NewError(CodeConflict, "no region allowed for this task")
 
 	// Phase 3: Circuit breaker errors
 	ErrCircuitOpen     = errors.New("circuit breaker is open — service unavailable")
@@ -68,24 +89,36 @@ var (
 	ErrTransferCancelled = errors.New("transfer was cancelled")
 
 	// Phase 5: Federation errors
-	ErrFederationNotFound  = errors.New("federation not found")
-	ErrFederationFull      = errors.New("federation has reached maximum member count")
-	ErrAlreadyFederated    = errors.New("node already belongs to a federation")
-	ErrNotFederated        = errors.New("node is not a member of this federation")
-	ErrAdminCannotLeave    = errors.New("admin cannot leave — transfer admin first or dissolve")
-	ErrFederationSuspended = errors.New("federation is suspended — no new members allowed")
+	ErrFederationNotFound  = NewError(CodeNotFound, "federation not found")
+	ErrFederationFull      = NewError(CodeQuotaExceeded, "federation has reached maximum member count")
+	ErrAlreadyFederated    = NewError(CodeConflict, "node already belongs to a federation")
+	ErrNotFederated        = NewError(CodeNotFound, "node is not a member of this federation")
+	ErrAdminCannotLeave    = NewError(CodeNotEligible, "admin cannot leave — transfer admin first or dissolve")
+	ErrFederationSuspended = NewError(CodeConflict, "federation is suspended — no new members allowed")
+	ErrInvalidFederation   = NewError(CodeInvalid, "invalid federation")
+	ErrFederationNameTaken = NewError(CodeConflict, "federation name already exists")
+	ErrTooManyFederations  = NewError(CodeQuotaExceeded, "maximum number of federations reached")
+	ErrFederationState     = NewError(CodeConflict, "federation status does not allow this operation")
+	ErrNotFederationAdmin  = NewError(CodeNotEligible, "only the federation admin can do this")
+	ErrInviteInvalid       = NewError(CodeInvalid, "invalid or expired federation invite")
 
 	// Phase 5: Governance errors
-	ErrProposalNotFound             = errors.New("governance proposal not found")
-	ErrVotingClosed                 = errors.New("voting period has ended")
-	ErrInsufficientCreditsToPropose = errors.New("insufficient credits to submit a proposal")
-	ErrQuorumNotReached             = errors.New("quorum not reached — proposal cannot pass")
-	ErrAlreadyVoted                 = errors.New("already cast a vote on this proposal")
-	ErrTooManyActiveProposals       = errors.New("maximum active proposals reached")
+	ErrProposalNotFound             = NewError(CodeNotFound, "governance proposal not found")
+	ErrVotingClosed                 = NewError(CodeConflict, "voting period has ended")
+	ErrInsufficientCreditsToPropose = NewError(CodeNotEligible, "insufficient credits to submit a proposal")
+	ErrQuorumNotReached             = NewError(CodeConflict, "quorum not reached — proposal cannot pass")
+	ErrAlreadyVoted                 = NewError(CodeConflict, "already cast a vote on this proposal")
+	ErrTooManyActiveProposals       = NewError(CodeQuotaExceeded, "maximum active proposals reached")
+	ErrInvalidProposal              = NewError(CodeInvalid, "invalid governance proposal")
+	ErrProposalState                = NewError(CodeConflict, "proposal status does not allow this operation")
+	ErrNotProposalAuthor            = NewError(CodeNotEligible, "only the proposal author can do this")
+	ErrInvalidVote                  = NewError(CodeInvalid, "invalid governance vote")
+	ErrVoteSignatureInvalid         = NewError(CodeInvalid, "governance vote signature invalid")
+	ErrInvalidProposalQuery         = NewError(CodeInvalid, "invalid governance proposal query")
 
 	// Phase 5: Reputation errors
-	ErrNodeNotRegistered = errors.New("node not registered in reputation system")
-	ErrReputationTooLow  = errors.New("reputation score below required threshold")
+	ErrNodeNotRegistered = NewError(CodeNotFound, "node not registered in reputation system")
+	ErrReputationTooLow  = NewError(CodeNotEligible, "reputation score below required threshold")
 
 	// Phase 5: Anomaly detection errors
 	ErrNodeAnomalous  = errors.New("node exhibits anomalous behavior")
@@ -109,9 +142,18 @@ var (
 	ErrRemediationExhausted  = errors.New("all remediation attempts exhausted — escalated")
 
 	// Phase 6: Network intelligence errors
-	ErrModelNotTracked     = errors.New("model not tracked by intelligence optimizer")
-	ErrNoPlacementData     = errors.New("insufficient data for placement optimization")
-	ErrRetirementProtected = errors.New("model is pinned and cannot be retired")
+	ErrModelNotTracked        = errors.New("model not tracked by intelligence optimizer")
+	ErrNoPlacementData        = errors.New("insufficient data for placement optimization")
+	ErrRetirementProtected    = NewError(CodeConflict, "model is pinned and cannot be retired")
+	ErrRetirementInFlight     = NewError(CodeConflict, "model is serving requests and cannot be retired")
+	ErrRetirementLastReplica  = NewError(CodeConflict, "model has too few other replicas to be retired")
+	ErrRetirementVetoed       = NewError(CodeConflict, "model retirement has been vetoed")
+	ErrRetirementNotVetoed    = NewError(CodeNotFound, "model retirement is not vetoed")
+	ErrHealthReportTooSoon    = NewError(CodeQuotaExceeded, "organization reported health too recently")
+	ErrStorageQuotaExceeded   = errors.New("model storage budget exceeded — unpin models or raise models.max_storage")
+	ErrRecommendationNotFound = NewError(CodeNotFound, "placement recommendation not found")
+	ErrRecommendationResolved = NewError(CodeConflict, "placement recommendation already resolved")
+	ErrInvalidAckStatus       = NewError(CodeInvalid, "acknowledgement status must be ACCEPTED, REJECTED, COMPLETED or FAILED")
 
 	// Phase 7: Planetary-scale errors
 	ErrContinentUnavailable = errors.New("no reachable regions on target continent")
@@ -135,4 +177,62 @@ var (
 	ErrCouncilElectionInvalid = errors.New("council election invalid — insufficient voter turnout")
 	ErrParameterProtected     = errors.New("parameter is protected — requires supermajority (67%+)")
 	ErrOpenSourceViolation    = errors.New("proposed change violates open-source compliance policy")
+
+	// Runtime parameter errors
+	ErrUnknownParam        = errors.New("unknown runtime parameter")
+	ErrInvalidParamValue   = errors.New("invalid runtime parameter value")
+	ErrParamChangeNotFound = errors.New("parameter change not found in history")
+
+	// Agent orchestration errors
+	ErrAgentRunNotFound    = NewError(CodeNotFound, "agent run not found")
+	ErrAgentPlanInvalid    = NewError(CodeInvalid, "invalid agent plan")
+	ErrUnknownAgentTool    = NewError(CodeInvalid, "unknown agent tool")
+	ErrAgentBudgetExceeded = NewError(CodeQuotaExceeded, "agent run would exceed its credit budget")
+	ErrAgentRunFinished    = NewError(CodeConflict, "agent run already finished")
+
+	// Embedding batch + vector store errors
+	ErrEmbedJobNotFound   = NewError(CodeNotFound, "embedding job not found")
+	ErrEmbedBatchInvalid  = NewError(CodeInvalid, "invalid embedding batch")
+	ErrCollectionNotFound = NewError(CodeNotFound, "vector collection not found")
+	ErrVectorDimMismatch  = NewError(CodeInvalid, "vector dimension mismatch")
+
+	// Redundant verification errors
+	ErrRedundancyInvalid = errors.New("invalid redundant execution request")
+	ErrNotEnoughReplicas = errors.New("not enough nodes for redundant execution")
+	ErrNoConsensus       = errors.New("replicas did not reach consensus")
+
+	// Task journal errors
+	ErrTaskEventOutOfOrder = NewError(CodeConflict, "task event out of lifecycle order")
+	ErrTaskAlreadyPaid     = NewError(CodeConflict, "task already paid")
+	ErrTaskNotAssigned     = NewError(CodeNotFound, "task was not assigned to a node by this requester")
+
+	// Idle compute policy errors
+	ErrIdlePolicyBlocked = errors.New("idle compute policy does not allow work now")
+	ErrInvalidIdlePolicy = errors.New("invalid idle compute policy")
+	ErrTaskDrained       = errors.New("task drained to give the machine back to its user")
+
+	// Thermal telemetry errors
+	ErrThermalThrottled = errors.New("node is in sustained thermal throttle")
+
+	// Namespace errors
+	ErrNamespaceNotFound    = NewError(CodeNotFound, "namespace not found")
+	ErrNamespaceExists      = NewError(CodeConflict, "namespace already exists")
+	ErrInvalidNamespace     = NewError(CodeInvalid, "invalid namespace")
+	ErrNamespaceKeyNotFound = NewError(CodeNotFound, "namespace API key not found")
+	ErrNamespaceAuthOff     = NewError(CodeConflict, "namespaces need a node API key (tutu secrets set api_key)")
+	ErrModelNotAllowed      = NewError(CodeNotEligible, "model not allowed in this namespace")
+	ErrRateLimited          = NewError(CodeQuotaExceeded, "namespace rate limit exceeded")
+
+	// Billing errors
+	ErrBillingDisabled     = NewError(CodeUnavailable, "billing webhook secret is not configured")
+	ErrWebhookSignature    = NewError(CodeNotEligible, "invalid webhook signature")
+	ErrPaymentEventInvalid = NewError(CodeInvalid, "invalid payment event")
+	ErrInvoiceNotFound     = NewError(CodeNotFound, "invoice not found")
+	ErrInvoiceRefunded     = NewError(CodeConflict, "invoice already refunded")
+
+	// Onboarding errors
+	ErrOnboardingStep      = NewError(CodeConflict, "onboarding step not reached yet")
+	ErrOnboardingInput     = NewError(CodeInvalid, "invalid onboarding input")
+	ErrOnboardingDisabled  = NewError(CodeUnavailable, "onboarding step not available on this node")
+	ErrStarterPackNotFound = NewError(CodeNotFound, "starter pack not found")
 )
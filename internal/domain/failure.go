@@ -0,0 +1,106 @@
+package domain
+
+import "strings"
+
+// ─── Failure Taxonomy ───────────────────────────────────────────────────────
+// One vocabulary for what went wrong on a node. The self-healing mesh keys
+// its runbooks by these types, the anomaly detector maps its findings onto
+// them, and federated health patterns report their most common failure in
+// them, so an incident, an anomaly and an organization's health summary can
+// be compared directly.
+
+// FailureType classifies a detected problem.
+type FailureType string
+
+const (
+	FailureHighErrorRate    FailureType = "HIGH_ERROR_RATE"   // Sudden spike in task failures
+	FailureCPUOverload      FailureType = "CPU_OVERLOAD"      // Node CPU consistently >95%
+	FailureMemoryExhausted  FailureType = "MEMORY_EXHAUSTED"  // Node memory >95%
+	FailureDiskFull         FailureType = "DISK_FULL"         // Node disk >95%
+	FailureNetworkPartition FailureType = "NETWORK_PARTITION" // Node intermittently unreachable
+	FailureHeartbeatLost    FailureType = "HEARTBEAT_LOST"    // Node stopped sending heartbeats
+	FailureGPUError         FailureType = "GPU_ERROR"         // GPU not responding
+	FailureThermalThrottle  FailureType = "THERMAL_THROTTLE"  // Node hot for a sustained period
+	FailureModelCorrupt     FailureType = "MODEL_CORRUPT"     // Model integrity check failed
+	FailureDurationOutlier  FailureType = "DURATION_OUTLIER"  // Task far slower/faster than the node's profile
+	FailureLowCPU           FailureType = "LOW_CPU"           // Suspiciously little CPU for the work claimed
+	FailureEarningSpike     FailureType = "EARNING_SPIKE"     // Abnormal credit earning rate
+	FailurePatternMismatch  FailureType = "PATTERN_MISMATCH"  // Behavior unlike the node's history
+	FailureUnknown          FailureType = "UNKNOWN"           // Reported, but not in this taxonomy
+)
+
+// FailureCategory groups failure types by what they affect.
+type FailureCategory string
+
+const (
+	FailureCategoryExecution FailureCategory = "execution" // tasks failing
+	FailureCategoryResource  FailureCategory = "resource"  // CPU, memory, disk
+	FailureCategoryNetwork   FailureCategory = "network"   // reachability
+	FailureCategoryHardware  FailureCategory = "hardware"  // GPU, temperature
+	FailureCategoryIntegrity FailureCategory = "integrity" // corrupt artifacts
+	FailureCategoryBehavior  FailureCategory = "behavior"  // statistical anomalies
+	FailureCategoryUnknown   FailureCategory = "unknown"
+)
+
+var failureCategories = map[FailureType]FailureCategory{
+	FailureHighErrorRate:    FailureCategoryExecution,
+	FailureCPUOverload:      FailureCategoryResource,
+	FailureMemoryExhausted:  FailureCategoryResource,
+	FailureDiskFull:         FailureCategoryResource,
+	FailureNetworkPartition: FailureCategoryNetwork,
+	FailureHeartbeatLost:    FailureCategoryNetwork,
+	FailureGPUError:         FailureCategoryHardware,
+	FailureThermalThrottle:  FailureCategoryHardware,
+	FailureModelCorrupt:     FailureCategoryIntegrity,
+	FailureDurationOutlier:  FailureCategoryBehavior,
+	FailureLowCPU:           FailureCategoryBehavior,
+	FailureEarningSpike:     FailureCategoryBehavior,
+	FailurePatternMismatch:  FailureCategoryBehavior,
+}
+
+// failureAliases maps names used before the taxonomy was shared.
+var failureAliases = map[string]FailureType{
+	"HIGH_FAIL_RATE": FailureHighErrorRate, // anomaly detector
+}
+
+// AllFailureTypes returns every known failure type, excluding
+// FailureUnknown.
+func AllFailureTypes() []FailureType {
+	return []FailureType{
+		FailureHighErrorRate, FailureCPUOverload, FailureMemoryExhausted, FailureDiskFull,
+		FailureNetworkPartition, FailureHeartbeatLost, FailureGPUError, FailureThermalThrottle,
+		FailureModelCorrupt, FailureDurationOutlier, FailureLowCPU, FailureEarningSpike,
+		FailurePatternMismatch,
+	}
+}
+
+// ParseFailureType maps a reported name onto the taxonomy. Matching is
+// case-insensitive and accepts legacy aliases; anything else, including an
+// empty name, is FailureUnknown.
+func ParseFailureType(s string) FailureType {
+	name := strings.ToUpper(strings.TrimSpace(s))
+	if ft := FailureType(name); ft.IsValid() {
+		return ft
+	}
+	if ft, ok := failureAliases[name]; ok {
+		return ft
+	}
+	return FailureUnknown
+}
+
+// IsValid reports whether f is a known failure type.
+func (f FailureType) IsValid() bool {
+	_, ok := failureCategories[f]
+	return ok
+}
+
+// Category returns the group f belongs to.
+func (f FailureType) Category() FailureCategory {
+	if c, ok := failureCategories[f]; ok {
+		return c
+	}
+	return FailureCategoryUnknown
+}
+
+// String returns the failure type's wire name.
+func (f FailureType) String() string { return string(f) }
@@ -60,10 +60,11 @@ type MCPToolInputSchema struct {
 
 // MCPSchemaProperty defines a single property in a JSON Schema.
 type MCPSchemaProperty struct {
-	Type        string   `json:"type"`
-	Description string   `json:"description"`
-	Enum        []string `json:"enum,omitempty"`
-	Default     any      `json:"default,omitempty"`
+	Type        string             `json:"type"`
+	Description string             `json:"description"`
+	Enum        []string           `json:"enum,omitempty"`
+	Default     any                `json:"default,omitempty"`
+	Items       *MCPSchemaProperty `json:"items,omitempty"` // element schema for "array"
 }
 
 // ─── Resource Definitions ───────────────────────────────────────────────────
@@ -115,6 +116,18 @@ type FineTuneParams struct {
 	LoRA       bool   `json:"lora"`
 }
 
+// EarningsParams are the arguments for the tutu_earnings tool.
+type EarningsParams struct {
+	Limit int `json:"limit"` // recent ledger entries to include
+}
+
+// MarketplaceSearchParams are the arguments for the tutu_marketplace_search tool.
+type MarketplaceSearchParams struct {
+	Query    string `json:"query"`
+	Category string `json:"category"`
+	Limit    int    `json:"limit"`
+}
+
 // ─── Usage Metering ─────────────────────────────────────────────────────────
 
 // UsageRecord captures a single metered API call.
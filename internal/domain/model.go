@@ -23,6 +23,8 @@ type ModelInfo struct {
 	PulledAt     time.Time `json:"pulled_at"`
 	LastUsed     time.Time `json:"last_used"`
 	Pinned       bool      `json:"pinned"`
+
+	Metadata *ModelMetadata `json:"metadata,omitempty"` // nil when not recorded
 }
 
 // Manifest describes a model's layers in OCI-like content-addressed format.
@@ -49,6 +51,47 @@ func (m *Manifest) TotalSize() int64 {
 	return total
 }
 
+// ModelVerification is the result of checking a model's blobs against the
+// digests recorded in its manifest.
+type ModelVerification struct {
+	Model          string    `json:"model"`
+	ExpectedDigest string    `json:"expected_digest"`
+	ActualDigest   string    `json:"actual_digest,omitempty"` // empty if the blob could not be read
+	Verified       bool      `json:"verified"`
+	Error          string    `json:"error,omitempty"`
+	CheckedAt      time.Time `json:"checked_at"`
+	Failures       int       `json:"failures"` // cumulative failed checks (tracked by storage)
+}
+
+// ModelBenchmark is a model's measured generation speed on a node's
+// hardware.
+type ModelBenchmark struct {
+	Model        string    `json:"model"`
+	TokensPerSec float64   `json:"tokens_per_sec"` // decode throughput after the first token
+	TTFTMs       float64   `json:"ttft_ms"`        // time to first token
+	Tokens       int       `json:"tokens"`         // tokens generated per run
+	Runs         int       `json:"runs"`           // timed runs; figures are their medians
+	MeasuredAt   time.Time `json:"measured_at"`
+}
+
+// ModelMetadata is what a model can do, recorded when it is pulled so
+// clients and the scheduler need not guess.
+type ModelMetadata struct {
+	Model         string    `json:"model"`
+	ContextLength int       `json:"context_length,omitempty"` // trained context window in tokens
+	Quantization  string    `json:"quantization,omitempty"`   // e.g. "Q4_K_M"
+	Modalities    []string  `json:"modalities"`               // accepted inputs, e.g. "text", "image"
+	ToolCalling   bool      `json:"tool_calling"`             // trained for function calling
+	License       string    `json:"license,omitempty"`        // SPDX ID or license name
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// Model input modalities.
+const (
+	ModalityText  = "text" // every language model
+	ModalityImage = "image"
+)
+
 // ModelRef is a parsed model reference (registry/namespace/name:tag).
 type ModelRef struct {
 	Registry  string
@@ -0,0 +1,45 @@
+// Package domain — namespace types.
+// A namespace lets several teams share one node: each has its own API keys,
+// model allowlist and rate limit, and its requests, tasks and ledger entries
+// carry its name so usage can be accounted per team.
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// DefaultNamespace is the namespace of requests made with the node's own API
+// key, or without a key when auth is off.
+const DefaultNamespace = "default"
+
+// Namespace is one tenant of a shared node.
+type Namespace struct {
+	Name         string    `json:"name"`
+	Models       []string  `json:"models,omitempty"`         // model allowlist (empty = every model)
+	RateLimitRPM int       `json:"rate_limit_rpm,omitempty"` // requests per minute (0 = unlimited)
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// AllowsModel reports whether the namespace may use a model. Allowlist
+// entries without a tag match every tag of that model.
+func (n Namespace) AllowsModel(model string) bool {
+	if len(n.Models) == 0 {
+		return true
+	}
+	for _, m := range n.Models {
+		if m == model || (!strings.Contains(m, ":") && strings.Split(model, ":")[0] == m) {
+			return true
+		}
+	}
+	return false
+}
+
+// NamespaceKey is an API key issued to a namespace. Only the SHA-256 of the
+// key is kept; ID is its public prefix, used to list and revoke it.
+type NamespaceKey struct {
+	ID        string    `json:"id"`
+	Namespace string    `json:"namespace"`
+	Hash      string    `json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,17 @@
+// Package domain — runtime parameter types.
+// Tunable subsystem settings changed on a running node (admin API or an
+// executed governance proposal) are recorded as changes, persisted so they
+// survive a restart.
+package domain
+
+import "time"
+
+// ParamChange records one applied runtime parameter change.
+type ParamChange struct {
+	ID        int64     `json:"id"`
+	Key       string    `json:"key"`
+	OldValue  string    `json:"old_value"`
+	NewValue  string    `json:"new_value"`
+	Source    string    `json:"source"` // "admin", "governance:<proposal>", "revert:<id>", "rollback:<id>"
+	AppliedAt time.Time `json:"applied_at"`
+}
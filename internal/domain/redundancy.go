@@ -0,0 +1,53 @@
+// Package domain — redundant verification types.
+// A high-value request can run on M nodes at once; the output at least N of
+// them agree on is accepted, only agreeing nodes are paid, and dissenters
+// are reported to reputation and anomaly detection.
+package domain
+
+import "time"
+
+// CompareMode selects how replica outputs are compared.
+type CompareMode string
+
+const (
+	CompareExact     CompareMode = "exact"     // byte-identical after trimming whitespace
+	CompareEmbedding CompareMode = "embedding" // cosine similarity of output embeddings
+	CompareLoss      CompareMode = "loss"      // final training loss within a tolerance
+)
+
+// ReplicaOutcome classifies one replica after the vote.
+type ReplicaOutcome string
+
+const (
+	ReplicaAgreed    ReplicaOutcome = "AGREED"    // matched the consensus output
+	ReplicaDissented ReplicaOutcome = "DISSENTED" // returned a different output
+	ReplicaFailed    ReplicaOutcome = "FAILED"    // returned an error
+	ReplicaUndecided ReplicaOutcome = "UNDECIDED" // succeeded but no consensus was reached
+)
+
+// ReplicaResult is one node's execution of a redundant request.
+type ReplicaResult struct {
+	Node      string         `json:"node"`
+	Output    string         `json:"output,omitempty"`
+	Error     string         `json:"error,omitempty"`
+	LatencyMs int64          `json:"latency_ms"`
+	Outcome   ReplicaOutcome `json:"outcome"`
+	Agreement float64        `json:"agreement,omitempty"` // similarity to the consensus output, 0..1
+	Paid      int64          `json:"paid_credits,omitempty"`
+}
+
+// RedundancyVerdict is the outcome of an N-of-M redundant execution.
+type RedundancyVerdict struct {
+	TaskID    string          `json:"task_id"`
+	Model     string          `json:"model"`
+	TaskType  TaskType        `json:"task_type,omitempty"`
+	Mode      CompareMode     `json:"mode"`
+	Threshold float64         `json:"threshold"` // similarity at which outputs agree
+	Quorum    int             `json:"quorum"`    // N
+	Consensus bool            `json:"consensus"`
+	Output    string          `json:"output,omitempty"` // the agreed output
+	Agreeing  int             `json:"agreeing"`
+	Withheld  string          `json:"withheld,omitempty"` // why agreeing nodes were not paid
+	Replicas  []ReplicaResult `json:"replicas"`           // M
+	DecidedAt time.Time       `json:"decided_at"`
+}
@@ -39,6 +39,15 @@ type Task struct {
 	Credits     int64      `json:"credits,omitempty"`
 	ResultHash  string     `json:"result_hash,omitempty"`
 	Error       string     `json:"error,omitempty"`
+	Deadline    time.Time  `json:"deadline,omitempty"`    // must complete by (zero = no deadline)
+	SLA         SLATier    `json:"sla,omitempty"`         // SLA class the task is accounted under
+	Namespace   string     `json:"namespace,omitempty"`   // tenant that submitted it (empty = network work)
+	TraceParent string     `json:"traceparent,omitempty"` // W3C trace context carried between nodes
+
+	// Attestation is the executor's signed statement about the result,
+	// delivered with the completed task so the requester can verify it
+	// before paying (nil = unattested).
+	Attestation *Attestation `json:"attestation,omitempty"`
 }
 
 // IsTerminal returns true if the task has reached a final state.
@@ -46,6 +55,11 @@ func (t *Task) IsTerminal() bool {
 	return t.Status == TaskCompleted || t.Status == TaskFailed || t.Status == TaskCancelled
 }
 
+// HasDeadline returns true if the task must complete by a fixed time.
+func (t *Task) HasDeadline() bool {
+	return !t.Deadline.IsZero()
+}
+
 // Duration returns how long the task took to execute (0 if not started/completed).
 func (t *Task) Duration() time.Duration {
 	if t.StartedAt.IsZero() || t.CompletedAt.IsZero() {
@@ -53,3 +67,59 @@ func (t *Task) Duration() time.Duration {
 	}
 	return t.CompletedAt.Sub(t.StartedAt)
 }
+
+// ─── Task Journal ───────────────────────────────────────────────────────────
+
+// TaskEventType is a step in a task's journaled lifecycle.
+type TaskEventType string
+
+const (
+	TaskEventSubmitted TaskEventType = "SUBMITTED"
+	TaskEventScheduled TaskEventType = "SCHEDULED"
+	TaskEventAssigned  TaskEventType = "ASSIGNED"
+	TaskEventStarted   TaskEventType = "STARTED"
+	TaskEventCompleted TaskEventType = "COMPLETED"
+	TaskEventFailed    TaskEventType = "FAILED"
+	TaskEventVerified  TaskEventType = "VERIFIED"
+	TaskEventPaid      TaskEventType = "PAID"
+	TaskEventRecovered TaskEventType = "RECOVERED" // resumed after a restart
+)
+
+// Stage returns the event's position in the lifecycle (1 = SUBMITTED,
+// 7 = PAID). COMPLETED and FAILED share a stage; RECOVERED and unknown
+// types are 0.
+func (t TaskEventType) Stage() int {
+	switch t {
+	case TaskEventSubmitted:
+		return 1
+	case TaskEventScheduled:
+		return 2
+	case TaskEventAssigned:
+		return 3
+	case TaskEventStarted:
+		return 4
+	case TaskEventCompleted, TaskEventFailed:
+		return 5
+	case TaskEventVerified:
+		return 6
+	case TaskEventPaid:
+		return 7
+	}
+	return 0
+}
+
+// TaskEvent is one append-only entry in the task journal.
+type TaskEvent struct {
+	Seq       int64         `json:"seq"`
+	TaskID    string        `json:"task_id"`
+	Type      TaskEventType `json:"type"`
+	TaskType  TaskType      `json:"task_type,omitempty"` // SUBMITTED only
+	NodeID    string        `json:"node_id,omitempty"`   // assignee, verifier or payee
+	Credits   int64         `json:"credits,omitempty"`   // PAID only
+	Detail    string        `json:"detail,omitempty"`    // result hash, error, reason
+	Timestamp time.Time     `json:"timestamp"`
+
+	// TraceParent is the W3C trace context a task arrived with (SUBMITTED
+	// only). It reaches journal observers but is not persisted.
+	TraceParent string `json:"traceparent,omitempty"`
+}
@@ -0,0 +1,565 @@
+// Package agent implements the AGENT task type: multi-step plans whose
+// steps are tool calls, routed to this node or to peers.
+//
+// How a run executes:
+//  1. A plan (ordered steps, each naming a tool and its JSON args) is
+//     validated and checkpointed as PENDING
+//  2. Steps run in order; a step's args may embed an earlier step's output
+//     with {{steps.<id>}}
+//  3. Before each step the tool's cost is checked against the run's credit
+//     budget; the credits actually used are charged afterwards
+//  4. The run is checkpointed after every step, so a restarted node resumes
+//     from the first step that has not completed
+//  5. Each run and step is recorded as a span on the observability Tracer
+//
+// The Orchestrator satisfies the executor's Backend interface: an AGENT task
+// whose ID is a run ID executes that run inside an executor slot.
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
+	"github.com/tutu-network/tutu/internal/infra/observability"
+)
+
+// ─── Configuration ──────────────────────────────────────────────────────────
+
+// Config tunes the orchestrator.
+type Config struct {
+	SelfID      string        // this node's ID, recorded on locally run steps
+	MaxSteps    int           // maximum steps per plan (default: 32)
+	StepTimeout time.Duration // per-step deadline (default: 2m)
+
+	// Now is an injectable clock for testing.
+	Now func() time.Time
+}
+
+// DefaultConfig returns production defaults.
+func DefaultConfig() Config {
+	return Config{
+		SelfID:      "node-local",
+		MaxSteps:    32,
+		StepTimeout: 2 * time.Minute,
+		Now:         time.Now,
+	}
+}
+
+// ─── Tools ──────────────────────────────────────────────────────────────────
+
+// ToolFunc executes a tool call. credits is what the call actually cost;
+// 0 charges the tool's flat Cost.
+type ToolFunc func(ctx context.Context, args json.RawMessage) (output json.RawMessage, credits int64, err error)
+
+// Tool is a capability steps can call.
+type Tool struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Cost        int64    `json:"cost_credits"` // checked against the budget before the call
+	Run         ToolFunc `json:"-"`
+}
+
+// Router picks the node that should run a tool call ("" = this node).
+type Router func(tool string) string
+
+// RemoteInvoker runs a tool call on another node.
+type RemoteInvoker func(ctx context.Context, node, tool string, args json.RawMessage) (json.RawMessage, int64, error)
+
+// Store persists run checkpoints (*sqlite.DB satisfies it).
+type Store interface {
+	SaveAgentRun(r domain.AgentRun) error
+	ListAgentRuns() ([]domain.AgentRun, error)
+}
+
+// PlanStep is one step of a submitted plan.
+type PlanStep struct {
+	ID   string          `json:"id"`
+	Tool string          `json:"tool"`
+	Args json.RawMessage `json:"args,omitempty"`
+}
+
+// Stats summarizes orchestrator activity.
+type Stats struct {
+	Runs      int   `json:"runs"`
+	Running   int   `json:"running"`
+	Completed int   `json:"completed"`
+	Failed    int   `json:"failed"`
+	Cancelled int   `json:"cancelled"`
+	Steps     int64 `json:"steps_executed"`
+	Credits   int64 `json:"credits_spent"`
+}
+
+// stepRef matches {{steps.<id>}} placeholders in step args.
+var stepRef = regexp.MustCompile(`\{\{steps\.([A-Za-z0-9_-]+)\}\}`)
+
+// ─── Orchestrator ───────────────────────────────────────────────────────────
+
+// Orchestrator plans, executes and checkpoints agent runs.
+type Orchestrator struct {
+	mu      sync.Mutex
+	cfg     Config
+	store   Store
+	tracer  *observability.Tracer
+	tools   map[string]Tool
+	runs    map[string]*domain.AgentRun
+	cancels map[string]context.CancelFunc
+	route   Router
+	remote  RemoteInvoker
+	nextID  int64
+	steps   int64
+}
+
+// New creates an orchestrator. store and tracer may be nil.
+func New(cfg Config, store Store, tracer *observability.Tracer) *Orchestrator {
+	def := DefaultConfig()
+	if cfg.SelfID == "" {
+		cfg.SelfID = def.SelfID
+	}
+	if cfg.MaxSteps <= 0 {
+		cfg.MaxSteps = def.MaxSteps
+	}
+	if cfg.StepTimeout <= 0 {
+		cfg.StepTimeout = def.StepTimeout
+	}
+	if cfg.Now == nil {
+		cfg.Now = def.Now
+	}
+	return &Orchestrator{
+		cfg:     cfg,
+		store:   store,
+		tracer:  tracer,
+		tools:   make(map[string]Tool),
+		runs:    make(map[string]*domain.AgentRun),
+		cancels: make(map[string]context.CancelFunc),
+		nextID:  1,
+	}
+}
+
+// RegisterTool makes a tool available to plans.
+func (o *Orchestrator) RegisterTool(t Tool) error {
+	if t.Name == "" || t.Run == nil {
+		return fmt.Errorf("register tool %q: name and Run are required", t.Name)
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if _, ok := o.tools[t.Name]; ok {
+		return fmt.Errorf("register tool %q: already registered", t.Name)
+	}
+	o.tools[t.Name] = t
+	return nil
+}
+
+// Tools returns the registered tools sorted by name.
+func (o *Orchestrator) Tools() []Tool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	out := make([]Tool, 0, len(o.tools))
+	for _, t := range o.tools {
+		out = append(out, t)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// SetRouter routes tool calls across nodes. Calls routed to another node
+// go through remote; without a router every step runs locally.
+func (o *Orchestrator) SetRouter(route Router, remote RemoteInvoker) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.route = route
+	o.remote = remote
+}
+
+// Load restores checkpointed runs from the store and returns the IDs of
+// runs that had not finished, ready to be executed again.
+func (o *Orchestrator) Load() ([]string, error) {
+	if o.store == nil {
+		return nil, nil
+	}
+	runs, err := o.store.ListAgentRuns()
+	if err != nil {
+		return nil, fmt.Errorf("load agent runs: %w", err)
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	var resume []string
+	for i := range runs {
+		r := runs[i]
+		if !r.IsTerminal() {
+			r.Status = domain.AgentRunPending
+			resume = append(resume, r.ID)
+		}
+		o.runs[r.ID] = &r
+	}
+	o.nextID += int64(len(runs))
+	return resume, nil
+}
+
+// Create validates a plan and checkpoints it as a PENDING run.
+// budget is the credit limit for the whole run (0 = unlimited).
+func (o *Orchestrator) Create(name string, budget int64, plan []PlanStep) (domain.AgentRun, error) {
+	o.mu.Lock()
+	if err := o.validateLocked(budget, plan); err != nil {
+		o.mu.Unlock()
+		return domain.AgentRun{}, err
+	}
+
+	now := o.cfg.Now()
+	run := &domain.AgentRun{
+		ID:        fmt.Sprintf("agent-%d-%d", now.UnixMilli(), o.nextID),
+		Name:      name,
+		Status:    domain.AgentRunPending,
+		Budget:    budget,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	o.nextID++
+	run.TraceID = run.ID
+	for _, s := range plan {
+		run.Steps = append(run.Steps, domain.AgentStep{
+			ID: s.ID, Tool: s.Tool, Args: s.Args, Status: domain.AgentStepPending,
+		})
+	}
+	o.runs[run.ID] = run
+	snapshot := cloneRun(run)
+	o.mu.Unlock()
+
+	o.checkpoint(snapshot)
+	return snapshot, nil
+}
+
+func (o *Orchestrator) validateLocked(budget int64, plan []PlanStep) error {
+	if len(plan) == 0 {
+		return fmt.Errorf("plan has no steps: %w", domain.ErrAgentPlanInvalid)
+	}
+	if len(plan) > o.cfg.MaxSteps {
+		return fmt.Errorf("plan has %d steps, limit is %d: %w", len(plan), o.cfg.MaxSteps, domain.ErrAgentPlanInvalid)
+	}
+	if budget < 0 {
+		return fmt.Errorf("budget must not be negative: %w", domain.ErrAgentPlanInvalid)
+	}
+	seen := make(map[string]bool, len(plan))
+	for i, s := range plan {
+		if s.ID == "" || seen[s.ID] {
+			return fmt.Errorf("step %d: id must be unique and non-empty: %w", i, domain.ErrAgentPlanInvalid)
+		}
+		if _, ok := o.tools[s.Tool]; !ok {
+			return fmt.Errorf("step %s: %q: %w", s.ID, s.Tool, domain.ErrUnknownAgentTool)
+		}
+		if len(s.Args) > 0 && !json.Valid(s.Args) {
+			return fmt.Errorf("step %s: args are not valid JSON: %w", s.ID, domain.ErrAgentPlanInvalid)
+		}
+		for _, m := range stepRef.FindAllStringSubmatch(string(s.Args), -1) {
+			if !seen[m[1]] {
+				return fmt.Errorf("step %s: {{steps.%s}} must refer to an earlier step: %w", s.ID, m[1], domain.ErrAgentPlanInvalid)
+			}
+		}
+		seen[s.ID] = true
+	}
+	return nil
+}
+
+// Execute implements the executor Backend interface for AGENT tasks: the
+// task ID names the run to execute. The result is the final step's output.
+func (o *Orchestrator) Execute(ctx context.Context, task domain.Task) ([]byte, error) {
+	run, err := o.Run(ctx, task.ID)
+	if err != nil {
+		return nil, err
+	}
+	if run.Status != domain.AgentRunCompleted {
+		return nil, fmt.Errorf("agent run %s %s: %s", run.ID, run.Status, run.Error)
+	}
+	return run.Steps[len(run.Steps)-1].Output, nil
+}
+
+// Run executes a run from its first unfinished step until it completes,
+// fails or is cancelled, and returns its final state. Step failures are
+// reported on the run; the error is only for runs that cannot be started.
+func (o *Orchestrator) Run(ctx context.Context, id string) (domain.AgentRun, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	o.mu.Lock()
+	run, ok := o.runs[id]
+	if !ok {
+		o.mu.Unlock()
+		return domain.AgentRun{}, fmt.Errorf("%s: %w", id, domain.ErrAgentRunNotFound)
+	}
+	if run.IsTerminal() || run.Status == domain.AgentRunRunning {
+		o.mu.Unlock()
+		return domain.AgentRun{}, fmt.Errorf("%s is %s: %w", id, run.Status, domain.ErrAgentRunFinished)
+	}
+	run.Status = domain.AgentRunRunning
+	run.UpdatedAt = o.cfg.Now()
+	o.cancels[id] = cancel
+	snapshot := cloneRun(run)
+	o.mu.Unlock()
+	o.checkpoint(snapshot)
+
+	ctx = observability.WithTraceID(ctx, snapshot.TraceID)
+	var runSpan *observability.Span
+	if o.tracer != nil {
+		runSpan = o.tracer.StartSpan(ctx, "agent.run", map[string]string{"run_id": id, "name": snapshot.Name})
+		ctx = observability.WithSpanID(ctx, runSpan.SpanID)
+	}
+
+	var runErr error
+	for {
+		i, err := o.runStep(ctx, id)
+		if err != nil {
+			runErr = err
+			break
+		}
+		if i < 0 {
+			break
+		}
+	}
+
+	o.mu.Lock()
+	delete(o.cancels, id)
+	switch {
+	case run.Status != domain.AgentRunRunning:
+		// Cancelled while a step was in flight
+	case runErr != nil:
+		run.Status = domain.AgentRunFailed
+		run.Error = runErr.Error()
+	default:
+		run.Status = domain.AgentRunCompleted
+	}
+	run.UpdatedAt = o.cfg.Now()
+	final := cloneRun(run)
+	o.mu.Unlock()
+	o.checkpoint(final)
+
+	if o.tracer != nil {
+		if final.Status == domain.AgentRunCancelled {
+			runErr = context.Canceled
+		}
+		o.tracer.EndSpan(runSpan, runErr)
+	}
+	return final, nil
+}
+
+// runStep executes the next unfinished step and returns its index
+// (-1 when every step is done).
+func (o *Orchestrator) runStep(ctx context.Context, id string) (int, error) {
+	o.mu.Lock()
+	run := o.runs[id]
+	if run.Status != domain.AgentRunRunning {
+		o.mu.Unlock()
+		return -1, nil
+	}
+	i := run.NextStep()
+	if i < 0 {
+		o.mu.Unlock()
+		return -1, nil
+	}
+	step := run.Steps[i]
+	tool := o.tools[step.Tool]
+	if run.Budget > 0 && run.Spent+tool.Cost > run.Budget {
+		o.mu.Unlock()
+		return i, fmt.Errorf("step %s needs %d credits, %d of %d left: %w",
+			step.ID, tool.Cost, run.Budget-run.Spent, run.Budget, domain.ErrAgentBudgetExceeded)
+	}
+	args, err := expandArgs(step.Args, run.Steps[:i])
+	node := o.cfg.SelfID
+	if o.route != nil {
+		if n := o.route(step.Tool); n != "" {
+			node = n
+		}
+	}
+	remote := o.remote
+	o.mu.Unlock()
+	if err != nil {
+		return i, fmt.Errorf("step %s: %w", step.ID, err)
+	}
+
+	var span *observability.Span
+	if o.tracer != nil {
+		span = o.tracer.StartSpan(ctx, "agent.step", map[string]string{
+			"run_id": id, "step": step.ID, "tool": step.Tool, "node": node,
+		})
+	}
+
+	started := o.cfg.Now()
+	stepCtx, cancel := context.WithTimeout(ctx, o.cfg.StepTimeout)
+	var (
+		output  json.RawMessage
+		credits int64
+	)
+	switch {
+	case node == o.cfg.SelfID:
+		output, credits, err = tool.Run(stepCtx, args)
+	case remote != nil:
+		output, credits, err = remote(stepCtx, node, step.Tool, args)
+	default:
+		err = fmt.Errorf("no remote invoker for node %s", node)
+	}
+	cancel()
+	if err == nil && len(output) > 0 && !json.Valid(output) {
+		err = fmt.Errorf("tool %s returned invalid JSON", step.Tool)
+	}
+	if o.tracer != nil {
+		o.tracer.EndSpan(span, err)
+	}
+
+	o.mu.Lock()
+	s := &run.Steps[i]
+	s.Node = node
+	s.StartedAt = started
+	s.CompletedAt = o.cfg.Now()
+	if span != nil {
+		s.SpanID = span.SpanID
+	}
+	if err != nil {
+		s.Status = domain.AgentStepFailed
+		s.Error = err.Error()
+	} else {
+		if credits <= 0 {
+			credits = tool.Cost
+		}
+		s.Status = domain.AgentStepDone
+		s.Output = output
+		s.Credits = credits
+		s.Error = ""
+		run.Spent += credits
+		o.steps++
+		if run.Budget > 0 && run.Spent > run.Budget {
+			err = fmt.Errorf("step %s spent %d credits, run total %d of %d: %w",
+				step.ID, credits, run.Spent, run.Budget, domain.ErrAgentBudgetExceeded)
+		}
+	}
+	run.UpdatedAt = s.CompletedAt
+	snapshot := cloneRun(run)
+	o.mu.Unlock()
+	o.checkpoint(snapshot)
+
+	if err != nil {
+		return i, fmt.Errorf("step %s: %w", step.ID, err)
+	}
+	return i, nil
+}
+
+// Cancel stops a run. An in-flight step's context is cancelled.
+func (o *Orchestrator) Cancel(id string) error {
+	o.mu.Lock()
+	run, ok := o.runs[id]
+	if !ok {
+		o.mu.Unlock()
+		return fmt.Errorf("%s: %w", id, domain.ErrAgentRunNotFound)
+	}
+	if run.IsTerminal() {
+		o.mu.Unlock()
+		return fmt.Errorf("%s is %s: %w", id, run.Status, domain.ErrAgentRunFinished)
+	}
+	run.Status = domain.AgentRunCancelled
+	run.UpdatedAt = o.cfg.Now()
+	if cancel, ok := o.cancels[id]; ok {
+		cancel()
+	}
+	snapshot := cloneRun(run)
+	o.mu.Unlock()
+
+	o.checkpoint(snapshot)
+	return nil
+}
+
+// Get returns a run by ID.
+func (o *Orchestrator) Get(id string) (domain.AgentRun, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	run, ok := o.runs[id]
+	if !ok {
+		return domain.AgentRun{}, fmt.Errorf("%s: %w", id, domain.ErrAgentRunNotFound)
+	}
+	return cloneRun(run), nil
+}
+
+// List returns up to limit runs, newest first (limit <= 0 = all).
+func (o *Orchestrator) List(limit int) []domain.AgentRun {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	out := make([]domain.AgentRun, 0, len(o.runs))
+	for _, r := range o.runs {
+		out = append(out, cloneRun(r))
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.After(out[j].CreatedAt) })
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out
+}
+
+// Stats returns orchestrator statistics.
+func (o *Orchestrator) Stats() Stats {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	st := Stats{Runs: len(o.runs), Steps: o.steps}
+	for _, r := range o.runs {
+		st.Credits += r.Spent
+		switch r.Status {
+		case domain.AgentRunRunning:
+			st.Running++
+		case domain.AgentRunCompleted:
+			st.Completed++
+		case domain.AgentRunFailed:
+			st.Failed++
+		case domain.AgentRunCancelled:
+			st.Cancelled++
+		}
+	}
+	return st
+}
+
+// ─── Helpers ────────────────────────────────────────────────────────────────
+
+// checkpoint persists a run snapshot. Failures are logged, not fatal: the
+// run continues and the next checkpoint retries.
+func (o *Orchestrator) checkpoint(r domain.AgentRun) {
+	if o.store == nil {
+		return
+	}
+	if err := o.store.SaveAgentRun(r); err != nil {
+		log.Printf("[agent] checkpoint %s: %v", r.ID, err)
+	}
+}
+
+// expandArgs substitutes {{steps.<id>}} with earlier steps' outputs. The
+// placeholder sits inside a JSON string, so outputs are inserted escaped;
+// a JSON string output is inserted as its text.
+func expandArgs(args json.RawMessage, done []domain.AgentStep) (json.RawMessage, error) {
+	if !stepRef.Match(args) {
+		return args, nil
+	}
+	outputs := make(map[string]string, len(done))
+	for _, s := range done {
+		text := string(s.Output)
+		var str string
+		if json.Unmarshal(s.Output, &str) == nil {
+			text = str
+		}
+		quoted, _ := json.Marshal(text)
+		outputs[s.ID] = string(quoted[1 : len(quoted)-1])
+	}
+	out := stepRef.ReplaceAllStringFunc(string(args), func(m string) string {
+		return outputs[stepRef.FindStringSubmatch(m)[1]]
+	})
+	if !json.Valid([]byte(out)) {
+		return nil, fmt.Errorf("args invalid after substituting step outputs: %w", domain.ErrAgentPlanInvalid)
+	}
+	return json.RawMessage(out), nil
+}
+
+func cloneRun(r *domain.AgentRun) domain.AgentRun {
+	cp := *r
+	cp.Steps = append([]domain.AgentStep(nil), r.Steps...)
+	return cp
+}
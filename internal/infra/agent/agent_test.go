@@ -0,0 +1,246 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/tutu-network/tutu/internal/domain"
+	"github.com/tutu-network/tutu/internal/infra/observability"
+)
+
+// memStore is an in-memory Store.
+type memStore struct {
+	mu    sync.Mutex
+	runs  map[string]domain.AgentRun
+	order []string
+	saves int
+}
+
+func newMemStore() *memStore { return &memStore{runs: make(map[string]domain.AgentRun)} }
+
+func (m *memStore) SaveAgentRun(r domain.AgentRun) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.runs[r.ID]; !ok {
+		m.order = append(m.order, r.ID)
+	}
+	m.runs[r.ID] = r
+	m.saves++
+	return nil
+}
+
+func (m *memStore) ListAgentRuns() ([]domain.AgentRun, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make([]domain.AgentRun, 0, len(m.order))
+	for _, id := range m.order {
+		out = append(out, m.runs[id])
+	}
+	return out, nil
+}
+
+// echoTool returns its args unchanged.
+func echoTool(name string, cost int64) Tool {
+	return Tool{Name: name, Cost: cost, Run: func(_ context.Context, args json.RawMessage) (json.RawMessage, int64, error) {
+		return args, 0, nil
+	}}
+}
+
+func newTestOrchestrator(t *testing.T, store Store, tracer *observability.Tracer) *Orchestrator {
+	t.Helper()
+	o := New(Config{SelfID: "self"}, store, tracer)
+	if err := o.RegisterTool(echoTool("echo", 2)); err != nil {
+		t.Fatalf("RegisterTool: %v", err)
+	}
+	return o
+}
+
+func TestOrchestrator_Create_Validation(t *testing.T) {
+	o := newTestOrchestrator(t, nil, nil)
+	tests := []struct {
+		name   string
+		budget int64
+		plan   []PlanStep
+		want   error
+	}{
+		{"empty", 0, nil, domain.ErrAgentPlanInvalid},
+		{"unknown tool", 0, []PlanStep{{ID: "a", Tool: "nope"}}, domain.ErrUnknownAgentTool},
+		{"duplicate id", 0, []PlanStep{{ID: "a", Tool: "echo"}, {ID: "a", Tool: "echo"}}, domain.ErrAgentPlanInvalid},
+		{"bad json", 0, []PlanStep{{ID: "a", Tool: "echo", Args: json.RawMessage(`{`)}}, domain.ErrAgentPlanInvalid},
+		{"forward ref", 0, []PlanStep{{ID: "a", Tool: "echo", Args: json.RawMessage(`{"x":"{{steps.b}}"}`)}, {ID: "b", Tool: "echo"}}, domain.ErrAgentPlanInvalid},
+		{"negative budget", -1, []PlanStep{{ID: "a", Tool: "echo"}}, domain.ErrAgentPlanInvalid},
+		{"ok", 10, []PlanStep{{ID: "a", Tool: "echo"}}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := o.Create(tt.name, tt.budget, tt.plan)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Create() err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestOrchestrator_Run_ChainsOutputs(t *testing.T) {
+	store := newMemStore()
+	tracer := observability.NewTracer(observability.DefaultTracerConfig())
+	o := newTestOrchestrator(t, store, tracer)
+
+	run, err := o.Create("chain", 0, []PlanStep{
+		{ID: "first", Tool: "echo", Args: json.RawMessage(`"say \"hi\""`)},
+		{ID: "second", Tool: "echo", Args: json.RawMessage(`{"prev":"{{steps.first}}"}`)},
+	})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	out, err := o.Execute(context.Background(), domain.Task{ID: run.ID, Type: domain.TaskAgent})
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	var got struct{ Prev string }
+	if err := json.Unmarshal(out, &got); err != nil || got.Prev != `say "hi"` {
+		t.Errorf("output = %s, want prev=%q", out, `say "hi"`)
+	}
+
+	final, _ := o.Get(run.ID)
+	if final.Status != domain.AgentRunCompleted || final.Spent != 4 {
+		t.Errorf("status=%s spent=%d, want COMPLETED/4", final.Status, final.Spent)
+	}
+	for _, s := range final.Steps {
+		if s.Node != "self" || s.SpanID == "" {
+			t.Errorf("step %s: node=%q span=%q", s.ID, s.Node, s.SpanID)
+		}
+	}
+
+	// One run span plus one span per step, all on the run's trace
+	spans := tracer.Spans(10)
+	if len(spans) != 3 {
+		t.Fatalf("spans = %d, want 3", len(spans))
+	}
+	for _, sp := range spans {
+		if sp.TraceID != run.TraceID {
+			t.Errorf("span %s trace = %q, want %q", sp.Operation, sp.TraceID, run.TraceID)
+		}
+	}
+
+	// Created, started, two steps, finished
+	if store.saves != 5 {
+		t.Errorf("checkpoints = %d, want 5", store.saves)
+	}
+}
+
+func TestOrchestrator_Run_Budget(t *testing.T) {
+	o := newTestOrchestrator(t, nil, nil)
+	run, _ := o.Create("budget", 3, []PlanStep{{ID: "a", Tool: "echo"}, {ID: "b", Tool: "echo"}})
+
+	final, err := o.Run(context.Background(), run.ID)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if final.Status != domain.AgentRunFailed {
+		t.Fatalf("status = %s, want FAILED", final.Status)
+	}
+	if final.Steps[0].Status != domain.AgentStepDone || final.Steps[1].Status != domain.AgentStepPending {
+		t.Errorf("steps = %s/%s, want DONE/PENDING", final.Steps[0].Status, final.Steps[1].Status)
+	}
+	if final.Spent != 2 {
+		t.Errorf("spent = %d, want 2", final.Spent)
+	}
+	if _, err := o.Execute(context.Background(), domain.Task{ID: run.ID}); !errors.Is(err, domain.ErrAgentRunFinished) {
+		t.Errorf("re-run err = %v, want ErrAgentRunFinished", err)
+	}
+}
+
+func TestOrchestrator_Load_ResumesFromCheckpoint(t *testing.T) {
+	store := newMemStore()
+	o := newTestOrchestrator(t, store, nil)
+	fail := true
+	o.RegisterTool(Tool{Name: "flaky", Cost: 1, Run: func(_ context.Context, args json.RawMessage) (json.RawMessage, int64, error) {
+		if fail {
+			return nil, 0, errors.New("boom")
+		}
+		return json.RawMessage(`"ok"`), 0, nil
+	}})
+	run, _ := o.Create("resume", 0, []PlanStep{{ID: "a", Tool: "echo"}, {ID: "b", Tool: "flaky"}})
+
+	// Simulate a crash mid-run: the checkpoint shows RUNNING with step a done
+	o.Run(context.Background(), run.ID)
+	crashed, _ := o.Get(run.ID)
+	crashed.Status = domain.AgentRunRunning
+	crashed.Steps[1].Status = domain.AgentStepPending
+	store.SaveAgentRun(crashed)
+
+	o2 := newTestOrchestrator(t, store, nil)
+	o2.RegisterTool(Tool{Name: "flaky", Cost: 1, Run: func(_ context.Context, _ json.RawMessage) (json.RawMessage, int64, error) {
+		return json.RawMessage(`"ok"`), 0, nil
+	}})
+	resume, err := o2.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(resume) != 1 || resume[0] != run.ID {
+		t.Fatalf("resume = %v, want [%s]", resume, run.ID)
+	}
+	final, _ := o2.Run(context.Background(), run.ID)
+	if final.Status != domain.AgentRunCompleted {
+		t.Fatalf("status = %s, want COMPLETED (%s)", final.Status, final.Error)
+	}
+	if o2.Stats().Steps != 1 {
+		t.Errorf("steps executed after resume = %d, want 1", o2.Stats().Steps)
+	}
+}
+
+func TestOrchestrator_Router_Remote(t *testing.T) {
+	o := newTestOrchestrator(t, nil, nil)
+	var calledNode string
+	o.SetRouter(
+		func(tool string) string { return "peer-1" },
+		func(_ context.Context, node, tool string, args json.RawMessage) (json.RawMessage, int64, error) {
+			calledNode = node
+			return json.RawMessage(`1`), 7, nil
+		},
+	)
+	run, _ := o.Create("remote", 0, []PlanStep{{ID: "a", Tool: "echo"}})
+	final, _ := o.Run(context.Background(), run.ID)
+
+	if calledNode != "peer-1" || final.Steps[0].Node != "peer-1" {
+		t.Errorf("node = %q/%q, want peer-1", calledNode, final.Steps[0].Node)
+	}
+	if final.Spent != 7 {
+		t.Errorf("spent = %d, want remote-reported 7", final.Spent)
+	}
+}
+
+func TestOrchestrator_Cancel(t *testing.T) {
+	o := newTestOrchestrator(t, nil, nil)
+	started := make(chan struct{})
+	o.RegisterTool(Tool{Name: "block", Run: func(ctx context.Context, _ json.RawMessage) (json.RawMessage, int64, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, 0, ctx.Err()
+	}})
+	run, _ := o.Create("cancel", 0, []PlanStep{{ID: "a", Tool: "block"}, {ID: "b", Tool: "echo"}})
+
+	done := make(chan domain.AgentRun)
+	go func() {
+		r, _ := o.Run(context.Background(), run.ID)
+		done <- r
+	}()
+	<-started
+	if err := o.Cancel(run.ID); err != nil {
+		t.Fatalf("Cancel: %v", err)
+	}
+	final := <-done
+	if final.Status != domain.AgentRunCancelled {
+		t.Errorf("status = %s, want CANCELLED", final.Status)
+	}
+	if err := o.Cancel(run.ID); !errors.Is(err, domain.ErrAgentRunFinished) {
+		t.Errorf("second Cancel err = %v, want ErrAgentRunFinished", err)
+	}
+	if err := o.Cancel("missing"); !errors.Is(err, domain.ErrAgentRunNotFound) {
+		t.Errorf("Cancel(missing) err = %v, want ErrAgentRunNotFound", err)
+	}
+}
@@ -13,6 +13,8 @@ import (
 	"math"
 	"sync"
 	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
 )
 
 // ─── Constants ──────────────────────────────────────────────────────────────
@@ -72,6 +74,27 @@ func (a AnomalyType) String() string {
 	}
 }
 
+// FailureType maps the anomaly onto the shared failure taxonomy. AnomalyNone
+// has no failure type.
+func (a AnomalyType) FailureType() domain.FailureType {
+	switch a {
+	case AnomalyNone:
+		return ""
+	case AnomalyDurationOutlier:
+		return domain.FailureDurationOutlier
+	case AnomalyLowCPU:
+		return domain.FailureLowCPU
+	case AnomalyHighFailRate:
+		return domain.FailureHighErrorRate
+	case AnomalyEarningSpike:
+		return domain.FailureEarningSpike
+	case AnomalyPatternMismatch:
+		return domain.FailurePatternMismatch
+	default:
+		return domain.FailureUnknown
+	}
+}
+
 // Severity indicates how serious an anomaly is.
 type Severity int
 
@@ -108,12 +131,13 @@ type TaskEvent struct {
 
 // AnomalyResult is the outcome of analyzing a task event.
 type AnomalyResult struct {
-	IsAnomaly   bool        `json:"is_anomaly"`
-	Type        AnomalyType `json:"type"`
-	Severity    Severity    `json:"severity"`
-	Description string      `json:"description"`
-	NodeID      string      `json:"node_id"`
-	Timestamp   time.Time   `json:"timestamp"`
+	IsAnomaly   bool               `json:"is_anomaly"`
+	Type        AnomalyType        `json:"type"`
+	FailureType domain.FailureType `json:"failure_type,omitempty"` // Type in the shared taxonomy
+	Severity    Severity           `json:"severity"`
+	Description string             `json:"description"`
+	NodeID      string             `json:"node_id"`
+	Timestamp   time.Time          `json:"timestamp"`
 }
 
 // NodeProfile holds statistical data about a node's behavior.
@@ -297,6 +321,7 @@ func (d *Detector) Analyze(event TaskEvent) AnomalyResult {
 
 	// Track consecutive anomalies for escalation
 	if result.IsAnomaly {
+		result.FailureType = result.Type.FailureType()
 		profile.ConsecutiveAnomalies++
 		profile.TotalAnomalies++
 		profile.LastAnomaly = d.now()
@@ -3,6 +3,8 @@ package anomaly
 import (
 	"testing"
 	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
 )
 
 // ─── Helpers ────────────────────────────────────────────────────────────────
@@ -120,11 +122,29 @@ func TestAnalyze_LowCPU(t *testing.T) {
 	if result.Type != AnomalyLowCPU {
 		t.Errorf("type = %v, want AnomalyLowCPU", result.Type)
 	}
+	if result.FailureType != domain.FailureLowCPU {
+		t.Errorf("failure type = %s, want LOW_CPU", result.FailureType)
+	}
 	if result.Severity != SevCritical {
 		t.Errorf("severity = %v, want SevCritical", result.Severity)
 	}
 }
 
+func TestAnomalyType_FailureType(t *testing.T) {
+	if AnomalyNone.FailureType() != "" {
+		t.Errorf("AnomalyNone maps to %q", AnomalyNone.FailureType())
+	}
+	for _, a := range []AnomalyType{AnomalyDurationOutlier, AnomalyLowCPU, AnomalyHighFailRate, AnomalyEarningSpike, AnomalyPatternMismatch} {
+		if ft := a.FailureType(); !ft.IsValid() || ft.Category() == domain.FailureCategoryUnknown {
+			t.Errorf("%s maps to %q, not a taxonomy type", a, ft)
+		}
+	}
+	// The detector's old name resolves to the same type as self-healing's
+	if AnomalyHighFailRate.FailureType() != domain.ParseFailureType(AnomalyHighFailRate.String()) {
+		t.Error("HIGH_FAIL_RATE alias disagrees with the mapping")
+	}
+}
+
 func TestAnalyze_LowCPU_NonInference(t *testing.T) {
 	d := newTestDetector(t)
 
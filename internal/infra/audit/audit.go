@@ -0,0 +1,271 @@
+// Package audit implements a tamper-evident log of privileged operations.
+//
+// Every entry is chained to its predecessor:
+//
+//	hash(n) = SHA-256(seq ‖ timestamp ‖ category ‖ actor ‖ action ‖ target ‖ details ‖ hash(n-1))
+//
+// Modifying, deleting or reordering any entry invalidates every hash after
+// it, which Verify detects by re-reading the chain from the Store (SQLite in
+// production). Only the tail of the chain is kept in memory, so truncating
+// the stored log is detected too.
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
+)
+
+// GenesisHash is the PrevHash of the first entry in a chain.
+const GenesisHash = "0000000000000000000000000000000000000000000000000000000000000000"
+
+// Store persists audit entries. Implemented by *sqlite.DB.
+type Store interface {
+	InsertAuditEntry(e domain.AuditEntry) error
+	ListAuditEntries() ([]domain.AuditEntry, error)
+	LastAuditEntry() (*domain.AuditEntry, error) // nil when the log is empty
+}
+
+// Query filters audit entries. Zero-valued fields match everything.
+type Query struct {
+	Category domain.AuditCategory
+	Actor    string
+	Since    time.Time
+	Until    time.Time
+	Limit    int // 0 = no limit; most recent entries are kept
+}
+
+// matches reports whether e satisfies the query filters.
+func (q Query) matches(e domain.AuditEntry) bool {
+	if q.Category != "" && e.Category != q.Category {
+		return false
+	}
+	if q.Actor != "" && e.Actor != q.Actor {
+		return false
+	}
+	if !q.Since.IsZero() && e.Timestamp.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && e.Timestamp.After(q.Until) {
+		return false
+	}
+	return true
+}
+
+// VerifyResult reports the outcome of a chain integrity check.
+type VerifyResult struct {
+	Valid     bool   `json:"valid"`
+	Entries   int    `json:"entries"`
+	BrokenSeq int64  `json:"broken_seq,omitempty"` // first entry that fails verification
+	Reason    string `json:"reason,omitempty"`
+}
+
+// Log is an append-only hash-chained audit log. Thread-safe.
+type Log struct {
+	mu    sync.Mutex
+	store Store
+	tail  domain.AuditEntry // last entry written (zero = empty chain)
+	now   func() time.Time
+}
+
+// NewLog creates an audit log that writes every entry to store, resuming
+// the chain from its last entry. A nil store keeps entries in memory, for
+// tests and tools that do not persist the log.
+func NewLog(store Store) (*Log, error) {
+	if store == nil {
+		store = &memoryStore{}
+	}
+	l := &Log{store: store, now: time.Now}
+	last, err := store.LastAuditEntry()
+	if err != nil {
+		return nil, fmt.Errorf("load audit log: %w", err)
+	}
+	if last != nil {
+		l.tail = *last
+	}
+	return l, nil
+}
+
+// Record appends an entry to the chain and returns it.
+func (l *Log) Record(category domain.AuditCategory, actor, action, target, details string) (domain.AuditEntry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	prev := GenesisHash
+	seq := l.tail.Seq + 1
+	if l.tail.Seq > 0 {
+		prev = l.tail.Hash
+	}
+
+	e := domain.AuditEntry{
+		Seq:       seq,
+		Timestamp: l.now(),
+		Category:  category,
+		Actor:     actor,
+		Action:    action,
+		Target:    target,
+		Details:   details,
+		PrevHash:  prev,
+	}
+	e.Hash = ComputeHash(e)
+
+	if err := l.store.InsertAuditEntry(e); err != nil {
+		return domain.AuditEntry{}, fmt.Errorf("persist audit entry: %w", err)
+	}
+	l.tail = e
+	return e, nil
+}
+
+// Hook returns a callback that records entries under a fixed category and
+// actor. It matches the SetAuditHook signature used by governance,
+// federation and healing, and ignores persistence errors (audit must never
+// block the operation being audited).
+func (l *Log) Hook(category domain.AuditCategory, actor string) func(action, target, details string) {
+	return func(action, target, details string) {
+		_, _ = l.Record(category, actor, action, target, details)
+	}
+}
+
+// Query reads the stored entries matching q, in sequence order.
+func (l *Log) Query(q Query) ([]domain.AuditEntry, error) {
+	entries, err := l.store.ListAuditEntries()
+	if err != nil {
+		return nil, fmt.Errorf("read audit log: %w", err)
+	}
+	var out []domain.AuditEntry
+	for _, e := range entries {
+		if q.matches(e) {
+			out = append(out, e)
+		}
+	}
+	if q.Limit > 0 && len(out) > q.Limit {
+		out = out[len(out)-q.Limit:]
+	}
+	return out, nil
+}
+
+// Len returns the number of entries in the log.
+func (l *Log) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return int(l.tail.Seq)
+}
+
+// Export writes matching entries as JSON Lines (one entry per line).
+func (l *Log) Export(w io.Writer, q Query) error {
+	entries, err := l.Query(q)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	for _, e := range entries {
+		if err := enc.Encode(e); err != nil {
+			return fmt.Errorf("export audit entry %d: %w", e.Seq, err)
+		}
+	}
+	return nil
+}
+
+// Verify re-reads the chain from the store and checks its integrity up to
+// the last entry this log wrote. A stored chain that ends before that entry,
+// or whose entry there has a different hash, was truncated or rewritten.
+// Entries appended while the store is read are left for the next check.
+func (l *Log) Verify() (VerifyResult, error) {
+	l.mu.Lock()
+	tail := l.tail
+	l.mu.Unlock()
+
+	entries, err := l.store.ListAuditEntries()
+	if err != nil {
+		return VerifyResult{}, fmt.Errorf("read audit log: %w", err)
+	}
+	if n := sort.Search(len(entries), func(i int) bool { return entries[i].Seq > tail.Seq }); n < len(entries) {
+		entries = entries[:n]
+	}
+	res := VerifyChain(entries)
+	if !res.Valid || tail.Seq == 0 {
+		return res, nil
+	}
+	if n := len(entries); n == 0 || entries[n-1].Seq != tail.Seq || entries[n-1].Hash != tail.Hash {
+		return VerifyResult{Entries: len(entries), BrokenSeq: tail.Seq, Reason: "chain truncated"}, nil
+	}
+	return res, nil
+}
+
+// VerifyChain checks that entries form an unbroken hash chain starting at
+// GenesisHash. Use it to validate exported or persisted logs independently.
+func VerifyChain(entries []domain.AuditEntry) VerifyResult {
+	prev := GenesisHash
+	var prevSeq int64
+	for _, e := range entries {
+		switch {
+		case e.Seq != prevSeq+1:
+			return VerifyResult{Entries: len(entries), BrokenSeq: e.Seq, Reason: "sequence gap"}
+		case e.PrevHash != prev:
+			return VerifyResult{Entries: len(entries), BrokenSeq: e.Seq, Reason: "previous hash mismatch"}
+		case ComputeHash(e) != e.Hash:
+			return VerifyResult{Entries: len(entries), BrokenSeq: e.Seq, Reason: "entry hash mismatch"}
+		}
+		prev = e.Hash
+		prevSeq = e.Seq
+	}
+	return VerifyResult{Valid: true, Entries: len(entries)}
+}
+
+// ComputeHash returns the chained hash of an entry (ignores e.Hash).
+func ComputeHash(e domain.AuditEntry) string {
+	h := sha256.New()
+	// Length-prefix every field so ("ab","c") and ("a","bc") hash differently.
+	for _, field := range []string{
+		strconv.FormatInt(e.Seq, 10),
+		strconv.FormatInt(e.Timestamp.UnixNano(), 10),
+		string(e.Category),
+		e.Actor,
+		e.Action,
+		e.Target,
+		e.Details,
+		e.PrevHash,
+	} {
+		fmt.Fprintf(h, "%d:%s", len(field), field)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// ─── Memory Store ───────────────────────────────────────────────────────────
+
+// memoryStore holds the chain of a log opened without a Store.
+type memoryStore struct {
+	mu      sync.Mutex
+	entries []domain.AuditEntry
+}
+
+func (m *memoryStore) InsertAuditEntry(e domain.AuditEntry) error {
+	m.mu.Lock()
+	m.entries = append(m.entries, e)
+	m.mu.Unlock()
+	return nil
+}
+
+func (m *memoryStore) ListAuditEntries() ([]domain.AuditEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]domain.AuditEntry(nil), m.entries...), nil
+}
+
+func (m *memoryStore) LastAuditEntry() (*domain.AuditEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.entries) == 0 {
+		return nil, nil
+	}
+	e := m.entries[len(m.entries)-1]
+	return &e, nil
+}
@@ -0,0 +1,277 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
+)
+
+// memStore is an in-memory Store for tests.
+type memStore struct {
+	entries []domain.AuditEntry
+	failOn  error
+}
+
+func (m *memStore) InsertAuditEntry(e domain.AuditEntry) error {
+	if m.failOn != nil {
+		return m.failOn
+	}
+	m.entries = append(m.entries, e)
+	return nil
+}
+
+func (m *memStore) ListAuditEntries() ([]domain.AuditEntry, error) {
+	return append([]domain.AuditEntry(nil), m.entries...), nil
+}
+
+func (m *memStore) LastAuditEntry() (*domain.AuditEntry, error) {
+	if len(m.entries) == 0 {
+		return nil, nil
+	}
+	e := m.entries[len(m.entries)-1]
+	return &e, nil
+}
+
+// query runs l.Query, failing the test on a read error.
+func query(t *testing.T, l *Log, q Query) []domain.AuditEntry {
+	t.Helper()
+	entries, err := l.Query(q)
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	return entries
+}
+
+// verify runs l.Verify, failing the test on a read error.
+func verify(t *testing.T, l *Log) VerifyResult {
+	t.Helper()
+	res, err := l.Verify()
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	return res
+}
+
+func newTestLog(t *testing.T, store Store) *Log {
+	t.Helper()
+	l, err := NewLog(store)
+	if err != nil {
+		t.Fatalf("NewLog: %v", err)
+	}
+	base := time.Unix(1_700_000_000, 0)
+	var tick int64
+	l.now = func() time.Time {
+		tick++
+		return base.Add(time.Duration(tick) * time.Second)
+	}
+	return l
+}
+
+func seed(t *testing.T, l *Log) {
+	t.Helper()
+	records := []struct {
+		cat    domain.AuditCategory
+		actor  string
+		action string
+	}{
+		{domain.AuditAdminAPI, "10.0.0.1", "GET"},
+		{domain.AuditGovernance, "node-a", "proposal.execute"},
+		{domain.AuditFederation, "node-a", "federation.approve"},
+		{domain.AuditQuarantine, "node-b", "quarantine.start"},
+	}
+	for _, r := range records {
+		if _, err := l.Record(r.cat, r.actor, r.action, "target", ""); err != nil {
+			t.Fatalf("Record: %v", err)
+		}
+	}
+}
+
+func TestLog_Record_ChainsEntries(t *testing.T) {
+	l := newTestLog(t, nil)
+	seed(t, l)
+
+	entries := query(t, l, Query{})
+	if len(entries) != 4 {
+		t.Fatalf("entries = %d, want 4", len(entries))
+	}
+	if entries[0].PrevHash != GenesisHash {
+		t.Errorf("first PrevHash = %s, want genesis", entries[0].PrevHash)
+	}
+	for i := 1; i < len(entries); i++ {
+		if entries[i].PrevHash != entries[i-1].Hash {
+			t.Errorf("entry %d not chained to predecessor", i)
+		}
+		if entries[i].Seq != entries[i-1].Seq+1 {
+			t.Errorf("entry %d seq = %d, want %d", i, entries[i].Seq, entries[i-1].Seq+1)
+		}
+	}
+	if res := verify(t, l); !res.Valid || res.Entries != 4 {
+		t.Errorf("Verify = %+v, want valid with 4 entries", res)
+	}
+}
+
+func TestLog_Query(t *testing.T) {
+	l := newTestLog(t, nil)
+	seed(t, l)
+	all := query(t, l, Query{})
+
+	tests := []struct {
+		name string
+		q    Query
+		want int
+	}{
+		{"all", Query{}, 4},
+		{"category", Query{Category: domain.AuditFederation}, 1},
+		{"actor", Query{Actor: "node-a"}, 2},
+		{"since", Query{Since: all[2].Timestamp}, 2},
+		{"until", Query{Until: all[1].Timestamp}, 2},
+		{"limit keeps newest", Query{Limit: 1}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := query(t, l, tt.q); len(got) != tt.want {
+				t.Errorf("got %d entries, want %d", len(got), tt.want)
+			}
+		})
+	}
+
+	if got := query(t, l, Query{Limit: 1}); got[0].Seq != 4 {
+		t.Errorf("limit returned seq %d, want 4", got[0].Seq)
+	}
+}
+
+func TestLog_Export(t *testing.T) {
+	l := newTestLog(t, nil)
+	seed(t, l)
+
+	var buf bytes.Buffer
+	if err := l.Export(&buf, Query{}); err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+
+	var decoded []domain.AuditEntry
+	sc := bufio.NewScanner(&buf)
+	for sc.Scan() {
+		var e domain.AuditEntry
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			t.Fatalf("decode line: %v", err)
+		}
+		decoded = append(decoded, e)
+	}
+	if len(decoded) != 4 {
+		t.Fatalf("exported %d lines, want 4", len(decoded))
+	}
+	if res := VerifyChain(decoded); !res.Valid {
+		t.Errorf("exported chain invalid: %+v", res)
+	}
+}
+
+func TestVerifyChain_DetectsTampering(t *testing.T) {
+	l := newTestLog(t, nil)
+	seed(t, l)
+
+	tests := []struct {
+		name    string
+		mutate  func([]domain.AuditEntry) []domain.AuditEntry
+		wantSeq int64
+	}{
+		{"modified details", func(e []domain.AuditEntry) []domain.AuditEntry {
+			e[1].Details = "forged"
+			return e
+		}, 2},
+		{"deleted entry", func(e []domain.AuditEntry) []domain.AuditEntry {
+			return append(e[:1], e[2:]...)
+		}, 3},
+		{"reordered", func(e []domain.AuditEntry) []domain.AuditEntry {
+			e[1], e[2] = e[2], e[1]
+			return e
+		}, 3},
+		{"rehashed without relinking", func(e []domain.AuditEntry) []domain.AuditEntry {
+			e[1].Actor = "attacker"
+			e[1].Hash = ComputeHash(e[1])
+			return e
+		}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries := tt.mutate(query(t, l, Query{}))
+			res := VerifyChain(entries)
+			if res.Valid {
+				t.Fatal("tampered chain verified as valid")
+			}
+			if res.BrokenSeq != tt.wantSeq {
+				t.Errorf("BrokenSeq = %d, want %d (%s)", res.BrokenSeq, tt.wantSeq, res.Reason)
+			}
+		})
+	}
+}
+
+func TestLog_Verify_ReadsStore(t *testing.T) {
+	store := &memStore{}
+	l := newTestLog(t, store)
+	seed(t, l)
+
+	store.entries[1].Details = "forged"
+	if res := verify(t, l); res.Valid || res.BrokenSeq != 2 {
+		t.Errorf("Verify after editing the store = %+v, want broken at 2", res)
+	}
+	store.entries[1].Details = ""
+
+	store.entries = store.entries[:3]
+	if res := verify(t, l); res.Valid || res.BrokenSeq != 4 || res.Reason != "chain truncated" {
+		t.Errorf("Verify after truncating the store = %+v, want truncated at 4", res)
+	}
+	store.entries = nil
+	if res := verify(t, l); res.Valid {
+		t.Errorf("Verify after emptying the store = %+v, want invalid", res)
+	}
+}
+
+func TestNewLog_ResumesChainFromStore(t *testing.T) {
+	store := &memStore{}
+	l := newTestLog(t, store)
+	seed(t, l)
+
+	reopened := newTestLog(t, store)
+	if reopened.Len() != 4 {
+		t.Fatalf("Len = %d, want 4", reopened.Len())
+	}
+	e, err := reopened.Record(domain.AuditAdminAPI, "10.0.0.2", "GET", "/api/admin/audit", "")
+	if err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+	if e.Seq != 5 {
+		t.Errorf("Seq = %d, want 5", e.Seq)
+	}
+	if res := VerifyChain(store.entries); !res.Valid {
+		t.Errorf("persisted chain invalid: %+v", res)
+	}
+}
+
+func TestLog_Record_StoreError(t *testing.T) {
+	store := &memStore{failOn: errors.New("disk full")}
+	l := newTestLog(t, store)
+
+	if _, err := l.Record(domain.AuditAdminAPI, "x", "GET", "/", ""); err == nil {
+		t.Fatal("expected error from failing store")
+	}
+	if l.Len() != 0 {
+		t.Errorf("Len = %d, want 0 after failed write", l.Len())
+	}
+}
+
+func TestLog_Hook(t *testing.T) {
+	l := newTestLog(t, nil)
+	hook := l.Hook(domain.AuditGovernance, "node-a")
+	hook("proposal.execute", "prop-1", "category=PARAMETER")
+
+	got := query(t, l, Query{Category: domain.AuditGovernance})
+	if len(got) != 1 || got[0].Actor != "node-a" || got[0].Target != "prop-1" {
+		t.Errorf("hook entry = %+v", got)
+	}
+}
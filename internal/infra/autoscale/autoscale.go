@@ -30,6 +30,7 @@
 package autoscale
 
 import (
+	"sort"
 	"sync"
 	"time"
 )
@@ -128,6 +129,7 @@ type Decision struct {
 	Reason          string    // human-readable explanation
 	DecidedAt       time.Time // when the decision was made
 	Proactive       bool      // true if decided BEFORE the spike (vs reactive)
+	WarmBy          time.Time // PreWarm only: when the forecast spike arrives
 }
 
 // ─── Demand Sample ──────────────────────────────────────────────────────────
@@ -169,6 +171,10 @@ type Scaler struct {
 
 	// Observation count for confidence calculation.
 	observationCount int
+
+	// Timestamp of the newest sample applied; batches skip anything at or
+	// before it so a replayed range isn't learned twice.
+	latest time.Time
 }
 
 // NewScaler creates a new predictive auto-scaler.
@@ -251,7 +257,43 @@ func (s *Scaler) seasonBucket(t time.Time) int {
 func (s *Scaler) RecordDemand(sample Sample) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	s.recordLocked(sample)
+}
+
+// RecordDemandBatch applies many samples under one lock, for replaying
+// history or ingesting from a metrics pipeline. Samples are applied in
+// time order; of several with the same timestamp only the last is kept,
+// and samples no newer than the newest already recorded are skipped, so
+// overlapping batches are safe. It returns how many samples were applied.
+func (s *Scaler) RecordDemandBatch(samples []Sample) int {
+	sorted := make([]Sample, len(samples))
+	copy(sorted, samples)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Timestamp.Before(sorted[j].Timestamp)
+	})
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	applied := 0
+	for i, sample := range sorted {
+		if i+1 < len(sorted) && sorted[i+1].Timestamp.Equal(sample.Timestamp) {
+			continue // a later duplicate wins
+		}
+		if !s.latest.IsZero() && !sample.Timestamp.After(s.latest) {
+			continue
+		}
+		s.recordLocked(sample)
+		applied++
+	}
+	return applied
+}
 
+// recordLocked applies one sample. Must be called with s.mu held.
+func (s *Scaler) recordLocked(sample Sample) {
+	if sample.Timestamp.After(s.latest) {
+		s.latest = sample.Timestamp
+	}
 	bucket := s.seasonBucket(sample.Timestamp)
 
 	if !s.inited {
@@ -343,6 +385,7 @@ func (s *Scaler) Evaluate() Decision {
 		decision.Direction = PreWarm
 		decision.TargetCapacity = target
 		decision.Proactive = true
+		decision.WarmBy = now.Add(s.cfg.PreWarmLeadTime)
 		decision.Reason = "forecast shows upcoming spike — pre-warming nodes"
 		s.capacity = target
 		s.lastDecision = now
@@ -445,6 +488,26 @@ func (s *Scaler) Capacity() int {
 	return s.capacity
 }
 
+// Thresholds returns the current scale-up and scale-down thresholds.
+func (s *Scaler) Thresholds() (up, down float64) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.cfg.ScaleUpThreshold, s.cfg.ScaleDownThreshold
+}
+
+// SetThresholds changes the scaling thresholds at runtime. They must
+// satisfy 0 < down < up; otherwise the call is a no-op returning false.
+func (s *Scaler) SetThresholds(up, down float64) bool {
+	if down <= 0 || down >= up {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cfg.ScaleUpThreshold = up
+	s.cfg.ScaleDownThreshold = down
+	return true
+}
+
 // ─── Statistics & Gate Check ────────────────────────────────────────────────
 
 // ScalerStats exposes auto-scaler metrics.
@@ -596,4 +659,5 @@ func (s *Scaler) Reset() {
 	s.dFull = false
 	s.totalSpikes = 0
 	s.proactiveSpikes = 0
+	s.latest = time.Time{}
 }
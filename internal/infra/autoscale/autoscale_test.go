@@ -49,6 +49,24 @@ func TestNewScaler_InvalidConfig(t *testing.T) {
 	}
 }
 
+func TestSetThresholds(t *testing.T) {
+	s := NewScaler(DefaultConfig())
+	if !s.SetThresholds(0.9, 0.2) {
+		t.Fatal("SetThresholds(0.9, 0.2) rejected")
+	}
+	if up, down := s.Thresholds(); up != 0.9 || down != 0.2 {
+		t.Errorf("Thresholds() = %v, %v, want 0.9, 0.2", up, down)
+	}
+	for _, bad := range [][2]float64{{0.5, 0.5}, {0.3, 0.6}, {0.8, 0}} {
+		if s.SetThresholds(bad[0], bad[1]) {
+			t.Errorf("SetThresholds(%v, %v) accepted", bad[0], bad[1])
+		}
+	}
+	if up, down := s.Thresholds(); up != 0.9 || down != 0.2 {
+		t.Errorf("rejected call changed thresholds to %v, %v", up, down)
+	}
+}
+
 func TestRecordDemand_InitializesSmoothed(t *testing.T) {
 	s := NewScaler(DefaultConfig())
 
@@ -82,6 +100,37 @@ func TestRecordDemand_ExponentialSmoothing(t *testing.T) {
 	}
 }
 
+func TestRecordDemandBatch_MatchesSequential(t *testing.T) {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	var samples []Sample
+	for i := 0; i < 48; i++ {
+		samples = append(samples, Sample{Demand: float64(10 + i%24), Timestamp: base.Add(time.Duration(i) * time.Hour)})
+	}
+
+	seq := NewScaler(DefaultConfig())
+	for _, sm := range samples {
+		seq.RecordDemand(sm)
+	}
+
+	// Shuffled, with a duplicate timestamp whose later value wins
+	batch := NewScaler(DefaultConfig())
+	shuffled := append([]Sample{{Demand: 999, Timestamp: samples[5].Timestamp}}, samples[24:]...)
+	shuffled = append(shuffled, samples[:24]...)
+	if n := batch.RecordDemandBatch(shuffled); n != len(samples) {
+		t.Fatalf("applied %d samples, want %d", n, len(samples))
+	}
+	at := base.Add(72 * time.Hour)
+	if got, want := batch.Forecast(at), seq.Forecast(at); math.Abs(got-want) > 1e-9 {
+		t.Errorf("batch forecast = %f, sequential = %f", got, want)
+	}
+
+	// Replaying an overlapping range applies only the new samples
+	more := append(samples[40:], Sample{Demand: 30, Timestamp: base.Add(48 * time.Hour)})
+	if n := batch.RecordDemandBatch(more); n != 1 {
+		t.Errorf("overlapping batch applied %d samples, want 1", n)
+	}
+}
+
 func TestSeasonBucket(t *testing.T) {
 	cfg := DefaultConfig() // period=24
 	s := NewScaler(cfg)
@@ -127,6 +176,9 @@ func TestEvaluate_ScaleUp(t *testing.T) {
 	if d.Direction != ScaleUp && d.Direction != PreWarm {
 		t.Errorf("expected ScaleUp or PreWarm, got %s", d.Direction)
 	}
+	if (d.Direction == PreWarm) == d.WarmBy.IsZero() {
+		t.Errorf("%s decision has WarmBy %v", d.Direction, d.WarmBy)
+	}
 	if d.TargetCapacity <= 5 {
 		t.Errorf("target capacity should exceed 5, got %d", d.TargetCapacity)
 	}
@@ -0,0 +1,122 @@
+package autoscale
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// ─── Prometheus Ingestion ───────────────────────────────────────────────────
+// Backfills demand from a Prometheus range query (GET /api/v1/query_range),
+// so a restarted node resumes with a learned seasonal profile instead of a
+// flat one. Every series the query returns is summed per timestamp; NaN and
+// Inf points are dropped.
+
+// DefaultPromQuery is the demand expression used when none is configured:
+// tasks completed per minute across the network.
+const DefaultPromQuery = `sum(rate(tutu_tasks_completed_total[5m])) * 60`
+
+// PromRange is a Prometheus range query.
+type PromRange struct {
+	URL   string        // Prometheus base URL, e.g. http://prometheus:9090
+	Query string        // PromQL expression yielding demand (default DefaultPromQuery)
+	Start time.Time     // first point
+	End   time.Time     // last point
+	Step  time.Duration // resolution (default 5m)
+}
+
+// promResponse is the subset of the query_range response we read.
+type promResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error"`
+	Data   struct {
+		ResultType string `json:"resultType"`
+		Result     []struct {
+			Values [][2]any `json:"values"` // [unix seconds, "value"]
+		} `json:"result"`
+	} `json:"data"`
+}
+
+// FetchPrometheus runs a range query and returns its points as demand
+// samples in time order.
+func FetchPrometheus(ctx context.Context, client *http.Client, q PromRange) ([]Sample, error) {
+	if q.URL == "" {
+		return nil, fmt.Errorf("autoscale: prometheus URL is required")
+	}
+	if q.Query == "" {
+		q.Query = DefaultPromQuery
+	}
+	if q.Step <= 0 {
+		q.Step = 5 * time.Minute
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	params := url.Values{}
+	params.Set("query", q.Query)
+	params.Set("start", strconv.FormatInt(q.Start.Unix(), 10))
+	params.Set("end", strconv.FormatInt(q.End.Unix(), 10))
+	params.Set("step", strconv.FormatFloat(q.Step.Seconds(), 'f', -1, 64))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, q.URL+"/api/v1/query_range?"+params.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("autoscale: prometheus request: %w", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("autoscale: prometheus query: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var body promResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("autoscale: decode prometheus response (HTTP %d): %w", resp.StatusCode, err)
+	}
+	if body.Status != "success" {
+		return nil, fmt.Errorf("autoscale: prometheus query failed (HTTP %d): %s", resp.StatusCode, body.Error)
+	}
+	if body.Data.ResultType != "matrix" {
+		return nil, fmt.Errorf("autoscale: prometheus returned %q, want a matrix", body.Data.ResultType)
+	}
+
+	sums := make(map[int64]float64)
+	for _, series := range body.Data.Result {
+		for _, point := range series.Values {
+			ts, ok := point[0].(float64)
+			str, ok2 := point[1].(string)
+			if !ok || !ok2 {
+				return nil, fmt.Errorf("autoscale: malformed prometheus point %v", point)
+			}
+			v, err := strconv.ParseFloat(str, 64)
+			if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+				continue
+			}
+			sums[int64(ts)] += v
+		}
+	}
+
+	samples := make([]Sample, 0, len(sums))
+	for ts, v := range sums {
+		samples = append(samples, Sample{Demand: v, Timestamp: time.Unix(ts, 0)})
+	}
+	sort.Slice(samples, func(i, j int) bool {
+		return samples[i].Timestamp.Before(samples[j].Timestamp)
+	})
+	return samples, nil
+}
+
+// IngestPrometheus fetches a range query and records it with
+// RecordDemandBatch. It returns how many samples were applied.
+func (s *Scaler) IngestPrometheus(ctx context.Context, client *http.Client, q PromRange) (int, error) {
+	samples, err := FetchPrometheus(ctx, client, q)
+	if err != nil {
+		return 0, err
+	}
+	return s.RecordDemandBatch(samples), nil
+}
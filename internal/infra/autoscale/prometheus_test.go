@@ -0,0 +1,58 @@
+package autoscale
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestIngestPrometheus(t *testing.T) {
+	var query string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/query_range" {
+			http.NotFound(w, r)
+			return
+		}
+		query = r.URL.Query().Get("query")
+		w.Write([]byte(`{"status":"success","data":{"resultType":"matrix","result":[
+			{"metric":{"type":"INFERENCE"},"values":[[1735689600,"10"],[1735693200,"12"],[1735696800,"NaN"]]},
+			{"metric":{"type":"EMBEDDING"},"values":[[1735689600,"5"],[1735693200,"3"]]}
+		]}}`))
+	}))
+	defer srv.Close()
+
+	start := time.Unix(1735689600, 0)
+	samples, err := FetchPrometheus(context.Background(), srv.Client(), PromRange{URL: srv.URL, Start: start, End: start.Add(2 * time.Hour)})
+	if err != nil {
+		t.Fatalf("FetchPrometheus: %v", err)
+	}
+	if query != DefaultPromQuery {
+		t.Errorf("query = %q, want the default", query)
+	}
+	// Series are summed per timestamp; the NaN point is dropped
+	if len(samples) != 2 || samples[0].Demand != 15 || samples[1].Demand != 15 || !samples[0].Timestamp.Equal(start) {
+		t.Fatalf("samples = %+v", samples)
+	}
+
+	s := NewScaler(DefaultConfig())
+	if n, err := s.IngestPrometheus(context.Background(), srv.Client(), PromRange{URL: srv.URL, Start: start, End: start.Add(2 * time.Hour)}); err != nil || n != 2 {
+		t.Errorf("IngestPrometheus = %d, %v", n, err)
+	}
+}
+
+func TestFetchPrometheus_Error(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"status":"error","errorType":"bad_data","error":"parse error"}`))
+	}))
+	defer srv.Close()
+
+	if _, err := FetchPrometheus(context.Background(), srv.Client(), PromRange{URL: srv.URL, Query: "sum("}); err == nil {
+		t.Error("expected an error for a failed query")
+	}
+	if _, err := FetchPrometheus(context.Background(), nil, PromRange{}); err == nil {
+		t.Error("expected an error without a URL")
+	}
+}
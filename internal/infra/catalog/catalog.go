@@ -18,6 +18,9 @@ type ModelEntry struct {
 	Tags         []string // Searchable tags: ["llama3", "llama3:latest", "llama3:8b"]
 	ContextSize  int      // Default context window
 	ChatTemplate string   // Chat template style: "llama3", "chatml", "phi3"
+	Modalities   []string // Accepted inputs (nil = text only)
+	ToolCalling  bool     // Trained for function calling
+	License      string   // SPDX ID or license name
 }
 
 // Catalog is the built-in list of downloadable models.
@@ -37,6 +40,7 @@ var Catalog = []ModelEntry{
 		Tags:         []string{"tinyllama", "tinyllama:latest", "tinyllama:1.1b"},
 		ContextSize:  2048,
 		ChatTemplate: "chatml",
+		License:      "apache-2.0",
 	},
 	{
 		Name:         "phi3",
@@ -51,6 +55,7 @@ var Catalog = []ModelEntry{
 		Tags:         []string{"phi3", "phi3:latest", "phi3:mini", "phi3:3.8b"},
 		ContextSize:  4096,
 		ChatTemplate: "phi3",
+		License:      "mit",
 	},
 	{
 		Name:         "qwen2.5",
@@ -65,6 +70,8 @@ var Catalog = []ModelEntry{
 		Tags:         []string{"qwen2.5", "qwen2.5:latest", "qwen2.5:1.5b"},
 		ContextSize:  4096,
 		ChatTemplate: "chatml",
+		ToolCalling:  true,
+		License:      "apache-2.0",
 	},
 	{
 		Name:         "llama3",
@@ -79,6 +86,8 @@ var Catalog = []ModelEntry{
 		Tags:         []string{"llama3", "llama3:latest", "llama3:1b", "llama3.2", "llama3.2:1b"},
 		ContextSize:  4096,
 		ChatTemplate: "llama3",
+		ToolCalling:  true,
+		License:      "llama3.2",
 	},
 	{
 		Name:         "llama3:8b",
@@ -93,6 +102,8 @@ var Catalog = []ModelEntry{
 		Tags:         []string{"llama3:8b", "llama3.1:8b"},
 		ContextSize:  8192,
 		ChatTemplate: "llama3",
+		ToolCalling:  true,
+		License:      "llama3.1",
 	},
 	{
 		Name:         "gemma2",
@@ -107,6 +118,7 @@ var Catalog = []ModelEntry{
 		Tags:         []string{"gemma2", "gemma2:latest", "gemma2:2b"},
 		ContextSize:  8192,
 		ChatTemplate: "gemma",
+		License:      "gemma",
 	},
 	{
 		Name:         "smollm2",
@@ -121,6 +133,7 @@ var Catalog = []ModelEntry{
 		Tags:         []string{"smollm2", "smollm2:latest", "smollm2:360m"},
 		ContextSize:  2048,
 		ChatTemplate: "chatml",
+		License:      "apache-2.0",
 	},
 	{
 		Name:         "mistral",
@@ -135,6 +148,8 @@ var Catalog = []ModelEntry{
 		Tags:         []string{"mistral", "mistral:latest", "mistral:7b"},
 		ContextSize:  8192,
 		ChatTemplate: "mistral",
+		ToolCalling:  true,
+		License:      "apache-2.0",
 	},
 }
 
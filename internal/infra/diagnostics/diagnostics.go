@@ -0,0 +1,239 @@
+// Package diagnostics assembles support bundles: a single tar.gz holding a
+// node's recent logs, trace spans, subsystem statistics, database integrity
+// results, gossip membership and (redacted) configuration.
+//
+// Sections are registered as collectors and run when the bundle is written.
+// A failing collector never aborts the bundle — its error is recorded in
+// manifest.json so support can see what is missing and why. Every section
+// passes through the Redact function before it is written.
+package diagnostics
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ─── Configuration ──────────────────────────────────────────────────────────
+
+// Config tunes bundle generation.
+type Config struct {
+	// Redact scrubs secrets from every section (nil = no redaction).
+	Redact func(string) string
+
+	// Now is an injectable clock for testing.
+	Now func() time.Time
+}
+
+// ─── Bundle ─────────────────────────────────────────────────────────────────
+
+// Collector produces one section. Strings and byte slices are written as
+// is; anything else is encoded as indented JSON.
+type Collector func() (any, error)
+
+// SectionResult describes one section in the manifest.
+type SectionResult struct {
+	File  string `json:"file"`
+	Bytes int    `json:"bytes"`
+	Error string `json:"error,omitempty"`
+}
+
+// Manifest is written as manifest.json at the root of every bundle.
+type Manifest struct {
+	CreatedAt time.Time       `json:"created_at"`
+	Sections  []SectionResult `json:"sections"`
+}
+
+type section struct {
+	file    string
+	collect Collector
+}
+
+// Bundle is an ordered set of sections.
+type Bundle struct {
+	cfg      Config
+	sections []section
+}
+
+// NewBundle creates an empty bundle.
+func NewBundle(cfg Config) *Bundle {
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+	if cfg.Redact == nil {
+		cfg.Redact = func(s string) string { return s }
+	}
+	return &Bundle{cfg: cfg}
+}
+
+// Add registers a section written to file (e.g. "stats.json").
+func (b *Bundle) Add(file string, collect Collector) {
+	b.sections = append(b.sections, section{file: file, collect: collect})
+}
+
+// Write runs every collector and streams the bundle to w as tar.gz.
+// Only I/O errors on w are returned; collector errors land in the manifest.
+func (b *Bundle) Write(w io.Writer) (Manifest, error) {
+	now := b.cfg.Now()
+	manifest := Manifest{CreatedAt: now.UTC()}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	for _, s := range b.sections {
+		res := SectionResult{File: s.file}
+		data, err := render(s.collect)
+		if err != nil {
+			res.Error = b.cfg.Redact(err.Error())
+		}
+		if data != nil {
+			data = []byte(b.cfg.Redact(string(data)))
+			res.Bytes = len(data)
+			if err := writeFile(tw, s.file, data, now); err != nil {
+				return manifest, err
+			}
+		}
+		manifest.Sections = append(manifest.Sections, res)
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return manifest, err
+	}
+	if err := writeFile(tw, "manifest.json", data, now); err != nil {
+		return manifest, err
+	}
+	if err := tw.Close(); err != nil {
+		return manifest, err
+	}
+	return manifest, gz.Close()
+}
+
+// render runs a collector, recovering from panics so one broken subsystem
+// cannot take the whole bundle down.
+func render(collect Collector) (data []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			data, err = nil, fmt.Errorf("collector panicked: %v", r)
+		}
+	}()
+	v, err := collect()
+	if err != nil {
+		return nil, err
+	}
+	switch v := v.(type) {
+	case nil:
+		return nil, nil
+	case []byte:
+		return v, nil
+	case string:
+		return []byte(v), nil
+	default:
+		return json.MarshalIndent(v, "", "  ")
+	}
+}
+
+func writeFile(tw *tar.Writer, name string, data []byte, mod time.Time) error {
+	hdr := &tar.Header{
+		Name:    name,
+		Mode:    0600,
+		Size:    int64(len(data)),
+		ModTime: mod,
+	}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return fmt.Errorf("write %s: %w", name, err)
+	}
+	if _, err := tw.Write(data); err != nil {
+		return fmt.Errorf("write %s: %w", name, err)
+	}
+	return nil
+}
+
+// ─── Log Capture ────────────────────────────────────────────────────────────
+
+// LogRing is an io.Writer that keeps the last N log lines in memory.
+// Install it alongside stderr (io.MultiWriter) so a running daemon can
+// include recent logs in a bundle without a log file.
+type LogRing struct {
+	mu      sync.Mutex
+	lines   []string
+	next    int
+	full    bool
+	partial string
+}
+
+// NewLogRing keeps up to maxLines lines (default 1000).
+func NewLogRing(maxLines int) *LogRing {
+	if maxLines <= 0 {
+		maxLines = 1000
+	}
+	return &LogRing{lines: make([]string, maxLines)}
+}
+
+// Write implements io.Writer. Incomplete trailing lines are held until
+// their newline arrives.
+func (r *LogRing) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	text := r.partial + string(p)
+	parts := strings.Split(text, "\n")
+	r.partial = parts[len(parts)-1]
+	for _, line := range parts[:len(parts)-1] {
+		r.lines[r.next] = line
+		r.next = (r.next + 1) % len(r.lines)
+		if r.next == 0 {
+			r.full = true
+		}
+	}
+	return len(p), nil
+}
+
+// Lines returns the retained lines, oldest first.
+func (r *LogRing) Lines() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.full {
+		return append([]string(nil), r.lines[:r.next]...)
+	}
+	out := make([]string, 0, len(r.lines))
+	out = append(out, r.lines[r.next:]...)
+	return append(out, r.lines[:r.next]...)
+}
+
+// TailFile returns up to the last maxBytes of a file, starting at a line
+// boundary.
+func TailFile(path string, maxBytes int64) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	offset := info.Size() - maxBytes
+	if offset <= 0 {
+		return io.ReadAll(f)
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	br := bufio.NewReader(f)
+	if _, err := br.ReadString('\n'); err != nil && err != io.EOF {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	_, err = buf.ReadFrom(br)
+	return buf.Bytes(), err
+}
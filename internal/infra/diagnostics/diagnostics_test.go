@@ -0,0 +1,134 @@
+package diagnostics
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// readBundle unpacks a tar.gz into name → contents.
+func readBundle(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("gzip: %v", err)
+	}
+	tr := tar.NewReader(gz)
+	files := make(map[string]string)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("tar: %v", err)
+		}
+		b, _ := io.ReadAll(tr)
+		files[hdr.Name] = string(b)
+	}
+	return files
+}
+
+// ─── Bundle ─────────────────────────────────────────────────────────────────
+
+func TestBundle_Write(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	b := NewBundle(Config{
+		Redact: func(s string) string { return strings.ReplaceAll(s, "hunter2", "[REDACTED]") },
+		Now:    func() time.Time { return now },
+	})
+	b.Add("logs.txt", func() (any, error) { return "login password=hunter2\n", nil })
+	b.Add("stats.json", func() (any, error) { return map[string]int{"slots": 4}, nil })
+	b.Add("broken.json", func() (any, error) { return nil, errors.New("db hunter2 locked") })
+	b.Add("panics.json", func() (any, error) { panic("boom") })
+	b.Add("empty.json", func() (any, error) { return nil, nil })
+
+	var buf bytes.Buffer
+	manifest, err := b.Write(&buf)
+	if err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	files := readBundle(t, buf.Bytes())
+
+	if got := files["logs.txt"]; got != "login password=[REDACTED]\n" {
+		t.Errorf("logs.txt = %q", got)
+	}
+	if !strings.Contains(files["stats.json"], `"slots": 4`) {
+		t.Errorf("stats.json = %q", files["stats.json"])
+	}
+	for _, name := range []string{"broken.json", "panics.json", "empty.json"} {
+		if _, ok := files[name]; ok {
+			t.Errorf("%s should not be written", name)
+		}
+	}
+
+	var m Manifest
+	if err := json.Unmarshal([]byte(files["manifest.json"]), &m); err != nil {
+		t.Fatalf("manifest.json: %v", err)
+	}
+	if !m.CreatedAt.Equal(now) || len(m.Sections) != 5 {
+		t.Fatalf("manifest = %+v", m)
+	}
+	if m.Sections[2].Error != "db [REDACTED] locked" {
+		t.Errorf("broken error = %q, want redacted", m.Sections[2].Error)
+	}
+	if !strings.Contains(m.Sections[3].Error, "panicked") {
+		t.Errorf("panic error = %q", m.Sections[3].Error)
+	}
+	if manifest.Sections[0].Bytes != len(files["logs.txt"]) {
+		t.Errorf("logs bytes = %d, want %d", manifest.Sections[0].Bytes, len(files["logs.txt"]))
+	}
+}
+
+// ─── Log Capture ────────────────────────────────────────────────────────────
+
+func TestLogRing_Lines(t *testing.T) {
+	tests := []struct {
+		name   string
+		max    int
+		writes []string
+		want   []string
+	}{
+		{"empty", 3, nil, []string{}},
+		{"partial held", 3, []string{"a\nb"}, []string{"a"}},
+		{"split writes", 3, []string{"he", "llo\n"}, []string{"hello"}},
+		{"wraps", 3, []string{"1\n2\n3\n4\n5\n"}, []string{"3", "4", "5"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewLogRing(tt.max)
+			for _, w := range tt.writes {
+				r.Write([]byte(w))
+			}
+			got := r.Lines()
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("Lines() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTailFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tutu.log")
+	os.WriteFile(path, []byte("first line\nsecond line\nthird\n"), 0600)
+
+	got, err := TailFile(path, 100)
+	if err != nil || string(got) != "first line\nsecond line\nthird\n" {
+		t.Errorf("TailFile(100) = %q, %v", got, err)
+	}
+	got, err = TailFile(path, 10)
+	if err != nil || string(got) != "third\n" {
+		t.Errorf("TailFile(10) = %q, %v; want the last whole line", got, err)
+	}
+	if _, err := TailFile(filepath.Join(t.TempDir(), "missing"), 10); err == nil {
+		t.Error("TailFile(missing) should fail")
+	}
+}
@@ -0,0 +1,104 @@
+package dsa
+
+import (
+	"sync"
+	"time"
+)
+
+// ─── Dedup Window ───────────────────────────────────────────────────────────
+// Remembers recently seen idempotency keys so a retried write is applied
+// once. Bounded two ways: keys older than Window are forgotten, and past
+// MaxKeys the oldest key is evicted first. A replay that arrives after its
+// key was forgotten is applied again — the window only has to outlast the
+// sender's retries.
+//
+// Operations:
+//   Seen: O(1) amortized — expired keys are pruned from the front
+
+// DedupConfig configures a dedup window.
+type DedupConfig struct {
+	Window  time.Duration // How long a key is remembered
+	MaxKeys int           // Most keys remembered at once
+}
+
+// DefaultDedupConfig remembers up to 100k keys for 10 minutes.
+func DefaultDedupConfig() DedupConfig {
+	return DedupConfig{
+		Window:  10 * time.Minute,
+		MaxKeys: 100_000,
+	}
+}
+
+// DedupWindow is a thread-safe bounded set of recent keys.
+type DedupWindow struct {
+	mu     sync.Mutex
+	config DedupConfig
+	seen   map[string]time.Time // key → first seen
+	order  []string             // keys in insertion order
+}
+
+// NewDedupWindow creates an empty dedup window. Zero config fields take
+// their defaults.
+func NewDedupWindow(cfg DedupConfig) *DedupWindow {
+	def := DefaultDedupConfig()
+	if cfg.Window <= 0 {
+		cfg.Window = def.Window
+	}
+	if cfg.MaxKeys <= 0 {
+		cfg.MaxKeys = def.MaxKeys
+	}
+	return &DedupWindow{
+		config: cfg,
+		seen:   make(map[string]time.Time),
+	}
+}
+
+// Seen reports whether key was already seen within the window, and
+// remembers it if not. The empty key is never a duplicate.
+func (d *DedupWindow) Seen(key string, now time.Time) bool {
+	if key == "" {
+		return false
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.pruneLocked(now)
+	if _, ok := d.seen[key]; ok {
+		return true
+	}
+	d.seen[key] = now
+	d.order = append(d.order, key)
+	if len(d.order) > d.config.MaxKeys {
+		delete(d.seen, d.order[0])
+		d.order = d.order[1:]
+	}
+	return false
+}
+
+// Len returns the number of keys currently remembered.
+func (d *DedupWindow) Len() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.seen)
+}
+
+// Reset forgets every key.
+func (d *DedupWindow) Reset() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.seen = make(map[string]time.Time)
+	d.order = nil
+}
+
+// pruneLocked forgets keys first seen before now-Window.
+func (d *DedupWindow) pruneLocked(now time.Time) {
+	cutoff := now.Add(-d.config.Window)
+	n := 0
+	for n < len(d.order) && d.seen[d.order[n]].Before(cutoff) {
+		delete(d.seen, d.order[n])
+		n++
+	}
+	// Reslicing is enough: append reallocates once the front is used up,
+	// copying only the live keys
+	d.order = d.order[n:]
+}
@@ -390,3 +390,70 @@ func TestPriorityQueue_ConcurrentSafety(t *testing.T) {
 		t.Errorf("popped %d items, want 1000", count)
 	}
 }
+
+// ─── Dedup Window Tests ─────────────────────────────────────────────────────
+
+func TestDedupWindow_Seen(t *testing.T) {
+	d := NewDedupWindow(DedupConfig{Window: time.Minute, MaxKeys: 10})
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	if d.Seen("a", now) {
+		t.Fatal("first Seen(a) = true")
+	}
+	if !d.Seen("a", now.Add(30*time.Second)) {
+		t.Error("replay inside the window not caught")
+	}
+	if d.Seen("", now) || d.Seen("", now) {
+		t.Error("empty key treated as a duplicate")
+	}
+	if d.Seen("a", now.Add(61*time.Second)) {
+		t.Error("key remembered past the window")
+	}
+	if d.Len() != 1 {
+		t.Errorf("Len() = %d, want 1", d.Len())
+	}
+}
+
+func TestDedupWindow_MaxKeys(t *testing.T) {
+	d := NewDedupWindow(DedupConfig{Window: time.Hour, MaxKeys: 3})
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 4; i++ {
+		d.Seen(fmt.Sprintf("k%d", i), now)
+	}
+	if d.Len() != 3 {
+		t.Fatalf("Len() = %d, want 3", d.Len())
+	}
+	// The oldest key was evicted, the newest are kept
+	if d.Seen("k0", now) {
+		t.Error("evicted key still remembered")
+	}
+	if !d.Seen("k3", now) {
+		t.Error("newest key forgotten")
+	}
+
+	d.Reset()
+	if d.Len() != 0 || d.Seen("k3", now) {
+		t.Error("Reset kept keys")
+	}
+}
+
+// ─── Seeded Random Source Tests ─────────────────────────────────────────────
+
+func TestRand_SeedReplays(t *testing.T) {
+	draw := func(r *Rand) []float64 {
+		out := []float64{r.Float64(), float64(r.Intn(100)), r.NormFloat64()}
+		perm := []float64{1, 2, 3, 4, 5}
+		r.Shuffle(len(perm), func(i, j int) { perm[i], perm[j] = perm[j], perm[i] })
+		return append(out, perm...)
+	}
+	a, b := draw(NewRand(7)), draw(NewRand(7))
+	for i := range a {
+		if a[i] != b[i] {
+			t.Fatalf("seed 7 diverged at draw %d: %v vs %v", i, a, b)
+		}
+	}
+	if c := draw(NewRand(8)); c[0] == a[0] && c[1] == a[1] {
+		t.Error("seeds 7 and 8 produced the same draws")
+	}
+}
@@ -0,0 +1,226 @@
+package embedding
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/tutu-network/tutu/internal/domain"
+)
+
+// fakeEmbedder returns [len(text), 1] per input and fails any call that
+// includes "bad". It records every input it was asked to embed.
+type fakeEmbedder struct {
+	mu    sync.Mutex
+	calls int
+	seen  []string
+}
+
+func (f *fakeEmbedder) embed(_ context.Context, _ string, inputs []string) ([][]float32, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	for _, in := range inputs {
+		if strings.Contains(in, "bad") {
+			return nil, errors.New("cannot embed bad input")
+		}
+	}
+	f.seen = append(f.seen, inputs...)
+	out := make([][]float32, len(inputs))
+	for i, in := range inputs {
+		out[i] = []float32{float32(len(in)), 1}
+	}
+	return out, nil
+}
+
+func collect(t *testing.T, p *Pipeline, id string) []domain.EmbedItemResult {
+	t.Helper()
+	ch, err := p.Stream(context.Background(), id)
+	if err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+	var out []domain.EmbedItemResult
+	for r := range ch {
+		out = append(out, r)
+	}
+	return out
+}
+
+func TestPipeline_Submit_Validation(t *testing.T) {
+	f := &fakeEmbedder{}
+	p := NewPipeline(DefaultConfig(), f.embed, nil)
+	tests := []struct {
+		name  string
+		model string
+		coll  string
+		items []domain.EmbedItem
+	}{
+		{"no model", "", "", []domain.EmbedItem{{Text: "a"}}},
+		{"no items", "m", "", nil},
+		{"collection without store", "m", "docs", []domain.EmbedItem{{Text: "a"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := p.Submit(tt.model, tt.coll, tt.items); !errors.Is(err, domain.ErrEmbedBatchInvalid) {
+				t.Errorf("Submit() err = %v, want ErrEmbedBatchInvalid", err)
+			}
+		})
+	}
+}
+
+func TestPipeline_DedupesAndShards(t *testing.T) {
+	f := &fakeEmbedder{}
+	p := NewPipeline(Config{SelfID: "self", ShardSize: 2}, f.embed, nil)
+
+	items := []domain.EmbedItem{{Text: "a"}, {Text: "bb"}, {Text: "a"}, {Text: "ccc"}, {Text: "bb"}}
+	job, err := p.Submit("m", "", items)
+	if err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	if job.Unique != 3 || job.Shards != 2 {
+		t.Errorf("unique=%d shards=%d, want 3/2", job.Unique, job.Shards)
+	}
+
+	results := collect(t, p, job.ID)
+	if len(results) != len(items) {
+		t.Fatalf("results = %d, want %d", len(results), len(items))
+	}
+	for _, r := range results {
+		if r.Error != "" || int(r.Embedding[0]) != len(items[r.Index].Text) || r.Node != "self" {
+			t.Errorf("result %+v for %q", r, items[r.Index].Text)
+		}
+	}
+	if len(f.seen) != 3 {
+		t.Errorf("embedded %v, want each distinct input once", f.seen)
+	}
+	got, _ := p.Get(job.ID)
+	if got.Status != domain.EmbedJobCompleted || got.Done != 5 {
+		t.Errorf("job = %+v", got)
+	}
+	if st := p.Stats(); st.Deduplicated != 2 || st.Embedded != 3 {
+		t.Errorf("stats = %+v", st)
+	}
+}
+
+func TestPipeline_PerItemErrors(t *testing.T) {
+	f := &fakeEmbedder{}
+	p := NewPipeline(Config{ShardSize: 10}, f.embed, nil)
+
+	job, _ := p.Submit("m", "", []domain.EmbedItem{{ID: "x", Text: "ok"}, {ID: "y", Text: "bad"}, {ID: "z", Text: "fine"}})
+	results := collect(t, p, job.ID)
+
+	byID := make(map[string]domain.EmbedItemResult)
+	for _, r := range results {
+		byID[r.ID] = r
+	}
+	if byID["y"].Error == "" || byID["y"].Embedding != nil {
+		t.Errorf("bad item = %+v, want error", byID["y"])
+	}
+	if byID["x"].Error != "" || byID["z"].Error != "" {
+		t.Errorf("good items failed: %+v %+v", byID["x"], byID["z"])
+	}
+	got, _ := p.Get(job.ID)
+	if got.Done != 2 || got.Failed != 1 {
+		t.Errorf("done=%d failed=%d, want 2/1", got.Done, got.Failed)
+	}
+	if p.Stats().ShardRetries != 1 {
+		t.Errorf("shard retries = %d, want 1", p.Stats().ShardRetries)
+	}
+}
+
+func TestPipeline_RoutesShardsAcrossNodes(t *testing.T) {
+	f := &fakeEmbedder{}
+	p := NewPipeline(Config{SelfID: "self", ShardSize: 1}, f.embed, nil)
+	var (
+		mu     sync.Mutex
+		remote int
+	)
+	p.SetRouter(
+		func() []string { return []string{"self", "peer"} },
+		func(ctx context.Context, node, model string, inputs []string) ([][]float32, error) {
+			mu.Lock()
+			remote++
+			mu.Unlock()
+			return f.embed(ctx, model, inputs)
+		},
+	)
+
+	job, _ := p.Submit("m", "", []domain.EmbedItem{{Text: "a"}, {Text: "b"}, {Text: "c"}, {Text: "d"}})
+	nodes := make(map[string]int)
+	for _, r := range collect(t, p, job.ID) {
+		nodes[r.Node]++
+	}
+	if nodes["self"] != 2 || nodes["peer"] != 2 || remote != 2 {
+		t.Errorf("nodes = %v remote calls = %d, want 2 each", nodes, remote)
+	}
+}
+
+func TestPipeline_StoresVectorsForQuery(t *testing.T) {
+	f := &fakeEmbedder{}
+	vs, _ := NewVectorStore(nil)
+	p := NewPipeline(DefaultConfig(), f.embed, vs)
+
+	job, err := p.Submit("m", "docs", []domain.EmbedItem{{ID: "short", Text: "a"}, {ID: "long", Text: "aaaaaaaaaa"}})
+	if err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	collect(t, p, job.ID)
+
+	c, err := vs.Collection("docs")
+	if err != nil || c.Count != 2 || c.Dim != 2 || c.Model != "m" {
+		t.Fatalf("collection = %+v, %v", c, err)
+	}
+	// [10, 1] points the same way as "long"
+	matches, err := vs.Query("docs", []float32{10, 1}, 1)
+	if err != nil || len(matches) != 1 || matches[0].ID != "long" {
+		t.Errorf("Query = %+v, %v", matches, err)
+	}
+	if p.Stats().VectorsStored != 2 {
+		t.Errorf("vectors stored = %d, want 2", p.Stats().VectorsStored)
+	}
+}
+
+func TestVectorStore_Query(t *testing.T) {
+	vs, _ := NewVectorStore(nil)
+	err := vs.Upsert([]domain.VectorRecord{
+		{Collection: "c", ID: "x", Vector: []float32{1, 0}},
+		{Collection: "c", ID: "y", Vector: []float32{0, 1}},
+		{Collection: "c", ID: "xy", Vector: []float32{1, 1}},
+	})
+	if err != nil {
+		t.Fatalf("Upsert: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		query []float32
+		want  string
+	}{
+		{"x axis", []float32{2, 0}, "x"},
+		{"y axis", []float32{0, 3}, "y"},
+		{"diagonal", []float32{1, 1.1}, "xy"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := vs.Query("c", tt.query, 3)
+			if err != nil || len(m) != 3 || m[0].ID != tt.want {
+				t.Errorf("Query = %+v, %v, want top %s", m, err, tt.want)
+			}
+		})
+	}
+
+	if _, err := vs.Query("c", []float32{1, 2, 3}, 1); !errors.Is(err, domain.ErrVectorDimMismatch) {
+		t.Errorf("wrong dim err = %v", err)
+	}
+	if _, err := vs.Query("missing", []float32{1, 0}, 1); !errors.Is(err, domain.ErrCollectionNotFound) {
+		t.Errorf("missing collection err = %v", err)
+	}
+	if err := vs.Upsert([]domain.VectorRecord{{Collection: "c", ID: "z", Vector: []float32{1}}}); !errors.Is(err, domain.ErrVectorDimMismatch) {
+		t.Errorf("Upsert wrong dim err = %v", err)
+	}
+	if n, err := vs.Delete("c"); err != nil || n != 3 {
+		t.Errorf("Delete = %d, %v", n, err)
+	}
+}
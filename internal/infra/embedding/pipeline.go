@@ -0,0 +1,517 @@
+// Package embedding implements batch embedding jobs and a local vector
+// store for lightweight RAG.
+//
+// How a batch job runs:
+//  1. Identical inputs are collapsed, so each distinct text is embedded once
+//  2. Distinct inputs are split into shards and spread across nodes
+//     (round-robin over the router's nodes; this node when none is set)
+//  3. A failed shard is retried one input at a time on this node, so a
+//     single bad input only fails its own items
+//  4. Per-item results are streamed back as each shard finishes
+//  5. With a collection set, vectors are stored and can be queried by
+//     cosine similarity
+package embedding
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
+)
+
+// ─── Configuration ──────────────────────────────────────────────────────────
+
+// Config tunes the batch pipeline.
+type Config struct {
+	SelfID       string        // this node's ID, recorded on locally embedded items
+	ShardSize    int           // distinct inputs per shard (default: 64)
+	Parallel     int           // shards in flight per job (default: 4)
+	MaxItems     int           // maximum items per job (default: 100000)
+	ShardTimeout time.Duration // per-shard deadline (default: 2m)
+	RetainJobs   int           // finished jobs kept for status and replay (default: 100)
+
+	// Now is an injectable clock for testing.
+	Now func() time.Time
+}
+
+// DefaultConfig returns production defaults.
+func DefaultConfig() Config {
+	return Config{
+		SelfID:       "node-local",
+		ShardSize:    64,
+		Parallel:     4,
+		MaxItems:     100000,
+		ShardTimeout: 2 * time.Minute,
+		RetainJobs:   100,
+		Now:          time.Now,
+	}
+}
+
+// EmbedFunc computes one vector per input on this node.
+type EmbedFunc func(ctx context.Context, model string, inputs []string) ([][]float32, error)
+
+// RemoteEmbed computes vectors on another node.
+type RemoteEmbed func(ctx context.Context, node, model string, inputs []string) ([][]float32, error)
+
+// Stats summarizes pipeline activity.
+type Stats struct {
+	Jobs          int   `json:"jobs"`
+	Running       int   `json:"running"`
+	Items         int64 `json:"items"`
+	Embedded      int64 `json:"embedded"`       // distinct inputs sent to a model
+	Deduplicated  int64 `json:"deduplicated"`   // items served by another item's vector
+	Failed        int64 `json:"failed"`         // items with an error
+	ShardRetries  int64 `json:"shard_retries"`  // shards retried item by item
+	VectorsStored int64 `json:"vectors_stored"` // vectors written to collections
+}
+
+// ─── Pipeline ───────────────────────────────────────────────────────────────
+
+type job struct {
+	info    domain.EmbedJob
+	items   []domain.EmbedItem
+	results []domain.EmbedItemResult
+	updated chan struct{} // closed and replaced whenever results change
+	cancel  context.CancelFunc
+}
+
+// Pipeline runs embedding batch jobs.
+type Pipeline struct {
+	mu      sync.Mutex
+	cfg     Config
+	local   EmbedFunc
+	vectors *VectorStore
+	nodes   func() []string
+	remote  RemoteEmbed
+	jobs    map[string]*job
+	order   []string // job IDs, oldest first
+	nextID  int64
+	stats   Stats
+}
+
+// NewPipeline creates a pipeline embedding with local. vectors may be nil,
+// in which case jobs cannot name a collection.
+func NewPipeline(cfg Config, local EmbedFunc, vectors *VectorStore) *Pipeline {
+	def := DefaultConfig()
+	if cfg.SelfID == "" {
+		cfg.SelfID = def.SelfID
+	}
+	if cfg.ShardSize <= 0 {
+		cfg.ShardSize = def.ShardSize
+	}
+	if cfg.Parallel <= 0 {
+		cfg.Parallel = def.Parallel
+	}
+	if cfg.MaxItems <= 0 {
+		cfg.MaxItems = def.MaxItems
+	}
+	if cfg.ShardTimeout <= 0 {
+		cfg.ShardTimeout = def.ShardTimeout
+	}
+	if cfg.RetainJobs <= 0 {
+		cfg.RetainJobs = def.RetainJobs
+	}
+	if cfg.Now == nil {
+		cfg.Now = def.Now
+	}
+	return &Pipeline{
+		cfg:     cfg,
+		local:   local,
+		vectors: vectors,
+		jobs:    make(map[string]*job),
+		nextID:  1,
+	}
+}
+
+// SetRouter spreads shards across nodes. nodes returns the nodes eligible
+// for shards (this node's ID may be among them); shards for other nodes go
+// through remote.
+func (p *Pipeline) SetRouter(nodes func() []string, remote RemoteEmbed) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.nodes = nodes
+	p.remote = remote
+}
+
+// Vectors returns the pipeline's vector store (nil if none).
+func (p *Pipeline) Vectors() *VectorStore { return p.vectors }
+
+// Embed computes a single vector on this node (e.g. for a similarity query).
+func (p *Pipeline) Embed(ctx context.Context, model, text string) ([]float32, error) {
+	vecs, err := p.local(ctx, model, []string{text})
+	if err != nil {
+		return nil, err
+	}
+	if len(vecs) != 1 {
+		return nil, fmt.Errorf("embed: got %d vectors for 1 input", len(vecs))
+	}
+	return vecs[0], nil
+}
+
+// Submit validates a batch and starts it in the background. Items without
+// an ID are identified by their index.
+func (p *Pipeline) Submit(model, coll string, items []domain.EmbedItem) (domain.EmbedJob, error) {
+	if model == "" {
+		return domain.EmbedJob{}, fmt.Errorf("model is required: %w", domain.ErrEmbedBatchInvalid)
+	}
+	if len(items) == 0 || len(items) > p.cfg.MaxItems {
+		return domain.EmbedJob{}, fmt.Errorf("batch has %d items, want 1..%d: %w",
+			len(items), p.cfg.MaxItems, domain.ErrEmbedBatchInvalid)
+	}
+	if coll != "" {
+		if p.vectors == nil {
+			return domain.EmbedJob{}, fmt.Errorf("no vector store: %w", domain.ErrEmbedBatchInvalid)
+		}
+		if !ValidCollection(coll) {
+			return domain.EmbedJob{}, fmt.Errorf("collection %q: %w", coll, domain.ErrEmbedBatchInvalid)
+		}
+	}
+
+	items = append([]domain.EmbedItem(nil), items...)
+	seenIDs := make(map[string]bool, len(items))
+	for i := range items {
+		if items[i].ID == "" {
+			items[i].ID = strconv.Itoa(i)
+		}
+		if coll != "" && seenIDs[items[i].ID] {
+			return domain.EmbedJob{}, fmt.Errorf("duplicate item id %q: %w", items[i].ID, domain.ErrEmbedBatchInvalid)
+		}
+		seenIDs[items[i].ID] = true
+	}
+
+	// Dedupe: one entry per distinct text, listing the items that share it
+	var (
+		texts  []string
+		groups [][]int
+	)
+	byText := make(map[string]int, len(items))
+	for i, it := range items {
+		u, ok := byText[it.Text]
+		if !ok {
+			u = len(texts)
+			byText[it.Text] = u
+			texts = append(texts, it.Text)
+			groups = append(groups, nil)
+		}
+		groups[u] = append(groups[u], i)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p.mu.Lock()
+	now := p.cfg.Now()
+	j := &job{
+		info: domain.EmbedJob{
+			ID:         fmt.Sprintf("embed-%d-%d", now.UnixMilli(), p.nextID),
+			Model:      model,
+			Collection: coll,
+			Status:     domain.EmbedJobRunning,
+			Total:      len(items),
+			Unique:     len(texts),
+			Shards:     (len(texts) + p.cfg.ShardSize - 1) / p.cfg.ShardSize,
+			CreatedAt:  now,
+		},
+		items:   items,
+		updated: make(chan struct{}),
+		cancel:  cancel,
+	}
+	p.nextID++
+	p.jobs[j.info.ID] = j
+	p.order = append(p.order, j.info.ID)
+	p.stats.Items += int64(len(items))
+	p.stats.Deduplicated += int64(len(items) - len(texts))
+	info := j.info
+	p.mu.Unlock()
+
+	go p.run(ctx, j, texts, groups)
+	return info, nil
+}
+
+// run embeds every shard of a job, at most Parallel at a time.
+func (p *Pipeline) run(ctx context.Context, j *job, texts []string, groups [][]int) {
+	defer j.cancel()
+
+	p.mu.Lock()
+	nodes := []string{p.cfg.SelfID}
+	if p.nodes != nil {
+		if n := p.nodes(); len(n) > 0 {
+			nodes = n
+		}
+	}
+	p.mu.Unlock()
+
+	sem := make(chan struct{}, p.cfg.Parallel)
+	var wg sync.WaitGroup
+	for s, start := 0, 0; start < len(texts); s, start = s+1, start+p.cfg.ShardSize {
+		end := min(start+p.cfg.ShardSize, len(texts))
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(node string, start, end int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			vecs, errs, node := p.embedShard(ctx, node, j.info.Model, texts[start:end])
+			p.deliver(j, node, vecs, errs, groups[start:end])
+		}(nodes[s%len(nodes)], start, end)
+	}
+	wg.Wait()
+
+	p.mu.Lock()
+	if j.info.Status == domain.EmbedJobRunning {
+		j.info.Status = domain.EmbedJobCompleted
+	}
+	j.info.CompletedAt = p.cfg.Now()
+	p.notifyLocked(j)
+	p.pruneLocked()
+	p.mu.Unlock()
+}
+
+// embedShard embeds a shard on node. If the shard fails as a whole, its
+// inputs are retried one by one on this node; errs[i] is set for inputs
+// that still fail. The returned node is where the vectors came from.
+func (p *Pipeline) embedShard(ctx context.Context, node, model string, texts []string) ([][]float32, []error, string) {
+	vecs, err := p.call(ctx, node, model, texts)
+	errs := make([]error, len(texts))
+	if err == nil {
+		return vecs, errs, node
+	}
+
+	p.mu.Lock()
+	p.stats.ShardRetries++
+	p.mu.Unlock()
+	vecs = make([][]float32, len(texts))
+	for i, t := range texts {
+		if ctx.Err() != nil {
+			errs[i] = ctx.Err()
+			continue
+		}
+		one, err := p.call(ctx, p.cfg.SelfID, model, []string{t})
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+		vecs[i] = one[0]
+	}
+	return vecs, errs, p.cfg.SelfID
+}
+
+// call embeds texts on node and checks that one vector came back per input.
+func (p *Pipeline) call(ctx context.Context, node, model string, texts []string) ([][]float32, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.cfg.ShardTimeout)
+	defer cancel()
+
+	p.mu.Lock()
+	remote := p.remote
+	p.mu.Unlock()
+
+	var (
+		vecs [][]float32
+		err  error
+	)
+	switch {
+	case node == p.cfg.SelfID:
+		vecs, err = p.local(ctx, model, texts)
+	case remote != nil:
+		vecs, err = remote(ctx, node, model, texts)
+	default:
+		err = fmt.Errorf("no remote embedder for node %s", node)
+	}
+	if err == nil && len(vecs) != len(texts) {
+		err = fmt.Errorf("node %s returned %d vectors for %d inputs", node, len(vecs), len(texts))
+	}
+	return vecs, err
+}
+
+// deliver fans a shard's vectors out to every item sharing each input,
+// stores them when the job has a collection, and wakes result streams.
+func (p *Pipeline) deliver(j *job, node string, vecs [][]float32, errs []error, groups [][]int) {
+	var (
+		results []domain.EmbedItemResult
+		recs    []domain.VectorRecord
+	)
+	now := p.cfg.Now()
+	for u, members := range groups {
+		for _, i := range members {
+			r := domain.EmbedItemResult{Index: i, ID: j.items[i].ID, Node: node}
+			if errs[u] != nil {
+				r.Error = errs[u].Error()
+			} else {
+				r.Embedding = vecs[u]
+				if j.info.Collection != "" {
+					recs = append(recs, domain.VectorRecord{
+						Collection: j.info.Collection,
+						ID:         j.items[i].ID,
+						Model:      j.info.Model,
+						Text:       j.items[i].Text,
+						Vector:     vecs[u],
+						CreatedAt:  now,
+					})
+				}
+			}
+			results = append(results, r)
+		}
+	}
+
+	// A vector the store rejects (e.g. wrong dimension) is an item error
+	var storeErr error
+	if len(recs) > 0 {
+		storeErr = p.vectors.Upsert(recs)
+	}
+
+	sort.Slice(results, func(a, b int) bool { return results[a].Index < results[b].Index })
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, r := range results {
+		if storeErr != nil && r.Error == "" {
+			r.Embedding = nil
+			r.Error = storeErr.Error()
+		}
+		if r.Error != "" {
+			j.info.Failed++
+			p.stats.Failed++
+		} else {
+			j.info.Done++
+		}
+		j.results = append(j.results, r)
+	}
+	for _, err := range errs {
+		if err == nil {
+			p.stats.Embedded++
+		}
+	}
+	if storeErr == nil {
+		p.stats.VectorsStored += int64(len(recs))
+	}
+	p.notifyLocked(j)
+}
+
+func (p *Pipeline) notifyLocked(j *job) {
+	close(j.updated)
+	j.updated = make(chan struct{})
+}
+
+// pruneLocked drops the oldest finished jobs beyond RetainJobs.
+func (p *Pipeline) pruneLocked() {
+	finished := 0
+	for _, id := range p.order {
+		if p.jobs[id].info.Status != domain.EmbedJobRunning {
+			finished++
+		}
+	}
+	kept := p.order[:0]
+	for _, id := range p.order {
+		if finished > p.cfg.RetainJobs && p.jobs[id].info.Status != domain.EmbedJobRunning {
+			delete(p.jobs, id)
+			finished--
+			continue
+		}
+		kept = append(kept, id)
+	}
+	p.order = kept
+}
+
+// ─── Queries ────────────────────────────────────────────────────────────────
+
+// Stream returns a job's results: first those already available, then the
+// rest as shards finish. The channel closes when the job is done or ctx
+// ends.
+func (p *Pipeline) Stream(ctx context.Context, id string) (<-chan domain.EmbedItemResult, error) {
+	p.mu.Lock()
+	j, ok := p.jobs[id]
+	p.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", id, domain.ErrEmbedJobNotFound)
+	}
+
+	out := make(chan domain.EmbedItemResult)
+	go func() {
+		defer close(out)
+		next := 0
+		for {
+			p.mu.Lock()
+			pending := j.results[next:]
+			updated := j.updated
+			finished := j.info.Status != domain.EmbedJobRunning
+			p.mu.Unlock()
+
+			for _, r := range pending {
+				select {
+				case out <- r:
+				case <-ctx.Done():
+					return
+				}
+			}
+			next += len(pending)
+			if finished && len(pending) == 0 {
+				return
+			}
+			if len(pending) == 0 {
+				select {
+				case <-updated:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return out, nil
+}
+
+// Cancel stops a running job. Shards already in flight finish.
+func (p *Pipeline) Cancel(id string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	j, ok := p.jobs[id]
+	if !ok {
+		return fmt.Errorf("%s: %w", id, domain.ErrEmbedJobNotFound)
+	}
+	if j.info.Status == domain.EmbedJobRunning {
+		j.info.Status = domain.EmbedJobCancelled
+		j.cancel()
+	}
+	return nil
+}
+
+// Get returns a job's progress.
+func (p *Pipeline) Get(id string) (domain.EmbedJob, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	j, ok := p.jobs[id]
+	if !ok {
+		return domain.EmbedJob{}, fmt.Errorf("%s: %w", id, domain.ErrEmbedJobNotFound)
+	}
+	return j.info, nil
+}
+
+// List returns retained jobs, newest first.
+func (p *Pipeline) List() []domain.EmbedJob {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	out := make([]domain.EmbedJob, 0, len(p.order))
+	for i := len(p.order) - 1; i >= 0; i-- {
+		out = append(out, p.jobs[p.order[i]].info)
+	}
+	return out
+}
+
+// Stats returns pipeline statistics.
+func (p *Pipeline) Stats() Stats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	st := p.stats
+	st.Jobs = len(p.jobs)
+	for _, j := range p.jobs {
+		if j.info.Status == domain.EmbedJobRunning {
+			st.Running++
+		}
+	}
+	return st
+}
@@ -0,0 +1,213 @@
+package embedding
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"sync"
+
+	"github.com/tutu-network/tutu/internal/domain"
+)
+
+// ─── Vector Store ───────────────────────────────────────────────────────────
+// A lightweight local store for RAG: vectors live in SQLite and are held in
+// memory for brute-force cosine search. Fine for tens of thousands of
+// vectors per collection; not an ANN index.
+
+// Store persists vectors (*sqlite.DB satisfies it).
+type Store interface {
+	UpsertVectors(recs []domain.VectorRecord) error
+	ListVectors() ([]domain.VectorRecord, error)
+	DeleteVectorCollection(collection string) (int, error)
+}
+
+// collectionName restricts collection names to URL- and file-safe text.
+var collectionName = regexp.MustCompile(`^[A-Za-z0-9_.-]{1,64}$`)
+
+// ValidCollection reports whether name can be used as a collection name.
+func ValidCollection(name string) bool { return collectionName.MatchString(name) }
+
+type entry struct {
+	rec  domain.VectorRecord
+	norm float64
+}
+
+type collection struct {
+	model   string
+	dim     int
+	index   map[string]int // record ID → position in entries
+	entries []entry
+}
+
+// VectorStore holds embeddings grouped into named collections.
+type VectorStore struct {
+	mu          sync.RWMutex
+	store       Store
+	collections map[string]*collection
+}
+
+// NewVectorStore loads persisted vectors. store may be nil (memory only).
+func NewVectorStore(store Store) (*VectorStore, error) {
+	v := &VectorStore{store: store, collections: make(map[string]*collection)}
+	if store == nil {
+		return v, nil
+	}
+	recs, err := store.ListVectors()
+	if err != nil {
+		return nil, fmt.Errorf("load vectors: %w", err)
+	}
+	for _, r := range recs {
+		v.putLocked(r)
+	}
+	return v, nil
+}
+
+// Upsert adds or replaces vectors. Every vector in a collection must have
+// the same dimension.
+func (v *VectorStore) Upsert(recs []domain.VectorRecord) error {
+	if len(recs) == 0 {
+		return nil
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	dims := make(map[string]int)
+	for _, r := range recs {
+		if !ValidCollection(r.Collection) {
+			return fmt.Errorf("collection %q: %w", r.Collection, domain.ErrEmbedBatchInvalid)
+		}
+		want, ok := dims[r.Collection]
+		if !ok {
+			want = len(r.Vector)
+			if c, exists := v.collections[r.Collection]; exists && len(c.entries) > 0 {
+				want = c.dim
+			}
+			dims[r.Collection] = want
+		}
+		if len(r.Vector) == 0 || len(r.Vector) != want {
+			return fmt.Errorf("collection %s: vector %s has %d dims, want %d: %w",
+				r.Collection, r.ID, len(r.Vector), want, domain.ErrVectorDimMismatch)
+		}
+	}
+
+	if v.store != nil {
+		if err := v.store.UpsertVectors(recs); err != nil {
+			return fmt.Errorf("persist vectors: %w", err)
+		}
+	}
+	for _, r := range recs {
+		v.putLocked(r)
+	}
+	return nil
+}
+
+func (v *VectorStore) putLocked(r domain.VectorRecord) {
+	c, ok := v.collections[r.Collection]
+	if !ok {
+		c = &collection{index: make(map[string]int)}
+		v.collections[r.Collection] = c
+	}
+	c.model = r.Model
+	c.dim = len(r.Vector)
+	e := entry{rec: r, norm: norm(r.Vector)}
+	if i, ok := c.index[r.ID]; ok {
+		c.entries[i] = e
+		return
+	}
+	c.index[r.ID] = len(c.entries)
+	c.entries = append(c.entries, e)
+}
+
+// Query returns the k vectors in a collection most similar to vec.
+func (v *VectorStore) Query(name string, vec []float32, k int) ([]domain.VectorMatch, error) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	c, ok := v.collections[name]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", name, domain.ErrCollectionNotFound)
+	}
+	if len(vec) != c.dim {
+		return nil, fmt.Errorf("query has %d dims, collection %s has %d: %w",
+			len(vec), name, c.dim, domain.ErrVectorDimMismatch)
+	}
+	if k <= 0 {
+		k = 10
+	}
+
+	qn := norm(vec)
+	matches := make([]domain.VectorMatch, 0, len(c.entries))
+	for _, e := range c.entries {
+		matches = append(matches, domain.VectorMatch{
+			ID:    e.rec.ID,
+			Text:  e.rec.Text,
+			Score: cosine(vec, qn, e.rec.Vector, e.norm),
+		})
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Score > matches[j].Score })
+	if len(matches) > k {
+		matches = matches[:k]
+	}
+	return matches, nil
+}
+
+// Collection returns a collection summary.
+func (v *VectorStore) Collection(name string) (domain.VectorCollection, error) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	c, ok := v.collections[name]
+	if !ok {
+		return domain.VectorCollection{}, fmt.Errorf("%s: %w", name, domain.ErrCollectionNotFound)
+	}
+	return domain.VectorCollection{Name: name, Model: c.model, Dim: c.dim, Count: len(c.entries)}, nil
+}
+
+// Collections lists collections sorted by name.
+func (v *VectorStore) Collections() []domain.VectorCollection {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	out := make([]domain.VectorCollection, 0, len(v.collections))
+	for name, c := range v.collections {
+		out = append(out, domain.VectorCollection{Name: name, Model: c.model, Dim: c.dim, Count: len(c.entries)})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// Delete removes a collection and returns how many vectors it held.
+func (v *VectorStore) Delete(name string) (int, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	c, ok := v.collections[name]
+	if !ok {
+		return 0, fmt.Errorf("%s: %w", name, domain.ErrCollectionNotFound)
+	}
+	if v.store != nil {
+		if _, err := v.store.DeleteVectorCollection(name); err != nil {
+			return 0, fmt.Errorf("delete collection: %w", err)
+		}
+	}
+	delete(v.collections, name)
+	return len(c.entries), nil
+}
+
+func norm(v []float32) float64 {
+	var sum float64
+	for _, f := range v {
+		sum += float64(f) * float64(f)
+	}
+	return math.Sqrt(sum)
+}
+
+// cosine returns the cosine similarity of a and b given their norms
+// (0 when either is the zero vector).
+func cosine(a []float32, an float64, b []float32, bn float64) float64 {
+	if an == 0 || bn == 0 {
+		return 0
+	}
+	var dot float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+	}
+	return dot / (an * bn)
+}
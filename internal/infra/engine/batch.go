@@ -0,0 +1,276 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ─── Dynamic Request Batching ───────────────────────────────────────────────
+// Concurrent embedding requests for the same model are held for up to
+// MaxDelay and executed together:
+//
+//	request ─┐
+//	request ─┼─ pending[model] ──(MaxBatchSize reached | MaxDelay elapsed)──▶ flush
+//	request ─┘                                                                 │
+//	                                one Acquire + one Embed call per batch ◀─┘
+//
+// Stats reports the trade-off: requests per backend call (throughput gain)
+// versus queueing delay (added latency).
+//
+// Only embeddings are batched (the API, MCP tools and agents). Chat and
+// completion requests stream through PoolHandle, whose TokenStream carries
+// cancellation, backpressure, KV-cache slots and speculative decoding, and
+// llama-server already batches concurrent decodes across its slots.
+
+// BatchConfig tunes dynamic batching.
+type BatchConfig struct {
+	MaxBatchSize int           // flush as soon as this many requests are queued
+	MaxDelay     time.Duration // longest a request waits for companions
+
+	// Observe, if set, is called once per executed batch (e.g. to feed
+	// Prometheus histograms).
+	Observe func(model string, size int, wait time.Duration)
+}
+
+// DefaultBatchConfig returns production defaults: up to 8 requests, 5 ms window.
+func DefaultBatchConfig() BatchConfig {
+	return BatchConfig{
+		MaxBatchSize: 8,
+		MaxDelay:     5 * time.Millisecond,
+	}
+}
+
+// BatchStats summarizes batching effectiveness.
+type BatchStats struct {
+	Requests        int64         `json:"requests"`
+	Batches         int64         `json:"batches"`
+	AvgBatchSize    float64       `json:"avg_batch_size"`    // requests per backend call
+	SavedCalls      int64         `json:"saved_calls"`       // backend calls avoided by batching
+	AvgAddedLatency time.Duration `json:"avg_added_latency"` // mean queueing delay per request
+	MaxAddedLatency time.Duration `json:"max_added_latency"` // worst queueing delay observed
+	FullFlushes     int64         `json:"full_flushes"`      // batches flushed at MaxBatchSize
+	TimerFlushes    int64         `json:"timer_flushes"`     // batches flushed at MaxDelay
+}
+
+// batchRequest is one caller waiting on a batch.
+type batchRequest struct {
+	ctx      context.Context
+	inputs   []string
+	enqueued time.Time
+	result   chan batchResult
+}
+
+type batchResult struct {
+	embeds [][]float32
+	err    error
+}
+
+// pendingBatch accumulates requests until flushed.
+type pendingBatch struct {
+	model string
+	opts  LoadOptions
+	reqs  []*batchRequest
+	timer *time.Timer
+}
+
+// Batcher coalesces concurrent embedding requests in front of a Pool.
+type Batcher struct {
+	pool *Pool
+	cfg  BatchConfig
+
+	mu      sync.Mutex
+	pending map[string]*pendingBatch // model → batch being filled
+
+	statsMu    sync.Mutex
+	requests   int64
+	batches    int64
+	totalWait  time.Duration
+	maxWait    time.Duration
+	fullFlush  int64
+	timerFlush int64
+
+	now func() time.Time
+}
+
+// NewBatcher creates a batcher over pool. Non-positive config values fall
+// back to DefaultBatchConfig.
+func NewBatcher(pool *Pool, cfg BatchConfig) *Batcher {
+	def := DefaultBatchConfig()
+	if cfg.MaxBatchSize <= 0 {
+		cfg.MaxBatchSize = def.MaxBatchSize
+	}
+	if cfg.MaxDelay <= 0 {
+		cfg.MaxDelay = def.MaxDelay
+	}
+	return &Batcher{
+		pool:    pool,
+		cfg:     cfg,
+		pending: make(map[string]*pendingBatch),
+		now:     time.Now,
+	}
+}
+
+// Embed queues inputs and returns their embeddings once the batch runs.
+func (b *Batcher) Embed(ctx context.Context, model string, opts LoadOptions, inputs []string) ([][]float32, error) {
+	res, err := b.submit(ctx, model, opts, &batchRequest{inputs: inputs})
+	if err != nil {
+		return nil, err
+	}
+	return res.embeds, nil
+}
+
+// Stats returns a snapshot of batching effectiveness.
+func (b *Batcher) Stats() BatchStats {
+	b.statsMu.Lock()
+	defer b.statsMu.Unlock()
+	s := BatchStats{
+		Requests:        b.requests,
+		Batches:         b.batches,
+		SavedCalls:      b.requests - b.batches,
+		MaxAddedLatency: b.maxWait,
+		FullFlushes:     b.fullFlush,
+		TimerFlushes:    b.timerFlush,
+	}
+	if b.batches > 0 {
+		s.AvgBatchSize = float64(b.requests) / float64(b.batches)
+	}
+	if b.requests > 0 {
+		s.AvgAddedLatency = b.totalWait / time.Duration(b.requests)
+	}
+	return s
+}
+
+// submit enqueues a request and blocks until its result or ctx is done.
+func (b *Batcher) submit(ctx context.Context, model string, opts LoadOptions, req *batchRequest) (batchResult, error) {
+	req.ctx = ctx
+	req.enqueued = b.now()
+	req.result = make(chan batchResult, 1)
+
+	b.mu.Lock()
+	pb, ok := b.pending[model]
+	if !ok {
+		pb = &pendingBatch{model: model, opts: opts}
+		b.pending[model] = pb
+		pb.timer = time.AfterFunc(b.cfg.MaxDelay, func() { b.flush(pb, false) })
+	}
+	pb.reqs = append(pb.reqs, req)
+	full := len(pb.reqs) >= b.cfg.MaxBatchSize
+	b.mu.Unlock()
+
+	if full {
+		b.flush(pb, true)
+	}
+
+	select {
+	case res := <-req.result:
+		return res, res.err
+	case <-ctx.Done():
+		return batchResult{}, ctx.Err()
+	}
+}
+
+// flush detaches pb from the pending set and executes it. Safe to call from
+// both the size trigger and the timer; only the first call runs the batch.
+func (b *Batcher) flush(pb *pendingBatch, full bool) {
+	b.mu.Lock()
+	if b.pending[pb.model] != pb {
+		b.mu.Unlock()
+		return // already flushed
+	}
+	delete(b.pending, pb.model)
+	pb.timer.Stop()
+	b.mu.Unlock()
+
+	b.recordFlush(pb, full)
+	go b.execute(pb)
+}
+
+func (b *Batcher) recordFlush(pb *pendingBatch, full bool) {
+	now := b.now()
+	var batchWait time.Duration
+
+	b.statsMu.Lock()
+	b.batches++
+	b.requests += int64(len(pb.reqs))
+	if full {
+		b.fullFlush++
+	} else {
+		b.timerFlush++
+	}
+	for _, r := range pb.reqs {
+		w := now.Sub(r.enqueued)
+		b.totalWait += w
+		if w > b.maxWait {
+			b.maxWait = w
+		}
+		if w > batchWait {
+			batchWait = w
+		}
+	}
+	b.statsMu.Unlock()
+
+	if b.cfg.Observe != nil {
+		b.cfg.Observe(pb.model, len(pb.reqs), batchWait)
+	}
+}
+
+// execute runs a flushed batch against a single pooled handle.
+func (b *Batcher) execute(pb *pendingBatch) {
+	handle, err := b.pool.Acquire(pb.model, pb.opts)
+	if err != nil {
+		for _, r := range pb.reqs {
+			r.result <- batchResult{err: err}
+		}
+		return
+	}
+	defer handle.Release()
+
+	var inputs []string
+	for _, r := range pb.reqs {
+		inputs = append(inputs, r.inputs...)
+	}
+
+	ctx, stop := mergedContext(pb.reqs)
+	defer stop()
+	embeds, err := handle.Model().Embed(ctx, inputs)
+	if err == nil && len(embeds) != len(inputs) {
+		err = fmt.Errorf("batch embed: got %d vectors for %d inputs", len(embeds), len(inputs))
+	}
+
+	off := 0
+	for _, r := range pb.reqs {
+		if err != nil {
+			r.result <- batchResult{err: err}
+			continue
+		}
+		r.result <- batchResult{embeds: embeds[off : off+len(r.inputs)]}
+		off += len(r.inputs)
+	}
+}
+
+// mergedContext returns a context that is cancelled once every request's
+// context is done (the batch is still useful while any caller waits).
+// The returned stop func releases the watcher goroutine.
+func mergedContext(reqs []*batchRequest) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		for _, r := range reqs {
+			select {
+			case <-r.ctx.Done():
+			case <-done:
+				return
+			}
+		}
+		cancel()
+	}()
+	var once sync.Once
+	return ctx, func() {
+		once.Do(func() {
+			close(done)
+			cancel()
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
+)
+
+// ─── Model Benchmark ────────────────────────────────────────────────────────
+// Measures how fast a model generates on this node's hardware: time to the
+// first token (prompt processing plus scheduling overhead) and decode
+// throughput after it. The model is loaded before timing starts, so a cold
+// load does not count against it. Each measure is the median over a few
+// runs with a fixed seed and prompt, which keeps one noisy run from
+// skewing the published figure. Runs decode without the draft model of a
+// speculative pair, so the throughput is the baseline speculative decoding
+// is measured against (SpeculativeTracker.SetBaseline).
+
+// BenchmarkConfig configures a model benchmark.
+type BenchmarkConfig struct {
+	Prompt    string        // prompt to complete (default: a short instruction)
+	MaxTokens int           // tokens generated per run (default: 64)
+	Runs      int           // timed runs; the median is reported (default: 3)
+	Timeout   time.Duration // limit on the whole benchmark (default: 2m)
+}
+
+// DefaultBenchmarkConfig returns a short benchmark that finishes in seconds
+// on a GPU and well under a minute on a CPU.
+func DefaultBenchmarkConfig() BenchmarkConfig {
+	return BenchmarkConfig{
+		Prompt:    "Explain in a few sentences why the sky appears blue during the day.",
+		MaxTokens: 64,
+		Runs:      3,
+		Timeout:   2 * time.Minute,
+	}
+}
+
+// benchmarkSeed makes every run sample the same tokens.
+const benchmarkSeed int64 = 42
+
+// Benchmark measures model's time to first token and decode throughput.
+// The model is acquired (and loaded if needed) through the pool, so the
+// benchmark respects the memory budget and any guard.
+func (p *Pool) Benchmark(ctx context.Context, model string, cfg BenchmarkConfig) (domain.ModelBenchmark, error) {
+	def := DefaultBenchmarkConfig()
+	if cfg.Prompt == "" {
+		cfg.Prompt = def.Prompt
+	}
+	if cfg.MaxTokens <= 0 {
+		cfg.MaxTokens = def.MaxTokens
+	}
+	if cfg.Runs <= 0 {
+		cfg.Runs = def.Runs
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = def.Timeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, cfg.Timeout)
+	defer cancel()
+
+	h, err := p.Acquire(model, LoadOptions{NumGPULayers: -1})
+	if err != nil {
+		return domain.ModelBenchmark{}, err
+	}
+	defer h.Release()
+
+	seed := benchmarkSeed
+	params := GenerateParams{Temperature: 0, MaxTokens: cfg.MaxTokens, Seed: &seed, NoDraft: true}
+	ttfts := make([]float64, 0, cfg.Runs)
+	rates := make([]float64, 0, cfg.Runs)
+	tokens := 0
+	for i := 0; i < cfg.Runs; i++ {
+		run, err := benchmarkRun(ctx, h, cfg.Prompt, params)
+		if err != nil {
+			return domain.ModelBenchmark{}, fmt.Errorf("benchmark %s: run %d: %w", model, i+1, err)
+		}
+		ttfts = append(ttfts, run.ttftMs)
+		rates = append(rates, run.tokensPerSec)
+		tokens = max(tokens, run.tokens)
+	}
+	return domain.ModelBenchmark{
+		Model:        model,
+		TokensPerSec: median(rates),
+		TTFTMs:       median(ttfts),
+		Tokens:       tokens,
+		Runs:         cfg.Runs,
+		MeasuredAt:   time.Now(),
+	}, nil
+}
+
+// benchmarkResult is the timing of one generation.
+type benchmarkResult struct {
+	ttftMs       float64
+	tokensPerSec float64
+	tokens       int
+}
+
+func benchmarkRun(ctx context.Context, h *PoolHandle, prompt string, params GenerateParams) (benchmarkResult, error) {
+	start := time.Now()
+	stream, err := h.Generate(ctx, prompt, params)
+	if err != nil {
+		return benchmarkResult{}, err
+	}
+	var first, last time.Time
+	n := 0
+	for tok := range stream.Tokens() {
+		if tok.Text == "" && !tok.Done {
+			continue
+		}
+		now := time.Now()
+		if n == 0 {
+			first = now
+		}
+		last = now
+		n++
+	}
+	if err := stream.Err(); err != nil {
+		return benchmarkResult{}, err
+	}
+	if n == 0 {
+		return benchmarkResult{}, fmt.Errorf("no tokens generated")
+	}
+
+	res := benchmarkResult{ttftMs: float64(first.Sub(start).Microseconds()) / 1000, tokens: n}
+	// Decode throughput is measured between the first and last token; with
+	// a single token, fall back to the whole generation.
+	if decode := last.Sub(first); n > 1 && decode > 0 {
+		res.tokensPerSec = float64(n-1) / decode.Seconds()
+	} else if total := last.Sub(start); total > 0 {
+		res.tokensPerSec = float64(n) / total.Seconds()
+	}
+	return res, nil
+}
+
+// median returns the middle value (the mean of the middle two for an even
+// count).
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
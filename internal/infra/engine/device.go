@@ -0,0 +1,362 @@
+package engine
+
+import (
+	"fmt"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ─── GPU Device Placement ───────────────────────────────────────────────────
+// The DeviceManager decides where each model's weights live:
+//
+//	LoadOptions.Devices = [2]     → pin to GPU 2
+//	LoadOptions.Devices = [0, 1]  → split across GPUs 0 and 1
+//	LoadOptions.Devices = nil     → auto: the single GPU with most free VRAM,
+//	                                else a split across all GPUs, else CPU
+//
+// Splits are proportional to each device's free VRAM. Reservations are held
+// per model until the pool unloads it, so per-device usage stays accurate
+// across loads and evictions.
+
+// Device is a GPU visible to the inference backend.
+type Device struct {
+	Index     int    `json:"index"`
+	Name      string `json:"name"`
+	VRAMTotal uint64 `json:"vram_total"` // bytes
+	VRAMUsed  uint64 `json:"vram_used"`  // bytes used outside TuTu at enumeration time
+}
+
+// DeviceUsage is a device plus TuTu's current reservations on it.
+type DeviceUsage struct {
+	Device
+	Reserved uint64   `json:"reserved"` // bytes reserved by loaded models
+	Models   []string `json:"models"`
+}
+
+// Free returns the bytes still available on the device.
+func (u DeviceUsage) Free() uint64 {
+	used := u.VRAMUsed + u.Reserved
+	if used >= u.VRAMTotal {
+		return 0
+	}
+	return u.VRAMTotal - used
+}
+
+// Placement records where a model was loaded.
+type Placement struct {
+	Devices []int     `json:"devices"`         // empty = CPU
+	Split   []float64 `json:"split,omitempty"` // fraction of weights per device (len == len(Devices))
+	Bytes   uint64    `json:"bytes"`
+	// VRAMFit is 0..1 — the share of the chosen devices' free VRAM the model
+	// consumes (1.0 = did not fit; fell back to CPU). Matches the scale of
+	// intelligence.Optimizer.SetVRAMFit.
+	VRAMFit float64 `json:"vram_fit"`
+}
+
+// OnGPU reports whether the model was placed on at least one GPU.
+func (p Placement) OnGPU() bool { return len(p.Devices) > 0 }
+
+// Processor returns a short description for `tutu ps` ("CPU", "GPU 0", "GPU 0,1").
+func (p Placement) Processor() string {
+	if !p.OnGPU() {
+		return "CPU"
+	}
+	ids := make([]string, len(p.Devices))
+	for i, d := range p.Devices {
+		ids[i] = strconv.Itoa(d)
+	}
+	return "GPU " + strings.Join(ids, ",")
+}
+
+// Weights returns the split as one weight per visible device, indexed by
+// device index and zero for devices the placement does not use — the form
+// llama-server's --tensor-split expects. visible is raised to cover every
+// placed device.
+func (p Placement) Weights(visible int) []float64 {
+	for _, d := range p.Devices {
+		visible = max(visible, d+1)
+	}
+	w := make([]float64, visible)
+	for i, d := range p.Devices {
+		if i < len(p.Split) {
+			w[d] = p.Split[i]
+		}
+	}
+	return w
+}
+
+// DeviceManager tracks per-device VRAM and assigns models to GPUs. Thread-safe.
+type DeviceManager struct {
+	mu          sync.Mutex
+	devices     []Device
+	reserved    map[int]uint64       // device index → bytes reserved
+	placements  map[string]Placement // model → placement
+	onPlacement func(model string, p Placement)
+}
+
+// NewDeviceManager creates a manager over a fixed device list. A nil or empty
+// list means CPU-only: every placement falls back to CPU.
+func NewDeviceManager(devices []Device) *DeviceManager {
+	sorted := append([]Device(nil), devices...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Index < sorted[j].Index })
+	return &DeviceManager{
+		devices:    sorted,
+		reserved:   make(map[int]uint64),
+		placements: make(map[string]Placement),
+	}
+}
+
+// OnPlacement installs a callback fired after every successful placement
+// (e.g. to feed VRAMFit into the intelligence optimizer).
+func (m *DeviceManager) OnPlacement(fn func(model string, p Placement)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onPlacement = fn
+}
+
+// Place reserves VRAM for a model of the given size. requested lists device
+// indices to use (nil = auto). Explicit requests for unknown devices or
+// devices without enough free VRAM return an error; auto placement never
+// fails and falls back to CPU.
+func (m *DeviceManager) Place(model string, bytes uint64, requested []int) (Placement, error) {
+	m.mu.Lock()
+	prev, hadPrev := m.placements[model]
+	if hadPrev {
+		m.releaseLocked(model, prev)
+	}
+
+	var p Placement
+	var err error
+	if len(requested) > 0 {
+		p, err = m.placeExplicitLocked(bytes, requested)
+	} else {
+		p = m.placeAutoLocked(bytes)
+	}
+	if err != nil {
+		if hadPrev {
+			m.reserveLocked(model, prev)
+		}
+		m.mu.Unlock()
+		return Placement{}, err
+	}
+	m.reserveLocked(model, p)
+	hook := m.onPlacement
+	m.mu.Unlock()
+
+	if hook != nil {
+		hook(model, p)
+	}
+	return p, nil
+}
+
+func (m *DeviceManager) reserveLocked(model string, p Placement) {
+	for i, d := range p.Devices {
+		m.reserved[d] += uint64(float64(p.Bytes) * p.Split[i])
+	}
+	m.placements[model] = p
+}
+
+// Release frees a model's reservation. Unknown models are ignored.
+func (m *DeviceManager) Release(model string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if p, ok := m.placements[model]; ok {
+		m.releaseLocked(model, p)
+	}
+}
+
+// PlacementOf returns the current placement of a model.
+func (m *DeviceManager) PlacementOf(model string) (Placement, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	p, ok := m.placements[model]
+	return p, ok
+}
+
+// Usage returns per-device VRAM accounting, ordered by device index.
+func (m *DeviceManager) Usage() []DeviceUsage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.usageLocked()
+}
+
+func (m *DeviceManager) usageLocked() []DeviceUsage {
+	out := make([]DeviceUsage, len(m.devices))
+	for i, d := range m.devices {
+		out[i] = DeviceUsage{Device: d, Reserved: m.reserved[d.Index]}
+	}
+	for model, p := range m.placements {
+		for _, idx := range p.Devices {
+			for i := range out {
+				if out[i].Index == idx {
+					out[i].Models = append(out[i].Models, model)
+				}
+			}
+		}
+	}
+	for i := range out {
+		sort.Strings(out[i].Models)
+	}
+	return out
+}
+
+func (m *DeviceManager) releaseLocked(model string, p Placement) {
+	for i, d := range p.Devices {
+		share := uint64(float64(p.Bytes) * p.Split[i])
+		if m.reserved[d] <= share {
+			delete(m.reserved, d)
+		} else {
+			m.reserved[d] -= share
+		}
+	}
+	delete(m.placements, model)
+}
+
+func (m *DeviceManager) placeAutoLocked(bytes uint64) Placement {
+	usage := m.usageLocked()
+
+	// Best single device: most free VRAM that still fits.
+	best := -1
+	for i, u := range usage {
+		if u.Free() >= bytes && (best < 0 || u.Free() > usage[best].Free()) {
+			best = i
+		}
+	}
+	if best >= 0 {
+		return Placement{
+			Devices: []int{usage[best].Index},
+			Split:   []float64{1},
+			Bytes:   bytes,
+			VRAMFit: fitScore(bytes, usage[best].Free()),
+		}
+	}
+
+	// Split across every device with free VRAM.
+	var chosen []DeviceUsage
+	var totalFree uint64
+	for _, u := range usage {
+		if u.Free() > 0 {
+			chosen = append(chosen, u)
+			totalFree += u.Free()
+		}
+	}
+	if len(chosen) > 1 && totalFree >= bytes {
+		return splitPlacement(chosen, bytes, totalFree)
+	}
+
+	return Placement{Bytes: bytes, VRAMFit: 1}
+}
+
+func (m *DeviceManager) placeExplicitLocked(bytes uint64, requested []int) (Placement, error) {
+	usage := m.usageLocked()
+	byIndex := make(map[int]DeviceUsage, len(usage))
+	for _, u := range usage {
+		byIndex[u.Index] = u
+	}
+
+	seen := make(map[int]bool)
+	var chosen []DeviceUsage
+	var totalFree uint64
+	for _, idx := range requested {
+		u, ok := byIndex[idx]
+		if !ok {
+			return Placement{}, fmt.Errorf("GPU %d not found", idx)
+		}
+		if seen[idx] {
+			continue
+		}
+		seen[idx] = true
+		chosen = append(chosen, u)
+		totalFree += u.Free()
+	}
+	if totalFree < bytes {
+		return Placement{}, fmt.Errorf("need %d bytes, GPUs %v have %d free", bytes, requested, totalFree)
+	}
+	if len(chosen) == 1 {
+		return Placement{
+			Devices: []int{chosen[0].Index},
+			Split:   []float64{1},
+			Bytes:   bytes,
+			VRAMFit: fitScore(bytes, totalFree),
+		}, nil
+	}
+	return splitPlacement(chosen, bytes, totalFree), nil
+}
+
+// splitPlacement distributes a model across devices proportionally to free VRAM.
+func splitPlacement(devices []DeviceUsage, bytes, totalFree uint64) Placement {
+	p := Placement{Bytes: bytes, VRAMFit: fitScore(bytes, totalFree)}
+	for _, u := range devices {
+		p.Devices = append(p.Devices, u.Index)
+		p.Split = append(p.Split, float64(u.Free())/float64(totalFree))
+	}
+	return p
+}
+
+// fitScore maps model size vs free VRAM to 0..1 (lower = better fit).
+func fitScore(bytes, free uint64) float64 {
+	if free == 0 {
+		return 1
+	}
+	f := float64(bytes) / float64(free)
+	if f > 1 {
+		return 1
+	}
+	return f
+}
+
+// ─── Enumeration ────────────────────────────────────────────────────────────
+
+// EnumerateGPUs lists NVIDIA GPUs via nvidia-smi. Returns an empty list (not
+// an error) when nvidia-smi is unavailable, so CPU-only hosts work unchanged.
+func EnumerateGPUs() ([]Device, error) {
+	bin, err := exec.LookPath("nvidia-smi")
+	if err != nil {
+		return nil, nil
+	}
+	out, err := exec.Command(bin,
+		"--query-gpu=index,name,memory.total,memory.used",
+		"--format=csv,noheader,nounits",
+	).Output()
+	if err != nil {
+		return nil, fmt.Errorf("nvidia-smi: %w", err)
+	}
+	return parseNvidiaSMI(string(out))
+}
+
+// parseNvidiaSMI parses `index, name, total MiB, used MiB` CSV lines.
+func parseNvidiaSMI(out string) ([]Device, error) {
+	const mib = 1024 * 1024
+	var devices []Device
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		fields := strings.Split(line, ",")
+		if len(fields) != 4 {
+			return nil, fmt.Errorf("parse nvidia-smi line %q: want 4 fields", line)
+		}
+		idx, err := strconv.Atoi(strings.TrimSpace(fields[0]))
+		if err != nil {
+			return nil, fmt.Errorf("parse GPU index: %w", err)
+		}
+		total, err := strconv.ParseUint(strings.TrimSpace(fields[2]), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parse GPU %d memory.total: %w", idx, err)
+		}
+		used, err := strconv.ParseUint(strings.TrimSpace(fields[3]), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parse GPU %d memory.used: %w", idx, err)
+		}
+		devices = append(devices, Device{
+			Index:     idx,
+			Name:      strings.TrimSpace(fields[1]),
+			VRAMTotal: total * mib,
+			VRAMUsed:  used * mib,
+		})
+	}
+	return devices, nil
+}
@@ -0,0 +1,413 @@
+package engine
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ─── KV-Cache Reuse ─────────────────────────────────────────────────────────
+// Every chat turn resends the whole conversation, and without reuse the
+// backend re-evaluates all of it before the first new token. llama-server
+// keeps a KV cache per slot (--parallel) and, with cache_prompt, skips the
+// longest prefix a request shares with what its slot last evaluated. So
+// the pool keeps each session on a slot of its own:
+//
+//   - a session's follow-up goes to the slot still holding its KV cache
+//   - with more sessions than slots, the least recently used session's
+//     cache is saved to disk (--slot-save-path) and restored into a slot
+//     when it returns; saved caches are bounded by BudgetBytes per model,
+//     least recently used deleted first
+//   - a new session goes to a slot last used with the same system prompt,
+//     so the common prefix is already evaluated (PrefixKey)
+//
+// Backends report prompt tokens evaluated and reused per generation; the
+// KVCacheTracker turns them into hit rates and an estimate of the prompt
+// time saved.
+
+// KVCacheConfig configures KV-cache reuse for subsequent model loads.
+type KVCacheConfig struct {
+	Slots       int    // parallel sequences per model, each with its own KV cache (default 4; 1 = no session pinning)
+	SaveDir     string // where evicted sessions' caches are saved ("" = dropped instead)
+	BudgetBytes int64  // disk budget for saved caches, per model (default 2 GiB)
+}
+
+// DefaultKVCacheConfig returns KV-cache defaults.
+func DefaultKVCacheConfig() KVCacheConfig {
+	return KVCacheConfig{Slots: 4, BudgetBytes: 2 << 30}
+}
+
+// KVOutcome classifies how much of a request's KV cache was warm.
+type KVOutcome string
+
+const (
+	KVResident KVOutcome = "resident" // the session's slot still held its cache
+	KVRestored KVOutcome = "restored" // the session's cache was restored from disk
+	KVPrefix   KVOutcome = "prefix"   // new to its slot, which held the same system prompt
+	KVCold     KVOutcome = "cold"     // nothing reusable
+)
+
+// SetKVCache configures KV-cache reuse for models loaded after this call.
+func (p *Pool) SetKVCache(cfg KVCacheConfig) {
+	def := DefaultKVCacheConfig()
+	if cfg.Slots <= 0 {
+		cfg.Slots = def.Slots
+	}
+	if cfg.BudgetBytes <= 0 {
+		cfg.BudgetBytes = def.BudgetBytes
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.kvcache = &cfg
+}
+
+// KVCache returns the tracker holding per-model cache statistics.
+func (p *Pool) KVCache() *KVCacheTracker {
+	return p.kvStats
+}
+
+// unsafeFileChars are replaced in model names used as directory names.
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+// applyKVCacheLocked fills the slot fields of opts.
+func (p *Pool) applyKVCacheLocked(name string, opts *LoadOptions) {
+	if p.kvcache == nil || opts.Slots != 0 {
+		return
+	}
+	opts.Slots = p.kvcache.Slots
+	opts.SlotBudgetBytes = p.kvcache.BudgetBytes
+	if p.kvcache.SaveDir != "" {
+		opts.SlotSaveDir = filepath.Join(p.kvcache.SaveDir, unsafeFileChars.ReplaceAllString(name, "_"))
+	}
+}
+
+// PrefixKey identifies a conversation's system prompt: the leading system
+// messages. Conversations with the same key share a reusable prefix. It is
+// "" when there is no system message.
+func PrefixKey(messages []ChatMessage) string {
+	h := sha256.New()
+	n := 0
+	for _, m := range messages {
+		if m.Role != "system" {
+			break
+		}
+		h.Write([]byte(m.Content))
+		h.Write([]byte{0})
+		n++
+	}
+	if n == 0 {
+		return ""
+	}
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
+
+// ─── Session Slots ──────────────────────────────────────────────────────────
+
+// SlotStore saves and restores slot KV caches, e.g. through llama-server's
+// /slots API.
+type SlotStore interface {
+	Save(slot int, file string) (bytes int64, err error)
+	Restore(slot int, file string) error
+	Delete(file string)
+}
+
+// SlotAssignment is where a request runs.
+type SlotAssignment struct {
+	Slot    int // -1 = let the backend choose
+	Outcome KVOutcome
+}
+
+type slotState struct {
+	session  string // "" = free
+	prefix   string // PrefixKey of what the slot last evaluated
+	lastUsed time.Time
+}
+
+type savedCache struct {
+	file     string
+	bytes    int64
+	lastUsed time.Time
+}
+
+// SessionCache assigns sessions to slots. Store calls run under its lock,
+// so assignments for one model are serialized; they are local disk writes.
+type SessionCache struct {
+	mu     sync.Mutex
+	slots  []slotState
+	saved  map[string]savedCache // session → saved cache
+	used   int64                 // bytes of saved caches
+	budget int64
+	store  SlotStore // nil = caches are not saved
+	now    func() time.Time
+}
+
+// NewSessionCache creates a cache over n slots. A nil store drops evicted
+// sessions' caches instead of saving them.
+func NewSessionCache(n int, budgetBytes int64, store SlotStore) *SessionCache {
+	return &SessionCache{
+		slots:  make([]slotState, n),
+		saved:  make(map[string]savedCache),
+		budget: budgetBytes,
+		store:  store,
+		now:    time.Now,
+	}
+}
+
+// Assign picks the slot for a request of session whose system prompt has
+// PrefixKey prefix. Without a session the request is not pinned, but a
+// slot holding the same prefix is still preferred.
+func (c *SessionCache) Assign(session, prefix string) SlotAssignment {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+
+	if session != "" {
+		for i := range c.slots {
+			if c.slots[i].session == session {
+				c.slots[i].lastUsed = now
+				c.slots[i].prefix = prefix
+				return SlotAssignment{Slot: i, Outcome: KVResident}
+			}
+		}
+	}
+
+	slot := c.victimLocked(prefix)
+	s := &c.slots[slot]
+	outcome := KVCold
+	if prefix != "" && s.prefix == prefix {
+		outcome = KVPrefix
+	}
+	if session == "" {
+		// Borrowing another session's slot would overwrite its cache.
+		if outcome == KVCold || s.session != "" {
+			return SlotAssignment{Slot: -1, Outcome: KVCold}
+		}
+		s.lastUsed = now
+		return SlotAssignment{Slot: slot, Outcome: outcome}
+	}
+
+	// Take the session's own saved cache out of the budget first, so that
+	// saving the evicted occupant cannot delete it.
+	sc, returning := c.saved[session]
+	if returning {
+		delete(c.saved, session)
+		c.used -= sc.bytes
+	}
+	if s.session != "" {
+		c.saveLocked(slot, now)
+	}
+	if returning && c.store != nil {
+		if err := c.store.Restore(slot, sc.file); err == nil {
+			outcome = KVRestored
+		}
+		c.store.Delete(sc.file)
+	}
+	*s = slotState{session: session, prefix: prefix, lastUsed: now}
+	return SlotAssignment{Slot: slot, Outcome: outcome}
+}
+
+// Forget drops a session's slot binding and saved cache.
+func (c *SessionCache) Forget(session string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i := range c.slots {
+		if c.slots[i].session == session {
+			c.slots[i].session = ""
+		}
+	}
+	if sc, ok := c.saved[session]; ok {
+		delete(c.saved, session)
+		c.used -= sc.bytes
+		if c.store != nil {
+			c.store.Delete(sc.file)
+		}
+	}
+}
+
+// SavedBytes returns the bytes of saved caches.
+func (c *SessionCache) SavedBytes() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.used
+}
+
+// victimLocked picks the slot for a new occupant: a free slot holding
+// prefix, the least recently used slot holding it, any free slot, else
+// the least recently used slot.
+func (c *SessionCache) victimLocked(prefix string) int {
+	best, bestRank := 0, 4
+	for i, s := range c.slots {
+		rank := 3
+		switch {
+		case prefix != "" && s.prefix == prefix && s.session == "":
+			rank = 0
+		case prefix != "" && s.prefix == prefix:
+			rank = 1
+		case s.session == "":
+			rank = 2
+		}
+		if rank < bestRank || rank == bestRank && s.lastUsed.Before(c.slots[best].lastUsed) {
+			best, bestRank = i, rank
+		}
+	}
+	return best
+}
+
+// saveLocked saves the cache of slot's session, then deletes the least
+// recently used saved caches until the budget holds.
+func (c *SessionCache) saveLocked(slot int, now time.Time) {
+	session := c.slots[slot].session
+	if c.store == nil || c.budget <= 0 {
+		return
+	}
+	sum := sha256.Sum256([]byte(session))
+	file := hex.EncodeToString(sum[:8]) + ".bin"
+	n, err := c.store.Save(slot, file)
+	if err != nil || n > c.budget {
+		if err == nil {
+			c.store.Delete(file)
+		}
+		return
+	}
+	c.saved[session] = savedCache{file: file, bytes: n, lastUsed: now}
+	c.used += n
+
+	if c.used <= c.budget {
+		return
+	}
+	sessions := make([]string, 0, len(c.saved))
+	for s := range c.saved {
+		sessions = append(sessions, s)
+	}
+	sort.Slice(sessions, func(i, j int) bool { return c.saved[sessions[i]].lastUsed.Before(c.saved[sessions[j]].lastUsed) })
+	for _, s := range sessions {
+		if c.used <= c.budget {
+			break
+		}
+		sc := c.saved[s]
+		delete(c.saved, s)
+		c.used -= sc.bytes
+		c.store.Delete(sc.file)
+	}
+}
+
+// ─── Statistics ────────────────────────────────────────────────────────────
+
+// KVCacheStats summarizes KV-cache reuse for one model.
+type KVCacheStats struct {
+	Model           string              `json:"model"`
+	Generations     int64               `json:"generations"`
+	Outcomes        map[KVOutcome]int64 `json:"outcomes"`
+	PromptTokens    int64               `json:"prompt_tokens"`     // prompt tokens evaluated
+	CachedTokens    int64               `json:"cached_tokens"`     // prompt tokens reused from the cache
+	HitRate         float64             `json:"hit_rate"`          // CachedTokens / all prompt tokens
+	PromptSavedSecs float64             `json:"prompt_saved_secs"` // estimated prompt evaluation time saved
+}
+
+type kvCounters struct {
+	generations int64
+	outcomes    map[KVOutcome]int64
+	prompt      int64
+	cached      int64
+	promptTime  time.Duration // time spent evaluating prompt tokens
+}
+
+// savedSecs estimates the time the cached tokens would have taken at the
+// observed prompt evaluation rate.
+func (c *kvCounters) savedSecs() float64 {
+	if c.prompt == 0 {
+		return 0
+	}
+	return c.promptTime.Seconds() / float64(c.prompt) * float64(c.cached)
+}
+
+// KVCacheTracker aggregates KV-cache reuse per model. Safe for concurrent
+// use.
+type KVCacheTracker struct {
+	mu       sync.Mutex
+	models   map[string]*kvCounters
+	onRecord func(model string, t GenerationTimings)
+}
+
+// NewKVCacheTracker creates an empty tracker.
+func NewKVCacheTracker() *KVCacheTracker {
+	return &KVCacheTracker{models: make(map[string]*kvCounters)}
+}
+
+// OnRecord installs a callback fired for every recorded generation
+// (e.g. to feed Prometheus counters).
+func (t *KVCacheTracker) OnRecord(fn func(model string, g GenerationTimings)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.onRecord = fn
+}
+
+// Record adds one generation's prompt timings. Generations without prompt
+// timings are ignored.
+func (t *KVCacheTracker) Record(model string, g GenerationTimings) {
+	if g.PromptTokens == 0 && g.CachedTokens == 0 {
+		return
+	}
+	t.mu.Lock()
+	c, ok := t.models[model]
+	if !ok {
+		c = &kvCounters{outcomes: make(map[KVOutcome]int64)}
+		t.models[model] = c
+	}
+	c.generations++
+	if g.KVOutcome != "" {
+		c.outcomes[g.KVOutcome]++
+	}
+	c.prompt += int64(g.PromptTokens)
+	c.cached += int64(g.CachedTokens)
+	c.promptTime += g.PromptElapsed
+	hook := t.onRecord
+	t.mu.Unlock()
+
+	if hook != nil {
+		hook(model, g)
+	}
+}
+
+// Stats returns KV-cache statistics for a model.
+func (t *KVCacheTracker) Stats(model string) KVCacheStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.statsLocked(model)
+}
+
+// AllStats returns statistics for every model with recorded generations,
+// sorted by model.
+func (t *KVCacheTracker) AllStats() []KVCacheStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	out := make([]KVCacheStats, 0, len(t.models))
+	for model := range t.models {
+		out = append(out, t.statsLocked(model))
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Model < out[j].Model })
+	return out
+}
+
+func (t *KVCacheTracker) statsLocked(model string) KVCacheStats {
+	s := KVCacheStats{Model: model, Outcomes: map[KVOutcome]int64{}}
+	c, ok := t.models[model]
+	if !ok {
+		return s
+	}
+	s.Generations = c.generations
+	for o, n := range c.outcomes {
+		s.Outcomes[o] = n
+	}
+	s.PromptTokens = c.prompt
+	s.CachedTokens = c.cached
+	if total := c.prompt + c.cached; total > 0 {
+		s.HitRate = float64(c.cached) / float64(total)
+	}
+	s.PromptSavedSecs = c.savedSecs()
+	return s
+}
@@ -0,0 +1,211 @@
+package engine
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/tutu-network/tutu/internal/domain"
+)
+
+// ─── Generation Parameter Guardrails ────────────────────────────────────────
+// API handlers decode caller-supplied sampling parameters into a ParamRequest
+// (nil = not specified) and pass it through ParamGuard.Resolve, which fills
+// server-side defaults and enforces global and per-model limits. A top_p
+// sent with temperature 0 is accepted, as OpenAI and Ollama do; greedy
+// decoding ignores it. The resulting GenerateParams reach the backend
+// unchanged.
+
+// Parameter bounds shared by every model.
+const (
+	MinTemperature = 0.0
+	MaxTemperature = 2.0
+	MinLogitBias   = -100.0
+	MaxLogitBias   = 100.0
+)
+
+// ParamRequest holds caller-supplied sampling parameters. Nil fields take
+// server-side defaults.
+type ParamRequest struct {
+	Temperature *float32
+	TopP        *float32
+	MaxTokens   *int
+	Stop        []string
+	Seed        *int64
+	LogitBias   map[string]float32 // token ID (decimal string) → bias
+}
+
+// ParamLimits caps what callers may request. Zero fields are unlimited.
+type ParamLimits struct {
+	MaxTokens     int // upper bound on max_tokens (typically ≤ context window)
+	MaxStop       int // maximum number of stop sequences
+	MaxStopLength int // maximum bytes per stop sequence
+	MaxLogitBias  int // maximum logit_bias entries
+}
+
+// DefaultParamLimits returns conservative limits matching the OpenAI API.
+func DefaultParamLimits() ParamLimits {
+	return ParamLimits{
+		MaxTokens:     4096,
+		MaxStop:       4,
+		MaxStopLength: 256,
+		MaxLogitBias:  300,
+	}
+}
+
+// ParamError is a structured validation failure for a single parameter.
+// It unwraps to domain.ErrInvalidParameter.
+type ParamError struct {
+	Param   string // request field name, e.g. "temperature"
+	Message string
+}
+
+func (e *ParamError) Error() string { return e.Param + ": " + e.Message }
+
+// Unwrap lets callers match with errors.Is(err, domain.ErrInvalidParameter).
+func (e *ParamError) Unwrap() error { return domain.ErrInvalidParameter }
+
+func paramErr(param, format string, args ...any) error {
+	return &ParamError{Param: param, Message: fmt.Sprintf(format, args...)}
+}
+
+// ParamGuard applies defaults and limits to generation requests. Thread-safe.
+type ParamGuard struct {
+	mu       sync.RWMutex
+	defaults GenerateParams
+	limits   ParamLimits
+	perModel map[string]ParamLimits
+}
+
+// NewParamGuard creates a guard with server-side defaults and global limits.
+func NewParamGuard(defaults GenerateParams, limits ParamLimits) *ParamGuard {
+	return &ParamGuard{
+		defaults: defaults,
+		limits:   limits,
+		perModel: make(map[string]ParamLimits),
+	}
+}
+
+// DefaultParamGuard returns a guard with TuTu's standard defaults.
+func DefaultParamGuard() *ParamGuard {
+	return NewParamGuard(GenerateParams{
+		Temperature: 0.7,
+		TopP:        0.9,
+		MaxTokens:   2048,
+	}, DefaultParamLimits())
+}
+
+// SetModelLimits overrides limits for one model. Zero fields inherit the
+// global limit.
+func (g *ParamGuard) SetModelLimits(model string, l ParamLimits) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.perModel[model] = l
+}
+
+// Defaults returns the server-side default parameters.
+func (g *ParamGuard) Defaults() GenerateParams {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.defaults
+}
+
+// LimitsFor returns the effective limits for a model.
+func (g *ParamGuard) LimitsFor(model string) ParamLimits {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	l := g.limits
+	if m, ok := g.perModel[model]; ok {
+		if m.MaxTokens > 0 {
+			l.MaxTokens = m.MaxTokens
+		}
+		if m.MaxStop > 0 {
+			l.MaxStop = m.MaxStop
+		}
+		if m.MaxStopLength > 0 {
+			l.MaxStopLength = m.MaxStopLength
+		}
+		if m.MaxLogitBias > 0 {
+			l.MaxLogitBias = m.MaxLogitBias
+		}
+	}
+	return l
+}
+
+// Resolve merges req over the defaults and validates the result against the
+// model's limits. Errors are *ParamError.
+func (g *ParamGuard) Resolve(model string, req ParamRequest) (GenerateParams, error) {
+	p := g.Defaults()
+	lim := g.LimitsFor(model)
+
+	if req.Temperature != nil {
+		t := *req.Temperature
+		if t < MinTemperature || t > MaxTemperature {
+			return GenerateParams{}, paramErr("temperature", "must be between %g and %g, got %g", MinTemperature, MaxTemperature, t)
+		}
+		p.Temperature = t
+	}
+
+	if req.TopP != nil {
+		tp := *req.TopP
+		if tp <= 0 || tp > 1 {
+			return GenerateParams{}, paramErr("top_p", "must be in (0, 1], got %g", tp)
+		}
+		p.TopP = tp
+	}
+
+	if req.MaxTokens != nil {
+		n := *req.MaxTokens
+		if n < 1 {
+			return GenerateParams{}, paramErr("max_tokens", "must be at least 1, got %d", n)
+		}
+		if lim.MaxTokens > 0 && n > lim.MaxTokens {
+			return GenerateParams{}, paramErr("max_tokens", "exceeds the limit of %d for model %s", lim.MaxTokens, model)
+		}
+		p.MaxTokens = n
+	} else if lim.MaxTokens > 0 && p.MaxTokens > lim.MaxTokens {
+		p.MaxTokens = lim.MaxTokens // clamp defaults to small-context models
+	}
+
+	if len(req.Stop) > 0 {
+		if lim.MaxStop > 0 && len(req.Stop) > lim.MaxStop {
+			return GenerateParams{}, paramErr("stop", "at most %d sequences allowed, got %d", lim.MaxStop, len(req.Stop))
+		}
+		for i, s := range req.Stop {
+			if s == "" {
+				return GenerateParams{}, paramErr("stop", "sequence %d is empty", i)
+			}
+			if lim.MaxStopLength > 0 && len(s) > lim.MaxStopLength {
+				return GenerateParams{}, paramErr("stop", "sequence %d exceeds %d bytes", i, lim.MaxStopLength)
+			}
+		}
+		p.Stop = append([]string(nil), req.Stop...)
+	}
+
+	if req.Seed != nil {
+		if *req.Seed < 0 {
+			return GenerateParams{}, paramErr("seed", "must be non-negative, got %d", *req.Seed)
+		}
+		seed := *req.Seed
+		p.Seed = &seed
+	}
+
+	if len(req.LogitBias) > 0 {
+		if lim.MaxLogitBias > 0 && len(req.LogitBias) > lim.MaxLogitBias {
+			return GenerateParams{}, paramErr("logit_bias", "at most %d entries allowed, got %d", lim.MaxLogitBias, len(req.LogitBias))
+		}
+		p.LogitBias = make(map[int]float32, len(req.LogitBias))
+		for k, v := range req.LogitBias {
+			id, err := strconv.Atoi(k)
+			if err != nil || id < 0 {
+				return GenerateParams{}, paramErr("logit_bias", "key %q is not a token ID", k)
+			}
+			if v < MinLogitBias || v > MaxLogitBias {
+				return GenerateParams{}, paramErr("logit_bias", "bias for token %d must be between %g and %g, got %g", id, MinLogitBias, MaxLogitBias, v)
+			}
+			p.LogitBias[id] = v
+		}
+	}
+
+	return p, nil
+}
@@ -7,6 +7,7 @@ import (
 	"container/list"
 	"context"
 	"fmt"
+	"os"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -44,6 +45,24 @@ type LoadOptions struct {
 	NumGPULayers int // -1 = auto, 0 = CPU only, N = specific
 	NumCtx       int // Context window size (default 4096)
 	NumThreads   int // 0 = auto (runtime.NumCPU())
+
+	// Device placement. Devices is the caller's request (nil = auto); the
+	// pool fills MainGPU and TensorSplit from the DeviceManager's decision.
+	Devices     []int
+	MainGPU     int
+	TensorSplit []float64 // weight per visible device, 0 = unused; nil = not placed
+
+	// Speculative decoding. The pool fills these from SetSpeculative;
+	// DraftModelPath == "" disables drafting.
+	DraftModelPath string
+	DraftMax       int // max drafted tokens per verification pass
+	DraftMin       int // min drafted tokens per pass
+
+	// KV-cache reuse. The pool fills these from SetKVCache; Slots <= 1
+	// disables session pinning.
+	Slots           int    // parallel sequences, each with its own KV cache
+	SlotSaveDir     string // where evicted sessions' caches are saved ("" = dropped)
+	SlotBudgetBytes int64  // disk budget for saved caches
 }
 
 // GenerateParams holds sampling parameters.
@@ -52,6 +71,10 @@ type GenerateParams struct {
 	TopP        float32
 	MaxTokens   int
 	Stop        []string
+	Seed        *int64          // nil = random
+	LogitBias   map[int]float32 // token ID → additive logit bias
+	SessionID   string          // pins a conversation to its KV cache ("" = unpinned)
+	NoDraft     bool            // decode without the draft model, even if one is loaded
 }
 
 // ─── Model Pool (LRU + Reference Counting) ──────────────────────────────────
@@ -69,21 +92,37 @@ type Pool struct {
 	resolver     func(name string) (string, error) // name → file path
 	idleTimeout  time.Duration
 	reapInterval time.Duration
+	devices      *DeviceManager               // nil = no device placement (backend decides)
+	speculative  map[string]SpeculativeConfig // target model → draft pairing
+	specStats    *SpeculativeTracker
+	kvcache      *KVCacheConfig // nil = backend defaults
+	kvStats      *KVCacheTracker
+	stream       StreamConfig // token relay settings for PoolHandle streams
+	guard        Guard        // wraps model loads and generation starts (nil = direct)
 }
 
+// Guard wraps a backend call for a model, e.g. with a circuit breaker. It
+// returns call's error, or refuses without running call.
+type Guard func(model string, call func() error) error
+
 type poolEntry struct {
-	handle   ModelHandle
-	name     string
-	memBytes uint64
-	refCount int32
-	element  *list.Element
-	lastUsed time.Time
+	handle    ModelHandle
+	name      string
+	memBytes  uint64
+	refCount  int32
+	element   *list.Element
+	lastUsed  time.Time
+	placement Placement
 }
 
 // PoolHandle is returned by Acquire. Caller MUST call Release() (use defer).
 type PoolHandle struct {
 	entry *poolEntry
 	pool  *Pool
+
+	mu       sync.Mutex // protects released, streams
+	released bool
+	streams  []*TokenStream
 }
 
 // NewPool creates a model pool with bounded memory.
@@ -96,7 +135,39 @@ func NewPool(backend InferenceBackend, maxMemBytes uint64, resolver func(string)
 		resolver:     resolver,
 		idleTimeout:  5 * time.Minute,
 		reapInterval: 30 * time.Second,
+		speculative:  make(map[string]SpeculativeConfig),
+		specStats:    NewSpeculativeTracker(),
+		kvStats:      NewKVCacheTracker(),
+		stream:       DefaultStreamConfig(),
+	}
+}
+
+// SetDeviceManager enables explicit GPU placement for subsequent loads.
+func (p *Pool) SetDeviceManager(dm *DeviceManager) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.devices = dm
+}
+
+// SetGuard routes model loads and generation starts through g.
+func (p *Pool) SetGuard(g Guard) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.guard = g
+}
+
+// callGuard returns the guard, or a pass-through when none is set.
+func (p *Pool) callGuard() Guard {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.guardLocked()
+}
+
+func (p *Pool) guardLocked() Guard {
+	if p.guard == nil {
+		return func(_ string, call func() error) error { return call() }
 	}
+	return p.guard
 }
 
 // Acquire loads or retrieves a cached model. Returns a handle with ref count.
@@ -119,11 +190,32 @@ func (p *Pool) Acquire(name string, opts LoadOptions) (*PoolHandle, error) {
 		return nil, fmt.Errorf("resolve model %q: %w", name, err)
 	}
 
+	// Choose devices before loading so the backend can honor the split
+	placement, err := p.placeLocked(name, path, &opts)
+	if err != nil {
+		return nil, err
+	}
+
+	p.applySpeculativeLocked(name, &opts)
+	p.applyKVCacheLocked(name, &opts)
+
 	// Load model
-	handle, err := p.backend.LoadModel(path, opts)
+	var handle ModelHandle
+	err = p.guardLocked()(name, func() error {
+		var loadErr error
+		handle, loadErr = p.backend.LoadModel(path, opts)
+		return loadErr
+	})
 	if err != nil {
+		p.releaseDevicesLocked(name)
 		return nil, fmt.Errorf("load model %q: %w", name, err)
 	}
+	if tr, ok := handle.(TimingsReporter); ok {
+		tr.SetTimingsHook(func(g GenerationTimings) {
+			p.specStats.Record(name, g)
+			p.kvStats.Record(name, g)
+		})
+	}
 
 	memNeeded := handle.MemoryBytes()
 
@@ -131,16 +223,18 @@ func (p *Pool) Acquire(name string, opts LoadOptions) (*PoolHandle, error) {
 	for p.usedMem+memNeeded > p.maxMem && p.lru.Len() > 0 {
 		if !p.evictOne() {
 			handle.Close()
+			p.releaseDevicesLocked(name)
 			return nil, domain.ErrPoolExhausted
 		}
 	}
 
 	entry := &poolEntry{
-		handle:   handle,
-		name:     name,
-		memBytes: memNeeded,
-		refCount: 1,
-		lastUsed: time.Now(),
+		handle:    handle,
+		name:      name,
+		memBytes:  memNeeded,
+		refCount:  1,
+		lastUsed:  time.Now(),
+		placement: placement,
 	}
 	entry.element = p.lru.PushFront(entry)
 	p.models[name] = entry
@@ -149,6 +243,43 @@ func (p *Pool) Acquire(name string, opts LoadOptions) (*PoolHandle, error) {
 	return &PoolHandle{entry: entry, pool: p}, nil
 }
 
+// placeLocked asks the DeviceManager where to load a model, sized by its
+// weights file, and writes the decision into opts.
+func (p *Pool) placeLocked(name, path string, opts *LoadOptions) (Placement, error) {
+	if p.devices == nil {
+		return Placement{}, nil
+	}
+	var size uint64
+	if fi, err := os.Stat(path); err == nil {
+		size = uint64(fi.Size())
+	}
+	placement, err := p.devices.Place(name, size, opts.Devices)
+	if err != nil {
+		return Placement{}, fmt.Errorf("place model %q: %w", name, err)
+	}
+	switch {
+	case !placement.OnGPU():
+		if len(p.devices.Usage()) > 0 {
+			opts.NumGPULayers = 0 // GPUs exist but none can hold the model
+		}
+	default:
+		visible := 0
+		for _, u := range p.devices.Usage() {
+			visible = max(visible, u.Index+1)
+		}
+		opts.MainGPU = placement.Devices[0]
+		opts.TensorSplit = placement.Weights(visible)
+	}
+	return placement, nil
+}
+
+// releaseDevicesLocked frees a model's VRAM reservation, if any.
+func (p *Pool) releaseDevicesLocked(name string) {
+	if p.devices != nil {
+		p.devices.Release(name)
+	}
+}
+
 // evictOne removes the least-recently-used model with refCount == 0.
 func (p *Pool) evictOne() bool {
 	for e := p.lru.Back(); e != nil; e = e.Prev() {
@@ -158,6 +289,7 @@ func (p *Pool) evictOne() bool {
 			p.lru.Remove(e)
 			delete(p.models, entry.name)
 			p.usedMem -= entry.memBytes
+			p.releaseDevicesLocked(entry.name)
 			return true
 		}
 	}
@@ -167,11 +299,33 @@ func (p *Pool) evictOne() bool {
 // Model returns the underlying model handle.
 func (h *PoolHandle) Model() ModelHandle { return h.entry.handle }
 
-// Release decrements the reference count. Must be called when done.
+// Release cancels any streams still running on this handle and decrements
+// the reference count. Must be called when done; extra calls are no-ops.
 func (h *PoolHandle) Release() {
+	h.mu.Lock()
+	if h.released {
+		h.mu.Unlock()
+		return
+	}
+	h.released = true
+	streams := h.streams
+	h.streams = nil
+	h.mu.Unlock()
+
+	for _, s := range streams {
+		s.Cancel()
+	}
 	atomic.AddInt32(&h.entry.refCount, -1)
 }
 
+// IsLoaded reports whether name is loaded, i.e. Acquire would not load it.
+func (p *Pool) IsLoaded(name string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, ok := p.models[name]
+	return ok
+}
+
 // LoadedModels returns info about all models currently in the pool.
 func (p *Pool) LoadedModels() []domain.LoadedModel {
 	p.mu.Lock()
@@ -179,7 +333,7 @@ func (p *Pool) LoadedModels() []domain.LoadedModel {
 
 	result := make([]domain.LoadedModel, 0, len(p.models))
 	for name, entry := range p.models {
-		processor := "CPU"
+		processor := entry.placement.Processor()
 		result = append(result, domain.LoadedModel{
 			Name:      name,
 			SizeBytes: int64(entry.memBytes),
@@ -190,6 +344,24 @@ func (p *Pool) LoadedModels() []domain.LoadedModel {
 	return result
 }
 
+// Refs returns how many handles currently hold model — the requests in
+// flight on it. An unloaded model has none.
+func (p *Pool) Refs(name string) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if entry, ok := p.models[name]; ok {
+		return int(atomic.LoadInt32(&entry.refCount))
+	}
+	return 0
+}
+
+// MemoryUsage returns the bytes held by loaded models and the pool budget.
+func (p *Pool) MemoryUsage() (used, max uint64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.usedMem, p.maxMem
+}
+
 // UnloadAll releases all models from the pool.
 func (p *Pool) UnloadAll() error {
 	p.mu.Lock()
@@ -199,6 +371,7 @@ func (p *Pool) UnloadAll() error {
 		entry.handle.Close()
 		p.lru.Remove(entry.element)
 		delete(p.models, name)
+		p.releaseDevicesLocked(name)
 	}
 	p.usedMem = 0
 	return nil
@@ -222,6 +395,7 @@ func (p *Pool) IdleReaper(ctx context.Context) {
 					p.lru.Remove(entry.element)
 					delete(p.models, name)
 					p.usedMem -= entry.memBytes
+					p.releaseDevicesLocked(name)
 				}
 			}
 			p.mu.Unlock()
@@ -2,9 +2,17 @@ package engine
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
 	"sync"
 	"testing"
 	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
 )
 
 // ─── Mock Backend Tests ─────────────────────────────────────────────────────
@@ -275,3 +283,921 @@ func TestPool_GenerateThroughHandle(t *testing.T) {
 		t.Error("should generate at least one token")
 	}
 }
+
+func TestPool_Benchmark(t *testing.T) {
+	pool := newTestPool()
+
+	b, err := pool.Benchmark(context.Background(), "bench-model", BenchmarkConfig{MaxTokens: 4, Runs: 3})
+	if err != nil {
+		t.Fatalf("Benchmark() error: %v", err)
+	}
+	if b.Model != "bench-model" || b.Runs != 3 || b.Tokens != 4 {
+		t.Errorf("benchmark = %+v, want 3 runs of 4 tokens", b)
+	}
+	if b.TokensPerSec <= 0 || b.TTFTMs < 0 || b.MeasuredAt.IsZero() {
+		t.Errorf("benchmark = %+v, want positive throughput", b)
+	}
+	if len(pool.LoadedModels()) != 1 {
+		t.Error("benchmark should leave the model loaded")
+	}
+}
+
+func TestMedian(t *testing.T) {
+	if m := median([]float64{5, 1, 3}); m != 3 {
+		t.Errorf("median odd = %g, want 3", m)
+	}
+	if m := median([]float64{4, 1, 3, 2}); m != 2.5 {
+		t.Errorf("median even = %g, want 2.5", m)
+	}
+}
+
+func TestPool_Guard(t *testing.T) {
+	pool := newTestPool()
+	refuse := errors.New("refused")
+	var calls []string
+	blocked := map[string]bool{"blocked": true}
+	pool.SetGuard(func(model string, call func() error) error {
+		calls = append(calls, model)
+		if blocked[model] {
+			return refuse
+		}
+		return call()
+	})
+
+	if _, err := pool.Acquire("blocked", LoadOptions{}); !errors.Is(err, refuse) {
+		t.Fatalf("Acquire(blocked) err = %v, want refused", err)
+	}
+	h, err := pool.Acquire("ok", LoadOptions{})
+	if err != nil {
+		t.Fatalf("Acquire(ok) error: %v", err)
+	}
+	defer h.Release()
+
+	blocked["ok"] = true // e.g. the breaker opened after the load
+	if _, err := h.Generate(context.Background(), "hi", GenerateParams{MaxTokens: 1}); !errors.Is(err, refuse) {
+		t.Errorf("Generate err = %v, want refused", err)
+	}
+	if len(calls) != 3 || calls[0] != "blocked" || calls[1] != "ok" || calls[2] != "ok" {
+		t.Errorf("guard calls = %v", calls)
+	}
+}
+
+// ─── Batcher Tests ──────────────────────────────────────────────────────────
+
+// countingBackend wraps MockBackend and counts backend calls per handle.
+type countingBackend struct {
+	mu         sync.Mutex
+	embedCalls int
+}
+
+func (c *countingBackend) LoadModel(path string, opts LoadOptions) (ModelHandle, error) {
+	h, err := NewMockBackend().LoadModel(path, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &countingHandle{MockModelHandle: h.(*MockModelHandle), backend: c}, nil
+}
+
+func (c *countingBackend) Close() {}
+
+type countingHandle struct {
+	*MockModelHandle
+	backend *countingBackend
+}
+
+func (h *countingHandle) Embed(ctx context.Context, input []string) ([][]float32, error) {
+	h.backend.mu.Lock()
+	h.backend.embedCalls++
+	h.backend.mu.Unlock()
+	return h.MockModelHandle.Embed(ctx, input)
+}
+
+func newTestBatcher(t *testing.T, backend InferenceBackend, cfg BatchConfig) *Batcher {
+	t.Helper()
+	resolver := func(name string) (string, error) { return "/models/" + name, nil }
+	pool := NewPool(backend, 1<<40, resolver)
+	return NewBatcher(pool, cfg)
+}
+
+func TestBatcher_Embed_CoalescesConcurrentRequests(t *testing.T) {
+	backend := &countingBackend{}
+	b := newTestBatcher(t, backend, BatchConfig{MaxBatchSize: 4, MaxDelay: time.Second})
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 4)
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(n int) {
+			defer wg.Done()
+			inputs := make([]string, n+1)
+			vecs, err := b.Embed(context.Background(), "embedder", LoadOptions{}, inputs)
+			if err == nil && len(vecs) != n+1 {
+				err = fmt.Errorf("got %d vectors, want %d", len(vecs), n+1)
+			}
+			errs <- err
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if backend.embedCalls != 1 {
+		t.Errorf("backend Embed calls = %d, want 1 (full batch)", backend.embedCalls)
+	}
+	s := b.Stats()
+	if s.Requests != 4 || s.Batches != 1 || s.SavedCalls != 3 || s.FullFlushes != 1 {
+		t.Errorf("stats = %+v", s)
+	}
+	if s.AvgBatchSize != 4 {
+		t.Errorf("AvgBatchSize = %v, want 4", s.AvgBatchSize)
+	}
+}
+
+func TestBatcher_FlushesAfterMaxDelay(t *testing.T) {
+	var observed []int
+	var mu sync.Mutex
+	cfg := BatchConfig{
+		MaxBatchSize: 16,
+		MaxDelay:     5 * time.Millisecond,
+		Observe: func(model string, size int, wait time.Duration) {
+			mu.Lock()
+			observed = append(observed, size)
+			mu.Unlock()
+		},
+	}
+	b := newTestBatcher(t, &countingBackend{}, cfg)
+
+	if _, err := b.Embed(context.Background(), "embedder", LoadOptions{}, []string{"solo"}); err != nil {
+		t.Fatalf("Embed: %v", err)
+	}
+	s := b.Stats()
+	if s.TimerFlushes != 1 || s.FullFlushes != 0 {
+		t.Errorf("stats = %+v, want one timer flush", s)
+	}
+	if s.AvgAddedLatency <= 0 {
+		t.Error("AvgAddedLatency should reflect queueing delay")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(observed) != 1 || observed[0] != 1 {
+		t.Errorf("observed = %v, want [1]", observed)
+	}
+}
+
+func TestBatcher_ContextCancelled(t *testing.T) {
+	b := newTestBatcher(t, &countingBackend{}, BatchConfig{MaxBatchSize: 8, MaxDelay: time.Second})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := b.Embed(ctx, "embedder", LoadOptions{}, []string{"x"}); err != context.Canceled {
+		t.Errorf("Embed(cancelled) = %v, want context.Canceled", err)
+	}
+}
+
+// ─── Device Placement Tests ─────────────────────────────────────────────────
+
+const gib = 1024 * 1024 * 1024
+
+func testDevices() []Device {
+	return []Device{
+		{Index: 0, Name: "GPU-A", VRAMTotal: 8 * gib},
+		{Index: 1, Name: "GPU-B", VRAMTotal: 24 * gib, VRAMUsed: 4 * gib},
+	}
+}
+
+func TestParseNvidiaSMI(t *testing.T) {
+	out := "0, NVIDIA GeForce RTX 4090, 24564, 1024\n1, NVIDIA A100-SXM4-80GB, 81920, 0\n"
+	devices, err := parseNvidiaSMI(out)
+	if err != nil {
+		t.Fatalf("parseNvidiaSMI: %v", err)
+	}
+	if len(devices) != 2 {
+		t.Fatalf("devices = %d, want 2", len(devices))
+	}
+	if devices[0].Name != "NVIDIA GeForce RTX 4090" || devices[0].VRAMTotal != 24564*1024*1024 {
+		t.Errorf("device 0 = %+v", devices[0])
+	}
+	if devices[1].VRAMUsed != 0 {
+		t.Errorf("device 1 used = %d, want 0", devices[1].VRAMUsed)
+	}
+
+	if _, err := parseNvidiaSMI("0, broken"); err == nil {
+		t.Error("malformed line should fail")
+	}
+}
+
+func TestDeviceManager_Place(t *testing.T) {
+	tests := []struct {
+		name      string
+		bytes     uint64
+		requested []int
+		wantDevs  []int
+		wantErr   bool
+	}{
+		{"auto picks most free", 4 * gib, nil, []int{1}, false},
+		{"auto splits when no single fit", 26 * gib, nil, []int{0, 1}, false},
+		{"auto falls back to CPU", 40 * gib, nil, nil, false},
+		{"explicit single", 2 * gib, []int{0}, []int{0}, false},
+		{"explicit split", 10 * gib, []int{0, 1}, []int{0, 1}, false},
+		{"explicit unknown device", gib, []int{7}, nil, true},
+		{"explicit too small", 10 * gib, []int{0}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dm := NewDeviceManager(testDevices())
+			p, err := dm.Place("m", tt.bytes, tt.requested)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if len(p.Devices) != len(tt.wantDevs) {
+				t.Fatalf("devices = %v, want %v", p.Devices, tt.wantDevs)
+			}
+			for i := range p.Devices {
+				if p.Devices[i] != tt.wantDevs[i] {
+					t.Errorf("devices = %v, want %v", p.Devices, tt.wantDevs)
+				}
+			}
+			var sum float64
+			for _, f := range p.Split {
+				sum += f
+			}
+			if p.OnGPU() && (sum < 0.999 || sum > 1.001) {
+				t.Errorf("split %v sums to %v, want 1", p.Split, sum)
+			}
+			if p.VRAMFit < 0 || p.VRAMFit > 1 {
+				t.Errorf("VRAMFit = %v, want 0..1", p.VRAMFit)
+			}
+		})
+	}
+}
+
+func TestDeviceManager_TracksUsageAndRelease(t *testing.T) {
+	dm := NewDeviceManager(testDevices())
+	var fits []float64
+	dm.OnPlacement(func(model string, p Placement) { fits = append(fits, p.VRAMFit) })
+
+	dm.Place("big", 16*gib, nil)  // GPU 1: 20 GiB free → fits
+	dm.Place("small", 6*gib, nil) // GPU 0 now has the most free (8 vs 4)
+
+	usage := dm.Usage()
+	if usage[1].Reserved != 16*gib || usage[1].Free() != 4*gib {
+		t.Errorf("GPU 1 usage = %+v free=%d", usage[1], usage[1].Free())
+	}
+	if len(usage[0].Models) != 1 || usage[0].Models[0] != "small" {
+		t.Errorf("GPU 0 models = %v, want [small]", usage[0].Models)
+	}
+	if len(fits) != 2 || fits[0] != 0.8 || fits[1] != 0.75 {
+		t.Errorf("reported fits = %v, want [0.8 0.75]", fits)
+	}
+
+	dm.Release("big")
+	if got := dm.Usage()[1].Reserved; got != 0 {
+		t.Errorf("GPU 1 reserved after release = %d, want 0", got)
+	}
+	if _, ok := dm.PlacementOf("big"); ok {
+		t.Error("released model should have no placement")
+	}
+}
+
+// optsRecordingBackend captures the LoadOptions passed by the pool.
+type optsRecordingBackend struct {
+	MockBackend
+	last LoadOptions
+}
+
+func (b *optsRecordingBackend) LoadModel(path string, opts LoadOptions) (ModelHandle, error) {
+	b.last = opts
+	return b.MockBackend.LoadModel(path, opts)
+}
+
+func TestPool_DevicePlacement(t *testing.T) {
+	dir := t.TempDir()
+	weights := filepath.Join(dir, "model.gguf")
+	if err := os.WriteFile(weights, make([]byte, 4096), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	backend := &optsRecordingBackend{}
+	pool := NewPool(backend, 1<<40, func(string) (string, error) { return weights, nil })
+	dm := NewDeviceManager([]Device{{Index: 0, VRAMTotal: 2048}, {Index: 1, VRAMTotal: 6144}})
+	pool.SetDeviceManager(dm)
+
+	h, err := pool.Acquire("split-me", LoadOptions{NumGPULayers: -1, Devices: []int{0, 1}})
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+	h.Release()
+
+	if len(backend.last.TensorSplit) != 2 || backend.last.TensorSplit[0] != 0.25 {
+		t.Errorf("TensorSplit = %v, want [0.25 0.75]", backend.last.TensorSplit)
+	}
+	if got := pool.LoadedModels()[0].Processor; got != "GPU 0,1" {
+		t.Errorf("Processor = %q, want GPU 0,1", got)
+	}
+
+	pool.UnloadAll()
+	if dm.Usage()[1].Reserved != 0 {
+		t.Error("UnloadAll should release device reservations")
+	}
+}
+
+func TestPool_DevicePlacement_WeightsEveryVisibleDevice(t *testing.T) {
+	dir := t.TempDir()
+	weights := filepath.Join(dir, "model.gguf")
+	if err := os.WriteFile(weights, make([]byte, 4096), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	backend := &optsRecordingBackend{}
+	pool := NewPool(backend, 1<<40, func(string) (string, error) { return weights, nil })
+	pool.SetDeviceManager(NewDeviceManager([]Device{
+		{Index: 0, VRAMTotal: 8192}, {Index: 1, VRAMTotal: 2048}, {Index: 2, VRAMTotal: 6144},
+	}))
+
+	h, err := pool.Acquire("split", LoadOptions{NumGPULayers: -1, Devices: []int{1, 2}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Release()
+	if got := deviceArgs(backend.last); strings.Join(got, " ") != "--split-mode layer --tensor-split 0.000,0.250,0.750 --main-gpu 1" {
+		t.Errorf("split args = %v", got)
+	}
+
+	h, err = pool.Acquire("single", LoadOptions{NumGPULayers: -1, Devices: []int{0}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Release()
+	if got := deviceArgs(backend.last); strings.Join(got, " ") != "--split-mode none --main-gpu 0" {
+		t.Errorf("single-device args = %v", got)
+	}
+
+	if got := deviceArgs(LoadOptions{}); got != nil {
+		t.Errorf("unplaced args = %v, want none", got)
+	}
+}
+
+// ─── Parameter Guard Tests ──────────────────────────────────────────────────
+
+func TestParamGuard_Resolve(t *testing.T) {
+	f32 := func(v float32) *float32 { return &v }
+	i := func(v int) *int { return &v }
+	i64 := func(v int64) *int64 { return &v }
+
+	g := DefaultParamGuard()
+	g.SetModelLimits("tiny", ParamLimits{MaxTokens: 512})
+
+	tests := []struct {
+		name      string
+		model     string
+		req       ParamRequest
+		wantParam string // "" = valid
+	}{
+		{"defaults", "llama3", ParamRequest{}, ""},
+		{"full valid set", "llama3", ParamRequest{
+			Temperature: f32(1.2), TopP: f32(0.5), MaxTokens: i(100),
+			Stop: []string{"\n\n"}, Seed: i64(42), LogitBias: map[string]float32{"50256": -100},
+		}, ""},
+		{"temperature too high", "llama3", ParamRequest{Temperature: f32(2.5)}, "temperature"},
+		{"temperature negative", "llama3", ParamRequest{Temperature: f32(-0.1)}, "temperature"},
+		{"top_p zero", "llama3", ParamRequest{TopP: f32(0)}, "top_p"},
+		{"greedy with top_p", "llama3", ParamRequest{Temperature: f32(0), TopP: f32(0.5)}, ""},
+		{"max_tokens zero", "llama3", ParamRequest{MaxTokens: i(0)}, "max_tokens"},
+		{"max_tokens over global", "llama3", ParamRequest{MaxTokens: i(5000)}, "max_tokens"},
+		{"max_tokens over model", "tiny", ParamRequest{MaxTokens: i(1000)}, "max_tokens"},
+		{"too many stops", "llama3", ParamRequest{Stop: []string{"a", "b", "c", "d", "e"}}, "stop"},
+		{"empty stop", "llama3", ParamRequest{Stop: []string{""}}, "stop"},
+		{"negative seed", "llama3", ParamRequest{Seed: i64(-1)}, "seed"},
+		{"bias key not token", "llama3", ParamRequest{LogitBias: map[string]float32{"abc": 1}}, "logit_bias"},
+		{"bias out of range", "llama3", ParamRequest{LogitBias: map[string]float32{"1": 101}}, "logit_bias"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := g.Resolve(tt.model, tt.req)
+			if tt.wantParam == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			var pe *ParamError
+			if !errors.As(err, &pe) {
+				t.Fatalf("err = %v, want *ParamError", err)
+			}
+			if pe.Param != tt.wantParam {
+				t.Errorf("Param = %q, want %q", pe.Param, tt.wantParam)
+			}
+			if !errors.Is(err, domain.ErrInvalidParameter) {
+				t.Error("ParamError should unwrap to domain.ErrInvalidParameter")
+			}
+		})
+	}
+}
+
+func TestParamGuard_DefaultsAndClamp(t *testing.T) {
+	g := DefaultParamGuard()
+	g.SetModelLimits("tiny", ParamLimits{MaxTokens: 256})
+
+	p, err := g.Resolve("llama3", ParamRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Temperature != 0.7 || p.TopP != 0.9 || p.MaxTokens != 2048 || p.Seed != nil {
+		t.Errorf("defaults = %+v", p)
+	}
+
+	p, _ = g.Resolve("tiny", ParamRequest{})
+	if p.MaxTokens != 256 {
+		t.Errorf("tiny default MaxTokens = %d, want clamped to 256", p.MaxTokens)
+	}
+
+	seed := int64(7)
+	p, _ = g.Resolve("llama3", ParamRequest{Seed: &seed, LogitBias: map[string]float32{"42": 5}})
+	if p.Seed == nil || *p.Seed != 7 || p.LogitBias[42] != 5 {
+		t.Errorf("seed/bias not plumbed: %+v", p)
+	}
+}
+
+// ─── Speculative Decoding Tests ─────────────────────────────────────────────
+
+// timingsHandle is a MockModelHandle that reports generation timings.
+type timingsHandle struct {
+	MockModelHandle
+	hook func(GenerationTimings)
+}
+
+func (h *timingsHandle) SetTimingsHook(fn func(GenerationTimings)) { h.hook = fn }
+
+type timingsBackend struct {
+	optsRecordingBackend
+	handle *timingsHandle
+}
+
+func (b *timingsBackend) LoadModel(path string, opts LoadOptions) (ModelHandle, error) {
+	b.last = opts
+	b.handle = &timingsHandle{MockModelHandle: MockModelHandle{path: path, memSize: 1024}}
+	return b.handle, nil
+}
+
+func specResolver(name string) (string, error) {
+	if name == "missing" {
+		return "", domain.ErrModelNotFound
+	}
+	return "/models/" + name + ".gguf", nil
+}
+
+func TestPool_SetSpeculative(t *testing.T) {
+	tests := []struct {
+		name      string
+		cfg       SpeculativeConfig
+		wantErr   bool
+		wantDraft string
+		wantMax   int
+		wantMin   int
+	}{
+		{"defaults", SpeculativeConfig{DraftModel: "tiny"}, false, "/models/tiny.gguf", DefaultDraftTokens, 1},
+		{"explicit", SpeculativeConfig{DraftModel: "tiny", DraftTokens: 8, MinDraft: 2}, false, "/models/tiny.gguf", 8, 2},
+		{"unresolvable draft loads target alone", SpeculativeConfig{DraftModel: "missing"}, false, "", 0, 0},
+		{"draft equals target", SpeculativeConfig{DraftModel: "big"}, true, "", 0, 0},
+		{"negative tokens", SpeculativeConfig{DraftModel: "tiny", DraftTokens: -1}, true, "", 0, 0},
+		{"min exceeds max", SpeculativeConfig{DraftModel: "tiny", DraftTokens: 4, MinDraft: 5}, true, "", 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend := &optsRecordingBackend{}
+			pool := NewPool(backend, 1<<40, specResolver)
+
+			err := pool.SetSpeculative("big", tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SetSpeculative err = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			h, err := pool.Acquire("big", LoadOptions{})
+			if err != nil {
+				t.Fatalf("Acquire: %v", err)
+			}
+			h.Release()
+
+			got := backend.last
+			if got.DraftModelPath != tt.wantDraft || got.DraftMax != tt.wantMax || got.DraftMin != tt.wantMin {
+				t.Errorf("draft opts = (%q, %d, %d), want (%q, %d, %d)",
+					got.DraftModelPath, got.DraftMax, got.DraftMin, tt.wantDraft, tt.wantMax, tt.wantMin)
+			}
+		})
+	}
+}
+
+func TestPool_SetSpeculative_Unpair(t *testing.T) {
+	backend := &optsRecordingBackend{}
+	pool := NewPool(backend, 1<<40, specResolver)
+	pool.SetSpeculative("big", SpeculativeConfig{DraftModel: "tiny"})
+	pool.SetSpeculative("big", SpeculativeConfig{})
+
+	h, err := pool.Acquire("big", LoadOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Release()
+	if backend.last.DraftModelPath != "" {
+		t.Errorf("DraftModelPath = %q after unpairing, want empty", backend.last.DraftModelPath)
+	}
+	if s := pool.Speculative().Stats("big"); s.DraftModel != "" {
+		t.Errorf("DraftModel = %q after unpairing", s.DraftModel)
+	}
+}
+
+func TestPool_Speculative_RecordsHandleTimings(t *testing.T) {
+	backend := &timingsBackend{}
+	pool := NewPool(backend, 1<<40, specResolver)
+	pool.SetSpeculative("big", SpeculativeConfig{DraftModel: "tiny"})
+
+	var observed int
+	pool.Speculative().OnRecord(func(model string, g GenerationTimings) {
+		if model == "big" {
+			observed++
+		}
+	})
+
+	h, err := pool.Acquire("big", LoadOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Release()
+	if backend.handle.hook == nil {
+		t.Fatal("pool did not install a timings hook")
+	}
+
+	// llama-server final-chunk timings: 100 tokens in 1s, 80 drafted, 60 accepted.
+	lt := &llamaTimings{PredictedN: 100, PredictedMS: 1000, DraftN: 80, DraftNAccepted: 60}
+	backend.handle.hook(lt.generation())
+	backend.handle.hook(GenerationTimings{PredictedTokens: 50, Elapsed: time.Second})
+
+	s := pool.Speculative().Stats("big")
+	if s.DraftModel != "tiny" || s.Generations != 1 || s.Drafted != 80 || s.Accepted != 60 {
+		t.Errorf("stats = %+v", s)
+	}
+	if s.AcceptanceRate != 0.75 {
+		t.Errorf("AcceptanceRate = %g, want 0.75", s.AcceptanceRate)
+	}
+	if s.TokensPerSec != 100 {
+		t.Errorf("TokensPerSec = %g, want 100 (the undrafted generation is not counted)", s.TokensPerSec)
+	}
+	if s.Speedup != 0 {
+		t.Errorf("Speedup = %g without a baseline, want 0", s.Speedup)
+	}
+	pool.Speculative().SetBaseline("big", 50)
+	if s := pool.Speculative().Stats("big"); s.BaselinePerSec != 50 || s.Speedup != 2 || s.LatencySavedPct != 50 {
+		t.Errorf("with a 50 tok/s baseline: stats = %+v, want Speedup 2, LatencySavedPct 50", s)
+	}
+	if observed != 2 {
+		t.Errorf("OnRecord fired %d times, want 2", observed)
+	}
+	if all := pool.Speculative().AllStats(); len(all) != 1 {
+		t.Errorf("AllStats = %d entries, want 1", len(all))
+	}
+}
+
+func TestSpeculativeTracker_IgnoresUndrafted(t *testing.T) {
+	tr := NewSpeculativeTracker()
+	tr.Record("m", GenerationTimings{PredictedTokens: 10, Elapsed: time.Second, DraftTokens: 10, AcceptedTokens: 4})
+	tr.Record("plain", GenerationTimings{PredictedTokens: 10, Elapsed: time.Second})
+
+	s := tr.Stats("m")
+	if all := tr.AllStats(); len(all) != 1 || all[0].Model != "m" {
+		t.Errorf("AllStats = %+v, want only m", all)
+	}
+	if s.AcceptanceRate != 0.4 {
+		t.Errorf("AcceptanceRate = %g, want 0.4", s.AcceptanceRate)
+	}
+}
+
+// ─── Streaming Tests ────────────────────────────────────────────────────────
+
+// streamHandle emits n tokens then Done (n < 0 = forever), honouring ctx.
+type streamHandle struct {
+	MockModelHandle
+	n         int
+	produced  chan struct{} // closed after the final token was handed off
+	cancelled chan struct{} // closed when the generator observed ctx.Done
+}
+
+func newStreamHandle(n int) *streamHandle {
+	return &streamHandle{n: n, produced: make(chan struct{}), cancelled: make(chan struct{})}
+}
+
+func (h *streamHandle) Generate(ctx context.Context, prompt string, params GenerateParams) (<-chan domain.Token, error) {
+	ch := make(chan domain.Token)
+	go func() {
+		defer close(ch)
+		for i := 0; h.n < 0 || i <= h.n; i++ {
+			tok := domain.Token{Text: fmt.Sprintf("t%d ", i), Done: i == h.n}
+			select {
+			case ch <- tok:
+			case <-ctx.Done():
+				close(h.cancelled)
+				return
+			}
+		}
+		close(h.produced)
+	}()
+	return ch, nil
+}
+
+type handleBackend struct{ h ModelHandle }
+
+func (b *handleBackend) LoadModel(string, LoadOptions) (ModelHandle, error) { return b.h, nil }
+func (b *handleBackend) Close()                                             {}
+
+func newStreamPool(h ModelHandle, cfg StreamConfig) *Pool {
+	pool := NewPool(&handleBackend{h: h}, 1<<40, func(string) (string, error) { return "/m.gguf", nil })
+	pool.SetStreamConfig(cfg)
+	return pool
+}
+
+func TestPoolHandle_Generate_ContextCancelAbortsBackend(t *testing.T) {
+	sh := newStreamHandle(-1)
+	pool := newStreamPool(sh, DefaultStreamConfig())
+	h, _ := pool.Acquire("m", LoadOptions{})
+	defer h.Release()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := h.Generate(ctx, "hi", GenerateParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-stream.Tokens()
+	cancel() // client disconnected
+
+	for range stream.Tokens() {
+	}
+	if !errors.Is(stream.Err(), context.Canceled) {
+		t.Errorf("Err = %v, want context.Canceled", stream.Err())
+	}
+	select {
+	case <-sh.cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("backend generation was not cancelled")
+	}
+}
+
+func TestPoolHandle_Release_CancelsStreams(t *testing.T) {
+	sh := newStreamHandle(-1)
+	pool := newStreamPool(sh, DefaultStreamConfig())
+	h, _ := pool.Acquire("m", LoadOptions{})
+
+	stream, err := h.Generate(context.Background(), "hi", GenerateParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Release()
+	h.Release() // idempotent
+
+	stream.Wait()
+	select {
+	case <-sh.cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("Release did not cancel generation")
+	}
+	if _, err := h.Generate(context.Background(), "again", GenerateParams{}); err == nil {
+		t.Error("Generate on a released handle should fail")
+	}
+	if pool.models["m"].refCount != 0 {
+		t.Errorf("refCount = %d, want 0", pool.models["m"].refCount)
+	}
+}
+
+func TestTokenStream_Backpressure(t *testing.T) {
+	tests := []struct {
+		name          string
+		policy        BackpressurePolicy
+		stall         time.Duration
+		wantErr       error
+		wantDelivered int
+		wantDropped   int
+	}{
+		{"abort", BackpressureAbort, time.Minute, domain.ErrConsumerTooSlow, 2, 0},
+		{"block stalls out", BackpressureBlock, 20 * time.Millisecond, domain.ErrConsumerTooSlow, 2, 0},
+		{"drop keeps final token", BackpressureDrop, time.Minute, nil, 3, 8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sh := newStreamHandle(10)
+			pool := newStreamPool(sh, StreamConfig{Buffer: 2, Policy: tt.policy, StallTimeout: tt.stall})
+			h, _ := pool.Acquire("m", LoadOptions{})
+			defer h.Release()
+
+			stream, err := h.Generate(context.Background(), "hi", GenerateParams{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Consumer stays away until the producer is done or aborted.
+			select {
+			case <-sh.produced:
+			case <-sh.cancelled:
+			case <-time.After(2 * time.Second):
+				t.Fatal("producer neither finished nor was cancelled")
+			}
+			var got []domain.Token
+			for tok := range stream.Tokens() {
+				got = append(got, tok)
+			}
+
+			st := stream.Wait()
+			if !errors.Is(st.Err, tt.wantErr) {
+				t.Errorf("Err = %v, want %v", st.Err, tt.wantErr)
+			}
+			if st.Delivered != tt.wantDelivered || len(got) != tt.wantDelivered {
+				t.Errorf("delivered = %d (received %d), want %d", st.Delivered, len(got), tt.wantDelivered)
+			}
+			if st.Dropped != tt.wantDropped {
+				t.Errorf("Dropped = %d, want %d", st.Dropped, tt.wantDropped)
+			}
+			if tt.wantErr == nil && !got[len(got)-1].Done {
+				t.Error("final Done token was not delivered")
+			}
+		})
+	}
+}
+
+func TestTokenStream_ObserveAndCompletion(t *testing.T) {
+	sh := newStreamHandle(5)
+	var observed StreamStats
+	cfg := DefaultStreamConfig()
+	cfg.Observe = func(model string, st StreamStats) { observed = st }
+	pool := newStreamPool(sh, cfg)
+	h, _ := pool.Acquire("m", LoadOptions{})
+	defer h.Release()
+
+	stream, err := h.Generate(context.Background(), "hi", GenerateParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := 0
+	for range stream.Tokens() {
+		n++
+	}
+	stream.Wait()
+	if n != 6 || stream.Err() != nil {
+		t.Errorf("received %d tokens (err %v), want 6 and nil", n, stream.Err())
+	}
+	if observed.Delivered != 6 || observed.FirstToken <= 0 {
+		t.Errorf("Observe saw %+v", observed)
+	}
+}
+
+// truncatedHandle emits two tokens and closes its stream without Done, as a
+// backend that crashed mid-reply does.
+type truncatedHandle struct{ MockModelHandle }
+
+func (*truncatedHandle) Generate(ctx context.Context, prompt string, params GenerateParams) (<-chan domain.Token, error) {
+	ch := make(chan domain.Token, 2)
+	ch <- domain.Token{Text: "a "}
+	ch <- domain.Token{Text: "b "}
+	close(ch)
+	return ch, nil
+}
+
+func TestTokenStream_BackendEOFIsNotCancellation(t *testing.T) {
+	pool := newStreamPool(&truncatedHandle{}, DefaultStreamConfig())
+	h, _ := pool.Acquire("m", LoadOptions{})
+	defer h.Release()
+
+	stream, err := h.Generate(context.Background(), "hi", GenerateParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range stream.Tokens() {
+	}
+	st := stream.Wait()
+	if !errors.Is(st.Err, domain.ErrStreamTruncated) || st.Delivered != 2 {
+		t.Errorf("Err = %v, Delivered = %d, want ErrStreamTruncated and 2", st.Err, st.Delivered)
+	}
+}
+
+// fakeSlotStore records slot saves and restores; every cache is 100 bytes.
+type fakeSlotStore struct {
+	saved    map[string]int // file → slot it was saved from
+	restored []int
+	deleted  []string
+}
+
+func (f *fakeSlotStore) Save(slot int, file string) (int64, error) {
+	f.saved[file] = slot
+	return 100, nil
+}
+
+func (f *fakeSlotStore) Restore(slot int, file string) error {
+	if _, ok := f.saved[file]; !ok {
+		return os.ErrNotExist
+	}
+	f.restored = append(f.restored, slot)
+	return nil
+}
+
+func (f *fakeSlotStore) Delete(file string) {
+	delete(f.saved, file)
+	f.deleted = append(f.deleted, file)
+}
+
+func TestSessionCache_Assign(t *testing.T) {
+	store := &fakeSlotStore{saved: map[string]int{}}
+	c := NewSessionCache(2, 150, store)
+	now := time.Unix(0, 0)
+	c.now = func() time.Time { now = now.Add(time.Second); return now }
+
+	sys := PrefixKey([]ChatMessage{{Role: "system", Content: "be brief"}, {Role: "user", Content: "hi"}})
+	if sys == "" || PrefixKey([]ChatMessage{{Role: "user", Content: "hi"}}) != "" {
+		t.Fatalf("PrefixKey: system prompt = %q", sys)
+	}
+
+	steps := []struct {
+		session, prefix string
+		wantSlot        int
+		wantOutcome     KVOutcome
+	}{
+		{"a", sys, 0, KVCold},
+		{"a", sys, 0, KVResident},
+		{"b", "", 1, KVCold},
+		{"c", sys, 0, KVPrefix},   // evicts a (least recently used), same system prompt
+		{"a", sys, 0, KVRestored}, // evicts c, restores a's saved cache
+		{"", sys, -1, KVCold},     // both slots owned: not pinned
+		{"c", sys, 0, KVRestored},
+		{"b", "", 1, KVResident},
+		{"d", "", 0, KVCold}, // saving c pushes the budget: a's older cache is dropped
+		{"a", sys, 1, KVCold},
+	}
+	for i, st := range steps {
+		got := c.Assign(st.session, st.prefix)
+		if got.Slot != st.wantSlot || got.Outcome != st.wantOutcome {
+			t.Errorf("step %d (%q): got slot %d %s, want %d %s", i, st.session, got.Slot, got.Outcome, st.wantSlot, st.wantOutcome)
+		}
+	}
+	if len(store.restored) != 2 {
+		t.Errorf("restored = %v, want two restores", store.restored)
+	}
+	if c.SavedBytes() > 150 {
+		t.Errorf("SavedBytes = %d, over the 150 byte budget", c.SavedBytes())
+	}
+
+	c.Forget("d")
+	if got := c.Assign("e", ""); got.Slot != 0 {
+		t.Errorf("after Forget, e got slot %d, want the freed slot 0", got.Slot)
+	}
+}
+
+func TestPool_KVCache_RecordsPromptTimings(t *testing.T) {
+	backend := &timingsBackend{}
+	pool := NewPool(backend, 1<<40, specResolver)
+	pool.SetKVCache(KVCacheConfig{Slots: 3, SaveDir: "/var/kv"})
+
+	var observed int
+	pool.KVCache().OnRecord(func(model string, g GenerationTimings) { observed++ })
+
+	h, err := pool.Acquire("llama3:8b", LoadOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Release()
+	if backend.last.Slots != 3 || backend.last.SlotBudgetBytes != DefaultKVCacheConfig().BudgetBytes {
+		t.Errorf("LoadOptions = %+v, want 3 slots and the default budget", backend.last)
+	}
+	if want := filepath.Join("/var/kv", "llama3_8b"); backend.last.SlotSaveDir != want {
+		t.Errorf("SlotSaveDir = %q, want %q", backend.last.SlotSaveDir, want)
+	}
+
+	// Cold turn: 300 prompt tokens in 600ms. Follow-up: 100 new, 300 cached.
+	lt := &llamaTimings{PredictedN: 10, PredictedMS: 100, PromptN: 300, PromptMS: 600}
+	g := lt.generation()
+	g.KVOutcome = KVCold
+	backend.handle.hook(g)
+	lt = &llamaTimings{PredictedN: 10, PredictedMS: 100, PromptN: 100, PromptMS: 200, CacheN: 300}
+	g = lt.generation()
+	g.KVOutcome = KVResident
+	backend.handle.hook(g)
+	backend.handle.hook(GenerationTimings{PredictedTokens: 5, Elapsed: time.Second}) // no prompt timings
+
+	s := pool.KVCache().Stats("llama3:8b")
+	if s.Generations != 2 || s.PromptTokens != 400 || s.CachedTokens != 300 {
+		t.Errorf("stats = %+v", s)
+	}
+	if s.Outcomes[KVCold] != 1 || s.Outcomes[KVResident] != 1 {
+		t.Errorf("Outcomes = %v", s.Outcomes)
+	}
+	if math.Abs(s.HitRate-300.0/700) > 1e-9 {
+		t.Errorf("HitRate = %g, want %g", s.HitRate, 300.0/700)
+	}
+	// 800ms for 400 tokens → 2ms each; 300 cached tokens saved 0.6s.
+	if math.Abs(s.PromptSavedSecs-0.6) > 1e-9 {
+		t.Errorf("PromptSavedSecs = %g, want 0.6", s.PromptSavedSecs)
+	}
+	if observed != 2 {
+		t.Errorf("OnRecord fired %d times, want 2", observed)
+	}
+}
@@ -0,0 +1,250 @@
+package engine
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ─── Speculative Decoding ───────────────────────────────────────────────────
+// A small draft model proposes DraftTokens tokens ahead; the large target
+// model verifies the whole run in one batched forward pass and keeps the
+// longest accepted prefix. Output is identical to the target alone, but each
+// target pass can emit several tokens.
+//
+// Pairing is configured per target model with Pool.SetSpeculative. The pool
+// resolves the draft model's weights and passes them to the backend through
+// LoadOptions (llama-server: --model-draft / --draft-max / --draft-min).
+// Backends report per-generation timings through TimingsReporter, which the
+// SpeculativeTracker aggregates into acceptance rate and throughput. The
+// speedup is measured against the model's benchmark (Pool.Benchmark), which
+// decodes without the draft model: the daemon passes each result to
+// SetBaseline.
+
+// SpeculativeConfig pairs a target model with a draft model.
+type SpeculativeConfig struct {
+	DraftModel  string // draft model name, resolved like any other model
+	DraftTokens int    // max tokens drafted per verification pass (default 16)
+	MinDraft    int    // min tokens worth drafting (default 1)
+}
+
+// DefaultDraftTokens is the draft length used when DraftTokens is unset.
+const DefaultDraftTokens = 16
+
+// ─── Pool Integration ──────────────────────────────────────────────────────
+
+// SetSpeculative pairs target with a draft model for loads after this call;
+// an already-loaded target keeps its current configuration until reloaded.
+// A zero cfg.DraftModel removes the pairing.
+func (p *Pool) SetSpeculative(target string, cfg SpeculativeConfig) error {
+	if cfg.DraftModel == target && target != "" {
+		return fmt.Errorf("speculative %s: draft model must differ from target", target)
+	}
+	if cfg.DraftTokens < 0 || cfg.MinDraft < 0 {
+		return fmt.Errorf("speculative %s: draft token counts must be non-negative", target)
+	}
+	if cfg.DraftTokens > 0 && cfg.MinDraft > cfg.DraftTokens {
+		return fmt.Errorf("speculative %s: min draft %d exceeds draft tokens %d", target, cfg.MinDraft, cfg.DraftTokens)
+	}
+
+	p.mu.Lock()
+	if cfg.DraftModel == "" {
+		delete(p.speculative, target)
+	} else {
+		p.speculative[target] = cfg
+	}
+	p.mu.Unlock()
+
+	p.specStats.setDraft(target, cfg.DraftModel)
+	return nil
+}
+
+// Speculative returns the tracker holding per-model acceptance and
+// throughput statistics.
+func (p *Pool) Speculative() *SpeculativeTracker {
+	return p.specStats
+}
+
+// applySpeculativeLocked fills the draft fields of opts for a paired target.
+// A draft that cannot be resolved is skipped: the target still loads and
+// serves at normal speed.
+func (p *Pool) applySpeculativeLocked(name string, opts *LoadOptions) {
+	cfg, ok := p.speculative[name]
+	if !ok || opts.DraftModelPath != "" {
+		return
+	}
+	draftPath, err := p.resolver(cfg.DraftModel)
+	if err != nil {
+		return
+	}
+	opts.DraftModelPath = draftPath
+	opts.DraftMax = cfg.DraftTokens
+	if opts.DraftMax == 0 {
+		opts.DraftMax = DefaultDraftTokens
+	}
+	opts.DraftMin = cfg.MinDraft
+	if opts.DraftMin == 0 {
+		opts.DraftMin = 1
+	}
+}
+
+// ─── Statistics ────────────────────────────────────────────────────────────
+
+// GenerationTimings describes one completed generation as reported by the backend.
+type GenerationTimings struct {
+	PredictedTokens int           // tokens emitted
+	Elapsed         time.Duration // decode time for PredictedTokens
+	DraftTokens     int           // tokens proposed by the draft model (0 = not speculative)
+	AcceptedTokens  int           // drafted tokens accepted by the target
+
+	PromptTokens  int           // prompt tokens evaluated
+	CachedTokens  int           // prompt tokens reused from the KV cache
+	PromptElapsed time.Duration // evaluation time for PromptTokens
+	KVOutcome     KVOutcome     // how the request's slot was chosen ("" = unpinned)
+}
+
+// TimingsReporter is implemented by model handles that can report timings
+// for each completed generation.
+type TimingsReporter interface {
+	SetTimingsHook(fn func(GenerationTimings))
+}
+
+// SpeculativeStats summarizes speculative decoding for one target model.
+type SpeculativeStats struct {
+	Model          string  `json:"model"`
+	DraftModel     string  `json:"draft_model,omitempty"`
+	Generations    int64   `json:"generations"`     // speculative generations observed
+	Drafted        int64   `json:"drafted"`         // tokens proposed by the draft model
+	Accepted       int64   `json:"accepted"`        // drafted tokens accepted by the target
+	AcceptanceRate float64 `json:"acceptance_rate"` // Accepted / Drafted
+	TokensPerSec   float64 `json:"tokens_per_sec"`  // speculative decode throughput
+
+	BaselinePerSec  float64 `json:"baseline_per_sec"`  // benchmarked throughput without the draft (0 = not measured)
+	Speedup         float64 `json:"speedup"`           // TokensPerSec / BaselinePerSec (0 = unknown)
+	LatencySavedPct float64 `json:"latency_saved_pct"` // per-token latency reduction, percent
+}
+
+// specCounters accumulates speculative timings for one model.
+type specCounters struct {
+	generations int64
+	tokens      int64
+	elapsed     time.Duration
+	drafted     int64
+	accepted    int64
+}
+
+func (c *specCounters) tokensPerSec() float64 {
+	if c.elapsed <= 0 {
+		return 0
+	}
+	return float64(c.tokens) / c.elapsed.Seconds()
+}
+
+// SpeculativeTracker aggregates generation timings per model. Thread-safe.
+type SpeculativeTracker struct {
+	mu          sync.Mutex
+	speculative map[string]*specCounters
+	baseline    map[string]float64 // model → tokens/sec without a draft
+	drafts      map[string]string  // target → draft model
+	onRecord    func(model string, t GenerationTimings)
+}
+
+// NewSpeculativeTracker creates an empty tracker.
+func NewSpeculativeTracker() *SpeculativeTracker {
+	return &SpeculativeTracker{
+		speculative: make(map[string]*specCounters),
+		baseline:    make(map[string]float64),
+		drafts:      make(map[string]string),
+	}
+}
+
+// SetBaseline records a model's throughput without a draft model, the
+// reference for its speedup. Non-positive values clear it.
+func (t *SpeculativeTracker) SetBaseline(model string, tokensPerSec float64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if tokensPerSec <= 0 {
+		delete(t.baseline, model)
+		return
+	}
+	t.baseline[model] = tokensPerSec
+}
+
+// OnRecord installs a callback fired for every recorded generation
+// (e.g. to feed Prometheus counters).
+func (t *SpeculativeTracker) OnRecord(fn func(model string, g GenerationTimings)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.onRecord = fn
+}
+
+// Record adds one generation's timings. Generations with DraftTokens == 0
+// are not counted, but still reach the OnRecord callback.
+func (t *SpeculativeTracker) Record(model string, g GenerationTimings) {
+	t.mu.Lock()
+	if g.DraftTokens > 0 {
+		c, ok := t.speculative[model]
+		if !ok {
+			c = &specCounters{}
+			t.speculative[model] = c
+		}
+		c.generations++
+		c.tokens += int64(g.PredictedTokens)
+		c.elapsed += g.Elapsed
+		c.drafted += int64(g.DraftTokens)
+		c.accepted += int64(g.AcceptedTokens)
+	}
+	hook := t.onRecord
+	t.mu.Unlock()
+
+	if hook != nil {
+		hook(model, g)
+	}
+}
+
+// Stats returns speculative decoding statistics for a model.
+func (t *SpeculativeTracker) Stats(model string) SpeculativeStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.statsLocked(model)
+}
+
+// AllStats returns statistics for every model with speculative generations.
+func (t *SpeculativeTracker) AllStats() []SpeculativeStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	out := make([]SpeculativeStats, 0, len(t.speculative))
+	for model := range t.speculative {
+		out = append(out, t.statsLocked(model))
+	}
+	return out
+}
+
+func (t *SpeculativeTracker) statsLocked(model string) SpeculativeStats {
+	s := SpeculativeStats{Model: model, DraftModel: t.drafts[model]}
+	if c, ok := t.speculative[model]; ok {
+		s.Generations = c.generations
+		s.Drafted = c.drafted
+		s.Accepted = c.accepted
+		s.TokensPerSec = c.tokensPerSec()
+		if c.drafted > 0 {
+			s.AcceptanceRate = float64(c.accepted) / float64(c.drafted)
+		}
+	}
+	s.BaselinePerSec = t.baseline[model]
+	if s.BaselinePerSec > 0 && s.TokensPerSec > 0 {
+		s.Speedup = s.TokensPerSec / s.BaselinePerSec
+		s.LatencySavedPct = (1 - 1/s.Speedup) * 100
+	}
+	return s
+}
+
+func (t *SpeculativeTracker) setDraft(target, draft string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if draft == "" {
+		delete(t.drafts, target)
+		return
+	}
+	t.drafts[target] = draft
+}
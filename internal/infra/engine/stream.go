@@ -0,0 +1,283 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
+)
+
+// ─── Token Streaming: Cancellation & Backpressure ───────────────────────────
+// PoolHandle.Generate and PoolHandle.Chat run the backend under a context
+// derived from the caller's, so a disconnected API client (cancelled request
+// context), an explicit TokenStream.Cancel or PoolHandle.Release all abort
+// generation promptly instead of burning GPU time on tokens nobody reads.
+//
+// Tokens are relayed to the consumer through a bounded buffer. When the
+// buffer is full the StreamConfig policy decides what happens:
+//
+//	BackpressureBlock → wait for the consumer; abort after StallTimeout
+//	BackpressureDrop  → discard tokens the consumer has no room for
+//	BackpressureAbort → abort generation immediately
+//
+// The final Done token is never dropped. A backend that closes its stream
+// without a Done token while nobody cancelled it (e.g. llama-server died
+// mid-reply) ends the stream with domain.ErrStreamTruncated.
+
+// BackpressurePolicy selects how a stream treats a consumer that falls behind.
+type BackpressurePolicy string
+
+const (
+	BackpressureBlock BackpressurePolicy = "block"
+	BackpressureDrop  BackpressurePolicy = "drop"
+	BackpressureAbort BackpressurePolicy = "abort"
+)
+
+// Valid reports whether p is a known policy.
+func (p BackpressurePolicy) Valid() bool {
+	switch p {
+	case BackpressureBlock, BackpressureDrop, BackpressureAbort:
+		return true
+	}
+	return false
+}
+
+// StreamConfig controls token relaying between the backend and consumers.
+type StreamConfig struct {
+	Buffer       int                // tokens buffered ahead of the consumer
+	Policy       BackpressurePolicy // behaviour when the buffer is full
+	StallTimeout time.Duration      // max wait on a full buffer (block policy, final token)
+
+	// Observe, if set, is called once per finished stream (e.g. for metrics).
+	Observe func(model string, st StreamStats)
+}
+
+// DefaultStreamConfig returns the default relay settings.
+func DefaultStreamConfig() StreamConfig {
+	return StreamConfig{
+		Buffer:       64,
+		Policy:       BackpressureBlock,
+		StallTimeout: 30 * time.Second,
+	}
+}
+
+// StreamStats describes a finished stream.
+type StreamStats struct {
+	Delivered  int           // tokens handed to the consumer
+	Dropped    int           // tokens discarded under BackpressureDrop
+	FirstToken time.Duration // backend start to first token (0 = none produced)
+	Err        error         // nil = completed; context error = cancelled; ErrConsumerTooSlow = aborted; ErrStreamTruncated = backend stopped
+}
+
+// TokenStream is a cancellable, backpressure-aware token stream.
+type TokenStream struct {
+	out    chan domain.Token
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu    sync.Mutex
+	stats StreamStats
+}
+
+// Tokens returns the consumer channel. It is closed when generation
+// completes, is cancelled or is aborted.
+func (s *TokenStream) Tokens() <-chan domain.Token { return s.out }
+
+// Cancel aborts generation. Safe to call more than once.
+func (s *TokenStream) Cancel() { s.cancel() }
+
+// Err returns why the stream ended: nil after a completed generation, the
+// context error after cancellation, domain.ErrConsumerTooSlow after a
+// backpressure abort, or domain.ErrStreamTruncated when the backend stopped
+// on its own. Only meaningful once Tokens() is closed.
+func (s *TokenStream) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stats.Err
+}
+
+// Stats returns delivery statistics. Only final once Tokens() is closed.
+func (s *TokenStream) Stats() StreamStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stats
+}
+
+// Wait blocks until the relay has finished and returns the final statistics.
+func (s *TokenStream) Wait() StreamStats {
+	<-s.done
+	return s.Stats()
+}
+
+// newTokenStream starts the backend under a cancellable child of ctx and
+// relays its tokens according to cfg.
+func newTokenStream(ctx context.Context, model string, cfg StreamConfig, start func(context.Context) (<-chan domain.Token, error)) (*TokenStream, error) {
+	gctx, cancel := context.WithCancel(ctx)
+	started := time.Now()
+	in, err := start(gctx)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	s := &TokenStream{
+		out:    make(chan domain.Token, cfg.Buffer),
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	go s.relay(gctx, model, cfg, in, started)
+	return s, nil
+}
+
+func (s *TokenStream) relay(ctx context.Context, model string, cfg StreamConfig, in <-chan domain.Token, started time.Time) {
+	defer close(s.done)
+
+	var err error
+	finished, first := false, true
+	for tok := range in {
+		if first {
+			first = false
+			s.mu.Lock()
+			s.stats.FirstToken = max(time.Since(started), 1)
+			s.mu.Unlock()
+		}
+		if err = s.deliver(ctx, cfg, tok); err != nil {
+			break
+		}
+		if tok.Done {
+			finished = true
+			break
+		}
+	}
+	if err == nil && !finished {
+		// Read before our own cancel below: only a cancellation from the
+		// caller, Cancel or Release explains a missing Done token
+		if err = ctx.Err(); err == nil {
+			err = domain.ErrStreamTruncated
+		}
+	}
+	s.cancel()
+	for range in { // let the producer exit
+	}
+
+	s.mu.Lock()
+	s.stats.Err = err
+	st := s.stats
+	s.mu.Unlock()
+	close(s.out)
+
+	if cfg.Observe != nil {
+		cfg.Observe(model, st)
+	}
+}
+
+// deliver hands one token to the consumer, applying the backpressure policy
+// when the buffer is full.
+func (s *TokenStream) deliver(ctx context.Context, cfg StreamConfig, tok domain.Token) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	select {
+	case s.out <- tok:
+		s.countDelivered()
+		return nil
+	default:
+	}
+
+	switch {
+	case tok.Done:
+		// Always try to deliver completion, whatever the policy.
+	case cfg.Policy == BackpressureDrop:
+		s.mu.Lock()
+		s.stats.Dropped++
+		s.mu.Unlock()
+		return nil
+	case cfg.Policy == BackpressureAbort:
+		return domain.ErrConsumerTooSlow
+	}
+
+	timer := time.NewTimer(cfg.StallTimeout)
+	defer timer.Stop()
+	select {
+	case s.out <- tok:
+		s.countDelivered()
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return domain.ErrConsumerTooSlow
+	}
+}
+
+func (s *TokenStream) countDelivered() {
+	s.mu.Lock()
+	s.stats.Delivered++
+	s.mu.Unlock()
+}
+
+// ─── Pool Integration ───────────────────────────────────────────────────────
+
+// SetStreamConfig replaces the relay settings for subsequent streams.
+// Non-positive or unknown values fall back to DefaultStreamConfig.
+func (p *Pool) SetStreamConfig(cfg StreamConfig) {
+	def := DefaultStreamConfig()
+	if cfg.Buffer <= 0 {
+		cfg.Buffer = def.Buffer
+	}
+	if !cfg.Policy.Valid() {
+		cfg.Policy = def.Policy
+	}
+	if cfg.StallTimeout <= 0 {
+		cfg.StallTimeout = def.StallTimeout
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.stream = cfg
+}
+
+func (p *Pool) streamConfig() StreamConfig {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.stream
+}
+
+// Generate streams a completion from the handle's model. Generation stops
+// when ctx is done, the stream is cancelled, or the handle is released.
+func (h *PoolHandle) Generate(ctx context.Context, prompt string, params GenerateParams) (*TokenStream, error) {
+	return h.startStream(ctx, func(gctx context.Context) (<-chan domain.Token, error) {
+		return h.entry.handle.Generate(gctx, prompt, params)
+	})
+}
+
+// Chat streams a chat completion from the handle's model. Cancellation
+// follows the same rules as Generate.
+func (h *PoolHandle) Chat(ctx context.Context, messages []ChatMessage, params GenerateParams) (*TokenStream, error) {
+	return h.startStream(ctx, func(gctx context.Context) (<-chan domain.Token, error) {
+		return h.entry.handle.Chat(gctx, messages, params)
+	})
+}
+
+func (h *PoolHandle) startStream(ctx context.Context, start func(context.Context) (<-chan domain.Token, error)) (*TokenStream, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.released {
+		return nil, errors.New("pool handle already released")
+	}
+	guard := h.pool.callGuard()
+	guarded := func(gctx context.Context) (<-chan domain.Token, error) {
+		var in <-chan domain.Token
+		err := guard(h.entry.name, func() error {
+			var startErr error
+			in, startErr = start(gctx)
+			return startErr
+		})
+		return in, err
+	}
+	s, err := newTokenStream(ctx, h.entry.name, h.pool.streamConfig(), guarded)
+	if err != nil {
+		return nil, err
+	}
+	h.streams = append(h.streams, s)
+	return s, nil
+}
@@ -25,6 +25,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -151,10 +152,22 @@ func (b *SubprocessBackend) LoadModel(path string, opts LoadOptions) (ModelHandl
 		"--model", path,
 		"--host", "127.0.0.1",
 		"--port", fmt.Sprintf("%d", port),
-		"--ctx-size", fmt.Sprintf("%d", coalesce(opts.NumCtx, 4096)),
+		"--ctx-size", fmt.Sprintf("%d", coalesce(opts.NumCtx, 4096)*max(opts.Slots, 1)),
 		"--no-mmap", // Safer on Windows
 	}
 
+	// KV-cache slots: llama-server splits --ctx-size across them, so it is
+	// scaled above to keep each session's full context window
+	if opts.Slots > 1 {
+		args = append(args, "--parallel", fmt.Sprintf("%d", opts.Slots))
+		if opts.SlotSaveDir != "" {
+			if err := os.MkdirAll(opts.SlotSaveDir, 0o755); err != nil {
+				return nil, fmt.Errorf("create slot save dir: %w", err)
+			}
+			args = append(args, "--slot-save-path", opts.SlotSaveDir)
+		}
+	}
+
 	// GPU layers
 	if opts.NumGPULayers >= 0 {
 		args = append(args, "--n-gpu-layers", fmt.Sprintf("%d", opts.NumGPULayers))
@@ -163,6 +176,29 @@ func (b *SubprocessBackend) LoadModel(path string, opts LoadOptions) (ModelHandl
 		args = append(args, "--n-gpu-layers", "99")
 	}
 
+	// Device placement (decided by the pool's DeviceManager)
+	args = append(args, deviceArgs(opts)...)
+
+	// Speculative decoding: the draft model shares the target's devices
+	memSize := uint64(stat.Size()) // Approximate — model file size
+	if opts.DraftModelPath != "" {
+		draftStat, err := os.Stat(opts.DraftModelPath)
+		if err != nil {
+			return nil, fmt.Errorf("draft model file not found: %w", err)
+		}
+		memSize += uint64(draftStat.Size())
+		args = append(args,
+			"--model-draft", opts.DraftModelPath,
+			"--draft-max", fmt.Sprintf("%d", coalesce(opts.DraftMax, DefaultDraftTokens)),
+			"--draft-min", fmt.Sprintf("%d", coalesce(opts.DraftMin, 1)),
+		)
+		if opts.NumGPULayers >= 0 {
+			args = append(args, "--n-gpu-layers-draft", fmt.Sprintf("%d", opts.NumGPULayers))
+		} else {
+			args = append(args, "--n-gpu-layers-draft", "99")
+		}
+	}
+
 	// Threads
 	if opts.NumThreads > 0 {
 		args = append(args, "--threads", fmt.Sprintf("%d", opts.NumThreads))
@@ -216,16 +252,24 @@ func (b *SubprocessBackend) LoadModel(path string, opts LoadOptions) (ModelHandl
 
 	b.progress("Model loaded — ready!")
 
-	return &SubprocessHandle{
+	h := &SubprocessHandle{
 		cmd:     cmd,
 		addr:    addr,
 		port:    port,
 		path:    path,
-		memSize: uint64(stat.Size()), // Approximate — model file size
+		memSize: memSize,
 		client: &http.Client{
 			Timeout: 10 * time.Minute, // Long timeout for generation
 		},
-	}, nil
+	}
+	if opts.Slots > 1 {
+		var store SlotStore
+		if opts.SlotSaveDir != "" {
+			store = &llamaSlotStore{client: h.client, addr: addr, dir: opts.SlotSaveDir}
+		}
+		h.sessions = NewSessionCache(opts.Slots, opts.SlotBudgetBytes, store)
+	}
+	return h, nil
 }
 
 // Close releases the backend (noop — handles close individually).
@@ -241,8 +285,115 @@ type SubprocessHandle struct {
 	path    string
 	memSize uint64
 	client  *http.Client
-	mu      sync.Mutex // protects closed
+	mu      sync.Mutex // protects closed, onTimings
 	closed  bool
+
+	sessions *SessionCache // nil = a single slot, nothing to pin
+
+	onTimings func(GenerationTimings)
+}
+
+// SetTimingsHook implements TimingsReporter.
+func (h *SubprocessHandle) SetTimingsHook(fn func(GenerationTimings)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.onTimings = fn
+}
+
+// reportTimings forwards llama-server's final-chunk timings to the hook.
+func (h *SubprocessHandle) reportTimings(t *llamaTimings, outcome KVOutcome) {
+	if t == nil {
+		return
+	}
+	h.mu.Lock()
+	hook := h.onTimings
+	h.mu.Unlock()
+	if hook != nil {
+		g := t.generation()
+		g.KVOutcome = outcome
+		hook(g)
+	}
+}
+
+// assignSlot picks the KV-cache slot for a request and sets it in body.
+func (h *SubprocessHandle) assignSlot(body map[string]interface{}, session, prefix string) KVOutcome {
+	body["cache_prompt"] = true
+	if h.sessions == nil {
+		return ""
+	}
+	a := h.sessions.Assign(session, prefix)
+	if a.Slot < 0 {
+		return ""
+	}
+	body["id_slot"] = a.Slot
+	return a.Outcome
+}
+
+// llamaTimings is the "timings" object llama-server attaches to the final
+// streamed chunk. Draft fields are present only with --model-draft.
+type llamaTimings struct {
+	PredictedN     int     `json:"predicted_n"`
+	PredictedMS    float64 `json:"predicted_ms"`
+	PromptN        int     `json:"prompt_n"`
+	PromptMS       float64 `json:"prompt_ms"`
+	CacheN         int     `json:"cache_n"`
+	DraftN         int     `json:"draft_n"`
+	DraftNAccepted int     `json:"draft_n_accepted"`
+}
+
+func (t *llamaTimings) generation() GenerationTimings {
+	return GenerationTimings{
+		PredictedTokens: t.PredictedN,
+		Elapsed:         time.Duration(t.PredictedMS * float64(time.Millisecond)),
+		DraftTokens:     t.DraftN,
+		AcceptedTokens:  t.DraftNAccepted,
+		PromptTokens:    t.PromptN,
+		CachedTokens:    t.CacheN,
+		PromptElapsed:   time.Duration(t.PromptMS * float64(time.Millisecond)),
+	}
+}
+
+// llamaSlotStore saves and restores slot caches through llama-server's
+// /slots API into dir (its --slot-save-path).
+type llamaSlotStore struct {
+	client *http.Client
+	addr   string
+	dir    string
+}
+
+func (s *llamaSlotStore) action(slot int, action, file string) (int64, error) {
+	body, _ := json.Marshal(map[string]string{"filename": file})
+	url := fmt.Sprintf("%s/slots/%d?action=%s", s.addr, slot, action)
+	resp, err := s.client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(resp.Body)
+		return 0, fmt.Errorf("llama-server slot %s %d: %s", action, resp.StatusCode, msg)
+	}
+	var result struct {
+		NWritten int64 `json:"n_written"`
+	}
+	json.NewDecoder(resp.Body).Decode(&result) //nolint:errcheck
+	return result.NWritten, nil
+}
+
+// Save implements SlotStore.
+func (s *llamaSlotStore) Save(slot int, file string) (int64, error) {
+	return s.action(slot, "save", file)
+}
+
+// Restore implements SlotStore.
+func (s *llamaSlotStore) Restore(slot int, file string) error {
+	_, err := s.action(slot, "restore", file)
+	return err
+}
+
+// Delete implements SlotStore.
+func (s *llamaSlotStore) Delete(file string) {
+	os.Remove(filepath.Join(s.dir, file)) //nolint:errcheck
 }
 
 // Generate sends a completion request to llama-server and streams tokens back.
@@ -262,6 +413,7 @@ func (h *SubprocessHandle) Generate(ctx context.Context, prompt string, params G
 		"top_p":        params.TopP,
 		"cache_prompt": true,
 	}
+	outcome := h.assignSlot(body, params.SessionID, "")
 	if params.MaxTokens > 0 {
 		body["n_predict"] = params.MaxTokens
 	} else {
@@ -270,6 +422,7 @@ func (h *SubprocessHandle) Generate(ctx context.Context, prompt string, params G
 	if len(params.Stop) > 0 {
 		body["stop"] = params.Stop
 	}
+	addSamplingExtras(body, params)
 
 	jsonBody, err := json.Marshal(body)
 	if err != nil {
@@ -315,8 +468,9 @@ func (h *SubprocessHandle) Generate(ctx context.Context, prompt string, params G
 			}
 
 			var chunk struct {
-				Content string `json:"content"`
-				Stop    bool   `json:"stop"`
+				Content string        `json:"content"`
+				Stop    bool          `json:"stop"`
+				Timings *llamaTimings `json:"timings"`
 			}
 			if err := json.Unmarshal([]byte(jsonData), &chunk); err != nil {
 				continue
@@ -332,6 +486,7 @@ func (h *SubprocessHandle) Generate(ctx context.Context, prompt string, params G
 			}
 
 			if chunk.Stop {
+				h.reportTimings(chunk.Timings, outcome)
 				return
 			}
 		}
@@ -357,6 +512,7 @@ func (h *SubprocessHandle) Chat(ctx context.Context, messages []ChatMessage, par
 		"temperature": params.Temperature,
 		"top_p":       params.TopP,
 	}
+	outcome := h.assignSlot(body, params.SessionID, PrefixKey(messages))
 	if params.MaxTokens > 0 {
 		body["max_tokens"] = params.MaxTokens
 	} else {
@@ -365,6 +521,7 @@ func (h *SubprocessHandle) Chat(ctx context.Context, messages []ChatMessage, par
 	if len(params.Stop) > 0 {
 		body["stop"] = params.Stop
 	}
+	addSamplingExtras(body, params)
 
 	jsonBody, err := json.Marshal(body)
 	if err != nil {
@@ -414,6 +571,7 @@ func (h *SubprocessHandle) Chat(ctx context.Context, messages []ChatMessage, par
 					} `json:"delta"`
 					FinishReason *string `json:"finish_reason"`
 				} `json:"choices"`
+				Timings *llamaTimings `json:"timings"`
 			}
 			if err := json.Unmarshal([]byte(jsonData), &chunk); err != nil {
 				continue
@@ -435,6 +593,7 @@ func (h *SubprocessHandle) Chat(ctx context.Context, messages []ChatMessage, par
 				}
 
 				if done {
+					h.reportTimings(chunk.Timings, outcome)
 					return
 				}
 			}
@@ -646,6 +805,49 @@ func killOrphanLlamaServers() {
 	time.Sleep(500 * time.Millisecond)
 }
 
+// addSamplingExtras adds optional seed, logit bias and draft suppression to
+// a llama-server request body. llama-server takes logit_bias as
+// [[token_id, bias], ...] and drafts nothing with speculative.n_max 0.
+func addSamplingExtras(body map[string]interface{}, params GenerateParams) {
+	if params.NoDraft {
+		body["speculative.n_max"] = 0
+	}
+	if params.Seed != nil {
+		body["seed"] = *params.Seed
+	}
+	if len(params.LogitBias) > 0 {
+		bias := make([][2]float64, 0, len(params.LogitBias))
+		for id, b := range params.LogitBias {
+			bias = append(bias, [2]float64{float64(id), float64(b)})
+		}
+		body["logit_bias"] = bias
+	}
+}
+
+// deviceArgs returns the llama-server flags for a placement. A model on one
+// GPU runs there alone; a split gives --tensor-split a weight for every
+// visible device, zero for the ones not used, since llama-server reads the
+// list positionally.
+func deviceArgs(opts LoadOptions) []string {
+	used := 0
+	for _, w := range opts.TensorSplit {
+		if w > 0 {
+			used++
+		}
+	}
+	switch {
+	case used == 1:
+		return []string{"--split-mode", "none", "--main-gpu", strconv.Itoa(opts.MainGPU)}
+	case used > 1:
+		split := make([]string, len(opts.TensorSplit))
+		for i, f := range opts.TensorSplit {
+			split[i] = strconv.FormatFloat(f, 'f', 3, 64)
+		}
+		return []string{"--split-mode", "layer", "--tensor-split", strings.Join(split, ","), "--main-gpu", strconv.Itoa(opts.MainGPU)}
+	}
+	return nil
+}
+
 // coalesce returns the first non-zero value.
 func coalesce(vals ...int) int {
 	for _, v := range vals {
@@ -9,11 +9,12 @@
 package federation
 
 import (
-	"errors"
 	"fmt"
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
 )
 
 // ─── Constants ──────────────────────────────────────────────────────────────
@@ -85,14 +86,15 @@ func (p SharingPolicy) String() string {
 
 // Federation represents a private sub-network owned by an organization.
 type Federation struct {
-	ID              string           `json:"id"`                // Unique federation ID (e.g. "fed-acme-corp")
-	Name            string           `json:"name"`              // Human-readable name
-	AdminNodeID     string           `json:"admin_node_id"`     // Node that created this federation
-	Status          FederationStatus `json:"status"`            // Lifecycle state
-	SharingPolicy   SharingPolicy    `json:"sharing_policy"`    // How spare capacity is shared
-	RevenueSharePct int              `json:"revenue_share_pct"` // Org revenue share (default 80%)
-	DataSovereignty bool             `json:"data_sovereignty"`  // Tasks must stay within federation
-	AllowedRegions  []string         `json:"allowed_regions"`   // Restrict to specific regions
+	ID              string           `json:"id"`                  // Unique federation ID (e.g. "fed-acme-corp")
+	Name            string           `json:"name"`                // Human-readable name
+	AdminNodeID     string           `json:"admin_node_id"`       // Node that created this federation
+	AdminKey        string           `json:"admin_key,omitempty"` // Public key that signs its invite codes
+	Status          FederationStatus `json:"status"`              // Lifecycle state
+	SharingPolicy   SharingPolicy    `json:"sharing_policy"`      // How spare capacity is shared
+	RevenueSharePct int              `json:"revenue_share_pct"`   // Org revenue share (default 80%)
+	DataSovereignty bool             `json:"data_sovereignty"`    // Tasks must stay within federation
+	AllowedRegions  []string         `json:"allowed_regions"`     // Restrict to specific regions
 	CreatedAt       time.Time        `json:"created_at"`
 	UpdatedAt       time.Time        `json:"updated_at"`
 }
@@ -123,6 +125,8 @@ type RegistryConfig struct {
 	MaxFederations     int  // Maximum number of active federations (0 = unlimited)
 	RequireApproval    bool // New federations need admin approval
 	DefaultSharePolicy SharingPolicy
+	InvitePath         string           // file of redeemed invite codes ("" = not persisted)
+	Now                func() time.Time // clock (nil = time.Now)
 }
 
 // DefaultRegistryConfig returns sensible defaults.
@@ -131,6 +135,7 @@ func DefaultRegistryConfig() RegistryConfig {
 		MaxFederations:     1000,
 		RequireApproval:    false,
 		DefaultSharePolicy: ShareSpare,
+		Now:                time.Now,
 	}
 }
 
@@ -144,15 +149,38 @@ type Registry struct {
 	federations map[string]*Federation                  // fedID → Federation
 	members     map[string]map[string]*FederationMember // fedID → nodeID → Member
 	nodeIndex   map[string]string                       // nodeID → fedID (quick lookup)
+	redeemed    map[string]string                       // nodeID → invite code it joined with
+
+	// auditHook records policy changes (nil = disabled). Called with the
+	// registry lock held, so it must not call back into the registry.
+	auditHook func(action, target, details string)
+}
+
+// SetAuditHook installs a callback invoked for every federation policy change.
+func (r *Registry) SetAuditHook(fn func(action, target, details string)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.auditHook = fn
+}
+
+// auditLocked forwards a policy change to the audit hook, if installed.
+func (r *Registry) auditLocked(action, fedID, details string) {
+	if r.auditHook != nil {
+		r.auditHook(action, fedID, details)
+	}
 }
 
 // NewRegistry creates a federation registry.
 func NewRegistry(cfg RegistryConfig) *Registry {
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
 	return &Registry{
 		config:      cfg,
 		federations: make(map[string]*Federation),
 		members:     make(map[string]map[string]*FederationMember),
 		nodeIndex:   make(map[string]string),
+		redeemed:    make(map[string]string),
 	}
 }
 
@@ -166,12 +194,12 @@ func (r *Registry) CreateFederation(name, adminNodeID string) (*Federation, erro
 
 	name = strings.TrimSpace(name)
 	if len(name) < MinNameLength {
-		return nil, fmt.Errorf("federation name must be at least %d characters", MinNameLength)
+		return nil, fmt.Errorf("%w: name must be at least %d characters", domain.ErrInvalidFederation, MinNameLength)
 	}
 
 	// Check if admin is already in a federation
 	if existingFed, ok := r.nodeIndex[adminNodeID]; ok {
-		return nil, fmt.Errorf("node %s already belongs to federation %s", adminNodeID, existingFed)
+		return nil, fmt.Errorf("%w: node %s is in %s", domain.ErrAlreadyFederated, adminNodeID, existingFed)
 	}
 
 	// Check max federations
@@ -182,17 +210,17 @@ func (r *Registry) CreateFederation(name, adminNodeID string) (*Federation, erro
 		}
 	}
 	if r.config.MaxFederations > 0 && activeCount >= r.config.MaxFederations {
-		return nil, errors.New("maximum number of federations reached")
+		return nil, domain.ErrTooManyFederations
 	}
 
 	// Check name uniqueness
 	for _, fed := range r.federations {
 		if strings.EqualFold(fed.Name, name) && fed.Status != FedDissolved {
-			return nil, fmt.Errorf("federation name %q already exists", name)
+			return nil, fmt.Errorf("%w: %q", domain.ErrFederationNameTaken, name)
 		}
 	}
 
-	now := time.Now()
+	now := r.config.Now()
 	fedID := fmt.Sprintf("fed-%s-%d", sanitizeName(name), now.UnixMilli()%100000)
 
 	status := FedActive
@@ -236,7 +264,7 @@ func (r *Registry) GetFederation(fedID string) (*Federation, error) {
 
 	fed, ok := r.federations[fedID]
 	if !ok {
-		return nil, fmt.Errorf("federation %s not found", fedID)
+		return nil, fmt.Errorf("%w: %s", domain.ErrFederationNotFound, fedID)
 	}
 	return fed, nil
 }
@@ -262,14 +290,15 @@ func (r *Registry) ApproveFederation(fedID string) error {
 
 	fed, ok := r.federations[fedID]
 	if !ok {
-		return fmt.Errorf("federation %s not found", fedID)
+		return fmt.Errorf("%w: %s", domain.ErrFederationNotFound, fedID)
 	}
 	if fed.Status != FedPending {
-		return fmt.Errorf("federation %s is %s, not PENDING", fedID, fed.Status)
+		return fmt.Errorf("%w: %s is %s, not PENDING", domain.ErrFederationState, fedID, fed.Status)
 	}
 
 	fed.Status = FedActive
-	fed.UpdatedAt = time.Now()
+	fed.UpdatedAt = r.config.Now()
+	r.auditLocked("federation.approve", fedID, "")
 	return nil
 }
 
@@ -280,14 +309,15 @@ func (r *Registry) SuspendFederation(fedID string) error {
 
 	fed, ok := r.federations[fedID]
 	if !ok {
-		return fmt.Errorf("federation %s not found", fedID)
+		return fmt.Errorf("%w: %s", domain.ErrFederationNotFound, fedID)
 	}
 	if fed.Status == FedDissolved {
-		return errors.New("cannot suspend a dissolved federation")
+		return fmt.Errorf("%w: cannot suspend a dissolved federation", domain.ErrFederationState)
 	}
 
 	fed.Status = FedSuspended
-	fed.UpdatedAt = time.Now()
+	fed.UpdatedAt = r.config.Now()
+	r.auditLocked("federation.suspend", fedID, "")
 	return nil
 }
 
@@ -298,18 +328,21 @@ func (r *Registry) DissolveFederation(fedID string) error {
 
 	fed, ok := r.federations[fedID]
 	if !ok {
-		return fmt.Errorf("federation %s not found", fedID)
+		return fmt.Errorf("%w: %s", domain.ErrFederationNotFound, fedID)
 	}
 
 	// Release all members from node index
+	members := make([]string, 0, len(r.members[fedID]))
 	for nodeID := range r.members[fedID] {
 		delete(r.nodeIndex, nodeID)
+		members = append(members, nodeID)
 	}
 	delete(r.members, fedID)
 
 	fed.Status = FedDissolved
-	fed.UpdatedAt = time.Now()
-	return nil
+	fed.UpdatedAt = r.config.Now()
+	r.auditLocked("federation.dissolve", fedID, "")
+	return r.forgetInvitesLocked(members...)
 }
 
 // SetSharingPolicy updates how a federation shares capacity.
@@ -319,14 +352,15 @@ func (r *Registry) SetSharingPolicy(fedID string, policy SharingPolicy) error {
 
 	fed, ok := r.federations[fedID]
 	if !ok {
-		return fmt.Errorf("federation %s not found", fedID)
+		return fmt.Errorf("%w: %s", domain.ErrFederationNotFound, fedID)
 	}
 	if fed.Status != FedActive {
-		return fmt.Errorf("federation %s is not active", fedID)
+		return fmt.Errorf("%w: %s is %s, not ACTIVE", domain.ErrFederationState, fedID, fed.Status)
 	}
 
 	fed.SharingPolicy = policy
-	fed.UpdatedAt = time.Now()
+	fed.UpdatedAt = r.config.Now()
+	r.auditLocked("federation.sharing_policy", fedID, "policy="+policy.String())
 	return nil
 }
 
@@ -337,11 +371,12 @@ func (r *Registry) SetAllowedRegions(fedID string, regions []string) error {
 
 	fed, ok := r.federations[fedID]
 	if !ok {
-		return fmt.Errorf("federation %s not found", fedID)
+		return fmt.Errorf("%w: %s", domain.ErrFederationNotFound, fedID)
 	}
 
 	fed.AllowedRegions = regions
-	fed.UpdatedAt = time.Now()
+	fed.UpdatedAt = r.config.Now()
+	r.auditLocked("federation.allowed_regions", fedID, "regions="+strings.Join(regions, ","))
 	return nil
 }
 
@@ -351,26 +386,33 @@ func (r *Registry) SetAllowedRegions(fedID string, regions []string) error {
 func (r *Registry) JoinFederation(fedID, nodeID string) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
+	return r.joinLocked(fedID, nodeID)
+}
 
+// joinLocked adds a node to a federation. Must be called with r.mu held.
+func (r *Registry) joinLocked(fedID, nodeID string) error {
 	fed, ok := r.federations[fedID]
 	if !ok {
-		return fmt.Errorf("federation %s not found", fedID)
+		return fmt.Errorf("%w: %s", domain.ErrFederationNotFound, fedID)
+	}
+	if fed.Status == FedSuspended {
+		return fmt.Errorf("%w: %s", domain.ErrFederationSuspended, fedID)
 	}
 	if fed.Status != FedActive {
-		return fmt.Errorf("federation %s is not active (status: %s)", fedID, fed.Status)
+		return fmt.Errorf("%w: %s is %s, not ACTIVE", domain.ErrFederationState, fedID, fed.Status)
 	}
 
 	// Check if node is already in any federation
 	if existing, exists := r.nodeIndex[nodeID]; exists {
-		return fmt.Errorf("node %s already belongs to federation %s", nodeID, existing)
+		return fmt.Errorf("%w: node %s is in %s", domain.ErrAlreadyFederated, nodeID, existing)
 	}
 
 	members := r.members[fedID]
 	if len(members) >= MaxNodesPerFederation {
-		return fmt.Errorf("federation %s reached max node limit (%d)", fedID, MaxNodesPerFederation)
+		return fmt.Errorf("%w: %s has %d nodes", domain.ErrFederationFull, fedID, MaxNodesPerFederation)
 	}
 
-	now := time.Now()
+	now := r.config.Now()
 	members[nodeID] = &FederationMember{
 		NodeID:     nodeID,
 		FedID:      fedID,
@@ -391,18 +433,18 @@ func (r *Registry) LeaveFederation(nodeID string) error {
 
 	fedID, ok := r.nodeIndex[nodeID]
 	if !ok {
-		return fmt.Errorf("node %s is not in any federation", nodeID)
+		return fmt.Errorf("%w: node %s is not in any federation", domain.ErrNotFederated, nodeID)
 	}
 
 	fed := r.federations[fedID]
 	if fed.AdminNodeID == nodeID {
-		return errors.New("admin cannot leave — dissolve the federation instead")
+		return domain.ErrAdminCannotLeave
 	}
 
 	delete(r.members[fedID], nodeID)
 	delete(r.nodeIndex, nodeID)
-	fed.UpdatedAt = time.Now()
-	return nil
+	fed.UpdatedAt = r.config.Now()
+	return r.forgetInvitesLocked(nodeID)
 }
 
 // Members returns all members of a federation.
@@ -412,7 +454,7 @@ func (r *Registry) Members(fedID string) ([]*FederationMember, error) {
 
 	members, ok := r.members[fedID]
 	if !ok {
-		return nil, fmt.Errorf("federation %s not found", fedID)
+		return nil, fmt.Errorf("%w: %s", domain.ErrFederationNotFound, fedID)
 	}
 
 	result := make([]*FederationMember, 0, len(members))
@@ -499,7 +541,7 @@ func (r *Registry) Stats(fedID string) FederationStats {
 	stats.MemberCount = len(members)
 
 	// Count active members (seen in last 10 minutes)
-	cutoff := time.Now().Add(-10 * time.Minute)
+	cutoff := r.config.Now().Add(-10 * time.Minute)
 	for _, m := range members {
 		if m.LastActive.After(cutoff) {
 			stats.ActiveMembers++
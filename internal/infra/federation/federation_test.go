@@ -1,8 +1,15 @@
 package federation
 
 import (
+	"encoding/base64"
+	"errors"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
+	"github.com/tutu-network/tutu/internal/security"
 )
 
 // ─── Helpers ────────────────────────────────────────────────────────────────
@@ -111,6 +118,32 @@ func TestCreateFederation_MaxFederations(t *testing.T) {
 	}
 }
 
+func TestRegistry_ErrorCodes(t *testing.T) {
+	r := newTestRegistry(t)
+	fed, _ := r.CreateFederation("Acme Corp", "node-1")
+
+	_, err := r.CreateFederation("AB", "node-2")
+	if !errors.Is(err, domain.ErrInvalidFederation) || domain.CodeOf(err) != domain.CodeInvalid {
+		t.Errorf("short name = %v", err)
+	}
+	if _, err := r.CreateFederation("acme corp", "node-2"); !errors.Is(err, domain.ErrFederationNameTaken) {
+		t.Errorf("duplicate name = %v", err)
+	}
+	if _, err := r.GetFederation("fed-missing"); domain.CodeOf(err) != domain.CodeNotFound {
+		t.Errorf("GetFederation(missing) = %v", err)
+	}
+	if err := r.JoinFederation(fed.ID, "node-1"); domain.CodeOf(err) != domain.CodeConflict {
+		t.Errorf("join twice = %v", err)
+	}
+	if err := r.LeaveFederation("node-1"); !errors.Is(err, domain.ErrAdminCannotLeave) {
+		t.Errorf("admin leave = %v", err)
+	}
+	r.SuspendFederation(fed.ID)
+	if err := r.JoinFederation(fed.ID, "node-3"); !errors.Is(err, domain.ErrFederationSuspended) {
+		t.Errorf("join suspended = %v", err)
+	}
+}
+
 // ─── Lifecycle Tests ────────────────────────────────────────────────────────
 
 func TestApproveFederation(t *testing.T) {
@@ -360,6 +393,30 @@ func TestSetAllowedRegions(t *testing.T) {
 	}
 }
 
+func TestAuditHook_PolicyChanges(t *testing.T) {
+	r := newTestRegistry(t)
+	var actions []string
+	r.SetAuditHook(func(action, target, details string) {
+		actions = append(actions, action)
+	})
+
+	fed, _ := r.CreateFederation("TestCorp", "node-admin")
+	r.SetSharingPolicy(fed.ID, ShareAll)
+	r.SetAllowedRegions(fed.ID, []string{"us-east-1"})
+	r.SuspendFederation(fed.ID)
+	r.DissolveFederation(fed.ID)
+
+	want := []string{"federation.sharing_policy", "federation.allowed_regions", "federation.suspend", "federation.dissolve"}
+	if len(actions) != len(want) {
+		t.Fatalf("audit actions = %v, want %v", actions, want)
+	}
+	for i := range want {
+		if actions[i] != want[i] {
+			t.Errorf("action[%d] = %q, want %q", i, actions[i], want[i])
+		}
+	}
+}
+
 // ─── Stats + ActiveCount Tests ─────────────────────────────────────────────
 
 func TestStats(t *testing.T) {
@@ -419,3 +476,116 @@ func TestSharingPolicyString(t *testing.T) {
 		}
 	}
 }
+
+// ─── Invite Tests ───────────────────────────────────────────────────────────
+
+func newTestKeypair(t *testing.T) *security.Keypair {
+	t.Helper()
+	kp, err := security.GenerateKeypair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return kp
+}
+
+func TestInvite_RedeemOnAnotherNode(t *testing.T) {
+	clock := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := func() time.Time { return clock }
+	issuer := NewRegistry(RegistryConfig{Now: now})
+	joiner := NewRegistry(RegistryConfig{Now: now, InvitePath: filepath.Join(t.TempDir(), "invites.json")})
+	kp := newTestKeypair(t)
+	fed, _ := issuer.CreateFederation("Acme Corp", "node-admin")
+
+	if _, err := issuer.CreateInvite(fed.ID, "node-other", kp, time.Hour); !errors.Is(err, domain.ErrNotFederationAdmin) {
+		t.Errorf("non-admin invite: err = %v, want ErrNotFederationAdmin", err)
+	}
+	inv, err := issuer.CreateInvite(fed.ID, "node-admin", kp, time.Hour)
+	if err != nil {
+		t.Fatalf("CreateInvite: %v", err)
+	}
+	if _, err := issuer.CreateInvite(fed.ID, "node-admin", newTestKeypair(t), time.Hour); !errors.Is(err, domain.ErrNotFederationAdmin) {
+		t.Errorf("invite with another key: err = %v, want ErrNotFederationAdmin", err)
+	}
+
+	// The joining node has never heard of the federation
+	fedID, err := joiner.RedeemInvite(" "+inv.Code+" ", "node-1")
+	if err != nil || fedID != fed.ID {
+		t.Fatalf("RedeemInvite = %q, %v", fedID, err)
+	}
+	if got, _ := joiner.NodeFederation("node-1"); got != fed.ID {
+		t.Errorf("node-1 federation = %q, want %q", got, fed.ID)
+	}
+	if got, _ := joiner.NodeFederation("node-admin"); got != fed.ID {
+		t.Errorf("admin federation = %q, want %q", got, fed.ID)
+	}
+	if _, err := joiner.RedeemInvite(inv.Code, "node-1"); !errors.Is(err, domain.ErrAlreadyFederated) {
+		t.Errorf("rejoin: err = %v, want ErrAlreadyFederated", err)
+	}
+
+	// Membership survives a restart, and leaving forgets the code
+	restarted := NewRegistry(RegistryConfig{Now: now, InvitePath: joiner.config.InvitePath})
+	if err := restarted.LoadInvites(); err != nil {
+		t.Fatalf("LoadInvites: %v", err)
+	}
+	if got, _ := restarted.NodeFederation("node-1"); got != fed.ID {
+		t.Errorf("after restart node-1 federation = %q, want %q", got, fed.ID)
+	}
+	if err := restarted.LeaveFederation("node-1"); err != nil {
+		t.Fatalf("LeaveFederation: %v", err)
+	}
+	again := NewRegistry(RegistryConfig{Now: now, InvitePath: joiner.config.InvitePath})
+	if err := again.LoadInvites(); err != nil {
+		t.Fatalf("LoadInvites: %v", err)
+	}
+	if _, ok := again.NodeFederation("node-1"); ok {
+		t.Error("node-1 rejoined after leaving")
+	}
+}
+
+func TestInvite_Rejected(t *testing.T) {
+	clock := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	issuer := NewRegistry(RegistryConfig{Now: func() time.Time { return clock }})
+	kp := newTestKeypair(t)
+	fed, _ := issuer.CreateFederation("Acme Corp", "node-admin")
+	inv, _ := issuer.CreateInvite(fed.ID, "node-admin", kp, time.Hour)
+
+	// A code for the same federation signed by an impostor's key
+	impostor := NewRegistry(RegistryConfig{Now: func() time.Time { return clock }})
+	fake, _ := impostor.CreateFederation("Acme Corp", "node-admin")
+	impostor.federations[fed.ID] = fake
+	fake.ID = fed.ID
+	forged, err := impostor.CreateInvite(fed.ID, "node-admin", newTestKeypair(t), time.Hour)
+	if err != nil {
+		t.Fatalf("CreateInvite: %v", err)
+	}
+
+	payload, sig, _ := strings.Cut(strings.TrimPrefix(inv.Code, invitePrefix), ".")
+	raw, _ := base64.RawURLEncoding.DecodeString(payload)
+	raw = []byte(strings.Replace(string(raw), `"Acme Corp"`, `"Evil Corp"`, 1))
+	tampered := invitePrefix + base64.RawURLEncoding.EncodeToString(raw) + "." + sig
+
+	joiner := NewRegistry(RegistryConfig{Now: func() time.Time { return clock }})
+	if _, err := joiner.RedeemInvite(inv.Code, "node-1"); err != nil {
+		t.Fatalf("RedeemInvite: %v", err)
+	}
+	tests := []struct {
+		name string
+		code string
+	}{
+		{"unknown", "NOPE-NOPE"},
+		{"tampered", tampered},
+		{"signed by another key", forged.Code},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := joiner.RedeemInvite(tt.code, "node-2"); !errors.Is(err, domain.ErrInviteInvalid) {
+				t.Errorf("err = %v, want ErrInviteInvalid", err)
+			}
+		})
+	}
+
+	clock = clock.Add(time.Hour)
+	if _, err := joiner.RedeemInvite(inv.Code, "node-2"); !errors.Is(err, domain.ErrInviteInvalid) {
+		t.Errorf("expired code: err = %v, want ErrInviteInvalid", err)
+	}
+}
@@ -0,0 +1,278 @@
+package federation
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
+	"github.com/tutu-network/tutu/internal/security"
+)
+
+// ─── Invites ────────────────────────────────────────────────────────────────
+// A federation admin hands out invite codes so a new node can join without
+// knowing the federation ID. Every node keeps its own registry, so the code
+// cannot be a lookup key into the issuer's memory — it carries everything
+// the joining node needs:
+//
+//  1. The admin's node signs the federation's settings, a random ID and an
+//     expiry with its node key; the code is the token and its signature
+//  2. The joining node checks the signature and expiry against its own
+//     clock, adopts the federation if it does not know it yet, and joins
+//  3. A federation is pinned to the key that signed its first code: codes
+//     for it signed by any other key are rejected
+//  4. Redeemed codes are saved to RegistryConfig.InvitePath and replayed by
+//     LoadInvites, so membership survives a restart; leaving or dissolving
+//     the federation drops them
+//
+// A code admits any number of nodes until it expires: redemptions are not
+// reported back to the issuer, so a use limit could not be enforced.
+
+// invitePrefix versions the invite code format.
+const invitePrefix = "tutu-invite-v1."
+
+// Invite is a code that admits nodes to a federation.
+type Invite struct {
+	Code      string    `json:"code"`
+	FedID     string    `json:"fed_id"`
+	CreatedBy string    `json:"created_by"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// inviteToken is the signed content of an invite code.
+type inviteToken struct {
+	ID         string     `json:"id"`
+	Federation Federation `json:"federation"` // settings when the code was issued
+	IssuerKey  string     `json:"issuer_key"` // hex ed25519 public key
+	ExpiresAt  time.Time  `json:"expires_at"`
+}
+
+// inviteFile is the on-disk form of the redeemed codes.
+type inviteFile struct {
+	Redeemed map[string]string `json:"redeemed"` // nodeID → code
+}
+
+// CreateInvite issues an invite code for a federation, signed with kp. Only
+// its admin may issue codes, and always with the same key.
+func (r *Registry) CreateInvite(fedID, nodeID string, kp *security.Keypair, ttl time.Duration) (Invite, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	fed, ok := r.federations[fedID]
+	if !ok {
+		return Invite{}, fmt.Errorf("%w: %s", domain.ErrFederationNotFound, fedID)
+	}
+	if fed.AdminNodeID != nodeID {
+		return Invite{}, fmt.Errorf("%w: only the admin of %s can invite", domain.ErrNotFederationAdmin, fedID)
+	}
+	if fed.Status != FedActive {
+		return Invite{}, fmt.Errorf("%w: %s is %s, not ACTIVE", domain.ErrFederationState, fedID, fed.Status)
+	}
+	if ttl <= 0 {
+		return Invite{}, fmt.Errorf("%w: invite needs a positive lifetime", domain.ErrInvalidFederation)
+	}
+	key := kp.PublicKeyHex()
+	if fed.AdminKey != "" && fed.AdminKey != key {
+		return Invite{}, fmt.Errorf("%w: %s invites are signed by another key", domain.ErrNotFederationAdmin, fedID)
+	}
+
+	var id [8]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return Invite{}, fmt.Errorf("generate invite code: %w", err)
+	}
+	fed.AdminKey = key
+	tok := inviteToken{
+		ID:         hex.EncodeToString(id[:]),
+		Federation: *fed,
+		IssuerKey:  key,
+		ExpiresAt:  r.config.Now().Add(ttl).UTC(),
+	}
+	payload, err := json.Marshal(tok)
+	if err != nil {
+		return Invite{}, fmt.Errorf("encode invite: %w", err)
+	}
+	enc := base64.RawURLEncoding
+	inv := Invite{
+		Code:      invitePrefix + enc.EncodeToString(payload) + "." + enc.EncodeToString(kp.Sign(payload)),
+		FedID:     fedID,
+		CreatedBy: nodeID,
+		ExpiresAt: tok.ExpiresAt,
+	}
+	r.auditLocked("federation.invite", fedID, fmt.Sprintf("id=%s expires=%s", tok.ID, tok.ExpiresAt.Format(time.RFC3339)))
+	return inv, nil
+}
+
+// RedeemInvite joins nodeID to the federation an invite code belongs to
+// and returns the federation ID.
+func (r *Registry) RedeemInvite(code, nodeID string) (string, error) {
+	code = strings.TrimSpace(code)
+	tok, err := parseInvite(code)
+	if err != nil {
+		return "", err
+	}
+	if !r.config.Now().Before(tok.ExpiresAt) {
+		return "", fmt.Errorf("%w: code expired at %s", domain.ErrInviteInvalid, tok.ExpiresAt.Format(time.RFC3339))
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.redeemLocked(tok, nodeID); err != nil {
+		return "", err
+	}
+	r.redeemed[nodeID] = code
+	if err := r.saveInvitesLocked(); err != nil {
+		delete(r.redeemed, nodeID)
+		delete(r.members[tok.Federation.ID], nodeID)
+		delete(r.nodeIndex, nodeID)
+		return "", err
+	}
+	return tok.Federation.ID, nil
+}
+
+// LoadInvites replays the codes saved to RegistryConfig.InvitePath,
+// rejoining the federations this node's members redeemed them for. Codes
+// that no longer apply are dropped.
+func (r *Registry) LoadInvites() error {
+	if r.config.InvitePath == "" {
+		return nil
+	}
+	data, err := os.ReadFile(r.config.InvitePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("federation: load invites: %w", err)
+	}
+	var f inviteFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return fmt.Errorf("federation: load invites: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var errs []error
+	for nodeID, code := range f.Redeemed {
+		tok, err := parseInvite(code)
+		if err == nil {
+			// The code was valid when redeemed; expiry only limits new joins
+			err = r.redeemLocked(tok, nodeID)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("node %s: %w", nodeID, err))
+			continue
+		}
+		r.redeemed[nodeID] = code
+	}
+	if len(errs) > 0 {
+		errs = append(errs, r.saveInvitesLocked())
+	}
+	return errors.Join(errs...)
+}
+
+// redeemLocked adopts the federation a verified token describes, if this
+// registry does not know it yet, and joins nodeID to it. Must be called
+// with r.mu held.
+func (r *Registry) redeemLocked(tok inviteToken, nodeID string) error {
+	snap := tok.Federation
+	fed, ok := r.federations[snap.ID]
+	switch {
+	case ok && fed.AdminNodeID != snap.AdminNodeID:
+		return fmt.Errorf("%w: %s has a different admin here", domain.ErrInviteInvalid, snap.ID)
+	case ok && fed.AdminKey != "" && fed.AdminKey != tok.IssuerKey:
+		return fmt.Errorf("%w: %s invites are signed by another key", domain.ErrInviteInvalid, snap.ID)
+	case ok:
+		fed.AdminKey = tok.IssuerKey
+	case snap.AdminKey != tok.IssuerKey:
+		return fmt.Errorf("%w: code not signed by the federation admin", domain.ErrInviteInvalid)
+	default:
+		fed = &snap
+		fed.Status = FedActive
+		r.federations[fed.ID] = fed
+		r.members[fed.ID] = make(map[string]*FederationMember)
+		if _, taken := r.nodeIndex[fed.AdminNodeID]; !taken {
+			r.members[fed.ID][fed.AdminNodeID] = &FederationMember{
+				NodeID:   fed.AdminNodeID,
+				FedID:    fed.ID,
+				Role:     "admin",
+				JoinedAt: fed.CreatedAt,
+			}
+			r.nodeIndex[fed.AdminNodeID] = fed.ID
+		}
+		r.auditLocked("federation.adopt", fed.ID, "admin="+fed.AdminNodeID)
+	}
+	return r.joinLocked(fed.ID, nodeID)
+}
+
+// forgetInvitesLocked drops the saved codes of nodes that left their
+// federation. Must be called with r.mu held.
+func (r *Registry) forgetInvitesLocked(nodeIDs ...string) error {
+	changed := false
+	for _, id := range nodeIDs {
+		if _, ok := r.redeemed[id]; ok {
+			delete(r.redeemed, id)
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	return r.saveInvitesLocked()
+}
+
+// saveInvitesLocked writes the redeemed codes to InvitePath. Must be called
+// with r.mu held.
+func (r *Registry) saveInvitesLocked() error {
+	path := r.config.InvitePath
+	if path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(inviteFile{Redeemed: r.redeemed}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("federation: save invites: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("federation: save invites: %w", err)
+	}
+	if err := os.WriteFile(path+".tmp", data, 0o600); err != nil {
+		return fmt.Errorf("federation: save invites: %w", err)
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// parseInvite decodes an invite code and checks its signature.
+func parseInvite(code string) (inviteToken, error) {
+	body, ok := strings.CutPrefix(code, invitePrefix)
+	if !ok {
+		return inviteToken{}, domain.ErrInviteInvalid
+	}
+	payloadPart, sigPart, ok := strings.Cut(body, ".")
+	if !ok {
+		return inviteToken{}, domain.ErrInviteInvalid
+	}
+	enc := base64.RawURLEncoding
+	payload, err1 := enc.DecodeString(payloadPart)
+	sig, err2 := enc.DecodeString(sigPart)
+	if err1 != nil || err2 != nil {
+		return inviteToken{}, fmt.Errorf("%w: malformed code", domain.ErrInviteInvalid)
+	}
+	var tok inviteToken
+	if err := json.Unmarshal(payload, &tok); err != nil {
+		return inviteToken{}, fmt.Errorf("%w: malformed code", domain.ErrInviteInvalid)
+	}
+	pub, err := hex.DecodeString(tok.IssuerKey)
+	if err != nil || len(pub) != ed25519.PublicKeySize || !security.Verify(payload, sig, ed25519.PublicKey(pub)) {
+		return inviteToken{}, fmt.Errorf("%w: bad signature", domain.ErrInviteInvalid)
+	}
+	if tok.Federation.ID == "" || tok.Federation.AdminNodeID == "" {
+		return inviteToken{}, fmt.Errorf("%w: no federation", domain.ErrInviteInvalid)
+	}
+	return tok, nil
+}
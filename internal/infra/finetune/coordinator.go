@@ -29,6 +29,7 @@ var (
 	ErrGradientMismatch  = errors.New("gradient dimensions do not match")
 	ErrCheckpointMissing = errors.New("checkpoint not available")
 	ErrEpochTimeout      = errors.New("epoch exceeded time limit")
+	ErrNodeThrottled     = errors.New("node is thermally throttled")
 )
 
 // ─── Job Types ──────────────────────────────────────────────────────────────
@@ -157,6 +158,7 @@ type CoordinatorConfig struct {
 	MaxConcurrentJobs int           // Max simultaneous fine-tune jobs
 	EpochTimeout      time.Duration // Max time for one epoch across all nodes
 	CreditPerMinute   int64         // Fine-tuning credit cost per minute
+	HeavyShardSamples int           // Shards this large avoid throttled nodes (0 = every shard)
 }
 
 // DefaultCoordinatorConfig returns production defaults.
@@ -165,6 +167,7 @@ func DefaultCoordinatorConfig() CoordinatorConfig {
 		MaxConcurrentJobs: 3,
 		EpochTimeout:      30 * time.Minute,
 		CreditPerMinute:   10, // Architecture Part X: 10 cr/min fine-tuning
+		HeavyShardSamples: 1000,
 	}
 }
 
@@ -176,6 +179,8 @@ type Coordinator struct {
 	shards map[string][]DataShard      // jobID → shards
 	grads  map[string][]GradientUpdate // jobID → gradient updates
 	checks map[string][]Checkpoint     // jobID → checkpoints
+
+	throttled func(nodeID string) bool // nil = no node is throttled
 }
 
 // NewCoordinator creates a fine-tuning coordinator.
@@ -252,7 +257,23 @@ func (c *Coordinator) ListJobs() []FineTuneJob {
 	return result
 }
 
-// AssignShards records how dataset was split across nodes.
+// SetThrottled installs the check for nodes in sustained thermal throttle.
+// Heavy shards are not assigned to them: a hot laptop would only throttle
+// further and hold back every epoch.
+func (c *Coordinator) SetThrottled(fn func(nodeID string) bool) {
+	c.mu.Lock()
+	c.throttled = fn
+	c.mu.Unlock()
+}
+
+// IsHeavy reports whether a shard is large enough to keep off throttled
+// nodes.
+func (c *Coordinator) IsHeavy(shard DataShard) bool {
+	return shard.SampleCount >= c.config.HeavyShardSamples
+}
+
+// AssignShards records how dataset was split across nodes. It fails with
+// ErrNodeThrottled if a heavy shard is placed on a throttled node.
 func (c *Coordinator) AssignShards(jobID string, shards []DataShard) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -265,6 +286,13 @@ func (c *Coordinator) AssignShards(jobID string, shards []DataShard) error {
 	if len(shards) < job.MinNodes {
 		return ErrInsufficientNodes
 	}
+	if c.throttled != nil {
+		for _, s := range shards {
+			if c.IsHeavy(s) && c.throttled(s.NodeID) {
+				return fmt.Errorf("%w: shard %d on %s", ErrNodeThrottled, s.ShardIndex, s.NodeID)
+			}
+		}
+	}
 
 	job.Status = JobSharding
 	c.shards[jobID] = shards
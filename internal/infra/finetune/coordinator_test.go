@@ -1,6 +1,7 @@
 package finetune
 
 import (
+	"errors"
 	"fmt"
 	"testing"
 	"time"
@@ -159,6 +160,32 @@ func TestCoordinator_AssignShards_Insufficient(t *testing.T) {
 	}
 }
 
+func TestCoordinator_AssignShards_Throttled(t *testing.T) {
+	c := NewCoordinator(DefaultCoordinatorConfig())
+	c.SetThrottled(func(nodeID string) bool { return nodeID == "hot-laptop" })
+	c.SubmitJob(FineTuneJob{ID: "j3", MinNodes: 2})
+
+	err := c.AssignShards("j3", []DataShard{
+		{ShardIndex: 0, NodeID: "desktop", SampleCount: 5000},
+		{ShardIndex: 1, NodeID: "hot-laptop", SampleCount: 5000},
+	})
+	if !errors.Is(err, ErrNodeThrottled) {
+		t.Fatalf("err = %v, want ErrNodeThrottled", err)
+	}
+	if len(c.Shards("j3")) != 0 {
+		t.Error("rejected assignment was recorded")
+	}
+
+	// A light shard may still go to the throttled node
+	err = c.AssignShards("j3", []DataShard{
+		{ShardIndex: 0, NodeID: "desktop", SampleCount: 9900},
+		{ShardIndex: 1, NodeID: "hot-laptop", SampleCount: 100},
+	})
+	if err != nil {
+		t.Fatalf("AssignShards with light shard: %v", err)
+	}
+}
+
 func TestCoordinator_TrainingLifecycle(t *testing.T) {
 	c := newTestCoordinator()
 	c.SubmitJob(FineTuneJob{ID: "life", MinNodes: 1})
@@ -0,0 +1,407 @@
+package gossip
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// ─── Model Availability Index ───────────────────────────────────────────────
+// Every node announces which models (and which version of each, by digest)
+// it hosts. Announcements are piggybacked on SWIM PING/ACK messages exactly
+// like membership updates, so they reach the whole cluster in O(log N)
+// protocol periods. Each node keeps the freshest announcement per peer and
+// treats entries older than TTL as stale — a node that stops re-announcing
+// (crashed, partitioned, gossip disabled) silently drops out of the index.
+//
+// Ordering: announcements carry a per-node sequence number. A lower or equal
+// sequence number than the one already held is ignored, so re-delivered or
+// reordered packets cannot roll the index back.
+//
+// A model the node has benchmarked also carries its measured generation
+// speed, which the scheduler adds to its latency estimate for the node.
+
+// ModelVersion identifies a hosted model build.
+type ModelVersion struct {
+	Name   string `json:"name"`
+	Digest string `json:"digest,omitempty"`
+
+	// Benchmarked speed on the hosting node (0 = not measured).
+	TokensPerSec float64 `json:"tok_per_sec,omitempty"`
+	TTFTMs       float64 `json:"ttft_ms,omitempty"`
+}
+
+// Announcement is a node's full list of hosted models.
+type Announcement struct {
+	NodeID string         `json:"node_id"`
+	Seq    uint64         `json:"seq"`
+	Models []ModelVersion `json:"models"`
+}
+
+// AvailabilityConfig controls announcement refresh and staleness.
+type AvailabilityConfig struct {
+	TTL             time.Duration // entries older than this are stale (default: 60s)
+	RefreshInterval time.Duration // how often the local node re-announces (default: 20s)
+
+	// Local, if set, is polled on every refresh for this node's hosted models.
+	Local func() []ModelVersion
+
+	Now func() time.Time // injectable clock (default: time.Now)
+}
+
+// DefaultAvailabilityConfig returns conservative defaults: three refreshes
+// fit into one TTL, so a single lost round never expires a live node.
+func DefaultAvailabilityConfig() AvailabilityConfig {
+	return AvailabilityConfig{
+		TTL:             60 * time.Second,
+		RefreshInterval: 20 * time.Second,
+	}
+}
+
+// NodeAvailability is one node's entry in the index.
+type NodeAvailability struct {
+	NodeID    string         `json:"node_id"`
+	Models    []ModelVersion `json:"models"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	Stale     bool           `json:"stale"`
+}
+
+// availEntry is the index's record of one node.
+type availEntry struct {
+	seq       uint64
+	models    map[string]string // name → digest
+	list      []ModelVersion
+	updatedAt time.Time
+}
+
+// AvailabilityIndex is a queryable, staleness-aware map of which nodes host
+// which models. It is safe for concurrent use.
+type AvailabilityIndex struct {
+	mu     sync.RWMutex
+	cfg    AvailabilityConfig
+	selfID string
+	self   *availEntry
+	nodes  map[string]*availEntry // remote nodeID → entry
+}
+
+// NewAvailabilityIndex creates an index for the local node selfID.
+func NewAvailabilityIndex(selfID string, cfg AvailabilityConfig) *AvailabilityIndex {
+	def := DefaultAvailabilityConfig()
+	if cfg.TTL <= 0 {
+		cfg.TTL = def.TTL
+	}
+	if cfg.RefreshInterval <= 0 {
+		cfg.RefreshInterval = def.RefreshInterval
+	}
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+	return &AvailabilityIndex{
+		cfg:    cfg,
+		selfID: selfID,
+		self:   &availEntry{seq: uint64(time.Now().UnixNano()), models: map[string]string{}}, // above any seq a previous run announced
+		nodes:  make(map[string]*availEntry),
+	}
+}
+
+// SetLocal replaces the local node's hosted models and returns the
+// announcement to disseminate. The sequence number always advances, so a
+// re-announcement of an unchanged list still refreshes peers' TTL. It
+// starts from the clock rather than 1, so peers that still hold a previous
+// run's announcement accept this run's instead of ignoring it until expiry.
+func (x *AvailabilityIndex) SetLocal(models []ModelVersion) Announcement {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.self = newAvailEntry(x.self.seq+1, models, x.cfg.Now())
+	return Announcement{NodeID: x.selfID, Seq: x.self.seq, Models: x.self.list}
+}
+
+// Refresh re-reads the local model list from cfg.Local (if set) and returns
+// the resulting announcement.
+func (x *AvailabilityIndex) Refresh() Announcement {
+	if x.cfg.Local != nil {
+		return x.SetLocal(x.cfg.Local())
+	}
+	x.mu.RLock()
+	models := x.self.list
+	x.mu.RUnlock()
+	return x.SetLocal(models)
+}
+
+// Local returns the local node's current announcement.
+func (x *AvailabilityIndex) Local() Announcement {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return Announcement{NodeID: x.selfID, Seq: x.self.seq, Models: x.self.list}
+}
+
+// Apply merges a remote announcement. Returns true if it was newer than what
+// the index held (and should therefore be re-gossiped).
+func (x *AvailabilityIndex) Apply(a Announcement) bool {
+	if a.NodeID == "" || a.NodeID == x.selfID {
+		return false
+	}
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if cur, ok := x.nodes[a.NodeID]; ok && a.Seq <= cur.seq {
+		return false
+	}
+	x.nodes[a.NodeID] = newAvailEntry(a.Seq, a.Models, x.cfg.Now())
+	return true
+}
+
+// Remove forgets a node (e.g. when SWIM declares it dead).
+func (x *AvailabilityIndex) Remove(nodeID string) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	delete(x.nodes, nodeID)
+}
+
+// Expire drops stale remote entries and returns how many were removed.
+func (x *AvailabilityIndex) Expire() int {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	now := x.cfg.Now()
+	n := 0
+	for id, e := range x.nodes {
+		if x.staleLocked(e, now) {
+			delete(x.nodes, id)
+			n++
+		}
+	}
+	return n
+}
+
+// HasModel reports whether nodeID hosts model according to fresh data.
+// Unknown and stale nodes report false.
+func (x *AvailabilityIndex) HasModel(nodeID, model string) bool {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	e := x.entryLocked(nodeID)
+	if e == nil {
+		return false
+	}
+	_, ok := e.models[model]
+	return ok
+}
+
+// Model returns nodeID's announced entry for model according to fresh data.
+func (x *AvailabilityIndex) Model(nodeID, model string) (ModelVersion, bool) {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	e := x.entryLocked(nodeID)
+	if e == nil {
+		return ModelVersion{}, false
+	}
+	i := sort.Search(len(e.list), func(i int) bool { return e.list[i].Name >= model })
+	if i < len(e.list) && e.list[i].Name == model {
+		return e.list[i], true
+	}
+	return ModelVersion{}, false
+}
+
+// Hosts returns the nodes (including this one) that host model, sorted by
+// node ID. Stale entries are excluded.
+func (x *AvailabilityIndex) Hosts(model string) []string {
+	return x.HostsVersion(model, "")
+}
+
+// HostsVersion is Hosts restricted to a specific digest. An empty digest
+// matches any version.
+func (x *AvailabilityIndex) HostsVersion(model, digest string) []string {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	now := x.cfg.Now()
+
+	var out []string
+	match := func(id string, e *availEntry) {
+		d, ok := e.models[model]
+		if ok && (digest == "" || d == digest) {
+			out = append(out, id)
+		}
+	}
+	match(x.selfID, x.self)
+	for id, e := range x.nodes {
+		if !x.staleLocked(e, now) {
+			match(id, e)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Snapshot returns every known node's entry, including stale ones (flagged),
+// sorted by node ID. The local node is always first.
+func (x *AvailabilityIndex) Snapshot() []NodeAvailability {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	now := x.cfg.Now()
+
+	out := make([]NodeAvailability, 0, len(x.nodes)+1)
+	for id, e := range x.nodes {
+		out = append(out, NodeAvailability{
+			NodeID:    id,
+			Models:    e.list,
+			UpdatedAt: e.updatedAt,
+			Stale:     x.staleLocked(e, now),
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].NodeID < out[j].NodeID })
+	self := NodeAvailability{NodeID: x.selfID, Models: x.self.list, UpdatedAt: x.self.updatedAt}
+	return append([]NodeAvailability{self}, out...)
+}
+
+// Len returns the number of remote nodes in the index (fresh or stale).
+func (x *AvailabilityIndex) Len() int {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return len(x.nodes)
+}
+
+// entryLocked returns the fresh entry for nodeID, or nil.
+func (x *AvailabilityIndex) entryLocked(nodeID string) *availEntry {
+	if nodeID == x.selfID {
+		return x.self
+	}
+	e, ok := x.nodes[nodeID]
+	if !ok || x.staleLocked(e, x.cfg.Now()) {
+		return nil
+	}
+	return e
+}
+
+func (x *AvailabilityIndex) staleLocked(e *availEntry, now time.Time) bool {
+	return now.Sub(e.updatedAt) > x.cfg.TTL
+}
+
+func newAvailEntry(seq uint64, models []ModelVersion, now time.Time) *availEntry {
+	e := &availEntry{
+		seq:       seq,
+		models:    make(map[string]string, len(models)),
+		list:      make([]ModelVersion, 0, len(models)),
+		updatedAt: now,
+	}
+	for _, m := range models {
+		if m.Name == "" {
+			continue
+		}
+		if _, dup := e.models[m.Name]; dup {
+			continue
+		}
+		e.models[m.Name] = m.Digest
+		e.list = append(e.list, m)
+	}
+	sort.Slice(e.list, func(i, j int) bool { return e.list[i].Name < e.list[j].Name })
+	return e
+}
+
+// ─── SWIM Integration ───────────────────────────────────────────────────────
+
+// SetAvailability attaches an availability index. The local announcement is
+// refreshed every RefreshInterval and piggybacked alongside membership
+// updates; received announcements are merged and re-gossiped when new.
+func (s *SWIM) SetAvailability(idx *AvailabilityIndex) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.avail = idx
+	s.lastAnnounce = time.Time{}
+}
+
+// Availability returns the attached index, or nil.
+func (s *SWIM) Availability() *AvailabilityIndex {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.avail
+}
+
+// Announce immediately disseminates a new local model list (e.g. after a
+// pull or removal) instead of waiting for the next refresh.
+func (s *SWIM) Announce(models []ModelVersion) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.avail == nil {
+		return
+	}
+	s.queueAnnouncement(s.avail.SetLocal(models))
+	s.lastAnnounce = s.avail.cfg.Now()
+}
+
+// refreshAvailability re-announces the local node and expires stale peers.
+// Called once per probe cycle.
+func (s *SWIM) refreshAvailability() {
+	s.mu.RLock()
+	idx, last := s.avail, s.lastAnnounce
+	s.mu.RUnlock()
+	if idx == nil {
+		return
+	}
+
+	idx.Expire()
+	now := idx.cfg.Now()
+	if now.Sub(last) < idx.cfg.RefreshInterval {
+		return
+	}
+	a := idx.Refresh() // may call cfg.Local — outside s.mu
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.avail == idx {
+		s.queueAnnouncement(a)
+		s.lastAnnounce = now
+	}
+}
+
+// applyAnnouncement merges a received announcement and re-queues it for
+// dissemination if it was new.
+func (s *SWIM) applyAnnouncement(a Announcement) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.avail != nil && s.avail.Apply(a) {
+		s.queueAnnouncement(a)
+	}
+}
+
+// forgetAvailability drops a dead node's announcement.
+// Must be called with s.mu held.
+func (s *SWIM) forgetAvailability(nodeID string) {
+	if s.avail != nil {
+		s.avail.Remove(nodeID)
+	}
+}
+
+// queueAnnouncement adds an announcement to the piggyback queue, replacing
+// any older one from the same node. Must be called with s.mu held.
+func (s *SWIM) queueAnnouncement(a Announcement) {
+	for i, q := range s.announce {
+		if q.NodeID == a.NodeID {
+			s.announce = append(s.announce[:i], s.announce[i+1:]...)
+			break
+		}
+	}
+	s.announce = append(s.announce, a)
+	s.announceLeft[a.NodeID] = s.config.Lambda * s.logN()
+}
+
+// drainAnnouncements returns pending announcements for piggybacking.
+func (s *SWIM) drainAnnouncements() []Announcement {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.announce) == 0 {
+		return nil
+	}
+
+	result := make([]Announcement, 0, len(s.announce))
+	remaining := make([]Announcement, 0)
+	for _, a := range s.announce {
+		result = append(result, a)
+		s.announceLeft[a.NodeID]--
+		if s.announceLeft[a.NodeID] > 0 {
+			remaining = append(remaining, a)
+		} else {
+			delete(s.announceLeft, a.NodeID)
+		}
+	}
+	s.announce = remaining
+	return result
+}
@@ -0,0 +1,213 @@
+package gossip
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func testAvailability(self string, clock *time.Time) *AvailabilityIndex {
+	cfg := DefaultAvailabilityConfig()
+	cfg.TTL = 10 * time.Second
+	cfg.Now = func() time.Time { return *clock }
+	return NewAvailabilityIndex(self, cfg)
+}
+
+func TestAvailabilityIndex_ApplyOrdering(t *testing.T) {
+	clock := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	x := testAvailability("self", &clock)
+
+	tests := []struct {
+		name  string
+		a     Announcement
+		apply bool
+		hosts []string
+	}{
+		{"first", Announcement{NodeID: "n1", Seq: 2, Models: []ModelVersion{{Name: "llama3"}}}, true, []string{"n1"}},
+		{"older seq ignored", Announcement{NodeID: "n1", Seq: 1}, false, []string{"n1"}},
+		{"duplicate ignored", Announcement{NodeID: "n1", Seq: 2}, false, []string{"n1"}},
+		{"newer replaces", Announcement{NodeID: "n1", Seq: 3, Models: []ModelVersion{{Name: "phi3"}}}, true, nil},
+		{"self ignored", Announcement{NodeID: "self", Seq: 9, Models: []ModelVersion{{Name: "llama3"}}}, false, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := x.Apply(tt.a); got != tt.apply {
+				t.Errorf("Apply() = %v, want %v", got, tt.apply)
+			}
+			if got := x.Hosts("llama3"); !reflect.DeepEqual(got, tt.hosts) {
+				t.Errorf("Hosts(llama3) = %v, want %v", got, tt.hosts)
+			}
+		})
+	}
+}
+
+func TestAvailabilityIndex_Staleness(t *testing.T) {
+	clock := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	x := testAvailability("self", &clock)
+	x.SetLocal([]ModelVersion{{Name: "llama3", Digest: "sha256:aa"}})
+	x.Apply(Announcement{NodeID: "n1", Seq: 1, Models: []ModelVersion{{Name: "llama3", Digest: "sha256:bb"}}})
+
+	if got := x.Hosts("llama3"); !reflect.DeepEqual(got, []string{"n1", "self"}) {
+		t.Fatalf("Hosts = %v, want [n1 self]", got)
+	}
+	if got := x.HostsVersion("llama3", "sha256:bb"); !reflect.DeepEqual(got, []string{"n1"}) {
+		t.Errorf("HostsVersion(bb) = %v, want [n1]", got)
+	}
+
+	clock = clock.Add(11 * time.Second)
+	if x.HasModel("n1", "llama3") {
+		t.Error("stale node should not report the model")
+	}
+	if !x.HasModel("self", "llama3") {
+		t.Error("local node never goes stale")
+	}
+	snap := x.Snapshot()
+	if len(snap) != 2 || snap[0].NodeID != "self" || !snap[1].Stale {
+		t.Errorf("Snapshot = %+v, want self first and n1 stale", snap)
+	}
+	if n := x.Expire(); n != 1 {
+		t.Errorf("Expire() = %d, want 1", n)
+	}
+	if x.Len() != 0 {
+		t.Errorf("Len() = %d after expiry, want 0", x.Len())
+	}
+}
+
+func TestAvailabilityIndex_ModelBenchmark(t *testing.T) {
+	clock := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	x := testAvailability("self", &clock)
+	x.Apply(Announcement{NodeID: "n1", Seq: 1, Models: []ModelVersion{
+		{Name: "phi3"},
+		{Name: "llama3", Digest: "sha256:aa", TokensPerSec: 42.5, TTFTMs: 180},
+	}})
+
+	m, ok := x.Model("n1", "llama3")
+	if !ok || m.TokensPerSec != 42.5 || m.TTFTMs != 180 {
+		t.Errorf("Model(n1, llama3) = %+v, %v", m, ok)
+	}
+	if m, ok := x.Model("n1", "phi3"); !ok || m.TTFTMs != 0 {
+		t.Errorf("Model(n1, phi3) = %+v, %v, want unbenchmarked entry", m, ok)
+	}
+	if _, ok := x.Model("n1", "mistral"); ok {
+		t.Error("Model reported a model n1 does not host")
+	}
+
+	clock = clock.Add(11 * time.Second)
+	if _, ok := x.Model("n1", "llama3"); ok {
+		t.Error("Model reported a stale entry")
+	}
+}
+
+func TestAvailabilityIndex_RefreshAdvancesSeq(t *testing.T) {
+	clock := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	models := []ModelVersion{{Name: "b"}, {Name: "a"}, {Name: "a"}, {Name: ""}}
+	cfg := DefaultAvailabilityConfig()
+	cfg.Now = func() time.Time { return clock }
+	cfg.Local = func() []ModelVersion { return models }
+	x := NewAvailabilityIndex("self", cfg)
+
+	a1 := x.Refresh()
+	a2 := x.Refresh()
+	if a2.Seq != a1.Seq+1 {
+		t.Errorf("seq %d → %d, want +1", a1.Seq, a2.Seq)
+	}
+	want := []ModelVersion{{Name: "a"}, {Name: "b"}}
+	if !reflect.DeepEqual(a2.Models, want) {
+		t.Errorf("Models = %+v, want %+v (sorted, deduplicated)", a2.Models, want)
+	}
+}
+
+func TestAvailabilityIndex_RestartIsNotIgnored(t *testing.T) {
+	peer := NewAvailabilityIndex("peer", DefaultAvailabilityConfig())
+	before := NewAvailabilityIndex("n1", DefaultAvailabilityConfig())
+	before.SetLocal([]ModelVersion{{Name: "old"}})
+	if !peer.Apply(before.SetLocal([]ModelVersion{{Name: "old"}})) {
+		t.Fatal("first announcement not applied")
+	}
+
+	restarted := NewAvailabilityIndex("n1", DefaultAvailabilityConfig())
+	if !peer.Apply(restarted.SetLocal([]ModelVersion{{Name: "new"}})) {
+		t.Fatal("announcement after a restart was ignored as a replay")
+	}
+	if !peer.HasModel("n1", "new") || peer.HasModel("n1", "old") {
+		t.Error("peer should hold the restarted node's models")
+	}
+}
+
+func TestSWIM_AnnouncementDissemination(t *testing.T) {
+	clock := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	s, _ := newTestSWIM(t, "node-1")
+	s.SetAvailability(testAvailability("node-1", &clock))
+
+	// A new remote announcement is applied and re-queued for gossip.
+	s.applyAnnouncement(Announcement{NodeID: "node-2", Seq: 1, Models: []ModelVersion{{Name: "llama3"}}})
+	if !s.Availability().HasModel("node-2", "llama3") {
+		t.Fatal("announcement not applied")
+	}
+	if got := s.drainAnnouncements(); len(got) != 1 || got[0].NodeID != "node-2" {
+		t.Fatalf("drainAnnouncements = %+v, want node-2 re-gossiped", got)
+	}
+
+	// A replay is not re-gossiped.
+	s.drainAnnouncements()
+	s.drainAnnouncements()
+	s.applyAnnouncement(Announcement{NodeID: "node-2", Seq: 1})
+	for i := 0; i < 10; i++ {
+		for _, a := range s.drainAnnouncements() {
+			if a.Seq == 1 && len(a.Models) == 0 {
+				t.Fatal("replayed announcement was re-gossiped")
+			}
+		}
+	}
+
+	// A dead node drops out of the index.
+	s.mu.Lock()
+	s.forgetAvailability("node-2")
+	s.mu.Unlock()
+	if s.Availability().HasModel("node-2", "llama3") {
+		t.Error("dead node still listed")
+	}
+}
+
+func TestTwoNodes_AvailabilityConverges(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	node1, _ := newTestSWIM(t, "node-1")
+	node2, _ := newTestSWIM(t, "node-2")
+	node1.SetAvailability(NewAvailabilityIndex("node-1", AvailabilityConfig{}))
+	node2.SetAvailability(NewAvailabilityIndex("node-2", AvailabilityConfig{}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for _, n := range []*SWIM{node1, node2} {
+		wg.Add(1)
+		go func(node *SWIM) {
+			defer wg.Done()
+			node.Start(ctx)
+		}(n)
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	node1.Announce([]ModelVersion{{Name: "llama3", Digest: "sha256:aa"}})
+	node2.Join([]string{node1.selfAddr.String()})
+
+	deadline := time.After(4 * time.Second)
+	for !node2.Availability().HasModel("node-1", "llama3") {
+		select {
+		case <-deadline:
+			cancel()
+			wg.Wait()
+			t.Fatal("node2 never learned node-1's models")
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+
+	cancel()
+	wg.Wait()
+}
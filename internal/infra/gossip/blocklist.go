@@ -0,0 +1,433 @@
+package gossip
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/security"
+)
+
+// ─── Blocklist ──────────────────────────────────────────────────────────────
+// Nodes banned by a governance security proposal and model digests flagged
+// as malware are blocked network-wide. Entries are signed by the node that
+// issued them and ride on SWIM PING/ACK messages like quarantine notices:
+//
+//  1. An entry is accepted only if its signature verifies against the
+//     public key its signer's node ID encodes, and the signer is trusted —
+//     this node or one of BlocklistConfig.Signers. Other entries are
+//     counted and dropped, never re-gossiped
+//  2. One entry per {kind, subject}; an entry replaces the current one only
+//     if it was issued later, so a revocation overrides the block it lifts
+//     and a stale copy cannot bring it back
+//  3. Entries end at Until (zero = until revoked) without any further
+//     message; revocations are kept until the block would have ended, at
+//     most RevocationTTL
+//  4. Active entries are re-announced every Reannounce, so nodes that join
+//     later learn them too; at most blockPiggyback entries ride on one
+//     message
+//
+// Each entry carries appeal metadata: until when, and how, the decision can
+// be contested. Enforcement is up to the caller (see Blocked).
+
+// BlockKind is what a blocklist entry blocks.
+type BlockKind string
+
+const (
+	BlockNode  BlockKind = "node"  // Subject is a node ID
+	BlockModel BlockKind = "model" // Subject is a model's SHA-256 digest
+)
+
+// BlockEntry blocks a node or model — or, with Revoked set, lifts an
+// earlier block.
+type BlockEntry struct {
+	Kind        BlockKind `json:"kind"`
+	Subject     string    `json:"subject"`
+	Reason      string    `json:"reason"`
+	ProposalID  string    `json:"proposal_id,omitempty"` // governance proposal that decided it
+	IssuedAt    time.Time `json:"issued_at"`
+	Until       time.Time `json:"until"` // zero = until revoked
+	Revoked     bool      `json:"revoked,omitempty"`
+	AppealUntil time.Time `json:"appeal_until"`         // appeals accepted until (zero = none)
+	AppealVia   string    `json:"appeal_via,omitempty"` // how to file an appeal
+	Signer      string    `json:"signer"`               // issuer's node ID (hex Ed25519 public key)
+	Signature   []byte    `json:"signature"`
+}
+
+// Key identifies the blocked node or model.
+func (e BlockEntry) Key() string {
+	return string(e.Kind) + ":" + e.Subject
+}
+
+// Active reports whether the entry blocks its subject at now.
+func (e BlockEntry) Active(now time.Time) bool {
+	return !e.Revoked && (e.Until.IsZero() || now.Before(e.Until))
+}
+
+// Appealable reports whether the entry can still be appealed at now.
+func (e BlockEntry) Appealable(now time.Time) bool {
+	return e.Active(now) && now.Before(e.AppealUntil)
+}
+
+// SigningPayload returns the bytes the signer signs: every field but the
+// signature.
+func (e BlockEntry) SigningPayload() []byte {
+	return []byte(strings.Join([]string{
+		"tutu-blocklist-v1",
+		string(e.Kind),
+		e.Subject,
+		e.Reason,
+		e.ProposalID,
+		unixNanoString(e.IssuedAt),
+		unixNanoString(e.Until),
+		strconv.FormatBool(e.Revoked),
+		unixNanoString(e.AppealUntil),
+		e.AppealVia,
+		e.Signer,
+	}, "\n"))
+}
+
+// Sign sets kp as the entry's signer and signs it.
+func (e *BlockEntry) Sign(kp *security.Keypair) {
+	e.Signer = kp.PublicKeyHex()
+	e.Signature = kp.Sign(e.SigningPayload())
+}
+
+// Verify checks the entry's fields and its signature.
+func (e BlockEntry) Verify() error {
+	if e.Kind != BlockNode && e.Kind != BlockModel {
+		return fmt.Errorf("gossip: unknown blocklist kind %q", e.Kind)
+	}
+	if e.Subject == "" || e.IssuedAt.IsZero() {
+		return fmt.Errorf("gossip: blocklist entry needs a subject and an issue time")
+	}
+	pub, err := hex.DecodeString(e.Signer)
+	if err != nil || len(pub) != ed25519.PublicKeySize {
+		return fmt.Errorf("gossip: blocklist signer %q is not a public key", e.Signer)
+	}
+	if !security.Verify(e.SigningPayload(), e.Signature, ed25519.PublicKey(pub)) {
+		return fmt.Errorf("gossip: blocklist entry %s has a bad signature", e.Key())
+	}
+	return nil
+}
+
+// unixNanoString formats t for a signing payload; the zero time is "0".
+func unixNanoString(t time.Time) string {
+	if t.IsZero() {
+		return "0"
+	}
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
+
+// BlocklistConfig tunes the blocklist.
+type BlocklistConfig struct {
+	Signers       []string         // node IDs whose entries are accepted, besides this node's
+	RevocationTTL time.Duration    // longest a revocation is kept (default: 7d)
+	Reannounce    time.Duration    // active entries are re-gossiped this often (default: 5m)
+	MaxEntries    int              // entries held (default: 10000)
+	ClockSkew     time.Duration    // how far in the future an entry may be issued (default: 5m)
+	Now           func() time.Time // clock (nil = time.Now)
+}
+
+// DefaultBlocklistConfig returns the default blocklist settings.
+func DefaultBlocklistConfig() BlocklistConfig {
+	return BlocklistConfig{
+		RevocationTTL: 7 * 24 * time.Hour,
+		Reannounce:    5 * time.Minute,
+		MaxEntries:    10000,
+		ClockSkew:     5 * time.Minute,
+	}
+}
+
+// blockPiggyback bounds the entries piggybacked on one message; signed
+// entries are large and the re-announcement queues every one.
+const blockPiggyback = 8
+
+// BlocklistStats counts entries held and rejected.
+type BlocklistStats struct {
+	BlockedNodes  int   `json:"blocked_nodes"`
+	BlockedModels int   `json:"blocked_models"`
+	Revocations   int   `json:"revocations"`
+	Untrusted     int64 `json:"untrusted"` // entries from signers not trusted
+	Invalid       int64 `json:"invalid"`   // malformed, badly signed or issued in the future
+}
+
+// Blocklist is the gossiped set of blocked nodes and models. It is safe for
+// concurrent use.
+type Blocklist struct {
+	mu       sync.RWMutex
+	cfg      BlocklistConfig
+	trusted  map[string]bool
+	entries  map[string]BlockEntry // Key → latest entry
+	onChange func(BlockEntry)
+
+	untrusted atomic.Int64
+	invalid   atomic.Int64
+}
+
+// NewBlocklist creates an empty blocklist for selfID. Zero config fields
+// take their defaults.
+func NewBlocklist(selfID string, cfg BlocklistConfig) *Blocklist {
+	def := DefaultBlocklistConfig()
+	if cfg.RevocationTTL <= 0 {
+		cfg.RevocationTTL = def.RevocationTTL
+	}
+	if cfg.Reannounce <= 0 {
+		cfg.Reannounce = def.Reannounce
+	}
+	if cfg.MaxEntries <= 0 {
+		cfg.MaxEntries = def.MaxEntries
+	}
+	if cfg.ClockSkew <= 0 {
+		cfg.ClockSkew = def.ClockSkew
+	}
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+	trusted := map[string]bool{selfID: true}
+	for _, s := range cfg.Signers {
+		trusted[strings.ToLower(strings.TrimSpace(s))] = true
+	}
+	return &Blocklist{cfg: cfg, trusted: trusted, entries: make(map[string]BlockEntry)}
+}
+
+// OnChange sets a callback for every entry accepted (e.g. to persist it).
+// It is called with the blocklist lock held and must not call back into it.
+func (b *Blocklist) OnChange(fn func(BlockEntry)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.onChange = fn
+}
+
+// Apply verifies and merges an entry. Returns true if it was newer than the
+// held entry (and should therefore be re-gossiped); an error if it was
+// rejected.
+func (b *Blocklist) Apply(e BlockEntry) (bool, error) {
+	if err := e.Verify(); err != nil {
+		b.invalid.Add(1)
+		return false, err
+	}
+	if !b.trusted[e.Signer] {
+		b.untrusted.Add(1)
+		return false, fmt.Errorf("gossip: blocklist signer %s is not trusted", e.Signer)
+	}
+	now := b.cfg.Now()
+	if e.IssuedAt.After(now.Add(b.cfg.ClockSkew)) {
+		b.invalid.Add(1)
+		return false, fmt.Errorf("gossip: blocklist entry %s issued in the future", e.Key())
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	cur, ok := b.entries[e.Key()]
+	if ok && !e.IssuedAt.After(cur.IssuedAt) {
+		return false, nil
+	}
+	if !ok && len(b.entries) >= b.cfg.MaxEntries {
+		return false, fmt.Errorf("gossip: blocklist is full (%d entries)", b.cfg.MaxEntries)
+	}
+	b.entries[e.Key()] = e
+	if b.onChange != nil {
+		b.onChange(e)
+	}
+	return true, nil
+}
+
+// Blocked returns the entry blocking kind/subject, if one is in effect.
+func (b *Blocklist) Blocked(kind BlockKind, subject string) (BlockEntry, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	e, ok := b.entries[BlockEntry{Kind: kind, Subject: subject}.Key()]
+	if !ok || !e.Active(b.cfg.Now()) {
+		return BlockEntry{}, false
+	}
+	return e, true
+}
+
+// NodeBlocked reports whether nodeID is blocked.
+func (b *Blocklist) NodeBlocked(nodeID string) bool {
+	_, ok := b.Blocked(BlockNode, nodeID)
+	return ok
+}
+
+// ModelBlocked reports whether the model with this SHA-256 digest is
+// blocked. A "sha256:" prefix is ignored.
+func (b *Blocklist) ModelBlocked(digest string) bool {
+	_, ok := b.Blocked(BlockModel, strings.TrimPrefix(strings.ToLower(digest), "sha256:"))
+	return ok
+}
+
+// Expiry returns when e is dropped: at Until, or for a revocation at most
+// RevocationTTL after it was issued. Zero means never.
+func (b *Blocklist) Expiry(e BlockEntry) time.Time {
+	end := e.Until
+	if e.Revoked && (end.IsZero() || end.After(e.IssuedAt.Add(b.cfg.RevocationTTL))) {
+		end = e.IssuedAt.Add(b.cfg.RevocationTTL)
+	}
+	return end
+}
+
+// Expire drops ended entries and returns how many were removed.
+func (b *Blocklist) Expire() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := b.cfg.Now()
+	removed := 0
+	for key, e := range b.entries {
+		if end := b.Expiry(e); !end.IsZero() && !now.Before(end) {
+			delete(b.entries, key)
+			removed++
+		}
+	}
+	return removed
+}
+
+// Entries returns every entry held, revocations included, sorted by key.
+func (b *Blocklist) Entries() []BlockEntry {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	out := make([]BlockEntry, 0, len(b.entries))
+	for _, e := range b.entries {
+		out = append(out, e)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Key() < out[j].Key() })
+	return out
+}
+
+// Stats returns the number of entries held and rejected.
+func (b *Blocklist) Stats() BlocklistStats {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	st := BlocklistStats{Untrusted: b.untrusted.Load(), Invalid: b.invalid.Load()}
+	now := b.cfg.Now()
+	for _, e := range b.entries {
+		switch {
+		case !e.Active(now):
+			if e.Revoked {
+				st.Revocations++
+			}
+		case e.Kind == BlockNode:
+			st.BlockedNodes++
+		case e.Kind == BlockModel:
+			st.BlockedModels++
+		}
+	}
+	return st
+}
+
+// ─── SWIM Integration ───────────────────────────────────────────────────────
+
+// SetBlocklist attaches a blocklist. Received entries are verified, merged
+// and re-gossiped when new.
+func (s *SWIM) SetBlocklist(list *Blocklist) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.blocklist = list
+}
+
+// Blocklist returns the attached blocklist, or nil.
+func (s *SWIM) Blocklist() *Blocklist {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.blocklist
+}
+
+// maintainBlocklist drops ended entries and re-announces active ones when
+// due. Called once per probe cycle.
+func (s *SWIM) maintainBlocklist() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b := s.blocklist
+	if b == nil {
+		return
+	}
+	b.Expire()
+	now := b.cfg.Now()
+	if now.Sub(s.lastBlockAnnounce) < b.cfg.Reannounce {
+		return
+	}
+	s.lastBlockAnnounce = now
+	for _, e := range b.Entries() {
+		s.queueBlock(e)
+	}
+}
+
+// PublishBlock records a locally issued entry and disseminates it.
+func (s *SWIM) PublishBlock(e BlockEntry) error {
+	b := s.Blocklist()
+	if b == nil {
+		return fmt.Errorf("gossip: no blocklist attached")
+	}
+	fresh, err := b.Apply(e)
+	if err != nil {
+		return err
+	}
+	if fresh {
+		s.mu.Lock()
+		s.queueBlock(e)
+		s.mu.Unlock()
+	}
+	return nil
+}
+
+// applyBlock merges a received entry and re-queues it for dissemination if
+// it was new. Rejected entries are only counted.
+func (s *SWIM) applyBlock(e BlockEntry) {
+	b := s.Blocklist()
+	if b == nil {
+		return
+	}
+	// Verified outside s.mu; signatures are the expensive part
+	if fresh, err := b.Apply(e); err == nil && fresh {
+		s.mu.Lock()
+		s.queueBlock(e)
+		s.mu.Unlock()
+	}
+}
+
+// queueBlock adds an entry to the piggyback queue, replacing any older one
+// with the same key. Must be called with s.mu held.
+func (s *SWIM) queueBlock(e BlockEntry) {
+	for i, q := range s.blockQueue {
+		if q.Key() == e.Key() {
+			s.blockQueue = append(s.blockQueue[:i], s.blockQueue[i+1:]...)
+			break
+		}
+	}
+	s.blockQueue = append(s.blockQueue, e)
+	s.blockLeft[e.Key()] = s.config.Lambda * s.logN()
+}
+
+// drainBlocks returns up to blockPiggyback pending entries for
+// piggybacking, oldest queued first.
+func (s *SWIM) drainBlocks() []BlockEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.blockQueue) == 0 {
+		return nil
+	}
+
+	n := min(len(s.blockQueue), blockPiggyback)
+	result := make([]BlockEntry, 0, n)
+	remaining := make([]BlockEntry, 0, len(s.blockQueue))
+	for _, e := range s.blockQueue[:n] {
+		result = append(result, e)
+		s.blockLeft[e.Key()]--
+		if s.blockLeft[e.Key()] > 0 {
+			remaining = append(remaining, e)
+		} else {
+			delete(s.blockLeft, e.Key())
+		}
+	}
+	// Entries not sent this time go first next time
+	rest := append([]BlockEntry(nil), s.blockQueue[n:]...)
+	s.blockQueue = append(rest, remaining...)
+	return result
+}
@@ -0,0 +1,111 @@
+package gossip
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/security"
+)
+
+func newTestKeypair(t *testing.T) *security.Keypair {
+	t.Helper()
+	kp, err := security.GenerateKeypair()
+	if err != nil {
+		t.Fatalf("GenerateKeypair: %v", err)
+	}
+	return kp
+}
+
+func TestBlocklist_ApplyAndExpire(t *testing.T) {
+	clock := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	self, gov, stranger := newTestKeypair(t), newTestKeypair(t), newTestKeypair(t)
+	b := NewBlocklist(self.PublicKeyHex(), BlocklistConfig{
+		Signers: []string{gov.PublicKeyHex()},
+		Now:     func() time.Time { return clock },
+	})
+	signed := func(kp *security.Keypair, e BlockEntry) BlockEntry {
+		e.Sign(kp)
+		return e
+	}
+	ban := BlockEntry{Kind: BlockNode, Subject: "n1", IssuedAt: clock, Until: clock.Add(time.Hour)}
+
+	tampered := signed(gov, ban)
+	tampered.Until = time.Time{}
+	tests := []struct {
+		name    string
+		e       BlockEntry
+		apply   bool
+		wantErr bool
+		blocked bool
+	}{
+		{"untrusted signer", signed(stranger, ban), false, true, false},
+		{"tampered", tampered, false, true, false},
+		{"trusted signer", signed(gov, ban), true, false, true},
+		{"same issue time ignored", signed(self, BlockEntry{Kind: BlockNode, Subject: "n1", IssuedAt: clock, Revoked: true}), false, false, true},
+		{"revoke", signed(self, BlockEntry{Kind: BlockNode, Subject: "n1", IssuedAt: clock.Add(time.Minute), Revoked: true}), true, false, false},
+		{"stale ban ignored", signed(gov, BlockEntry{Kind: BlockNode, Subject: "n1", IssuedAt: clock.Add(time.Second)}), false, false, false},
+		{"issued in the future", signed(gov, BlockEntry{Kind: BlockNode, Subject: "n1", IssuedAt: clock.Add(time.Hour)}), false, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := b.Apply(tt.e)
+			if got != tt.apply || (err != nil) != tt.wantErr {
+				t.Errorf("Apply() = %v, %v; want %v, error %v", got, err, tt.apply, tt.wantErr)
+			}
+			if got := b.NodeBlocked("n1"); got != tt.blocked {
+				t.Errorf("NodeBlocked(n1) = %v, want %v", got, tt.blocked)
+			}
+		})
+	}
+	if st := b.Stats(); st.Untrusted != 1 || st.Invalid != 2 || st.Revocations != 1 {
+		t.Errorf("stats = %+v, want 1 untrusted, 2 invalid, 1 revocation", st)
+	}
+
+	// A model flagged until revoked; digests match with or without a prefix
+	malware := signed(gov, BlockEntry{Kind: BlockModel, Subject: "abc123", IssuedAt: clock,
+		AppealUntil: clock.Add(24 * time.Hour)})
+	if _, err := b.Apply(malware); err != nil {
+		t.Fatalf("Apply(model): %v", err)
+	}
+	if !b.ModelBlocked("sha256:ABC123") {
+		t.Error("model digest not blocked")
+	}
+	if e, _ := b.Blocked(BlockModel, "abc123"); !e.Appealable(clock) {
+		t.Error("block should be appealable within its window")
+	}
+
+	clock = clock.Add(30 * 24 * time.Hour)
+	if n := b.Expire(); n != 1 {
+		t.Errorf("Expire() = %d, want 1 (the revocation)", n)
+	}
+	if !b.ModelBlocked("abc123") {
+		t.Error("a block without Until expired")
+	}
+}
+
+func TestSWIM_BlocklistDissemination(t *testing.T) {
+	kp := newTestKeypair(t)
+	s, _ := newTestSWIM(t, "node-1")
+	s.SetBlocklist(NewBlocklist(kp.PublicKeyHex(), BlocklistConfig{}))
+
+	for i := range blockPiggyback + 2 {
+		e := BlockEntry{Kind: BlockModel, Subject: string(rune('a' + i)), IssuedAt: time.Now()}
+		e.Sign(kp)
+		s.applyBlock(e)
+	}
+	if got := len(s.drainBlocks()); got != blockPiggyback {
+		t.Fatalf("first drain = %d entries, want %d", got, blockPiggyback)
+	}
+	if got := s.drainBlocks(); len(got) != blockPiggyback || got[0].Subject != "i" {
+		t.Fatalf("second drain should start with the entries left over, got %d starting %+v", len(got), got[0])
+	}
+
+	// Untrusted entries are not re-gossiped
+	s.blockQueue, s.blockLeft = nil, map[string]int{}
+	e := BlockEntry{Kind: BlockNode, Subject: "node-9", IssuedAt: time.Now()}
+	e.Sign(newTestKeypair(t))
+	s.applyBlock(e)
+	if got := s.drainBlocks(); len(got) != 0 || s.Blocklist().NodeBlocked("node-9") {
+		t.Errorf("untrusted entry applied or queued: %+v", got)
+	}
+}
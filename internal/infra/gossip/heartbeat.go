@@ -0,0 +1,294 @@
+package gossip
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
+)
+
+// ─── Load Heartbeats ────────────────────────────────────────────────────────
+// A heartbeat is a small, unacknowledged message a node sends straight to a
+// few random members, faster than the probe cycle. It carries what a
+// scheduler needs to place work — utilization, queue depth, free VRAM, the
+// models already loaded, its hardware tier and whether it is running hot —
+// so nobody has to ask:
+//
+//  1. Every Interval the local heartbeat is rebuilt (cfg.Local) and sent to
+//     Fanout random non-dead members
+//  2. Receivers keep the newest heartbeat per node (by sequence number) and
+//     do not relay it — heartbeats are point-to-point, unlike load reports
+//  3. Entries go stale after TTL and are dropped when SWIM declares the
+//     sender dead
+
+// Heartbeat is a node's current load.
+type Heartbeat struct {
+	NodeID     string   `json:"node_id"`
+	Seq        uint64   `json:"seq"`
+	Load       float64  `json:"load"` // utilization 0..1
+	QueueDepth int      `json:"queue_depth"`
+	FreeVRAM   uint64   `json:"free_vram"`            // bytes free across GPUs
+	HotModels  []string `json:"hot,omitempty"`        // models loaded in memory
+	Relay      string   `json:"relay,omitempty"`      // NAT relay address, if volunteering
+	Throttled  bool     `json:"throttled,omitempty"`  // in sustained thermal throttle
+	Tier       string   `json:"tier,omitempty"`       // benchmarked hardware tier
+	ChunkPort  int      `json:"chunk_port,omitempty"` // TCP port serving model chunks, if seeding
+}
+
+// IsHot reports whether model is loaded on the node.
+func (h Heartbeat) IsHot(model string) bool {
+	for _, m := range h.HotModels {
+		if m == model {
+			return true
+		}
+	}
+	return false
+}
+
+// HeartbeatConfig controls heartbeat cadence and staleness.
+type HeartbeatConfig struct {
+	Interval time.Duration // send cadence (default: 500ms)
+	TTL      time.Duration // entries older than this are stale (default: 3s)
+	Fanout   int           // members each heartbeat is sent to (default: 3)
+
+	// Local, if set, is polled on every send for this node's heartbeat.
+	// NodeID and Seq are filled in by the index.
+	Local func() Heartbeat
+
+	Now func() time.Time // injectable clock (default: time.Now)
+}
+
+// DefaultHeartbeatConfig returns defaults that beat twice per probe cycle
+// and tolerate a few lost packets.
+func DefaultHeartbeatConfig() HeartbeatConfig {
+	return HeartbeatConfig{
+		Interval: 500 * time.Millisecond,
+		TTL:      3 * time.Second,
+		Fanout:   3,
+	}
+}
+
+// NodeHeartbeat is one node's entry in the heartbeat index.
+type NodeHeartbeat struct {
+	Heartbeat
+	ReceivedAt time.Time `json:"received_at"`
+	Stale      bool      `json:"stale"`
+}
+
+// HeartbeatIndex holds the newest heartbeat of every peer. It is safe for
+// concurrent use.
+type HeartbeatIndex struct {
+	mu     sync.RWMutex
+	cfg    HeartbeatConfig
+	selfID string
+	self   Heartbeat
+	nodes  map[string]NodeHeartbeat // remote nodeID → entry
+}
+
+// NewHeartbeatIndex creates a heartbeat index for the local node selfID.
+func NewHeartbeatIndex(selfID string, cfg HeartbeatConfig) *HeartbeatIndex {
+	def := DefaultHeartbeatConfig()
+	if cfg.Interval <= 0 {
+		cfg.Interval = def.Interval
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = def.TTL
+	}
+	if cfg.Fanout <= 0 {
+		cfg.Fanout = def.Fanout
+	}
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+	return &HeartbeatIndex{
+		cfg:    cfg,
+		selfID: selfID,
+		nodes:  make(map[string]NodeHeartbeat),
+	}
+}
+
+// SetLocal records the local heartbeat and returns it stamped with this
+// node's ID and the next sequence number.
+func (x *HeartbeatIndex) SetLocal(h Heartbeat) Heartbeat {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	h.NodeID = x.selfID
+	h.Seq = x.self.Seq + 1
+	h.QueueDepth = max(h.QueueDepth, 0)
+	x.self = h
+	return h
+}
+
+// Refresh rebuilds the local heartbeat from cfg.Local (if set).
+func (x *HeartbeatIndex) Refresh() Heartbeat {
+	if x.cfg.Local != nil {
+		return x.SetLocal(x.cfg.Local())
+	}
+	x.mu.RLock()
+	h := x.self
+	x.mu.RUnlock()
+	return x.SetLocal(h)
+}
+
+// Local returns the last local heartbeat.
+func (x *HeartbeatIndex) Local() Heartbeat {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return x.self
+}
+
+// Apply merges a remote heartbeat. Returns false if it was not newer than
+// the held one.
+func (x *HeartbeatIndex) Apply(h Heartbeat) bool {
+	if h.NodeID == "" || h.NodeID == x.selfID {
+		return false
+	}
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if cur, ok := x.nodes[h.NodeID]; ok && h.Seq <= cur.Seq {
+		return false
+	}
+	x.nodes[h.NodeID] = NodeHeartbeat{Heartbeat: h, ReceivedAt: x.cfg.Now()}
+	return true
+}
+
+// Get returns nodeID's heartbeat if a fresh one exists.
+func (x *HeartbeatIndex) Get(nodeID string) (Heartbeat, bool) {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	e, ok := x.nodes[nodeID]
+	if !ok || x.cfg.Now().Sub(e.ReceivedAt) > x.cfg.TTL {
+		return Heartbeat{}, false
+	}
+	return e.Heartbeat, true
+}
+
+// Fresh returns every peer with a fresh heartbeat, sorted by node ID.
+func (x *HeartbeatIndex) Fresh() []Heartbeat {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	now := x.cfg.Now()
+	out := make([]Heartbeat, 0, len(x.nodes))
+	for _, e := range x.nodes {
+		if now.Sub(e.ReceivedAt) <= x.cfg.TTL {
+			out = append(out, e.Heartbeat)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].NodeID < out[j].NodeID })
+	return out
+}
+
+// Snapshot returns every known peer's entry, including stale ones (flagged),
+// sorted by node ID.
+func (x *HeartbeatIndex) Snapshot() []NodeHeartbeat {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	now := x.cfg.Now()
+	out := make([]NodeHeartbeat, 0, len(x.nodes))
+	for _, e := range x.nodes {
+		e.Stale = now.Sub(e.ReceivedAt) > x.cfg.TTL
+		out = append(out, e)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].NodeID < out[j].NodeID })
+	return out
+}
+
+// Remove forgets a node (e.g. when SWIM declares it dead).
+func (x *HeartbeatIndex) Remove(nodeID string) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	delete(x.nodes, nodeID)
+}
+
+// Expire drops stale entries and returns how many were removed.
+func (x *HeartbeatIndex) Expire() int {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	now := x.cfg.Now()
+	n := 0
+	for id, e := range x.nodes {
+		if now.Sub(e.ReceivedAt) > x.cfg.TTL {
+			delete(x.nodes, id)
+			n++
+		}
+	}
+	return n
+}
+
+// ─── SWIM Integration ───────────────────────────────────────────────────────
+
+// SetHeartbeat attaches a heartbeat index. Start sends the local heartbeat
+// every Interval; received heartbeats are merged.
+func (s *SWIM) SetHeartbeat(idx *HeartbeatIndex) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.heartbeat = idx
+}
+
+// Heartbeat returns the attached heartbeat index, or nil.
+func (s *SWIM) Heartbeat() *HeartbeatIndex {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.heartbeat
+}
+
+// heartbeatLoop sends heartbeats until ctx is done. It does nothing if no
+// index is attached when Start runs.
+func (s *SWIM) heartbeatLoop(ctx context.Context) {
+	idx := s.Heartbeat()
+	if idx == nil {
+		return
+	}
+	ticker := time.NewTicker(idx.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.sendHeartbeat(idx)
+		}
+	}
+}
+
+// sendHeartbeat refreshes the local heartbeat and sends it to Fanout
+// random members.
+func (s *SWIM) sendHeartbeat(idx *HeartbeatIndex) {
+	idx.Expire()
+	targets := s.randomMembersWith(idx.cfg.Fanout, "", FeatureHeartbeat)
+	if len(targets) == 0 {
+		return
+	}
+	h := idx.Refresh() // may call cfg.Local — outside s.mu
+	for _, m := range targets {
+		s.sendMessage(m.addr, Message{Type: MsgHeartbeat, From: s.selfID, Heartbeat: &h})
+	}
+}
+
+// applyHeartbeat merges a received heartbeat and counts it as a sign of
+// life from a known member. A heartbeat is only accepted from the node it
+// describes.
+func (s *SWIM) applyHeartbeat(from string, h Heartbeat) {
+	if h.NodeID != from {
+		return
+	}
+	s.mu.Lock()
+	idx := s.heartbeat
+	if m, ok := s.members[from]; ok && m.state != domain.PeerDead {
+		m.lastAck = time.Now()
+	}
+	s.mu.Unlock()
+	if idx != nil {
+		idx.Apply(h)
+	}
+}
+
+// forgetHeartbeat drops a dead node's heartbeat.
+// Must be called with s.mu held.
+func (s *SWIM) forgetHeartbeat(nodeID string) {
+	if s.heartbeat != nil {
+		s.heartbeat.Remove(nodeID)
+	}
+}
@@ -0,0 +1,89 @@
+package gossip
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHeartbeatIndex_ApplyAndStaleness(t *testing.T) {
+	clock := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	x := NewHeartbeatIndex("self", HeartbeatConfig{TTL: 3 * time.Second, Now: func() time.Time { return clock }})
+
+	tests := []struct {
+		name  string
+		h     Heartbeat
+		apply bool
+	}{
+		{"first", Heartbeat{NodeID: "n1", Seq: 2, Load: 0.5, HotModels: []string{"llama3"}}, true},
+		{"older seq ignored", Heartbeat{NodeID: "n1", Seq: 1, Load: 0.9}, false},
+		{"second node", Heartbeat{NodeID: "n2", Seq: 1, QueueDepth: 4, FreeVRAM: 8 << 30}, true},
+		{"self ignored", Heartbeat{NodeID: "self", Seq: 9}, false},
+		{"anonymous ignored", Heartbeat{Seq: 1}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := x.Apply(tt.h); got != tt.apply {
+				t.Errorf("Apply() = %v, want %v", got, tt.apply)
+			}
+		})
+	}
+
+	h, ok := x.Get("n1")
+	if !ok || h.Load != 0.5 || !h.IsHot("llama3") || h.IsHot("phi3") {
+		t.Fatalf("Get(n1) = %+v, %v", h, ok)
+	}
+	if fresh := x.Fresh(); len(fresh) != 2 || fresh[0].NodeID != "n1" {
+		t.Fatalf("Fresh() = %+v, want [n1 n2]", fresh)
+	}
+
+	clock = clock.Add(4 * time.Second)
+	if _, ok := x.Get("n1"); ok {
+		t.Error("stale heartbeat returned")
+	}
+	if snap := x.Snapshot(); len(snap) != 2 || !snap[0].Stale {
+		t.Errorf("Snapshot() = %+v, want two stale entries", snap)
+	}
+	if n := x.Expire(); n != 2 {
+		t.Errorf("Expire() = %d, want 2", n)
+	}
+}
+
+func TestHeartbeatIndex_Refresh(t *testing.T) {
+	depth := 3
+	x := NewHeartbeatIndex("self", HeartbeatConfig{Local: func() Heartbeat {
+		return Heartbeat{NodeID: "spoofed", QueueDepth: depth}
+	}})
+
+	first := x.Refresh()
+	depth = -1
+	second := x.Refresh()
+	if first.NodeID != "self" || first.Seq != 1 || first.QueueDepth != 3 {
+		t.Errorf("first = %+v", first)
+	}
+	if second.Seq != 2 || second.QueueDepth != 0 {
+		t.Errorf("second = %+v, want seq 2 and depth clamped to 0", second)
+	}
+}
+
+func TestSWIM_Heartbeat(t *testing.T) {
+	s, _ := newTestSWIM(t, "node-1")
+	s.SetHeartbeat(NewHeartbeatIndex("node-1", HeartbeatConfig{}))
+
+	s.handleMessage(Message{Type: MsgHeartbeat, From: "node-2", Heartbeat: &Heartbeat{NodeID: "node-2", Seq: 1, Load: 0.25}}, nil)
+	if h, ok := s.Heartbeat().Get("node-2"); !ok || h.Load != 0.25 {
+		t.Fatalf("Get(node-2) = %+v, %v", h, ok)
+	}
+
+	// A node cannot report on behalf of another
+	s.handleMessage(Message{Type: MsgHeartbeat, From: "node-2", Heartbeat: &Heartbeat{NodeID: "node-3", Seq: 1}}, nil)
+	if _, ok := s.Heartbeat().Get("node-3"); ok {
+		t.Error("relayed heartbeat accepted")
+	}
+
+	s.mu.Lock()
+	s.forgetHeartbeat("node-2")
+	s.mu.Unlock()
+	if _, ok := s.Heartbeat().Get("node-2"); ok {
+		t.Error("dead node still listed")
+	}
+}
@@ -0,0 +1,557 @@
+package gossip
+
+import (
+	"context"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
+	"github.com/tutu-network/tutu/internal/infra/dsa"
+)
+
+// ─── Hierarchical Gossip ────────────────────────────────────────────────────
+// Flat SWIM has every node track every other node, which stops scaling long
+// before the region → zone → cluster hierarchy fills up. With a hierarchy
+// attached, gossip runs in two tiers:
+//
+//  1. Tier 1 is this SWIM instance: nodes join only their own cluster, so
+//     membership, load and heartbeats stay inside it
+//  2. Every Interval each member derives the cluster's representatives —
+//     the Representatives live members with the lowest node IDs. Everyone
+//     computes the same set from the membership view, so there is no
+//     election round, and a failed representative is replaced as soon as
+//     SWIM declares it dead
+//  3. Representatives summarize the cluster (member counts, mean load, total
+//     queue depth and free VRAM, hot models — from heartbeats) and send the
+//     summary, with the freshest ones they hold for other clusters, to
+//     representatives of Fanout other clusters (tier 2). Seeds bootstrap
+//     tier 2 until a remote representative is known
+//  4. Representatives also push those summaries to every member of their
+//     own cluster, so any node can answer questions about remote clusters
+//  5. Receivers keep the newest summary per cluster (by sequence number);
+//     summaries older than TTL are stale and eventually dropped
+//
+// A summary for the local cluster is only accepted from a cluster member,
+// so a remote node cannot rewrite what this cluster says about itself.
+
+// summaryBatch caps the summaries per message to keep datagrams small.
+const summaryBatch = 16
+
+// Representative is a cluster member that gossips between clusters.
+type Representative struct {
+	NodeID string `json:"node_id"`
+	Addr   string `json:"addr,omitempty"` // gossip address, as seen by the receiver
+}
+
+// ClusterSummary is the aggregate state a cluster gossips to the others.
+type ClusterSummary struct {
+	Region          string           `json:"region"`
+	Zone            string           `json:"zone"`
+	Cluster         string           `json:"cluster"`
+	Seq             uint64           `json:"seq"`
+	Issuer          string           `json:"issuer"` // representative that built it
+	Representatives []Representative `json:"reps"`
+	Members         int              `json:"members"` // non-dead members
+	Alive           int              `json:"alive"`
+	Load            float64          `json:"load"`        // mean utilization of members with fresh heartbeats
+	QueueDepth      int              `json:"queue_depth"` // total across members
+	FreeVRAM        uint64           `json:"free_vram"`   // total bytes across members
+	HotModels       []string         `json:"hot,omitempty"`
+}
+
+// Key returns the summary's cluster key (see ClusterKey).
+func (c ClusterSummary) Key() string { return ClusterKey(c.Region, c.Zone, c.Cluster) }
+
+// IsHot reports whether model is loaded somewhere in the cluster.
+func (c ClusterSummary) IsHot(model string) bool {
+	for _, m := range c.HotModels {
+		if m == model {
+			return true
+		}
+	}
+	return false
+}
+
+// ClusterKey identifies a cluster as "region/zone/cluster".
+func ClusterKey(region, zone, cluster string) string {
+	return region + "/" + zone + "/" + cluster
+}
+
+// HierarchyConfig places the node in the hierarchy and tunes tier 2.
+type HierarchyConfig struct {
+	Region  string // this node's region
+	Zone    string // this node's zone within the region
+	Cluster string // this node's cluster within the zone
+
+	Representatives int           // representatives per cluster (default: 2)
+	Interval        time.Duration // summary cadence (default: 5s)
+	TTL             time.Duration // summaries older than this are stale (default: 30s)
+	Fanout          int           // remote clusters each round reaches (default: 3)
+	Seeds           []string      // gossip addresses in other clusters, for bootstrap
+
+	Now  func() time.Time // injectable clock (default: time.Now)
+	Seed int64            // RNG seed for fanout targets (0 = time-based)
+}
+
+// DefaultHierarchyConfig returns defaults that keep two representatives per
+// cluster and tolerate a few lost rounds before a summary goes stale.
+func DefaultHierarchyConfig() HierarchyConfig {
+	return HierarchyConfig{
+		Representatives: 2,
+		Interval:        5 * time.Second,
+		TTL:             30 * time.Second,
+		Fanout:          3,
+	}
+}
+
+// ClusterView is one cluster's entry in the hierarchy.
+type ClusterView struct {
+	ClusterSummary
+	ReceivedAt time.Time `json:"received_at"`
+	Local      bool      `json:"local"`
+	Stale      bool      `json:"stale"`
+}
+
+// HierarchyStats summarizes the hierarchy for diagnostics.
+type HierarchyStats struct {
+	Cluster          string   `json:"cluster"`
+	Representatives  []string `json:"representatives"`
+	Representative   bool     `json:"representative"`
+	Clusters         int      `json:"clusters"`
+	Stale            int      `json:"stale"`
+	SummariesSent    uint64   `json:"summaries_sent"`
+	SummariesApplied uint64   `json:"summaries_applied"`
+}
+
+// clusterEntry is the hierarchy's record of one cluster.
+type clusterEntry struct {
+	summary    ClusterSummary
+	receivedAt time.Time
+}
+
+// Hierarchy holds this node's cluster representatives and the newest
+// summary of every known cluster. It is safe for concurrent use.
+type Hierarchy struct {
+	mu       sync.RWMutex
+	cfg      HierarchyConfig
+	selfID   string
+	local    string   // local cluster key
+	reps     []string // current representatives of the local cluster
+	clusters map[string]clusterEntry
+	rng      *dsa.Rand
+
+	sent    uint64
+	applied uint64
+}
+
+// NewHierarchy creates the hierarchy view of the local node selfID.
+func NewHierarchy(selfID string, cfg HierarchyConfig) *Hierarchy {
+	def := DefaultHierarchyConfig()
+	if cfg.Representatives <= 0 {
+		cfg.Representatives = def.Representatives
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = def.Interval
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = def.TTL
+	}
+	if cfg.Fanout <= 0 {
+		cfg.Fanout = def.Fanout
+	}
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+	return &Hierarchy{
+		cfg:      cfg,
+		selfID:   selfID,
+		local:    ClusterKey(cfg.Region, cfg.Zone, cfg.Cluster),
+		reps:     []string{selfID},
+		clusters: make(map[string]clusterEntry),
+		rng:      dsa.NewRand(cfg.Seed),
+	}
+}
+
+// LocalCluster returns the local cluster key.
+func (h *Hierarchy) LocalCluster() string { return h.local }
+
+// Elect recomputes the local representatives from the IDs of the live
+// cluster members (this node is always a candidate) and returns them.
+func (h *Hierarchy) Elect(members []string) []string {
+	ids := append([]string{h.selfID}, members...)
+	sort.Strings(ids)
+	reps := make([]string, 0, h.cfg.Representatives)
+	for i, id := range ids {
+		if i > 0 && id == ids[i-1] {
+			continue
+		}
+		if len(reps) == h.cfg.Representatives {
+			break
+		}
+		reps = append(reps, id)
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.reps = reps
+	return append([]string(nil), reps...)
+}
+
+// Representatives returns the local cluster's current representatives.
+func (h *Hierarchy) Representatives() []string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return append([]string(nil), h.reps...)
+}
+
+// IsRepresentative reports whether this node currently represents its
+// cluster.
+func (h *Hierarchy) IsRepresentative() bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for _, id := range h.reps {
+		if id == h.selfID {
+			return true
+		}
+	}
+	return false
+}
+
+// Publish stamps a summary of the local cluster with this node as issuer
+// and a sequence number above any held for the cluster, records it and
+// returns it.
+func (h *Hierarchy) Publish(sum ClusterSummary) ClusterSummary {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	sum.Region, sum.Zone, sum.Cluster = h.cfg.Region, h.cfg.Zone, h.cfg.Cluster
+	sum.Issuer = h.selfID
+	sum.Seq = h.clusters[h.local].summary.Seq + 1
+	h.clusters[h.local] = clusterEntry{summary: sum, receivedAt: h.cfg.Now()}
+	return sum
+}
+
+// Apply merges a received summary. Returns false if it was not newer than
+// the held one.
+func (h *Hierarchy) Apply(sum ClusterSummary) bool {
+	if sum.Cluster == "" || sum.Issuer == "" {
+		return false
+	}
+	key := sum.Key()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if cur, ok := h.clusters[key]; ok && sum.Seq <= cur.summary.Seq {
+		return false
+	}
+	h.clusters[key] = clusterEntry{summary: sum, receivedAt: h.cfg.Now()}
+	h.applied++
+	return true
+}
+
+// Summary returns the fresh summary of the cluster with the given key.
+func (h *Hierarchy) Summary(key string) (ClusterSummary, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	e, ok := h.clusters[key]
+	if !ok || h.cfg.Now().Sub(e.receivedAt) > h.cfg.TTL {
+		return ClusterSummary{}, false
+	}
+	return e.summary, true
+}
+
+// Remote returns the fresh summaries of other clusters, sorted by key. An
+// empty region matches every region.
+func (h *Hierarchy) Remote(region string) []ClusterSummary {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	now := h.cfg.Now()
+	var out []ClusterSummary
+	for key, e := range h.clusters {
+		if key == h.local || now.Sub(e.receivedAt) > h.cfg.TTL {
+			continue
+		}
+		if region == "" || e.summary.Region == region {
+			out = append(out, e.summary)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Key() < out[j].Key() })
+	return out
+}
+
+// Clusters returns every known cluster, including stale ones (flagged),
+// sorted by key.
+func (h *Hierarchy) Clusters() []ClusterView {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	now := h.cfg.Now()
+	out := make([]ClusterView, 0, len(h.clusters))
+	for key, e := range h.clusters {
+		out = append(out, ClusterView{
+			ClusterSummary: e.summary,
+			ReceivedAt:     e.receivedAt,
+			Local:          key == h.local,
+			Stale:          now.Sub(e.receivedAt) > h.cfg.TTL,
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Key() < out[j].Key() })
+	return out
+}
+
+// Expire drops summaries older than twice the TTL and returns how many were
+// removed. Stale summaries are kept for a while so operators can see which
+// clusters went quiet.
+func (h *Hierarchy) Expire() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	now := h.cfg.Now()
+	n := 0
+	for key, e := range h.clusters {
+		if now.Sub(e.receivedAt) > 2*h.cfg.TTL {
+			delete(h.clusters, key)
+			n++
+		}
+	}
+	return n
+}
+
+// Stats returns counters for diagnostics.
+func (h *Hierarchy) Stats() HierarchyStats {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	now := h.cfg.Now()
+	st := HierarchyStats{
+		Cluster:          h.local,
+		Representatives:  append([]string(nil), h.reps...),
+		Clusters:         len(h.clusters),
+		SummariesSent:    h.sent,
+		SummariesApplied: h.applied,
+	}
+	for _, id := range h.reps {
+		st.Representative = st.Representative || id == h.selfID
+	}
+	for _, e := range h.clusters {
+		if now.Sub(e.receivedAt) > h.cfg.TTL {
+			st.Stale++
+		}
+	}
+	return st
+}
+
+// outgoing returns the fresh summaries to gossip, the local cluster's first
+// and then the most recently received.
+func (h *Hierarchy) outgoing() []ClusterSummary {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	now := h.cfg.Now()
+	entries := make([]clusterEntry, 0, len(h.clusters))
+	for key, e := range h.clusters {
+		if key != h.local && now.Sub(e.receivedAt) <= h.cfg.TTL {
+			entries = append(entries, e)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].receivedAt.After(entries[j].receivedAt) })
+
+	var out []ClusterSummary
+	if e, ok := h.clusters[h.local]; ok {
+		out = append(out, e.summary)
+	}
+	for _, e := range entries {
+		out = append(out, e.summary)
+	}
+	return out
+}
+
+// remoteTargets picks representative addresses in up to Fanout other
+// clusters, falling back to the seeds while none are known.
+func (h *Hierarchy) remoteTargets() []string {
+	h.mu.RLock()
+	now := h.cfg.Now()
+	var addrs []string
+	for key, e := range h.clusters {
+		if key == h.local || now.Sub(e.receivedAt) > h.cfg.TTL {
+			continue
+		}
+		for _, r := range e.summary.Representatives {
+			if r.Addr != "" {
+				addrs = append(addrs, r.Addr)
+				break
+			}
+		}
+	}
+	h.mu.RUnlock()
+
+	if len(addrs) == 0 {
+		addrs = append(addrs, h.cfg.Seeds...)
+	}
+	sort.Strings(addrs) // collected from a map; fix the order before shuffling
+	h.rng.Shuffle(len(addrs), func(i, j int) { addrs[i], addrs[j] = addrs[j], addrs[i] })
+	if len(addrs) > h.cfg.Fanout {
+		addrs = addrs[:h.cfg.Fanout]
+	}
+	return addrs
+}
+
+// ─── SWIM Integration ───────────────────────────────────────────────────────
+
+// SetHierarchy attaches the hierarchy view. Start then elects
+// representatives every Interval and, while this node is one of them,
+// exchanges cluster summaries with other clusters.
+func (s *SWIM) SetHierarchy(h *Hierarchy) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hierarchy = h
+}
+
+// Hierarchy returns the attached hierarchy view, or nil.
+func (s *SWIM) Hierarchy() *Hierarchy {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.hierarchy
+}
+
+// hierarchyLoop runs tier 2 until ctx is done. It does nothing if no
+// hierarchy is attached when Start runs.
+func (s *SWIM) hierarchyLoop(ctx context.Context) {
+	h := s.Hierarchy()
+	if h == nil {
+		return
+	}
+	ticker := time.NewTicker(h.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.exchangeSummaries(h)
+		}
+	}
+}
+
+// exchangeSummaries elects the cluster's representatives and, if this node
+// is one, publishes the local summary and sends the held summaries to other
+// clusters and to every local member.
+func (s *SWIM) exchangeSummaries(h *Hierarchy) {
+	h.Expire()
+	members := s.clusterMembers()
+	ids := make([]string, 0, len(members))
+	for _, m := range members {
+		if m.state == domain.PeerAlive {
+			ids = append(ids, m.nodeID)
+		}
+	}
+	h.Elect(ids)
+	if !h.IsRepresentative() {
+		return
+	}
+
+	h.Publish(s.summarize(h, members))
+	sums := h.outgoing()
+	var sent uint64
+	for _, a := range h.remoteTargets() {
+		if addr, err := net.ResolveUDPAddr("udp4", a); err == nil {
+			sent += s.sendSummaries(addr, sums)
+		}
+	}
+	for _, m := range members {
+		if s.peerSupports(m.nodeID, FeatureSummary) {
+			sent += s.sendSummaries(m.addr, sums)
+		}
+	}
+	h.mu.Lock()
+	h.sent += sent
+	h.mu.Unlock()
+}
+
+// summarize aggregates the local cluster from the membership view and the
+// heartbeat index. members excludes this node.
+func (s *SWIM) summarize(h *Hierarchy, members []member) ClusterSummary {
+	sum := ClusterSummary{Members: len(members) + 1, Alive: 1}
+	addrs := make(map[string]string, len(members))
+	for _, m := range members {
+		if m.state == domain.PeerAlive {
+			sum.Alive++
+		}
+		addrs[m.nodeID] = m.addr.String()
+	}
+	for _, id := range h.Representatives() {
+		sum.Representatives = append(sum.Representatives, Representative{NodeID: id, Addr: addrs[id]})
+	}
+
+	idx := s.Heartbeat()
+	if idx == nil {
+		return sum
+	}
+	beats := append([]Heartbeat{idx.Local()}, idx.Fresh()...)
+	hot := make(map[string]bool)
+	for _, b := range beats {
+		sum.Load += b.Load
+		sum.QueueDepth += b.QueueDepth
+		sum.FreeVRAM += b.FreeVRAM
+		for _, m := range b.HotModels {
+			hot[m] = true
+		}
+	}
+	sum.Load /= float64(len(beats))
+	for m := range hot {
+		sum.HotModels = append(sum.HotModels, m)
+	}
+	sort.Strings(sum.HotModels)
+	return sum
+}
+
+// sendSummaries sends sums to addr in batches and returns how many were sent.
+func (s *SWIM) sendSummaries(addr *net.UDPAddr, sums []ClusterSummary) uint64 {
+	var n uint64
+	for start := 0; start < len(sums); start += summaryBatch {
+		batch := sums[start:min(start+summaryBatch, len(sums))]
+		s.sendMessage(addr, Message{Type: MsgSummary, From: s.selfID, Summaries: batch})
+		n += uint64(len(batch))
+	}
+	return n
+}
+
+// applySummaries merges received summaries. The sender's own summary learns
+// the address it was sent from, which is how tier 2 finds representatives
+// behind address translation; summaries of the local cluster are only
+// accepted from its members.
+func (s *SWIM) applySummaries(msg Message, from *net.UDPAddr) {
+	s.mu.RLock()
+	h := s.hierarchy
+	m, isMember := s.members[msg.From]
+	isMember = isMember && m.state != domain.PeerDead
+	s.mu.RUnlock()
+	if h == nil {
+		return
+	}
+	for _, sum := range msg.Summaries {
+		if sum.Key() == h.local && !isMember {
+			continue
+		}
+		if sum.Issuer == msg.From {
+			reps := make([]Representative, len(sum.Representatives))
+			copy(reps, sum.Representatives)
+			for i := range reps {
+				if reps[i].NodeID == msg.From {
+					reps[i].Addr = from.String()
+				}
+			}
+			sum.Representatives = reps
+		}
+		h.Apply(sum)
+	}
+}
+
+// clusterMembers returns copies of the non-dead members, excluding seed
+// entries.
+func (s *SWIM) clusterMembers() []member {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]member, 0, len(s.members))
+	for id, m := range s.members {
+		if m.state != domain.PeerDead && m.addr != nil && (len(id) < 5 || id[:5] != "seed:") {
+			out = append(out, *m)
+		}
+	}
+	return out
+}
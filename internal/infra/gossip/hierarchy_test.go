@@ -0,0 +1,151 @@
+package gossip
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestHierarchy_ElectPublishAndApply(t *testing.T) {
+	clock := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := NewHierarchy("n2", HierarchyConfig{
+		Region: "us-east", Zone: "a", Cluster: "c1",
+		TTL: 10 * time.Second,
+		Now: func() time.Time { return clock },
+	})
+
+	if reps := h.Elect([]string{"n3", "n1", "n4"}); len(reps) != 2 || reps[0] != "n1" || reps[1] != "n2" {
+		t.Fatalf("Elect() = %v, want [n1 n2]", reps)
+	}
+	if !h.IsRepresentative() {
+		t.Error("n2 should represent its cluster")
+	}
+	h.Elect([]string{"n0", "n1"})
+	if h.IsRepresentative() {
+		t.Error("n2 still a representative with two lower IDs alive")
+	}
+
+	own := h.Publish(ClusterSummary{Members: 3})
+	if own.Seq != 1 || own.Issuer != "n2" || own.Key() != "us-east/a/c1" {
+		t.Fatalf("Publish() = %+v", own)
+	}
+	// A peer representative's newer summary of the same cluster wins, and
+	// the next local summary continues after it
+	h.Apply(ClusterSummary{Region: "us-east", Zone: "a", Cluster: "c1", Issuer: "n1", Seq: 5})
+	if own = h.Publish(ClusterSummary{}); own.Seq != 6 {
+		t.Errorf("Publish() after peer summary: seq %d, want 6", own.Seq)
+	}
+
+	tests := []struct {
+		name  string
+		sum   ClusterSummary
+		apply bool
+	}{
+		{"remote", ClusterSummary{Region: "eu-west", Zone: "b", Cluster: "c7", Issuer: "r1", Seq: 3, Load: 0.4}, true},
+		{"older seq ignored", ClusterSummary{Region: "eu-west", Zone: "b", Cluster: "c7", Issuer: "r2", Seq: 2}, false},
+		{"second remote", ClusterSummary{Region: "us-east", Zone: "b", Cluster: "c2", Issuer: "r3", Seq: 1}, true},
+		{"no cluster ignored", ClusterSummary{Region: "us-east", Issuer: "r4", Seq: 1}, false},
+		{"no issuer ignored", ClusterSummary{Region: "us-east", Zone: "b", Cluster: "c3", Seq: 1}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := h.Apply(tt.sum); got != tt.apply {
+				t.Errorf("Apply() = %v, want %v", got, tt.apply)
+			}
+		})
+	}
+
+	if sum, ok := h.Summary("eu-west/b/c7"); !ok || sum.Load != 0.4 {
+		t.Fatalf("Summary(eu-west/b/c7) = %+v, %v", sum, ok)
+	}
+	if remote := h.Remote(""); len(remote) != 2 || remote[0].Key() != "eu-west/b/c7" {
+		t.Errorf("Remote() = %+v, want two remote clusters", remote)
+	}
+	if remote := h.Remote("us-east"); len(remote) != 1 || remote[0].Cluster != "c2" {
+		t.Errorf("Remote(us-east) = %+v, want [c2]", remote)
+	}
+	if out := h.outgoing(); len(out) != 3 || out[0].Key() != h.LocalCluster() {
+		t.Errorf("outgoing() = %+v, want the local summary first", out)
+	}
+
+	clock = clock.Add(11 * time.Second)
+	if _, ok := h.Summary("eu-west/b/c7"); ok {
+		t.Error("stale summary returned")
+	}
+	if st := h.Stats(); st.Clusters != 3 || st.Stale != 3 || st.Representative {
+		t.Errorf("Stats() = %+v", st)
+	}
+	clock = clock.Add(10 * time.Second)
+	if n := h.Expire(); n != 3 {
+		t.Errorf("Expire() = %d, want 3", n)
+	}
+}
+
+func TestHierarchy_RemoteTargetsFallBackToSeeds(t *testing.T) {
+	h := NewHierarchy("n1", HierarchyConfig{Cluster: "c1", Fanout: 1, Seeds: []string{"10.0.0.9:7946"}})
+	if got := h.remoteTargets(); len(got) != 1 || got[0] != "10.0.0.9:7946" {
+		t.Fatalf("remoteTargets() = %v, want the seed", got)
+	}
+	h.Apply(ClusterSummary{Cluster: "c2", Issuer: "r1", Seq: 1,
+		Representatives: []Representative{{NodeID: "r1", Addr: "10.0.1.1:7946"}}})
+	if got := h.remoteTargets(); len(got) != 1 || got[0] != "10.0.1.1:7946" {
+		t.Errorf("remoteTargets() = %v, want the known representative", got)
+	}
+}
+
+func TestSWIM_ApplySummaries(t *testing.T) {
+	s, _ := newTestSWIM(t, "node-1")
+	h := NewHierarchy("node-1", HierarchyConfig{Cluster: "c1"})
+	s.SetHierarchy(h)
+	from := &net.UDPAddr{IP: net.IPv4(203, 0, 113, 5), Port: 7946}
+
+	s.applySummaries(Message{From: "r1", Summaries: []ClusterSummary{
+		{Cluster: "c2", Issuer: "r1", Seq: 1, Representatives: []Representative{{NodeID: "r1"}, {NodeID: "r2"}}},
+		{Cluster: "c1", Issuer: "r1", Seq: 9}, // not a member of c1
+	}}, from)
+
+	sum, ok := h.Summary(ClusterKey("", "", "c2"))
+	if !ok || sum.Representatives[0].Addr != from.String() || sum.Representatives[1].Addr != "" {
+		t.Fatalf("Summary(c2) = %+v, %v; want r1 at %s", sum, ok, from)
+	}
+	if _, ok := h.Summary(h.LocalCluster()); ok {
+		t.Error("a non-member rewrote the local cluster's summary")
+	}
+}
+
+func TestSWIM_HierarchyExchange(t *testing.T) {
+	node1, _ := newTestSWIM(t, "node-1")
+	node2, _ := newTestSWIM(t, "node-2")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	cfg := HierarchyConfig{Region: "us-east", Zone: "a", Cluster: "c1", Interval: 100 * time.Millisecond}
+	h1 := NewHierarchy("node-1", cfg)
+	node1.SetHierarchy(h1)
+	go node1.Start(ctx)
+	time.Sleep(100 * time.Millisecond)
+
+	// node-2 lives in another cluster and only knows node-1 as a seed
+	cfg.Cluster = "c2"
+	cfg.Seeds = []string{node1.selfAddr.String()}
+	h2 := NewHierarchy("node-2", cfg)
+	node2.SetHierarchy(h2)
+	go node2.Start(ctx)
+
+	for {
+		_, ok1 := h1.Summary("us-east/a/c2")
+		_, ok2 := h2.Summary("us-east/a/c1")
+		if ok1 && ok2 {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			t.Fatalf("summaries not exchanged (node-1 knows c2: %v, node-2 knows c1: %v)", ok1, ok2)
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+	if node1.AliveCount() != 0 || node2.AliveCount() != 0 {
+		t.Error("tier 2 traffic added members across clusters")
+	}
+}
@@ -0,0 +1,108 @@
+package gossip
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
+)
+
+// ─── Fleet Learning ─────────────────────────────────────────────────────────
+// Nodes share what their schedulers learned so a new node does not start
+// from scratch. Every Interval the local summary (cfg.Local) is sent to
+// Fanout random members, unacknowledged and not relayed; a summary is only
+// accepted from a known, live member. The payload format belongs to the
+// caller (see mlscheduler.ArmSummary) and must fit one datagram.
+
+// LearningConfig configures the learning exchange.
+type LearningConfig struct {
+	Interval time.Duration                     // send cadence (default: 30s)
+	Fanout   int                               // members per round (default: 3)
+	Local    func() []byte                     // builds the local summary; nil or empty skips the round
+	Apply    func(from string, payload []byte) // merges a peer's summary; runs on the receive loop
+}
+
+// DefaultLearningConfig returns the default exchange cadence.
+func DefaultLearningConfig() LearningConfig {
+	return LearningConfig{
+		Interval: 30 * time.Second,
+		Fanout:   3,
+	}
+}
+
+// maxLearningPayload keeps a summary inside the receive buffer with room
+// for the envelope and piggybacked updates.
+const maxLearningPayload = 48 * 1024
+
+// SetLearning attaches the learning exchange. Start sends the local summary
+// every Interval; received summaries go to cfg.Apply. Zero fields take
+// their defaults.
+func (s *SWIM) SetLearning(cfg LearningConfig) {
+	def := DefaultLearningConfig()
+	if cfg.Interval <= 0 {
+		cfg.Interval = def.Interval
+	}
+	if cfg.Fanout <= 0 {
+		cfg.Fanout = def.Fanout
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.learning = &cfg
+}
+
+// learningConfig returns the attached exchange, or nil.
+func (s *SWIM) learningConfig() *LearningConfig {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.learning
+}
+
+// learningLoop sends summaries until ctx is done. It does nothing if no
+// exchange is attached when Start runs.
+func (s *SWIM) learningLoop(ctx context.Context) {
+	cfg := s.learningConfig()
+	if cfg == nil || cfg.Local == nil {
+		return
+	}
+	ticker := time.NewTicker(cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.sendLearning(cfg)
+		}
+	}
+}
+
+// sendLearning builds the local summary and sends it to Fanout random
+// members. Oversized or non-JSON summaries are dropped.
+func (s *SWIM) sendLearning(cfg *LearningConfig) {
+	targets := s.randomMembersWith(cfg.Fanout, "", FeatureLearning)
+	if len(targets) == 0 {
+		return
+	}
+	payload := cfg.Local() // outside s.mu
+	if len(payload) == 0 || len(payload) > maxLearningPayload || !json.Valid(payload) {
+		return
+	}
+	for _, m := range targets {
+		s.sendMessage(m.addr, Message{Type: MsgLearning, From: s.selfID, Learning: payload})
+	}
+}
+
+// handleLearning hands a received summary to cfg.Apply if it came from a
+// known, live member.
+func (s *SWIM) handleLearning(msg Message) {
+	s.mu.RLock()
+	cfg := s.learning
+	m, ok := s.members[msg.From]
+	known := ok && m.state != domain.PeerDead
+	s.mu.RUnlock()
+	if cfg == nil || cfg.Apply == nil || !known || msg.From == s.selfID || len(msg.Learning) == 0 {
+		return
+	}
+	cfg.Apply(msg.From, msg.Learning)
+}
@@ -0,0 +1,29 @@
+package gossip
+
+import (
+	"testing"
+
+	"github.com/tutu-network/tutu/internal/domain"
+)
+
+func TestSWIM_Learning_AcceptsLiveMembersOnly(t *testing.T) {
+	s, _ := newTestSWIM(t, "node-1")
+	got := map[string]string{}
+	s.SetLearning(LearningConfig{Apply: func(from string, payload []byte) {
+		got[from] = string(payload)
+	}})
+	s.members["node-2"] = &member{nodeID: "node-2", state: domain.PeerAlive}
+	s.members["node-3"] = &member{nodeID: "node-3", state: domain.PeerDead}
+
+	for _, from := range []string{"node-2", "node-3", "node-4", "node-1"} {
+		s.handleMessage(Message{Type: MsgLearning, From: from, Learning: []byte(`[]`)}, nil)
+	}
+	if len(got) != 1 || got["node-2"] != `[]` {
+		t.Errorf("applied %v, want only node-2", got)
+	}
+
+	cfg := s.learningConfig()
+	if cfg.Interval != DefaultLearningConfig().Interval || cfg.Fanout != DefaultLearningConfig().Fanout {
+		t.Errorf("defaults not applied: %+v", cfg)
+	}
+}
@@ -0,0 +1,305 @@
+package gossip
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// ─── Queue Load Advertisement ───────────────────────────────────────────────
+// Every node advertises its scheduler queue depth so idle peers can find
+// work to steal without polling anyone. Reports ride on SWIM PING/ACK
+// messages next to membership updates and model announcements, carry a
+// per-node sequence number (older or equal reports are ignored) and expire
+// after TTL, so a node that stops reporting is never picked as a victim.
+//
+// Load changes much faster than hosted models, so the default refresh is
+// short and only the latest report per node is ever retransmitted.
+
+// LoadReport is a node's advertised queue depth.
+type LoadReport struct {
+	NodeID     string `json:"node_id"`
+	Seq        uint64 `json:"seq"`
+	QueueDepth int    `json:"queue_depth"`
+}
+
+// LoadConfig controls load report refresh and staleness.
+type LoadConfig struct {
+	TTL             time.Duration // entries older than this are stale (default: 15s)
+	RefreshInterval time.Duration // how often the local node re-reports (default: 5s)
+
+	// Local, if set, is polled on every refresh for this node's queue depth.
+	Local func() int
+
+	Now func() time.Time // injectable clock (default: time.Now)
+}
+
+// DefaultLoadConfig returns defaults that keep reports at most a few
+// seconds old while three refreshes still fit into one TTL.
+func DefaultLoadConfig() LoadConfig {
+	return LoadConfig{
+		TTL:             15 * time.Second,
+		RefreshInterval: 5 * time.Second,
+	}
+}
+
+// NodeLoad is one node's entry in the load index.
+type NodeLoad struct {
+	NodeID     string    `json:"node_id"`
+	QueueDepth int       `json:"queue_depth"`
+	UpdatedAt  time.Time `json:"updated_at"`
+	Stale      bool      `json:"stale"`
+}
+
+// loadEntry is the index's record of one node.
+type loadEntry struct {
+	seq       uint64
+	depth     int
+	updatedAt time.Time
+}
+
+// LoadIndex is a staleness-aware map of peers' queue depths. It is safe for
+// concurrent use.
+type LoadIndex struct {
+	mu     sync.RWMutex
+	cfg    LoadConfig
+	selfID string
+	self   loadEntry
+	nodes  map[string]loadEntry // remote nodeID → entry
+}
+
+// NewLoadIndex creates a load index for the local node selfID.
+func NewLoadIndex(selfID string, cfg LoadConfig) *LoadIndex {
+	def := DefaultLoadConfig()
+	if cfg.TTL <= 0 {
+		cfg.TTL = def.TTL
+	}
+	if cfg.RefreshInterval <= 0 {
+		cfg.RefreshInterval = def.RefreshInterval
+	}
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+	return &LoadIndex{
+		cfg:    cfg,
+		selfID: selfID,
+		self:   loadEntry{seq: uint64(time.Now().UnixNano())}, // above any seq a previous run reported
+		nodes:  make(map[string]loadEntry),
+	}
+}
+
+// SetLocal records the local queue depth and returns the report to
+// disseminate. The sequence number always advances, from the clock at
+// startup, so a restarted node's reports are not ignored as replays.
+func (x *LoadIndex) SetLocal(depth int) LoadReport {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.self = loadEntry{seq: x.self.seq + 1, depth: max(depth, 0), updatedAt: x.cfg.Now()}
+	return LoadReport{NodeID: x.selfID, Seq: x.self.seq, QueueDepth: x.self.depth}
+}
+
+// Refresh re-reads the local queue depth from cfg.Local (if set) and returns
+// the resulting report.
+func (x *LoadIndex) Refresh() LoadReport {
+	if x.cfg.Local != nil {
+		return x.SetLocal(x.cfg.Local())
+	}
+	x.mu.RLock()
+	depth := x.self.depth
+	x.mu.RUnlock()
+	return x.SetLocal(depth)
+}
+
+// Apply merges a remote report. Returns true if it was newer than what the
+// index held (and should therefore be re-gossiped).
+func (x *LoadIndex) Apply(r LoadReport) bool {
+	if r.NodeID == "" || r.NodeID == x.selfID {
+		return false
+	}
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if cur, ok := x.nodes[r.NodeID]; ok && r.Seq <= cur.seq {
+		return false
+	}
+	x.nodes[r.NodeID] = loadEntry{seq: r.Seq, depth: max(r.QueueDepth, 0), updatedAt: x.cfg.Now()}
+	return true
+}
+
+// Remove forgets a node (e.g. when SWIM declares it dead).
+func (x *LoadIndex) Remove(nodeID string) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	delete(x.nodes, nodeID)
+}
+
+// Expire drops stale remote entries and returns how many were removed.
+func (x *LoadIndex) Expire() int {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	now := x.cfg.Now()
+	n := 0
+	for id, e := range x.nodes {
+		if now.Sub(e.updatedAt) > x.cfg.TTL {
+			delete(x.nodes, id)
+			n++
+		}
+	}
+	return n
+}
+
+// Depth returns nodeID's advertised queue depth and whether a fresh report
+// exists for it.
+func (x *LoadIndex) Depth(nodeID string) (int, bool) {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	if nodeID == x.selfID {
+		return x.self.depth, true
+	}
+	e, ok := x.nodes[nodeID]
+	if !ok || x.cfg.Now().Sub(e.updatedAt) > x.cfg.TTL {
+		return 0, false
+	}
+	return e.depth, true
+}
+
+// Busiest returns fresh remote nodes whose queue depth is at least minDepth,
+// deepest first (ties broken by node ID).
+func (x *LoadIndex) Busiest(minDepth int) []NodeLoad {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	now := x.cfg.Now()
+
+	var out []NodeLoad
+	for id, e := range x.nodes {
+		if e.depth >= minDepth && now.Sub(e.updatedAt) <= x.cfg.TTL {
+			out = append(out, NodeLoad{NodeID: id, QueueDepth: e.depth, UpdatedAt: e.updatedAt})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].QueueDepth != out[j].QueueDepth {
+			return out[i].QueueDepth > out[j].QueueDepth
+		}
+		return out[i].NodeID < out[j].NodeID
+	})
+	return out
+}
+
+// Snapshot returns every known node's entry, including stale ones (flagged),
+// sorted by node ID. The local node is always first.
+func (x *LoadIndex) Snapshot() []NodeLoad {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	now := x.cfg.Now()
+
+	out := make([]NodeLoad, 0, len(x.nodes)+1)
+	for id, e := range x.nodes {
+		out = append(out, NodeLoad{
+			NodeID:     id,
+			QueueDepth: e.depth,
+			UpdatedAt:  e.updatedAt,
+			Stale:      now.Sub(e.updatedAt) > x.cfg.TTL,
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].NodeID < out[j].NodeID })
+	self := NodeLoad{NodeID: x.selfID, QueueDepth: x.self.depth, UpdatedAt: x.self.updatedAt}
+	return append([]NodeLoad{self}, out...)
+}
+
+// ─── SWIM Integration ───────────────────────────────────────────────────────
+
+// SetLoad attaches a load index. The local report is refreshed every
+// RefreshInterval and piggybacked alongside membership updates; received
+// reports are merged and re-gossiped when new.
+func (s *SWIM) SetLoad(idx *LoadIndex) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.load = idx
+	s.lastLoad = time.Time{}
+}
+
+// Load returns the attached load index, or nil.
+func (s *SWIM) Load() *LoadIndex {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.load
+}
+
+// refreshLoad re-reports the local queue depth and expires stale peers.
+// Called once per probe cycle.
+func (s *SWIM) refreshLoad() {
+	s.mu.RLock()
+	idx, last := s.load, s.lastLoad
+	s.mu.RUnlock()
+	if idx == nil {
+		return
+	}
+
+	idx.Expire()
+	now := idx.cfg.Now()
+	if now.Sub(last) < idx.cfg.RefreshInterval {
+		return
+	}
+	r := idx.Refresh() // may call cfg.Local — outside s.mu
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.load == idx {
+		s.queueLoad(r)
+		s.lastLoad = now
+	}
+}
+
+// applyLoad merges a received report and re-queues it for dissemination if
+// it was new.
+func (s *SWIM) applyLoad(r LoadReport) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.load != nil && s.load.Apply(r) {
+		s.queueLoad(r)
+	}
+}
+
+// forgetLoad drops a dead node's report.
+// Must be called with s.mu held.
+func (s *SWIM) forgetLoad(nodeID string) {
+	if s.load != nil {
+		s.load.Remove(nodeID)
+	}
+}
+
+// queueLoad adds a report to the piggyback queue, replacing any older one
+// from the same node. Must be called with s.mu held.
+func (s *SWIM) queueLoad(r LoadReport) {
+	for i, q := range s.loadQueue {
+		if q.NodeID == r.NodeID {
+			s.loadQueue = append(s.loadQueue[:i], s.loadQueue[i+1:]...)
+			break
+		}
+	}
+	s.loadQueue = append(s.loadQueue, r)
+	s.loadLeft[r.NodeID] = s.config.Lambda * s.logN()
+}
+
+// drainLoad returns pending load reports for piggybacking.
+func (s *SWIM) drainLoad() []LoadReport {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.loadQueue) == 0 {
+		return nil
+	}
+
+	result := make([]LoadReport, 0, len(s.loadQueue))
+	remaining := make([]LoadReport, 0)
+	for _, r := range s.loadQueue {
+		result = append(result, r)
+		s.loadLeft[r.NodeID]--
+		if s.loadLeft[r.NodeID] > 0 {
+			remaining = append(remaining, r)
+		} else {
+			delete(s.loadLeft, r.NodeID)
+		}
+	}
+	s.loadQueue = remaining
+	return result
+}
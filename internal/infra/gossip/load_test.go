@@ -0,0 +1,70 @@
+package gossip
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoadIndex_BusiestAndStaleness(t *testing.T) {
+	clock := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	x := NewLoadIndex("self", LoadConfig{TTL: 10 * time.Second, Now: func() time.Time { return clock }})
+	x.SetLocal(50)
+
+	tests := []struct {
+		name  string
+		r     LoadReport
+		apply bool
+	}{
+		{"first", LoadReport{NodeID: "n1", Seq: 2, QueueDepth: 8}, true},
+		{"older seq ignored", LoadReport{NodeID: "n1", Seq: 1, QueueDepth: 100}, false},
+		{"second node", LoadReport{NodeID: "n2", Seq: 1, QueueDepth: 20}, true},
+		{"shallow node", LoadReport{NodeID: "n3", Seq: 1, QueueDepth: 1}, true},
+		{"self ignored", LoadReport{NodeID: "self", Seq: 9, QueueDepth: 0}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := x.Apply(tt.r); got != tt.apply {
+				t.Errorf("Apply() = %v, want %v", got, tt.apply)
+			}
+		})
+	}
+
+	busy := x.Busiest(4)
+	if len(busy) != 2 || busy[0].NodeID != "n2" || busy[1].NodeID != "n1" {
+		t.Fatalf("Busiest(4) = %+v, want [n2 n1]", busy)
+	}
+	if d, ok := x.Depth("self"); !ok || d != 50 {
+		t.Errorf("Depth(self) = %d, %v", d, ok)
+	}
+
+	clock = clock.Add(11 * time.Second)
+	if len(x.Busiest(0)) != 0 {
+		t.Error("stale nodes should not be offered as victims")
+	}
+	if _, ok := x.Depth("n1"); ok {
+		t.Error("stale node reported a depth")
+	}
+	if n := x.Expire(); n != 3 {
+		t.Errorf("Expire() = %d, want 3", n)
+	}
+}
+
+func TestSWIM_LoadDissemination(t *testing.T) {
+	s, _ := newTestSWIM(t, "node-1")
+	s.SetLoad(NewLoadIndex("node-1", LoadConfig{}))
+
+	s.applyLoad(LoadReport{NodeID: "node-2", Seq: 1, QueueDepth: 12})
+	if d, ok := s.Load().Depth("node-2"); !ok || d != 12 {
+		t.Fatalf("Depth(node-2) = %d, %v; want 12", d, ok)
+	}
+	if got := s.drainLoad(); len(got) != 1 || got[0].NodeID != "node-2" {
+		t.Fatalf("drainLoad = %+v, want node-2 re-gossiped", got)
+	}
+
+	s.mu.Lock()
+	s.forgetLoad("node-2")
+	s.mu.Unlock()
+	if _, ok := s.Load().Depth("node-2"); ok {
+		t.Error("dead node still listed")
+	}
+}
@@ -0,0 +1,188 @@
+package gossip
+
+import (
+	"slices"
+	"sync/atomic"
+
+	"github.com/tutu-network/tutu/internal/domain"
+)
+
+// ─── Protocol Versions ──────────────────────────────────────────────────────
+// Fleets upgrade one node at a time, so nodes of different releases gossip
+// with each other. Every message carries the highest protocol version its
+// sender speaks and the oldest it still accepts; PING and ACK also carry
+// the features it has enabled. The receiver records both per peer:
+//
+//   - Two nodes talk at the lower of their versions. If that is below
+//     either side's minimum the peer is incompatible: its messages are
+//     dropped and counted, so it ends up suspected and declared dead
+//     instead of half-working.
+//   - A message without a version comes from a node that predates the
+//     handshake and is taken as version 1.
+//   - Direct messages (heartbeats, rendezvous signals, cluster summaries,
+//     learning summaries) go only to peers that advertise the feature, or —
+//     when a peer advertises none — whose version includes it. Peers not
+//     heard from yet are assumed to speak ours; an older node ignores
+//     message types it does not know.
+//   - Piggybacked fields are always sent: JSON decoding skips fields a
+//     peer does not know.
+//
+// A change that older nodes would misread gets a new version, with the
+// features it introduces listed in versionFeatures.
+
+// Protocol versions this node speaks.
+const (
+	ProtocolVersion    uint16 = 2 // version 2 adds the handshake and direct messages
+	MinProtocolVersion uint16 = 1 // oldest version still accepted
+)
+
+// legacyVersion is assumed for messages that carry no version.
+const legacyVersion uint16 = 1
+
+// Features a node can advertise.
+const (
+	FeatureAvailability = "avail" // piggybacked model availability
+	FeatureLoad         = "load"  // piggybacked queue depth
+	FeatureQuarantine   = "quar"  // piggybacked quarantine notices
+	FeatureBlocklist    = "block" // piggybacked blocklist entries
+	FeatureHeartbeat    = "hb"    // MsgHeartbeat
+	FeatureRendezvous   = "rdv"   // MsgRendezvous
+	FeatureSummary      = "clus"  // MsgSummary
+	FeatureLearning     = "learn" // MsgLearning
+)
+
+// versionFeatures is the compatibility matrix: the features each protocol
+// version speaks, assumed for peers that do not advertise their own.
+var versionFeatures = map[uint16][]string{
+	1: {FeatureAvailability, FeatureLoad, FeatureQuarantine},
+	2: {FeatureAvailability, FeatureLoad, FeatureQuarantine, FeatureBlocklist,
+		FeatureHeartbeat, FeatureRendezvous, FeatureSummary, FeatureLearning},
+}
+
+// PeerProtocol is what a peer advertised in its last message.
+type PeerProtocol struct {
+	Version    uint16   `json:"version"`     // highest version the peer speaks
+	MinVersion uint16   `json:"min_version"` // oldest version it accepts
+	Negotiated uint16   `json:"negotiated"`  // version the two nodes talk at
+	Features   []string `json:"features"`
+	Legacy     bool     `json:"legacy"` // peer predates the handshake
+}
+
+// Supports reports whether the peer speaks feature.
+func (p PeerProtocol) Supports(feature string) bool {
+	return slices.Contains(p.Features, feature)
+}
+
+// ProtocolStats summarizes the protocol versions of known peers.
+type ProtocolStats struct {
+	Version      uint16         `json:"version"`
+	MinVersion   uint16         `json:"min_version"`
+	Peers        map[uint16]int `json:"peers"`        // negotiated version → peers
+	Legacy       int            `json:"legacy"`       // peers that predate the handshake
+	Incompatible int64          `json:"incompatible"` // messages dropped from incompatible peers
+}
+
+// protocolState is this node's side of the handshake. Peers are tracked in
+// SWIM.protos under s.mu.
+type protocolState struct {
+	version      uint16
+	minVersion   uint16
+	incompatible atomic.Int64
+}
+
+func newProtocolState() *protocolState {
+	return &protocolState{version: ProtocolVersion, minVersion: MinProtocolVersion}
+}
+
+// negotiate checks a received message's protocol version and records what
+// its sender advertised. It reports false if the sender is incompatible.
+func (s *SWIM) negotiate(msg Message) bool {
+	p := PeerProtocol{Version: msg.Version, MinVersion: msg.MinVersion}
+	if p.Version == 0 {
+		p.Version, p.MinVersion, p.Legacy = legacyVersion, legacyVersion, true
+	}
+	if p.MinVersion == 0 || p.MinVersion > p.Version {
+		p.MinVersion = p.Version
+	}
+	p.Negotiated = min(s.proto.version, p.Version)
+	if p.Negotiated < max(s.proto.minVersion, p.MinVersion) {
+		s.proto.incompatible.Add(1)
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	prev, seen := s.protos[msg.From]
+	switch {
+	case msg.Features != nil:
+		p.Features = slices.Clone(msg.Features)
+	case seen && prev.Version == p.Version && prev.MinVersion == p.MinVersion:
+		p.Features = prev.Features
+	default:
+		p.Features = versionFeatures[p.Negotiated]
+	}
+	s.protos[msg.From] = p
+	return true
+}
+
+// localFeatures lists the features this node advertises: those of its
+// version, less the exchanges it has not enabled.
+func (s *SWIM) localFeatures() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]string, 0, len(versionFeatures[s.proto.version]))
+	for _, f := range versionFeatures[s.proto.version] {
+		switch {
+		case f == FeatureSummary && s.hierarchy == nil,
+			f == FeatureLearning && s.learning == nil:
+			continue
+		}
+		out = append(out, f)
+	}
+	return out
+}
+
+// peerSupports reports whether a direct message of feature may be sent to
+// nodeID. Peers not heard from yet are assumed to support it.
+func (s *SWIM) peerSupports(nodeID, feature string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.supportsLocked(nodeID, feature)
+}
+
+// supportsLocked is peerSupports with s.mu held.
+func (s *SWIM) supportsLocked(nodeID, feature string) bool {
+	p, ok := s.protos[nodeID]
+	return !ok || p.Supports(feature)
+}
+
+// PeerProtocol returns what nodeID advertised in its last message.
+func (s *SWIM) PeerProtocol(nodeID string) (PeerProtocol, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	p, ok := s.protos[nodeID]
+	p.Features = slices.Clone(p.Features)
+	return p, ok
+}
+
+// ProtocolStats returns this node's versions and those of its peers.
+func (s *SWIM) ProtocolStats() ProtocolStats {
+	st := ProtocolStats{
+		Version:      s.proto.version,
+		MinVersion:   s.proto.minVersion,
+		Peers:        make(map[uint16]int),
+		Incompatible: s.proto.incompatible.Load(),
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for id, p := range s.protos {
+		if m, ok := s.members[id]; !ok || m.state == domain.PeerDead {
+			continue
+		}
+		st.Peers[p.Negotiated]++
+		if p.Legacy {
+			st.Legacy++
+		}
+	}
+	return st
+}
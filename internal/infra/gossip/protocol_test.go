@@ -0,0 +1,153 @@
+package gossip
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
+)
+
+func TestNegotiate(t *testing.T) {
+	s, _ := newTestSWIM(t, "node-1")
+	s.proto.minVersion = 2
+
+	cases := []struct {
+		from            string
+		version, minVer uint16
+		want            bool
+		negotiated      uint16
+	}{
+		{"same", ProtocolVersion, MinProtocolVersion, true, ProtocolVersion},
+		{"newer", ProtocolVersion + 1, ProtocolVersion, true, ProtocolVersion},
+		{"legacy", 0, 0, false, 0},                                      // v1 is below our minimum
+		{"too-new", ProtocolVersion + 2, ProtocolVersion + 1, false, 0}, // its minimum is above us
+	}
+	for _, c := range cases {
+		ok := s.negotiate(Message{From: c.from, Version: c.version, MinVersion: c.minVer})
+		if ok != c.want {
+			t.Errorf("%s: negotiate = %v, want %v", c.from, ok, c.want)
+			continue
+		}
+		p, known := s.PeerProtocol(c.from)
+		if known != c.want || (known && p.Negotiated != c.negotiated) {
+			t.Errorf("%s: PeerProtocol = %+v, %v", c.from, p, known)
+		}
+	}
+	if got := s.ProtocolStats().Incompatible; got != 2 {
+		t.Errorf("incompatible = %d, want 2", got)
+	}
+}
+
+func TestNegotiate_Features(t *testing.T) {
+	s, _ := newTestSWIM(t, "node-1")
+
+	// A node that predates the handshake gets version 1's features
+	s.negotiate(Message{Type: MsgHeartbeat, From: "old"})
+	p, _ := s.PeerProtocol("old")
+	if !p.Legacy || p.Negotiated != 1 || p.Supports(FeatureHeartbeat) || !p.Supports(FeatureLoad) {
+		t.Errorf("legacy peer = %+v", p)
+	}
+
+	// Advertised features are kept across messages without them …
+	s.negotiate(Message{Type: MsgPing, From: "new", Version: 2, MinVersion: 1,
+		Features: []string{FeatureHeartbeat}})
+	s.negotiate(Message{Type: MsgHeartbeat, From: "new", Version: 2, MinVersion: 1})
+	if p, _ := s.PeerProtocol("new"); !slices.Equal(p.Features, []string{FeatureHeartbeat}) {
+		t.Errorf("features = %v, want [hb]", p.Features)
+	}
+	if s.peerSupports("new", FeatureLearning) || !s.peerSupports("new", FeatureHeartbeat) {
+		t.Error("peerSupports does not follow the advertised features")
+	}
+	if !s.peerSupports("unknown", FeatureLearning) {
+		t.Error("a peer not heard from yet should be assumed current")
+	}
+
+	// … until the peer changes version
+	s.negotiate(Message{Type: MsgHeartbeat, From: "new"})
+	if p, _ := s.PeerProtocol("new"); p.Supports(FeatureHeartbeat) {
+		t.Errorf("downgraded peer kept its features: %v", p.Features)
+	}
+}
+
+func TestLocalFeatures(t *testing.T) {
+	s, _ := newTestSWIM(t, "node-1")
+	if f := s.localFeatures(); slices.Contains(f, FeatureLearning) || !slices.Contains(f, FeatureHeartbeat) {
+		t.Errorf("features without learning = %v", f)
+	}
+	s.SetLearning(LearningConfig{})
+	if f := s.localFeatures(); !slices.Contains(f, FeatureLearning) {
+		t.Errorf("features with learning = %v", f)
+	}
+}
+
+func TestRandomMembersWith(t *testing.T) {
+	s, _ := newTestSWIM(t, "node-1")
+	for _, id := range []string{"a", "b", "c"} {
+		s.members[id] = &member{nodeID: id, state: domain.PeerAlive}
+	}
+	s.negotiate(Message{From: "a"}) // legacy
+	s.negotiate(Message{From: "b", Version: 2, MinVersion: 1, Features: []string{FeatureHeartbeat}})
+
+	var got []string
+	for _, m := range s.randomMembersWith(3, "", FeatureHeartbeat) {
+		got = append(got, m.nodeID)
+	}
+	slices.Sort(got)
+	if !slices.Equal(got, []string{"b", "c"}) {
+		t.Errorf("heartbeat targets = %v, want [b c]", got)
+	}
+	if err := s.SendRendezvous("a", []byte(`{}`)); err == nil {
+		t.Error("rendezvous sent to a legacy peer")
+	}
+}
+
+func TestTwoNodes_MixedVersions(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	node1, _ := newTestSWIM(t, "node-1")
+	node2, _ := newTestSWIM(t, "node-2")
+	node2.proto.version = 1 // an older release
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var wg sync.WaitGroup
+	for _, n := range []*SWIM{node1, node2} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			n.Start(ctx)
+		}()
+	}
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+	time.Sleep(100 * time.Millisecond)
+	if err := node2.Join([]string{node1.Addr().String()}); err != nil {
+		t.Fatalf("Join() error: %v", err)
+	}
+
+	deadline := time.Now().Add(3 * time.Second)
+	for {
+		p1, ok1 := node1.PeerProtocol("node-2")
+		p2, ok2 := node2.PeerProtocol("node-1")
+		if ok1 && ok2 {
+			if p1.Negotiated != 1 || p2.Negotiated != 1 {
+				t.Errorf("negotiated %d and %d, want 1", p1.Negotiated, p2.Negotiated)
+			}
+			if p1.Supports(FeatureHeartbeat) {
+				t.Errorf("node-2 advertised %v, want version 1's features", p1.Features)
+			}
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("nodes did not exchange protocol versions")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
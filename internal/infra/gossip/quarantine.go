@@ -0,0 +1,271 @@
+package gossip
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/security"
+)
+
+// ─── Quarantine Notices ─────────────────────────────────────────────────────
+// A node that quarantines a peer tells the rest of the network, so nobody
+// keeps assigning work to it. Notices ride on SWIM PING/ACK messages like
+// load reports, and are signed by their issuer like blocklist entries:
+//
+//  1. A notice is accepted only if its signature verifies against the
+//     public key its issuer's node ID encodes, and the issuer is trusted —
+//     this node or one of the list's signers. Other notices are dropped,
+//     never re-gossiped
+//  2. One entry per quarantined node; a notice replaces the current entry
+//     only if it was issued later, so a release overrides the quarantine it
+//     ends and a stale copy cannot resurrect one. Only the issuer of a
+//     quarantine can release it
+//  3. Entries end at Until without any further message — a node whose
+//     issuer went away is not blocked forever
+//  4. Notices are not tied to membership: a quarantined node that leaves
+//     and rejoins is still quarantined
+
+// QuarantineNotice announces that NodeID is quarantined until Until, or —
+// with Released set — that an earlier quarantine was lifted.
+type QuarantineNotice struct {
+	NodeID    string    `json:"node_id"`
+	Reason    string    `json:"reason"`
+	Issuer    string    `json:"issuer"` // issuer's node ID (hex Ed25519 public key)
+	IssuedAt  time.Time `json:"issued_at"`
+	Until     time.Time `json:"until"`
+	Released  bool      `json:"released,omitempty"`
+	Signature []byte    `json:"signature"`
+}
+
+// SigningPayload returns the bytes the issuer signs: every field but the
+// signature.
+func (n QuarantineNotice) SigningPayload() []byte {
+	return []byte(strings.Join([]string{
+		"tutu-quarantine-v1",
+		n.NodeID,
+		n.Reason,
+		n.Issuer,
+		unixNanoString(n.IssuedAt),
+		unixNanoString(n.Until),
+		strconv.FormatBool(n.Released),
+	}, "\n"))
+}
+
+// Sign sets kp as the notice's issuer and signs it.
+func (n *QuarantineNotice) Sign(kp *security.Keypair) {
+	n.Issuer = kp.PublicKeyHex()
+	n.Signature = kp.Sign(n.SigningPayload())
+}
+
+// Verify checks the notice's fields and its signature.
+func (n QuarantineNotice) Verify() error {
+	if n.NodeID == "" || n.IssuedAt.IsZero() {
+		return fmt.Errorf("gossip: quarantine notice needs a node and an issue time")
+	}
+	pub, err := hex.DecodeString(n.Issuer)
+	if err != nil || len(pub) != ed25519.PublicKeySize {
+		return fmt.Errorf("gossip: quarantine issuer %q is not a public key", n.Issuer)
+	}
+	if !security.Verify(n.SigningPayload(), n.Signature, ed25519.PublicKey(pub)) {
+		return fmt.Errorf("gossip: quarantine notice for %s has a bad signature", n.NodeID)
+	}
+	return nil
+}
+
+// Active reports whether the notice quarantines its node at now.
+func (n QuarantineNotice) Active(now time.Time) bool {
+	return !n.Released && now.Before(n.Until)
+}
+
+// quarantineClockSkew is how far in the future a notice may be issued.
+const quarantineClockSkew = 5 * time.Minute
+
+// QuarantineList is the gossiped set of quarantined nodes. It is safe for
+// concurrent use.
+type QuarantineList struct {
+	mu      sync.RWMutex
+	now     func() time.Time
+	trusted map[string]bool
+	notices map[string]QuarantineNotice // quarantined nodeID → latest notice
+}
+
+// NewQuarantineList creates an empty list for selfID that accepts notices
+// issued by selfID and signers. now defaults to time.Now.
+func NewQuarantineList(selfID string, signers []string, now func() time.Time) *QuarantineList {
+	if now == nil {
+		now = time.Now
+	}
+	trusted := map[string]bool{selfID: true}
+	for _, s := range signers {
+		trusted[strings.ToLower(strings.TrimSpace(s))] = true
+	}
+	return &QuarantineList{now: now, trusted: trusted, notices: make(map[string]QuarantineNotice)}
+}
+
+// Apply verifies and merges a notice. Returns true if it was newer than the
+// held entry (and should therefore be re-gossiped); an error if it was
+// rejected.
+func (q *QuarantineList) Apply(n QuarantineNotice) (bool, error) {
+	if err := n.Verify(); err != nil {
+		return false, err
+	}
+	if !q.trusted[n.Issuer] {
+		return false, fmt.Errorf("gossip: quarantine issuer %s is not trusted", n.Issuer)
+	}
+	if n.IssuedAt.After(q.now().Add(quarantineClockSkew)) {
+		return false, fmt.Errorf("gossip: quarantine notice for %s issued in the future", n.NodeID)
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	cur, ok := q.notices[n.NodeID]
+	if ok && !n.IssuedAt.After(cur.IssuedAt) {
+		return false, nil
+	}
+	if ok && n.Released && cur.Issuer != n.Issuer {
+		return false, fmt.Errorf("gossip: quarantine of %s can only be released by %s", n.NodeID, cur.Issuer)
+	}
+	q.notices[n.NodeID] = n
+	return true, nil
+}
+
+// Quarantined reports whether nodeID is currently quarantined.
+func (q *QuarantineList) Quarantined(nodeID string) bool {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	n, ok := q.notices[nodeID]
+	return ok && n.Active(q.now())
+}
+
+// Expire drops ended entries and returns how many were removed.
+func (q *QuarantineList) Expire() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	now := q.now()
+	removed := 0
+	for id, n := range q.notices {
+		// Keep releases until the quarantine they lifted would have ended,
+		// so an older in-flight notice cannot win
+		if !now.Before(n.Until) {
+			delete(q.notices, id)
+			removed++
+		}
+	}
+	return removed
+}
+
+// Active returns the notices currently in effect, sorted by node ID.
+func (q *QuarantineList) Active() []QuarantineNotice {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	now := q.now()
+	var out []QuarantineNotice
+	for _, n := range q.notices {
+		if n.Active(now) {
+			out = append(out, n)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].NodeID < out[j].NodeID })
+	return out
+}
+
+// ─── SWIM Integration ───────────────────────────────────────────────────────
+
+// SetQuarantine attaches a quarantine list. Received notices are verified,
+// merged and re-gossiped when new.
+func (s *SWIM) SetQuarantine(list *QuarantineList) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.quarantine = list
+}
+
+// Quarantine returns the attached quarantine list, or nil.
+func (s *SWIM) Quarantine() *QuarantineList {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.quarantine
+}
+
+// expireQuarantine drops ended notices. Called once per probe cycle.
+func (s *SWIM) expireQuarantine() {
+	if q := s.Quarantine(); q != nil {
+		q.Expire()
+	}
+}
+
+// PublishQuarantine records a locally issued, signed notice and
+// disseminates it.
+func (s *SWIM) PublishQuarantine(n QuarantineNotice) error {
+	q := s.Quarantine()
+	if q == nil {
+		return fmt.Errorf("gossip: no quarantine list attached")
+	}
+	fresh, err := q.Apply(n)
+	if err != nil {
+		return err
+	}
+	if fresh {
+		s.mu.Lock()
+		s.queueQuarantine(n)
+		s.mu.Unlock()
+	}
+	return nil
+}
+
+// applyQuarantine merges a received notice and re-queues it for
+// dissemination if it was new. Rejected notices are dropped.
+func (s *SWIM) applyQuarantine(n QuarantineNotice) {
+	q := s.Quarantine()
+	if q == nil {
+		return
+	}
+	// Verified outside s.mu; signatures are the expensive part
+	if fresh, err := q.Apply(n); err == nil && fresh {
+		s.mu.Lock()
+		s.queueQuarantine(n)
+		s.mu.Unlock()
+	}
+}
+
+// queueQuarantine adds a notice to the piggyback queue, replacing any older
+// one about the same node. Must be called with s.mu held.
+func (s *SWIM) queueQuarantine(n QuarantineNotice) {
+	for i, q := range s.quarQueue {
+		if q.NodeID == n.NodeID {
+			s.quarQueue = append(s.quarQueue[:i], s.quarQueue[i+1:]...)
+			break
+		}
+	}
+	s.quarQueue = append(s.quarQueue, n)
+	s.quarLeft[n.NodeID] = s.config.Lambda * s.logN()
+}
+
+// drainQuarantine returns pending notices for piggybacking.
+func (s *SWIM) drainQuarantine() []QuarantineNotice {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.quarQueue) == 0 {
+		return nil
+	}
+
+	result := make([]QuarantineNotice, 0, len(s.quarQueue))
+	remaining := make([]QuarantineNotice, 0)
+	for _, n := range s.quarQueue {
+		result = append(result, n)
+		s.quarLeft[n.NodeID]--
+		if s.quarLeft[n.NodeID] > 0 {
+			remaining = append(remaining, n)
+		} else {
+			delete(s.quarLeft, n.NodeID)
+		}
+	}
+	s.quarQueue = remaining
+	return result
+}
@@ -0,0 +1,102 @@
+package gossip
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/security"
+)
+
+func TestQuarantineList_ApplyAndExpire(t *testing.T) {
+	clock := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	self, peer, stranger := newTestKeypair(t), newTestKeypair(t), newTestKeypair(t)
+	q := NewQuarantineList(self.PublicKeyHex(), []string{peer.PublicKeyHex()}, func() time.Time { return clock })
+	until := clock.Add(time.Hour)
+	signed := func(kp *security.Keypair, n QuarantineNotice) QuarantineNotice {
+		n.Sign(kp)
+		return n
+	}
+
+	tampered := signed(self, QuarantineNotice{NodeID: "n1", IssuedAt: clock, Until: until})
+	tampered.Until = until.Add(24 * time.Hour)
+	tests := []struct {
+		name        string
+		n           QuarantineNotice
+		apply       bool
+		wantErr     bool
+		quarantined bool
+	}{
+		{"unsigned", QuarantineNotice{NodeID: "n1", IssuedAt: clock, Until: until}, false, true, false},
+		{"untrusted issuer", signed(stranger, QuarantineNotice{NodeID: "n1", IssuedAt: clock, Until: until}), false, true, false},
+		{"tampered", tampered, false, true, false},
+		{"quarantine", signed(self, QuarantineNotice{NodeID: "n1", IssuedAt: clock, Until: until}), true, false, true},
+		{"same issue time ignored", signed(self, QuarantineNotice{NodeID: "n1", IssuedAt: clock, Until: until, Released: true}), false, false, true},
+		{"release by another issuer", signed(peer, QuarantineNotice{NodeID: "n1", IssuedAt: clock.Add(time.Minute), Until: until, Released: true}), false, true, true},
+		{"release", signed(self, QuarantineNotice{NodeID: "n1", IssuedAt: clock.Add(time.Minute), Until: until, Released: true}), true, false, false},
+		{"stale quarantine ignored", signed(self, QuarantineNotice{NodeID: "n1", IssuedAt: clock.Add(time.Second), Until: until}), false, false, false},
+		{"issued in the future", signed(self, QuarantineNotice{NodeID: "n1", IssuedAt: clock.Add(time.Hour), Until: until.Add(time.Hour)}), false, true, false},
+		{"empty node", signed(self, QuarantineNotice{IssuedAt: clock, Until: until}), false, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := q.Apply(tt.n)
+			if got != tt.apply || (err != nil) != tt.wantErr {
+				t.Errorf("Apply() = %v, %v; want %v, error %v", got, err, tt.apply, tt.wantErr)
+			}
+			if got := q.Quarantined("n1"); got != tt.quarantined {
+				t.Errorf("Quarantined(n1) = %v, want %v", got, tt.quarantined)
+			}
+		})
+	}
+
+	if _, err := q.Apply(signed(peer, QuarantineNotice{NodeID: "n2", IssuedAt: clock, Until: clock.Add(10 * time.Minute)})); err != nil {
+		t.Fatalf("Apply(n2) error: %v", err)
+	}
+	if active := q.Active(); len(active) != 1 || active[0].NodeID != "n2" {
+		t.Fatalf("Active() = %+v, want [n2]", active)
+	}
+
+	clock = clock.Add(30 * time.Minute)
+	if q.Quarantined("n2") {
+		t.Error("n2 still quarantined after Until")
+	}
+	if n := q.Expire(); n != 1 {
+		t.Errorf("Expire() = %d, want 1 (release kept until its Until)", n)
+	}
+}
+
+func TestSWIM_QuarantineDissemination(t *testing.T) {
+	kp := newTestKeypair(t)
+	s, _ := newTestSWIM(t, "node-1")
+	s.SetQuarantine(NewQuarantineList(kp.PublicKeyHex(), nil, nil))
+
+	n := QuarantineNotice{NodeID: "node-3", IssuedAt: time.Now(), Until: time.Now().Add(time.Hour)}
+	n.Sign(kp)
+	s.applyQuarantine(n)
+	if !s.Quarantine().Quarantined("node-3") {
+		t.Fatal("node-3 not quarantined after notice")
+	}
+	if got := s.drainQuarantine(); len(got) != 1 || got[0].NodeID != "node-3" {
+		t.Fatalf("drainQuarantine = %+v, want node-3 re-gossiped", got)
+	}
+
+	// A duplicate is not re-queued
+	s.applyQuarantine(n)
+	s.mu.Lock()
+	queued := len(s.quarQueue)
+	s.mu.Unlock()
+	if queued != 1 {
+		t.Errorf("queue length = %d after duplicate, want 1", queued)
+	}
+
+	// Notices from untrusted issuers are neither applied nor re-gossiped
+	forged := QuarantineNotice{NodeID: "node-4", IssuedAt: time.Now(), Until: time.Now().Add(time.Hour)}
+	forged.Sign(newTestKeypair(t))
+	s.applyQuarantine(forged)
+	s.mu.Lock()
+	queued = len(s.quarQueue)
+	s.mu.Unlock()
+	if queued != 1 || s.Quarantine().Quarantined("node-4") {
+		t.Errorf("untrusted notice applied or queued (queue %d)", queued)
+	}
+}
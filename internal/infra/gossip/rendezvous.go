@@ -0,0 +1,57 @@
+package gossip
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+
+	"github.com/tutu-network/tutu/internal/domain"
+)
+
+// ─── Rendezvous ─────────────────────────────────────────────────────────────
+// NAT traversal needs a side channel to exchange public addresses before two
+// nodes can talk directly. Gossip already reaches every member, so it
+// carries those signals: an opaque payload sent straight to the member's
+// gossip address, unacknowledged and not relayed. The payload format belongs
+// to the caller (see nat.PunchRequest).
+
+// OnRendezvous sets the callback for received rendezvous payloads. It runs on
+// the receive loop and must not block.
+func (s *SWIM) OnRendezvous(fn func(from string, payload []byte)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onRendezvous = fn
+}
+
+// SendRendezvous sends payload to a live member.
+func (s *SWIM) SendRendezvous(nodeID string, payload []byte) error {
+	if !json.Valid(payload) {
+		return fmt.Errorf("gossip: rendezvous payload is not JSON")
+	}
+	s.mu.RLock()
+	m, ok := s.members[nodeID]
+	alive := ok && m.state != domain.PeerDead
+	var addr *net.UDPAddr
+	if alive {
+		addr = m.addr
+	}
+	s.mu.RUnlock()
+	if !alive {
+		return fmt.Errorf("gossip: %s is not a live member", nodeID)
+	}
+	if !s.peerSupports(nodeID, FeatureRendezvous) {
+		return fmt.Errorf("gossip: %s does not support rendezvous", nodeID)
+	}
+	s.sendMessage(addr, Message{Type: MsgRendezvous, From: s.selfID, Rendezvous: payload})
+	return nil
+}
+
+// handleRendezvous hands a received payload to the callback.
+func (s *SWIM) handleRendezvous(msg Message) {
+	s.mu.RLock()
+	fn := s.onRendezvous
+	s.mu.RUnlock()
+	if fn != nil && len(msg.Rendezvous) > 0 && msg.From != s.selfID {
+		fn(msg.From, msg.Rendezvous)
+	}
+}
@@ -0,0 +1,31 @@
+package gossip
+
+import (
+	"testing"
+
+	"github.com/tutu-network/tutu/internal/domain"
+)
+
+func TestSWIM_Rendezvous(t *testing.T) {
+	s, _ := newTestSWIM(t, "node-1")
+	var gotFrom, gotPayload string
+	s.OnRendezvous(func(from string, payload []byte) {
+		gotFrom, gotPayload = from, string(payload)
+	})
+
+	s.handleMessage(Message{Type: MsgRendezvous, From: "node-2", Rendezvous: []byte(`{"nonce":1}`)}, nil)
+	if gotFrom != "node-2" || gotPayload != `{"nonce":1}` {
+		t.Errorf("callback got (%q, %q)", gotFrom, gotPayload)
+	}
+
+	if err := s.SendRendezvous("node-2", []byte(`{}`)); err == nil {
+		t.Error("SendRendezvous to an unknown node should fail")
+	}
+	s.members["node-3"] = &member{nodeID: "node-3", state: domain.PeerDead}
+	if err := s.SendRendezvous("node-3", []byte(`{}`)); err == nil {
+		t.Error("SendRendezvous to a dead node should fail")
+	}
+	if err := s.SendRendezvous("node-3", []byte("not json")); err == nil {
+		t.Error("SendRendezvous accepted a non-JSON payload")
+	}
+}
@@ -0,0 +1,292 @@
+package gossip
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/security"
+)
+
+// ─── Replay Protection ──────────────────────────────────────────────────────
+// Gossip runs over plain UDP, so a captured packet can be sent again later —
+// a replayed SUSPECT or DEAD update flaps a healthy node, a replayed ACK
+// keeps a dead one alive. Three checks stop that:
+//
+//   - Every message carries its send time. Messages stamped more than
+//     MaxClockSkew away from the local clock are dropped.
+//   - Every message except an ACK carries the sender's own sequence number,
+//     strictly increasing across all its messages. Each receiver keeps a
+//     sliding window per sender and drops numbers it has already seen or
+//     that fall behind the window. The counter starts at the sender's clock
+//     in nanoseconds, so a restarted node continues above its old numbers.
+//   - An ACK echoes the sequence number of the probe it answers and is only
+//     accepted while that probe is in flight, once per responder.
+//
+// State updates are stamped when their originator makes the change. An
+// update older than MaxUpdateAge is ignored. Updates are ordered by
+// incarnation first: a higher incarnation wins whatever its stamp — clocks
+// differ between the node refuting a suspicion and the one that raised it —
+// and within an incarnation an update no newer than one already applied is
+// ignored.
+//
+// Every message is signed by its sender, and the signature covers the send
+// time and sequence number, so a captured packet cannot be restamped. The
+// signature is over the payload bytes as sent and is checked before they
+// are decoded. A node identified by its public key only admits messages
+// that verify against the sender's ID; named nodes (tests, simulations)
+// have no key to check against.
+
+// ReplayStats counts messages and updates dropped by replay protection.
+type ReplayStats struct {
+	Replayed       int64 `json:"replayed"`        // sequence number already seen from the sender
+	OutOfOrder     int64 `json:"out_of_order"`    // sequence number behind the sender's window
+	ClockSkew      int64 `json:"clock_skew"`      // send time missing or beyond MaxClockSkew
+	UnsolicitedAck int64 `json:"unsolicited_ack"` // ACK for a probe not in flight
+	StaleUpdates   int64 `json:"stale_updates"`   // state update too old or superseded
+	BadSignature   int64 `json:"bad_signature"`   // unsigned, badly signed or not from a keyed sender
+}
+
+// replayGuard holds the receive-side sequence windows and the probes in
+// flight. It has its own lock: it is consulted on every packet, before the
+// membership lock is taken.
+type replayGuard struct {
+	mu       sync.Mutex
+	windows  map[string]*seqWindow // sender → window
+	inflight map[uint64]*probe     // our probe seq → probe
+
+	replayed       atomic.Int64
+	outOfOrder     atomic.Int64
+	clockSkew      atomic.Int64
+	unsolicitedAck atomic.Int64
+	staleUpdates   atomic.Int64
+	badSignature   atomic.Int64
+}
+
+// seqWindow is the sliding window of sequence numbers seen from one sender.
+type seqWindow struct {
+	highest  uint64
+	seen     map[uint64]struct{} // numbers in (highest-size, highest]
+	lastSeen time.Time
+}
+
+// probe is a ping this node sent and is waiting to have acked.
+type probe struct {
+	sentAt time.Time
+	acked  map[string]bool // responders already accepted
+
+	// Set when the ping was sent on behalf of a PING-REQ: the ACK is
+	// relayed to the requester under its own sequence number.
+	relayTo  *net.UDPAddr
+	relaySeq uint64
+}
+
+func newReplayGuard() *replayGuard {
+	return &replayGuard{
+		windows:  make(map[string]*seqWindow),
+		inflight: make(map[uint64]*probe),
+	}
+}
+
+// ReplayStats returns how many messages and updates replay protection has
+// dropped.
+func (s *SWIM) ReplayStats() ReplayStats {
+	g := s.replay
+	return ReplayStats{
+		Replayed:       g.replayed.Load(),
+		OutOfOrder:     g.outOfOrder.Load(),
+		ClockSkew:      g.clockSkew.Load(),
+		UnsolicitedAck: g.unsolicitedAck.Load(),
+		StaleUpdates:   g.staleUpdates.Load(),
+		BadSignature:   g.badSignature.Load(),
+	}
+}
+
+// nextSeq allocates this node's next sequence number.
+func (s *SWIM) nextSeq() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.seqNo++
+	return s.seqNo
+}
+
+// open verifies a received packet's signature and decodes its message. A
+// message whose sender differs from the envelope's signer is dropped.
+func (s *SWIM) open(data []byte) (Message, bool) {
+	var env envelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return Message{}, false
+	}
+	if !s.authentic(env) {
+		s.replay.badSignature.Add(1)
+		return Message{}, false
+	}
+	var msg Message
+	if err := json.Unmarshal(env.Payload, &msg); err != nil {
+		return Message{}, false
+	}
+	if msg.From != env.From {
+		s.replay.badSignature.Add(1)
+		return Message{}, false
+	}
+	return msg, true
+}
+
+// admit checks a received message's send time and sequence number. For an
+// ACK it also returns the probe being answered.
+func (s *SWIM) admit(msg Message, now time.Time) (*probe, bool) {
+	g := s.replay
+	sent := time.Unix(0, msg.SentAt)
+	if msg.SentAt == 0 || absDuration(now.Sub(sent)) > s.config.MaxClockSkew {
+		g.clockSkew.Add(1)
+		return nil, false
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if msg.Type == MsgAck {
+		p, ok := g.inflight[msg.SeqNo]
+		if !ok || now.Sub(p.sentAt) > s.ackTTL() {
+			g.unsolicitedAck.Add(1)
+			return nil, false
+		}
+		if p.acked[msg.From] {
+			g.replayed.Add(1)
+			return nil, false
+		}
+		p.acked[msg.From] = true
+		return p, true
+	}
+
+	w, ok := g.windows[msg.From]
+	if !ok {
+		w = &seqWindow{seen: make(map[uint64]struct{})}
+		g.windows[msg.From] = w
+	}
+	size := uint64(s.config.ReplayWindow)
+	switch {
+	case w.highest >= size && msg.SeqNo <= w.highest-size:
+		g.outOfOrder.Add(1)
+		return nil, false
+	case msg.SeqNo <= w.highest:
+		if _, dup := w.seen[msg.SeqNo]; dup {
+			g.replayed.Add(1)
+			return nil, false
+		}
+	default:
+		w.highest = msg.SeqNo
+		if w.highest >= size {
+			for seq := range w.seen {
+				if seq <= w.highest-size {
+					delete(w.seen, seq)
+				}
+			}
+		}
+	}
+	w.seen[msg.SeqNo] = struct{}{}
+	w.lastSeen = now
+	return nil, true
+}
+
+// authentic reports whether env's payload was signed by the key its
+// sender's ID encodes. A sender with a named ID is only admitted by a named
+// node.
+func (s *SWIM) authentic(env envelope) bool {
+	pub := nodeKey(env.From)
+	if pub == nil {
+		return !s.keyed
+	}
+	return security.Verify(env.Payload, env.Signature, pub)
+}
+
+// nodeKey returns the public key a node ID encodes, or nil for a named ID.
+func nodeKey(nodeID string) ed25519.PublicKey {
+	pub, err := hex.DecodeString(nodeID)
+	if err != nil || len(pub) != ed25519.PublicKeySize {
+		return nil
+	}
+	return ed25519.PublicKey(pub)
+}
+
+// trackProbe records a ping in flight so its ACK will be accepted.
+func (s *SWIM) trackProbe(seq uint64, relayTo *net.UDPAddr, relaySeq uint64) {
+	g := s.replay
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.inflight[seq] = &probe{
+		sentAt:   time.Now(),
+		acked:    make(map[string]bool),
+		relayTo:  relayTo,
+		relaySeq: relaySeq,
+	}
+}
+
+// pruneReplay drops expired probes and the windows of senders not heard
+// from for longer than any of their messages could still be accepted.
+func (s *SWIM) pruneReplay(now time.Time) {
+	g := s.replay
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for seq, p := range g.inflight {
+		if now.Sub(p.sentAt) > s.ackTTL() {
+			delete(g.inflight, seq)
+		}
+	}
+	for from, w := range g.windows {
+		if now.Sub(w.lastSeen) > 2*s.config.MaxClockSkew {
+			delete(g.windows, from)
+		}
+	}
+}
+
+// ackTTL is how long a probe accepts ACKs: the direct and indirect
+// timeouts, with room for the relay's own round trip.
+func (s *SWIM) ackTTL() time.Duration {
+	return 4 * s.config.PingTimeout
+}
+
+// freshUpdate reports whether a state update may be applied to m. Caller
+// must hold s.mu.
+func (s *SWIM) freshUpdate(m *member, su StateUpdate, now time.Time) bool {
+	at := time.Unix(0, su.At)
+	age := now.Sub(at)
+	if su.At == 0 || age > s.config.MaxUpdateAge || age < -s.config.MaxClockSkew {
+		s.replay.staleUpdates.Add(1)
+		return false
+	}
+	if su.Incarnation > m.updateInc {
+		return true
+	}
+	if su.Incarnation < m.updateInc || su.At <= m.updateAt {
+		// Retransmissions of an applied update are expected; only count
+		// the ones that would have changed the member's state
+		if su.State != m.state {
+			s.replay.staleUpdates.Add(1)
+		}
+		return false
+	}
+	return true
+}
+
+// relayAck passes an ACK received for a ping sent on behalf of a PING-REQ
+// back to the requester.
+func (s *SWIM) relayAck(p *probe, target string) {
+	s.sendMessage(p.relayTo, Message{
+		Type:   MsgAck,
+		SeqNo:  p.relaySeq,
+		From:   s.selfID,
+		Target: target,
+	})
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
@@ -0,0 +1,221 @@
+package gossip
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
+	"github.com/tutu-network/tutu/internal/security"
+)
+
+func TestAdmit_SequenceWindow(t *testing.T) {
+	s, _ := newTestSWIM(t, "node-1")
+	s.config.ReplayWindow = 8
+	now := time.Now()
+	msg := func(seq uint64) Message {
+		return Message{Type: MsgHeartbeat, From: "node-2", SeqNo: seq, SentAt: now.UnixNano()}
+	}
+
+	steps := []struct {
+		seq  uint64
+		want bool
+	}{
+		{100, true},
+		{101, true},
+		{101, false}, // replayed
+		{99, true},   // late but inside the window, first time
+		{99, false},  // replayed
+		{110, true},
+		{102, false}, // behind the window
+		{103, true},
+	}
+	for i, st := range steps {
+		if _, ok := s.admit(msg(st.seq), now); ok != st.want {
+			t.Errorf("step %d: admit(seq %d) = %v, want %v", i, st.seq, ok, st.want)
+		}
+	}
+	if got := s.ReplayStats(); got.Replayed != 2 || got.OutOfOrder != 1 {
+		t.Errorf("stats = %+v, want 2 replayed, 1 out of order", got)
+	}
+
+	// Sequence numbers are per sender
+	if _, ok := s.admit(Message{Type: MsgPing, From: "node-3", SeqNo: 101, SentAt: now.UnixNano()}, now); !ok {
+		t.Error("another sender's sequence number was rejected")
+	}
+}
+
+func TestAdmit_ClockSkew(t *testing.T) {
+	s, cfg := newTestSWIM(t, "node-1")
+	now := time.Now()
+	for _, sentAt := range []int64{
+		0, // unstamped
+		now.Add(-cfg.MaxClockSkew - time.Second).UnixNano(),
+		now.Add(cfg.MaxClockSkew + time.Second).UnixNano(),
+	} {
+		if _, ok := s.admit(Message{Type: MsgPing, From: "node-2", SeqNo: 1, SentAt: sentAt}, now); ok {
+			t.Errorf("message sent at %d admitted", sentAt)
+		}
+	}
+	if got := s.ReplayStats().ClockSkew; got != 3 {
+		t.Errorf("clock skew rejections = %d, want 3", got)
+	}
+}
+
+func TestAdmit_Acks(t *testing.T) {
+	s, _ := newTestSWIM(t, "node-1")
+	now := time.Now()
+	ack := func(from string, seq uint64) Message {
+		return Message{Type: MsgAck, From: from, SeqNo: seq, SentAt: now.UnixNano()}
+	}
+
+	if _, ok := s.admit(ack("node-2", 42), now); ok {
+		t.Error("ACK for a probe never sent was admitted")
+	}
+	seq := s.nextSeq()
+	s.trackProbe(seq, nil, 0)
+	if _, ok := s.admit(ack("node-2", seq), now); !ok {
+		t.Error("ACK for a probe in flight was rejected")
+	}
+	if _, ok := s.admit(ack("node-2", seq), now); ok {
+		t.Error("replayed ACK was admitted")
+	}
+	// A relay may answer the same probe
+	if _, ok := s.admit(ack("node-3", seq), now); !ok {
+		t.Error("indirect ACK was rejected")
+	}
+	// Expired probes no longer accept ACKs
+	if _, ok := s.admit(ack("node-4", seq), now.Add(s.ackTTL()+time.Second)); ok {
+		t.Error("ACK after the probe expired was admitted")
+	}
+	if got := s.ReplayStats(); got.UnsolicitedAck != 2 || got.Replayed != 1 {
+		t.Errorf("stats = %+v, want 2 unsolicited, 1 replayed", got)
+	}
+}
+
+func TestApplyStateUpdate_RejectsReplays(t *testing.T) {
+	s, cfg := newTestSWIM(t, "node-1")
+	s.members["node-2"] = &member{nodeID: "node-2", state: domain.PeerAlive}
+	now := time.Now()
+
+	// Too old to apply
+	s.applyStateUpdate(StateUpdate{NodeID: "node-2", State: domain.PeerSuspect, At: now.Add(-cfg.MaxUpdateAge - time.Second).UnixNano()})
+	if s.members["node-2"].state != domain.PeerAlive {
+		t.Fatal("stale update was applied")
+	}
+
+	suspect := StateUpdate{NodeID: "node-2", State: domain.PeerSuspect, At: now.UnixNano()}
+	s.applyStateUpdate(suspect)
+	if s.members["node-2"].state != domain.PeerSuspect {
+		t.Fatal("fresh update was not applied")
+	}
+	s.applyStateUpdate(suspect) // retransmission: no change, not counted
+
+	// The node answers a probe; replaying the same SUSPECT must not flap it
+	s.members["node-2"].state = domain.PeerAlive
+	s.applyStateUpdate(suspect)
+	if s.members["node-2"].state != domain.PeerAlive {
+		t.Error("replayed update flapped the node")
+	}
+	if got := s.ReplayStats().StaleUpdates; got != 2 {
+		t.Errorf("stale updates = %d, want 2", got)
+	}
+}
+
+func TestApplyStateUpdate_IncarnationBeforeTimestamp(t *testing.T) {
+	s, _ := newTestSWIM(t, "node-1")
+	s.members["node-2"] = &member{nodeID: "node-2", state: domain.PeerAlive}
+	now := time.Now()
+
+	s.applyStateUpdate(StateUpdate{NodeID: "node-2", State: domain.PeerSuspect, At: now.UnixNano()})
+	if s.members["node-2"].state != domain.PeerSuspect {
+		t.Fatal("suspicion was not applied")
+	}
+
+	// The refutation is stamped by node-2's clock, a few seconds behind
+	refute := StateUpdate{NodeID: "node-2", State: domain.PeerAlive, Incarnation: 1, At: now.Add(-5 * time.Second).UnixNano()}
+	s.applyStateUpdate(refute)
+	if m := s.members["node-2"]; m.state != domain.PeerAlive || m.incarnation != 1 {
+		t.Fatalf("refutation dropped: state %s, incarnation %d", m.state, m.incarnation)
+	}
+
+	// Within an incarnation the stamp still orders updates
+	s.applyStateUpdate(StateUpdate{NodeID: "node-2", State: domain.PeerSuspect, Incarnation: 1, At: now.Add(-6 * time.Second).UnixNano()})
+	if s.members["node-2"].state != domain.PeerAlive {
+		t.Error("older update in the same incarnation was applied")
+	}
+	s.applyStateUpdate(StateUpdate{NodeID: "node-2", State: domain.PeerSuspect, Incarnation: 0, At: now.Add(time.Second).UnixNano()})
+	if s.members["node-2"].state != domain.PeerAlive {
+		t.Error("update from an older incarnation was applied")
+	}
+}
+
+func TestOpen_Signatures(t *testing.T) {
+	selfKP, peerKP := newTestKeypair(t), newTestKeypair(t)
+	s := New(selfKP.PublicKeyHex(), DefaultConfig(), selfKP)
+	peer := New(peerKP.PublicKeyHex(), DefaultConfig(), peerKP)
+	now := time.Now()
+	seal := func(from string, payload []byte, kp *security.Keypair) []byte {
+		data, err := json.Marshal(envelope{From: from, Payload: payload, Signature: kp.Sign(payload)})
+		if err != nil {
+			t.Fatalf("Marshal: %v", err)
+		}
+		return data
+	}
+
+	data, err := peer.encode(Message{Type: MsgPing, From: peer.selfID, SeqNo: 7, SentAt: now.UnixNano(),
+		State: []StateUpdate{{NodeID: "x", State: domain.PeerSuspect, At: now.UnixNano()}}})
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	msg, ok := s.open(data)
+	if !ok || msg.SeqNo != 7 || len(msg.State) != 1 {
+		t.Fatalf("open(signed message from a keyed peer) = %+v, %v", msg, ok)
+	}
+	if _, ok := s.admit(msg, now); !ok {
+		t.Fatal("signed message from a keyed peer was rejected")
+	}
+
+	// A field this node does not know is covered by the signature as sent
+	newer := fmt.Sprintf(`{"type":1,"seq":8,"ts":%d,"from":%q,"future":{"x":1}}`, now.UnixNano(), peer.selfID)
+	if msg, ok := s.open(seal(peer.selfID, []byte(newer), peerKP)); !ok || msg.SeqNo != 8 {
+		t.Errorf("message with a newer peer's extra field = %+v, %v, want admitted", msg, ok)
+	}
+
+	// A captured packet restamped to pass the replay checks
+	var env envelope
+	_ = json.Unmarshal(data, &env)
+	env.Payload = bytes.Replace(env.Payload, []byte(`"seq":7`), []byte(`"seq":9`), 1)
+	restamped, _ := json.Marshal(env)
+	if _, ok := s.open(restamped); ok {
+		t.Error("restamped message was admitted")
+	}
+	// Signed by another key than the one its sender ID encodes
+	forged, _ := New(peer.selfID, DefaultConfig(), newTestKeypair(t)).encode(Message{Type: MsgPing, From: peer.selfID, SeqNo: 9, SentAt: now.UnixNano()})
+	if _, ok := s.open(forged); ok {
+		t.Error("message signed with the wrong key was admitted")
+	}
+	// Signed by the peer, but speaking for another node
+	spoofed := fmt.Sprintf(`{"type":1,"seq":10,"ts":%d,"from":"node-2"}`, now.UnixNano())
+	if _, ok := s.open(seal(peer.selfID, []byte(spoofed), peerKP)); ok {
+		t.Error("message from another sender than its signer was admitted")
+	}
+	// Named senders carry no key to check against
+	unsigned, _ := New("node-2", DefaultConfig(), nil).encode(Message{Type: MsgPing, From: "node-2", SeqNo: 1, SentAt: now.UnixNano()})
+	if _, ok := s.open(unsigned); ok {
+		t.Error("unsigned message from a named sender was admitted by a keyed node")
+	}
+	if got := s.ReplayStats().BadSignature; got != 4 {
+		t.Errorf("bad signatures = %d, want 4", got)
+	}
+}
+
+func TestNew_SeqSeededFromClock(t *testing.T) {
+	before := uint64(time.Now().UnixNano())
+	s, _ := newTestSWIM(t, "node-1")
+	if seq := s.nextSeq(); seq <= before {
+		t.Errorf("first sequence number %d is not above the clock (%d); a restart would reuse numbers", seq, before)
+	}
+}
@@ -0,0 +1,71 @@
+package gossip
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
+)
+
+// ─── Round-Trip Times ───────────────────────────────────────────────────────
+// Every direct probe that is acked is also an RTT measurement. OnRTT hands
+// those to a network profiler for free; Ping measures a chosen member on
+// demand, outside the probe cycle and without changing its state.
+
+// OnRTT sets a callback for round-trip times measured by direct probes. It
+// runs on the probe goroutine and must not block.
+func (s *SWIM) OnRTT(fn func(nodeID string, rtt time.Duration)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onRTT = fn
+}
+
+// Ping sends a direct ping to a live member and returns the round-trip time.
+func (s *SWIM) Ping(ctx context.Context, nodeID string) (time.Duration, error) {
+	s.mu.Lock()
+	m, ok := s.members[nodeID]
+	if !ok || m.state == domain.PeerDead {
+		s.mu.Unlock()
+		return 0, fmt.Errorf("gossip: %s is not a live member", nodeID)
+	}
+	addr := m.addr
+	s.seqNo++
+	seq := s.seqNo
+	s.mu.Unlock()
+	s.trackProbe(seq, nil, 0)
+
+	ackCh := make(chan bool, 1)
+	s.pendingMu.Lock()
+	s.pending[seq] = ackCh
+	s.pendingMu.Unlock()
+	defer func() {
+		s.pendingMu.Lock()
+		delete(s.pending, seq)
+		s.pendingMu.Unlock()
+	}()
+
+	sent := time.Now()
+	s.sendMessage(addr, Message{Type: MsgPing, SeqNo: seq, From: s.selfID})
+
+	timer := time.NewTimer(s.config.PingTimeout)
+	defer timer.Stop()
+	select {
+	case <-ackCh:
+		return time.Since(sent), nil
+	case <-timer.C:
+		return 0, fmt.Errorf("gossip: ping %s timed out", nodeID)
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+}
+
+// reportRTT passes a direct probe's round-trip time to the OnRTT callback.
+func (s *SWIM) reportRTT(nodeID string, rtt time.Duration) {
+	s.mu.RLock()
+	fn := s.onRTT
+	s.mu.RUnlock()
+	if fn != nil {
+		fn(nodeID, rtt)
+	}
+}
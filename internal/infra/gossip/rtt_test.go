@@ -0,0 +1,45 @@
+package gossip
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSWIM_PingAndOnRTT(t *testing.T) {
+	node1, _ := newTestSWIM(t, "node-1")
+	node2, _ := newTestSWIM(t, "node-2")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var measured atomic.Int32
+	node2.OnRTT(func(nodeID string, rtt time.Duration) {
+		if nodeID == "node-1" && rtt > 0 {
+			measured.Add(1)
+		}
+	})
+	go node1.Start(ctx)
+	time.Sleep(100 * time.Millisecond)
+	go node2.Start(ctx)
+	time.Sleep(100 * time.Millisecond)
+	if err := node2.Join([]string{node1.selfAddr.String()}); err != nil {
+		t.Fatalf("Join: %v", err)
+	}
+
+	for node2.AliveCount() == 0 || measured.Load() == 0 {
+		select {
+		case <-ctx.Done():
+			t.Fatalf("no probe RTT measured (alive=%d)", node2.AliveCount())
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+
+	rtt, err := node2.Ping(ctx, "node-1")
+	if err != nil || rtt <= 0 {
+		t.Errorf("Ping(node-1) = %v, %v", rtt, err)
+	}
+	if _, err := node2.Ping(ctx, "node-9"); err == nil {
+		t.Error("Ping of an unknown member should fail")
+	}
+}
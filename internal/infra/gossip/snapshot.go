@@ -0,0 +1,178 @@
+package gossip
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
+)
+
+// ─── Membership Snapshots ───────────────────────────────────────────────────
+// Piggybacking spreads membership O(log N) updates per probe, so a fresh
+// node in a large cluster takes minutes to learn everyone. A seed can
+// instead hand over its whole member list: the joiner dials the seed's
+// snapshot address over TCP, reads one gzip-compressed JSON snapshot,
+// imports it and then starts normal gossip — probing the imported members
+// introduces it to them. Snapshot entries never override what gossip
+// already knows; they only fill in members the node has not heard of.
+
+const (
+	snapshotTimeout  = 10 * time.Second // per connection, both ends
+	maxSnapshotBytes = 64 << 20         // decompressed size limit
+)
+
+// SnapshotMember is one member in a membership snapshot.
+type SnapshotMember struct {
+	NodeID      string           `json:"node_id"`
+	Addr        string           `json:"addr"` // gossip UDP address
+	State       domain.PeerState `json:"state"`
+	Incarnation uint64           `json:"incarnation"`
+}
+
+// Snapshot is a full membership list as seen by one node.
+type Snapshot struct {
+	From    string           `json:"from"`
+	Members []SnapshotMember `json:"members"`
+}
+
+// Snapshot returns the current non-dead membership, excluding unresolved
+// seed entries.
+func (s *SWIM) Snapshot() Snapshot {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	snap := Snapshot{From: s.selfID, Members: make([]SnapshotMember, 0, len(s.members))}
+	for id, m := range s.members {
+		if strings.HasPrefix(id, "seed:") || m.state == domain.PeerDead || m.addr == nil {
+			continue
+		}
+		snap.Members = append(snap.Members, SnapshotMember{
+			NodeID:      m.nodeID,
+			Addr:        m.addr.String(),
+			State:       m.state,
+			Incarnation: m.incarnation,
+		})
+	}
+	return snap
+}
+
+// ImportSnapshot adds members from snap that this node does not know yet
+// and returns how many were added. Dead entries, this node itself and
+// unresolvable addresses are skipped.
+func (s *SWIM) ImportSnapshot(snap Snapshot) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	added := 0
+	for _, sm := range snap.Members {
+		if sm.NodeID == "" || sm.NodeID == s.selfID || sm.State == domain.PeerDead {
+			continue
+		}
+		if _, ok := s.members[sm.NodeID]; ok {
+			continue
+		}
+		addr, err := net.ResolveUDPAddr("udp4", sm.Addr)
+		if err != nil {
+			continue
+		}
+		m := &member{
+			nodeID:      sm.NodeID,
+			addr:        addr,
+			state:       sm.State,
+			incarnation: sm.Incarnation,
+			lastAck:     now,
+		}
+		if m.state == domain.PeerSuspect {
+			m.suspectAt = now
+		}
+		s.members[sm.NodeID] = m
+		added++
+		if s.onJoin != nil {
+			go s.onJoin(sm.NodeID)
+		}
+	}
+	return added
+}
+
+// ServeSnapshots answers every connection on ln with a compressed
+// membership snapshot. Blocks until ctx is cancelled.
+func (s *SWIM) ServeSnapshots(ctx context.Context, ln net.Listener) error {
+	go func() {
+		<-ctx.Done()
+		ln.Close()
+	}()
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return fmt.Errorf("gossip: accept snapshot request: %w", err)
+		}
+		go s.writeSnapshot(conn)
+	}
+}
+
+// writeSnapshot sends one snapshot and closes the connection.
+func (s *SWIM) writeSnapshot(conn net.Conn) {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(snapshotTimeout))
+
+	zw := gzip.NewWriter(conn)
+	if err := json.NewEncoder(zw).Encode(s.Snapshot()); err != nil {
+		return
+	}
+	zw.Close()
+}
+
+// FetchSnapshot reads a membership snapshot from a seed's snapshot address.
+func FetchSnapshot(ctx context.Context, addr string) (Snapshot, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("gossip: dial snapshot seed %s: %w", addr, err)
+	}
+	defer conn.Close()
+	deadline := time.Now().Add(snapshotTimeout)
+	if dl, ok := ctx.Deadline(); ok && dl.Before(deadline) {
+		deadline = dl
+	}
+	conn.SetDeadline(deadline)
+
+	zr, err := gzip.NewReader(conn)
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("gossip: read snapshot from %s: %w", addr, err)
+	}
+	var snap Snapshot
+	if err := json.NewDecoder(io.LimitReader(zr, maxSnapshotBytes)).Decode(&snap); err != nil {
+		return Snapshot{}, fmt.Errorf("gossip: decode snapshot from %s: %w", addr, err)
+	}
+	return snap, nil
+}
+
+// Bootstrap imports membership from the first seed that answers and
+// returns how many members were added. It is meant to run before Start;
+// once gossip is running, piggybacking keeps the list current.
+func (s *SWIM) Bootstrap(ctx context.Context, seeds []string) (int, error) {
+	var errs []error
+	for _, addr := range seeds {
+		snap, err := FetchSnapshot(ctx, addr)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		return s.ImportSnapshot(snap), nil
+	}
+	if len(errs) == 0 {
+		return 0, fmt.Errorf("gossip: no snapshot seeds")
+	}
+	return 0, errors.Join(errs...)
+}
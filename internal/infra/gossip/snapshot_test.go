@@ -0,0 +1,94 @@
+package gossip
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
+)
+
+func TestSnapshot_SkipsSeedsAndDead(t *testing.T) {
+	s, _ := newTestSWIM(t, "node-1")
+	addr := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 7001}
+	s.members["node-2"] = &member{nodeID: "node-2", addr: addr, state: domain.PeerAlive, incarnation: 3}
+	s.members["node-3"] = &member{nodeID: "node-3", addr: addr, state: domain.PeerDead}
+	s.members["seed:127.0.0.1:7001"] = &member{nodeID: "seed:127.0.0.1:7001", addr: addr, state: domain.PeerAlive}
+
+	snap := s.Snapshot()
+	if snap.From != "node-1" || len(snap.Members) != 1 {
+		t.Fatalf("snapshot = %+v, want only node-2", snap)
+	}
+	if m := snap.Members[0]; m.NodeID != "node-2" || m.Addr != "127.0.0.1:7001" || m.Incarnation != 3 {
+		t.Errorf("member = %+v", m)
+	}
+}
+
+func TestImportSnapshot(t *testing.T) {
+	s, _ := newTestSWIM(t, "node-1")
+	known := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 7002}
+	s.members["node-2"] = &member{nodeID: "node-2", addr: known, state: domain.PeerSuspect, incarnation: 5}
+
+	added := s.ImportSnapshot(Snapshot{From: "seed", Members: []SnapshotMember{
+		{NodeID: "node-1", Addr: "127.0.0.1:7001", State: domain.PeerAlive},                 // self
+		{NodeID: "node-2", Addr: "127.0.0.1:9999", State: domain.PeerAlive, Incarnation: 1}, // already known
+		{NodeID: "node-3", Addr: "127.0.0.1:7003", State: domain.PeerAlive, Incarnation: 2},
+		{NodeID: "node-4", Addr: "127.0.0.1:7004", State: domain.PeerDead},
+		{NodeID: "node-5", Addr: "not an address", State: domain.PeerAlive},
+	}})
+	if added != 1 {
+		t.Fatalf("added = %d, want 1", added)
+	}
+	if m := s.members["node-3"]; m == nil || m.incarnation != 2 || m.state != domain.PeerAlive {
+		t.Errorf("node-3 = %+v", m)
+	}
+	if m := s.members["node-2"]; m.addr != known || m.state != domain.PeerSuspect {
+		t.Errorf("snapshot overrode gossip state for node-2: %+v", m)
+	}
+}
+
+func TestBootstrap_OverTCP(t *testing.T) {
+	seed, _ := newTestSWIM(t, "seed")
+	for i, id := range []string{"node-a", "node-b", "node-c"} {
+		seed.members[id] = &member{
+			nodeID: id,
+			addr:   &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 7100 + i},
+			state:  domain.PeerAlive,
+		}
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- seed.ServeSnapshots(ctx, ln) }()
+
+	joiner, _ := newTestSWIM(t, "joiner")
+	bctx, bcancel := context.WithTimeout(ctx, 5*time.Second)
+	defer bcancel()
+	// The first seed is unreachable; bootstrap falls through to the next
+	n, err := joiner.Bootstrap(bctx, []string{"127.0.0.1:1", ln.Addr().String()})
+	if err != nil || n != 3 {
+		t.Fatalf("Bootstrap = %d, %v; want 3 members", n, err)
+	}
+	if joiner.AliveCount() != 3 {
+		t.Errorf("AliveCount = %d, want 3", joiner.AliveCount())
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("ServeSnapshots = %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Error("ServeSnapshots did not stop on cancel")
+	}
+
+	if _, err := joiner.Bootstrap(context.Background(), nil); err == nil {
+		t.Error("expected an error with no seeds")
+	}
+}
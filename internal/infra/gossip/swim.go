@@ -13,12 +13,13 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"math/rand"
 	"net"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/tutu-network/tutu/internal/domain"
+	"github.com/tutu-network/tutu/internal/infra/dsa"
 	"github.com/tutu-network/tutu/internal/security"
 )
 
@@ -30,6 +31,12 @@ type Config struct {
 	SuspectTTL  time.Duration // Time before SUSPECT → DEAD (default: 5s)
 	K           int           // Indirect ping targets (default: 3)
 	Lambda      int           // Piggyback retransmission factor (default: 3)
+	Seed        int64         // RNG seed for probe and fanout targets (0 = time-based)
+
+	// Replay protection (see replay.go)
+	MaxClockSkew time.Duration // Max distance of a message's send time from ours (default: 30s)
+	MaxUpdateAge time.Duration // Oldest state update still applied (default: 2m)
+	ReplayWindow int           // Sequence numbers tracked per sender (default: 1024)
 }
 
 // DefaultConfig returns conservative SWIM defaults.
@@ -41,6 +48,10 @@ func DefaultConfig() Config {
 		SuspectTTL:  5 * time.Second,
 		K:           3,
 		Lambda:      3,
+
+		MaxClockSkew: 30 * time.Second,
+		MaxUpdateAge: 2 * time.Minute,
+		ReplayWindow: 1024,
 	}
 }
 
@@ -48,20 +59,45 @@ func DefaultConfig() Config {
 type MessageType uint8
 
 const (
-	MsgPing    MessageType = 1
-	MsgAck     MessageType = 2
-	MsgPingReq MessageType = 3
-	MsgState   MessageType = 4 // Piggybacked state update
+	MsgPing       MessageType = 1
+	MsgAck        MessageType = 2
+	MsgPingReq    MessageType = 3
+	MsgState      MessageType = 4 // Piggybacked state update
+	MsgHeartbeat  MessageType = 5 // Unacknowledged load heartbeat
+	MsgRendezvous MessageType = 6 // Opaque NAT traversal signal
+	MsgSummary    MessageType = 7 // Cluster summaries between tiers
+	MsgLearning   MessageType = 8 // Opaque scheduler learning summary
 )
 
 // Message is a SWIM protocol message sent over UDP.
 type Message struct {
-	Type      MessageType   `json:"type"`
-	SeqNo     uint64        `json:"seq"`
-	From      string        `json:"from"`
-	Target    string        `json:"target,omitempty"`
-	State     []StateUpdate `json:"state,omitempty"` // Piggybacked
-	Signature []byte        `json:"sig,omitempty"`
+	Type       MessageType        `json:"type"`
+	SeqNo      uint64             `json:"seq"` // Sender's sequence number; ACKs echo the probe's
+	SentAt     int64              `json:"ts"`  // Unix nanoseconds
+	From       string             `json:"from"`
+	Target     string             `json:"target,omitempty"`
+	State      []StateUpdate      `json:"state,omitempty"` // Piggybacked
+	Avail      []Announcement     `json:"avail,omitempty"` // Piggybacked model availability
+	Load       []LoadReport       `json:"load,omitempty"`  // Piggybacked queue depth
+	Quarantine []QuarantineNotice `json:"quar,omitempty"`  // Piggybacked quarantine notices
+	Blocks     []BlockEntry       `json:"block,omitempty"` // Piggybacked blocklist entries
+	Heartbeat  *Heartbeat         `json:"hb,omitempty"`    // MsgHeartbeat payload
+	Rendezvous json.RawMessage    `json:"rdv,omitempty"`   // MsgRendezvous payload
+	Summaries  []ClusterSummary   `json:"clus,omitempty"`  // MsgSummary payload
+	Learning   json.RawMessage    `json:"learn,omitempty"` // MsgLearning payload
+	Version    uint16             `json:"v,omitempty"`     // Highest protocol version the sender speaks (see protocol.go)
+	MinVersion uint16             `json:"vmin,omitempty"`  // Oldest protocol version it accepts
+	Features   []string           `json:"feat,omitempty"`  // Sender's features; PING and ACK only
+}
+
+// envelope is the packet on the wire: the marshalled Message and the
+// sender's signature over exactly those bytes. The receiver verifies the
+// payload as received before decoding it, so fields a newer peer adds are
+// covered by its signature without the receiver having to know them.
+type envelope struct {
+	From      string `json:"from"`
+	Payload   []byte `json:"payload"`
+	Signature []byte `json:"sig,omitempty"`
 }
 
 // StateUpdate is a piggybacked membership state change.
@@ -69,6 +105,7 @@ type StateUpdate struct {
 	NodeID      string           `json:"node_id"`
 	State       domain.PeerState `json:"state"`
 	Incarnation uint64           `json:"incarnation"`
+	At          int64            `json:"at"` // When the originator made the change, Unix nanoseconds
 }
 
 // member tracks internal membership state.
@@ -79,6 +116,8 @@ type member struct {
 	incarnation uint64
 	suspectAt   time.Time // When node was marked SUSPECT
 	lastAck     time.Time
+	updateAt    int64  // Timestamp of the newest state update applied
+	updateInc   uint64 // Incarnation that update carried
 }
 
 // SWIM implements the SWIM membership protocol over UDP.
@@ -91,9 +130,49 @@ type SWIM struct {
 	members   map[string]*member
 	seqNo     uint64
 	keypair   *security.Keypair
+	keyed     bool // selfID is a public key: only keyed, signed peers are admitted
+	rng       *dsa.Rand
 	broadcast []StateUpdate  // Pending piggybacked state changes
 	bcastLeft map[string]int // nodeID → remaining retransmissions
 
+	// Model availability (see availability.go)
+	avail        *AvailabilityIndex
+	announce     []Announcement // Pending piggybacked announcements
+	announceLeft map[string]int // nodeID → remaining retransmissions
+	lastAnnounce time.Time
+
+	// Queue load reports (see load.go)
+	load      *LoadIndex
+	loadQueue []LoadReport   // Pending piggybacked reports
+	loadLeft  map[string]int // nodeID → remaining retransmissions
+	lastLoad  time.Time
+
+	// Quarantine notices (see quarantine.go)
+	quarantine *QuarantineList
+	quarQueue  []QuarantineNotice // Pending piggybacked notices
+	quarLeft   map[string]int     // quarantined nodeID → remaining retransmissions
+
+	// Blocklist entries (see blocklist.go)
+	blocklist         *Blocklist
+	blockQueue        []BlockEntry   // Pending piggybacked entries
+	blockLeft         map[string]int // entry key → remaining retransmissions
+	lastBlockAnnounce time.Time
+
+	// Load heartbeats (see heartbeat.go)
+	heartbeat *HeartbeatIndex
+
+	// NAT traversal signals (see rendezvous.go)
+	onRendezvous func(from string, payload []byte)
+
+	// Round-trip times of direct probes (see rtt.go)
+	onRTT func(nodeID string, rtt time.Duration)
+
+	// Cluster representatives and summaries (see hierarchy.go)
+	hierarchy *Hierarchy
+
+	// Scheduler learning exchange (see learning.go)
+	learning *LearningConfig
+
 	// Callbacks
 	onJoin  func(nodeID string)
 	onLeave func(nodeID string)
@@ -101,17 +180,45 @@ type SWIM struct {
 	// Pending acks
 	pendingMu sync.Mutex
 	pending   map[uint64]chan bool // seqNo → ack channel
+
+	// Sequence windows and probes in flight (see replay.go)
+	replay *replayGuard
+
+	// Protocol versions and peers' features (see protocol.go)
+	proto  *protocolState
+	protos map[string]PeerProtocol
 }
 
 // New creates a new SWIM protocol instance.
 func New(selfID string, cfg Config, kp *security.Keypair) *SWIM {
+	def := DefaultConfig()
+	if cfg.MaxClockSkew <= 0 {
+		cfg.MaxClockSkew = def.MaxClockSkew
+	}
+	if cfg.MaxUpdateAge <= 0 {
+		cfg.MaxUpdateAge = def.MaxUpdateAge
+	}
+	if cfg.ReplayWindow <= 0 {
+		cfg.ReplayWindow = def.ReplayWindow
+	}
 	return &SWIM{
 		config:    cfg,
 		selfID:    selfID,
 		keypair:   kp,
+		keyed:     nodeKey(selfID) != nil,
+		rng:       dsa.NewRand(cfg.Seed),
 		members:   make(map[string]*member),
+		seqNo:     uint64(time.Now().UnixNano()), // above any number a previous run used
 		pending:   make(map[uint64]chan bool),
 		bcastLeft: make(map[string]int),
+		replay:    newReplayGuard(),
+		proto:     newProtocolState(),
+		protos:    make(map[string]PeerProtocol),
+
+		announceLeft: make(map[string]int),
+		loadLeft:     make(map[string]int),
+		quarLeft:     make(map[string]int),
+		blockLeft:    make(map[string]int),
 	}
 }
 
@@ -155,6 +262,14 @@ func (s *SWIM) AliveCount() int {
 	return count
 }
 
+// Addr returns the UDP address the node listens on, or nil before Start
+// has bound it.
+func (s *SWIM) Addr() *net.UDPAddr {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.selfAddr
+}
+
 // Join seeds the membership with known peers.
 func (s *SWIM) Join(addrs []string) error {
 	for _, a := range addrs {
@@ -189,12 +304,23 @@ func (s *SWIM) Start(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("listen udp: %w", err)
 	}
+	s.mu.Lock()
 	s.conn = conn
 	s.selfAddr = conn.LocalAddr().(*net.UDPAddr)
+	s.mu.Unlock()
 
 	// Receiver goroutine
 	go s.receiveLoop(ctx)
 
+	// Heartbeats run on their own, faster cadence
+	go s.heartbeatLoop(ctx)
+
+	// Tier-2 cluster summaries, if a hierarchy is attached
+	go s.hierarchyLoop(ctx)
+
+	// Scheduler learning summaries, if an exchange is attached
+	go s.learningLoop(ctx)
+
 	// Probe cycle
 	ticker := time.NewTicker(s.config.Interval)
 	defer ticker.Stop()
@@ -205,6 +331,11 @@ func (s *SWIM) Start(ctx context.Context) error {
 			s.conn.Close()
 			return nil
 		case <-ticker.C:
+			s.refreshAvailability()
+			s.refreshLoad()
+			s.expireQuarantine()
+			s.maintainBlocklist()
+			s.pruneReplay(time.Now())
 			s.probeCycle()
 			s.reapSuspects()
 		}
@@ -218,10 +349,8 @@ func (s *SWIM) probeCycle() {
 		return
 	}
 
-	s.mu.Lock()
-	s.seqNo++
-	seq := s.seqNo
-	s.mu.Unlock()
+	seq := s.nextSeq()
+	s.trackProbe(seq, nil, 0)
 
 	ackCh := make(chan bool, 1)
 	s.pendingMu.Lock()
@@ -235,11 +364,16 @@ func (s *SWIM) probeCycle() {
 	}()
 
 	// Phase 1: Direct PING
+	sent := time.Now()
 	s.sendMessage(target.addr, Message{
-		Type:  MsgPing,
-		SeqNo: seq,
-		From:  s.selfID,
-		State: s.drainBroadcast(),
+		Type:       MsgPing,
+		SeqNo:      seq,
+		From:       s.selfID,
+		State:      s.drainBroadcast(),
+		Avail:      s.drainAnnouncements(),
+		Load:       s.drainLoad(),
+		Quarantine: s.drainQuarantine(),
+		Blocks:     s.drainBlocks(),
 	})
 
 	timer := time.NewTimer(s.config.PingTimeout)
@@ -248,6 +382,7 @@ func (s *SWIM) probeCycle() {
 	select {
 	case <-ackCh:
 		// Direct ACK received
+		s.reportRTT(target.nodeID, time.Since(sent))
 		return
 	case <-timer.C:
 		// No response — Phase 2: Indirect PING-REQ
@@ -291,6 +426,9 @@ func (s *SWIM) reapSuspects() {
 					NodeID: id,
 					State:  domain.PeerDead,
 				})
+				s.forgetAvailability(id)
+				s.forgetLoad(id)
+				s.forgetHeartbeat(id)
 				if s.onLeave != nil {
 					go s.onLeave(id)
 				}
@@ -318,12 +456,19 @@ func (s *SWIM) receiveLoop(ctx context.Context) {
 			continue
 		}
 
-		var msg Message
-		if err := json.Unmarshal(buf[:n], &msg); err != nil {
+		msg, ok := s.open(buf[:n])
+		if !ok {
+			continue
+		}
+		p, ok := s.admit(msg, time.Now())
+		if !ok || !s.negotiate(msg) {
 			continue
 		}
 
 		s.handleMessage(msg, remoteAddr)
+		if p != nil && p.relayTo != nil {
+			s.relayAck(p, msg.From)
+		}
 	}
 }
 
@@ -333,6 +478,18 @@ func (s *SWIM) handleMessage(msg Message, from *net.UDPAddr) {
 	for _, su := range msg.State {
 		s.applyStateUpdate(su)
 	}
+	for _, a := range msg.Avail {
+		s.applyAnnouncement(a)
+	}
+	for _, r := range msg.Load {
+		s.applyLoad(r)
+	}
+	for _, n := range msg.Quarantine {
+		s.applyQuarantine(n)
+	}
+	for _, e := range msg.Blocks {
+		s.applyBlock(e)
+	}
 
 	switch msg.Type {
 	case MsgPing:
@@ -341,6 +498,16 @@ func (s *SWIM) handleMessage(msg Message, from *net.UDPAddr) {
 		s.handleAck(msg, from)
 	case MsgPingReq:
 		s.handlePingReq(msg, from)
+	case MsgHeartbeat:
+		if msg.Heartbeat != nil {
+			s.applyHeartbeat(msg.From, *msg.Heartbeat)
+		}
+	case MsgRendezvous:
+		s.handleRendezvous(msg)
+	case MsgSummary:
+		s.applySummaries(msg, from)
+	case MsgLearning:
+		s.handleLearning(msg)
 	}
 }
 
@@ -372,10 +539,14 @@ func (s *SWIM) handlePing(msg Message, from *net.UDPAddr) {
 
 	// Reply with ACK
 	s.sendMessage(from, Message{
-		Type:  MsgAck,
-		SeqNo: msg.SeqNo,
-		From:  s.selfID,
-		State: s.drainBroadcast(),
+		Type:       MsgAck,
+		SeqNo:      msg.SeqNo,
+		From:       s.selfID,
+		State:      s.drainBroadcast(),
+		Avail:      s.drainAnnouncements(),
+		Load:       s.drainLoad(),
+		Quarantine: s.drainQuarantine(),
+		Blocks:     s.drainBlocks(),
 	})
 }
 
@@ -428,10 +599,13 @@ func (s *SWIM) handlePingReq(msg Message, from *net.UDPAddr) {
 		return
 	}
 
-	// Forward a PING to the target with the original sequence number
+	// Ping the target under our own sequence number; its ACK is relayed
+	// back to the requester under the requester's
+	seq := s.nextSeq()
+	s.trackProbe(seq, from, msg.SeqNo)
 	s.sendMessage(target.addr, Message{
 		Type:  MsgPing,
-		SeqNo: msg.SeqNo,
+		SeqNo: seq,
 		From:  s.selfID,
 	})
 }
@@ -469,6 +643,11 @@ func (s *SWIM) applyStateUpdate(su StateUpdate) {
 	if su.Incarnation < m.incarnation {
 		return
 	}
+	// ...and the update is recent and newer than the last one applied
+	if !s.freshUpdate(m, su, time.Now()) {
+		return
+	}
+	m.updateAt, m.updateInc = su.At, su.Incarnation
 
 	switch su.State {
 	case domain.PeerSuspect:
@@ -480,6 +659,9 @@ func (s *SWIM) applyStateUpdate(su StateUpdate) {
 	case domain.PeerDead:
 		m.state = domain.PeerDead
 		m.incarnation = su.Incarnation
+		s.forgetAvailability(su.NodeID)
+		s.forgetLoad(su.NodeID)
+		s.forgetHeartbeat(su.NodeID)
 		if s.onLeave != nil {
 			go s.onLeave(su.NodeID)
 		}
@@ -495,6 +677,9 @@ func (s *SWIM) applyStateUpdate(su StateUpdate) {
 // queueBroadcast adds a state update to the piggyback queue.
 // Must be called with s.mu held.
 func (s *SWIM) queueBroadcast(su StateUpdate) {
+	if su.At == 0 {
+		su.At = time.Now().UnixNano()
+	}
 	s.broadcast = append(s.broadcast, su)
 	s.bcastLeft[su.NodeID] = s.config.Lambda * s.logN()
 }
@@ -538,10 +723,8 @@ func (s *SWIM) logN() int {
 // ─── Helpers ────────────────────────────────────────────────────────────────
 
 func (s *SWIM) sendPing(addr *net.UDPAddr, target string) {
-	s.mu.Lock()
-	s.seqNo++
-	seq := s.seqNo
-	s.mu.Unlock()
+	seq := s.nextSeq()
+	s.trackProbe(seq, nil, 0)
 
 	s.sendMessage(addr, Message{
 		Type:  MsgPing,
@@ -550,19 +733,37 @@ func (s *SWIM) sendPing(addr *net.UDPAddr, target string) {
 	})
 }
 
+// sendMessage stamps msg with the send time, the protocol versions — plus
+// the features on a PING or ACK — and, unless it is an ACK or already
+// numbered, a fresh sequence number, then signs and sends it.
 func (s *SWIM) sendMessage(addr *net.UDPAddr, msg Message) {
-	data, err := json.Marshal(msg)
+	if msg.SeqNo == 0 && msg.Type != MsgAck {
+		msg.SeqNo = s.nextSeq()
+	}
+	msg.SentAt = time.Now().UnixNano()
+	msg.Version, msg.MinVersion = s.proto.version, s.proto.minVersion
+	if msg.Type == MsgPing || msg.Type == MsgAck {
+		msg.Features = s.localFeatures()
+	}
+
+	data, err := s.encode(msg)
 	if err != nil {
 		return
 	}
+	s.conn.WriteToUDP(data, addr)
+}
 
-	// Sign the message if we have a keypair
+// encode marshals msg into an envelope, signed if we have a keypair.
+func (s *SWIM) encode(msg Message) ([]byte, error) {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	env := envelope{From: msg.From, Payload: payload}
 	if s.keypair != nil {
-		msg.Signature = s.keypair.Sign(data)
+		env.Signature = s.keypair.Sign(payload)
 	}
-
-	data, _ = json.Marshal(msg) // Re-marshal with signature
-	s.conn.WriteToUDP(data, addr)
+	return json.Marshal(env)
 }
 
 func (s *SWIM) randomMember() *member {
@@ -578,21 +779,30 @@ func (s *SWIM) randomMember() *member {
 	if len(alive) == 0 {
 		return nil
 	}
-	return alive[rand.Intn(len(alive))]
+	sortMembers(alive)
+	return alive[s.rng.Intn(len(alive))]
 }
 
 func (s *SWIM) randomMembers(k int, exclude string) []*member {
+	return s.randomMembersWith(k, exclude, "")
+}
+
+// randomMembersWith picks up to k random live members other than exclude
+// that support feature ("" = any).
+func (s *SWIM) randomMembersWith(k int, exclude, feature string) []*member {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
 	candidates := make([]*member, 0)
 	for _, m := range s.members {
-		if m.nodeID != exclude && m.state != domain.PeerDead {
+		if m.nodeID != exclude && m.state != domain.PeerDead &&
+			(feature == "" || s.supportsLocked(m.nodeID, feature)) {
 			candidates = append(candidates, m)
 		}
 	}
 
-	rand.Shuffle(len(candidates), func(i, j int) {
+	sortMembers(candidates)
+	s.rng.Shuffle(len(candidates), func(i, j int) {
 		candidates[i], candidates[j] = candidates[j], candidates[i]
 	})
 
@@ -601,3 +811,9 @@ func (s *SWIM) randomMembers(k int, exclude string) []*member {
 	}
 	return candidates[:k]
 }
+
+// sortMembers orders members by node ID, so a seeded choice among them does
+// not depend on map iteration order.
+func sortMembers(ms []*member) {
+	sort.Slice(ms, func(i, j int) bool { return ms[i].nodeID < ms[j].nodeID })
+}
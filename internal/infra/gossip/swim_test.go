@@ -3,6 +3,7 @@ package gossip
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"sync"
 	"testing"
 	"time"
@@ -121,6 +122,30 @@ func TestRandomMember_SkipsDead(t *testing.T) {
 	}
 }
 
+func TestRandomMembers_Seeded(t *testing.T) {
+	picks := func() []string {
+		cfg := DefaultConfig()
+		cfg.Seed = 42
+		s := New("node-1", cfg, nil)
+		for i := 0; i < 20; i++ {
+			id := fmt.Sprintf("node-%02d", i+2)
+			s.members[id] = &member{nodeID: id, state: domain.PeerAlive}
+		}
+		var out []string
+		for i := 0; i < 5; i++ {
+			out = append(out, s.randomMember().nodeID)
+			for _, m := range s.randomMembers(3, "") {
+				out = append(out, m.nodeID)
+			}
+		}
+		return out
+	}
+	a, b := picks(), picks()
+	if !reflect.DeepEqual(a, b) {
+		t.Errorf("same seed, different targets:\n%v\n%v", a, b)
+	}
+}
+
 func TestBroadcastQueue(t *testing.T) {
 	s, _ := newTestSWIM(t, "node-1")
 	s.members["a"] = &member{nodeID: "a", state: domain.PeerAlive}
@@ -233,6 +258,53 @@ func TestTwoNodes_Discovery(t *testing.T) {
 	wg.Wait()
 }
 
+func TestTwoNodes_KeyedDiscovery(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	// Nodes identified by their public keys admit only signed messages
+	cfg := DefaultConfig()
+	cfg.BindAddr = "127.0.0.1:0"
+	cfg.PingTimeout = 200 * time.Millisecond
+	cfg.Interval = 100 * time.Millisecond
+	nodes := make([]*SWIM, 2)
+	for i := range nodes {
+		kp := newTestKeypair(t)
+		nodes[i] = New(kp.PublicKeyHex(), cfg, kp)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var wg sync.WaitGroup
+	for _, n := range nodes {
+		wg.Add(1)
+		go func(n *SWIM) {
+			defer wg.Done()
+			n.Start(ctx)
+		}(n)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if err := nodes[1].Join([]string{nodes[0].selfAddr.String()}); err != nil {
+		t.Fatalf("Join() error: %v", err)
+	}
+
+	deadline := time.Now().Add(3 * time.Second)
+	for time.Now().Before(deadline) && (nodes[0].AliveCount() < 1 || nodes[1].AliveCount() < 1) {
+		time.Sleep(50 * time.Millisecond)
+	}
+	cancel()
+	wg.Wait()
+	for i, n := range nodes {
+		if n.AliveCount() < 1 {
+			t.Errorf("node %d sees %d alive peers, want 1", i, n.AliveCount())
+		}
+		if bad := n.ReplayStats().BadSignature; bad != 0 {
+			t.Errorf("node %d rejected %d signed messages", i, bad)
+		}
+	}
+}
+
 func TestThreeNodes_FullMesh(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping integration test in short mode")
@@ -0,0 +1,168 @@
+package governance
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
+	"github.com/tutu-network/tutu/internal/security"
+)
+
+// ─── Batch Votes ────────────────────────────────────────────────────────────
+// An operator running a fleet of nodes would otherwise cast the same vote
+// once per node, and quorum suffers when they don't bother. A BatchVote is
+// one payload — proposal, choice, issue time — signed by each node's own
+// Ed25519 key, so it can be prepared offline and submitted once:
+//
+//   - every signature is checked against the public key the node ID
+//     encodes; a node with a bad signature is rejected, the rest still vote
+//   - each node's weight is its own balance, as for CastVote
+//   - a payload issued before a node's current vote was cast is stale and
+//     does not overwrite it, so replaying an old batch cannot undo a change
+//   - a payload issued more than BatchClockSkew in the future is refused
+
+// MaxBatchVoters bounds the signatures in one batch.
+const MaxBatchVoters = 1000
+
+// BatchClockSkew is how far in the future a batch may be issued.
+const BatchClockSkew = 5 * time.Minute
+
+// NodeSignature is one node's signature over a batch payload.
+type NodeSignature struct {
+	NodeID    string `json:"node_id"`   // hex Ed25519 public key
+	Signature []byte `json:"signature"` // over BatchVote.SigningPayload
+}
+
+// BatchVote casts one choice on one proposal for several nodes.
+type BatchVote struct {
+	ProposalID string          `json:"proposal_id"`
+	Choice     VoteChoice      `json:"choice"`
+	IssuedAt   time.Time       `json:"issued_at"`
+	Signatures []NodeSignature `json:"signatures"`
+}
+
+// BatchVoteResult reports which nodes of a batch voted.
+type BatchVoteResult struct {
+	Cast     []string          `json:"cast"`               // node IDs whose vote was recorded
+	Rejected map[string]string `json:"rejected,omitempty"` // node ID → reason
+}
+
+// SigningPayload returns the bytes each node signs. It covers everything
+// but the signatures, so one payload serves the whole batch.
+func (b BatchVote) SigningPayload() []byte {
+	return []byte("tutu-governance-vote-v1\n" + b.ProposalID + "\n" +
+		strconv.Itoa(int(b.Choice)) + "\n" + strconv.FormatInt(b.IssuedAt.UnixNano(), 10))
+}
+
+// Sign adds kp's signature to the batch, for the node kp identifies.
+func (b *BatchVote) Sign(kp *security.Keypair) {
+	b.Signatures = append(b.Signatures, NodeSignature{
+		NodeID:    kp.PublicKeyHex(),
+		Signature: kp.Sign(b.SigningPayload()),
+	})
+}
+
+// CastBatch records b's vote for every node whose signature verifies.
+// weight returns a node's current credit balance. It fails as a whole only
+// when the batch itself is unusable (unknown or closed proposal, bad
+// choice, no or too many signatures, a node listed twice, issued in the
+// future); per-node problems are reported in the result.
+func (e *Engine) CastBatch(b BatchVote, weight func(nodeID string) int64) (BatchVoteResult, error) {
+	if err := e.checkBatch(b); err != nil {
+		return BatchVoteResult{}, err
+	}
+	payload := b.SigningPayload()
+	res := BatchVoteResult{Cast: []string{}}
+	reject := func(nodeID string, err error) {
+		if res.Rejected == nil {
+			res.Rejected = make(map[string]string)
+		}
+		res.Rejected[nodeID] = err.Error()
+	}
+
+	// Verify outside the lock; signatures are the expensive part.
+	verified := make([]string, 0, len(b.Signatures))
+	for _, sig := range b.Signatures {
+		if err := verifyNodeSignature(payload, sig); err != nil {
+			reject(sig.NodeID, err)
+			continue
+		}
+		verified = append(verified, sig.NodeID)
+	}
+
+	var first []Vote
+	e.mu.Lock()
+	for _, nodeID := range verified {
+		if existing, ok := e.votes[b.ProposalID][nodeID]; ok && b.IssuedAt.Before(existing.CastAt) {
+			reject(nodeID, fmt.Errorf("%w: superseded by the vote cast at %s", domain.ErrInvalidVote, existing.CastAt.Format(time.RFC3339)))
+			continue
+		}
+		cast, err := e.castVoteLocked(b.ProposalID, nodeID, b.Choice, weight(nodeID))
+		if err != nil {
+			reject(nodeID, err)
+			continue
+		}
+		res.Cast = append(res.Cast, nodeID)
+		if cast != nil {
+			first = append(first, *cast)
+		}
+	}
+	hook := e.voteHook
+	e.mu.Unlock()
+
+	if hook != nil {
+		for _, v := range first {
+			hook(v)
+		}
+	}
+	return res, nil
+}
+
+// checkBatch validates the batch as a whole.
+func (e *Engine) checkBatch(b BatchVote) error {
+	if b.Choice < VoteFor || b.Choice > VoteAbstain {
+		return fmt.Errorf("%w: unknown choice %d", domain.ErrInvalidVote, b.Choice)
+	}
+	if len(b.Signatures) == 0 {
+		return fmt.Errorf("%w: batch has no signatures", domain.ErrInvalidVote)
+	}
+	if len(b.Signatures) > MaxBatchVoters {
+		return fmt.Errorf("%w: %d signatures, at most %d", domain.ErrInvalidVote, len(b.Signatures), MaxBatchVoters)
+	}
+	seen := make(map[string]bool, len(b.Signatures))
+	for _, sig := range b.Signatures {
+		if seen[sig.NodeID] {
+			return fmt.Errorf("%w: node %s signed twice", domain.ErrInvalidVote, sig.NodeID)
+		}
+		seen[sig.NodeID] = true
+	}
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	prop, ok := e.proposals[b.ProposalID]
+	if !ok {
+		return fmt.Errorf("%w: %s", domain.ErrProposalNotFound, b.ProposalID)
+	}
+	if prop.Status != PropActive {
+		return fmt.Errorf("%w: %s is %s, expected ACTIVE", domain.ErrProposalState, b.ProposalID, prop.Status)
+	}
+	if b.IssuedAt.IsZero() || b.IssuedAt.After(e.now().Add(BatchClockSkew)) {
+		return fmt.Errorf("%w: issued at %s", domain.ErrInvalidVote, b.IssuedAt.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// verifyNodeSignature checks sig against the public key its node ID encodes.
+func verifyNodeSignature(payload []byte, sig NodeSignature) error {
+	pub, err := hex.DecodeString(sig.NodeID)
+	if err != nil || len(pub) != ed25519.PublicKeySize {
+		return fmt.Errorf("%w: node ID is not a public key", domain.ErrVoteSignatureInvalid)
+	}
+	if !security.Verify(payload, sig.Signature, ed25519.PublicKey(pub)) {
+		return domain.ErrVoteSignatureInvalid
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package governance
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
+	"github.com/tutu-network/tutu/internal/security"
+)
+
+func newFleet(t *testing.T, n int) []*security.Keypair {
+	t.Helper()
+	fleet := make([]*security.Keypair, n)
+	for i := range fleet {
+		kp, err := security.GenerateKeypair()
+		if err != nil {
+			t.Fatalf("GenerateKeypair: %v", err)
+		}
+		fleet[i] = kp
+	}
+	return fleet
+}
+
+func TestCastBatch(t *testing.T) {
+	e := newTestEngine(t)
+	prop := createAndOpenProposal(t, e, "Batch")
+	var hooked []Vote
+	e.SetVoteHook(func(v Vote) { hooked = append(hooked, v) })
+	fleet := newFleet(t, 3)
+
+	b := BatchVote{ProposalID: prop.ID, Choice: VoteFor, IssuedAt: time.Now()}
+	for _, kp := range fleet {
+		b.Sign(kp)
+	}
+	// A tampered signature only loses that node's vote
+	b.Signatures[2].Signature = append([]byte(nil), b.Signatures[2].Signature...)
+	b.Signatures[2].Signature[0] ^= 0xff
+
+	res, err := e.CastBatch(b, func(string) int64 { return 1000 })
+	if err != nil {
+		t.Fatalf("CastBatch: %v", err)
+	}
+	if len(res.Cast) != 2 || len(res.Rejected) != 1 || res.Rejected[fleet[2].PublicKeyHex()] == "" {
+		t.Errorf("result = %+v, want 2 cast and the tampered node rejected", res)
+	}
+	tally, _ := e.Tally(prop.ID)
+	if tally.ForWeight != 2000 || tally.VoterCount != 2 {
+		t.Errorf("tally = %+v, want 2 voters, 2000 for", tally)
+	}
+	if len(hooked) != 2 {
+		t.Errorf("vote hook called %d times, want 2", len(hooked))
+	}
+
+	// A node that changed its vote is not overwritten by replaying the batch
+	time.Sleep(time.Millisecond)
+	e.CastVote(prop.ID, fleet[0].PublicKeyHex(), VoteAgainst, 1000)
+	b.Signatures = b.Signatures[:1]
+	res, _ = e.CastBatch(b, func(string) int64 { return 1000 })
+	if len(res.Cast) != 0 {
+		t.Errorf("stale batch cast %v", res.Cast)
+	}
+	if tally, _ := e.Tally(prop.ID); tally.AgainstWeight != 1000 {
+		t.Errorf("against = %d after replay, want 1000", tally.AgainstWeight)
+	}
+}
+
+func TestCastBatch_Invalid(t *testing.T) {
+	e := newTestEngine(t)
+	prop := createAndOpenProposal(t, e, "Batch")
+	kp := newFleet(t, 1)[0]
+
+	signed := func(mod func(*BatchVote)) BatchVote {
+		b := BatchVote{ProposalID: prop.ID, Choice: VoteAgainst, IssuedAt: time.Now()}
+		mod(&b)
+		b.Sign(kp)
+		return b
+	}
+	tests := []struct {
+		name string
+		b    BatchVote
+		want error
+	}{
+		{"unknown proposal", signed(func(b *BatchVote) { b.ProposalID = "prop-0" }), domain.ErrProposalNotFound},
+		{"bad choice", signed(func(b *BatchVote) { b.Choice = 7 }), domain.ErrInvalidVote},
+		{"future", signed(func(b *BatchVote) { b.IssuedAt = time.Now().Add(time.Hour) }), domain.ErrInvalidVote},
+		{"unsigned", BatchVote{ProposalID: prop.ID, IssuedAt: time.Now()}, domain.ErrInvalidVote},
+		{"signed twice", func() BatchVote {
+			b := signed(func(*BatchVote) {})
+			b.Sign(kp)
+			return b
+		}(), domain.ErrInvalidVote},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := e.CastBatch(tt.b, func(string) int64 { return 1 }); !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	// A node ID that is not a key is rejected per node
+	b := signed(func(*BatchVote) {})
+	b.Signatures = append(b.Signatures, NodeSignature{NodeID: "node-1", Signature: []byte("sig")})
+	res, err := e.CastBatch(b, func(string) int64 { return 1 })
+	if err != nil || len(res.Cast) != 1 || res.Rejected["node-1"] == "" {
+		t.Errorf("CastBatch = %+v, %v; want node-1 rejected", res, err)
+	}
+}
@@ -9,12 +9,15 @@
 package governance
 
 import (
-	"errors"
+	"encoding/base64"
 	"fmt"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
 )
 
 // ─── Constants ──────────────────────────────────────────────────────────────
@@ -192,6 +195,29 @@ type Engine struct {
 
 	// now is a function that returns the current time — injectable for testing.
 	now func() time.Time
+
+	// auditHook records privileged actions (nil = disabled). Called with the
+	// engine lock held, so it must not call back into the engine.
+	auditHook func(action, target, details string)
+
+	// voteHook observes new votes (nil = disabled). Called without the
+	// engine lock.
+	voteHook func(Vote)
+}
+
+// SetAuditHook installs a callback invoked for every executed proposal.
+func (e *Engine) SetAuditHook(fn func(action, target, details string)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.auditHook = fn
+}
+
+// SetVoteHook installs a callback invoked for every first vote a node casts
+// on a proposal; changing an existing vote does not call it.
+func (e *Engine) SetVoteHook(fn func(Vote)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.voteHook = fn
 }
 
 // NewEngine creates a governance engine.
@@ -222,13 +248,13 @@ func (e *Engine) CreateProposal(title, description string, category ProposalCate
 
 	title = strings.TrimSpace(title)
 	if title == "" {
-		return nil, errors.New("proposal title is required")
+		return nil, fmt.Errorf("%w: title is required", domain.ErrInvalidProposal)
 	}
 	if author == "" {
-		return nil, errors.New("proposal author is required")
+		return nil, fmt.Errorf("%w: author is required", domain.ErrInvalidProposal)
 	}
 	if authorCredits < e.config.MinCredits {
-		return nil, fmt.Errorf("need at least %d credits to propose (have %d)", e.config.MinCredits, authorCredits)
+		return nil, fmt.Errorf("%w: need %d, have %d", domain.ErrInsufficientCreditsToPropose, e.config.MinCredits, authorCredits)
 	}
 
 	// Check active proposal limit
@@ -239,7 +265,7 @@ func (e *Engine) CreateProposal(title, description string, category ProposalCate
 		}
 	}
 	if activeCount >= MaxActiveProposals {
-		return nil, errors.New("maximum active proposals reached")
+		return nil, domain.ErrTooManyActiveProposals
 	}
 
 	now := e.now()
@@ -269,10 +295,10 @@ func (e *Engine) OpenProposal(propID string) error {
 
 	prop, ok := e.proposals[propID]
 	if !ok {
-		return fmt.Errorf("proposal %s not found", propID)
+		return fmt.Errorf("%w: %s", domain.ErrProposalNotFound, propID)
 	}
 	if prop.Status != PropDraft {
-		return fmt.Errorf("proposal %s is %s, expected DRAFT", propID, prop.Status)
+		return fmt.Errorf("%w: %s is %s, expected DRAFT", domain.ErrProposalState, propID, prop.Status)
 	}
 
 	now := e.now()
@@ -289,13 +315,13 @@ func (e *Engine) CancelProposal(propID, nodeID string) error {
 
 	prop, ok := e.proposals[propID]
 	if !ok {
-		return fmt.Errorf("proposal %s not found", propID)
+		return fmt.Errorf("%w: %s", domain.ErrProposalNotFound, propID)
 	}
 	if prop.Author != nodeID {
-		return errors.New("only the proposal author can cancel")
+		return fmt.Errorf("%w: cancel %s", domain.ErrNotProposalAuthor, propID)
 	}
 	if prop.Status != PropDraft && prop.Status != PropActive {
-		return fmt.Errorf("cannot cancel proposal in %s state", prop.Status)
+		return fmt.Errorf("%w: cannot cancel %s in %s state", domain.ErrProposalState, propID, prop.Status)
 	}
 
 	prop.Status = PropCancelled
@@ -310,7 +336,7 @@ func (e *Engine) GetProposal(propID string) (*Proposal, error) {
 
 	prop, ok := e.proposals[propID]
 	if !ok {
-		return nil, fmt.Errorf("proposal %s not found", propID)
+		return nil, fmt.Errorf("%w: %s", domain.ErrProposalNotFound, propID)
 	}
 	return prop, nil
 }
@@ -335,29 +361,205 @@ func (e *Engine) ListProposals(status *ProposalStatus) []*Proposal {
 	return result
 }
 
+// ─── Proposal Queries ───────────────────────────────────────────────────────
+// QueryProposals pages through proposals with keyset cursors: a cursor holds
+// the sort key and ID of the last proposal returned, so a page is stable
+// while proposals are created or change status between requests — nothing
+// is skipped or repeated, and new proposals appear only on pages not yet
+// fetched.
+
+// ProposalSort selects the sort key for QueryProposals.
+type ProposalSort int
+
+const (
+	SortByCreated ProposalSort = iota // CreatedAt (default)
+	SortByExpiry                      // ExpiresAt; drafts have none and sort as oldest
+)
+
+// String returns the sort key name.
+func (s ProposalSort) String() string {
+	switch s {
+	case SortByCreated:
+		return "created"
+	case SortByExpiry:
+		return "expiry"
+	default:
+		return "unknown"
+	}
+}
+
+// DefaultQueryLimit and MaxQueryLimit bound ProposalQuery.Limit.
+const (
+	DefaultQueryLimit = 50
+	MaxQueryLimit     = 500
+)
+
+// ProposalQuery filters, sorts and pages proposals. Zero fields match all.
+type ProposalQuery struct {
+	Status    *ProposalStatus
+	Category  *ProposalCategory
+	Author    string       // exact NodeID
+	Text      string       // case-insensitive substring of title or description
+	Sort      ProposalSort // sort key
+	Ascending bool         // oldest first (default newest first)
+	Limit     int          // page size (default DefaultQueryLimit, capped at MaxQueryLimit)
+	Cursor    string       // NextCursor from the previous page
+}
+
+// ProposalPage is one page of QueryProposals results.
+type ProposalPage struct {
+	Proposals  []*Proposal `json:"proposals"`
+	NextCursor string      `json:"next_cursor,omitempty"` // empty on the last page
+}
+
+// QueryProposals returns one page of proposals matching q.
+func (e *Engine) QueryProposals(q ProposalQuery) (ProposalPage, error) {
+	if q.Sort != SortByCreated && q.Sort != SortByExpiry {
+		return ProposalPage{}, fmt.Errorf("%w: unknown sort %d", domain.ErrInvalidProposalQuery, q.Sort)
+	}
+	if q.Limit <= 0 {
+		q.Limit = DefaultQueryLimit
+	}
+	if q.Limit > MaxQueryLimit {
+		q.Limit = MaxQueryLimit
+	}
+	var after *proposalCursor
+	if q.Cursor != "" {
+		c, err := decodeProposalCursor(q.Cursor, q.Sort)
+		if err != nil {
+			return ProposalPage{}, err
+		}
+		after = &c
+	}
+	text := strings.ToLower(strings.TrimSpace(q.Text))
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	matched := make([]*Proposal, 0)
+	for _, p := range e.proposals {
+		if q.Status != nil && p.Status != *q.Status {
+			continue
+		}
+		if q.Category != nil && p.Category != *q.Category {
+			continue
+		}
+		if q.Author != "" && p.Author != q.Author {
+			continue
+		}
+		if text != "" && !strings.Contains(strings.ToLower(p.Title), text) &&
+			!strings.Contains(strings.ToLower(p.Description), text) {
+			continue
+		}
+		if after != nil && !after.before(cursorOf(p, q.Sort), q.Ascending) {
+			continue
+		}
+		matched = append(matched, p)
+	}
+
+	sort.Slice(matched, func(i, j int) bool {
+		return cursorOf(matched[i], q.Sort).before(cursorOf(matched[j], q.Sort), q.Ascending)
+	})
+
+	page := ProposalPage{Proposals: matched}
+	if len(matched) > q.Limit {
+		page.Proposals = matched[:q.Limit]
+		page.NextCursor = cursorOf(page.Proposals[q.Limit-1], q.Sort).encode()
+	}
+	return page, nil
+}
+
+// proposalCursor is a position in a sorted proposal listing.
+type proposalCursor struct {
+	sort ProposalSort
+	key  int64  // sort key, unix nanoseconds
+	id   string // tie-breaker
+}
+
+// cursorOf returns p's position under the given sort.
+func cursorOf(p *Proposal, by ProposalSort) proposalCursor {
+	t := p.CreatedAt
+	if by == SortByExpiry {
+		t = p.ExpiresAt
+	}
+	var key int64
+	if !t.IsZero() {
+		key = t.UnixNano()
+	}
+	return proposalCursor{sort: by, key: key, id: p.ID}
+}
+
+// before reports whether c comes before o in the listing order.
+func (c proposalCursor) before(o proposalCursor, ascending bool) bool {
+	if c.key != o.key {
+		return (c.key < o.key) == ascending
+	}
+	if c.id == o.id {
+		return false
+	}
+	return (c.id < o.id) == ascending
+}
+
+// encode renders the cursor as an opaque URL-safe token.
+func (c proposalCursor) encode() string {
+	raw := fmt.Sprintf("%d:%d:%s", c.sort, c.key, c.id)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeProposalCursor parses a cursor, rejecting one minted for another sort.
+func decodeProposalCursor(s string, by ProposalSort) (proposalCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return proposalCursor{}, fmt.Errorf("%w: malformed cursor", domain.ErrInvalidProposalQuery)
+	}
+	parts := strings.SplitN(string(raw), ":", 3)
+	if len(parts) != 3 {
+		return proposalCursor{}, fmt.Errorf("%w: malformed cursor", domain.ErrInvalidProposalQuery)
+	}
+	sortKey, err1 := strconv.Atoi(parts[0])
+	key, err2 := strconv.ParseInt(parts[1], 10, 64)
+	if err1 != nil || err2 != nil {
+		return proposalCursor{}, fmt.Errorf("%w: malformed cursor", domain.ErrInvalidProposalQuery)
+	}
+	if ProposalSort(sortKey) != by {
+		return proposalCursor{}, fmt.Errorf("%w: cursor is for sort %q, not %q", domain.ErrInvalidProposalQuery, ProposalSort(sortKey), by)
+	}
+	return proposalCursor{sort: by, key: key, id: parts[2]}, nil
+}
+
 // ─── Voting ─────────────────────────────────────────────────────────────────
 
 // CastVote records a node's vote on an active proposal.
 // weight is the voter's current credit balance.
 func (e *Engine) CastVote(propID, nodeID string, choice VoteChoice, weight int64) error {
 	e.mu.Lock()
-	defer e.mu.Unlock()
+	cast, err := e.castVoteLocked(propID, nodeID, choice, weight)
+	hook := e.voteHook
+	e.mu.Unlock()
+	if cast != nil && hook != nil {
+		hook(*cast)
+	}
+	return err
+}
 
+// castVoteLocked records a vote and returns a copy of it when it is the
+// node's first on the proposal. Must be called with e.mu held.
+func (e *Engine) castVoteLocked(propID, nodeID string, choice VoteChoice, weight int64) (*Vote, error) {
 	prop, ok := e.proposals[propID]
 	if !ok {
-		return fmt.Errorf("proposal %s not found", propID)
+		return nil, fmt.Errorf("%w: %s", domain.ErrProposalNotFound, propID)
 	}
 	if prop.Status != PropActive {
-		return fmt.Errorf("proposal %s is not active (status: %s)", propID, prop.Status)
+		return nil, fmt.Errorf("%w: %s is %s, expected ACTIVE", domain.ErrProposalState, propID, prop.Status)
 	}
 
 	now := e.now()
 	if now.After(prop.ExpiresAt) {
-		return errors.New("voting period has ended")
+		return nil, fmt.Errorf("%w: %s", domain.ErrVotingClosed, propID)
 	}
 
 	if weight <= 0 {
-		return errors.New("vote weight must be positive")
+		return nil, fmt.Errorf("%w: vote weight must be positive", domain.ErrInvalidVote)
 	}
 
 	// Check for duplicate vote — update if changed
@@ -367,17 +569,19 @@ func (e *Engine) CastVote(propID, nodeID string, choice VoteChoice, weight int64
 		existing.Choice = choice
 		existing.Weight = weight
 		existing.CastAt = now
-		return nil
+		return nil, nil
 	}
 
-	voters[nodeID] = &Vote{
+	vote := &Vote{
 		ProposalID: propID,
 		NodeID:     nodeID,
 		Choice:     choice,
 		Weight:     weight,
 		CastAt:     now,
 	}
-	return nil
+	voters[nodeID] = vote
+	cast := *vote
+	return &cast, nil
 }
 
 // Tally computes the current vote counts for a proposal.
@@ -387,7 +591,7 @@ func (e *Engine) Tally(propID string) (*VoteTally, error) {
 
 	_, ok := e.proposals[propID]
 	if !ok {
-		return nil, fmt.Errorf("proposal %s not found", propID)
+		return nil, fmt.Errorf("%w: %s", domain.ErrProposalNotFound, propID)
 	}
 
 	return e.tallyLocked(propID), nil
@@ -473,13 +677,17 @@ func (e *Engine) MarkExecuted(propID string) error {
 
 	prop, ok := e.proposals[propID]
 	if !ok {
-		return fmt.Errorf("proposal %s not found", propID)
+		return fmt.Errorf("%w: %s", domain.ErrProposalNotFound, propID)
 	}
 	if prop.Status != PropPassed {
-		return fmt.Errorf("proposal %s is %s, expected PASSED", propID, prop.Status)
+		return fmt.Errorf("%w: %s is %s, expected PASSED", domain.ErrProposalState, propID, prop.Status)
 	}
 
 	prop.Status = PropExecuted
+	if e.auditHook != nil {
+		e.auditHook("proposal.execute", propID,
+			fmt.Sprintf("category=%s %s=%s", prop.Category, prop.ParamKey, prop.ParamValue))
+	}
 	return nil
 }
 
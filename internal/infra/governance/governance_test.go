@@ -1,8 +1,11 @@
 package governance
 
 import (
+	"errors"
 	"testing"
 	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
 )
 
 // ─── Helpers ────────────────────────────────────────────────────────────────
@@ -99,6 +102,26 @@ func TestCreateProposal_EmptyAuthor(t *testing.T) {
 	}
 }
 
+func TestEngine_ErrorCodes(t *testing.T) {
+	e := newTestEngine(t)
+	if _, err := e.CreateProposal("Test", "desc", CatNetworkParam, "node-poor", 50, "", ""); domain.CodeOf(err) != domain.CodeNotEligible {
+		t.Errorf("poor author = %v", err)
+	}
+	if _, err := e.CreateProposal("", "desc", CatNetworkParam, "node-1", 500, "", ""); !errors.Is(err, domain.ErrInvalidProposal) {
+		t.Errorf("empty title = %v", err)
+	}
+	if err := e.OpenProposal("prop-missing"); !errors.Is(err, domain.ErrProposalNotFound) {
+		t.Errorf("open missing = %v", err)
+	}
+	prop, _ := e.CreateProposal("Test", "desc", CatNetworkParam, "node-1", 500, "", "")
+	if err := e.CastVote(prop.ID, "node-2", VoteFor, 100); !errors.Is(err, domain.ErrProposalState) {
+		t.Errorf("vote on draft = %v", err)
+	}
+	if err := e.CancelProposal(prop.ID, "node-2"); domain.CodeOf(err) != domain.CodeNotEligible {
+		t.Errorf("cancel by non-author = %v", err)
+	}
+}
+
 func TestOpenProposal(t *testing.T) {
 	e := newTestEngine(t)
 	prop, _ := e.CreateProposal("Test", "desc", CatNetworkParam, "node-1", 500, "", "")
@@ -222,6 +245,21 @@ func TestCastVote_ChangeVote(t *testing.T) {
 	}
 }
 
+func TestSetVoteHook_FirstVoteOnly(t *testing.T) {
+	e := newTestEngine(t)
+	prop := createAndOpenProposal(t, e, "Hooked")
+	var votes []Vote
+	e.SetVoteHook(func(v Vote) { votes = append(votes, v) })
+
+	e.CastVote(prop.ID, "node-1", VoteFor, 500)
+	e.CastVote(prop.ID, "node-1", VoteAgainst, 500) // change, not a new vote
+	e.CastVote(prop.ID, "node-2", VoteFor, 0)       // rejected
+
+	if len(votes) != 1 || votes[0].NodeID != "node-1" || votes[0].Choice != VoteFor {
+		t.Errorf("hook calls = %+v, want node-1's first vote", votes)
+	}
+}
+
 func TestCastVote_NotActive(t *testing.T) {
 	e := newTestEngine(t)
 	prop, _ := e.CreateProposal("Draft", "desc", CatNetworkParam, "node-1", 500, "", "")
@@ -370,6 +408,30 @@ func TestMarkExecuted(t *testing.T) {
 	}
 }
 
+func TestMarkExecuted_AuditHook(t *testing.T) {
+	e := newTestEngine(t)
+	e.SetTotalCredits(10000)
+	e.now = fixedTime(2025, 1, 1)
+
+	var actions, targets []string
+	e.SetAuditHook(func(action, target, details string) {
+		actions = append(actions, action)
+		targets = append(targets, target)
+	})
+
+	prop := createAndOpenProposal(t, e, "Audited")
+	e.CastVote(prop.ID, "node-1", VoteFor, 5000)
+	e.now = fixedTime(2025, 1, 10)
+	e.ResolveExpired()
+
+	if err := e.MarkExecuted(prop.ID); err != nil {
+		t.Fatalf("MarkExecuted failed: %v", err)
+	}
+	if len(actions) != 1 || actions[0] != "proposal.execute" || targets[0] != prop.ID {
+		t.Errorf("audit calls = %v %v, want one proposal.execute for %s", actions, targets, prop.ID)
+	}
+}
+
 func TestMarkExecuted_NotPassed(t *testing.T) {
 	e := newTestEngine(t)
 	prop, _ := e.CreateProposal("Test", "desc", CatNetworkParam, "node-1", 500, "", "")
@@ -427,6 +489,101 @@ func TestProposalCount(t *testing.T) {
 	}
 }
 
+func TestQueryProposals_Filters(t *testing.T) {
+	e := newTestEngine(t)
+	e.now = tickingClock()
+	e.CreateProposal("Raise earning rate", "more credits per task", CatEarningRate, "node-a", 500, "", "")
+	e.CreateProposal("Ban model", "remove unsafe checkpoint", CatSecurity, "node-b", 500, "", "")
+	e.CreateProposal("Lower earning rate", "", CatEarningRate, "node-b", 500, "", "")
+
+	tests := []struct {
+		name string
+		q    ProposalQuery
+		want int
+	}{
+		{"all", ProposalQuery{}, 3},
+		{"category", ProposalQuery{Category: func() *ProposalCategory { c := CatEarningRate; return &c }()}, 2},
+		{"author", ProposalQuery{Author: "node-b"}, 2},
+		{"title text", ProposalQuery{Text: "EARNING"}, 2},
+		{"description text", ProposalQuery{Text: "unsafe"}, 1},
+		{"combined", ProposalQuery{Author: "node-b", Text: "earning"}, 1},
+	}
+	for _, tt := range tests {
+		page, err := e.QueryProposals(tt.q)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if len(page.Proposals) != tt.want {
+			t.Errorf("%s: got %d proposals, want %d", tt.name, len(page.Proposals), tt.want)
+		}
+	}
+}
+
+func TestQueryProposals_StableCursor(t *testing.T) {
+	e := newTestEngine(t)
+	e.now = tickingClock()
+	for i := 0; i < 5; i++ {
+		createAndOpenProposal(t, e, "prop")
+	}
+
+	first, err := e.QueryProposals(ProposalQuery{Limit: 2})
+	if err != nil || len(first.Proposals) != 2 || first.NextCursor == "" {
+		t.Fatalf("first page = %+v, %v", first, err)
+	}
+
+	// A proposal created between pages must not shift the listing
+	createAndOpenProposal(t, e, "late")
+
+	var ids []string
+	for _, p := range first.Proposals {
+		ids = append(ids, p.ID)
+	}
+	cursor := first.NextCursor
+	for cursor != "" {
+		page, err := e.QueryProposals(ProposalQuery{Limit: 2, Cursor: cursor})
+		if err != nil {
+			t.Fatalf("QueryProposals: %v", err)
+		}
+		for _, p := range page.Proposals {
+			ids = append(ids, p.ID)
+		}
+		cursor = page.NextCursor
+	}
+	if len(ids) != 5 {
+		t.Fatalf("paged %d proposals, want the original 5: %v", len(ids), ids)
+	}
+	for i := 1; i < len(ids); i++ {
+		if ids[i] >= ids[i-1] {
+			t.Errorf("not newest first: %v", ids)
+		}
+	}
+
+	// Ascending by expiry starts with the oldest
+	asc, _ := e.QueryProposals(ProposalQuery{Sort: SortByExpiry, Ascending: true, Limit: 1})
+	if asc.Proposals[0].ID != ids[len(ids)-1] {
+		t.Errorf("oldest expiry = %s, want %s", asc.Proposals[0].ID, ids[len(ids)-1])
+	}
+}
+
+func TestQueryProposals_BadCursor(t *testing.T) {
+	e := newTestEngine(t)
+	e.now = tickingClock()
+	for i := 0; i < 3; i++ {
+		createAndOpenProposal(t, e, "prop")
+	}
+	page, _ := e.QueryProposals(ProposalQuery{Limit: 1})
+
+	for _, q := range []ProposalQuery{
+		{Cursor: "not base64!"},
+		{Cursor: page.NextCursor, Sort: SortByExpiry}, // minted for created
+		{Sort: ProposalSort(9)},
+	} {
+		if _, err := e.QueryProposals(q); !errors.Is(err, domain.ErrInvalidProposalQuery) {
+			t.Errorf("%+v: err = %v, want ErrInvalidProposalQuery", q, err)
+		}
+	}
+}
+
 // ─── String Methods ─────────────────────────────────────────────────────────
 
 func TestProposalStatusString(t *testing.T) {
@@ -0,0 +1,167 @@
+package healing
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ═══════════════════════════════════════════════════════════════════════════
+// Breaker Registry
+// ═══════════════════════════════════════════════════════════════════════════
+// The registry hands out named breakers sharing one config and keeps their
+// state durable:
+//
+//  1. Restore loads every persisted breaker at start, so a peer that was
+//     tripped before a restart stays blocked until its reset timeout
+//  2. Every state change is written back to the store
+//  3. Transport and Guard put peer HTTP calls ("peer:<host>") and engine
+//     invocations ("engine:<model>") behind a breaker each
+
+// BreakerStore persists circuit breaker state. *sqlite.DB satisfies it.
+type BreakerStore interface {
+	UpsertCircuitBreaker(name string, state, failures, totalTrips int, trippedAt *time.Time) error
+	GetCircuitBreaker(name string) (state, failures, totalTrips int, trippedAt *time.Time, err error)
+	ListCircuitBreakers() ([]string, error)
+}
+
+// Registry owns the node's circuit breakers.
+type Registry struct {
+	mu       sync.Mutex
+	config   CircuitBreakerConfig
+	store    BreakerStore // nil = in-memory only
+	breakers map[string]*CircuitBreaker
+}
+
+// NewRegistry creates a breaker registry. store may be nil.
+func NewRegistry(cfg CircuitBreakerConfig, store BreakerStore) *Registry {
+	return &Registry{
+		config:   cfg,
+		store:    store,
+		breakers: make(map[string]*CircuitBreaker),
+	}
+}
+
+// Restore loads every persisted breaker.
+func (r *Registry) Restore() error {
+	if r.store == nil {
+		return nil
+	}
+	names, err := r.store.ListCircuitBreakers()
+	if err != nil {
+		return fmt.Errorf("list circuit breakers: %w", err)
+	}
+	for _, name := range names {
+		r.Breaker(name)
+	}
+	return nil
+}
+
+// Breaker returns the named breaker, creating it — with any persisted
+// state — on first use.
+func (r *Registry) Breaker(name string) *CircuitBreaker {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if cb, ok := r.breakers[name]; ok {
+		return cb
+	}
+
+	cb := NewCircuitBreaker(name, r.config)
+	if r.store != nil {
+		state, failures, trips, trippedAt, err := r.store.GetCircuitBreaker(name)
+		if err != nil {
+			log.Printf("[healing] restore breaker %s: %v", name, err)
+		} else {
+			snap := Snapshot{State: CBState(state), Failures: failures, TotalTrips: trips}
+			if trippedAt != nil {
+				snap.TrippedAt = *trippedAt
+			}
+			cb.Restore(snap)
+		}
+		cb.OnStateChange(r.persist)
+	}
+	r.breakers[name] = cb
+	return cb
+}
+
+// persist writes a breaker's state to the store.
+func (r *Registry) persist(snap Snapshot) {
+	var trippedAt *time.Time
+	if !snap.TrippedAt.IsZero() {
+		trippedAt = &snap.TrippedAt
+	}
+	if err := r.store.UpsertCircuitBreaker(snap.Name, int(snap.State), snap.Failures, snap.TotalTrips, trippedAt); err != nil {
+		log.Printf("[healing] persist breaker %s: %v", snap.Name, err)
+	}
+}
+
+// Reset closes the named breaker. Returns false if it does not exist.
+func (r *Registry) Reset(name string) bool {
+	r.mu.Lock()
+	cb, ok := r.breakers[name]
+	r.mu.Unlock()
+	if ok {
+		cb.Reset()
+	}
+	return ok
+}
+
+// Snapshots returns every breaker's state, sorted by name.
+func (r *Registry) Snapshots() []Snapshot {
+	r.mu.Lock()
+	breakers := make([]*CircuitBreaker, 0, len(r.breakers))
+	for _, cb := range r.breakers {
+		breakers = append(breakers, cb)
+	}
+	r.mu.Unlock()
+
+	out := make([]Snapshot, 0, len(breakers))
+	for _, cb := range breakers {
+		out = append(out, cb.Snapshot())
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// ─── Wrappers ───────────────────────────────────────────────────────────────
+
+// Guard returns a call wrapper that runs each call through the breaker
+// named prefix+key, e.g. Guard("engine:") for engine.Pool.SetGuard.
+func (r *Registry) Guard(prefix string) func(key string, call func() error) error {
+	return func(key string, call func() error) error {
+		return r.Breaker(prefix + key).Execute(call)
+	}
+}
+
+// Transport wraps base (nil = http.DefaultTransport) so that each request
+// goes through the breaker of its host, "peer:<host>". Transport errors and
+// 5xx responses count as failures.
+func (r *Registry) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &breakerTransport{registry: r, base: base}
+}
+
+type breakerTransport struct {
+	registry *Registry
+	base     http.RoundTripper
+}
+
+func (t *breakerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	cb := t.registry.Breaker("peer:" + req.URL.Host)
+	if err := cb.Allow(); err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	outcome := err
+	if err == nil && resp.StatusCode >= http.StatusInternalServerError {
+		outcome = fmt.Errorf("%s: %s", req.URL.Host, resp.Status)
+	}
+	cb.Record(outcome, time.Since(start))
+	return resp, err
+}
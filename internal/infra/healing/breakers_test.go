@@ -0,0 +1,114 @@
+package healing
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// memStore is an in-memory BreakerStore.
+type memStore struct {
+	rows map[string]Snapshot
+}
+
+func (m *memStore) UpsertCircuitBreaker(name string, state, failures, totalTrips int, trippedAt *time.Time) error {
+	s := Snapshot{Name: name, State: CBState(state), Failures: failures, TotalTrips: totalTrips}
+	if trippedAt != nil {
+		s.TrippedAt = *trippedAt
+	}
+	m.rows[name] = s
+	return nil
+}
+
+func (m *memStore) GetCircuitBreaker(name string) (int, int, int, *time.Time, error) {
+	s, ok := m.rows[name]
+	if !ok {
+		return 0, 0, 0, nil, nil
+	}
+	return int(s.State), s.Failures, s.TotalTrips, &s.TrippedAt, nil
+}
+
+func (m *memStore) ListCircuitBreakers() ([]string, error) {
+	var names []string
+	for name := range m.rows {
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+func testRegistryConfig() CircuitBreakerConfig {
+	return CircuitBreakerConfig{FailureThreshold: 2, ResetTimeout: time.Minute, HalfOpenMax: 1}
+}
+
+func TestRegistry_PersistAndRestore(t *testing.T) {
+	store := &memStore{rows: map[string]Snapshot{}}
+	reg := NewRegistry(testRegistryConfig(), store)
+	cb := reg.Breaker("peer:a")
+	cb.RecordFailure()
+	cb.RecordFailure()
+	if got := store.rows["peer:a"]; got.State != CBOpen || got.TotalTrips != 1 || got.TrippedAt.IsZero() {
+		t.Fatalf("persisted = %+v, want OPEN with 1 trip", got)
+	}
+
+	// A restarted node restores the tripped breaker
+	restarted := NewRegistry(testRegistryConfig(), store)
+	if err := restarted.Restore(); err != nil {
+		t.Fatal(err)
+	}
+	snaps := restarted.Snapshots()
+	if len(snaps) != 1 || snaps[0].State != CBOpen || snaps[0].TotalTrips != 1 {
+		t.Fatalf("restored = %+v", snaps)
+	}
+	if err := restarted.Breaker("peer:a").Allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Allow() after restore = %v, want open", err)
+	}
+
+	if !restarted.Reset("peer:a") || store.rows["peer:a"].State != CBClosed {
+		t.Errorf("Reset not persisted: %+v", store.rows["peer:a"])
+	}
+	if restarted.Reset("missing") {
+		t.Error("Reset(missing) = true")
+	}
+}
+
+func TestRegistry_Transport(t *testing.T) {
+	status := http.StatusInternalServerError
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+	host, _ := url.Parse(srv.URL)
+
+	reg := NewRegistry(testRegistryConfig(), nil)
+	client := &http.Client{Transport: reg.Transport(nil)}
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(srv.URL)
+		if err != nil {
+			t.Fatalf("request %d: %v", i, err)
+		}
+		resp.Body.Close()
+	}
+	if _, err := client.Get(srv.URL); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("third request err = %v, want circuit open", err)
+	}
+	if snaps := reg.Snapshots(); len(snaps) != 1 || snaps[0].Name != "peer:"+host.Host {
+		t.Errorf("snapshots = %+v", snaps)
+	}
+}
+
+func TestRegistry_Guard(t *testing.T) {
+	reg := NewRegistry(testRegistryConfig(), nil)
+	guard := reg.Guard("engine:")
+	fail := errors.New("load failed")
+	guard("llama", func() error { return fail })
+	guard("llama", func() error { return fail })
+	if err := guard("llama", func() error { return nil }); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("guard err = %v, want circuit open", err)
+	}
+	if err := guard("qwen", func() error { return nil }); err != nil {
+		t.Errorf("other model err = %v, want nil", err)
+	}
+}
@@ -2,7 +2,7 @@
 // Architecture Part XVI: Circuit breakers, node quarantine, and automatic rollback.
 //
 // Circuit Breaker states:
-//   - CLOSED  (normal) → errors exceed threshold → OPEN
+//   - CLOSED  (normal) → errors, failure rate or slow-call rate exceed threshold → OPEN
 //   - OPEN    (blocking) → after timeout → HALF_OPEN
 //   - HALF_OPEN (probing) → probe succeeds → CLOSED, probe fails → OPEN
 //
@@ -10,12 +10,17 @@
 //   - 3 failures → 1 hour quarantine
 //   - Verification fail → 24 hour quarantine
 //   - 3 quarantines in 7 days → 30 day ban
+//   - Expired quarantine → probation check → release on probation, where a
+//     single failure re-quarantines
 package healing
 
 import (
 	"fmt"
 	"sync"
 	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
+	"github.com/tutu-network/tutu/internal/infra/observability"
 )
 
 // ═══════════════════════════════════════════════════════════════════════════
@@ -45,11 +50,19 @@ func (s CBState) String() string {
 	}
 }
 
-// CircuitBreakerConfig configures a circuit breaker.
+// CircuitBreakerConfig configures a circuit breaker. A breaker trips on
+// FailureThreshold net failures, or — once the sliding window holds MinCalls
+// outcomes — when the failure rate or slow-call rate reaches its threshold.
+// Zero rates disable the rate checks.
 type CircuitBreakerConfig struct {
 	FailureThreshold int           // number of failures to trip (default 5)
 	ResetTimeout     time.Duration // time in OPEN before trying HALF_OPEN (default 30s)
 	HalfOpenMax      int           // max requests allowed in HALF_OPEN (default 3)
+	Window           int           // outcomes in the sliding window (default 20)
+	MinCalls         int           // outcomes needed before rates apply (default 10)
+	FailureRate      float64       // failed fraction of the window to trip (default 0.5)
+	SlowCall         time.Duration // calls at least this long are slow (default 10s)
+	SlowCallRate     float64       // slow fraction of the window to trip (default 0.8)
 }
 
 // DefaultCircuitBreakerConfig returns production defaults.
@@ -58,9 +71,20 @@ func DefaultCircuitBreakerConfig() CircuitBreakerConfig {
 		FailureThreshold: 5,
 		ResetTimeout:     30 * time.Second,
 		HalfOpenMax:      3,
+		Window:           20,
+		MinCalls:         10,
+		FailureRate:      0.5,
+		SlowCall:         10 * time.Second,
+		SlowCallRate:     0.8,
 	}
 }
 
+// outcome is one call in the sliding window.
+type outcome struct {
+	failed bool
+	slow   bool
+}
+
 // CircuitBreaker implements the circuit breaker pattern.
 // Thread-safe for concurrent use.
 type CircuitBreaker struct {
@@ -70,14 +94,23 @@ type CircuitBreaker struct {
 	state       CBState
 	failures    int
 	successes   int // successes in HALF_OPEN state
+	window      []outcome
 	lastFailure time.Time
 	trippedAt   time.Time
 	totalTrips  int
+	onChange    func(Snapshot)   // called outside the lock after a state change
 	now         func() time.Time // injectable clock for testing
 }
 
 // NewCircuitBreaker creates a circuit breaker with the given name and config.
 func NewCircuitBreaker(name string, cfg CircuitBreakerConfig) *CircuitBreaker {
+	if cfg.Window <= 0 {
+		cfg.Window = DefaultCircuitBreakerConfig().Window
+	}
+	if cfg.MinCalls <= 0 || cfg.MinCalls > cfg.Window {
+		cfg.MinCalls = min(DefaultCircuitBreakerConfig().MinCalls, cfg.Window)
+	}
+	observability.CircuitBreakerState.WithLabelValues(name).Set(float64(CBClosed))
 	return &CircuitBreaker{
 		name:   name,
 		config: cfg,
@@ -86,126 +119,236 @@ func NewCircuitBreaker(name string, cfg CircuitBreakerConfig) *CircuitBreaker {
 	}
 }
 
+// Name returns the breaker's name.
+func (cb *CircuitBreaker) Name() string { return cb.name }
+
+// OnStateChange registers a callback invoked after every state change.
+func (cb *CircuitBreaker) OnStateChange(fn func(Snapshot)) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.onChange = fn
+}
+
 // Allow checks whether a request should be permitted.
 // Returns an error if the circuit is open.
 func (cb *CircuitBreaker) Allow() error {
 	cb.mu.Lock()
-	defer cb.mu.Unlock()
+	changed := cb.halfOpenIfDueLocked()
+	open := cb.state == CBOpen
+	cb.mu.Unlock()
+	cb.notify(changed)
 
-	switch cb.state {
-	case CBClosed:
-		return nil
-	case CBOpen:
-		// Check if it's time to transition to half-open
-		if cb.now().Sub(cb.trippedAt) >= cb.config.ResetTimeout {
-			cb.state = CBHalfOpen
-			cb.successes = 0
-			return nil
-		}
+	if open {
 		return fmt.Errorf("%s: %w", cb.name, ErrCircuitOpen)
-	case CBHalfOpen:
-		return nil
 	}
 	return nil
 }
 
+// Execute runs fn if the breaker allows it and records the outcome and
+// duration. Returns ErrCircuitOpen without calling fn when open.
+func (cb *CircuitBreaker) Execute(fn func() error) error {
+	if err := cb.Allow(); err != nil {
+		return err
+	}
+	start := cb.now()
+	err := fn()
+	cb.Record(err, cb.now().Sub(start))
+	return err
+}
+
+// Record records a call's outcome: failed when err != nil, slow when d
+// reaches the SlowCall threshold.
+func (cb *CircuitBreaker) Record(err error, d time.Duration) {
+	slow := cb.config.SlowCall > 0 && d >= cb.config.SlowCall
+	cb.record(err != nil, slow)
+}
+
 // RecordSuccess records a successful request.
-func (cb *CircuitBreaker) RecordSuccess() {
+func (cb *CircuitBreaker) RecordSuccess() { cb.record(false, false) }
+
+// RecordFailure records a failed request. May trip the breaker.
+func (cb *CircuitBreaker) RecordFailure() { cb.record(true, false) }
+
+func (cb *CircuitBreaker) record(failed, slow bool) {
 	cb.mu.Lock()
-	defer cb.mu.Unlock()
+	changed := false
+	if failed {
+		cb.lastFailure = cb.now()
+	}
 
 	switch cb.state {
 	case CBHalfOpen:
+		if failed || slow {
+			// Any failed or slow probe in half-open → back to open
+			changed = cb.tripLocked()
+			break
+		}
 		cb.successes++
 		if cb.successes >= cb.config.HalfOpenMax {
 			// Enough successful probes → close the circuit
-			cb.state = CBClosed
-			cb.failures = 0
-			cb.successes = 0
+			changed = cb.setStateLocked(CBClosed)
 		}
 	case CBClosed:
-		// Decay failures on success (simple reset)
-		if cb.failures > 0 {
+		cb.window = append(cb.window, outcome{failed: failed, slow: slow})
+		if len(cb.window) > cb.config.Window {
+			cb.window = cb.window[1:]
+		}
+		if failed {
+			cb.failures++
+		} else if cb.failures > 0 {
+			// Decay failures on success (simple reset)
 			cb.failures--
 		}
-	}
-}
-
-// RecordFailure records a failed request. May trip the breaker.
-func (cb *CircuitBreaker) RecordFailure() {
-	cb.mu.Lock()
-	defer cb.mu.Unlock()
-
-	cb.lastFailure = cb.now()
-
-	switch cb.state {
-	case CBClosed:
-		cb.failures++
-		if cb.failures >= cb.config.FailureThreshold {
-			cb.state = CBOpen
-			cb.trippedAt = cb.now()
-			cb.totalTrips++
+		failRate, slowRate := cb.ratesLocked()
+		if cb.failures >= cb.config.FailureThreshold ||
+			len(cb.window) >= cb.config.MinCalls &&
+				(cb.config.FailureRate > 0 && failRate >= cb.config.FailureRate ||
+					cb.config.SlowCallRate > 0 && slowRate >= cb.config.SlowCallRate) {
+			changed = cb.tripLocked()
 		}
-	case CBHalfOpen:
-		// Any failure in half-open → back to open
-		cb.state = CBOpen
-		cb.trippedAt = cb.now()
-		cb.totalTrips++
 	}
+	cb.mu.Unlock()
+	cb.notify(changed)
 }
 
 // State returns the current circuit breaker state.
 func (cb *CircuitBreaker) State() CBState {
 	cb.mu.Lock()
-	defer cb.mu.Unlock()
 	// Auto-transition OPEN → HALF_OPEN if timeout has elapsed
-	if cb.state == CBOpen && cb.now().Sub(cb.trippedAt) >= cb.config.ResetTimeout {
-		cb.state = CBHalfOpen
-		cb.successes = 0
-	}
-	return cb.state
+	changed := cb.halfOpenIfDueLocked()
+	st := cb.state
+	cb.mu.Unlock()
+	cb.notify(changed)
+	return st
 }
 
 // Snapshot returns a point-in-time view of the circuit breaker.
 type Snapshot struct {
-	Name       string    `json:"name"`
-	State      CBState   `json:"state"`
-	Failures   int       `json:"failures"`
-	TotalTrips int       `json:"total_trips"`
-	TrippedAt  time.Time `json:"tripped_at,omitempty"`
+	Name        string    `json:"name"`
+	State       CBState   `json:"state"`
+	StateName   string    `json:"state_name"`
+	Failures    int       `json:"failures"`
+	TotalTrips  int       `json:"total_trips"`
+	TrippedAt   time.Time `json:"tripped_at,omitempty"`
+	Calls       int       `json:"calls"`        // outcomes in the sliding window
+	FailureRate float64   `json:"failure_rate"` // over the sliding window
+	SlowRate    float64   `json:"slow_rate"`
 }
 
 // Snapshot returns the current state snapshot.
 func (cb *CircuitBreaker) Snapshot() Snapshot {
 	cb.mu.Lock()
-	defer cb.mu.Unlock()
-	// Read state directly (not via cb.State()) to avoid mutex re-entrance.
-	st := cb.state
-	if st == CBOpen && cb.now().Sub(cb.trippedAt) >= cb.config.ResetTimeout {
-		st = CBHalfOpen
-		cb.state = CBHalfOpen
-		cb.successes = 0
-	}
-	return Snapshot{
-		Name:       cb.name,
-		State:      st,
-		Failures:   cb.failures,
-		TotalTrips: cb.totalTrips,
-		TrippedAt:  cb.trippedAt,
-	}
+	changed := cb.halfOpenIfDueLocked()
+	snap := cb.snapshotLocked()
+	cb.mu.Unlock()
+	cb.notify(changed)
+	return snap
 }
 
 // Reset forces the circuit breaker back to closed state.
 func (cb *CircuitBreaker) Reset() {
+	cb.mu.Lock()
+	changed := cb.setStateLocked(CBClosed)
+	cb.mu.Unlock()
+	cb.notify(changed)
+}
+
+// Restore applies persisted state, e.g. after a restart. An OPEN breaker
+// stays open until ResetTimeout has passed since its original trip.
+func (cb *CircuitBreaker) Restore(snap Snapshot) {
 	cb.mu.Lock()
 	defer cb.mu.Unlock()
-	cb.state = CBClosed
-	cb.failures = 0
+	switch snap.State {
+	case CBClosed, CBOpen, CBHalfOpen:
+		cb.state = snap.State
+	default:
+		cb.state = CBClosed
+	}
+	cb.failures = snap.Failures
+	cb.totalTrips = snap.TotalTrips
+	cb.trippedAt = snap.TrippedAt
 	cb.successes = 0
+	cb.window = nil
+	observability.CircuitBreakerState.WithLabelValues(cb.name).Set(float64(cb.state))
+}
+
+// halfOpenIfDueLocked moves OPEN → HALF_OPEN once ResetTimeout has passed.
+func (cb *CircuitBreaker) halfOpenIfDueLocked() bool {
+	if cb.state == CBOpen && cb.now().Sub(cb.trippedAt) >= cb.config.ResetTimeout {
+		return cb.setStateLocked(CBHalfOpen)
+	}
+	return false
+}
+
+// tripLocked opens the circuit.
+func (cb *CircuitBreaker) tripLocked() bool {
+	cb.trippedAt = cb.now()
+	cb.totalTrips++
+	observability.CircuitBreakerTrips.WithLabelValues(cb.name).Inc()
+	return cb.setStateLocked(CBOpen)
+}
+
+// setStateLocked changes state; closing clears the failure counters.
+// Returns whether the state changed.
+func (cb *CircuitBreaker) setStateLocked(to CBState) bool {
+	from := cb.state
+	cb.state = to
+	cb.successes = 0
+	if to == CBClosed {
+		cb.failures = 0
+		cb.window = nil
+	}
+	observability.CircuitBreakerState.WithLabelValues(cb.name).Set(float64(to))
+	return from != to
+}
+
+func (cb *CircuitBreaker) ratesLocked() (failRate, slowRate float64) {
+	if len(cb.window) == 0 {
+		return 0, 0
+	}
+	var failed, slow int
+	for _, o := range cb.window {
+		if o.failed {
+			failed++
+		}
+		if o.slow {
+			slow++
+		}
+	}
+	n := float64(len(cb.window))
+	return float64(failed) / n, float64(slow) / n
+}
+
+func (cb *CircuitBreaker) snapshotLocked() Snapshot {
+	failRate, slowRate := cb.ratesLocked()
+	return Snapshot{
+		Name:        cb.name,
+		State:       cb.state,
+		StateName:   cb.state.String(),
+		Failures:    cb.failures,
+		TotalTrips:  cb.totalTrips,
+		TrippedAt:   cb.trippedAt,
+		Calls:       len(cb.window),
+		FailureRate: failRate,
+		SlowRate:    slowRate,
+	}
+}
+
+// notify reports a state change to the registered listener.
+func (cb *CircuitBreaker) notify(changed bool) {
+	if !changed {
+		return
+	}
+	cb.mu.Lock()
+	fn, snap := cb.onChange, cb.snapshotLocked()
+	cb.mu.Unlock()
+	if fn != nil {
+		fn(snap)
+	}
 }
 
 // ErrCircuitOpen is returned when the circuit breaker is open.
-var ErrCircuitOpen = fmt.Errorf("circuit breaker open")
+var ErrCircuitOpen = domain.ErrCircuitOpen
 
 // ═══════════════════════════════════════════════════════════════════════════
 // Quarantine Manager
@@ -218,6 +361,9 @@ const (
 	QuarantineTaskFailures     QuarantineReason = "task_failures"     // 3+ task failures
 	QuarantineVerificationFail QuarantineReason = "verification_fail" // result verification failed
 	QuarantineAnomaly          QuarantineReason = "anomaly"           // behavioral anomaly detected
+	QuarantineReputation       QuarantineReason = "reputation"        // reputation fell below the floor
+	QuarantineIncident         QuarantineReason = "incident"          // self-healing incident open on the node
+	QuarantineProbationFail    QuarantineReason = "probation_fail"    // failed a probation check or failed on probation
 )
 
 // QuarantineRecord tracks a quarantine period.
@@ -242,6 +388,8 @@ type QuarantineConfig struct {
 	BanWindowDays        int           // rolling window for quarantine count (default 7)
 	BanThreshold         int           // quarantines to trigger ban (default 3)
 	FailureThreshold     int           // task failures to trigger quarantine (default 3)
+	ProbationDuration    time.Duration // probation after release (default 24h)
+	SweepInterval        time.Duration // how often Run checks for expiry (default 1m)
 }
 
 // DefaultQuarantineConfig returns production defaults per Architecture Part XVI.
@@ -253,6 +401,8 @@ func DefaultQuarantineConfig() QuarantineConfig {
 		BanWindowDays:        7,
 		BanThreshold:         3,
 		FailureThreshold:     3,
+		ProbationDuration:    24 * time.Hour,
+		SweepInterval:        time.Minute,
 	}
 }
 
@@ -263,38 +413,77 @@ type QuarantineManager struct {
 	records  map[string][]QuarantineRecord // nodeID → history
 	failures map[string]int                // nodeID → consecutive failure count
 	now      func() time.Time
+
+	probation     map[string]time.Time // nodeID → probation end
+	store         QuarantineStore      // nil = in-memory only
+	probe         ProbeFunc            // nil = release on expiry
+	triggers      func() []QuarantineTrigger
+	onChange      func(QuarantineRecord)
+	quarantines   int64
+	releases      int64
+	probeFailures int64
+
+	// auditHook records quarantine actions (nil = disabled). Called with the
+	// manager lock held, so it must not call back into the manager.
+	auditHook func(action, target, details string)
+}
+
+// SetAuditHook installs a callback invoked for every quarantine and release.
+func (qm *QuarantineManager) SetAuditHook(fn func(action, target, details string)) {
+	qm.mu.Lock()
+	defer qm.mu.Unlock()
+	qm.auditHook = fn
 }
 
 // NewQuarantineManager creates a quarantine manager.
 func NewQuarantineManager(cfg QuarantineConfig) *QuarantineManager {
+	def := DefaultQuarantineConfig()
+	if cfg.ProbationDuration <= 0 {
+		cfg.ProbationDuration = def.ProbationDuration
+	}
+	if cfg.SweepInterval <= 0 {
+		cfg.SweepInterval = def.SweepInterval
+	}
 	return &QuarantineManager{
-		config:   cfg,
-		records:  make(map[string][]QuarantineRecord),
-		failures: make(map[string]int),
-		now:      time.Now,
+		config:    cfg,
+		records:   make(map[string][]QuarantineRecord),
+		failures:  make(map[string]int),
+		now:       time.Now,
+		probation: make(map[string]time.Time),
 	}
 }
 
 // RecordFailure increments the failure count for a node.
 // If failures reach the threshold, the node is automatically quarantined.
+// A node on probation is quarantined on its first failure.
 // Returns non-nil QuarantineRecord if quarantine was triggered.
 func (qm *QuarantineManager) RecordFailure(nodeID string) *QuarantineRecord {
 	qm.mu.Lock()
-	defer qm.mu.Unlock()
-
+	var rec *QuarantineRecord
 	qm.failures[nodeID]++
-	if qm.failures[nodeID] >= qm.config.FailureThreshold {
+	if qm.onProbationLocked(nodeID) {
+		qm.failures[nodeID] = 0
+		rec = qm.quarantineLocked(nodeID, QuarantineProbationFail)
+	} else if qm.failures[nodeID] >= qm.config.FailureThreshold {
 		qm.failures[nodeID] = 0
-		return qm.quarantineLocked(nodeID, QuarantineTaskFailures)
+		rec = qm.quarantineLocked(nodeID, QuarantineTaskFailures)
 	}
-	return nil
+	qm.mu.Unlock()
+
+	if rec != nil {
+		qm.changed(*rec)
+	}
+	return rec
 }
 
 // RecordVerificationFailure immediately quarantines a node for verification failure.
 func (qm *QuarantineManager) RecordVerificationFailure(nodeID string) *QuarantineRecord {
 	qm.mu.Lock()
-	defer qm.mu.Unlock()
-	return qm.quarantineLocked(nodeID, QuarantineVerificationFail)
+	rec := qm.quarantineLocked(nodeID, QuarantineVerificationFail)
+	qm.mu.Unlock()
+
+	qm.changed(*rec)
+	return rec
 }
 
 // IsQuarantined checks if a node is currently quarantined.
@@ -327,11 +516,28 @@ func (qm *QuarantineManager) ActiveQuarantine(nodeID string) *QuarantineRecord {
 // Release manually releases a node from quarantine.
 func (qm *QuarantineManager) Release(nodeID string) {
 	qm.mu.Lock()
-	defer qm.mu.Unlock()
+	now := qm.now()
+	var released *QuarantineRecord
 	for i := range qm.records[nodeID] {
+		if qm.records[nodeID][i].IsActive(now) {
+			rec := qm.records[nodeID][i]
+			rec.Released = true
+			released = &rec
+		}
 		qm.records[nodeID][i].Released = true
 	}
 	qm.failures[nodeID] = 0
+	if qm.auditHook != nil {
+		qm.auditHook("quarantine.release", nodeID, "")
+	}
+	if released != nil {
+		qm.releases++
+	}
+	qm.mu.Unlock()
+
+	if released != nil {
+		qm.changed(*released)
+	}
 }
 
 // RecentQuarantineCount returns how many quarantines a node has had in the ban window.
@@ -374,6 +580,12 @@ func (qm *QuarantineManager) quarantineLocked(nodeID string, reason QuarantineRe
 	}
 
 	qm.records[nodeID] = append(qm.records[nodeID], record)
+	delete(qm.probation, nodeID)
+	qm.quarantines++
+	if qm.auditHook != nil {
+		qm.auditHook("quarantine.start", nodeID,
+			fmt.Sprintf("reason=%s expires=%s", reason, record.ExpiresAt.UTC().Format(time.RFC3339)))
+	}
 	return &record
 }
 
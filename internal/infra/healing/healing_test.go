@@ -1,6 +1,7 @@
 package healing
 
 import (
+	"errors"
 	"testing"
 	"time"
 )
@@ -216,6 +217,72 @@ func TestCircuitBreaker_Reset(t *testing.T) {
 	}
 }
 
+// ─── Failure Rate & Slow Calls ──────────────────────────────────────────────
+
+func TestCircuitBreaker_RateThresholds(t *testing.T) {
+	cfg := CircuitBreakerConfig{
+		FailureThreshold: 100, // out of the way
+		ResetTimeout:     time.Second,
+		HalfOpenMax:      1,
+		Window:           10,
+		MinCalls:         4,
+		FailureRate:      0.5,
+		SlowCall:         time.Second,
+		SlowCallRate:     0.75,
+	}
+	fail := errors.New("boom")
+	type call struct {
+		err error
+		d   time.Duration
+	}
+	tests := []struct {
+		name  string
+		calls []call
+		want  CBState
+	}{
+		{"below min calls", []call{{fail, 0}, {fail, 0}, {fail, 0}}, CBClosed},
+		{"failure rate", []call{{nil, 0}, {fail, 0}, {nil, 0}, {fail, 0}}, CBOpen},
+		{"slow calls", []call{{nil, 2 * time.Second}, {nil, 2 * time.Second}, {nil, 0}, {nil, 3 * time.Second}}, CBOpen},
+		{"healthy", []call{{nil, 0}, {fail, 0}, {nil, 2 * time.Second}, {nil, 0}, {nil, 0}}, CBClosed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cb := NewCircuitBreaker("rate-cb", cfg)
+			for _, c := range tt.calls {
+				cb.Record(c.err, c.d)
+			}
+			if got := cb.State(); got != tt.want {
+				t.Errorf("state = %s, want %s (snapshot %+v)", got, tt.want, cb.Snapshot())
+			}
+		})
+	}
+}
+
+func TestCircuitBreaker_Execute(t *testing.T) {
+	clock := time.Now()
+	cb := newTestCBWithClock(t, func() time.Time { return clock })
+	var changes []CBState
+	cb.OnStateChange(func(s Snapshot) { changes = append(changes, s.State) })
+
+	fail := errors.New("boom")
+	for i := 0; i < 3; i++ {
+		if err := cb.Execute(func() error { return fail }); !errors.Is(err, fail) {
+			t.Fatalf("Execute err = %v", err)
+		}
+	}
+	ran := false
+	if err := cb.Execute(func() error { ran = true; return nil }); !errors.Is(err, ErrCircuitOpen) || ran {
+		t.Fatalf("Execute while open: err = %v, ran = %v", err, ran)
+	}
+
+	clock = clock.Add(2 * time.Second)
+	cb.Execute(func() error { return nil })
+	cb.Execute(func() error { return nil })
+	if len(changes) != 3 || changes[0] != CBOpen || changes[1] != CBHalfOpen || changes[2] != CBClosed {
+		t.Errorf("state changes = %v, want OPEN, HALF_OPEN, CLOSED", changes)
+	}
+}
+
 // ═══════════════════════════════════════════════════════════════════════════
 // Quarantine Manager Tests
 // ═══════════════════════════════════════════════════════════════════════════
@@ -327,6 +394,23 @@ func TestQuarantine_Release(t *testing.T) {
 	}
 }
 
+func TestQuarantine_AuditHook(t *testing.T) {
+	clock := time.Now()
+	qm := newTestQM(t, func() time.Time { return clock })
+	var actions []string
+	qm.SetAuditHook(func(action, target, details string) {
+		actions = append(actions, action+":"+target)
+	})
+
+	qm.RecordVerificationFailure("node-1")
+	qm.Release("node-1")
+
+	want := []string{"quarantine.start:node-1", "quarantine.release:node-1"}
+	if len(actions) != 2 || actions[0] != want[0] || actions[1] != want[1] {
+		t.Errorf("audit actions = %v, want %v", actions, want)
+	}
+}
+
 func TestQuarantine_BanEscalation(t *testing.T) {
 	clock := time.Now()
 	qm := newTestQM(t, func() time.Time { return clock })
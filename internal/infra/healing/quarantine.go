@@ -0,0 +1,318 @@
+package healing
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/infra/observability"
+)
+
+// ═══════════════════════════════════════════════════════════════════════════
+// Quarantine Orchestration
+// ═══════════════════════════════════════════════════════════════════════════
+// The manager is the single owner of quarantine decisions on this node:
+//
+//  1. Triggers come from task failures, verification, and — via Trigger or
+//     the SetTriggers source polled every sweep — anomaly detection,
+//     reputation and self-healing incidents
+//  2. Every quarantine and release is persisted to the store and handed to
+//     the OnChange hook, which gossips it to the network
+//  3. Sweep finds expired quarantines and runs the probation check: a pass
+//     releases the node on probation, a failure re-quarantines it (and
+//     counts towards the ban escalation)
+//  4. Restore reloads the ban window's records at start, so a restart does
+//     not lift a quarantine or reset escalation
+
+// QuarantineRow is a persisted quarantine record as returned by the store.
+type QuarantineRow = struct {
+	NodeID    string
+	Reason    string
+	StartedAt time.Time
+	ExpiresAt time.Time
+	Released  bool
+}
+
+// QuarantineStore persists quarantine records. *sqlite.DB satisfies it.
+type QuarantineStore interface {
+	InsertQuarantineRecord(nodeID, reason string, startedAt, expiresAt time.Time) (int64, error)
+	ReleaseQuarantine(nodeID string) error
+	ListQuarantinesSince(since time.Time) ([]QuarantineRow, error)
+}
+
+// ProbeFunc is the probation check run when a quarantine expires. A nil
+// error releases the node on probation.
+type ProbeFunc func(ctx context.Context, nodeID string) error
+
+// QuarantineTrigger is an external reason to quarantine a node.
+type QuarantineTrigger struct {
+	NodeID string
+	Reason QuarantineReason
+}
+
+// SetStore makes quarantines durable. Call Restore to load existing records.
+func (qm *QuarantineManager) SetStore(store QuarantineStore) {
+	qm.mu.Lock()
+	defer qm.mu.Unlock()
+	qm.store = store
+}
+
+// SetProbe installs the probation check. Without one, expired quarantines
+// are released unconditionally.
+func (qm *QuarantineManager) SetProbe(fn ProbeFunc) {
+	qm.mu.Lock()
+	defer qm.mu.Unlock()
+	qm.probe = fn
+}
+
+// SetTriggers installs a trigger source polled at the end of every sweep.
+func (qm *QuarantineManager) SetTriggers(fn func() []QuarantineTrigger) {
+	qm.mu.Lock()
+	defer qm.mu.Unlock()
+	qm.triggers = fn
+}
+
+// OnChange registers a callback invoked — outside the manager lock — for
+// every quarantine and release. Released records carry Released=true.
+func (qm *QuarantineManager) OnChange(fn func(QuarantineRecord)) {
+	qm.mu.Lock()
+	defer qm.mu.Unlock()
+	qm.onChange = fn
+}
+
+// Restore loads the records started within the ban window from the store.
+func (qm *QuarantineManager) Restore() error {
+	qm.mu.Lock()
+	store := qm.store
+	since := qm.now().AddDate(0, 0, -qm.config.BanWindowDays)
+	qm.mu.Unlock()
+	if store == nil {
+		return nil
+	}
+
+	rows, err := store.ListQuarantinesSince(since.UTC())
+	if err != nil {
+		return fmt.Errorf("list quarantines: %w", err)
+	}
+	qm.mu.Lock()
+	for _, row := range rows {
+		qm.records[row.NodeID] = append(qm.records[row.NodeID], QuarantineRecord{
+			NodeID:    row.NodeID,
+			Reason:    QuarantineReason(row.Reason),
+			StartedAt: row.StartedAt,
+			ExpiresAt: row.ExpiresAt,
+			Released:  row.Released,
+		})
+	}
+	active := qm.activeCountLocked()
+	qm.mu.Unlock()
+
+	observability.QuarantinedNodes.Set(float64(active))
+	return nil
+}
+
+// Trigger quarantines a node on an external signal (anomaly, reputation,
+// incident). Returns nil if the node is already quarantined.
+func (qm *QuarantineManager) Trigger(nodeID string, reason QuarantineReason) *QuarantineRecord {
+	qm.mu.Lock()
+	if qm.activeLocked(nodeID) != nil {
+		qm.mu.Unlock()
+		return nil
+	}
+	rec := qm.quarantineLocked(nodeID, reason)
+	qm.mu.Unlock()
+
+	qm.changed(*rec)
+	return rec
+}
+
+// OnProbation reports whether a released node is still on probation.
+func (qm *QuarantineManager) OnProbation(nodeID string) bool {
+	qm.mu.Lock()
+	defer qm.mu.Unlock()
+	return qm.onProbationLocked(nodeID)
+}
+
+// Active returns the quarantines currently in effect, sorted by node ID.
+func (qm *QuarantineManager) Active() []QuarantineRecord {
+	qm.mu.Lock()
+	defer qm.mu.Unlock()
+	var out []QuarantineRecord
+	for nodeID := range qm.records {
+		if rec := qm.activeLocked(nodeID); rec != nil {
+			out = append(out, *rec)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].NodeID < out[j].NodeID })
+	return out
+}
+
+// Sweep ends expired quarantines — each node is probed and either released
+// on probation or quarantined again — then applies the trigger source.
+func (qm *QuarantineManager) Sweep(ctx context.Context) {
+	qm.mu.Lock()
+	now := qm.now()
+	var due []QuarantineRecord
+	for nodeID, recs := range qm.records {
+		if qm.activeLocked(nodeID) != nil {
+			continue
+		}
+		var expired *QuarantineRecord
+		for i := range recs {
+			if !recs[i].Released {
+				// Expired but never released — claim it so that a
+				// concurrent sweep does not probe the node twice
+				recs[i].Released = true
+				expired = &recs[i]
+			}
+		}
+		if expired != nil {
+			due = append(due, *expired)
+		}
+	}
+	for nodeID, until := range qm.probation {
+		if !now.Before(until) {
+			delete(qm.probation, nodeID)
+		}
+	}
+	probe, triggers := qm.probe, qm.triggers
+	qm.mu.Unlock()
+
+	for _, rec := range due {
+		rec.Released = true
+		if probe != nil {
+			if err := probe(ctx, rec.NodeID); err != nil {
+				log.Printf("[healing] %s failed probation check: %v", rec.NodeID, err)
+				qm.persist(rec)
+				qm.mu.Lock()
+				qm.probeFailures++
+				next := qm.quarantineLocked(rec.NodeID, QuarantineProbationFail)
+				qm.mu.Unlock()
+				qm.changed(*next)
+				continue
+			}
+		}
+		qm.mu.Lock()
+		qm.probation[rec.NodeID] = qm.now().Add(qm.config.ProbationDuration)
+		qm.releases++
+		if qm.auditHook != nil {
+			qm.auditHook("quarantine.release", rec.NodeID, "expired; on probation")
+		}
+		qm.mu.Unlock()
+		qm.changed(rec)
+	}
+
+	if triggers != nil {
+		for _, t := range triggers() {
+			qm.Trigger(t.NodeID, t.Reason)
+		}
+	}
+}
+
+// Run sweeps every SweepInterval until ctx is done.
+func (qm *QuarantineManager) Run(ctx context.Context) {
+	ticker := time.NewTicker(qm.config.SweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			qm.Sweep(ctx)
+		}
+	}
+}
+
+// QuarantineStats summarizes the manager.
+type QuarantineStats struct {
+	Active        int   `json:"active"`
+	OnProbation   int   `json:"on_probation"`
+	Quarantines   int64 `json:"quarantines"`
+	Releases      int64 `json:"releases"`
+	ProbeFailures int64 `json:"probe_failures"`
+}
+
+// Stats returns a snapshot of the manager.
+func (qm *QuarantineManager) Stats() QuarantineStats {
+	qm.mu.Lock()
+	defer qm.mu.Unlock()
+	probation := 0
+	for nodeID := range qm.probation {
+		if qm.onProbationLocked(nodeID) {
+			probation++
+		}
+	}
+	return QuarantineStats{
+		Active:        qm.activeCountLocked(),
+		OnProbation:   probation,
+		Quarantines:   qm.quarantines,
+		Releases:      qm.releases,
+		ProbeFailures: qm.probeFailures,
+	}
+}
+
+// changed persists a quarantine or release, updates metrics and calls the
+// OnChange hook. Must be called without qm.mu held.
+func (qm *QuarantineManager) changed(rec QuarantineRecord) {
+	qm.mu.Lock()
+	hook := qm.onChange
+	active := qm.activeCountLocked()
+	qm.mu.Unlock()
+
+	observability.QuarantinedNodes.Set(float64(active))
+	if !rec.Released {
+		observability.QuarantineEvents.WithLabelValues(string(rec.Reason)).Inc()
+	}
+	qm.persist(rec)
+	if hook != nil {
+		hook(rec)
+	}
+}
+
+// persist writes a quarantine or release to the store.
+func (qm *QuarantineManager) persist(rec QuarantineRecord) {
+	qm.mu.Lock()
+	store := qm.store
+	qm.mu.Unlock()
+	if store == nil {
+		return
+	}
+
+	var err error
+	if rec.Released {
+		err = store.ReleaseQuarantine(rec.NodeID)
+	} else {
+		_, err = store.InsertQuarantineRecord(rec.NodeID, string(rec.Reason), rec.StartedAt.UTC(), rec.ExpiresAt.UTC())
+	}
+	if err != nil {
+		log.Printf("[healing] persist quarantine %s: %v", rec.NodeID, err)
+	}
+}
+
+func (qm *QuarantineManager) activeLocked(nodeID string) *QuarantineRecord {
+	now := qm.now()
+	for _, r := range qm.records[nodeID] {
+		if r.IsActive(now) {
+			rec := r
+			return &rec
+		}
+	}
+	return nil
+}
+
+func (qm *QuarantineManager) activeCountLocked() int {
+	count := 0
+	for nodeID := range qm.records {
+		if qm.activeLocked(nodeID) != nil {
+			count++
+		}
+	}
+	return count
+}
+
+func (qm *QuarantineManager) onProbationLocked(nodeID string) bool {
+	until, ok := qm.probation[nodeID]
+	return ok && qm.now().Before(until)
+}
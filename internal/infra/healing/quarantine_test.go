@@ -0,0 +1,133 @@
+package healing
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// memQuarantineStore is an in-memory QuarantineStore.
+type memQuarantineStore struct {
+	rows []QuarantineRow
+}
+
+func (m *memQuarantineStore) InsertQuarantineRecord(nodeID, reason string, startedAt, expiresAt time.Time) (int64, error) {
+	m.rows = append(m.rows, QuarantineRow{NodeID: nodeID, Reason: reason, StartedAt: startedAt, ExpiresAt: expiresAt})
+	return int64(len(m.rows)), nil
+}
+
+func (m *memQuarantineStore) ReleaseQuarantine(nodeID string) error {
+	for i := range m.rows {
+		if m.rows[i].NodeID == nodeID {
+			m.rows[i].Released = true
+		}
+	}
+	return nil
+}
+
+func (m *memQuarantineStore) ListQuarantinesSince(since time.Time) ([]QuarantineRow, error) {
+	var out []QuarantineRow
+	for _, r := range m.rows {
+		if r.StartedAt.After(since) {
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
+
+func TestQuarantine_TriggerPersistsAndRestores(t *testing.T) {
+	clock := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := &memQuarantineStore{}
+	qm := newTestQM(t, func() time.Time { return clock })
+	qm.SetStore(store)
+	var changes []QuarantineRecord
+	qm.OnChange(func(rec QuarantineRecord) { changes = append(changes, rec) })
+
+	if rec := qm.Trigger("node-1", QuarantineAnomaly); rec == nil {
+		t.Fatal("Trigger should quarantine node-1")
+	}
+	if rec := qm.Trigger("node-1", QuarantineReputation); rec != nil {
+		t.Errorf("Trigger on a quarantined node = %+v, want nil", rec)
+	}
+	if len(store.rows) != 1 || store.rows[0].Reason != string(QuarantineAnomaly) {
+		t.Fatalf("store rows = %+v, want one anomaly record", store.rows)
+	}
+
+	restored := newTestQM(t, func() time.Time { return clock.Add(time.Minute) })
+	restored.SetStore(store)
+	if err := restored.Restore(); err != nil {
+		t.Fatalf("Restore() error: %v", err)
+	}
+	if !restored.IsQuarantined("node-1") {
+		t.Error("quarantine lost across restart")
+	}
+
+	qm.Release("node-1")
+	if !store.rows[0].Released {
+		t.Error("release not persisted")
+	}
+	if len(changes) != 2 || changes[0].Released || !changes[1].Released {
+		t.Errorf("changes = %+v, want quarantine then release", changes)
+	}
+}
+
+func TestQuarantine_SweepProbation(t *testing.T) {
+	tests := []struct {
+		name        string
+		probe       ProbeFunc
+		quarantined bool
+		probation   bool
+		reason      QuarantineReason
+	}{
+		{"no probe releases", nil, false, true, ""},
+		{"probe passes", func(context.Context, string) error { return nil }, false, true, ""},
+		{"probe fails", func(context.Context, string) error { return errors.New("still anomalous") }, true, false, QuarantineProbationFail},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+			qm := newTestQM(t, func() time.Time { return clock })
+			qm.SetProbe(tt.probe)
+			qm.Trigger("node-1", QuarantineIncident)
+
+			clock = clock.Add(2 * time.Hour)
+			qm.Sweep(context.Background())
+
+			if got := qm.IsQuarantined("node-1"); got != tt.quarantined {
+				t.Errorf("IsQuarantined = %v, want %v", got, tt.quarantined)
+			}
+			if got := qm.OnProbation("node-1"); got != tt.probation {
+				t.Errorf("OnProbation = %v, want %v", got, tt.probation)
+			}
+			if tt.reason != "" {
+				if rec := qm.ActiveQuarantine("node-1"); rec == nil || rec.Reason != tt.reason {
+					t.Errorf("ActiveQuarantine = %+v, want reason %s", rec, tt.reason)
+				}
+			}
+		})
+	}
+}
+
+func TestQuarantine_FailureOnProbation(t *testing.T) {
+	clock := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	qm := newTestQM(t, func() time.Time { return clock })
+	qm.Trigger("node-1", QuarantineAnomaly)
+
+	clock = clock.Add(2 * time.Hour)
+	qm.Sweep(context.Background())
+	if rec := qm.RecordFailure("node-1"); rec == nil || rec.Reason != QuarantineProbationFail {
+		t.Fatalf("failure on probation = %+v, want probation_fail quarantine", rec)
+	}
+
+	st := qm.Stats()
+	if st.Active != 1 || st.Quarantines != 2 || st.Releases != 1 || st.OnProbation != 0 {
+		t.Errorf("Stats = %+v", st)
+	}
+
+	// A second sweep while the quarantine is active changes nothing
+	qm.Sweep(context.Background())
+	if !qm.IsQuarantined("node-1") {
+		t.Error("active quarantine released early")
+	}
+}
@@ -0,0 +1,269 @@
+// Package housekeeping runs the node's periodic maintenance jobs on one
+// schedule.
+//
+// Reputation decay, anomaly profile cleanup, retention pruning, retirement
+// scans and scaler evaluation each need to run every so often. Rather than
+// a ticker goroutine apiece, they are registered with a Coordinator that
+// runs them one at a time from a single loop — jobs that touch SQLite never
+// contend with each other for the write lock — and spreads them out with
+// jitter so nodes started together do not run their jobs in lockstep.
+// Every job keeps its last-run status for the status endpoint and can be
+// switched off or run on demand.
+package housekeeping
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrUnknownJob is returned for a job name that was never registered.
+var ErrUnknownJob = errors.New("housekeeping: unknown job")
+
+// Job is a periodic maintenance task.
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Enabled  bool
+	// Run does one round of work and returns a short summary of it
+	// ("decayed 3 nodes").
+	Run func(ctx context.Context) (string, error)
+}
+
+// JobStatus is a job's schedule and the outcome of its last run.
+type JobStatus struct {
+	Name         string        `json:"name"`
+	Enabled      bool          `json:"enabled"`
+	Interval     time.Duration `json:"interval"`
+	NextRun      time.Time     `json:"next_run,omitempty"` // zero while disabled
+	LastRun      time.Time     `json:"last_run,omitempty"`
+	LastDuration time.Duration `json:"last_duration"`
+	LastResult   string        `json:"last_result,omitempty"`
+	LastError    string        `json:"last_error,omitempty"`
+	Runs         int64         `json:"runs"`
+	Failures     int64         `json:"failures"`
+}
+
+// Config configures the coordinator.
+type Config struct {
+	// Jitter spreads each run by up to ± this fraction of the job's
+	// interval (default 0.1; negative disables jitter).
+	Jitter float64
+	Now    func() time.Time
+	Rand   func() float64 // uniform in [0, 1); default math/rand
+}
+
+// DefaultConfig returns production defaults.
+func DefaultConfig() Config {
+	return Config{Jitter: 0.1, Now: time.Now, Rand: rand.Float64}
+}
+
+// entry is a registered job and its status.
+type entry struct {
+	job    Job
+	status JobStatus
+}
+
+// Coordinator schedules and runs jobs. Thread-safe.
+type Coordinator struct {
+	mu     sync.Mutex
+	runMu  sync.Mutex // one job at a time, scheduled or on demand
+	config Config
+	jobs   map[string]*entry
+	wake   chan struct{} // schedule changed
+}
+
+// New creates a coordinator. Zero config fields take their defaults.
+func New(cfg Config) *Coordinator {
+	def := DefaultConfig()
+	if cfg.Jitter == 0 {
+		cfg.Jitter = def.Jitter
+	}
+	if cfg.Jitter < 0 {
+		cfg.Jitter = 0
+	}
+	if cfg.Now == nil {
+		cfg.Now = def.Now
+	}
+	if cfg.Rand == nil {
+		cfg.Rand = def.Rand
+	}
+	return &Coordinator{
+		config: cfg,
+		jobs:   make(map[string]*entry),
+		wake:   make(chan struct{}, 1),
+	}
+}
+
+// Register adds a job. Its first run is one jittered interval from now.
+func (c *Coordinator) Register(j Job) error {
+	if j.Name == "" || j.Run == nil || j.Interval <= 0 {
+		return fmt.Errorf("housekeeping: job needs a name, a positive interval and a Run func")
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.jobs[j.Name]; ok {
+		return fmt.Errorf("housekeeping: job %q already registered", j.Name)
+	}
+	e := &entry{job: j, status: JobStatus{Name: j.Name, Enabled: j.Enabled, Interval: j.Interval}}
+	if j.Enabled {
+		e.status.NextRun = c.nextLocked(c.config.Now(), j.Interval)
+	}
+	c.jobs[j.Name] = e
+	c.notify()
+	return nil
+}
+
+// SetEnabled switches a job on or off. A re-enabled job next runs one
+// jittered interval from now.
+func (c *Coordinator) SetEnabled(name string, enabled bool) (JobStatus, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.jobs[name]
+	if !ok {
+		return JobStatus{}, fmt.Errorf("%w: %s", ErrUnknownJob, name)
+	}
+	if e.status.Enabled != enabled {
+		e.status.Enabled = enabled
+		e.status.NextRun = time.Time{}
+		if enabled {
+			e.status.NextRun = c.nextLocked(c.config.Now(), e.job.Interval)
+		}
+		c.notify()
+	}
+	return e.status, nil
+}
+
+// RunNow runs a job immediately, enabled or not, and returns its status.
+// Its regular schedule restarts from this run.
+func (c *Coordinator) RunNow(ctx context.Context, name string) (JobStatus, error) {
+	c.mu.Lock()
+	_, ok := c.jobs[name]
+	c.mu.Unlock()
+	if !ok {
+		return JobStatus{}, fmt.Errorf("%w: %s", ErrUnknownJob, name)
+	}
+	return c.runJob(ctx, name), nil
+}
+
+// Status returns every job's status, by name.
+func (c *Coordinator) Status() []JobStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := make([]JobStatus, 0, len(c.jobs))
+	for _, e := range c.jobs {
+		out = append(out, e.status)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// Run executes due jobs until ctx is done. Jobs due at the same time run
+// in name order.
+func (c *Coordinator) Run(ctx context.Context) {
+	for {
+		due, wait := c.due()
+		for _, name := range due {
+			if ctx.Err() != nil {
+				return
+			}
+			c.runJob(ctx, name)
+		}
+		if len(due) > 0 {
+			continue
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-c.wake:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+// due returns the jobs whose run time has come, or how long until the next
+// one (an hour when nothing is scheduled).
+func (c *Coordinator) due() ([]string, time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.config.Now()
+	wait := time.Hour
+	var due []string
+	for name, e := range c.jobs {
+		if !e.status.Enabled {
+			continue
+		}
+		if !e.status.NextRun.After(now) {
+			due = append(due, name)
+		} else if d := e.status.NextRun.Sub(now); d < wait {
+			wait = d
+		}
+	}
+	sort.Strings(due)
+	return due, wait
+}
+
+// runJob runs one job and records the outcome.
+func (c *Coordinator) runJob(ctx context.Context, name string) JobStatus {
+	c.runMu.Lock()
+	defer c.runMu.Unlock()
+
+	c.mu.Lock()
+	run := c.jobs[name].job.Run
+	c.mu.Unlock()
+
+	start := c.config.Now()
+	result, err := safeRun(ctx, run)
+	end := c.config.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e := c.jobs[name]
+	s := &e.status
+	s.LastRun = start
+	s.LastDuration = end.Sub(start)
+	s.LastResult = result
+	s.LastError = ""
+	s.Runs++
+	if err != nil {
+		s.LastError = err.Error()
+		s.Failures++
+	}
+	if s.Enabled {
+		s.NextRun = c.nextLocked(end, e.job.Interval)
+	}
+	return *s
+}
+
+// safeRun calls run, turning a panic into an error so one broken job does
+// not take the coordinator down.
+func safeRun(ctx context.Context, run func(context.Context) (string, error)) (result string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return run(ctx)
+}
+
+// nextLocked returns the jittered time one interval after from. Caller must
+// hold c.mu.
+func (c *Coordinator) nextLocked(from time.Time, interval time.Duration) time.Time {
+	spread := (c.config.Rand()*2 - 1) * c.config.Jitter
+	return from.Add(interval + time.Duration(spread*float64(interval)))
+}
+
+// notify wakes the run loop to recompute its schedule.
+func (c *Coordinator) notify() {
+	select {
+	case c.wake <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,130 @@
+package housekeeping
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeClock is a settable clock.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	c.mu.Unlock()
+}
+
+func newTestCoordinator(clock *fakeClock, random float64) *Coordinator {
+	return New(Config{Jitter: 0.1, Now: clock.Now, Rand: func() float64 { return random }})
+}
+
+func TestCoordinator_JitteredSchedule(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+	noop := func(context.Context) (string, error) { return "", nil }
+
+	// Rand 0 → -10%, 0.5 → on time, 1 → +10%
+	for random, want := range map[float64]time.Duration{0: 54 * time.Minute, 0.5: time.Hour} {
+		c := newTestCoordinator(clock, random)
+		c.Register(Job{Name: "j", Interval: time.Hour, Enabled: true, Run: noop})
+		if got := c.Status()[0].NextRun.Sub(clock.Now()); got != want {
+			t.Errorf("rand %.1f: first run in %s, want %s", random, got, want)
+		}
+	}
+
+	c := newTestCoordinator(clock, 0.5)
+	if err := c.Register(Job{Name: "j", Interval: time.Hour, Enabled: true, Run: noop}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Register(Job{Name: "j", Interval: time.Hour, Run: noop}); err == nil {
+		t.Error("duplicate job registered")
+	}
+	if err := c.Register(Job{Name: "bad", Run: noop}); err == nil {
+		t.Error("job without interval registered")
+	}
+}
+
+func TestCoordinator_RunRecordsStatus(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+	c := newTestCoordinator(clock, 0.5)
+
+	var calls atomic.Int32
+	c.Register(Job{Name: "decay", Interval: time.Minute, Enabled: true, Run: func(context.Context) (string, error) {
+		calls.Add(1)
+		clock.Advance(2 * time.Second)
+		return "decayed 2 nodes", nil
+	}})
+	c.Register(Job{Name: "broken", Interval: time.Minute, Enabled: true, Run: func(context.Context) (string, error) {
+		panic("boom")
+	}})
+	c.Register(Job{Name: "off", Interval: time.Minute, Run: func(context.Context) (string, error) {
+		return "", errors.New("should not run on schedule")
+	}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() { c.Run(ctx); close(done) }()
+
+	clock.Advance(time.Minute)
+	c.notify()
+	deadline := time.Now().Add(2 * time.Second)
+	for calls.Load() == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	cancel()
+	<-done
+
+	byName := map[string]JobStatus{}
+	for _, s := range c.Status() {
+		byName[s.Name] = s
+	}
+	if s := byName["decay"]; s.Runs != 1 || s.LastResult != "decayed 2 nodes" || s.LastDuration != 2*time.Second ||
+		!s.NextRun.After(s.LastRun) {
+		t.Errorf("decay status = %+v", s)
+	}
+	if s := byName["broken"]; s.Failures != 1 || s.LastError != "panic: boom" {
+		t.Errorf("broken status = %+v", s)
+	}
+	if s := byName["off"]; s.Runs != 0 || !s.NextRun.IsZero() {
+		t.Errorf("disabled job status = %+v", s)
+	}
+}
+
+func TestCoordinator_EnableAndRunNow(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+	c := newTestCoordinator(clock, 0.5)
+	c.Register(Job{Name: "scan", Interval: time.Hour, Run: func(context.Context) (string, error) {
+		return "3 candidates", nil
+	}})
+
+	s, err := c.RunNow(context.Background(), "scan")
+	if err != nil || s.Runs != 1 || s.LastResult != "3 candidates" || !s.NextRun.IsZero() {
+		t.Errorf("RunNow on disabled job = %+v, %v", s, err)
+	}
+	s, _ = c.SetEnabled("scan", true)
+	if !s.Enabled || s.NextRun.Sub(clock.Now()) != time.Hour {
+		t.Errorf("enabled = %+v", s)
+	}
+	s, _ = c.SetEnabled("scan", false)
+	if s.Enabled || !s.NextRun.IsZero() {
+		t.Errorf("disabled = %+v", s)
+	}
+	if _, err := c.RunNow(context.Background(), "nope"); !errors.Is(err, ErrUnknownJob) {
+		t.Errorf("unknown job: err = %v", err)
+	}
+	if _, err := c.SetEnabled("nope", true); !errors.Is(err, ErrUnknownJob) {
+		t.Errorf("unknown job: err = %v", err)
+	}
+}
@@ -0,0 +1,256 @@
+package intelligence
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
+)
+
+// ─── Recommendation Acknowledgement ─────────────────────────────────────────
+// Optimize gives every recommendation an ID and status PENDING. Whoever
+// acts on it — an operator, or the Executor — reports back through
+// Acknowledge:
+//
+//	PENDING  → ACCEPTED | REJECTED | COMPLETED | FAILED
+//	ACCEPTED → COMPLETED | FAILED
+//
+// A recommendation still PENDING after AckTimeout is marked IGNORED; a late
+// acknowledgement is still taken. The outcome feeds the next cycles:
+//
+//   - a recommendation still open (PENDING or ACCEPTED) is not suggested
+//     again
+//   - one REJECTED, FAILED or IGNORED is suppressed for RetryCooldown,
+//     doubling with each further strike up to 8×; for PLACE the next-best
+//     node is suggested instead
+//   - COMPLETED clears the strikes
+//
+// Every change is reported to the hook set with SetRecommendationHook, so
+// outcomes can be persisted; RestoreRecommendations rebuilds the state
+// from them after a restart.
+
+// AckStatus is the lifecycle state of a recommendation.
+type AckStatus string
+
+const (
+	AckPending   AckStatus = "PENDING"
+	AckAccepted  AckStatus = "ACCEPTED"
+	AckRejected  AckStatus = "REJECTED"
+	AckCompleted AckStatus = "COMPLETED"
+	AckFailed    AckStatus = "FAILED"
+	AckIgnored   AckStatus = "IGNORED" // not acknowledged within AckTimeout
+)
+
+// final reports whether no further acknowledgement is accepted.
+func (s AckStatus) final() bool {
+	return s == AckRejected || s == AckCompleted || s == AckFailed
+}
+
+// strike reports whether the status suppresses the recommendation.
+func (s AckStatus) strike() bool {
+	return s == AckRejected || s == AckFailed || s == AckIgnored
+}
+
+// ParseRecommendationType is the inverse of RecommendationType.String.
+func ParseRecommendationType(s string) (RecommendationType, bool) {
+	switch s {
+	case "PLACE":
+		return RecommendPlace, true
+	case "EVICT":
+		return RecommendEvict, true
+	case "MOVE":
+		return RecommendMove, true
+	}
+	return 0, false
+}
+
+// maxCooldownFactor caps the backoff of a repeatedly refused recommendation.
+const maxCooldownFactor = 8
+
+// recFeedback is what past outcomes say about one recommendation.
+type recFeedback struct {
+	strikes int
+	until   time.Time // suppressed before this
+}
+
+// recKey identifies a recommendation across cycles.
+func recKey(r Recommendation) string {
+	return r.Type.String() + "\x00" + r.ModelName + "\x00" + r.FromNode + "\x00" + r.ToNode
+}
+
+// newRecID returns the ID of the i-th recommendation of a cycle.
+func newRecID(now time.Time, i int) string {
+	return strconv.FormatInt(now.UnixNano(), 36) + "-" + strconv.Itoa(i)
+}
+
+// SetRecommendationHook installs a callback fired with every new
+// recommendation and every status change, outside the optimizer's lock.
+func (o *Optimizer) SetRecommendationHook(fn func(Recommendation)) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.recHook = fn
+}
+
+// notify reports recommendations to the hook.
+func (o *Optimizer) notify(recs []Recommendation) {
+	o.mu.RLock()
+	hook := o.recHook
+	o.mu.RUnlock()
+	if hook == nil {
+		return
+	}
+	for _, r := range recs {
+		hook(r)
+	}
+}
+
+// Acknowledge records what happened to a recommendation. It fails with
+// domain.ErrRecommendationNotFound for an unknown (or long evicted) ID,
+// ErrInvalidAckStatus for a status that cannot be reported, and
+// ErrRecommendationResolved once the recommendation is final.
+func (o *Optimizer) Acknowledge(id string, status AckStatus, detail string) (Recommendation, error) {
+	switch status {
+	case AckAccepted, AckRejected, AckCompleted, AckFailed:
+	default:
+		return Recommendation{}, fmt.Errorf("acknowledge %s as %q: %w", id, status, domain.ErrInvalidAckStatus)
+	}
+
+	o.mu.Lock()
+	idx, ok := o.recIndex[id]
+	if !ok {
+		o.mu.Unlock()
+		return Recommendation{}, fmt.Errorf("acknowledge %s: %w", id, domain.ErrRecommendationNotFound)
+	}
+	rec := &o.recommendations[idx]
+	if rec.Status.final() || (rec.Status == AckAccepted && status == AckRejected) {
+		st := rec.Status
+		o.mu.Unlock()
+		return Recommendation{}, fmt.Errorf("acknowledge %s as %s: already %s: %w", id, status, st, domain.ErrRecommendationResolved)
+	}
+	now := o.cfg.Now()
+	o.setStatusLocked(rec, status, detail, now)
+	out := *rec
+	o.mu.Unlock()
+
+	o.notify([]Recommendation{out})
+	return out, nil
+}
+
+// setStatusLocked moves rec to status and updates the feedback for it.
+func (o *Optimizer) setStatusLocked(rec *Recommendation, status AckStatus, detail string, now time.Time) {
+	rec.Status, rec.Detail, rec.UpdatedAt = status, detail, now
+	o.applyFeedbackLocked(*rec)
+}
+
+// applyFeedbackLocked updates open recommendations and strikes for rec's
+// current status.
+func (o *Optimizer) applyFeedbackLocked(rec Recommendation) {
+	key := recKey(rec)
+	switch {
+	case rec.Status == AckPending || rec.Status == AckAccepted:
+		o.recOpen[key] = rec.ID
+		return
+	case o.recOpen[key] == rec.ID:
+		delete(o.recOpen, key)
+	}
+
+	if rec.Status == AckCompleted {
+		delete(o.recFeedback, key)
+		return
+	}
+	if !rec.Status.strike() {
+		return
+	}
+	fb, ok := o.recFeedback[key]
+	if !ok {
+		fb = &recFeedback{}
+		o.recFeedback[key] = fb
+	}
+	fb.strikes++
+	factor := maxCooldownFactor
+	if fb.strikes <= 3 {
+		factor = 1 << (fb.strikes - 1)
+	}
+	fb.until = rec.UpdatedAt.Add(time.Duration(factor) * o.cfg.RetryCooldown)
+}
+
+// suppressedLocked reports whether rec is still open or cooling down.
+func (o *Optimizer) suppressedLocked(rec Recommendation, now time.Time) bool {
+	key := recKey(rec)
+	if _, open := o.recOpen[key]; open {
+		return true
+	}
+	fb, ok := o.recFeedback[key]
+	return ok && now.Before(fb.until)
+}
+
+// expireLocked marks recommendations PENDING for longer than AckTimeout as
+// IGNORED and returns them.
+func (o *Optimizer) expireLocked(now time.Time) []Recommendation {
+	var expired []Recommendation
+	for _, id := range o.recOpen {
+		rec := &o.recommendations[o.recIndex[id]]
+		if rec.Status == AckPending && now.Sub(rec.CreatedAt) > o.cfg.AckTimeout {
+			o.setStatusLocked(rec, AckIgnored, "not acknowledged within "+o.cfg.AckTimeout.String(), now)
+			expired = append(expired, *rec)
+		}
+	}
+	return expired
+}
+
+// storeLocked appends rec to the history ring, forgetting the entry it
+// overwrites.
+func (o *Optimizer) storeLocked(rec Recommendation) {
+	if old := o.recommendations[o.recIdx]; old.ID != "" {
+		delete(o.recIndex, old.ID)
+		if key := recKey(old); o.recOpen[key] == old.ID {
+			delete(o.recOpen, key)
+		}
+	}
+	o.recommendations[o.recIdx] = rec
+	if rec.ID != "" {
+		o.recIndex[rec.ID] = o.recIdx
+	}
+	o.recIdx++
+	if o.recIdx >= o.recCap {
+		o.recIdx = 0
+		o.recFull = true
+	}
+}
+
+// RestoreRecommendations reloads persisted recommendations, oldest first,
+// after a restart: they are listed again and their statuses suppress
+// repeats as if they had just been reported. Recommendations without an
+// ID are skipped.
+func (o *Optimizer) RestoreRecommendations(recs []Recommendation) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for _, r := range recs {
+		if r.ID == "" {
+			continue
+		}
+		if _, dup := o.recIndex[r.ID]; dup {
+			continue
+		}
+		if r.Status == "" {
+			r.Status = AckPending
+		}
+		if r.UpdatedAt.IsZero() {
+			r.UpdatedAt = r.CreatedAt
+		}
+		o.storeLocked(r)
+		o.applyFeedbackLocked(r)
+	}
+}
+
+// Recommendation returns a recommendation by ID.
+func (o *Optimizer) Recommendation(id string) (Recommendation, bool) {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	idx, ok := o.recIndex[id]
+	if !ok {
+		return Recommendation{}, false
+	}
+	return o.recommendations[idx], true
+}
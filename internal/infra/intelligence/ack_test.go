@@ -0,0 +1,178 @@
+package intelligence
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
+)
+
+// ackOptimizer returns an optimizer on a settable clock whose data yields
+// one recommendation: MOVE llama-3 from node-B to node-A.
+func ackOptimizer(t *testing.T) (*Optimizer, *time.Time, *[]Recommendation) {
+	t.Helper()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	cfg := testConfig(now)
+	cfg.Now = func() time.Time { return now }
+	cfg.AckTimeout = 24 * time.Hour
+	cfg.RetryCooldown = 48 * time.Hour
+	o := NewOptimizer(cfg)
+	for i := 0; i < 20; i++ {
+		o.RecordRequest("llama-3", "node-A", 20, true)
+	}
+	for i := 0; i < 10; i++ {
+		o.RecordRequest("llama-3", "node-B", 300, false)
+	}
+	var seen []Recommendation
+	o.SetRecommendationHook(func(r Recommendation) { seen = append(seen, r) })
+	return o, &now, &seen
+}
+
+func TestAcknowledge_SuppressesRefusedRecommendations(t *testing.T) {
+	o, now, seen := ackOptimizer(t)
+
+	recs := o.Optimize()
+	if len(recs) != 1 || recs[0].ID == "" || recs[0].Status != AckPending {
+		t.Fatalf("recs = %+v, want one PENDING recommendation with an ID", recs)
+	}
+	if len(o.Optimize()) != 0 {
+		t.Error("an open recommendation was suggested again")
+	}
+
+	rec, err := o.Acknowledge(recs[0].ID, AckRejected, "maintenance window")
+	if err != nil || rec.Status != AckRejected || rec.Detail != "maintenance window" {
+		t.Fatalf("Acknowledge = %+v, %v", rec, err)
+	}
+	if len(*seen) != 2 || (*seen)[1].Status != AckRejected {
+		t.Errorf("hook saw %+v, want PENDING then REJECTED", *seen)
+	}
+
+	// Suppressed for the cooldown, then suggested again; a second refusal
+	// doubles the cooldown.
+	*now = now.Add(47 * time.Hour)
+	if len(o.Optimize()) != 0 {
+		t.Error("suggested again inside the cooldown")
+	}
+	*now = now.Add(2 * time.Hour)
+	again := o.Optimize()
+	if len(again) != 1 || again[0].ID == recs[0].ID {
+		t.Fatalf("after the cooldown recs = %+v, want a new recommendation", again)
+	}
+	if _, err := o.Acknowledge(again[0].ID, AckFailed, "transfer timed out"); err != nil {
+		t.Fatal(err)
+	}
+	*now = now.Add(95 * time.Hour)
+	if len(o.Optimize()) != 0 {
+		t.Error("suggested again inside the doubled cooldown")
+	}
+	*now = now.Add(2 * time.Hour)
+	if len(o.Optimize()) != 1 {
+		t.Error("not suggested after the doubled cooldown")
+	}
+}
+
+func TestAcknowledge_Transitions(t *testing.T) {
+	o, _, _ := ackOptimizer(t)
+	id := o.Optimize()[0].ID
+
+	tests := []struct {
+		id      string
+		status  AckStatus
+		wantErr error
+	}{
+		{"nope", AckAccepted, domain.ErrRecommendationNotFound},
+		{id, AckPending, domain.ErrInvalidAckStatus},
+		{id, AckAccepted, nil},
+		{id, AckRejected, domain.ErrRecommendationResolved}, // already under way
+		{id, AckCompleted, nil},
+		{id, AckFailed, domain.ErrRecommendationResolved},
+	}
+	for _, tt := range tests {
+		_, err := o.Acknowledge(tt.id, tt.status, "")
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("Acknowledge(%s, %s) = %v, want %v", tt.id, tt.status, err, tt.wantErr)
+		}
+	}
+	if rec, ok := o.Recommendation(id); !ok || rec.Status != AckCompleted {
+		t.Errorf("Recommendation = %+v, %v", rec, ok)
+	}
+	// Completed: nothing holds the recommendation back any more.
+	if len(o.Optimize()) != 1 {
+		t.Error("a completed recommendation should not be suppressed")
+	}
+}
+
+func TestOptimize_IgnoredAfterAckTimeout(t *testing.T) {
+	o, now, seen := ackOptimizer(t)
+	id := o.Optimize()[0].ID
+
+	*now = now.Add(25 * time.Hour)
+	if len(o.Optimize()) != 0 {
+		t.Error("an ignored recommendation was suggested again at once")
+	}
+	rec, _ := o.Recommendation(id)
+	if rec.Status != AckIgnored {
+		t.Errorf("status = %s, want IGNORED", rec.Status)
+	}
+	if last := (*seen)[len(*seen)-1]; last.ID != id || last.Status != AckIgnored {
+		t.Errorf("hook last saw %+v", last)
+	}
+	// A late acknowledgement is still taken.
+	if _, err := o.Acknowledge(id, AckCompleted, ""); err != nil {
+		t.Errorf("late Acknowledge: %v", err)
+	}
+}
+
+func TestOptimize_PlaceSkipsRefusedNode(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	cfg := testConfig(now)
+	cfg.Now = func() time.Time { return now }
+	cfg.PlaceMinNodeScore = 0.5
+	o := NewOptimizer(cfg)
+	for i := 0; i < 10; i++ {
+		o.RecordRequest("llama-3", "node-A", 50, true)
+		o.RecordRequest("mistral", "node-B", 50, true)
+		o.RecordRequest("mistral", "node-C", 50, true)
+	}
+
+	places := func() []string {
+		var to []string
+		for _, r := range o.Optimize() {
+			if r.Type == RecommendPlace && r.ModelName == "llama-3" {
+				to = append(to, r.ToNode)
+				if _, err := o.Acknowledge(r.ID, AckRejected, ""); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+		return to
+	}
+	first := places()
+	if len(first) != 1 {
+		t.Fatalf("PLACE llama-3 → %v, want one node", first)
+	}
+	second := places()
+	if len(second) != 1 || second[0] == first[0] {
+		t.Errorf("after rejecting %v, PLACE → %v, want the other node", first, second)
+	}
+}
+
+func TestRestoreRecommendations(t *testing.T) {
+	o, now, _ := ackOptimizer(t)
+	rec := o.Optimize()[0]
+	rec, _ = o.Acknowledge(rec.ID, AckFailed, "disk full")
+
+	// A fresh optimizer restored from the persisted outcome keeps the
+	// recommendation suppressed and listed.
+	restored, _, _ := ackOptimizer(t)
+	restored.cfg.Now = func() time.Time { return *now }
+	restored.RestoreRecommendations([]Recommendation{rec, {ModelName: "no-id"}})
+	if len(restored.Optimize()) != 0 {
+		t.Error("restored failure did not suppress the recommendation")
+	}
+	recent := restored.RecentRecommendations(10)
+	if len(recent) != 1 || recent[0].ID != rec.ID || recent[0].Status != AckFailed {
+		t.Errorf("RecentRecommendations = %+v", recent)
+	}
+}
@@ -0,0 +1,154 @@
+package intelligence
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
+)
+
+// ─── Health Report Anonymization ────────────────────────────────────────────
+// A HealthPattern names its organization, and exact node counts and task
+// volumes fingerprint it almost as well. An Anonymizer sits at the org
+// boundary and rewrites each pattern before it is shared:
+//
+//   - OrgID becomes a keyed pseudonym that rotates every RotationPeriod.
+//     Reports within one period still aggregate per org; reports from
+//     different periods cannot be linked to each other or to the org.
+//   - An org may report at most once per MinReportInterval, so the report
+//     cadence does not leak the org's activity either.
+//   - With Bucket set, NodeCount and TaskVolume are rounded down to the
+//     lower edge of their range, and ReportedAt to the report interval.
+//
+// The key never leaves the anonymizer; without one, a random key is drawn
+// at construction, so pseudonyms also change when the process restarts.
+
+// AnonymizerConfig configures health report anonymization.
+type AnonymizerConfig struct {
+	Key               []byte        // keys the pseudonyms (nil = random)
+	RotationPeriod    time.Duration // pseudonym lifetime (default 24h)
+	MinReportInterval time.Duration // per-org report spacing (default 1h)
+
+	// Bucket coarsens counts to the ranges below. Values under the first
+	// edge become 0; edges must be ascending.
+	Bucket            bool
+	NodeCountBuckets  []int   // default 1, 5, 10, 25, 50, 100, 250, 500, 1000
+	TaskVolumeBuckets []int64 // default powers of ten from 10 to 10^9
+
+	// Now is an injectable clock for testing.
+	Now func() time.Time
+}
+
+// DefaultAnonymizerConfig returns production defaults: rotating pseudonyms,
+// hourly reports, bucketing on.
+func DefaultAnonymizerConfig() AnonymizerConfig {
+	return AnonymizerConfig{
+		RotationPeriod:    24 * time.Hour,
+		MinReportInterval: time.Hour,
+		Bucket:            true,
+		NodeCountBuckets:  []int{1, 5, 10, 25, 50, 100, 250, 500, 1000},
+		TaskVolumeBuckets: []int64{10, 100, 1_000, 10_000, 100_000, 1_000_000, 10_000_000, 100_000_000, 1_000_000_000},
+		Now:               time.Now,
+	}
+}
+
+// Anonymizer rewrites health patterns before they leave the org.
+type Anonymizer struct {
+	cfg AnonymizerConfig
+
+	mu         sync.Mutex
+	lastReport map[string]time.Time // real OrgID → last accepted report
+}
+
+// NewAnonymizer creates an anonymizer. Zero config fields take their
+// defaults; bucket edges default only when Bucket is set.
+func NewAnonymizer(cfg AnonymizerConfig) (*Anonymizer, error) {
+	def := DefaultAnonymizerConfig()
+	if cfg.RotationPeriod <= 0 {
+		cfg.RotationPeriod = def.RotationPeriod
+	}
+	if cfg.MinReportInterval <= 0 {
+		cfg.MinReportInterval = def.MinReportInterval
+	}
+	if cfg.Bucket && len(cfg.NodeCountBuckets) == 0 {
+		cfg.NodeCountBuckets = def.NodeCountBuckets
+	}
+	if cfg.Bucket && len(cfg.TaskVolumeBuckets) == 0 {
+		cfg.TaskVolumeBuckets = def.TaskVolumeBuckets
+	}
+	if !sort.IntsAreSorted(cfg.NodeCountBuckets) ||
+		!sort.SliceIsSorted(cfg.TaskVolumeBuckets, func(i, j int) bool { return cfg.TaskVolumeBuckets[i] < cfg.TaskVolumeBuckets[j] }) {
+		return nil, fmt.Errorf("intelligence: anonymizer bucket edges must be ascending")
+	}
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+	if len(cfg.Key) == 0 {
+		cfg.Key = make([]byte, 32)
+		if _, err := rand.Read(cfg.Key); err != nil {
+			return nil, fmt.Errorf("intelligence: anonymizer key: %w", err)
+		}
+	}
+	return &Anonymizer{cfg: cfg, lastReport: make(map[string]time.Time)}, nil
+}
+
+// Anonymize returns p as it may be shared. It returns
+// domain.ErrHealthReportTooSoon if the org reported less than
+// MinReportInterval ago; that report is dropped, not queued.
+func (a *Anonymizer) Anonymize(p HealthPattern) (HealthPattern, error) {
+	if p.OrgID == "" {
+		return HealthPattern{}, fmt.Errorf("intelligence: health pattern has no org ID")
+	}
+	now := a.cfg.Now()
+
+	a.mu.Lock()
+	if last, ok := a.lastReport[p.OrgID]; ok && now.Sub(last) < a.cfg.MinReportInterval {
+		a.mu.Unlock()
+		return HealthPattern{}, fmt.Errorf("%w: next report from %s", domain.ErrHealthReportTooSoon,
+			last.Add(a.cfg.MinReportInterval).Format(time.RFC3339))
+	}
+	a.lastReport[p.OrgID] = now
+	a.mu.Unlock()
+
+	p.OrgID = a.pseudonym(p.OrgID, now)
+	if p.ReportedAt.IsZero() {
+		p.ReportedAt = now
+	}
+	if a.cfg.Bucket {
+		p.NodeCount = bucketFloor(p.NodeCount, a.cfg.NodeCountBuckets)
+		p.TaskVolume = bucketFloor(p.TaskVolume, a.cfg.TaskVolumeBuckets)
+		p.ReportedAt = p.ReportedAt.Truncate(a.cfg.MinReportInterval)
+	}
+	return p, nil
+}
+
+// pseudonym is HMAC(key, rotation epoch ‖ orgID), so the same org maps to
+// the same pseudonym only within one rotation period.
+func (a *Anonymizer) pseudonym(orgID string, now time.Time) string {
+	var epoch [8]byte
+	binary.BigEndian.PutUint64(epoch[:], uint64(now.UnixNano()/int64(a.cfg.RotationPeriod)))
+	mac := hmac.New(sha256.New, a.cfg.Key)
+	mac.Write(epoch[:])
+	mac.Write([]byte(orgID))
+	return "org-" + hex.EncodeToString(mac.Sum(nil)[:8])
+}
+
+// bucketFloor rounds v down to the largest edge not above it (0 below the
+// first edge).
+func bucketFloor[T int | int64](v T, edges []T) T {
+	var floor T
+	for _, e := range edges {
+		if v < e {
+			break
+		}
+		floor = e
+	}
+	return floor
+}
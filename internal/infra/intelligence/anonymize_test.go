@@ -0,0 +1,86 @@
+package intelligence
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
+)
+
+func TestAnonymize_RotatesPseudonyms(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	a, err := NewAnonymizer(AnonymizerConfig{
+		Key:               []byte("k"),
+		RotationPeriod:    24 * time.Hour,
+		MinReportInterval: time.Minute,
+		Now:               func() time.Time { return now },
+	})
+	if err != nil {
+		t.Fatalf("NewAnonymizer: %v", err)
+	}
+
+	p1, err := a.Anonymize(HealthPattern{OrgID: "acme"})
+	if err != nil {
+		t.Fatalf("Anonymize: %v", err)
+	}
+	other, _ := a.Anonymize(HealthPattern{OrgID: "globex"})
+	now = now.Add(time.Hour)
+	p2, _ := a.Anonymize(HealthPattern{OrgID: "acme"})
+	now = now.Add(24 * time.Hour)
+	p3, _ := a.Anonymize(HealthPattern{OrgID: "acme"})
+
+	if p1.OrgID == "acme" || p1.OrgID == other.OrgID {
+		t.Errorf("pseudonym %q does not hide the org", p1.OrgID)
+	}
+	if p1.OrgID != p2.OrgID {
+		t.Errorf("pseudonym changed within a rotation period: %q → %q", p1.OrgID, p2.OrgID)
+	}
+	if p3.OrgID == p1.OrgID {
+		t.Errorf("pseudonym %q did not rotate", p3.OrgID)
+	}
+}
+
+func TestAnonymize_MinReportInterval(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	a, _ := NewAnonymizer(AnonymizerConfig{MinReportInterval: time.Hour, Now: func() time.Time { return now }})
+
+	if _, err := a.Anonymize(HealthPattern{OrgID: "acme"}); err != nil {
+		t.Fatalf("first report: %v", err)
+	}
+	now = now.Add(30 * time.Minute)
+	if _, err := a.Anonymize(HealthPattern{OrgID: "acme"}); !errors.Is(err, domain.ErrHealthReportTooSoon) {
+		t.Errorf("report after 30m: err = %v, want ErrHealthReportTooSoon", err)
+	}
+	if _, err := a.Anonymize(HealthPattern{OrgID: "globex"}); err != nil {
+		t.Errorf("other org: %v", err)
+	}
+	now = now.Add(30 * time.Minute)
+	if _, err := a.Anonymize(HealthPattern{OrgID: "acme"}); err != nil {
+		t.Errorf("report after 1h: %v", err)
+	}
+}
+
+func TestAnonymize_Buckets(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 34, 56, 0, time.UTC)
+	p := HealthPattern{OrgID: "acme", NodeCount: 37, TaskVolume: 4_321, ReportedAt: now}
+
+	plain, _ := NewAnonymizer(AnonymizerConfig{Now: func() time.Time { return now }})
+	got, _ := plain.Anonymize(p)
+	if got.NodeCount != 37 || got.TaskVolume != 4_321 || !got.ReportedAt.Equal(now) {
+		t.Errorf("unbucketed = %+v, want counts unchanged", got)
+	}
+
+	bucketed, _ := NewAnonymizer(AnonymizerConfig{Bucket: true, Now: func() time.Time { return now }})
+	got, _ = bucketed.Anonymize(p)
+	if got.NodeCount != 25 || got.TaskVolume != 1_000 {
+		t.Errorf("bucketed counts = %d nodes, %d tasks, want 25 and 1000", got.NodeCount, got.TaskVolume)
+	}
+	if want := now.Truncate(time.Hour); !got.ReportedAt.Equal(want) {
+		t.Errorf("bucketed ReportedAt = %v, want %v", got.ReportedAt, want)
+	}
+
+	if _, err := NewAnonymizer(AnonymizerConfig{Bucket: true, NodeCountBuckets: []int{10, 5}}); err == nil {
+		t.Error("descending bucket edges accepted")
+	}
+}
@@ -0,0 +1,98 @@
+package intelligence
+
+// ─── Node Capacity ──────────────────────────────────────────────────────────
+// Placement only helps if the destination can hold the model. Each node's
+// disk and VRAM budget is registered with SetNodeCapacity, each model's
+// footprint with SetModelFootprint. Optimize then checks every
+// recommendation with a destination:
+//
+//   - the destination's disk must hold the model on top of the models it
+//     already hosts and those placed on it earlier in the same cycle
+//   - the model must fit in the destination's VRAM
+//
+// A recommendation that does not fit is dropped (for PLACE, the next-best
+// node is tried). One that fits is down-ranked by the headroom it leaves:
+// its score is scaled from 1× with the budgets untouched down to 0.5× when
+// it would fill them. Nodes or models without registered numbers are not
+// checked.
+
+// NodeCapacity is a node's storage and VRAM budget.
+type NodeCapacity struct {
+	DiskBytes int64   // model storage budget (0 = unknown)
+	VRAMGB    float64 // total GPU memory (0 = unknown)
+}
+
+// ModelFootprint is what hosting a model takes.
+type ModelFootprint struct {
+	DiskBytes int64   // size on disk (0 = unknown)
+	VRAMGB    float64 // GPU memory to load it fully (0 = unknown)
+}
+
+// SetNodeCapacity registers a node's disk and VRAM budget. Zero values
+// leave that budget unchecked.
+func (o *Optimizer) SetNodeCapacity(nodeID string, diskBytes int64, vramGB float64) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if diskBytes <= 0 && vramGB <= 0 {
+		delete(o.capacity, nodeID)
+		return
+	}
+	o.capacity[nodeID] = NodeCapacity{DiskBytes: max(diskBytes, 0), VRAMGB: max(vramGB, 0)}
+}
+
+// NodeCapacity returns a node's registered budget.
+func (o *Optimizer) NodeCapacity(nodeID string) (NodeCapacity, bool) {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	c, ok := o.capacity[nodeID]
+	return c, ok
+}
+
+// SetModelFootprint registers what hosting a model takes.
+func (o *Optimizer) SetModelFootprint(modelName string, f ModelFootprint) {
+	sh := o.shard(modelName)
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	sh.entryLocked(modelName).footprint = f
+}
+
+// capacityCheck tracks disk use per node through one optimization cycle.
+type capacityCheck struct {
+	caps     map[string]NodeCapacity
+	diskUsed map[string]int64 // hosted models plus this cycle's placements
+	rejected int
+}
+
+// fit reports whether f fits on nodeID, given whether the node already
+// hosts the model, and the factor to scale the recommendation's score by.
+func (c *capacityCheck) fit(nodeID string, f ModelFootprint, hosted bool) (float64, bool) {
+	cp, ok := c.caps[nodeID]
+	if !ok {
+		return 1, true
+	}
+	headroom := 1.0
+	if cp.DiskBytes > 0 && f.DiskBytes > 0 {
+		used := c.diskUsed[nodeID]
+		if !hosted {
+			used += f.DiskBytes
+		}
+		if used > cp.DiskBytes {
+			c.rejected++
+			return 0, false
+		}
+		headroom = min(headroom, 1-float64(used)/float64(cp.DiskBytes))
+	}
+	if cp.VRAMGB > 0 && f.VRAMGB > 0 {
+		if f.VRAMGB > cp.VRAMGB {
+			c.rejected++
+			return 0, false
+		}
+		headroom = min(headroom, 1-f.VRAMGB/cp.VRAMGB)
+	}
+	return 0.5 + 0.5*headroom, true
+}
+
+// commit records a model placed on nodeID in this cycle.
+func (c *capacityCheck) commit(nodeID string, f ModelFootprint) {
+	c.diskUsed[nodeID] += f.DiskBytes
+}
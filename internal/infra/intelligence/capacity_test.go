@@ -0,0 +1,107 @@
+package intelligence
+
+import (
+	"testing"
+	"time"
+)
+
+// placeOptimizer returns an optimizer on which llama-3 (hosted on node-A)
+// is popular enough to be PLACEd on node-B or node-C, which host mistral.
+func placeOptimizer(t *testing.T) *Optimizer {
+	t.Helper()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	cfg := testConfig(now)
+	cfg.Now = func() time.Time { return now }
+	cfg.PlaceMinNodeScore = 0.5
+	o := NewOptimizer(cfg)
+	for i := 0; i < 10; i++ {
+		o.RecordRequest("llama-3", "node-A", 50, true)
+		o.RecordRequest("mistral", "node-B", 50, true)
+		o.RecordRequest("mistral", "node-C", 50, true)
+	}
+	o.SetModelFootprint("llama-3", ModelFootprint{DiskBytes: 8 << 30, VRAMGB: 6})
+	o.SetModelFootprint("mistral", ModelFootprint{DiskBytes: 4 << 30, VRAMGB: 5})
+	return o
+}
+
+// placements returns the PLACE recommendations for llama-3 by destination.
+func placements(recs []Recommendation) map[string]Recommendation {
+	out := make(map[string]Recommendation)
+	for _, r := range recs {
+		if r.Type == RecommendPlace && r.ModelName == "llama-3" {
+			out[r.ToNode] = r
+		}
+	}
+	return out
+}
+
+func TestOptimize_PlaceRespectsCapacity(t *testing.T) {
+	unchecked := placements(placeOptimizer(t).Optimize())
+	if len(unchecked) != 1 {
+		t.Fatalf("without capacities PLACE = %+v, want one", unchecked)
+	}
+	var first string
+	for id := range unchecked {
+		first = id
+	}
+	other := map[string]string{"node-B": "node-C", "node-C": "node-B"}[first]
+
+	tests := []struct {
+		name   string
+		disk   int64
+		vramGB float64
+		want   string
+	}{
+		{"disk full", 10 << 30, 0, other}, // 4 GiB hosted + 8 GiB > 10 GiB
+		{"VRAM too small", 0, 4, other},   // 6 GB model on a 4 GB card
+		{"fits", 100 << 30, 24, first},    // plenty of room
+		{"unknown budget", 0, 0, first},   // not checked
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := placeOptimizer(t)
+			o.SetNodeCapacity(first, tt.disk, tt.vramGB)
+			got := placements(o.Optimize())
+			if _, ok := got[tt.want]; !ok || len(got) != 1 {
+				t.Errorf("PLACE → %v, want %s", got, tt.want)
+			}
+			if rejected := o.Stats().CapacityRejected; (tt.want == other) != (rejected > 0) {
+				t.Errorf("CapacityRejected = %d", rejected)
+			}
+		})
+	}
+}
+
+func TestOptimize_CapacityDownRanks(t *testing.T) {
+	roomy, tight := placeOptimizer(t), placeOptimizer(t)
+	for _, id := range []string{"node-B", "node-C"} {
+		roomy.SetNodeCapacity(id, 1<<40, 80)
+		tight.SetNodeCapacity(id, 13<<30, 7) // 12 of 13 GiB used, 6 of 7 GB VRAM
+	}
+	r, tt := placements(roomy.Optimize()), placements(tight.Optimize())
+	if len(r) != 1 || len(tt) != 1 {
+		t.Fatalf("PLACE roomy = %v, tight = %v", r, tt)
+	}
+	var rs, ts float64
+	for _, rec := range r {
+		rs = rec.Score
+	}
+	for _, rec := range tt {
+		ts = rec.Score
+	}
+	if ts >= rs || ts < rs*0.5 {
+		t.Errorf("tight score %.3f, roomy %.3f: want down-ranked, at most halved", ts, rs)
+	}
+}
+
+func TestSetNodeCapacity(t *testing.T) {
+	o := NewOptimizer(DefaultConfig())
+	o.SetNodeCapacity("node-A", 50<<30, 24)
+	if c, ok := o.NodeCapacity("node-A"); !ok || c.DiskBytes != 50<<30 || c.VRAMGB != 24 {
+		t.Errorf("NodeCapacity = %+v, %v", c, ok)
+	}
+	o.SetNodeCapacity("node-A", 0, 0)
+	if _, ok := o.NodeCapacity("node-A"); ok {
+		t.Error("zero budgets should unregister the node")
+	}
+}
@@ -0,0 +1,337 @@
+package intelligence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ─── Placement Executor ─────────────────────────────────────────────────────
+// Applying a whole optimization cycle at once can saturate the network: 50
+// MOVE recommendations are 50 model transfers competing for bandwidth. The
+// executor applies recommendations under a budget and in stages:
+//
+//   - at most MaxConcurrent transfers run at once, across every rollout
+//   - at most MaxBytesPerHour are moved in any sliding hour; a
+//     recommendation that would exceed it is deferred to a later rollout
+//   - recommendations go out best-first, StageSize at a time; after each
+//     stage the executor waits Settle, re-measures, and aborts the rollout
+//     if the metric regressed by more than RegressionTolerance
+//
+// EVICT moves no bytes and is only subject to the concurrency limit.
+
+// ExecutorConfig configures the placement executor.
+type ExecutorConfig struct {
+	MaxConcurrent       int           // transfers in flight (default 4)
+	MaxBytesPerHour     int64         // bytes moved per sliding hour (default 50 GiB)
+	StageSize           int           // recommendations per stage (default 5)
+	Settle              time.Duration // wait after a stage before measuring (default 5m)
+	RegressionTolerance float64       // abort when the metric worsens by more than this fraction (default 0.10)
+
+	// Now is an injectable clock for testing.
+	Now func() time.Time
+}
+
+// DefaultExecutorConfig returns production defaults.
+func DefaultExecutorConfig() ExecutorConfig {
+	return ExecutorConfig{
+		MaxConcurrent:       4,
+		MaxBytesPerHour:     50 << 30,
+		StageSize:           5,
+		Settle:              5 * time.Minute,
+		RegressionTolerance: 0.10,
+		Now:                 time.Now,
+	}
+}
+
+// ExecutorHooks connect the executor to the rest of the node.
+type ExecutorHooks struct {
+	// Apply carries out one recommendation (required).
+	Apply func(ctx context.Context, rec Recommendation) error
+	// Size returns the bytes a transfer of model moves (nil = 0).
+	Size func(model string) int64
+	// Measure returns the health metric a rollout must not regress, lower
+	// is better — e.g. p95 inference latency (nil = never abort).
+	Measure func() float64
+	// Acknowledge reports each applied recommendation as COMPLETED or
+	// FAILED, e.g. to Optimizer.Acknowledge (nil = not reported).
+	Acknowledge func(rec Recommendation, status AckStatus, detail string)
+}
+
+// StageResult records one stage of a rollout.
+type StageResult struct {
+	Applied []Recommendation `json:"applied"`
+	Failed  []Recommendation `json:"failed,omitempty"`
+	Before  float64          `json:"before"` // metric when the stage started
+	After   float64          `json:"after"`  // metric after Settle
+}
+
+// Rollout is the outcome of one Execute call.
+type Rollout struct {
+	Stages   []StageResult    `json:"stages"`
+	Deferred []Recommendation `json:"deferred,omitempty"` // over budget or not reached after an abort
+	Aborted  bool             `json:"aborted"`
+	Reason   string           `json:"reason,omitempty"` // why the rollout aborted
+}
+
+// ExecutorStats is a point-in-time view of the executor.
+type ExecutorStats struct {
+	InFlight      int   `json:"in_flight"`
+	BytesLastHour int64 `json:"bytes_last_hour"`
+	Applied       int64 `json:"applied"`
+	Failed        int64 `json:"failed"`
+	Deferred      int64 `json:"deferred"`
+	Aborts        int64 `json:"aborts"`
+}
+
+// transfer is one budgeted transfer in the sliding hour.
+type transfer struct {
+	at    time.Time
+	bytes int64
+}
+
+// Executor applies placement recommendations within a transfer budget.
+type Executor struct {
+	cfg   ExecutorConfig
+	hooks ExecutorHooks
+	slots chan struct{} // one token per in-flight transfer
+
+	mu        sync.Mutex
+	transfers []transfer // oldest first, pruned to the last hour
+	applied   int64
+	failed    int64
+	deferred  int64
+	aborts    int64
+}
+
+// NewExecutor creates a placement executor. Zero config fields take their
+// defaults.
+func NewExecutor(cfg ExecutorConfig, hooks ExecutorHooks) *Executor {
+	def := DefaultExecutorConfig()
+	if cfg.MaxConcurrent <= 0 {
+		cfg.MaxConcurrent = def.MaxConcurrent
+	}
+	if cfg.MaxBytesPerHour <= 0 {
+		cfg.MaxBytesPerHour = def.MaxBytesPerHour
+	}
+	if cfg.StageSize <= 0 {
+		cfg.StageSize = def.StageSize
+	}
+	if cfg.Settle <= 0 {
+		cfg.Settle = def.Settle
+	}
+	if cfg.RegressionTolerance <= 0 {
+		cfg.RegressionTolerance = def.RegressionTolerance
+	}
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+	return &Executor{
+		cfg:   cfg,
+		hooks: hooks,
+		slots: make(chan struct{}, cfg.MaxConcurrent),
+	}
+}
+
+// Execute rolls out recs in stages, best score first. It returns the
+// rollout so far together with ctx's error if ctx ends mid-rollout.
+func (e *Executor) Execute(ctx context.Context, recs []Recommendation) (Rollout, error) {
+	if e.hooks.Apply == nil {
+		return Rollout{}, fmt.Errorf("intelligence: executor has no Apply hook")
+	}
+	queue := make([]Recommendation, len(recs))
+	copy(queue, recs)
+	sort.SliceStable(queue, func(i, j int) bool { return queue[i].Score > queue[j].Score })
+
+	var out Rollout
+	for len(queue) > 0 {
+		// Fill the stage with whatever fits the byte budget
+		var stage []Recommendation
+		var rest []Recommendation
+		for i, rec := range queue {
+			if len(stage) == e.cfg.StageSize {
+				rest = append(rest, queue[i:]...)
+				break
+			}
+			if e.reserve(e.bytesFor(rec)) {
+				stage = append(stage, rec)
+			} else {
+				out.Deferred = append(out.Deferred, rec)
+			}
+		}
+		queue = rest
+		if len(stage) == 0 {
+			break
+		}
+
+		res := StageResult{Before: e.measure()}
+		res.Applied, res.Failed = e.applyStage(ctx, stage)
+		if err := ctx.Err(); err != nil {
+			out.Stages = append(out.Stages, res)
+			out.Deferred = append(out.Deferred, queue...)
+			e.count(out.Deferred)
+			return out, err
+		}
+
+		if e.hooks.Measure != nil {
+			timer := time.NewTimer(e.cfg.Settle)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				out.Stages = append(out.Stages, res)
+				out.Deferred = append(out.Deferred, queue...)
+				e.count(out.Deferred)
+				return out, ctx.Err()
+			case <-timer.C:
+			}
+			res.After = e.measure()
+		}
+		out.Stages = append(out.Stages, res)
+
+		if e.regressed(res) {
+			out.Aborted = true
+			out.Reason = fmt.Sprintf("metric regressed from %.3f to %.3f after stage %d", res.Before, res.After, len(out.Stages))
+			out.Deferred = append(out.Deferred, queue...)
+			e.mu.Lock()
+			e.aborts++
+			e.mu.Unlock()
+			break
+		}
+	}
+	e.count(out.Deferred)
+	return out, nil
+}
+
+// applyStage runs one stage's recommendations, at most MaxConcurrent at a
+// time across all rollouts.
+func (e *Executor) applyStage(ctx context.Context, stage []Recommendation) (applied, failed []Recommendation) {
+	var (
+		wg  sync.WaitGroup
+		mu  sync.Mutex
+		ok  = make([]bool, len(stage))
+		err = make([]error, len(stage))
+	)
+	for i, rec := range stage {
+		select {
+		case e.slots <- struct{}{}:
+		case <-ctx.Done():
+			err[i] = ctx.Err()
+			continue
+		}
+		wg.Add(1)
+		go func(i int, rec Recommendation) {
+			defer wg.Done()
+			defer func() { <-e.slots }()
+			applyErr := e.hooks.Apply(ctx, rec)
+			mu.Lock()
+			ok[i], err[i] = applyErr == nil, applyErr
+			mu.Unlock()
+		}(i, rec)
+	}
+	wg.Wait()
+
+	e.mu.Lock()
+	for i, rec := range stage {
+		if ok[i] {
+			applied = append(applied, rec)
+			e.applied++
+		} else if !errors.Is(err[i], context.Canceled) && !errors.Is(err[i], context.DeadlineExceeded) {
+			failed = append(failed, rec)
+			e.failed++
+		}
+	}
+	e.mu.Unlock()
+
+	if e.hooks.Acknowledge != nil {
+		for i, rec := range stage {
+			switch {
+			case ok[i]:
+				e.hooks.Acknowledge(rec, AckCompleted, "")
+			case !errors.Is(err[i], context.Canceled) && !errors.Is(err[i], context.DeadlineExceeded):
+				e.hooks.Acknowledge(rec, AckFailed, err[i].Error())
+			}
+		}
+	}
+	return applied, failed
+}
+
+// reserve books n bytes against the sliding-hour budget, reporting false
+// if they do not fit. Reserved bytes count even if the transfer fails —
+// a failed transfer may still have moved most of the model.
+func (e *Executor) reserve(n int64) bool {
+	if n <= 0 {
+		return true
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := e.cfg.Now()
+	used := e.pruneLocked(now)
+	if used+n > e.cfg.MaxBytesPerHour {
+		return false
+	}
+	e.transfers = append(e.transfers, transfer{at: now, bytes: n})
+	return true
+}
+
+// pruneLocked drops transfers older than an hour and returns the bytes left.
+func (e *Executor) pruneLocked(now time.Time) int64 {
+	cutoff := now.Add(-time.Hour)
+	n := 0
+	for n < len(e.transfers) && !e.transfers[n].at.After(cutoff) {
+		n++
+	}
+	e.transfers = e.transfers[n:]
+	var used int64
+	for _, t := range e.transfers {
+		used += t.bytes
+	}
+	return used
+}
+
+// bytesFor returns the bytes a recommendation moves.
+func (e *Executor) bytesFor(rec Recommendation) int64 {
+	if rec.Type == RecommendEvict || e.hooks.Size == nil {
+		return 0
+	}
+	return e.hooks.Size(rec.ModelName)
+}
+
+func (e *Executor) measure() float64 {
+	if e.hooks.Measure == nil {
+		return 0
+	}
+	return e.hooks.Measure()
+}
+
+// regressed reports whether a stage made the metric worse than tolerated.
+func (e *Executor) regressed(res StageResult) bool {
+	if e.hooks.Measure == nil || res.Before <= 0 {
+		return false
+	}
+	return res.After > res.Before*(1+e.cfg.RegressionTolerance)
+}
+
+// count adds deferred recommendations to the stats.
+func (e *Executor) count(deferred []Recommendation) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.deferred += int64(len(deferred))
+}
+
+// Stats returns executor counters and the current budget usage.
+func (e *Executor) Stats() ExecutorStats {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return ExecutorStats{
+		InFlight:      len(e.slots),
+		BytesLastHour: e.pruneLocked(e.cfg.Now()),
+		Applied:       e.applied,
+		Failed:        e.failed,
+		Deferred:      e.deferred,
+		Aborts:        e.aborts,
+	}
+}
@@ -0,0 +1,166 @@
+package intelligence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func moves(n int) []Recommendation {
+	recs := make([]Recommendation, n)
+	for i := range recs {
+		recs[i] = Recommendation{
+			Type:      RecommendMove,
+			ModelName: fmt.Sprintf("model-%02d", i),
+			Score:     float64(i) / float64(n),
+		}
+	}
+	return recs
+}
+
+func TestExecutor_ConcurrencyLimit(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	ex := NewExecutor(ExecutorConfig{MaxConcurrent: 2, StageSize: 10}, ExecutorHooks{
+		Apply: func(ctx context.Context, rec Recommendation) error {
+			n := inFlight.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			inFlight.Add(-1)
+			return nil
+		},
+	})
+
+	out, err := ex.Execute(context.Background(), moves(8))
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if len(out.Stages) != 1 || len(out.Stages[0].Applied) != 8 {
+		t.Fatalf("rollout = %+v", out)
+	}
+	if p := peak.Load(); p > 2 {
+		t.Errorf("peak concurrency = %d, want <= 2", p)
+	}
+}
+
+func TestExecutor_ByteBudget(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	var mu sync.Mutex
+	var applied []string
+	ex := NewExecutor(ExecutorConfig{MaxBytesPerHour: 250, Now: func() time.Time { return now }}, ExecutorHooks{
+		Apply: func(ctx context.Context, rec Recommendation) error {
+			mu.Lock()
+			applied = append(applied, rec.ModelName)
+			mu.Unlock()
+			return nil
+		},
+		Size: func(string) int64 { return 100 },
+	})
+
+	recs := append(moves(4), Recommendation{Type: RecommendEvict, ModelName: "stale"})
+	out, _ := ex.Execute(context.Background(), recs)
+	// Two 100-byte moves fit in 250; evictions are free
+	if len(applied) != 3 || len(out.Deferred) != 2 {
+		t.Fatalf("applied %v, deferred %d", applied, len(out.Deferred))
+	}
+	if out.Deferred[0].ModelName != "model-01" {
+		t.Errorf("lowest scores should be deferred, got %+v", out.Deferred)
+	}
+	if s := ex.Stats(); s.BytesLastHour != 200 || s.Deferred != 2 {
+		t.Errorf("stats = %+v", s)
+	}
+
+	// An hour later the budget is free again
+	now = now.Add(time.Hour)
+	out, _ = ex.Execute(context.Background(), out.Deferred)
+	if len(out.Deferred) != 0 {
+		t.Errorf("deferred after the window = %d, want 0", len(out.Deferred))
+	}
+}
+
+func TestExecutor_AbortOnRegression(t *testing.T) {
+	var applied atomic.Int32
+	var latency atomic.Int64 // Apply runs concurrently within a stage
+	latency.Store(100)
+	ex := NewExecutor(ExecutorConfig{StageSize: 2, Settle: time.Millisecond}, ExecutorHooks{
+		Apply: func(ctx context.Context, rec Recommendation) error {
+			if applied.Add(1) > 2 {
+				latency.Store(150) // the second stage hurts
+			}
+			return nil
+		},
+		Measure: func() float64 { return float64(latency.Load()) },
+	})
+
+	out, err := ex.Execute(context.Background(), moves(7))
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if !out.Aborted || len(out.Stages) != 2 || len(out.Deferred) != 3 {
+		t.Fatalf("rollout = %+v", out)
+	}
+	if out.Stages[1].Before != 100 || out.Stages[1].After != 150 {
+		t.Errorf("stage 2 = %+v", out.Stages[1])
+	}
+	if s := ex.Stats(); s.Aborts != 1 || s.Applied != 4 {
+		t.Errorf("stats = %+v", s)
+	}
+}
+
+func TestExecutor_FailuresAndCancel(t *testing.T) {
+	ex := NewExecutor(ExecutorConfig{StageSize: 2, Settle: time.Hour}, ExecutorHooks{
+		Apply: func(ctx context.Context, rec Recommendation) error {
+			if rec.ModelName == "model-03" {
+				return errors.New("transfer failed")
+			}
+			return nil
+		},
+		Measure: func() float64 { return 1 },
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	out, err := ex.Execute(ctx, moves(4))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want deadline exceeded while settling", err)
+	}
+	if len(out.Stages) != 1 || len(out.Stages[0].Failed) != 1 || len(out.Deferred) != 2 {
+		t.Errorf("rollout = %+v", out)
+	}
+
+	if _, err := NewExecutor(ExecutorConfig{}, ExecutorHooks{}).Execute(context.Background(), moves(1)); err == nil {
+		t.Error("expected an error without an Apply hook")
+	}
+}
+
+func TestExecutor_AcknowledgesOutcomes(t *testing.T) {
+	var mu sync.Mutex
+	got := map[string]AckStatus{}
+	ex := NewExecutor(ExecutorConfig{}, ExecutorHooks{
+		Apply: func(ctx context.Context, rec Recommendation) error {
+			if rec.ModelName == "model-01" {
+				return errors.New("no space")
+			}
+			return nil
+		},
+		Acknowledge: func(rec Recommendation, status AckStatus, detail string) {
+			mu.Lock()
+			got[rec.ModelName] = status
+			mu.Unlock()
+		},
+	})
+	if _, err := ex.Execute(context.Background(), moves(2)); err != nil {
+		t.Fatal(err)
+	}
+	if got["model-00"] != AckCompleted || got["model-01"] != AckFailed {
+		t.Errorf("acknowledged %v", got)
+	}
+}
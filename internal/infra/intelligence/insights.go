@@ -0,0 +1,161 @@
+package intelligence
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
+)
+
+// ─── Health Insight Reports ─────────────────────────────────────────────────
+// Organizations contribute health patterns; what they get back is a
+// comparison with the rest of the network. PublishHealthInsights aggregates
+// the patterns, compares each org's latest one with the network medians —
+// "your failure rate is 2.1× the network median for DISK_FULL" — and hands
+// the reports to the hook installed with SetHealthReportHook, which
+// delivers them to the orgs.
+//
+// A median over two orgs is the other org's figure, so no reports are
+// produced until MinInsightOrgs have reported — and none at all in
+// aggregate-only mode (HealthPrivacyConfig.AggregateOnly).
+
+// MinInsightOrgs is how many orgs must report before any is compared with
+// the network.
+const MinInsightOrgs = 3
+
+// OrgHealthReport compares one org's latest health pattern with the
+// network.
+type OrgHealthReport struct {
+	OrgID             string             `json:"org_id"`
+	FailureRate       float64            `json:"failure_rate"`
+	MedianFailureRate float64            `json:"median_failure_rate"`
+	FailureRatio      float64            `json:"failure_ratio"` // FailureRate / median (0 if the median is 0)
+	MTTRSeconds       float64            `json:"mttr_seconds"`
+	MedianMTTRSeconds float64            `json:"median_mttr_seconds"`
+	MTTRRatio         float64            `json:"mttr_ratio"`
+	TopFailureType    domain.FailureType `json:"top_failure_type"`
+	Orgs              int                `json:"orgs"` // orgs compared against
+	Summary           string             `json:"summary"`
+	GeneratedAt       time.Time          `json:"generated_at"`
+}
+
+// SetHealthReportHook installs fn to receive the network insight and the
+// per-org reports each time PublishHealthInsights runs. fn runs without
+// the optimizer lock held.
+func (o *Optimizer) SetHealthReportHook(fn func(HealthInsight, []OrgHealthReport)) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.reportHook = fn
+}
+
+// PublishHealthInsights aggregates the reported patterns, builds the
+// per-org reports and passes both to the report hook.
+func (o *Optimizer) PublishHealthInsights() (HealthInsight, []OrgHealthReport) {
+	insight := o.AggregateHealthInsights()
+	reports := o.OrgHealthReports()
+
+	o.mu.RLock()
+	hook := o.reportHook
+	o.mu.RUnlock()
+	if hook != nil {
+		hook(insight, reports)
+	}
+	return insight, reports
+}
+
+// OrgHealthReports compares each org's latest pattern within HealthMaxAge
+// with the network medians, ordered by org ID. It returns nil below
+// MinInsightOrgs and in aggregate-only mode.
+func (o *Optimizer) OrgHealthReports() []OrgHealthReport {
+	o.mu.RLock()
+	if o.cfg.HealthPrivacy.AggregateOnly {
+		o.mu.RUnlock()
+		return nil
+	}
+	noisy := o.cfg.HealthPrivacy.Epsilon > 0
+	patterns, _ := o.livePatternsLocked()
+	latest := make(map[string]HealthPattern)
+	for _, p := range patterns {
+		if prev, ok := latest[p.OrgID]; !ok || !p.ReportedAt.Before(prev.ReportedAt) {
+			latest[p.OrgID] = p
+		}
+	}
+	now := o.cfg.Now()
+	o.mu.RUnlock()
+
+	if len(latest) < MinInsightOrgs {
+		return nil
+	}
+	failRates := make([]float64, 0, len(latest))
+	mttrs := make([]float64, 0, len(latest))
+	for org, p := range latest {
+		if noisy {
+			// Noisy figures can leave their range; compare them clamped
+			p.AvgFailureRate = min(max(p.AvgFailureRate, 0), 1)
+			p.AvgMTTR = max(p.AvgMTTR, 0)
+			latest[org] = p
+		}
+		failRates = append(failRates, p.AvgFailureRate)
+		mttrs = append(mttrs, p.AvgMTTR)
+	}
+	medFail, medMTTR := median(failRates), median(mttrs)
+
+	reports := make([]OrgHealthReport, 0, len(latest))
+	for org, p := range latest {
+		r := OrgHealthReport{
+			OrgID:             org,
+			FailureRate:       p.AvgFailureRate,
+			MedianFailureRate: medFail,
+			FailureRatio:      ratio(p.AvgFailureRate, medFail),
+			MTTRSeconds:       p.AvgMTTR,
+			MedianMTTRSeconds: medMTTR,
+			MTTRRatio:         ratio(p.AvgMTTR, medMTTR),
+			TopFailureType:    p.TopFailureType,
+			Orgs:              len(latest),
+			GeneratedAt:       now,
+		}
+		r.Summary = r.summary()
+		reports = append(reports, r)
+	}
+	sort.Slice(reports, func(i, j int) bool { return reports[i].OrgID < reports[j].OrgID })
+	return reports
+}
+
+// summary is the one-line comparison shown to the org.
+func (r OrgHealthReport) summary() string {
+	var s string
+	switch {
+	case r.MedianFailureRate == 0 && r.FailureRate == 0:
+		s = "your failure rate matches the network median of 0"
+	case r.MedianFailureRate == 0:
+		s = fmt.Sprintf("your failure rate is %.1f%% while the network median is 0", r.FailureRate*100)
+	default:
+		s = fmt.Sprintf("your failure rate is %.1f× the network median", r.FailureRatio)
+	}
+	if r.TopFailureType != "" {
+		s += " for " + string(r.TopFailureType)
+	}
+	if r.MTTRRatio > 0 {
+		s += fmt.Sprintf("; recovery takes %.1f× the median", r.MTTRRatio)
+	}
+	return s
+}
+
+// median returns the median of xs, reordering them.
+func median(xs []float64) float64 {
+	sort.Float64s(xs)
+	n := len(xs)
+	if n%2 == 1 {
+		return xs[n/2]
+	}
+	return (xs[n/2-1] + xs[n/2]) / 2
+}
+
+// ratio returns v / base, or 0 if base is 0.
+func ratio(v, base float64) float64 {
+	if base == 0 {
+		return 0
+	}
+	return v / base
+}
@@ -12,7 +12,8 @@
 //   - Model Placement: popular models should live on fast, well-connected nodes.
 //     Unpopular models can live on slower nodes or be evicted entirely. The
 //     Optimizer periodically recomputes an ideal placement and emits
-//     recommendations (move model X from node A to node B).
+//     recommendations (place model X on node A, evict it from node B, or
+//     move it from node B to node A).
 //
 //   - Affinity Score: a per-{model, node} score combining cache hit rate,
 //     inference latency, GPU VRAM fit, and request frequency. Higher affinity
@@ -32,9 +33,14 @@
 package intelligence
 
 import (
+	"hash/fnv"
 	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
+	"github.com/tutu-network/tutu/internal/infra/dsa"
 )
 
 // ─── Configuration ──────────────────────────────────────────────────────────
@@ -55,11 +61,43 @@ type Config struct {
 	// MaxRecommendations caps how many placement moves are recommended per cycle.
 	MaxRecommendations int
 
+	// PlaceTopModels is how many of the most-requested models are popular
+	// enough to be PLACEd on nodes that don't host them yet, and
+	// PlaceMinNodeScore the node score (mean affinity across the models a
+	// node hosts) a node needs to receive one. Above 1 disables PLACE.
+	PlaceTopModels    int
+	PlaceMinNodeScore float64
+
+	// A host whose affinity for a model is below EvictMaxAffinity, or whose
+	// VRAM fit for it is at least EvictVRAMPressure, is told to EVICT it —
+	// as long as another node keeps hosting the model.
+	EvictMaxAffinity  float64
+	EvictVRAMPressure float64
+
+	// AckTimeout is how long a recommendation may stay PENDING before it
+	// counts as IGNORED, and RetryCooldown how long a REJECTED, FAILED or
+	// IGNORED one is not suggested again (doubling per repeat; see ack.go).
+	AckTimeout    time.Duration
+	RetryCooldown time.Duration
+
 	// MaxRetirementCandidates caps how many models are flagged for retirement per cycle.
 	MaxRetirementCandidates int
 
-	// HealthHistorySize caps the federated health pattern history.
+	// HealthHistorySize caps the federated health pattern history, and
+	// HealthMaxAge drops patterns reported longer ago than that from the
+	// insights.
 	HealthHistorySize int
+	HealthMaxAge      time.Duration
+
+	// HealthPrivacy adds differential privacy noise to health patterns as
+	// they are reported (see privacy.go).
+	HealthPrivacy HealthPrivacyConfig
+
+	// DedupWindow is how long RecordRequestWithKey remembers an idempotency
+	// key, and DedupMaxKeys how many it remembers at once. A replay inside
+	// the window is dropped.
+	DedupWindow  time.Duration
+	DedupMaxKeys int
 
 	// Now is an injectable clock for testing.
 	Now func() time.Time
@@ -72,8 +110,17 @@ func DefaultConfig() Config {
 		PlacementInterval:       7 * 24 * time.Hour, // weekly
 		MinRequestsForPlacement: 10,
 		MaxRecommendations:      50,
+		PlaceTopModels:          10,
+		PlaceMinNodeScore:       0.7,
+		EvictMaxAffinity:        0.1,
+		EvictVRAMPressure:       0.95,
+		AckTimeout:              7 * 24 * time.Hour,  // one placement cycle
+		RetryCooldown:           28 * 24 * time.Hour, // four cycles
 		MaxRetirementCandidates: 100,
 		HealthHistorySize:       10_000,
+		HealthMaxAge:            30 * 24 * time.Hour,
+		DedupWindow:             10 * time.Minute,
+		DedupMaxKeys:            100_000,
 		Now:                     time.Now,
 	}
 }
@@ -100,6 +147,21 @@ type NodeModelAffinity struct {
 	RequestCount  int64   // requests served on this node
 	VRAMFitScore  float64 // 0..1 — how well the model fits in available VRAM
 	AffinityScore float64 // computed composite score 0..1
+	SLOCompliance float64 // 0..1 — share of requests meeting the model's latency SLO (0 without one)
+}
+
+// ─── Latency SLOs ───────────────────────────────────────────────────────────
+
+// DefaultSLOLatencyWeight is the share of the affinity score given to
+// latency for a model with an SLO, up from 0.30 for models without one.
+const DefaultSLOLatencyWeight = 0.6
+
+// ModelSLO is a model's latency objective. Realtime models (voice, chat)
+// set one so placement favors nodes that keep them under target; batch
+// models leave it unset.
+type ModelSLO struct {
+	TargetLatencyMs float64 // inference latency each request should meet
+	LatencyWeight   float64 // 0..1 share of affinity given to latency (default DefaultSLOLatencyWeight)
 }
 
 // ─── Placement Recommendation ───────────────────────────────────────────────
@@ -129,6 +191,7 @@ func (r RecommendationType) String() string {
 
 // Recommendation is a single placement optimization suggestion.
 type Recommendation struct {
+	ID        string // unique, for Acknowledge
 	Type      RecommendationType
 	ModelName string  // which model
 	FromNode  string  // source node (empty for PLACE)
@@ -136,17 +199,27 @@ type Recommendation struct {
 	Reason    string  // human-readable justification
 	Score     float64 // expected improvement score 0..1
 	CreatedAt time.Time
+
+	// For models with a latency SLO: the target, and the share of requests
+	// that met it on ToNode — the compliance to expect after the change.
+	SLOTargetMs   float64
+	SLOCompliance float64
+
+	// Acknowledgement state (see ack.go).
+	Status    AckStatus
+	Detail    string // reported with the status, e.g. why it failed
+	UpdatedAt time.Time
 }
 
 // ─── Retirement Candidate ───────────────────────────────────────────────────
 
 // RetirementCandidate is a model flagged for potential removal.
 type RetirementCandidate struct {
-	ModelName     string
-	LastRequested time.Time
-	DaysSinceUse  int
-	SizeBytes     int64
-	Reason        string
+	ModelName     string    `json:"model"`
+	LastRequested time.Time `json:"last_requested"`
+	DaysSinceUse  int       `json:"days_since_use"`
+	SizeBytes     int64     `json:"size_bytes,omitempty"`
+	Reason        string    `json:"reason"`
 }
 
 // ─── Federated Health Pattern ───────────────────────────────────────────────
@@ -154,54 +227,161 @@ type RetirementCandidate struct {
 // HealthPattern is an aggregated health observation from an organization.
 // No raw data is shared — only summary statistics (privacy-preserving).
 type HealthPattern struct {
-	OrgID          string    // anonymous organization identifier
-	AvgFailureRate float64   // average task failure rate (0..1)
-	AvgMTTR        float64   // average recovery time in seconds
-	TopFailureType string    // most common failure type
-	NodeCount      int       // number of nodes in the org
-	TaskVolume     int64     // total tasks processed in the reporting period
-	ReportedAt     time.Time // when the pattern was reported
+	OrgID          string             // organization pseudonym (see Anonymizer)
+	AvgFailureRate float64            // average task failure rate (0..1)
+	AvgMTTR        float64            // average recovery time in seconds
+	TopFailureType domain.FailureType // most common failure type
+	NodeCount      int                // number of nodes in the org
+	TaskVolume     int64              // total tasks processed in the reporting period
+	ReportedAt     time.Time          // when the pattern was reported
 }
 
 // ─── Optimizer ──────────────────────────────────────────────────────────────
 
 // Optimizer is the Phase 6 network intelligence engine.
+//
+// Request telemetry is the hot path: every inference on a busy gateway
+// records one. Popularity and affinity live in shards keyed by model name,
+// so RecordRequest locks only its model's shard and never mu; mu guards the
+// rest of the state. Readers that need mu take it before any shard lock.
 type Optimizer struct {
 	mu  sync.RWMutex
 	cfg Config
 
-	// Model popularity tracking.
-	popularity map[string]*modelStats // modelName → stats
-
-	// Per-{node, model} affinity tracking.
-	affinities map[string]map[string]*affinityStats // nodeID → modelName → stats
+	// Model popularity and per-{node, model} affinity, sharded by model.
+	shards [statsShards]*statsShard
 
 	// Placement recommendation history.
 	recommendations []Recommendation
 	recIdx          int
 	recCap          int
 	recFull         bool
+	recIndex        map[string]int          // ID → ring position
+	recOpen         map[string]string       // recKey → ID of the PENDING or ACCEPTED recommendation
+	recFeedback     map[string]*recFeedback // recKey → past refusals
+	recHook         func(Recommendation)
 
 	// Retirement candidates from last scan.
 	retirementCandidates []RetirementCandidate
 
-	// Federated health patterns.
+	// Federated health patterns, and who receives the per-org reports.
 	healthPatterns []HealthPattern
 	hpIdx          int
 	hpFull         bool
+	reportHook     func(HealthInsight, []OrgHealthReport)
 
 	// Optimization cycle tracking.
 	lastOptimization  time.Time
 	optimizationCount int64
+
+	// Gossiped model availability; nil = assume every node with affinity
+	// history still hosts the model.
+	avail ModelAvailability
+
+	// Disk and VRAM budgets (see capacity.go), and the recommendations
+	// dropped because they did not fit.
+	capacity         map[string]NodeCapacity
+	capacityRejected int64
+
+	// Recently applied idempotency keys, and the replays they caught.
+	dedup      *dsa.DedupWindow
+	duplicates atomic.Int64
+}
+
+// statsShards is the number of popularity/affinity shards. A power of two
+// well above the core count of a gateway keeps collisions between hot
+// models rare.
+const statsShards = 64
+
+// statsShard holds the statistics of the models that hash to it.
+type statsShard struct {
+	mu     sync.Mutex
+	models map[string]*modelEntry // modelName → entry
+}
+
+// modelEntry is one model's popularity and its affinity on each node.
+type modelEntry struct {
+	pop   *modelStats               // nil until the model is first requested
+	nodes map[string]*affinityStats // nodeID → stats
+	slo   *ModelSLO                 // nil = no latency objective
+
+	footprint ModelFootprint // disk and VRAM it takes (see capacity.go)
+}
+
+func newStatsShards() (shards [statsShards]*statsShard) {
+	for i := range shards {
+		shards[i] = &statsShard{models: make(map[string]*modelEntry)}
+	}
+	return shards
+}
+
+// shard returns the shard holding modelName.
+func (o *Optimizer) shard(modelName string) *statsShard {
+	h := fnv.New32a()
+	h.Write([]byte(modelName))
+	return o.shards[h.Sum32()%statsShards]
+}
+
+// entryLocked returns modelName's entry in sh, creating it if needed.
+func (sh *statsShard) entryLocked(modelName string) *modelEntry {
+	e, ok := sh.models[modelName]
+	if !ok {
+		e = &modelEntry{nodes: make(map[string]*affinityStats)}
+		sh.models[modelName] = e
+	}
+	return e
+}
+
+// affinityLocked returns the {node, model} stats in e, creating them if needed.
+func (e *modelEntry) affinityLocked(nodeID string) *affinityStats {
+	as, ok := e.nodes[nodeID]
+	if !ok {
+		as = &affinityStats{}
+		e.nodes[nodeID] = as
+	}
+	return as
 }
 
 // modelStats tracks request volume and latency for a model.
 type modelStats struct {
 	totalReqs    int64
-	recentReqs   int64
+	recent       recentWindow
 	lastReq      time.Time
 	latencySum   float64
 	latencyCount int64
+	hourly       [24]int64 // requests by UTC hour of day (see prewarm.go)
+}
+
+// recentBuckets is the number of hourly buckets in the recent window.
+const recentBuckets = 24
+
+// recentWindow counts requests over the last 24 hours in hourly buckets.
+// Each bucket remembers which hour it counts, so a bucket left over from a
+// previous day is reset on its next use and ignored until then.
+type recentWindow struct {
+	counts [recentBuckets]int64
+	hours  [recentBuckets]int64 // Unix hour each bucket counts
+}
+
+func (w *recentWindow) add(now time.Time) {
+	h := now.Unix() / 3600
+	i := h % recentBuckets
+	if w.hours[i] != h {
+		w.hours[i], w.counts[i] = h, 0
+	}
+	w.counts[i]++
+}
+
+// count returns the requests in the current hour and the 23 before it.
+func (w *recentWindow) count(now time.Time) int64 {
+	h := now.Unix() / 3600
+	var n int64
+	for i, bh := range w.hours {
+		if age := h - bh; age >= 0 && age < recentBuckets {
+			n += w.counts[i]
+		}
+	}
+	return n
 }
 
 // affinityStats tracks per-{node, model} performance.
@@ -212,6 +392,8 @@ type affinityStats struct {
 	latencySum   float64
 	latencyCount int64
 	vramFit      float64 // 0..1 — how much of VRAM the model uses (lower = better fit)
+	sloSamples   int64   // requests timed against the model's SLO
+	sloMet       int64   // of those, requests within target
 }
 
 // NewOptimizer creates a new network intelligence optimizer.
@@ -228,59 +410,129 @@ func NewOptimizer(cfg Config) *Optimizer {
 	if cfg.MaxRecommendations <= 0 {
 		cfg.MaxRecommendations = 50
 	}
+	if cfg.PlaceTopModels <= 0 {
+		cfg.PlaceTopModels = 10
+	}
+	if cfg.PlaceMinNodeScore <= 0 {
+		cfg.PlaceMinNodeScore = 0.7
+	}
+	if cfg.EvictMaxAffinity <= 0 {
+		cfg.EvictMaxAffinity = 0.1
+	}
+	if cfg.EvictVRAMPressure <= 0 {
+		cfg.EvictVRAMPressure = 0.95
+	}
+	if cfg.AckTimeout <= 0 {
+		cfg.AckTimeout = cfg.PlacementInterval
+	}
+	if cfg.RetryCooldown <= 0 {
+		cfg.RetryCooldown = 4 * cfg.PlacementInterval
+	}
 	if cfg.MaxRetirementCandidates <= 0 {
 		cfg.MaxRetirementCandidates = 100
 	}
 	if cfg.HealthHistorySize <= 0 {
 		cfg.HealthHistorySize = 10_000
 	}
+	if cfg.HealthMaxAge <= 0 {
+		cfg.HealthMaxAge = 30 * 24 * time.Hour
+	}
+	cfg.HealthPrivacy = cfg.HealthPrivacy.withDefaults()
 	if cfg.Now == nil {
 		cfg.Now = time.Now
 	}
 
 	return &Optimizer{
 		cfg:             cfg,
-		popularity:      make(map[string]*modelStats),
-		affinities:      make(map[string]map[string]*affinityStats),
+		shards:          newStatsShards(),
 		recommendations: make([]Recommendation, 1000),
 		recCap:          1000,
+		recIndex:        make(map[string]int),
+		recOpen:         make(map[string]string),
+		recFeedback:     make(map[string]*recFeedback),
+		capacity:        make(map[string]NodeCapacity),
 		healthPatterns:  make([]HealthPattern, cfg.HealthHistorySize),
+		dedup:           dsa.NewDedupWindow(dsa.DedupConfig{Window: cfg.DedupWindow, MaxKeys: cfg.DedupMaxKeys}),
 	}
 }
 
+// ModelAvailability reports which nodes currently host which models. It is
+// satisfied by the gossip availability index.
+type ModelAvailability interface {
+	HasModel(nodeID, model string) bool
+}
+
+// SetAvailability makes affinity queries and placement optimization consider
+// only nodes that currently host a model. Affinity history for nodes that
+// have since dropped the model (or left the network) is kept but ignored.
+func (o *Optimizer) SetAvailability(av ModelAvailability) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.avail = av
+}
+
+// hostsLocked reports whether nodeID should be treated as hosting modelName.
+func (o *Optimizer) hostsLocked(nodeID, modelName string) bool {
+	return o.avail == nil || o.avail.HasModel(nodeID, modelName)
+}
+
+// RetirementDays returns the current retirement idle threshold.
+func (o *Optimizer) RetirementDays() int {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return o.cfg.RetirementDays
+}
+
+// SetRetirementDays changes the retirement idle threshold at runtime; the
+// next ScanRetirements uses it. Non-positive values are ignored.
+func (o *Optimizer) SetRetirementDays(days int) {
+	if days <= 0 {
+		return
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.cfg.RetirementDays = days
+}
+
 // ─── Record Request ─────────────────────────────────────────────────────────
 
 // RecordRequest records that a model was requested on a specific node.
 // This updates both the global popularity and the per-node affinity.
 func (o *Optimizer) RecordRequest(modelName, nodeID string, latencyMs float64, cacheHit bool) {
-	o.mu.Lock()
-	defer o.mu.Unlock()
+	o.RecordRequestWithKey("", modelName, nodeID, latencyMs, cacheHit)
+}
 
+// RecordRequestWithKey is RecordRequest for senders that retry: a request
+// whose idempotency key was already recorded within the dedup window is
+// dropped so it doesn't count twice toward popularity and affinity. It
+// reports whether the request was recorded. An empty key always records.
+func (o *Optimizer) RecordRequestWithKey(key, modelName, nodeID string, latencyMs float64, cacheHit bool) bool {
 	now := o.cfg.Now()
+	if o.dedup.Seen(key, now) {
+		o.duplicates.Add(1)
+		return false
+	}
+
+	sh := o.shard(modelName)
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	e := sh.entryLocked(modelName)
 
 	// Update global model popularity.
-	ms, exists := o.popularity[modelName]
-	if !exists {
+	ms := e.pop
+	if ms == nil {
 		ms = &modelStats{}
-		o.popularity[modelName] = ms
+		e.pop = ms
 	}
 	ms.totalReqs++
-	ms.recentReqs++
+	ms.recent.add(now)
 	ms.lastReq = now
 	ms.latencySum += latencyMs
 	ms.latencyCount++
+	ms.hourly[now.UTC().Hour()]++
 
 	// Update per-{node, model} affinity.
-	nodeMap, exists := o.affinities[nodeID]
-	if !exists {
-		nodeMap = make(map[string]*affinityStats)
-		o.affinities[nodeID] = nodeMap
-	}
-	as, exists := nodeMap[modelName]
-	if !exists {
-		as = &affinityStats{}
-		nodeMap[modelName] = as
-	}
+	as := e.affinityLocked(nodeID)
 	as.requests++
 	if cacheHit {
 		as.cacheHits++
@@ -289,52 +541,93 @@ func (o *Optimizer) RecordRequest(modelName, nodeID string, latencyMs float64, c
 	}
 	as.latencySum += latencyMs
 	as.latencyCount++
+	if e.slo != nil {
+		as.sloSamples++
+		if latencyMs <= e.slo.TargetLatencyMs {
+			as.sloMet++
+		}
+	}
+	return true
+}
+
+// SetModelSLO sets a model's latency objective; a zero TargetLatencyMs
+// clears it. Compliance is counted from this call on, against the new
+// target.
+func (o *Optimizer) SetModelSLO(modelName string, slo ModelSLO) {
+	sh := o.shard(modelName)
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	e := sh.entryLocked(modelName)
+	for _, as := range e.nodes {
+		as.sloSamples, as.sloMet = 0, 0
+	}
+	if slo.TargetLatencyMs <= 0 {
+		e.slo = nil
+		return
+	}
+	if slo.LatencyWeight <= 0 || slo.LatencyWeight > 1 {
+		slo.LatencyWeight = DefaultSLOLatencyWeight
+	}
+	e.slo = &slo
+}
+
+// ModelSLO returns a model's latency objective, if it has one.
+func (o *Optimizer) ModelSLO(modelName string) (ModelSLO, bool) {
+	sh := o.shard(modelName)
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	if e, ok := sh.models[modelName]; ok && e.slo != nil {
+		return *e.slo, true
+	}
+	return ModelSLO{}, false
 }
 
 // SetVRAMFit updates the VRAM fit score for a model on a node.
 // 0.0 = model perfectly fits, 1.0 = model far too large for available VRAM.
 func (o *Optimizer) SetVRAMFit(nodeID, modelName string, fitScore float64) {
-	o.mu.Lock()
-	defer o.mu.Unlock()
-
-	nodeMap, exists := o.affinities[nodeID]
-	if !exists {
-		nodeMap = make(map[string]*affinityStats)
-		o.affinities[nodeID] = nodeMap
-	}
-	as, exists := nodeMap[modelName]
-	if !exists {
-		as = &affinityStats{}
-		nodeMap[modelName] = as
-	}
-	as.vramFit = fitScore
+	sh := o.shard(modelName)
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	sh.entryLocked(modelName).affinityLocked(nodeID).vramFit = fitScore
 }
 
 // ─── Model Popularity ───────────────────────────────────────────────────────
 
-// TopModels returns the top N models by total request count.
+// TopModels returns the top N models by requests in the last 24 hours,
+// then by total requests.
 func (o *Optimizer) TopModels(n int) []ModelPopularity {
-	o.mu.RLock()
-	defer o.mu.RUnlock()
-
-	models := make([]ModelPopularity, 0, len(o.popularity))
-	for name, ms := range o.popularity {
-		var avgLat float64
-		if ms.latencyCount > 0 {
-			avgLat = ms.latencySum / float64(ms.latencyCount)
+	now := o.cfg.Now()
+	var models []ModelPopularity
+	for _, sh := range o.shards {
+		sh.mu.Lock()
+		for name, e := range sh.models {
+			ms := e.pop
+			if ms == nil {
+				continue
+			}
+			var avgLat float64
+			if ms.latencyCount > 0 {
+				avgLat = ms.latencySum / float64(ms.latencyCount)
+			}
+			models = append(models, ModelPopularity{
+				ModelName:     name,
+				TotalReqs:     ms.totalReqs,
+				RecentReqs:    ms.recent.count(now),
+				LastRequested: ms.lastReq,
+				AvgLatencyMs:  avgLat,
+			})
 		}
-		models = append(models, ModelPopularity{
-			ModelName:     name,
-			TotalReqs:     ms.totalReqs,
-			RecentReqs:    ms.recentReqs,
-			LastRequested: ms.lastReq,
-			AvgLatencyMs:  avgLat,
-		})
+		sh.mu.Unlock()
 	}
 
-	// Sort by total requests descending.
 	sort.Slice(models, func(i, j int) bool {
-		return models[i].TotalReqs > models[j].TotalReqs
+		if models[i].RecentReqs != models[j].RecentReqs {
+			return models[i].RecentReqs > models[j].RecentReqs
+		}
+		if models[i].TotalReqs != models[j].TotalReqs {
+			return models[i].TotalReqs > models[j].TotalReqs
+		}
+		return models[i].ModelName < models[j].ModelName
 	})
 
 	if n > len(models) {
@@ -349,8 +642,10 @@ func (o *Optimizer) TopModels(n int) []ModelPopularity {
 //
 // affinity = 0.3 * cacheHitRate + 0.3 * (1 - normalizedLatency) + 0.2 * requestShare + 0.2 * (1 - vramFit)
 //
-// Higher = model belongs on this node.
-func computeAffinity(as *affinityStats, maxLatency float64, maxReqs int64) float64 {
+// With a latency SLO, the latency term is SLO compliance instead and gets
+// slo.LatencyWeight of the score; the other terms share the rest in the
+// same proportions. Higher = model belongs on this node.
+func computeAffinity(as *affinityStats, maxLatency float64, maxReqs int64, slo *ModelSLO) float64 {
 	// Cache hit rate.
 	var hitRate float64
 	total := as.cacheHits + as.cacheMisses
@@ -383,35 +678,67 @@ func computeAffinity(as *affinityStats, maxLatency float64, maxReqs int64) float
 		vramScore = 0
 	}
 
-	return 0.30*hitRate + 0.30*latScore + 0.20*reqShare + 0.20*vramScore
+	if slo == nil {
+		return 0.30*hitRate + 0.30*latScore + 0.20*reqShare + 0.20*vramScore
+	}
+	rest := (1 - slo.LatencyWeight) / 0.70
+	return slo.LatencyWeight*sloCompliance(as, slo) +
+		rest*(0.30*hitRate+0.20*reqShare+0.20*vramScore)
+}
+
+// sloCompliance is the share of a node's requests that met the SLO. Before
+// any request has been timed against the target it is estimated from the
+// average latency.
+func sloCompliance(as *affinityStats, slo *ModelSLO) float64 {
+	if slo == nil {
+		return 0
+	}
+	if as.sloSamples > 0 {
+		return float64(as.sloMet) / float64(as.sloSamples)
+	}
+	if as.latencyCount == 0 {
+		return 0
+	}
+	avg := as.latencySum / float64(as.latencyCount)
+	if avg <= slo.TargetLatencyMs {
+		return 1
+	}
+	return slo.TargetLatencyMs / avg
+}
+
+// normalizers returns the model's highest average latency and request count
+// across nodes, which computeAffinity normalizes against.
+func (e *modelEntry) normalizers() (maxLat float64, maxReqs int64) {
+	for _, as := range e.nodes {
+		if as.latencyCount > 0 {
+			if avg := as.latencySum / float64(as.latencyCount); avg > maxLat {
+				maxLat = avg
+			}
+		}
+		if as.requests > maxReqs {
+			maxReqs = as.requests
+		}
+	}
+	return maxLat, maxReqs
 }
 
 // NodeAffinities returns affinity scores for all {node, model} pairs for a given model.
 func (o *Optimizer) NodeAffinities(modelName string) []NodeModelAffinity {
 	o.mu.RLock()
 	defer o.mu.RUnlock()
+	sh := o.shard(modelName)
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
 
-	// Find max latency and max request count for normalization.
-	var maxLat float64
-	var maxReqs int64
-	for _, nodeMap := range o.affinities {
-		if as, ok := nodeMap[modelName]; ok {
-			if as.latencyCount > 0 {
-				avg := as.latencySum / float64(as.latencyCount)
-				if avg > maxLat {
-					maxLat = avg
-				}
-			}
-			if as.requests > maxReqs {
-				maxReqs = as.requests
-			}
-		}
+	e, ok := sh.models[modelName]
+	if !ok {
+		return nil
 	}
+	maxLat, maxReqs := e.normalizers()
 
 	var result []NodeModelAffinity
-	for nodeID, nodeMap := range o.affinities {
-		as, ok := nodeMap[modelName]
-		if !ok {
+	for nodeID, as := range e.nodes {
+		if !o.hostsLocked(nodeID, modelName) {
 			continue
 		}
 		var hitRate float64
@@ -424,7 +751,7 @@ func (o *Optimizer) NodeAffinities(modelName string) []NodeModelAffinity {
 			avgLat = as.latencySum / float64(as.latencyCount)
 		}
 
-		score := computeAffinity(as, maxLat, maxReqs)
+		score := computeAffinity(as, maxLat, maxReqs, e.slo)
 		result = append(result, NodeModelAffinity{
 			NodeID:        nodeID,
 			ModelName:     modelName,
@@ -433,6 +760,7 @@ func (o *Optimizer) NodeAffinities(modelName string) []NodeModelAffinity {
 			RequestCount:  as.requests,
 			VRAMFitScore:  as.vramFit,
 			AffinityScore: score,
+			SLOCompliance: sloCompliance(as, e.slo),
 		})
 	}
 
@@ -447,94 +775,281 @@ func (o *Optimizer) NodeAffinities(modelName string) []NodeModelAffinity {
 // ─── Placement Optimization ─────────────────────────────────────────────────
 
 // Optimize runs the placement optimization cycle.
-// Returns a list of recommendations (place, move, or evict models).
+// Returns a list of recommendations (place, move, or evict models), best
+// first.
+//
+// Every model with at least MinRequestsForPlacement requests is scored on
+// each node that hosts it, and each node is scored by its mean affinity
+// across the models it hosts. Then, per model:
+//
+//   - MOVE from the worst host to the best when their affinity gap exceeds
+//     0.3
+//   - EVICT from hosts below EvictMaxAffinity or at EvictVRAMPressure,
+//     keeping at least one host
+//   - for the PlaceTopModels most-requested models over the last 24
+//     hours, PLACE on the
+//     best-scoring node that doesn't host the model, if its node score is
+//     at least PlaceMinNodeScore and the model is not known to strain its
+//     VRAM
+//
+// A recommendation still open or recently refused is left out (see
+// ack.go); one whose destination lacks the disk or VRAM for the model is
+// left out or down-ranked (see capacity.go).
 func (o *Optimizer) Optimize() []Recommendation {
+	recs, changed := o.optimize()
+	o.notify(changed)
+	return recs
+}
+
+// optimize runs the cycle, returning its recommendations and every
+// recommendation whose status changed, new ones included.
+func (o *Optimizer) optimize() (recs, changed []Recommendation) {
 	o.mu.Lock()
 	defer o.mu.Unlock()
 
 	now := o.cfg.Now()
 	o.lastOptimization = now
 	o.optimizationCount++
+	changed = o.expireLocked(now)
 
-	var recs []Recommendation
+	views, diskUsed := o.placementViewsLocked(now)
+	capCheck := &capacityCheck{caps: o.capacity, diskUsed: diskUsed}
 
-	// For each popular model, find the best and worst nodes.
-	for modelName, ms := range o.popularity {
-		if ms.totalReqs < o.cfg.MinRequestsForPlacement {
-			continue // not enough data
-		}
-
-		// Find max latency / max reqs for this model across nodes.
-		var maxLat float64
-		var maxReqs int64
-		for _, nodeMap := range o.affinities {
-			if as, ok := nodeMap[modelName]; ok {
-				if as.latencyCount > 0 {
-					avg := as.latencySum / float64(as.latencyCount)
-					if avg > maxLat {
-						maxLat = avg
-					}
-				}
-				if as.requests > maxReqs {
-					maxReqs = as.requests
-				}
+	// Node score: mean affinity across the models a node hosts.
+	type mean struct {
+		sum float64
+		n   int
+	}
+	nodeScores := make(map[string]*mean)
+	for _, v := range views {
+		for _, h := range v.hosts {
+			m, ok := nodeScores[h.nodeID]
+			if !ok {
+				m = &mean{}
+				nodeScores[h.nodeID] = m
 			}
+			m.sum += h.score
+			m.n++
 		}
+	}
+	nodes := make([]string, 0, len(nodeScores))
+	for id := range nodeScores {
+		nodes = append(nodes, id)
+	}
+	nodeScore := func(id string) float64 { return nodeScores[id].sum / float64(nodeScores[id].n) }
+	sort.Slice(nodes, func(i, j int) bool {
+		if si, sj := nodeScore(nodes[i]), nodeScore(nodes[j]); si != sj {
+			return si > sj
+		}
+		return nodes[i] < nodes[j]
+	})
 
-		// Compute affinity for each node that has this model.
-		type scored struct {
-			nodeID string
-			score  float64
+	// Models popular right now are eligible for PLACE.
+	sort.Slice(views, func(i, j int) bool {
+		if views[i].recentReqs != views[j].recentReqs {
+			return views[i].recentReqs > views[j].recentReqs
 		}
-		var candidates []scored
-		for nodeID, nodeMap := range o.affinities {
-			as, ok := nodeMap[modelName]
-			if !ok {
-				continue
-			}
-			score := computeAffinity(as, maxLat, maxReqs)
-			candidates = append(candidates, scored{nodeID, score})
+		if views[i].totalReqs != views[j].totalReqs {
+			return views[i].totalReqs > views[j].totalReqs
 		}
+		return views[i].name < views[j].name
+	})
 
-		if len(candidates) < 2 {
-			continue
+	for rank, v := range views {
+		sloRec := func(rec Recommendation, h placementHost) Recommendation {
+			if v.sloTargetMs > 0 {
+				rec.SLOTargetMs = v.sloTargetMs
+				rec.SLOCompliance = h.sloCompliance
+			}
+			return rec
 		}
 
-		// Sort: best node first, worst node last.
-		sort.Slice(candidates, func(i, j int) bool {
-			return candidates[i].score > candidates[j].score
+		// Sort: best host first, worst host last.
+		sort.Slice(v.hosts, func(i, j int) bool {
+			return v.hosts[i].score > v.hosts[j].score
 		})
-
-		best := candidates[0]
-		worst := candidates[len(candidates)-1]
+		remaining := len(v.hosts)
 
 		// Recommend moving model from worst node to best node if there's
 		// a significant affinity gap (>0.3).
-		gap := best.score - worst.score
-		if gap > 0.3 && len(recs) < o.cfg.MaxRecommendations {
-			recs = append(recs, Recommendation{
+		moved := ""
+		if len(v.hosts) >= 2 {
+			best, worst := v.hosts[0], v.hosts[len(v.hosts)-1]
+			rec := sloRec(Recommendation{
 				Type:      RecommendMove,
-				ModelName: modelName,
+				ModelName: v.name,
 				FromNode:  worst.nodeID,
 				ToNode:    best.nodeID,
 				Reason:    "significant affinity gap — move to higher-performing node",
-				Score:     gap,
+				Score:     best.score - worst.score,
 				CreatedAt: now,
-			})
+			}, best)
+			if rec.Score > 0.3 && !o.suppressedLocked(rec, now) {
+				if factor, ok := capCheck.fit(best.nodeID, v.footprint, true); ok {
+					rec.Score *= factor
+					recs = append(recs, rec)
+					moved = worst.nodeID
+					remaining--
+				}
+			}
+		}
+
+		// Evict from the worst hosts first, never the last one.
+		for i := len(v.hosts) - 1; i >= 0 && remaining > 1; i-- {
+			h := v.hosts[i]
+			if h.nodeID == moved {
+				continue
+			}
+			rec := Recommendation{Type: RecommendEvict, ModelName: v.name, FromNode: h.nodeID, CreatedAt: now}
+			switch {
+			case h.vramFit >= o.cfg.EvictVRAMPressure:
+				rec.Reason = "VRAM pressure — model barely fits on this node"
+				rec.Score = h.vramFit
+			case h.score < o.cfg.EvictMaxAffinity:
+				rec.Reason = "very low affinity — model performs poorly on this node"
+				rec.Score = 1 - h.score
+			default:
+				continue
+			}
+			if o.suppressedLocked(rec, now) {
+				continue
+			}
+			recs = append(recs, rec)
+			remaining--
+		}
+
+		if rank >= o.cfg.PlaceTopModels {
+			continue
+		}
+		for _, id := range nodes {
+			score := nodeScore(id)
+			if score < o.cfg.PlaceMinNodeScore {
+				break
+			}
+			if v.hosted(id) || (o.avail != nil && o.avail.HasModel(id, v.name)) {
+				continue
+			}
+			if fit, ok := v.vramFits[id]; ok && fit >= o.cfg.EvictVRAMPressure {
+				continue
+			}
+			rec := Recommendation{
+				Type:      RecommendPlace,
+				ModelName: v.name,
+				ToNode:    id,
+				Reason:    "popular model missing from a high-scoring node",
+				Score:     score,
+				CreatedAt: now,
+			}
+			if o.suppressedLocked(rec, now) {
+				continue // try the next-best node
+			}
+			factor, ok := capCheck.fit(id, v.footprint, false)
+			if !ok {
+				continue
+			}
+			rec.Score *= factor
+			capCheck.commit(id, v.footprint)
+			recs = append(recs, rec)
+			break
 		}
 	}
 
+	o.capacityRejected += int64(capCheck.rejected)
+
+	sort.SliceStable(recs, func(i, j int) bool { return recs[i].Score > recs[j].Score })
+	if len(recs) > o.cfg.MaxRecommendations {
+		recs = recs[:o.cfg.MaxRecommendations]
+	}
+
 	// Store recommendations in ring buffer.
-	for _, r := range recs {
-		o.recommendations[o.recIdx] = r
-		o.recIdx++
-		if o.recIdx >= o.recCap {
-			o.recIdx = 0
-			o.recFull = true
+	for i := range recs {
+		recs[i].ID = newRecID(now, i)
+		recs[i].Status = AckPending
+		recs[i].UpdatedAt = now
+		o.storeLocked(recs[i])
+		o.applyFeedbackLocked(recs[i])
+	}
+
+	return recs, append(changed, recs...)
+}
+
+// placementHost is one host of a model, as Optimize sees it.
+type placementHost struct {
+	nodeID        string
+	score         float64 // affinity
+	vramFit       float64
+	sloCompliance float64
+}
+
+// placementView is a snapshot of one model's placement, taken under its
+// shard lock.
+type placementView struct {
+	name        string
+	totalReqs   int64
+	recentReqs  int64   // last 24h
+	sloTargetMs float64 // 0 = no SLO
+	footprint   ModelFootprint
+	hosts       []placementHost
+	vramFits    map[string]float64 // non-hosts with a known VRAM fit
+}
+
+func (v *placementView) hosted(nodeID string) bool {
+	for _, h := range v.hosts {
+		if h.nodeID == nodeID {
+			return true
 		}
 	}
+	return false
+}
 
-	return recs
+// placementViewsLocked snapshots every model with enough requests to be
+// placed, and returns the disk each node's hosted models take.
+func (o *Optimizer) placementViewsLocked(now time.Time) ([]placementView, map[string]int64) {
+	var views []placementView
+	diskUsed := make(map[string]int64)
+	for _, sh := range o.shards {
+		sh.mu.Lock()
+		for modelName, e := range sh.models {
+			if e.footprint.DiskBytes > 0 {
+				for nodeID := range e.nodes {
+					if o.hostsLocked(nodeID, modelName) {
+						diskUsed[nodeID] += e.footprint.DiskBytes
+					}
+				}
+			}
+			if e.pop == nil || e.pop.totalReqs < o.cfg.MinRequestsForPlacement {
+				continue // not enough data
+			}
+			v := placementView{
+				name:       modelName,
+				totalReqs:  e.pop.totalReqs,
+				recentReqs: e.pop.recent.count(now),
+				footprint:  e.footprint,
+				vramFits:   make(map[string]float64),
+			}
+			if e.slo != nil {
+				v.sloTargetMs = e.slo.TargetLatencyMs
+			}
+			maxLat, maxReqs := e.normalizers()
+			for nodeID, as := range e.nodes {
+				if !o.hostsLocked(nodeID, modelName) {
+					if as.vramFit > 0 {
+						v.vramFits[nodeID] = as.vramFit
+					}
+					continue
+				}
+				v.hosts = append(v.hosts, placementHost{
+					nodeID:        nodeID,
+					score:         computeAffinity(as, maxLat, maxReqs, e.slo),
+					vramFit:       as.vramFit,
+					sloCompliance: sloCompliance(as, e.slo),
+				})
+			}
+			views = append(views, v)
+		}
+		sh.mu.Unlock()
+	}
+	return views, diskUsed
 }
 
 // ─── Retirement Scanning ────────────────────────────────────────────────────
@@ -549,16 +1064,20 @@ func (o *Optimizer) ScanRetirements() []RetirementCandidate {
 	threshold := now.AddDate(0, 0, -o.cfg.RetirementDays)
 
 	var candidates []RetirementCandidate
-	for name, ms := range o.popularity {
-		if ms.lastReq.Before(threshold) {
-			daysSince := int(now.Sub(ms.lastReq).Hours() / 24)
-			candidates = append(candidates, RetirementCandidate{
-				ModelName:     name,
-				LastRequested: ms.lastReq,
-				DaysSinceUse:  daysSince,
-				Reason:        "inactive for retirement period",
-			})
+	for _, sh := range o.shards {
+		sh.mu.Lock()
+		for name, e := range sh.models {
+			if ms := e.pop; ms != nil && ms.lastReq.Before(threshold) {
+				daysSince := int(now.Sub(ms.lastReq).Hours() / 24)
+				candidates = append(candidates, RetirementCandidate{
+					ModelName:     name,
+					LastRequested: ms.lastReq,
+					DaysSinceUse:  daysSince,
+					Reason:        "inactive for retirement period",
+				})
+			}
 		}
+		sh.mu.Unlock()
 	}
 
 	// Sort by days since use descending (oldest first).
@@ -588,7 +1107,16 @@ func (o *Optimizer) RetirementCandidates() []RetirementCandidate {
 
 // ReportHealthPattern adds a cross-organization health pattern observation.
 // Each org reports summary statistics (no raw data) for federated learning.
+// A pattern without a report time is stamped with the current time; with a
+// privacy budget set, its figures are stored with noise added.
 func (o *Optimizer) ReportHealthPattern(pattern HealthPattern) {
+	// Orgs may run older builds; map their names onto the shared taxonomy
+	pattern.TopFailureType = domain.ParseFailureType(string(pattern.TopFailureType))
+	if pattern.ReportedAt.IsZero() {
+		pattern.ReportedAt = o.cfg.Now()
+	}
+	pattern = o.cfg.HealthPrivacy.privatize(pattern)
+
 	o.mu.Lock()
 	defer o.mu.Unlock()
 
@@ -600,74 +1128,125 @@ func (o *Optimizer) ReportHealthPattern(pattern HealthPattern) {
 	}
 }
 
-// AggregateHealthInsights computes network-wide health insights from
-// all reported patterns. Returns average failure rate, average MTTR,
-// and the most common failure type across organizations.
+// livePatternsLocked returns the reported patterns no older than
+// HealthMaxAge, and how many were older. Caller must hold o.mu.
+func (o *Optimizer) livePatternsLocked() ([]HealthPattern, int) {
+	count := o.hpIdx
+	if o.hpFull {
+		count = len(o.healthPatterns)
+	}
+	cutoff := o.cfg.Now().Add(-o.cfg.HealthMaxAge)
+	live := make([]HealthPattern, 0, count)
+	expired := 0
+	for i := 0; i < count; i++ {
+		p := o.healthPatterns[i]
+		switch {
+		case p.OrgID == "":
+		case p.ReportedAt.Before(cutoff):
+			expired++
+		default:
+			live = append(live, p)
+		}
+	}
+	return live, expired
+}
+
+// AggregateHealthInsights computes network-wide health insights from the
+// patterns reported within HealthMaxAge. Failure rate and MTTR are given
+// both as a plain average over the patterns and weighted by task volume,
+// so a 500-node org counts for more than a 2-node one. In aggregate-only
+// mode the figures are withheld until MinInsightOrgs orgs have reported.
 func (o *Optimizer) AggregateHealthInsights() HealthInsight {
 	o.mu.RLock()
 	defer o.mu.RUnlock()
 
-	var count int
-	if o.hpFull {
-		count = len(o.healthPatterns)
-	} else {
-		count = o.hpIdx
+	privacy := o.cfg.HealthPrivacy
+	patterns, expired := o.livePatternsLocked()
+	if len(patterns) == 0 {
+		return HealthInsight{ExpiredPatterns: expired, Epsilon: privacy.Epsilon}
 	}
-	if count == 0 {
-		return HealthInsight{}
+	if privacy.AggregateOnly && distinctOrgs(patterns) < MinInsightOrgs {
+		return HealthInsight{OrgCount: len(patterns), ExpiredPatterns: expired, Epsilon: privacy.Epsilon, Withheld: true}
 	}
 
 	var totalFailRate, totalMTTR float64
-	failTypeCounts := make(map[string]int)
+	var weightedFail, weightedMTTR float64
+	failTypeCounts := make(map[domain.FailureType]int)
 	var totalNodes int
 	var totalTasks int64
-	var orgs int
 
-	for i := 0; i < count; i++ {
-		p := o.healthPatterns[i]
-		if p.OrgID == "" {
-			continue
-		}
+	for _, p := range patterns {
 		totalFailRate += p.AvgFailureRate
 		totalMTTR += p.AvgMTTR
 		failTypeCounts[p.TopFailureType]++
 		totalNodes += p.NodeCount
-		totalTasks += p.TaskVolume
-		orgs++
-	}
-
-	if orgs == 0 {
-		return HealthInsight{}
+		if p.TaskVolume > 0 {
+			totalTasks += p.TaskVolume
+			weightedFail += p.AvgFailureRate * float64(p.TaskVolume)
+			weightedMTTR += p.AvgMTTR * float64(p.TaskVolume)
+		}
 	}
 
-	// Find most common failure type.
-	var topType string
+	// Find most common failure type (ties go to the first name).
+	var topType domain.FailureType
 	var topCount int
 	for ft, c := range failTypeCounts {
-		if c > topCount {
+		if c > topCount || (c == topCount && ft < topType) {
 			topType = ft
 			topCount = c
 		}
 	}
 
-	return HealthInsight{
-		OrgCount:       orgs,
-		AvgFailureRate: totalFailRate / float64(orgs),
-		AvgMTTRSeconds: totalMTTR / float64(orgs),
-		TopFailureType: topType,
-		TotalNodes:     totalNodes,
-		TotalTasks:     totalTasks,
+	orgs := len(patterns)
+	insight := HealthInsight{
+		OrgCount:            orgs,
+		AvgFailureRate:      totalFailRate / float64(orgs),
+		AvgMTTRSeconds:      totalMTTR / float64(orgs),
+		WeightedFailureRate: totalFailRate / float64(orgs),
+		WeightedMTTRSeconds: totalMTTR / float64(orgs),
+		TopFailureType:      topType,
+		TotalNodes:          totalNodes,
+		TotalTasks:          totalTasks,
+		ExpiredPatterns:     expired,
+		Epsilon:             privacy.Epsilon,
+	}
+	if totalTasks > 0 {
+		insight.WeightedFailureRate = weightedFail / float64(totalTasks)
+		insight.WeightedMTTRSeconds = weightedMTTR / float64(totalTasks)
 	}
+	if privacy.Epsilon > 0 {
+		// Noisy figures can leave their range; publish them clamped
+		insight.AvgFailureRate = min(max(insight.AvgFailureRate, 0), 1)
+		insight.WeightedFailureRate = min(max(insight.WeightedFailureRate, 0), 1)
+		insight.AvgMTTRSeconds = max(insight.AvgMTTRSeconds, 0)
+		insight.WeightedMTTRSeconds = max(insight.WeightedMTTRSeconds, 0)
+		insight.TotalNodes = max(insight.TotalNodes, 0)
+	}
+	return insight
+}
+
+// distinctOrgs counts the orgs among patterns.
+func distinctOrgs(patterns []HealthPattern) int {
+	orgs := make(map[string]struct{}, len(patterns))
+	for _, p := range patterns {
+		orgs[p.OrgID] = struct{}{}
+	}
+	return len(orgs)
 }
 
 // HealthInsight is an aggregated view of network-wide health.
 type HealthInsight struct {
-	OrgCount       int     // number of organizations reporting
-	AvgFailureRate float64 // average failure rate across orgs
-	AvgMTTRSeconds float64 // average MTTR in seconds
-	TopFailureType string  // most common failure type
-	TotalNodes     int     // total nodes across all orgs
-	TotalTasks     int64   // total tasks across all orgs
+	OrgCount            int                // number of organizations reporting
+	AvgFailureRate      float64            // unweighted average failure rate across orgs
+	AvgMTTRSeconds      float64            // unweighted average MTTR in seconds
+	WeightedFailureRate float64            // failure rate weighted by task volume
+	WeightedMTTRSeconds float64            // MTTR weighted by task volume
+	TopFailureType      domain.FailureType // most common failure type
+	TotalNodes          int                // total nodes across all orgs
+	TotalTasks          int64              // total tasks across all orgs
+	ExpiredPatterns     int                // patterns older than HealthMaxAge, left out
+	Epsilon             float64            // privacy budget of the noise in the patterns (0 = exact)
+	Withheld            bool               // aggregate-only mode: too few orgs to publish figures
 }
 
 // ─── Statistics & Gate Check ────────────────────────────────────────────────
@@ -680,6 +1259,8 @@ type OptimizerStats struct {
 	TotalRecommendations   int   // total recommendations produced
 	RetirementCandidates   int   // models flagged for retirement
 	HealthPatternsReceived int   // federated health observations
+	DuplicatesDropped      int64 // replayed requests dropped by idempotency key
+	CapacityRejected       int64 // recommendations dropped for lack of disk or VRAM
 }
 
 // Stats returns current optimizer statistics.
@@ -701,13 +1282,30 @@ func (o *Optimizer) Stats() OptimizerStats {
 		hpCount = o.hpIdx
 	}
 
+	models := 0
+	nodes := make(map[string]struct{})
+	for _, sh := range o.shards {
+		sh.mu.Lock()
+		for _, e := range sh.models {
+			if e.pop != nil {
+				models++
+			}
+			for nodeID := range e.nodes {
+				nodes[nodeID] = struct{}{}
+			}
+		}
+		sh.mu.Unlock()
+	}
+
 	return OptimizerStats{
-		TrackedModels:          len(o.popularity),
-		TrackedNodes:           len(o.affinities),
+		TrackedModels:          models,
+		TrackedNodes:           len(nodes),
 		TotalOptimizations:     o.optimizationCount,
 		TotalRecommendations:   totalRecs,
 		RetirementCandidates:   len(o.retirementCandidates),
 		HealthPatternsReceived: hpCount,
+		DuplicatesDropped:      o.duplicates.Load(),
+		CapacityRejected:       o.capacityRejected,
 	}
 }
 
@@ -756,15 +1354,31 @@ func (o *Optimizer) Reset() {
 	o.mu.Lock()
 	defer o.mu.Unlock()
 
-	o.popularity = make(map[string]*modelStats)
-	o.affinities = make(map[string]map[string]*affinityStats)
+	for _, sh := range o.shards {
+		sh.mu.Lock()
+		// SLOs and footprints are configuration, not learned state
+		models := make(map[string]*modelEntry)
+		for name, e := range sh.models {
+			if e.slo != nil || e.footprint != (ModelFootprint{}) {
+				models[name] = &modelEntry{nodes: make(map[string]*affinityStats), slo: e.slo, footprint: e.footprint}
+			}
+		}
+		sh.models = models
+		sh.mu.Unlock()
+	}
 	o.recommendations = make([]Recommendation, o.recCap)
 	o.recIdx = 0
 	o.recFull = false
+	o.recIndex = make(map[string]int)
+	o.recOpen = make(map[string]string)
+	o.recFeedback = make(map[string]*recFeedback)
 	o.retirementCandidates = nil
 	o.healthPatterns = make([]HealthPattern, o.cfg.HealthHistorySize)
 	o.hpIdx = 0
 	o.hpFull = false
 	o.lastOptimization = time.Time{}
 	o.optimizationCount = 0
+	// The dedup window is kept: a replay arriving after the reset is still
+	// a replay.
+	o.duplicates.Store(0)
 }
@@ -1,8 +1,14 @@
 package intelligence
 
 import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"sync"
 	"testing"
 	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
 )
 
 // ─── Helpers ────────────────────────────────────────────────────────────────
@@ -16,6 +22,14 @@ func fixedClock(start time.Time, step time.Duration) func() time.Time {
 	}
 }
 
+// seedPopularity sets a model's popularity stats directly.
+func seedPopularity(o *Optimizer, modelName string, ms modelStats) {
+	sh := o.shard(modelName)
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	sh.entryLocked(modelName).pop = &ms
+}
+
 func testConfig(start time.Time) Config {
 	return Config{
 		RetirementDays:          30,
@@ -78,6 +92,39 @@ func TestRecordRequest_TracksPopularity(t *testing.T) {
 	}
 }
 
+func TestRecordRequestWithKey_DropsReplays(t *testing.T) {
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	cfg := testConfig(base)
+	cfg.DedupWindow = time.Minute
+	cfg.Now = fixedClock(base, 20*time.Second)
+	o := NewOptimizer(cfg)
+
+	if !o.RecordRequestWithKey("req-1", "llama3", "node-1", 50, true) {
+		t.Fatal("first delivery dropped")
+	}
+	if o.RecordRequestWithKey("req-1", "llama3", "node-1", 50, true) {
+		t.Error("replay inside the window recorded")
+	}
+	o.RecordRequestWithKey("", "llama3", "node-1", 50, true)
+	o.RecordRequestWithKey("", "llama3", "node-1", 50, true)
+
+	top := o.TopModels(1)
+	if len(top) != 1 || top[0].TotalReqs != 3 {
+		t.Fatalf("TopModels = %+v, want 3 requests", top)
+	}
+	if aff := o.NodeAffinities("llama3"); len(aff) != 1 || aff[0].RequestCount != 3 {
+		t.Errorf("affinity = %+v, want 3 requests", aff)
+	}
+	if st := o.Stats(); st.DuplicatesDropped != 1 {
+		t.Errorf("DuplicatesDropped = %d, want 1", st.DuplicatesDropped)
+	}
+
+	// Past the window the key is forgotten
+	if !o.RecordRequestWithKey("req-1", "llama3", "node-1", 50, true) {
+		t.Error("key still remembered after the window")
+	}
+}
+
 func TestRecordRequest_MultipleModels(t *testing.T) {
 	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
 	o := NewOptimizer(testConfig(base))
@@ -99,6 +146,39 @@ func TestRecordRequest_MultipleModels(t *testing.T) {
 	}
 }
 
+func TestTopModels_RecentWindow(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	cfg := testConfig(now)
+	cfg.Now = func() time.Time { return now }
+	o := NewOptimizer(cfg)
+
+	// "legacy" was busy two days ago; "current" is busy today.
+	for i := 0; i < 50; i++ {
+		o.RecordRequest("legacy", "n1", 30, true)
+	}
+	now = now.Add(47 * time.Hour)
+	for i := 0; i < 5; i++ {
+		o.RecordRequest("current", "n1", 30, true)
+	}
+	now = now.Add(23 * time.Hour)
+	o.RecordRequest("current", "n1", 30, true)
+
+	top := o.TopModels(2)
+	if len(top) != 2 || top[0].ModelName != "current" {
+		t.Fatalf("TopModels = %+v, want current first", top)
+	}
+	if top[0].RecentReqs != 6 || top[1].RecentReqs != 0 || top[1].TotalReqs != 50 {
+		t.Errorf("recent = %d/%d, legacy total = %d; want 6/0, 50",
+			top[0].RecentReqs, top[1].RecentReqs, top[1].TotalReqs)
+	}
+
+	// An hour later the oldest bucket of "current" has slid out.
+	now = now.Add(time.Hour)
+	if top := o.TopModels(1); top[0].RecentReqs != 1 {
+		t.Errorf("after an hour RecentReqs = %d, want 1", top[0].RecentReqs)
+	}
+}
+
 func TestNodeAffinities(t *testing.T) {
 	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
 	o := NewOptimizer(testConfig(base))
@@ -181,6 +261,158 @@ func TestOptimize_ProducesRecommendations(t *testing.T) {
 	}
 }
 
+func TestOptimize_LatencySLO(t *testing.T) {
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	record := func(o *Optimizer) {
+		// node-A is fast but cold; node-B is warm but too slow for realtime
+		for i := 0; i < 10; i++ {
+			o.RecordRequest("whisper-rt", "node-A", 100, false)
+			o.RecordRequest("whisper-rt", "node-B", 400, true)
+		}
+	}
+
+	plain := NewOptimizer(testConfig(base))
+	record(plain)
+	if affs := plain.NodeAffinities("whisper-rt"); affs[0].NodeID != "node-B" {
+		t.Fatalf("without an SLO the warm node should rank first, got %+v", affs)
+	}
+	if recs := plain.Optimize(); len(recs) != 0 {
+		t.Errorf("without an SLO the gap is too small to move, got %+v", recs)
+	}
+
+	o := NewOptimizer(testConfig(base))
+	o.SetModelSLO("whisper-rt", ModelSLO{TargetLatencyMs: 200})
+	record(o)
+	affs := o.NodeAffinities("whisper-rt")
+	if affs[0].NodeID != "node-A" || affs[0].SLOCompliance != 1 || affs[1].SLOCompliance != 0 {
+		t.Fatalf("with an SLO the fast node should rank first, got %+v", affs)
+	}
+	recs := o.Optimize()
+	if len(recs) != 1 || recs[0].FromNode != "node-B" || recs[0].ToNode != "node-A" {
+		t.Fatalf("recs = %+v, want a MOVE from node-B to node-A", recs)
+	}
+	if recs[0].SLOTargetMs != 200 || recs[0].SLOCompliance != 1 {
+		t.Errorf("SLO fields = %v/%v, want 200/1", recs[0].SLOTargetMs, recs[0].SLOCompliance)
+	}
+
+	// The SLO is configuration and survives a reset
+	o.Reset()
+	if slo, ok := o.ModelSLO("whisper-rt"); !ok || slo.LatencyWeight != DefaultSLOLatencyWeight {
+		t.Errorf("ModelSLO after reset = %+v, %v", slo, ok)
+	}
+	o.SetModelSLO("whisper-rt", ModelSLO{})
+	if _, ok := o.ModelSLO("whisper-rt"); ok {
+		t.Error("a zero target should clear the SLO")
+	}
+}
+
+func TestSetModelSLO_EstimatesFromHistory(t *testing.T) {
+	o := NewOptimizer(testConfig(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)))
+	o.RecordRequest("whisper-rt", "node-A", 400, true)
+	o.SetModelSLO("whisper-rt", ModelSLO{TargetLatencyMs: 200})
+
+	// No request timed against the target yet: estimated from the average
+	if got := o.NodeAffinities("whisper-rt")[0].SLOCompliance; got != 0.5 {
+		t.Errorf("estimated compliance = %v, want 0.5", got)
+	}
+	o.RecordRequest("whisper-rt", "node-A", 150, true)
+	if got := o.NodeAffinities("whisper-rt")[0].SLOCompliance; got != 1 {
+		t.Errorf("measured compliance = %v, want 1", got)
+	}
+}
+
+type fakeAvailability map[string]bool // "node/model" → hosted
+
+func (f fakeAvailability) HasModel(nodeID, model string) bool { return f[nodeID+"/"+model] }
+
+func TestOptimize_RespectsAvailability(t *testing.T) {
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	o := NewOptimizer(testConfig(base))
+
+	for i := 0; i < 20; i++ {
+		o.RecordRequest("llama-3", "node-A", 20, true)
+	}
+	for i := 0; i < 10; i++ {
+		o.RecordRequest("llama-3", "node-B", 300, false)
+	}
+
+	// node-A has since dropped the model — its history must not count.
+	o.SetAvailability(fakeAvailability{"node-B/llama-3": true})
+
+	affs := o.NodeAffinities("llama-3")
+	if len(affs) != 1 || affs[0].NodeID != "node-B" {
+		t.Fatalf("NodeAffinities = %+v, want only node-B", affs)
+	}
+	if recs := o.Optimize(); len(recs) != 0 {
+		t.Errorf("expected no recommendations with a single host, got %+v", recs)
+	}
+}
+
+func TestOptimize_PlaceAndEvict(t *testing.T) {
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	cfg := testConfig(base)
+	cfg.PlaceMinNodeScore = 0.6
+	cfg.EvictMaxAffinity = 0.35
+	o := NewOptimizer(cfg)
+
+	// llama-3: node-A fast and warm, node-B slow and cold, node-E worst.
+	for i := 0; i < 20; i++ {
+		o.RecordRequest("llama-3", "node-A", 20, true)
+	}
+	for i := 0; i < 10; i++ {
+		o.RecordRequest("llama-3", "node-B", 300, false)
+	}
+	o.RecordRequest("llama-3", "node-E", 300, false)
+	o.SetVRAMFit("node-E", "llama-3", 0.9)
+
+	// mistral: node-C and node-D alike, but it barely fits node-D's VRAM.
+	for i := 0; i < 10; i++ {
+		o.RecordRequest("mistral", "node-C", 50, true)
+		o.RecordRequest("mistral", "node-D", 50, true)
+	}
+	o.SetVRAMFit("node-D", "mistral", 0.97)
+
+	type key struct {
+		typ      RecommendationType
+		model    string
+		from, to string
+	}
+	got := make(map[key]bool)
+	for _, r := range o.Optimize() {
+		got[key{r.Type, r.ModelName, r.FromNode, r.ToNode}] = true
+	}
+	want := []key{
+		{RecommendMove, "llama-3", "node-E", "node-A"}, // worst host moves to the best
+		{RecommendEvict, "llama-3", "node-B", ""},      // low affinity
+		{RecommendEvict, "mistral", "node-D", ""},      // VRAM pressure
+		{RecommendPlace, "llama-3", "", "node-C"},      // best node without it
+		{RecommendPlace, "mistral", "", "node-A"},
+	}
+	for _, k := range want {
+		if !got[k] {
+			t.Errorf("missing %s %s %s→%s", k.typ, k.model, k.from, k.to)
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("got %d recommendations, want %d: %v", len(got), len(want), got)
+	}
+}
+
+func TestOptimize_EvictKeepsLastHost(t *testing.T) {
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	o := NewOptimizer(testConfig(base))
+	for i := 0; i < 10; i++ {
+		o.RecordRequest("llama-3", "node-A", 50, true)
+	}
+	o.SetVRAMFit("node-A", "llama-3", 0.99)
+
+	for _, r := range o.Optimize() {
+		if r.Type == RecommendEvict {
+			t.Errorf("evicted the only host: %+v", r)
+		}
+	}
+}
+
 func TestOptimize_NotEnoughData(t *testing.T) {
 	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
 	cfg := testConfig(base)
@@ -204,17 +436,15 @@ func TestScanRetirements(t *testing.T) {
 	o := NewOptimizer(cfg)
 
 	// Model last requested 60 days ago — should be retirement candidate.
-	o.mu.Lock()
-	o.popularity["old-model"] = &modelStats{
+	seedPopularity(o, "old-model", modelStats{
 		totalReqs: 5,
 		lastReq:   base.AddDate(0, 0, -60),
-	}
+	})
 	// Model last requested 10 days ago — should NOT be candidate.
-	o.popularity["recent-model"] = &modelStats{
+	seedPopularity(o, "recent-model", modelStats{
 		totalReqs: 50,
 		lastReq:   base.AddDate(0, 0, -10),
-	}
-	o.mu.Unlock()
+	})
 
 	candidates := o.ScanRetirements()
 	if len(candidates) != 1 {
@@ -234,9 +464,7 @@ func TestRetirementCandidates_Persists(t *testing.T) {
 	cfg.Now = func() time.Time { return base }
 	o := NewOptimizer(cfg)
 
-	o.mu.Lock()
-	o.popularity["old"] = &modelStats{lastReq: base.AddDate(0, 0, -90)}
-	o.mu.Unlock()
+	seedPopularity(o, "old", modelStats{lastReq: base.AddDate(0, 0, -90)})
 
 	o.ScanRetirements()
 
@@ -284,6 +512,107 @@ func TestReportHealthPattern(t *testing.T) {
 	}
 }
 
+func TestAggregateHealthInsights_WeightedAndExpired(t *testing.T) {
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	cfg := testConfig(base)
+	cfg.HealthMaxAge = 7 * 24 * time.Hour
+	o := NewOptimizer(cfg)
+
+	// A 500-node org with few failures, a 2-node org with many, and an
+	// ancient report that no longer counts
+	o.ReportHealthPattern(HealthPattern{OrgID: "big", AvgFailureRate: 0.01, AvgMTTR: 60,
+		NodeCount: 500, TaskVolume: 9900, ReportedAt: base.Add(-time.Hour)})
+	o.ReportHealthPattern(HealthPattern{OrgID: "small", AvgFailureRate: 0.5, AvgMTTR: 600,
+		NodeCount: 2, TaskVolume: 100, ReportedAt: base})
+	o.ReportHealthPattern(HealthPattern{OrgID: "gone", AvgFailureRate: 0.9, AvgMTTR: 6000,
+		NodeCount: 50, TaskVolume: 5000, ReportedAt: base.Add(-8 * 24 * time.Hour)})
+
+	insight := o.AggregateHealthInsights()
+	if insight.OrgCount != 2 || insight.ExpiredPatterns != 1 || insight.TotalTasks != 10_000 {
+		t.Fatalf("insight = %+v, want 2 orgs, 1 expired, 10000 tasks", insight)
+	}
+	if math.Abs(insight.AvgFailureRate-0.255) > 1e-9 {
+		t.Errorf("unweighted failure rate = %f, want 0.255", insight.AvgFailureRate)
+	}
+	if math.Abs(insight.WeightedFailureRate-0.0149) > 1e-9 { // (0.01*9900 + 0.5*100) / 10000
+		t.Errorf("weighted failure rate = %f, want 0.0149", insight.WeightedFailureRate)
+	}
+	if math.Abs(insight.WeightedMTTRSeconds-65.4) > 1e-9 {
+		t.Errorf("weighted MTTR = %f, want 65.4", insight.WeightedMTTRSeconds)
+	}
+}
+
+func TestReportHealthPattern_DifferentialPrivacy(t *testing.T) {
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	// u = 0.75 always draws +scale·ln 2; Epsilon 4 gives each figure a
+	// budget of 1, so the noise is its sensitivity · ln 2
+	cfg := testConfig(base)
+	cfg.HealthPrivacy = HealthPrivacyConfig{Epsilon: 4, MaxMTTR: 1000 * time.Second,
+		MaxNodeCount: 100, MaxTaskVolume: 10_000, Rand: func() float64 { return 0.75 }}
+	o := NewOptimizer(cfg)
+	o.ReportHealthPattern(HealthPattern{OrgID: "a", AvgFailureRate: 0.1, AvgMTTR: 100,
+		NodeCount: 10, TaskVolume: 1000, ReportedAt: base})
+	insight := o.AggregateHealthInsights()
+	if math.Abs(insight.AvgFailureRate-(0.1+math.Ln2)) > 1e-9 || math.Abs(insight.AvgMTTRSeconds-(100+1000*math.Ln2)) > 1e-6 ||
+		insight.TotalNodes != 79 || insight.TotalTasks != 7931 || insight.Epsilon != 4 {
+		t.Errorf("insight = %+v, want every figure shifted by its sensitivity · ln 2", insight)
+	}
+
+	// Noise averages out across reports; published figures stay in range
+	rng := rand.New(rand.NewPCG(1, 2))
+	cfg.HealthPrivacy = HealthPrivacyConfig{Epsilon: 8, Rand: func() float64 {
+		for {
+			if u := rng.Float64(); u > 0 {
+				return u
+			}
+		}
+	}}
+	o = NewOptimizer(cfg)
+	for i := range 2000 {
+		o.ReportHealthPattern(HealthPattern{OrgID: fmt.Sprintf("org-%d", i), AvgFailureRate: 0.2, ReportedAt: base})
+	}
+	insight = o.AggregateHealthInsights()
+	if math.Abs(insight.AvgFailureRate-0.2) > 0.05 || insight.AvgMTTRSeconds < 0 {
+		t.Errorf("mean of noisy failure rates = %f, want about 0.2", insight.AvgFailureRate)
+	}
+	for _, r := range o.OrgHealthReports() {
+		if r.FailureRate < 0 || r.FailureRate > 1 {
+			t.Fatalf("report failure rate %f out of range", r.FailureRate)
+		}
+	}
+
+	// Aggregate-only: no per-org reports, and nothing until enough orgs
+	cfg.HealthPrivacy = HealthPrivacyConfig{AggregateOnly: true}
+	o = NewOptimizer(cfg)
+	for i := range 2 {
+		o.ReportHealthPattern(HealthPattern{OrgID: fmt.Sprintf("org-%d", i), AvgFailureRate: 0.3, ReportedAt: base})
+	}
+	if insight := o.AggregateHealthInsights(); !insight.Withheld || insight.AvgFailureRate != 0 {
+		t.Errorf("two orgs in aggregate-only mode: %+v, want withheld", insight)
+	}
+	o.ReportHealthPattern(HealthPattern{OrgID: "org-2", AvgFailureRate: 0.3, ReportedAt: base})
+	if insight := o.AggregateHealthInsights(); insight.Withheld || math.Abs(insight.AvgFailureRate-0.3) > 1e-9 {
+		t.Errorf("three orgs in aggregate-only mode: %+v", insight)
+	}
+	if reports := o.OrgHealthReports(); reports != nil {
+		t.Errorf("aggregate-only mode produced %d org reports", len(reports))
+	}
+}
+
+func TestReportHealthPattern_NormalizesFailureType(t *testing.T) {
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	o := NewOptimizer(testConfig(base))
+
+	// Older builds report the anomaly detector's name, in any case
+	for i, ft := range []domain.FailureType{"HIGH_FAIL_RATE", "high_error_rate", "DISK_FULL"} {
+		o.ReportHealthPattern(HealthPattern{OrgID: fmt.Sprintf("org-%d", i), TopFailureType: ft, ReportedAt: base})
+	}
+	if got := o.AggregateHealthInsights().TopFailureType; got != domain.FailureHighErrorRate {
+		t.Errorf("top failure type = %s, want HIGH_ERROR_RATE", got)
+	}
+}
+
 func TestAggregateHealthInsights_Empty(t *testing.T) {
 	o := NewOptimizer(DefaultConfig())
 	insight := o.AggregateHealthInsights()
@@ -379,3 +708,132 @@ func TestReset(t *testing.T) {
 		t.Errorf("optimizations after reset = %d, want 0", st.TotalOptimizations)
 	}
 }
+
+// ─── Concurrency ────────────────────────────────────────────────────────────
+
+func TestPlanPrewarm_SeasonalPopularity(t *testing.T) {
+	now := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	cfg := testConfig(now)
+	cfg.Now = func() time.Time { return now }
+	o := NewOptimizer(cfg)
+
+	// Mornings are for coding, evenings for chat
+	for i := 0; i < 10; i++ {
+		o.RecordRequest("coder", "node-A", 50, true)
+	}
+	o.RecordRequest("chat", "node-B", 50, true)
+	o.RecordRequest("chat", "node-C", 90, false)
+	now = now.Add(11 * time.Hour)
+	for i := 0; i < 10; i++ {
+		o.RecordRequest("chat", "node-B", 50, true)
+	}
+
+	morning := time.Date(2025, 1, 2, 8, 55, 0, 0, time.UTC)
+	fc := o.ForecastModels(morning, morning.Add(10*time.Minute))
+	if len(fc) != 2 || fc[0].ModelName != "coder" || fc[0].Requests != 10 || math.Abs(fc[0].Share-10.0/12) > 1e-9 {
+		t.Fatalf("morning forecast = %+v", fc)
+	}
+
+	plan := o.PlanPrewarm(morning, morning.Add(10*time.Minute), DefaultPrewarmConfig())
+	if len(plan) != 2 || plan[0].ModelName != "coder" || plan[0].NodeID != "node-A" ||
+		plan[1].ModelName != "chat" || plan[1].NodeID != "node-B" {
+		t.Errorf("morning plan = %+v", plan)
+	}
+	if !plan[0].WarmBy.Equal(morning.Add(10 * time.Minute)) {
+		t.Errorf("WarmBy = %v", plan[0].WarmBy)
+	}
+
+	evening := time.Date(2025, 1, 2, 20, 0, 0, 0, time.UTC)
+	plan = o.PlanPrewarm(evening, evening.Add(10*time.Minute), PrewarmConfig{NodesPerModel: 2, MinShare: 0.5})
+	if len(plan) != 2 || plan[0].ModelName != "chat" || plan[0].Share != 1 {
+		t.Errorf("evening plan = %+v", plan)
+	}
+	if got := o.PlanPrewarm(evening.Add(-6*time.Hour), evening.Add(-5*time.Hour), PrewarmConfig{}); len(got) != 0 {
+		t.Errorf("quiet hours planned %+v", got)
+	}
+}
+
+func TestRecordRequest_Concurrent(t *testing.T) {
+	o := NewOptimizer(DefaultConfig())
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 500; i++ {
+				o.RecordRequest(fmt.Sprintf("model-%d", i%10), fmt.Sprintf("node-%d", g), 40, i%2 == 0)
+			}
+		}(g)
+	}
+	// Readers run alongside the writers
+	for i := 0; i < 20; i++ {
+		o.TopModels(5)
+		o.NodeAffinities("model-3")
+		o.Optimize()
+	}
+	wg.Wait()
+
+	var total int64
+	for _, m := range o.TopModels(10) {
+		total += m.TotalReqs
+	}
+	if total != 8*500 {
+		t.Errorf("total requests = %d, want %d", total, 8*500)
+	}
+	if st := o.Stats(); st.TrackedModels != 10 || st.TrackedNodes != 8 {
+		t.Errorf("Stats = %+v, want 10 models on 8 nodes", st)
+	}
+}
+
+// BenchmarkRecordRequest_Parallel records requests from every core. With
+// requests spread over many models the shards let throughput scale with
+// -cpu; a single hot model is the worst case, where every call contends
+// for the same shard.
+func BenchmarkRecordRequest_Parallel(b *testing.B) {
+	for _, models := range []int{1, 16, 256} {
+		b.Run(fmt.Sprintf("models=%d", models), func(b *testing.B) {
+			o := NewOptimizer(DefaultConfig())
+			names := make([]string, models)
+			for i := range names {
+				names[i] = fmt.Sprintf("model-%d", i)
+			}
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				i := 0
+				for pb.Next() {
+					o.RecordRequest(names[i%models], "node-1", 40, true)
+					i++
+				}
+			})
+		})
+	}
+}
+
+func TestPublishHealthInsights_OrgReports(t *testing.T) {
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	o := NewOptimizer(testConfig(base))
+
+	var got []OrgHealthReport
+	o.SetHealthReportHook(func(_ HealthInsight, reports []OrgHealthReport) { got = reports })
+
+	o.ReportHealthPattern(HealthPattern{OrgID: "org-a", AvgFailureRate: 0.21, AvgMTTR: 600, TopFailureType: "DISK_FULL", ReportedAt: base})
+	o.ReportHealthPattern(HealthPattern{OrgID: "org-b", AvgFailureRate: 0.10, AvgMTTR: 300, ReportedAt: base})
+	if _, reports := o.PublishHealthInsights(); reports != nil {
+		t.Fatalf("two orgs produced reports: %+v", reports)
+	}
+
+	// org-a's newer pattern replaces its old one
+	o.ReportHealthPattern(HealthPattern{OrgID: "org-c", AvgFailureRate: 0.05, AvgMTTR: 100, ReportedAt: base})
+	o.ReportHealthPattern(HealthPattern{OrgID: "org-a", AvgFailureRate: 0.21, AvgMTTR: 600, TopFailureType: "DISK_FULL", ReportedAt: base.Add(time.Hour)})
+	o.PublishHealthInsights()
+	if len(got) != 3 || got[0].OrgID != "org-a" {
+		t.Fatalf("reports = %+v", got)
+	}
+	a := got[0]
+	if a.MedianFailureRate != 0.10 || math.Abs(a.FailureRatio-2.1) > 1e-9 || a.MTTRRatio != 2 {
+		t.Errorf("org-a report = %+v", a)
+	}
+	if want := "your failure rate is 2.1× the network median for DISK_FULL; recovery takes 2.0× the median"; a.Summary != want {
+		t.Errorf("summary = %q, want %q", a.Summary, want)
+	}
+}
@@ -0,0 +1,136 @@
+package intelligence
+
+import (
+	"sort"
+	"time"
+)
+
+// ─── Forecast Pre-Warm ──────────────────────────────────────────────────────
+// When the auto-scaler forecasts a spike it only knows how much demand is
+// coming, not for what. Request history answers the second half: every
+// model's requests are counted by UTC hour of day, so the models popular in
+// the spike's hours can be loaded before it arrives, on the nodes with the
+// best affinity for them.
+
+// ModelForecast is a model's expected share of requests over a window.
+type ModelForecast struct {
+	ModelName string
+	Requests  int64   // requests historically seen in the window's hours
+	Share     float64 // Requests / all models' requests in those hours
+}
+
+// PrewarmConfig bounds a pre-warm plan.
+type PrewarmConfig struct {
+	MaxModels     int     // most models to pre-warm (default 3)
+	NodesPerModel int     // best-affinity nodes per model (default 1)
+	MinShare      float64 // skip models forecast below this share (default 0.05)
+}
+
+// DefaultPrewarmConfig returns the default plan bounds.
+func DefaultPrewarmConfig() PrewarmConfig {
+	return PrewarmConfig{
+		MaxModels:     3,
+		NodesPerModel: 1,
+		MinShare:      0.05,
+	}
+}
+
+// PreloadInstruction asks a node to load a model before a forecast spike.
+type PreloadInstruction struct {
+	ModelName string
+	NodeID    string
+	Share     float64   // the model's forecast share of requests
+	WarmBy    time.Time // when the spike is expected
+}
+
+// windowHours returns the UTC hours of day that [from, to] touches.
+func windowHours(from, to time.Time) []int {
+	if to.Before(from) {
+		from, to = to, from
+	}
+	var hours []int
+	seen := [24]bool{}
+	for t := from.UTC().Truncate(time.Hour); !t.After(to) && len(hours) < 24; t = t.Add(time.Hour) {
+		if h := t.Hour(); !seen[h] {
+			seen[h] = true
+			hours = append(hours, h)
+		}
+	}
+	return hours
+}
+
+// ForecastModels ranks models by their share of the requests historically
+// seen in the hours of day [from, to] covers, most requested first.
+func (o *Optimizer) ForecastModels(from, to time.Time) []ModelForecast {
+	hours := windowHours(from, to)
+	var out []ModelForecast
+	var total int64
+	for _, sh := range o.shards {
+		sh.mu.Lock()
+		for name, e := range sh.models {
+			if e.pop == nil {
+				continue
+			}
+			var n int64
+			for _, h := range hours {
+				n += e.pop.hourly[h]
+			}
+			if n > 0 {
+				out = append(out, ModelForecast{ModelName: name, Requests: n})
+				total += n
+			}
+		}
+		sh.mu.Unlock()
+	}
+	for i := range out {
+		out[i].Share = float64(out[i].Requests) / float64(total)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Requests != out[j].Requests {
+			return out[i].Requests > out[j].Requests
+		}
+		return out[i].ModelName < out[j].ModelName
+	})
+	return out
+}
+
+// PlanPrewarm picks the models forecast for [from, to] and the nodes to
+// load them on, best affinity first. Models with no node known to host
+// them are skipped.
+func (o *Optimizer) PlanPrewarm(from, to time.Time, cfg PrewarmConfig) []PreloadInstruction {
+	def := DefaultPrewarmConfig()
+	if cfg.MaxModels <= 0 {
+		cfg.MaxModels = def.MaxModels
+	}
+	if cfg.NodesPerModel <= 0 {
+		cfg.NodesPerModel = def.NodesPerModel
+	}
+	if cfg.MinShare < 0 {
+		cfg.MinShare = 0
+	}
+
+	var plan []PreloadInstruction
+	models := 0
+	for _, f := range o.ForecastModels(from, to) {
+		if models == cfg.MaxModels || f.Share < cfg.MinShare {
+			break
+		}
+		nodes := o.NodeAffinities(f.ModelName)
+		if len(nodes) == 0 {
+			continue
+		}
+		if len(nodes) > cfg.NodesPerModel {
+			nodes = nodes[:cfg.NodesPerModel]
+		}
+		for _, n := range nodes {
+			plan = append(plan, PreloadInstruction{
+				ModelName: f.ModelName,
+				NodeID:    n.NodeID,
+				Share:     f.Share,
+				WarmBy:    to,
+			})
+		}
+		models++
+	}
+	return plan
+}
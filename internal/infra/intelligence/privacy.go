@@ -0,0 +1,95 @@
+package intelligence
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"math"
+	"time"
+)
+
+// ─── Health Report Privacy ──────────────────────────────────────────────────
+// The Anonymizer hides who reported a pattern; summary statistics can still
+// give away what an org's fleet looks like. With HealthPrivacyConfig.Epsilon
+// set, ReportHealthPattern adds Laplace noise to every pattern before it is
+// stored, so the optimizer never holds an org's true figures:
+//
+//  1. Each figure is clipped to its range — failure rate to [0, 1], MTTR to
+//     MaxMTTR, node count to MaxNodeCount, task volume to MaxTaskVolume —
+//     and the range is its sensitivity: a report can be swapped for any
+//     other without the difference showing
+//  2. Epsilon is split evenly over the four figures, each getting noise of
+//     scale sensitivity / (Epsilon/4)
+//  3. Noisy values are stored unclamped, so the noise averages out across
+//     orgs; only the published figures are clamped to their valid range
+//
+// A smaller Epsilon is more private and noisier. With AggregateOnly set, no
+// per-org report leaves the optimizer, and the network-wide insight is
+// withheld until MinInsightOrgs orgs have reported.
+
+// HealthPrivacyConfig configures differential privacy for federated health
+// patterns. The zero value stores patterns as reported.
+type HealthPrivacyConfig struct {
+	Epsilon       float64       // privacy budget per report (0 = no noise)
+	MaxMTTR       time.Duration // MTTR clipping bound and sensitivity (default 24h)
+	MaxNodeCount  int           // node count clipping bound and sensitivity (default 1,000)
+	MaxTaskVolume int64         // task volume clipping bound and sensitivity (default 1,000,000)
+	AggregateOnly bool          // publish network aggregates only, no per-org reports
+
+	// Rand returns uniform samples in (0, 1); nil draws from crypto/rand,
+	// so the noise cannot be predicted and subtracted.
+	Rand func() float64
+}
+
+// healthNoiseFields is how many figures share a report's privacy budget.
+const healthNoiseFields = 4
+
+// withDefaults fills the zero bounds.
+func (c HealthPrivacyConfig) withDefaults() HealthPrivacyConfig {
+	if c.MaxMTTR <= 0 {
+		c.MaxMTTR = 24 * time.Hour
+	}
+	if c.MaxNodeCount <= 0 {
+		c.MaxNodeCount = 1_000
+	}
+	if c.MaxTaskVolume <= 0 {
+		c.MaxTaskVolume = 1_000_000
+	}
+	if c.Rand == nil {
+		c.Rand = cryptoUniform
+	}
+	return c
+}
+
+// privatize returns p with Laplace noise added to its figures, or p as is
+// when no privacy budget is set.
+func (c HealthPrivacyConfig) privatize(p HealthPattern) HealthPattern {
+	if c.Epsilon <= 0 {
+		return p
+	}
+	eps := c.Epsilon / healthNoiseFields
+	maxMTTR := c.MaxMTTR.Seconds()
+
+	p.AvgFailureRate = min(max(p.AvgFailureRate, 0), 1) + c.laplace(1/eps)
+	p.AvgMTTR = min(max(p.AvgMTTR, 0), maxMTTR) + c.laplace(maxMTTR/eps)
+	nodes := float64(min(max(p.NodeCount, 0), c.MaxNodeCount))
+	p.NodeCount = int(math.Round(nodes + c.laplace(float64(c.MaxNodeCount)/eps)))
+	volume := float64(min(max(p.TaskVolume, 0), c.MaxTaskVolume))
+	p.TaskVolume = int64(math.Round(volume + c.laplace(float64(c.MaxTaskVolume)/eps)))
+	return p
+}
+
+// laplace draws from the Laplace distribution centred on 0 with the given
+// scale.
+func (c HealthPrivacyConfig) laplace(scale float64) float64 {
+	u := c.Rand() - 0.5
+	return scale * math.Copysign(math.Log(1-2*math.Abs(u)), u)
+}
+
+// cryptoUniform returns a uniform sample in (0, 1) from crypto/rand.
+func cryptoUniform() float64 {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic("intelligence: crypto/rand: " + err.Error())
+	}
+	return (float64(binary.LittleEndian.Uint64(b[:])>>11) + 0.5) / (1 << 53)
+}
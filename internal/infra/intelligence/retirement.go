@@ -0,0 +1,237 @@
+package intelligence
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
+)
+
+// ─── Retirement Execution ───────────────────────────────────────────────────
+// ScanRetirements only says a model has gone unrequested here. Deleting it
+// is safe only if, at that moment:
+//
+//   - no in-flight request holds the model
+//   - no pin source protects it — the operator's pin, or a federation
+//     catalog that promises the model to its members
+//   - at least MinReplicas other nodes still host it, so the network does
+//     not lose its last copy because this node stopped seeing traffic
+//
+// ExecuteRetirement runs these checks — and that nobody has vetoed the
+// retirement (see Veto) — removes the model only if all of them pass, and
+// records the outcome either way.
+
+// RetirementConfig configures the retirement executor.
+type RetirementConfig struct {
+	MinReplicas int // other nodes that must still host a model (default 1)
+	HistorySize int // outcomes kept for RecentRetirements (default 100)
+
+	// NoticePeriod is how long an announced model must stay a candidate
+	// before its grace period starts, and GracePeriod how long that runs
+	// before the model is removed (see Advance)
+	NoticePeriod time.Duration // default 24h
+	GracePeriod  time.Duration // default 7 days
+
+	// Now is an injectable clock for testing.
+	Now func() time.Time
+}
+
+// DefaultRetirementConfig returns production defaults.
+func DefaultRetirementConfig() RetirementConfig {
+	return RetirementConfig{
+		MinReplicas:  1,
+		HistorySize:  100,
+		NoticePeriod: 24 * time.Hour,
+		GracePeriod:  7 * 24 * time.Hour,
+		Now:          time.Now,
+	}
+}
+
+// PinSource reports why a model must be kept, or "" if it need not be.
+type PinSource func(model string) string
+
+// RetirementHooks connect the retirement executor to the rest of the node.
+type RetirementHooks struct {
+	// InFlight returns how many requests currently hold model (nil = 0).
+	InFlight func(model string) int
+	// Pins are consulted in order; the first non-empty reason blocks.
+	Pins []PinSource
+	// Replicas returns the other nodes hosting model (nil = none known,
+	// which blocks every retirement while MinReplicas > 0).
+	Replicas func(model string) []string
+	// Remove deletes the model and logs it (required).
+	Remove func(model, reason string) error
+	// Announce tells the model's owners that it is going to be retired
+	// (nil = nobody to tell). An error keeps the model a candidate.
+	Announce func(RetirementStatus) error
+}
+
+// RetirementOutcome records one ExecuteRetirement call.
+type RetirementOutcome struct {
+	Model     string    `json:"model"`
+	Reason    string    `json:"reason"`            // why retirement was requested
+	Executed  bool      `json:"executed"`          // the model was removed
+	Blocked   string    `json:"blocked,omitempty"` // failed check or removal error
+	InFlight  int       `json:"in_flight"`         // requests holding the model
+	PinnedBy  string    `json:"pinned_by,omitempty"`
+	VetoedBy  string    `json:"vetoed_by,omitempty"`
+	Replicas  []string  `json:"replicas,omitempty"` // other nodes hosting it
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// Retirer deletes retirement candidates once they pass the safety checks.
+type Retirer struct {
+	cfg   RetirementConfig
+	hooks RetirementHooks
+
+	mu       sync.Mutex
+	history  []RetirementOutcome // ring buffer
+	histIdx  int
+	histFull bool
+	workflow map[string]*RetirementStatus // model → status, until retired or cancelled
+	wfHook   func(RetirementStatus)
+}
+
+// NewRetirer creates a retirement executor. Zero config fields take their
+// defaults; a negative MinReplicas disables the replica check.
+func NewRetirer(cfg RetirementConfig, hooks RetirementHooks) *Retirer {
+	def := DefaultRetirementConfig()
+	if cfg.MinReplicas == 0 {
+		cfg.MinReplicas = def.MinReplicas
+	}
+	if cfg.MinReplicas < 0 {
+		cfg.MinReplicas = 0
+	}
+	if cfg.HistorySize <= 0 {
+		cfg.HistorySize = def.HistorySize
+	}
+	if cfg.NoticePeriod <= 0 {
+		cfg.NoticePeriod = def.NoticePeriod
+	}
+	if cfg.GracePeriod <= 0 {
+		cfg.GracePeriod = def.GracePeriod
+	}
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+	return &Retirer{
+		cfg:      cfg,
+		hooks:    hooks,
+		history:  make([]RetirementOutcome, cfg.HistorySize),
+		workflow: make(map[string]*RetirementStatus),
+	}
+}
+
+// ExecuteRetirement removes model if it is not in use, not pinned, not
+// vetoed and hosted elsewhere. A failed check returns the outcome together
+// with domain.ErrRetirementInFlight, ErrRetirementProtected,
+// ErrRetirementVetoed or ErrRetirementLastReplica; nothing is removed in
+// that case.
+func (r *Retirer) ExecuteRetirement(model, reason string) (RetirementOutcome, error) {
+	out, err := r.execute(model, reason)
+	if err == nil {
+		if st, ok := r.markRetired(model, out.CheckedAt); ok {
+			r.notifyWorkflow([]RetirementStatus{st})
+		}
+	}
+	return out, err
+}
+
+// execute is ExecuteRetirement without the workflow update.
+func (r *Retirer) execute(model, reason string) (RetirementOutcome, error) {
+	if r.hooks.Remove == nil {
+		return RetirementOutcome{}, fmt.Errorf("intelligence: retirer has no Remove hook")
+	}
+	out := RetirementOutcome{Model: model, Reason: reason, CheckedAt: r.cfg.Now()}
+	err := r.check(&out)
+	if err == nil {
+		if err = r.hooks.Remove(model, reason); err == nil {
+			out.Executed = true
+		}
+	}
+	if err != nil {
+		out.Blocked = err.Error()
+	}
+	r.record(out)
+	return out, err
+}
+
+// check fills in the outcome's checks and returns the first that fails.
+func (r *Retirer) check(out *RetirementOutcome) error {
+	if r.hooks.InFlight != nil {
+		out.InFlight = r.hooks.InFlight(out.Model)
+	}
+	if out.InFlight > 0 {
+		return fmt.Errorf("retire %s: %d requests: %w", out.Model, out.InFlight, domain.ErrRetirementInFlight)
+	}
+	if v, ok := r.veto(out.Model); ok {
+		out.VetoedBy = v.By
+		return fmt.Errorf("retire %s: vetoed by %s: %w", out.Model, v.By, domain.ErrRetirementVetoed)
+	}
+	for _, pin := range r.hooks.Pins {
+		if by := pin(out.Model); by != "" {
+			out.PinnedBy = by
+			return fmt.Errorf("retire %s: pinned by %s: %w", out.Model, by, domain.ErrRetirementProtected)
+		}
+	}
+	if r.hooks.Replicas != nil {
+		out.Replicas = r.hooks.Replicas(out.Model)
+	}
+	if len(out.Replicas) < r.cfg.MinReplicas {
+		return fmt.Errorf("retire %s: %d other replicas, need %d: %w",
+			out.Model, len(out.Replicas), r.cfg.MinReplicas, domain.ErrRetirementLastReplica)
+	}
+	return nil
+}
+
+func (r *Retirer) record(out RetirementOutcome) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.history[r.histIdx] = out
+	r.histIdx = (r.histIdx + 1) % len(r.history)
+	if r.histIdx == 0 {
+		r.histFull = true
+	}
+}
+
+// RecentRetirements returns up to limit outcomes, newest first.
+func (r *Retirer) RecentRetirements(limit int) []RetirementOutcome {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	count := r.histIdx
+	if r.histFull {
+		count = len(r.history)
+	}
+	if limit > count {
+		limit = count
+	}
+	if limit <= 0 {
+		return nil
+	}
+	result := make([]RetirementOutcome, limit)
+	idx := r.histIdx
+	for i := range result {
+		idx--
+		if idx < 0 {
+			idx = len(r.history) - 1
+		}
+		result[i] = r.history[idx]
+	}
+	return result
+}
+
+// OtherHosts adapts a list of hosts (including self, as the availability
+// index reports them) to RetirementHooks.Replicas.
+func OtherHosts(selfID string, hosts func(model string) []string) func(string) []string {
+	return func(model string) []string {
+		var others []string
+		for _, id := range hosts(model) {
+			if id != selfID {
+				others = append(others, id)
+			}
+		}
+		return others
+	}
+}
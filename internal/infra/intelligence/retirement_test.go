@@ -0,0 +1,199 @@
+package intelligence
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
+)
+
+// fakeNode is the node state the retirement checks consult.
+type fakeNode struct {
+	inFlight map[string]int
+	pinned   map[string]string
+	hosts    map[string][]string // including self
+	removed  []string
+}
+
+func (f *fakeNode) hooks() RetirementHooks {
+	return RetirementHooks{
+		InFlight: func(m string) int { return f.inFlight[m] },
+		Pins:     []PinSource{func(m string) string { return f.pinned[m] }},
+		Replicas: OtherHosts("self", func(m string) []string { return f.hosts[m] }),
+		Remove: func(m, _ string) error {
+			f.removed = append(f.removed, m)
+			return nil
+		},
+	}
+}
+
+func TestExecuteRetirement_Checks(t *testing.T) {
+	node := &fakeNode{
+		inFlight: map[string]int{"busy": 2},
+		pinned:   map[string]string{"pinned": "operator"},
+		hosts: map[string][]string{
+			"busy":   {"self", "n2"},
+			"pinned": {"self", "n2"},
+			"last":   {"self"},
+			"idle":   {"n2", "self"},
+		},
+	}
+	r := NewRetirer(RetirementConfig{}, node.hooks())
+
+	tests := []struct {
+		model string
+		want  error
+	}{
+		{"busy", domain.ErrRetirementInFlight},
+		{"pinned", domain.ErrRetirementProtected},
+		{"last", domain.ErrRetirementLastReplica},
+		{"idle", nil},
+	}
+	for _, tt := range tests {
+		out, err := r.ExecuteRetirement(tt.model, "idle")
+		if !errors.Is(err, tt.want) {
+			t.Errorf("%s: err = %v, want %v", tt.model, err, tt.want)
+		}
+		if out.Executed != (tt.want == nil) {
+			t.Errorf("%s: executed = %v", tt.model, out.Executed)
+		}
+		if tt.want != nil && domain.CodeOf(err) != domain.CodeConflict {
+			t.Errorf("%s: code = %q, want conflict", tt.model, domain.CodeOf(err))
+		}
+	}
+	if len(node.removed) != 1 || node.removed[0] != "idle" {
+		t.Errorf("removed = %v, want [idle]", node.removed)
+	}
+
+	recent := r.RecentRetirements(10)
+	if len(recent) != 4 {
+		t.Fatalf("recorded %d outcomes, want 4", len(recent))
+	}
+	if recent[0].Model != "idle" || !recent[0].Executed || len(recent[0].Replicas) != 1 {
+		t.Errorf("newest outcome = %+v", recent[0])
+	}
+	if recent[2].PinnedBy != "operator" || recent[2].Blocked == "" {
+		t.Errorf("pinned outcome = %+v", recent[2])
+	}
+}
+
+func TestExecuteRetirement_ReplicaCheckDisabled(t *testing.T) {
+	node := &fakeNode{}
+	r := NewRetirer(RetirementConfig{MinReplicas: -1}, node.hooks())
+	if _, err := r.ExecuteRetirement("solo", "idle"); err != nil {
+		t.Fatalf("ExecuteRetirement: %v", err)
+	}
+	if len(node.removed) != 1 {
+		t.Errorf("removed = %v, want [solo]", node.removed)
+	}
+}
+
+func TestExecuteRetirement_RemoveFails(t *testing.T) {
+	hooks := (&fakeNode{hosts: map[string][]string{"m": {"n2"}}}).hooks()
+	hooks.Remove = func(string, string) error { return errors.New("disk busy") }
+	r := NewRetirer(RetirementConfig{HistorySize: 1}, hooks)
+
+	out, err := r.ExecuteRetirement("m", "idle")
+	if err == nil || out.Executed || out.Blocked != "disk busy" {
+		t.Errorf("outcome = %+v, err = %v", out, err)
+	}
+	if got := r.RecentRetirements(5); len(got) != 1 {
+		t.Errorf("history = %d outcomes, want 1", len(got))
+	}
+}
+
+func TestRetirementWorkflow_Advance(t *testing.T) {
+	clock := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	node := &fakeNode{hosts: map[string][]string{"old": {"self", "n2"}}}
+	hooks := node.hooks()
+	var announced []string
+	hooks.Announce = func(st RetirementStatus) error {
+		announced = append(announced, st.Model)
+		return nil
+	}
+	r := NewRetirer(RetirementConfig{
+		NoticePeriod: time.Hour,
+		GracePeriod:  24 * time.Hour,
+		Now:          func() time.Time { return clock },
+	}, hooks)
+	var persisted []RetirementState
+	r.SetWorkflowHook(func(st RetirementStatus) { persisted = append(persisted, st.State) })
+	scan := []RetirementCandidate{{ModelName: "old", Reason: "unused for 30 days"}}
+
+	steps := []struct {
+		after time.Duration
+		want  RetirementState
+	}{
+		{0, RetireAnnounced},
+		{30 * time.Minute, RetireAnnounced}, // notice period not served
+		{time.Hour, RetireGrace},
+		{12 * time.Hour, RetireGrace},
+		{24 * time.Hour, RetireRetired},
+	}
+	for _, s := range steps {
+		clock = clock.Add(s.after)
+		r.Advance(scan)
+		got := RetireRetired
+		if wf := r.Workflow(); len(wf) == 1 {
+			got = wf[0].State
+		}
+		if got != s.want {
+			t.Fatalf("after +%s: state = %s, want %s", s.after, got, s.want)
+		}
+	}
+	if len(node.removed) != 1 || len(announced) != 1 {
+		t.Errorf("removed %v, announced %v", node.removed, announced)
+	}
+	want := []RetirementState{RetireAnnounced, RetireGrace, RetireRetired}
+	if !slices.Equal(persisted, want) {
+		t.Errorf("hook saw %v, want %v", persisted, want)
+	}
+
+	// A model requested again leaves the workflow
+	r.Advance([]RetirementCandidate{{ModelName: "busy"}})
+	if st := r.Advance(nil); len(st) != 1 || st[0].State != RetireCancelled {
+		t.Errorf("unflagged model: %+v", st)
+	}
+}
+
+func TestRetirementWorkflow_Veto(t *testing.T) {
+	clock := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	node := &fakeNode{hosts: map[string][]string{"m": {"n2"}}}
+	r := NewRetirer(RetirementConfig{
+		NoticePeriod: time.Hour,
+		GracePeriod:  time.Hour,
+		Now:          func() time.Time { return clock },
+	}, node.hooks())
+	scan := []RetirementCandidate{{ModelName: "m"}}
+
+	r.Advance(scan)
+	clock = clock.Add(time.Hour)
+	r.Advance(scan) // grace period starts
+	r.Veto("m", RetirementVeto{By: "owner:alice", Until: clock.Add(48 * time.Hour)})
+
+	clock = clock.Add(2 * time.Hour)
+	r.Advance(scan)
+	if wf := r.Workflow(); len(wf) != 1 || wf[0].State != RetireVetoed {
+		t.Fatalf("workflow = %+v, want m vetoed", wf)
+	}
+	out, err := r.ExecuteRetirement("m", "operator request")
+	if !errors.Is(err, domain.ErrRetirementVetoed) || out.VetoedBy != "owner:alice" {
+		t.Errorf("ExecuteRetirement on a vetoed model: %+v, %v", out, err)
+	}
+	if len(node.removed) != 0 || len(r.InGracePeriod()) != 0 {
+		t.Fatalf("vetoed model removed or offered for eviction")
+	}
+
+	// Lifting the veto starts the workflow over
+	if _, err := r.LiftVeto("m"); err != nil {
+		t.Fatalf("LiftVeto: %v", err)
+	}
+	if _, err := r.LiftVeto("m"); !errors.Is(err, domain.ErrRetirementNotVetoed) {
+		t.Errorf("second LiftVeto: %v", err)
+	}
+	if st := r.Advance(scan); len(st) != 1 || st[0].State != RetireAnnounced {
+		t.Errorf("after lifting: %+v", st)
+	}
+}
@@ -0,0 +1,337 @@
+package intelligence
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
+)
+
+// ─── Retirement Workflow ────────────────────────────────────────────────────
+// A model the scan flags is not removed on the spot. Each scan's candidates
+// are handed to Advance, which moves every model one step along
+//
+//	CANDIDATE → ANNOUNCED → GRACE_PERIOD → RETIRED
+//
+//  1. CANDIDATE: flagged by ScanRetirements; the Announce hook tells the
+//     model's owners (retried on the next Advance if it fails)
+//  2. ANNOUNCED: once the model has stayed a candidate for NoticePeriod,
+//     its grace period starts
+//  3. GRACE_PERIOD: when GracePeriod has run out, ExecuteRetirement runs
+//     the safety checks; a failed check leaves the model in its grace
+//     period until the next Advance
+//  4. RETIRED: the model was removed
+//
+// A model the scan no longer flags — it was requested again — is
+// CANCELLED. Until it is retired, a model owner or a governance proposal
+// can Veto the retirement: the model is VETOED, ExecuteRetirement refuses
+// to remove it, and it stays so until the veto ends or is lifted, when it
+// starts over as a candidate. Every change is reported to the hook set
+// with SetWorkflowHook; RestoreWorkflow rebuilds the state after a restart.
+
+// RetirementState is a model's place in the retirement workflow.
+type RetirementState string
+
+const (
+	RetireCandidate RetirementState = "CANDIDATE"
+	RetireAnnounced RetirementState = "ANNOUNCED"
+	RetireGrace     RetirementState = "GRACE_PERIOD"
+	RetireRetired   RetirementState = "RETIRED"
+	RetireVetoed    RetirementState = "VETOED"
+	RetireCancelled RetirementState = "CANCELLED"
+)
+
+// Terminal reports whether the model has left the workflow.
+func (s RetirementState) Terminal() bool {
+	return s == RetireRetired || s == RetireCancelled
+}
+
+// RetirementVeto blocks a model's retirement.
+type RetirementVeto struct {
+	By     string    `json:"by"` // e.g. "owner:alice", "governance:<proposal>"
+	Reason string    `json:"reason,omitempty"`
+	At     time.Time `json:"at"`
+	Until  time.Time `json:"until"` // zero = until lifted
+}
+
+// RetirementStatus is a model's progress through the retirement workflow.
+type RetirementStatus struct {
+	Model       string          `json:"model"`
+	State       RetirementState `json:"state"`
+	Reason      string          `json:"reason,omitempty"` // why the scan flagged it
+	FlaggedAt   time.Time       `json:"flagged_at"`
+	AnnouncedAt time.Time       `json:"announced_at"`
+	GraceUntil  time.Time       `json:"grace_until"` // removal is attempted from then on
+	Veto        *RetirementVeto `json:"veto,omitempty"`
+	LastError   string          `json:"last_error,omitempty"` // failed announcement or check
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+// clone returns a copy that shares nothing with s.
+func (s *RetirementStatus) clone() RetirementStatus {
+	out := *s
+	if s.Veto != nil {
+		v := *s.Veto
+		out.Veto = &v
+	}
+	return out
+}
+
+// vetoedAt reports whether a veto is in effect at now.
+func (s *RetirementStatus) vetoedAt(now time.Time) bool {
+	return s.State == RetireVetoed && (s.Veto.Until.IsZero() || now.Before(s.Veto.Until))
+}
+
+// SetWorkflowHook installs a callback fired with every workflow change,
+// outside the retirer's lock. Terminal states are reported once and then
+// forgotten.
+func (r *Retirer) SetWorkflowHook(fn func(RetirementStatus)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.wfHook = fn
+}
+
+// Advance moves the workflow forward given the latest scan's candidates
+// and returns the statuses that changed.
+func (r *Retirer) Advance(candidates []RetirementCandidate) []RetirementStatus {
+	now := r.cfg.Now()
+	flagged := make(map[string]RetirementCandidate, len(candidates))
+	for _, c := range candidates {
+		flagged[c.ModelName] = c
+	}
+
+	var changed []RetirementStatus
+	var announce, retire []string
+	r.mu.Lock()
+	for name, st := range r.workflow {
+		_, still := flagged[name]
+		switch {
+		case st.State == RetireVetoed:
+			if st.vetoedAt(now) {
+				continue
+			}
+			if !still {
+				changed = append(changed, r.finishLocked(st, RetireCancelled, now))
+				continue
+			}
+			r.restartLocked(st, flagged[name].Reason, now)
+			announce = append(announce, name)
+		case !still:
+			changed = append(changed, r.finishLocked(st, RetireCancelled, now))
+		case st.State == RetireCandidate:
+			announce = append(announce, name)
+		case st.State == RetireAnnounced && !now.Before(st.AnnouncedAt.Add(r.cfg.NoticePeriod)):
+			st.State, st.GraceUntil, st.UpdatedAt = RetireGrace, now.Add(r.cfg.GracePeriod), now
+			changed = append(changed, st.clone())
+		case st.State == RetireGrace && !now.Before(st.GraceUntil):
+			retire = append(retire, name)
+		}
+	}
+	for name, c := range flagged {
+		if _, ok := r.workflow[name]; ok {
+			continue
+		}
+		st := &RetirementStatus{Model: name}
+		r.restartLocked(st, c.Reason, now)
+		r.workflow[name] = st
+		announce = append(announce, name)
+	}
+	r.mu.Unlock()
+
+	for _, name := range announce {
+		if st, ok := r.announce(name, now); ok {
+			changed = append(changed, st)
+		}
+	}
+	for _, name := range retire {
+		if st, ok := r.retireDue(name); ok {
+			changed = append(changed, st)
+		}
+	}
+	r.notifyWorkflow(changed)
+	return changed
+}
+
+// restartLocked makes st a fresh candidate.
+func (r *Retirer) restartLocked(st *RetirementStatus, reason string, now time.Time) {
+	*st = RetirementStatus{Model: st.Model, State: RetireCandidate, Reason: reason, FlaggedAt: now, UpdatedAt: now}
+}
+
+// finishLocked moves st to a terminal state and forgets it.
+func (r *Retirer) finishLocked(st *RetirementStatus, state RetirementState, now time.Time) RetirementStatus {
+	st.State, st.UpdatedAt = state, now
+	delete(r.workflow, st.Model)
+	return st.clone()
+}
+
+// announce runs the Announce hook for a candidate.
+func (r *Retirer) announce(name string, now time.Time) (RetirementStatus, bool) {
+	r.mu.Lock()
+	st, ok := r.workflow[name]
+	if !ok || st.State != RetireCandidate {
+		r.mu.Unlock()
+		return RetirementStatus{}, false
+	}
+	snap := st.clone()
+	r.mu.Unlock()
+
+	var err error
+	if r.hooks.Announce != nil {
+		err = r.hooks.Announce(snap)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if st, ok = r.workflow[name]; !ok || st.State != RetireCandidate {
+		return RetirementStatus{}, false
+	}
+	st.UpdatedAt = now
+	if err != nil {
+		st.LastError = fmt.Sprintf("announce: %v", err)
+		return st.clone(), true
+	}
+	st.State, st.AnnouncedAt, st.LastError = RetireAnnounced, now, ""
+	return st.clone(), true
+}
+
+// retireDue removes a model whose grace period has ended, if it passes the
+// safety checks.
+func (r *Retirer) retireDue(name string) (RetirementStatus, bool) {
+	r.mu.Lock()
+	st, ok := r.workflow[name]
+	if !ok || st.State != RetireGrace {
+		r.mu.Unlock()
+		return RetirementStatus{}, false
+	}
+	reason := st.Reason
+	r.mu.Unlock()
+
+	out, err := r.execute(name, reason)
+	if err == nil {
+		return r.markRetired(name, out.CheckedAt)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if st, ok = r.workflow[name]; !ok || st.State != RetireGrace {
+		return RetirementStatus{}, false
+	}
+	st.LastError, st.UpdatedAt = out.Blocked, out.CheckedAt
+	return st.clone(), true
+}
+
+// markRetired ends the workflow of a model ExecuteRetirement removed.
+func (r *Retirer) markRetired(model string, at time.Time) (RetirementStatus, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	st, ok := r.workflow[model]
+	if !ok {
+		return RetirementStatus{}, false
+	}
+	return r.finishLocked(st, RetireRetired, at), true
+}
+
+// Veto blocks model's retirement until v.Until (zero = until lifted). A
+// model not in the workflow yet is vetoed ahead of time.
+func (r *Retirer) Veto(model string, v RetirementVeto) RetirementStatus {
+	now := r.cfg.Now()
+	if v.At.IsZero() {
+		v.At = now
+	}
+	r.mu.Lock()
+	st, ok := r.workflow[model]
+	if !ok {
+		st = &RetirementStatus{Model: model, FlaggedAt: now}
+		r.workflow[model] = st
+	}
+	st.State, st.Veto, st.LastError, st.UpdatedAt = RetireVetoed, &v, "", now
+	out := st.clone()
+	r.mu.Unlock()
+	r.notifyWorkflow([]RetirementStatus{out})
+	return out
+}
+
+// LiftVeto ends a veto; the model starts over as a candidate if the next
+// scan still flags it. Returns domain.ErrRetirementNotVetoed if it is not
+// vetoed.
+func (r *Retirer) LiftVeto(model string) (RetirementStatus, error) {
+	now := r.cfg.Now()
+	r.mu.Lock()
+	st, ok := r.workflow[model]
+	if !ok || !st.vetoedAt(now) {
+		r.mu.Unlock()
+		return RetirementStatus{}, fmt.Errorf("lift veto on %s: %w", model, domain.ErrRetirementNotVetoed)
+	}
+	st.Veto.Until = now
+	st.UpdatedAt = now
+	out := st.clone()
+	r.mu.Unlock()
+	r.notifyWorkflow([]RetirementStatus{out})
+	return out, nil
+}
+
+// veto returns the veto in effect for model, if any.
+func (r *Retirer) veto(model string) (RetirementVeto, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	st, ok := r.workflow[model]
+	if !ok || !st.vetoedAt(r.cfg.Now()) {
+		return RetirementVeto{}, false
+	}
+	return *st.Veto, true
+}
+
+// Workflow returns every model in the workflow, by name.
+func (r *Retirer) Workflow() []RetirementStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]RetirementStatus, 0, len(r.workflow))
+	for _, st := range r.workflow {
+		out = append(out, st.clone())
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Model < out[j].Model })
+	return out
+}
+
+// InGracePeriod returns the models whose grace period has started: they
+// were announced and are not vetoed, so storage pressure may evict them
+// first.
+func (r *Retirer) InGracePeriod() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []string
+	for name, st := range r.workflow {
+		if st.State == RetireGrace {
+			out = append(out, name)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// RestoreWorkflow reloads persisted statuses, e.g. after a restart.
+// Terminal states are skipped.
+func (r *Retirer) RestoreWorkflow(statuses []RetirementStatus) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, st := range statuses {
+		if st.Model == "" || st.State.Terminal() || (st.State == RetireVetoed && st.Veto == nil) {
+			continue
+		}
+		st := st.clone()
+		r.workflow[st.Model] = &st
+	}
+}
+
+// notifyWorkflow reports changes to the hook.
+func (r *Retirer) notifyWorkflow(changed []RetirementStatus) {
+	r.mu.Lock()
+	hook := r.wfHook
+	r.mu.Unlock()
+	if hook == nil {
+		return
+	}
+	for _, st := range changed {
+		hook(st)
+	}
+}
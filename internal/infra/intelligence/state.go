@@ -0,0 +1,135 @@
+package intelligence
+
+import "time"
+
+// ─── Persistent State ───────────────────────────────────────────────────────
+// Popularity and affinity take weeks of traffic to learn, and the placement
+// gate counts optimization cycles; neither should start over with every
+// restart. ExportState snapshots the learned statistics and cycle counters,
+// RestoreState loads such a snapshot into a fresh optimizer. Recommendation
+// outcomes are persisted separately through the recommendation hook (see
+// ack.go). SLOs are configuration and are not part of the state.
+
+// ModelState is a model's learned popularity.
+type ModelState struct {
+	ModelName     string
+	TotalReqs     int64
+	LastRequested time.Time
+	LatencySum    float64
+	LatencyCount  int64
+	Hourly        [24]int64 // requests by UTC hour of day
+	Recent        [24]int64 // last-24h window: requests per bucket …
+	RecentHours   [24]int64 // … and the Unix hour each bucket counts
+}
+
+// AffinityState is a {node, model} pair's learned performance.
+type AffinityState struct {
+	NodeID       string
+	ModelName    string
+	Requests     int64
+	CacheHits    int64
+	CacheMisses  int64
+	LatencySum   float64
+	LatencyCount int64
+	VRAMFit      float64
+	SLOSamples   int64
+	SLOMet       int64
+}
+
+// State is everything the optimizer has learned.
+type State struct {
+	Models           []ModelState
+	Affinities       []AffinityState
+	LastOptimization time.Time
+	Optimizations    int64
+}
+
+// ExportState returns a snapshot of the learned state.
+func (o *Optimizer) ExportState() State {
+	o.mu.RLock()
+	st := State{LastOptimization: o.lastOptimization, Optimizations: o.optimizationCount}
+	o.mu.RUnlock()
+
+	for _, sh := range o.shards {
+		sh.mu.Lock()
+		for name, e := range sh.models {
+			if ms := e.pop; ms != nil {
+				st.Models = append(st.Models, ModelState{
+					ModelName:     name,
+					TotalReqs:     ms.totalReqs,
+					LastRequested: ms.lastReq,
+					LatencySum:    ms.latencySum,
+					LatencyCount:  ms.latencyCount,
+					Hourly:        ms.hourly,
+					Recent:        ms.recent.counts,
+					RecentHours:   ms.recent.hours,
+				})
+			}
+			for nodeID, as := range e.nodes {
+				st.Affinities = append(st.Affinities, AffinityState{
+					NodeID:       nodeID,
+					ModelName:    name,
+					Requests:     as.requests,
+					CacheHits:    as.cacheHits,
+					CacheMisses:  as.cacheMisses,
+					LatencySum:   as.latencySum,
+					LatencyCount: as.latencyCount,
+					VRAMFit:      as.vramFit,
+					SLOSamples:   as.sloSamples,
+					SLOMet:       as.sloMet,
+				})
+			}
+		}
+		sh.mu.Unlock()
+	}
+	return st
+}
+
+// RestoreState loads a snapshot taken by ExportState, replacing the
+// statistics of every model and {node, model} pair it contains. The cycle
+// counters are taken if they are ahead of the optimizer's own.
+func (o *Optimizer) RestoreState(st State) {
+	o.mu.Lock()
+	if st.Optimizations > o.optimizationCount {
+		o.optimizationCount = st.Optimizations
+	}
+	if st.LastOptimization.After(o.lastOptimization) {
+		o.lastOptimization = st.LastOptimization
+	}
+	o.mu.Unlock()
+
+	for _, m := range st.Models {
+		if m.ModelName == "" {
+			continue
+		}
+		sh := o.shard(m.ModelName)
+		sh.mu.Lock()
+		sh.entryLocked(m.ModelName).pop = &modelStats{
+			totalReqs:    m.TotalReqs,
+			recent:       recentWindow{counts: m.Recent, hours: m.RecentHours},
+			lastReq:      m.LastRequested,
+			latencySum:   m.LatencySum,
+			latencyCount: m.LatencyCount,
+			hourly:       m.Hourly,
+		}
+		sh.mu.Unlock()
+	}
+	for _, a := range st.Affinities {
+		if a.ModelName == "" || a.NodeID == "" {
+			continue
+		}
+		sh := o.shard(a.ModelName)
+		sh.mu.Lock()
+		sh.entryLocked(a.ModelName).nodes[a.NodeID] = &affinityStats{
+			requests:     a.Requests,
+			cacheHits:    a.CacheHits,
+			cacheMisses:  a.CacheMisses,
+			latencySum:   a.LatencySum,
+			latencyCount: a.LatencyCount,
+			vramFit:      a.VRAMFit,
+			sloSamples:   a.SLOSamples,
+			sloMet:       a.SLOMet,
+		}
+		sh.mu.Unlock()
+	}
+}
@@ -0,0 +1,47 @@
+package intelligence
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestOptimizer_ExportRestoreState(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	cfg := testConfig(now)
+	cfg.Now = func() time.Time { return now }
+	o := NewOptimizer(cfg)
+	for i := 0; i < 20; i++ {
+		o.RecordRequest("llama-3", "node-A", 20, true)
+	}
+	for i := 0; i < 10; i++ {
+		o.RecordRequest("llama-3", "node-B", 300, false)
+	}
+	o.SetVRAMFit("node-A", "llama-3", 0.4)
+	o.Optimize()
+	o.Optimize()
+
+	st := o.ExportState()
+	if len(st.Models) != 1 || len(st.Affinities) != 2 || st.Optimizations != 2 || !st.LastOptimization.Equal(now) {
+		t.Fatalf("ExportState = %+v", st)
+	}
+
+	// A fresh optimizer restored from the snapshot knows the same.
+	restored := NewOptimizer(cfg)
+	restored.RestoreState(st)
+	if !reflect.DeepEqual(restored.TopModels(5), o.TopModels(5)) {
+		t.Errorf("TopModels = %+v, want %+v", restored.TopModels(5), o.TopModels(5))
+	}
+	if !reflect.DeepEqual(restored.NodeAffinities("llama-3"), o.NodeAffinities("llama-3")) {
+		t.Errorf("NodeAffinities = %+v, want %+v", restored.NodeAffinities("llama-3"), o.NodeAffinities("llama-3"))
+	}
+	if !restored.GatePassed() || restored.Stats().TotalOptimizations != 2 {
+		t.Errorf("gate not restored: %+v", restored.Stats())
+	}
+
+	// The last-24h window keeps ageing from the restored buckets.
+	now = now.Add(25 * time.Hour)
+	if top := restored.TopModels(1); top[0].RecentReqs != 0 || top[0].TotalReqs != 30 {
+		t.Errorf("a day later TopModels = %+v, want 0 recent of 30", top)
+	}
+}
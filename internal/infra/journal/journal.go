@@ -0,0 +1,455 @@
+// Package journal keeps an event-sourced record of every task's lifecycle:
+// SUBMITTED, SCHEDULED, ASSIGNED, STARTED, COMPLETED (or FAILED), VERIFIED
+// and PAID. Each transition is appended to a Store before it takes effect.
+//
+//  1. Record rejects events that move a task backwards, so the journal is a
+//     valid history of every task it has seen
+//  2. New replays the store to rebuild the in-memory projection: the tasks
+//     still in flight and the latency of every stage
+//  3. Recover hands tasks that were in flight at the last shutdown back to
+//     their executor, journaling a RECOVERED event first
+//  4. Settle pays each task at most once: the store holds a single PAID event
+//     per task, and the payment itself must be idempotent on the task ID so a
+//     crash between paying and journaling is resolved on the next attempt
+//  5. The time between consecutive events is charged to the later stage,
+//     which gives end-to-end latency attribution per stage
+package journal
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
+	"github.com/tutu-network/tutu/internal/infra/observability"
+)
+
+// replayPage is the number of events read per query when rebuilding state.
+const replayPage = 1000
+
+// Store persists journal events. Implemented by *sqlite.DB.
+type Store interface {
+	AppendTaskEvent(e domain.TaskEvent) (int64, error)
+	ListTaskEvents(afterSeq int64, limit int) ([]domain.TaskEvent, error)
+	ListTaskEventsByTask(taskID string) ([]domain.TaskEvent, error)
+}
+
+// Config controls the journal.
+type Config struct {
+	Retention time.Duration    // how long finished tasks stay in memory (default: 24h)
+	Now       func() time.Time // clock (default: time.Now)
+}
+
+// DefaultConfig returns production defaults.
+func DefaultConfig() Config {
+	return Config{Retention: 24 * time.Hour}
+}
+
+// TaskState is the projection of one task's events.
+type TaskState struct {
+	TaskID    string               `json:"task_id"`
+	TaskType  domain.TaskType      `json:"task_type,omitempty"`
+	NodeID    string               `json:"node_id,omitempty"`
+	Last      domain.TaskEventType `json:"last"`
+	Attempts  int                  `json:"attempts"` // 1 + times recovered
+	Failed    bool                 `json:"failed,omitempty"`
+	Paid      bool                 `json:"paid,omitempty"`
+	Submitted time.Time            `json:"submitted,omitempty"`
+	Updated   time.Time            `json:"updated"`
+
+	stage int // lifecycle position the next event must exceed
+}
+
+// InFlight reports whether the task was accepted but has not finished.
+func (s TaskState) InFlight() bool {
+	return s.stage < domain.TaskEventCompleted.Stage()
+}
+
+// StageStats summarizes the latency charged to one stage.
+type StageStats struct {
+	Count  int64   `json:"count"`
+	MeanMs float64 `json:"mean_ms"`
+	MaxMs  float64 `json:"max_ms"`
+	LastMs float64 `json:"last_ms"`
+}
+
+func (s *StageStats) observe(d time.Duration) {
+	ms := float64(d) / float64(time.Millisecond)
+	s.Count++
+	s.MeanMs += (ms - s.MeanMs) / float64(s.Count)
+	if ms > s.MaxMs {
+		s.MaxMs = ms
+	}
+	s.LastMs = ms
+}
+
+// StageLatency is the time one task took to reach a stage.
+type StageLatency struct {
+	Stage     domain.TaskEventType `json:"stage"`
+	LatencyMs float64              `json:"latency_ms"`
+}
+
+// Breakdown attributes a task's end-to-end latency to its stages.
+type Breakdown struct {
+	TaskID  string         `json:"task_id"`
+	Stages  []StageLatency `json:"stages"`
+	TotalMs float64        `json:"total_ms"`
+}
+
+// Stats reports journal activity.
+type Stats struct {
+	Events     int64                               `json:"events"`
+	LastSeq    int64                               `json:"last_seq"`
+	Tasks      int                                 `json:"tasks"`
+	InFlight   int                                 `json:"in_flight"`
+	Recovered  int64                               `json:"recovered"`
+	Paid       int64                               `json:"paid"`
+	Duplicates int64                               `json:"duplicate_payments"`
+	Rejected   int64                               `json:"rejected"`
+	Stages     map[domain.TaskEventType]StageStats `json:"stages"`
+}
+
+// Journal records task events and maintains their projection. Thread-safe.
+type Journal struct {
+	mu       sync.Mutex
+	cfg      Config
+	store    Store
+	tasks    map[string]*TaskState
+	settling map[string]bool // tasks with a payment in progress
+	stages   map[domain.TaskEventType]*StageStats
+	stats    Stats
+	onEvent  []func(domain.TaskEvent) // live event observers
+}
+
+// New creates a journal and replays every stored event into it.
+func New(cfg Config, store Store) (*Journal, error) {
+	def := DefaultConfig()
+	if cfg.Retention <= 0 {
+		cfg.Retention = def.Retention
+	}
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+	j := &Journal{
+		cfg:      cfg,
+		store:    store,
+		tasks:    make(map[string]*TaskState),
+		settling: make(map[string]bool),
+		stages:   make(map[domain.TaskEventType]*StageStats),
+	}
+	for after := int64(0); ; {
+		events, err := store.ListTaskEvents(after, replayPage)
+		if err != nil {
+			return nil, fmt.Errorf("journal: replay: %w", err)
+		}
+		for _, e := range events {
+			j.apply(e, false)
+			after = e.Seq
+		}
+		if len(events) < replayPage {
+			break
+		}
+	}
+	return j, nil
+}
+
+// ─── Recording ──────────────────────────────────────────────────────────────
+
+// Record validates and appends an event, filling in its sequence number and
+// (if zero) timestamp. An event that would move a known task backwards
+// returns domain.ErrTaskEventOutOfOrder; a second payment returns
+// domain.ErrTaskAlreadyPaid. A task's first event may be at any stage, so
+// tasks accepted before the journal existed can still be settled.
+func (j *Journal) Record(e domain.TaskEvent) (domain.TaskEvent, error) {
+	if e.TaskID == "" {
+		return e, errors.New("journal: event has no task ID")
+	}
+	if e.Type.Stage() == 0 && e.Type != domain.TaskEventRecovered {
+		return e, fmt.Errorf("journal: unknown event type %q", e.Type)
+	}
+	if e.Timestamp.IsZero() {
+		e.Timestamp = j.cfg.Now()
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if err := j.check(e); err != nil {
+		if errors.Is(err, domain.ErrTaskAlreadyPaid) {
+			j.duplicate()
+		} else {
+			j.stats.Rejected++
+		}
+		return e, fmt.Errorf("journal: %s %s: %w", e.TaskID, e.Type, err)
+	}
+	seq, err := j.store.AppendTaskEvent(e)
+	if errors.Is(err, domain.ErrTaskAlreadyPaid) {
+		// Paid before the projection was pruned
+		j.duplicate()
+		return e, fmt.Errorf("journal: %s %s: %w", e.TaskID, e.Type, err)
+	}
+	if err != nil {
+		return e, fmt.Errorf("journal: append: %w", err)
+	}
+	e.Seq = seq
+	j.apply(e, true)
+	for _, fn := range j.onEvent {
+		fn(e)
+	}
+	return e, nil
+}
+
+// OnEvent registers fn to see every event Record accepts, in order, after
+// it is stored. Replayed events are not delivered. fn runs with the journal
+// locked and must not call back into it.
+func (j *Journal) OnEvent(fn func(domain.TaskEvent)) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.onEvent = append(j.onEvent, fn)
+}
+
+// check reports whether e may follow the task's recorded events.
+// Caller must hold j.mu.
+func (j *Journal) check(e domain.TaskEvent) error {
+	st, ok := j.tasks[e.TaskID]
+	if !ok {
+		return nil
+	}
+	switch {
+	case e.Type == domain.TaskEventPaid && st.Paid:
+		return domain.ErrTaskAlreadyPaid
+	case e.Type == domain.TaskEventRecovered:
+		if !st.InFlight() {
+			return domain.ErrTaskEventOutOfOrder
+		}
+	case e.Type.Stage() <= st.stage:
+		return domain.ErrTaskEventOutOfOrder
+	case st.Failed && (e.Type == domain.TaskEventVerified || e.Type == domain.TaskEventPaid):
+		return domain.ErrTaskEventOutOfOrder
+	}
+	return nil
+}
+
+// apply folds an event into the projection. live is false during replay,
+// when latency is not exported again. Caller must hold j.mu (or be New).
+func (j *Journal) apply(e domain.TaskEvent, live bool) {
+	j.stats.Events++
+	j.stats.LastSeq = e.Seq
+	st, ok := j.tasks[e.TaskID]
+	if !ok {
+		st = &TaskState{TaskID: e.TaskID, Attempts: 1}
+		j.tasks[e.TaskID] = st
+	} else if e.Type != domain.TaskEventRecovered {
+		j.observe(e.Type, e.Timestamp.Sub(st.Updated), live)
+	}
+
+	switch e.Type {
+	case domain.TaskEventSubmitted:
+		st.TaskType = e.TaskType
+		st.Submitted = e.Timestamp
+	case domain.TaskEventAssigned:
+		st.NodeID = e.NodeID
+	case domain.TaskEventFailed:
+		st.Failed = true
+	case domain.TaskEventPaid:
+		st.Paid = true
+		j.stats.Paid++
+	case domain.TaskEventRecovered:
+		// Resumed tasks are scheduled again from the start
+		st.Attempts++
+		st.stage = domain.TaskEventScheduled.Stage()
+		j.stats.Recovered++
+	}
+	if s := e.Type.Stage(); s > st.stage {
+		st.stage = s
+	}
+	st.Last = e.Type
+	st.Updated = e.Timestamp
+}
+
+// observe charges d to a stage. Caller must hold j.mu.
+func (j *Journal) observe(stage domain.TaskEventType, d time.Duration, live bool) {
+	if d < 0 {
+		d = 0
+	}
+	s, ok := j.stages[stage]
+	if !ok {
+		s = &StageStats{}
+		j.stages[stage] = s
+	}
+	s.observe(d)
+	if live {
+		observability.TaskStageLatency.WithLabelValues(string(stage)).Observe(float64(d) / float64(time.Millisecond))
+	}
+}
+
+// duplicate counts a refused payment. Caller must hold j.mu.
+func (j *Journal) duplicate() {
+	j.stats.Duplicates++
+	observability.TaskDuplicatePayments.Inc()
+}
+
+// Verify records that a task's result was verified by nodeID.
+func (j *Journal) Verify(taskID, nodeID, detail string) error {
+	_, err := j.Record(domain.TaskEvent{TaskID: taskID, Type: domain.TaskEventVerified, NodeID: nodeID, Detail: detail})
+	return err
+}
+
+// Settle pays for a task exactly once. pay moves the credits and must be
+// idempotent on the task ID: if the node crashed after paying but before
+// the PAID event was written, the next Settle calls pay again and relies on
+// it not to pay twice. A task that is already paid returns
+// domain.ErrTaskAlreadyPaid without calling pay.
+func (j *Journal) Settle(taskID, nodeID string, credits int64, pay func() error) error {
+	j.mu.Lock()
+	e := domain.TaskEvent{TaskID: taskID, Type: domain.TaskEventPaid, NodeID: nodeID, Credits: credits}
+	if err := j.check(e); err != nil || j.settling[taskID] {
+		if err == nil {
+			err = domain.ErrTaskAlreadyPaid
+		}
+		if errors.Is(err, domain.ErrTaskAlreadyPaid) {
+			j.duplicate()
+		}
+		j.mu.Unlock()
+		return fmt.Errorf("journal: settle %s: %w", taskID, err)
+	}
+	j.settling[taskID] = true
+	j.mu.Unlock()
+
+	defer func() {
+		j.mu.Lock()
+		delete(j.settling, taskID)
+		j.mu.Unlock()
+	}()
+	if err := pay(); err != nil {
+		return fmt.Errorf("journal: settle %s: %w", taskID, err)
+	}
+	_, err := j.Record(e)
+	return err
+}
+
+// ─── Recovery ───────────────────────────────────────────────────────────────
+
+// InFlight returns the tasks that were accepted but have not finished,
+// oldest first.
+func (j *Journal) InFlight() []TaskState {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	var out []TaskState
+	for _, st := range j.tasks {
+		if st.InFlight() {
+			out = append(out, *st)
+		}
+	}
+	sort.Slice(out, func(a, b int) bool {
+		if !out[a].Updated.Equal(out[b].Updated) {
+			return out[a].Updated.Before(out[b].Updated)
+		}
+		return out[a].TaskID < out[b].TaskID
+	})
+	return out
+}
+
+// Recover journals a RECOVERED event for every in-flight task and passes it
+// to resume. A task resume cannot restart is journaled as FAILED. It
+// returns the number of tasks resumed.
+func (j *Journal) Recover(resume func(TaskState) error) int {
+	resumed := 0
+	for _, st := range j.InFlight() {
+		if _, err := j.Record(domain.TaskEvent{TaskID: st.TaskID, Type: domain.TaskEventRecovered}); err != nil {
+			continue // finished since InFlight was read
+		}
+		st.Attempts++
+		if err := resume(st); err != nil {
+			_, _ = j.Record(domain.TaskEvent{TaskID: st.TaskID, Type: domain.TaskEventFailed, Detail: "recovery: " + err.Error()})
+			continue
+		}
+		resumed++
+	}
+	return resumed
+}
+
+// ─── Queries ────────────────────────────────────────────────────────────────
+
+// Task returns a task's projected state.
+func (j *Journal) Task(taskID string) (TaskState, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	st, ok := j.tasks[taskID]
+	if !ok {
+		return TaskState{}, false
+	}
+	return *st, true
+}
+
+// History returns every event recorded for a task, oldest first.
+func (j *Journal) History(taskID string) ([]domain.TaskEvent, error) {
+	events, err := j.store.ListTaskEventsByTask(taskID)
+	if err != nil {
+		return nil, fmt.Errorf("journal: history %s: %w", taskID, err)
+	}
+	return events, nil
+}
+
+// Replay returns up to limit events after sequence number after, oldest
+// first. Consumers page through the journal by passing the last Seq seen.
+func (j *Journal) Replay(after int64, limit int) ([]domain.TaskEvent, error) {
+	events, err := j.store.ListTaskEvents(after, limit)
+	if err != nil {
+		return nil, fmt.Errorf("journal: replay: %w", err)
+	}
+	return events, nil
+}
+
+// Breakdown attributes a task's latency to the stages it passed through.
+// Time spent before a restart is charged to RECOVERED.
+func (j *Journal) Breakdown(taskID string) (Breakdown, error) {
+	events, err := j.History(taskID)
+	if err != nil {
+		return Breakdown{}, err
+	}
+	b := Breakdown{TaskID: taskID, Stages: []StageLatency{}}
+	for i := 1; i < len(events); i++ {
+		d := events[i].Timestamp.Sub(events[i-1].Timestamp)
+		ms := float64(d) / float64(time.Millisecond)
+		b.Stages = append(b.Stages, StageLatency{Stage: events[i].Type, LatencyMs: ms})
+		b.TotalMs += ms
+	}
+	return b, nil
+}
+
+// Prune drops finished tasks that have not changed within the retention
+// window from memory. Their events stay in the store. It returns the number
+// of tasks dropped.
+func (j *Journal) Prune() int {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	cutoff := j.cfg.Now().Add(-j.cfg.Retention)
+	n := 0
+	for id, st := range j.tasks {
+		if !st.InFlight() && st.Updated.Before(cutoff) && !j.settling[id] {
+			delete(j.tasks, id)
+			n++
+		}
+	}
+	return n
+}
+
+// Stats returns journal statistics.
+func (j *Journal) Stats() Stats {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	st := j.stats
+	st.Tasks = len(j.tasks)
+	for _, t := range j.tasks {
+		if t.InFlight() {
+			st.InFlight++
+		}
+	}
+	st.Stages = make(map[domain.TaskEventType]StageStats, len(j.stages))
+	for k, v := range j.stages {
+		st.Stages[k] = *v
+	}
+	return st
+}
@@ -0,0 +1,204 @@
+package journal
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
+	"github.com/tutu-network/tutu/internal/infra/sqlite"
+)
+
+type fakeClock struct{ now time.Time }
+
+func (c *fakeClock) Now() time.Time          { return c.now }
+func (c *fakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func newTestDB(t *testing.T) *sqlite.DB {
+	t.Helper()
+	db, err := sqlite.Open(t.TempDir())
+	if err != nil {
+		t.Fatalf("Open() error: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func newTestJournal(t *testing.T, db *sqlite.DB, clock *fakeClock) *Journal {
+	t.Helper()
+	cfg := DefaultConfig()
+	cfg.Now = clock.Now
+	j, err := New(cfg, db)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	return j
+}
+
+// record appends an event one second after the previous one.
+func record(t *testing.T, j *Journal, clock *fakeClock, taskID string, typ domain.TaskEventType) error {
+	t.Helper()
+	clock.Advance(time.Second)
+	_, err := j.Record(domain.TaskEvent{TaskID: taskID, Type: typ, TaskType: domain.TaskInference})
+	return err
+}
+
+func TestJournal_LifecycleOrder(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	j := newTestJournal(t, newTestDB(t), clock)
+
+	for _, typ := range []domain.TaskEventType{
+		domain.TaskEventSubmitted, domain.TaskEventScheduled, domain.TaskEventAssigned,
+		domain.TaskEventStarted, domain.TaskEventCompleted,
+	} {
+		if err := record(t, j, clock, "t1", typ); err != nil {
+			t.Fatalf("Record(%s) error: %v", typ, err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		task string
+		typ  domain.TaskEventType
+		want error
+	}{
+		{"backwards", "t1", domain.TaskEventStarted, domain.ErrTaskEventOutOfOrder},
+		{"fail after complete", "t1", domain.TaskEventFailed, domain.ErrTaskEventOutOfOrder},
+		{"recover finished task", "t1", domain.TaskEventRecovered, domain.ErrTaskEventOutOfOrder},
+		{"verify", "t1", domain.TaskEventVerified, nil},
+		{"first event at any stage", "t2", domain.TaskEventStarted, nil},
+		{"fail", "t2", domain.TaskEventFailed, nil},
+		{"pay failed task", "t2", domain.TaskEventPaid, domain.ErrTaskEventOutOfOrder},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := record(t, j, clock, tt.task, tt.typ)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Record(%s %s) = %v, want %v", tt.task, tt.typ, err, tt.want)
+			}
+		})
+	}
+
+	b, err := j.Breakdown("t1")
+	if err != nil {
+		t.Fatalf("Breakdown() error: %v", err)
+	}
+	if len(b.Stages) != 5 || b.Stages[0].Stage != domain.TaskEventScheduled || b.TotalMs != 8000 {
+		t.Errorf("Breakdown() = %+v, want 5 stages over 8s", b)
+	}
+	st := j.Stats()
+	if st.Rejected != 4 || st.Stages[domain.TaskEventVerified].Count != 1 || st.Stages[domain.TaskEventVerified].MeanMs != 4000 {
+		t.Errorf("Stats() = %+v", st)
+	}
+}
+
+func TestJournal_SettleExactlyOnce(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	db := newTestDB(t)
+	j := newTestJournal(t, db, clock)
+	_ = record(t, j, clock, "t1", domain.TaskEventCompleted)
+
+	pays := 0
+	pay := func() error { pays++; return nil }
+	if err := j.Settle("t1", "node-a", 12, pay); err != nil {
+		t.Fatalf("Settle() error: %v", err)
+	}
+	if err := j.Settle("t1", "node-a", 12, pay); !errors.Is(err, domain.ErrTaskAlreadyPaid) {
+		t.Errorf("second Settle() = %v, want ErrTaskAlreadyPaid", err)
+	}
+	if pays != 1 {
+		t.Errorf("paid %d times, want 1", pays)
+	}
+
+	// A failed payment leaves the task unpaid
+	if err := j.Settle("t2", "node-a", 5, func() error { return errors.New("ledger down") }); err == nil {
+		t.Error("Settle() with failing pay succeeded")
+	}
+	if st, _ := j.Task("t2"); st.Paid {
+		t.Error("t2 marked paid after a failed payment")
+	}
+
+	// After a restart and prune the store still refuses a second payment
+	clock.Advance(48 * time.Hour)
+	j = newTestJournal(t, db, clock)
+	if n := j.Prune(); n != 1 {
+		t.Fatalf("Prune() = %d, want 1", n)
+	}
+	if err := j.Settle("t1", "node-a", 12, pay); !errors.Is(err, domain.ErrTaskAlreadyPaid) || pays != 2 {
+		t.Errorf("Settle() after prune = %v with %d pays; want ErrTaskAlreadyPaid after an idempotent retry", err, pays)
+	}
+	if st := j.Stats(); st.Paid != 1 || st.Duplicates != 1 {
+		t.Errorf("Stats() = %+v, want 1 paid and 1 duplicate", st)
+	}
+}
+
+func TestJournal_ReplayAndRecover(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	db := newTestDB(t)
+	j := newTestJournal(t, db, clock)
+	for _, typ := range []domain.TaskEventType{domain.TaskEventSubmitted, domain.TaskEventScheduled, domain.TaskEventStarted} {
+		_ = record(t, j, clock, "running", typ)
+	}
+	_ = record(t, j, clock, "queued", domain.TaskEventSubmitted)
+	_ = record(t, j, clock, "done", domain.TaskEventSubmitted)
+	_ = record(t, j, clock, "done", domain.TaskEventCompleted)
+
+	// Restart: the projection is rebuilt from the store
+	j = newTestJournal(t, db, clock)
+	inFlight := j.InFlight()
+	if len(inFlight) != 2 || inFlight[0].TaskID != "running" || inFlight[0].TaskType != domain.TaskInference {
+		t.Fatalf("InFlight() after replay = %+v, want running and queued", inFlight)
+	}
+
+	var resumed []string
+	n := j.Recover(func(st TaskState) error {
+		if st.TaskID == "queued" {
+			return errors.New("payload lost")
+		}
+		resumed = append(resumed, st.TaskID)
+		return nil
+	})
+	if n != 1 || len(resumed) != 1 || resumed[0] != "running" {
+		t.Fatalf("Recover() = %d resumed %v, want [running]", n, resumed)
+	}
+	if st, _ := j.Task("running"); st.Attempts != 2 || st.Last != domain.TaskEventRecovered {
+		t.Errorf("running after recovery = %+v", st)
+	}
+	if st, _ := j.Task("queued"); !st.Failed {
+		t.Errorf("queued after failed recovery = %+v, want failed", st)
+	}
+	// A recovered task runs through its stages again
+	if err := record(t, j, clock, "running", domain.TaskEventAssigned); err != nil {
+		t.Errorf("Record(ASSIGNED) after recovery: %v", err)
+	}
+
+	events, err := j.Replay(0, 3)
+	if err != nil || len(events) != 3 || events[2].Seq != 3 {
+		t.Fatalf("Replay(0, 3) = %+v, %v", events, err)
+	}
+	// 6 before the restart, 3 from recovery and the new ASSIGNED
+	if events, _ = j.Replay(events[2].Seq, 0); len(events) != 7 {
+		t.Errorf("Replay(3, 0) returned %d events, want 7", len(events))
+	}
+}
+
+func TestJournal_OnEventSeesAcceptedEventsOnly(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	j := newTestJournal(t, newTestDB(t), clock)
+	var seen []domain.TaskEventType
+	j.OnEvent(func(e domain.TaskEvent) {
+		if e.Seq == 0 {
+			t.Errorf("%s delivered without a sequence number", e.Type)
+		}
+		seen = append(seen, e.Type)
+	})
+
+	_ = record(t, j, clock, "t1", domain.TaskEventSubmitted)
+	_ = record(t, j, clock, "t1", domain.TaskEventStarted)
+	if err := record(t, j, clock, "t1", domain.TaskEventAssigned); err == nil {
+		t.Fatal("out-of-order ASSIGNED accepted")
+	}
+	if len(seen) != 2 || seen[1] != domain.TaskEventStarted {
+		t.Errorf("observed %v, want [SUBMITTED STARTED]", seen)
+	}
+}
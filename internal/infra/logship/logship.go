@@ -0,0 +1,575 @@
+// Package logship forwards the daemon's log output to a central collector,
+// so fleet operators can read logs from headless nodes.
+//
+//  1. The Shipper is an io.Writer installed next to stderr. Each complete
+//     line is parsed into an Entry ("[component] message", with a level
+//     taken from WARNING/ERROR prefixes) and redacted before it is queued
+//  2. Writes never block logging: the in-memory queue is bounded, and when
+//     it is full the oldest entries spill to the disk buffer
+//  3. Run sends batches of up to BatchSize entries every FlushInterval (or
+//     as soon as a batch fills). A failed batch goes to the disk buffer and
+//     the shipper backs off exponentially; a 429 or 503 with Retry-After
+//     is honoured — that is the collector's backpressure
+//  4. The disk buffer is a directory of batch files, drained oldest first
+//     once the collector answers again. Past MaxBufferBytes the oldest
+//     files are deleted and counted as dropped
+//  5. Entries are sent either as a plain JSON document or as OTLP/HTTP
+//     JSON logs (POST /v1/logs) for an OpenTelemetry collector
+package logship
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Format selects the wire format of shipped batches.
+type Format string
+
+const (
+	FormatJSON Format = "json" // {"node": ..., "entries": [...]} to Endpoint
+	FormatOTLP Format = "otlp" // OTLP/HTTP JSON logs to Endpoint/v1/logs
+)
+
+// Entry is one structured log line.
+type Entry struct {
+	Time      time.Time `json:"time"`
+	Level     string    `json:"level"`               // "info", "warn" or "error"
+	Component string    `json:"component,omitempty"` // from a "[component]" prefix
+	Message   string    `json:"message"`
+	Node      string    `json:"node,omitempty"`
+}
+
+// Rule redacts every match of Pattern with Replace (regexp.ReplaceAllString
+// syntax, so "${1}" keeps a capture group).
+type Rule struct {
+	Pattern string
+	Replace string
+}
+
+// DefaultRules redact credentials that commonly end up in log lines.
+var DefaultRules = []Rule{
+	{Pattern: `(?i)(bearer\s+)[A-Za-z0-9._~+/=-]+`, Replace: "${1}[REDACTED]"},
+	{Pattern: `(?i)(\b(?:password|passwd|secret|token|api_key|apikey)\s*[=:]\s*)[^\s&",]+`, Replace: "${1}[REDACTED]"},
+	{Pattern: `(://)[^/@\s:]+:[^/@\s]+@`, Replace: "${1}[REDACTED]@"},
+}
+
+// Config controls the shipper.
+type Config struct {
+	Endpoint       string              // collector URL (required)
+	Format         Format              // wire format (default FormatJSON)
+	Headers        map[string]string   // extra request headers, e.g. Authorization
+	Node           string              // node ID stamped on every entry
+	BatchSize      int                 // entries per request (default 500)
+	FlushInterval  time.Duration       // max wait before a partial batch is sent (default 5s)
+	MaxQueue       int                 // entries held in memory (default 10_000)
+	BufferDir      string              // disk buffer for outages ("" = memory only, overflow is dropped)
+	MaxBufferBytes int64               // disk buffer cap (default 64MB)
+	MaxBackoff     time.Duration       // longest wait after failures (default 5m)
+	Rules          []Rule              // redaction rules (default DefaultRules)
+	Redact         func(string) string // applied before Rules (e.g. stored secrets)
+	Client         *http.Client        // default: 30s timeout
+	Now            func() time.Time    // clock (default time.Now)
+}
+
+// DefaultConfig returns production defaults. Endpoint must still be set.
+func DefaultConfig() Config {
+	return Config{
+		Format:         FormatJSON,
+		BatchSize:      500,
+		FlushInterval:  5 * time.Second,
+		MaxQueue:       10_000,
+		MaxBufferBytes: 64 << 20,
+		MaxBackoff:     5 * time.Minute,
+		Rules:          DefaultRules,
+	}
+}
+
+// Stats reports shipper activity.
+type Stats struct {
+	Shipped     int64  `json:"shipped"`      // entries accepted by the collector
+	Batches     int64  `json:"batches"`      // successful requests
+	Failures    int64  `json:"failures"`     // failed requests
+	Spilled     int64  `json:"spilled"`      // entries written to the disk buffer
+	Dropped     int64  `json:"dropped"`      // entries lost to full buffers
+	Queued      int    `json:"queued"`       // entries in memory
+	BufferFiles int    `json:"buffer_files"` // batches waiting on disk
+	BufferBytes int64  `json:"buffer_bytes"`
+	LastError   string `json:"last_error,omitempty"`
+}
+
+// Shipper batches log lines and forwards them. Thread-safe.
+type Shipper struct {
+	mu      sync.Mutex
+	config  Config
+	rules   []*regexp.Regexp
+	repl    []string
+	partial string
+	queue   []Entry
+	seq     int64 // next buffer file number
+	stats   Stats
+	wake    chan struct{}
+	retryAt time.Time
+	backoff time.Duration
+}
+
+// New creates a shipper. Zero config fields take their defaults.
+func New(cfg Config) (*Shipper, error) {
+	def := DefaultConfig()
+	if cfg.Endpoint == "" {
+		return nil, fmt.Errorf("logship: endpoint is required")
+	}
+	switch cfg.Format {
+	case "":
+		cfg.Format = def.Format
+	case FormatJSON, FormatOTLP:
+	default:
+		return nil, fmt.Errorf("logship: unknown format %q", cfg.Format)
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = def.BatchSize
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = def.FlushInterval
+	}
+	if cfg.MaxQueue <= 0 {
+		cfg.MaxQueue = def.MaxQueue
+	}
+	if cfg.MaxBufferBytes <= 0 {
+		cfg.MaxBufferBytes = def.MaxBufferBytes
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = def.MaxBackoff
+	}
+	if cfg.Rules == nil {
+		cfg.Rules = def.Rules
+	}
+	if cfg.Client == nil {
+		cfg.Client = &http.Client{Timeout: 30 * time.Second}
+	}
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+
+	s := &Shipper{config: cfg, wake: make(chan struct{}, 1)}
+	for _, r := range cfg.Rules {
+		re, err := regexp.Compile(r.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("logship: redaction rule %q: %w", r.Pattern, err)
+		}
+		s.rules = append(s.rules, re)
+		s.repl = append(s.repl, r.Replace)
+	}
+	if cfg.BufferDir != "" {
+		if err := os.MkdirAll(cfg.BufferDir, 0o700); err != nil {
+			return nil, fmt.Errorf("logship: buffer dir: %w", err)
+		}
+		// Resume numbering after batches left by the last run
+		files, _ := s.bufferFiles()
+		for _, f := range files {
+			if n, err := strconv.ParseInt(strings.TrimSuffix(filepath.Base(f), ".json"), 10, 64); err == nil && n >= s.seq {
+				s.seq = n + 1
+			}
+		}
+	}
+	return s, nil
+}
+
+// SetNode sets the node ID stamped on entries written from now on.
+func (s *Shipper) SetNode(nodeID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.config.Node = nodeID
+}
+
+// ─── Intake ─────────────────────────────────────────────────────────────────
+
+// Write implements io.Writer. Incomplete trailing lines are held until
+// their newline arrives. It never blocks on the network.
+func (s *Shipper) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	text := s.partial + string(p)
+	lines := strings.Split(text, "\n")
+	s.partial = lines[len(lines)-1]
+	for _, line := range lines[:len(lines)-1] {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		s.enqueueLocked(s.parse(line))
+	}
+	if len(s.queue) >= s.config.BatchSize {
+		s.signal()
+	}
+	return len(p), nil
+}
+
+// logPrefix matches the standard logger's date and time prefix.
+var logPrefix = regexp.MustCompile(`^\d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2}(\.\d+)? `)
+
+// parse turns a log line into a redacted entry. Caller must hold s.mu.
+func (s *Shipper) parse(line string) Entry {
+	e := Entry{Time: s.config.Now(), Level: "info", Node: s.config.Node}
+	line = logPrefix.ReplaceAllString(line, "")
+	if strings.HasPrefix(line, "[") {
+		if end := strings.Index(line, "] "); end > 0 {
+			e.Component = line[1:end]
+			line = line[end+2:]
+		}
+	}
+	upper := strings.ToUpper(line)
+	switch {
+	case strings.HasPrefix(upper, "ERROR"), strings.HasPrefix(upper, "FATAL"), strings.HasPrefix(upper, "PANIC"):
+		e.Level = "error"
+	case strings.HasPrefix(upper, "WARNING"), strings.HasPrefix(upper, "WARN"):
+		e.Level = "warn"
+	}
+	e.Message = s.redact(line)
+	return e
+}
+
+// redact applies the configured redactor and rules. Caller must hold s.mu.
+func (s *Shipper) redact(text string) string {
+	if s.config.Redact != nil {
+		text = s.config.Redact(text)
+	}
+	for i, re := range s.rules {
+		text = re.ReplaceAllString(text, s.repl[i])
+	}
+	return text
+}
+
+// enqueueLocked queues an entry, spilling the oldest batch to disk when the
+// queue is full. Caller must hold s.mu.
+func (s *Shipper) enqueueLocked(e Entry) {
+	if len(s.queue) >= s.config.MaxQueue {
+		n := s.config.BatchSize
+		if n > len(s.queue) {
+			n = len(s.queue)
+		}
+		s.spillLocked(s.queue[:n])
+		s.queue = append(s.queue[:0:0], s.queue[n:]...)
+	}
+	s.queue = append(s.queue, e)
+}
+
+// signal wakes Run without blocking.
+func (s *Shipper) signal() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// ─── Shipping ───────────────────────────────────────────────────────────────
+
+// Run ships batches until ctx is done, then moves whatever is still queued
+// to the disk buffer so the next run sends it.
+func (s *Shipper) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.config.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			s.mu.Lock()
+			for len(s.queue) > 0 {
+				n := min(s.config.BatchSize, len(s.queue))
+				s.spillLocked(s.queue[:n])
+				s.queue = s.queue[n:]
+			}
+			s.mu.Unlock()
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+		s.Flush(ctx)
+	}
+}
+
+// Flush sends the disk buffer, oldest first, then the in-memory queue, and
+// stops at the first failure. It returns how many entries were shipped.
+// While backing off after a failure it sends nothing.
+func (s *Shipper) Flush(ctx context.Context) int {
+	s.mu.Lock()
+	if s.config.Now().Before(s.retryAt) {
+		s.mu.Unlock()
+		return 0
+	}
+	s.mu.Unlock()
+
+	shipped := 0
+	files, _ := s.bufferFiles()
+	for _, f := range files {
+		batch, err := readBatch(f)
+		if err != nil {
+			// Unreadable (torn write during a crash): nothing to salvage
+			s.mu.Lock()
+			s.stats.Dropped++
+			s.mu.Unlock()
+			os.Remove(f)
+			continue
+		}
+		if !s.send(ctx, batch) {
+			return shipped
+		}
+		os.Remove(f)
+		shipped += len(batch)
+	}
+
+	for {
+		s.mu.Lock()
+		n := min(s.config.BatchSize, len(s.queue))
+		batch := append([]Entry(nil), s.queue[:n]...)
+		s.queue = s.queue[n:]
+		s.mu.Unlock()
+		if len(batch) == 0 {
+			return shipped
+		}
+		if !s.send(ctx, batch) {
+			s.mu.Lock()
+			s.spillLocked(batch)
+			s.mu.Unlock()
+			return shipped
+		}
+		shipped += len(batch)
+	}
+}
+
+// send posts one batch and updates the backoff state. It reports whether
+// the collector accepted it.
+func (s *Shipper) send(ctx context.Context, batch []Entry) bool {
+	retryAfter, err := s.post(ctx, batch)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err == nil {
+		s.stats.Shipped += int64(len(batch))
+		s.stats.Batches++
+		s.stats.LastError = ""
+		s.backoff = 0
+		s.retryAt = time.Time{}
+		return true
+	}
+	s.stats.Failures++
+	s.stats.LastError = err.Error()
+	if s.backoff == 0 {
+		s.backoff = time.Second
+	} else {
+		s.backoff = min(2*s.backoff, s.config.MaxBackoff)
+	}
+	wait := max(s.backoff, retryAfter)
+	s.retryAt = s.config.Now().Add(wait)
+	return false
+}
+
+// post encodes and sends a batch, returning the collector's Retry-After.
+func (s *Shipper) post(ctx context.Context, batch []Entry) (time.Duration, error) {
+	url := s.config.Endpoint
+	var body any
+	if s.config.Format == FormatOTLP {
+		url = strings.TrimSuffix(url, "/")
+		if !strings.HasSuffix(url, "/v1/logs") {
+			url += "/v1/logs"
+		}
+		body = otlpLogs(batch)
+	} else {
+		body = struct {
+			Node    string  `json:"node,omitempty"`
+			Entries []Entry `json:"entries"`
+		}{s.config.Node, batch}
+	}
+	buf, err := json.Marshal(body)
+	if err != nil {
+		return 0, fmt.Errorf("logship: encode: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(buf))
+	if err != nil {
+		return 0, fmt.Errorf("logship: request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range s.config.Headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := s.config.Client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("logship: send: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode/100 == 2 {
+		return 0, nil
+	}
+	var retryAfter time.Duration
+	if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && secs > 0 {
+		retryAfter = time.Duration(secs) * time.Second
+	}
+	return retryAfter, fmt.Errorf("logship: collector returned HTTP %d", resp.StatusCode)
+}
+
+// Stats returns a snapshot of shipper activity.
+func (s *Shipper) Stats() Stats {
+	files, size := s.bufferFiles()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st := s.stats
+	st.Queued = len(s.queue)
+	st.BufferFiles = len(files)
+	st.BufferBytes = size
+	return st
+}
+
+// ─── Disk Buffer ────────────────────────────────────────────────────────────
+
+// spillLocked writes a batch to the disk buffer, evicting the oldest files
+// past MaxBufferBytes. Without a buffer dir the batch is dropped. Caller
+// must hold s.mu.
+func (s *Shipper) spillLocked(batch []Entry) {
+	if len(batch) == 0 {
+		return
+	}
+	if s.config.BufferDir == "" {
+		s.stats.Dropped += int64(len(batch))
+		return
+	}
+	buf, err := json.Marshal(batch)
+	if err != nil {
+		s.stats.Dropped += int64(len(batch))
+		return
+	}
+	path := filepath.Join(s.config.BufferDir, fmt.Sprintf("%016d.json", s.seq))
+	s.seq++
+	if err := os.WriteFile(path, buf, 0o600); err != nil {
+		s.stats.Dropped += int64(len(batch))
+		s.stats.LastError = fmt.Sprintf("logship: buffer: %v", err)
+		return
+	}
+	s.stats.Spilled += int64(len(batch))
+
+	files, size := s.bufferFiles()
+	for len(files) > 1 && size > s.config.MaxBufferBytes {
+		if info, err := os.Stat(files[0]); err == nil {
+			size -= info.Size()
+			if old, err := readBatch(files[0]); err == nil {
+				s.stats.Dropped += int64(len(old))
+			}
+		}
+		os.Remove(files[0])
+		files = files[1:]
+	}
+}
+
+// bufferFiles lists buffered batches oldest first, with their total size.
+func (s *Shipper) bufferFiles() ([]string, int64) {
+	if s.config.BufferDir == "" {
+		return nil, 0
+	}
+	files, _ := filepath.Glob(filepath.Join(s.config.BufferDir, "*.json"))
+	sort.Strings(files)
+	var size int64
+	for _, f := range files {
+		if info, err := os.Stat(f); err == nil {
+			size += info.Size()
+		}
+	}
+	return files, size
+}
+
+// readBatch loads one buffered batch.
+func readBatch(path string) ([]Entry, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var batch []Entry
+	if err := json.Unmarshal(buf, &batch); err != nil {
+		return nil, err
+	}
+	return batch, nil
+}
+
+// ─── OTLP Encoding ──────────────────────────────────────────────────────────
+
+type otlpValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+type otlpAttr struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+func attr(k, v string) otlpAttr {
+	return otlpAttr{Key: k, Value: otlpValue{StringValue: v}}
+}
+
+type otlpRecord struct {
+	TimeUnixNano   string     `json:"timeUnixNano"`
+	SeverityNumber int        `json:"severityNumber"`
+	SeverityText   string     `json:"severityText"`
+	Body           otlpValue  `json:"body"`
+	Attributes     []otlpAttr `json:"attributes,omitempty"`
+}
+
+// otlpSeverity maps levels to OTLP severity numbers.
+var otlpSeverity = map[string]int{"info": 9, "warn": 13, "error": 17}
+
+// otlpLogs encodes a batch as an OTLP ExportLogsServiceRequest. Entries
+// are grouped by node so each becomes one resource.
+func otlpLogs(batch []Entry) any {
+	type scopeLogs struct {
+		Scope struct {
+			Name string `json:"name"`
+		} `json:"scope"`
+		LogRecords []otlpRecord `json:"logRecords"`
+	}
+	type resourceLogs struct {
+		Resource struct {
+			Attributes []otlpAttr `json:"attributes"`
+		} `json:"resource"`
+		ScopeLogs []scopeLogs `json:"scopeLogs"`
+	}
+
+	byNode := make(map[string][]otlpRecord)
+	var nodes []string
+	for _, e := range batch {
+		r := otlpRecord{
+			TimeUnixNano:   strconv.FormatInt(e.Time.UnixNano(), 10),
+			SeverityNumber: otlpSeverity[e.Level],
+			SeverityText:   strings.ToUpper(e.Level),
+			Body:           otlpValue{StringValue: e.Message},
+		}
+		if e.Component != "" {
+			r.Attributes = []otlpAttr{attr("component", e.Component)}
+		}
+		if _, ok := byNode[e.Node]; !ok {
+			nodes = append(nodes, e.Node)
+		}
+		byNode[e.Node] = append(byNode[e.Node], r)
+	}
+
+	out := make([]resourceLogs, 0, len(nodes))
+	for _, node := range nodes {
+		var rl resourceLogs
+		rl.Resource.Attributes = []otlpAttr{attr("service.name", "tutu")}
+		if node != "" {
+			rl.Resource.Attributes = append(rl.Resource.Attributes, attr("service.instance.id", node))
+		}
+		sl := scopeLogs{LogRecords: byNode[node]}
+		sl.Scope.Name = "github.com/tutu-network/tutu"
+		rl.ScopeLogs = []scopeLogs{sl}
+		out = append(out, rl)
+	}
+	return map[string]any{"resourceLogs": out}
+}
@@ -0,0 +1,148 @@
+package logship
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// collector records shipped entries and fails while down is set.
+type collector struct {
+	mu      sync.Mutex
+	down    bool
+	entries []Entry
+}
+
+func (c *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.down {
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	var body struct {
+		Entries []Entry `json:"entries"`
+	}
+	_ = json.NewDecoder(r.Body).Decode(&body)
+	c.entries = append(c.entries, body.Entries...)
+}
+
+func TestShipper_ParsesAndRedacts(t *testing.T) {
+	c := &collector{}
+	srv := httptest.NewServer(c)
+	defer srv.Close()
+
+	s, err := New(Config{
+		Endpoint: srv.URL,
+		Node:     "node-1",
+		Redact:   func(text string) string { return strings.ReplaceAll(text, "hunter2", "[SECRET]") },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = s.Write([]byte("2026/01/01 12:00:00 [network] WARNING: peer unreachable token=abc123\n"))
+	_, _ = s.Write([]byte("[api] login with hunter2 via https://bob:pw@host/x"))
+	_, _ = s.Write([]byte(" done\n"))
+	if n := s.Flush(context.Background()); n != 2 {
+		t.Fatalf("Flush() = %d, want 2", n)
+	}
+
+	want := []Entry{
+		{Level: "warn", Component: "network", Message: "WARNING: peer unreachable token=[REDACTED]", Node: "node-1"},
+		{Level: "info", Component: "api", Message: "login with [SECRET] via https://[REDACTED]@host/x done", Node: "node-1"},
+	}
+	for i, w := range want {
+		got := c.entries[i]
+		got.Time = time.Time{}
+		if got != w {
+			t.Errorf("entry %d = %+v, want %+v", i, got, w)
+		}
+	}
+}
+
+func TestShipper_BuffersOnDiskDuringOutage(t *testing.T) {
+	c := &collector{down: true}
+	srv := httptest.NewServer(c)
+	defer srv.Close()
+
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	cfg := Config{
+		Endpoint:  srv.URL,
+		BatchSize: 2,
+		MaxQueue:  2,
+		BufferDir: t.TempDir(),
+		Now:       func() time.Time { return now },
+	}
+	s, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		_, _ = s.Write([]byte("[x] line\n"))
+	}
+	if n := s.Flush(context.Background()); n != 0 {
+		t.Fatalf("Flush() during outage = %d, want 0", n)
+	}
+	st := s.Stats()
+	if st.Failures != 1 || st.Queued != 1 || st.BufferFiles != 2 {
+		t.Fatalf("stats during outage = %+v, want 1 failure, 2 buffered batches, 1 queued", st)
+	}
+
+	// The collector asked for 30s; nothing is sent before then
+	c.mu.Lock()
+	c.down = false
+	c.mu.Unlock()
+	now = now.Add(10 * time.Second)
+	if n := s.Flush(context.Background()); n != 0 {
+		t.Errorf("Flush() inside Retry-After = %d, want 0", n)
+	}
+
+	// A restarted shipper resumes from the same buffer; the stopped one
+	// moved its queue there on shutdown
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	s.Run(ctx)
+	now = now.Add(30 * time.Second)
+	s2, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := s2.Flush(context.Background()); n != 5 {
+		t.Errorf("Flush() after recovery = %d, want 5", n)
+	}
+	if st := s2.Stats(); st.BufferFiles != 0 || st.Dropped != 0 {
+		t.Errorf("stats after recovery = %+v, want empty buffer, nothing dropped", st)
+	}
+	if len(c.entries) != 5 {
+		t.Errorf("collector got %d entries, want 5", len(c.entries))
+	}
+}
+
+func TestShipper_DropsWithoutBufferDir(t *testing.T) {
+	s, err := New(Config{Endpoint: "http://127.0.0.1:0", BatchSize: 1, MaxQueue: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = s.Write([]byte("a\nb\nc\n"))
+	if st := s.Stats(); st.Queued != 1 || st.Dropped != 2 {
+		t.Errorf("stats = %+v, want 1 queued, 2 dropped", st)
+	}
+}
+
+func TestNew_RejectsBadConfig(t *testing.T) {
+	for _, cfg := range []Config{
+		{},
+		{Endpoint: "http://x", Format: "syslog"},
+		{Endpoint: "http://x", Rules: []Rule{{Pattern: "("}}},
+	} {
+		if _, err := New(cfg); err == nil {
+			t.Errorf("New(%+v) succeeded", cfg)
+		}
+	}
+}
@@ -0,0 +1,324 @@
+package marketplace
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ─── Lineage ────────────────────────────────────────────────────────────────
+// Every listing can record how it was produced: the base model, the
+// fine-tune job that trained it, the digest of its training data and the
+// listing it was derived from. Publish checks the record against what the
+// store already knows — the parent must exist and share the base model, and
+// a fine-tune job must exist and have trained the base or the parent — so a
+// user can audit a derived model's whole ancestry before trusting it.
+
+// Provenance fields reported as gaps when a listing leaves them empty.
+const (
+	GapFineTuneJob = "finetune_job_id"
+	GapDataset     = "dataset_digest"
+	GapDigest      = "digest"
+)
+
+// LineageNode is one listing in a lineage.
+type LineageNode struct {
+	ID            string        `json:"id"`
+	ModelName     string        `json:"model_name"`
+	Version       string        `json:"version,omitempty"`
+	Creator       string        `json:"creator"`
+	BaseModel     string        `json:"base_model,omitempty"`
+	FineTuneJobID string        `json:"finetune_job_id,omitempty"`
+	DatasetDigest string        `json:"dataset_digest,omitempty"`
+	Digest        string        `json:"digest,omitempty"`
+	ParentID      string        `json:"parent_id,omitempty"`
+	Status        ListingStatus `json:"status"`
+	Depth         int           `json:"depth"`          // generations from the queried listing (ancestors < 0)
+	Gaps          []string      `json:"gaps,omitempty"` // provenance fields left empty
+}
+
+// Lineage is a listing's ancestry and descendants.
+type Lineage struct {
+	ListingID   string        `json:"listing_id"`
+	BaseModel   string        `json:"base_model,omitempty"`
+	Self        LineageNode   `json:"self"`
+	Ancestors   []LineageNode `json:"ancestors"`   // parent first, root last
+	Descendants []LineageNode `json:"descendants"` // breadth-first
+	// Complete is true when the listing and every ancestor record their
+	// fine-tune job, dataset digest and model digest.
+	Complete bool `json:"complete"`
+}
+
+// SetJobLookup makes Publish verify fine-tune job IDs. fn returns the base
+// model a job trained, and false for unknown jobs. Without it, job IDs are
+// recorded as given.
+func (s *Store) SetJobLookup(fn func(jobID string) (baseModel string, ok bool)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobBase = fn
+}
+
+// checkLineageLocked validates a new listing's provenance and fills in a
+// base model inherited from its parent or job. Caller must hold s.mu.
+func (s *Store) checkLineageLocked(l *Listing) error {
+	var parent *Listing
+	if l.ParentID != "" {
+		if l.ParentID == l.ID {
+			return fmt.Errorf("%w: %s lists itself as parent", ErrLineageConflict, l.ID)
+		}
+		p, ok := s.listings[l.ParentID]
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrParentNotFound, l.ParentID)
+		}
+		parent = p
+		// A derived model shares its parent's base model
+		switch {
+		case l.BaseModel == "":
+			l.BaseModel = parent.BaseModel
+		case parent.BaseModel != "" && l.BaseModel != parent.BaseModel:
+			return fmt.Errorf("%w: base model %q, parent %s is based on %q",
+				ErrLineageConflict, l.BaseModel, parent.ID, parent.BaseModel)
+		}
+	}
+
+	if l.FineTuneJobID == "" || s.jobBase == nil {
+		return nil
+	}
+	trained, ok := s.jobBase(l.FineTuneJobID)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownJob, l.FineTuneJobID)
+	}
+	switch {
+	case l.BaseModel == "":
+		l.BaseModel = trained
+	case trained == l.BaseModel:
+	case parent != nil && trained == parent.ModelName:
+	default:
+		return fmt.Errorf("%w: job %s trained %q, not %q", ErrLineageConflict, l.FineTuneJobID, trained, l.BaseModel)
+	}
+	return nil
+}
+
+// Lineage returns a listing's ancestors and descendants.
+func (s *Store) Lineage(id string) (Lineage, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	l, ok := s.listings[id]
+	if !ok {
+		return Lineage{}, ErrListingNotFound
+	}
+	out := Lineage{
+		ListingID:   id,
+		BaseModel:   l.BaseModel,
+		Self:        lineageNode(l, 0),
+		Ancestors:   []LineageNode{},
+		Descendants: []LineageNode{},
+	}
+	out.Complete = len(out.Self.Gaps) == 0
+
+	seen := map[string]bool{id: true}
+	for depth, cur := -1, l; cur.ParentID != "" && !seen[cur.ParentID]; depth-- {
+		p, ok := s.listings[cur.ParentID]
+		if !ok {
+			out.Complete = false
+			break
+		}
+		seen[p.ID] = true
+		node := lineageNode(p, depth)
+		out.Complete = out.Complete && len(node.Gaps) == 0
+		out.Ancestors = append(out.Ancestors, node)
+		cur = p
+	}
+
+	for _, d := range s.descendantsLocked(id) {
+		out.Descendants = append(out.Descendants, lineageNode(s.listings[d.id], d.depth))
+	}
+	return out, nil
+}
+
+type descendant struct {
+	id    string
+	depth int
+}
+
+// descendantsLocked lists every listing derived from id, breadth-first.
+// Caller must hold s.mu.
+func (s *Store) descendantsLocked(id string) []descendant {
+	var out []descendant
+	seen := map[string]bool{id: true}
+	queue := []descendant{{id, 0}}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for _, child := range s.children[cur.id] {
+			if seen[child] {
+				continue
+			}
+			seen[child] = true
+			d := descendant{child, cur.depth + 1}
+			out = append(out, d)
+			queue = append(queue, d)
+		}
+	}
+	return out
+}
+
+// lineageNode summarizes a listing's provenance.
+func lineageNode(l *Listing, depth int) LineageNode {
+	n := LineageNode{
+		ID:            l.ID,
+		ModelName:     l.ModelName,
+		Version:       l.Version,
+		Creator:       l.Creator,
+		BaseModel:     l.BaseModel,
+		FineTuneJobID: l.FineTuneJobID,
+		DatasetDigest: l.DatasetDigest,
+		Digest:        l.Digest,
+		ParentID:      l.ParentID,
+		Status:        l.Status,
+		Depth:         depth,
+	}
+	if l.FineTuneJobID == "" {
+		n.Gaps = append(n.Gaps, GapFineTuneJob)
+	}
+	if l.DatasetDigest == "" {
+		n.Gaps = append(n.Gaps, GapDataset)
+	}
+	if l.Digest == "" {
+		n.Gaps = append(n.Gaps, GapDigest)
+	}
+	return n
+}
+
+// ─── Lineage Graph ──────────────────────────────────────────────────────────
+
+// Graph node kinds.
+const (
+	NodeListing = "listing"
+	NodeBase    = "base_model"
+	NodeJob     = "finetune_job"
+	NodeDataset = "dataset"
+)
+
+// Graph edge kinds, each pointing from a listing to what produced it.
+const (
+	EdgeDerivedFrom = "derived_from" // listing → parent listing
+	EdgeBaseModel   = "base_model"   // root listing → base model
+	EdgeTrainedBy   = "trained_by"   // listing → fine-tune job
+	EdgeTrainedOn   = "trained_on"   // listing → dataset
+)
+
+// GraphNode is a vertex of a lineage graph.
+type GraphNode struct {
+	ID     string        `json:"id"` // "<kind>:<id>"
+	Kind   string        `json:"kind"`
+	Label  string        `json:"label"`
+	Status ListingStatus `json:"status,omitempty"` // listings only
+}
+
+// GraphEdge is a directed provenance edge.
+type GraphEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Kind string `json:"kind"`
+}
+
+// LineageGraph is the whole family a listing belongs to: its root ancestor,
+// everything derived from that root, and the base models, jobs and
+// datasets that produced them.
+type LineageGraph struct {
+	ListingID string      `json:"listing_id"`
+	Nodes     []GraphNode `json:"nodes"`
+	Edges     []GraphEdge `json:"edges"`
+}
+
+// LineageGraph exports the lineage family of a listing.
+func (s *Store) LineageGraph(id string) (LineageGraph, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	l, ok := s.listings[id]
+	if !ok {
+		return LineageGraph{}, ErrListingNotFound
+	}
+	// Climb to the root, then take everything below it
+	root := l
+	for seen := map[string]bool{l.ID: true}; root.ParentID != ""; {
+		p, ok := s.listings[root.ParentID]
+		if !ok || seen[p.ID] {
+			break
+		}
+		seen[p.ID] = true
+		root = p
+	}
+	members := []*Listing{root}
+	for _, d := range s.descendantsLocked(root.ID) {
+		members = append(members, s.listings[d.id])
+	}
+
+	g := LineageGraph{ListingID: id, Nodes: []GraphNode{}, Edges: []GraphEdge{}}
+	added := make(map[string]bool)
+	addNode := func(kind, id, label string, status ListingStatus) string {
+		key := kind + ":" + id
+		if !added[key] {
+			added[key] = true
+			g.Nodes = append(g.Nodes, GraphNode{ID: key, Kind: kind, Label: label, Status: status})
+		}
+		return key
+	}
+	for _, m := range members {
+		label := m.ModelName
+		if m.Version != "" {
+			label += " " + m.Version
+		}
+		self := addNode(NodeListing, m.ID, label, m.Status)
+		switch {
+		case m.ParentID != "" && s.listings[m.ParentID] != nil:
+			g.Edges = append(g.Edges, GraphEdge{From: self, To: NodeListing + ":" + m.ParentID, Kind: EdgeDerivedFrom})
+		case m.BaseModel != "":
+			g.Edges = append(g.Edges, GraphEdge{From: self, To: addNode(NodeBase, m.BaseModel, m.BaseModel, ""), Kind: EdgeBaseModel})
+		}
+		if m.FineTuneJobID != "" {
+			g.Edges = append(g.Edges, GraphEdge{From: self, To: addNode(NodeJob, m.FineTuneJobID, m.FineTuneJobID, ""), Kind: EdgeTrainedBy})
+		}
+		if m.DatasetDigest != "" {
+			g.Edges = append(g.Edges, GraphEdge{From: self, To: addNode(NodeDataset, m.DatasetDigest, shortDigest(m.DatasetDigest), ""), Kind: EdgeTrainedOn})
+		}
+	}
+	sort.Slice(g.Nodes, func(i, j int) bool { return g.Nodes[i].ID < g.Nodes[j].ID })
+	return g, nil
+}
+
+// DOT renders the graph in Graphviz DOT syntax.
+func (g LineageGraph) DOT() string {
+	shapes := map[string]string{
+		NodeListing: "box",
+		NodeBase:    "cylinder",
+		NodeJob:     "ellipse",
+		NodeDataset: "folder",
+	}
+	var b strings.Builder
+	b.WriteString("digraph lineage {\n\trankdir=BT;\n")
+	for _, n := range g.Nodes {
+		attrs := fmt.Sprintf("label=%q, shape=%s", n.Label, shapes[n.Kind])
+		if n.ID == NodeListing+":"+g.ListingID {
+			attrs += ", style=bold"
+		}
+		fmt.Fprintf(&b, "\t%q [%s];\n", n.ID, attrs)
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(&b, "\t%q -> %q [label=%q];\n", e.From, e.To, e.Kind)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// shortDigest abbreviates a digest for display.
+func shortDigest(d string) string {
+	d = strings.TrimPrefix(d, "sha256:")
+	if len(d) > 12 {
+		return d[:12]
+	}
+	return d
+}
@@ -29,6 +29,10 @@ var (
 	ErrDuplicateReview   = errors.New("already reviewed this model")
 	ErrModelUnverified   = errors.New("model has not passed quality checks")
 	ErrInsufficientFunds = errors.New("insufficient credits for download")
+	ErrParentNotFound    = errors.New("parent listing not found")
+	ErrUnknownJob        = errors.New("fine-tune job not found")
+	ErrLineageConflict   = errors.New("lineage does not match the parent listing or fine-tune job")
+	ErrModelBlocked      = errors.New("model or its creator is on the network blocklist")
 )
 
 // ─── Listing Types ──────────────────────────────────────────────────────────
@@ -75,6 +79,11 @@ type Listing struct {
 	CreatedAt    time.Time     `json:"created_at"`
 	PublishedAt  time.Time     `json:"published_at,omitempty"`
 	Benchmarks   Benchmarks    `json:"benchmarks"`
+
+	// Provenance — how the model was produced (see Lineage)
+	FineTuneJobID string `json:"finetune_job_id,omitempty"` // finetune.Coordinator job that trained it
+	DatasetDigest string `json:"dataset_digest,omitempty"`  // SHA-256 of the training data
+	ParentID      string `json:"parent_id,omitempty"`       // listing it was derived from
 }
 
 // Benchmarks holds verified performance metrics for a listed model.
@@ -151,6 +160,29 @@ type Store struct {
 	listings map[string]*Listing      // id → listing
 	reviews  map[string][]*Review     // listingID → reviews
 	checks   map[string]*QualityCheck // listingID → latest quality check
+	children map[string][]string      // parentID → derived listing IDs
+	jobBase  func(jobID string) (baseModel string, ok bool)
+	blocked  Blocklist
+}
+
+// Blocklist reports models and nodes blocked network-wide (e.g. a
+// gossip.Blocklist).
+type Blocklist interface {
+	ModelBlocked(digest string) bool
+	NodeBlocked(nodeID string) bool
+}
+
+// SetBlocklist hides listings whose model digest or creator is blocked from
+// Search and refuses their downloads. nil disables the check.
+func (s *Store) SetBlocklist(b Blocklist) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.blocked = b
+}
+
+// blockedLocked reports whether l is blocked. Caller must hold s.mu.
+func (s *Store) blockedLocked(l *Listing) bool {
+	return s.blocked != nil && (s.blocked.ModelBlocked(l.Digest) || s.blocked.NodeBlocked(l.Creator))
 }
 
 // NewStore creates a marketplace store.
@@ -160,6 +192,7 @@ func NewStore(cfg StoreConfig) *Store {
 		listings: make(map[string]*Listing),
 		reviews:  make(map[string][]*Review),
 		checks:   make(map[string]*QualityCheck),
+		children: make(map[string][]string),
 	}
 }
 
@@ -188,12 +221,20 @@ func (s *Store) Publish(listing Listing) error {
 		return fmt.Errorf("price %d outside allowed range [%d, %d]", listing.Price, s.config.MinPrice, s.config.MaxPrice)
 	}
 
+	// Validate provenance against the parent and the fine-tune job
+	if err := s.checkLineageLocked(&listing); err != nil {
+		return err
+	}
+
 	listing.Status = StatusPending
 	listing.CreatedAt = time.Now()
 	listing.Downloads = 0
 	listing.TotalRevenue = 0
 
 	s.listings[listing.ID] = &listing
+	if listing.ParentID != "" {
+		s.children[listing.ParentID] = append(s.children[listing.ParentID], listing.ID)
+	}
 	return nil
 }
 
@@ -211,14 +252,15 @@ func (s *Store) GetListing(id string) (*Listing, error) {
 }
 
 // Search finds listings matching category and/or text query.
-// Returns only APPROVED listings sorted by downloads (most popular first).
+// Returns only APPROVED listings that are not blocked, sorted by downloads
+// (most popular first).
 func (s *Store) Search(category Category, query string) []Listing {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
 	var results []Listing
 	for _, l := range s.listings {
-		if l.Status != StatusApproved {
+		if l.Status != StatusApproved || s.blockedLocked(l) {
 			continue
 		}
 		if category != "" && l.Category != category {
@@ -287,6 +329,9 @@ func (s *Store) RecordDownload(listingID string) (int64, error) {
 	if l.Status != StatusApproved {
 		return 0, ErrModelUnverified
 	}
+	if s.blockedLocked(l) {
+		return 0, ErrModelBlocked
+	}
 
 	l.Downloads++
 	creatorShare := l.Price * int64(s.config.CreatorSharePct) / 100
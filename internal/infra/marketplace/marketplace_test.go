@@ -1,7 +1,9 @@
 package marketplace
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 	"testing"
 )
 
@@ -172,6 +174,32 @@ func TestStore_RecordDownload_Unapproved(t *testing.T) {
 	}
 }
 
+type fakeBlocklist struct{ models, nodes map[string]bool }
+
+func (b fakeBlocklist) ModelBlocked(digest string) bool { return b.models[digest] }
+func (b fakeBlocklist) NodeBlocked(nodeID string) bool  { return b.nodes[nodeID] }
+
+func TestStore_Blocklist(t *testing.T) {
+	s := setupSearchStore(t)
+	s.Publish(Listing{ID: "4", ModelName: "Malware", Creator: "c", Price: 10, SizeBytes: 1, Digest: "bad"})
+	s.ApproveQuality(QualityCheck{ListingID: "4", Passed: true})
+	s.SetBlocklist(fakeBlocklist{models: map[string]bool{"bad": true}, nodes: map[string]bool{"b": true}})
+
+	if _, err := s.RecordDownload("4"); !errors.Is(err, ErrModelBlocked) {
+		t.Errorf("download of a blocked digest: err = %v, want ErrModelBlocked", err)
+	}
+	if _, err := s.RecordDownload("2"); !errors.Is(err, ErrModelBlocked) {
+		t.Errorf("download from a blocked creator: err = %v, want ErrModelBlocked", err)
+	}
+	var ids []string
+	for _, l := range s.Search("", "") {
+		ids = append(ids, l.ID)
+	}
+	if strings.Join(ids, ",") != "1,3" {
+		t.Errorf("search = %v, want [1 3]", ids)
+	}
+}
+
 // ─── Search Tests ───────────────────────────────────────────────────────────
 
 func setupSearchStore(t *testing.T) *Store {
@@ -460,3 +488,104 @@ func TestStore_ConcurrentPublishAndSearch(t *testing.T) {
 	}
 	// No panics = pass
 }
+
+// ─── Lineage Tests ──────────────────────────────────────────────────────────
+
+func TestStore_PublishChecksLineage(t *testing.T) {
+	s := NewStore(DefaultStoreConfig())
+	s.SetJobLookup(func(jobID string) (string, bool) {
+		switch jobID {
+		case "job-base":
+			return "llama3", true
+		case "job-child":
+			return "Coder", true // trained on top of the parent listing
+		}
+		return "", false
+	})
+	if err := s.Publish(Listing{ID: "root", ModelName: "Coder", Creator: "a", Price: 1, FineTuneJobID: "job-base"}); err != nil {
+		t.Fatalf("Publish(root): %v", err)
+	}
+	if l, _ := s.GetListing("root"); l.BaseModel != "llama3" {
+		t.Errorf("root base model = %q, want llama3 from its job", l.BaseModel)
+	}
+
+	tests := []struct {
+		name    string
+		listing Listing
+		want    error
+	}{
+		{"missing parent", Listing{ID: "x1", ParentID: "ghost"}, ErrParentNotFound},
+		{"own parent", Listing{ID: "x2", ParentID: "x2"}, ErrLineageConflict},
+		{"base disagrees with parent", Listing{ID: "x3", ParentID: "root", BaseModel: "mistral"}, ErrLineageConflict},
+		{"unknown job", Listing{ID: "x4", FineTuneJobID: "job-ghost"}, ErrUnknownJob},
+		{"job trained another model", Listing{ID: "x5", BaseModel: "mistral", FineTuneJobID: "job-base"}, ErrLineageConflict},
+		{"job trained the parent", Listing{ID: "child", ParentID: "root", FineTuneJobID: "job-child"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.listing.Creator, tt.listing.Price = "b", 1
+			if err := s.Publish(tt.listing); !errors.Is(err, tt.want) {
+				t.Errorf("Publish() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+	if l, _ := s.GetListing("child"); l.BaseModel != "llama3" {
+		t.Errorf("child base model = %q, want llama3 inherited from root", l.BaseModel)
+	}
+}
+
+func TestStore_LineageAndGraph(t *testing.T) {
+	s := NewStore(DefaultStoreConfig())
+	full := func(id, parent string) Listing {
+		return Listing{ID: id, ModelName: id, BaseModel: "llama3", Creator: "a", Price: 1, ParentID: parent,
+			FineTuneJobID: "job-" + id, DatasetDigest: "sha256:" + id, Digest: "d-" + id}
+	}
+	for _, l := range []Listing{full("a", ""), full("b", "a"), full("c", "b"), full("d", "a")} {
+		if err := s.Publish(l); err != nil {
+			t.Fatalf("Publish(%s): %v", l.ID, err)
+		}
+	}
+
+	lin, err := s.Lineage("b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lin.Ancestors) != 1 || lin.Ancestors[0].ID != "a" || lin.Ancestors[0].Depth != -1 {
+		t.Errorf("ancestors = %+v, want [a at -1]", lin.Ancestors)
+	}
+	if len(lin.Descendants) != 1 || lin.Descendants[0].ID != "c" || !lin.Complete {
+		t.Errorf("descendants = %+v complete = %v, want [c], complete", lin.Descendants, lin.Complete)
+	}
+
+	// An ancestor without a dataset digest makes the lineage incomplete
+	gap := full("e", "c")
+	gap.DatasetDigest = ""
+	s.Publish(gap)
+	s.Publish(full("f", "e"))
+	if lin, _ := s.Lineage("f"); lin.Complete || len(lin.Ancestors) != 4 {
+		t.Errorf("Lineage(f) complete = %v with %d ancestors, want incomplete with 4", lin.Complete, len(lin.Ancestors))
+	}
+
+	// The graph covers the whole family, whichever member is asked about
+	g, err := s.LineageGraph("d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	listings, derived := 0, 0
+	for _, n := range g.Nodes {
+		if n.Kind == NodeListing {
+			listings++
+		}
+	}
+	for _, e := range g.Edges {
+		if e.Kind == EdgeDerivedFrom {
+			derived++
+		}
+	}
+	if listings != 6 || derived != 5 {
+		t.Errorf("graph has %d listings and %d derived_from edges, want 6 and 5", listings, derived)
+	}
+	if dot := g.DOT(); !strings.Contains(dot, `"listing:d" [label="d", shape=box, style=bold]`) {
+		t.Errorf("DOT() does not highlight the queried listing:\n%s", dot)
+	}
+}
@@ -25,6 +25,78 @@ var InferenceTokens = promauto.NewCounterVec(prometheus.CounterOpts{
 	Help:      "Total tokens generated.",
 }, []string{"model"})
 
+// InferenceBatchSize tracks embedding requests coalesced per backend call.
+var InferenceBatchSize = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: "tutu",
+	Name:      "inference_batch_size",
+	Help:      "Embedding requests executed per batched backend call.",
+	Buckets:   []float64{1, 2, 4, 8, 16, 32},
+}, []string{"model"})
+
+// InferenceBatchWait tracks the queueing delay batching adds to an embedding request.
+var InferenceBatchWait = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: "tutu",
+	Name:      "inference_batch_wait_seconds",
+	Help:      "Longest time a request in the batch waited before execution.",
+	Buckets:   []float64{0.001, 0.002, 0.005, 0.01, 0.025, 0.05, 0.1},
+}, []string{"model"})
+
+// InferenceStreamsAborted counts token streams that ended before completion.
+var InferenceStreamsAborted = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "tutu",
+	Name:      "inference_streams_aborted_total",
+	Help:      "Token streams stopped early, by reason (cancelled, slow_consumer, backend_eof).",
+}, []string{"model", "reason"})
+
+// InferenceTokensDropped counts tokens discarded under the drop backpressure policy.
+var InferenceTokensDropped = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "tutu",
+	Name:      "inference_tokens_dropped_total",
+	Help:      "Tokens discarded because the consumer fell behind.",
+}, []string{"model"})
+
+// SpeculativeDrafted counts tokens proposed by draft models.
+var SpeculativeDrafted = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "tutu",
+	Name:      "speculative_drafted_tokens_total",
+	Help:      "Tokens proposed by the draft model during speculative decoding.",
+}, []string{"model"})
+
+// SpeculativeAccepted counts drafted tokens accepted by the target model.
+var SpeculativeAccepted = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "tutu",
+	Name:      "speculative_accepted_tokens_total",
+	Help:      "Drafted tokens accepted by the target model.",
+}, []string{"model"})
+
+// SpeculativeSpeedup tracks decode throughput relative to the benchmarked baseline.
+var SpeculativeSpeedup = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: "tutu",
+	Name:      "speculative_speedup_ratio",
+	Help:      "Speculative tokens/sec divided by the model's benchmarked tokens/sec without a draft (0 = not benchmarked yet).",
+}, []string{"model"})
+
+// KVCacheTokens counts prompt tokens by whether they were evaluated or reused from the KV cache.
+var KVCacheTokens = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "tutu",
+	Name:      "kvcache_tokens_total",
+	Help:      "Prompt tokens evaluated (kind=evaluated) or reused from the KV cache (kind=cached).",
+}, []string{"model", "kind"})
+
+// KVCacheSessions counts pinned requests by how warm their KV-cache slot was.
+var KVCacheSessions = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "tutu",
+	Name:      "kvcache_sessions_total",
+	Help:      "Pinned requests by KV-cache outcome (resident, restored, prefix, cold).",
+}, []string{"model", "outcome"})
+
+// KVCacheSavedSeconds estimates prompt evaluation time saved by KV-cache reuse.
+var KVCacheSavedSeconds = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "tutu",
+	Name:      "kvcache_saved_seconds_total",
+	Help:      "Estimated prompt evaluation time saved by reusing cached tokens.",
+}, []string{"model"})
+
 // ─── Tasks ──────────────────────────────────────────────────────────────────
 
 // TasksCompleted tracks completed tasks by type.
@@ -56,6 +128,58 @@ var TaskAssignLatency = promauto.NewHistogram(prometheus.HistogramOpts{
 	Buckets:   []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5},
 })
 
+// SchedulerRerouted counts selections that skipped the best-scoring node.
+var SchedulerRerouted = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "tutu",
+	Name:      "scheduler_rerouted_total",
+	Help:      "Scheduling decisions that skipped the best-scoring node, by reason (incident, quarantined).",
+}, []string{"reason"})
+
+// AffinityRoutes counts hinted inference requests by affinity outcome.
+var AffinityRoutes = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "tutu",
+	Name:      "affinity_routes_total",
+	Help:      "Inference requests by routing-hint affinity outcome (none, new, hit, moved).",
+}, []string{"outcome"})
+
+// InferenceWarm counts inference requests by whether their model was
+// already loaded, with and without a routing hint.
+var InferenceWarm = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "tutu",
+	Name:      "inference_warm_total",
+	Help:      "Inference requests by routing hint (true, false) and whether the model was already loaded (warm, cold).",
+}, []string{"hinted", "cache"})
+
+// ─── Namespaces ─────────────────────────────────────────────────────────────
+
+// NamespaceRequests counts inference requests admitted per namespace.
+var NamespaceRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "tutu",
+	Name:      "namespace_requests_total",
+	Help:      "Inference requests admitted, by namespace and model.",
+}, []string{"namespace", "model"})
+
+// NamespaceTokens counts tokens generated per namespace.
+var NamespaceTokens = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "tutu",
+	Name:      "namespace_tokens_total",
+	Help:      "Tokens generated, by namespace and model.",
+}, []string{"namespace", "model"})
+
+// NamespaceRejected counts requests refused by namespace limits.
+var NamespaceRejected = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "tutu",
+	Name:      "namespace_rejected_total",
+	Help:      "Requests refused, by namespace and reason (rate_limited, model_denied).",
+}, []string{"namespace", "reason"})
+
+// NamespaceTasks counts tasks submitted per namespace.
+var NamespaceTasks = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "tutu",
+	Name:      "namespace_tasks_total",
+	Help:      "Tasks submitted, by namespace and type.",
+}, []string{"namespace", "type"})
+
 // ─── Credits ────────────────────────────────────────────────────────────────
 
 // CreditsEarned tracks total credits earned.
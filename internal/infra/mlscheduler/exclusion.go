@@ -0,0 +1,44 @@
+package mlscheduler
+
+// ─── Exclusion ──────────────────────────────────────────────────────────────
+// The bandit only knows what its rewards have taught it, so a node that is
+// mid-incident or quarantined can still score best. An exclusion is a hard
+// filter applied before scoring: excluded candidates are never selected,
+// whatever their arm statistics. When the node the bandit would have chosen
+// is excluded, the selection counts as rerouted and OnReroute is told why.
+
+// Exclusion configures the hard filter.
+type Exclusion struct {
+	// Excluded reports whether a node must not be selected, and why (e.g.
+	// "incident", "quarantined"). It must not call back into the scheduler.
+	Excluded func(nodeID string) (reason string, excluded bool)
+
+	// OnReroute is called when the best-scoring candidate was excluded and
+	// another node was selected, or none could be (optional).
+	OnReroute func(nodeID, reason string)
+}
+
+// SetExclusion installs the hard filter; a zero Exclusion removes it.
+func (s *Scheduler) SetExclusion(ex Exclusion) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.exclusion = ex
+}
+
+// excludedLocked returns why each candidate is excluded ("" = eligible),
+// or nil when no filter is set. Must be called with s.mu held.
+func (s *Scheduler) excludedLocked(candidates []Features) []string {
+	if s.exclusion.Excluded == nil {
+		return nil
+	}
+	reasons := make([]string, len(candidates))
+	for i, c := range candidates {
+		if reason, ok := s.exclusion.Excluded(c.NodeID); ok {
+			if reason == "" {
+				reason = "excluded"
+			}
+			reasons[i] = reason
+		}
+	}
+	return reasons
+}
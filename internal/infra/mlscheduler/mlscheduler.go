@@ -12,6 +12,8 @@
 //     that has paid out best so far) with EXPLORATION (trying arms we haven't
 //     pulled much, in case they're secretly better).
 //
+//     Thompson sampling is the alternative policy (see policy.go).
+//
 //   - Feature Extraction: before the bandit chooses an arm, we extract
 //     numerical features from the {task, node} pair — latency, load, GPU
 //     availability, model cache state. These features form the "context".
@@ -30,7 +32,10 @@ package mlscheduler
 import (
 	"math"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/tutu-network/tutu/internal/infra/dsa"
 )
 
 // ─── Configuration ──────────────────────────────────────────────────────────
@@ -43,14 +48,38 @@ type Config struct {
 	// where node behavior can shift quickly.
 	ExplorationFactor float64
 
+	// Policy scores arms for selection (see policy.go). nil = UCB1.
+	Policy SelectionPolicy
+
 	// MinObservations is how many observations an arm needs before
 	// we trust its statistics. Below this threshold we always explore.
 	MinObservations int
 
+	// PriorWeight is how many pseudo-observations a new arm's prior counts
+	// as. The prior mean is the HeuristicScore of the features the arm was
+	// first offered with, so a new arm starts near what the heuristic
+	// expects instead of with infinite optimism, and real rewards outweigh
+	// it as they arrive. A prior at least MinObservations strong lets UCB1
+	// score the arm before its first pull. 0 disables priors.
+	PriorWeight float64
+
+	// PerNodeArms gives each node arms of its own (see nodearms.go) so the
+	// bandit learns node-specific behavior, not just that of the bucket.
+	// MaxNodeArms bounds how many nodes get them (default 256); the rest
+	// keep sharing bucket arms.
+	PerNodeArms bool
+	MaxNodeArms int
+
+	// RemoteWeightCap is the most pseudo-observations peers' shared
+	// experience may add to one arm, combined (see MergeRemote).
+	RemoteWeightCap float64
+
 	// DecayFactor controls how strongly older observations are discounted.
 	// 1.0 = no decay (all observations weighted equally).
 	// 0.95 = each observation's weight decays by 5% per subsequent observation.
-	// Helps adapt to changing node behavior over time.
+	// Helps adapt to changing node behavior over time: an arm's mean is the
+	// decayed mean, so its last ~1/(1-DecayFactor) rewards dominate, while
+	// its pull count (and so the exploration bonus) is not decayed.
 	DecayFactor float64
 
 	// RewardWeights controls the cost optimizer's multi-objective balance.
@@ -59,10 +88,27 @@ type Config struct {
 	CostWeight     float64 // how much we value low credit cost (default 0.3)
 	FairnessWeight float64 // how much we value spreading work (default 0.2)
 
+	// PriorityWeights overrides the reward weights for priority classes
+	// (0=realtime .. 4=spot), normalized like the global ones; realtime
+	// tasks care about latency far more than spot tasks do. Each class
+	// listed gets arms of its own (the arm key gains ":p<class>"), so
+	// rewards scored with different weights never share a mean.
+	PriorityWeights map[int]RewardWeights
+
 	// HistoryCapacity is the maximum number of observations to retain.
 	// Oldest observations are evicted when this limit is reached.
 	HistoryCapacity int
 
+	// ShadowMaxPending bounds the shadow decisions awaiting an outcome
+	// (see shadow.go); past it the oldest are dropped unresolved.
+	ShadowMaxPending int
+
+	// DedupWindow is how long RecordOutcomeWithKey remembers an idempotency
+	// key, and DedupMaxKeys how many it remembers at once. A replay inside
+	// the window is dropped.
+	DedupWindow  time.Duration
+	DedupMaxKeys int
+
 	// Now is an injectable clock for testing.
 	Now func() time.Time
 }
@@ -72,11 +118,17 @@ func DefaultConfig() Config {
 	return Config{
 		ExplorationFactor: 1.5,
 		MinObservations:   3,
+		PriorWeight:       3,
+		RemoteWeightCap:   20,
+		MaxNodeArms:       256,
 		DecayFactor:       0.95,
 		LatencyWeight:     0.5,
 		CostWeight:        0.3,
 		FairnessWeight:    0.2,
 		HistoryCapacity:   100_000,
+		ShadowMaxPending:  10_000,
+		DedupWindow:       10 * time.Minute,
+		DedupMaxKeys:      100_000,
 		Now:               time.Now,
 	}
 }
@@ -90,13 +142,14 @@ type Features struct {
 	TaskType     string  // "INFERENCE", "EMBEDDING", "FINE_TUNE", "AGENT"
 	Priority     int     // task priority class (0=realtime .. 4=spot)
 	NodeLoad     float64 // current node CPU utilization 0..1
-	LatencyMs    float64 // estimated network latency to node
+	LatencyMs    float64 // estimated latency to node (network plus time to first token)
 	HasModelHot  bool    // is the required model already loaded in memory?
 	GPUAvailable bool    // does the node have a free GPU?
 	VRAMGB       float64 // GPU VRAM available (0 if no GPU)
 	Reputation   float64 // node trust score from reputation system
 	CreditRate   float64 // credits per task on this node
 	QueueDepth   int     // tasks already queued on this node
+	Throttled    bool    // is the node in sustained thermal throttle?
 }
 
 // armKey returns a coarsened key that groups similar {task, node} scenarios
@@ -123,35 +176,67 @@ func (f Features) armKey() string {
 		hot = "hot"
 	}
 
-	// Example key: "INFERENCE:light:gpu:hot" — a manageable ~64 arms
-	return f.TaskType + ":" + loadBucket + ":" + gpu + ":" + hot
+	// Example key: "INFERENCE:light:gpu:hot" — a manageable ~64 arms.
+	// Throttled nodes get arms of their own so a hot laptop's slow
+	// fine-tunes do not drag down what the bandit learned about it cool.
+	key := f.TaskType + ":" + loadBucket + ":" + gpu + ":" + hot
+	if f.Throttled {
+		key += ":throttled"
+	}
+	return key
 }
 
 // ─── Observation ────────────────────────────────────────────────────────────
 
 // Observation records the outcome of a single scheduling decision.
 type Observation struct {
-	ArmKey     string    // which arm was pulled
-	NodeID     string    // which node executed the task
-	Reward     float64   // 0..1 composite score
-	LatencyMs  float64   // actual end-to-end latency
-	CreditCost float64   // credits consumed
-	RecordedAt time.Time // when the observation was recorded
+	ArmKey     string    `json:"arm_key"`     // which arm was pulled
+	NodeID     string    `json:"node_id"`     // which node executed the task
+	Reward     float64   `json:"reward"`      // 0..1 composite score
+	LatencyMs  float64   `json:"latency_ms"`  // actual end-to-end latency
+	CreditCost float64   `json:"credit_cost"` // credits consumed
+	RecordedAt time.Time `json:"recorded_at"` // when the observation was recorded
 }
 
 // ─── Arm State ──────────────────────────────────────────────────────────────
 
 // armStats tracks the running statistics for one arm of the bandit.
 // Uses Welford's online algorithm for numerically stable mean + variance.
+// mu guards the fields, so outcomes for different arms don't contend.
 type armStats struct {
+	mu       sync.Mutex
 	pulls    int     // how many times this arm has been pulled
 	totalQ   float64 // sum of rewards (for simple mean fallback)
 	mean     float64 // running mean (Welford)
 	m2       float64 // sum of squared differences (Welford)
 	lastPull time.Time
+
+	// Exponentially decayed reward sum and weight: each new reward
+	// multiplies the older ones' weight by decay (see Config.DecayFactor).
+	decay      float64
+	decayedSum float64
+	decayedN   float64
+
+	priorN    float64 // pseudo-observations the prior counts as
+	priorMean float64 // HeuristicScore of the arm's first features
+
+	remote    map[string]remoteStat // peer nodeID → shared statistics (see share.go)
+	remoteCap float64               // cap on the peers' combined weight
+}
+
+// effective returns the pull count and mean with the prior and the fleet's
+// shared experience blended in. Local rewards enter at their decayed mean.
+func (a *armStats) effective() (n, mean float64) {
+	rn, rmean := a.remoteLocked(a.remoteCap)
+	n = float64(a.pulls) + a.priorN + rn
+	if n == 0 {
+		return 0, 0
+	}
+	return n, (a.priorN*a.priorMean + float64(a.pulls)*a.decayedMean() + rn*rmean) / n
 }
 
-// update incorporates a new reward observation using Welford's method.
+// update incorporates a new reward observation using Welford's method,
+// and into the decayed mean.
 func (a *armStats) update(reward float64, now time.Time) {
 	a.pulls++
 	a.totalQ += reward
@@ -160,6 +245,22 @@ func (a *armStats) update(reward float64, now time.Time) {
 	delta2 := reward - a.mean
 	a.m2 += delta * delta2
 	a.lastPull = now
+
+	decay := a.decay
+	if decay <= 0 {
+		decay = 1
+	}
+	a.decayedSum = decay*a.decayedSum + reward
+	a.decayedN = decay*a.decayedN + 1
+}
+
+// decayedMean returns the mean with older rewards discounted (0 before the
+// first pull).
+func (a *armStats) decayedMean() float64 {
+	if a.decayedN == 0 {
+		return 0
+	}
+	return a.decayedSum / a.decayedN
 }
 
 // variance returns the sample variance (0 if fewer than 2 pulls).
@@ -172,6 +273,12 @@ func (a *armStats) variance() float64 {
 
 // ─── Heuristic Baseline ────────────────────────────────────────────────────
 
+// Score multipliers for a node in sustained thermal throttle.
+const (
+	throttledPenalty         = 0.5
+	throttledFineTunePenalty = 0.1
+)
+
 // HeuristicScore computes the Phase 3 heuristic score for a {task, node}
 // pair. This is our BASELINE that the ML scheduler must outperform by 30%.
 //
@@ -180,6 +287,7 @@ func (a *armStats) variance() float64 {
 //	score = 0.3*latency + 0.25*load + 0.2*cache + 0.15*reputation + 0.1*gpu
 //
 // All components are normalized to [0, 1] where higher = better candidate.
+// A thermally throttled node's score is then scaled down.
 func HeuristicScore(f Features) float64 {
 	// Latency component: lower latency → higher score.
 	// Assume max expected latency ~500ms. Clamp and invert.
@@ -203,29 +311,66 @@ func HeuristicScore(f Features) float64 {
 		gpuScore = 1.0
 	}
 
-	return 0.30*latScore + 0.25*loadScore + 0.20*cacheScore + 0.15*repScore + 0.10*gpuScore
+	score := 0.30*latScore + 0.25*loadScore + 0.20*cacheScore + 0.15*repScore + 0.10*gpuScore
+
+	// Thermal throttle: the node is clocking itself down, and heavy work
+	// only keeps it hot. Fine-tunes are the heaviest, so they avoid it most.
+	if f.Throttled {
+		if f.TaskType == "FINE_TUNE" {
+			score *= throttledFineTunePenalty
+		} else {
+			score *= throttledPenalty
+		}
+	}
+	return score
 }
 
 // ─── ML Scheduler ───────────────────────────────────────────────────────────
 
 // Scheduler is the Phase 6 ML-driven scheduler using UCB1 multi-armed bandit.
+//
+// RecordOutcome runs once per finished task, so its state is split into
+// independently locked parts instead of one scheduler-wide lock: the arm
+// map (each arm locked on its own), the observation ring, the fairness
+// counters and the latency trackers. mu guards only the mutable config.
+// Only Reset holds more than one of these at a time, in declaration order.
 type Scheduler struct {
-	mu    sync.RWMutex
-	cfg   Config
-	arms  map[string]*armStats // key → arm statistics
-	total int                  // total pulls across all arms
-	hist  []Observation        // observation history (ring buffer)
-	hIdx  int                  // next write index in ring buffer
-	hFull bool                 // whether the ring buffer has wrapped
+	mu  sync.RWMutex
+	cfg Config
+
+	armsMu   sync.RWMutex
+	arms     map[string]*armStats // key → arm statistics
+	nodeArms map[string]bool      // nodes with arms of their own (PerNodeArms)
+	total    atomic.Int64         // total pulls across all arms
+
+	histMu sync.Mutex
+	hist   []Observation // observation history (ring buffer)
+	hIdx   int           // next write index in ring buffer
+	hFull  bool          // whether the ring buffer has wrapped
+
+	// Fairness tracking: tasks per node.
+	fairMu         sync.RWMutex
+	nodeTaskCounts map[string]int64
 
 	// Performance tracking: ML vs heuristic.
+	perfMu              sync.Mutex
 	mlLatencySum        float64
 	mlCount             int64
 	heuristicLatencySum float64
 	heuristicCount      int64
 
-	// Fairness tracking: tasks per node.
-	nodeTaskCounts map[string]int64
+	// Recently applied idempotency keys, and the replays they caught.
+	dedup      *dsa.DedupWindow
+	duplicates atomic.Int64
+
+	// Hard filter on candidates (see exclusion.go), guarded by mu.
+	exclusion     Exclusion
+	rerouted      atomic.Int64
+	unschedulable atomic.Int64
+
+	// Shadow decisions and their counterfactual comparison (shadow.go).
+	shadowMu sync.Mutex
+	shadow   shadowState
 }
 
 // NewScheduler creates a new ML-driven scheduler.
@@ -233,15 +378,30 @@ func NewScheduler(cfg Config) *Scheduler {
 	if cfg.ExplorationFactor <= 0 {
 		cfg.ExplorationFactor = 1.5
 	}
+	if cfg.Policy == nil {
+		cfg.Policy = UCB1{}
+	}
 	if cfg.MinObservations <= 0 {
 		cfg.MinObservations = 3
 	}
+	if cfg.PriorWeight < 0 {
+		cfg.PriorWeight = 0
+	}
+	if cfg.RemoteWeightCap <= 0 {
+		cfg.RemoteWeightCap = 20
+	}
+	if cfg.MaxNodeArms <= 0 {
+		cfg.MaxNodeArms = 256
+	}
 	if cfg.DecayFactor <= 0 || cfg.DecayFactor > 1 {
 		cfg.DecayFactor = 0.95
 	}
 	if cfg.HistoryCapacity <= 0 {
 		cfg.HistoryCapacity = 100_000
 	}
+	if cfg.ShadowMaxPending <= 0 {
+		cfg.ShadowMaxPending = 10_000
+	}
 	if cfg.Now == nil {
 		cfg.Now = time.Now
 	}
@@ -256,12 +416,41 @@ func NewScheduler(cfg Config) *Scheduler {
 		cfg.CostWeight /= total
 		cfg.FairnessWeight /= total
 	}
+	// Normalize per-priority weights into a copy, dropping unusable ones.
+	prio := make(map[int]RewardWeights, len(cfg.PriorityWeights))
+	for class, w := range cfg.PriorityWeights {
+		if w, ok := w.normalized(); ok {
+			prio[class] = w
+		}
+	}
+	cfg.PriorityWeights = prio
 	return &Scheduler{
 		cfg:            cfg,
 		arms:           make(map[string]*armStats),
+		nodeArms:       make(map[string]bool),
 		hist:           make([]Observation, cfg.HistoryCapacity),
 		nodeTaskCounts: make(map[string]int64),
+		dedup:          dsa.NewDedupWindow(dsa.DedupConfig{Window: cfg.DedupWindow, MaxKeys: cfg.DedupMaxKeys}),
+		shadow:         newShadowState(),
+	}
+}
+
+// ExplorationFactor returns the current UCB1 exploration constant.
+func (s *Scheduler) ExplorationFactor() float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.cfg.ExplorationFactor
+}
+
+// SetExplorationFactor changes the UCB1 exploration constant at runtime.
+// Non-positive values are ignored. Arm statistics are kept.
+func (s *Scheduler) SetExplorationFactor(c float64) {
+	if c <= 0 {
+		return
 	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cfg.ExplorationFactor = c
 }
 
 // ─── UCB1 Selection ─────────────────────────────────────────────────────────
@@ -280,23 +469,45 @@ func NewScheduler(cfg Config) *Scheduler {
 //
 // The first term favors arms that have performed well (exploitation).
 // The second term gives a bonus to under-explored arms (exploration).
-// As n(arm) grows, the bonus shrinks — we become more confident.
+// As n(arm) grows, the bonus shrinks — we become more confident. An arm's
+// prior (see Config.PriorWeight) counts toward both mean(arm) and n(arm).
+//
+// Must be called with s.mu.RLock and arm.mu held.
 func (s *Scheduler) ucb1Score(arm *armStats) float64 {
-	if arm.pulls == 0 || s.total == 0 {
-		return math.Inf(1) // never pulled → infinite optimism → always try
+	return UCB1{}.Score(s.armState(arm))
+}
+
+// armState is what the selection policy sees of arm.
+// Must be called with s.mu.RLock and arm.mu held.
+func (s *Scheduler) armState(arm *armStats) ArmState {
+	n, mean := arm.effective()
+	return ArmState{
+		N:           n,
+		Mean:        mean,
+		Variance:    arm.variance(),
+		Pulls:       arm.pulls,
+		Total:       float64(s.total.Load()),
+		Exploration: s.cfg.ExplorationFactor,
 	}
-	exploitation := arm.mean
-	exploration := s.cfg.ExplorationFactor * math.Sqrt(math.Log(float64(s.total))/float64(arm.pulls))
-	return exploitation + exploration
 }
 
-// SelectNode picks the best node from a set of candidates using UCB1.
+// Policy returns the name of the selection policy.
+func (s *Scheduler) Policy() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.cfg.Policy.Name()
+}
+
+// SelectNode picks the best node from a set of candidates using the
+// selection policy (UCB1 unless Config.Policy says otherwise).
 // For each candidate, it:
 //  1. Extracts the arm key from the features.
-//  2. Computes the UCB1 score for that arm.
+//  2. Computes the policy's score for that arm.
 //  3. Returns the candidate with the highest score.
 //
 // Returns the selected Features and the arm key (for later reward attribution).
+// Candidates excluded by SetExclusion are skipped; if none is eligible the
+// zero Features and "" are returned.
 func (s *Scheduler) SelectNode(candidates []Features) (Features, string) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -304,27 +515,55 @@ func (s *Scheduler) SelectNode(candidates []Features) (Features, string) {
 	if len(candidates) == 0 {
 		return Features{}, ""
 	}
+	excluded := s.excludedLocked(candidates)
+	keys := make([]string, len(candidates))
+	for i, c := range candidates {
+		keys[i] = s.keyFor(c)
+		if s.cfg.PriorWeight > 0 && (excluded == nil || excluded[i] == "") {
+			s.seedArm(keys[i], c)
+		}
+	}
 
-	bestIdx := 0
-	bestScore := math.Inf(-1)
+	s.armsMu.RLock()
+	defer s.armsMu.RUnlock()
 
-	for i, c := range candidates {
-		key := c.armKey()
-		arm, exists := s.arms[key]
-		var score float64
-		if !exists || arm.pulls < s.cfg.MinObservations {
-			// Not enough data — give maximum exploration bonus.
-			score = math.Inf(1)
-		} else {
-			score = s.ucb1Score(arm)
+	bestIdx, eligibleIdx := 0, -1
+	bestScore, eligibleScore := math.Inf(-1), math.Inf(-1)
+
+	for i := range candidates {
+		arm, exists := s.arms[keys[i]]
+		score := math.Inf(1) // not enough data — maximum exploration bonus
+		if exists {
+			arm.mu.Lock()
+			if n, _ := arm.effective(); n >= float64(s.cfg.MinObservations) {
+				score = s.cfg.Policy.Score(s.armState(arm))
+			}
+			arm.mu.Unlock()
 		}
 		if score > bestScore {
 			bestScore = score
 			bestIdx = i
 		}
+		if (excluded == nil || excluded[i] == "") && score > eligibleScore {
+			eligibleScore = score
+			eligibleIdx = i
+		}
 	}
 
-	return candidates[bestIdx], candidates[bestIdx].armKey()
+	if excluded != nil && excluded[bestIdx] != "" {
+		if eligibleIdx < 0 {
+			s.unschedulable.Add(1)
+		} else {
+			s.rerouted.Add(1)
+		}
+		if s.exclusion.OnReroute != nil {
+			s.exclusion.OnReroute(candidates[bestIdx].NodeID, excluded[bestIdx])
+		}
+	}
+	if eligibleIdx < 0 {
+		return Features{}, ""
+	}
+	return candidates[eligibleIdx], keys[eligibleIdx]
 }
 
 // ─── Reward Computation ─────────────────────────────────────────────────────
@@ -337,20 +576,94 @@ func (s *Scheduler) SelectNode(candidates []Features) (Features, string) {
 //   - Fairness: 1 - clamp(gini(node_loads), 0, 1)  → more equal = higher reward
 //
 // The Gini coefficient measures inequality: 0 = perfect equality, 1 = max inequality.
+// ComputeReward uses the global weights; ComputeRewardFor those of a
+// priority class.
 func (s *Scheduler) ComputeReward(latencyMs, creditCost float64) float64 {
-	s.mu.RLock()
+	return s.reward(s.RewardWeights(), latencyMs, creditCost)
+}
+
+// ComputeRewardFor is ComputeReward with the weights of the given priority
+// class (see Config.PriorityWeights), or the global ones if it has none.
+func (s *Scheduler) ComputeRewardFor(priority int, latencyMs, creditCost float64) float64 {
+	return s.reward(s.RewardWeightsFor(priority), latencyMs, creditCost)
+}
+
+// outcomeReward scores an outcome on armKey with the weights of the
+// priority class the arm belongs to.
+func (s *Scheduler) outcomeReward(armKey string, latencyMs, creditCost float64) float64 {
+	if class, ok := priorityOf(armKey); ok {
+		return s.ComputeRewardFor(class, latencyMs, creditCost)
+	}
+	return s.ComputeReward(latencyMs, creditCost)
+}
+
+func (s *Scheduler) reward(w RewardWeights, latencyMs, creditCost float64) float64 {
+	s.fairMu.RLock()
 	gini := s.giniCoefficient()
-	s.mu.RUnlock()
+	s.fairMu.RUnlock()
 
 	latReward := 1.0 - math.Min(latencyMs/1000.0, 1.0)
 	costReward := 1.0 - math.Min(creditCost/100.0, 1.0)
 	fairReward := 1.0 - gini
 
-	return s.cfg.LatencyWeight*latReward + s.cfg.CostWeight*costReward + s.cfg.FairnessWeight*fairReward
+	return w.Latency*latReward + w.Cost*costReward + w.Fairness*fairReward
+}
+
+// RewardWeights are the reward's objective weights. They sum to 1.
+type RewardWeights struct {
+	Latency  float64 `json:"latency"`
+	Cost     float64 `json:"cost"`
+	Fairness float64 `json:"fairness"`
+}
+
+// RewardWeights returns the current objective weights.
+func (s *Scheduler) RewardWeights() RewardWeights {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return RewardWeights{Latency: s.cfg.LatencyWeight, Cost: s.cfg.CostWeight, Fairness: s.cfg.FairnessWeight}
+}
+
+// RewardWeightsFor returns the objective weights of a priority class: its
+// own if Config.PriorityWeights has them, else the global ones.
+func (s *Scheduler) RewardWeightsFor(priority int) RewardWeights {
+	s.mu.RLock()
+	w, ok := s.cfg.PriorityWeights[priority]
+	s.mu.RUnlock()
+	if ok {
+		return w
+	}
+	return s.RewardWeights()
+}
+
+// SetRewardWeights changes the objective weights at runtime, normalized to
+// sum to 1. Negative or all-zero weights are a no-op returning false. Arm
+// statistics keep the rewards they were learned with. Priority classes
+// with weights of their own keep them.
+func (s *Scheduler) SetRewardWeights(w RewardWeights) bool {
+	w, ok := w.normalized()
+	if !ok {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cfg.LatencyWeight = w.Latency
+	s.cfg.CostWeight = w.Cost
+	s.cfg.FairnessWeight = w.Fairness
+	return true
+}
+
+// normalized scales w to sum to 1. Negative or all-zero weights are not
+// usable and return false.
+func (w RewardWeights) normalized() (RewardWeights, bool) {
+	total := w.Latency + w.Cost + w.Fairness
+	if w.Latency < 0 || w.Cost < 0 || w.Fairness < 0 || total <= 0 {
+		return RewardWeights{}, false
+	}
+	return RewardWeights{Latency: w.Latency / total, Cost: w.Cost / total, Fairness: w.Fairness / total}, true
 }
 
 // giniCoefficient computes the Gini coefficient of node task counts.
-// Must be called with at least s.mu.RLock held.
+// Must be called with at least s.fairMu.RLock held.
 //
 // Gini = (2 * Σ i*x_i) / (n * Σ x_i) - (n+1)/n
 // where x_i are sorted task counts. Returns 0 if fewer than 2 nodes.
@@ -393,50 +706,115 @@ func (s *Scheduler) giniCoefficient() float64 {
 // RecordOutcome records the result of a scheduling decision.
 // This updates the bandit's arm statistics and the performance trackers.
 func (s *Scheduler) RecordOutcome(armKey, nodeID string, latencyMs, creditCost float64) {
-	reward := s.ComputeReward(latencyMs, creditCost)
-
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	s.RecordOutcomeWithKey("", armKey, nodeID, latencyMs, creditCost)
+}
 
-	// Update arm statistics.
-	arm, exists := s.arms[armKey]
-	if !exists {
-		arm = &armStats{}
-		s.arms[armKey] = arm
-	}
+// RecordOutcomeWithKey is RecordOutcome for senders that retry: an outcome
+// whose idempotency key was already recorded within the dedup window is
+// dropped so it doesn't reward its arm twice. It reports whether the
+// outcome was recorded. An empty key always records.
+func (s *Scheduler) RecordOutcomeWithKey(key, armKey, nodeID string, latencyMs, creditCost float64) bool {
 	now := s.cfg.Now()
-	arm.update(reward, now)
-	s.total++
-
-	// Update per-node fairness tracker.
-	s.nodeTaskCounts[nodeID]++
-
-	// Record observation in ring buffer.
-	obs := Observation{
+	if s.dedup.Seen(key, now) {
+		s.duplicates.Add(1)
+		return false
+	}
+	reward := s.outcomeReward(armKey, latencyMs, creditCost)
+	s.learn(armKey, nodeID, reward, now)
+	s.observe(Observation{
 		ArmKey:     armKey,
 		NodeID:     nodeID,
 		Reward:     reward,
 		LatencyMs:  latencyMs,
 		CreditCost: creditCost,
 		RecordedAt: now,
-	}
+	})
+	s.trackLatency(latencyMs)
+	return true
+}
+
+// observe records obs in the observation ring buffer.
+func (s *Scheduler) observe(obs Observation) {
+	s.histMu.Lock()
+	defer s.histMu.Unlock()
 	s.hist[s.hIdx] = obs
 	s.hIdx++
-	if s.hIdx >= s.cfg.HistoryCapacity {
+	if s.hIdx >= len(s.hist) {
 		s.hIdx = 0
 		s.hFull = true
 	}
+}
 
-	// Track ML scheduler latency.
+// trackLatency counts an ML-scheduled task's latency toward Stats.
+func (s *Scheduler) trackLatency(latencyMs float64) {
+	s.perfMu.Lock()
+	defer s.perfMu.Unlock()
 	s.mlLatencySum += latencyMs
 	s.mlCount++
 }
 
+// learn rewards armKey and counts the task toward nodeID's fair share.
+func (s *Scheduler) learn(armKey, nodeID string, reward float64, now time.Time) {
+	// Update arm statistics. A node's own arm also teaches its bucket.
+	for _, key := range outcomeArms(armKey) {
+		arm := s.arm(key)
+		arm.mu.Lock()
+		arm.update(reward, now)
+		arm.mu.Unlock()
+	}
+	s.total.Add(1)
+
+	// Update per-node fairness tracker.
+	s.fairMu.Lock()
+	s.nodeTaskCounts[nodeID]++
+	s.fairMu.Unlock()
+}
+
+// arm returns the stats of armKey, creating them on first use. Lookups of
+// existing arms only take the read lock.
+func (s *Scheduler) arm(armKey string) *armStats {
+	s.armsMu.RLock()
+	arm, ok := s.arms[armKey]
+	s.armsMu.RUnlock()
+	if ok {
+		return arm
+	}
+	s.armsMu.Lock()
+	defer s.armsMu.Unlock()
+	if arm, ok = s.arms[armKey]; !ok {
+		arm = &armStats{remoteCap: s.cfg.RemoteWeightCap, decay: s.cfg.DecayFactor}
+		s.arms[armKey] = arm
+	}
+	return arm
+}
+
+// seedArm creates the arm key for f with a prior if it does not exist yet:
+// what the bucket has learned for a node's own arm, else the heuristic.
+// Must be called with s.mu.RLock held.
+func (s *Scheduler) seedArm(key string, f Features) {
+	s.armsMu.RLock()
+	_, ok := s.arms[key]
+	s.armsMu.RUnlock()
+	if ok {
+		return
+	}
+	s.armsMu.Lock()
+	defer s.armsMu.Unlock()
+	if _, ok := s.arms[key]; !ok {
+		s.arms[key] = &armStats{
+			priorN:    s.cfg.PriorWeight,
+			priorMean: s.priorLocked(key, f),
+			remoteCap: s.cfg.RemoteWeightCap,
+			decay:     s.cfg.DecayFactor,
+		}
+	}
+}
+
 // RecordHeuristicBaseline records a heuristic-scheduled task's latency
 // so we can compute the improvement ratio.
 func (s *Scheduler) RecordHeuristicBaseline(latencyMs float64) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	s.perfMu.Lock()
+	defer s.perfMu.Unlock()
 	s.heuristicLatencySum += latencyMs
 	s.heuristicCount++
 }
@@ -445,39 +823,54 @@ func (s *Scheduler) RecordHeuristicBaseline(latencyMs float64) {
 
 // Stats returns current ML scheduler statistics.
 type Stats struct {
-	TotalObservations int     // total observations recorded
-	UniqueArms        int     // number of distinct arms
-	UniqueNodes       int     // number of distinct nodes seen
-	MLAvgLatencyMs    float64 // average latency for ML-scheduled tasks
-	HeurAvgLatencyMs  float64 // average latency for heuristic-scheduled tasks
-	ImprovementPct    float64 // (heur - ml) / heur * 100 — positive = ML is better
-	GiniCoefficient   float64 // current fairness measure
+	TotalObservations int     `json:"total_observations"`  // total observations recorded
+	UniqueArms        int     `json:"unique_arms"`         // number of distinct arms
+	UniqueNodes       int     `json:"unique_nodes"`        // number of distinct nodes seen
+	MLAvgLatencyMs    float64 `json:"ml_avg_latency_ms"`   // average latency for ML-scheduled tasks
+	HeurAvgLatencyMs  float64 `json:"heur_avg_latency_ms"` // average latency for heuristic-scheduled tasks
+	ImprovementPct    float64 `json:"improvement_pct"`     // (heur - ml) / heur * 100 — positive = ML is better
+	GiniCoefficient   float64 `json:"gini_coefficient"`    // current fairness measure
+	DuplicatesDropped int64   `json:"duplicates_dropped"`  // replayed outcomes dropped by idempotency key
+	Rerouted          int64   `json:"rerouted"`            // selections that skipped an excluded best-scoring node
+	Unschedulable     int64   `json:"unschedulable"`       // selections where every candidate was excluded
+	Policy            string  `json:"policy"`              // selection policy name
 }
 
 // Stats returns current performance statistics.
 func (s *Scheduler) Stats() Stats {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
 	var mlAvg, heurAvg, improvement float64
+	s.perfMu.Lock()
 	if s.mlCount > 0 {
 		mlAvg = s.mlLatencySum / float64(s.mlCount)
 	}
 	if s.heuristicCount > 0 {
 		heurAvg = s.heuristicLatencySum / float64(s.heuristicCount)
 	}
+	s.perfMu.Unlock()
 	if heurAvg > 0 {
 		improvement = (heurAvg - mlAvg) / heurAvg * 100.0
 	}
 
+	s.armsMu.RLock()
+	arms := len(s.arms)
+	s.armsMu.RUnlock()
+
+	s.fairMu.RLock()
+	nodes, gini := len(s.nodeTaskCounts), s.giniCoefficient()
+	s.fairMu.RUnlock()
+
 	return Stats{
-		TotalObservations: s.total,
-		UniqueArms:        len(s.arms),
-		UniqueNodes:       len(s.nodeTaskCounts),
+		TotalObservations: int(s.total.Load()),
+		UniqueArms:        arms,
+		UniqueNodes:       nodes,
 		MLAvgLatencyMs:    mlAvg,
 		HeurAvgLatencyMs:  heurAvg,
 		ImprovementPct:    improvement,
-		GiniCoefficient:   s.giniCoefficient(),
+		GiniCoefficient:   gini,
+		DuplicatesDropped: s.duplicates.Load(),
+		Rerouted:          s.rerouted.Load(),
+		Unschedulable:     s.unschedulable.Load(),
+		Policy:            s.Policy(),
 	}
 }
 
@@ -494,8 +887,8 @@ func (s *Scheduler) GatePassed(minImprovementPct float64) bool {
 
 // Observations returns the most recent N observations.
 func (s *Scheduler) Observations(limit int) []Observation {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+	s.histMu.Lock()
+	defer s.histMu.Unlock()
 
 	var count int
 	if s.hFull {
@@ -527,27 +920,51 @@ func (s *Scheduler) Observations(limit int) []Observation {
 
 // ArmInfo exposes the statistics of a single bandit arm.
 type ArmInfo struct {
-	Key      string  // arm identifier
-	Pulls    int     // times this arm was selected
-	MeanQ    float64 // average reward
-	Variance float64 // reward variance
-	UCBScore float64 // current UCB1 score
+	Key          string  `json:"key"`            // arm identifier
+	Pulls        int     `json:"pulls"`          // times this arm was selected
+	MeanQ        float64 `json:"mean_q"`         // average reward, every pull weighted equally
+	DecayedMeanQ float64 `json:"decayed_mean_q"` // average reward with older pulls discounted (see Config.DecayFactor)
+	Variance     float64 `json:"variance"`       // reward variance
+	UCBScore     float64 `json:"ucb_score"`      // current UCB1 score (+Inf before any data)
+
+	PriorMean    float64 `json:"prior_mean"`    // heuristic prior mean (0 without a prior)
+	PriorWeight  float64 `json:"prior_weight"`  // pseudo-observations the prior counts as
+	RemoteWeight float64 `json:"remote_weight"` // pseudo-observations shared by peers (capped)
+
+	// Posterior is the selection policy's belief about the arm's mean
+	// reward (nil under UCB1).
+	Posterior *Posterior `json:"posterior,omitempty"`
 }
 
 // Arms returns statistics for all known arms.
 func (s *Scheduler) Arms() []ArmInfo {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
+	s.armsMu.RLock()
+	defer s.armsMu.RUnlock()
 
+	pp, _ := s.cfg.Policy.(PosteriorPolicy)
 	result := make([]ArmInfo, 0, len(s.arms))
 	for key, arm := range s.arms {
+		arm.mu.Lock()
 		result = append(result, ArmInfo{
-			Key:      key,
-			Pulls:    arm.pulls,
-			MeanQ:    arm.mean,
-			Variance: arm.variance(),
-			UCBScore: s.ucb1Score(arm),
+			Key:          key,
+			Pulls:        arm.pulls,
+			MeanQ:        arm.mean,
+			DecayedMeanQ: arm.decayedMean(),
+			Variance:     arm.variance(),
+			UCBScore:     s.ucb1Score(arm),
+
+			PriorMean:   arm.priorMean,
+			PriorWeight: arm.priorN,
 		})
+		info := &result[len(result)-1]
+		info.RemoteWeight, _ = arm.remoteLocked(arm.remoteCap)
+		if pp != nil {
+			post := pp.Posterior(s.armState(arm))
+			info.Posterior = &post
+		}
+		arm.mu.Unlock()
 	}
 	return result
 }
@@ -557,9 +974,20 @@ func (s *Scheduler) Arms() []ArmInfo {
 func (s *Scheduler) Reset() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	s.armsMu.Lock()
+	defer s.armsMu.Unlock()
+	s.histMu.Lock()
+	defer s.histMu.Unlock()
+	s.fairMu.Lock()
+	defer s.fairMu.Unlock()
+	s.perfMu.Lock()
+	defer s.perfMu.Unlock()
+	s.shadowMu.Lock()
+	defer s.shadowMu.Unlock()
 
 	s.arms = make(map[string]*armStats)
-	s.total = 0
+	s.nodeArms = make(map[string]bool)
+	s.total.Store(0)
 	s.hist = make([]Observation, s.cfg.HistoryCapacity)
 	s.hIdx = 0
 	s.hFull = false
@@ -568,4 +996,10 @@ func (s *Scheduler) Reset() {
 	s.heuristicLatencySum = 0
 	s.heuristicCount = 0
 	s.nodeTaskCounts = make(map[string]int64)
+	s.shadow = newShadowState()
+	// The dedup window is kept: a replay arriving after the reset is still
+	// a replay. So is the exclusion, which is configuration.
+	s.duplicates.Store(0)
+	s.rerouted.Store(0)
+	s.unschedulable.Store(0)
 }
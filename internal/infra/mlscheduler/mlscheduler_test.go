@@ -1,7 +1,9 @@
 package mlscheduler
 
 import (
+	"fmt"
 	"math"
+	"sync"
 	"testing"
 	"time"
 )
@@ -42,8 +44,8 @@ func TestNewScheduler_DefaultConfig(t *testing.T) {
 	if len(s.arms) != 0 {
 		t.Errorf("new scheduler should have 0 arms, got %d", len(s.arms))
 	}
-	if s.total != 0 {
-		t.Errorf("new scheduler should have 0 total, got %d", s.total)
+	if s.total.Load() != 0 {
+		t.Errorf("new scheduler should have 0 total, got %d", s.total.Load())
 	}
 }
 
@@ -67,6 +69,87 @@ func TestNewScheduler_InvalidConfig(t *testing.T) {
 	}
 }
 
+func TestSetExplorationFactor(t *testing.T) {
+	s := NewScheduler(DefaultConfig())
+	s.SetExplorationFactor(3)
+	if got := s.ExplorationFactor(); got != 3 {
+		t.Errorf("ExplorationFactor() = %v, want 3", got)
+	}
+	s.SetExplorationFactor(-1)
+	if got := s.ExplorationFactor(); got != 3 {
+		t.Errorf("non-positive factor applied: %v", got)
+	}
+}
+
+func TestSetRewardWeights(t *testing.T) {
+	s := NewScheduler(DefaultConfig())
+	if !s.SetRewardWeights(RewardWeights{Latency: 2, Cost: 1, Fairness: 1}) {
+		t.Fatal("SetRewardWeights rejected valid weights")
+	}
+	if got := s.RewardWeights(); got != (RewardWeights{Latency: 0.5, Cost: 0.25, Fairness: 0.25}) {
+		t.Errorf("weights = %+v, want normalized 0.5/0.25/0.25", got)
+	}
+	// Fast and free with no fairness penalty (a single node) earns 1
+	if r := s.ComputeReward(0, 0); math.Abs(r-1) > 1e-9 {
+		t.Errorf("ComputeReward(0, 0) = %v, want 1", r)
+	}
+	if r := s.ComputeReward(1000, 0); math.Abs(r-0.5) > 1e-9 {
+		t.Errorf("ComputeReward(1000, 0) = %v, want 0.5", r)
+	}
+
+	for _, w := range []RewardWeights{{}, {Latency: -1, Cost: 2}} {
+		if s.SetRewardWeights(w) {
+			t.Errorf("SetRewardWeights(%+v) accepted", w)
+		}
+	}
+}
+
+func TestPriorityRewardWeights(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.PriorWeight = 0
+	cfg.PriorityWeights = map[int]RewardWeights{
+		0: {Latency: 2},           // realtime: latency only
+		4: {Cost: 1},              // spot: cost only
+		3: {Latency: -1, Cost: 2}, // unusable, dropped
+	}
+	s := NewScheduler(cfg)
+
+	if w := s.RewardWeightsFor(0); w != (RewardWeights{Latency: 1}) {
+		t.Errorf("realtime weights = %+v, want normalized latency only", w)
+	}
+	if w := s.RewardWeightsFor(3); w != s.RewardWeights() {
+		t.Errorf("class with invalid weights = %+v, want the global ones", w)
+	}
+	// Slow but free: worthless to realtime, perfect for spot
+	if r := s.ComputeRewardFor(0, 1000, 0); math.Abs(r) > 1e-9 {
+		t.Errorf("realtime reward = %v, want 0", r)
+	}
+	if r := s.ComputeRewardFor(4, 1000, 0); math.Abs(r-1) > 1e-9 {
+		t.Errorf("spot reward = %v, want 1", r)
+	}
+
+	// Classes with their own weights get their own arms, rewarded with them
+	realtime := mkFeatures("n1", "INFERENCE", 0.1, true, true)
+	realtime.Priority = 0
+	normal := realtime
+	normal.Priority = 2
+	_, rtKey := s.SelectNode([]Features{realtime})
+	_, normalKey := s.SelectNode([]Features{normal})
+	if rtKey != realtime.armKey()+":p0" || normalKey != normal.armKey() {
+		t.Fatalf("keys = %q, %q; want the realtime one split out", rtKey, normalKey)
+	}
+	s.RecordOutcome(rtKey, "n1", 1000, 0)
+	s.RecordOutcome(normalKey, "n1", 1000, 0)
+	for _, a := range s.Arms() {
+		if a.Key == rtKey && a.MeanQ != 0 {
+			t.Errorf("realtime arm mean = %v, want 0", a.MeanQ)
+		}
+		if a.Key == normalKey && math.Abs(a.MeanQ-s.ComputeReward(1000, 0)) > 1e-9 {
+			t.Errorf("normal arm mean = %v, want the global reward", a.MeanQ)
+		}
+	}
+}
+
 func TestFeatures_ArmKey(t *testing.T) {
 	tests := []struct {
 		name string
@@ -93,6 +176,13 @@ func TestFeatures_ArmKey(t *testing.T) {
 			f:    mkFeatures("n4", "FINE_TUNE", 0.3, false, true),
 			want: "FINE_TUNE:light:nogpu:hot",
 		},
+		{
+			name: "fine_tune_throttled",
+			f: Features{
+				TaskType: "FINE_TUNE", NodeLoad: 0.1, GPUAvailable: true, Throttled: true,
+			},
+			want: "FINE_TUNE:idle:gpu:cold:throttled",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -136,6 +226,23 @@ func TestHeuristicScore(t *testing.T) {
 	}
 }
 
+func TestHeuristicScore_ThermalThrottle(t *testing.T) {
+	cool := Features{TaskType: "INFERENCE", LatencyMs: 10, NodeLoad: 0.1, Reputation: 1.0, GPUAvailable: true}
+	hot := cool
+	hot.Throttled = true
+	if HeuristicScore(hot) >= HeuristicScore(cool) {
+		t.Error("throttled node should score below the same node cool")
+	}
+
+	// A hot laptop loses a fine-tune even to a busy, distant cool node
+	hot.TaskType = "FINE_TUNE"
+	busy := Features{TaskType: "FINE_TUNE", LatencyMs: 300, NodeLoad: 0.8, Reputation: 0.5}
+	if HeuristicScore(hot) >= HeuristicScore(busy) {
+		t.Errorf("throttled fine-tune score %.3f should be below busy node %.3f",
+			HeuristicScore(hot), HeuristicScore(busy))
+	}
+}
+
 func TestSelectNode_ExploresUnknownArms(t *testing.T) {
 	s := NewScheduler(DefaultConfig())
 
@@ -155,6 +262,194 @@ func TestSelectNode_ExploresUnknownArms(t *testing.T) {
 	}
 }
 
+func TestSelectNode_ColdStartPrior(t *testing.T) {
+	cold := mkFeatures("n1", "INFERENCE", 0.9, false, false)
+	warm := mkFeatures("n2", "INFERENCE", 0.1, true, true)
+
+	// Without priors both arms are unknown and the first one is tried blindly
+	cfg := DefaultConfig()
+	cfg.PriorWeight = 0
+	if got, _ := NewScheduler(cfg).SelectNode([]Features{cold, warm}); got.NodeID != "n1" {
+		t.Errorf("without priors selected %s, want n1", got.NodeID)
+	}
+
+	// With priors the heuristic favorite goes first
+	s := NewScheduler(DefaultConfig())
+	got, key := s.SelectNode([]Features{cold, warm})
+	if got.NodeID != "n2" {
+		t.Fatalf("with priors selected %s, want n2", got.NodeID)
+	}
+	var info ArmInfo
+	for _, a := range s.Arms() {
+		if a.Key == key {
+			info = a
+		}
+	}
+	if info.PriorWeight != 3 || math.Abs(info.PriorMean-HeuristicScore(warm)) > 1e-9 {
+		t.Errorf("arm %s prior = %v×%v, want 3×%v", key, info.PriorWeight, info.PriorMean, HeuristicScore(warm))
+	}
+
+	// Real rewards outweigh the prior: the favorite keeps failing slowly
+	for i := 0; i < 20; i++ {
+		s.RecordOutcome(key, "n2", 1000, 100)
+		s.RecordOutcome(cold.armKey(), "n1", 20, 1)
+	}
+	if got, _ := s.SelectNode([]Features{cold, warm}); got.NodeID != "n1" {
+		t.Errorf("after bad outcomes selected %s, want n1", got.NodeID)
+	}
+}
+
+func TestMergeRemote_BootstrapsFromFleet(t *testing.T) {
+	bad := mkFeatures("n1", "INFERENCE", 0.9, false, false)
+	good := mkFeatures("n2", "INFERENCE", 0.1, true, true)
+	cfg := DefaultConfig()
+	cfg.PriorWeight = 0
+
+	// A peer that has already learned n2 is the better arm
+	peer := NewScheduler(cfg)
+	for i := 0; i < 40; i++ {
+		peer.RecordOutcome(bad.armKey(), "n1", 900, 90)
+		peer.RecordOutcome(good.armKey(), "n2", 20, 1)
+	}
+	sums := peer.Summaries()
+	if len(sums) != 2 || sums[0].Pulls != 40 {
+		t.Fatalf("Summaries() = %+v", sums)
+	}
+
+	s := NewScheduler(cfg)
+	if got, _ := s.SelectNode([]Features{bad, good}); got.NodeID != "n1" {
+		t.Fatalf("before merging selected %s, want n1", got.NodeID)
+	}
+	if n := s.MergeRemote("peer", sums, 0.5); n != 2 {
+		t.Errorf("MergeRemote merged %d arms, want 2", n)
+	}
+	if got, _ := s.SelectNode([]Features{bad, good}); got.NodeID != "n2" {
+		t.Errorf("after merging selected %s, want n2", got.NodeID)
+	}
+
+	// Remote weight is capped, merges are idempotent, and shared experience
+	// is never re-shared
+	s.MergeRemote("peer", sums, 0.5)
+	for _, a := range s.Arms() {
+		if a.RemoteWeight != cfg.RemoteWeightCap {
+			t.Errorf("arm %s remote weight = %v, want %v", a.Key, a.RemoteWeight, cfg.RemoteWeightCap)
+		}
+	}
+	if got := s.Summaries(); len(got) != 0 {
+		t.Errorf("Summaries() re-shared remote experience: %+v", got)
+	}
+
+	// Forgetting the peer, or losing trust in it, drops what it shared
+	s.ForgetRemote("peer")
+	s.MergeRemote("other", []ArmSummary{{Key: good.armKey(), Pulls: 4, Mean: 0.9}, {Key: "", Pulls: 1, Mean: 2}}, 0.5)
+	for _, a := range s.Arms() {
+		want := 0.0
+		if a.Key == good.armKey() {
+			want = 2
+		}
+		if a.RemoteWeight != want {
+			t.Errorf("arm %s remote weight = %v, want %v", a.Key, a.RemoteWeight, want)
+		}
+	}
+	s.MergeRemote("other", sums, 0)
+	for _, a := range s.Arms() {
+		if a.RemoteWeight != 0 {
+			t.Errorf("arm %s kept weight %v from an untrusted peer", a.Key, a.RemoteWeight)
+		}
+	}
+}
+
+func TestSelectNode_PerNodeArms(t *testing.T) {
+	// Same bucket, very different nodes: n1 is fast, n2 slow
+	fast := mkFeatures("n1", "INFERENCE", 0.1, true, true)
+	slow := mkFeatures("n2", "INFERENCE", 0.1, true, true)
+	outcome := map[string]float64{"n1": 20, "n2": 2000}
+
+	run := func(cfg Config) (fastPicks int, s *Scheduler) {
+		s = NewScheduler(cfg)
+		for i := 0; i < 200; i++ {
+			got, key := s.SelectNode([]Features{slow, fast}) // shared arms tie → first
+			s.RecordOutcome(key, got.NodeID, outcome[got.NodeID], 1)
+			if i >= 100 && got.NodeID == "n1" {
+				fastPicks++
+			}
+		}
+		return fastPicks, s
+	}
+
+	shared, _ := run(DefaultConfig())
+	cfg := DefaultConfig()
+	cfg.PerNodeArms = true
+	perNode, s := run(cfg)
+	if shared != 0 || perNode < 80 {
+		t.Errorf("fast node picked %d/100 with per-node arms, %d/100 shared", perNode, shared)
+	}
+
+	// Each node's arm also taught the bucket, and a new node starts from it
+	bucket := fast.armKey()
+	var bucketMean float64
+	for _, a := range s.Arms() {
+		if a.Key == bucket {
+			bucketMean = a.DecayedMeanQ
+		}
+	}
+	if bucketMean == 0 {
+		t.Fatalf("bucket arm %s never updated", bucket)
+	}
+	newcomer := mkFeatures("n3", "INFERENCE", 0.1, true, true)
+	_, key := s.SelectNode([]Features{newcomer})
+	for _, a := range s.Arms() {
+		if a.Key == key && math.Abs(a.PriorMean-bucketMean) > 1e-9 {
+			t.Errorf("new node arm %s prior = %v, want bucket mean %v", key, a.PriorMean, bucketMean)
+		}
+	}
+
+	// Past MaxNodeArms, nodes share the bucket arm
+	cfg.MaxNodeArms = 1
+	s = NewScheduler(cfg)
+	if _, key := s.SelectNode([]Features{fast}); key != bucket+"@n1" {
+		t.Errorf("first node key = %q, want its own arm", key)
+	}
+	if _, key := s.SelectNode([]Features{slow}); key != bucket {
+		t.Errorf("node past the cap key = %q, want bucket %q", key, bucket)
+	}
+}
+
+func TestSelectNode_Exclusion(t *testing.T) {
+	bad := mkFeatures("n1", "INFERENCE", 0.1, true, true)
+	ok := mkFeatures("n2", "INFERENCE", 0.9, false, false)
+	s := NewScheduler(DefaultConfig())
+	if got, _ := s.SelectNode([]Features{bad, ok}); got.NodeID != "n1" {
+		t.Fatalf("without exclusion selected %s, want n1", got.NodeID)
+	}
+
+	incident := map[string]bool{"n1": true}
+	var reroutes []string
+	s.SetExclusion(Exclusion{
+		Excluded: func(nodeID string) (string, bool) { return "incident", incident[nodeID] },
+		OnReroute: func(nodeID, reason string) {
+			reroutes = append(reroutes, nodeID+":"+reason)
+		},
+	})
+	if got, key := s.SelectNode([]Features{bad, ok}); got.NodeID != "n2" || key != ok.armKey() {
+		t.Errorf("with n1 mid-incident selected %s (%s), want n2", got.NodeID, key)
+	}
+
+	incident["n2"] = true
+	if got, key := s.SelectNode([]Features{bad, ok}); got.NodeID != "" || key != "" {
+		t.Errorf("with every node excluded selected %q (%q)", got.NodeID, key)
+	}
+	st := s.Stats()
+	if st.Rerouted != 1 || st.Unschedulable != 1 || len(reroutes) != 2 || reroutes[0] != "n1:incident" {
+		t.Errorf("rerouted = %d, unschedulable = %d, hooks = %v", st.Rerouted, st.Unschedulable, reroutes)
+	}
+
+	s.SetExclusion(Exclusion{})
+	if got, _ := s.SelectNode([]Features{bad, ok}); got.NodeID != "n1" {
+		t.Errorf("after removing exclusion selected %s, want n1", got.NodeID)
+	}
+}
+
 func TestSelectNode_Empty(t *testing.T) {
 	s := NewScheduler(DefaultConfig())
 	selected, key := s.SelectNode(nil)
@@ -190,6 +485,29 @@ func TestRecordOutcome_UpdatesStats(t *testing.T) {
 	}
 }
 
+func TestRecordOutcomeWithKey_DropsReplays(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	cfg := DefaultConfig()
+	cfg.Now = fixedClock(now, time.Second)
+	s := NewScheduler(cfg)
+
+	if !s.RecordOutcomeWithKey("task-1", "INFERENCE:idle:gpu:hot", "node-1", 50.0, 10.0) {
+		t.Fatal("first delivery dropped")
+	}
+	if s.RecordOutcomeWithKey("task-1", "INFERENCE:idle:gpu:hot", "node-1", 500.0, 10.0) {
+		t.Error("replay recorded")
+	}
+	s.RecordOutcomeWithKey("task-2", "INFERENCE:idle:gpu:hot", "node-1", 30.0, 8.0)
+
+	stats := s.Stats()
+	if stats.TotalObservations != 2 || stats.DuplicatesDropped != 1 {
+		t.Errorf("stats = %+v, want 2 observations and 1 duplicate", stats)
+	}
+	if stats.MLAvgLatencyMs != 40.0 {
+		t.Errorf("replay skewed avg latency: %f", stats.MLAvgLatencyMs)
+	}
+}
+
 func TestRecordHeuristicBaseline(t *testing.T) {
 	s := NewScheduler(DefaultConfig())
 	s.RecordHeuristicBaseline(100)
@@ -413,3 +731,274 @@ func TestArmStats_Welford(t *testing.T) {
 		t.Errorf("single sample variance should be 0, got %f", single.variance())
 	}
 }
+
+func TestArmStats_Decay(t *testing.T) {
+	now := time.Now()
+	raw := &armStats{decay: 1.0}
+	decayed := &armStats{decay: 0.9}
+
+	// A node that was good for 50 pulls, then degrades for 10
+	for i := 0; i < 50; i++ {
+		raw.update(0.9, now)
+		decayed.update(0.9, now)
+	}
+	for i := 0; i < 10; i++ {
+		raw.update(0.1, now)
+		decayed.update(0.1, now)
+	}
+
+	if math.Abs(raw.decayedMean()-raw.mean) > 1e-9 {
+		t.Errorf("decay 1.0: decayed mean %f != raw mean %f", raw.decayedMean(), raw.mean)
+	}
+	if decayed.mean < 0.7 {
+		t.Errorf("raw mean = %f, want stale rewards to dominate it", decayed.mean)
+	}
+	if decayed.decayedMean() > 0.5 {
+		t.Errorf("decayed mean = %f, want the recent rewards to dominate it", decayed.decayedMean())
+	}
+	if _, eff := decayed.effective(); math.Abs(eff-decayed.decayedMean()) > 1e-9 {
+		t.Errorf("effective mean = %f, want decayed mean %f", eff, decayed.decayedMean())
+	}
+}
+
+// ─── Concurrency ────────────────────────────────────────────────────────────
+
+func TestRecordOutcome_Concurrent(t *testing.T) {
+	s := NewScheduler(DefaultConfig())
+	candidates := []Features{
+		mkFeatures("n1", "INFERENCE", 0.1, true, true),
+		mkFeatures("n2", "INFERENCE", 0.9, false, false),
+	}
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 500; i++ {
+				_, key := s.SelectNode(candidates)
+				s.RecordOutcome(key, fmt.Sprintf("node-%d", g), 50, 5)
+				if i%100 == 0 {
+					s.RecordHeuristicBaseline(80)
+					s.Stats()
+					s.Observations(10)
+					s.Arms()
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	st := s.Stats()
+	if st.TotalObservations != 8*500 || st.UniqueNodes != 8 {
+		t.Errorf("Stats = %+v, want %d observations on 8 nodes", st, 8*500)
+	}
+	pulls := 0
+	for _, a := range s.Arms() {
+		pulls += a.Pulls
+	}
+	if pulls != 8*500 {
+		t.Errorf("arm pulls = %d, want %d", pulls, 8*500)
+	}
+	if n := len(s.Observations(10_000)); n != 8*500 {
+		t.Errorf("history holds %d observations, want %d", n, 8*500)
+	}
+}
+
+// BenchmarkRecordOutcome_Parallel records outcomes from every core. Arm
+// stats, history, fairness counters and latency trackers are locked
+// separately, so concurrent outcomes only serialize on the short critical
+// section they share; compare runs with -cpu 1,4,8.
+func BenchmarkRecordOutcome_Parallel(b *testing.B) {
+	for _, arms := range []int{1, 16} {
+		b.Run(fmt.Sprintf("arms=%d", arms), func(b *testing.B) {
+			cfg := DefaultConfig()
+			cfg.HistoryCapacity = 10_000
+			s := NewScheduler(cfg)
+			keys := make([]string, arms)
+			for i := range keys {
+				keys[i] = fmt.Sprintf("INFERENCE:idle:gpu:hot:%d", i)
+			}
+			nodes := make([]string, 32)
+			for i := range nodes {
+				nodes[i] = fmt.Sprintf("node-%d", i)
+			}
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				i := 0
+				for pb.Next() {
+					s.RecordOutcome(keys[i%arms], nodes[i%len(nodes)], 50, 5)
+					i++
+				}
+			})
+		})
+	}
+}
+
+// ─── Shadow Mode ────────────────────────────────────────────────────────────
+
+func TestShadow_CounterfactualReport(t *testing.T) {
+	s := NewScheduler(DefaultConfig())
+	fast := mkFeatures("n1", "INFERENCE", 0.1, true, true)
+	slow := mkFeatures("n2", "INFERENCE", 0.9, false, false)
+	latency := map[string]float64{"n1": 50, "n2": 500}
+
+	// The heuristic alternates; the bandit learns from its outcomes
+	for i := 0; i < 200; i++ {
+		id := fmt.Sprintf("t%d", i)
+		d, ok := s.Shadow(id, []Features{fast, slow}, i%2)
+		if !ok {
+			t.Fatalf("Shadow(%s) not recorded", id)
+		}
+		if !s.RecordShadowOutcome(id, latency[d.HeuristicNodeID], 10) {
+			t.Fatalf("RecordShadowOutcome(%s) found no decision", id)
+		}
+	}
+	if s.RecordShadowOutcome("t0", 50, 10) {
+		t.Error("outcome recorded twice")
+	}
+
+	r := s.ShadowReport()
+	if r.Decisions != 200 || r.Resolved != 200 || r.Pending != 0 {
+		t.Errorf("report = %+v, want 200 decisions, all resolved", r)
+	}
+	if r.Agreed+r.Estimated+r.Unestimated != r.Resolved {
+		t.Errorf("agreed %d + estimated %d + unestimated %d != resolved %d", r.Agreed, r.Estimated, r.Unestimated, r.Resolved)
+	}
+	if !r.GatePassed(30) {
+		t.Errorf("improvement = %.1f%% (heur %.0fms, ml %.0fms), want the gate passed", r.ImprovementPct, r.HeurAvgLatencyMs, r.MLAvgLatencyMs)
+	}
+	if st := s.Stats(); st.MLAvgLatencyMs != 0 || st.HeurAvgLatencyMs != 0 || s.GatePassed(30) {
+		t.Errorf("shadow outcomes leaked into Stats: %+v", st)
+	}
+}
+
+func TestShadow_BoundsPending(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.ShadowMaxPending = 2
+	s := NewScheduler(cfg)
+	c := []Features{mkFeatures("n1", "INFERENCE", 0.1, true, true)}
+
+	for _, id := range []string{"a", "b", "c"} {
+		s.Shadow(id, c, 0)
+	}
+	if _, ok := s.Shadow("c", c, 0); ok {
+		t.Error("pending task ID shadowed twice")
+	}
+	if _, ok := s.Shadow("d", c, 1); ok {
+		t.Error("out-of-range heuristic pick accepted")
+	}
+	if s.RecordShadowOutcome("a", 50, 10) {
+		t.Error("oldest decision was not dropped")
+	}
+	if r := s.ShadowReport(); r.Pending != 2 || r.Decisions != 3 {
+		t.Errorf("report = %+v, want 2 pending of 3", r)
+	}
+}
+
+func TestThompsonSampling(t *testing.T) {
+	for _, policy := range []SelectionPolicy{NewThompsonBeta(7), NewThompsonGaussian(7)} {
+		t.Run(policy.Name(), func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.Policy = policy
+			cfg.PriorWeight = 0
+			s := NewScheduler(cfg)
+
+			good := mkFeatures("n1", "INFERENCE", 0.1, true, true)
+			bad := mkFeatures("n2", "INFERENCE", 0.9, false, false)
+			for i := 0; i < 30; i++ {
+				s.RecordOutcome(good.armKey(), "n1", float64(100+(i%5)*40), 5) // noisy but fast
+				s.RecordOutcome(bad.armKey(), "n2", float64(700+(i%5)*40), 5)
+			}
+
+			picks := 0
+			for i := 0; i < 200; i++ {
+				if f, _ := s.SelectNode([]Features{bad, good}); f.NodeID == "n1" {
+					picks++
+				}
+			}
+			if picks < 190 {
+				t.Errorf("picked the better node %d/200 times", picks)
+			}
+
+			if got := s.Stats().Policy; got != policy.Name() {
+				t.Errorf("Stats.Policy = %q, want %q", got, policy.Name())
+			}
+			for _, a := range s.Arms() {
+				if a.Posterior == nil {
+					t.Fatalf("arm %s has no posterior", a.Key)
+				}
+			}
+		})
+	}
+
+	if _, ok := PolicyByName("epsilon-greedy", 0); ok {
+		t.Error("PolicyByName accepted an unknown policy")
+	}
+	if s := NewScheduler(DefaultConfig()); s.Policy() != PolicyUCB1 {
+		t.Errorf("default policy = %q", s.Policy())
+	}
+}
+
+func TestThompsonBeta_Posterior(t *testing.T) {
+	p := NewThompsonBeta(1).Posterior(ArmState{N: 10, Mean: 0.8})
+	if math.Abs(p.Alpha-9) > 1e-9 || math.Abs(p.Beta-3) > 1e-9 {
+		t.Errorf("posterior = %+v, want Beta(9, 3)", p)
+	}
+	// Samples stay in [0, 1] and centre on the posterior mean 0.75.
+	tb := NewThompsonBeta(1)
+	var sum float64
+	for i := 0; i < 2000; i++ {
+		x := tb.Score(ArmState{N: 10, Mean: 0.8})
+		if x < 0 || x > 1 {
+			t.Fatalf("sample %f outside [0, 1]", x)
+		}
+		sum += x
+	}
+	if mean := sum / 2000; math.Abs(mean-0.75) > 0.02 {
+		t.Errorf("sample mean = %f, want ~0.75", mean)
+	}
+}
+
+func TestRecordPipelineOutcome(t *testing.T) {
+	s := NewScheduler(DefaultConfig())
+	steps := []PipelineStep{
+		{ArmKey: "AGENT:idle:gpu:hot", NodeID: "n1", LatencyMs: 100, CreditCost: 2},
+		{ArmKey: "AGENT:heavy:nogpu:cold", NodeID: "n2", LatencyMs: 300, CreditCost: 2},
+		{ArmKey: "AGENT:idle:gpu:hot", NodeID: "n3", LatencyMs: 100, CreditCost: 2},
+	}
+
+	total := s.ComputeReward(500, 6)
+	rewards := s.PipelineRewards(steps)
+	if rewards[1] >= rewards[0] || rewards[0] != rewards[2] {
+		t.Errorf("rewards = %v, want the slow step lowest", rewards)
+	}
+	if mean := (rewards[0] + rewards[1] + rewards[2]) / 3; math.Abs(mean-total) > 1e-9 {
+		t.Errorf("mean step reward = %f, want the pipeline reward %f", mean, total)
+	}
+
+	if !s.RecordPipelineOutcome("run-1", steps) {
+		t.Fatal("RecordPipelineOutcome = false")
+	}
+	if s.RecordPipelineOutcome("run-1", steps) {
+		t.Error("replayed pipeline was recorded")
+	}
+	if s.RecordPipelineOutcome("", nil) {
+		t.Error("empty pipeline was recorded")
+	}
+
+	st := s.Stats()
+	if st.TotalObservations != 3 || st.UniqueNodes != 3 || st.MLAvgLatencyMs != 500 || st.DuplicatesDropped != 1 {
+		t.Errorf("stats = %+v", st)
+	}
+	pulls := make(map[string]int)
+	for _, a := range s.Arms() {
+		pulls[a.Key] = a.Pulls
+	}
+	if pulls["AGENT:idle:gpu:hot"] != 2 || pulls["AGENT:heavy:nogpu:cold"] != 1 {
+		t.Errorf("pulls = %v", pulls)
+	}
+	if obs := s.Observations(10); len(obs) != 3 || obs[1].NodeID != "n2" {
+		t.Errorf("observations = %+v", obs)
+	}
+}
@@ -0,0 +1,98 @@
+package mlscheduler
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ─── Per-Node Arms ──────────────────────────────────────────────────────────
+// armKey buckets features, so two very different nodes that happen to be
+// equally loaded, GPU-equipped and warm share one arm: the bandit learns
+// the bucket, never the node. With Config.PerNodeArms, a node's arms are
+// the bucket keys suffixed with "@nodeID":
+//
+//   - a node's new arm starts from what its bucket has learned (the
+//     prior), so a node is not explored from scratch in every bucket
+//   - every outcome on a node's arm also updates its bucket arm, which
+//     keeps learning for new nodes and those past MaxNodeArms
+//   - at most MaxNodeArms nodes get arms of their own, first come first
+//     served, so the arm space stays bounded at MaxNodeArms × buckets;
+//     later nodes share the bucket arms as before
+
+// nodeArmSep separates a bucket key from the node ID in a per-node arm key.
+const nodeArmSep = "@"
+
+// priorityArmSep introduces the priority class in the key of a class with
+// reward weights of its own.
+const priorityArmSep = ":p"
+
+// keyFor returns the arm f is scored and rewarded under. Must be called
+// with s.mu.RLock held and s.armsMu not held.
+func (s *Scheduler) keyFor(f Features) string {
+	key := f.armKey()
+	if _, ok := s.cfg.PriorityWeights[f.Priority]; ok {
+		key += priorityArmSep + strconv.Itoa(f.Priority)
+	}
+	if !s.cfg.PerNodeArms || f.NodeID == "" {
+		return key
+	}
+	s.armsMu.RLock()
+	known := s.nodeArms[f.NodeID]
+	s.armsMu.RUnlock()
+	if !known {
+		s.armsMu.Lock()
+		if !s.nodeArms[f.NodeID] && len(s.nodeArms) < s.cfg.MaxNodeArms {
+			s.nodeArms[f.NodeID] = true
+		}
+		known = s.nodeArms[f.NodeID]
+		s.armsMu.Unlock()
+	}
+	if !known {
+		return key
+	}
+	return key + nodeArmSep + f.NodeID
+}
+
+// priorityOf returns the priority class an arm key was split out for
+// (see Config.PriorityWeights).
+func priorityOf(armKey string) (int, bool) {
+	bucket := bucketKey(armKey)
+	i := strings.LastIndex(bucket, priorityArmSep)
+	if i < 0 {
+		return 0, false
+	}
+	class, err := strconv.Atoi(bucket[i+len(priorityArmSep):])
+	return class, err == nil
+}
+
+// bucketKey strips the node suffix from a per-node arm key.
+func bucketKey(armKey string) string {
+	bucket, _, _ := strings.Cut(armKey, nodeArmSep)
+	return bucket
+}
+
+// outcomeArms lists the arms an outcome on armKey updates: the arm itself
+// and, for a node's own arm, its bucket.
+func outcomeArms(armKey string) []string {
+	if bucket := bucketKey(armKey); bucket != armKey {
+		return []string{armKey, bucket}
+	}
+	return []string{armKey}
+}
+
+// priorLocked returns the prior mean for a new arm: the bucket's mean once
+// it has MinObservations for a node's own arm, else the heuristic score.
+// Must be called with s.armsMu held.
+func (s *Scheduler) priorLocked(key string, f Features) float64 {
+	if bucket := bucketKey(key); bucket != key {
+		if arm, ok := s.arms[bucket]; ok {
+			arm.mu.Lock()
+			n, mean := arm.effective()
+			arm.mu.Unlock()
+			if n >= float64(s.cfg.MinObservations) {
+				return mean
+			}
+		}
+	}
+	return HeuristicScore(f)
+}
@@ -0,0 +1,87 @@
+package mlscheduler
+
+import "math"
+
+// ─── Pipeline Outcomes ──────────────────────────────────────────────────────
+// Agent runs and fine-tunes span several nodes, one scheduling decision
+// per step, but only the pipeline as a whole has an outcome worth
+// scoring: the user waited for the sum of the steps. Crediting that
+// outcome to one arm teaches the others nothing; crediting every arm with
+// the full reward blames a fast step for a slow one.
+//
+// RecordPipelineOutcome scores the pipeline once — total latency, total
+// cost — and splits the shortfall from a perfect reward across the steps
+// in proportion to their share of the latency:
+//
+//	reward_i = clamp(1 - n · share_i · (1 - R), 0, 1)
+//
+// With equal shares every step gets R; a step that took more than its
+// share gets less, one that took less gets more, and the steps' mean
+// reward stays R. Each step counts as one pull of its arm and one task
+// toward its node's fair share, and appears in the observation history.
+
+// PipelineStep is one step of a multi-node pipeline.
+type PipelineStep struct {
+	ArmKey     string  `json:"arm_key"` // as returned by SelectNode for the step
+	NodeID     string  `json:"node_id"`
+	LatencyMs  float64 `json:"latency_ms"`
+	CreditCost float64 `json:"credit_cost"`
+}
+
+// PipelineRewards splits the reward of a pipeline across its steps (see
+// above) without recording anything. The pipeline is scored with the
+// weights of the first step's priority class.
+func (s *Scheduler) PipelineRewards(steps []PipelineStep) []float64 {
+	if len(steps) == 0 {
+		return nil
+	}
+	var latency, cost float64
+	for _, st := range steps {
+		latency += math.Max(st.LatencyMs, 0)
+		cost += math.Max(st.CreditCost, 0)
+	}
+	total := s.outcomeReward(steps[0].ArmKey, latency, cost)
+
+	rewards := make([]float64, len(steps))
+	n := float64(len(steps))
+	for i, st := range steps {
+		share := 1 / n
+		if latency > 0 {
+			share = math.Max(st.LatencyMs, 0) / latency
+		}
+		rewards[i] = math.Min(math.Max(1-n*share*(1-total), 0), 1)
+	}
+	return rewards
+}
+
+// RecordPipelineOutcome records the outcome of a multi-node pipeline,
+// rewarding each step's arm with its share (see PipelineRewards). Like
+// RecordOutcomeWithKey it drops a replayed idempotency key, and it
+// reports whether the outcome was recorded; an empty pipeline is not.
+func (s *Scheduler) RecordPipelineOutcome(key string, steps []PipelineStep) bool {
+	if len(steps) == 0 {
+		return false
+	}
+	now := s.cfg.Now()
+	if s.dedup.Seen(key, now) {
+		s.duplicates.Add(1)
+		return false
+	}
+	var latency float64
+	for i, r := range s.PipelineRewards(steps) {
+		st := steps[i]
+		s.learn(st.ArmKey, st.NodeID, r, now)
+		s.observe(Observation{
+			ArmKey:     st.ArmKey,
+			NodeID:     st.NodeID,
+			Reward:     r,
+			LatencyMs:  st.LatencyMs,
+			CreditCost: st.CreditCost,
+			RecordedAt: now,
+		})
+		latency += st.LatencyMs
+	}
+	// The user waited for the whole pipeline; compare that to the heuristic.
+	s.trackLatency(latency)
+	return true
+}
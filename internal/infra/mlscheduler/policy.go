@@ -0,0 +1,180 @@
+package mlscheduler
+
+import (
+	"math"
+
+	"github.com/tutu-network/tutu/internal/infra/dsa"
+)
+
+// ─── Selection Policies ─────────────────────────────────────────────────────
+// SelectNode asks a SelectionPolicy to score each candidate's arm and picks
+// the highest score. UCB1 is the default: deterministic, but its
+// exploration bonus shrinks only with pulls, so with noisy rewards it keeps
+// revisiting arms long after their means have separated. Thompson sampling
+// instead draws a plausible mean from each arm's posterior and picks the
+// best draw — an arm is tried about as often as it is likely to be the
+// best, which converges faster when rewards are noisy.
+//
+//   - ThompsonBeta treats a reward in [0, 1] as a fractional success: the
+//     posterior is Beta(1 + n·mean, 1 + n·(1-mean))
+//   - ThompsonGaussian uses a normal posterior around the mean whose width
+//     is the reward spread over √(n+1)
+//
+// Both read the same effective statistics UCB1 does: the decayed mean,
+// with the prior and peers' shared experience blended in. Arms below
+// MinObservations are still explored first under every policy.
+
+// ArmState is what a policy sees of an arm.
+type ArmState struct {
+	N           float64 // effective pulls: local, prior and peers' pseudo-observations
+	Mean        float64 // effective mean reward in [0, 1]
+	Variance    float64 // sample variance of the local rewards (0 below 2 pulls)
+	Pulls       int     // local pulls
+	Total       float64 // pulls across all arms
+	Exploration float64 // Config.ExplorationFactor
+}
+
+// Posterior is a policy's belief about an arm's mean reward. Beta policies
+// fill Alpha and Beta, Gaussian ones Mu and Sigma.
+type Posterior struct {
+	Alpha float64 `json:"alpha,omitempty"`
+	Beta  float64 `json:"beta,omitempty"`
+	Mu    float64 `json:"mu,omitempty"`
+	Sigma float64 `json:"sigma,omitempty"`
+}
+
+// SelectionPolicy scores arms for SelectNode. Score may be randomized, and
+// must be safe for concurrent use.
+type SelectionPolicy interface {
+	Name() string
+	Score(a ArmState) float64
+}
+
+// PosteriorPolicy is implemented by policies that keep a posterior per
+// arm; Arms reports it.
+type PosteriorPolicy interface {
+	SelectionPolicy
+	Posterior(a ArmState) Posterior
+}
+
+// Policy names.
+const (
+	PolicyUCB1             = "ucb1"
+	PolicyThompsonBeta     = "thompson-beta"
+	PolicyThompsonGaussian = "thompson-gaussian"
+)
+
+// PolicyByName returns the named built-in policy, seeding Thompson sampling
+// with seed (0 = from the clock). It reports false for an unknown name.
+func PolicyByName(name string, seed int64) (SelectionPolicy, bool) {
+	switch name {
+	case PolicyUCB1, "":
+		return UCB1{}, true
+	case PolicyThompsonBeta:
+		return NewThompsonBeta(seed), true
+	case PolicyThompsonGaussian:
+		return NewThompsonGaussian(seed), true
+	}
+	return nil, false
+}
+
+// ─── UCB1 ───────────────────────────────────────────────────────────────────
+
+// UCB1 scores an arm by its mean plus an exploration bonus (see ucb1Score).
+type UCB1 struct{}
+
+// Name implements SelectionPolicy.
+func (UCB1) Name() string { return PolicyUCB1 }
+
+// Score implements SelectionPolicy.
+func (UCB1) Score(a ArmState) float64 {
+	if a.N == 0 {
+		return math.Inf(1) // never pulled, no prior → infinite optimism → always try
+	}
+	total := a.Total
+	if total < 1 {
+		total = 1
+	}
+	return a.Mean + a.Exploration*math.Sqrt(math.Log(total)/a.N)
+}
+
+// ─── Thompson Sampling ──────────────────────────────────────────────────────
+
+// ThompsonBeta samples an arm's mean from a Beta posterior.
+type ThompsonBeta struct {
+	rng *dsa.Rand
+}
+
+// NewThompsonBeta returns a Beta Thompson sampler seeded with seed
+// (0 = from the clock).
+func NewThompsonBeta(seed int64) *ThompsonBeta {
+	return &ThompsonBeta{rng: dsa.NewRand(seed)}
+}
+
+// Name implements SelectionPolicy.
+func (*ThompsonBeta) Name() string { return PolicyThompsonBeta }
+
+// Posterior implements PosteriorPolicy.
+func (*ThompsonBeta) Posterior(a ArmState) Posterior {
+	mean := math.Min(math.Max(a.Mean, 0), 1)
+	return Posterior{Alpha: 1 + a.N*mean, Beta: 1 + a.N*(1-mean)}
+}
+
+// Score implements SelectionPolicy.
+func (t *ThompsonBeta) Score(a ArmState) float64 {
+	p := t.Posterior(a)
+	x := sampleGamma(t.rng, p.Alpha)
+	y := sampleGamma(t.rng, p.Beta)
+	return x / (x + y)
+}
+
+// ThompsonGaussian samples an arm's mean from a normal posterior.
+type ThompsonGaussian struct {
+	rng *dsa.Rand
+}
+
+// NewThompsonGaussian returns a Gaussian Thompson sampler seeded with seed
+// (0 = from the clock).
+func NewThompsonGaussian(seed int64) *ThompsonGaussian {
+	return &ThompsonGaussian{rng: dsa.NewRand(seed)}
+}
+
+// Name implements SelectionPolicy.
+func (*ThompsonGaussian) Name() string { return PolicyThompsonGaussian }
+
+// maxRewardVariance is the variance assumed before an arm has two local
+// rewards: the largest a reward in [0, 1] can have.
+const maxRewardVariance = 0.25
+
+// Posterior implements PosteriorPolicy.
+func (*ThompsonGaussian) Posterior(a ArmState) Posterior {
+	v := a.Variance
+	if a.Pulls < 2 {
+		v = maxRewardVariance
+	}
+	return Posterior{Mu: a.Mean, Sigma: math.Sqrt(v / (a.N + 1))}
+}
+
+// Score implements SelectionPolicy.
+func (t *ThompsonGaussian) Score(a ArmState) float64 {
+	p := t.Posterior(a)
+	return p.Mu + p.Sigma*t.rng.NormFloat64()
+}
+
+// sampleGamma draws from Gamma(shape, 1) for shape >= 1 (Marsaglia–Tsang).
+func sampleGamma(rng *dsa.Rand, shape float64) float64 {
+	d := shape - 1.0/3.0
+	c := 1 / math.Sqrt(9*d)
+	for {
+		x := rng.NormFloat64()
+		v := 1 + c*x
+		if v <= 0 {
+			continue
+		}
+		v = v * v * v
+		u := rng.Float64()
+		if u < 1-0.0331*x*x*x*x || math.Log(u) < 0.5*x*x+d*(1-v+math.Log(v)) {
+			return d * v
+		}
+	}
+}
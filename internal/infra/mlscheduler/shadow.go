@@ -0,0 +1,194 @@
+package mlscheduler
+
+import "time"
+
+// ─── Shadow Mode ────────────────────────────────────────────────────────────
+// Before the bandit places real traffic, it can run in shadow: the
+// heuristic keeps scheduling, and for each task Shadow records which node
+// the bandit WOULD have picked. When the task finishes, RecordShadowOutcome
+// completes the decision:
+//
+//   - the heuristic's pick is rewarded as if the bandit had chosen it, so
+//     the bandit learns from the heuristic's traffic
+//   - the node's observed latency is folded into a per-node mean
+//   - where both picked the same node, the counterfactual latency is the
+//     actual one; otherwise it is the mean latency observed on the
+//     bandit's node, and the decision stays unestimated until the
+//     heuristic has sent that node work
+//
+// ShadowReport compares the two, so the 30% gate can be checked before any
+// traffic is flipped. Shadow outcomes do not count toward Stats, which
+// measures traffic the bandit actually placed. The per-node mean ignores
+// the node's load at the time, so the estimate is only fair while both
+// policies spread work similarly.
+
+// ShadowDecision is what the bandit would have done with one task the
+// heuristic placed.
+type ShadowDecision struct {
+	TaskID          string
+	HeuristicNodeID string
+	HeuristicArm    string // arm the heuristic's pick is rewarded under
+	MLNodeID        string // "" if every candidate was excluded
+	MLArm           string
+	Agreed          bool // both picked the same node
+	DecidedAt       time.Time
+}
+
+// ShadowReport compares the heuristic's actual latency with the bandit's
+// counterfactual latency over the resolved shadow decisions.
+type ShadowReport struct {
+	Decisions   int64 `json:"decisions"`   // shadow decisions made
+	Pending     int   `json:"pending"`     // decisions awaiting an outcome
+	Resolved    int64 `json:"resolved"`    // decisions with an outcome
+	Agreed      int64 `json:"agreed"`      // resolved decisions where both picked the same node
+	Estimated   int64 `json:"estimated"`   // resolved disagreements with a counterfactual latency
+	Unestimated int64 `json:"unestimated"` // resolved disagreements on nodes with no latency yet
+
+	// Over Agreed + Estimated decisions.
+	HeurAvgLatencyMs float64 `json:"heur_avg_latency_ms"`
+	MLAvgLatencyMs   float64 `json:"ml_avg_latency_ms"` // counterfactual
+	ImprovementPct   float64 `json:"improvement_pct"`   // (heur - ml) / heur * 100 — positive = ML is better
+	AgreementPct     float64 `json:"agreement_pct"`     // Agreed / Resolved * 100
+}
+
+// GatePassed reports whether the bandit's counterfactual latency beats the
+// heuristic by at least minImprovementPct, the shadow counterpart of
+// Scheduler.GatePassed.
+func (r ShadowReport) GatePassed(minImprovementPct float64) bool {
+	return r.HeurAvgLatencyMs > 0 && r.ImprovementPct >= minImprovementPct
+}
+
+// latencyMean is a running mean latency.
+type latencyMean struct {
+	n    int64
+	mean float64
+}
+
+// shadowState is the shadow bookkeeping, guarded by Scheduler.shadowMu.
+type shadowState struct {
+	pending map[string]ShadowDecision // task ID → decision
+	order   []string                  // pending task IDs, oldest first
+	nodes   map[string]*latencyMean   // node → latency observed in shadow
+
+	decisions, resolved, agreed, estimated, unestimated int64
+	heurSum, mlSum                                      float64
+}
+
+func newShadowState() shadowState {
+	return shadowState{
+		pending: make(map[string]ShadowDecision),
+		nodes:   make(map[string]*latencyMean),
+	}
+}
+
+// Shadow records which of candidates the bandit would pick for taskID,
+// where the heuristic picked candidates[heuristic]. It returns false,
+// recording nothing, if heuristic is out of range or taskID is empty or
+// already pending.
+func (s *Scheduler) Shadow(taskID string, candidates []Features, heuristic int) (ShadowDecision, bool) {
+	if taskID == "" || heuristic < 0 || heuristic >= len(candidates) {
+		return ShadowDecision{}, false
+	}
+	chosen, mlArm := s.SelectNode(candidates)
+	s.mu.RLock()
+	heurArm := s.keyFor(candidates[heuristic])
+	limit := s.cfg.ShadowMaxPending
+	s.mu.RUnlock()
+
+	d := ShadowDecision{
+		TaskID:          taskID,
+		HeuristicNodeID: candidates[heuristic].NodeID,
+		HeuristicArm:    heurArm,
+		MLNodeID:        chosen.NodeID,
+		MLArm:           mlArm,
+		Agreed:          chosen.NodeID == candidates[heuristic].NodeID,
+		DecidedAt:       s.cfg.Now(),
+	}
+
+	s.shadowMu.Lock()
+	defer s.shadowMu.Unlock()
+	sh := &s.shadow
+	if _, ok := sh.pending[taskID]; ok {
+		return ShadowDecision{}, false
+	}
+	for len(sh.order) >= limit {
+		delete(sh.pending, sh.order[0])
+		sh.order = sh.order[1:]
+	}
+	sh.pending[taskID] = d
+	sh.order = append(sh.order, taskID)
+	sh.decisions++
+	return d, true
+}
+
+// RecordShadowOutcome completes taskID's shadow decision with the outcome
+// of the heuristic's pick. It reports whether a pending decision was found.
+func (s *Scheduler) RecordShadowOutcome(taskID string, latencyMs, creditCost float64) bool {
+	s.shadowMu.Lock()
+	sh := &s.shadow
+	d, ok := sh.pending[taskID]
+	if !ok {
+		s.shadowMu.Unlock()
+		return false
+	}
+	delete(sh.pending, taskID)
+	for i, id := range sh.order {
+		if id == taskID {
+			sh.order = append(sh.order[:i], sh.order[i+1:]...)
+			break
+		}
+	}
+
+	// Estimate before folding in this outcome, so a node's first task
+	// never serves as its own counterfactual.
+	sh.resolved++
+	switch ml := sh.nodes[d.MLNodeID]; {
+	case d.Agreed:
+		sh.agreed++
+		sh.heurSum += latencyMs
+		sh.mlSum += latencyMs
+	case ml != nil:
+		sh.estimated++
+		sh.heurSum += latencyMs
+		sh.mlSum += ml.mean
+	default:
+		sh.unestimated++
+	}
+	lm := sh.nodes[d.HeuristicNodeID]
+	if lm == nil {
+		lm = &latencyMean{}
+		sh.nodes[d.HeuristicNodeID] = lm
+	}
+	lm.n++
+	lm.mean += (latencyMs - lm.mean) / float64(lm.n)
+	s.shadowMu.Unlock()
+
+	s.learn(d.HeuristicArm, d.HeuristicNodeID, s.outcomeReward(d.HeuristicArm, latencyMs, creditCost), s.cfg.Now())
+	return true
+}
+
+// ShadowReport returns the shadow comparison so far.
+func (s *Scheduler) ShadowReport() ShadowReport {
+	s.shadowMu.Lock()
+	defer s.shadowMu.Unlock()
+	sh := &s.shadow
+	r := ShadowReport{
+		Decisions:   sh.decisions,
+		Pending:     len(sh.pending),
+		Resolved:    sh.resolved,
+		Agreed:      sh.agreed,
+		Estimated:   sh.estimated,
+		Unestimated: sh.unestimated,
+	}
+	if compared := sh.agreed + sh.estimated; compared > 0 {
+		r.HeurAvgLatencyMs = sh.heurSum / float64(compared)
+		r.MLAvgLatencyMs = sh.mlSum / float64(compared)
+	}
+	if r.HeurAvgLatencyMs > 0 {
+		r.ImprovementPct = (r.HeurAvgLatencyMs - r.MLAvgLatencyMs) / r.HeurAvgLatencyMs * 100.0
+	}
+	if sh.resolved > 0 {
+		r.AgreementPct = float64(sh.agreed) / float64(sh.resolved) * 100.0
+	}
+	return r
+}
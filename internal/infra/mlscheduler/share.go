@@ -0,0 +1,133 @@
+package mlscheduler
+
+import (
+	"sort"
+)
+
+// ─── Fleet Learning ─────────────────────────────────────────────────────────
+// Every node learns arm statistics from its own outcomes, so a new node
+// starts from scratch. Nodes periodically exchange summaries (pull count
+// and mean per arm) and blend their peers' experience into their own:
+//
+//   - A peer's summary counts as trust × pulls pseudo-observations at the
+//     peer's mean, trust being 0..1 (e.g. the peer's reputation)
+//   - Each peer's latest summary replaces its previous one, so repeated
+//     exchanges never double count
+//   - Remote pseudo-observations per arm are capped at RemoteWeightCap, so
+//     local outcomes soon outweigh the fleet's
+//   - Summaries carry only local observations; peers' experience is never
+//     echoed back into the fleet
+
+// maxSummaries bounds a summary to the most-pulled arms.
+const maxSummaries = 256
+
+// ArmSummary is one arm's locally observed statistics.
+type ArmSummary struct {
+	Key   string  `json:"key"`
+	Pulls int     `json:"pulls"`
+	Mean  float64 `json:"mean"`
+}
+
+// remoteStat is one peer's contribution to an arm.
+type remoteStat struct {
+	n    float64 // trust-weighted pulls
+	mean float64
+}
+
+// Summaries returns the locally observed statistics of the most-pulled
+// arms, for sharing with peers.
+func (s *Scheduler) Summaries() []ArmSummary {
+	s.armsMu.RLock()
+	defer s.armsMu.RUnlock()
+
+	out := make([]ArmSummary, 0, len(s.arms))
+	for key, arm := range s.arms {
+		arm.mu.Lock()
+		if arm.pulls > 0 {
+			out = append(out, ArmSummary{Key: key, Pulls: arm.pulls, Mean: arm.decayedMean()})
+		}
+		arm.mu.Unlock()
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Pulls != out[j].Pulls {
+			return out[i].Pulls > out[j].Pulls
+		}
+		return out[i].Key < out[j].Key
+	})
+	if len(out) > maxSummaries {
+		out = out[:maxSummaries]
+	}
+	return out
+}
+
+// MergeRemote replaces nodeID's contribution with sums, weighted by trust
+// (clamped to 0..1; 0 forgets the node). It returns how many arms were
+// merged. Malformed entries are skipped.
+func (s *Scheduler) MergeRemote(nodeID string, sums []ArmSummary, trust float64) int {
+	if trust <= 0 {
+		s.ForgetRemote(nodeID)
+		return 0
+	}
+	if trust > 1 {
+		trust = 1
+	}
+	latest := make(map[string]ArmSummary, len(sums))
+	for _, sum := range sums {
+		if sum.Key == "" || sum.Pulls <= 0 || sum.Mean < 0 || sum.Mean > 1 {
+			continue
+		}
+		latest[sum.Key] = sum
+	}
+
+	// Drop the node's contribution to arms missing from this summary
+	s.armsMu.RLock()
+	for key, arm := range s.arms {
+		if _, ok := latest[key]; !ok {
+			arm.mu.Lock()
+			delete(arm.remote, nodeID)
+			arm.mu.Unlock()
+		}
+	}
+	s.armsMu.RUnlock()
+
+	for key, sum := range latest {
+		arm := s.arm(key)
+		arm.mu.Lock()
+		if arm.remote == nil {
+			arm.remote = make(map[string]remoteStat)
+		}
+		arm.remote[nodeID] = remoteStat{n: trust * float64(sum.Pulls), mean: sum.Mean}
+		arm.mu.Unlock()
+	}
+	return len(latest)
+}
+
+// ForgetRemote drops everything learned from nodeID, e.g. when it leaves
+// the network or loses trust.
+func (s *Scheduler) ForgetRemote(nodeID string) {
+	s.armsMu.RLock()
+	defer s.armsMu.RUnlock()
+	for _, arm := range s.arms {
+		arm.mu.Lock()
+		delete(arm.remote, nodeID)
+		arm.mu.Unlock()
+	}
+}
+
+// remoteLocked returns the fleet's pseudo-observations for the arm, capped
+// at limit, and their mean. Must be called with a.mu held.
+func (a *armStats) remoteLocked(limit float64) (n, mean float64) {
+	var sum float64
+	for _, r := range a.remote {
+		n += r.n
+		sum += r.n * r.mean
+	}
+	if n == 0 {
+		return 0, 0
+	}
+	mean = sum / n
+	if limit > 0 && n > limit {
+		n = limit
+	}
+	return n, mean
+}
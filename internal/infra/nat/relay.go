@@ -0,0 +1,428 @@
+package nat
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+// ─── Volunteer Relay ────────────────────────────────────────────────────────
+// When hole punching is impossible (symmetric ↔ symmetric) or fails, two
+// nodes meet at a relay run by a volunteer with a reachable address:
+//
+//  1. A peer sends a bind hello; the relay answers with a cookie derived
+//     from the peer's address, the session and a secret that rotates every
+//     relayCookieEpoch, and keeps no state. The hello is as large as the
+//     answer, so spoofed hellos cannot be amplified
+//  2. The peer echoes the cookie in a bind signed with its node key that
+//     names the other member. The relay binds it only if the cookie matches
+//     the sender's address — the sender receives where it claims to be —
+//     and the signing key and named peer hash to the session ID, so only
+//     the two nodes a session belongs to can join it
+//  3. Data datagrams for a session are forwarded to the other member
+//  4. The relay holds at most MaxSessions sessions and a source address
+//     binds at most MaxSessionsPerSource of them; sessions idle for longer
+//     than SessionTTL are dropped
+//
+// Node IDs are hex-encoded ed25519 public keys, as in gossip. The relay
+// also answers STUN binding requests, so every volunteer doubles as a STUN
+// server for address discovery.
+//
+// Wire format (one datagram each):
+//
+//	'B' session[16] zero[16]                          bind hello
+//	'C' session[16] cookie[16]                        challenge
+//	'B' session[16] cookie[16] key[32] sig[64] peer   signed bind
+//	'A' session[16] members                           bind ack
+//	'D' session[16] payload                           data
+
+const (
+	relayBind      = 'B'
+	relayChallenge = 'C'
+	relayAck       = 'A'
+	relayData      = 'D'
+)
+
+const (
+	relayHeaderLen = 1 + 16                                                        // opcode and session ID
+	relayHelloLen  = relayHeaderLen + 16                                           // header and cookie
+	relaySignedLen = relayHelloLen + ed25519.PublicKeySize + ed25519.SignatureSize // before the peer ID
+)
+
+// relayCookieEpoch is how often the cookie secret rotates. A cookie is
+// accepted in the epoch it was issued and the next.
+const relayCookieEpoch = 30 * time.Second
+
+// relayBindDomain prefixes the signed part of a bind.
+const relayBindDomain = "tutu-relay-bind-v1\n"
+
+// SessionID returns the relay session of two nodes. It is symmetric, so both
+// sides derive the same ID without negotiating.
+func SessionID(a, b string) [16]byte {
+	if b < a {
+		a, b = b, a
+	}
+	sum := sha256.Sum256([]byte(a + "\x00" + b))
+	return [16]byte(sum[:16])
+}
+
+// RelayPacket frames payload as a data datagram for session.
+func RelayPacket(session [16]byte, payload []byte) []byte {
+	pkt := make([]byte, relayHeaderLen+len(payload))
+	pkt[0] = relayData
+	copy(pkt[1:], session[:])
+	copy(pkt[relayHeaderLen:], payload)
+	return pkt
+}
+
+// bindHello frames the unsigned first bind for session.
+func bindHello(session [16]byte) []byte {
+	pkt := make([]byte, relayHelloLen)
+	pkt[0] = relayBind
+	copy(pkt[1:], session[:])
+	return pkt
+}
+
+// signedBind frames a bind for the session between the signer and peer,
+// echoing the relay's cookie.
+func signedBind(session, cookie [16]byte, peer string, pub ed25519.PublicKey, sign func([]byte) []byte) []byte {
+	pkt := make([]byte, 0, relaySignedLen+len(peer))
+	pkt = append(pkt, relayBind)
+	pkt = append(pkt, session[:]...)
+	pkt = append(pkt, cookie[:]...)
+	pkt = append(pkt, pub...)
+	pkt = append(pkt, sign(bindSigningPayload(session, cookie[:], peer))...)
+	return append(pkt, peer...)
+}
+
+// bindSigningPayload is the part of a bind covered by the signature.
+func bindSigningPayload(session [16]byte, cookie []byte, peer string) []byte {
+	msg := make([]byte, 0, len(relayBindDomain)+len(session)+len(cookie)+len(peer))
+	msg = append(msg, relayBindDomain...)
+	msg = append(msg, session[:]...)
+	msg = append(msg, cookie...)
+	return append(msg, peer...)
+}
+
+// RelayConfig configures a volunteer relay.
+type RelayConfig struct {
+	SessionTTL           time.Duration    // idle sessions are dropped (default: 2m)
+	MaxSessions          int              // sessions held at once (default: 1024)
+	MaxSessionsPerSource int              // sessions one IP address may bind (default: 4)
+	Now                  func() time.Time // injectable clock (default: time.Now)
+}
+
+// DefaultRelayConfig returns defaults that outlive a few missed keepalives.
+func DefaultRelayConfig() RelayConfig {
+	return RelayConfig{
+		SessionTTL:           2 * time.Minute,
+		MaxSessions:          1024,
+		MaxSessionsPerSource: 4,
+	}
+}
+
+// relayMember is a node bound to a session.
+type relayMember struct {
+	key  string // node ID
+	addr *net.UDPAddr
+}
+
+// relaySession is one pair of peers meeting at the relay.
+type relaySession struct {
+	members  []relayMember // at most two
+	lastSeen time.Time
+}
+
+// Relay forwards datagrams between peers that cannot reach each other
+// directly. It is safe for concurrent use.
+type Relay struct {
+	mu        sync.Mutex
+	cfg       RelayConfig
+	secret    [32]byte
+	sessions  map[[16]byte]*relaySession
+	perSource map[string]int // source IP → members bound from it
+	forwarded int64
+	dropped   int64
+	rejected  int64
+	bindings  int64
+}
+
+// NewRelay creates a relay.
+func NewRelay(cfg RelayConfig) *Relay {
+	def := DefaultRelayConfig()
+	if cfg.SessionTTL <= 0 {
+		cfg.SessionTTL = def.SessionTTL
+	}
+	if cfg.MaxSessions <= 0 {
+		cfg.MaxSessions = def.MaxSessions
+	}
+	if cfg.MaxSessionsPerSource <= 0 {
+		cfg.MaxSessionsPerSource = def.MaxSessionsPerSource
+	}
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+	r := &Relay{cfg: cfg, sessions: make(map[[16]byte]*relaySession), perSource: make(map[string]int)}
+	if _, err := rand.Read(r.secret[:]); err != nil {
+		panic("nat: crypto/rand: " + err.Error())
+	}
+	return r
+}
+
+// Serve relays datagrams received on conn until ctx is done.
+func (r *Relay) Serve(ctx context.Context, conn net.PacketConn) error {
+	go func() {
+		<-ctx.Done()
+		conn.SetReadDeadline(time.Now())
+	}()
+
+	buf := make([]byte, 64*1024)
+	lastExpire := r.cfg.Now()
+	for {
+		n, from, err := conn.ReadFrom(buf)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			var ne net.Error
+			if errors.As(err, &ne) && ne.Timeout() {
+				continue
+			}
+			return fmt.Errorf("nat: relay read: %w", err)
+		}
+		addr, ok := from.(*net.UDPAddr)
+		if !ok {
+			continue
+		}
+		for _, out := range r.handle(buf[:n], addr) {
+			conn.WriteTo(out.data, out.to)
+		}
+		if now := r.cfg.Now(); now.Sub(lastExpire) > r.cfg.SessionTTL/4 {
+			r.Expire()
+			lastExpire = now
+		}
+	}
+}
+
+// relayOut is a datagram the relay sends in response.
+type relayOut struct {
+	to   *net.UDPAddr
+	data []byte
+}
+
+// handle processes one datagram and returns the datagrams to send.
+func (r *Relay) handle(pkt []byte, from *net.UDPAddr) []relayOut {
+	if resp, err := BindingResponse(pkt, from); err == nil {
+		return []relayOut{{to: from, data: resp}}
+	}
+	if len(pkt) < relayHeaderLen {
+		return nil
+	}
+	session := [16]byte(pkt[1:relayHeaderLen])
+
+	switch pkt[0] {
+	case relayBind:
+		return r.bind(pkt, session, from)
+
+	case relayData:
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		s := r.sessions[session]
+		i := -1
+		if s != nil {
+			i = s.index(from)
+		}
+		if i < 0 || len(s.members) < 2 {
+			r.dropped++
+			return nil
+		}
+		s.lastSeen = r.cfg.Now()
+		r.forwarded++
+		return []relayOut{{to: s.members[1-i].addr, data: append([]byte(nil), pkt...)}}
+	}
+	return nil
+}
+
+// bind answers a bind hello with a challenge and binds a signed bind that
+// echoes a valid cookie.
+func (r *Relay) bind(pkt []byte, session [16]byte, from *net.UDPAddr) []relayOut {
+	if len(pkt) < relayHelloLen {
+		r.count(&r.rejected)
+		return nil
+	}
+	now := r.cfg.Now()
+	cookie := pkt[relayHeaderLen:relayHelloLen]
+	if len(pkt) == relayHelloLen || !r.validCookie(cookie, session, from, now) {
+		// A hello, or a cookie issued to another address or too long ago
+		c := r.cookie(session, from, epochOf(now))
+		out := make([]byte, 0, relayHelloLen)
+		out = append(append(append(out, relayChallenge), session[:]...), c[:]...)
+		return []relayOut{{to: from, data: out}}
+	}
+	if len(pkt) <= relaySignedLen {
+		r.count(&r.rejected)
+		return nil
+	}
+	pub := ed25519.PublicKey(pkt[relayHelloLen : relayHelloLen+ed25519.PublicKeySize])
+	sig := pkt[relayHelloLen+ed25519.PublicKeySize : relaySignedLen]
+	peer := string(pkt[relaySignedLen:])
+	key := hex.EncodeToString(pub)
+	if SessionID(key, peer) != session || !ed25519.Verify(pub, bindSigningPayload(session, cookie, peer), sig) {
+		r.count(&r.rejected)
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s := r.sessions[session]
+	i := -1
+	if s != nil {
+		i = s.member(key)
+	}
+	if i < 0 || !sameAddr(s.members[i].addr, from) {
+		switch {
+		case s == nil && len(r.sessions) >= r.cfg.MaxSessions,
+			r.perSource[from.IP.String()] >= r.cfg.MaxSessionsPerSource,
+			i < 0 && s != nil && len(s.members) == 2:
+			r.dropped++
+			return nil
+		}
+	}
+	if s == nil {
+		s = &relaySession{}
+		r.sessions[session] = s
+	}
+	switch {
+	case i < 0:
+		s.members = append(s.members, relayMember{key: key, addr: from})
+		r.perSource[from.IP.String()]++
+		r.bindings++
+	case !sameAddr(s.members[i].addr, from):
+		// The member's NAT mapping changed
+		r.releaseLocked(s.members[i].addr)
+		s.members[i].addr = from
+		r.perSource[from.IP.String()]++
+	}
+	s.lastSeen = now
+	ack := make([]byte, relayHeaderLen+1)
+	ack[0] = relayAck
+	copy(ack[1:], session[:])
+	ack[relayHeaderLen] = byte(len(s.members))
+	return []relayOut{{to: from, data: ack}}
+}
+
+// cookie returns the return-path cookie for session at addr in epoch.
+func (r *Relay) cookie(session [16]byte, addr *net.UDPAddr, epoch int64) [16]byte {
+	mac := hmac.New(sha256.New, r.secret[:])
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], uint64(epoch))
+	mac.Write(buf[:])
+	mac.Write(session[:])
+	mac.Write(addr.IP.To16())
+	binary.BigEndian.PutUint16(buf[:2], uint16(addr.Port))
+	mac.Write(buf[:2])
+	return [16]byte(mac.Sum(nil)[:16])
+}
+
+// validCookie reports whether cookie was issued to addr for session in the
+// current or the previous epoch.
+func (r *Relay) validCookie(cookie []byte, session [16]byte, addr *net.UDPAddr, now time.Time) bool {
+	epoch := epochOf(now)
+	for _, e := range []int64{epoch, epoch - 1} {
+		if c := r.cookie(session, addr, e); hmac.Equal(cookie, c[:]) {
+			return true
+		}
+	}
+	return false
+}
+
+func epochOf(t time.Time) int64 { return t.UnixNano() / int64(relayCookieEpoch) }
+
+// count increments a relay counter.
+func (r *Relay) count(c *int64) {
+	r.mu.Lock()
+	*c++
+	r.mu.Unlock()
+}
+
+// releaseLocked gives back a source's binding. Must be called with r.mu
+// held.
+func (r *Relay) releaseLocked(addr *net.UDPAddr) {
+	ip := addr.IP.String()
+	if r.perSource[ip]--; r.perSource[ip] <= 0 {
+		delete(r.perSource, ip)
+	}
+}
+
+// index returns the position of the member bound from addr, or -1.
+func (s *relaySession) index(addr *net.UDPAddr) int {
+	for i, m := range s.members {
+		if sameAddr(m.addr, addr) {
+			return i
+		}
+	}
+	return -1
+}
+
+// member returns the position of the member with node ID key, or -1.
+func (s *relaySession) member(key string) int {
+	for i, m := range s.members {
+		if m.key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+func sameAddr(a, b *net.UDPAddr) bool {
+	return a.IP.Equal(b.IP) && a.Port == b.Port
+}
+
+// Expire drops idle sessions and returns how many were removed.
+func (r *Relay) Expire() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := r.cfg.Now()
+	n := 0
+	for id, s := range r.sessions {
+		if now.Sub(s.lastSeen) > r.cfg.SessionTTL {
+			for _, m := range s.members {
+				r.releaseLocked(m.addr)
+			}
+			delete(r.sessions, id)
+			n++
+		}
+	}
+	return n
+}
+
+// RelayStats summarizes a relay.
+type RelayStats struct {
+	Sessions  int   `json:"sessions"`
+	Bindings  int64 `json:"bindings"`
+	Forwarded int64 `json:"forwarded"`
+	Dropped   int64 `json:"dropped"`  // data without a session, or binds over a limit
+	Rejected  int64 `json:"rejected"` // malformed or badly signed binds
+}
+
+// Stats returns a snapshot of the relay.
+func (r *Relay) Stats() RelayStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return RelayStats{
+		Sessions:  len(r.sessions),
+		Bindings:  r.bindings,
+		Forwarded: r.forwarded,
+		Dropped:   r.dropped,
+		Rejected:  r.rejected,
+	}
+}
@@ -0,0 +1,222 @@
+package nat
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+// ─── STUN Binding (RFC 5389) ────────────────────────────────────────────────
+// Only the Binding method is implemented — enough to learn the address a
+// NAT maps a socket to. The NAT type follows from comparing mappings:
+//
+//  1. Mapped address equals the local address → no NAT
+//  2. Two servers see the same mapping → cone NAT (endpoint-independent)
+//  3. Two servers see different mappings → symmetric NAT
+//
+// Telling full from restricted cone needs a server with a second IP; with
+// only the Binding method a cone NAT is reported as restricted cone, the
+// conservative choice for hole punching.
+
+const (
+	stunMagicCookie     = 0x2112A442
+	stunHeaderLen       = 20
+	stunBindingRequest  = 0x0001
+	stunBindingResponse = 0x0101
+	stunAttrMapped      = 0x0001
+	stunAttrXORMapped   = 0x0020
+)
+
+// errNotSTUN is returned for datagrams that are not STUN binding messages.
+var errNotSTUN = errors.New("nat: not a STUN binding message")
+
+// stunRequest builds a binding request with a random transaction ID.
+func stunRequest() (req []byte, txID [12]byte) {
+	_, _ = rand.Read(txID[:])
+	req = make([]byte, stunHeaderLen)
+	binary.BigEndian.PutUint16(req[0:], stunBindingRequest)
+	binary.BigEndian.PutUint32(req[4:], stunMagicCookie)
+	copy(req[8:], txID[:])
+	return req, txID
+}
+
+// parseSTUNResponse extracts the mapped address from a binding response
+// for txID, preferring XOR-MAPPED-ADDRESS.
+func parseSTUNResponse(msg []byte, txID [12]byte) (*net.UDPAddr, error) {
+	if len(msg) < stunHeaderLen ||
+		binary.BigEndian.Uint16(msg[0:]) != stunBindingResponse ||
+		binary.BigEndian.Uint32(msg[4:]) != stunMagicCookie {
+		return nil, errNotSTUN
+	}
+	if [12]byte(msg[8:20]) != txID {
+		return nil, fmt.Errorf("nat: STUN transaction mismatch")
+	}
+	length := int(binary.BigEndian.Uint16(msg[2:]))
+	if stunHeaderLen+length > len(msg) {
+		return nil, fmt.Errorf("nat: truncated STUN response")
+	}
+
+	var mapped *net.UDPAddr
+	attrs := msg[stunHeaderLen : stunHeaderLen+length]
+	for len(attrs) >= 4 {
+		typ := binary.BigEndian.Uint16(attrs[0:])
+		n := int(binary.BigEndian.Uint16(attrs[2:]))
+		if 4+n > len(attrs) {
+			break
+		}
+		val := attrs[4 : 4+n]
+		switch typ {
+		case stunAttrXORMapped:
+			if addr := decodeSTUNAddr(val, true); addr != nil {
+				return addr, nil
+			}
+		case stunAttrMapped:
+			mapped = decodeSTUNAddr(val, false)
+		}
+		attrs = attrs[4+(n+3)&^3:] // attributes are padded to 4 bytes
+	}
+	if mapped == nil {
+		return nil, fmt.Errorf("nat: STUN response has no mapped address")
+	}
+	return mapped, nil
+}
+
+// decodeSTUNAddr decodes an IPv4 (XOR-)MAPPED-ADDRESS value.
+func decodeSTUNAddr(val []byte, xor bool) *net.UDPAddr {
+	if len(val) < 8 || val[1] != 0x01 { // IPv4 only
+		return nil
+	}
+	port := binary.BigEndian.Uint16(val[2:])
+	ip := net.IPv4(val[4], val[5], val[6], val[7]).To4()
+	if xor {
+		port ^= stunMagicCookie >> 16
+		var cookie [4]byte
+		binary.BigEndian.PutUint32(cookie[:], stunMagicCookie)
+		for i := range ip {
+			ip[i] ^= cookie[i]
+		}
+	}
+	return &net.UDPAddr{IP: ip, Port: int(port)}
+}
+
+// BindingResponse answers a STUN binding request from addr with its
+// XOR-MAPPED-ADDRESS, so any node — typically a relay volunteer — can act as
+// a STUN server. Returns an error for anything but an IPv4 binding request.
+func BindingResponse(req []byte, addr *net.UDPAddr) ([]byte, error) {
+	if len(req) < stunHeaderLen ||
+		binary.BigEndian.Uint16(req[0:]) != stunBindingRequest ||
+		binary.BigEndian.Uint32(req[4:]) != stunMagicCookie {
+		return nil, errNotSTUN
+	}
+	ip := addr.IP.To4()
+	if ip == nil {
+		return nil, fmt.Errorf("nat: STUN over IPv6 is not supported")
+	}
+
+	resp := make([]byte, stunHeaderLen+12)
+	binary.BigEndian.PutUint16(resp[0:], stunBindingResponse)
+	binary.BigEndian.PutUint16(resp[2:], 12)
+	copy(resp[4:20], req[4:20]) // magic cookie + transaction ID
+	binary.BigEndian.PutUint16(resp[20:], stunAttrXORMapped)
+	binary.BigEndian.PutUint16(resp[22:], 8)
+	resp[25] = 0x01 // IPv4
+	binary.BigEndian.PutUint16(resp[26:], uint16(addr.Port)^(stunMagicCookie>>16))
+	binary.BigEndian.PutUint32(resp[28:], binary.BigEndian.Uint32(ip)^stunMagicCookie)
+	return resp, nil
+}
+
+// Binding sends a binding request to server over conn and returns the
+// mapped address. conn must not be read concurrently.
+func Binding(ctx context.Context, conn net.PacketConn, server string, timeout time.Duration) (*net.UDPAddr, error) {
+	raddr, err := net.ResolveUDPAddr("udp4", server)
+	if err != nil {
+		return nil, fmt.Errorf("nat: resolve STUN server %s: %w", server, err)
+	}
+	deadline := time.Now().Add(timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	if err := conn.SetReadDeadline(deadline); err != nil {
+		return nil, fmt.Errorf("nat: set deadline: %w", err)
+	}
+	defer conn.SetReadDeadline(time.Time{})
+
+	req, txID := stunRequest()
+	if _, err := conn.WriteTo(req, raddr); err != nil {
+		return nil, fmt.Errorf("nat: STUN request to %s: %w", server, err)
+	}
+	buf := make([]byte, 1024)
+	for {
+		n, from, err := conn.ReadFrom(buf)
+		if err != nil {
+			return nil, fmt.Errorf("nat: STUN response from %s: %w", server, err)
+		}
+		if from.String() != raddr.String() {
+			continue // stray datagram
+		}
+		addr, err := parseSTUNResponse(buf[:n], txID)
+		if errors.Is(err, errNotSTUN) {
+			continue
+		}
+		return addr, err
+	}
+}
+
+// Discover learns conn's public address from the given STUN servers and
+// classifies the NAT. At least one server must answer; a single answer can
+// only tell "no NAT" from "some cone NAT".
+func Discover(ctx context.Context, conn net.PacketConn, servers []string, timeout time.Duration) (*STUNResult, error) {
+	start := time.Now()
+	local := conn.LocalAddr().(*net.UDPAddr)
+
+	var mapped []*net.UDPAddr
+	var lastErr error
+	for _, server := range servers {
+		addr, err := Binding(ctx, conn, server, timeout)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		mapped = append(mapped, addr)
+	}
+	if len(mapped) == 0 {
+		if lastErr == nil {
+			lastErr = fmt.Errorf("nat: no STUN servers configured")
+		}
+		return nil, lastErr
+	}
+
+	res := &STUNResult{
+		PublicAddr: mapped[0].String(),
+		NATType:    NATRestrictedCone,
+		LatencyMs:  int(time.Since(start).Milliseconds()),
+	}
+	for _, m := range mapped[1:] {
+		if m.String() != mapped[0].String() {
+			res.NATType = NATSymmetric
+		}
+	}
+	if res.NATType != NATSymmetric && mapped[0].Port == local.Port &&
+		(mapped[0].IP.Equal(local.IP) || isLocalIP(mapped[0].IP)) {
+		res.NATType = NATNone
+	}
+	return res, nil
+}
+
+// isLocalIP reports whether ip belongs to one of this host's interfaces.
+func isLocalIP(ip net.IP) bool {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return false
+	}
+	for _, a := range addrs {
+		if n, ok := a.(*net.IPNet); ok && n.IP.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
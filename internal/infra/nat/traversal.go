@@ -6,7 +6,9 @@
 //  2. TURN relay (Phase 2): relay server adds ~20ms, 95% success rate
 //  3. Direct P2P (Phase 3): UDP hole punching, <5ms, ~70% success rate
 //
-// This package provides the abstractions for levels 2 and 3.
+// This package provides the abstractions for levels 2 and 3. Traverser (see
+// traverser.go) implements them for real: STUN discovery, hole punching
+// signalled over gossip, and volunteer relays in place of a TURN server.
 package nat
 
 import (
@@ -259,6 +261,7 @@ const (
 	StrategyCloudMediated ConnStrategy = iota // Phase 1: all through Cloud Core
 	StrategyTURNRelay                         // Phase 2: TURN relay (~20ms overhead)
 	StrategyDirectP2P                         // Phase 3: UDP hole punch (<5ms)
+	StrategyPeerRelay                         // Relay through a volunteer node
 )
 
 // String returns a human-readable strategy name.
@@ -270,6 +273,8 @@ func (s ConnStrategy) String() string {
 		return "turn-relay"
 	case StrategyDirectP2P:
 		return "direct-p2p"
+	case StrategyPeerRelay:
+		return "peer-relay"
 	default:
 		return "unknown"
 	}
@@ -279,6 +284,7 @@ func (s ConnStrategy) String() string {
 type ConnResult struct {
 	Strategy  ConnStrategy `json:"strategy"`
 	PeerID    string       `json:"peer_id"`
+	Addr      string       `json:"addr,omitempty"` // peer (direct) or relay address
 	LocalNAT  NATType      `json:"local_nat"`
 	RemoteNAT NATType      `json:"remote_nat"`
 	LatencyMs int          `json:"latency_ms"`
@@ -72,6 +72,7 @@ func TestConnStrategy_String(t *testing.T) {
 		{StrategyCloudMediated, "cloud-mediated"},
 		{StrategyTURNRelay, "turn-relay"},
 		{StrategyDirectP2P, "direct-p2p"},
+		{StrategyPeerRelay, "peer-relay"},
 	}
 	for _, tt := range tests {
 		if got := tt.s.String(); got != tt.want {
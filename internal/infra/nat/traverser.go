@@ -0,0 +1,582 @@
+package nat
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/infra/observability"
+)
+
+// ─── Traverser ──────────────────────────────────────────────────────────────
+// The traverser owns one UDP socket and connects it to peers with the best
+// strategy that works, falling back in order:
+//
+//  1. Direct P2P — both sides learn their public address via STUN, exchange
+//     it through the Signal hook (gossip), then send punch datagrams at each
+//     other until one gets through
+//  2. Peer relay — both sides bind the same session at a volunteer relay
+//  3. Cloud-mediated — always available; traffic stays on the Cloud Core path
+//
+// Every attempt is counted in NATConnections and successful ones in
+// NATLatency, labelled by strategy.
+//
+// Punch wire format: 'P' nonce[8] (punch), 'K' nonce[8] (punch ack).
+
+const (
+	punchPing = 'P'
+	punchAck  = 'K'
+)
+
+// punchInterval is the resend cadence of punch and relay bind datagrams.
+const punchInterval = 50 * time.Millisecond
+
+// Endpoint is a node's address as seen from the internet.
+type Endpoint struct {
+	NodeID string  `json:"node_id"`
+	Addr   string  `json:"addr"`
+	NAT    NATType `json:"nat"`
+}
+
+// PunchRequest is the rendezvous signal exchanged through the Signal hook.
+// A request without Relay asks the peer to punch towards Addr; the peer
+// replies (Reply=true) with its own address, or none if punching between
+// the two NAT types cannot work. A request with Relay asks the peer to bind
+// the session at that relay.
+type PunchRequest struct {
+	From  string  `json:"from"`
+	Addr  string  `json:"addr,omitempty"`
+	NAT   NATType `json:"nat"`
+	Nonce uint64  `json:"nonce"`
+	Relay string  `json:"relay,omitempty"`
+	Reply bool    `json:"reply,omitempty"`
+}
+
+// Hooks connect the traverser to the rest of the node.
+type Hooks struct {
+	// Signal delivers a punch request to a peer (e.g. over gossip).
+	Signal func(ctx context.Context, peerID string, req PunchRequest) error
+	// Relays lists the addresses of volunteer relays, best first.
+	Relays func() []string
+	// Sign signs relay binds with the node key the node ID encodes. Without
+	// it, relays refuse this node.
+	Sign func(msg []byte) []byte
+}
+
+// TraverserConfig configures a Traverser.
+type TraverserConfig struct {
+	STUNServers  []string         // queried in order by Discover
+	STUNTimeout  time.Duration    // per server (default: 3s)
+	PunchTimeout time.Duration    // rendezvous reply and punching (default: 3s)
+	RelayTimeout time.Duration    // waiting for the peer at a relay (default: 2s)
+	Now          func() time.Time // injectable clock (default: time.Now)
+}
+
+// DefaultTraverserConfig returns defaults using the public STUN server.
+func DefaultTraverserConfig() TraverserConfig {
+	return TraverserConfig{
+		STUNServers:  []string{DefaultSTUNConfig().ServerAddr},
+		STUNTimeout:  3 * time.Second,
+		PunchTimeout: 3 * time.Second,
+		RelayTimeout: 2 * time.Second,
+	}
+}
+
+// strategyStats accumulates outcomes for one strategy.
+type strategyStats struct {
+	attempts  int64
+	successes int64
+	latencyMs int64 // sum over successes
+}
+
+// Traverser establishes connections to peers over a single UDP socket. It is
+// safe for concurrent use.
+type Traverser struct {
+	mu      sync.Mutex
+	cfg     TraverserConfig
+	selfID  string
+	conn    net.PacketConn
+	hooks   Hooks
+	local   Endpoint
+	punches map[uint64]chan *net.UDPAddr // nonce → punch waiter
+	replies map[uint64]chan PunchRequest // nonce → rendezvous waiter
+	binds   map[[16]byte]*relayWait      // relay session → bind waiter
+	peers   map[string]ConnResult        // last result per peer
+	stats   map[ConnStrategy]*strategyStats
+}
+
+// NewTraverser creates a traverser for selfID on conn. Until Discover runs,
+// the local endpoint is conn's own address with an unknown NAT type.
+func NewTraverser(selfID string, conn net.PacketConn, cfg TraverserConfig, hooks Hooks) *Traverser {
+	def := DefaultTraverserConfig()
+	if cfg.STUNTimeout <= 0 {
+		cfg.STUNTimeout = def.STUNTimeout
+	}
+	if cfg.PunchTimeout <= 0 {
+		cfg.PunchTimeout = def.PunchTimeout
+	}
+	if cfg.RelayTimeout <= 0 {
+		cfg.RelayTimeout = def.RelayTimeout
+	}
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+	return &Traverser{
+		cfg:     cfg,
+		selfID:  selfID,
+		conn:    conn,
+		hooks:   hooks,
+		local:   Endpoint{NodeID: selfID, Addr: conn.LocalAddr().String()},
+		punches: make(map[uint64]chan *net.UDPAddr),
+		replies: make(map[uint64]chan PunchRequest),
+		binds:   make(map[[16]byte]*relayWait),
+		peers:   make(map[string]ConnResult),
+		stats:   make(map[ConnStrategy]*strategyStats),
+	}
+}
+
+// Discover learns the public endpoint from the STUN servers. It reads the
+// socket directly, so it must be called before Run.
+func (t *Traverser) Discover(ctx context.Context) error {
+	res, err := Discover(ctx, t.conn, t.cfg.STUNServers, t.cfg.STUNTimeout)
+	if err != nil {
+		return err
+	}
+	t.mu.Lock()
+	t.local.Addr = res.PublicAddr
+	t.local.NAT = res.NATType
+	t.mu.Unlock()
+	return nil
+}
+
+// Local returns this node's endpoint.
+func (t *Traverser) Local() Endpoint {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.local
+}
+
+// Run reads punch and relay replies from the socket until ctx is done.
+func (t *Traverser) Run(ctx context.Context) error {
+	go func() {
+		<-ctx.Done()
+		t.conn.SetReadDeadline(time.Now())
+	}()
+
+	buf := make([]byte, 2048)
+	for {
+		n, from, err := t.conn.ReadFrom(buf)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			var ne net.Error
+			if errors.As(err, &ne) && ne.Timeout() {
+				continue
+			}
+			return fmt.Errorf("nat: read: %w", err)
+		}
+		if addr, ok := from.(*net.UDPAddr); ok {
+			t.handleDatagram(buf[:n], addr)
+		}
+	}
+}
+
+// handleDatagram completes the waiter a punch or relay ack belongs to.
+func (t *Traverser) handleDatagram(pkt []byte, from *net.UDPAddr) {
+	switch {
+	case len(pkt) == 9 && (pkt[0] == punchPing || pkt[0] == punchAck):
+		nonce := binary.BigEndian.Uint64(pkt[1:])
+		if pkt[0] == punchPing {
+			t.conn.WriteTo(punchPacket(punchAck, nonce), from)
+		}
+		t.mu.Lock()
+		ch := t.punches[nonce]
+		t.mu.Unlock()
+		if ch != nil {
+			select {
+			case ch <- from:
+			default:
+			}
+		}
+
+	case len(pkt) == relayHelloLen && pkt[0] == relayChallenge:
+		t.mu.Lock()
+		w := t.binds[[16]byte(pkt[1:relayHeaderLen])]
+		t.mu.Unlock()
+		if w != nil {
+			select {
+			case w.cookie <- [16]byte(pkt[relayHeaderLen:]):
+			default:
+			}
+		}
+
+	case len(pkt) == relayHeaderLen+1 && pkt[0] == relayAck:
+		if pkt[relayHeaderLen] < 2 {
+			return // the peer has not bound yet
+		}
+		t.mu.Lock()
+		w := t.binds[[16]byte(pkt[1:relayHeaderLen])]
+		t.mu.Unlock()
+		if w != nil {
+			select {
+			case w.bound <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
+// Connect reaches peerID with the first strategy that works. The result is
+// always successful: cloud mediation is the last resort.
+func (t *Traverser) Connect(ctx context.Context, peerID string) ConnResult {
+	local := t.Local()
+	res := ConnResult{PeerID: peerID, LocalNAT: local.NAT, RemoteNAT: NATUnknown}
+	var errs []error
+
+	// Strategy 1: direct P2P
+	start := t.cfg.Now()
+	reply, err := t.rendezvous(ctx, peerID, PunchRequest{From: t.selfID, Addr: local.Addr, NAT: local.NAT, Nonce: newNonce()})
+	if err == nil {
+		res.RemoteNAT = reply.NAT
+		if reply.Addr != "" && CanPunchThrough(local.NAT, reply.NAT) {
+			addr, err := t.punch(ctx, reply.Addr, reply.Nonce)
+			if err == nil {
+				return t.finish(res, StrategyDirectP2P, addr.String(), start, nil)
+			}
+			t.record(StrategyDirectP2P, false, 0)
+			errs = append(errs, err)
+		} else {
+			errs = append(errs, fmt.Errorf("nat: cannot punch %s ↔ %s", local.NAT, reply.NAT))
+		}
+	} else {
+		errs = append(errs, err)
+	}
+
+	// Strategy 2: volunteer relay
+	if t.hooks.Relays != nil {
+		for _, relay := range t.hooks.Relays() {
+			start := t.cfg.Now()
+			err := t.signal(ctx, peerID, PunchRequest{From: t.selfID, NAT: local.NAT, Relay: relay})
+			if err == nil {
+				err = t.joinRelay(ctx, relay, peerID)
+			}
+			if err == nil {
+				return t.finish(res, StrategyPeerRelay, relay, start, nil)
+			}
+			t.record(StrategyPeerRelay, false, 0)
+			errs = append(errs, err)
+		}
+	}
+
+	// Strategy 3: cloud-mediated (always works)
+	return t.finish(res, StrategyCloudMediated, "", t.cfg.Now(), errors.Join(errs...))
+}
+
+// finish records a successful connection and returns its result.
+func (t *Traverser) finish(res ConnResult, s ConnStrategy, addr string, start time.Time, err error) ConnResult {
+	res.Strategy = s
+	res.Addr = addr
+	res.Success = true
+	res.LatencyMs = int(t.cfg.Now().Sub(start).Milliseconds())
+	if err != nil {
+		res.Error = err.Error()
+	}
+	t.record(s, true, res.LatencyMs)
+	t.mu.Lock()
+	t.peers[res.PeerID] = res
+	t.mu.Unlock()
+	return res
+}
+
+// Path returns the last connection result for peerID, if any.
+func (t *Traverser) Path(peerID string) (ConnResult, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	res, ok := t.peers[peerID]
+	return res, ok
+}
+
+// Forget drops the connection result for peerID (e.g. when it leaves).
+func (t *Traverser) Forget(peerID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.peers, peerID)
+}
+
+// HandlePunch answers a punch request received from a peer. Punching and
+// relay binds run in the background until ctx is done or they time out.
+func (t *Traverser) HandlePunch(ctx context.Context, req PunchRequest) {
+	if req.Reply {
+		t.mu.Lock()
+		ch := t.replies[req.Nonce]
+		t.mu.Unlock()
+		if ch != nil {
+			select {
+			case ch <- req:
+			default:
+			}
+		}
+		return
+	}
+
+	if req.Relay != "" {
+		go t.joinRelay(ctx, req.Relay, req.From)
+		return
+	}
+
+	local := t.Local()
+	reply := PunchRequest{From: t.selfID, NAT: local.NAT, Nonce: req.Nonce, Reply: true}
+	canPunch := req.Addr != "" && CanPunchThrough(local.NAT, req.NAT)
+	if canPunch {
+		reply.Addr = local.Addr
+	}
+	if err := t.signal(ctx, req.From, reply); err != nil || !canPunch {
+		return
+	}
+	go t.punch(ctx, req.Addr, req.Nonce)
+}
+
+// rendezvous sends req to the peer and waits for its reply.
+func (t *Traverser) rendezvous(ctx context.Context, peerID string, req PunchRequest) (PunchRequest, error) {
+	ch := make(chan PunchRequest, 1)
+	t.mu.Lock()
+	t.replies[req.Nonce] = ch
+	t.mu.Unlock()
+	defer func() {
+		t.mu.Lock()
+		delete(t.replies, req.Nonce)
+		t.mu.Unlock()
+	}()
+
+	if err := t.signal(ctx, peerID, req); err != nil {
+		return PunchRequest{}, err
+	}
+	timer := time.NewTimer(t.cfg.PunchTimeout)
+	defer timer.Stop()
+	select {
+	case reply := <-ch:
+		return reply, nil
+	case <-timer.C:
+		return PunchRequest{}, fmt.Errorf("nat: no rendezvous reply from %s", peerID)
+	case <-ctx.Done():
+		return PunchRequest{}, ctx.Err()
+	}
+}
+
+// signal delivers req through the Signal hook.
+func (t *Traverser) signal(ctx context.Context, peerID string, req PunchRequest) error {
+	if t.hooks.Signal == nil {
+		return fmt.Errorf("nat: no signalling channel")
+	}
+	if err := t.hooks.Signal(ctx, peerID, req); err != nil {
+		return fmt.Errorf("nat: signal %s: %w", peerID, err)
+	}
+	return nil
+}
+
+// punch sends punch datagrams to addr until one from the peer arrives.
+func (t *Traverser) punch(ctx context.Context, addr string, nonce uint64) (*net.UDPAddr, error) {
+	raddr, err := net.ResolveUDPAddr("udp4", addr)
+	if err != nil {
+		return nil, fmt.Errorf("nat: resolve %s: %w", addr, err)
+	}
+	ch := make(chan *net.UDPAddr, 1)
+	t.mu.Lock()
+	t.punches[nonce] = ch
+	t.mu.Unlock()
+	defer func() {
+		t.mu.Lock()
+		delete(t.punches, nonce)
+		t.mu.Unlock()
+	}()
+
+	pkt := punchPacket(punchPing, nonce)
+	if err := t.resend(ctx, pkt, raddr, t.cfg.PunchTimeout, func() bool {
+		select {
+		case raddr = <-ch:
+			return true
+		default:
+			return false
+		}
+	}); err != nil {
+		return nil, fmt.Errorf("nat: punch %s: %w", addr, err)
+	}
+	return raddr, nil
+}
+
+// relayWait collects a relay's answers to this node's binds.
+type relayWait struct {
+	cookie chan [16]byte
+	bound  chan struct{}
+}
+
+// joinRelay binds the session with peerID at relay and waits until the
+// peer has bound too.
+func (t *Traverser) joinRelay(ctx context.Context, relay, peerID string) error {
+	pub := nodeKey(t.selfID)
+	if pub == nil || t.hooks.Sign == nil {
+		return fmt.Errorf("nat: relay %s: binds must be signed with the node key", relay)
+	}
+	raddr, err := net.ResolveUDPAddr("udp4", relay)
+	if err != nil {
+		return fmt.Errorf("nat: resolve relay %s: %w", relay, err)
+	}
+	session := SessionID(t.selfID, peerID)
+	w := &relayWait{cookie: make(chan [16]byte, 1), bound: make(chan struct{}, 1)}
+	t.mu.Lock()
+	t.binds[session] = w
+	t.mu.Unlock()
+	defer func() {
+		t.mu.Lock()
+		delete(t.binds, session)
+		t.mu.Unlock()
+	}()
+
+	var cookie [16]byte
+	if err := t.resend(ctx, bindHello(session), raddr, t.cfg.RelayTimeout, func() bool {
+		select {
+		case cookie = <-w.cookie:
+			return true
+		default:
+			return false
+		}
+	}); err != nil {
+		return fmt.Errorf("nat: relay %s: no challenge: %w", relay, err)
+	}
+
+	bind := signedBind(session, cookie, peerID, pub, t.hooks.Sign)
+	if err := t.resend(ctx, bind, raddr, t.cfg.RelayTimeout, func() bool {
+		select {
+		case <-w.bound:
+			return true
+		default:
+			return false
+		}
+	}); err != nil {
+		return fmt.Errorf("nat: relay %s: %w", relay, err)
+	}
+	return nil
+}
+
+// nodeKey returns the public key a node ID encodes, or nil.
+func nodeKey(nodeID string) ed25519.PublicKey {
+	pub, err := hex.DecodeString(nodeID)
+	if err != nil || len(pub) != ed25519.PublicKeySize {
+		return nil
+	}
+	return ed25519.PublicKey(pub)
+}
+
+// resend writes pkt to addr every punchInterval until done reports true or
+// timeout passes.
+func (t *Traverser) resend(ctx context.Context, pkt []byte, addr *net.UDPAddr, timeout time.Duration, done func() bool) error {
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	ticker := time.NewTicker(punchInterval)
+	defer ticker.Stop()
+	for {
+		if _, err := t.conn.WriteTo(pkt, addr); err != nil {
+			return err
+		}
+		select {
+		case <-ticker.C:
+		case <-deadline.C:
+			if done() {
+				return nil
+			}
+			return fmt.Errorf("timed out after %s", timeout)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		if done() {
+			return nil
+		}
+	}
+}
+
+// record counts an attempt in the stats and metrics.
+func (t *Traverser) record(s ConnStrategy, ok bool, latencyMs int) {
+	t.mu.Lock()
+	st := t.stats[s]
+	if st == nil {
+		st = &strategyStats{}
+		t.stats[s] = st
+	}
+	st.attempts++
+	if ok {
+		st.successes++
+		st.latencyMs += int64(latencyMs)
+	}
+	t.mu.Unlock()
+
+	observability.NATConnections.WithLabelValues(s.String(), strconv.FormatBool(ok)).Inc()
+	if ok {
+		observability.NATLatency.WithLabelValues(s.String()).Observe(float64(latencyMs))
+	}
+}
+
+// punchPacket frames a punch or punch ack datagram.
+func punchPacket(op byte, nonce uint64) []byte {
+	pkt := make([]byte, 9)
+	pkt[0] = op
+	binary.BigEndian.PutUint64(pkt[1:], nonce)
+	return pkt
+}
+
+// newNonce returns a random punch nonce.
+func newNonce() uint64 {
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+	return binary.BigEndian.Uint64(b[:])
+}
+
+// ─── Stats ──────────────────────────────────────────────────────────────────
+
+// StrategyStats summarizes the attempts made with one strategy.
+type StrategyStats struct {
+	Strategy     string  `json:"strategy"`
+	Attempts     int64   `json:"attempts"`
+	Successes    int64   `json:"successes"`
+	SuccessRate  float64 `json:"success_rate"`
+	AvgLatencyMs float64 `json:"avg_latency_ms"`
+}
+
+// TraverserStats summarizes a traverser.
+type TraverserStats struct {
+	Local      Endpoint        `json:"local"`
+	Peers      []ConnResult    `json:"peers"` // last result per peer
+	Strategies []StrategyStats `json:"strategies"`
+}
+
+// Stats returns a snapshot of the traverser.
+func (t *Traverser) Stats() TraverserStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	out := TraverserStats{Local: t.local}
+	for _, r := range t.peers {
+		out.Peers = append(out.Peers, r)
+	}
+	sort.Slice(out.Peers, func(i, j int) bool { return out.Peers[i].PeerID < out.Peers[j].PeerID })
+	for s, st := range t.stats {
+		ss := StrategyStats{Strategy: s.String(), Attempts: st.attempts, Successes: st.successes}
+		if st.attempts > 0 {
+			ss.SuccessRate = float64(st.successes) / float64(st.attempts)
+		}
+		if st.successes > 0 {
+			ss.AvgLatencyMs = float64(st.latencyMs) / float64(st.successes)
+		}
+		out.Strategies = append(out.Strategies, ss)
+	}
+	sort.Slice(out.Strategies, func(i, j int) bool { return out.Strategies[i].Strategy < out.Strategies[j].Strategy })
+	return out
+}
@@ -0,0 +1,325 @@
+package nat
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ═══════════════════════════════════════════════════════════════════════════
+// STUN, Relay and Traverser Tests — loopback sockets only
+// ═══════════════════════════════════════════════════════════════════════════
+
+func listenLoopback(t *testing.T) net.PacketConn {
+	t.Helper()
+	conn, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// startRelay runs a relay on a loopback socket and returns its address.
+func startRelay(t *testing.T, ctx context.Context) (*Relay, string) {
+	t.Helper()
+	conn := listenLoopback(t)
+	r := NewRelay(RelayConfig{})
+	go r.Serve(ctx, conn)
+	return r, conn.LocalAddr().String()
+}
+
+// ─── STUN ───────────────────────────────────────────────────────────────────
+
+func TestSTUN_RoundTrip(t *testing.T) {
+	req, txID := stunRequest()
+	from := &net.UDPAddr{IP: net.IPv4(203, 0, 113, 7), Port: 40123}
+	resp, err := BindingResponse(req, from)
+	if err != nil {
+		t.Fatalf("BindingResponse: %v", err)
+	}
+	got, err := parseSTUNResponse(resp, txID)
+	if err != nil {
+		t.Fatalf("parseSTUNResponse: %v", err)
+	}
+	if got.String() != from.String() {
+		t.Errorf("mapped = %s, want %s", got, from)
+	}
+
+	var other [12]byte
+	if _, err := parseSTUNResponse(resp, other); err == nil {
+		t.Error("response accepted for a different transaction")
+	}
+	if _, err := BindingResponse([]byte("hello"), from); err == nil {
+		t.Error("BindingResponse accepted a non-STUN datagram")
+	}
+}
+
+func TestDiscover_NoNAT(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_, server := startRelay(t, ctx)
+
+	conn := listenLoopback(t)
+	res, err := Discover(ctx, conn, []string{server}, time.Second)
+	if err != nil {
+		t.Fatalf("Discover: %v", err)
+	}
+	if res.PublicAddr != conn.LocalAddr().String() {
+		t.Errorf("PublicAddr = %s, want %s", res.PublicAddr, conn.LocalAddr())
+	}
+	if res.NATType != NATNone {
+		t.Errorf("NATType = %s, want none", res.NATType)
+	}
+}
+
+func TestDiscover_NoServers(t *testing.T) {
+	if _, err := Discover(context.Background(), listenLoopback(t), nil, time.Second); err == nil {
+		t.Error("Discover with no servers should fail")
+	}
+}
+
+// ─── Relay ──────────────────────────────────────────────────────────────────
+
+func TestSessionID_Symmetric(t *testing.T) {
+	if SessionID("a", "b") != SessionID("b", "a") {
+		t.Error("SessionID is not symmetric")
+	}
+	if SessionID("a", "b") == SessionID("a", "c") {
+		t.Error("different pairs share a session")
+	}
+}
+
+// testNode is a node identity for relay tests.
+type testNode struct {
+	id   string
+	pub  ed25519.PublicKey
+	priv ed25519.PrivateKey
+}
+
+func newTestNode(t *testing.T) testNode {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return testNode{id: hex.EncodeToString(pub), pub: pub, priv: priv}
+}
+
+func (n testNode) sign(msg []byte) []byte { return ed25519.Sign(n.priv, msg) }
+
+// bindAt runs the hello/challenge exchange and returns the signed bind's
+// reply.
+func bindAt(t *testing.T, r *Relay, n testNode, peer string, from *net.UDPAddr) []relayOut {
+	t.Helper()
+	session := SessionID(n.id, peer)
+	out := r.handle(bindHello(session), from)
+	if len(out) != 1 || len(out[0].data) != relayHelloLen || out[0].data[0] != relayChallenge {
+		t.Fatalf("hello answered with %v, want a challenge", out)
+	}
+	return r.handle(signedBind(session, [16]byte(out[0].data[relayHeaderLen:]), peer, n.pub, n.sign), from)
+}
+
+func TestRelay_ForwardsBetweenMembers(t *testing.T) {
+	r := NewRelay(RelayConfig{})
+	na, nb, nc := newTestNode(t), newTestNode(t), newTestNode(t)
+	a := &net.UDPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 1}
+	b := &net.UDPAddr{IP: net.IPv4(10, 0, 0, 2), Port: 2}
+	c := &net.UDPAddr{IP: net.IPv4(10, 0, 0, 3), Port: 3}
+	session := SessionID(na.id, nb.id)
+
+	if out := r.handle(RelayPacket(session, []byte("early")), a); out != nil {
+		t.Error("data forwarded before the session had two members")
+	}
+	if out := bindAt(t, r, na, nb.id, a); len(out) != 1 || out[0].data[relayHeaderLen] != 1 {
+		t.Fatalf("first bind ack = %v, want 1 member", out)
+	}
+	if out := bindAt(t, r, nb, na.id, b); len(out) != 1 || out[0].data[relayHeaderLen] != 2 {
+		t.Fatalf("second bind ack = %v, want 2 members", out)
+	}
+
+	out := r.handle(RelayPacket(session, []byte("hi")), a)
+	if len(out) != 1 || out[0].to.String() != b.String() || string(out[0].data[relayHeaderLen:]) != "hi" {
+		t.Fatalf("forward = %v, want hi to %s", out, b)
+	}
+	if out := r.handle(RelayPacket(session, []byte("hi")), c); out != nil {
+		t.Error("data from a non-member forwarded")
+	}
+	if st := r.Stats(); st.Sessions != 1 || st.Bindings != 2 || st.Forwarded != 1 || st.Dropped != 2 {
+		t.Errorf("Stats = %+v", st)
+	}
+
+	// A third node cannot sign its way into the session
+	hello := r.handle(bindHello(session), c)
+	cookie := [16]byte(hello[0].data[relayHeaderLen:])
+	if out := r.handle(signedBind(session, cookie, nb.id, nc.pub, nc.sign), c); out != nil {
+		t.Error("a node outside the session bound it")
+	}
+	if st := r.Stats(); st.Rejected != 1 || st.Bindings != 2 {
+		t.Errorf("Stats = %+v, want the outsider rejected", st)
+	}
+}
+
+func TestRelay_RequiresReturnPath(t *testing.T) {
+	now := time.Now()
+	r := NewRelay(RelayConfig{Now: func() time.Time { return now }})
+	na, nb := newTestNode(t), newTestNode(t)
+	a := &net.UDPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 1}
+	spoofed := &net.UDPAddr{IP: net.IPv4(192, 0, 2, 9), Port: 9}
+	session := SessionID(na.id, nb.id)
+
+	hello := r.handle(bindHello(session), a)
+	cookie := [16]byte(hello[0].data[relayHeaderLen:])
+	bind := signedBind(session, cookie, nb.id, na.pub, na.sign)
+
+	// The cookie only works from the address it was sent to, and a bad
+	// or stale one is answered with a fresh challenge, never an ack
+	for _, tt := range []struct {
+		name string
+		pkt  []byte
+		from *net.UDPAddr
+	}{
+		{"unsigned header-only bind", bindHello(session)[:relayHeaderLen], a},
+		{"cookie from another address", bind, spoofed},
+		{"zero cookie", signedBind(session, [16]byte{}, nb.id, na.pub, na.sign), a},
+	} {
+		for _, out := range r.handle(tt.pkt, tt.from) {
+			if out.data[0] != relayChallenge {
+				t.Errorf("%s: answered with %q, want a challenge or nothing", tt.name, out.data[0])
+			}
+		}
+	}
+	if st := r.Stats(); st.Sessions != 0 {
+		t.Fatalf("Stats = %+v, want no session", st)
+	}
+
+	now = now.Add(3 * relayCookieEpoch)
+	if out := r.handle(bind, a); len(out) != 1 || out[0].data[0] != relayChallenge {
+		t.Errorf("expired cookie answered with %v, want a challenge", out)
+	}
+}
+
+func TestRelay_SessionLimits(t *testing.T) {
+	r := NewRelay(RelayConfig{MaxSessions: 3, MaxSessionsPerSource: 2})
+	src := &net.UDPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 1}
+	self := newTestNode(t)
+	for i := 0; i < 3; i++ {
+		out := bindAt(t, r, self, newTestNode(t).id, src)
+		if ok := len(out) == 1; ok != (i < 2) {
+			t.Errorf("bind %d from one source: ack %v", i, out)
+		}
+	}
+	other := &net.UDPAddr{IP: net.IPv4(10, 0, 0, 2), Port: 2}
+	if out := bindAt(t, r, self, newTestNode(t).id, other); len(out) != 1 {
+		t.Errorf("bind from another source refused: %v", out)
+	}
+	third := &net.UDPAddr{IP: net.IPv4(10, 0, 0, 3), Port: 3}
+	if out := bindAt(t, r, self, newTestNode(t).id, third); out != nil {
+		t.Errorf("bind over MaxSessions acked: %v", out)
+	}
+	if st := r.Stats(); st.Sessions != 3 || st.Dropped != 2 {
+		t.Errorf("Stats = %+v", st)
+	}
+}
+
+func TestRelay_ExpiresIdleSessions(t *testing.T) {
+	now := time.Now()
+	r := NewRelay(RelayConfig{SessionTTL: time.Minute, MaxSessionsPerSource: 1, Now: func() time.Time { return now }})
+	na := newTestNode(t)
+	src := &net.UDPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 1}
+	bindAt(t, r, na, newTestNode(t).id, src)
+
+	now = now.Add(2 * time.Minute)
+	if n := r.Expire(); n != 1 {
+		t.Errorf("Expire() = %d, want 1", n)
+	}
+	if out := bindAt(t, r, na, newTestNode(t).id, src); len(out) != 1 {
+		t.Errorf("source still at its limit after expiry: %v", out)
+	}
+}
+
+// ─── Traverser ──────────────────────────────────────────────────────────────
+
+// pair wires two traversers whose Signal hooks deliver to each other.
+func pair(t *testing.T, ctx context.Context, relays func() []string) (a, b *Traverser) {
+	t.Helper()
+	cfg := TraverserConfig{PunchTimeout: time.Second, RelayTimeout: time.Second}
+	na, nb := newTestNode(t), newTestNode(t)
+	byID := map[string]**Traverser{na.id: &a, nb.id: &b}
+	signal := func(ctx context.Context, peerID string, req PunchRequest) error {
+		go (*byID[peerID]).HandlePunch(ctx, req)
+		return nil
+	}
+	a = NewTraverser(na.id, listenLoopback(t), cfg, Hooks{Signal: signal, Relays: relays, Sign: na.sign})
+	b = NewTraverser(nb.id, listenLoopback(t), cfg, Hooks{Signal: signal, Relays: relays, Sign: nb.sign})
+	go a.Run(ctx)
+	go b.Run(ctx)
+	return a, b
+}
+
+func TestTraverser_DirectP2P(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	a, b := pair(t, ctx, nil)
+	peer := b.Local().NodeID
+
+	res := a.Connect(ctx, peer)
+	if !res.Success || res.Strategy != StrategyDirectP2P {
+		t.Fatalf("Connect = %+v, want direct-p2p", res)
+	}
+	if res.Addr != b.Local().Addr {
+		t.Errorf("Addr = %s, want %s", res.Addr, b.Local().Addr)
+	}
+	st := a.Stats()
+	if len(st.Strategies) != 1 || st.Strategies[0].Successes != 1 {
+		t.Errorf("Strategies = %+v", st.Strategies)
+	}
+	if len(st.Peers) != 1 || st.Peers[0].PeerID != peer {
+		t.Errorf("Peers = %+v", st.Peers)
+	}
+	if path, ok := a.Path(peer); !ok || path.Strategy != StrategyDirectP2P {
+		t.Errorf("Path(b) = %+v, %v", path, ok)
+	}
+	a.Forget(peer)
+	if _, ok := a.Path(peer); ok {
+		t.Error("Path(b) still set after Forget")
+	}
+}
+
+func TestTraverser_SymmetricFallsBackToRelay(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	relay, relayAddr := startRelay(t, ctx)
+	a, b := pair(t, ctx, func() []string { return []string{relayAddr} })
+	a.local.NAT, b.local.NAT = NATSymmetric, NATSymmetric
+
+	res := a.Connect(ctx, b.Local().NodeID)
+	if !res.Success || res.Strategy != StrategyPeerRelay || res.Addr != relayAddr {
+		t.Fatalf("Connect = %+v, want peer-relay via %s", res, relayAddr)
+	}
+	if res.RemoteNAT != NATSymmetric {
+		t.Errorf("RemoteNAT = %s, want symmetric", res.RemoteNAT)
+	}
+	if st := relay.Stats(); st.Sessions != 1 || st.Bindings != 2 {
+		t.Errorf("relay Stats = %+v, want one session with both peers", st)
+	}
+}
+
+func TestTraverser_CloudFallback(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	tr := NewTraverser("a", listenLoopback(t), TraverserConfig{}, Hooks{})
+	go tr.Run(ctx)
+
+	res := tr.Connect(ctx, "b")
+	if !res.Success || res.Strategy != StrategyCloudMediated {
+		t.Fatalf("Connect = %+v, want cloud-mediated", res)
+	}
+	if !strings.Contains(res.Error, "no signalling channel") {
+		t.Errorf("Error = %q, want the direct failure", res.Error)
+	}
+}
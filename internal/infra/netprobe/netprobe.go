@@ -0,0 +1,683 @@
+// Package netprobe measures round-trip time and bandwidth to peers and keeps
+// a per-peer network profile for topology-aware placement.
+//
+//  1. Passive samples — RTTs of gossip probes, sizes and durations of chunk
+//     transfers — are folded in as they happen and cost nothing
+//  2. Every ProbeInterval, peers are probed actively, most overdue first:
+//     an RTT ping when their availability check (below) is due, then a
+//     bandwidth transfer of ProbeBytes when their bandwidth is missing or
+//     older than StaleAfter
+//  3. Active probing spends at most BudgetBytes per BudgetWindow, so probes
+//     never compete with real traffic; peers over budget wait for the next
+//     window
+//  4. Measurements lose weight with age (HalfLife): estimates drift back to
+//     the network-wide mean and new samples replace stale ones faster.
+//     Profiles not refreshed for MaxAge are dropped
+//
+// Each peer is also checked for availability on its own schedule: new
+// peers, peers that flap between online and offline and peers last found
+// offline every MinCheckInterval; the others less often the higher their
+// reputation, up to MaxCheckInterval. A passive RTT that arrives when a
+// check is due counts as the check; otherwise the peer is pinged. Every
+// check is reported to Hooks.OnAvailability.
+package netprobe
+
+import (
+	"context"
+	"math"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Priors used before anything has been measured.
+const (
+	defaultRTTMs     = 100.0       // milliseconds
+	defaultBandwidth = 1024 * 1024 // bytes/sec, matches the p2p download prior
+	pingCost         = 128         // bytes charged to the budget per RTT probe
+)
+
+// Config tunes the prober.
+type Config struct {
+	ProbeInterval     time.Duration // active probe round cadence (default: 30s)
+	StaleAfter        time.Duration // profiles older than this are re-probed (default: 5m)
+	HalfLife          time.Duration // measurement weight halves every HalfLife (default: 10m)
+	MaxAge            time.Duration // profiles older than this are dropped (default: 1h)
+	ProbeBytes        int           // bandwidth probe size (default: 256 KiB)
+	BudgetBytes       int64         // active probe bytes per BudgetWindow (default: 4 MiB)
+	BudgetWindow      time.Duration // budget period (default: 1m)
+	MaxProbesPerRound int           // peers probed per round (default: 4)
+	Alpha             float64       // EWMA weight of a fresh sample (default: 0.3)
+	MinCheckInterval  time.Duration // availability checks of new, flappy or offline peers (default: 1m)
+	MaxCheckInterval  time.Duration // availability checks of stable, trusted peers (default: 30m)
+	NewPeerAge        time.Duration // peers first seen this recently count as new (default: 1h)
+
+	Now func() time.Time // injectable clock (default: time.Now)
+}
+
+// DefaultConfig returns defaults that re-measure each peer every few minutes
+// for well under 1% of a home uplink.
+func DefaultConfig() Config {
+	return Config{
+		ProbeInterval:     30 * time.Second,
+		StaleAfter:        5 * time.Minute,
+		HalfLife:          10 * time.Minute,
+		MaxAge:            time.Hour,
+		ProbeBytes:        256 << 10,
+		BudgetBytes:       4 << 20,
+		BudgetWindow:      time.Minute,
+		MaxProbesPerRound: 4,
+		Alpha:             0.3,
+		MinCheckInterval:  time.Minute,
+		MaxCheckInterval:  30 * time.Minute,
+		NewPeerAge:        time.Hour,
+	}
+}
+
+// Hooks connect the prober to the network. Ping and Transfer are optional;
+// without them the profile is built from passive samples only.
+type Hooks struct {
+	// Peers lists the peers worth profiling.
+	Peers func() []string
+	// Ping measures the round-trip time to a peer.
+	Ping func(ctx context.Context, peer string) (time.Duration, error)
+	// Transfer moves n bytes from a peer and returns how long it took.
+	Transfer func(ctx context.Context, peer string, n int) (time.Duration, error)
+	// Reputation returns a peer's reputation, 0..1 (nil = 0.5 for all).
+	Reputation func(peer string) float64
+	// OnAvailability receives every availability check.
+	OnAvailability func(peer string, online bool)
+}
+
+// Profile is the network profile of one peer.
+type Profile struct {
+	NodeID           string    `json:"node_id"`
+	RTTMs            float64   `json:"rtt_ms"`
+	BandwidthBps     float64   `json:"bandwidth_bps"` // bytes/sec
+	RTTSamples       int       `json:"rtt_samples"`
+	BandwidthSamples int       `json:"bandwidth_samples"`
+	Confidence       float64   `json:"confidence"` // weight of the newest measurement, 0..1
+	UpdatedAt        time.Time `json:"updated_at"`
+	Stale            bool      `json:"stale"`
+	Online           bool      `json:"online"`     // as of the last availability check
+	Flap             float64   `json:"flap"`       // rate of online/offline changes between checks, 0..1
+	CheckedAt        time.Time `json:"checked_at"` // last availability check (zero = never)
+	NextCheck        time.Time `json:"next_check"`
+}
+
+// estimate is an aged EWMA.
+type estimate struct {
+	value   float64
+	samples int
+	at      time.Time
+}
+
+// availability is a peer's availability check history.
+type availability struct {
+	firstSeen time.Time
+	checkedAt time.Time // zero = never checked
+	checks    int
+	online    bool
+	flap      float64       // EWMA of state changes between checks
+	interval  time.Duration // check interval, as of the last scheduling
+}
+
+// entry is the prober's record of one peer.
+type entry struct {
+	rtt   estimate // milliseconds
+	bw    estimate // bytes/sec
+	avail availability
+}
+
+// updatedAt returns when the peer was last measured.
+func (e *entry) updatedAt() time.Time {
+	if e.bw.at.After(e.rtt.at) {
+		return e.bw.at
+	}
+	return e.rtt.at
+}
+
+// Prober builds per-peer network profiles. It is safe for concurrent use.
+type Prober struct {
+	mu    sync.Mutex
+	cfg   Config
+	hooks Hooks
+	peers map[string]*entry
+
+	windowStart time.Time
+	spent       int64 // budget bytes spent in the current window
+
+	probes        int64
+	probeFailures int64
+	overBudget    int64
+	passive       int64
+	checks        int64
+	offline       int64
+}
+
+// New creates a prober. Non-positive config values fall back to
+// DefaultConfig.
+func New(cfg Config, hooks Hooks) *Prober {
+	def := DefaultConfig()
+	if cfg.ProbeInterval <= 0 {
+		cfg.ProbeInterval = def.ProbeInterval
+	}
+	if cfg.StaleAfter <= 0 {
+		cfg.StaleAfter = def.StaleAfter
+	}
+	if cfg.HalfLife <= 0 {
+		cfg.HalfLife = def.HalfLife
+	}
+	if cfg.MaxAge <= 0 {
+		cfg.MaxAge = def.MaxAge
+	}
+	if cfg.ProbeBytes <= 0 {
+		cfg.ProbeBytes = def.ProbeBytes
+	}
+	if cfg.BudgetBytes <= 0 {
+		cfg.BudgetBytes = def.BudgetBytes
+	}
+	if cfg.BudgetWindow <= 0 {
+		cfg.BudgetWindow = def.BudgetWindow
+	}
+	if cfg.MaxProbesPerRound <= 0 {
+		cfg.MaxProbesPerRound = def.MaxProbesPerRound
+	}
+	if cfg.Alpha <= 0 || cfg.Alpha > 1 {
+		cfg.Alpha = def.Alpha
+	}
+	if cfg.MinCheckInterval <= 0 {
+		cfg.MinCheckInterval = def.MinCheckInterval
+	}
+	if cfg.MaxCheckInterval < cfg.MinCheckInterval {
+		cfg.MaxCheckInterval = max(def.MaxCheckInterval, cfg.MinCheckInterval)
+	}
+	if cfg.NewPeerAge <= 0 {
+		cfg.NewPeerAge = def.NewPeerAge
+	}
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+	return &Prober{cfg: cfg, hooks: hooks, peers: make(map[string]*entry)}
+}
+
+// ─── Samples ────────────────────────────────────────────────────────────────
+
+// ObserveRTT folds a passively measured round-trip time into peer's profile.
+func (p *Prober) ObserveRTT(peer string, rtt time.Duration) {
+	if peer == "" || rtt <= 0 {
+		return
+	}
+	p.mu.Lock()
+	p.passive++
+	e := p.entryLocked(peer)
+	p.foldLocked(&e.rtt, float64(rtt)/float64(time.Millisecond))
+	check := p.checkDueLocked(e, p.cfg.Now())
+	if check {
+		p.checkedLocked(e, true)
+	}
+	p.mu.Unlock()
+	if check {
+		p.reportAvailability(peer, true)
+	}
+}
+
+// ObserveTransfer folds a passively measured transfer of n bytes into peer's
+// bandwidth estimate.
+func (p *Prober) ObserveTransfer(peer string, n int, elapsed time.Duration) {
+	if peer == "" || n <= 0 {
+		return
+	}
+	elapsed = max(elapsed, time.Millisecond)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.passive++
+	p.foldLocked(&p.entryLocked(peer).bw, float64(n)/elapsed.Seconds())
+}
+
+// foldLocked adds a sample to an estimate. The older the estimate, the more
+// the sample replaces it.
+func (p *Prober) foldLocked(e *estimate, sample float64) {
+	now := p.cfg.Now()
+	if e.samples == 0 {
+		e.value = sample
+	} else {
+		alpha := 1 - (1-p.cfg.Alpha)*p.weight(e.at, now)
+		e.value = alpha*sample + (1-alpha)*e.value
+	}
+	e.samples++
+	e.at = now
+}
+
+func (p *Prober) entryLocked(peer string) *entry {
+	e := p.peers[peer]
+	if e == nil {
+		now := p.cfg.Now()
+		e = &entry{avail: availability{firstSeen: now, interval: p.cfg.MinCheckInterval}}
+		p.peers[peer] = e
+	}
+	return e
+}
+
+// weight is the confidence left in a measurement taken at: 1 when fresh,
+// halving every HalfLife.
+func (p *Prober) weight(at, now time.Time) float64 {
+	age := now.Sub(at)
+	if age <= 0 {
+		return 1
+	}
+	return math.Exp2(-float64(age) / float64(p.cfg.HalfLife))
+}
+
+// ─── Queries ────────────────────────────────────────────────────────────────
+
+// Profile returns peer's profile with age-decayed estimates.
+func (p *Prober) Profile(peer string) (Profile, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	e, ok := p.peers[peer]
+	if !ok {
+		return Profile{}, false
+	}
+	return p.profileLocked(peer, e, p.cfg.Now()), true
+}
+
+// Profiles returns every peer's profile, sorted by node ID.
+func (p *Prober) Profiles() []Profile {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.cfg.Now()
+	out := make([]Profile, 0, len(p.peers))
+	for id, e := range p.peers {
+		out = append(out, p.profileLocked(id, e, now))
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].NodeID < out[j].NodeID })
+	return out
+}
+
+// LatencyMs returns the estimated RTT to peer in milliseconds. Unmeasured
+// peers get the network-wide mean.
+func (p *Prober) LatencyMs(peer string) float64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.cfg.Now()
+	prior := p.meanLocked(func(e *entry) estimate { return e.rtt }, defaultRTTMs)
+	if e, ok := p.peers[peer]; ok {
+		return p.decayed(e.rtt, prior, now)
+	}
+	return prior
+}
+
+// Bandwidth returns the estimated bandwidth from peer in bytes/sec.
+// Unmeasured peers get the network-wide mean.
+func (p *Prober) Bandwidth(peer string) float64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.cfg.Now()
+	prior := p.meanLocked(func(e *entry) estimate { return e.bw }, defaultBandwidth)
+	if e, ok := p.peers[peer]; ok {
+		return p.decayed(e.bw, prior, now)
+	}
+	return prior
+}
+
+func (p *Prober) profileLocked(peer string, e *entry, now time.Time) Profile {
+	rttPrior := p.meanLocked(func(e *entry) estimate { return e.rtt }, defaultRTTMs)
+	bwPrior := p.meanLocked(func(e *entry) estimate { return e.bw }, defaultBandwidth)
+	updated := e.updatedAt()
+	prof := Profile{
+		NodeID:           peer,
+		RTTMs:            p.decayed(e.rtt, rttPrior, now),
+		BandwidthBps:     p.decayed(e.bw, bwPrior, now),
+		RTTSamples:       e.rtt.samples,
+		BandwidthSamples: e.bw.samples,
+		Confidence:       p.weight(updated, now),
+		UpdatedAt:        updated,
+		Stale:            now.Sub(updated) > p.cfg.StaleAfter,
+		Online:           e.avail.online,
+		Flap:             e.avail.flap,
+		CheckedAt:        e.avail.checkedAt,
+	}
+	if !e.avail.checkedAt.IsZero() {
+		prof.NextCheck = e.avail.checkedAt.Add(e.avail.interval)
+	}
+	return prof
+}
+
+// decayed blends an estimate towards prior as it ages.
+func (p *Prober) decayed(e estimate, prior float64, now time.Time) float64 {
+	if e.samples == 0 {
+		return prior
+	}
+	w := p.weight(e.at, now)
+	return w*e.value + (1-w)*prior
+}
+
+// meanLocked averages the measured estimates selected by field, or returns
+// def when nothing has been measured.
+func (p *Prober) meanLocked(field func(*entry) estimate, def float64) float64 {
+	var sum float64
+	n := 0
+	for _, e := range p.peers {
+		if est := field(e); est.samples > 0 {
+			sum += est.value
+			n++
+		}
+	}
+	if n == 0 {
+		return def
+	}
+	return sum / float64(n)
+}
+
+// ─── Active Probing ─────────────────────────────────────────────────────────
+
+// ProbeOnce probes up to MaxProbesPerRound peers whose availability check
+// is due or whose profile is missing or stale, most overdue first, within
+// the byte budget. Returns the number of peers probed.
+func (p *Prober) ProbeOnce(ctx context.Context) int {
+	if p.hooks.Peers == nil || (p.hooks.Ping == nil && p.hooks.Transfer == nil) {
+		return 0
+	}
+	peers := p.hooks.Peers()
+	p.schedule(peers)
+	due := p.due(peers)
+	probed := 0
+	for _, peer := range due {
+		if ctx.Err() != nil {
+			break
+		}
+		if p.probe(ctx, peer) {
+			probed++
+		}
+	}
+	return probed
+}
+
+// schedule sets each known peer's availability check interval from its
+// history and reputation. Reputations are looked up outside the lock.
+func (p *Prober) schedule(peers []string) {
+	reps := make(map[string]float64, len(peers))
+	for _, peer := range peers {
+		reps[peer] = 0.5
+		if p.hooks.Reputation != nil {
+			reps[peer] = min(max(p.hooks.Reputation(peer), 0), 1)
+		}
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.cfg.Now()
+	for peer, rep := range reps {
+		if e := p.peers[peer]; e != nil {
+			e.avail.interval = p.checkInterval(e.avail, rep, now)
+		}
+	}
+}
+
+// checkInterval is how long a peer may go unchecked: MinCheckInterval while
+// it is new or was last offline, otherwise growing with its reputation and
+// shrinking with how often it flaps.
+func (p *Prober) checkInterval(a availability, reputation float64, now time.Time) time.Duration {
+	if now.Sub(a.firstSeen) < p.cfg.NewPeerAge || !a.online {
+		return p.cfg.MinCheckInterval
+	}
+	stability := reputation * (1 - a.flap)
+	span := p.cfg.MaxCheckInterval - p.cfg.MinCheckInterval
+	return p.cfg.MinCheckInterval + time.Duration(stability*float64(span))
+}
+
+// checkDueLocked reports whether a peer's availability check is due.
+func (p *Prober) checkDueLocked(e *entry, now time.Time) bool {
+	return e.avail.checkedAt.IsZero() || now.Sub(e.avail.checkedAt) >= e.avail.interval
+}
+
+// checkedLocked records an availability check.
+func (p *Prober) checkedLocked(e *entry, online bool) {
+	a := &e.avail
+	if a.checks > 0 {
+		changed := 0.0
+		if online != a.online {
+			changed = 1
+		}
+		a.flap = p.cfg.Alpha*changed + (1-p.cfg.Alpha)*a.flap
+	}
+	a.checks++
+	a.online = online
+	a.checkedAt = p.cfg.Now()
+	p.checks++
+	if !online {
+		p.offline++
+	}
+}
+
+// reportAvailability passes a check to the OnAvailability hook.
+func (p *Prober) reportAvailability(peer string, online bool) {
+	if p.hooks.OnAvailability != nil {
+		p.hooks.OnAvailability(peer, online)
+	}
+}
+
+// due returns the peers to probe this round.
+func (p *Prober) due(peers []string) []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.cfg.Now()
+	type candidate struct {
+		peer string
+		at   time.Time // when the oldest measurement or check fell due; zero = never
+	}
+	var cands []candidate
+	for _, peer := range peers {
+		e := p.peers[peer]
+		if e == nil {
+			cands = append(cands, candidate{peer: peer})
+			continue
+		}
+		needRTT := p.hooks.Ping != nil && p.needPingLocked(e, now)
+		needBW := p.hooks.Transfer != nil && p.staleLocked(e.bw, now)
+		var at []time.Time
+		if needRTT {
+			at = append(at, p.pingDueLocked(e))
+		}
+		if needBW {
+			at = append(at, dueAt(e.bw, p.cfg.StaleAfter))
+		}
+		if len(at) > 0 {
+			cands = append(cands, candidate{peer, slices.MinFunc(at, time.Time.Compare)})
+		}
+	}
+	sort.SliceStable(cands, func(i, j int) bool { return cands[i].at.Before(cands[j].at) })
+	out := make([]string, 0, min(len(cands), p.cfg.MaxProbesPerRound))
+	for _, c := range cands[:min(len(cands), p.cfg.MaxProbesPerRound)] {
+		out = append(out, c.peer)
+	}
+	return out
+}
+
+// staleLocked reports whether an estimate needs an active probe.
+func (p *Prober) staleLocked(e estimate, now time.Time) bool {
+	return e.samples == 0 || now.Sub(e.at) > p.cfg.StaleAfter
+}
+
+// needPingLocked reports whether a peer needs an RTT ping: its RTT was never
+// measured or its availability check is due.
+func (p *Prober) needPingLocked(e *entry, now time.Time) bool {
+	return e.rtt.samples == 0 || p.checkDueLocked(e, now)
+}
+
+// pingDueLocked returns when a peer's ping fell due; zero if it was never
+// measured or checked.
+func (p *Prober) pingDueLocked(e *entry) time.Time {
+	if e.rtt.samples == 0 || e.avail.checkedAt.IsZero() {
+		return time.Time{}
+	}
+	return e.avail.checkedAt.Add(e.avail.interval)
+}
+
+// dueAt returns when an estimate goes stale; zero if it was never measured.
+func dueAt(e estimate, staleAfter time.Duration) time.Time {
+	if e.samples == 0 {
+		return time.Time{}
+	}
+	return e.at.Add(staleAfter)
+}
+
+// probe runs the active probes one peer needs. Returns false if none ran.
+func (p *Prober) probe(ctx context.Context, peer string) bool {
+	p.mu.Lock()
+	now := p.cfg.Now()
+	var e entry
+	if cur := p.peers[peer]; cur != nil {
+		e = *cur
+	}
+	needRTT := p.hooks.Ping != nil && p.needPingLocked(&e, now)
+	needBW := p.hooks.Transfer != nil && p.staleLocked(e.bw, now)
+	p.mu.Unlock()
+
+	ran := false
+	if needRTT && p.spend(pingCost) {
+		ran = true
+		rtt, err := p.hooks.Ping(ctx, peer)
+		p.recordProbe(err)
+		if ctx.Err() == nil {
+			p.mu.Lock()
+			e := p.entryLocked(peer)
+			if err == nil {
+				p.foldLocked(&e.rtt, float64(rtt)/float64(time.Millisecond))
+			}
+			p.checkedLocked(e, err == nil)
+			p.mu.Unlock()
+			p.reportAvailability(peer, err == nil)
+		}
+	}
+	if needBW && p.spend(int64(p.cfg.ProbeBytes)) {
+		ran = true
+		elapsed, err := p.hooks.Transfer(ctx, peer, p.cfg.ProbeBytes)
+		p.recordProbe(err)
+		if err == nil {
+			elapsed = max(elapsed, time.Millisecond)
+			p.mu.Lock()
+			p.foldLocked(&p.entryLocked(peer).bw, float64(p.cfg.ProbeBytes)/elapsed.Seconds())
+			p.mu.Unlock()
+		}
+	}
+	return ran
+}
+
+// spend takes n bytes from the current window's budget. Returns false (and
+// counts it) if the budget is exhausted.
+func (p *Prober) spend(n int64) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.cfg.Now()
+	if now.Sub(p.windowStart) >= p.cfg.BudgetWindow {
+		p.windowStart = now
+		p.spent = 0
+	}
+	if p.spent+n > p.cfg.BudgetBytes {
+		p.overBudget++
+		return false
+	}
+	p.spent += n
+	return true
+}
+
+func (p *Prober) recordProbe(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.probes++
+	if err != nil {
+		p.probeFailures++
+	}
+}
+
+// Run probes every ProbeInterval and expires old profiles until ctx is done.
+func (p *Prober) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.cfg.ProbeInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.Expire()
+			p.ProbeOnce(ctx)
+		}
+	}
+}
+
+// Expire drops profiles not refreshed for MaxAge and returns how many were
+// removed.
+func (p *Prober) Expire() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.cfg.Now()
+	n := 0
+	for id, e := range p.peers {
+		if now.Sub(e.updatedAt()) > p.cfg.MaxAge {
+			delete(p.peers, id)
+			n++
+		}
+	}
+	return n
+}
+
+// Remove forgets a peer (e.g. when it leaves the network).
+func (p *Prober) Remove(peer string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.peers, peer)
+}
+
+// ─── Stats ──────────────────────────────────────────────────────────────────
+
+// Stats summarizes the prober.
+type Stats struct {
+	Peers          int     `json:"peers"`
+	Stale          int     `json:"stale"`
+	MeanRTTMs      float64 `json:"mean_rtt_ms"`
+	MeanBandwidth  float64 `json:"mean_bandwidth_bps"`
+	Probes         int64   `json:"probes"`
+	ProbeFailures  int64   `json:"probe_failures"`
+	OverBudget     int64   `json:"over_budget"`     // probes skipped for budget
+	PassiveSamples int64   `json:"passive_samples"` // samples from real traffic
+	BudgetSpent    int64   `json:"budget_spent"`    // bytes in the current window
+	Checks         int64   `json:"availability_checks"`
+	OfflineChecks  int64   `json:"offline_checks"`
+	Flapping       int     `json:"flapping"`   // peers whose flap rate is at least 0.5
+	DueChecks      int     `json:"due_checks"` // peers whose availability check is due
+}
+
+// Stats returns a snapshot of the prober.
+func (p *Prober) Stats() Stats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.cfg.Now()
+	st := Stats{
+		Peers:          len(p.peers),
+		MeanRTTMs:      p.meanLocked(func(e *entry) estimate { return e.rtt }, defaultRTTMs),
+		MeanBandwidth:  p.meanLocked(func(e *entry) estimate { return e.bw }, defaultBandwidth),
+		Probes:         p.probes,
+		ProbeFailures:  p.probeFailures,
+		OverBudget:     p.overBudget,
+		PassiveSamples: p.passive,
+		Checks:         p.checks,
+		OfflineChecks:  p.offline,
+	}
+	if now.Sub(p.windowStart) < p.cfg.BudgetWindow {
+		st.BudgetSpent = p.spent
+	}
+	for _, e := range p.peers {
+		if now.Sub(e.updatedAt()) > p.cfg.StaleAfter {
+			st.Stale++
+		}
+		if e.avail.flap >= 0.5 {
+			st.Flapping++
+		}
+		if p.checkDueLocked(e, now) {
+			st.DueChecks++
+		}
+	}
+	return st
+}
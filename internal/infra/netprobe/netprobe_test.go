@@ -0,0 +1,222 @@
+package netprobe
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+type fakeClock struct{ now time.Time }
+
+func (c *fakeClock) Now() time.Time          { return c.now }
+func (c *fakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func newTestProber(hooks Hooks) (*Prober, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	cfg := DefaultConfig()
+	cfg.Now = clock.Now
+	return New(cfg, hooks), clock
+}
+
+func approx(a, b float64) bool { return math.Abs(a-b) < 1e-6 }
+
+func TestProber_PassiveSamples(t *testing.T) {
+	p, _ := newTestProber(Hooks{})
+	p.ObserveRTT("a", 40*time.Millisecond)
+	p.ObserveRTT("a", 60*time.Millisecond)
+	p.ObserveTransfer("a", 2<<20, time.Second)
+
+	prof, ok := p.Profile("a")
+	if !ok {
+		t.Fatal("no profile for a")
+	}
+	// EWMA with alpha 0.3: 0.3×60 + 0.7×40
+	if !approx(prof.RTTMs, 46) || prof.RTTSamples != 2 {
+		t.Errorf("RTT = %.2f over %d samples, want 46 over 2", prof.RTTMs, prof.RTTSamples)
+	}
+	if !approx(prof.BandwidthBps, 2<<20) {
+		t.Errorf("Bandwidth = %.0f, want %d", prof.BandwidthBps, 2<<20)
+	}
+	if prof.Confidence != 1 || prof.Stale {
+		t.Errorf("fresh profile: confidence %.2f stale %v", prof.Confidence, prof.Stale)
+	}
+}
+
+func TestProber_UnmeasuredPeerGetsNetworkMean(t *testing.T) {
+	p, _ := newTestProber(Hooks{})
+	if got := p.LatencyMs("x"); got != defaultRTTMs {
+		t.Errorf("LatencyMs with no data = %.1f, want %.1f", got, defaultRTTMs)
+	}
+	p.ObserveRTT("a", 20*time.Millisecond)
+	p.ObserveRTT("b", 40*time.Millisecond)
+	if got := p.LatencyMs("x"); !approx(got, 30) {
+		t.Errorf("LatencyMs(unmeasured) = %.1f, want mean 30", got)
+	}
+	if got := p.Bandwidth("x"); got != defaultBandwidth {
+		t.Errorf("Bandwidth with no data = %.0f, want %d", got, defaultBandwidth)
+	}
+}
+
+func TestProber_StaleMeasurementsDecay(t *testing.T) {
+	p, clock := newTestProber(Hooks{})
+	p.ObserveRTT("a", 10*time.Millisecond)
+	p.ObserveRTT("b", 90*time.Millisecond) // mean prior = 50
+
+	clock.Advance(10 * time.Minute) // one half-life
+	prof, _ := p.Profile("a")
+	if !approx(prof.RTTMs, 30) || !approx(prof.Confidence, 0.5) || !prof.Stale {
+		t.Errorf("after one half-life: RTT %.2f confidence %.2f stale %v, want 30, 0.5, true",
+			prof.RTTMs, prof.Confidence, prof.Stale)
+	}
+
+	// A new sample replaces a half-forgotten estimate faster than alpha
+	p.ObserveRTT("a", 70*time.Millisecond)
+	prof, _ = p.Profile("a")
+	// alpha' = 1 − 0.7×0.5 = 0.65 → 0.65×70 + 0.35×10
+	if !approx(prof.RTTMs, 49) {
+		t.Errorf("RTT after stale refresh = %.2f, want 49", prof.RTTMs)
+	}
+
+	clock.Advance(2 * time.Hour)
+	if n := p.Expire(); n != 2 {
+		t.Errorf("Expire() = %d, want 2", n)
+	}
+}
+
+func TestProber_ProbeOnce(t *testing.T) {
+	var pinged, transferred []string
+	p, clock := newTestProber(Hooks{
+		Peers: func() []string { return []string{"a", "b", "c"} },
+		Ping: func(_ context.Context, peer string) (time.Duration, error) {
+			pinged = append(pinged, peer)
+			if peer == "c" {
+				return 0, errors.New("unreachable")
+			}
+			return 25 * time.Millisecond, nil
+		},
+		Transfer: func(_ context.Context, peer string, n int) (time.Duration, error) {
+			transferred = append(transferred, peer)
+			return 250 * time.Millisecond, nil
+		},
+	})
+	p.ObserveRTT("b", 5*time.Millisecond) // fresh RTT: b only needs bandwidth
+
+	if n := p.ProbeOnce(context.Background()); n != 3 {
+		t.Fatalf("ProbeOnce() = %d, want 3", n)
+	}
+	if len(pinged) != 2 || len(transferred) != 3 {
+		t.Errorf("pinged %v, transferred %v; want a,c pinged and all transferred", pinged, transferred)
+	}
+	if got := p.LatencyMs("a"); !approx(got, 25) {
+		t.Errorf("LatencyMs(a) = %.2f, want 25", got)
+	}
+	if got := p.Bandwidth("a"); !approx(got, float64(256<<10)*4) {
+		t.Errorf("Bandwidth(a) = %.0f, want %d", got, 256<<10*4)
+	}
+
+	// Nothing is stale any more except c's failed ping
+	pinged, transferred = nil, nil
+	p.ProbeOnce(context.Background())
+	if len(pinged) != 1 || pinged[0] != "c" || len(transferred) != 0 {
+		t.Errorf("second round pinged %v, transferred %v; want only c pinged", pinged, transferred)
+	}
+
+	clock.Advance(6 * time.Minute)
+	st := p.Stats()
+	if st.Probes != 6 || st.ProbeFailures != 2 || st.PassiveSamples != 1 {
+		t.Errorf("Stats = %+v", st)
+	}
+}
+
+func TestProber_Budget(t *testing.T) {
+	transfers := 0
+	clock := &fakeClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	cfg := DefaultConfig()
+	cfg.Now = clock.Now
+	cfg.ProbeBytes = 1 << 20
+	cfg.BudgetBytes = 2 << 20
+	p := New(cfg, Hooks{
+		Peers: func() []string { return []string{"a", "b", "c", "d"} },
+		Transfer: func(context.Context, string, int) (time.Duration, error) {
+			transfers++
+			return time.Second, nil
+		},
+	})
+
+	if n := p.ProbeOnce(context.Background()); n != 2 || transfers != 2 {
+		t.Fatalf("ProbeOnce() = %d with %d transfers, want 2 within budget", n, transfers)
+	}
+	if st := p.Stats(); st.OverBudget != 2 || st.BudgetSpent != 2<<20 {
+		t.Errorf("Stats = %+v, want 2 over budget and the whole budget spent", st)
+	}
+
+	clock.Advance(time.Minute)
+	if n := p.ProbeOnce(context.Background()); n != 2 {
+		t.Errorf("next window ProbeOnce() = %d, want the 2 remaining peers", n)
+	}
+}
+
+func TestProber_AdaptiveAvailabilityChecks(t *testing.T) {
+	online := map[string]bool{"stable": true, "flappy": true, "low": true}
+	reputation := map[string]float64{"stable": 1, "flappy": 1, "low": 0}
+	checks := map[string][]bool{}
+	p, clock := newTestProber(Hooks{
+		Peers: func() []string { return []string{"stable", "flappy", "low"} },
+		Ping: func(_ context.Context, peer string) (time.Duration, error) {
+			if !online[peer] {
+				return 0, errors.New("unreachable")
+			}
+			return 10 * time.Millisecond, nil
+		},
+		Reputation:     func(peer string) float64 { return reputation[peer] },
+		OnAvailability: func(peer string, up bool) { checks[peer] = append(checks[peer], up) },
+	})
+
+	// New peers are checked every MinCheckInterval; flappy toggles each time
+	for range 4 {
+		p.ProbeOnce(context.Background())
+		online["flappy"] = !online["flappy"]
+		clock.Advance(15 * time.Minute)
+	}
+	for _, peer := range []string{"stable", "flappy", "low"} {
+		if n := len(checks[peer]); n != 4 {
+			t.Fatalf("%s checked %d times during its first hour, want 4", peer, n)
+		}
+	}
+
+	// Once established the trusted stable peer is checked every 30m, the
+	// low-reputation one every round; the flappy one is checked less often
+	// as it settles down
+	clear(checks)
+	online["flappy"] = true
+	for range 4 {
+		p.ProbeOnce(context.Background())
+		clock.Advance(10 * time.Minute)
+	}
+	if n := len(checks["stable"]); n != 1 {
+		t.Errorf("stable checked %d times in 40m, want 1", n)
+	}
+	if n := len(checks["low"]); n != 4 {
+		t.Errorf("low-reputation peer checked %d times in 40m, want 4", n)
+	}
+	if n := len(checks["flappy"]); n != 3 {
+		t.Errorf("flappy checked %d times in 40m, want 3", n)
+	}
+	if prof, _ := p.Profile("flappy"); !prof.Online || prof.Flap <= 0 || prof.NextCheck.Before(prof.CheckedAt) {
+		t.Errorf("flappy profile = %+v", prof)
+	}
+
+	// A passive RTT that arrives when a check is due counts as the check
+	clear(checks)
+	clock.Advance(time.Hour)
+	p.ObserveRTT("stable", 12*time.Millisecond)
+	p.ObserveRTT("stable", 12*time.Millisecond)
+	if got := checks["stable"]; len(got) != 1 || !got[0] {
+		t.Errorf("passive checks = %v, want one online check", got)
+	}
+	if st := p.Stats(); st.Checks != 21 || st.OfflineChecks != 2 {
+		t.Errorf("Stats = %+v", st)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net"
 	"sync"
 	"time"
 
@@ -20,6 +21,9 @@ import (
 	"github.com/tutu-network/tutu/internal/security"
 )
 
+// bootstrapTimeout bounds the membership snapshot fetch at start.
+const bootstrapTimeout = 15 * time.Second
+
 // FabricConfig configures the network fabric.
 type FabricConfig struct {
 	Enabled           bool
@@ -27,6 +31,13 @@ type FabricConfig struct {
 	HeartbeatInterval time.Duration
 	Region            string
 	GossipConfig      gossip.Config
+	Availability      gossip.AvailabilityConfig // model availability announcements
+	Load              gossip.LoadConfig         // queue depth reports for work stealing
+	Heartbeat         gossip.HeartbeatConfig    // load heartbeats for placement
+	Hierarchy         gossip.HierarchyConfig    // two-tier gossip; flat when Cluster is empty
+	Blocklist         gossip.BlocklistConfig    // signed bans of nodes and model digests
+	SnapshotAddr      string                    // TCP address serving membership snapshots ("" = disabled)
+	SnapshotSeeds     []string                  // snapshot addresses to bootstrap membership from
 }
 
 // DefaultFabricConfig returns defaults matching Architecture Part VIII.
@@ -37,6 +48,11 @@ func DefaultFabricConfig() FabricConfig {
 		HeartbeatInterval: 10 * time.Second,
 		Region:            "auto",
 		GossipConfig:      gossip.DefaultConfig(),
+		Availability:      gossip.DefaultAvailabilityConfig(),
+		Load:              gossip.DefaultLoadConfig(),
+		Heartbeat:         gossip.DefaultHeartbeatConfig(),
+		Hierarchy:         gossip.DefaultHierarchyConfig(),
+		Blocklist:         gossip.DefaultBlocklistConfig(),
 	}
 }
 
@@ -59,6 +75,12 @@ type Fabric struct {
 	keypair     *security.Keypair
 	governor    *resource.Governor
 	swim        *gossip.SWIM
+	avail       *gossip.AvailabilityIndex
+	load        *gossip.LoadIndex
+	quarantine  *gossip.QuarantineList
+	blocklist   *gossip.Blocklist
+	heartbeats  *gossip.HeartbeatIndex
+	hierarchy   *gossip.Hierarchy
 	isOnline    bool
 	stopped     bool // Prevents re-registration after Stop()
 	startedAt   time.Time
@@ -66,6 +88,9 @@ type Fabric struct {
 
 	// Task handler receives task assignments from Cloud Core
 	taskHandler func(task domain.Task) error
+
+	// Trace context stamped on outgoing task messages (nil = none)
+	traceParent func(taskID string) string
 }
 
 // NewFabric creates a network fabric.
@@ -89,6 +114,25 @@ func NewFabric(cfg FabricConfig, kp *security.Keypair, gov *resource.Governor) *
 		log.Printf("[network] peer left: %s", id)
 	})
 
+	// Model availability rides on the same gossip
+	f.avail = gossip.NewAvailabilityIndex(nodeID, cfg.Availability)
+	f.swim.SetAvailability(f.avail)
+	f.load = gossip.NewLoadIndex(nodeID, cfg.Load)
+	f.swim.SetLoad(f.load)
+	f.quarantine = gossip.NewQuarantineList(nodeID, cfg.Blocklist.Signers, nil)
+	f.swim.SetQuarantine(f.quarantine)
+	f.blocklist = gossip.NewBlocklist(nodeID, cfg.Blocklist)
+	f.swim.SetBlocklist(f.blocklist)
+	f.heartbeats = gossip.NewHeartbeatIndex(nodeID, cfg.Heartbeat)
+	f.swim.SetHeartbeat(f.heartbeats)
+
+	// With a cluster configured, SWIM covers only the cluster and elected
+	// representatives gossip summaries between clusters
+	if cfg.Hierarchy.Cluster != "" {
+		f.hierarchy = gossip.NewHierarchy(nodeID, cfg.Hierarchy)
+		f.swim.SetHierarchy(f.hierarchy)
+	}
+
 	return f
 }
 
@@ -97,6 +141,13 @@ func (f *Fabric) OnTaskAssigned(handler func(task domain.Task) error) {
 	f.taskHandler = handler
 }
 
+// SetTraceParent stamps outgoing task messages that carry no trace context
+// with fn(task.ID), so the receiving node records its spans in the same
+// trace.
+func (f *Fabric) SetTraceParent(fn func(taskID string) string) {
+	f.traceParent = fn
+}
+
 // NodeID returns this node's public key hex identifier.
 func (f *Fabric) NodeID() string {
 	return f.nodeID
@@ -117,6 +168,32 @@ func (f *Fabric) Start(ctx context.Context) error {
 		// Continue in offline mode — Architecture Part XVIII
 	}
 
+	// Learn full membership from a seed before gossip starts probing
+	if len(f.config.SnapshotSeeds) > 0 {
+		bctx, cancel := context.WithTimeout(ctx, bootstrapTimeout)
+		n, err := f.swim.Bootstrap(bctx, f.config.SnapshotSeeds)
+		cancel()
+		if err != nil {
+			log.Printf("[network] membership bootstrap failed: %v", err)
+		} else {
+			log.Printf("[network] bootstrapped %d members from snapshot", n)
+		}
+	}
+
+	// Serve our membership to joining nodes
+	if f.config.SnapshotAddr != "" {
+		ln, err := net.Listen("tcp", f.config.SnapshotAddr)
+		if err != nil {
+			log.Printf("[network] snapshot listener: %v", err)
+		} else {
+			go func() {
+				if err := f.swim.ServeSnapshots(ctx, ln); err != nil {
+					log.Printf("[network] snapshot server error: %v", err)
+				}
+			}()
+		}
+	}
+
 	// Start SWIM gossip in background
 	go func() {
 		if err := f.swim.Start(ctx); err != nil {
@@ -161,6 +238,91 @@ func (f *Fabric) Peers() []domain.Peer {
 	return f.swim.Members()
 }
 
+// Availability returns the gossiped model availability index.
+func (f *Fabric) Availability() *gossip.AvailabilityIndex {
+	return f.avail
+}
+
+// Load returns the gossiped queue depth index.
+func (f *Fabric) Load() *gossip.LoadIndex {
+	return f.load
+}
+
+// ReplayStats returns how many gossip messages replay protection dropped.
+func (f *Fabric) ReplayStats() gossip.ReplayStats {
+	return f.swim.ReplayStats()
+}
+
+// ProtocolStats returns the gossip protocol versions of this node and its
+// peers.
+func (f *Fabric) ProtocolStats() gossip.ProtocolStats {
+	return f.swim.ProtocolStats()
+}
+
+// Heartbeats returns the index of peers' load heartbeats.
+func (f *Fabric) Heartbeats() *gossip.HeartbeatIndex {
+	return f.heartbeats
+}
+
+// Hierarchy returns the cluster hierarchy view, or nil when gossip is flat.
+func (f *Fabric) Hierarchy() *gossip.Hierarchy {
+	return f.hierarchy
+}
+
+// Quarantine returns the gossiped quarantine list.
+func (f *Fabric) Quarantine() *gossip.QuarantineList {
+	return f.quarantine
+}
+
+// PublishQuarantine signs a quarantine or release as this node and gossips
+// it.
+func (f *Fabric) PublishQuarantine(n gossip.QuarantineNotice) error {
+	n.Sign(f.keypair)
+	return f.swim.PublishQuarantine(n)
+}
+
+// Blocklist returns the gossiped blocklist.
+func (f *Fabric) Blocklist() *gossip.Blocklist {
+	return f.blocklist
+}
+
+// PublishBlock gossips a blocklist entry signed by this node or a trusted
+// signer.
+func (f *Fabric) PublishBlock(e gossip.BlockEntry) error {
+	return f.swim.PublishBlock(e)
+}
+
+// SendRendezvous sends a NAT traversal signal to a peer over gossip.
+func (f *Fabric) SendRendezvous(nodeID string, payload []byte) error {
+	return f.swim.SendRendezvous(nodeID, payload)
+}
+
+// OnRendezvous sets the handler for NAT traversal signals from peers.
+func (f *Fabric) OnRendezvous(fn func(from string, payload []byte)) {
+	f.swim.OnRendezvous(fn)
+}
+
+// SetLearning attaches the scheduler learning exchange (see
+// gossip.LearningConfig). Call before Start.
+func (f *Fabric) SetLearning(cfg gossip.LearningConfig) {
+	f.swim.SetLearning(cfg)
+}
+
+// OnRTT sets the handler for round-trip times measured by gossip probes.
+func (f *Fabric) OnRTT(fn func(nodeID string, rtt time.Duration)) {
+	f.swim.OnRTT(fn)
+}
+
+// Ping measures the round-trip time to a peer over gossip.
+func (f *Fabric) Ping(ctx context.Context, nodeID string) (time.Duration, error) {
+	return f.swim.Ping(ctx, nodeID)
+}
+
+// AnnounceModels immediately gossips a changed local model list.
+func (f *Fabric) AnnounceModels(models []gossip.ModelVersion) {
+	f.swim.Announce(models)
+}
+
 // JoinPeers seeds the gossip layer with known peer addresses.
 func (f *Fabric) JoinPeers(addrs []string) error {
 	return f.swim.Join(addrs)
@@ -258,8 +420,11 @@ func (f *Fabric) SubmitTaskResult(ctx context.Context, task domain.Task) error {
 	if !f.IsOnline() {
 		return fmt.Errorf("node is offline")
 	}
+	if task.TraceParent == "" && f.traceParent != nil {
+		task.TraceParent = f.traceParent(task.ID)
+	}
 
 	// Phase 1: Stub
-	log.Printf("[network] task result stub: %s status=%s", task.ID, task.Status)
+	log.Printf("[network] task result stub: %s status=%s traceparent=%s", task.ID, task.Status, task.TraceParent)
 	return nil
 }
@@ -64,6 +64,7 @@ type Tracer struct {
 	spans    []Span
 	maxSpans int
 	enabled  bool
+	recorded uint64 // spans ever recorded, the cursor for SpansSince
 }
 
 // TracerConfig configures the tracer.
@@ -128,12 +129,31 @@ func (t *Tracer) EndSpan(span *Span, err error) {
 
 	t.mu.Lock()
 	defer t.mu.Unlock()
+	t.recordLocked(*span)
+}
 
+// Record stores a span that was timed elsewhere, such as one rebuilt from
+// journaled task events. A zero Duration is computed from the timestamps.
+func (t *Tracer) Record(span Span) {
+	if !t.enabled {
+		return
+	}
+	if span.Duration == 0 && !span.EndTime.IsZero() {
+		span.Duration = span.EndTime.Sub(span.StartTime)
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.recordLocked(span)
+}
+
+// recordLocked appends a span to the ring buffer. Caller must hold t.mu.
+func (t *Tracer) recordLocked(span Span) {
 	// Ring buffer: overwrite oldest if at capacity
 	if len(t.spans) >= t.maxSpans {
 		t.spans = t.spans[1:]
 	}
-	t.spans = append(t.spans, *span)
+	t.spans = append(t.spans, span)
+	t.recorded++
 }
 
 // Spans returns a copy of the recent spans.
@@ -152,6 +172,25 @@ func (t *Tracer) Spans(limit int) []Span {
 	return out
 }
 
+// SpansSince returns the spans recorded after cursor, oldest first, and the
+// cursor to pass next time. Spans already pushed out of the ring buffer are
+// skipped. Start from cursor 0.
+func (t *Tracer) SpansSince(cursor uint64) ([]Span, uint64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	oldest := t.recorded - uint64(len(t.spans))
+	if cursor < oldest {
+		cursor = oldest
+	}
+	if cursor >= t.recorded {
+		return nil, t.recorded
+	}
+	out := make([]Span, t.recorded-cursor)
+	copy(out, t.spans[cursor-oldest:])
+	return out, t.recorded
+}
+
 // SpanCount returns the number of recorded spans.
 func (t *Tracer) SpanCount() int {
 	t.mu.Lock()
@@ -253,6 +292,22 @@ var SchedulerTasksRejected = promauto.NewCounterVec(prometheus.CounterOpts{
 	Help:      "Total tasks rejected by back-pressure.",
 }, []string{"level"})
 
+// SchedulerTasksDeferred tracks tasks told to retry later by admission control.
+var SchedulerTasksDeferred = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "tutu",
+	Subsystem: "scheduler",
+	Name:      "tasks_deferred_total",
+	Help:      "Total tasks deferred by back-pressure admission control.",
+}, []string{"level"})
+
+// SchedulerBackPressureTransitions tracks back-pressure level changes.
+var SchedulerBackPressureTransitions = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "tutu",
+	Subsystem: "scheduler",
+	Name:      "back_pressure_transitions_total",
+	Help:      "Total back-pressure level transitions by source and target level.",
+}, []string{"from", "to"})
+
 // ─── Region Metrics ─────────────────────────────────────────────────────────
 
 // RegionRoutingDecisions tracks routing decisions by reason.
@@ -263,12 +318,12 @@ var RegionRoutingDecisions = promauto.NewCounterVec(prometheus.CounterOpts{
 	Help:      "Total routing decisions by reason.",
 }, []string{"reason"})
 
-// RegionLatency tracks cross-region latency.
+// RegionLatency tracks measured round trips between regions.
 var RegionLatency = promauto.NewHistogramVec(prometheus.HistogramOpts{
 	Namespace: "tutu",
 	Subsystem: "region",
 	Name:      "latency_ms",
-	Help:      "Cross-region latency in milliseconds.",
+	Help:      "Measured round-trip latency between regions in milliseconds.",
 	Buckets:   []float64{1, 5, 10, 25, 50, 100, 200, 500},
 }, []string{"from", "to"})
 
@@ -335,6 +390,27 @@ var NATLatency = promauto.NewHistogramVec(prometheus.HistogramOpts{
 	Buckets:   []float64{1, 3, 5, 10, 20, 50, 100},
 }, []string{"strategy"})
 
+// ─── Task Journal Metrics ───────────────────────────────────────────────────
+
+// TaskStageLatency tracks the time a task spends reaching each lifecycle
+// stage from the one before it.
+var TaskStageLatency = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: "tutu",
+	Subsystem: "tasks",
+	Name:      "stage_latency_ms",
+	Help:      "Time to reach each task lifecycle stage from the previous one, in milliseconds.",
+	Buckets:   []float64{1, 10, 50, 100, 500, 1000, 5000, 30000, 120000},
+}, []string{"stage"})
+
+// TaskDuplicatePayments tracks payments refused because the task was
+// already paid.
+var TaskDuplicatePayments = promauto.NewCounter(prometheus.CounterOpts{
+	Namespace: "tutu",
+	Subsystem: "tasks",
+	Name:      "duplicate_payments_total",
+	Help:      "Total task payments refused because the task was already paid.",
+})
+
 // ─── Trace Metrics ──────────────────────────────────────────────────────────
 
 // TracesRecorded tracks total spans recorded.
@@ -0,0 +1,169 @@
+package observability
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ─── OTLP Export ────────────────────────────────────────────────────────────
+// Pushes spans to any OpenTelemetry collector over OTLP/HTTP with the JSON
+// encoding (POST /v1/traces), which needs no SDK or protobuf dependency.
+// Spans started with non-W3C IDs (a custom context value, or the legacy
+// timestamp IDs) are hashed to IDs of the right width, so related spans
+// still share a trace in the backend.
+
+// OTLPConfig configures span export.
+type OTLPConfig struct {
+	Endpoint string       // collector base URL, e.g. http://otel-collector:4318
+	Service  string       // service.name resource attribute (default "tutu")
+	NodeID   string       // service.instance.id resource attribute
+	Client   *http.Client // default http.DefaultClient
+}
+
+type otlpKV struct {
+	Key   string `json:"key"`
+	Value struct {
+		StringValue string `json:"stringValue"`
+	} `json:"value"`
+}
+
+type otlpSpan struct {
+	TraceID           string   `json:"traceId"`
+	SpanID            string   `json:"spanId"`
+	ParentSpanID      string   `json:"parentSpanId,omitempty"`
+	Name              string   `json:"name"`
+	Kind              int      `json:"kind"`
+	StartTimeUnixNano string   `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string   `json:"endTimeUnixNano"`
+	Attributes        []otlpKV `json:"attributes,omitempty"`
+	Status            struct {
+		Code int `json:"code"`
+	} `json:"status"`
+}
+
+type otlpScopeSpans struct {
+	Scope struct {
+		Name string `json:"name"`
+	} `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpResourceSpans struct {
+	Resource struct {
+		Attributes []otlpKV `json:"attributes"`
+	} `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+// ExportOTLP sends spans to the collector in one request. Open spans (no
+// end time) are skipped.
+func ExportOTLP(ctx context.Context, cfg OTLPConfig, spans []Span) error {
+	if cfg.Endpoint == "" {
+		return fmt.Errorf("observability: OTLP endpoint is required")
+	}
+	if cfg.Service == "" {
+		cfg.Service = "tutu"
+	}
+	if cfg.Client == nil {
+		cfg.Client = http.DefaultClient
+	}
+
+	out := make([]otlpSpan, 0, len(spans))
+	for _, s := range spans {
+		if s.EndTime.IsZero() {
+			continue
+		}
+		sp := otlpSpan{
+			TraceID:           otlpID(s.TraceID, 32),
+			SpanID:            otlpID(s.SpanID, 16),
+			Name:              s.Operation,
+			Kind:              int(s.Kind) + 1, // OTLP: 1 internal, 2 server, 3 client
+			StartTimeUnixNano: strconv.FormatInt(s.StartTime.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(s.EndTime.UnixNano(), 10),
+			Attributes:        otlpAttrs(s.Attrs),
+		}
+		if s.ParentID != "" {
+			sp.ParentSpanID = otlpID(s.ParentID, 16)
+		}
+		sp.Status.Code = 1 // OK
+		if s.Status == SpanError {
+			sp.Status.Code = 2
+		}
+		out = append(out, sp)
+	}
+	if len(out) == 0 {
+		return nil
+	}
+
+	resource := map[string]string{"service.name": cfg.Service}
+	if cfg.NodeID != "" {
+		resource["service.instance.id"] = cfg.NodeID
+	}
+	var rs otlpResourceSpans
+	rs.Resource.Attributes = otlpAttrs(resource)
+	scope := otlpScopeSpans{Spans: out}
+	scope.Scope.Name = "github.com/tutu-network/tutu"
+	rs.ScopeSpans = []otlpScopeSpans{scope}
+	body := otlpRequest{ResourceSpans: []otlpResourceSpans{rs}}
+
+	buf, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("observability: encode OTLP: %w", err)
+	}
+	url := strings.TrimSuffix(cfg.Endpoint, "/")
+	if !strings.HasSuffix(url, "/v1/traces") {
+		url += "/v1/traces"
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(buf))
+	if err != nil {
+		return fmt.Errorf("observability: OTLP request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := cfg.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("observability: OTLP export: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("observability: OTLP export: HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// otlpID returns id if it is already n hex digits, else a hash of it.
+func otlpID(id string, n int) string {
+	if isHex(id, n) {
+		return id
+	}
+	sum := sha256.Sum256([]byte(id))
+	return hex.EncodeToString(sum[:n/2])
+}
+
+// otlpAttrs converts attributes to OTLP key-values in key order.
+func otlpAttrs(attrs map[string]string) []otlpKV {
+	keys := make([]string, 0, len(attrs))
+	for k := range attrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	out := make([]otlpKV, len(keys))
+	for i, k := range keys {
+		out[i].Key = k
+		out[i].Value.StringValue = attrs[k]
+	}
+	return out
+}
@@ -0,0 +1,264 @@
+package observability
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
+)
+
+// ─── Task Traces ────────────────────────────────────────────────────────────
+// Rebuilds a span tree for every task from its journaled lifecycle events.
+// The time between two events becomes a span named after the later stage,
+// the same attribution the journal uses for stage latency, and all of a
+// task's spans hang off one root span that covers submit → paid (or failed).
+//
+// Trace IDs are W3C TraceContext IDs. A task that arrives with a traceparent
+// joins that trace; otherwise the trace and root span IDs are derived from
+// the task ID, so every node that touches a task lands in the same trace
+// even without propagation. Only the node that saw SUBMITTED emits the root
+// span; other nodes parent their stage spans to the propagated span.
+
+// stageOps names the span ending at each event.
+var stageOps = map[domain.TaskEventType]string{
+	domain.TaskEventSubmitted: "task.submit",
+	domain.TaskEventScheduled: "task.schedule",
+	domain.TaskEventAssigned:  "task.assign",
+	domain.TaskEventStarted:   "task.transfer",
+	domain.TaskEventCompleted: "task.execute",
+	domain.TaskEventFailed:    "task.execute",
+	domain.TaskEventVerified:  "task.verify",
+	domain.TaskEventPaid:      "task.pay",
+	domain.TaskEventRecovered: "task.recover",
+}
+
+// TaskTraceConfig configures a TaskTracer.
+type TaskTraceConfig struct {
+	MaxTasks int // open task traces kept at once (default 10_000)
+}
+
+// DefaultTaskTraceConfig returns production defaults.
+func DefaultTaskTraceConfig() TaskTraceConfig {
+	return TaskTraceConfig{MaxTasks: 10_000}
+}
+
+// taskTrace is the open trace of one task.
+type taskTrace struct {
+	traceID  string
+	rootID   string // root span ID
+	parentID string // parent of this node's stage spans
+	remote   string // span ID propagated by the sending node, if any
+	owner    bool   // saw SUBMITTED, so emits the root span
+	start    time.Time
+	last     time.Time
+	attrs    map[string]string
+}
+
+// TaskTracer turns task events into spans on a Tracer. Thread-safe.
+type TaskTracer struct {
+	mu     sync.Mutex
+	tracer *Tracer
+	config TaskTraceConfig
+	open   map[string]*taskTrace
+}
+
+// NewTaskTracer creates a task tracer recording into tracer. Zero config
+// fields take their defaults.
+func NewTaskTracer(tracer *Tracer, cfg TaskTraceConfig) *TaskTracer {
+	if cfg.MaxTasks <= 0 {
+		cfg.MaxTasks = DefaultTaskTraceConfig().MaxTasks
+	}
+	return &TaskTracer{
+		tracer: tracer,
+		config: cfg,
+		open:   make(map[string]*taskTrace),
+	}
+}
+
+// Observe records the span ending at e. Events must arrive in journal
+// order; pass it to journal.OnEvent.
+func (tt *TaskTracer) Observe(e domain.TaskEvent) {
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+
+	tr, ok := tt.open[e.TaskID]
+	if !ok {
+		tr = tt.beginLocked(e)
+	}
+	if e.NodeID != "" && e.Type == domain.TaskEventAssigned {
+		tr.attrs["node.id"] = e.NodeID
+	}
+
+	span := Span{
+		TraceID:   tr.traceID,
+		SpanID:    NewSpanID(),
+		ParentID:  tr.parentID,
+		Operation: stageOps[e.Type],
+		Kind:      SpanInternal,
+		StartTime: tr.last,
+		EndTime:   e.Timestamp,
+		Status:    SpanOK,
+		Attrs:     tt.attrsLocked(tr, e),
+	}
+	if !ok {
+		span.StartTime = e.Timestamp
+	}
+	if e.Type == domain.TaskEventFailed {
+		span.Status = SpanError
+	}
+	tt.tracer.Record(span)
+	tr.last = e.Timestamp
+
+	if e.Type != domain.TaskEventPaid && e.Type != domain.TaskEventFailed {
+		return
+	}
+	if tr.owner {
+		root := Span{
+			TraceID:   tr.traceID,
+			SpanID:    tr.rootID,
+			ParentID:  tr.remote,
+			Operation: "task",
+			Kind:      SpanServer,
+			StartTime: tr.start,
+			EndTime:   e.Timestamp,
+			Status:    span.Status,
+			Attrs:     tt.attrsLocked(tr, e),
+		}
+		tt.tracer.Record(root)
+	}
+	delete(tt.open, e.TaskID)
+}
+
+// beginLocked opens the trace for a task's first event. Caller must hold
+// tt.mu.
+func (tt *TaskTracer) beginLocked(e domain.TaskEvent) *taskTrace {
+	if len(tt.open) >= tt.config.MaxTasks {
+		tt.evictLocked()
+	}
+	tr := &taskTrace{
+		traceID: TaskTraceID(e.TaskID),
+		rootID:  TaskRootSpanID(e.TaskID),
+		owner:   e.Type == domain.TaskEventSubmitted,
+		start:   e.Timestamp,
+		last:    e.Timestamp,
+		attrs:   map[string]string{"task.id": e.TaskID},
+	}
+	if e.TaskType != "" {
+		tr.attrs["task.type"] = string(e.TaskType)
+	}
+	tr.parentID = tr.rootID
+	if traceID, spanID, ok := ParseTraceParent(e.TraceParent); ok {
+		tr.traceID = traceID
+		tr.remote = spanID
+		if !tr.owner {
+			tr.parentID = spanID
+		}
+	}
+	tt.open[e.TaskID] = tr
+	return tr
+}
+
+// evictLocked drops the trace idle the longest. Caller must hold tt.mu.
+func (tt *TaskTracer) evictLocked() {
+	var oldest string
+	var at time.Time
+	for id, tr := range tt.open {
+		if oldest == "" || tr.last.Before(at) {
+			oldest, at = id, tr.last
+		}
+	}
+	delete(tt.open, oldest)
+}
+
+// attrsLocked returns a span's attributes. Caller must hold tt.mu.
+func (tt *TaskTracer) attrsLocked(tr *taskTrace, e domain.TaskEvent) map[string]string {
+	attrs := make(map[string]string, len(tr.attrs)+2)
+	for k, v := range tr.attrs {
+		attrs[k] = v
+	}
+	attrs["task.event"] = string(e.Type)
+	if e.Detail != "" {
+		attrs["task.detail"] = e.Detail
+	}
+	return attrs
+}
+
+// TraceParent returns the traceparent to send with a task to another node.
+// Unknown tasks get the IDs derived from their task ID.
+func (tt *TaskTracer) TraceParent(taskID string) string {
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+	if tr, ok := tt.open[taskID]; ok {
+		return FormatTraceParent(tr.traceID, tr.parentID)
+	}
+	return FormatTraceParent(TaskTraceID(taskID), TaskRootSpanID(taskID))
+}
+
+// Open returns the number of task traces still in progress.
+func (tt *TaskTracer) Open() int {
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+	return len(tt.open)
+}
+
+// ─── W3C TraceContext ───────────────────────────────────────────────────────
+
+// TaskTraceID derives a task's 32-hex-digit trace ID from its task ID.
+func TaskTraceID(taskID string) string {
+	sum := sha256.Sum256([]byte("trace:" + taskID))
+	return hex.EncodeToString(sum[:16])
+}
+
+// TaskRootSpanID derives the 16-hex-digit ID of a task's root span.
+func TaskRootSpanID(taskID string) string {
+	sum := sha256.Sum256([]byte("span:" + taskID))
+	return hex.EncodeToString(sum[:8])
+}
+
+// NewSpanID returns a random 16-hex-digit span ID.
+func NewSpanID() string {
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// FormatTraceParent renders a sampled version-00 traceparent header.
+func FormatTraceParent(traceID, spanID string) string {
+	return "00-" + traceID + "-" + spanID + "-01"
+}
+
+// ParseTraceParent extracts the trace and parent span IDs from a
+// traceparent header. ok is false for anything malformed, including the
+// all-zero IDs the spec forbids.
+func ParseTraceParent(header string) (traceID, spanID string, ok bool) {
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) < 4 || !isHex(parts[0], 2) || parts[0] == "ff" || !isHex(parts[3], 2) {
+		return "", "", false
+	}
+	if parts[0] == "00" && len(parts) != 4 {
+		return "", "", false
+	}
+	traceID, spanID = parts[1], parts[2]
+	if !isHex(traceID, 32) || !isHex(spanID, 16) ||
+		strings.Trim(traceID, "0") == "" || strings.Trim(spanID, "0") == "" {
+		return "", "", false
+	}
+	return traceID, spanID, true
+}
+
+// isHex reports whether s is n lowercase hex digits.
+func isHex(s string, n int) bool {
+	if len(s) != n {
+		return false
+	}
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
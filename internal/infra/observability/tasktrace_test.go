@@ -0,0 +1,162 @@
+package observability
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
+)
+
+// ─── Task Traces ────────────────────────────────────────────────────────────
+
+func TestTaskTracer_FullLifecycleAcrossNodes(t *testing.T) {
+	t0 := time.Unix(1_700_000_000, 0)
+	at := func(s int) time.Time { return t0.Add(time.Duration(s) * time.Second) }
+
+	// Submitting node: submit → schedule, then hands the task off
+	origin := NewTracer(DefaultTracerConfig())
+	ot := NewTaskTracer(origin, TaskTraceConfig{})
+	ot.Observe(domain.TaskEvent{TaskID: "t1", Type: domain.TaskEventSubmitted, TaskType: domain.TaskInference, Timestamp: at(0)})
+	ot.Observe(domain.TaskEvent{TaskID: "t1", Type: domain.TaskEventScheduled, Timestamp: at(1)})
+	tp := ot.TraceParent("t1")
+
+	// Worker node: joins the trace from the propagated traceparent
+	worker := NewTracer(DefaultTracerConfig())
+	wt := NewTaskTracer(worker, TaskTraceConfig{})
+	wt.Observe(domain.TaskEvent{TaskID: "t1", Type: domain.TaskEventAssigned, NodeID: "w", TraceParent: tp, Timestamp: at(2)})
+	wt.Observe(domain.TaskEvent{TaskID: "t1", Type: domain.TaskEventStarted, Timestamp: at(4)})
+	wt.Observe(domain.TaskEvent{TaskID: "t1", Type: domain.TaskEventCompleted, Timestamp: at(9)})
+
+	// Back on the origin: assign → pay
+	for i, typ := range []domain.TaskEventType{domain.TaskEventAssigned, domain.TaskEventStarted,
+		domain.TaskEventCompleted, domain.TaskEventVerified, domain.TaskEventPaid} {
+		ot.Observe(domain.TaskEvent{TaskID: "t1", Type: typ, Timestamp: at(10 + i)})
+	}
+	if ot.Open() != 0 {
+		t.Fatalf("Open() = %d after PAID, want 0", ot.Open())
+	}
+
+	traceID, rootID, ok := ParseTraceParent(tp)
+	if !ok {
+		t.Fatalf("TraceParent %q does not parse", tp)
+	}
+	all := append(origin.Spans(0), worker.Spans(0)...)
+	ops := map[string]bool{}
+	for _, s := range all {
+		if s.TraceID != traceID {
+			t.Errorf("%s trace = %s, want %s", s.Operation, s.TraceID, traceID)
+		}
+		if s.Operation != "task" && s.ParentID != rootID {
+			t.Errorf("%s parent = %s, want root %s", s.Operation, s.ParentID, rootID)
+		}
+		ops[s.Operation] = true
+	}
+	for _, op := range []string{"task", "task.submit", "task.schedule", "task.transfer", "task.execute", "task.verify", "task.pay"} {
+		if !ops[op] {
+			t.Errorf("no %s span", op)
+		}
+	}
+
+	root := origin.Spans(1)[0]
+	if root.Operation != "task" || root.SpanID != rootID || root.Duration != 14*time.Second {
+		t.Errorf("root = %s %s %v, want task %s 14s", root.Operation, root.SpanID, root.Duration, rootID)
+	}
+	if exec := worker.Spans(1)[0]; exec.Operation != "task.execute" || exec.Duration != 5*time.Second || exec.Attrs["node.id"] != "w" {
+		t.Errorf("worker execute span = %+v", exec)
+	}
+}
+
+func TestTaskTracer_AdoptsCallerTrace(t *testing.T) {
+	tr := NewTracer(DefaultTracerConfig())
+	tt := NewTaskTracer(tr, TaskTraceConfig{})
+	caller := FormatTraceParent("4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7")
+	now := time.Now()
+	tt.Observe(domain.TaskEvent{TaskID: "t2", Type: domain.TaskEventSubmitted, TraceParent: caller, Timestamp: now})
+	tt.Observe(domain.TaskEvent{TaskID: "t2", Type: domain.TaskEventFailed, Detail: "oom", Timestamp: now.Add(time.Second)})
+
+	root := tr.Spans(1)[0]
+	if root.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" || root.ParentID != "00f067aa0ba902b7" {
+		t.Errorf("root trace/parent = %s/%s, want the caller's", root.TraceID, root.ParentID)
+	}
+	if root.Status != SpanError || root.Attrs["task.detail"] != "oom" {
+		t.Errorf("root status = %d detail = %q, want error oom", root.Status, root.Attrs["task.detail"])
+	}
+}
+
+func TestTaskTracer_EvictsIdlest(t *testing.T) {
+	tt := NewTaskTracer(NewTracer(DefaultTracerConfig()), TaskTraceConfig{MaxTasks: 2})
+	now := time.Now()
+	for i, id := range []string{"a", "b", "c"} {
+		tt.Observe(domain.TaskEvent{TaskID: id, Type: domain.TaskEventSubmitted, Timestamp: now.Add(time.Duration(i) * time.Second)})
+	}
+	if tt.Open() != 2 {
+		t.Errorf("Open() = %d, want 2", tt.Open())
+	}
+}
+
+func TestParseTraceParent(t *testing.T) {
+	tests := []struct {
+		in string
+		ok bool
+	}{
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", true},
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", false},
+		{"01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", true},
+		{"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", false},
+		{"00-00000000000000000000000000000000-00f067aa0ba902b7-01", false},
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01", false},
+		{"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if _, _, ok := ParseTraceParent(tt.in); ok != tt.ok {
+			t.Errorf("ParseTraceParent(%q) ok = %v, want %v", tt.in, ok, tt.ok)
+		}
+	}
+}
+
+// ─── Tracer Cursor & OTLP ───────────────────────────────────────────────────
+
+func TestTracer_SpansSince(t *testing.T) {
+	tr := NewTracer(TracerConfig{Enabled: true, MaxSpans: 3})
+	for i := 0; i < 5; i++ {
+		tr.EndSpan(tr.StartSpan(context.Background(), "op", nil), nil)
+	}
+	spans, next := tr.SpansSince(0)
+	if len(spans) != 3 || next != 5 {
+		t.Errorf("SpansSince(0) = %d spans, cursor %d; want 3, 5", len(spans), next)
+	}
+	if spans, _ := tr.SpansSince(next); len(spans) != 0 {
+		t.Errorf("SpansSince(%d) = %d spans, want 0", next, len(spans))
+	}
+}
+
+func TestExportOTLP(t *testing.T) {
+	var got otlpRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/traces" {
+			t.Errorf("path = %s, want /v1/traces", r.URL.Path)
+		}
+		_ = json.NewDecoder(r.Body).Decode(&got)
+	}))
+	defer srv.Close()
+
+	tr := NewTracer(DefaultTracerConfig())
+	span := tr.StartSpan(WithTraceID(context.Background(), "trace-abc"), "legacy", nil)
+	tr.EndSpan(span, nil)
+	if err := ExportOTLP(context.Background(), OTLPConfig{Endpoint: srv.URL}, tr.Spans(0)); err != nil {
+		t.Fatal(err)
+	}
+
+	spans := got.ResourceSpans[0].ScopeSpans[0].Spans
+	if len(spans) != 1 || spans[0].Name != "legacy" {
+		t.Fatalf("exported %+v", spans)
+	}
+	if !isHex(spans[0].TraceID, 32) || !isHex(spans[0].SpanID, 16) {
+		t.Errorf("IDs %s/%s are not W3C width", spans[0].TraceID, spans[0].SpanID)
+	}
+}
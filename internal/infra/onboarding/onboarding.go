@@ -0,0 +1,454 @@
+// Package onboarding implements the first-run setup wizard.
+//
+// A new node walks through a fixed sequence of steps — detect hardware,
+// benchmark it, choose when to earn, pick a starter pack of models, and
+// optionally join a federation by invite code. Progress is a small state
+// machine persisted to disk, so the desktop UI and the CLI can both drive
+// it and pick up where the other left off.
+package onboarding
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
+	"github.com/tutu-network/tutu/internal/infra/catalog"
+	"github.com/tutu-network/tutu/internal/infra/passive"
+	"github.com/tutu-network/tutu/internal/infra/resource"
+)
+
+// ─── Steps ──────────────────────────────────────────────────────────────────
+
+// Step is one stage of onboarding.
+type Step string
+
+const (
+	StepHardware   Step = "hardware"   // detect CPU, RAM and GPUs
+	StepBenchmark  Step = "benchmark"  // measure tokens/sec and settle the tier
+	StepEarnings   Step = "earnings"   // accept or adjust the idle compute policy
+	StepModels     Step = "models"     // choose a starter pack
+	StepFederation Step = "federation" // join by invite code, or skip
+	StepDone       Step = "done"
+)
+
+// Steps lists the steps in the order they must first be completed.
+var Steps = []Step{StepHardware, StepBenchmark, StepEarnings, StepModels, StepFederation}
+
+// index returns the position of s in Steps (len(Steps) for StepDone).
+func (s Step) index() int {
+	for i, st := range Steps {
+		if st == s {
+			return i
+		}
+	}
+	return len(Steps)
+}
+
+// ─── State ──────────────────────────────────────────────────────────────────
+
+// EarningSettings is when the node works for the network and what that is
+// expected to earn.
+type EarningSettings struct {
+	IdlePolicy             resource.IdlePolicy `json:"idle_policy"`
+	EstimatedHourlyCredits int64               `json:"estimated_hourly_credits"` // while the policy admits work
+}
+
+// State is the wizard's progress. A step may be redone at any time once it
+// has been reached; Step is the first step not yet completed.
+type State struct {
+	Step              Step              `json:"step"`
+	Completed         []Step            `json:"completed"`
+	Hardware          *passive.Hardware `json:"hardware,omitempty"`
+	Profile           *passive.Profile  `json:"profile,omitempty"`
+	SuggestedEarnings *EarningSettings  `json:"suggested_earnings,omitempty"` // set once benchmarked
+	Earnings          *EarningSettings  `json:"earnings,omitempty"`
+	RecommendedPack   string            `json:"recommended_pack,omitempty"`
+	Pack              string            `json:"pack,omitempty"` // "" for a custom selection
+	Models            []string          `json:"models,omitempty"`
+	FederationID      string            `json:"federation_id,omitempty"`
+	FederationSkipped bool              `json:"federation_skipped,omitempty"`
+	StartedAt         time.Time         `json:"started_at"`
+	UpdatedAt         time.Time         `json:"updated_at"`
+	CompletedAt       time.Time         `json:"completed_at,omitempty"`
+}
+
+// Done reports whether onboarding is finished.
+func (s State) Done() bool { return s.Step == StepDone }
+
+// ─── Wizard ─────────────────────────────────────────────────────────────────
+
+// Hooks connect the wizard to the node. Each is required for its step.
+type Hooks struct {
+	Detect         func() passive.Hardware
+	Benchmark      func(ctx context.Context) (passive.Profile, error)
+	ApplyEarnings  func(resource.IdlePolicy) error
+	InstallModels  func(models []string) error // start pulling; need not wait
+	JoinFederation func(inviteCode string) (fedID string, err error)
+}
+
+// Config configures the wizard.
+type Config struct {
+	Path string // state file ("" = not persisted)
+	Now  func() time.Time
+}
+
+// Wizard drives onboarding. Thread-safe; steps run one at a time.
+type Wizard struct {
+	mu     sync.Mutex // guards state
+	stepMu sync.Mutex // serializes steps, which may run slow hooks
+	config Config
+	hooks  Hooks
+	state  State
+}
+
+// New creates a wizard, resuming the progress saved at cfg.Path.
+func New(cfg Config, hooks Hooks) (*Wizard, error) {
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+	w := &Wizard{config: cfg, hooks: hooks}
+	w.state = w.freshState()
+	if cfg.Path == "" {
+		return w, nil
+	}
+	data, err := os.ReadFile(cfg.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return w, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("onboarding: read state: %w", err)
+	}
+	if err := json.Unmarshal(data, &w.state); err != nil {
+		return nil, fmt.Errorf("onboarding: decode state: %w", err)
+	}
+	return w, nil
+}
+
+func (w *Wizard) freshState() State {
+	now := w.config.Now()
+	return State{Step: StepHardware, Completed: []Step{}, StartedAt: now, UpdatedAt: now}
+}
+
+// State returns the current progress.
+func (w *Wizard) State() State {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.copyLocked()
+}
+
+func (w *Wizard) copyLocked() State {
+	s := w.state
+	s.Completed = append([]Step{}, s.Completed...)
+	s.Models = append([]string(nil), s.Models...)
+	return s
+}
+
+// Reset discards all progress.
+func (w *Wizard) Reset() (State, error) {
+	w.stepMu.Lock()
+	defer w.stepMu.Unlock()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.state = w.freshState()
+	return w.copyLocked(), w.saveLocked()
+}
+
+// DetectHardware runs the hardware step.
+func (w *Wizard) DetectHardware() (State, error) {
+	return w.run(StepHardware, func() (func(*State), error) {
+		if w.hooks.Detect == nil {
+			return nil, hookMissing(StepHardware)
+		}
+		hw := w.hooks.Detect()
+		return func(s *State) { s.Hardware = &hw }, nil
+	})
+}
+
+// RunBenchmark runs the benchmark step and derives the suggested earning
+// settings and starter pack from the measured tier.
+func (w *Wizard) RunBenchmark(ctx context.Context) (State, error) {
+	return w.run(StepBenchmark, func() (func(*State), error) {
+		if w.hooks.Benchmark == nil {
+			return nil, hookMissing(StepBenchmark)
+		}
+		p, err := w.hooks.Benchmark(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("onboarding: benchmark: %w", err)
+		}
+		return func(s *State) {
+			s.Profile = &p
+			s.Hardware = &p.Hardware
+			suggested := SuggestEarnings(p.Tier)
+			s.SuggestedEarnings = &suggested
+			s.RecommendedPack = RecommendPack(p.Tier).ID
+		}, nil
+	})
+}
+
+// SetEarnings runs the earnings step. A nil policy accepts the suggestion.
+func (w *Wizard) SetEarnings(policy *resource.IdlePolicy) (State, error) {
+	return w.run(StepEarnings, func() (func(*State), error) {
+		if w.hooks.ApplyEarnings == nil {
+			return nil, hookMissing(StepEarnings)
+		}
+		w.mu.Lock()
+		suggested, tier := w.state.SuggestedEarnings, w.tierLocked()
+		w.mu.Unlock()
+
+		settings := EarningSettings{EstimatedHourlyCredits: passive.EstimatedHourlyCredits(tier, 1)}
+		switch {
+		case policy != nil:
+			settings.IdlePolicy = *policy
+		case suggested != nil:
+			settings = *suggested
+		default:
+			settings = SuggestEarnings(tier)
+		}
+		if err := settings.IdlePolicy.Validate(); err != nil {
+			return nil, err
+		}
+		if err := w.hooks.ApplyEarnings(settings.IdlePolicy); err != nil {
+			return nil, fmt.Errorf("onboarding: apply earning settings: %w", err)
+		}
+		return func(s *State) { s.Earnings = &settings }, nil
+	})
+}
+
+// SelectModels runs the models step with a starter pack, or with an
+// explicit list of catalog models when pack is empty.
+func (w *Wizard) SelectModels(pack string, models []string) (State, error) {
+	return w.run(StepModels, func() (func(*State), error) {
+		if w.hooks.InstallModels == nil {
+			return nil, hookMissing(StepModels)
+		}
+		if pack != "" {
+			p, ok := LookupPack(pack)
+			if !ok {
+				return nil, fmt.Errorf("%w: %s", domain.ErrStarterPackNotFound, pack)
+			}
+			models = p.Models
+		}
+		if len(models) == 0 {
+			return nil, fmt.Errorf("%w: choose a starter pack or at least one model", domain.ErrOnboardingInput)
+		}
+		for _, m := range models {
+			if catalog.Lookup(m) == nil {
+				return nil, fmt.Errorf("%w: %s is not in the catalog", domain.ErrModelNotFound, m)
+			}
+		}
+		if err := w.hooks.InstallModels(models); err != nil {
+			return nil, fmt.Errorf("onboarding: install models: %w", err)
+		}
+		models = append([]string(nil), models...)
+		return func(s *State) { s.Pack, s.Models = pack, models }, nil
+	})
+}
+
+// JoinFederation runs the federation step by redeeming an invite code.
+func (w *Wizard) JoinFederation(inviteCode string) (State, error) {
+	return w.run(StepFederation, func() (func(*State), error) {
+		if w.hooks.JoinFederation == nil {
+			return nil, hookMissing(StepFederation)
+		}
+		if inviteCode == "" {
+			return nil, fmt.Errorf("%w: invite code is required", domain.ErrOnboardingInput)
+		}
+		fedID, err := w.hooks.JoinFederation(inviteCode)
+		if err != nil {
+			return nil, err
+		}
+		return func(s *State) { s.FederationID, s.FederationSkipped = fedID, false }, nil
+	})
+}
+
+// SkipFederation completes the federation step without joining one.
+func (w *Wizard) SkipFederation() (State, error) {
+	return w.run(StepFederation, func() (func(*State), error) {
+		return func(s *State) { s.FederationSkipped = true }, nil
+	})
+}
+
+// run executes one step: it checks the step has been reached, runs the
+// step's work without holding the state lock, then applies the result and
+// advances past the step.
+func (w *Wizard) run(step Step, work func() (func(*State), error)) (State, error) {
+	w.stepMu.Lock()
+	defer w.stepMu.Unlock()
+
+	w.mu.Lock()
+	current := w.state.Step
+	w.mu.Unlock()
+	if step.index() > current.index() {
+		return w.State(), fmt.Errorf("%w: %s comes after %s", domain.ErrOnboardingStep, step, current)
+	}
+
+	apply, err := work()
+	if err != nil {
+		return w.State(), err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	apply(&w.state)
+	now := w.config.Now()
+	w.state.UpdatedAt = now
+	if !w.completedLocked(step) {
+		w.state.Completed = append(w.state.Completed, step)
+	}
+	if step == current {
+		next := StepDone
+		if i := step.index() + 1; i < len(Steps) {
+			next = Steps[i]
+		}
+		w.state.Step = next
+		if next == StepDone {
+			w.state.CompletedAt = now
+		}
+	}
+	return w.copyLocked(), w.saveLocked()
+}
+
+func (w *Wizard) completedLocked(step Step) bool {
+	for _, s := range w.state.Completed {
+		if s == step {
+			return true
+		}
+	}
+	return false
+}
+
+// tierLocked returns the benchmarked tier, or the tier the detected
+// hardware implies. Caller must hold w.mu.
+func (w *Wizard) tierLocked() passive.HardwareTier {
+	switch {
+	case w.state.Profile != nil:
+		return w.state.Profile.Tier
+	case w.state.Hardware != nil:
+		return passive.ClassifyHardware(w.state.Hardware.CPUCores, w.state.Hardware.VRAMGB())
+	default:
+		return passive.TierBasic
+	}
+}
+
+// saveLocked persists the state. Caller must hold w.mu.
+func (w *Wizard) saveLocked() error {
+	if w.config.Path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(w.state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("onboarding: encode state: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(w.config.Path), 0o755); err != nil {
+		return fmt.Errorf("onboarding: save state: %w", err)
+	}
+	if err := os.WriteFile(w.config.Path+".tmp", data, 0o644); err != nil {
+		return fmt.Errorf("onboarding: save state: %w", err)
+	}
+	return os.Rename(w.config.Path+".tmp", w.config.Path)
+}
+
+func hookMissing(step Step) error {
+	return fmt.Errorf("%w: %s", domain.ErrOnboardingDisabled, step)
+}
+
+// ─── Suggestions ────────────────────────────────────────────────────────────
+
+// SuggestEarnings proposes an idle compute policy for a hardware tier.
+// CPU-only machines are usually laptops, so they work only while the user
+// is well away and plugged in; the bigger the GPU, the more likely the
+// machine is a dedicated box that can work whenever it is not busy.
+func SuggestEarnings(tier passive.HardwareTier) EarningSettings {
+	p := resource.DefaultIdlePolicy()
+	p.Enabled = true
+	switch tier {
+	case passive.TierBasic:
+		p.MinIdle = domain.IdleDeep.String()
+		p.MaxCPUPercent = 20
+		p.RequireACPower = true
+	case passive.TierMid:
+		// DefaultIdlePolicy: light idle, 30% CPU/GPU, 50% battery
+	case passive.TierHigh:
+		p.MaxCPUPercent = 50
+		p.MaxGPUPercent = 20
+		p.RequireACPower = true
+	case passive.TierUltra:
+		p.MinIdle = domain.IdleActive.String()
+		p.MaxCPUPercent = 0
+		p.MaxGPUPercent = 50
+		p.RequireACPower = true
+	}
+	return EarningSettings{IdlePolicy: p, EstimatedHourlyCredits: passive.EstimatedHourlyCredits(tier, 1)}
+}
+
+// StarterPack is a curated set of catalog models for a first install.
+type StarterPack struct {
+	ID          string               `json:"id"`
+	Name        string               `json:"name"`
+	Description string               `json:"description"`
+	Models      []string             `json:"models"`
+	MinTier     passive.HardwareTier `json:"min_tier"` // smallest tier that runs the pack comfortably
+	SizeBytes   int64                `json:"size_bytes"`
+}
+
+// Packs are the starter packs, smallest first.
+var Packs = []StarterPack{
+	{
+		ID:          "lite",
+		Name:        "Lite",
+		Description: "Small, fast models that run well on any CPU",
+		Models:      []string{"smollm2", "tinyllama", "qwen2.5"},
+		MinTier:     passive.TierBasic,
+	},
+	{
+		ID:          "standard",
+		Name:        "Standard",
+		Description: "Capable everyday chat models for a basic GPU",
+		Models:      []string{"llama3", "gemma2", "phi3"},
+		MinTier:     passive.TierMid,
+	},
+	{
+		ID:          "power",
+		Name:        "Power",
+		Description: "7–8B models that earn the most on a high-end GPU",
+		Models:      []string{"llama3:8b", "mistral", "phi3"},
+		MinTier:     passive.TierHigh,
+	},
+}
+
+func init() {
+	for i := range Packs {
+		for _, m := range Packs[i].Models {
+			if e := catalog.Lookup(m); e != nil {
+				Packs[i].SizeBytes += e.SizeBytes
+			}
+		}
+	}
+}
+
+// LookupPack returns the starter pack with the given ID.
+func LookupPack(id string) (StarterPack, bool) {
+	for _, p := range Packs {
+		if p.ID == id {
+			return p, true
+		}
+	}
+	return StarterPack{}, false
+}
+
+// RecommendPack returns the largest pack the tier runs comfortably.
+func RecommendPack(tier passive.HardwareTier) StarterPack {
+	best := Packs[0]
+	for _, p := range Packs {
+		if p.MinTier <= tier {
+			best = p
+		}
+	}
+	return best
+}
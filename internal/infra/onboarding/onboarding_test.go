@@ -0,0 +1,160 @@
+package onboarding
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/tutu-network/tutu/internal/domain"
+	"github.com/tutu-network/tutu/internal/infra/catalog"
+	"github.com/tutu-network/tutu/internal/infra/passive"
+	"github.com/tutu-network/tutu/internal/infra/resource"
+)
+
+// fakeNode records what the wizard asked the node to do.
+type fakeNode struct {
+	policy    *resource.IdlePolicy
+	installed []string
+	joined    string
+}
+
+func (f *fakeNode) hooks() Hooks {
+	gpu := passive.Hardware{CPUCores: 16, RAMBytes: 64 << 30, GPUs: []passive.GPU{{Name: "RTX", VRAMBytes: 16 << 30}}}
+	return Hooks{
+		Detect: func() passive.Hardware { return gpu },
+		Benchmark: func(context.Context) (passive.Profile, error) {
+			return passive.Profile{Hardware: gpu, BenchmarkTPS: 4000, Tier: passive.TierHigh, TierName: "high"}, nil
+		},
+		ApplyEarnings: func(p resource.IdlePolicy) error { f.policy = &p; return nil },
+		InstallModels: func(m []string) error { f.installed = m; return nil },
+		JoinFederation: func(code string) (string, error) {
+			if code != "GOOD-CODE" {
+				return "", domain.ErrInviteInvalid
+			}
+			f.joined = code
+			return "fed-acme", nil
+		},
+	}
+}
+
+func TestWizard_FullFlow(t *testing.T) {
+	node := &fakeNode{}
+	path := filepath.Join(t.TempDir(), "onboarding.json")
+	w, err := New(Config{Path: path}, node.hooks())
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	// Steps cannot be skipped ahead
+	if _, err := w.SelectModels("lite", nil); !errors.Is(err, domain.ErrOnboardingStep) {
+		t.Errorf("models before hardware: err = %v, want ErrOnboardingStep", err)
+	}
+
+	if st, err := w.DetectHardware(); err != nil || st.Step != StepBenchmark || st.Hardware.CPUCores != 16 {
+		t.Fatalf("DetectHardware = %+v, %v", st, err)
+	}
+	st, err := w.RunBenchmark(context.Background())
+	if err != nil {
+		t.Fatalf("RunBenchmark: %v", err)
+	}
+	if st.RecommendedPack != "power" || st.SuggestedEarnings == nil || st.SuggestedEarnings.EstimatedHourlyCredits != 40 {
+		t.Errorf("after benchmark = %+v", st)
+	}
+
+	if _, err := w.SetEarnings(nil); err != nil {
+		t.Fatalf("SetEarnings: %v", err)
+	}
+	if node.policy == nil || !node.policy.Enabled || node.policy.MaxCPUPercent != 50 {
+		t.Errorf("applied policy = %+v, want the high-tier suggestion", node.policy)
+	}
+
+	if _, err := w.SelectModels("huge", nil); !errors.Is(err, domain.ErrStarterPackNotFound) {
+		t.Errorf("unknown pack: err = %v", err)
+	}
+	if _, err := w.SelectModels("", []string{"not-a-model"}); !errors.Is(err, domain.ErrModelNotFound) {
+		t.Errorf("unknown model: err = %v", err)
+	}
+	if st, err = w.SelectModels("power", nil); err != nil || st.Step != StepFederation {
+		t.Fatalf("SelectModels = %+v, %v", st, err)
+	}
+	if len(node.installed) != 3 || node.installed[0] != "llama3:8b" {
+		t.Errorf("installed = %v", node.installed)
+	}
+
+	if _, err := w.JoinFederation("BAD"); !errors.Is(err, domain.ErrInviteInvalid) {
+		t.Errorf("bad invite: err = %v", err)
+	}
+	st, err = w.JoinFederation("GOOD-CODE")
+	if err != nil || !st.Done() || st.FederationID != "fed-acme" || st.CompletedAt.IsZero() {
+		t.Fatalf("JoinFederation = %+v, %v", st, err)
+	}
+
+	// Progress survives a restart, and earlier steps can be redone
+	w2, err := New(Config{Path: path}, node.hooks())
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	if st := w2.State(); !st.Done() || len(st.Completed) != len(Steps) || st.Pack != "power" {
+		t.Errorf("reopened state = %+v", st)
+	}
+	custom := resource.DefaultIdlePolicy()
+	if st, err := w2.SetEarnings(&custom); err != nil || !st.Done() || st.Earnings.IdlePolicy.Enabled {
+		t.Errorf("redo earnings = %+v, %v", st, err)
+	}
+
+	if st, _ := w2.Reset(); st.Step != StepHardware || len(st.Completed) != 0 || st.Profile != nil {
+		t.Errorf("after Reset = %+v", st)
+	}
+}
+
+func TestWizard_SkipFederationAndMissingHooks(t *testing.T) {
+	node := &fakeNode{}
+	hooks := node.hooks()
+	hooks.Benchmark = nil
+	w, _ := New(Config{}, hooks)
+	w.DetectHardware()
+	if _, err := w.RunBenchmark(context.Background()); !errors.Is(err, domain.ErrOnboardingDisabled) {
+		t.Fatalf("no benchmark hook: err = %v", err)
+	}
+
+	w, _ = New(Config{}, node.hooks())
+	w.DetectHardware()
+	w.RunBenchmark(context.Background())
+	bad := resource.IdlePolicy{MaxCPUPercent: 300}
+	if _, err := w.SetEarnings(&bad); !errors.Is(err, domain.ErrInvalidIdlePolicy) {
+		t.Errorf("invalid policy: err = %v", err)
+	}
+	w.SetEarnings(nil)
+	w.SelectModels("", []string{"tinyllama"})
+	st, err := w.SkipFederation()
+	if err != nil || !st.Done() || !st.FederationSkipped || st.Pack != "" {
+		t.Errorf("SkipFederation = %+v, %v", st, err)
+	}
+}
+
+func TestPacks(t *testing.T) {
+	for _, p := range Packs {
+		for _, m := range p.Models {
+			if catalog.Lookup(m) == nil {
+				t.Errorf("pack %s: %s is not in the catalog", p.ID, m)
+			}
+		}
+		if p.SizeBytes == 0 {
+			t.Errorf("pack %s has no size", p.ID)
+		}
+	}
+	for tier, want := range map[passive.HardwareTier]string{
+		passive.TierBasic: "lite", passive.TierMid: "standard",
+		passive.TierHigh: "power", passive.TierUltra: "power",
+	} {
+		if got := RecommendPack(tier).ID; got != want {
+			t.Errorf("RecommendPack(%s) = %s, want %s", tier, got, want)
+		}
+	}
+	for tier := passive.TierBasic; tier <= passive.TierUltra; tier++ {
+		if err := SuggestEarnings(tier).IdlePolicy.Validate(); err != nil {
+			t.Errorf("SuggestEarnings(%s) invalid: %v", tier, err)
+		}
+	}
+}
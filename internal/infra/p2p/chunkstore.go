@@ -0,0 +1,533 @@
+package p2p
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/bits"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ─── Content-Defined Chunking ───────────────────────────────────────────────
+// Fixed-size chunks (SplitIntoChunks) stop matching as soon as bytes are
+// inserted, so two fine-tunes of one base model share almost nothing. A
+// gear rolling hash instead cuts where the content says so:
+//
+//	h = (h << 1) + gear[b]      cut when h & mask == 0 (and size ≥ MinSize)
+//
+// Boundaries depend only on nearby bytes, so shared base layers produce the
+// same chunks in every model that contains them and an edit only disturbs
+// the chunks around it. The result is an ordinary ChunkManifest with
+// variable chunk sizes, so Downloader and the swarm work unchanged.
+
+// ChunkerConfig bounds content-defined chunk sizes.
+type ChunkerConfig struct {
+	MinSize int // no cut before this many bytes
+	AvgSize int // target average; must be a power of two
+	MaxSize int // forced cut at this size
+}
+
+// DefaultChunkerConfig targets DefaultChunkSize chunks on average.
+func DefaultChunkerConfig() ChunkerConfig {
+	return ChunkerConfig{
+		MinSize: MinChunkSize * 4,
+		AvgSize: DefaultChunkSize,
+		MaxSize: MaxChunkSize,
+	}
+}
+
+func (c ChunkerConfig) validate() error {
+	if c.AvgSize <= 0 || c.AvgSize&(c.AvgSize-1) != 0 {
+		return fmt.Errorf("chunker: average size %d is not a power of two", c.AvgSize)
+	}
+	if c.MinSize <= 0 || c.MinSize > c.AvgSize || c.AvgSize > c.MaxSize {
+		return fmt.Errorf("chunker: want 0 < min ≤ avg ≤ max, got %d/%d/%d", c.MinSize, c.AvgSize, c.MaxSize)
+	}
+	return nil
+}
+
+// gearTable holds 256 pseudo-random values derived from a fixed seed with
+// splitmix64. It must never change: chunk boundaries (and therefore every
+// published digest) depend on it.
+var gearTable = func() [256]uint64 {
+	var t [256]uint64
+	x := uint64(0x7475747563646331) // "tutucdc1"
+	for i := range t {
+		x += 0x9E3779B97F4A7C15
+		z := x
+		z = (z ^ (z >> 30)) * 0xBF58476D1CE4E5B9
+		z = (z ^ (z >> 27)) * 0x94D049BB133111EB
+		t[i] = z ^ (z >> 31)
+	}
+	return t
+}()
+
+// Chunker splits a stream into content-defined chunks.
+type Chunker struct {
+	r    *bufio.Reader
+	cfg  ChunkerConfig
+	mask uint64
+	buf  []byte
+}
+
+// NewChunker creates a chunker over r.
+func NewChunker(r io.Reader, cfg ChunkerConfig) (*Chunker, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	// The top bits of a gear hash mix the most input, so test those.
+	maskBits := bits.TrailingZeros(uint(cfg.AvgSize))
+	return &Chunker{
+		r:    bufio.NewReaderSize(r, 1<<20),
+		cfg:  cfg,
+		mask: ^uint64(0) << (64 - maskBits),
+		buf:  make([]byte, 0, cfg.MaxSize),
+	}, nil
+}
+
+// Next returns the next chunk. The slice is reused by the following call.
+// Returns io.EOF after the last chunk.
+func (c *Chunker) Next() ([]byte, error) {
+	c.buf = c.buf[:0]
+	var h uint64
+	for {
+		b, err := c.r.ReadByte()
+		if err == io.EOF {
+			if len(c.buf) == 0 {
+				return nil, io.EOF
+			}
+			return c.buf, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		c.buf = append(c.buf, b)
+		h = (h << 1) + gearTable[b]
+		n := len(c.buf)
+		if n >= c.cfg.MaxSize || (n >= c.cfg.MinSize && h&c.mask == 0) {
+			return c.buf, nil
+		}
+	}
+}
+
+// ─── Chunk Store ────────────────────────────────────────────────────────────
+// ChunkStore keeps each distinct chunk once on disk, named by its SHA-256:
+//
+//	<dir>/chunks/<first 2 hex>/<digest>
+//	<dir>/manifests/<name>.json
+//
+// Manifests reference chunks; GC removes chunks no manifest references.
+
+// ChunkStore is a content-addressed chunk store. Thread-safe.
+type ChunkStore struct {
+	mu  sync.Mutex
+	dir string
+	cfg ChunkerConfig
+	now func() time.Time
+}
+
+// IngestStats reports deduplication for one ingested artifact.
+type IngestStats struct {
+	Chunks     int   `json:"chunks"`
+	NewChunks  int   `json:"new_chunks"`
+	NewBytes   int64 `json:"new_bytes"`   // bytes actually written
+	DedupBytes int64 `json:"dedup_bytes"` // bytes already present
+}
+
+// GCStats reports a garbage collection pass.
+type GCStats struct {
+	Kept       int   `json:"kept"`
+	Removed    int   `json:"removed"`
+	FreedBytes int64 `json:"freed_bytes"`
+}
+
+// StoreStats summarizes the store.
+type StoreStats struct {
+	Manifests     int     `json:"manifests"`
+	Chunks        int     `json:"chunks"`
+	LogicalBytes  int64   `json:"logical_bytes"`  // sum of all artifact sizes
+	PhysicalBytes int64   `json:"physical_bytes"` // bytes of distinct chunks on disk
+	DedupRatio    float64 `json:"dedup_ratio"`    // logical / physical
+}
+
+// NewChunkStore opens (creating if needed) a chunk store rooted at dir.
+func NewChunkStore(dir string, cfg ChunkerConfig) (*ChunkStore, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	for _, sub := range []string{"chunks", "manifests"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o755); err != nil {
+			return nil, fmt.Errorf("create chunk store: %w", err)
+		}
+	}
+	return &ChunkStore{dir: dir, cfg: cfg, now: time.Now}, nil
+}
+
+// ChunkPath returns where a chunk lives on disk.
+func (s *ChunkStore) ChunkPath(d ChunkDigest) string {
+	name := string(d)
+	prefix := "00"
+	if len(name) >= 2 {
+		prefix = name[:2]
+	}
+	return filepath.Join(s.dir, "chunks", prefix, name)
+}
+
+func (s *ChunkStore) manifestPath(name string) string {
+	safe := strings.NewReplacer("/", "_", ":", "_", "\\", "_").Replace(name)
+	return filepath.Join(s.dir, "manifests", safe+".json")
+}
+
+// Has reports whether the chunk is stored.
+func (s *ChunkStore) Has(d ChunkDigest) bool {
+	_, err := os.Stat(s.ChunkPath(d))
+	return err == nil
+}
+
+// Get reads and verifies a chunk.
+func (s *ChunkStore) Get(d ChunkDigest) ([]byte, error) {
+	data, err := os.ReadFile(s.ChunkPath(d))
+	if err != nil {
+		return nil, fmt.Errorf("read chunk %s: %w", d, err)
+	}
+	if err := VerifyChunk(data, d); err != nil {
+		return nil, fmt.Errorf("chunk %s: %w", d, err)
+	}
+	return data, nil
+}
+
+// Put stores a chunk, returning its digest and whether it was already present.
+func (s *ChunkStore) Put(data []byte) (ChunkDigest, bool, error) {
+	sum := sha256.Sum256(data)
+	d := ChunkDigest(encodeHex(sum[:]))
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	existed, err := s.putLocked(d, data)
+	return d, existed, err
+}
+
+func (s *ChunkStore) putLocked(d ChunkDigest, data []byte) (bool, error) {
+	path := s.ChunkPath(d)
+	if _, err := os.Stat(path); err == nil {
+		return true, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return false, fmt.Errorf("create chunk dir: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return false, fmt.Errorf("write chunk %s: %w", d, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return false, fmt.Errorf("commit chunk %s: %w", d, err)
+	}
+	return false, nil
+}
+
+// Ingest splits r into content-defined chunks, stores new ones and records a
+// manifest under name (replacing any previous manifest of that name).
+func (s *ChunkStore) Ingest(name string, r io.Reader) (*ChunkManifest, IngestStats, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var st IngestStats
+	m, err := chunkManifest(name, r, s.cfg, func(d ChunkDigest, data []byte) error {
+		existed, err := s.putLocked(d, data)
+		if err != nil {
+			return err
+		}
+		if existed {
+			st.DedupBytes += int64(len(data))
+		} else {
+			st.NewChunks++
+			st.NewBytes += int64(len(data))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, IngestStats{}, err
+	}
+	m.CreatedAt = s.now()
+	st.Chunks = len(m.Chunks)
+
+	if err := s.saveManifestLocked(m); err != nil {
+		return nil, IngestStats{}, err
+	}
+	return m, st, nil
+}
+
+// BuildManifest describes r as content-defined chunks without storing them,
+// for serving a file that already exists on disk.
+func BuildManifest(name string, r io.Reader, cfg ChunkerConfig) (*ChunkManifest, error) {
+	m, err := chunkManifest(name, r, cfg, nil)
+	if err != nil {
+		return nil, err
+	}
+	m.CreatedAt = time.Now()
+	return m, nil
+}
+
+// chunkManifest splits r and builds its manifest, handing every chunk to
+// sink (if set) as it goes.
+func chunkManifest(name string, r io.Reader, cfg ChunkerConfig, sink func(d ChunkDigest, data []byte) error) (*ChunkManifest, error) {
+	ch, err := NewChunker(r, cfg)
+	if err != nil {
+		return nil, err
+	}
+	m := &ChunkManifest{ModelName: name, ChunkSize: cfg.AvgSize}
+	whole := sha256.New()
+	for {
+		data, err := ch.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("chunk %s: %w", name, err)
+		}
+		whole.Write(data)
+		sum := sha256.Sum256(data)
+		d := ChunkDigest(encodeHex(sum[:]))
+		if sink != nil {
+			if err := sink(d, data); err != nil {
+				return nil, err
+			}
+		}
+		m.Chunks = append(m.Chunks, ChunkInfo{
+			Index:  len(m.Chunks),
+			Offset: m.TotalSize,
+			Size:   len(data),
+			Digest: d,
+		})
+		m.TotalSize += int64(len(data))
+	}
+	if len(m.Chunks) == 0 {
+		return nil, fmt.Errorf("%s: empty artifact", name)
+	}
+	m.ModelDigest = encodeHex(whole.Sum(nil))
+	return m, nil
+}
+
+// AddManifest records a manifest obtained elsewhere (e.g. from a peer).
+// Its chunks need not be present yet; see Missing.
+func (s *ChunkStore) AddManifest(m *ChunkManifest) error {
+	if err := m.Validate(); err != nil {
+		return fmt.Errorf("validate manifest: %w", err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.saveManifestLocked(m)
+}
+
+func (s *ChunkStore) saveManifestLocked(m *ChunkManifest) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode manifest: %w", err)
+	}
+	path := s.manifestPath(m.ModelName)
+	if err := os.WriteFile(path+".tmp", data, 0o644); err != nil {
+		return fmt.Errorf("write manifest: %w", err)
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// Manifest loads a manifest by name.
+func (s *ChunkStore) Manifest(name string) (*ChunkManifest, error) {
+	data, err := os.ReadFile(s.manifestPath(name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("manifest %s: %w", name, ErrChunkNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read manifest %s: %w", name, err)
+	}
+	var m ChunkManifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("decode manifest %s: %w", name, err)
+	}
+	return &m, nil
+}
+
+// Manifests lists stored manifest names, sorted.
+func (s *ChunkStore) Manifests() ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ms, err := s.loadManifestsLocked()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(ms))
+	for i, m := range ms {
+		names[i] = m.ModelName
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// RemoveManifest deletes a manifest. Its chunks stay until the next GC.
+func (s *ChunkStore) RemoveManifest(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	err := os.Remove(s.manifestPath(name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// Assemble writes the artifact described by a stored manifest to w,
+// verifying every chunk.
+func (s *ChunkStore) Assemble(name string, w io.Writer) error {
+	m, err := s.Manifest(name)
+	if err != nil {
+		return err
+	}
+	whole := sha256.New()
+	for _, c := range m.Chunks {
+		data, err := s.Get(c.Digest)
+		if err != nil {
+			return err
+		}
+		whole.Write(data)
+		if _, err := w.Write(data); err != nil {
+			return fmt.Errorf("write %s: %w", name, err)
+		}
+	}
+	if got := encodeHex(whole.Sum(nil)); got != m.ModelDigest {
+		return fmt.Errorf("%w: %s digest %s, want %s", ErrChunkCorrupted, name, got, m.ModelDigest)
+	}
+	return nil
+}
+
+// Missing returns the indices of m's chunks not present in the store — the
+// only chunks a delta update has to download.
+func (s *ChunkStore) Missing(m *ChunkManifest) []int {
+	var out []int
+	seen := make(map[ChunkDigest]bool)
+	for _, c := range m.Chunks {
+		if seen[c.Digest] {
+			continue
+		}
+		seen[c.Digest] = true
+		if !s.Has(c.Digest) {
+			out = append(out, c.Index)
+		}
+	}
+	return out
+}
+
+// Delta returns the chunks of next that old does not contain.
+func Delta(old, next *ChunkManifest) []ChunkInfo {
+	have := make(map[ChunkDigest]bool, len(old.Chunks))
+	for _, c := range old.Chunks {
+		have[c.Digest] = true
+	}
+	var out []ChunkInfo
+	for _, c := range next.Chunks {
+		if !have[c.Digest] {
+			have[c.Digest] = true
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// GC removes every chunk not referenced by a stored manifest (mark & sweep).
+func (s *ChunkStore) GC() (GCStats, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ms, err := s.loadManifestsLocked()
+	if err != nil {
+		return GCStats{}, err
+	}
+	live := make(map[string]bool)
+	for _, m := range ms {
+		for _, c := range m.Chunks {
+			live[string(c.Digest)] = true
+		}
+	}
+
+	var st GCStats
+	err = filepath.WalkDir(filepath.Join(s.dir, "chunks"), func(path string, e os.DirEntry, err error) error {
+		if err != nil || e.IsDir() {
+			return err
+		}
+		if live[e.Name()] {
+			st.Kept++
+			return nil
+		}
+		info, err := e.Info()
+		if err != nil {
+			return err
+		}
+		if err := os.Remove(path); err != nil {
+			return fmt.Errorf("remove chunk: %w", err)
+		}
+		st.Removed++
+		st.FreedBytes += info.Size()
+		return nil
+	})
+	if err != nil {
+		return st, fmt.Errorf("gc: %w", err)
+	}
+	return st, nil
+}
+
+// Stats reports logical vs physical size across all manifests.
+func (s *ChunkStore) Stats() (StoreStats, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ms, err := s.loadManifestsLocked()
+	if err != nil {
+		return StoreStats{}, err
+	}
+	st := StoreStats{Manifests: len(ms)}
+	distinct := make(map[ChunkDigest]int)
+	for _, m := range ms {
+		st.LogicalBytes += m.TotalSize
+		for _, c := range m.Chunks {
+			distinct[c.Digest] = c.Size
+		}
+	}
+	for d, size := range distinct {
+		if s.Has(d) {
+			st.Chunks++
+			st.PhysicalBytes += int64(size)
+		}
+	}
+	if st.PhysicalBytes > 0 {
+		st.DedupRatio = float64(st.LogicalBytes) / float64(st.PhysicalBytes)
+	}
+	return st, nil
+}
+
+func (s *ChunkStore) loadManifestsLocked() ([]*ChunkManifest, error) {
+	entries, err := os.ReadDir(filepath.Join(s.dir, "manifests"))
+	if err != nil {
+		return nil, fmt.Errorf("list manifests: %w", err)
+	}
+	var out []*ChunkManifest
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(s.dir, "manifests", e.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("read manifest %s: %w", e.Name(), err)
+		}
+		var m ChunkManifest
+		if err := json.Unmarshal(data, &m); err != nil {
+			return nil, fmt.Errorf("decode manifest %s: %w", e.Name(), err)
+		}
+		out = append(out, &m)
+	}
+	return out, nil
+}
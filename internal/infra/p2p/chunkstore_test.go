@@ -0,0 +1,245 @@
+package p2p
+
+import (
+	"bytes"
+	"context"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testChunkerConfig keeps chunks small so tests stay fast.
+func testChunkerConfig() ChunkerConfig {
+	return ChunkerConfig{MinSize: 16 * 1024, AvgSize: MinChunkSize, MaxSize: 1024 * 1024}
+}
+
+func randomBytes(seed int64, n int) []byte {
+	b := make([]byte, n)
+	rand.New(rand.NewSource(seed)).Read(b)
+	return b
+}
+
+func newTestChunkStore(t *testing.T) *ChunkStore {
+	t.Helper()
+	s, err := NewChunkStore(t.TempDir(), testChunkerConfig())
+	if err != nil {
+		t.Fatalf("NewChunkStore: %v", err)
+	}
+	return s
+}
+
+// ─── Chunker Tests ──────────────────────────────────────────────────────────
+
+func TestChunker_BoundsAndDeterminism(t *testing.T) {
+	cfg := testChunkerConfig()
+	data := randomBytes(1, 8*1024*1024)
+
+	split := func() []int {
+		ch, err := NewChunker(bytes.NewReader(data), cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var sizes []int
+		for {
+			c, err := ch.Next()
+			if err != nil {
+				break
+			}
+			sizes = append(sizes, len(c))
+		}
+		return sizes
+	}
+
+	a, b := split(), split()
+	if len(a) != len(b) {
+		t.Fatal("chunking is not deterministic")
+	}
+	total := 0
+	for i, n := range a {
+		if n != b[i] {
+			t.Fatalf("chunk %d size differs between runs", i)
+		}
+		if n > cfg.MaxSize || (n < cfg.MinSize && i != len(a)-1) {
+			t.Errorf("chunk %d size %d outside [%d, %d]", i, n, cfg.MinSize, cfg.MaxSize)
+		}
+		total += n
+	}
+	if total != len(data) {
+		t.Errorf("chunks cover %d bytes, want %d", total, len(data))
+	}
+	avg := total / len(a)
+	if avg < cfg.AvgSize/2 || avg > cfg.AvgSize*2 {
+		t.Errorf("average chunk %d far from target %d", avg, cfg.AvgSize)
+	}
+}
+
+func TestNewChunker_InvalidConfig(t *testing.T) {
+	tests := []ChunkerConfig{
+		{MinSize: 1024, AvgSize: 3000, MaxSize: 8192},
+		{MinSize: 8192, AvgSize: 4096, MaxSize: 16384},
+		{MinSize: 1024, AvgSize: 8192, MaxSize: 4096},
+	}
+	for _, cfg := range tests {
+		if _, err := NewChunker(bytes.NewReader(nil), cfg); err == nil {
+			t.Errorf("config %+v accepted", cfg)
+		}
+	}
+}
+
+// ─── Chunk Store Tests ──────────────────────────────────────────────────────
+
+func TestChunkStore_Ingest_DeduplicatesSharedBase(t *testing.T) {
+	s := newTestChunkStore(t)
+	base := randomBytes(2, 6*1024*1024)
+	adapterA := append(append([]byte(nil), base...), randomBytes(3, 512*1024)...)
+	// Insert bytes near the front: fixed-size chunks would all shift.
+	adapterB := append(append(randomBytes(4, 1000), base...), randomBytes(5, 512*1024)...)
+
+	_, stBase, err := s.Ingest("base", bytes.NewReader(base))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stBase.DedupBytes != 0 {
+		t.Errorf("first ingest dedup = %d, want 0", stBase.DedupBytes)
+	}
+
+	_, stA, err := s.Ingest("ft-a", bytes.NewReader(adapterA))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, stB, err := s.Ingest("ft-b", bytes.NewReader(adapterB))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, st := range map[string]IngestStats{"ft-a": stA, "ft-b": stB} {
+		if st.NewBytes > 2*1024*1024 {
+			t.Errorf("%s wrote %d new bytes; shared base was not deduplicated", name, st.NewBytes)
+		}
+	}
+
+	stats, err := s.Stats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Manifests != 3 || stats.DedupRatio < 2 {
+		t.Errorf("stats = %+v, want 3 manifests and dedup ratio ≥ 2", stats)
+	}
+}
+
+func TestChunkStore_AssembleRoundTrip(t *testing.T) {
+	s := newTestChunkStore(t)
+	data := randomBytes(6, 3*1024*1024+123)
+	m, _, err := s.Ingest("model", bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Validate(); err != nil {
+		t.Errorf("content-defined manifest invalid: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := s.Assemble("model", &out); err != nil {
+		t.Fatalf("Assemble: %v", err)
+	}
+	if !bytes.Equal(out.Bytes(), data) {
+		t.Error("assembled artifact differs from input")
+	}
+
+	// Corrupt one chunk on disk.
+	os.WriteFile(s.ChunkPath(m.Chunks[0].Digest), []byte("garbage"), 0o644)
+	if err := s.Assemble("model", &bytes.Buffer{}); err == nil {
+		t.Error("Assemble should fail on a corrupt chunk")
+	}
+}
+
+func TestChunkStore_DeltaAndMissing(t *testing.T) {
+	s := newTestChunkStore(t)
+	base := randomBytes(7, 4*1024*1024)
+	v1 := append(append([]byte(nil), base...), randomBytes(8, 256*1024)...)
+	v2 := append(append([]byte(nil), base...), randomBytes(9, 256*1024)...)
+
+	m1, _, _ := s.Ingest("model:v1", bytes.NewReader(v1))
+
+	// v2 is described by a manifest from elsewhere; only the adapter is new.
+	other := newTestChunkStore(t)
+	m2, _, _ := other.Ingest("model:v2", bytes.NewReader(v2))
+
+	delta := Delta(m1, m2)
+	missing := s.Missing(m2)
+	if len(missing) != len(delta) {
+		t.Errorf("Missing = %d chunks, Delta = %d; want equal", len(missing), len(delta))
+	}
+	var deltaBytes int
+	for _, c := range delta {
+		deltaBytes += c.Size
+	}
+	if deltaBytes == 0 || deltaBytes > 2*1024*1024 {
+		t.Errorf("delta = %d bytes, want a small non-zero update", deltaBytes)
+	}
+}
+
+func TestChunkStore_GC(t *testing.T) {
+	s := newTestChunkStore(t)
+	base := randomBytes(10, 2*1024*1024)
+	ft := append(append([]byte(nil), base...), randomBytes(11, 512*1024)...)
+	mBase, _, _ := s.Ingest("base", bytes.NewReader(base))
+	mFT, _, _ := s.Ingest("ft", bytes.NewReader(ft))
+
+	if st, _ := s.GC(); st.Removed != 0 {
+		t.Errorf("GC removed %d referenced chunks", st.Removed)
+	}
+
+	// Only the base's tail chunk (cut at EOF) is not shared with the fine-tune.
+	s.RemoveManifest("base")
+	if st, _ := s.GC(); st.Removed != len(Delta(mFT, mBase)) {
+		t.Errorf("GC removed %d chunks, want %d base-only chunks", st.Removed, len(Delta(mFT, mBase)))
+	}
+	if len(s.Missing(mFT)) != 0 {
+		t.Error("fine-tune lost chunks after GC")
+	}
+
+	s.RemoveManifest("ft")
+	st, err := s.GC()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.Kept != 0 || st.Removed == 0 || st.FreedBytes < int64(len(ft)) {
+		t.Errorf("GC after removing all manifests = %+v", st)
+	}
+	if names, _ := s.Manifests(); len(names) != 0 {
+		t.Errorf("Manifests = %v, want none", names)
+	}
+}
+
+func TestDownloader_Download_UsesLocalChunks(t *testing.T) {
+	local := newTestChunkStore(t)
+	base := randomBytes(12, 3*1024*1024)
+	local.Ingest("base", bytes.NewReader(base))
+
+	remote := newTestChunkStore(t)
+	ft := append(append([]byte(nil), base...), randomBytes(13, 512*1024)...)
+	m, _, _ := remote.Ingest("ft", bytes.NewReader(ft))
+
+	chunks := make([][]byte, len(m.Chunks))
+	for i, c := range m.Chunks {
+		chunks[i], _ = remote.Get(c.Digest)
+	}
+	fetcher := newFakeFetcher(chunks)
+	d := NewDownloader("me", swarmWith(m, "peer"), fetcher, nil, DownloadConfig{Seed: 1, Local: local})
+
+	dest := filepath.Join(t.TempDir(), "ft.gguf")
+	if _, err := d.Download(context.Background(), m, dest); err != nil {
+		t.Fatalf("Download: %v", err)
+	}
+	if want := len(local.Missing(m)); want != 0 {
+		t.Errorf("fetched chunks not added to local store (%d missing)", want)
+	}
+	if len(fetcher.fetched) == 0 || len(fetcher.fetched) >= len(m.Chunks) {
+		t.Errorf("fetched %d of %d chunks; want only the delta", len(fetcher.fetched), len(m.Chunks))
+	}
+	got, _ := os.ReadFile(dest)
+	if !bytes.Equal(got, ft) {
+		t.Error("downloaded artifact differs")
+	}
+}
@@ -41,6 +41,7 @@ var (
 	ErrChunkNotFound     = errors.New("chunk not found on any peer")
 	ErrTransferCancelled = errors.New("transfer cancelled")
 	ErrInvalidChunkSize  = errors.New("chunk size out of allowed range")
+	ErrModelBlocked      = errors.New("model is on the network blocklist")
 )
 
 // ─── Chunk Types ────────────────────────────────────────────────────────────
@@ -0,0 +1,516 @@
+package p2p
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ─── Download Manager ───────────────────────────────────────────────────────
+// Downloader fetches a model described by a ChunkManifest from the swarm:
+//
+//  1. Chunks already recorded as COMPLETED in chunk_transfers are re-hashed
+//     on disk and skipped (resume after restart); chunks present in the
+//     local chunk store are copied instead of fetched (delta updates)
+//  2. Remaining chunks are fetched in parallel, each from a peer picked at
+//     random weighted by reputation × measured throughput
+//  3. Every chunk is SHA-256 verified before it is written; a bad or failed
+//     chunk is retried from a different peer and the source is penalized
+//  4. The assembled file is verified against ModelDigest and renamed into
+//     place
+//
+// Chunks are written into "<dest>.partial" at their manifest offsets, so an
+// interrupted download keeps everything it already verified.
+// Models and peers on the network blocklist are refused outright.
+
+// ChunkFetcher retrieves one chunk from a peer (transport abstraction).
+type ChunkFetcher interface {
+	FetchChunk(ctx context.Context, peer string, manifest *ChunkManifest, index int) ([]byte, error)
+}
+
+// TransferStore persists per-chunk transfer state (sqlite chunk_transfers).
+type TransferStore interface {
+	StartChunkTransfer(manifestID string, chunkIndex int, fromPeer, toPeer string, sizeBytes int64) error
+	CompleteChunkTransfer(manifestID string, chunkIndex int, toPeer string) error
+	FailChunkTransfer(manifestID string, chunkIndex int, toPeer string) error
+	CompletedChunks(manifestID, toPeer string) ([]int, error)
+}
+
+// LocalChunks is a local content-addressed chunk source such as ChunkStore.
+// Chunks found locally are never fetched, so a fine-tune whose base model is
+// already present downloads only its delta.
+type LocalChunks interface {
+	Has(d ChunkDigest) bool
+	Get(d ChunkDigest) ([]byte, error)
+	Put(data []byte) (ChunkDigest, bool, error)
+}
+
+// DownloadConfig tunes the download manager.
+type DownloadConfig struct {
+	Concurrency int                       // parallel chunk fetches
+	MaxAttempts int                       // fetch attempts per chunk (distinct peers)
+	Reputation  func(peer string) float64 // 0..1; nil = every peer neutral (0.5)
+	OnProgress  func(ev DownloadEvent)    // optional progress events for the UI
+	Seed        int64                     // peer selection RNG seed (0 = time-based)
+	Now         func() time.Time          // clock (nil = time.Now)
+	Local       LocalChunks               // optional dedup source and sink
+
+	// Blocklist enforcement (nil = nothing blocked): models whose digest
+	// is blocked are not downloaded, blocked peers are never fetched from.
+	ModelBlocked func(digest string) bool
+	PeerBlocked  func(peer string) bool
+
+	// Network profile (netprobe): Bandwidth is the bytes/sec prior for peers
+	// this downloader has not measured yet (0 = unknown); OnTransfer reports
+	// every verified chunk fetch as a passive bandwidth sample.
+	Bandwidth  func(peer string) float64
+	OnTransfer func(peer string, bytes int, elapsed time.Duration)
+}
+
+// DefaultDownloadConfig returns sensible defaults.
+func DefaultDownloadConfig() DownloadConfig {
+	return DownloadConfig{
+		Concurrency: 4,
+		MaxAttempts: 3,
+	}
+}
+
+// Throughput prior for peers never measured, and smoothing for measurements.
+const (
+	defaultPeerThroughput = 1024 * 1024 // bytes/sec
+	throughputAlpha       = 0.3         // EWMA weight of the newest sample
+	minPeerReputation     = 0.01        // keeps low-reputation peers as a last resort
+)
+
+// DownloadEventType labels a progress event.
+type DownloadEventType string
+
+const (
+	EventResumed     DownloadEventType = "resumed"      // verified chunks found on disk
+	EventChunkDone   DownloadEventType = "chunk_done"   // chunk fetched and verified
+	EventChunkFailed DownloadEventType = "chunk_failed" // one fetch attempt failed
+	EventComplete    DownloadEventType = "complete"     // whole file verified and in place
+	EventFailed      DownloadEventType = "failed"       // download gave up
+)
+
+// DownloadEvent reports download progress.
+type DownloadEvent struct {
+	Type        DownloadEventType `json:"type"`
+	Model       string            `json:"model"`
+	Chunk       int               `json:"chunk"` // -1 for whole-download events
+	Peer        string            `json:"peer,omitempty"`
+	BytesDone   int64             `json:"bytes_done"`
+	TotalBytes  int64             `json:"total_bytes"`
+	Percent     float64           `json:"percent"`
+	BytesPerSec float64           `json:"bytes_per_sec"` // this session's download rate
+	Error       string            `json:"error,omitempty"`
+}
+
+// Downloader runs resumable, verified, multi-peer chunk downloads. Thread-safe.
+type Downloader struct {
+	nodeID  string
+	swarm   *PeerChunkMap
+	fetcher ChunkFetcher
+	store   TransferStore // nil = no resume across restarts
+	cfg     DownloadConfig
+
+	mu         sync.Mutex
+	throughput map[string]float64 // peer → EWMA bytes/sec
+	rng        *rand.Rand
+}
+
+// NewDownloader creates a download manager for nodeID. Non-positive config
+// values fall back to DefaultDownloadConfig.
+func NewDownloader(nodeID string, swarm *PeerChunkMap, fetcher ChunkFetcher, store TransferStore, cfg DownloadConfig) *Downloader {
+	def := DefaultDownloadConfig()
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = def.Concurrency
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = def.MaxAttempts
+	}
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return &Downloader{
+		nodeID:     nodeID,
+		swarm:      swarm,
+		fetcher:    fetcher,
+		store:      store,
+		cfg:        cfg,
+		throughput: make(map[string]float64),
+		rng:        rand.New(rand.NewSource(seed)),
+	}
+}
+
+// PeerThroughput returns the measured throughput of a peer in bytes/sec
+// (0 = never measured).
+func (d *Downloader) PeerThroughput(peer string) float64 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.throughput[peer]
+}
+
+// download is the state of one Download call.
+type download struct {
+	manifest *ChunkManifest
+	progress *TransferProgress
+	file     *os.File
+	started  time.Time
+	fetched  atomic.Int64 // bytes fetched this session (excludes resumed)
+}
+
+// Download fetches manifest's model into dest. It returns the final
+// progress; on error the partial file is kept so a later call resumes.
+func (d *Downloader) Download(ctx context.Context, manifest *ChunkManifest, dest string) (*TransferProgress, error) {
+	if err := manifest.Validate(); err != nil {
+		return nil, fmt.Errorf("validate manifest: %w", err)
+	}
+	if err := manifest.VerifySignature(); err != nil {
+		return nil, err
+	}
+	if d.cfg.ModelBlocked != nil && d.cfg.ModelBlocked(manifest.ModelDigest) {
+		return nil, fmt.Errorf("%w: %s (%s)", ErrModelBlocked, manifest.ModelName, manifest.ModelDigest)
+	}
+
+	partial := dest + ".partial"
+	f, err := os.OpenFile(partial, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("open partial file: %w", err)
+	}
+	defer f.Close()
+	if err := f.Truncate(manifest.TotalSize); err != nil {
+		return nil, fmt.Errorf("size partial file: %w", err)
+	}
+
+	dl := &download{
+		manifest: manifest,
+		progress: NewTransferProgress(manifest),
+		file:     f,
+		started:  d.cfg.Now(),
+	}
+	if err := d.resume(dl); err != nil {
+		return dl.progress, err
+	}
+
+	if err := d.fetchPending(ctx, dl); err != nil {
+		d.emit(dl, DownloadEvent{Type: EventFailed, Chunk: -1, Error: err.Error()})
+		return dl.progress, err
+	}
+
+	if err := f.Sync(); err != nil {
+		return dl.progress, fmt.Errorf("sync partial file: %w", err)
+	}
+	if err := verifyFileDigest(f, manifest.ModelDigest); err != nil {
+		d.emit(dl, DownloadEvent{Type: EventFailed, Chunk: -1, Error: err.Error()})
+		return dl.progress, err
+	}
+	if err := f.Close(); err != nil {
+		return dl.progress, fmt.Errorf("close partial file: %w", err)
+	}
+	if err := os.Rename(partial, dest); err != nil {
+		return dl.progress, fmt.Errorf("move download into place: %w", err)
+	}
+	d.emit(dl, DownloadEvent{Type: EventComplete, Chunk: -1})
+	return dl.progress, nil
+}
+
+// resume marks chunks that the store lists as completed and that still hash
+// correctly on disk, then fills pending chunks from the local chunk store.
+func (d *Downloader) resume(dl *download) error {
+	resumed, err := d.resumeFromStore(dl)
+	if err != nil {
+		return err
+	}
+	if d.cfg.Local != nil {
+		for _, idx := range dl.progress.PendingChunks() {
+			c := dl.manifest.Chunks[idx]
+			if !d.cfg.Local.Has(c.Digest) {
+				continue
+			}
+			data, err := d.cfg.Local.Get(c.Digest)
+			if err != nil {
+				continue // damaged local copy — fetch it instead
+			}
+			if _, err := dl.file.WriteAt(data, c.Offset); err != nil {
+				return fmt.Errorf("write chunk %d: %w", idx, err)
+			}
+			dl.progress.MarkComplete(idx)
+			resumed++
+		}
+	}
+	if resumed > 0 {
+		d.emit(dl, DownloadEvent{Type: EventResumed, Chunk: -1})
+	}
+	return nil
+}
+
+func (d *Downloader) resumeFromStore(dl *download) (int, error) {
+	if d.store == nil {
+		return 0, nil
+	}
+	done, err := d.store.CompletedChunks(dl.manifest.ModelDigest, d.nodeID)
+	if err != nil {
+		return 0, fmt.Errorf("load completed chunks: %w", err)
+	}
+	resumed := 0
+	for _, idx := range done {
+		if idx < 0 || idx >= dl.manifest.ChunkCount() {
+			continue
+		}
+		c := dl.manifest.Chunks[idx]
+		buf := make([]byte, c.Size)
+		if _, err := dl.file.ReadAt(buf, c.Offset); err != nil && !errors.Is(err, io.EOF) {
+			continue
+		}
+		if VerifyChunk(buf, c.Digest) != nil {
+			continue // stale record or damaged file — fetch again
+		}
+		dl.progress.MarkComplete(idx)
+		resumed++
+	}
+	return resumed, nil
+}
+
+// fetchPending downloads every pending chunk with cfg.Concurrency workers.
+func (d *Downloader) fetchPending(ctx context.Context, dl *download) error {
+	pending := dl.progress.PendingChunks()
+	work := make(chan int)
+	errs := make(chan error, len(pending))
+
+	var wg sync.WaitGroup
+	for i := 0; i < d.cfg.Concurrency && i < len(pending); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range work {
+				if err := d.fetchChunk(ctx, dl, idx); err != nil {
+					errs <- err
+				}
+			}
+		}()
+	}
+
+feed:
+	for _, idx := range pending {
+		select {
+		case work <- idx:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(work)
+	wg.Wait()
+	close(errs)
+
+	if ctx.Err() != nil {
+		return fmt.Errorf("%w: %v", ErrTransferCancelled, ctx.Err())
+	}
+	if err := <-errs; err != nil {
+		return err
+	}
+	return nil
+}
+
+// fetchChunk tries up to MaxAttempts distinct peers for one chunk.
+func (d *Downloader) fetchChunk(ctx context.Context, dl *download, idx int) error {
+	c := dl.manifest.Chunks[idx]
+	manifestID := dl.manifest.ModelDigest
+	tried := make(map[string]bool)
+	var lastErr error
+
+	for attempt := 0; attempt < d.cfg.MaxAttempts; attempt++ {
+		peer, ok := d.selectPeer(c.Digest, tried)
+		if !ok {
+			break
+		}
+		tried[peer] = true
+		if d.store != nil {
+			if err := d.store.StartChunkTransfer(manifestID, idx, peer, d.nodeID, int64(c.Size)); err != nil {
+				return fmt.Errorf("record chunk %d start: %w", idx, err)
+			}
+		}
+
+		start := d.cfg.Now()
+		data, err := d.fetcher.FetchChunk(ctx, peer, dl.manifest, idx)
+		if err == nil {
+			err = VerifyChunk(data, c.Digest)
+		}
+		if err == nil {
+			_, err = dl.file.WriteAt(data, c.Offset)
+			if err != nil {
+				return fmt.Errorf("write chunk %d: %w", idx, err)
+			}
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			lastErr = err
+			d.penalize(peer)
+			dl.progress.MarkFailed(idx)
+			if d.store != nil {
+				if serr := d.store.FailChunkTransfer(manifestID, idx, d.nodeID); serr != nil {
+					return fmt.Errorf("record chunk %d failure: %w", idx, serr)
+				}
+			}
+			d.emit(dl, DownloadEvent{Type: EventChunkFailed, Chunk: idx, Peer: peer, Error: err.Error()})
+			continue
+		}
+
+		if d.cfg.Local != nil {
+			if _, _, err := d.cfg.Local.Put(data); err != nil {
+				return fmt.Errorf("store chunk %d locally: %w", idx, err)
+			}
+		}
+		d.observe(peer, len(data), d.cfg.Now().Sub(start))
+		dl.fetched.Add(int64(len(data)))
+		dl.progress.MarkComplete(idx)
+		if d.store != nil {
+			if err := d.store.CompleteChunkTransfer(manifestID, idx, d.nodeID); err != nil {
+				return fmt.Errorf("record chunk %d completion: %w", idx, err)
+			}
+		}
+		d.emit(dl, DownloadEvent{Type: EventChunkDone, Chunk: idx, Peer: peer})
+		return nil
+	}
+
+	if lastErr == nil {
+		lastErr = ErrNoPeersAvailable
+	}
+	return fmt.Errorf("chunk %d: %w", idx, lastErr)
+}
+
+// ─── Peer Selection ─────────────────────────────────────────────────────────
+
+// selectPeer picks an untried, unblocked peer holding digest, weighted by
+// reputation × throughput.
+func (d *Downloader) selectPeer(digest ChunkDigest, tried map[string]bool) (string, bool) {
+	var candidates []string
+	for _, p := range d.swarm.PeersWithChunk(digest) {
+		if !tried[p] && (d.cfg.PeerBlocked == nil || !d.cfg.PeerBlocked(p)) {
+			candidates = append(candidates, p)
+		}
+	}
+	if len(candidates) == 0 {
+		return "", false
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	weights := make([]float64, len(candidates))
+	var total float64
+	for i, p := range candidates {
+		weights[i] = d.reputation(p) * d.throughputLocked(p)
+		total += weights[i]
+	}
+	r := d.rng.Float64() * total
+	for i, w := range weights {
+		if r < w {
+			return candidates[i], true
+		}
+		r -= w
+	}
+	return candidates[len(candidates)-1], true
+}
+
+func (d *Downloader) reputation(peer string) float64 {
+	if d.cfg.Reputation == nil {
+		return 0.5
+	}
+	return max(d.cfg.Reputation(peer), minPeerReputation)
+}
+
+// throughputLocked returns a peer's measured throughput, else its network
+// profile bandwidth, else the mean of measured peers (or
+// defaultPeerThroughput) so new peers get explored.
+func (d *Downloader) throughputLocked(peer string) float64 {
+	if t, ok := d.throughput[peer]; ok {
+		return t
+	}
+	if d.cfg.Bandwidth != nil {
+		if b := d.cfg.Bandwidth(peer); b > 0 {
+			return b
+		}
+	}
+	if len(d.throughput) == 0 {
+		return defaultPeerThroughput
+	}
+	var sum float64
+	for _, t := range d.throughput {
+		sum += t
+	}
+	return sum / float64(len(d.throughput))
+}
+
+// observe folds a successful transfer into the peer's throughput estimate.
+func (d *Downloader) observe(peer string, bytes int, elapsed time.Duration) {
+	if elapsed <= 0 {
+		elapsed = time.Millisecond
+	}
+	if d.cfg.OnTransfer != nil {
+		d.cfg.OnTransfer(peer, bytes, elapsed)
+	}
+	sample := float64(bytes) / elapsed.Seconds()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if prev, ok := d.throughput[peer]; ok {
+		d.throughput[peer] = throughputAlpha*sample + (1-throughputAlpha)*prev
+	} else {
+		d.throughput[peer] = sample
+	}
+}
+
+// penalize halves a peer's throughput estimate after a failed or corrupt chunk.
+func (d *Downloader) penalize(peer string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.throughput[peer] = d.throughputLocked(peer) / 2
+}
+
+// ─── Helpers ────────────────────────────────────────────────────────────────
+
+func (d *Downloader) emit(dl *download, ev DownloadEvent) {
+	if d.cfg.OnProgress == nil {
+		return
+	}
+	dl.progress.mu.Lock()
+	ev.BytesDone = dl.progress.BytesDone
+	dl.progress.mu.Unlock()
+	ev.Model = dl.manifest.ModelName
+	ev.TotalBytes = dl.manifest.TotalSize
+	if ev.TotalBytes > 0 {
+		ev.Percent = float64(ev.BytesDone) / float64(ev.TotalBytes) * 100
+	}
+	if elapsed := d.cfg.Now().Sub(dl.started); elapsed > 0 {
+		ev.BytesPerSec = float64(dl.fetched.Load()) / elapsed.Seconds()
+	}
+	d.cfg.OnProgress(ev)
+}
+
+// verifyFileDigest checks the SHA-256 of the whole assembled file.
+func verifyFileDigest(f *os.File, want string) error {
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("rewind partial file: %w", err)
+	}
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return fmt.Errorf("hash partial file: %w", err)
+	}
+	if got := encodeHex(h.Sum(nil)); got != want {
+		return fmt.Errorf("%w: model digest %s, want %s", ErrChunkCorrupted, got, want)
+	}
+	return nil
+}
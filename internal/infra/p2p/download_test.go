@@ -0,0 +1,340 @@
+package p2p
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+// ─── Test Doubles ───────────────────────────────────────────────────────────
+
+// fakeFetcher serves chunks from memory. corrupt lists peers that return
+// bad data; down lists peers that error.
+type fakeFetcher struct {
+	mu      sync.Mutex
+	chunks  [][]byte
+	corrupt map[string]bool
+	down    map[string]bool
+	calls   map[string]int // peer → fetches
+	fetched []int          // chunk indices fetched successfully
+}
+
+func newFakeFetcher(chunks [][]byte) *fakeFetcher {
+	return &fakeFetcher{
+		chunks:  chunks,
+		corrupt: make(map[string]bool),
+		down:    make(map[string]bool),
+		calls:   make(map[string]int),
+	}
+}
+
+func (f *fakeFetcher) FetchChunk(ctx context.Context, peer string, m *ChunkManifest, index int) ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls[peer]++
+	if f.down[peer] {
+		return nil, errors.New("connection refused")
+	}
+	data := append([]byte(nil), f.chunks[index]...)
+	if f.corrupt[peer] {
+		data[0] ^= 0xFF
+		return data, nil
+	}
+	f.fetched = append(f.fetched, index)
+	return data, nil
+}
+
+// memTransferStore is an in-memory TransferStore.
+type memTransferStore struct {
+	mu     sync.Mutex
+	status map[int]string
+}
+
+func newMemTransferStore() *memTransferStore {
+	return &memTransferStore{status: make(map[int]string)}
+}
+
+func (s *memTransferStore) StartChunkTransfer(_ string, idx int, _, _ string, _ int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status[idx] = "IN_PROGRESS"
+	return nil
+}
+
+func (s *memTransferStore) CompleteChunkTransfer(_ string, idx int, _ string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status[idx] = "COMPLETED"
+	return nil
+}
+
+func (s *memTransferStore) FailChunkTransfer(_ string, idx int, _ string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status[idx] = "FAILED"
+	return nil
+}
+
+func (s *memTransferStore) CompletedChunks(_, _ string) ([]int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []int
+	for idx, st := range s.status {
+		if st == "COMPLETED" {
+			out = append(out, idx)
+		}
+	}
+	sort.Ints(out)
+	return out, nil
+}
+
+func testModel(t *testing.T, chunks int) (*ChunkManifest, [][]byte) {
+	t.Helper()
+	data := make([]byte, chunks*MinChunkSize)
+	for i := range data {
+		data[i] = byte(i * 7 % 251)
+	}
+	return SplitIntoChunks("dl-test", data, MinChunkSize)
+}
+
+func swarmWith(m *ChunkManifest, peers ...string) *PeerChunkMap {
+	swarm := NewPeerChunkMap()
+	var digests []ChunkDigest
+	for _, c := range m.Chunks {
+		digests = append(digests, c.Digest)
+	}
+	for _, p := range peers {
+		swarm.RegisterPeer(p, digests)
+	}
+	return swarm
+}
+
+// ─── Downloader Tests ───────────────────────────────────────────────────────
+
+func TestDownloader_Download_VerifiesAndAssembles(t *testing.T) {
+	manifest, chunks := testModel(t, 6)
+	fetcher := newFakeFetcher(chunks)
+	var events []DownloadEvent
+	var evMu sync.Mutex
+
+	d := NewDownloader("me", swarmWith(manifest, "peer-a", "peer-b", "peer-c"), fetcher, newMemTransferStore(), DownloadConfig{
+		Seed: 1,
+		OnProgress: func(ev DownloadEvent) {
+			evMu.Lock()
+			events = append(events, ev)
+			evMu.Unlock()
+		},
+	})
+
+	dest := filepath.Join(t.TempDir(), "model.gguf")
+	progress, err := d.Download(context.Background(), manifest, dest)
+	if err != nil {
+		t.Fatalf("Download: %v", err)
+	}
+	if !progress.IsComplete() {
+		t.Error("progress should be complete")
+	}
+
+	got, err := os.ReadFile(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if int64(len(got)) != manifest.TotalSize {
+		t.Fatalf("file size = %d, want %d", len(got), manifest.TotalSize)
+	}
+	if _, err := os.Stat(dest + ".partial"); !os.IsNotExist(err) {
+		t.Error("partial file should be renamed away")
+	}
+
+	last := events[len(events)-1]
+	if last.Type != EventComplete || last.Percent != 100 {
+		t.Errorf("last event = %+v, want complete at 100%%", last)
+	}
+	done := 0
+	for _, ev := range events {
+		if ev.Type == EventChunkDone {
+			done++
+		}
+	}
+	if done != 6 {
+		t.Errorf("chunk_done events = %d, want 6", done)
+	}
+}
+
+func TestDownloader_Download_RetriesCorruptPeer(t *testing.T) {
+	manifest, chunks := testModel(t, 4)
+	fetcher := newFakeFetcher(chunks)
+	fetcher.corrupt["evil"] = true
+
+	d := NewDownloader("me", swarmWith(manifest, "evil", "good"), fetcher, nil, DownloadConfig{Seed: 7, Concurrency: 1})
+	dest := filepath.Join(t.TempDir(), "model.gguf")
+	if _, err := d.Download(context.Background(), manifest, dest); err != nil {
+		t.Fatalf("Download: %v", err)
+	}
+	if fetcher.calls["good"] != 4 {
+		t.Errorf("good peer served %d chunks, want 4", fetcher.calls["good"])
+	}
+	if fetcher.calls["evil"] > 0 && d.PeerThroughput("evil") >= d.PeerThroughput("good") {
+		t.Error("corrupt peer should be ranked below the good peer")
+	}
+}
+
+func TestDownloader_Download_FailsWithoutPeers(t *testing.T) {
+	manifest, chunks := testModel(t, 2)
+	fetcher := newFakeFetcher(chunks)
+	fetcher.down["only"] = true
+
+	d := NewDownloader("me", swarmWith(manifest, "only"), fetcher, nil, DownloadConfig{Seed: 1})
+	_, err := d.Download(context.Background(), manifest, filepath.Join(t.TempDir(), "m.gguf"))
+	if err == nil {
+		t.Fatal("expected error when the only peer is down")
+	}
+}
+
+func TestDownloader_Download_Blocklist(t *testing.T) {
+	manifest, chunks := testModel(t, 3)
+	fetcher := newFakeFetcher(chunks)
+
+	d := NewDownloader("me", swarmWith(manifest, "banned", "good"), fetcher, nil, DownloadConfig{
+		Seed:        1,
+		PeerBlocked: func(peer string) bool { return peer == "banned" },
+	})
+	if _, err := d.Download(context.Background(), manifest, filepath.Join(t.TempDir(), "m.gguf")); err != nil {
+		t.Fatalf("Download: %v", err)
+	}
+	if fetcher.calls["banned"] != 0 {
+		t.Errorf("blocked peer served %d chunks", fetcher.calls["banned"])
+	}
+
+	d = NewDownloader("me", swarmWith(manifest, "good"), fetcher, nil, DownloadConfig{
+		ModelBlocked: func(digest string) bool { return digest == manifest.ModelDigest },
+	})
+	_, err := d.Download(context.Background(), manifest, filepath.Join(t.TempDir(), "m.gguf"))
+	if !errors.Is(err, ErrModelBlocked) {
+		t.Errorf("Download of a blocked model = %v, want ErrModelBlocked", err)
+	}
+}
+
+func TestDownloader_Download_ResumesAfterRestart(t *testing.T) {
+	manifest, chunks := testModel(t, 5)
+	store := newMemTransferStore()
+	dest := filepath.Join(t.TempDir(), "model.gguf")
+
+	// First run: peer dies after serving some chunks.
+	first := newFakeFetcher(chunks)
+	d1 := NewDownloader("me", swarmWith(manifest, "flaky"), &stopAfter{inner: first, n: 3}, store, DownloadConfig{Seed: 1, Concurrency: 1, MaxAttempts: 1})
+	if _, err := d1.Download(context.Background(), manifest, dest); err == nil {
+		t.Fatal("first run should fail")
+	}
+	completed, _ := store.CompletedChunks("", "")
+	if len(completed) != 3 {
+		t.Fatalf("completed after first run = %v, want 3 chunks", completed)
+	}
+
+	// Second run (fresh downloader, same store): only the rest is fetched.
+	second := newFakeFetcher(chunks)
+	d2 := NewDownloader("me", swarmWith(manifest, "peer"), second, store, DownloadConfig{Seed: 1})
+	progress, err := d2.Download(context.Background(), manifest, dest)
+	if err != nil {
+		t.Fatalf("resume: %v", err)
+	}
+	if len(second.fetched) != 2 {
+		t.Errorf("resumed download fetched %d chunks, want 2", len(second.fetched))
+	}
+	if !progress.IsComplete() {
+		t.Error("resumed download incomplete")
+	}
+}
+
+func TestDownloader_Download_Cancelled(t *testing.T) {
+	manifest, chunks := testModel(t, 3)
+	d := NewDownloader("me", swarmWith(manifest, "p"), newFakeFetcher(chunks), nil, DownloadConfig{Seed: 1})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := d.Download(ctx, manifest, filepath.Join(t.TempDir(), "m.gguf"))
+	if !errors.Is(err, ErrTransferCancelled) {
+		t.Errorf("err = %v, want ErrTransferCancelled", err)
+	}
+}
+
+func TestDownloader_SelectPeer_PrefersReputableFastPeers(t *testing.T) {
+	manifest, chunks := testModel(t, 1)
+	d := NewDownloader("me", swarmWith(manifest, "fast", "slow"), newFakeFetcher(chunks), nil, DownloadConfig{
+		Seed: 42,
+		Reputation: func(peer string) float64 {
+			if peer == "fast" {
+				return 0.9
+			}
+			return 0.1
+		},
+	})
+	d.observe("fast", 10<<20, 1e9) // 10 MB/s
+	d.observe("slow", 1<<20, 1e9)  // 1 MB/s
+
+	picks := map[string]int{}
+	for i := 0; i < 1000; i++ {
+		p, _ := d.selectPeer(manifest.Chunks[0].Digest, nil)
+		picks[p]++
+	}
+	if picks["fast"] < 950 {
+		t.Errorf("fast peer picked %d/1000 times, want ≥ 950", picks["fast"])
+	}
+	if picks["slow"] == 0 {
+		t.Error("slow peer should still be picked occasionally")
+	}
+}
+
+func TestDownloader_SelectPeer_UsesNetworkProfile(t *testing.T) {
+	manifest, chunks := testModel(t, 1)
+	var reported []string
+	d := NewDownloader("me", swarmWith(manifest, "near", "far"), newFakeFetcher(chunks), nil, DownloadConfig{
+		Seed: 7,
+		Bandwidth: func(peer string) float64 {
+			if peer == "near" {
+				return 50 << 20
+			}
+			return 1 << 20
+		},
+		OnTransfer: func(peer string, bytes int, _ time.Duration) {
+			reported = append(reported, peer)
+		},
+	})
+
+	picks := map[string]int{}
+	for i := 0; i < 1000; i++ {
+		p, _ := d.selectPeer(manifest.Chunks[0].Digest, nil)
+		picks[p]++
+	}
+	if picks["near"] < 950 {
+		t.Errorf("high-bandwidth peer picked %d/1000 times, want ≥ 950", picks["near"])
+	}
+
+	d.observe("far", 1<<20, time.Second)
+	if len(reported) != 1 || reported[0] != "far" {
+		t.Errorf("OnTransfer calls = %v, want [far]", reported)
+	}
+}
+
+// stopAfter fails every fetch after the first n successes.
+type stopAfter struct {
+	mu    sync.Mutex
+	inner ChunkFetcher
+	n     int
+}
+
+func (s *stopAfter) FetchChunk(ctx context.Context, peer string, m *ChunkManifest, index int) ([]byte, error) {
+	s.mu.Lock()
+	if s.n == 0 {
+		s.mu.Unlock()
+		return nil, errors.New("peer went away")
+	}
+	s.n--
+	s.mu.Unlock()
+	return s.inner.FetchChunk(ctx, peer, m, index)
+}
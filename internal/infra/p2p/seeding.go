@@ -0,0 +1,452 @@
+package p2p
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ─── Seeding: Upload Limits & Choking ───────────────────────────────────────
+// A node seeding popular models must not saturate its uplink. Seeder gates
+// every chunk upload through two token buckets (global and per-peer) and a
+// BitTorrent-style choke algorithm:
+//
+//   - Every RechokeInterval, interested peers are ranked by what they give
+//     back — bytes they uploaded to us plus credits they paid, both smoothed
+//   - The top UploadSlots are unchoked; everyone else waits
+//   - One extra "optimistic" slot rotates among choked peers so newcomers
+//     with nothing to offer yet can still bootstrap
+//
+// Upload counters feed the earnings system: SettleContribution converts
+// the bytes uploaded since the last settlement into whole credits and
+// carries the bytes too few to earn one into the next settlement, so slow
+// seeders are paid eventually instead of never.
+
+// ErrPeerChoked is returned when a choked peer requests data.
+var ErrPeerChoked = errors.New("peer is choked")
+
+// SeedConfig tunes upload limits and fairness.
+type SeedConfig struct {
+	GlobalBytesPerSec int64         // total upload cap (0 = unlimited)
+	PeerBytesPerSec   int64         // per-peer upload cap (0 = unlimited)
+	UploadSlots       int           // regular unchoked peers
+	OptimisticSlots   int           // rotating unchoked slots for choked peers
+	OptimisticEvery   int           // rotate the optimistic peer every N rechokes
+	RechokeInterval   time.Duration // how often RunRechoke re-ranks peers
+	CreditWeight      float64       // bytes of reciprocation one paid credit is worth
+	Seed              int64         // optimistic-unchoke RNG seed (0 = time-based)
+}
+
+// DefaultSeedConfig returns BitTorrent-like defaults with no bandwidth cap.
+func DefaultSeedConfig() SeedConfig {
+	return SeedConfig{
+		UploadSlots:     4,
+		OptimisticSlots: 1,
+		OptimisticEvery: 3,
+		RechokeInterval: 10 * time.Second,
+		CreditWeight:    64 * 1024 * 1024, // 1 credit ≈ 64 MB reciprocated
+	}
+}
+
+// reciprocationAlpha smooths per-rechoke reciprocation samples.
+const reciprocationAlpha = 0.5
+
+// PeerSeedStats describes one peer from the seeder's point of view.
+type PeerSeedStats struct {
+	Peer        string  `json:"peer"`
+	Interested  bool    `json:"interested"`
+	Choked      bool    `json:"choked"`
+	Optimistic  bool    `json:"optimistic"`
+	Uploaded    int64   `json:"uploaded"`   // bytes we sent them
+	Downloaded  int64   `json:"downloaded"` // bytes they sent us
+	CreditsPaid int64   `json:"credits_paid"`
+	Score       float64 `json:"score"` // smoothed reciprocation used for ranking
+}
+
+// SeedingStats summarizes seeding activity.
+type SeedingStats struct {
+	Uploaded int64            `json:"uploaded"`
+	ByModel  map[string]int64 `json:"by_model"`
+	Unchoked int              `json:"unchoked"`
+	Peers    []PeerSeedStats  `json:"peers"`
+}
+
+// Contribution is bandwidth contributed since the previous settlement.
+type Contribution struct {
+	Bytes   int64            `json:"bytes"`    // bytes paid for, including any carried in
+	Credits int64            `json:"credits"`  // credits earned for Bytes
+	Carried int64            `json:"carried"`  // bytes left over for the next settlement
+	ByModel map[string]int64 `json:"by_model"` // bytes uploaded in this period, per model
+	Since   time.Time        `json:"since"`
+	Until   time.Time        `json:"until"`
+}
+
+type seedPeer struct {
+	interested  bool
+	choked      bool
+	optimistic  bool
+	uploaded    int64
+	downloaded  int64
+	creditsPaid int64
+	bucket      *tokenBucket
+
+	// reciprocation since the last rechoke, folded into score
+	recentBytes   int64
+	recentCredits int64
+	score         float64
+}
+
+// Seeder enforces upload limits and choke decisions. Thread-safe.
+type Seeder struct {
+	mu       sync.Mutex
+	cfg      SeedConfig
+	global   *tokenBucket
+	peers    map[string]*seedPeer
+	uploaded int64
+	byModel  map[string]int64
+	rechokes int
+	rng      *rand.Rand
+
+	settleMu       sync.Mutex // serializes SettleContribution
+	settled        time.Time
+	settledBytes   int64 // uploaded bytes already paid for
+	settledByModel map[string]int64
+
+	now      func() time.Time
+	sleep    func(ctx context.Context, d time.Duration) error
+	onUpload func(peer, model string, n int64)
+}
+
+// NewSeeder creates a seeder. Non-positive slot and interval values fall
+// back to DefaultSeedConfig.
+func NewSeeder(cfg SeedConfig) *Seeder {
+	def := DefaultSeedConfig()
+	if cfg.UploadSlots <= 0 {
+		cfg.UploadSlots = def.UploadSlots
+	}
+	if cfg.OptimisticSlots < 0 {
+		cfg.OptimisticSlots = 0
+	}
+	if cfg.OptimisticEvery <= 0 {
+		cfg.OptimisticEvery = def.OptimisticEvery
+	}
+	if cfg.RechokeInterval <= 0 {
+		cfg.RechokeInterval = def.RechokeInterval
+	}
+	if cfg.CreditWeight <= 0 {
+		cfg.CreditWeight = def.CreditWeight
+	}
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	s := &Seeder{
+		cfg:            cfg,
+		peers:          make(map[string]*seedPeer),
+		byModel:        make(map[string]int64),
+		settledByModel: make(map[string]int64),
+		rng:            rand.New(rand.NewSource(seed)),
+		now:            time.Now,
+		sleep:          sleepCtx,
+	}
+	s.global = newTokenBucket(cfg.GlobalBytesPerSec, s.now())
+	s.settled = s.now()
+	return s
+}
+
+func (s *Seeder) peerLocked(id string) *seedPeer {
+	p, ok := s.peers[id]
+	if !ok {
+		p = &seedPeer{choked: true, bucket: newTokenBucket(s.cfg.PeerBytesPerSec, s.now())}
+		s.peers[id] = p
+	}
+	return p
+}
+
+// SetInterested records whether a peer wants data from us.
+func (s *Seeder) SetInterested(peer string, interested bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p := s.peerLocked(peer)
+	p.interested = interested
+	if !interested {
+		p.choked = true
+		p.optimistic = false
+	}
+}
+
+// RemovePeer forgets a disconnected peer.
+func (s *Seeder) RemovePeer(peer string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.peers, peer)
+}
+
+// RecordDownload credits a peer for bytes it uploaded to us (reciprocation).
+func (s *Seeder) RecordDownload(peer string, bytes int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p := s.peerLocked(peer)
+	p.downloaded += bytes
+	p.recentBytes += bytes
+}
+
+// RecordPayment credits a peer for credits it paid us for data.
+func (s *Seeder) RecordPayment(peer string, credits int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p := s.peerLocked(peer)
+	p.creditsPaid += credits
+	p.recentCredits += credits
+}
+
+// IsChoked reports whether uploads to peer are currently refused.
+func (s *Seeder) IsChoked(peer string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.peers[peer]
+	return !ok || p.choked
+}
+
+// Rechoke re-ranks interested peers and returns the unchoked set, sorted.
+func (s *Seeder) Rechoke() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var interested []string
+	for id, p := range s.peers {
+		sample := float64(p.recentBytes) + float64(p.recentCredits)*s.cfg.CreditWeight
+		p.score = reciprocationAlpha*sample + (1-reciprocationAlpha)*p.score
+		p.recentBytes, p.recentCredits = 0, 0
+		if p.interested {
+			interested = append(interested, id)
+		}
+	}
+	sort.Slice(interested, func(i, j int) bool {
+		a, b := s.peers[interested[i]], s.peers[interested[j]]
+		if a.score != b.score {
+			return a.score > b.score
+		}
+		return interested[i] < interested[j]
+	})
+
+	unchoke := make(map[string]bool)
+	for i, id := range interested {
+		if i >= s.cfg.UploadSlots {
+			break
+		}
+		unchoke[id] = true
+	}
+
+	// Keep the optimistic peers between rotations unless they earned a slot.
+	rotate := s.rechokes%s.cfg.OptimisticEvery == 0
+	s.rechokes++
+	var optimistic []string
+	if !rotate {
+		for _, id := range interested {
+			if p := s.peers[id]; p.optimistic && !unchoke[id] {
+				optimistic = append(optimistic, id)
+			}
+		}
+	}
+	var pool []string
+	for _, id := range interested {
+		if !unchoke[id] && !contains(optimistic, id) {
+			pool = append(pool, id)
+		}
+	}
+	for len(optimistic) < s.cfg.OptimisticSlots && len(pool) > 0 {
+		i := s.rng.Intn(len(pool))
+		optimistic = append(optimistic, pool[i])
+		pool = append(pool[:i], pool[i+1:]...)
+	}
+
+	var out []string
+	for id, p := range s.peers {
+		p.optimistic = contains(optimistic, id)
+		p.choked = !unchoke[id] && !p.optimistic
+		if !p.choked {
+			out = append(out, id)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// RechokeInterval returns how often RunRechoke re-ranks peers.
+func (s *Seeder) RechokeInterval() time.Duration {
+	return s.cfg.RechokeInterval
+}
+
+// RunRechoke re-ranks peers every RechokeInterval until ctx is done.
+func (s *Seeder) RunRechoke(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.RechokeInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.Rechoke()
+		}
+	}
+}
+
+// OnUpload registers fn to be called, outside the seeder lock, with every
+// upload WaitUpload admits.
+func (s *Seeder) OnUpload(fn func(peer, model string, n int64)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onUpload = fn
+}
+
+// WaitUpload admits an upload of n bytes of model to peer, blocking until
+// both rate limits allow it. Returns ErrPeerChoked for choked peers.
+func (s *Seeder) WaitUpload(ctx context.Context, peer, model string, n int64) error {
+	s.mu.Lock()
+	p, ok := s.peers[peer]
+	if !ok || p.choked {
+		s.mu.Unlock()
+		return ErrPeerChoked
+	}
+	now := s.now()
+	delay := max(s.global.reserve(n, now), p.bucket.reserve(n, now))
+	p.uploaded += n
+	s.uploaded += n
+	s.byModel[model] += n
+	hook := s.onUpload
+	s.mu.Unlock()
+
+	if hook != nil {
+		hook(peer, model, n)
+	}
+	if delay > 0 {
+		return s.sleep(ctx, delay)
+	}
+	return nil
+}
+
+// Stats returns lifetime seeding statistics.
+func (s *Seeder) Stats() SeedingStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st := SeedingStats{Uploaded: s.uploaded, ByModel: make(map[string]int64, len(s.byModel))}
+	for m, n := range s.byModel {
+		st.ByModel[m] = n
+	}
+	for id, p := range s.peers {
+		if !p.choked {
+			st.Unchoked++
+		}
+		st.Peers = append(st.Peers, PeerSeedStats{
+			Peer:        id,
+			Interested:  p.interested,
+			Choked:      p.choked,
+			Optimistic:  p.optimistic,
+			Uploaded:    p.uploaded,
+			Downloaded:  p.downloaded,
+			CreditsPaid: p.creditsPaid,
+			Score:       p.score,
+		})
+	}
+	sort.Slice(st.Peers, func(i, j int) bool { return st.Peers[i].Peer < st.Peers[j].Peer })
+	return st
+}
+
+// SettleContribution pays for the bytes uploaded since the previous
+// settlement and starts a new period. earn converts bytes into whole
+// credits (credit.SeedingEarningAmount) and must not call the seeder; the
+// bytes beyond the last whole credit are carried into the next settlement.
+// pay records the earnings and is called without the seeder lock; the
+// period is closed only if it succeeds, so a failed payment leaves the
+// bytes to be paid by the next settlement. Settlements are serialized.
+func (s *Seeder) SettleContribution(earn func(bytes int64) int64, pay func(Contribution) error) (Contribution, error) {
+	s.settleMu.Lock()
+	defer s.settleMu.Unlock()
+
+	s.mu.Lock()
+	now := s.now()
+	pending := s.uploaded - s.settledBytes
+	credits := max(earn(pending), 0)
+	// The fewest bytes that still earn the same credits: earn is monotonic
+	paid := int64(sort.Search(int(pending), func(n int) bool { return earn(int64(n)) >= credits }))
+	c := Contribution{
+		Bytes:   paid,
+		Credits: credits,
+		Carried: pending - paid,
+		ByModel: make(map[string]int64),
+		Since:   s.settled,
+		Until:   now,
+	}
+	byModel := make(map[string]int64, len(s.byModel))
+	for m, n := range s.byModel {
+		if d := n - s.settledByModel[m]; d > 0 {
+			c.ByModel[m] = d
+		}
+		byModel[m] = n
+	}
+	s.mu.Unlock()
+
+	if err := pay(c); err != nil {
+		return c, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.settled = now
+	s.settledBytes += paid
+	s.settledByModel = byModel
+	return c, nil
+}
+
+// ─── Token Bucket ───────────────────────────────────────────────────────────
+
+// tokenBucket is a byte-rate limiter with a one-second burst. Reservations
+// may overdraw it; the caller waits out the debt. Not thread-safe.
+type tokenBucket struct {
+	rate   float64 // bytes/sec; 0 = unlimited
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate int64, now time.Time) *tokenBucket {
+	return &tokenBucket{rate: float64(rate), tokens: float64(rate), last: now}
+}
+
+// reserve takes n bytes and returns how long the caller must wait.
+func (b *tokenBucket) reserve(n int64, now time.Time) time.Duration {
+	if b.rate <= 0 {
+		return 0
+	}
+	b.tokens = min(b.rate, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+	b.tokens -= float64(n)
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,210 @@
+package p2p
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// newTestSeeder returns a seeder on a manual clock that records sleeps.
+func newTestSeeder(cfg SeedConfig) (*Seeder, *time.Time, *[]time.Duration) {
+	s := NewSeeder(cfg)
+	clock := time.Unix(1_700_000_000, 0)
+	var slept []time.Duration
+	s.now = func() time.Time { return clock }
+	s.sleep = func(_ context.Context, d time.Duration) error {
+		slept = append(slept, d)
+		return nil
+	}
+	s.global = newTokenBucket(cfg.GlobalBytesPerSec, clock)
+	s.settled = clock
+	return s, &clock, &slept
+}
+
+func TestSeeder_Rechoke_PrefersReciprocatingAndPayingPeers(t *testing.T) {
+	s, _, _ := newTestSeeder(SeedConfig{UploadSlots: 2, OptimisticSlots: 1, Seed: 1})
+	for _, p := range []string{"leech-1", "leech-2", "trader", "payer"} {
+		s.SetInterested(p, true)
+	}
+	s.RecordDownload("trader", 50<<20)
+	s.RecordPayment("payer", 2) // 2 × 64 MB worth
+
+	unchoked := s.Rechoke()
+	if len(unchoked) != 3 {
+		t.Fatalf("unchoked = %v, want 2 regular + 1 optimistic", unchoked)
+	}
+	if s.IsChoked("trader") || s.IsChoked("payer") {
+		t.Error("reciprocating and paying peers should be unchoked")
+	}
+	leechesUnchoked := 0
+	for _, p := range []string{"leech-1", "leech-2"} {
+		if !s.IsChoked(p) {
+			leechesUnchoked++
+		}
+	}
+	if leechesUnchoked != 1 {
+		t.Errorf("%d leeches unchoked, want exactly 1 optimistic", leechesUnchoked)
+	}
+}
+
+func TestSeeder_Rechoke_OptimisticRotation(t *testing.T) {
+	s, _, _ := newTestSeeder(SeedConfig{UploadSlots: 1, OptimisticSlots: 1, OptimisticEvery: 2, Seed: 3})
+	for _, p := range []string{"a", "b", "c", "d", "e"} {
+		s.SetInterested(p, true)
+	}
+	s.RecordDownload("a", 1<<20)
+
+	optimistic := func() string {
+		for _, p := range s.Stats().Peers {
+			if p.Optimistic {
+				return p.Peer
+			}
+		}
+		return ""
+	}
+
+	s.Rechoke()
+	first := optimistic()
+	s.Rechoke()
+	if optimistic() != first {
+		t.Error("optimistic peer changed before its rotation period")
+	}
+
+	seen := map[string]bool{first: true}
+	for i := 0; i < 20; i++ {
+		s.Rechoke()
+		seen[optimistic()] = true
+	}
+	if len(seen) < 3 {
+		t.Errorf("optimistic slot visited %d peers, want rotation across choked peers", len(seen))
+	}
+	if seen["a"] {
+		t.Error("regularly unchoked peer should not take the optimistic slot")
+	}
+}
+
+func TestSeeder_WaitUpload(t *testing.T) {
+	s, clock, slept := newTestSeeder(SeedConfig{GlobalBytesPerSec: 1 << 20, PeerBytesPerSec: 512 << 10, UploadSlots: 4})
+
+	if err := s.WaitUpload(context.Background(), "stranger", "llama3", 1); !errors.Is(err, ErrPeerChoked) {
+		t.Errorf("unknown peer err = %v, want ErrPeerChoked", err)
+	}
+
+	s.SetInterested("p", true)
+	s.Rechoke()
+
+	// Per-peer bucket: 512 KB burst, then 1 MB more costs 2s of debt.
+	if err := s.WaitUpload(context.Background(), "p", "llama3", 512<<10); err != nil {
+		t.Fatal(err)
+	}
+	if len(*slept) != 0 {
+		t.Errorf("burst upload slept %v", *slept)
+	}
+	s.WaitUpload(context.Background(), "p", "llama3", 1<<20)
+	if len(*slept) != 1 || (*slept)[0] != 2*time.Second {
+		t.Errorf("slept = %v, want [2s]", *slept)
+	}
+
+	// After the debt is paid back, a small upload is free again.
+	*clock = clock.Add(3 * time.Second)
+	*slept = nil
+	s.WaitUpload(context.Background(), "p", "phi3", 1024)
+	if len(*slept) != 0 {
+		t.Errorf("slept after refill = %v", *slept)
+	}
+
+	st := s.Stats()
+	if st.Uploaded != 512<<10+1<<20+1024 || st.ByModel["phi3"] != 1024 {
+		t.Errorf("stats = %+v", st)
+	}
+}
+
+func TestSeeder_OnUpload(t *testing.T) {
+	s, _, _ := newTestSeeder(SeedConfig{UploadSlots: 4})
+	var total int64
+	s.OnUpload(func(peer, model string, n int64) { total += n })
+
+	s.SetInterested("p", true)
+	s.Rechoke()
+	s.WaitUpload(context.Background(), "p", "llama3", 4096)
+	s.WaitUpload(context.Background(), "stranger", "llama3", 1024) // choked
+
+	if total != 4096 {
+		t.Errorf("hook saw %d bytes, want 4096", total)
+	}
+}
+
+func TestSeeder_SettleContribution(t *testing.T) {
+	s, clock, _ := newTestSeeder(SeedConfig{})
+	s.SetInterested("p", true)
+	s.Rechoke()
+
+	earn := func(bytes int64) int64 { return bytes / 300 } // one credit per 300 bytes
+	settle := func() Contribution {
+		t.Helper()
+		c, err := s.SettleContribution(earn, func(Contribution) error { return nil })
+		if err != nil {
+			t.Fatalf("settle: %v", err)
+		}
+		return c
+	}
+
+	s.WaitUpload(context.Background(), "p", "llama3", 1000)
+	*clock = clock.Add(time.Hour)
+	c := settle()
+	if c.Credits != 3 || c.Bytes != 900 || c.Carried != 100 || c.ByModel["llama3"] != 1000 || c.Until.Sub(c.Since) != time.Hour {
+		t.Errorf("first settlement = %+v, want 3 credits for 900 bytes, 100 carried", c)
+	}
+
+	s.WaitUpload(context.Background(), "p", "phi3", 500)
+	c = settle()
+	if c.Credits != 2 || c.Bytes != 600 || c.Carried != 0 || len(c.ByModel) != 1 || c.ByModel["phi3"] != 500 {
+		t.Errorf("second settlement = %+v, want 2 credits for the new 500 bytes plus 100 carried", c)
+	}
+
+	s.WaitUpload(context.Background(), "p", "phi3", 200)
+	if c = settle(); c.Credits != 0 || c.Bytes != 0 || c.Carried != 200 {
+		t.Errorf("settlement below one credit = %+v, want everything carried", c)
+	}
+	if c = settle(); c.Carried != 200 {
+		t.Errorf("carried bytes were lost: %+v", c)
+	}
+}
+
+func TestSeeder_SettleContribution_FailedPaymentIsRetried(t *testing.T) {
+	s, clock, _ := newTestSeeder(SeedConfig{})
+	s.SetInterested("p", true)
+	s.Rechoke()
+	earn := func(bytes int64) int64 { return bytes / 300 }
+
+	s.WaitUpload(context.Background(), "p", "llama3", 1000)
+	*clock = clock.Add(time.Hour)
+	errLedger := errors.New("ledger unavailable")
+	if _, err := s.SettleContribution(earn, func(Contribution) error { return errLedger }); !errors.Is(err, errLedger) {
+		t.Fatalf("err = %v, want the payment error", err)
+	}
+
+	*clock = clock.Add(time.Hour)
+	var paid Contribution
+	c, err := s.SettleContribution(earn, func(c Contribution) error { paid = c; return nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Credits != 3 || paid.Bytes != 900 || c.ByModel["llama3"] != 1000 || c.Until.Sub(c.Since) != 2*time.Hour {
+		t.Errorf("settlement after failed payment = %+v, want the unpaid period settled again", c)
+	}
+}
+
+func TestSeeder_NotInterestedIsChoked(t *testing.T) {
+	s, _, _ := newTestSeeder(SeedConfig{})
+	s.SetInterested("p", true)
+	s.Rechoke()
+	if s.IsChoked("p") {
+		t.Fatal("interested peer with free slot should be unchoked")
+	}
+	s.SetInterested("p", false)
+	if !s.IsChoked("p") {
+		t.Error("uninterested peer should be choked")
+	}
+}
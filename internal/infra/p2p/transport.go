@@ -0,0 +1,485 @@
+package p2p
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/security"
+)
+
+// ─── Chunk Transport ────────────────────────────────────────────────────────
+// Nodes serve the models they host to peers over HTTP:
+//
+//	GET /p2p/v1/models/{digest}/manifest     ChunkManifest (JSON)
+//	GET /p2p/v1/models/{digest}/chunks/{i}   chunk i (application/octet-stream)
+//
+// {digest} is the SHA-256 hex of the whole model file. Every request names
+// the requesting node and is signed with its key over "<method> <path>
+// <unix time>", so the server knows which peer it is uploading to. Asking
+// for a model marks the peer interested in the Seeder, and every chunk is
+// admitted by Seeder.WaitUpload: a choked peer is answered 503 with a
+// Retry-After of one rechoke interval, and is unchoked (or not) by the next
+// rechoke. Manifests are built with the content-defined Chunker the first
+// time a model is asked for, in the background; until then requests for it
+// are answered 503 too.
+//
+// ChunkClient is the other end: the ChunkFetcher a Downloader uses to fetch
+// from peers. It waits out 503 answers for up to MaxWait before giving up on
+// a peer, so a choked peer costs a download time rather than a failure.
+
+// Request authentication headers.
+const (
+	HeaderNode          = "X-Tutu-Node"
+	HeaderNodeTime      = "X-Tutu-Node-Time"
+	HeaderNodeSignature = "X-Tutu-Node-Signature"
+)
+
+// manifestRetry is what peers are told to wait while a manifest is built.
+const manifestRetry = 5 * time.Second
+
+// LocalModel is a model file this node can serve.
+type LocalModel struct {
+	Name string
+	Path string
+}
+
+// ChunkServerConfig tunes the chunk server.
+type ChunkServerConfig struct {
+	Chunker     ChunkerConfig // manifest chunking (zero = DefaultChunkerConfig)
+	MaxSkew     time.Duration // accepted request clock skew (default: 1m)
+	IdleTimeout time.Duration // peers silent this long stop being interested (default: 1m)
+
+	// Blocklist enforcement (nil = nothing blocked): blocked peers are
+	// refused, blocked models are not served.
+	PeerBlocked  func(peer string) bool
+	ModelBlocked func(digest string) bool
+
+	Now func() time.Time // clock (nil = time.Now)
+}
+
+// DefaultChunkServerConfig returns sensible defaults.
+func DefaultChunkServerConfig() ChunkServerConfig {
+	return ChunkServerConfig{
+		Chunker:     DefaultChunkerConfig(),
+		MaxSkew:     time.Minute,
+		IdleTimeout: time.Minute,
+		Now:         time.Now,
+	}
+}
+
+// ChunkServer serves hosted models to peers through a Seeder. Thread-safe.
+type ChunkServer struct {
+	kp     *security.Keypair
+	seeder *Seeder
+	models func(digest string) (LocalModel, bool)
+	cfg    ChunkServerConfig
+
+	mu        sync.Mutex
+	manifests map[string]*manifestBuild // model digest → manifest
+	lastSeen  map[string]time.Time      // peer → last request
+	swept     time.Time
+}
+
+// manifestBuild is a manifest being built, or built, for one model.
+type manifestBuild struct {
+	done     chan struct{}
+	manifest *ChunkManifest
+	err      error
+}
+
+// NewChunkServer creates a chunk server. models resolves a model digest
+// (SHA-256 hex) to the local file hosting it; manifests are signed with kp.
+// Zero config values fall back to DefaultChunkServerConfig.
+func NewChunkServer(kp *security.Keypair, seeder *Seeder, models func(digest string) (LocalModel, bool), cfg ChunkServerConfig) *ChunkServer {
+	def := DefaultChunkServerConfig()
+	if cfg.Chunker == (ChunkerConfig{}) {
+		cfg.Chunker = def.Chunker
+	}
+	if cfg.MaxSkew <= 0 {
+		cfg.MaxSkew = def.MaxSkew
+	}
+	if cfg.IdleTimeout <= 0 {
+		cfg.IdleTimeout = def.IdleTimeout
+	}
+	if cfg.Now == nil {
+		cfg.Now = def.Now
+	}
+	return &ChunkServer{
+		kp:        kp,
+		seeder:    seeder,
+		models:    models,
+		cfg:       cfg,
+		manifests: make(map[string]*manifestBuild),
+		lastSeen:  make(map[string]time.Time),
+	}
+}
+
+// Handler returns the HTTP handler for the chunk transport.
+func (s *ChunkServer) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /p2p/v1/models/{digest}/manifest", s.handleManifest)
+	mux.HandleFunc("GET /p2p/v1/models/{digest}/chunks/{index}", s.handleChunk)
+	return mux
+}
+
+func (s *ChunkServer) handleManifest(w http.ResponseWriter, r *http.Request) {
+	peer, model, digest, ok := s.admit(w, r)
+	if !ok {
+		return
+	}
+	m, ok := s.manifest(w, digest, model)
+	if !ok {
+		return
+	}
+	s.seeder.SetInterested(peer, true)
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(m)
+}
+
+func (s *ChunkServer) handleChunk(w http.ResponseWriter, r *http.Request) {
+	peer, model, digest, ok := s.admit(w, r)
+	if !ok {
+		return
+	}
+	m, ok := s.manifest(w, digest, model)
+	if !ok {
+		return
+	}
+	index, err := strconv.Atoi(r.PathValue("index"))
+	if err != nil || index < 0 || index >= m.ChunkCount() {
+		http.Error(w, "no such chunk", http.StatusNotFound)
+		return
+	}
+	c := m.Chunks[index]
+	data, err := readChunk(model.Path, c)
+	if err != nil {
+		http.Error(w, "read chunk", http.StatusInternalServerError)
+		return
+	}
+
+	s.seeder.SetInterested(peer, true)
+	if err := s.seeder.WaitUpload(r.Context(), peer, model.Name, int64(len(data))); err != nil {
+		if errors.Is(err, ErrPeerChoked) {
+			retryLater(w, s.seeder.RechokeInterval(), "choked")
+		}
+		return
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	_, _ = w.Write(data)
+}
+
+// admit authenticates a request and resolves the model it asks for,
+// answering the request itself when it is refused.
+func (s *ChunkServer) admit(w http.ResponseWriter, r *http.Request) (peer string, model LocalModel, digest string, ok bool) {
+	peer, err := s.authenticate(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return "", LocalModel{}, "", false
+	}
+	if s.cfg.PeerBlocked != nil && s.cfg.PeerBlocked(peer) {
+		http.Error(w, "peer is blocked", http.StatusForbidden)
+		return "", LocalModel{}, "", false
+	}
+	s.touch(peer)
+
+	digest = r.PathValue("digest")
+	if s.cfg.ModelBlocked != nil && s.cfg.ModelBlocked(digest) {
+		http.Error(w, ErrModelBlocked.Error(), http.StatusForbidden)
+		return "", LocalModel{}, "", false
+	}
+	model, ok = s.models(digest)
+	if !ok {
+		s.mu.Lock()
+		delete(s.manifests, digest)
+		s.mu.Unlock()
+		http.Error(w, "model not hosted", http.StatusNotFound)
+		return "", LocalModel{}, "", false
+	}
+	return peer, model, digest, true
+}
+
+// authenticate checks the request signature and returns the peer's node ID.
+func (s *ChunkServer) authenticate(r *http.Request) (string, error) {
+	peer := r.Header.Get(HeaderNode)
+	pub, err := hex.DecodeString(peer)
+	if err != nil || len(pub) != ed25519.PublicKeySize {
+		return "", errors.New("missing or malformed node ID")
+	}
+	ts, err := strconv.ParseInt(r.Header.Get(HeaderNodeTime), 10, 64)
+	if err != nil {
+		return "", errors.New("missing or malformed request time")
+	}
+	if skew := s.cfg.Now().Sub(time.Unix(ts, 0)); skew > s.cfg.MaxSkew || skew < -s.cfg.MaxSkew {
+		return "", errors.New("request time out of range")
+	}
+	sig, err := hex.DecodeString(r.Header.Get(HeaderNodeSignature))
+	if err != nil || !security.Verify(requestMessage(r.Method, r.URL.Path, ts), sig, ed25519.PublicKey(pub)) {
+		return "", errors.New("bad request signature")
+	}
+	return peer, nil
+}
+
+// signRequest signs r as node kp at now.
+func signRequest(r *http.Request, kp *security.Keypair, now time.Time) {
+	ts := now.Unix()
+	r.Header.Set(HeaderNode, kp.PublicKeyHex())
+	r.Header.Set(HeaderNodeTime, strconv.FormatInt(ts, 10))
+	r.Header.Set(HeaderNodeSignature, hex.EncodeToString(kp.Sign(requestMessage(r.Method, r.URL.Path, ts))))
+}
+
+func requestMessage(method, path string, ts int64) []byte {
+	return []byte(fmt.Sprintf("%s %s %d", method, path, ts))
+}
+
+// touch records a request from peer and drops the interest of peers that
+// have gone quiet, so finished downloads stop holding upload slots.
+func (s *ChunkServer) touch(peer string) {
+	now := s.cfg.Now()
+	var idle []string
+	s.mu.Lock()
+	s.lastSeen[peer] = now
+	if now.Sub(s.swept) >= s.cfg.IdleTimeout {
+		s.swept = now
+		for p, seen := range s.lastSeen {
+			if now.Sub(seen) >= s.cfg.IdleTimeout {
+				idle = append(idle, p)
+				delete(s.lastSeen, p)
+			}
+		}
+	}
+	s.mu.Unlock()
+
+	for _, p := range idle {
+		s.seeder.SetInterested(p, false)
+	}
+}
+
+// manifest returns the model's manifest, starting its build on first use.
+// While it is being built, or if the build failed, it answers the request.
+func (s *ChunkServer) manifest(w http.ResponseWriter, digest string, model LocalModel) (*ChunkManifest, bool) {
+	s.mu.Lock()
+	b, ok := s.manifests[digest]
+	if !ok {
+		b = &manifestBuild{done: make(chan struct{})}
+		s.manifests[digest] = b
+		go s.build(b, digest, model)
+	}
+	s.mu.Unlock()
+
+	select {
+	case <-b.done:
+	default:
+		retryLater(w, manifestRetry, "manifest is being built")
+		return nil, false
+	}
+	if b.err != nil {
+		s.mu.Lock()
+		if s.manifests[digest] == b {
+			delete(s.manifests, digest) // try again on the next request
+		}
+		s.mu.Unlock()
+		http.Error(w, "build manifest", http.StatusInternalServerError)
+		return nil, false
+	}
+	return b.manifest, true
+}
+
+// build chunks the model file and signs its manifest. A file whose digest
+// is not the one it is served under is an error.
+func (s *ChunkServer) build(b *manifestBuild, digest string, model LocalModel) {
+	defer close(b.done)
+	f, err := os.Open(model.Path)
+	if err != nil {
+		b.err = err
+		return
+	}
+	defer f.Close()
+	m, err := BuildManifest(model.Name, f, s.cfg.Chunker)
+	if err != nil {
+		b.err = err
+		return
+	}
+	if m.ModelDigest != digest {
+		b.err = fmt.Errorf("%w: %s hashes to %s, served as %s", ErrChunkCorrupted, model.Path, m.ModelDigest, digest)
+		return
+	}
+	SignManifest(m, s.kp.Private)
+	b.manifest = m
+}
+
+// readChunk reads chunk c of the file at path.
+func readChunk(path string, c ChunkInfo) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	data := make([]byte, c.Size)
+	if _, err := f.ReadAt(data, c.Offset); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// retryLater answers 503 with a Retry-After of wait, rounded up to seconds.
+func retryLater(w http.ResponseWriter, wait time.Duration, reason string) {
+	secs := int((wait + time.Second - 1) / time.Second)
+	w.Header().Set("Retry-After", strconv.Itoa(max(secs, 1)))
+	http.Error(w, reason, http.StatusServiceUnavailable)
+}
+
+// ─── Chunk Client ───────────────────────────────────────────────────────────
+
+// maxManifestBytes bounds a manifest response (a 100 GB model at the
+// minimum average chunk size is about 45 MB of JSON).
+const maxManifestBytes = 64 << 20
+
+// ChunkClientConfig tunes the chunk client.
+type ChunkClientConfig struct {
+	MaxWait time.Duration    // longest a request waits out 503 answers (default: 1m)
+	Now     func() time.Time // clock for request signatures (nil = time.Now)
+}
+
+// DefaultChunkClientConfig returns sensible defaults.
+func DefaultChunkClientConfig() ChunkClientConfig {
+	return ChunkClientConfig{
+		MaxWait: time.Minute,
+		Now:     time.Now,
+	}
+}
+
+// ChunkClient fetches manifests and chunks from peers' chunk servers. It
+// implements ChunkFetcher. Thread-safe.
+type ChunkClient struct {
+	kp     *security.Keypair
+	client *http.Client
+	addr   func(peer string) (string, bool)
+	cfg    ChunkClientConfig
+}
+
+// NewChunkClient creates a chunk client that signs its requests with kp.
+// addr resolves a peer's node ID to the host:port of its chunk server.
+// Zero config values fall back to DefaultChunkClientConfig.
+func NewChunkClient(kp *security.Keypair, client *http.Client, addr func(peer string) (string, bool), cfg ChunkClientConfig) *ChunkClient {
+	def := DefaultChunkClientConfig()
+	if cfg.MaxWait <= 0 {
+		cfg.MaxWait = def.MaxWait
+	}
+	if cfg.Now == nil {
+		cfg.Now = def.Now
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &ChunkClient{kp: kp, client: client, addr: addr, cfg: cfg}
+}
+
+// FetchManifest fetches peer's manifest for the model whose SHA-256 hex is
+// digest. The manifest must describe that model and be signed by peer.
+func (c *ChunkClient) FetchManifest(ctx context.Context, peer, digest string) (*ChunkManifest, error) {
+	body, err := c.get(ctx, peer, "/p2p/v1/models/"+digest+"/manifest", maxManifestBytes)
+	if err != nil {
+		return nil, err
+	}
+	var m ChunkManifest
+	if err := json.Unmarshal(body, &m); err != nil {
+		return nil, fmt.Errorf("peer %s: decode manifest: %w", peer, err)
+	}
+	if m.ModelDigest != digest {
+		return nil, fmt.Errorf("peer %s: manifest is for %s, want %s", peer, m.ModelDigest, digest)
+	}
+	if err := m.Validate(); err != nil {
+		return nil, fmt.Errorf("peer %s: %w", peer, err)
+	}
+	if m.PublisherKey != peer || m.Signature == "" {
+		return nil, fmt.Errorf("peer %s: %w: not signed by the peer", peer, ErrManifestInvalid)
+	}
+	if err := m.VerifySignature(); err != nil {
+		return nil, fmt.Errorf("peer %s: %w", peer, err)
+	}
+	return &m, nil
+}
+
+// FetchChunk fetches chunk index of manifest's model from peer. The caller
+// verifies its digest.
+func (c *ChunkClient) FetchChunk(ctx context.Context, peer string, manifest *ChunkManifest, index int) ([]byte, error) {
+	if index < 0 || index >= manifest.ChunkCount() {
+		return nil, fmt.Errorf("chunk %d: %w", index, ErrChunkNotFound)
+	}
+	size := manifest.Chunks[index].Size
+	data, err := c.get(ctx, peer, fmt.Sprintf("/p2p/v1/models/%s/chunks/%d", manifest.ModelDigest, index), int64(size))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) != size {
+		return nil, fmt.Errorf("peer %s: chunk %d is %d bytes, want %d: %w", peer, index, len(data), size, ErrChunkCorrupted)
+	}
+	return data, nil
+}
+
+// get issues a signed GET to peer and returns up to limit bytes of the
+// body, waiting out 503 answers for up to MaxWait.
+func (c *ChunkClient) get(ctx context.Context, peer, path string, limit int64) ([]byte, error) {
+	addr, ok := c.addr(peer)
+	if !ok {
+		return nil, fmt.Errorf("peer %s: no chunk server address", peer)
+	}
+	deadline := c.cfg.Now().Add(c.cfg.MaxWait)
+	for {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://"+addr+path, nil)
+		if err != nil {
+			return nil, err
+		}
+		signRequest(req, c.kp, c.cfg.Now())
+		resp, err := c.client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("peer %s: %w", peer, err)
+		}
+		body, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("peer %s: read %s: %w", peer, path, err)
+		}
+
+		switch resp.StatusCode {
+		case http.StatusOK:
+			if int64(len(body)) > limit {
+				return nil, fmt.Errorf("peer %s: %s exceeds %d bytes", peer, path, limit)
+			}
+			return body, nil
+		case http.StatusNotFound:
+			return nil, fmt.Errorf("peer %s: %s: %w", peer, path, ErrChunkNotFound)
+		case http.StatusServiceUnavailable:
+			wait := retryAfter(resp.Header.Get("Retry-After"))
+			if c.cfg.Now().Add(wait).After(deadline) {
+				return nil, fmt.Errorf("peer %s: %s: %w", peer, path, ErrPeerChoked)
+			}
+			if err := sleepCtx(ctx, wait); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("peer %s: %s: HTTP %d: %s", peer, path, resp.StatusCode, strings.TrimSpace(string(body)))
+		}
+	}
+}
+
+// retryAfter parses a Retry-After header in seconds (default: 1s).
+func retryAfter(v string) time.Duration {
+	secs, err := strconv.Atoi(v)
+	if err != nil || secs <= 0 {
+		return time.Second
+	}
+	return time.Duration(secs) * time.Second
+}
@@ -0,0 +1,247 @@
+package p2p
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/security"
+)
+
+// newTestChunkServer serves one random model file and returns the server,
+// its seeder, the file contents and the model digest.
+func newTestChunkServer(t *testing.T, cfg ChunkServerConfig) (*ChunkServer, *Seeder, []byte, string) {
+	t.Helper()
+	data := randomBytes(7, 1<<20)
+	path := filepath.Join(t.TempDir(), "model.gguf")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	m, err := BuildManifest("m", bytes.NewReader(data), testChunkerConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	kp, err := security.GenerateKeypair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	seeder := NewSeeder(SeedConfig{})
+	cfg.Chunker = testChunkerConfig()
+	srv := NewChunkServer(kp, seeder, func(digest string) (LocalModel, bool) {
+		return LocalModel{Name: "m", Path: path}, digest == m.ModelDigest
+	}, cfg)
+	return srv, seeder, data, m.ModelDigest
+}
+
+// get issues a request signed by kp (unsigned if kp is nil).
+func get(t *testing.T, h http.Handler, kp *security.Keypair, path string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if kp != nil {
+		signRequest(req, kp, time.Now())
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+// waitManifest requests the manifest until it has been built.
+func waitManifest(t *testing.T, srv *ChunkServer, kp *security.Keypair, digest string) *ChunkManifest {
+	t.Helper()
+	for i := 0; i < 200; i++ {
+		rec := get(t, srv.Handler(), kp, "/p2p/v1/models/"+digest+"/manifest")
+		if rec.Code == http.StatusOK {
+			var m ChunkManifest
+			if err := json.NewDecoder(rec.Body).Decode(&m); err != nil {
+				t.Fatal(err)
+			}
+			return &m
+		}
+		if rec.Code != http.StatusServiceUnavailable {
+			t.Fatalf("manifest status = %d: %s", rec.Code, rec.Body)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("manifest was never built")
+	return nil
+}
+
+func TestChunkServer_ServesChunksThroughSeeder(t *testing.T) {
+	srv, seeder, data, digest := newTestChunkServer(t, ChunkServerConfig{})
+	peer, _ := security.GenerateKeypair()
+
+	m := waitManifest(t, srv, peer, digest)
+	if m.ModelDigest != digest || m.VerifySignature() != nil || m.PublisherKey != srv.kp.PublicKeyHex() {
+		t.Fatalf("manifest = %+v, want one signed by the server for %s", m, digest)
+	}
+
+	rec := get(t, srv.Handler(), peer, "/p2p/v1/models/"+digest+"/chunks/0")
+	if rec.Code != http.StatusServiceUnavailable || rec.Header().Get("Retry-After") != "10" {
+		t.Fatalf("choked peer got %d (Retry-After %q), want 503 with the rechoke interval", rec.Code, rec.Header().Get("Retry-After"))
+	}
+
+	seeder.Rechoke()
+	c := m.Chunks[0]
+	rec = get(t, srv.Handler(), peer, "/p2p/v1/models/"+digest+"/chunks/0")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unchoked peer got %d: %s", rec.Code, rec.Body)
+	}
+	body, _ := io.ReadAll(rec.Body)
+	if !bytes.Equal(body, data[c.Offset:c.Offset+int64(c.Size)]) {
+		t.Error("chunk bytes differ from the file")
+	}
+	if st := seeder.Stats(); st.Uploaded != int64(c.Size) || st.ByModel["m"] != int64(c.Size) {
+		t.Errorf("seeder stats = %+v, want the chunk counted as uploaded", st)
+	}
+
+	if rec := get(t, srv.Handler(), peer, "/p2p/v1/models/"+digest+"/chunks/99"); rec.Code != http.StatusNotFound {
+		t.Errorf("out of range chunk = %d, want 404", rec.Code)
+	}
+	if rec := get(t, srv.Handler(), peer, "/p2p/v1/models/ff/manifest"); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown model = %d, want 404", rec.Code)
+	}
+}
+
+func TestChunkServer_Authentication(t *testing.T) {
+	srv, _, _, digest := newTestChunkServer(t, ChunkServerConfig{})
+	peer, _ := security.GenerateKeypair()
+	other, _ := security.GenerateKeypair()
+	path := "/p2p/v1/models/" + digest + "/manifest"
+
+	if rec := get(t, srv.Handler(), nil, path); rec.Code != http.StatusUnauthorized {
+		t.Errorf("unsigned request = %d, want 401", rec.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	signRequest(req, peer, time.Now())
+	req.Header.Set(HeaderNode, other.PublicKeyHex()) // claims to be another node
+	rec := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("spoofed node = %d, want 401", rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, path, nil)
+	signRequest(req, peer, time.Now().Add(-time.Hour))
+	rec = httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("stale request = %d, want 401", rec.Code)
+	}
+}
+
+func TestChunkServer_Blocklist(t *testing.T) {
+	blockedPeer, _ := security.GenerateKeypair()
+	peer, _ := security.GenerateKeypair()
+	var blockedModel string
+	srv, _, _, digest := newTestChunkServer(t, ChunkServerConfig{
+		PeerBlocked:  func(p string) bool { return p == blockedPeer.PublicKeyHex() },
+		ModelBlocked: func(d string) bool { return d == blockedModel },
+	})
+	path := "/p2p/v1/models/" + digest + "/manifest"
+
+	if rec := get(t, srv.Handler(), blockedPeer, path); rec.Code != http.StatusForbidden {
+		t.Errorf("blocked peer = %d, want 403", rec.Code)
+	}
+	blockedModel = digest
+	if rec := get(t, srv.Handler(), peer, path); rec.Code != http.StatusForbidden {
+		t.Errorf("blocked model = %d, want 403", rec.Code)
+	}
+}
+
+func TestChunkServer_IdlePeersLoseInterest(t *testing.T) {
+	clock := time.Now()
+	srv, seeder, _, digest := newTestChunkServer(t, ChunkServerConfig{
+		IdleTimeout: time.Minute,
+		MaxSkew:     time.Hour, // requests are signed with the real clock
+		Now:         func() time.Time { return clock },
+	})
+	done, _ := security.GenerateKeypair()
+	active, _ := security.GenerateKeypair()
+
+	waitManifest(t, srv, done, digest)
+	if seeder.Rechoke(); seeder.IsChoked(done.PublicKeyHex()) {
+		t.Fatal("interested peer should be unchoked")
+	}
+
+	clock = clock.Add(2 * time.Minute)
+	get(t, srv.Handler(), active, "/p2p/v1/models/"+digest+"/manifest")
+	if !seeder.IsChoked(done.PublicKeyHex()) {
+		t.Error("a peer silent past IdleTimeout should no longer hold an upload slot")
+	}
+}
+
+// ─── Chunk Client Tests ─────────────────────────────────────────────────────
+
+// newTestChunkClient points a client for kp at srv, served over HTTP: every
+// peer but "unknown" resolves to it.
+func newTestChunkClient(t *testing.T, srv *ChunkServer, kp *security.Keypair, cfg ChunkClientConfig) *ChunkClient {
+	t.Helper()
+	hs := httptest.NewServer(srv.Handler())
+	t.Cleanup(hs.Close)
+	addr := strings.TrimPrefix(hs.URL, "http://")
+	return NewChunkClient(kp, hs.Client(), func(peer string) (string, bool) {
+		return addr, peer != "unknown"
+	}, cfg)
+}
+
+func TestChunkClient_DownloadsFromChunkServer(t *testing.T) {
+	srv, seeder, data, digest := newTestChunkServer(t, ChunkServerConfig{})
+	me, _ := security.GenerateKeypair()
+	server := srv.kp.PublicKeyHex()
+	client := newTestChunkClient(t, srv, me, ChunkClientConfig{})
+
+	waitManifest(t, srv, me, digest)
+	seeder.Rechoke()
+	ctx := context.Background()
+	m, err := client.FetchManifest(ctx, server, digest)
+	if err != nil {
+		t.Fatalf("FetchManifest: %v", err)
+	}
+
+	dest := filepath.Join(t.TempDir(), "model.gguf")
+	d := NewDownloader(me.PublicKeyHex(), swarmWith(m, server), client, nil, DownloadConfig{Seed: 1})
+	if _, err := d.Download(ctx, m, dest); err != nil {
+		t.Fatalf("Download: %v", err)
+	}
+	got, _ := os.ReadFile(dest)
+	if !bytes.Equal(got, data) {
+		t.Error("downloaded file differs from the served one")
+	}
+	if st := seeder.Stats(); st.Uploaded != int64(len(data)) {
+		t.Errorf("seeder uploaded %d bytes, want %d", st.Uploaded, len(data))
+	}
+}
+
+func TestChunkClient_Refusals(t *testing.T) {
+	srv, _, _, digest := newTestChunkServer(t, ChunkServerConfig{})
+	me, _ := security.GenerateKeypair()
+	server := srv.kp.PublicKeyHex()
+	client := newTestChunkClient(t, srv, me, ChunkClientConfig{MaxWait: time.Millisecond})
+	ctx := context.Background()
+
+	if _, err := client.FetchManifest(ctx, "unknown", digest); err == nil {
+		t.Error("FetchManifest from a peer without an address should fail")
+	}
+	if _, err := client.FetchManifest(ctx, server, strings.Repeat("0", 64)); !errors.Is(err, ErrChunkNotFound) {
+		t.Errorf("unhosted model: err = %v, want ErrChunkNotFound", err)
+	}
+
+	m := waitManifest(t, srv, me, digest)
+	impostor, _ := security.GenerateKeypair()
+	if _, err := client.FetchManifest(ctx, impostor.PublicKeyHex(), digest); !errors.Is(err, ErrManifestInvalid) {
+		t.Errorf("manifest signed by another node: err = %v, want ErrManifestInvalid", err)
+	}
+	if _, err := client.FetchChunk(ctx, server, m, 0); !errors.Is(err, ErrPeerChoked) {
+		t.Errorf("choked past MaxWait: err = %v, want ErrPeerChoked", err)
+	}
+}
@@ -0,0 +1,500 @@
+// Package params is the runtime parameter service: a registry of tunable
+// values (exploration factor, scaling thresholds, retirement days, rate
+// limits, ...) that can be changed on a running node — from the admin API
+// or by executing a passed governance proposal — without a restart.
+//
+// Each parameter is registered with a getter and a setter that applies the
+// value to its live subsystem. Every change is kept in a bounded history,
+// written through to an optional Store and reported to the audit hook, and
+// any change can be reverted, which is itself recorded as a new change.
+// Replay reapplies the stored values after a restart: a passed governance
+// proposal is executed once, so its value must outlive the process.
+// Governance changes are watched for a probation period and rolled back
+// automatically if the subsystem's health regresses.
+package params
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
+	"github.com/tutu-network/tutu/internal/infra/governance"
+)
+
+// ─── Configuration ──────────────────────────────────────────────────────────
+
+// Config tunes the parameter service.
+type Config struct {
+	// MaxHistory caps how many changes are kept for inspection and revert.
+	MaxHistory int
+
+	// Probation is how long a governance change is watched before it is
+	// kept. Only parameters with a Health signal are watched.
+	Probation time.Duration
+
+	// RollbackTolerance is the fraction of its pre-change value that Health
+	// may drop by during probation before the change is rolled back.
+	RollbackTolerance float64
+
+	// Now is an injectable clock for testing.
+	Now func() time.Time
+}
+
+// DefaultConfig returns production defaults.
+func DefaultConfig() Config {
+	return Config{
+		MaxHistory:        500,
+		Probation:         time.Hour,
+		RollbackTolerance: 0.10,
+		Now:               time.Now,
+	}
+}
+
+// ─── Types ──────────────────────────────────────────────────────────────────
+
+// Spec registers a tunable parameter.
+type Spec struct {
+	Key         string
+	Description string
+
+	// Get returns the live value, formatted as Set accepts it.
+	Get func() string
+
+	// Set parses, validates and applies a value to the running subsystem.
+	// Errors should wrap domain.ErrInvalidParamValue.
+	Set func(value string) error
+
+	// Health optionally measures the subsystem the parameter tunes, higher
+	// being better; ok is false while there is too little data. Governance
+	// changes to a parameter with Health are rolled back if it regresses
+	// (see CheckProbation).
+	Health func() (value float64, ok bool)
+}
+
+// WithHealth returns the spec with a Health signal.
+func (s Spec) WithHealth(fn func() (float64, bool)) Spec {
+	s.Health = fn
+	return s
+}
+
+// Param is a parameter's current state.
+type Param struct {
+	Key         string `json:"key"`
+	Description string `json:"description"`
+	Value       string `json:"value"`
+	Default     string `json:"default"` // value at registration
+}
+
+// Change records one applied parameter change.
+type Change = domain.ParamChange
+
+// Store persists the change log. Implemented by *sqlite.DB.
+type Store interface {
+	InsertParamChange(c domain.ParamChange) error
+	ListParamChanges() ([]domain.ParamChange, error)
+}
+
+// ─── Service ────────────────────────────────────────────────────────────────
+
+// Service owns the parameter registry and change history.
+type Service struct {
+	mu       sync.Mutex
+	cfg      Config
+	specs    map[string]Spec
+	defaults map[string]string
+	history  []Change // oldest first
+	nextID   int64
+	store    Store // nil = changes are not persisted
+
+	// failed remembers governance proposals whose value was rejected so
+	// they are not retried on every execution pass.
+	failed map[string]bool
+
+	// probation holds governance changes still being watched, by key.
+	probation map[string]probation
+
+	// auditHook records every applied change (nil = disabled).
+	auditHook func(action, target, details string)
+}
+
+// NewService creates an empty parameter service.
+func NewService(cfg Config) *Service {
+	def := DefaultConfig()
+	if cfg.MaxHistory <= 0 {
+		cfg.MaxHistory = def.MaxHistory
+	}
+	if cfg.Probation <= 0 {
+		cfg.Probation = def.Probation
+	}
+	if cfg.RollbackTolerance <= 0 {
+		cfg.RollbackTolerance = def.RollbackTolerance
+	}
+	if cfg.Now == nil {
+		cfg.Now = def.Now
+	}
+	return &Service{
+		cfg:       cfg,
+		specs:     make(map[string]Spec),
+		defaults:  make(map[string]string),
+		failed:    make(map[string]bool),
+		probation: make(map[string]probation),
+		nextID:    1,
+	}
+}
+
+// SetAuditHook installs a callback invoked for every applied change.
+func (s *Service) SetAuditHook(fn func(action, target, details string)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.auditHook = fn
+}
+
+// SetStore writes every subsequent change through to st.
+func (s *Service) SetStore(st Store) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.store = st
+}
+
+// Replay loads the stored change log and reapplies, to every registered
+// parameter, the value of its latest change. It runs once at startup,
+// after registration; reapplying is not recorded as a new change. Values
+// the subsystem now rejects are skipped and reported.
+func (s *Service) Replay() ([]Change, []error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.store == nil {
+		return nil, nil
+	}
+	log, err := s.store.ListParamChanges()
+	if err != nil {
+		return nil, []error{fmt.Errorf("load param changes: %w", err)}
+	}
+
+	latest := make(map[string]Change)
+	for _, ch := range log {
+		latest[ch.Key] = ch
+		s.nextID = max(s.nextID, ch.ID+1)
+	}
+	s.history = append(log, s.history...)
+	if len(s.history) > s.cfg.MaxHistory {
+		s.history = s.history[len(s.history)-s.cfg.MaxHistory:]
+	}
+
+	var (
+		applied []Change
+		errs    []error
+	)
+	for _, key := range sortedKeys(latest) {
+		ch := latest[key]
+		spec, ok := s.specs[key]
+		if !ok || spec.Get() == ch.NewValue {
+			continue
+		}
+		if err := spec.Set(ch.NewValue); err != nil {
+			errs = append(errs, fmt.Errorf("replay change %d to %s: %w", ch.ID, key, err))
+			continue
+		}
+		applied = append(applied, ch)
+	}
+	return applied, errs
+}
+
+// Register adds a tunable parameter. Registering a key twice is an error.
+func (s *Service) Register(spec Spec) error {
+	if spec.Key == "" || spec.Get == nil || spec.Set == nil {
+		return fmt.Errorf("register %q: key, Get and Set are required", spec.Key)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.specs[spec.Key]; ok {
+		return fmt.Errorf("register %q: already registered", spec.Key)
+	}
+	s.specs[spec.Key] = spec
+	s.defaults[spec.Key] = spec.Get()
+	return nil
+}
+
+// List returns all parameters sorted by key.
+func (s *Service) List() []Param {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]Param, 0, len(s.specs))
+	for key, spec := range s.specs {
+		out = append(out, Param{
+			Key:         key,
+			Description: spec.Description,
+			Value:       spec.Get(),
+			Default:     s.defaults[key],
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Key < out[j].Key })
+	return out
+}
+
+// Get returns a parameter's current value.
+func (s *Service) Get(key string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	spec, ok := s.specs[key]
+	if !ok {
+		return "", fmt.Errorf("%s: %w", key, domain.ErrUnknownParam)
+	}
+	return spec.Get(), nil
+}
+
+// Set applies a new value to a running subsystem and records the change.
+func (s *Service) Set(key, value, source string) (Change, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.setLocked(key, value, source)
+}
+
+func (s *Service) setLocked(key, value, source string) (Change, error) {
+	spec, ok := s.specs[key]
+	if !ok {
+		return Change{}, fmt.Errorf("%s: %w", key, domain.ErrUnknownParam)
+	}
+	old := spec.Get()
+	if err := spec.Set(value); err != nil {
+		return Change{}, fmt.Errorf("set %s: %w", key, err)
+	}
+
+	ch := Change{
+		ID:        s.nextID,
+		Key:       key,
+		OldValue:  old,
+		NewValue:  spec.Get(),
+		Source:    source,
+		AppliedAt: s.cfg.Now(),
+	}
+	if s.store != nil {
+		if err := s.store.InsertParamChange(ch); err != nil {
+			// An unrecorded value would be lost on restart: undo it
+			_ = spec.Set(old)
+			return Change{}, fmt.Errorf("persist change to %s: %w", key, err)
+		}
+	}
+	s.nextID++
+	delete(s.probation, key) // a newer change supersedes the one watched
+	s.history = append(s.history, ch)
+	if len(s.history) > s.cfg.MaxHistory {
+		s.history = s.history[len(s.history)-s.cfg.MaxHistory:]
+	}
+	if s.auditHook != nil {
+		s.auditHook("param.set", key,
+			fmt.Sprintf("change=%d old=%s new=%s source=%s", ch.ID, ch.OldValue, ch.NewValue, source))
+	}
+	return ch, nil
+}
+
+// Revert restores the value a change replaced. The revert is recorded as
+// a new change with source "revert:<id>".
+func (s *Service) Revert(changeID int64) (Change, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if ch, ok := s.changeLocked(changeID); ok {
+		return s.setLocked(ch.Key, ch.OldValue, "revert:"+strconv.FormatInt(changeID, 10))
+	}
+	return Change{}, fmt.Errorf("change %d: %w", changeID, domain.ErrParamChangeNotFound)
+}
+
+// History returns up to limit changes, newest first (limit <= 0 = all kept).
+func (s *Service) History(limit int) []Change {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := len(s.history)
+	if limit <= 0 || limit > n {
+		limit = n
+	}
+	out := make([]Change, limit)
+	for i := 0; i < limit; i++ {
+		out[i] = s.history[n-1-i]
+	}
+	return out
+}
+
+// ─── Governance Execution ───────────────────────────────────────────────────
+
+// ExecuteProposals closes expired votes and applies every passed proposal
+// whose ParamKey names a registered parameter, marking it executed.
+// Proposals for unknown keys are left for other executors; proposals whose
+// value is rejected are reported once and not retried.
+func (s *Service) ExecuteProposals(gov *governance.Engine) ([]Change, []error) {
+	gov.ResolveExpired()
+	passed := governance.PropPassed
+
+	var (
+		changes []Change
+		errs    []error
+	)
+	for _, p := range gov.ListProposals(&passed) {
+		s.mu.Lock()
+		_, known := s.specs[p.ParamKey]
+		skip := !known || s.failed[p.ID]
+		s.mu.Unlock()
+		if skip {
+			continue
+		}
+
+		ch, err := s.setWatched(p.ParamKey, p.ParamValue, "governance:"+p.ID)
+		if err != nil {
+			s.mu.Lock()
+			s.failed[p.ID] = true
+			s.mu.Unlock()
+			errs = append(errs, fmt.Errorf("proposal %s: %w", p.ID, err))
+			continue
+		}
+		if err := gov.MarkExecuted(p.ID); err != nil {
+			errs = append(errs, err)
+		}
+		changes = append(changes, ch)
+	}
+	return changes, errs
+}
+
+// ─── Probation & Rollback ───────────────────────────────────────────────────
+// A passed proposal is applied without anyone watching the result, so a
+// governance change to a parameter with a Health signal is on probation:
+// Health is read before the change and again once Probation has passed,
+// and a regression beyond RollbackTolerance reverts the change. The revert
+// is recorded with source "rollback:<id>". A parameter changed again while
+// on probation is judged no further.
+
+// probation is a governance change being watched.
+type probation struct {
+	changeID int64
+	baseline float64 // Health before the change
+	until    time.Time
+}
+
+// Probation returns the governance changes currently being watched.
+func (s *Service) Probation() []Change {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []Change
+	for _, ch := range s.history {
+		if p, ok := s.probation[ch.Key]; ok && p.changeID == ch.ID {
+			out = append(out, ch)
+		}
+	}
+	return out
+}
+
+// setWatched is Set for governance changes: the change goes on probation
+// if its parameter has a Health signal with enough data.
+func (s *Service) setWatched(key, value, source string) (Change, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var (
+		baseline float64
+		ok       bool
+	)
+	if spec, known := s.specs[key]; known && spec.Health != nil {
+		baseline, ok = spec.Health()
+	}
+	ch, err := s.setLocked(key, value, source)
+	if err == nil && ok {
+		s.probation[key] = probation{changeID: ch.ID, baseline: baseline, until: ch.AppliedAt.Add(s.cfg.Probation)}
+	}
+	return ch, err
+}
+
+// CheckProbation judges the changes whose probation has ended, rolling
+// back those whose parameter's Health regressed. It returns the rollbacks.
+// Changes judged while Health has too little data are kept.
+func (s *Service) CheckProbation() ([]Change, []error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.cfg.Now()
+
+	var (
+		rollbacks []Change
+		errs      []error
+	)
+	for key, p := range s.probation {
+		if now.Before(p.until) {
+			continue
+		}
+		delete(s.probation, key)
+		health, ok := s.specs[key].Health()
+		if !ok || health >= p.baseline-s.cfg.RollbackTolerance*math.Abs(p.baseline) {
+			continue
+		}
+		old, found := s.changeLocked(p.changeID)
+		if !found {
+			continue
+		}
+		ch, err := s.setLocked(key, old.OldValue, "rollback:"+strconv.FormatInt(p.changeID, 10))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("roll back change %d: %w", p.changeID, err))
+			continue
+		}
+		if s.auditHook != nil {
+			s.auditHook("param.rollback", key,
+				fmt.Sprintf("change=%d health=%g baseline=%g", p.changeID, health, p.baseline))
+		}
+		rollbacks = append(rollbacks, ch)
+	}
+	sort.Slice(rollbacks, func(i, j int) bool { return rollbacks[i].ID < rollbacks[j].ID })
+	return rollbacks, errs
+}
+
+// sortedKeys returns the keys of m in order.
+func sortedKeys(m map[string]Change) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// changeLocked finds a change in the history.
+func (s *Service) changeLocked(id int64) (Change, bool) {
+	for _, ch := range s.history {
+		if ch.ID == id {
+			return ch, true
+		}
+	}
+	return Change{}, false
+}
+
+// ─── Typed Specs ────────────────────────────────────────────────────────────
+
+// Float builds a Spec for a float parameter bounded to [lo, hi]. set may
+// reject values that are in range but inconsistent with other settings.
+func Float(key, desc string, lo, hi float64, get func() float64, set func(float64) error) Spec {
+	return Spec{
+		Key:         key,
+		Description: desc,
+		Get:         func() string { return strconv.FormatFloat(get(), 'f', -1, 64) },
+		Set: func(v string) error {
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil || f < lo || f > hi {
+				return fmt.Errorf("%q not a number in [%g, %g]: %w", v, lo, hi, domain.ErrInvalidParamValue)
+			}
+			return set(f)
+		},
+	}
+}
+
+// Int builds a Spec for an integer parameter bounded to [lo, hi].
+func Int(key, desc string, lo, hi int, get func() int, set func(int) error) Spec {
+	return Spec{
+		Key:         key,
+		Description: desc,
+		Get:         func() string { return strconv.Itoa(get()) },
+		Set: func(v string) error {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < lo || n > hi {
+				return fmt.Errorf("%q not an integer in [%d, %d]: %w", v, lo, hi, domain.ErrInvalidParamValue)
+			}
+			return set(n)
+		},
+	}
+}
@@ -0,0 +1,292 @@
+package params
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
+	"github.com/tutu-network/tutu/internal/infra/governance"
+)
+
+// testService registers one float ("ml.c", default 1.5) and one bounded
+// int ("days", default 30) backed by plain variables.
+func testService(t *testing.T) (*Service, *float64, *int) {
+	t.Helper()
+	c, days := 1.5, 30
+	s := NewService(Config{})
+	must := func(err error) {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	must(s.Register(Float("ml.c", "exploration", 0.1, 10,
+		func() float64 { return c },
+		func(v float64) error { c = v; return nil })))
+	must(s.Register(Int("days", "retirement", 1, 365,
+		func() int { return days },
+		func(v int) error {
+			if v == 13 {
+				return fmt.Errorf("unlucky: %w", domain.ErrInvalidParamValue)
+			}
+			days = v
+			return nil
+		})))
+	return s, &c, &days
+}
+
+func TestService_Set(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		value   string
+		wantErr error
+	}{
+		{"float", "ml.c", "2.5", nil},
+		{"int", "days", "7", nil},
+		{"unknown key", "nope", "1", domain.ErrUnknownParam},
+		{"not a number", "ml.c", "fast", domain.ErrInvalidParamValue},
+		{"out of range", "days", "0", domain.ErrInvalidParamValue},
+		{"setter rejects", "days", "13", domain.ErrInvalidParamValue},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _, _ := testService(t)
+			ch, err := s.Set(tt.key, tt.value, "admin")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Set(%s=%s) = %v, want %v", tt.key, tt.value, err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if n := len(s.History(0)); n != 0 {
+					t.Errorf("failed Set recorded %d changes", n)
+				}
+				return
+			}
+			if ch.NewValue != tt.value {
+				t.Errorf("NewValue = %q, want %q", ch.NewValue, tt.value)
+			}
+			if got, _ := s.Get(tt.key); got != tt.value {
+				t.Errorf("Get = %q, want %q", got, tt.value)
+			}
+		})
+	}
+}
+
+func TestService_RevertAndAudit(t *testing.T) {
+	s, c, _ := testService(t)
+	var audited []string
+	s.SetAuditHook(func(action, target, details string) {
+		audited = append(audited, action+" "+target)
+	})
+
+	first, _ := s.Set("ml.c", "3", "admin")
+	s.Set("ml.c", "4", "admin")
+
+	rev, err := s.Revert(first.ID)
+	if err != nil {
+		t.Fatalf("Revert: %v", err)
+	}
+	if *c != 1.5 || rev.NewValue != "1.5" || rev.Source != fmt.Sprintf("revert:%d", first.ID) {
+		t.Errorf("after revert c = %v, change = %+v", *c, rev)
+	}
+	if h := s.History(0); len(h) != 3 || h[0].ID != rev.ID {
+		t.Errorf("History = %+v, want 3 changes newest first", h)
+	}
+	if len(audited) != 3 {
+		t.Errorf("audited = %v, want 3 param.set entries", audited)
+	}
+	if _, err := s.Revert(99); !errors.Is(err, domain.ErrParamChangeNotFound) {
+		t.Errorf("Revert(99) = %v, want ErrParamChangeNotFound", err)
+	}
+
+	list := s.List()
+	if len(list) != 2 || list[1].Key != "ml.c" || list[1].Default != "1.5" {
+		t.Errorf("List = %+v", list)
+	}
+}
+
+func TestService_CheckProbation(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := NewService(Config{Now: func() time.Time { return now }})
+	c, health, haveData := 1.5, 100.0, true
+	if err := s.Register(Float("ml.c", "exploration", 0.1, 10,
+		func() float64 { return c },
+		func(v float64) error { c = v; return nil }).
+		WithHealth(func() (float64, bool) { return health, haveData })); err != nil {
+		t.Fatal(err)
+	}
+	var audits []string
+	s.SetAuditHook(func(action, target, details string) { audits = append(audits, action) })
+
+	cfg := governance.DefaultEngineConfig()
+	cfg.VotingDuration = time.Millisecond
+	gov := governance.NewEngine(cfg)
+	gov.SetTotalCredits(1000)
+	propose := func(value string) {
+		t.Helper()
+		p, err := gov.CreateProposal("tune", "", governance.CatNetworkParam, "node-1", 500, "ml.c", value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := gov.OpenProposal(p.ID); err != nil {
+			t.Fatal(err)
+		}
+		if err := gov.CastVote(p.ID, "node-1", governance.VoteFor, 500); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	// A change whose health holds within tolerance is kept
+	propose("2")
+	if changes, _ := s.ExecuteProposals(gov); len(changes) != 1 || len(s.Probation()) != 1 {
+		t.Fatalf("changes = %+v, probation = %+v", changes, s.Probation())
+	}
+	health = 95
+	if rb, _ := s.CheckProbation(); len(rb) != 0 {
+		t.Errorf("rolled back before probation ended: %+v", rb)
+	}
+	now = now.Add(time.Hour)
+	if rb, _ := s.CheckProbation(); len(rb) != 0 || c != 2 || len(s.Probation()) != 0 {
+		t.Errorf("rollbacks = %+v, c = %v, want the change kept", rb, c)
+	}
+
+	// A regression rolls the change back
+	health = 100
+	propose("3")
+	s.ExecuteProposals(gov)
+	health = 80
+	now = now.Add(time.Hour)
+	rb, errs := s.CheckProbation()
+	if len(rb) != 1 || len(errs) != 0 || c != 2 || rb[0].Source != "rollback:2" {
+		t.Errorf("rollbacks = %+v, errs = %v, c = %v, want back to 2", rb, errs, c)
+	}
+	if audits[len(audits)-1] != "param.rollback" {
+		t.Errorf("audits = %v", audits)
+	}
+
+	// Without a baseline there is nothing to judge
+	haveData = false
+	propose("4")
+	s.ExecuteProposals(gov)
+	if len(s.Probation()) != 0 || c != 4 {
+		t.Errorf("probation = %+v, c = %v", s.Probation(), c)
+	}
+}
+
+func TestService_HistoryBounded(t *testing.T) {
+	s := NewService(Config{MaxHistory: 2})
+	v := 0
+	s.Register(Int("n", "", 0, 100, func() int { return v }, func(n int) error { v = n; return nil }))
+	for i := 1; i <= 5; i++ {
+		s.Set("n", fmt.Sprint(i), "admin")
+	}
+	h := s.History(0)
+	if len(h) != 2 || h[0].NewValue != "5" || h[1].NewValue != "4" {
+		t.Errorf("History = %+v, want last two changes", h)
+	}
+}
+
+func TestService_ExecuteProposals(t *testing.T) {
+	s, c, days := testService(t)
+
+	cfg := governance.DefaultEngineConfig()
+	cfg.VotingDuration = time.Millisecond
+	gov := governance.NewEngine(cfg)
+	gov.SetTotalCredits(1000)
+
+	propose := func(key, value string) string {
+		t.Helper()
+		p, err := gov.CreateProposal("tune "+key, "", governance.CatNetworkParam, "node-1", 500, key, value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := gov.OpenProposal(p.ID); err != nil {
+			t.Fatal(err)
+		}
+		if err := gov.CastVote(p.ID, "node-1", governance.VoteFor, 500); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(2 * time.Millisecond) // proposal IDs are millisecond timestamps
+		return p.ID
+	}
+	good := propose("ml.c", "2")
+	bad := propose("days", "13")
+	other := propose("not.ours", "x")
+
+	changes, errs := s.ExecuteProposals(gov)
+	if len(changes) != 1 || changes[0].Source != "governance:"+good || *c != 2 {
+		t.Errorf("changes = %+v, c = %v", changes, *c)
+	}
+	if len(errs) != 1 || *days != 30 {
+		t.Errorf("errs = %v, days = %d, want one rejection", errs, *days)
+	}
+
+	status := func(id string) governance.ProposalStatus {
+		p, _ := gov.GetProposal(id)
+		return p.Status
+	}
+	if status(good) != governance.PropExecuted || status(bad) != governance.PropPassed || status(other) != governance.PropPassed {
+		t.Errorf("statuses = %v %v %v", status(good), status(bad), status(other))
+	}
+
+	// The rejected proposal is not retried.
+	if changes, errs := s.ExecuteProposals(gov); len(changes) != 0 || len(errs) != 0 {
+		t.Errorf("second pass = %v %v, want nothing", changes, errs)
+	}
+}
+
+// memStore is an in-memory Store.
+type memStore struct {
+	changes []domain.ParamChange
+	fail    bool
+}
+
+func (m *memStore) InsertParamChange(c domain.ParamChange) error {
+	if m.fail {
+		return errors.New("disk full")
+	}
+	m.changes = append(m.changes, c)
+	return nil
+}
+
+func (m *memStore) ListParamChanges() ([]domain.ParamChange, error) {
+	return append([]domain.ParamChange(nil), m.changes...), nil
+}
+
+func TestService_ReplayAfterRestart(t *testing.T) {
+	store := &memStore{}
+	s, _, _ := testService(t)
+	s.SetStore(store)
+	for _, kv := range [][2]string{{"ml.c", "2.5"}, {"days", "7"}, {"ml.c", "4"}} {
+		if _, err := s.Set(kv[0], kv[1], "governance:p1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A restarted node registers the config values, then replays
+	s2, c, days := testService(t)
+	s2.SetStore(store)
+	store.changes = append(store.changes, domain.ParamChange{ID: 4, Key: "gone", NewValue: "1"})
+	replayed, errs := s2.Replay()
+	if len(errs) != 0 || len(replayed) != 2 || *c != 4 || *days != 7 {
+		t.Fatalf("replayed %+v (errs %v): ml.c = %g, days = %d, want 4 and 7", replayed, errs, *c, *days)
+	}
+	if h := s2.History(0); len(h) != 4 {
+		t.Errorf("history = %d changes, want the 4 stored", len(h))
+	}
+	ch, err := s2.Set("days", "9", "admin")
+	if err != nil || ch.ID != 5 {
+		t.Errorf("next change = %+v, %v; want ID 5", ch, err)
+	}
+	if p, _ := s2.Get("days"); p != "9" {
+		t.Errorf("days = %s, want 9", p)
+	}
+
+	// A change that cannot be stored is undone
+	store.fail = true
+	if _, err := s2.Set("days", "20", "admin"); err == nil || *days != 9 {
+		t.Errorf("unstored change: err = %v, days = %d; want an error and 9", err, *days)
+	}
+}
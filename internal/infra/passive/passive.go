@@ -5,7 +5,8 @@
 //   - Idle-aware capacity advertising (advertises more capacity when idle)
 //   - Popular model prefetching (pre-loads models likely to be requested)
 //   - Morning earnings report (summarizes overnight earnings)
-//   - Hardware tier classification for earnings estimation
+//   - Hardware tier classification for earnings estimation, from detected
+//     hardware and a standard inference benchmark
 package passive
 
 import (
@@ -44,6 +45,16 @@ func (t HardwareTier) String() string {
 	}
 }
 
+// ParseTier returns the tier named s, as gossiped in heartbeats.
+func ParseTier(s string) (HardwareTier, bool) {
+	for t := TierBasic; t <= TierUltra; t++ {
+		if t.String() == s {
+			return t, true
+		}
+	}
+	return TierBasic, false
+}
+
 // ClassifyHardware determines the hardware tier from specs.
 func ClassifyHardware(cpuCores int, vramGB float64) HardwareTier {
 	switch {
@@ -99,6 +110,20 @@ func NewCapacityAdvertiser(tier HardwareTier) *CapacityAdvertiser {
 	}
 }
 
+// SetTier updates the hardware tier after the node is re-profiled.
+func (ca *CapacityAdvertiser) SetTier(tier HardwareTier) {
+	ca.mu.Lock()
+	defer ca.mu.Unlock()
+	ca.tier = tier
+}
+
+// Tier returns the hardware tier being advertised.
+func (ca *CapacityAdvertiser) Tier() HardwareTier {
+	ca.mu.Lock()
+	defer ca.mu.Unlock()
+	return ca.tier
+}
+
 // UpdateIdleLevel updates the current idle level.
 func (ca *CapacityAdvertiser) UpdateIdleLevel(level domain.IdleLevel) {
 	ca.mu.Lock()
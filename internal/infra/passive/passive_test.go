@@ -1,6 +1,8 @@
 package passive
 
 import (
+	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -221,3 +223,104 @@ func TestEarningsReport_CreditsPerHour_ZeroDuration(t *testing.T) {
 		t.Errorf("CreditsPerHour() with zero duration = %f, want 0", rep.CreditsPerHour())
 	}
 }
+
+// ─── Hardware Profiler ──────────────────────────────────────────────────────
+
+func TestParseTier(t *testing.T) {
+	for _, tier := range []HardwareTier{TierBasic, TierMid, TierHigh, TierUltra} {
+		if got, ok := ParseTier(tier.String()); !ok || got != tier {
+			t.Errorf("ParseTier(%q) = %s, %v", tier.String(), got, ok)
+		}
+	}
+	if _, ok := ParseTier("quantum"); ok {
+		t.Error("ParseTier accepted an unknown tier")
+	}
+}
+
+func TestClassifyProfile(t *testing.T) {
+	cfg := DefaultProfilerConfig()
+	laptop := Hardware{CPUCores: 10, RAMBytes: 32 << 30}
+	if got := ClassifyProfile(laptop, 2000, cfg); got != TierMid {
+		t.Errorf("fast 32GB CPU-only node = %s, want mid", got)
+	}
+	if got := ClassifyProfile(laptop, 100, cfg); got != TierBasic {
+		t.Errorf("slow CPU-only node = %s, want basic", got)
+	}
+	small := Hardware{CPUCores: 4, RAMBytes: 8 << 30}
+	if got := ClassifyProfile(small, 2000, cfg); got != TierBasic {
+		t.Errorf("8GB CPU-only node = %s, want basic", got)
+	}
+	gpu := Hardware{CPUCores: 8, GPUs: []GPU{{Name: "RTX 4090", VRAMBytes: 24 << 30}}}
+	if got := ClassifyProfile(gpu, 50, cfg); got != TierHigh {
+		t.Errorf("24GB GPU node = %s, want high", got)
+	}
+}
+
+func TestHardware_Fingerprint(t *testing.T) {
+	a := Hardware{CPUCores: 8, RAMBytes: 16 << 30, GPUs: []GPU{{"A", 8 << 30}, {"B", 12 << 30}}}
+	b := Hardware{CPUCores: 8, RAMBytes: 16<<30 - 64<<20, GPUs: []GPU{{"B", 12 << 30}, {"A", 8 << 30}}}
+	if a.Fingerprint() != b.Fingerprint() {
+		t.Error("GPU order or firmware-reserved RAM changed the fingerprint")
+	}
+	c := a
+	c.GPUs = a.GPUs[:1]
+	if a.Fingerprint() == c.Fingerprint() {
+		t.Error("removing a GPU did not change the fingerprint")
+	}
+}
+
+func TestProfiler_RebenchmarksOnHardwareChange(t *testing.T) {
+	hw := Hardware{CPUCores: 8, RAMBytes: 16 << 30}
+	benches := 0
+	p := NewProfiler(DefaultProfilerConfig(), func() Hardware { return hw }, func(context.Context) (float64, error) {
+		benches++
+		return 100, nil
+	})
+	var changes []HardwareTier
+	p.OnChange(func(prof Profile) { changes = append(changes, prof.Tier) })
+
+	if _, ok := p.Profile(); ok {
+		t.Fatal("profiled before the first refresh")
+	}
+	prof, changed, err := p.Refresh(context.Background())
+	if err != nil || !changed || prof.Tier != TierBasic || prof.TierName != "basic" {
+		t.Fatalf("first Refresh = %+v, %v, %v", prof, changed, err)
+	}
+	if _, changed, _ := p.Refresh(context.Background()); changed || benches != 1 {
+		t.Errorf("unchanged hardware re-benchmarked (changed=%v, benches=%d)", changed, benches)
+	}
+
+	hw.GPUs = []GPU{{Name: "RTX 3060", VRAMBytes: 12 << 30}}
+	prof, changed, _ = p.Refresh(context.Background())
+	if !changed || benches != 2 || p.Tier() != TierHigh {
+		t.Errorf("after adding a GPU: changed=%v benches=%d tier=%s", changed, benches, p.Tier())
+	}
+	if len(changes) != 2 || changes[1] != TierHigh {
+		t.Errorf("OnChange tiers = %v, want [basic high]", changes)
+	}
+}
+
+func TestProfiler_BenchmarkError(t *testing.T) {
+	boom := errors.New("boom")
+	p := NewProfiler(DefaultProfilerConfig(), func() Hardware { return Hardware{CPUCores: 2} },
+		func(context.Context) (float64, error) { return 0, boom })
+	if _, _, err := p.Refresh(context.Background()); !errors.Is(err, boom) {
+		t.Errorf("err = %v, want boom", err)
+	}
+	if _, ok := p.Profile(); ok {
+		t.Error("failed benchmark recorded a profile")
+	}
+}
+
+func TestBenchmark(t *testing.T) {
+	tps, err := Benchmark(context.Background(), 8)
+	if err != nil || tps <= 0 {
+		t.Fatalf("Benchmark = %f, %v", tps, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Benchmark(ctx, 8); !errors.Is(err, context.Canceled) {
+		t.Errorf("cancelled Benchmark err = %v", err)
+	}
+}
@@ -0,0 +1,335 @@
+package passive
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ─── Hardware Profiler ──────────────────────────────────────────────────────
+// The profiler decides the node's HardwareTier from what it measures rather
+// than what it is told:
+//
+//  1. Detect CPU cores, RAM and every GPU with its VRAM
+//  2. Run a short, fixed inference workload — the decode loop of a small
+//     transformer — and record its tokens per second
+//  3. Classify: VRAM sets the tier; a CPU-only node with enough RAM and a
+//     fast enough benchmark counts as mid-tier (e.g. unified-memory laptops)
+//  4. Every CheckInterval the hardware is detected again, and the benchmark
+//     re-run only if its fingerprint changed — a GPU added or removed, RAM
+//     upgraded. OnChange listeners hear about every new profile
+
+// GPU is a detected graphics card.
+type GPU struct {
+	Name      string `json:"name"`
+	VRAMBytes uint64 `json:"vram_bytes"`
+}
+
+// Hardware is what the profiler detected on the host.
+type Hardware struct {
+	CPUCores int    `json:"cpu_cores"`
+	RAMBytes uint64 `json:"ram_bytes"` // 0 = unknown
+	GPUs     []GPU  `json:"gpus,omitempty"`
+}
+
+// VRAMGB returns the total VRAM across GPUs in GiB.
+func (h Hardware) VRAMGB() float64 {
+	var total uint64
+	for _, g := range h.GPUs {
+		total += g.VRAMBytes
+	}
+	return float64(total) / (1 << 30)
+}
+
+// RAMGB returns installed RAM in GiB.
+func (h Hardware) RAMGB() float64 {
+	return float64(h.RAMBytes) / (1 << 30)
+}
+
+// Fingerprint identifies the hardware configuration. RAM is rounded to the
+// GiB so the few megabytes firmware reserves do not count as a change.
+func (h Hardware) Fingerprint() string {
+	gpus := make([]string, len(h.GPUs))
+	for i, g := range h.GPUs {
+		gpus[i] = fmt.Sprintf("%s/%d", g.Name, g.VRAMBytes>>20)
+	}
+	sort.Strings(gpus)
+	key := fmt.Sprintf("cpu=%d ram=%.0f gpu=%s", h.CPUCores, math.Round(h.RAMGB()), strings.Join(gpus, ","))
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:8])
+}
+
+// Profile is the result of profiling the node.
+type Profile struct {
+	Hardware      Hardware     `json:"hardware"`
+	Fingerprint   string       `json:"fingerprint"`
+	BenchmarkTPS  float64      `json:"benchmark_tps"` // tokens/sec on the standard workload
+	Tier          HardwareTier `json:"tier"`
+	TierName      string       `json:"tier_name"`
+	BenchmarkedAt time.Time    `json:"benchmarked_at"`
+}
+
+// ProfilerConfig tunes the hardware profiler.
+type ProfilerConfig struct {
+	CheckInterval time.Duration // hardware re-detection period (default: 10m)
+	BenchTokens   int           // tokens decoded by the built-in benchmark (default: 256)
+	CPUMidTPS     float64       // benchmark a CPU-only node needs for TierMid (default: 1500)
+	CPUMidRAMGB   float64       // RAM a CPU-only node needs for TierMid (default: 16)
+	Now           func() time.Time
+}
+
+// DefaultProfilerConfig returns production defaults. The built-in
+// benchmark takes well under a second on a current laptop.
+func DefaultProfilerConfig() ProfilerConfig {
+	return ProfilerConfig{
+		CheckInterval: 10 * time.Minute,
+		BenchTokens:   256,
+		CPUMidTPS:     1500,
+		CPUMidRAMGB:   16,
+		Now:           time.Now,
+	}
+}
+
+// ClassifyProfile assigns a tier from detected hardware and a benchmark
+// result.
+func ClassifyProfile(hw Hardware, tps float64, cfg ProfilerConfig) HardwareTier {
+	tier := ClassifyHardware(hw.CPUCores, hw.VRAMGB())
+	if tier == TierBasic && tps >= cfg.CPUMidTPS && hw.RAMGB() >= cfg.CPUMidRAMGB {
+		tier = TierMid
+	}
+	return tier
+}
+
+// Profiler detects, benchmarks and classifies the node's hardware. It is
+// safe for concurrent use.
+type Profiler struct {
+	mu       sync.Mutex
+	cfg      ProfilerConfig
+	detect   func() Hardware
+	bench    func(ctx context.Context) (float64, error)
+	profile  Profile
+	profiled bool
+	onChange func(Profile)
+	benchMu  sync.Mutex // one benchmark at a time
+}
+
+// NewProfiler creates a profiler. detect reads the host's hardware; bench
+// runs the benchmark and returns tokens per second (nil = the built-in
+// Benchmark).
+func NewProfiler(cfg ProfilerConfig, detect func() Hardware, bench func(ctx context.Context) (float64, error)) *Profiler {
+	def := DefaultProfilerConfig()
+	if cfg.CheckInterval <= 0 {
+		cfg.CheckInterval = def.CheckInterval
+	}
+	if cfg.BenchTokens <= 0 {
+		cfg.BenchTokens = def.BenchTokens
+	}
+	if cfg.CPUMidTPS <= 0 {
+		cfg.CPUMidTPS = def.CPUMidTPS
+	}
+	if cfg.CPUMidRAMGB <= 0 {
+		cfg.CPUMidRAMGB = def.CPUMidRAMGB
+	}
+	if cfg.Now == nil {
+		cfg.Now = def.Now
+	}
+	if bench == nil {
+		tokens := cfg.BenchTokens
+		bench = func(ctx context.Context) (float64, error) { return Benchmark(ctx, tokens) }
+	}
+	return &Profiler{cfg: cfg, detect: detect, bench: bench}
+}
+
+// OnChange installs the callback run whenever a new profile is recorded.
+func (p *Profiler) OnChange(fn func(Profile)) {
+	p.mu.Lock()
+	p.onChange = fn
+	p.mu.Unlock()
+}
+
+// Profile returns the latest profile and whether the node has been
+// profiled yet.
+func (p *Profiler) Profile() (Profile, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.profile, p.profiled
+}
+
+// Tier returns the node's tier (TierBasic until profiled).
+func (p *Profiler) Tier() HardwareTier {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.profile.Tier
+}
+
+// Refresh detects the hardware and re-benchmarks if it changed since the
+// last profile. It returns the current profile and whether it is new.
+func (p *Profiler) Refresh(ctx context.Context) (Profile, bool, error) {
+	hw := p.detect()
+	p.mu.Lock()
+	same := p.profiled && p.profile.Fingerprint == hw.Fingerprint()
+	current := p.profile
+	p.mu.Unlock()
+	if same {
+		return current, false, nil
+	}
+	prof, err := p.benchmark(ctx, hw)
+	return prof, err == nil, err
+}
+
+// Rebenchmark re-runs the benchmark regardless of hardware changes.
+func (p *Profiler) Rebenchmark(ctx context.Context) (Profile, error) {
+	return p.benchmark(ctx, p.detect())
+}
+
+// benchmark measures hw and records the resulting profile.
+func (p *Profiler) benchmark(ctx context.Context, hw Hardware) (Profile, error) {
+	p.benchMu.Lock()
+	defer p.benchMu.Unlock()
+
+	tps, err := p.bench(ctx)
+	if err != nil {
+		return Profile{}, fmt.Errorf("passive: benchmark: %w", err)
+	}
+	tier := ClassifyProfile(hw, tps, p.cfg)
+	prof := Profile{
+		Hardware:      hw,
+		Fingerprint:   hw.Fingerprint(),
+		BenchmarkTPS:  tps,
+		Tier:          tier,
+		TierName:      tier.String(),
+		BenchmarkedAt: p.cfg.Now(),
+	}
+
+	p.mu.Lock()
+	p.profile, p.profiled = prof, true
+	onChange := p.onChange
+	p.mu.Unlock()
+
+	if onChange != nil {
+		onChange(prof)
+	}
+	return prof, nil
+}
+
+// Run profiles the node now and re-checks the hardware every interval
+// until ctx is done.
+func (p *Profiler) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.cfg.CheckInterval)
+	defer ticker.Stop()
+
+	p.Refresh(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.Refresh(ctx)
+		}
+	}
+}
+
+// ─── Benchmark ──────────────────────────────────────────────────────────────
+
+// Shape of the benchmark model: a 4-layer decoder with a 256-wide hidden
+// state and a 1024-wide feed-forward block, about 8 MB of float32 weights.
+const (
+	benchLayers = 4
+	benchHidden = 256
+	benchFFN    = 1024
+)
+
+// Benchmark decodes tokens through a small fixed transformer-shaped model
+// and returns tokens per second. The weights are deterministic, so every
+// node runs exactly the same arithmetic; the work is spread over all
+// cores, as a real inference backend would.
+func Benchmark(ctx context.Context, tokens int) (float64, error) {
+	type layer struct{ up, down []float32 }
+	layers := make([]layer, benchLayers)
+	seed := uint32(2463534242)
+	next := func() float32 {
+		seed ^= seed << 13
+		seed ^= seed >> 17
+		seed ^= seed << 5
+		return float32(seed)/float32(math.MaxUint32) - 0.5
+	}
+	for i := range layers {
+		layers[i].up = make([]float32, benchFFN*benchHidden)
+		layers[i].down = make([]float32, benchHidden*benchFFN)
+		for j := range layers[i].up {
+			layers[i].up[j] = next() * 0.1
+			layers[i].down[j] = next() * 0.1
+		}
+	}
+
+	workers := runtime.GOMAXPROCS(0)
+	state := make([]float32, benchHidden)
+	ffn := make([]float32, benchFFN)
+	for i := range state {
+		state[i] = next()
+	}
+
+	start := time.Now()
+	for t := 0; t < tokens; t++ {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+		for _, l := range layers {
+			matVec(ffn, l.up, state, workers)
+			for i, v := range ffn {
+				if v < 0 {
+					ffn[i] = 0 // ReLU
+				}
+			}
+			matVec(state, l.down, ffn, workers)
+			normalize(state)
+		}
+	}
+	elapsed := time.Since(start).Seconds()
+	if elapsed <= 0 {
+		elapsed = 1e-9
+	}
+	return float64(tokens) / elapsed, nil
+}
+
+// matVec computes out = m·v for a row-major m, splitting rows over workers.
+func matVec(out, m, v []float32, workers int) {
+	rows, cols := len(out), len(v)
+	chunk := (rows + workers - 1) / workers
+	var wg sync.WaitGroup
+	for lo := 0; lo < rows; lo += chunk {
+		hi := min(lo+chunk, rows)
+		wg.Add(1)
+		go func(lo, hi int) {
+			defer wg.Done()
+			for r := lo; r < hi; r++ {
+				row := m[r*cols : (r+1)*cols]
+				var sum float32
+				for c, x := range row {
+					sum += x * v[c]
+				}
+				out[r] = sum
+			}
+		}(lo, hi)
+	}
+	wg.Wait()
+}
+
+// normalize scales v to unit RMS so activations stay bounded.
+func normalize(v []float32) {
+	var sq float64
+	for _, x := range v {
+		sq += float64(x) * float64(x)
+	}
+	rms := math.Sqrt(sq/float64(len(v))) + 1e-6
+	for i := range v {
+		v[i] = float32(float64(v[i]) / rms)
+	}
+}
@@ -0,0 +1,256 @@
+package redundancy
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/tutu-network/tutu/internal/domain"
+)
+
+// ─── Verification Policies ──────────────────────────────────────────────────
+// What counts as "the same result" depends on the task: embeddings of the
+// same input are bit-identical, chat completions only mean the same thing,
+// and two fine-tuning runs never match but should land on a similar loss.
+// A Registry maps each compare mode to a Verifier, and each task type to a
+// Policy — a mode plus the similarity at which outputs agree. A request
+// that names a task type but no mode is verified by that type's policy.
+//
+// The similarity doubles as a graded accuracy signal: every replica's
+// similarity to the consensus output is reported with its outcome. A
+// verifier that also implements Acceptor can veto the consensus output
+// itself (a loss above the limit); agreeing nodes are then not paid.
+
+// Verifier scores how closely replica outputs agree.
+type Verifier interface {
+	// Similarity returns a function scoring outputs[i] against outputs[j]
+	// in [0, 1], where 1 is identical.
+	Similarity(ctx context.Context, req Request, outputs []string) (func(i, j int) float64, error)
+}
+
+// Acceptor is implemented by verifiers that also judge a single output.
+type Acceptor interface {
+	// Accept returns an error if output must not be paid for even when
+	// the replicas agree on it.
+	Accept(output string) error
+}
+
+// Policy is how results of one task type are verified.
+type Policy struct {
+	Mode      domain.CompareMode `json:"mode"`
+	Threshold float64            `json:"threshold"` // outputs agree at similarity >= Threshold
+}
+
+// Registry holds the verifiers and per-task-type policies. It is safe for
+// concurrent use.
+type Registry struct {
+	mu        sync.RWMutex
+	verifiers map[domain.CompareMode]Verifier
+	policies  map[domain.TaskType]Policy
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		verifiers: make(map[domain.CompareMode]Verifier),
+		policies:  make(map[domain.TaskType]Policy),
+	}
+}
+
+// RegisterVerifier installs v for mode, replacing any previous one.
+func (r *Registry) RegisterVerifier(mode domain.CompareMode, v Verifier) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.verifiers[mode] = v
+}
+
+// Verifier returns the verifier for mode.
+func (r *Registry) Verifier(mode domain.CompareMode) (Verifier, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	v, ok := r.verifiers[mode]
+	return v, ok
+}
+
+// SetPolicy sets the policy for taskType. The mode must have a verifier
+// and the threshold must lie in (0, 1].
+func (r *Registry) SetPolicy(taskType domain.TaskType, p Policy) error {
+	if taskType == "" {
+		return fmt.Errorf("policy needs a task type: %w", domain.ErrRedundancyInvalid)
+	}
+	if p.Threshold <= 0 || p.Threshold > 1 {
+		return fmt.Errorf("threshold %.2f for %s outside (0, 1]: %w", p.Threshold, taskType, domain.ErrRedundancyInvalid)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.verifiers[p.Mode]; !ok {
+		return fmt.Errorf("no verifier for compare mode %q: %w", p.Mode, domain.ErrRedundancyInvalid)
+	}
+	r.policies[taskType] = p
+	return nil
+}
+
+// Policy returns the policy for taskType.
+func (r *Registry) Policy(taskType domain.TaskType) (Policy, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	p, ok := r.policies[taskType]
+	return p, ok
+}
+
+// Policies returns a copy of every task type's policy.
+func (r *Registry) Policies() map[domain.TaskType]Policy {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make(map[domain.TaskType]Policy, len(r.policies))
+	for t, p := range r.policies {
+		out[t] = p
+	}
+	return out
+}
+
+// Modes returns the registered compare modes, sorted.
+func (r *Registry) Modes() []domain.CompareMode {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]domain.CompareMode, 0, len(r.verifiers))
+	for m := range r.verifiers {
+		out = append(out, m)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out
+}
+
+// defaultRegistry returns the built-in verifiers and policies:
+//
+//	EMBEDDING          exact    1.0
+//	INFERENCE, AGENT   embedding Config.Similarity (exact without an
+//	                   Embed hook or Config.EmbedModel)
+//	FINE_TUNE          loss     1.0
+func defaultRegistry(cfg Config, hooks Hooks) *Registry {
+	r := NewRegistry()
+	r.RegisterVerifier(domain.CompareExact, HashVerifier{})
+	r.RegisterVerifier(domain.CompareLoss, LossVerifier{Tolerance: cfg.LossTolerance, MaxLoss: cfg.MaxLoss})
+	chat := Policy{Mode: domain.CompareExact, Threshold: 1}
+	if hooks.Embed != nil {
+		r.RegisterVerifier(domain.CompareEmbedding, EmbeddingVerifier{Embed: hooks.Embed})
+		if cfg.EmbedModel != "" {
+			chat = Policy{Mode: domain.CompareEmbedding, Threshold: cfg.Similarity}
+		}
+	}
+	r.policies[domain.TaskEmbedding] = Policy{Mode: domain.CompareExact, Threshold: 1}
+	r.policies[domain.TaskInference] = chat
+	r.policies[domain.TaskAgent] = chat
+	r.policies[domain.TaskFineTune] = Policy{Mode: domain.CompareLoss, Threshold: 1}
+	return r
+}
+
+// ─── Built-in Verifiers ─────────────────────────────────────────────────────
+
+// HashVerifier treats outputs as equal when they are byte-identical after
+// trimming whitespace.
+type HashVerifier struct{}
+
+// Similarity implements Verifier.
+func (HashVerifier) Similarity(_ context.Context, _ Request, outputs []string) (func(i, j int) float64, error) {
+	digests := make([][32]byte, len(outputs))
+	for i, o := range outputs {
+		digests[i] = sha256.Sum256([]byte(strings.TrimSpace(o)))
+	}
+	return func(i, j int) float64 { return boolScore(digests[i] == digests[j]) }, nil
+}
+
+// EmbeddingVerifier scores outputs by the cosine similarity of their
+// embeddings under the request's embedding model.
+type EmbeddingVerifier struct {
+	Embed func(ctx context.Context, model string, texts []string) ([][]float32, error)
+}
+
+// Similarity implements Verifier.
+func (v EmbeddingVerifier) Similarity(ctx context.Context, req Request, outputs []string) (func(i, j int) float64, error) {
+	if req.EmbedModel == "" {
+		return nil, fmt.Errorf("embedding comparison needs an embedding model: %w", domain.ErrRedundancyInvalid)
+	}
+	vecs, err := v.Embed(ctx, req.EmbedModel, outputs)
+	if err != nil {
+		return nil, fmt.Errorf("embed replica outputs: %w", err)
+	}
+	if len(vecs) != len(outputs) {
+		return nil, fmt.Errorf("embed replica outputs: got %d vectors for %d outputs", len(vecs), len(outputs))
+	}
+	return func(i, j int) float64 { return math.Max(0, cosine(vecs[i], vecs[j])) }, nil
+}
+
+// LossVerifier compares the final loss of training runs. An output is
+// either a bare number or a JSON object with a "loss" field. Two losses
+// agree when they differ by at most Tolerance relative to the larger one;
+// with MaxLoss set, a loss above it is not accepted.
+type LossVerifier struct {
+	Tolerance float64 // relative, e.g. 0.05 = 5%
+	MaxLoss   float64 // 0 = no limit
+}
+
+// Similarity implements Verifier. Outputs without a loss agree with
+// nothing, themselves included.
+func (v LossVerifier) Similarity(_ context.Context, _ Request, outputs []string) (func(i, j int) float64, error) {
+	losses := make([]float64, len(outputs))
+	for i, o := range outputs {
+		l, err := ParseLoss(o)
+		if err != nil {
+			l = math.NaN()
+		}
+		losses[i] = l
+	}
+	return func(i, j int) float64 {
+		a, b := losses[i], losses[j]
+		if math.IsNaN(a) || math.IsNaN(b) {
+			return 0
+		}
+		scale := math.Max(math.Abs(a), math.Abs(b))
+		return boolScore(math.Abs(a-b) <= v.Tolerance*scale)
+	}, nil
+}
+
+// Accept implements Acceptor.
+func (v LossVerifier) Accept(output string) error {
+	l, err := ParseLoss(output)
+	if err != nil {
+		return err
+	}
+	if v.MaxLoss > 0 && l > v.MaxLoss {
+		return fmt.Errorf("loss %.4f above the %.4f limit", l, v.MaxLoss)
+	}
+	return nil
+}
+
+// ParseLoss extracts the final loss from a training output.
+func ParseLoss(output string) (float64, error) {
+	s := strings.TrimSpace(output)
+	l, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		var report struct {
+			Loss *float64 `json:"loss"`
+		}
+		if json.Unmarshal([]byte(s), &report) != nil || report.Loss == nil {
+			return 0, fmt.Errorf("output has no loss")
+		}
+		l = *report.Loss
+	}
+	if math.IsNaN(l) || math.IsInf(l, 0) {
+		return 0, fmt.Errorf("loss is not finite")
+	}
+	return l, nil
+}
+
+func boolScore(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
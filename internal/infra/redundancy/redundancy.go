@@ -0,0 +1,456 @@
+// Package redundancy implements verification by redundant execution: an
+// optional N-of-M mode for high-value inference requests.
+//
+// How a verification runs:
+//  1. The request runs on M distinct nodes concurrently
+//  2. Successful outputs are grouped by the verifier the request's compare
+//     mode, or its task type's policy, selects (see policy.go)
+//  3. If the largest group has at least N members it is the consensus;
+//     N must be a strict majority of M, so there is never a tie
+//  4. Only agreeing nodes are paid, and only if the verifier accepts the
+//     consensus output; dissenters are reported to reputation and anomaly
+//     detection through the outcome hook
+//
+// Without consensus nobody is paid and nobody is penalized: the request
+// itself is reported as unverified. A replica whose request never reached
+// its node (ErrNotDispatched) fails without being reported at all.
+package redundancy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
+)
+
+// ─── Configuration ──────────────────────────────────────────────────────────
+
+// Config tunes the corrector.
+type Config struct {
+	SelfID        string        // this node's ID; it never pays itself
+	Replicas      int           // default M (default: 3)
+	Quorum        int           // default N (default: 2)
+	Similarity    float64       // cosine threshold in embedding mode (default: 0.95)
+	EmbedModel    string        // model used to embed outputs in embedding mode
+	LossTolerance float64       // relative loss difference that still agrees in loss mode (default: 0.05)
+	MaxLoss       float64       // loss mode: consensus losses above this are not paid (0 = no limit)
+	Timeout       time.Duration // per-replica deadline (default: 2m)
+	History       int           // verdicts kept for inspection (default: 100)
+
+	// Now is an injectable clock for testing.
+	Now func() time.Time
+}
+
+// DefaultConfig returns production defaults.
+func DefaultConfig() Config {
+	return Config{
+		SelfID:        "node-local",
+		Replicas:      3,
+		Quorum:        2,
+		Similarity:    0.95,
+		LossTolerance: 0.05,
+		Timeout:       2 * time.Minute,
+		History:       100,
+		Now:           time.Now,
+	}
+}
+
+// Request is a request to execute redundantly.
+type Request struct {
+	TaskID     string             `json:"task_id"`
+	Model      string             `json:"model"`
+	Prompt     string             `json:"prompt"`
+	MaxTokens  int                `json:"max_tokens,omitempty"`
+	TaskType   domain.TaskType    `json:"task_type,omitempty"`   // selects the verification policy
+	Mode       domain.CompareMode `json:"mode,omitempty"`        // overrides the policy; default exact
+	EmbedModel string             `json:"embed_model,omitempty"` // embedding mode model; default Config.EmbedModel
+	Replicas   int                `json:"replicas,omitempty"`    // M; 0 = config default
+	Quorum     int                `json:"quorum,omitempty"`      // N; 0 = config default
+	Payment    int64              `json:"payment,omitempty"`     // credits per agreeing node
+}
+
+// ErrNotDispatched is wrapped by Execute errors for requests that never
+// reached the node. The replica fails, but the node is not held to account
+// for it.
+var ErrNotDispatched = errors.New("redundancy: request not dispatched")
+
+// Hooks connect the corrector to the node. Nodes and Execute are required.
+type Hooks struct {
+	// Nodes returns up to n candidate nodes, best first.
+	Nodes func(n int) []string
+
+	// Execute runs the request on node and returns its output.
+	Execute func(ctx context.Context, node string, req Request) (string, error)
+
+	// Embed computes output embeddings (required for embedding mode).
+	Embed func(ctx context.Context, model string, texts []string) ([][]float32, error)
+
+	// Pay credits an agreeing node. ctx is the Verify call's, so the payer
+	// can tell who the request was made for.
+	Pay func(ctx context.Context, node string, amount int64, taskID string) error
+
+	// Outcome reports each replica's outcome and its similarity to the
+	// consensus output, e.g. to reputation and anomaly detection.
+	Outcome func(node string, outcome domain.ReplicaOutcome, agreement float64, taskID string, latency time.Duration)
+}
+
+// Stats summarizes corrector activity.
+type Stats struct {
+	Verifications int64 `json:"verifications"`
+	Consensus     int64 `json:"consensus"`
+	NoConsensus   int64 `json:"no_consensus"`
+	Dissents      int64 `json:"dissents"`
+	Failures      int64 `json:"failures"`
+	Withheld      int64 `json:"withheld"` // consensus outputs the verifier rejected
+	CreditsPaid   int64 `json:"credits_paid"`
+}
+
+// ─── Corrector ──────────────────────────────────────────────────────────────
+
+// Corrector runs redundant executions and settles them.
+type Corrector struct {
+	mu       sync.Mutex
+	cfg      Config
+	hooks    Hooks
+	policies *Registry
+	verdicts []domain.RedundancyVerdict // newest last
+	stats    Stats
+}
+
+// New creates a corrector.
+func New(cfg Config, hooks Hooks) *Corrector {
+	def := DefaultConfig()
+	if cfg.SelfID == "" {
+		cfg.SelfID = def.SelfID
+	}
+	if cfg.Replicas <= 0 {
+		cfg.Replicas = def.Replicas
+	}
+	if cfg.Quorum <= 0 {
+		cfg.Quorum = def.Quorum
+	}
+	if cfg.Similarity <= 0 || cfg.Similarity > 1 {
+		cfg.Similarity = def.Similarity
+	}
+	if cfg.LossTolerance <= 0 {
+		cfg.LossTolerance = def.LossTolerance
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = def.Timeout
+	}
+	if cfg.History <= 0 {
+		cfg.History = def.History
+	}
+	if cfg.Now == nil {
+		cfg.Now = def.Now
+	}
+	return &Corrector{cfg: cfg, hooks: hooks, policies: defaultRegistry(cfg, hooks)}
+}
+
+// Policies returns the corrector's verification policy registry, where
+// verifiers and per-task-type policies can be added or replaced.
+func (c *Corrector) Policies() *Registry { return c.policies }
+
+// Verify executes req on M nodes and settles the result. The verdict is
+// returned even without consensus, together with ErrNoConsensus.
+func (c *Corrector) Verify(ctx context.Context, req Request) (domain.RedundancyVerdict, error) {
+	if req.Replicas <= 0 {
+		req.Replicas = c.cfg.Replicas
+	}
+	if req.Quorum <= 0 {
+		req.Quorum = c.cfg.Quorum
+	}
+	if req.EmbedModel == "" {
+		req.EmbedModel = c.cfg.EmbedModel
+	}
+	if req.TaskID == "" {
+		req.TaskID = fmt.Sprintf("redundant-%d", c.cfg.Now().UnixNano())
+	}
+	if err := c.validate(req); err != nil {
+		return domain.RedundancyVerdict{}, err
+	}
+	policy, verifier, err := c.resolve(&req)
+	if err != nil {
+		return domain.RedundancyVerdict{}, err
+	}
+
+	nodes := dedupe(c.hooks.Nodes(req.Replicas))
+	if len(nodes) < req.Replicas {
+		return domain.RedundancyVerdict{}, fmt.Errorf("need %d nodes, have %d: %w",
+			req.Replicas, len(nodes), domain.ErrNotEnoughReplicas)
+	}
+	nodes = nodes[:req.Replicas]
+
+	replicas, dispatched := c.execute(ctx, nodes, req)
+	groups, sim, err := c.group(ctx, req, verifier, policy.Threshold, replicas)
+	if err != nil {
+		return domain.RedundancyVerdict{}, err
+	}
+
+	verdict := domain.RedundancyVerdict{
+		TaskID:    req.TaskID,
+		Model:     req.Model,
+		TaskType:  req.TaskType,
+		Mode:      req.Mode,
+		Threshold: policy.Threshold,
+		Quorum:    req.Quorum,
+		Replicas:  replicas,
+	}
+	winner := -1
+	for i, g := range groups {
+		if len(g) >= req.Quorum && (winner < 0 || len(g) > len(groups[winner])) {
+			winner = i
+		}
+	}
+	if winner >= 0 {
+		verdict.Consensus = true
+		verdict.Agreeing = len(groups[winner])
+		verdict.Output = replicas[groups[winner][0]].Output
+		for _, g := range groups {
+			for _, i := range g {
+				verdict.Replicas[i].Agreement = sim(groups[winner][0], i)
+			}
+		}
+		if a, ok := verifier.(Acceptor); ok {
+			if err := a.Accept(verdict.Output); err != nil {
+				verdict.Withheld = err.Error()
+			}
+		}
+	}
+	c.settle(ctx, &verdict, groups, winner, req.Payment, dispatched)
+	verdict.DecidedAt = c.cfg.Now()
+
+	c.mu.Lock()
+	c.verdicts = append(c.verdicts, verdict)
+	if len(c.verdicts) > c.cfg.History {
+		c.verdicts = c.verdicts[len(c.verdicts)-c.cfg.History:]
+	}
+	c.stats.Verifications++
+	if verdict.Consensus {
+		c.stats.Consensus++
+		if verdict.Withheld != "" {
+			c.stats.Withheld++
+		}
+	} else {
+		c.stats.NoConsensus++
+	}
+	for _, r := range verdict.Replicas {
+		switch r.Outcome {
+		case domain.ReplicaDissented:
+			c.stats.Dissents++
+		case domain.ReplicaFailed:
+			c.stats.Failures++
+		}
+		c.stats.CreditsPaid += r.Paid
+	}
+	c.mu.Unlock()
+
+	if !verdict.Consensus {
+		return verdict, fmt.Errorf("task %s: largest agreeing group below quorum %d of %d: %w",
+			req.TaskID, req.Quorum, req.Replicas, domain.ErrNoConsensus)
+	}
+	return verdict, nil
+}
+
+func (c *Corrector) validate(req Request) error {
+	switch {
+	case req.Model == "":
+		return fmt.Errorf("model is required: %w", domain.ErrRedundancyInvalid)
+	case req.Quorum > req.Replicas || 2*req.Quorum <= req.Replicas:
+		return fmt.Errorf("quorum %d must be a majority of %d replicas: %w", req.Quorum, req.Replicas, domain.ErrRedundancyInvalid)
+	case req.Payment < 0:
+		return fmt.Errorf("payment must not be negative: %w", domain.ErrRedundancyInvalid)
+	}
+	return nil
+}
+
+// execute runs req on every node concurrently. dispatched reports, per
+// replica, whether the request reached its node.
+func (c *Corrector) execute(ctx context.Context, nodes []string, req Request) (out []domain.ReplicaResult, dispatched []bool) {
+	out = make([]domain.ReplicaResult, len(nodes))
+	dispatched = make([]bool, len(nodes))
+	for i := range dispatched {
+		dispatched[i] = true
+	}
+	var wg sync.WaitGroup
+	for i, node := range nodes {
+		wg.Add(1)
+		go func(i int, node string) {
+			defer wg.Done()
+			rctx, cancel := context.WithTimeout(ctx, c.cfg.Timeout)
+			defer cancel()
+
+			start := time.Now()
+			output, err := c.hooks.Execute(rctx, node, req)
+			out[i] = domain.ReplicaResult{Node: node, Output: output, LatencyMs: time.Since(start).Milliseconds()}
+			if err != nil {
+				out[i].Output = ""
+				out[i].Error = err.Error()
+				out[i].Outcome = domain.ReplicaFailed
+				dispatched[i] = !errors.Is(err, ErrNotDispatched)
+			}
+		}(i, node)
+	}
+	wg.Wait()
+	return out, dispatched
+}
+
+// resolve picks the policy and verifier for req and fills in its mode. An
+// explicit mode wins; otherwise the task type's policy applies, and a
+// request with neither is compared exactly.
+func (c *Corrector) resolve(req *Request) (Policy, Verifier, error) {
+	policy := Policy{Mode: domain.CompareExact, Threshold: 1}
+	if req.TaskType != "" {
+		p, ok := c.policies.Policy(req.TaskType)
+		if !ok && req.Mode == "" {
+			return Policy{}, nil, fmt.Errorf("no verification policy for task type %q: %w", req.TaskType, domain.ErrRedundancyInvalid)
+		}
+		if ok {
+			policy = p
+		}
+	}
+	if req.Mode != "" && req.Mode != policy.Mode {
+		policy = Policy{Mode: req.Mode, Threshold: 1}
+		if req.Mode == domain.CompareEmbedding {
+			policy.Threshold = c.cfg.Similarity
+		}
+	}
+	req.Mode = policy.Mode
+
+	verifier, ok := c.policies.Verifier(policy.Mode)
+	if !ok {
+		return Policy{}, nil, fmt.Errorf("unknown compare mode %q: %w", policy.Mode, domain.ErrRedundancyInvalid)
+	}
+	if policy.Mode == domain.CompareEmbedding && req.EmbedModel == "" {
+		return Policy{}, nil, fmt.Errorf("embedding comparison needs an embedding model: %w", domain.ErrRedundancyInvalid)
+	}
+	return policy, verifier, nil
+}
+
+// group clusters the successful replicas by output: a replica joins the
+// first group whose representative it is at least threshold-similar to.
+// Each group lists replica indexes; the first member is the group's
+// representative. The returned function scores two replicas by index.
+func (c *Corrector) group(ctx context.Context, req Request, v Verifier, threshold float64, replicas []domain.ReplicaResult) ([][]int, func(a, b int) float64, error) {
+	var ok []int
+	for i, r := range replicas {
+		if r.Outcome != domain.ReplicaFailed {
+			ok = append(ok, i)
+		}
+	}
+	if len(ok) == 0 {
+		return nil, nil, nil
+	}
+
+	outputs := make([]string, len(ok))
+	pos := make(map[int]int, len(ok))
+	for j, i := range ok {
+		outputs[j] = replicas[i].Output
+		pos[i] = j
+	}
+	score, err := v.Similarity(ctx, req, outputs)
+	if err != nil {
+		return nil, nil, err
+	}
+	sim := func(a, b int) float64 { return score(pos[a], pos[b]) }
+
+	var groups [][]int
+	for _, i := range ok {
+		placed := false
+		for g := range groups {
+			if sim(groups[g][0], i) >= threshold {
+				groups[g] = append(groups[g], i)
+				placed = true
+				break
+			}
+		}
+		if !placed {
+			groups = append(groups, []int{i})
+		}
+	}
+	return groups, sim, nil
+}
+
+// settle assigns outcomes, pays agreeing nodes unless the consensus output
+// was rejected, and reports every replica that was dispatched.
+func (c *Corrector) settle(ctx context.Context, v *domain.RedundancyVerdict, groups [][]int, winner int, payment int64, dispatched []bool) {
+	for g, members := range groups {
+		for _, i := range members {
+			switch {
+			case winner < 0:
+				v.Replicas[i].Outcome = domain.ReplicaUndecided
+			case g == winner:
+				v.Replicas[i].Outcome = domain.ReplicaAgreed
+			default:
+				v.Replicas[i].Outcome = domain.ReplicaDissented
+			}
+		}
+	}
+
+	for i := range v.Replicas {
+		r := &v.Replicas[i]
+		if r.Outcome == domain.ReplicaAgreed && v.Withheld == "" && payment > 0 && r.Node != c.cfg.SelfID && c.hooks.Pay != nil {
+			if err := c.hooks.Pay(ctx, r.Node, payment, v.TaskID); err == nil {
+				r.Paid = payment
+			}
+		}
+		if c.hooks.Outcome != nil && dispatched[i] {
+			c.hooks.Outcome(r.Node, r.Outcome, r.Agreement, v.TaskID, time.Duration(r.LatencyMs)*time.Millisecond)
+		}
+	}
+}
+
+// Recent returns up to limit verdicts, newest first.
+func (c *Corrector) Recent(limit int) []domain.RedundancyVerdict {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if limit <= 0 || limit > len(c.verdicts) {
+		limit = len(c.verdicts)
+	}
+	out := make([]domain.RedundancyVerdict, 0, limit)
+	for i := len(c.verdicts) - 1; i >= 0 && len(out) < limit; i-- {
+		out = append(out, c.verdicts[i])
+	}
+	return out
+}
+
+// Stats returns corrector statistics.
+func (c *Corrector) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
+
+// ─── Helpers ────────────────────────────────────────────────────────────────
+
+func dedupe(nodes []string) []string {
+	seen := make(map[string]bool, len(nodes))
+	out := nodes[:0:0]
+	for _, n := range nodes {
+		if n != "" && !seen[n] {
+			seen[n] = true
+			out = append(out, n)
+		}
+	}
+	return out
+}
+
+func cosine(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
@@ -0,0 +1,261 @@
+package redundancy
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
+)
+
+// harness records the hooks' side effects.
+type harness struct {
+	mu       sync.Mutex
+	outputs  map[string]string // node → output ("ERR" = fail, "SKIP" = not dispatched)
+	paid     map[string]int64
+	outcomes map[string]domain.ReplicaOutcome
+	agree    map[string]float64
+}
+
+func newHarness(outputs map[string]string) *harness {
+	return &harness{
+		outputs:  outputs,
+		paid:     make(map[string]int64),
+		outcomes: make(map[string]domain.ReplicaOutcome),
+		agree:    make(map[string]float64),
+	}
+}
+
+func (h *harness) hooks(nodes ...string) Hooks {
+	return Hooks{
+		Nodes: func(n int) []string { return nodes },
+		Execute: func(_ context.Context, node string, _ Request) (string, error) {
+			switch h.outputs[node] {
+			case "ERR":
+				return "", errors.New("node crashed")
+			case "SKIP":
+				return "", ErrNotDispatched
+			}
+			return h.outputs[node], nil
+		},
+		Embed: func(_ context.Context, _ string, texts []string) ([][]float32, error) {
+			// Outputs starting with "cat" point one way, everything else another
+			out := make([][]float32, len(texts))
+			for i, t := range texts {
+				if strings.HasPrefix(t, "cat") {
+					out[i] = []float32{1, 0.05}
+				} else {
+					out[i] = []float32{0, 1}
+				}
+			}
+			return out, nil
+		},
+		Pay: func(_ context.Context, node string, amount int64, _ string) error {
+			h.mu.Lock()
+			defer h.mu.Unlock()
+			h.paid[node] += amount
+			return nil
+		},
+		Outcome: func(node string, o domain.ReplicaOutcome, agreement float64, _ string, _ time.Duration) {
+			h.mu.Lock()
+			defer h.mu.Unlock()
+			h.outcomes[node] = o
+			h.agree[node] = agreement
+		},
+	}
+}
+
+func TestCorrector_Verify(t *testing.T) {
+	tests := []struct {
+		name          string
+		mode          domain.CompareMode
+		outputs       map[string]string
+		wantConsensus bool
+		wantOutput    string
+		wantOutcomes  map[string]domain.ReplicaOutcome
+	}{
+		{
+			name:          "unanimous",
+			outputs:       map[string]string{"a": "42", "b": "42", "c": "42 \n"},
+			wantConsensus: true,
+			wantOutput:    "42",
+			wantOutcomes:  map[string]domain.ReplicaOutcome{"a": domain.ReplicaAgreed, "b": domain.ReplicaAgreed, "c": domain.ReplicaAgreed},
+		},
+		{
+			name:          "one dissenter",
+			outputs:       map[string]string{"a": "42", "b": "41", "c": "42"},
+			wantConsensus: true,
+			wantOutput:    "42",
+			wantOutcomes:  map[string]domain.ReplicaOutcome{"a": domain.ReplicaAgreed, "b": domain.ReplicaDissented, "c": domain.ReplicaAgreed},
+		},
+		{
+			name:          "one failure",
+			outputs:       map[string]string{"a": "ERR", "b": "x", "c": "x"},
+			wantConsensus: true,
+			wantOutput:    "x",
+			wantOutcomes:  map[string]domain.ReplicaOutcome{"a": domain.ReplicaFailed, "b": domain.ReplicaAgreed, "c": domain.ReplicaAgreed},
+		},
+		{
+			name:          "not dispatched is not reported",
+			outputs:       map[string]string{"a": "SKIP", "b": "x", "c": "x"},
+			wantConsensus: true,
+			wantOutput:    "x",
+			wantOutcomes:  map[string]domain.ReplicaOutcome{"a": "", "b": domain.ReplicaAgreed, "c": domain.ReplicaAgreed},
+		},
+		{
+			name:         "no consensus",
+			outputs:      map[string]string{"a": "1", "b": "2", "c": "ERR"},
+			wantOutcomes: map[string]domain.ReplicaOutcome{"a": domain.ReplicaUndecided, "b": domain.ReplicaUndecided, "c": domain.ReplicaFailed},
+		},
+		{
+			name:          "embedding similarity",
+			mode:          domain.CompareEmbedding,
+			outputs:       map[string]string{"a": "cat on a mat", "b": "cats sit on mats", "c": "dog"},
+			wantConsensus: true,
+			wantOutput:    "cat on a mat",
+			wantOutcomes:  map[string]domain.ReplicaOutcome{"a": domain.ReplicaAgreed, "b": domain.ReplicaAgreed, "c": domain.ReplicaDissented},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newHarness(tt.outputs)
+			c := New(Config{SelfID: "self", EmbedModel: "embedder"}, h.hooks("a", "b", "c"))
+
+			v, err := c.Verify(context.Background(), Request{Model: "m", Mode: tt.mode, Payment: 5})
+			if tt.wantConsensus && err != nil {
+				t.Fatalf("Verify: %v", err)
+			}
+			if !tt.wantConsensus && !errors.Is(err, domain.ErrNoConsensus) {
+				t.Fatalf("Verify err = %v, want ErrNoConsensus", err)
+			}
+			if v.Consensus != tt.wantConsensus || v.Output != tt.wantOutput {
+				t.Errorf("consensus=%v output=%q, want %v %q", v.Consensus, v.Output, tt.wantConsensus, tt.wantOutput)
+			}
+			for node, want := range tt.wantOutcomes {
+				if h.outcomes[node] != want {
+					t.Errorf("node %s outcome = %s, want %s", node, h.outcomes[node], want)
+				}
+				wantPaid := int64(0)
+				if want == domain.ReplicaAgreed {
+					wantPaid = 5
+				}
+				if h.paid[node] != wantPaid {
+					t.Errorf("node %s paid %d, want %d", node, h.paid[node], wantPaid)
+				}
+			}
+		})
+	}
+}
+
+func TestCorrector_Verify_Validation(t *testing.T) {
+	h := newHarness(map[string]string{"a": "x", "self": "x"})
+	c := New(Config{SelfID: "self"}, h.hooks("a", "self"))
+
+	tests := []struct {
+		name string
+		req  Request
+		want error
+	}{
+		{"no model", Request{}, domain.ErrRedundancyInvalid},
+		{"quorum not a majority", Request{Model: "m", Replicas: 4, Quorum: 2}, domain.ErrRedundancyInvalid},
+		{"quorum above replicas", Request{Model: "m", Replicas: 2, Quorum: 3}, domain.ErrRedundancyInvalid},
+		{"unknown mode", Request{Model: "m", Mode: "fuzzy"}, domain.ErrRedundancyInvalid},
+		{"unknown task type", Request{Model: "m", TaskType: "MINING"}, domain.ErrRedundancyInvalid},
+		{"too few nodes", Request{Model: "m", Replicas: 3, Quorum: 2}, domain.ErrNotEnoughReplicas},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := c.Verify(context.Background(), tt.req); !errors.Is(err, tt.want) {
+				t.Errorf("Verify err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	// 2-of-2 including this node: the local replica is never paid
+	v, err := c.Verify(context.Background(), Request{Model: "m", Replicas: 2, Quorum: 2, Payment: 3})
+	if err != nil || !v.Consensus {
+		t.Fatalf("Verify = %+v, %v", v, err)
+	}
+	if h.paid["self"] != 0 || h.paid["a"] != 3 {
+		t.Errorf("paid = %v, want only a", h.paid)
+	}
+	if st := c.Stats(); st.Verifications != 1 || st.CreditsPaid != 3 {
+		t.Errorf("stats = %+v", st)
+	}
+	if len(c.Recent(10)) != 1 {
+		t.Errorf("Recent = %d, want 1", len(c.Recent(10)))
+	}
+}
+
+func TestCorrector_Policies(t *testing.T) {
+	run := func(cfg Config, outputs map[string]string, taskType domain.TaskType) (*harness, *Corrector, domain.RedundancyVerdict) {
+		t.Helper()
+		h := newHarness(outputs)
+		cfg.SelfID = "self"
+		c := New(cfg, h.hooks("a", "b", "c"))
+		v, err := c.Verify(context.Background(), Request{Model: "m", TaskType: taskType, Payment: 5})
+		if err != nil {
+			t.Fatalf("Verify %s: %v", taskType, err)
+		}
+		return h, c, v
+	}
+
+	// Training: losses within 5% agree, whatever their format
+	losses := map[string]string{"a": "0.50", "b": `{"loss": 0.51}`, "c": "0.90"}
+	h, _, v := run(Config{}, losses, domain.TaskFineTune)
+	if v.Mode != domain.CompareLoss || v.Agreeing != 2 {
+		t.Fatalf("fine-tune verdict = %+v", v)
+	}
+	if h.outcomes["c"] != domain.ReplicaDissented || h.paid["a"] != 5 || h.paid["b"] != 5 {
+		t.Errorf("outcomes %v, paid %v", h.outcomes, h.paid)
+	}
+
+	// A consensus loss above the limit is not paid for
+	h, c, v := run(Config{MaxLoss: 0.4}, losses, domain.TaskFineTune)
+	if !v.Consensus || v.Withheld == "" {
+		t.Fatalf("verdict = %+v, want consensus with payment withheld", v)
+	}
+	if len(h.paid) != 0 || c.Stats().Withheld != 1 {
+		t.Errorf("paid %v, stats %+v", h.paid, c.Stats())
+	}
+	if h.outcomes["a"] != domain.ReplicaAgreed {
+		t.Errorf("a = %s, want AGREED", h.outcomes["a"])
+	}
+
+	// Chat: meaning, not bytes; agreement is graded
+	chat := map[string]string{"a": "cat on a mat", "b": "cats sit on mats", "c": "dog"}
+	h, _, v = run(Config{EmbedModel: "embedder"}, chat, domain.TaskInference)
+	if v.Mode != domain.CompareEmbedding || v.Agreeing != 2 {
+		t.Fatalf("inference verdict = %+v", v)
+	}
+	if h.agree["b"] != 1 || h.agree["c"] >= 0.95 || h.agree["c"] <= 0 {
+		t.Errorf("agreement = %v", h.agree)
+	}
+
+	// Embeddings: bytes only
+	h = newHarness(chat)
+	c = New(Config{SelfID: "self", EmbedModel: "embedder"}, h.hooks("a", "b", "c"))
+	if _, err := c.Verify(context.Background(), Request{Model: "m", TaskType: domain.TaskEmbedding}); !errors.Is(err, domain.ErrNoConsensus) {
+		t.Errorf("embedding task err = %v, want ErrNoConsensus", err)
+	}
+}
+
+func TestRegistry_SetPolicy(t *testing.T) {
+	r := NewRegistry()
+	r.RegisterVerifier(domain.CompareExact, HashVerifier{})
+	if err := r.SetPolicy(domain.TaskAgent, Policy{Mode: domain.CompareExact, Threshold: 1}); err != nil {
+		t.Fatalf("SetPolicy: %v", err)
+	}
+	if err := r.SetPolicy(domain.TaskAgent, Policy{Mode: domain.CompareLoss, Threshold: 1}); !errors.Is(err, domain.ErrRedundancyInvalid) {
+		t.Errorf("unregistered mode err = %v", err)
+	}
+	if err := r.SetPolicy(domain.TaskAgent, Policy{Mode: domain.CompareExact, Threshold: 1.5}); !errors.Is(err, domain.ErrRedundancyInvalid) {
+		t.Errorf("threshold 1.5 err = %v", err)
+	}
+	if p, _ := r.Policy(domain.TaskAgent); p.Mode != domain.CompareExact {
+		t.Errorf("policy = %+v", p)
+	}
+}
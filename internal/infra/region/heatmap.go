@@ -0,0 +1,290 @@
+package region
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
+	"github.com/tutu-network/tutu/internal/infra/observability"
+)
+
+// ─── Latency Heatmap ────────────────────────────────────────────────────────
+// domain.RegionLatencyMs is a static estimate. The heatmap replaces it with
+// what this node measures: every round trip to a peer — gossip probes and
+// network profile probes — is recorded against the pair {local region, peer
+// region} and exported through the tutu_region_latency_ms histogram.
+//
+//  1. Samples are kept per pair in fixed windows of Window; when a window
+//     ends it becomes the previous one and the window before is dropped
+//  2. Percentiles come from the current window, or from the previous one
+//     until the current one holds MinSamples
+//  3. The trend compares the two windows' medians: more than
+//     TrendThreshold apart is up or down, anything less is flat
+//  4. Pairs without enough samples fall back to the static table and are
+//     marked estimated
+//
+// A round trip is symmetric, so a pair's cell is the same in both
+// directions. The router routes on the median (see Router.SetHeatmap); the
+// matrix feeds the desktop network map.
+
+// Trend is the direction a pair's latency is moving in.
+type Trend string
+
+const (
+	TrendUp   Trend = "up"
+	TrendDown Trend = "down"
+	TrendFlat Trend = "flat"
+)
+
+// Arrow returns the trend as an arrow for display; "" if it is unknown.
+func (t Trend) Arrow() string {
+	switch t {
+	case TrendUp:
+		return "↑"
+	case TrendDown:
+		return "↓"
+	case TrendFlat:
+		return "→"
+	}
+	return ""
+}
+
+// HeatmapConfig tunes the latency heatmap.
+type HeatmapConfig struct {
+	Window         time.Duration    // samples per window (default: 5m)
+	MaxSamples     int              // samples kept per pair and window (default: 512)
+	MinSamples     int              // samples before a window is trusted (default: 5)
+	TrendThreshold float64          // relative median change that counts as a trend (default: 0.1)
+	Now            func() time.Time // clock (nil = time.Now)
+}
+
+// DefaultHeatmapConfig returns the default heatmap settings.
+func DefaultHeatmapConfig() HeatmapConfig {
+	return HeatmapConfig{
+		Window:         5 * time.Minute,
+		MaxSamples:     512,
+		MinSamples:     5,
+		TrendThreshold: 0.1,
+	}
+}
+
+// LatencyCell is the latency between two regions.
+type LatencyCell struct {
+	From      domain.RegionID `json:"from"`
+	To        domain.RegionID `json:"to"`
+	P50Ms     float64         `json:"p50_ms"`
+	P95Ms     float64         `json:"p95_ms"`
+	P99Ms     float64         `json:"p99_ms"`
+	Samples   int             `json:"samples"`
+	Estimated bool            `json:"estimated"` // no measurements: the static table
+	Trend     Trend           `json:"trend,omitempty"`
+	Arrow     string          `json:"arrow,omitempty"`
+	PrevP50Ms float64         `json:"prev_p50_ms,omitempty"` // median of the previous window
+	UpdatedAt time.Time       `json:"updated_at"`            // last sample (zero if estimated)
+}
+
+// LatencyMatrix is the latency between every pair of regions, row by row.
+type LatencyMatrix struct {
+	Regions     []domain.RegionID `json:"regions"`
+	Cells       []LatencyCell     `json:"cells"`
+	Window      time.Duration     `json:"window_ns"`
+	GeneratedAt time.Time         `json:"generated_at"`
+}
+
+// Cell returns the from → to cell.
+func (m LatencyMatrix) Cell(from, to domain.RegionID) (LatencyCell, bool) {
+	for _, c := range m.Cells {
+		if c.From == from && c.To == to {
+			return c, true
+		}
+	}
+	return LatencyCell{}, false
+}
+
+// pairSamples holds one pair's current and previous window.
+type pairSamples struct {
+	start     time.Time // current window
+	cur, prev []float64
+	updated   time.Time
+}
+
+// Heatmap aggregates measured latencies between regions. It is safe for
+// concurrent use.
+type Heatmap struct {
+	mu    sync.Mutex
+	cfg   HeatmapConfig
+	pairs map[[2]domain.RegionID]*pairSamples
+}
+
+// NewHeatmap creates an empty heatmap. Zero config fields take their
+// defaults.
+func NewHeatmap(cfg HeatmapConfig) *Heatmap {
+	def := DefaultHeatmapConfig()
+	if cfg.Window <= 0 {
+		cfg.Window = def.Window
+	}
+	if cfg.MaxSamples <= 0 {
+		cfg.MaxSamples = def.MaxSamples
+	}
+	if cfg.MinSamples <= 0 {
+		cfg.MinSamples = def.MinSamples
+	}
+	if cfg.TrendThreshold <= 0 {
+		cfg.TrendThreshold = def.TrendThreshold
+	}
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+	return &Heatmap{cfg: cfg, pairs: make(map[[2]domain.RegionID]*pairSamples)}
+}
+
+// pairOf orders a pair so that (a, b) and (b, a) share samples.
+func pairOf(a, b domain.RegionID) [2]domain.RegionID {
+	if a > b {
+		a, b = b, a
+	}
+	return [2]domain.RegionID{a, b}
+}
+
+// Observe records a round trip between a node in from and one in to.
+// Unknown regions are ignored.
+func (h *Heatmap) Observe(from, to domain.RegionID, rtt time.Duration) {
+	if !from.IsValid() || !to.IsValid() || rtt <= 0 {
+		return
+	}
+	ms := float64(rtt) / float64(time.Millisecond)
+	observability.RegionLatency.WithLabelValues(string(from), string(to)).Observe(ms)
+
+	now := h.cfg.Now()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	key := pairOf(from, to)
+	p, ok := h.pairs[key]
+	if !ok {
+		p = &pairSamples{start: now}
+		h.pairs[key] = p
+	}
+	h.rotateLocked(p, now)
+	if len(p.cur) >= h.cfg.MaxSamples {
+		p.cur = p.cur[1:]
+	}
+	p.cur = append(p.cur, ms)
+	p.updated = now
+}
+
+// rotateLocked moves p's windows forward to now. Must be called with h.mu
+// held.
+func (h *Heatmap) rotateLocked(p *pairSamples, now time.Time) {
+	elapsed := now.Sub(p.start)
+	switch {
+	case elapsed < h.cfg.Window:
+		return
+	case elapsed < 2*h.cfg.Window:
+		p.prev, p.cur = p.cur, nil
+		p.start = p.start.Add(h.cfg.Window)
+	default:
+		p.prev, p.cur = nil, nil
+		p.start = now
+	}
+}
+
+// cellLocked computes the from → to cell. Must be called with h.mu held.
+func (h *Heatmap) cellLocked(from, to domain.RegionID, now time.Time) LatencyCell {
+	c := LatencyCell{From: from, To: to}
+	p, ok := h.pairs[pairOf(from, to)]
+	if ok {
+		h.rotateLocked(p, now)
+	}
+	var window []float64
+	switch {
+	case !ok:
+	case len(p.cur) >= h.cfg.MinSamples:
+		window = p.cur
+	case len(p.prev) >= h.cfg.MinSamples:
+		window = p.prev
+	}
+	if window == nil {
+		est := float64(domain.RegionLatencyMs(from, to))
+		c.P50Ms, c.P95Ms, c.P99Ms, c.Estimated = est, est, est, true
+		return c
+	}
+
+	sorted := sortedCopy(window)
+	c.P50Ms = percentile(sorted, 0.50)
+	c.P95Ms = percentile(sorted, 0.95)
+	c.P99Ms = percentile(sorted, 0.99)
+	c.Samples = len(window)
+	c.UpdatedAt = p.updated
+	if len(p.cur) >= h.cfg.MinSamples && len(p.prev) >= h.cfg.MinSamples {
+		c.PrevP50Ms = percentile(sortedCopy(p.prev), 0.50)
+		c.Trend = trendOf(c.P50Ms, c.PrevP50Ms, h.cfg.TrendThreshold)
+		c.Arrow = c.Trend.Arrow()
+	}
+	return c
+}
+
+// trendOf classifies the change from prev to cur.
+func trendOf(cur, prev, threshold float64) Trend {
+	switch {
+	case prev <= 0:
+		return TrendFlat
+	case cur > prev*(1+threshold):
+		return TrendUp
+	case cur < prev*(1-threshold):
+		return TrendDown
+	}
+	return TrendFlat
+}
+
+// LatencyMs returns the median from → to latency this node measures, or
+// the static estimate if it has too few samples.
+func (h *Heatmap) LatencyMs(from, to domain.RegionID) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	c := h.cellLocked(from, to, h.cfg.Now())
+	return int(math.Round(c.P50Ms))
+}
+
+// Matrix returns the cell of every pair of regions, from and to both
+// ranging over regions (nil = every known region).
+func (h *Heatmap) Matrix(regions []domain.RegionID) LatencyMatrix {
+	if regions == nil {
+		regions = domain.AllRegions()
+	}
+	regions = append([]domain.RegionID(nil), regions...)
+	sort.Slice(regions, func(i, j int) bool { return regions[i] < regions[j] })
+
+	now := h.cfg.Now()
+	m := LatencyMatrix{
+		Regions:     regions,
+		Cells:       make([]LatencyCell, 0, len(regions)*len(regions)),
+		Window:      h.cfg.Window,
+		GeneratedAt: now,
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, from := range regions {
+		for _, to := range regions {
+			m.Cells = append(m.Cells, h.cellLocked(from, to, now))
+		}
+	}
+	return m
+}
+
+// sortedCopy returns the samples in ascending order.
+func sortedCopy(samples []float64) []float64 {
+	out := append([]float64(nil), samples...)
+	sort.Float64s(out)
+	return out
+}
+
+// percentile returns the q-th quantile of sorted samples (nearest rank).
+func percentile(sorted []float64, q float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	i := int(math.Ceil(q*float64(len(sorted)))) - 1
+	return sorted[max(0, min(i, len(sorted)-1))]
+}
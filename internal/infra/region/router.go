@@ -4,20 +4,62 @@
 // Routing priority:
 //  1. Data residency constraint (hard requirement — if set, must be honored)
 //  2. Same-region preference (lowest latency)
-//  3. Lowest-load failover (if home region is overloaded)
-//  4. Cross-region with latency penalty
+//  3. Nearest healthy neighbour (if home region is unhealthy)
+//  4. Lowest-load failover (if home region is overloaded)
+//  5. Cross-region with latency penalty
+//
+// Region status comes from the persisted region_status table (Sync), a
+// federation's AllowedRegions bound every choice, and latencies are measured
+// by the attached Heatmap where it has samples.
 package region
 
 import (
+	"fmt"
 	"sort"
 	"sync"
 	"time"
 
 	"github.com/tutu-network/tutu/internal/domain"
+	"github.com/tutu-network/tutu/internal/infra/observability"
 )
 
 // ─── Router ─────────────────────────────────────────────────────────────────
 
+// Request asks for a routing decision.
+type Request struct {
+	Routing    domain.TaskRouting `json:"routing"`
+	Federation string             `json:"federation_id,omitempty"` // limits routing to its AllowedRegions
+	Nodes      []NodeRegion       `json:"nodes,omitempty"`         // candidate nodes to rank
+}
+
+// NodeRegion is a candidate node and the region it runs in.
+type NodeRegion struct {
+	NodeID string          `json:"node_id"`
+	Region domain.RegionID `json:"region"`
+}
+
+// Result is a routing decision with the candidate nodes in preference
+// order.
+type Result struct {
+	domain.RouteDecision
+	Nodes []NodeRegion `json:"nodes,omitempty"`
+}
+
+// Decision is a recorded routing decision.
+type Decision struct {
+	domain.RouteDecision
+	Federation string    `json:"federation_id,omitempty"`
+	At         time.Time `json:"at"`
+}
+
+// Stats summarises the router.
+type Stats struct {
+	LocalRegion    domain.RegionID  `json:"local_region"`
+	HealthyRegions int              `json:"healthy_regions"`
+	Decisions      map[string]int64 `json:"decisions"` // by reason
+	SyncedAt       time.Time        `json:"synced_at"`
+}
+
 // Router makes geo-aware task routing decisions across regions.
 // It maintains a snapshot of each region's health and routes tasks
 // to minimize latency while respecting data-residency requirements.
@@ -29,6 +71,13 @@ type Router struct {
 	// Configuration thresholds
 	loadThreshold float64 // above this, prefer cross-region routing
 	maxLatencyMs  int     // reject routes above this latency
+
+	allowed    func(federationID string) ([]domain.RegionID, error)
+	heatmap    *Heatmap         // measured latencies (nil = the static table)
+	decisions  map[string]int64 // by reason
+	history    []Decision
+	historyCap int
+	syncedAt   time.Time
 }
 
 // Config holds router configuration.
@@ -36,6 +85,7 @@ type Config struct {
 	LocalRegion   domain.RegionID
 	LoadThreshold float64 // default 0.8 — route away if load > 80%
 	MaxLatencyMs  int     // default 200ms — reject routes with higher penalty
+	History       int     // default 100 — recent decisions kept
 }
 
 // DefaultConfig returns sensible router defaults.
@@ -44,6 +94,7 @@ func DefaultConfig() Config {
 		LocalRegion:   domain.RegionUSEast,
 		LoadThreshold: 0.8,
 		MaxLatencyMs:  200,
+		History:       100,
 	}
 }
 
@@ -55,12 +106,17 @@ func NewRouter(cfg Config) *Router {
 	if cfg.MaxLatencyMs <= 0 {
 		cfg.MaxLatencyMs = 200
 	}
+	if cfg.History <= 0 {
+		cfg.History = 100
+	}
 
 	r := &Router{
 		regions:       make(map[domain.RegionID]*domain.RegionStatus),
 		localReg:      cfg.LocalRegion,
 		loadThreshold: cfg.LoadThreshold,
 		maxLatencyMs:  cfg.MaxLatencyMs,
+		decisions:     make(map[string]int64),
+		historyCap:    cfg.History,
 	}
 
 	// Initialize all known regions as healthy with zero load.
@@ -85,6 +141,58 @@ func (r *Router) UpdateRegion(status domain.RegionStatus) {
 	r.regions[status.Region] = &s
 }
 
+// Sync applies persisted region statuses, e.g. from the region_status
+// table. Unknown regions are ignored.
+func (r *Router) Sync(statuses []domain.RegionStatus) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, status := range statuses {
+		if !status.Region.IsValid() {
+			continue
+		}
+		s := status // copy
+		r.regions[status.Region] = &s
+	}
+	r.syncedAt = time.Now()
+}
+
+// SetAllowedRegions sets the lookup for a federation's AllowedRegions. An
+// empty list leaves the federation unrestricted.
+func (r *Router) SetAllowedRegions(fn func(federationID string) ([]domain.RegionID, error)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.allowed = fn
+}
+
+// SetHeatmap routes on the latencies h measures instead of the static
+// table; pairs h has too few samples for keep their estimate.
+func (r *Router) SetHeatmap(h *Heatmap) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.heatmap = h
+}
+
+// LatencyMatrix returns the latency between every pair of regions: measured
+// where the attached heatmap has samples, estimated elsewhere.
+func (r *Router) LatencyMatrix() LatencyMatrix {
+	r.mu.RLock()
+	h := r.heatmap
+	r.mu.RUnlock()
+	if h == nil {
+		h = NewHeatmap(HeatmapConfig{})
+	}
+	return h.Matrix(nil)
+}
+
+// latencyLocked returns the latency routing assumes between two regions.
+// Must be called with r.mu held.
+func (r *Router) latencyLocked(from, to domain.RegionID) int {
+	if r.heatmap != nil {
+		return r.heatmap.LatencyMs(from, to)
+	}
+	return domain.RegionLatencyMs(from, to)
+}
+
 // RegionStatus returns the current status of a specific region.
 func (r *Router) RegionStatus(id domain.RegionID) (domain.RegionStatus, bool) {
 	r.mu.RLock()
@@ -113,61 +221,110 @@ func (r *Router) AllRegionStatuses() []domain.RegionStatus {
 // Algorithm (in priority order):
 //  1. If DataResidency is set → must use that region (hard constraint).
 //  2. If preferred region is healthy and below load threshold → use it.
-//  3. Otherwise, pick the healthy region with lowest load + latency score.
-//  4. If no healthy region exists → fallback to local region.
+//  3. If the local region is healthy and below load threshold → use it.
+//  4. If the local region is unhealthy → fail over to the nearest healthy
+//     neighbour region.
+//  5. Otherwise, pick the healthy region with lowest load + latency score.
+//  6. If no healthy region exists → fallback to local region.
 func (r *Router) Route(routing domain.TaskRouting) domain.RouteDecision {
+	res, _ := r.RouteTask(Request{Routing: routing})
+	return res.RouteDecision
+}
+
+// RouteTask routes a task, restricted to the AllowedRegions of the
+// federation it is submitted for, and ranks the candidate nodes: nodes in
+// the target region first, then nodes in healthy allowed regions nearest to
+// it. Every decision is counted by reason and kept in the history.
+func (r *Router) RouteTask(req Request) (Result, error) {
+	var allowed map[domain.RegionID]bool
 	r.mu.RLock()
-	defer r.mu.RUnlock()
+	allowedFn := r.allowed
+	r.mu.RUnlock()
+	if req.Federation != "" && allowedFn != nil {
+		regions, err := allowedFn(req.Federation)
+		if err != nil {
+			return Result{}, err
+		}
+		if len(regions) > 0 {
+			allowed = make(map[domain.RegionID]bool, len(regions))
+			for _, reg := range regions {
+				allowed[reg] = true
+			}
+		}
+	}
+
+	r.mu.RLock()
+	decision, err := r.decideLocked(req.Routing, allowed)
+	var nodes []NodeRegion
+	if err == nil {
+		nodes = r.rankLocked(decision.TargetRegion, req.Nodes, allowed)
+	}
+	r.mu.RUnlock()
+
+	if err != nil {
+		decision = domain.RouteDecision{SourceRegion: r.localReg, Reason: "rejected"}
+	}
+	r.record(decision, req.Federation)
+	return Result{RouteDecision: decision, Nodes: nodes}, err
+}
 
+// decideLocked picks the target region. allowed == nil permits every
+// region. Must be called with r.mu held.
+func (r *Router) decideLocked(routing domain.TaskRouting, allowed map[domain.RegionID]bool) (domain.RouteDecision, error) {
 	source := r.localReg
+	permitted := func(id domain.RegionID) bool { return allowed == nil || allowed[id] }
+	decide := func(target domain.RegionID, reason string) domain.RouteDecision {
+		return domain.RouteDecision{
+			TargetRegion:   target,
+			SourceRegion:   source,
+			LatencyPenalty: r.latencyLocked(source, target),
+			Reason:         reason,
+		}
+	}
 
 	// Priority 1: Data residency hard constraint
 	if routing.RequiresRegion() {
 		target := routing.DataResidency
-		return domain.RouteDecision{
-			TargetRegion:   target,
-			SourceRegion:   source,
-			LatencyPenalty: domain.RegionLatencyMs(source, target),
-			Reason:         "data-residency",
+		if !permitted(target) {
+			return domain.RouteDecision{}, fmt.Errorf("data residency %s is outside the federation's regions: %w",
+				target, domain.ErrRegionNotAllowed)
 		}
+		return decide(target, "data-residency"), nil
 	}
 
 	// Priority 2: Preferred region (if healthy + not overloaded)
 	preferred := routing.PreferredRegion()
-	if preferred != "" {
+	if preferred != "" && permitted(preferred) {
 		if s, ok := r.regions[preferred]; ok && s.Healthy && s.Load() < r.loadThreshold {
-			return domain.RouteDecision{
-				TargetRegion:   preferred,
-				SourceRegion:   source,
-				LatencyPenalty: domain.RegionLatencyMs(source, preferred),
-				Reason:         "preferred-region",
-			}
+			return decide(preferred, "preferred-region"), nil
 		}
 	}
 
 	// Priority 3: Same region if healthy and below threshold
-	if s, ok := r.regions[source]; ok && s.Healthy && s.Load() < r.loadThreshold {
-		return domain.RouteDecision{
-			TargetRegion:   source,
-			SourceRegion:   source,
-			LatencyPenalty: 0,
-			Reason:         "same-region",
+	local, ok := r.regions[source]
+	if ok && permitted(source) && local.Healthy && local.Load() < r.loadThreshold {
+		return decide(source, "same-region"), nil
+	}
+
+	// Priority 4: Local region down — nearest healthy neighbour
+	if !ok || !local.Healthy {
+		if target, found := r.nearestLocked(source, permitted); found {
+			return decide(target, "failover"), nil
 		}
 	}
 
-	// Priority 4: Find best alternative — scored by (low load + low latency)
+	// Priority 5: Find best alternative — scored by (low load + low latency)
 	type candidate struct {
-		region  domain.RegionID
-		score   float64
-		latency int
+		region domain.RegionID
+		score  float64
 	}
 
 	candidates := make([]candidate, 0, len(r.regions))
 	for id, s := range r.regions {
-		if !s.Healthy {
+		if !s.Healthy || !permitted(id) {
 			continue
 		}
-		latency := domain.RegionLatencyMs(source, id)
+		latency := r.latencyLocked(source, id)
 		if latency > r.maxLatencyMs {
 			continue
 		}
@@ -175,29 +332,99 @@ func (r *Router) Route(routing domain.TaskRouting) domain.RouteDecision {
 		loadScore := s.Load()
 		latencyScore := float64(latency) / float64(r.maxLatencyMs)
 		score := 0.7*loadScore + 0.3*latencyScore
-		candidates = append(candidates, candidate{region: id, score: score, latency: latency})
+		candidates = append(candidates, candidate{region: id, score: score})
 	}
 
 	sort.Slice(candidates, func(i, j int) bool {
-		return candidates[i].score < candidates[j].score
+		if candidates[i].score != candidates[j].score {
+			return candidates[i].score < candidates[j].score
+		}
+		return candidates[i].region < candidates[j].region
 	})
 
 	if len(candidates) > 0 {
-		best := candidates[0]
-		return domain.RouteDecision{
-			TargetRegion:   best.region,
-			SourceRegion:   source,
-			LatencyPenalty: best.latency,
-			Reason:         "lowest-load",
+		return decide(candidates[0].region, "lowest-load"), nil
+	}
+
+	// Fallback: local region regardless of health (best effort), or the
+	// first allowed region when the local one is not allowed
+	if permitted(source) {
+		return decide(source, "fallback"), nil
+	}
+	regions := make([]domain.RegionID, 0, len(allowed))
+	for id := range allowed {
+		regions = append(regions, id)
+	}
+	sort.Slice(regions, func(i, j int) bool { return regions[i] < regions[j] })
+	return decide(regions[0], "fallback"), nil
+}
+
+// nearestLocked returns the healthy permitted region closest to from,
+// preferring regions below the load threshold. Must be called with r.mu
+// held.
+func (r *Router) nearestLocked(from domain.RegionID, permitted func(domain.RegionID) bool) (domain.RegionID, bool) {
+	var (
+		best     domain.RegionID
+		bestBusy bool
+		bestLat  int
+		found    bool
+	)
+	for id, s := range r.regions {
+		if id == from || !s.Healthy || !permitted(id) {
+			continue
+		}
+		lat := r.latencyLocked(from, id)
+		if lat > r.maxLatencyMs {
+			continue
+		}
+		busy := s.Load() >= r.loadThreshold
+		better := !found ||
+			(busy != bestBusy && !busy) ||
+			(busy == bestBusy && (lat < bestLat || lat == bestLat && id < best))
+		if better {
+			best, bestBusy, bestLat, found = id, busy, lat, true
 		}
 	}
+	return best, found
+}
 
-	// Fallback: local region regardless of health (best effort)
-	return domain.RouteDecision{
-		TargetRegion:   source,
-		SourceRegion:   source,
-		LatencyPenalty: 0,
-		Reason:         "fallback",
+// rankLocked orders candidate nodes for a task routed to target. Must be
+// called with r.mu held.
+func (r *Router) rankLocked(target domain.RegionID, nodes []NodeRegion, allowed map[domain.RegionID]bool) []NodeRegion {
+	out := make([]NodeRegion, 0, len(nodes))
+	for _, n := range nodes {
+		if n.Region == target {
+			out = append(out, n)
+			continue
+		}
+		if allowed != nil && !allowed[n.Region] {
+			continue
+		}
+		if s, ok := r.regions[n.Region]; !ok || !s.Healthy {
+			continue
+		}
+		out = append(out, n)
+	}
+	lat := make(map[domain.RegionID]int)
+	for _, n := range out {
+		if _, ok := lat[n.Region]; !ok {
+			lat[n.Region] = r.latencyLocked(target, n.Region)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return lat[out[i].Region] < lat[out[j].Region] })
+	return out
+}
+
+// record counts a decision and appends it to the history.
+func (r *Router) record(d domain.RouteDecision, federationID string) {
+	observability.RegionRoutingDecisions.WithLabelValues(d.Reason).Inc()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.decisions[d.Reason]++
+	r.history = append(r.history, Decision{RouteDecision: d, Federation: federationID, At: time.Now()})
+	if len(r.history) > r.historyCap {
+		r.history = r.history[len(r.history)-r.historyCap:]
 	}
 }
 
@@ -213,3 +440,33 @@ func (r *Router) HealthyRegionCount() int {
 	}
 	return count
 }
+
+// Decisions returns recent routing decisions, newest first.
+func (r *Router) Decisions(limit int) []Decision {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if limit <= 0 || limit > len(r.history) {
+		limit = len(r.history)
+	}
+	out := make([]Decision, 0, limit)
+	for i := len(r.history) - 1; i >= 0 && len(out) < limit; i-- {
+		out = append(out, r.history[i])
+	}
+	return out
+}
+
+// Stats returns router statistics.
+func (r *Router) Stats() Stats {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	st := Stats{LocalRegion: r.localReg, Decisions: make(map[string]int64, len(r.decisions)), SyncedAt: r.syncedAt}
+	for reason, n := range r.decisions {
+		st.Decisions[reason] = n
+	}
+	for _, s := range r.regions {
+		if s.Healthy {
+			st.HealthyRegions++
+		}
+	}
+	return st
+}
@@ -1,6 +1,7 @@
 package region
 
 import (
+	"errors"
 	"testing"
 	"time"
 
@@ -208,3 +209,148 @@ func TestRouter_Route_Fallback_AllUnhealthy(t *testing.T) {
 		t.Errorf("TargetRegion = %s, want local region %s", decision.TargetRegion, domain.RegionUSEast)
 	}
 }
+
+// ─── Persisted Status, Failover & Federation Constraints ───────────────────
+
+func TestRouter_Sync(t *testing.T) {
+	r := newTestRouter(t, domain.RegionUSEast)
+	r.Sync([]domain.RegionStatus{
+		{Region: domain.RegionEUWest, Healthy: false},
+		{Region: "mars-north", Healthy: true},
+	})
+	if s, _ := r.RegionStatus(domain.RegionEUWest); s.Healthy {
+		t.Error("eu-west should be unhealthy after sync")
+	}
+	if _, ok := r.RegionStatus("mars-north"); ok {
+		t.Error("unknown region should be ignored")
+	}
+	if st := r.Stats(); st.HealthyRegions != 2 || st.SyncedAt.IsZero() {
+		t.Errorf("Stats = %+v", st)
+	}
+}
+
+func TestRouter_Route_NeighbourFailover(t *testing.T) {
+	r := newTestRouter(t, domain.RegionUSEast)
+	r.Sync([]domain.RegionStatus{
+		{Region: domain.RegionUSEast, Healthy: false},
+		{Region: domain.RegionEUWest, Healthy: true, NodeCount: 10, ActiveTasks: 5},
+		{Region: domain.RegionAPSouth, Healthy: true, NodeCount: 100},
+	})
+	// ap-south is idler, but eu-west is the nearer neighbour
+	decision := r.Route(domain.TaskRouting{})
+	if decision.Reason != "failover" || decision.TargetRegion != domain.RegionEUWest {
+		t.Errorf("decision = %+v, want failover to eu-west", decision)
+	}
+}
+
+func TestRouter_RouteTask_FederationRegions(t *testing.T) {
+	r := newTestRouter(t, domain.RegionUSEast)
+	for _, reg := range domain.AllRegions() {
+		r.UpdateRegion(domain.RegionStatus{Region: reg, Healthy: true, NodeCount: 10})
+	}
+	r.SetAllowedRegions(func(fedID string) ([]domain.RegionID, error) {
+		if fedID != "fed-eu" {
+			return nil, domain.ErrFederationNotFound
+		}
+		return []domain.RegionID{domain.RegionEUWest, domain.RegionAPSouth}, nil
+	})
+
+	tests := []struct {
+		name       string
+		req        Request
+		wantRegion domain.RegionID
+		wantReason string
+		wantErr    error
+	}{
+		{"unrestricted", Request{}, domain.RegionUSEast, "same-region", nil},
+		{"local not allowed", Request{Federation: "fed-eu"}, domain.RegionEUWest, "lowest-load", nil},
+		{"preferred not allowed", Request{Federation: "fed-eu", Routing: domain.TaskRouting{RegionAffinity: []domain.RegionID{domain.RegionUSEast}}}, domain.RegionEUWest, "lowest-load", nil},
+		{"residency outside", Request{Federation: "fed-eu", Routing: domain.TaskRouting{DataResidency: domain.RegionUSEast}}, "", "rejected", domain.ErrRegionNotAllowed},
+		{"unknown federation", Request{Federation: "nope"}, "", "", domain.ErrFederationNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := r.RouteTask(tt.req)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if res.TargetRegion != tt.wantRegion || res.Reason != tt.wantReason {
+				t.Errorf("decision = %+v, want %s/%s", res.RouteDecision, tt.wantRegion, tt.wantReason)
+			}
+		})
+	}
+
+	st := r.Stats()
+	if st.Decisions["lowest-load"] != 2 || st.Decisions["rejected"] != 1 || st.Decisions["same-region"] != 1 {
+		t.Errorf("Decisions = %v", st.Decisions)
+	}
+	if d := r.Decisions(1); len(d) != 1 || d[0].Reason != "rejected" || d[0].Federation != "fed-eu" {
+		t.Errorf("latest decision = %+v", d)
+	}
+}
+
+func TestRouter_RouteTask_RanksNodes(t *testing.T) {
+	r := newTestRouter(t, domain.RegionEUWest)
+	r.Sync([]domain.RegionStatus{
+		{Region: domain.RegionEUWest, Healthy: true, NodeCount: 10},
+		{Region: domain.RegionUSEast, Healthy: true, NodeCount: 10},
+		{Region: domain.RegionAPSouth, Healthy: false},
+	})
+	res, err := r.RouteTask(Request{Nodes: []NodeRegion{
+		{NodeID: "ap", Region: domain.RegionAPSouth},
+		{NodeID: "us", Region: domain.RegionUSEast},
+		{NodeID: "eu", Region: domain.RegionEUWest},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Nodes) != 2 || res.Nodes[0].NodeID != "eu" || res.Nodes[1].NodeID != "us" {
+		t.Errorf("Nodes = %+v, want eu then us (ap-south unhealthy)", res.Nodes)
+	}
+}
+
+// ─── Measured Latency ───────────────────────────────────────────────────────
+
+func TestHeatmap_MatrixAndRouting(t *testing.T) {
+	clock := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := NewHeatmap(HeatmapConfig{Window: time.Minute, Now: func() time.Time { return clock }})
+	for i := range 10 {
+		h.Observe(domain.RegionUSEast, domain.RegionEUWest, time.Duration(40+i)*time.Millisecond)
+	}
+	clock = clock.Add(time.Minute)
+	for i := range 10 {
+		h.Observe(domain.RegionEUWest, domain.RegionUSEast, time.Duration(60+i)*time.Millisecond)
+		h.Observe(domain.RegionUSEast, domain.RegionAPSouth, 30*time.Millisecond)
+	}
+	h.Observe(domain.RegionEUWest, domain.RegionAPSouth, 10*time.Millisecond) // too few samples
+
+	m := h.Matrix(nil)
+	if len(m.Regions) != 3 || len(m.Cells) != 9 {
+		t.Fatalf("matrix = %d regions, %d cells; want 3, 9", len(m.Regions), len(m.Cells))
+	}
+	c, _ := m.Cell(domain.RegionUSEast, domain.RegionEUWest)
+	if c.Estimated || c.Samples != 10 || c.P50Ms != 64 || c.P99Ms != 69 || c.PrevP50Ms != 44 || c.Trend != TrendUp || c.Arrow != "↑" {
+		t.Errorf("us-east → eu-west = %+v, want measured p50 64 trending up from 44", c)
+	}
+	if c, _ := m.Cell(domain.RegionAPSouth, domain.RegionEUWest); !c.Estimated || c.P50Ms != 120 {
+		t.Errorf("ap-south → eu-west = %+v, want the static estimate", c)
+	}
+
+	// Measured latency beats the table when failing over
+	r := newTestRouter(t, domain.RegionUSEast)
+	r.UpdateRegion(domain.RegionStatus{Region: domain.RegionUSEast, Healthy: false})
+	if d := r.Route(domain.TaskRouting{}); d.TargetRegion != domain.RegionEUWest {
+		t.Fatalf("static failover = %s, want eu-west", d.TargetRegion)
+	}
+	r.SetHeatmap(h)
+	d := r.Route(domain.TaskRouting{})
+	if d.TargetRegion != domain.RegionAPSouth || d.LatencyPenalty != 30 {
+		t.Errorf("measured failover = %+v, want ap-south at 30ms", d)
+	}
+
+	// Two idle windows later nothing is measured any more
+	clock = clock.Add(3 * time.Minute)
+	if got := h.LatencyMs(domain.RegionUSEast, domain.RegionAPSouth); got != 180 {
+		t.Errorf("stale LatencyMs = %d, want the 180ms estimate", got)
+	}
+}
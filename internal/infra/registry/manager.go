@@ -10,6 +10,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/tutu-network/tutu/internal/domain"
@@ -27,6 +28,21 @@ type Manager struct {
 	db          *sqlite.DB
 	urlOverride string           // If set, use this base URL instead of HuggingFace (for testing)
 	bloom       *dsa.BloomFilter // DSA: O(1) probabilistic model existence check
+
+	mu        sync.Mutex
+	verified  map[string]blobStamp             // blob path → stamp at last successful hash
+	onCorrupt func(v domain.ModelVerification) // called when a model fails verification
+
+	budget     int64           // storage cap in bytes (0 = unlimited), see quota.go
+	evictFirst func() []string // preferred eviction order (retirement candidates)
+
+	swarm SwarmFetch // downloads from peers hosting a model (nil = origin only), see swarm.go
+}
+
+// blobStamp identifies a blob's on-disk state so unchanged files are not rehashed.
+type blobStamp struct {
+	size    int64
+	modTime time.Time
 }
 
 // NewManager creates a Manager rooted at dir.
@@ -34,8 +50,9 @@ type Manager struct {
 // seeded from existing DB entries to avoid cold-start misses.
 func NewManager(dir string, db *sqlite.DB) *Manager {
 	mgr := &Manager{
-		dir: dir,
-		db:  db,
+		dir:      dir,
+		db:       db,
+		verified: make(map[string]blobStamp),
 		bloom: dsa.NewBloomFilter(dsa.BloomConfig{
 			ExpectedItems: 500,
 			FPRate:        0.001, // 0.1% false positive rate
@@ -125,6 +142,11 @@ func (m *Manager) Resolve(name string) (string, error) {
 		return "", err
 	}
 
+	// Refuse to serve weights that do not match the manifest
+	if v := m.verifyManifest(ref, manifest); !v.Verified {
+		return "", fmt.Errorf("model %s: %s: %w", ref, v.Error, domain.ErrModelCorrupted)
+	}
+
 	// Find the weights layer (typically the largest layer or type "model")
 	for _, layer := range manifest.Layers {
 		if layer.MediaType == "application/vnd.tutu.model" ||
@@ -147,9 +169,108 @@ func (m *Manager) Resolve(name string) (string, error) {
 	return "", fmt.Errorf("model %s has no layers: %w", ref, domain.ErrModelCorrupted)
 }
 
+// ─── Integrity Verification ─────────────────────────────────────────────────
+// Every blob is content-addressed, so a model is intact iff each layer's
+// SHA-256 matches the digest in its manifest. Resolve runs this check before
+// handing a path to the engine pool; a blob whose size and mtime are
+// unchanged since its last successful check is not rehashed.
+
+// SetCorruptionHook installs a callback invoked whenever a model fails
+// verification (e.g. to raise a MODEL_CORRUPT incident in the self-heal mesh).
+func (m *Manager) SetCorruptionHook(fn func(v domain.ModelVerification)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onCorrupt = fn
+}
+
+// Verify rehashes every blob of a model and records the result.
+func (m *Manager) Verify(name string) (domain.ModelVerification, error) {
+	ref := ParseRef(name)
+	manifest, err := m.loadManifest(ref)
+	if err != nil {
+		return domain.ModelVerification{}, err
+	}
+	m.mu.Lock()
+	for _, layer := range manifest.Layers {
+		delete(m.verified, m.BlobPath(layer.Digest))
+	}
+	m.mu.Unlock()
+	return m.verifyManifest(ref, manifest), nil
+}
+
+// verifyManifest checks each layer against its digest, persists the result
+// and fires the corruption hook on failure.
+func (m *Manager) verifyManifest(ref domain.ModelRef, manifest domain.Manifest) domain.ModelVerification {
+	v := domain.ModelVerification{Model: ref.String(), Verified: true, CheckedAt: time.Now()}
+
+	for _, layer := range manifest.Layers {
+		v.ExpectedDigest = layer.Digest
+		actual, err := m.verifyBlob(layer.Digest)
+		v.ActualDigest = actual
+		if err != nil {
+			v.Verified = false
+			v.Error = err.Error()
+			break
+		}
+	}
+
+	if m.db != nil {
+		_ = m.db.RecordModelVerification(v)
+	}
+	if !v.Verified {
+		m.mu.Lock()
+		hook := m.onCorrupt
+		m.mu.Unlock()
+		if hook != nil {
+			hook(v)
+		}
+	}
+	return v
+}
+
+// verifyBlob hashes a blob and compares it to its expected digest.
+// Returns the actual digest (empty if unreadable).
+func (m *Manager) verifyBlob(digest string) (string, error) {
+	path := m.BlobPath(digest)
+	fi, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("blob %s missing", digest)
+	}
+	stamp := blobStamp{size: fi.Size(), modTime: fi.ModTime()}
+
+	m.mu.Lock()
+	cached, ok := m.verified[path]
+	m.mu.Unlock()
+	if ok && cached.size == stamp.size && cached.modTime.Equal(stamp.modTime) {
+		return digest, nil
+	}
+
+	if !strings.HasPrefix(digest, "sha256:") {
+		return "", fmt.Errorf("blob %s: unsupported digest algorithm", digest)
+	}
+	sum, err := hashFile(path)
+	if err != nil {
+		return "", fmt.Errorf("hash blob %s: %w", digest, err)
+	}
+	actual := "sha256:" + sum
+	if actual != digest {
+		return actual, fmt.Errorf("digest mismatch: manifest %s, blob %s", digest, actual)
+	}
+
+	m.mu.Lock()
+	m.verified[path] = stamp
+	m.mu.Unlock()
+	return actual, nil
+}
+
 // List returns all locally stored models.
 func (m *Manager) List() ([]domain.ModelInfo, error) {
-	return m.db.ListModels()
+	models, err := m.db.ListModels()
+	if err != nil {
+		return nil, err
+	}
+	m.attachMetadata(models)
+	return models, nil
 }
 
 // Remove deletes a model from local storage.
@@ -170,6 +291,9 @@ func (m *Manager) Remove(name string) error {
 	_ = os.Remove(mpath)
 
 	// Remove from DB
+	if err := m.db.DeleteModelMetadata(ref.String()); err != nil {
+		return err
+	}
 	return m.db.DeleteModel(ref.String())
 }
 
@@ -183,12 +307,14 @@ func (m *Manager) Show(name string) (*domain.ModelInfo, error) {
 	if info == nil {
 		return nil, domain.ErrModelNotFound
 	}
+	info.Metadata = m.metadata(info.Name)
 	return info, nil
 }
 
-// Pull downloads a real GGUF model from HuggingFace.
-// It streams the file to disk with progress reporting and creates
-// the manifest + DB entry once download completes.
+// Pull downloads a real GGUF model, from peers that host it when a swarm
+// is set (see swarm.go) and otherwise from HuggingFace. It streams the file
+// to disk with progress reporting and creates the manifest + DB entry once
+// download completes.
 func (m *Manager) Pull(name string, progress func(status string, pct float64)) error {
 	ref := ParseRef(name)
 
@@ -212,12 +338,8 @@ func (m *Manager) Pull(name string, progress func(status string, pct float64)) e
 		return nil
 	}
 
-	// Look up in catalog
-	entry := catalog.Lookup(ref.String())
-	if entry == nil {
-		// Also try just the name without tag
-		entry = catalog.Lookup(ref.Name)
-	}
+	// Look up in catalog, by full name then without the tag
+	entry := lookupEntry(ref)
 	if entry == nil {
 		// Unknown model: if we have a URL override (test mode), create a synthetic entry
 		if m.urlOverride != "" {
@@ -237,10 +359,22 @@ func (m *Manager) Pull(name string, progress func(status string, pct float64)) e
 		}
 	}
 
+	// Make room under the storage budget before downloading
+	if evicted, err := m.makeRoom(entry.SizeBytes); err != nil {
+		return fmt.Errorf("pull %s: %w", ref, err)
+	} else if len(evicted) > 0 && progress != nil {
+		progress(fmt.Sprintf("evicted %s to free space", strings.Join(evicted, ", ")), 0)
+	}
+
 	url := entry.DownloadURL()
 	if m.urlOverride != "" {
 		url = m.urlOverride + "/" + entry.HFFile
 	}
+	// Peers that host the model are tried before the origin
+	if blob, ok := m.pullFromSwarm(ref, url, progress); ok {
+		return m.install(ref, entry, blob, progress)
+	}
+
 	if progress != nil {
 		progress(fmt.Sprintf("downloading %s (%s)", entry.Name, domain.HumanSize(entry.SizeBytes)), 0)
 	}
@@ -332,7 +466,12 @@ func (m *Manager) Pull(name string, progress func(status string, pct float64)) e
 		}
 	}
 	f.Close()
+	return m.install(ref, entry, tmpPath, progress)
+}
 
+// install moves a downloaded model file into the blob store and records
+// its manifest and metadata.
+func (m *Manager) install(ref domain.ModelRef, entry *catalog.ModelEntry, tmpPath string, progress func(status string, pct float64)) error {
 	// Compute SHA256 of the full file (for content addressing)
 	digest, err := hashFile(tmpPath)
 	if err != nil {
@@ -395,6 +534,9 @@ func (m *Manager) Pull(name string, progress func(status string, pct float64)) e
 	if err := m.db.UpsertModel(info); err != nil {
 		return err
 	}
+	if err := m.db.UpsertModelMetadata(entryMetadata(info.Name, entry, now)); err != nil {
+		return err
+	}
 
 	// DSA: Register in Bloom filter for O(1) future existence checks
 	m.bloom.Add(ref.String())
@@ -504,7 +646,10 @@ func (m *Manager) CreateFromTuTufile(name string, tf domain.TuTufile) error {
 		PulledAt:  now,
 		Format:    "gguf",
 	}
-	return m.db.UpsertModel(info)
+	if err := m.db.UpsertModel(info); err != nil {
+		return err
+	}
+	return m.inheritMetadata(info.Name, tf, now)
 }
 
 // --- Internal helpers ---
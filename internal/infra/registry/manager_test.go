@@ -1,6 +1,7 @@
 package registry
 
 import (
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -129,6 +130,64 @@ func TestManager_Pull_AlreadyExists(t *testing.T) {
 	}
 }
 
+// newSwarmOrigin serves content for GETs (counting them) and announces
+// its digest on HEAD as Hugging Face does for LFS files.
+func newSwarmOrigin(t *testing.T, content []byte, gets *int) string {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w.Header().Set("X-Linked-Etag", `"`+computeSHA256(content)+`"`)
+			w.WriteHeader(http.StatusFound)
+			return
+		}
+		*gets++
+		w.Write(content)
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+func TestManager_Pull_FromSwarm(t *testing.T) {
+	mgr := newTestManager(t)
+	content := []byte("GGUF-FROM-PEERS")
+	var gets int
+	mgr.SetTestURL(newSwarmOrigin(t, content, &gets))
+
+	var asked string
+	mgr.SetSwarm(func(model, digest, dest string, progress func(string, float64)) error {
+		asked = model + "@" + digest
+		return os.WriteFile(dest, content, 0o644)
+	})
+	if err := mgr.Pull("llama3", nil); err != nil {
+		t.Fatalf("Pull() error: %v", err)
+	}
+	if want := "llama3@" + computeSHA256(content); asked != want {
+		t.Errorf("swarm asked for %q, want %q", asked, want)
+	}
+	if gets != 0 {
+		t.Errorf("origin served %d downloads, want 0", gets)
+	}
+	info, err := mgr.Show("llama3")
+	if err != nil || info.Digest != "sha256:"+computeSHA256(content) {
+		t.Errorf("Show() = %+v, %v, want the swarm's file installed", info, err)
+	}
+}
+
+func TestManager_Pull_SwarmFallsBackToOrigin(t *testing.T) {
+	mgr := newTestManager(t)
+	var gets int
+	mgr.SetTestURL(newSwarmOrigin(t, []byte("GGUF-FROM-ORIGIN"), &gets))
+	mgr.SetSwarm(func(model, digest, dest string, progress func(string, float64)) error {
+		return errors.New("no peers")
+	})
+	if err := mgr.Pull("llama3", nil); err != nil {
+		t.Fatalf("Pull() error: %v", err)
+	}
+	if gets != 1 {
+		t.Errorf("origin served %d downloads, want 1", gets)
+	}
+}
+
 // ─── HasLocal Tests ─────────────────────────────────────────────────────────
 
 func TestManager_HasLocal(t *testing.T) {
@@ -185,6 +244,60 @@ func TestManager_Resolve_NotFound(t *testing.T) {
 	}
 }
 
+func TestManager_Resolve_CorruptBlob(t *testing.T) {
+	mgr := newTestManager(t)
+	if err := mgr.Pull("llama3", nil); err != nil {
+		t.Fatalf("Pull() error: %v", err)
+	}
+	path, err := mgr.Resolve("llama3")
+	if err != nil {
+		t.Fatalf("Resolve() error: %v", err)
+	}
+
+	var reported []domain.ModelVerification
+	mgr.SetCorruptionHook(func(v domain.ModelVerification) { reported = append(reported, v) })
+
+	// Flip the weights on disk — same path, different content
+	if err := os.WriteFile(path, []byte("GGUF-TAMPERED"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = mgr.Resolve("llama3")
+	if !errors.Is(err, domain.ErrModelCorrupted) {
+		t.Fatalf("Resolve(corrupt) = %v, want ErrModelCorrupted", err)
+	}
+	if len(reported) != 1 || reported[0].Verified || reported[0].ActualDigest == reported[0].ExpectedDigest {
+		t.Errorf("corruption hook calls = %+v, want one failed verification", reported)
+	}
+
+	rec, err := mgr.db.GetModelVerification("llama3")
+	if err != nil || rec == nil {
+		t.Fatalf("GetModelVerification = %v, %v", rec, err)
+	}
+	if rec.Verified || rec.Failures != 1 {
+		t.Errorf("stored verification = %+v, want failed with 1 failure", rec)
+	}
+}
+
+func TestManager_Verify(t *testing.T) {
+	mgr := newTestManager(t)
+	if err := mgr.Pull("llama3", nil); err != nil {
+		t.Fatalf("Pull() error: %v", err)
+	}
+
+	v, err := mgr.Verify("llama3")
+	if err != nil {
+		t.Fatalf("Verify() error: %v", err)
+	}
+	if !v.Verified || v.ActualDigest != v.ExpectedDigest {
+		t.Errorf("Verify() = %+v, want verified", v)
+	}
+
+	if _, err := mgr.Verify("nonexistent"); err == nil {
+		t.Error("Verify(nonexistent) should fail")
+	}
+}
+
 // ─── List Tests ─────────────────────────────────────────────────────────────
 
 func TestManager_List(t *testing.T) {
@@ -246,6 +359,51 @@ func TestManager_Show_NotFound(t *testing.T) {
 	}
 }
 
+func TestManager_Metadata(t *testing.T) {
+	mgr := newTestManager(t)
+
+	if err := mgr.Pull("llama3", nil); err != nil {
+		t.Fatalf("Pull() error: %v", err)
+	}
+	info, err := mgr.Show("llama3")
+	if err != nil {
+		t.Fatalf("Show() error: %v", err)
+	}
+	md := info.Metadata
+	if md == nil || md.ContextLength != 4096 || md.Quantization != "Q4_K_M" || !md.ToolCalling ||
+		md.License != "llama3.2" || len(md.Modalities) != 1 || md.Modalities[0] != domain.ModalityText {
+		t.Fatalf("Metadata = %+v, want the catalog entry's", md)
+	}
+
+	// A TuTufile model inherits the base model's, with its own overrides.
+	tf := domain.TuTufile{
+		From:       "llama3",
+		License:    "custom",
+		Parameters: map[string][]string{"num_ctx": {"2048"}},
+	}
+	if err := mgr.CreateFromTuTufile("my-pirate", tf); err != nil {
+		t.Fatalf("CreateFromTuTufile() error: %v", err)
+	}
+	models, err := mgr.List()
+	if err != nil {
+		t.Fatalf("List() error: %v", err)
+	}
+	for _, m := range models {
+		if m.Name == "my-pirate" {
+			if m.Metadata == nil || m.Metadata.ContextLength != 2048 || m.Metadata.License != "custom" || !m.Metadata.ToolCalling {
+				t.Errorf("my-pirate metadata = %+v", m.Metadata)
+			}
+		}
+	}
+
+	if err := mgr.Remove("my-pirate"); err != nil {
+		t.Fatalf("Remove() error: %v", err)
+	}
+	if md, _ := mgr.db.GetModelMetadata("my-pirate"); md != nil {
+		t.Errorf("metadata kept after Remove: %+v", md)
+	}
+}
+
 // ─── Remove Tests ───────────────────────────────────────────────────────────
 
 func TestManager_Remove(t *testing.T) {
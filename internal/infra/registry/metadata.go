@@ -0,0 +1,96 @@
+package registry
+
+import (
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
+	"github.com/tutu-network/tutu/internal/infra/catalog"
+)
+
+// ─── Model Metadata ─────────────────────────────────────────────────────────
+// Pull records what a model can do — context window, quantization,
+// modalities, tool calling, license — from its catalog entry, and a
+// TuTufile model inherits its base model's. Show and List attach it to the
+// model info; models pulled before metadata was recorded fall back to the
+// catalog.
+
+// entryMetadata is the metadata a catalog entry describes.
+func entryMetadata(model string, e *catalog.ModelEntry, now time.Time) domain.ModelMetadata {
+	modalities := e.Modalities
+	if len(modalities) == 0 {
+		modalities = []string{domain.ModalityText}
+	}
+	return domain.ModelMetadata{
+		Model:         model,
+		ContextLength: e.ContextSize,
+		Quantization:  e.Quantization,
+		Modalities:    append([]string(nil), modalities...),
+		ToolCalling:   e.ToolCalling,
+		License:       e.License,
+		UpdatedAt:     now,
+	}
+}
+
+// lookupEntry finds the catalog entry for ref, by full name then bare name.
+func lookupEntry(ref domain.ModelRef) *catalog.ModelEntry {
+	if e := catalog.Lookup(ref.String()); e != nil {
+		return e
+	}
+	return catalog.Lookup(ref.Name)
+}
+
+// metadata returns the recorded metadata of a model, or what the catalog
+// says about it, or nil.
+func (m *Manager) metadata(name string) *domain.ModelMetadata {
+	md, err := m.db.GetModelMetadata(name)
+	if err != nil {
+		log.Printf("[registry] metadata of %s: %v", name, err)
+	}
+	if md != nil {
+		return md
+	}
+	if e := lookupEntry(ParseRef(name)); e != nil {
+		md := entryMetadata(name, e, time.Time{})
+		return &md
+	}
+	return nil
+}
+
+// attachMetadata fills in Metadata for each model.
+func (m *Manager) attachMetadata(models []domain.ModelInfo) {
+	stored, err := m.db.ListModelMetadata()
+	if err != nil {
+		log.Printf("[registry] list metadata: %v", err)
+	}
+	for i := range models {
+		if md, ok := stored[models[i].Name]; ok {
+			models[i].Metadata = &md
+		} else if e := lookupEntry(ParseRef(models[i].Name)); e != nil {
+			md := entryMetadata(models[i].Name, e, time.Time{})
+			models[i].Metadata = &md
+		}
+	}
+}
+
+// inheritMetadata records a TuTufile model's metadata: its base model's,
+// with the TuTufile's LICENSE and num_ctx taking precedence.
+func (m *Manager) inheritMetadata(model string, tf domain.TuTufile, now time.Time) error {
+	md := domain.ModelMetadata{Modalities: []string{domain.ModalityText}}
+	if tf.From != "" {
+		if base := m.metadata(ParseRef(tf.From).String()); base != nil {
+			md = *base
+		}
+	}
+	md.Model, md.UpdatedAt = model, now
+	if tf.License != "" {
+		md.License = tf.License
+	}
+	if v := tf.Parameters["num_ctx"]; len(v) > 0 {
+		if n, err := strconv.Atoi(v[len(v)-1]); err == nil && n > 0 {
+			md.ContextLength = n
+		}
+	}
+	return m.db.UpsertModelMetadata(md)
+}
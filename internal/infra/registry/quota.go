@@ -0,0 +1,195 @@
+package registry
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
+)
+
+// ─── Storage Quota & Pinning ────────────────────────────────────────────────
+// A node may cap how much disk its cached models use. When a pull would
+// exceed the budget, unpinned models are evicted until it fits:
+//
+//  1. retirement candidates from the intelligence optimizer, in its order
+//  2. then least-recently-used models
+//
+// Pinned models are never evicted automatically. An explicit Remove still
+// deletes them — pinning protects against the node, not the operator.
+
+// StorageUsage summarizes the local model store.
+type StorageUsage struct {
+	BudgetBytes int64              `json:"budget_bytes"` // 0 = unlimited
+	UsedBytes   int64              `json:"used_bytes"`
+	PinnedBytes int64              `json:"pinned_bytes"`
+	FreeBytes   int64              `json:"free_bytes"` // remaining budget; 0 if unlimited or over
+	Models      []domain.ModelInfo `json:"models"`
+}
+
+// SetStorageBudget caps total model storage. Zero or negative disables the cap.
+func (m *Manager) SetStorageBudget(bytes int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if bytes < 0 {
+		bytes = 0
+	}
+	m.budget = bytes
+}
+
+// SetEvictionCandidates installs a source of models to evict first when
+// storage runs out (e.g. the intelligence optimizer's retirement scan).
+func (m *Manager) SetEvictionCandidates(fn func() []string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.evictFirst = fn
+}
+
+// SetPinned pins or unpins a model.
+func (m *Manager) SetPinned(name string, pinned bool) error {
+	if err := m.db.SetModelPinned(ParseRef(name).String(), pinned); err != nil {
+		return fmt.Errorf("pin %s: %w", name, err)
+	}
+	return nil
+}
+
+// Usage reports storage consumption against the budget.
+func (m *Manager) Usage() (StorageUsage, error) {
+	models, err := m.db.ListModels()
+	if err != nil {
+		return StorageUsage{}, err
+	}
+	m.mu.Lock()
+	u := StorageUsage{BudgetBytes: m.budget, Models: models}
+	m.mu.Unlock()
+
+	for _, mi := range models {
+		u.UsedBytes += mi.SizeBytes
+		if mi.Pinned {
+			u.PinnedBytes += mi.SizeBytes
+		}
+	}
+	if u.BudgetBytes > 0 && u.UsedBytes < u.BudgetBytes {
+		u.FreeBytes = u.BudgetBytes - u.UsedBytes
+	}
+	if u.Models == nil {
+		u.Models = []domain.ModelInfo{}
+	}
+	return u, nil
+}
+
+// Retire removes an unpinned model and records it in the retirement log.
+// Returns domain.ErrRetirementProtected for pinned models.
+func (m *Manager) Retire(name, reason string) error {
+	info, err := m.Show(name)
+	if err != nil {
+		return err
+	}
+	if info.Pinned {
+		return fmt.Errorf("retire %s: %w", info.Name, domain.ErrRetirementProtected)
+	}
+	if err := m.Remove(info.Name); err != nil {
+		return err
+	}
+	_ = m.db.LogModelRetirement(info.Name, info.LastUsed, info.SizeBytes, reason)
+	return nil
+}
+
+// EnforceQuota evicts unpinned models until usage is within budget and
+// returns the names of evicted models.
+func (m *Manager) EnforceQuota() ([]string, error) {
+	return m.makeRoom(0)
+}
+
+// makeRoom evicts unpinned models until need more bytes fit in the budget.
+// Returns domain.ErrStorageQuotaExceeded if even evicting every unpinned
+// model would not be enough; nothing is evicted in that case.
+func (m *Manager) makeRoom(need int64) ([]string, error) {
+	m.mu.Lock()
+	budget, advisor := m.budget, m.evictFirst
+	m.mu.Unlock()
+	if budget <= 0 {
+		return nil, nil
+	}
+
+	u, err := m.Usage()
+	if err != nil {
+		return nil, err
+	}
+	excess := u.UsedBytes + need - budget
+	if excess <= 0 {
+		return nil, nil
+	}
+	if u.PinnedBytes+need > budget {
+		return nil, fmt.Errorf("need %s, budget %s, pinned %s: %w",
+			domain.HumanSize(need), domain.HumanSize(budget),
+			domain.HumanSize(u.PinnedBytes), domain.ErrStorageQuotaExceeded)
+	}
+
+	var preferred []string
+	if advisor != nil {
+		preferred = advisor()
+	}
+
+	var evicted []string
+	for _, mi := range evictionOrder(u.Models, preferred) {
+		if excess <= 0 {
+			break
+		}
+		reason := "storage quota"
+		if contains(preferred, mi.Name) {
+			reason = "storage quota: retirement candidate"
+		}
+		if err := m.Retire(mi.Name, reason); err != nil {
+			return evicted, err
+		}
+		evicted = append(evicted, mi.Name)
+		excess -= mi.SizeBytes
+	}
+	return evicted, nil
+}
+
+// evictionOrder returns unpinned models with retirement candidates first
+// (in candidate order), then the rest least-recently-used first.
+func evictionOrder(models []domain.ModelInfo, preferred []string) []domain.ModelInfo {
+	byName := make(map[string]domain.ModelInfo, len(models))
+	for _, mi := range models {
+		if !mi.Pinned {
+			byName[mi.Name] = mi
+		}
+	}
+
+	order := make([]domain.ModelInfo, 0, len(byName))
+	for _, name := range preferred {
+		name = ParseRef(name).String()
+		if mi, ok := byName[name]; ok {
+			order = append(order, mi)
+			delete(byName, name)
+		}
+	}
+
+	rest := make([]domain.ModelInfo, 0, len(byName))
+	for _, mi := range byName {
+		rest = append(rest, mi)
+	}
+	sort.Slice(rest, func(i, j int) bool {
+		return lastTouched(rest[i]).Before(lastTouched(rest[j]))
+	})
+	return append(order, rest...)
+}
+
+func lastTouched(mi domain.ModelInfo) time.Time {
+	if mi.LastUsed.After(mi.PulledAt) {
+		return mi.LastUsed
+	}
+	return mi.PulledAt
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if ParseRef(n).String() == name {
+			return true
+		}
+	}
+	return false
+}
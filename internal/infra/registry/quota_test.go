@@ -0,0 +1,116 @@
+package registry
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
+)
+
+// seedModels inserts model records directly (no blobs needed for quota math).
+func seedModels(t *testing.T, mgr *Manager, models ...domain.ModelInfo) {
+	t.Helper()
+	for _, m := range models {
+		if err := mgr.db.UpsertModel(m); err != nil {
+			t.Fatalf("UpsertModel(%s): %v", m.Name, err)
+		}
+	}
+}
+
+func quotaFixture(t *testing.T) *Manager {
+	t.Helper()
+	mgr := newTestManager(t)
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	seedModels(t, mgr,
+		domain.ModelInfo{Name: "old", SizeBytes: 400, PulledAt: base},
+		domain.ModelInfo{Name: "mid", SizeBytes: 300, PulledAt: base.Add(time.Hour)},
+		domain.ModelInfo{Name: "new", SizeBytes: 200, PulledAt: base.Add(2 * time.Hour)},
+		domain.ModelInfo{Name: "keep", SizeBytes: 100, PulledAt: base, Pinned: true},
+	)
+	return mgr
+}
+
+func TestManager_Usage(t *testing.T) {
+	mgr := quotaFixture(t)
+	mgr.SetStorageBudget(2000)
+
+	u, err := mgr.Usage()
+	if err != nil {
+		t.Fatalf("Usage: %v", err)
+	}
+	if u.UsedBytes != 1000 || u.PinnedBytes != 100 || u.FreeBytes != 1000 {
+		t.Errorf("Usage = used %d pinned %d free %d, want 1000/100/1000", u.UsedBytes, u.PinnedBytes, u.FreeBytes)
+	}
+	if len(u.Models) != 4 {
+		t.Errorf("Models = %d, want 4", len(u.Models))
+	}
+}
+
+func TestManager_EnforceQuota(t *testing.T) {
+	tests := []struct {
+		name      string
+		budget    int64
+		preferred []string
+		want      []string
+	}{
+		{"unlimited", 0, nil, nil},
+		{"within budget", 1000, nil, nil},
+		{"LRU first", 700, nil, []string{"old"}},
+		{"LRU until fits", 400, nil, []string{"old", "mid"}},
+		{"retirement candidates first", 800, []string{"new"}, []string{"new"}},
+		{"pinned candidate skipped", 700, []string{"keep"}, []string{"old"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mgr := quotaFixture(t)
+			mgr.SetStorageBudget(tt.budget)
+			mgr.SetEvictionCandidates(func() []string { return tt.preferred })
+
+			evicted, err := mgr.EnforceQuota()
+			if err != nil {
+				t.Fatalf("EnforceQuota: %v", err)
+			}
+			if !reflect.DeepEqual(evicted, tt.want) {
+				t.Errorf("evicted = %v, want %v", evicted, tt.want)
+			}
+			if info, _ := mgr.db.GetModel("keep"); info == nil {
+				t.Error("pinned model was evicted")
+			}
+		})
+	}
+}
+
+func TestManager_MakeRoom_PinnedExceedsBudget(t *testing.T) {
+	mgr := quotaFixture(t)
+	mgr.SetStorageBudget(150)
+
+	_, err := mgr.makeRoom(100)
+	if !errors.Is(err, domain.ErrStorageQuotaExceeded) {
+		t.Fatalf("err = %v, want ErrStorageQuotaExceeded", err)
+	}
+	if u, _ := mgr.Usage(); len(u.Models) != 4 {
+		t.Errorf("models = %d, want 4 — nothing may be evicted on failure", len(u.Models))
+	}
+}
+
+func TestManager_SetPinned(t *testing.T) {
+	mgr := quotaFixture(t)
+
+	if err := mgr.SetPinned("old", true); err != nil {
+		t.Fatalf("SetPinned: %v", err)
+	}
+	if err := mgr.Retire("old", "test"); !errors.Is(err, domain.ErrRetirementProtected) {
+		t.Errorf("Retire(pinned) = %v, want ErrRetirementProtected", err)
+	}
+	if err := mgr.SetPinned("old", false); err != nil {
+		t.Fatalf("SetPinned(false): %v", err)
+	}
+	if err := mgr.Retire("old", "test"); err != nil {
+		t.Errorf("Retire(unpinned) = %v", err)
+	}
+	if err := mgr.SetPinned("ghost", true); !errors.Is(err, domain.ErrModelNotFound) {
+		t.Errorf("SetPinned(missing) = %v, want ErrModelNotFound", err)
+	}
+}
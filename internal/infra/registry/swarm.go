@@ -0,0 +1,90 @@
+package registry
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
+)
+
+// ─── Swarm Pulls ────────────────────────────────────────────────────────────
+// A model that peers already host is fetched from them, chunk by chunk,
+// before the origin is asked for it. Peers are not trusted to say what a
+// model is: the digest to fetch comes from the origin, which for Hugging
+// Face LFS files is the X-Linked-Etag header of a HEAD on the download URL,
+// read without following the redirect to the CDN. When the origin does not
+// publish a digest, or the swarm cannot deliver, Pull downloads from the
+// origin as before.
+
+// SwarmFetch downloads the model file whose SHA-256 hex is digest into dest,
+// from the peers that host model, reporting progress like Pull.
+type SwarmFetch func(model, digest, dest string, progress func(status string, pct float64)) error
+
+// originHeadTimeout bounds the digest lookup that precedes a swarm pull.
+const originHeadTimeout = 15 * time.Second
+
+// SetSwarm installs a peer download path tried before the origin.
+func (m *Manager) SetSwarm(fetch SwarmFetch) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.swarm = fetch
+}
+
+// pullFromSwarm fetches ref from peers into a temp file and returns its
+// path, or false if the origin download is needed.
+func (m *Manager) pullFromSwarm(ref domain.ModelRef, url string, progress func(status string, pct float64)) (string, bool) {
+	m.mu.Lock()
+	fetch := m.swarm
+	m.mu.Unlock()
+	if fetch == nil {
+		return "", false
+	}
+	digest, ok := originDigest(url)
+	if !ok {
+		return "", false
+	}
+
+	if progress != nil {
+		progress("looking for peers hosting "+ref.String(), 0)
+	}
+	dest := filepath.Join(m.dir, "blobs", ".swarm-"+ref.Name+".tmp")
+	if err := fetch(ref.String(), digest, dest, progress); err != nil {
+		if progress != nil {
+			progress(fmt.Sprintf("peers unavailable (%v), downloading from origin", err), 0)
+		}
+		return "", false
+	}
+	return dest, true
+}
+
+// originDigest asks the origin for the SHA-256 hex of the file at url.
+func originDigest(url string) (string, bool) {
+	client := &http.Client{
+		Timeout: originHeadTimeout,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse // the digest is on the redirect itself
+		},
+	}
+	req, err := http.NewRequest(http.MethodHead, url, nil)
+	if err != nil {
+		return "", false
+	}
+	req.Header.Set("User-Agent", "TuTu/0.1.0")
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", false
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		return "", false
+	}
+	digest := strings.ToLower(strings.Trim(resp.Header.Get("X-Linked-Etag"), `"`))
+	if b, err := hex.DecodeString(digest); err != nil || len(b) != 32 {
+		return "", false
+	}
+	return digest, true
+}
@@ -0,0 +1,218 @@
+package reputation
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// ─── Bulk Export ────────────────────────────────────────────────────────────
+// Operators and researchers studying trust dynamics need the whole table,
+// not the leaderboard. ExportAll streams every node as JSON Lines or CSV;
+// Summary reduces the table to a score histogram, tier counts and component
+// averages. Anonymized exports replace node IDs with keyed pseudonyms: the
+// same salt maps a node to the same pseudonym across exports, so snapshots
+// can be joined over time without revealing who is who.
+
+// ExportFormat selects the ExportAll encoding.
+type ExportFormat string
+
+const (
+	FormatJSON ExportFormat = "json" // JSON Lines, one node per line
+	FormatCSV  ExportFormat = "csv"  // header row, then one node per row
+)
+
+// ExportOptions configures ExportAll.
+type ExportOptions struct {
+	Format    ExportFormat // default FormatJSON
+	Anonymize bool         // replace node IDs with pseudonyms
+	Salt      string       // keys the pseudonyms; empty = random per export, so exports cannot be joined
+}
+
+// ExportRecord is one node in an export.
+type ExportRecord struct {
+	NodeID     string     `json:"node_id"`
+	Overall    float64    `json:"overall"`
+	Tier       string     `json:"tier"`
+	Components Components `json:"components"`
+	Penalties  float64    `json:"penalties"`
+	TaskCount  int        `json:"task_count"`
+	DaysActive int        `json:"days_active"`
+	JoinedAt   time.Time  `json:"joined_at"`
+	LastUpdate time.Time  `json:"last_update"`
+}
+
+// csvHeader is the CSV column order.
+var csvHeader = []string{
+	"node_id", "overall", "tier",
+	"reliability", "accuracy", "availability", "speed", "longevity",
+	"penalties", "task_count", "days_active", "joined_at", "last_update",
+}
+
+// ExportAll writes every node to w, ordered by (possibly anonymized) node
+// ID. It returns the number of nodes written. The table is snapshotted
+// first, so a slow writer never holds the tracker lock.
+func (t *Tracker) ExportAll(w io.Writer, opts ExportOptions) (int, error) {
+	if opts.Format == "" {
+		opts.Format = FormatJSON
+	}
+	if opts.Format != FormatJSON && opts.Format != FormatCSV {
+		return 0, fmt.Errorf("reputation: unknown export format %q", opts.Format)
+	}
+	var key []byte
+	if opts.Anonymize {
+		key = []byte(opts.Salt)
+		if len(key) == 0 {
+			key = make([]byte, 32)
+			if _, err := rand.Read(key); err != nil {
+				return 0, fmt.Errorf("reputation: export salt: %w", err)
+			}
+		}
+	}
+
+	records := t.snapshot()
+	for i := range records {
+		if key != nil {
+			records[i].NodeID = pseudonym(key, records[i].NodeID)
+		}
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].NodeID < records[j].NodeID })
+
+	if opts.Format == FormatCSV {
+		return len(records), writeCSV(w, records)
+	}
+	enc := json.NewEncoder(w)
+	for i, r := range records {
+		if err := enc.Encode(r); err != nil {
+			return i, fmt.Errorf("reputation: export node %d: %w", i, err)
+		}
+	}
+	return len(records), nil
+}
+
+// writeCSV writes records with a header row.
+func writeCSV(w io.Writer, records []ExportRecord) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return fmt.Errorf("reputation: export header: %w", err)
+	}
+	f := func(v float64) string { return strconv.FormatFloat(v, 'f', 6, 64) }
+	for i, r := range records {
+		c := r.Components
+		row := []string{
+			r.NodeID, f(r.Overall), r.Tier,
+			f(c.Reliability), f(c.Accuracy), f(c.Availability), f(c.Speed), f(c.Longevity),
+			f(r.Penalties), strconv.Itoa(r.TaskCount), strconv.Itoa(r.DaysActive),
+			r.JoinedAt.UTC().Format(time.RFC3339), r.LastUpdate.UTC().Format(time.RFC3339),
+		}
+		if err := cw.Write(row); err != nil {
+			return fmt.Errorf("reputation: export node %d: %w", i, err)
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// snapshot copies every node into an export record.
+func (t *Tracker) snapshot() []ExportRecord {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	out := make([]ExportRecord, 0, len(t.nodes))
+	for _, rep := range t.nodes {
+		out = append(out, ExportRecord{
+			NodeID:     rep.NodeID,
+			Overall:    rep.Overall(),
+			Tier:       rep.TrustTier(),
+			Components: rep.Components,
+			Penalties:  rep.Penalties,
+			TaskCount:  rep.TaskCount,
+			DaysActive: rep.DaysActive,
+			JoinedAt:   rep.JoinedAt,
+			LastUpdate: rep.LastUpdate,
+		})
+	}
+	return out
+}
+
+// pseudonym derives a stand-in for a node ID that is stable per key.
+func pseudonym(key []byte, nodeID string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(nodeID))
+	return "anon-" + hex.EncodeToString(mac.Sum(nil)[:8])
+}
+
+// ─── Summary ────────────────────────────────────────────────────────────────
+
+// DefaultHistogramBins is the Summary bucket count when none is given.
+const DefaultHistogramBins = 10
+
+// HistogramBin counts nodes whose overall score falls in [Lo, Hi); the last
+// bin also includes Hi.
+type HistogramBin struct {
+	Lo    float64 `json:"lo"`
+	Hi    float64 `json:"hi"`
+	Count int     `json:"count"`
+}
+
+// Summary describes the distribution of reputation across the network.
+type Summary struct {
+	Nodes         int            `json:"nodes"`
+	Histogram     []HistogramBin `json:"histogram"` // overall score over [0, 1]
+	Tiers         map[string]int `json:"tiers"`     // TrustTier → node count
+	MeanOverall   float64        `json:"mean_overall"`
+	MeanPenalties float64        `json:"mean_penalties"`
+	Components    Components     `json:"components"` // per-component means
+}
+
+// Summary buckets overall scores into equal-width bins over [0, 1]
+// (DefaultHistogramBins when bins <= 0) and averages every component.
+func (t *Tracker) Summary(bins int) Summary {
+	if bins <= 0 {
+		bins = DefaultHistogramBins
+	}
+	records := t.snapshot()
+
+	s := Summary{
+		Nodes:     len(records),
+		Histogram: make([]HistogramBin, bins),
+		Tiers:     map[string]int{"EXCELLENT": 0, "GOOD": 0, "NEUTRAL": 0, "LOW": 0, "POOR": 0},
+	}
+	width := CeilingReputation / float64(bins)
+	for i := range s.Histogram {
+		s.Histogram[i] = HistogramBin{Lo: float64(i) * width, Hi: float64(i+1) * width}
+	}
+	for _, r := range records {
+		b := int(r.Overall / width)
+		if b >= bins {
+			b = bins - 1
+		}
+		s.Histogram[b].Count++
+		s.Tiers[r.Tier]++
+		s.MeanOverall += r.Overall
+		s.MeanPenalties += r.Penalties
+		s.Components.Reliability += r.Components.Reliability
+		s.Components.Accuracy += r.Components.Accuracy
+		s.Components.Availability += r.Components.Availability
+		s.Components.Speed += r.Components.Speed
+		s.Components.Longevity += r.Components.Longevity
+	}
+	if n := float64(len(records)); n > 0 {
+		s.MeanOverall /= n
+		s.MeanPenalties /= n
+		s.Components.Reliability /= n
+		s.Components.Accuracy /= n
+		s.Components.Availability /= n
+		s.Components.Speed /= n
+		s.Components.Longevity /= n
+	}
+	return s
+}
@@ -0,0 +1,111 @@
+package reputation
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestExportAll_JSON(t *testing.T) {
+	tr := newTestTracker(t)
+	tr.Register("node-b")
+	tr.Register("node-a")
+	tr.RecordPenalty("node-b", PenaltyEvent{Severity: 1, Reason: "bad result"})
+
+	var buf bytes.Buffer
+	n, err := tr.ExportAll(&buf, ExportOptions{})
+	if err != nil || n != 2 {
+		t.Fatalf("ExportAll = %d, %v", n, err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2", len(lines))
+	}
+	var rec ExportRecord
+	if err := json.Unmarshal([]byte(lines[1]), &rec); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if rec.NodeID != "node-b" || rec.Penalties != 1 {
+		t.Errorf("second record = %+v, want node-b with a penalty", rec)
+	}
+}
+
+func TestExportAll_CSV(t *testing.T) {
+	tr := newTestTracker(t)
+	tr.Register("node-a")
+
+	var buf bytes.Buffer
+	if _, err := tr.ExportAll(&buf, ExportOptions{Format: FormatCSV}); err != nil {
+		t.Fatalf("ExportAll: %v", err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("read csv: %v", err)
+	}
+	if len(rows) != 2 || len(rows[1]) != len(csvHeader) || rows[1][0] != "node-a" {
+		t.Errorf("rows = %v", rows)
+	}
+
+	if _, err := tr.ExportAll(&buf, ExportOptions{Format: "xml"}); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+func TestExportAll_Anonymize(t *testing.T) {
+	tr := newTestTracker(t)
+	tr.Register("node-a")
+
+	export := func(opts ExportOptions) ExportRecord {
+		t.Helper()
+		var buf bytes.Buffer
+		if _, err := tr.ExportAll(&buf, opts); err != nil {
+			t.Fatalf("ExportAll: %v", err)
+		}
+		var rec ExportRecord
+		if err := json.Unmarshal(buf.Bytes(), &rec); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		return rec
+	}
+
+	a := export(ExportOptions{Anonymize: true, Salt: "study-1"})
+	b := export(ExportOptions{Anonymize: true, Salt: "study-1"})
+	if !strings.HasPrefix(a.NodeID, "anon-") || strings.Contains(a.NodeID, "node-a") {
+		t.Errorf("node ID not anonymized: %s", a.NodeID)
+	}
+	if a.NodeID != b.NodeID {
+		t.Errorf("same salt gave %s and %s", a.NodeID, b.NodeID)
+	}
+	if c := export(ExportOptions{Anonymize: true, Salt: "study-2"}); c.NodeID == a.NodeID {
+		t.Error("different salts gave the same pseudonym")
+	}
+	if r1, r2 := export(ExportOptions{Anonymize: true}), export(ExportOptions{Anonymize: true}); r1.NodeID == r2.NodeID {
+		t.Error("unsalted exports should not be joinable")
+	}
+}
+
+func TestSummary(t *testing.T) {
+	tr := newTestTracker(t)
+	if s := tr.Summary(0); s.Nodes != 0 || len(s.Histogram) != DefaultHistogramBins || s.MeanOverall != 0 {
+		t.Errorf("empty summary = %+v", s)
+	}
+
+	tr.Register("node-a")
+	tr.Register("node-b")
+	rep := tr.Get("node-b")
+	rep.Components = Components{Reliability: 1, Accuracy: 1, Availability: 1, Speed: 1, Longevity: 1}
+
+	s := tr.Summary(4)
+	if s.Nodes != 2 || s.Tiers["LOW"] != 1 || s.Tiers["EXCELLENT"] != 1 {
+		t.Errorf("tiers = %v", s.Tiers)
+	}
+	// 0.45 lands in [0.25, 0.5); 1.0 lands in the closed last bin
+	if s.Histogram[1].Count != 1 || s.Histogram[3].Count != 1 {
+		t.Errorf("histogram = %+v", s.Histogram)
+	}
+	if !almostEqual(s.Components.Reliability, 0.75, 1e-9) || !almostEqual(s.MeanOverall, 0.725, 1e-9) {
+		t.Errorf("means = %+v, overall %f", s.Components, s.MeanOverall)
+	}
+}
@@ -19,6 +19,8 @@ import (
 	"math"
 	"sync"
 	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
 )
 
 // ─── Constants ──────────────────────────────────────────────────────────────
@@ -137,19 +139,24 @@ func (nr *NodeReputation) alpha() float64 {
 type TaskOutcome struct {
 	Successful     bool          // Did the task complete without error?
 	ResultVerified bool          // Was the result verified correct?
+	Accuracy       float64       // Graded verification score in (0, 1] for a verified result (0 = ungraded)
+	Disputed       bool          // Result was disputed after payment (counts as failed and inaccurate)
 	ExpectedTime   time.Duration // How long was expected?
 	ActualTime     time.Duration // How long did it actually take?
+	FederationID   string        // Federation the task ran in ("" = public network)
 }
 
 // AvailabilityCheck describes whether a node was online when pinged.
 type AvailabilityCheck struct {
-	WasOnline bool
+	WasOnline    bool
+	FederationID string // Federation that needed the node ("" = public network)
 }
 
 // PenaltyEvent logs a penalty against a node.
 type PenaltyEvent struct {
-	Severity float64 // How severe (0.1 = minor, 1.0 = major)
-	Reason   string
+	Severity     float64 // How severe (0.1 = minor, 1.0 = major)
+	Reason       string
+	FederationID string // Federation the offence happened in ("" = public network)
 }
 
 // ─── Configuration ──────────────────────────────────────────────────────────
@@ -177,6 +184,9 @@ type Tracker struct {
 	config TrackerConfig
 	nodes  map[string]*NodeReputation // nodeID → reputation
 
+	// Federation-scoped reputations (see scoped.go).
+	scoped map[string]map[string]*NodeReputation // fedID → nodeID → reputation
+
 	// Injectable clock for testing.
 	now func() time.Time
 }
@@ -186,10 +196,19 @@ func NewTracker(cfg TrackerConfig) *Tracker {
 	return &Tracker{
 		config: cfg,
 		nodes:  make(map[string]*NodeReputation),
+		scoped: make(map[string]map[string]*NodeReputation),
 		now:    time.Now,
 	}
 }
 
+// SetClock replaces the tracker's clock, e.g. with a cluster-wide fake
+// clock in integration tests.
+func (t *Tracker) SetClock(now func() time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.now = now
+}
+
 // ─── Node Registration ─────────────────────────────────────────────────────
 
 // Register initializes reputation for a new node at the default neutral level.
@@ -201,8 +220,14 @@ func (t *Tracker) Register(nodeID string) *NodeReputation {
 		return existing
 	}
 
-	now := t.now()
-	rep := &NodeReputation{
+	rep := newNodeReputation(nodeID, t.now())
+	t.nodes[nodeID] = rep
+	return rep
+}
+
+// newNodeReputation returns a neutral reputation joined at now.
+func newNodeReputation(nodeID string, now time.Time) *NodeReputation {
+	return &NodeReputation{
 		NodeID: nodeID,
 		Components: Components{
 			Reliability:  DefaultReputation,
@@ -215,8 +240,6 @@ func (t *Tracker) Register(nodeID string) *NodeReputation {
 		LastDecay:  now,
 		JoinedAt:   now,
 	}
-	t.nodes[nodeID] = rep
-	return rep
 }
 
 // Get returns a node's current reputation. Returns nil if not registered.
@@ -240,30 +263,46 @@ func (t *Tracker) GetOrRegister(nodeID string) *NodeReputation {
 // ─── Score Updates ──────────────────────────────────────────────────────────
 
 // RecordTask updates reputation based on a task outcome.
-// Updates reliability, accuracy, and speed in one call.
+// Updates reliability, accuracy, and speed in one call. A federation-tagged
+// outcome also updates the node's score within that federation.
 func (t *Tracker) RecordTask(nodeID string, outcome TaskOutcome) error {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
 	rep, ok := t.nodes[nodeID]
 	if !ok {
-		return fmt.Errorf("node %s not registered", nodeID)
+		return fmt.Errorf("%w: %s", domain.ErrNodeNotRegistered, nodeID)
+	}
+	now := t.now()
+	rep.applyTask(outcome, now)
+	if outcome.FederationID != "" {
+		t.scopedLocked(outcome.FederationID, nodeID, now).applyTask(outcome, now)
 	}
+	return nil
+}
 
+// applyTask folds a task outcome into the reputation.
+func (rep *NodeReputation) applyTask(outcome TaskOutcome, now time.Time) {
 	α := rep.alpha()
 
 	// Reliability: 1.0 if successful, 0.0 if failed
 	reliabilitySignal := 0.0
-	if outcome.Successful {
+	if outcome.Successful && !outcome.Disputed {
 		reliabilitySignal = 1.0
 	}
 	rep.Components.Reliability = ema(rep.Components.Reliability, reliabilitySignal, α)
 
-	// Accuracy: 1.0 if verified, 0.0 if not (only update if task completed)
-	if outcome.Successful {
+	// Accuracy: 1.0 if verified (or its graded score), 0.0 if not
+	// (only update if task completed or its result was disputed)
+	if outcome.Disputed {
+		rep.Components.Accuracy = ema(rep.Components.Accuracy, 0, α)
+	} else if outcome.Successful {
 		accuracySignal := 0.0
 		if outcome.ResultVerified {
 			accuracySignal = 1.0
+			if outcome.Accuracy > 0 && outcome.Accuracy < 1 {
+				accuracySignal = outcome.Accuracy
+			}
 		}
 		rep.Components.Accuracy = ema(rep.Components.Accuracy, accuracySignal, α)
 	}
@@ -275,16 +314,14 @@ func (t *Tracker) RecordTask(nodeID string, outcome TaskOutcome) error {
 	}
 
 	rep.TaskCount++
-	rep.LastUpdate = t.now()
+	rep.LastUpdate = now
 
 	// Update longevity based on days since join
-	days := int(t.now().Sub(rep.JoinedAt).Hours() / 24)
+	days := int(now.Sub(rep.JoinedAt).Hours() / 24)
 	if days > rep.DaysActive {
 		rep.DaysActive = days
 	}
 	rep.Components.Longevity = math.Min(1.0, float64(rep.DaysActive)/float64(LongevityFullDays))
-
-	return nil
 }
 
 // RecordAvailability updates the availability component.
@@ -294,16 +331,25 @@ func (t *Tracker) RecordAvailability(nodeID string, check AvailabilityCheck) err
 
 	rep, ok := t.nodes[nodeID]
 	if !ok {
-		return fmt.Errorf("node %s not registered", nodeID)
+		return fmt.Errorf("%w: %s", domain.ErrNodeNotRegistered, nodeID)
 	}
 
+	now := t.now()
+	rep.applyAvailability(check, now)
+	if check.FederationID != "" {
+		t.scopedLocked(check.FederationID, nodeID, now).applyAvailability(check, now)
+	}
+	return nil
+}
+
+// applyAvailability folds an availability check into the reputation.
+func (rep *NodeReputation) applyAvailability(check AvailabilityCheck, now time.Time) {
 	signal := 0.0
 	if check.WasOnline {
 		signal = 1.0
 	}
 	rep.Components.Availability = ema(rep.Components.Availability, signal, rep.alpha())
-	rep.LastUpdate = t.now()
-	return nil
+	rep.LastUpdate = now
 }
 
 // RecordPenalty adds a penalty to a node's reputation.
@@ -313,11 +359,17 @@ func (t *Tracker) RecordPenalty(nodeID string, penalty PenaltyEvent) error {
 
 	rep, ok := t.nodes[nodeID]
 	if !ok {
-		return fmt.Errorf("node %s not registered", nodeID)
+		return fmt.Errorf("%w: %s", domain.ErrNodeNotRegistered, nodeID)
 	}
 
+	now := t.now()
 	rep.Penalties += penalty.Severity
-	rep.LastUpdate = t.now()
+	rep.LastUpdate = now
+	if penalty.FederationID != "" {
+		scoped := t.scopedLocked(penalty.FederationID, nodeID, now)
+		scoped.Penalties += penalty.Severity
+		scoped.LastUpdate = now
+	}
 	return nil
 }
 
@@ -334,39 +386,52 @@ func (t *Tracker) ApplyDecay() int {
 	decayed := 0
 
 	for _, rep := range t.nodes {
-		weeksSinceUpdate := now.Sub(rep.LastUpdate).Hours() / (24 * 7)
-		if weeksSinceUpdate < 1 {
-			continue // Active within the last week
+		if rep.decay(now, t.config.DecayRate) {
+			decayed++
 		}
-
-		// Only decay if enough time has passed since last decay
-		weeksSinceDecay := now.Sub(rep.LastDecay).Hours() / (24 * 7)
-		if weeksSinceDecay < 1 {
-			continue
+	}
+	// Federation-scoped scores decay alongside, uncounted
+	for _, nodes := range t.scoped {
+		for _, rep := range nodes {
+			rep.decay(now, t.config.DecayRate)
 		}
+	}
 
-		// Apply decay: reduce each component by rate * weeks
-		decayFactor := 1.0 - t.config.DecayRate*math.Floor(weeksSinceDecay)
-		if decayFactor < 0 {
-			decayFactor = 0
-		}
+	return decayed
+}
 
-		rep.Components.Reliability *= decayFactor
-		rep.Components.Accuracy *= decayFactor
-		rep.Components.Availability *= decayFactor
-		rep.Components.Speed *= decayFactor
+// decay applies weekly inactivity decay and reports whether it did.
+func (rep *NodeReputation) decay(now time.Time, rate float64) bool {
+	weeksSinceUpdate := now.Sub(rep.LastUpdate).Hours() / (24 * 7)
+	if weeksSinceUpdate < 1 {
+		return false // Active within the last week
+	}
 
-		// Enforce floor
-		rep.Components.Reliability = math.Max(rep.Components.Reliability, FloorReputation)
-		rep.Components.Accuracy = math.Max(rep.Components.Accuracy, FloorReputation)
-		rep.Components.Availability = math.Max(rep.Components.Availability, FloorReputation)
-		rep.Components.Speed = math.Max(rep.Components.Speed, FloorReputation)
+	// Only decay if enough time has passed since last decay
+	weeksSinceDecay := now.Sub(rep.LastDecay).Hours() / (24 * 7)
+	if weeksSinceDecay < 1 {
+		return false
+	}
 
-		rep.LastDecay = now
-		decayed++
+	// Apply decay: reduce each component by rate * weeks
+	decayFactor := 1.0 - rate*math.Floor(weeksSinceDecay)
+	if decayFactor < 0 {
+		decayFactor = 0
 	}
 
-	return decayed
+	rep.Components.Reliability *= decayFactor
+	rep.Components.Accuracy *= decayFactor
+	rep.Components.Availability *= decayFactor
+	rep.Components.Speed *= decayFactor
+
+	// Enforce floor
+	rep.Components.Reliability = math.Max(rep.Components.Reliability, FloorReputation)
+	rep.Components.Accuracy = math.Max(rep.Components.Accuracy, FloorReputation)
+	rep.Components.Availability = math.Max(rep.Components.Availability, FloorReputation)
+	rep.Components.Speed = math.Max(rep.Components.Speed, FloorReputation)
+
+	rep.LastDecay = now
+	return true
 }
 
 // ─── Queries ────────────────────────────────────────────────────────────────
@@ -422,6 +487,9 @@ func (t *Tracker) Remove(nodeID string) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 	delete(t.nodes, nodeID)
+	for _, nodes := range t.scoped {
+		delete(nodes, nodeID)
+	}
 }
 
 // ─── Pure Helper Functions ──────────────────────────────────────────────────
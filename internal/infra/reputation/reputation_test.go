@@ -186,6 +186,24 @@ func TestRecordTask_SuccessfulFast(t *testing.T) {
 	}
 }
 
+func TestRecordTask_GradedAccuracy(t *testing.T) {
+	tr := newTestTracker(t)
+	full := tr.Register("node-1")
+	graded := tr.Register("node-2")
+
+	_ = tr.RecordTask("node-1", TaskOutcome{Successful: true, ResultVerified: true})
+	_ = tr.RecordTask("node-2", TaskOutcome{Successful: true, ResultVerified: true, Accuracy: 0.6})
+
+	if graded.Components.Accuracy >= full.Components.Accuracy {
+		t.Errorf("graded accuracy %f should trail full accuracy %f",
+			graded.Components.Accuracy, full.Components.Accuracy)
+	}
+	want := ema(DefaultReputation, 0.6, AlphaColdStart)
+	if math.Abs(graded.Components.Accuracy-want) > 1e-9 {
+		t.Errorf("graded accuracy = %f, want %f", graded.Components.Accuracy, want)
+	}
+}
+
 func TestRecordTask_Failed(t *testing.T) {
 	tr := newTestTracker(t)
 	rep := tr.Register("node-1")
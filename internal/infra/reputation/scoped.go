@@ -0,0 +1,103 @@
+package reputation
+
+import (
+	"sort"
+	"time"
+)
+
+// ─── Federation-Scoped Reputation ───────────────────────────────────────────
+// Organizations want to trust nodes on their behavior inside the federation,
+// not on what they did on the public network. Events tagged with a
+// FederationID update two reputations in parallel: the node's public one,
+// which reflects everything, and its reputation within that federation,
+// which starts neutral at the node's first tagged event and sees only
+// tagged events. Scoped scores decay like public ones and are removed with
+// the node.
+
+// scopedLocked returns nodeID's reputation within fedID, creating it at
+// neutral if needed. Must be called with t.mu held.
+func (t *Tracker) scopedLocked(fedID, nodeID string, now time.Time) *NodeReputation {
+	nodes, ok := t.scoped[fedID]
+	if !ok {
+		nodes = make(map[string]*NodeReputation)
+		t.scoped[fedID] = nodes
+	}
+	rep, ok := nodes[nodeID]
+	if !ok {
+		rep = newNodeReputation(nodeID, now)
+		nodes[nodeID] = rep
+	}
+	return rep
+}
+
+// GetWithin returns a copy of nodeID's reputation within fedID, or nil if
+// the node has no tagged events there.
+func (t *Tracker) GetWithin(nodeID, fedID string) *NodeReputation {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	rep, ok := t.scoped[fedID][nodeID]
+	if !ok {
+		return nil
+	}
+	cp := *rep
+	return &cp
+}
+
+// OverallWithin returns nodeID's overall score computed only from events
+// inside fedID. ok is false if the node has no tagged events there.
+func (t *Tracker) OverallWithin(nodeID, fedID string) (score float64, ok bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	rep, ok := t.scoped[fedID][nodeID]
+	if !ok {
+		return 0, false
+	}
+	return rep.Overall(), true
+}
+
+// TopNodesWithin returns copies of the reputations within fedID, sorted by
+// overall score descending (ties by node ID).
+func (t *Tracker) TopNodesWithin(fedID string, limit int) []*NodeReputation {
+	t.mu.RLock()
+	nodes := make([]*NodeReputation, 0, len(t.scoped[fedID]))
+	for _, rep := range t.scoped[fedID] {
+		cp := *rep
+		nodes = append(nodes, &cp)
+	}
+	t.mu.RUnlock()
+
+	sort.Slice(nodes, func(i, j int) bool {
+		oi, oj := nodes[i].Overall(), nodes[j].Overall()
+		if oi != oj {
+			return oi > oj
+		}
+		return nodes[i].NodeID < nodes[j].NodeID
+	})
+	if limit > 0 && limit < len(nodes) {
+		nodes = nodes[:limit]
+	}
+	return nodes
+}
+
+// Federations returns the IDs of federations with scoped reputations,
+// sorted.
+func (t *Tracker) Federations() []string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	out := make([]string, 0, len(t.scoped))
+	for id, nodes := range t.scoped {
+		if len(nodes) > 0 {
+			out = append(out, id)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// ForgetFederation drops every reputation scoped to fedID, e.g. when the
+// federation is dissolved.
+func (t *Tracker) ForgetFederation(fedID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.scoped, fedID)
+}
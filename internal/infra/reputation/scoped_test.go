@@ -0,0 +1,54 @@
+package reputation
+
+import "testing"
+
+func TestRecordTask_FederationScoped(t *testing.T) {
+	tr := newTestTracker(t)
+	tr.Register("node-1")
+	tr.Register("node-2")
+
+	// node-1 fails publicly but behaves inside fed-a
+	for i := 0; i < 5; i++ {
+		_ = tr.RecordTask("node-1", TaskOutcome{Successful: false})
+		_ = tr.RecordTask("node-1", TaskOutcome{Successful: true, ResultVerified: true, FederationID: "fed-a"})
+	}
+	_ = tr.RecordTask("node-2", TaskOutcome{Successful: false, FederationID: "fed-a"})
+	_ = tr.RecordAvailability("node-2", AvailabilityCheck{WasOnline: false, FederationID: "fed-a"})
+	_ = tr.RecordPenalty("node-2", PenaltyEvent{Severity: 1, FederationID: "fed-a"})
+
+	public := tr.Get("node-1")
+	if public.TaskCount != 10 {
+		t.Errorf("public task count = %d, want 10 (tagged events count publicly too)", public.TaskCount)
+	}
+	scoped := tr.GetWithin("node-1", "fed-a")
+	if scoped == nil || scoped.TaskCount != 5 {
+		t.Fatalf("scoped = %+v, want 5 tagged tasks", scoped)
+	}
+	within, ok := tr.OverallWithin("node-1", "fed-a")
+	if !ok || within <= public.Overall() {
+		t.Errorf("OverallWithin = %v, %v; want above public %v", within, ok, public.Overall())
+	}
+	if _, ok := tr.OverallWithin("node-1", "fed-b"); ok {
+		t.Error("OverallWithin reported a score for a federation with no events")
+	}
+
+	if p := tr.GetWithin("node-2", "fed-a"); p.Penalties != 1 || p.Components.Availability >= DefaultReputation {
+		t.Errorf("node-2 within fed-a = %+v", p)
+	}
+	top := tr.TopNodesWithin("fed-a", 0)
+	if len(top) != 2 || top[0].NodeID != "node-1" {
+		t.Errorf("TopNodesWithin = %+v", top)
+	}
+	if got := tr.Federations(); len(got) != 1 || got[0] != "fed-a" {
+		t.Errorf("Federations = %v", got)
+	}
+
+	tr.Remove("node-2")
+	if tr.GetWithin("node-2", "fed-a") != nil {
+		t.Error("Remove kept the scoped reputation")
+	}
+	tr.ForgetFederation("fed-a")
+	if len(tr.Federations()) != 0 {
+		t.Error("ForgetFederation kept scoped reputations")
+	}
+}
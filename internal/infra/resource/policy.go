@@ -0,0 +1,341 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
+)
+
+// ─── Idle Compute Policy ────────────────────────────────────────────────────
+// The user decides when their machine may work for the network. An
+// IdlePolicy sets the limits: how busy the CPU and GPU may be with the
+// user's own work, how long they must have been away, which hours of the
+// day are allowed, and battery and temperature floors. The PolicyEngine
+// samples the machine on a timer, admits network tasks only while every
+// limit holds, and asks for running tasks to be drained the moment the
+// user comes back or the hardware runs hot.
+
+// IdlePolicy is the user's compute budget. Zero limits are disabled.
+type IdlePolicy struct {
+	Enabled        bool     `json:"enabled"`
+	MaxCPUPercent  int      `json:"max_cpu_percent"`   // user CPU load above this blocks work
+	MaxGPUPercent  int      `json:"max_gpu_percent"`   // GPU load above this blocks work
+	MinIdle        string   `json:"min_idle"`          // active, light, deep or locked
+	Windows        []string `json:"windows,omitempty"` // local "22:00-07:00" spans; empty = any time
+	MinBatteryPct  int      `json:"min_battery_pct"`   // on battery, below this blocks work
+	RequireACPower bool     `json:"require_ac_power"`  // never work on battery
+	MaxTempC       int      `json:"max_temp_c"`        // CPU or GPU hotter than this drains work
+}
+
+// DefaultIdlePolicy returns a policy that, once enabled, works only while
+// the user has stepped away and the machine is cool and charged.
+func DefaultIdlePolicy() IdlePolicy {
+	return IdlePolicy{
+		Enabled:       false,
+		MaxCPUPercent: 30,
+		MaxGPUPercent: 30,
+		MinIdle:       domain.IdleLight.String(),
+		MinBatteryPct: 50,
+		MaxTempC:      85,
+	}
+}
+
+// Validate checks the policy. Errors wrap domain.ErrInvalidIdlePolicy.
+func (p IdlePolicy) Validate() error {
+	for name, v := range map[string]int{
+		"max_cpu_percent": p.MaxCPUPercent,
+		"max_gpu_percent": p.MaxGPUPercent,
+		"min_battery_pct": p.MinBatteryPct,
+	} {
+		if v < 0 || v > 100 {
+			return fmt.Errorf("%w: %s must be between 0 and 100, got %d", domain.ErrInvalidIdlePolicy, name, v)
+		}
+	}
+	if p.MaxTempC < 0 {
+		return fmt.Errorf("%w: max_temp_c must not be negative, got %d", domain.ErrInvalidIdlePolicy, p.MaxTempC)
+	}
+	if _, err := parseIdleLevel(p.MinIdle); err != nil {
+		return err
+	}
+	for _, w := range p.Windows {
+		if _, _, err := parseWindow(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseIdleLevel maps a MinIdle name to its level ("" = active).
+func parseIdleLevel(s string) (domain.IdleLevel, error) {
+	for _, l := range []domain.IdleLevel{domain.IdleActive, domain.IdleLight, domain.IdleDeep, domain.IdleLocked} {
+		if s == l.String() {
+			return l, nil
+		}
+	}
+	if s == "" {
+		return domain.IdleActive, nil
+	}
+	return 0, fmt.Errorf("%w: min_idle must be active, light, deep or locked, got %q", domain.ErrInvalidIdlePolicy, s)
+}
+
+// parseWindow parses "HH:MM-HH:MM" into minutes after midnight. A window
+// whose end is before its start spans midnight.
+func parseWindow(s string) (start, end int, err error) {
+	from, to, ok := strings.Cut(s, "-")
+	if ok {
+		start, err = parseClock(from)
+		if err == nil {
+			end, err = parseClock(to)
+		}
+	}
+	if !ok || err != nil || start == end {
+		return 0, 0, fmt.Errorf("%w: window must look like 22:00-07:00, got %q", domain.ErrInvalidIdlePolicy, s)
+	}
+	return start, end, nil
+}
+
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// inWindows reports whether now falls inside any window (true for none).
+func inWindows(windows []string, now time.Time) bool {
+	if len(windows) == 0 {
+		return true
+	}
+	m := now.Hour()*60 + now.Minute()
+	for _, w := range windows {
+		start, end, err := parseWindow(w)
+		if err != nil {
+			continue
+		}
+		if start < end && m >= start && m < end {
+			return true
+		}
+		if start > end && (m >= start || m < end) {
+			return true
+		}
+	}
+	return false
+}
+
+// ─── Evaluation ─────────────────────────────────────────────────────────────
+
+// Sample is one reading of the machine's state.
+type Sample struct {
+	CPUPercent float64          `json:"cpu_percent"` // user load, -1 = unknown
+	GPUPercent float64          `json:"gpu_percent"` // -1 = unknown
+	Idle       domain.IdleLevel `json:"-"`
+	IdleName   string           `json:"idle"`
+	OnBattery  bool             `json:"on_battery"`
+	BatteryPct int              `json:"battery_pct"`
+	CPUTempC   int              `json:"cpu_temp_c"` // 0 = unknown
+	GPUTempC   int              `json:"gpu_temp_c"`
+}
+
+// Decision is the policy's verdict on a sample.
+type Decision struct {
+	Accept  bool      `json:"accept"`
+	Drain   bool      `json:"drain"`             // running tasks must stop
+	Reasons []string  `json:"reasons,omitempty"` // why work is not accepted
+	At      time.Time `json:"at"`
+}
+
+// Evaluate applies the policy to a sample taken at now. A disabled policy
+// accepts everything.
+func (p IdlePolicy) Evaluate(s Sample, now time.Time) Decision {
+	d := Decision{Accept: true, At: now}
+	if !p.Enabled {
+		return d
+	}
+	block := func(drain bool, format string, args ...interface{}) {
+		d.Accept = false
+		d.Drain = d.Drain || drain
+		d.Reasons = append(d.Reasons, fmt.Sprintf(format, args...))
+	}
+
+	// The user being back at the machine is the one signal that must stop
+	// running work, not just refuse new work
+	if need, _ := parseIdleLevel(p.MinIdle); s.Idle < need {
+		block(true, "user is %s (policy needs %s)", s.Idle, need)
+	}
+	hottest := max(s.CPUTempC, s.GPUTempC)
+	if p.MaxTempC > 0 && hottest > p.MaxTempC {
+		block(true, "hardware at %d°C (limit %d°C)", hottest, p.MaxTempC)
+	}
+	if p.MaxCPUPercent > 0 && s.CPUPercent > float64(p.MaxCPUPercent) {
+		block(false, "CPU %.0f%% busy (limit %d%%)", s.CPUPercent, p.MaxCPUPercent)
+	}
+	if p.MaxGPUPercent > 0 && s.GPUPercent > float64(p.MaxGPUPercent) {
+		block(false, "GPU %.0f%% busy (limit %d%%)", s.GPUPercent, p.MaxGPUPercent)
+	}
+	if !inWindows(p.Windows, now) {
+		block(false, "outside allowed hours %s", strings.Join(p.Windows, ", "))
+	}
+	if s.OnBattery {
+		if p.RequireACPower {
+			block(false, "on battery power")
+		} else if s.BatteryPct < p.MinBatteryPct {
+			block(false, "battery at %d%% (minimum %d%%)", s.BatteryPct, p.MinBatteryPct)
+		}
+	}
+	return d
+}
+
+// ─── Engine ─────────────────────────────────────────────────────────────────
+
+// PolicyConfig tunes the policy engine.
+type PolicyConfig struct {
+	Interval time.Duration    // re-evaluation period (default: 5s)
+	Now      func() time.Time // injectable clock for testing
+}
+
+// DefaultPolicyConfig returns production defaults.
+func DefaultPolicyConfig() PolicyConfig {
+	return PolicyConfig{
+		Interval: 5 * time.Second,
+		Now:      time.Now,
+	}
+}
+
+// PolicyStatus is the engine's current state.
+type PolicyStatus struct {
+	Policy   IdlePolicy `json:"policy"`
+	Decision Decision   `json:"decision"`
+	Sample   Sample     `json:"sample"`
+	Rejected int64      `json:"rejected"` // tasks refused by the policy
+	Drains   int64      `json:"drains"`   // times running work was drained
+}
+
+// PolicyEngine enforces an IdlePolicy against periodic samples.
+type PolicyEngine struct {
+	mu       sync.Mutex
+	cfg      PolicyConfig
+	policy   IdlePolicy
+	sample   func() Sample
+	last     Sample
+	decision Decision
+	onDrain  func(reason string)
+	rejected int64
+	drains   int64
+}
+
+// NewPolicyEngine creates an engine for policy, reading the machine with
+// sample.
+func NewPolicyEngine(cfg PolicyConfig, policy IdlePolicy, sample func() Sample) (*PolicyEngine, error) {
+	def := DefaultPolicyConfig()
+	if cfg.Interval <= 0 {
+		cfg.Interval = def.Interval
+	}
+	if cfg.Now == nil {
+		cfg.Now = def.Now
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	return &PolicyEngine{cfg: cfg, policy: policy, sample: sample}, nil
+}
+
+// OnDrain installs the callback run when work must stop. It fires once per
+// transition into the draining state, with the reasons joined.
+func (e *PolicyEngine) OnDrain(fn func(reason string)) {
+	e.mu.Lock()
+	e.onDrain = fn
+	e.mu.Unlock()
+}
+
+// Policy returns the policy in force.
+func (e *PolicyEngine) Policy() IdlePolicy {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.policy
+}
+
+// SetPolicy replaces the policy and re-evaluates immediately.
+func (e *PolicyEngine) SetPolicy(p IdlePolicy) (Decision, error) {
+	if err := p.Validate(); err != nil {
+		return Decision{}, err
+	}
+	e.mu.Lock()
+	e.policy = p
+	e.mu.Unlock()
+	return e.Evaluate(), nil
+}
+
+// Evaluate samples the machine and applies the policy.
+func (e *PolicyEngine) Evaluate() Decision {
+	s := e.sample()
+	s.IdleName = s.Idle.String()
+
+	e.mu.Lock()
+	wasDraining := e.decision.Drain
+	d := e.policy.Evaluate(s, e.cfg.Now())
+	e.last, e.decision = s, d
+	onDrain := e.onDrain
+	drain := d.Drain && !wasDraining
+	if drain {
+		e.drains++
+	}
+	e.mu.Unlock()
+
+	if drain && onDrain != nil {
+		onDrain(strings.Join(d.Reasons, "; "))
+	}
+	return d
+}
+
+// Admit returns nil if the latest decision accepts work, or an error
+// wrapping domain.ErrIdlePolicyBlocked with the reasons.
+func (e *PolicyEngine) Admit(task domain.Task) error {
+	e.mu.Lock()
+	evaluated := !e.decision.At.IsZero()
+	e.mu.Unlock()
+	if !evaluated {
+		e.Evaluate()
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.decision.Accept {
+		return nil
+	}
+	e.rejected++
+	return fmt.Errorf("%w: %s", domain.ErrIdlePolicyBlocked, strings.Join(e.decision.Reasons, "; "))
+}
+
+// Status returns the policy, latest sample and decision.
+func (e *PolicyEngine) Status() PolicyStatus {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return PolicyStatus{
+		Policy:   e.policy,
+		Decision: e.decision,
+		Sample:   e.last,
+		Rejected: e.rejected,
+		Drains:   e.drains,
+	}
+}
+
+// Run re-evaluates the policy every interval until ctx is done.
+func (e *PolicyEngine) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.cfg.Interval)
+	defer ticker.Stop()
+
+	e.Evaluate()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			e.Evaluate()
+		}
+	}
+}
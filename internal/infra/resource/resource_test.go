@@ -1,7 +1,9 @@
 package resource
 
 import (
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/tutu-network/tutu/internal/domain"
 )
@@ -130,3 +132,98 @@ func TestIdleDetector_Update(t *testing.T) {
 	// Just verify Update doesn't panic
 	d.Update()
 }
+
+// ─── Idle Compute Policy Tests ──────────────────────────────────────────────
+
+func TestIdlePolicy_Evaluate(t *testing.T) {
+	policy := DefaultIdlePolicy()
+	policy.Enabled = true
+	policy.Windows = []string{"22:00-07:00"}
+	night := time.Date(2026, 3, 1, 23, 30, 0, 0, time.UTC)
+	away := Sample{CPUPercent: 5, GPUPercent: -1, Idle: domain.IdleDeep, BatteryPct: 100}
+
+	tests := []struct {
+		name   string
+		mutate func(*Sample)
+		at     time.Time
+		accept bool
+		drain  bool
+	}{
+		{"away at night", func(*Sample) {}, night, true, false},
+		{"after midnight", func(*Sample) {}, night.Add(2 * time.Hour), true, false},
+		{"daytime", func(*Sample) {}, night.Add(-12 * time.Hour), false, false},
+		{"user busy", func(s *Sample) { s.CPUPercent = 60 }, night, false, false},
+		{"user back", func(s *Sample) { s.Idle = domain.IdleActive }, night, false, true},
+		{"hot", func(s *Sample) { s.GPUTempC = 90 }, night, false, true},
+		{"low battery", func(s *Sample) { s.OnBattery, s.BatteryPct = true, 30 }, night, false, false},
+		{"unknown usage", func(s *Sample) { s.CPUPercent = -1 }, night, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := away
+			tt.mutate(&s)
+			d := policy.Evaluate(s, tt.at)
+			if d.Accept != tt.accept || d.Drain != tt.drain {
+				t.Errorf("Evaluate() = %+v, want accept=%v drain=%v", d, tt.accept, tt.drain)
+			}
+		})
+	}
+
+	policy.Enabled = false
+	if d := policy.Evaluate(Sample{Idle: domain.IdleActive, CPUPercent: 100}, night); !d.Accept {
+		t.Errorf("disabled policy rejected work: %+v", d)
+	}
+}
+
+func TestIdlePolicy_Validate(t *testing.T) {
+	bad := []IdlePolicy{
+		{MaxCPUPercent: 101},
+		{MinIdle: "asleep"},
+		{Windows: []string{"22:00"}},
+		{Windows: []string{"08:00-08:00"}},
+		{MaxTempC: -1},
+	}
+	for _, p := range bad {
+		if err := p.Validate(); !errors.Is(err, domain.ErrInvalidIdlePolicy) {
+			t.Errorf("Validate(%+v) = %v, want ErrInvalidIdlePolicy", p, err)
+		}
+	}
+	if err := DefaultIdlePolicy().Validate(); err != nil {
+		t.Errorf("DefaultIdlePolicy().Validate() = %v", err)
+	}
+}
+
+func TestPolicyEngine_DrainOnce(t *testing.T) {
+	sample := Sample{CPUPercent: 5, Idle: domain.IdleDeep, BatteryPct: 100}
+	policy := DefaultIdlePolicy()
+	policy.Enabled = true
+	e, err := NewPolicyEngine(DefaultPolicyConfig(), policy, func() Sample { return sample })
+	if err != nil {
+		t.Fatalf("NewPolicyEngine() error: %v", err)
+	}
+	var drains []string
+	e.OnDrain(func(reason string) { drains = append(drains, reason) })
+
+	if err := e.Admit(domain.Task{ID: "t1"}); err != nil {
+		t.Fatalf("Admit() while away = %v", err)
+	}
+
+	sample.Idle = domain.IdleActive
+	e.Evaluate()
+	e.Evaluate()
+	if len(drains) != 1 {
+		t.Fatalf("drained %d times, want once per return: %v", len(drains), drains)
+	}
+	if err := e.Admit(domain.Task{ID: "t2"}); !errors.Is(err, domain.ErrIdlePolicyBlocked) {
+		t.Errorf("Admit() while active = %v, want ErrIdlePolicyBlocked", err)
+	}
+
+	// Loosening the policy takes effect immediately
+	policy.MinIdle = "active"
+	if d, err := e.SetPolicy(policy); err != nil || !d.Accept {
+		t.Errorf("SetPolicy() = %+v, %v; want accept", d, err)
+	}
+	if st := e.Status(); st.Rejected != 1 || st.Drains != 1 || st.Sample.IdleName != "active" {
+		t.Errorf("Status() = %+v", st)
+	}
+}
@@ -1,5 +1,12 @@
 package resource
 
+import (
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+)
+
 // ThermalMonitor reads CPU and GPU temperatures.
 // Phase 1 foundation uses a stub implementation. Full platform-specific
 // implementations (WMI on Windows, CoreFoundation on macOS, sysfs on Linux)
@@ -22,6 +29,30 @@ func (t *ThermalMonitor) GPUTemp() int {
 	return readGPUTemp()
 }
 
+// PowerMonitor reads the machine's power draw.
+type PowerMonitor struct{}
+
+// NewPowerMonitor creates a power monitor.
+func NewPowerMonitor() *PowerMonitor {
+	return &PowerMonitor{}
+}
+
+// Watts returns the current power draw in watts: the battery discharge rate
+// where the platform reports it plus the GPU board power. Returns 0 when
+// neither is available.
+func (p *PowerMonitor) Watts() float64 {
+	w := readPowerDraw()
+	for _, gpu := range nvidiaSMI("power.draw") {
+		w += gpu
+	}
+	return w
+}
+
+// TotalMemory returns the installed RAM in bytes, or 0 when unknown.
+func TotalMemory() uint64 {
+	return readTotalMemory()
+}
+
 // BatteryMonitor reads battery state.
 type BatteryMonitor struct{}
 
@@ -44,3 +75,81 @@ func (b *BatteryMonitor) Percentage() int {
 func (b *BatteryMonitor) IsCharging() bool {
 	return isBatteryCharging()
 }
+
+// UsageMonitor reports how busy the machine is with work other than
+// TuTu's own. CPU usage is measured between successive calls, so the first
+// call reports -1 (unknown), as does a platform without a reader.
+type UsageMonitor struct {
+	mu                        sync.Mutex
+	lastIdle, lastTotal, last uint64
+	primed                    bool
+}
+
+// NewUsageMonitor creates a usage monitor.
+func NewUsageMonitor() *UsageMonitor {
+	return &UsageMonitor{}
+}
+
+// CPUPercent returns the CPU utilisation since the previous call, excluding
+// this process, or -1 when unknown.
+func (u *UsageMonitor) CPUPercent() float64 {
+	idle, total, self, ok := readCPUTimes()
+	if !ok {
+		return -1
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	primed := u.primed && total > u.lastTotal && idle >= u.lastIdle && self >= u.last
+	dIdle, dTotal, dSelf := idle-u.lastIdle, total-u.lastTotal, self-u.last
+	u.lastIdle, u.lastTotal, u.last, u.primed = idle, total, self, true
+	if !primed {
+		return -1
+	}
+	busy := float64(dTotal) - float64(dIdle) - float64(dSelf)
+	if busy < 0 {
+		busy = 0
+	}
+	return 100 * busy / float64(dTotal)
+}
+
+// GPUPercent returns the GPU utilisation, or -1 when unknown. It includes
+// TuTu's own GPU work.
+func (u *UsageMonitor) GPUPercent() float64 {
+	return readGPUUsage()
+}
+
+// nvidiaSMIUsage returns the busiest NVIDIA GPU's utilisation, or -1 when
+// nvidia-smi is not installed.
+func nvidiaSMIUsage() float64 {
+	usage := -1.0
+	for _, v := range nvidiaSMI("utilization.gpu") {
+		usage = max(usage, v)
+	}
+	return usage
+}
+
+// nvidiaSMITemp returns the hottest NVIDIA GPU's temperature, or 0 when
+// nvidia-smi is not installed.
+func nvidiaSMITemp() int {
+	temp := 0.0
+	for _, v := range nvidiaSMI("temperature.gpu") {
+		temp = max(temp, v)
+	}
+	return int(temp)
+}
+
+// nvidiaSMI queries one numeric field for every NVIDIA GPU. It returns nil
+// when nvidia-smi is not installed; GPUs that report "[N/A]" are skipped.
+func nvidiaSMI(field string) []float64 {
+	out, err := exec.Command("nvidia-smi", "--query-gpu="+field, "--format=csv,noheader,nounits").Output()
+	if err != nil {
+		return nil
+	}
+	var values []float64
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if v, err := strconv.ParseFloat(strings.TrimSpace(line), 64); err == nil {
+			values = append(values, v)
+		}
+	}
+	return values
+}
@@ -69,3 +69,28 @@ func isBatteryCharging() bool {
 	}
 	return strings.Contains(string(out), "AC Power") || strings.Contains(string(out), "charging")
 }
+
+// readCPUTimes is not implemented on macOS; usage is reported as unknown.
+func readCPUTimes() (idle, total, self uint64, ok bool) {
+	return 0, 0, 0, false
+}
+
+// readGPUUsage is not implemented on macOS.
+func readGPUUsage() float64 {
+	return -1
+}
+
+// readPowerDraw is not implemented on macOS.
+func readPowerDraw() float64 {
+	return 0
+}
+
+// readTotalMemory reads hw.memsize on macOS.
+func readTotalMemory() uint64 {
+	out, err := exec.Command("sysctl", "-n", "hw.memsize").Output()
+	if err != nil {
+		return 0
+	}
+	n, _ := strconv.ParseUint(strings.TrimSpace(string(out)), 10, 64)
+	return n
+}
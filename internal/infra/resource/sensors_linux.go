@@ -21,9 +21,9 @@ func readCPUTemp() int {
 	return milliC / 1000
 }
 
-// readGPUTemp reads GPU temperature. Stub for Phase 1.
+// readGPUTemp reads GPU temperature from nvidia-smi, or 0 without it.
 func readGPUTemp() int {
-	return 0
+	return nvidiaSMITemp()
 }
 
 // hasBattery checks for battery on Linux via sysfs.
@@ -53,3 +53,84 @@ func isBatteryCharging() bool {
 	}
 	return strings.TrimSpace(string(data)) == "Charging"
 }
+
+// readCPUTimes reads cumulative CPU time on Linux from /proc/stat, in clock
+// ticks: idle time, total time and the time spent by this process.
+func readCPUTimes() (idle, total, self uint64, ok bool) {
+	data, err := os.ReadFile("/proc/stat")
+	if err != nil {
+		return 0, 0, 0, false
+	}
+	line, _, _ := strings.Cut(string(data), "\n")
+	fields := strings.Fields(line)
+	if len(fields) < 5 || fields[0] != "cpu" {
+		return 0, 0, 0, false
+	}
+	for i, f := range fields[1:] {
+		n, err := strconv.ParseUint(f, 10, 64)
+		if err != nil {
+			return 0, 0, 0, false
+		}
+		total += n
+		if i == 3 || i == 4 { // idle, iowait
+			idle += n
+		}
+	}
+
+	// utime and stime are fields 14 and 15, counted after the
+	// parenthesised command name, which may contain spaces
+	stat, err := os.ReadFile("/proc/self/stat")
+	if err != nil {
+		return idle, total, 0, true
+	}
+	if i := strings.LastIndexByte(string(stat), ')'); i >= 0 {
+		rest := strings.Fields(string(stat[i+1:]))
+		if len(rest) > 12 {
+			utime, _ := strconv.ParseUint(rest[11], 10, 64)
+			stime, _ := strconv.ParseUint(rest[12], 10, 64)
+			self = utime + stime
+		}
+	}
+	return idle, total, self, true
+}
+
+// readGPUUsage reads GPU utilisation from nvidia-smi, or -1 without it.
+func readGPUUsage() float64 {
+	return nvidiaSMIUsage()
+}
+
+// readPowerDraw reads the battery discharge rate on Linux, in watts, from
+// power_now or, on batteries that only report it, current_now × voltage_now.
+// Returns 0 on AC power or without a battery.
+func readPowerDraw() float64 {
+	read := func(name string) float64 {
+		data, err := os.ReadFile("/sys/class/power_supply/BAT0/" + name)
+		if err != nil {
+			return 0
+		}
+		v, _ := strconv.ParseFloat(strings.TrimSpace(string(data)), 64)
+		return v
+	}
+	if isBatteryCharging() {
+		return 0
+	}
+	if uw := read("power_now"); uw > 0 {
+		return uw / 1e6
+	}
+	return read("current_now") * read("voltage_now") / 1e12
+}
+
+// readTotalMemory reads MemTotal from /proc/meminfo on Linux.
+func readTotalMemory() uint64 {
+	data, err := os.ReadFile("/proc/meminfo")
+	if err != nil {
+		return 0
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if rest, ok := strings.CutPrefix(line, "MemTotal:"); ok {
+			kb, _ := strconv.ParseUint(strings.TrimSuffix(strings.TrimSpace(rest), " kB"), 10, 64)
+			return kb << 10
+		}
+	}
+	return 0
+}
@@ -28,9 +28,9 @@ func readCPUTemp() int {
 	return celsius
 }
 
-// readGPUTemp reads GPU temperature. Stub for Phase 1.
+// readGPUTemp reads GPU temperature from nvidia-smi, or 0 without it.
 func readGPUTemp() int {
-	return 0
+	return nvidiaSMITemp()
 }
 
 // hasBattery checks for battery presence on Windows.
@@ -68,3 +68,29 @@ func isBatteryCharging() bool {
 	status, _ := strconv.Atoi(strings.TrimSpace(string(out)))
 	return status == 2 // 2 = AC connected / charging
 }
+
+// readCPUTimes is not implemented on Windows; usage is reported as unknown.
+func readCPUTimes() (idle, total, self uint64, ok bool) {
+	return 0, 0, 0, false
+}
+
+// readGPUUsage reads GPU utilisation from nvidia-smi, or -1 without it.
+func readGPUUsage() float64 {
+	return nvidiaSMIUsage()
+}
+
+// readPowerDraw is not implemented on Windows; only GPU power is reported.
+func readPowerDraw() float64 {
+	return 0
+}
+
+// readTotalMemory reads installed RAM on Windows via WMI.
+func readTotalMemory() uint64 {
+	out, err := exec.Command("powershell", "-NoProfile", "-Command",
+		`(Get-CimInstance Win32_ComputerSystem -ErrorAction SilentlyContinue).TotalPhysicalMemory`).Output()
+	if err != nil {
+		return 0
+	}
+	n, _ := strconv.ParseUint(strings.TrimSpace(string(out)), 10, 64)
+	return n
+}
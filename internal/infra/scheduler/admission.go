@@ -0,0 +1,359 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
+	"github.com/tutu-network/tutu/internal/infra/observability"
+)
+
+// ─── Admission Control ──────────────────────────────────────────────────────
+// The admission controller turns node load into a back-pressure level and
+// decides, per priority class, whether new work is admitted, deferred (the
+// caller retries later) or shed:
+//
+//  1. Queue depth, memory use and smoothed engine latency are sampled every
+//     Interval; each maps to a level through its own thresholds. Latency is
+//     the engine's time to first token, and the estimate halves every
+//     LatencyHalfLife without a new sample — samples only come from
+//     admitted work, so a node shedding on latency would otherwise never
+//     see it fall
+//  2. The worst signal wins — escalation takes effect immediately
+//  3. De-escalation waits until the current level has been held for
+//     CoolDown and every signal is below its threshold by Hysteresis, so
+//     the level does not flap around a boundary
+//  4. Every transition is recorded with the signal that caused it, set on
+//     the back_pressure_level gauge and counted by source/target level
+//
+//	level    P0      P1      P2      P3     P4
+//	NONE     admit   admit   admit   admit  admit
+//	SOFT     admit   admit   admit   defer  shed
+//	MEDIUM   admit   defer   defer   shed   shed
+//	HARD     defer   shed    shed    shed   shed
+
+// AdmissionAction is what happens to new work at the current level.
+type AdmissionAction string
+
+const (
+	ActionAdmit AdmissionAction = "ADMIT"
+	ActionDefer AdmissionAction = "DEFER"
+	ActionShed  AdmissionAction = "SHED"
+)
+
+// admissionPolicy is indexed by [level][priority class].
+var admissionPolicy = [4][5]AdmissionAction{
+	BPNone:   {ActionAdmit, ActionAdmit, ActionAdmit, ActionAdmit, ActionAdmit},
+	BPSoft:   {ActionAdmit, ActionAdmit, ActionAdmit, ActionDefer, ActionShed},
+	BPMedium: {ActionAdmit, ActionDefer, ActionDefer, ActionShed, ActionShed},
+	BPHard:   {ActionDefer, ActionShed, ActionShed, ActionShed, ActionShed},
+}
+
+// AdmissionConfig configures the admission controller. Thresholds are
+// ordered SOFT, MEDIUM, HARD; a zero threshold never triggers.
+type AdmissionConfig struct {
+	Interval        time.Duration    // how often signals are sampled (default 1s)
+	QueueDepth      [3]int           // queued tasks (default 1000/5000/10000)
+	MemoryUsed      [3]float64       // fraction of the memory budget in use (default 0.85/0.95/0.99)
+	Latency         [3]time.Duration // smoothed engine time to first token (default 10s/30s/60s)
+	LatencyHalfLife time.Duration    // decay of the latency estimate without samples (default 30s)
+	Hysteresis      float64          // relative margin below a threshold to step down (default 0.1)
+	CoolDown        time.Duration    // minimum time at a level before stepping down (default 10s)
+	DeferAfter      time.Duration    // retry hint per level for deferred work (default 2s × level)
+	History         int              // transitions kept (default 100)
+	Now             func() time.Time
+}
+
+// DefaultAdmissionConfig returns admission defaults. Queue thresholds match
+// the scheduler's own back-pressure limits.
+func DefaultAdmissionConfig() AdmissionConfig {
+	sc := DefaultConfig()
+	return AdmissionConfig{
+		Interval:        time.Second,
+		QueueDepth:      [3]int{sc.BackPressureSoft, sc.BackPressureMedium, sc.BackPressureHard},
+		MemoryUsed:      [3]float64{0.85, 0.95, 0.99},
+		Latency:         [3]time.Duration{10 * time.Second, 30 * time.Second, 60 * time.Second},
+		LatencyHalfLife: 30 * time.Second,
+		Hysteresis:      0.1,
+		CoolDown:        10 * time.Second,
+		DeferAfter:      2 * time.Second,
+		History:         100,
+		Now:             time.Now,
+	}
+}
+
+// Signals are the load inputs sampled by the controller. Latency is filled
+// in from ObserveLatency and need not be set by the source.
+type Signals struct {
+	QueueDepth int           `json:"queue_depth"`
+	MemoryUsed float64       `json:"memory_used"` // 0..1
+	Latency    time.Duration `json:"latency"`
+}
+
+// Transition is a back-pressure level change.
+type Transition struct {
+	From    BackPressureLevel `json:"from"`
+	To      BackPressureLevel `json:"to"`
+	Cause   string            `json:"cause"` // queue_depth, memory, latency or recovered
+	Signals Signals           `json:"signals"`
+	At      time.Time         `json:"at"`
+}
+
+// AdmissionDecision is the verdict for one piece of work.
+type AdmissionDecision struct {
+	Action     AdmissionAction   `json:"action"`
+	Level      BackPressureLevel `json:"level"`
+	RetryAfter time.Duration     `json:"retry_after,omitempty"` // set for DEFER
+}
+
+// AdmissionStats summarises the controller.
+type AdmissionStats struct {
+	Level       BackPressureLevel `json:"level"`
+	LevelName   string            `json:"level_name"`
+	Since       time.Time         `json:"since"`
+	Signals     Signals           `json:"signals"`
+	Admitted    int64             `json:"admitted"`
+	Deferred    int64             `json:"deferred"`
+	Shed        int64             `json:"shed"`
+	Transitions int64             `json:"transitions"`
+}
+
+// latencyAlpha is the EWMA weight of a new latency observation.
+const latencyAlpha = 0.2
+
+// Admission is the back-pressure admission controller.
+type Admission struct {
+	mu        sync.Mutex
+	cfg       AdmissionConfig
+	source    func() Signals
+	notify    func(Transition)
+	level     BackPressureLevel
+	since     time.Time
+	signals   Signals
+	latency   float64   // EWMA, nanoseconds
+	latencyAt time.Time // when latency was last updated
+	history   []Transition
+	stats     AdmissionStats
+}
+
+// NewAdmission creates a controller sampling source. A nil source reports
+// no load apart from observed latency.
+func NewAdmission(cfg AdmissionConfig, source func() Signals) *Admission {
+	def := DefaultAdmissionConfig()
+	if cfg.Interval <= 0 {
+		cfg.Interval = def.Interval
+	}
+	if cfg.Hysteresis < 0 || cfg.Hysteresis >= 1 {
+		cfg.Hysteresis = def.Hysteresis
+	}
+	if cfg.CoolDown < 0 {
+		cfg.CoolDown = def.CoolDown
+	}
+	if cfg.DeferAfter <= 0 {
+		cfg.DeferAfter = def.DeferAfter
+	}
+	if cfg.History <= 0 {
+		cfg.History = def.History
+	}
+	if cfg.LatencyHalfLife <= 0 {
+		cfg.LatencyHalfLife = def.LatencyHalfLife
+	}
+	if cfg.Now == nil {
+		cfg.Now = def.Now
+	}
+	if source == nil {
+		source = func() Signals { return Signals{} }
+	}
+	observability.SchedulerBackPressure.Set(float64(BPNone))
+	return &Admission{cfg: cfg, source: source, since: cfg.Now()}
+}
+
+// OnTransition registers a callback invoked (outside the lock) after every
+// level change.
+func (a *Admission) OnTransition(fn func(Transition)) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.notify = fn
+}
+
+// Run re-evaluates the level every Interval until ctx is done.
+func (a *Admission) Run(ctx context.Context) {
+	ticker := time.NewTicker(a.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			a.Evaluate()
+		}
+	}
+}
+
+// ObserveLatency feeds one engine time to first token into the smoothed
+// latency signal.
+func (a *Admission) ObserveLatency(d time.Duration) {
+	now := a.cfg.Now()
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	cur := a.latencyLocked(now)
+	if cur == 0 {
+		a.latency = float64(d)
+	} else {
+		a.latency = latencyAlpha*float64(d) + (1-latencyAlpha)*cur
+	}
+	a.latencyAt = now
+}
+
+// latencyLocked returns the latency estimate decayed to now. Must be called
+// with a.mu held.
+func (a *Admission) latencyLocked(now time.Time) float64 {
+	idle := now.Sub(a.latencyAt)
+	if a.latency == 0 || idle <= 0 {
+		return a.latency
+	}
+	return a.latency * math.Exp2(-float64(idle)/float64(a.cfg.LatencyHalfLife))
+}
+
+// Evaluate samples the signals and moves to a new level if warranted.
+// Returns the level in effect afterwards.
+func (a *Admission) Evaluate() BackPressureLevel {
+	sig := a.source() // may take locks elsewhere — outside a.mu
+
+	now := a.cfg.Now()
+	a.mu.Lock()
+	sig.Latency = time.Duration(a.latencyLocked(now))
+	a.signals = sig
+
+	target, cause := a.levelFor(sig, 1)
+	switch {
+	case target > a.level:
+		// escalate immediately
+	case target < a.level && now.Sub(a.since) >= a.cfg.CoolDown:
+		target, _ = a.levelFor(sig, 1-a.cfg.Hysteresis)
+		cause = "recovered"
+	default:
+		target = a.level
+	}
+	if target == a.level {
+		a.mu.Unlock()
+		return target
+	}
+
+	t := Transition{From: a.level, To: target, Cause: cause, Signals: sig, At: now}
+	a.level, a.since = target, now
+	a.history = append(a.history, t)
+	if len(a.history) > a.cfg.History {
+		a.history = a.history[len(a.history)-a.cfg.History:]
+	}
+	a.stats.Transitions++
+	notify := a.notify
+	a.mu.Unlock()
+
+	observability.SchedulerBackPressure.Set(float64(target))
+	observability.SchedulerBackPressureTransitions.WithLabelValues(t.From.String(), t.To.String()).Inc()
+	if notify != nil {
+		notify(t)
+	}
+	return target
+}
+
+// levelFor maps signals to a level with every threshold scaled by scale,
+// returning the worst level and the signal that produced it.
+func (a *Admission) levelFor(sig Signals, scale float64) (BackPressureLevel, string) {
+	level, cause := BPNone, ""
+	check := func(name string, value float64, thresholds [3]float64) {
+		for i := 2; i >= 0; i-- {
+			if thresholds[i] > 0 && value >= thresholds[i]*scale {
+				if l := BackPressureLevel(i + 1); l > level {
+					level, cause = l, name
+				}
+				return
+			}
+		}
+	}
+	q, l := a.cfg.QueueDepth, a.cfg.Latency
+	check("queue_depth", float64(sig.QueueDepth), [3]float64{float64(q[0]), float64(q[1]), float64(q[2])})
+	check("memory", sig.MemoryUsed, a.cfg.MemoryUsed)
+	check("latency", float64(sig.Latency), [3]float64{float64(l[0]), float64(l[1]), float64(l[2])})
+	return level, cause
+}
+
+// Admit decides what happens to new work of the given priority class.
+func (a *Admission) Admit(priority int) AdmissionDecision {
+	p := min(max(priority, P0Realtime), P4Spot)
+
+	a.mu.Lock()
+	d := AdmissionDecision{Action: admissionPolicy[a.level][p], Level: a.level}
+	switch d.Action {
+	case ActionAdmit:
+		a.stats.Admitted++
+	case ActionDefer:
+		a.stats.Deferred++
+		d.RetryAfter = a.cfg.DeferAfter * time.Duration(d.Level)
+	case ActionShed:
+		a.stats.Shed++
+	}
+	a.mu.Unlock()
+
+	switch d.Action {
+	case ActionDefer:
+		observability.SchedulerTasksDeferred.WithLabelValues(d.Level.String()).Inc()
+	case ActionShed:
+		observability.SchedulerTasksRejected.WithLabelValues(d.Level.String()).Inc()
+	}
+	return d
+}
+
+// Check is Admit as an error: nil when admitted, ErrAdmissionDeferred when
+// deferred, and the level's back-pressure error when shed.
+func (a *Admission) Check(priority int) error {
+	d := a.Admit(priority)
+	switch d.Action {
+	case ActionDefer:
+		return fmt.Errorf("%s, retry in %s: %w", d.Level, d.RetryAfter, domain.ErrAdmissionDeferred)
+	case ActionShed:
+		switch d.Level {
+		case BPSoft:
+			return domain.ErrBackPressureSoft
+		case BPMedium:
+			return domain.ErrBackPressureMedium
+		default:
+			return domain.ErrBackPressureHard
+		}
+	}
+	return nil
+}
+
+// Level returns the current back-pressure level.
+func (a *Admission) Level() BackPressureLevel {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.level
+}
+
+// Transitions returns recent level changes, newest first.
+func (a *Admission) Transitions(limit int) []Transition {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if limit <= 0 || limit > len(a.history) {
+		limit = len(a.history)
+	}
+	out := make([]Transition, 0, limit)
+	for i := len(a.history) - 1; i >= 0 && len(out) < limit; i-- {
+		out = append(out, a.history[i])
+	}
+	return out
+}
+
+// Stats returns controller statistics.
+func (a *Admission) Stats() AdmissionStats {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	s := a.stats
+	s.Level, s.LevelName, s.Since, s.Signals = a.level, a.level.String(), a.since, a.signals
+	return s
+}
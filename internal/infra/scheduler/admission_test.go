@@ -0,0 +1,116 @@
+package scheduler
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
+)
+
+func newTestAdmission(sig *Signals, clock *time.Time) *Admission {
+	cfg := DefaultAdmissionConfig()
+	cfg.QueueDepth = [3]int{10, 50, 100}
+	cfg.Now = func() time.Time { return *clock }
+	return NewAdmission(cfg, func() Signals { return *sig })
+}
+
+func TestAdmission_Levels(t *testing.T) {
+	clock := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	sig := Signals{}
+	a := newTestAdmission(&sig, &clock)
+
+	steps := []struct {
+		name    string
+		advance time.Duration
+		sig     Signals
+		want    BackPressureLevel
+	}{
+		{"idle", 0, Signals{QueueDepth: 3}, BPNone},
+		{"queue soft", 0, Signals{QueueDepth: 12}, BPSoft},
+		{"memory jumps to hard", 0, Signals{QueueDepth: 12, MemoryUsed: 0.995}, BPHard},
+		{"cool-down holds the level", 5 * time.Second, Signals{QueueDepth: 12}, BPHard},
+		{"steps down after cool-down", 10 * time.Second, Signals{QueueDepth: 12}, BPSoft},
+		{"within hysteresis stays", 20 * time.Second, Signals{QueueDepth: 9}, BPSoft},
+		{"clearly below recovers", 0, Signals{QueueDepth: 8}, BPNone},
+	}
+	for _, st := range steps {
+		clock = clock.Add(st.advance)
+		sig = st.sig
+		if got := a.Evaluate(); got != st.want {
+			t.Fatalf("%s: level = %s, want %s", st.name, got, st.want)
+		}
+	}
+
+	tr := a.Transitions(0)
+	if len(tr) != 4 {
+		t.Fatalf("transitions = %+v, want 4", tr)
+	}
+	if tr[len(tr)-2].Cause != "memory" || tr[0].Cause != "recovered" || tr[0].To != BPNone {
+		t.Errorf("transitions = %+v", tr)
+	}
+}
+
+func TestAdmission_Policy(t *testing.T) {
+	clock := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	sig := Signals{QueueDepth: 60}
+	a := newTestAdmission(&sig, &clock)
+	a.Evaluate() // MEDIUM
+
+	tests := []struct {
+		priority int
+		want     error
+	}{
+		{P0Realtime, nil},
+		{P1High, domain.ErrAdmissionDeferred},
+		{P2Normal, domain.ErrAdmissionDeferred},
+		{P3Low, domain.ErrBackPressureMedium},
+		{P4Spot, domain.ErrBackPressureMedium},
+	}
+	for _, tt := range tests {
+		t.Run(PriorityLabel(tt.priority), func(t *testing.T) {
+			if err := a.Check(tt.priority); !errors.Is(err, tt.want) {
+				t.Errorf("Check(%d) = %v, want %v", tt.priority, err, tt.want)
+			}
+		})
+	}
+	if d := a.Admit(P1High); d.RetryAfter != 4*time.Second {
+		t.Errorf("RetryAfter = %s, want 4s at MEDIUM", d.RetryAfter)
+	}
+	if st := a.Stats(); st.Admitted != 1 || st.Deferred != 3 || st.Shed != 2 || st.LevelName != "MEDIUM" {
+		t.Errorf("stats = %+v", st)
+	}
+}
+
+func TestAdmission_LatencySignal(t *testing.T) {
+	clock := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	sig := Signals{}
+	a := newTestAdmission(&sig, &clock)
+
+	for i := 0; i < 20; i++ {
+		a.ObserveLatency(45 * time.Second)
+	}
+	if got := a.Evaluate(); got != BPMedium {
+		t.Errorf("level = %s, want MEDIUM from 45s latency", got)
+	}
+	if tr := a.Transitions(1); len(tr) != 1 || tr[0].Cause != "latency" {
+		t.Errorf("transitions = %+v", tr)
+	}
+
+	// Nothing is admitted at MEDIUM, so no samples arrive: the estimate
+	// decays on its own and the level recovers
+	clock = clock.Add(30 * time.Second)
+	if got := a.Evaluate(); got != BPSoft {
+		t.Errorf("level = %s after one half-life, want SOFT (22.5s)", got)
+	}
+	clock = clock.Add(2 * time.Minute)
+	if got := a.Evaluate(); got != BPNone {
+		t.Errorf("level = %s after idle decay, want NONE", got)
+	}
+
+	// A fresh sample is smoothed against the decayed estimate
+	a.ObserveLatency(20 * time.Second)
+	if got := a.Evaluate(); got != BPNone {
+		t.Errorf("level = %s after one slow sample, want NONE", got)
+	}
+}
@@ -0,0 +1,228 @@
+package scheduler
+
+import (
+	"sync"
+	"time"
+)
+
+// ─── Request Affinity ───────────────────────────────────────────────────────
+// A conversation's follow-up request is cheapest on the node that served
+// the previous turn: the model is loaded and its KV cache already holds the
+// shared prefix. Clients opt in with a routing hint — a session ID or any
+// cache key they choose — and the AffinityTable remembers which node last
+// served each hint for each model:
+//
+//   - Prefer moves the remembered node to the front of a ranking, as long
+//     as it is still a candidate; otherwise the ranking is left alone and
+//     the request counts as moved
+//   - Record notes where the request actually ran
+//   - Observe counts whether the serving node had the model warm, for
+//     hinted and unhinted requests apart, so the warm-hit rate the hints
+//     buy can be read off Stats
+//
+// Entries are forgotten after TTL without use, and past MaxEntries the
+// least recently used goes first.
+
+// AffinityConfig configures an affinity table.
+type AffinityConfig struct {
+	TTL        time.Duration // forget a hint unused this long (default 30m)
+	MaxEntries int           // most hints remembered at once (default 100_000)
+	Now        func() time.Time
+}
+
+// DefaultAffinityConfig returns affinity defaults.
+func DefaultAffinityConfig() AffinityConfig {
+	return AffinityConfig{
+		TTL:        30 * time.Minute,
+		MaxEntries: 100_000,
+		Now:        time.Now,
+	}
+}
+
+// AffinityOutcome classifies one Prefer call.
+type AffinityOutcome string
+
+const (
+	AffinityNone  AffinityOutcome = "none"  // no hint given
+	AffinityNew   AffinityOutcome = "new"   // hint not seen (or expired) for this model
+	AffinityHit   AffinityOutcome = "hit"   // remembered node is a candidate and ranked first
+	AffinityMoved AffinityOutcome = "moved" // remembered node is no longer a candidate
+)
+
+// AffinityStats summarizes affinity routing.
+type AffinityStats struct {
+	Entries int   `json:"entries"`
+	Hits    int64 `json:"hits"`
+	New     int64 `json:"new"`
+	Moved   int64 `json:"moved"`
+
+	// Whether the serving node had the model warm, hinted requests apart
+	// from unhinted ones.
+	HintedRequests   int64   `json:"hinted_requests"`
+	HintedWarm       int64   `json:"hinted_warm"`
+	UnhintedRequests int64   `json:"unhinted_requests"`
+	UnhintedWarm     int64   `json:"unhinted_warm"`
+	HintedWarmRate   float64 `json:"hinted_warm_rate"`   // 0..1
+	UnhintedWarmRate float64 `json:"unhinted_warm_rate"` // 0..1
+}
+
+type affinityEntry struct {
+	nodeID   string
+	lastUsed time.Time
+}
+
+// AffinityTable maps routing hints to the node that last served them. It
+// is safe for concurrent use.
+type AffinityTable struct {
+	mu      sync.Mutex
+	cfg     AffinityConfig
+	entries map[string]*affinityEntry // hint + "\x00" + model → entry
+	stats   AffinityStats
+}
+
+// NewAffinityTable creates an empty table. Zero config fields take their
+// defaults.
+func NewAffinityTable(cfg AffinityConfig) *AffinityTable {
+	def := DefaultAffinityConfig()
+	if cfg.TTL <= 0 {
+		cfg.TTL = def.TTL
+	}
+	if cfg.MaxEntries <= 0 {
+		cfg.MaxEntries = def.MaxEntries
+	}
+	if cfg.Now == nil {
+		cfg.Now = def.Now
+	}
+	return &AffinityTable{cfg: cfg, entries: make(map[string]*affinityEntry)}
+}
+
+func affinityKey(hint, model string) string { return hint + "\x00" + model }
+
+// Lookup returns the node that last served hint for model.
+func (t *AffinityTable) Lookup(hint, model string) (string, bool) {
+	if hint == "" {
+		return "", false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	e, ok := t.liveLocked(affinityKey(hint, model), t.cfg.Now())
+	if !ok {
+		return "", false
+	}
+	return e.nodeID, true
+}
+
+// Prefer moves the node that last served hint for model to the front of
+// ranked, keeping the others in order, and reports the outcome. ranked is
+// not modified.
+func (t *AffinityTable) Prefer(hint, model string, ranked []NodeCandidate) ([]NodeCandidate, AffinityOutcome) {
+	if hint == "" {
+		return ranked, AffinityNone
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	e, ok := t.liveLocked(affinityKey(hint, model), t.cfg.Now())
+	if !ok {
+		t.stats.New++
+		return ranked, AffinityNew
+	}
+	for i, c := range ranked {
+		if c.NodeID != e.nodeID {
+			continue
+		}
+		out := make([]NodeCandidate, 0, len(ranked))
+		out = append(out, c)
+		out = append(out, ranked[:i]...)
+		out = append(out, ranked[i+1:]...)
+		t.stats.Hits++
+		return out, AffinityHit
+	}
+	t.stats.Moved++
+	return ranked, AffinityMoved
+}
+
+// Record notes that hint's request for model ran on nodeID.
+func (t *AffinityTable) Record(hint, model, nodeID string) {
+	if hint == "" || nodeID == "" {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.cfg.Now()
+	key := affinityKey(hint, model)
+	if e, ok := t.entries[key]; ok {
+		e.nodeID, e.lastUsed = nodeID, now
+		return
+	}
+	if len(t.entries) >= t.cfg.MaxEntries {
+		t.evictLocked(now)
+	}
+	t.entries[key] = &affinityEntry{nodeID: nodeID, lastUsed: now}
+}
+
+// Observe counts whether a request found its model warm on the node that
+// served it.
+func (t *AffinityTable) Observe(hinted, warm bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if hinted {
+		t.stats.HintedRequests++
+		if warm {
+			t.stats.HintedWarm++
+		}
+		return
+	}
+	t.stats.UnhintedRequests++
+	if warm {
+		t.stats.UnhintedWarm++
+	}
+}
+
+// Stats returns the routing and warm-hit counters.
+func (t *AffinityTable) Stats() AffinityStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	st := t.stats
+	st.Entries = len(t.entries)
+	if st.HintedRequests > 0 {
+		st.HintedWarmRate = float64(st.HintedWarm) / float64(st.HintedRequests)
+	}
+	if st.UnhintedRequests > 0 {
+		st.UnhintedWarmRate = float64(st.UnhintedWarm) / float64(st.UnhintedRequests)
+	}
+	return st
+}
+
+// liveLocked returns the entry for key unless it expired, which it drops.
+func (t *AffinityTable) liveLocked(key string, now time.Time) (*affinityEntry, bool) {
+	e, ok := t.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if now.Sub(e.lastUsed) > t.cfg.TTL {
+		delete(t.entries, key)
+		return nil, false
+	}
+	return e, true
+}
+
+// evictLocked drops expired entries, or the least recently used one if
+// none expired. Eviction scans the table, but only runs once it is full.
+func (t *AffinityTable) evictLocked(now time.Time) {
+	oldestKey := ""
+	var oldest time.Time
+	dropped := false
+	for k, e := range t.entries {
+		if now.Sub(e.lastUsed) > t.cfg.TTL {
+			delete(t.entries, k)
+			dropped = true
+			continue
+		}
+		if oldestKey == "" || e.lastUsed.Before(oldest) {
+			oldestKey, oldest = k, e.lastUsed
+		}
+	}
+	if !dropped && oldestKey != "" {
+		delete(t.entries, oldestKey)
+	}
+}
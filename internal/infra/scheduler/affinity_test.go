@@ -0,0 +1,68 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAffinity_Prefer(t *testing.T) {
+	clock := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	tbl := NewAffinityTable(AffinityConfig{TTL: time.Minute, MaxEntries: 2, Now: func() time.Time { return clock }})
+	ranked := []NodeCandidate{{NodeID: "a"}, {NodeID: "b"}, {NodeID: "c"}}
+
+	if _, out := tbl.Prefer("", "m", ranked); out != AffinityNone {
+		t.Errorf("no hint = %s, want none", out)
+	}
+	if _, out := tbl.Prefer("s1", "m", ranked); out != AffinityNew {
+		t.Errorf("first turn = %s, want new", out)
+	}
+	tbl.Record("s1", "m", "c")
+
+	got, out := tbl.Prefer("s1", "m", ranked)
+	if out != AffinityHit || got[0].NodeID != "c" || got[1].NodeID != "a" || got[2].NodeID != "b" {
+		t.Errorf("follow-up = %s %v, want hit with c first", out, got)
+	}
+	if ranked[0].NodeID != "a" {
+		t.Error("Prefer modified its input")
+	}
+	if _, out := tbl.Prefer("s1", "other-model", ranked); out != AffinityNew {
+		t.Errorf("other model = %s, want new", out)
+	}
+	if _, out := tbl.Prefer("s1", "m", ranked[:2]); out != AffinityMoved {
+		t.Errorf("node gone = %s, want moved", out)
+	}
+
+	// Expiry, then LRU eviction at MaxEntries
+	clock = clock.Add(2 * time.Minute)
+	if _, ok := tbl.Lookup("s1", "m"); ok {
+		t.Error("entry outlived its TTL")
+	}
+	tbl.Record("s1", "m", "a")
+	clock = clock.Add(time.Second)
+	tbl.Record("s2", "m", "b")
+	clock = clock.Add(time.Second)
+	tbl.Record("s3", "m", "c")
+	if _, ok := tbl.Lookup("s1", "m"); ok {
+		t.Error("least recently used entry was not evicted")
+	}
+	if n, ok := tbl.Lookup("s3", "m"); !ok || n != "c" {
+		t.Errorf("Lookup s3 = %q, %v", n, ok)
+	}
+
+	st := tbl.Stats()
+	if st.Entries != 2 || st.Hits != 1 || st.New != 2 || st.Moved != 1 {
+		t.Errorf("stats = %+v", st)
+	}
+}
+
+func TestAffinity_WarmRates(t *testing.T) {
+	tbl := NewAffinityTable(AffinityConfig{})
+	for i := 0; i < 4; i++ {
+		tbl.Observe(true, i > 0) // cold first turn, warm follow-ups
+		tbl.Observe(false, i%2 == 0)
+	}
+	st := tbl.Stats()
+	if st.HintedWarmRate != 0.75 || st.UnhintedWarmRate != 0.5 {
+		t.Errorf("warm rates = %.2f hinted, %.2f unhinted", st.HintedWarmRate, st.UnhintedWarmRate)
+	}
+}
@@ -0,0 +1,174 @@
+package scheduler
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
+)
+
+// ─── Deadlines & SLA Classes ────────────────────────────────────────────────
+// A task may carry an absolute deadline, an SLA class, or both. A class
+// without a deadline gets one from its budget at enqueue time:
+//
+//  1. Within a priority class the earliest deadline runs first (EDF);
+//     tasks without a deadline queue behind those with one
+//  2. Enqueue estimates when the task would finish — the work queued ahead
+//     of it spread over Workers, plus its own service time — and rejects it
+//     with ErrDeadlineUnmeetable when that is past the deadline
+//  3. CompleteTask records met/missed per SLA class; the miss rates are
+//     the evidence for SLA_PRICING governance proposals
+//
+// Service time is learned from completions, so feasibility checks only
+// start once the first timed task finishes (or ServiceTime is configured).
+
+// serviceAlpha is the EWMA weight of a new service time observation.
+const serviceAlpha = 0.2
+
+// DefaultSLABudgets returns the end-to-end deadline per SLA class, matching
+// the MCP tiers' p99 latency targets. Spot work has no deadline.
+func DefaultSLABudgets() map[domain.SLATier]time.Duration {
+	return map[domain.SLATier]time.Duration{
+		domain.SLARealtime: 200 * time.Millisecond,
+		domain.SLAStandard: 2 * time.Second,
+		domain.SLABatch:    30 * time.Second,
+	}
+}
+
+// SLAClassStats is deadline accounting for one SLA class. Tasks with a
+// deadline but no class are reported under "custom".
+type SLAClassStats struct {
+	Class     domain.SLATier `json:"class"`
+	Met       int64          `json:"met"`
+	Missed    int64          `json:"missed"`
+	Rejected  int64          `json:"rejected"`  // refused at enqueue as unmeetable
+	MissRate  float64        `json:"miss_rate"` // missed / (met + missed)
+	WorstLate time.Duration  `json:"worst_late"`
+}
+
+// withDeadline fills in a deadline from the task's SLA budget.
+func (s *Scheduler) withDeadline(task domain.Task, now time.Time) domain.Task {
+	if !task.HasDeadline() && task.SLA != "" {
+		if budget := s.config.SLABudgets[task.SLA]; budget > 0 {
+			task.Deadline = now.Add(budget)
+		}
+	}
+	return task
+}
+
+// feasibleLocked rejects a task whose estimated completion is past its
+// deadline. Must be called with s.mu held.
+func (s *Scheduler) feasibleLocked(task domain.Task, pClass int, now time.Time) error {
+	if !task.HasDeadline() {
+		return nil
+	}
+	if !now.Before(task.Deadline) {
+		s.slaLocked(task.SLA).Rejected++
+		return fmt.Errorf("deadline %s already passed: %w", task.Deadline.Format(time.RFC3339), domain.ErrDeadlineUnmeetable)
+	}
+	if s.serviceTime <= 0 {
+		return nil // nothing learned yet
+	}
+
+	ahead := 0
+	for q := 0; q < pClass; q++ {
+		ahead += len(s.queues[q])
+	}
+	for _, qt := range s.queues[pClass] {
+		if qt.Task.HasDeadline() && !qt.Task.Deadline.After(task.Deadline) {
+			ahead++
+		}
+	}
+	eta := now.Add(time.Duration(ahead/s.config.Workers+1) * s.serviceTime)
+	if eta.After(task.Deadline) {
+		s.slaLocked(task.SLA).Rejected++
+		return fmt.Errorf("%d tasks ahead, estimated finish %s after deadline: %w",
+			ahead, eta.Sub(task.Deadline).Round(time.Millisecond), domain.ErrDeadlineUnmeetable)
+	}
+	return nil
+}
+
+// edfBefore reports whether a should run before b among tasks of equal
+// effective priority.
+func edfBefore(a, b QueuedTask) bool {
+	ad, bd := a.Task.HasDeadline(), b.Task.HasDeadline()
+	switch {
+	case ad && bd && !a.Task.Deadline.Equal(b.Task.Deadline):
+		return a.Task.Deadline.Before(b.Task.Deadline)
+	case ad != bd:
+		return ad
+	default:
+		return a.QueuedAt.Before(b.QueuedAt)
+	}
+}
+
+// CompleteTask records a finished task: the completion counter, the service
+// time estimate (when StartedAt is set) and, for tasks with a deadline,
+// whether the deadline was met.
+func (s *Scheduler) CompleteTask(task domain.Task, finishedAt time.Time) {
+	s.totalCompleted.Add(1)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !task.StartedAt.IsZero() && finishedAt.After(task.StartedAt) {
+		d := finishedAt.Sub(task.StartedAt)
+		if s.serviceTime <= 0 {
+			s.serviceTime = d
+		} else {
+			s.serviceTime = time.Duration(serviceAlpha*float64(d) + (1-serviceAlpha)*float64(s.serviceTime))
+		}
+	}
+	if !task.HasDeadline() {
+		return
+	}
+	st := s.slaLocked(task.SLA)
+	if late := finishedAt.Sub(task.Deadline); late > 0 {
+		st.Missed++
+		st.WorstLate = max(st.WorstLate, late)
+	} else {
+		st.Met++
+	}
+}
+
+// SLAReport returns deadline accounting per SLA class, sorted by class.
+func (s *Scheduler) SLAReport() []SLAClassStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.slaReportLocked()
+}
+
+// ServiceTime returns the current per-task service time estimate.
+func (s *Scheduler) ServiceTime() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.serviceTime
+}
+
+func (s *Scheduler) slaReportLocked() []SLAClassStats {
+	if len(s.sla) == 0 {
+		return nil
+	}
+	out := make([]SLAClassStats, 0, len(s.sla))
+	for _, st := range s.sla {
+		c := *st
+		if n := c.Met + c.Missed; n > 0 {
+			c.MissRate = float64(c.Missed) / float64(n)
+		}
+		out = append(out, c)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Class < out[j].Class })
+	return out
+}
+
+func (s *Scheduler) slaLocked(class domain.SLATier) *SLAClassStats {
+	if class == "" {
+		class = "custom"
+	}
+	st, ok := s.sla[class]
+	if !ok {
+		st = &SLAClassStats{Class: class}
+		s.sla[class] = st
+	}
+	return st
+}
@@ -0,0 +1,106 @@
+package scheduler
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
+)
+
+func TestScheduler_EDFWithinClass(t *testing.T) {
+	s := newTestScheduler(t)
+	now := time.Now()
+	tasks := []domain.Task{
+		{ID: "no-deadline", Priority: P2Normal},
+		{ID: "late", Priority: P2Normal, Deadline: now.Add(time.Hour)},
+		{ID: "soon", Priority: P2Normal, Deadline: now.Add(time.Minute)},
+		{ID: "high", Priority: P1High},
+	}
+	for _, task := range tasks {
+		if err := s.Enqueue(task, domain.TaskRouting{}); err != nil {
+			t.Fatalf("Enqueue(%s): %v", task.ID, err)
+		}
+	}
+	for _, want := range []string{"high", "soon", "late", "no-deadline"} {
+		got := s.Dequeue()
+		if got == nil || got.Task.ID != want {
+			t.Fatalf("Dequeue() = %v, want %s", got, want)
+		}
+	}
+}
+
+func TestScheduler_SLABudgetDeadline(t *testing.T) {
+	s := newTestScheduler(t)
+	before := time.Now()
+	if err := s.Enqueue(domain.Task{ID: "std", SLA: domain.SLAStandard}, domain.TaskRouting{}); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	if err := s.Enqueue(domain.Task{ID: "spot", SLA: domain.SLASpot, Priority: P4Spot}, domain.TaskRouting{}); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	std := s.Dequeue()
+	if d := std.Task.Deadline.Sub(before); d < 2*time.Second || d > 3*time.Second {
+		t.Errorf("standard deadline in %s, want ~2s", d)
+	}
+	if spot := s.Dequeue(); spot.Task.HasDeadline() {
+		t.Error("spot task got a deadline")
+	}
+}
+
+func TestScheduler_RejectsUnmeetableDeadline(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Workers = 2
+	cfg.ServiceTime = time.Second
+	s := NewScheduler(cfg)
+	for _, id := range []string{"a", "b", "c", "d"} {
+		s.Enqueue(domain.Task{ID: id, Priority: P1High}, domain.TaskRouting{})
+	}
+
+	tests := []struct {
+		name string
+		task domain.Task
+		want error
+	}{
+		// 4 higher-priority tasks on 2 workers → finishes in ~3s
+		{"fits", domain.Task{ID: "ok", Priority: P2Normal, Deadline: time.Now().Add(5 * time.Second)}, nil},
+		{"too tight", domain.Task{ID: "tight", Priority: P2Normal, Deadline: time.Now().Add(2 * time.Second), SLA: domain.SLAStandard}, domain.ErrDeadlineUnmeetable},
+		{"already passed", domain.Task{ID: "past", Deadline: time.Now().Add(-time.Second)}, domain.ErrDeadlineUnmeetable},
+		{"realtime jumps the queue", domain.Task{ID: "rt", Priority: P0Realtime, Deadline: time.Now().Add(1500 * time.Millisecond)}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := s.Enqueue(tt.task, domain.TaskRouting{}); !errors.Is(err, tt.want) {
+				t.Errorf("Enqueue err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	report := s.SLAReport()
+	if len(report) != 2 || report[0].Class != "custom" || report[0].Rejected != 1 || report[1].Rejected != 1 {
+		t.Errorf("SLAReport = %+v", report)
+	}
+}
+
+func TestScheduler_CompleteTaskAccounting(t *testing.T) {
+	s := newTestScheduler(t)
+	start := time.Now()
+	deadline := start.Add(2 * time.Second)
+
+	s.CompleteTask(domain.Task{SLA: domain.SLAStandard, StartedAt: start, Deadline: deadline}, start.Add(time.Second))
+	s.CompleteTask(domain.Task{SLA: domain.SLAStandard, StartedAt: start, Deadline: deadline}, start.Add(5*time.Second))
+	s.CompleteTask(domain.Task{StartedAt: start}, start.Add(time.Second)) // no deadline
+
+	st := s.Stats()
+	if st.TotalCompleted != 3 || len(st.SLA) != 1 {
+		t.Fatalf("stats = %+v", st)
+	}
+	c := st.SLA[0]
+	if c.Met != 1 || c.Missed != 1 || c.MissRate != 0.5 || c.WorstLate != 3*time.Second {
+		t.Errorf("standard class = %+v", c)
+	}
+	// EWMA of 1s, 5s, 1s
+	if got := s.ServiceTime(); got < time.Second || got > 2*time.Second {
+		t.Errorf("ServiceTime = %s, want between 1s and 2s", got)
+	}
+}
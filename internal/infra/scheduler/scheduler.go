@@ -30,6 +30,11 @@ type Config struct {
 	StealBatchSize     int           // how many tasks to steal at once (default: half of peer's queue)
 	StarvationInterval time.Duration // boost priority every N (default 60s)
 	PreemptionEnabled  bool          // allow realtime to preempt spot (default true)
+
+	// Deadlines (see deadline.go)
+	SLABudgets  map[domain.SLATier]time.Duration // deadline for tasks that name an SLA class but no deadline
+	Workers     int                              // tasks executed in parallel, for feasibility (default 4)
+	ServiceTime time.Duration                    // initial per-task service estimate (0 = learn from completions)
 }
 
 // DefaultConfig returns production scheduler defaults.
@@ -42,6 +47,8 @@ func DefaultConfig() Config {
 		StealBatchSize:     0, // 0 means "half of peer's queue"
 		StarvationInterval: 60 * time.Second,
 		PreemptionEnabled:  true,
+		SLABudgets:         DefaultSLABudgets(),
+		Workers:            4,
 	}
 }
 
@@ -139,11 +146,25 @@ type Scheduler struct {
 	totalRejected  atomic.Int64
 	totalStolen    atomic.Int64
 	totalPreempted atomic.Int64
+
+	// Deadline accounting — guarded by mu
+	serviceTime time.Duration // EWMA of observed task service time
+	sla         map[domain.SLATier]*SLAClassStats
 }
 
 // NewScheduler creates a new advanced scheduler.
 func NewScheduler(cfg Config) *Scheduler {
-	return &Scheduler{config: cfg}
+	if cfg.SLABudgets == nil {
+		cfg.SLABudgets = DefaultSLABudgets()
+	}
+	if cfg.Workers <= 0 {
+		cfg.Workers = DefaultConfig().Workers
+	}
+	return &Scheduler{
+		config:      cfg,
+		serviceTime: cfg.ServiceTime,
+		sla:         make(map[domain.SLATier]*SLAClassStats),
+	}
 }
 
 // ─── Enqueue ────────────────────────────────────────────────────────────────
@@ -174,9 +195,10 @@ func (s *Scheduler) Enqueue(task domain.Task, routing domain.TaskRouting) error
 		}
 	}
 
+	now := time.Now()
 	qt := QueuedTask{
-		Task:     task,
-		QueuedAt: time.Now(),
+		Task:     s.withDeadline(task, now),
+		QueuedAt: now,
 		Routing:  routing,
 	}
 
@@ -189,6 +211,12 @@ func (s *Scheduler) Enqueue(task domain.Task, routing domain.TaskRouting) error
 		pClass = 4
 	}
 
+	// Deadline feasibility — reject now rather than miss later
+	if err := s.feasibleLocked(qt.Task, pClass, now); err != nil {
+		s.totalRejected.Add(1)
+		return err
+	}
+
 	s.queues[pClass] = append(s.queues[pClass], qt)
 	s.totalEnqueued.Add(1)
 	return nil
@@ -204,7 +232,8 @@ func (s *Scheduler) Dequeue() *QueuedTask {
 	defer s.mu.Unlock()
 
 	// Scan from highest priority (P0) to lowest (P4).
-	// Within each queue, find the task with the best effective priority.
+	// Within each queue, find the task with the best effective priority;
+	// ties go to the earliest deadline (EDF), then the oldest task.
 	var bestIdx int = -1
 	var bestQueue int = -1
 	var bestEffective int = math.MaxInt
@@ -212,7 +241,7 @@ func (s *Scheduler) Dequeue() *QueuedTask {
 	for q := 0; q < 5; q++ {
 		for i, qt := range s.queues[q] {
 			eff := qt.EffectivePriority(s.config.StarvationInterval)
-			if eff < bestEffective {
+			if eff < bestEffective || (eff == bestEffective && edfBefore(qt, s.queues[bestQueue][bestIdx])) {
 				bestEffective = eff
 				bestIdx = i
 				bestQueue = q
@@ -324,6 +353,7 @@ type Stats struct {
 	TotalRejected  int64             `json:"total_rejected"`
 	TotalStolen    int64             `json:"total_stolen"`
 	TotalPreempted int64             `json:"total_preempted"`
+	SLA            []SLAClassStats   `json:"sla,omitempty"`
 }
 
 // Stats returns current scheduler statistics.
@@ -335,6 +365,7 @@ func (s *Scheduler) Stats() Stats {
 	for i := 0; i < 5; i++ {
 		byClass[i] = len(s.queues[i])
 	}
+	sla := s.slaReportLocked()
 	s.mu.Unlock()
 
 	return Stats{
@@ -346,6 +377,7 @@ func (s *Scheduler) Stats() Stats {
 		TotalRejected:  s.totalRejected.Load(),
 		TotalStolen:    s.totalStolen.Load(),
 		TotalPreempted: s.totalPreempted.Load(),
+		SLA:            sla,
 	}
 }
 
@@ -368,6 +400,16 @@ func (s *Scheduler) MarkCompleted() {
 	s.totalCompleted.Add(1)
 }
 
+// RestoreCounters sets the cumulative counters, so they continue from the
+// last persisted snapshot after a restart. Queued tasks are not restored.
+func (s *Scheduler) RestoreCounters(enqueued, completed, rejected, stolen, preempted int64) {
+	s.totalEnqueued.Store(enqueued)
+	s.totalCompleted.Store(completed)
+	s.totalRejected.Store(rejected)
+	s.totalStolen.Store(stolen)
+	s.totalPreempted.Store(preempted)
+}
+
 // ─── Internal ───────────────────────────────────────────────────────────────
 
 func (s *Scheduler) queueDepthLocked() int {
@@ -479,3 +521,31 @@ func RankNodes(candidates []NodeCandidate, task domain.Task, taskRegion domain.R
 	}
 	return ranked
 }
+
+// ─── Model Availability ─────────────────────────────────────────────────────
+
+// ModelAvailability reports which nodes host which models. It is satisfied
+// by the gossip availability index.
+type ModelAvailability interface {
+	HasModel(nodeID, model string) bool
+}
+
+// RankNodesForModel ranks only the candidates that host model according to
+// av. If no candidate hosts it, every candidate is ranked — the chosen node
+// will have to pull the model, which beats rejecting the task. A nil av
+// behaves like RankNodes.
+func RankNodesForModel(candidates []NodeCandidate, task domain.Task, taskRegion domain.RegionID, model string, av ModelAvailability) []NodeCandidate {
+	if av == nil || model == "" {
+		return RankNodes(candidates, task, taskRegion)
+	}
+	hosting := make([]NodeCandidate, 0, len(candidates))
+	for _, c := range candidates {
+		if av.HasModel(c.NodeID, model) {
+			hosting = append(hosting, c)
+		}
+	}
+	if len(hosting) == 0 {
+		return RankNodes(candidates, task, taskRegion)
+	}
+	return RankNodes(hosting, task, taskRegion)
+}
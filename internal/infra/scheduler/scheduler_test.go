@@ -440,6 +440,41 @@ func TestRankNodes(t *testing.T) {
 	}
 }
 
+type fakeAvailability map[string]bool // "node/model" → hosted
+
+func (f fakeAvailability) HasModel(nodeID, model string) bool { return f[nodeID+"/"+model] }
+
+func TestRankNodesForModel(t *testing.T) {
+	candidates := []NodeCandidate{
+		{NodeID: "good", Region: domain.RegionUSEast, Reputation: 0.95, CurrentLoad: 0.1, GPUAvailable: true},
+		{NodeID: "mid", Region: domain.RegionUSEast, Reputation: 0.5, CurrentLoad: 0.5, GPUAvailable: true},
+	}
+	task := domain.Task{Type: domain.TaskInference}
+
+	tests := []struct {
+		name string
+		av   ModelAvailability
+		want []string
+	}{
+		{"nil availability", nil, []string{"good", "mid"}},
+		{"only mid hosts", fakeAvailability{"mid/llama3": true}, []string{"mid"}},
+		{"nobody hosts", fakeAvailability{}, []string{"good", "mid"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ranked := RankNodesForModel(candidates, task, domain.RegionUSEast, "llama3", tt.av)
+			if len(ranked) != len(tt.want) {
+				t.Fatalf("RankNodesForModel() returned %d, want %d", len(ranked), len(tt.want))
+			}
+			for i, id := range tt.want {
+				if ranked[i].NodeID != id {
+					t.Errorf("ranked[%d] = %q, want %q", i, ranked[i].NodeID, id)
+				}
+			}
+		})
+	}
+}
+
 // ─── Stats ──────────────────────────────────────────────────────────────────
 
 func TestScheduler_Stats(t *testing.T) {
@@ -457,3 +492,18 @@ func TestScheduler_Stats(t *testing.T) {
 		t.Errorf("TotalCompleted = %d, want 1", stats.TotalCompleted)
 	}
 }
+
+func TestScheduler_RestoreCounters(t *testing.T) {
+	s := newTestScheduler(t)
+	s.RestoreCounters(500, 400, 10, 50, 5)
+	s.MarkCompleted()
+
+	stats := s.Stats()
+	if stats.TotalEnqueued != 500 || stats.TotalCompleted != 401 || stats.TotalRejected != 10 ||
+		stats.TotalStolen != 50 || stats.TotalPreempted != 5 {
+		t.Errorf("stats after restore = %+v", stats)
+	}
+	if stats.QueueDepth != 0 {
+		t.Errorf("QueueDepth = %d, want 0", stats.QueueDepth)
+	}
+}
@@ -0,0 +1,430 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
+	"github.com/tutu-network/tutu/internal/infra/observability"
+)
+
+// ─── Work-Stealing Protocol ─────────────────────────────────────────────────
+// Moves queued tasks from overloaded nodes to idle ones:
+//
+//  1. Every node advertises its queue depth via gossip (gossip.LoadReport)
+//  2. A node whose own queue is at or below IdleDepth asks the busiest
+//     peer (depth ≥ MinVictimDepth) for work
+//  3. The victim removes a batch from the top of its queues and returns it
+//     as a pending grant — the tasks belong to nobody until confirmed
+//  4. The thief drops tasks it already owns and confirms the rest; only
+//     then does it import them, and the victim records the thief as owner
+//     and re-queues the refused ones
+//  5. A grant not confirmed within HandoffTTL is reclaimed: its tasks go
+//     back into the victim's queue and a late confirmation is rejected, so
+//     the thief never imports them
+//
+// A task is therefore queued on exactly one node at any time. Confirmation
+// is idempotent, so a thief retries it when the reply is lost.
+
+// StealConfig configures the work stealer.
+type StealConfig struct {
+	SelfID         string
+	Interval       time.Duration // how often an idle node looks for work (default 2s)
+	IdleDepth      int           // steal only while the local queue is at most this deep (default 0)
+	MinVictimDepth int           // ignore peers advertising fewer queued tasks (default 4)
+	BatchSize      int           // tasks to ask for; 0 lets the victim give half its queue
+	HandoffTTL     time.Duration // unconfirmed grants are reclaimed after this (default 30s)
+	RequestTimeout time.Duration // per request/confirm call (default 5s)
+	ConfirmRetries int           // extra confirm attempts on transport errors (default 2)
+	RetainOwners   int           // task ownership records kept for dedupe (default 10000)
+	Now            func() time.Time
+}
+
+// DefaultStealConfig returns work-stealing defaults.
+func DefaultStealConfig() StealConfig {
+	return StealConfig{
+		SelfID:         "node-local",
+		Interval:       2 * time.Second,
+		MinVictimDepth: 4,
+		HandoffTTL:     30 * time.Second,
+		RequestTimeout: 5 * time.Second,
+		ConfirmRetries: 2,
+		RetainOwners:   10_000,
+		Now:            time.Now,
+	}
+}
+
+// StealRequest asks a victim for up to Max tasks (0 = victim decides).
+type StealRequest struct {
+	Thief string `json:"thief"`
+	Max   int    `json:"max,omitempty"`
+}
+
+// StealGrant is a batch of tasks offered to a thief, pending confirmation.
+type StealGrant struct {
+	ID        string       `json:"id"`
+	Victim    string       `json:"victim"`
+	Thief     string       `json:"thief"`
+	Tasks     []QueuedTask `json:"tasks"`
+	ExpiresAt time.Time    `json:"expires_at"`
+}
+
+// StealConfirm takes ownership of the Accepted task IDs of a grant.
+type StealConfirm struct {
+	GrantID  string   `json:"grant_id"`
+	Thief    string   `json:"thief"`
+	Accepted []string `json:"accepted"`
+}
+
+// StealHooks connect the stealer to other nodes. Victims lists peers with
+// at least minDepth queued tasks, busiest first; Request and Confirm reach a
+// peer's Grant and Confirm.
+type StealHooks struct {
+	Victims func(minDepth int) []string
+	Request func(ctx context.Context, victim string, req StealRequest) (StealGrant, error)
+	Confirm func(ctx context.Context, victim string, c StealConfirm) error
+}
+
+// StealStats summarises both sides of the protocol.
+type StealStats struct {
+	Attempts      int64 `json:"attempts"`       // steal requests sent to victims
+	Imported      int64 `json:"imported"`       // tasks taken over from peers
+	Duplicates    int64 `json:"duplicates"`     // offered tasks refused as already owned
+	Failed        int64 `json:"failed"`         // requests or confirmations that failed
+	Granted       int64 `json:"granted"`        // tasks offered to thieves
+	HandedOff     int64 `json:"handed_off"`     // offered tasks a thief took ownership of
+	Reclaimed     int64 `json:"reclaimed"`      // offered tasks returned to the local queue
+	PendingGrants int   `json:"pending_grants"` // grants awaiting confirmation
+}
+
+// grantState tracks a grant on the victim.
+type grantState struct {
+	grant     StealGrant
+	confirmed bool
+}
+
+// Stealer runs both sides of the work-stealing protocol for one node.
+type Stealer struct {
+	mu     sync.Mutex
+	cfg    StealConfig
+	sched  *Scheduler
+	hooks  StealHooks
+	grants map[string]*grantState
+	nextID int64
+
+	// owners maps task ID → owning node for tasks that changed hands,
+	// oldest first in ownerOrder so the ledger stays bounded.
+	owners     map[string]string
+	ownerOrder []string
+
+	stats StealStats
+}
+
+// NewStealer creates a stealer moving tasks in and out of sched.
+func NewStealer(cfg StealConfig, sched *Scheduler) *Stealer {
+	def := DefaultStealConfig()
+	if cfg.SelfID == "" {
+		cfg.SelfID = def.SelfID
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = def.Interval
+	}
+	if cfg.IdleDepth < 0 {
+		cfg.IdleDepth = 0
+	}
+	if cfg.MinVictimDepth <= 0 {
+		cfg.MinVictimDepth = def.MinVictimDepth
+	}
+	if cfg.HandoffTTL <= 0 {
+		cfg.HandoffTTL = def.HandoffTTL
+	}
+	if cfg.RequestTimeout <= 0 {
+		cfg.RequestTimeout = def.RequestTimeout
+	}
+	if cfg.ConfirmRetries < 0 {
+		cfg.ConfirmRetries = 0
+	}
+	if cfg.RetainOwners <= 0 {
+		cfg.RetainOwners = def.RetainOwners
+	}
+	if cfg.Now == nil {
+		cfg.Now = def.Now
+	}
+	return &Stealer{
+		cfg:    cfg,
+		sched:  sched,
+		grants: make(map[string]*grantState),
+		owners: make(map[string]string),
+		nextID: 1,
+	}
+}
+
+// SetHooks connects the stealer to peers. Without hooks the node only
+// answers steal requests.
+func (st *Stealer) SetHooks(h StealHooks) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.hooks = h
+}
+
+// Run steals and reclaims every Interval until ctx is done.
+func (st *Stealer) Run(ctx context.Context) {
+	ticker := time.NewTicker(st.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			st.Reclaim()
+			_, _ = st.StealOnce(ctx)
+		}
+	}
+}
+
+// ─── Thief Side ─────────────────────────────────────────────────────────────
+
+// StealOnce takes one batch from the busiest peer willing to give one, if
+// this node is idle. Returns the number of tasks imported.
+func (st *Stealer) StealOnce(ctx context.Context) (int, error) {
+	st.mu.Lock()
+	h := st.hooks
+	st.mu.Unlock()
+	if h.Victims == nil || h.Request == nil || h.Confirm == nil {
+		return 0, nil
+	}
+	if st.sched.QueueDepth() > st.cfg.IdleDepth {
+		return 0, nil
+	}
+
+	var lastErr error
+	for _, victim := range h.Victims(st.cfg.MinVictimDepth) {
+		if victim == st.cfg.SelfID {
+			continue
+		}
+		st.mu.Lock()
+		st.stats.Attempts++
+		st.mu.Unlock()
+		n, err := st.stealFrom(ctx, h, victim)
+		if err == nil {
+			return n, nil
+		}
+		lastErr = err
+	}
+	return 0, lastErr
+}
+
+// stealFrom runs one request/confirm round against victim.
+func (st *Stealer) stealFrom(ctx context.Context, h StealHooks, victim string) (int, error) {
+	reqCtx, cancel := context.WithTimeout(ctx, st.cfg.RequestTimeout)
+	grant, err := h.Request(reqCtx, victim, StealRequest{Thief: st.cfg.SelfID, Max: st.cfg.BatchSize})
+	cancel()
+	if err != nil {
+		st.fail()
+		return 0, fmt.Errorf("steal from %s: %w", victim, err)
+	}
+
+	// Refuse anything already queued or run here
+	st.mu.Lock()
+	accepted := make([]QueuedTask, 0, len(grant.Tasks))
+	ids := make([]string, 0, len(grant.Tasks))
+	for _, qt := range grant.Tasks {
+		if st.owners[qt.Task.ID] == st.cfg.SelfID {
+			st.stats.Duplicates++
+			continue
+		}
+		accepted = append(accepted, qt)
+		ids = append(ids, qt.Task.ID)
+	}
+	st.mu.Unlock()
+
+	confirm := StealConfirm{GrantID: grant.ID, Thief: st.cfg.SelfID, Accepted: ids}
+	for attempt := 0; ; attempt++ {
+		reqCtx, cancel := context.WithTimeout(ctx, st.cfg.RequestTimeout)
+		err = h.Confirm(reqCtx, victim, confirm)
+		cancel()
+		if err == nil || attempt >= st.cfg.ConfirmRetries || isStealRejection(err) {
+			break
+		}
+	}
+	if err != nil {
+		// The victim keeps (or reclaims) the tasks — importing them now
+		// would run them twice.
+		st.fail()
+		return 0, fmt.Errorf("confirm grant %s from %s: %w", grant.ID, victim, err)
+	}
+
+	st.sched.ImportStolenTasks(accepted)
+	st.mu.Lock()
+	for _, qt := range accepted {
+		st.recordOwnerLocked(qt.Task.ID, st.cfg.SelfID)
+	}
+	st.stats.Imported += int64(len(accepted))
+	st.mu.Unlock()
+	observability.SchedulerTasksStolen.Add(float64(len(accepted)))
+	return len(accepted), nil
+}
+
+// isStealRejection reports whether err is a definitive answer from the
+// victim rather than a transport failure worth retrying.
+func isStealRejection(err error) bool {
+	for _, target := range []error{domain.ErrStealGrantNotFound, domain.ErrStealGrantExpired, domain.ErrStealInvalid} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func (st *Stealer) fail() {
+	st.mu.Lock()
+	st.stats.Failed++
+	st.mu.Unlock()
+}
+
+// ─── Victim Side ────────────────────────────────────────────────────────────
+
+// Grant removes a batch of tasks from the local queues and offers it to
+// req.Thief. The tasks stay reserved until Confirm or HandoffTTL.
+func (st *Stealer) Grant(req StealRequest) (StealGrant, error) {
+	if req.Thief == "" || req.Thief == st.cfg.SelfID || req.Max < 0 {
+		return StealGrant{}, fmt.Errorf("thief %q, max %d: %w", req.Thief, req.Max, domain.ErrStealInvalid)
+	}
+	tasks := st.sched.StealableTasks(req.Max)
+	if len(tasks) == 0 {
+		return StealGrant{}, domain.ErrNothingToSteal
+	}
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	now := st.cfg.Now()
+	g := StealGrant{
+		ID:        fmt.Sprintf("steal-%d-%d", now.UnixMilli(), st.nextID),
+		Victim:    st.cfg.SelfID,
+		Thief:     req.Thief,
+		Tasks:     tasks,
+		ExpiresAt: now.Add(st.cfg.HandoffTTL),
+	}
+	st.nextID++
+	st.grants[g.ID] = &grantState{grant: g}
+	st.stats.Granted += int64(len(tasks))
+	return g, nil
+}
+
+// Confirm hands ownership of the accepted tasks to the thief and re-queues
+// the rest. Confirming an already confirmed grant again is a no-op.
+func (st *Stealer) Confirm(c StealConfirm) error {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	gs, ok := st.grants[c.GrantID]
+	if !ok || gs.grant.Thief != c.Thief {
+		return fmt.Errorf("%s: %w", c.GrantID, domain.ErrStealGrantNotFound)
+	}
+	if gs.confirmed {
+		return nil
+	}
+	if !st.cfg.Now().Before(gs.grant.ExpiresAt) {
+		st.reclaimLocked(gs)
+		return fmt.Errorf("%s: %w", c.GrantID, domain.ErrStealGrantExpired)
+	}
+
+	accepted := make(map[string]bool, len(c.Accepted))
+	for _, id := range c.Accepted {
+		accepted[id] = true
+	}
+	var refused []QueuedTask
+	for _, qt := range gs.grant.Tasks {
+		if accepted[qt.Task.ID] {
+			st.recordOwnerLocked(qt.Task.ID, c.Thief)
+			st.stats.HandedOff++
+		} else {
+			refused = append(refused, qt)
+		}
+	}
+	st.requeueLocked(refused)
+	gs.confirmed = true
+	gs.grant.Tasks = nil // ownership is settled; keep the ID for retries
+	return nil
+}
+
+// Reclaim returns the tasks of expired, unconfirmed grants to the local
+// queue and forgets settled grants. Returns the number of tasks reclaimed.
+func (st *Stealer) Reclaim() int {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	now := st.cfg.Now()
+	n := 0
+	for id, gs := range st.grants {
+		if now.Before(gs.grant.ExpiresAt) {
+			continue
+		}
+		if !gs.confirmed {
+			n += len(gs.grant.Tasks)
+			st.reclaimLocked(gs)
+		}
+		// Confirmed grants linger one TTL so retried confirms still succeed
+		if !now.Before(gs.grant.ExpiresAt.Add(st.cfg.HandoffTTL)) {
+			delete(st.grants, id)
+		}
+	}
+	return n
+}
+
+// reclaimLocked puts an unconfirmed grant's tasks back into the queue.
+// The grant stays known so a late confirmation is answered with
+// ErrStealGrantExpired rather than ErrStealGrantNotFound.
+func (st *Stealer) reclaimLocked(gs *grantState) {
+	if gs.confirmed || len(gs.grant.Tasks) == 0 {
+		return
+	}
+	st.requeueLocked(gs.grant.Tasks)
+	gs.grant.Tasks = nil
+	gs.grant.ExpiresAt = st.cfg.Now() // never confirmable again
+}
+
+func (st *Stealer) requeueLocked(tasks []QueuedTask) {
+	if len(tasks) == 0 {
+		return
+	}
+	st.sched.ImportStolenTasks(tasks)
+	st.stats.Reclaimed += int64(len(tasks))
+}
+
+// ─── Ownership & Stats ──────────────────────────────────────────────────────
+
+// Owner returns the node a transferred task belongs to. Tasks that never
+// changed hands are unknown.
+func (st *Stealer) Owner(taskID string) (string, bool) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	owner, ok := st.owners[taskID]
+	return owner, ok
+}
+
+// Stats returns work-stealing counters.
+func (st *Stealer) Stats() StealStats {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	out := st.stats
+	for _, gs := range st.grants {
+		if !gs.confirmed && len(gs.grant.Tasks) > 0 {
+			out.PendingGrants++
+		}
+	}
+	return out
+}
+
+func (st *Stealer) recordOwnerLocked(taskID, node string) {
+	if _, ok := st.owners[taskID]; !ok {
+		st.ownerOrder = append(st.ownerOrder, taskID)
+	}
+	st.owners[taskID] = node
+	for len(st.ownerOrder) > st.cfg.RetainOwners {
+		delete(st.owners, st.ownerOrder[0])
+		st.ownerOrder = st.ownerOrder[1:]
+	}
+}
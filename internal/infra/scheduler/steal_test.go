@@ -0,0 +1,169 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
+)
+
+// stealPair wires a thief to a victim in-process.
+type stealPair struct {
+	thief, victim           *Stealer
+	thiefSched, victimSched *Scheduler
+	clock                   time.Time
+	dropConfirm             bool // the confirm never reaches the victim
+}
+
+func newStealPair(t *testing.T, queued int) *stealPair {
+	t.Helper()
+	p := &stealPair{
+		clock:       time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		thiefSched:  NewScheduler(DefaultConfig()),
+		victimSched: NewScheduler(DefaultConfig()),
+	}
+	now := func() time.Time { return p.clock }
+	p.thief = NewStealer(StealConfig{SelfID: "thief", ConfirmRetries: -1, Now: now}, p.thiefSched)
+	p.victim = NewStealer(StealConfig{SelfID: "victim", Now: now}, p.victimSched)
+	p.thief.SetHooks(StealHooks{
+		Victims: func(int) []string { return []string{"victim"} },
+		Request: func(_ context.Context, _ string, req StealRequest) (StealGrant, error) {
+			return p.victim.Grant(req)
+		},
+		Confirm: func(_ context.Context, _ string, c StealConfirm) error {
+			if p.dropConfirm {
+				return errors.New("connection reset")
+			}
+			return p.victim.Confirm(c)
+		},
+	})
+	for i := 0; i < queued; i++ {
+		task := domain.Task{ID: fmt.Sprintf("t%d", i), Priority: P3Low}
+		if err := p.victimSched.Enqueue(task, domain.TaskRouting{}); err != nil {
+			t.Fatalf("Enqueue: %v", err)
+		}
+	}
+	return p
+}
+
+func TestStealer_StealOnce(t *testing.T) {
+	p := newStealPair(t, 10)
+
+	n, err := p.thief.StealOnce(context.Background())
+	if err != nil || n != 5 {
+		t.Fatalf("StealOnce = %d, %v; want 5 (half the victim's queue)", n, err)
+	}
+	if p.thiefSched.QueueDepth() != 5 || p.victimSched.QueueDepth() != 5 {
+		t.Errorf("depths thief=%d victim=%d, want 5/5", p.thiefSched.QueueDepth(), p.victimSched.QueueDepth())
+	}
+	if owner, _ := p.victim.Owner("t0"); owner != "thief" {
+		t.Errorf("victim's owner of t0 = %q, want thief", owner)
+	}
+	if owner, _ := p.thief.Owner("t0"); owner != "thief" {
+		t.Errorf("thief's owner of t0 = %q, want thief", owner)
+	}
+
+	// A busy thief does not steal
+	if n, _ := p.thief.StealOnce(context.Background()); n != 0 {
+		t.Errorf("busy thief stole %d tasks", n)
+	}
+
+	ts, vs := p.thief.Stats(), p.victim.Stats()
+	if ts.Imported != 5 || ts.Attempts != 1 || vs.Granted != 5 || vs.HandedOff != 5 || vs.PendingGrants != 0 {
+		t.Errorf("thief stats %+v, victim stats %+v", ts, vs)
+	}
+}
+
+func TestStealer_LostConfirmIsReclaimed(t *testing.T) {
+	p := newStealPair(t, 4)
+	p.dropConfirm = true
+
+	if _, err := p.thief.StealOnce(context.Background()); err == nil {
+		t.Fatal("StealOnce succeeded without a confirmation")
+	}
+	if p.thiefSched.QueueDepth() != 0 {
+		t.Fatalf("thief imported %d unconfirmed tasks", p.thiefSched.QueueDepth())
+	}
+	if p.victimSched.QueueDepth() != 2 || p.victim.Stats().PendingGrants != 1 {
+		t.Fatalf("victim depth=%d stats=%+v, want 2 queued + 1 pending grant", p.victimSched.QueueDepth(), p.victim.Stats())
+	}
+
+	// The grant expires and its tasks return to the victim
+	p.clock = p.clock.Add(31 * time.Second)
+	if n := p.victim.Reclaim(); n != 2 {
+		t.Errorf("Reclaim = %d, want 2", n)
+	}
+	if p.victimSched.QueueDepth() != 4 {
+		t.Errorf("victim depth = %d after reclaim, want 4", p.victimSched.QueueDepth())
+	}
+}
+
+func TestStealer_Confirm(t *testing.T) {
+	p := newStealPair(t, 4)
+	g, err := p.victim.Grant(StealRequest{Thief: "thief", Max: 3})
+	if err != nil || len(g.Tasks) != 3 {
+		t.Fatalf("Grant = %+v, %v", g, err)
+	}
+	if _, err := p.victim.Grant(StealRequest{Thief: "victim"}); !errors.Is(err, domain.ErrStealInvalid) {
+		t.Errorf("self-steal err = %v, want ErrStealInvalid", err)
+	}
+
+	tests := []struct {
+		name string
+		c    StealConfirm
+		want error
+	}{
+		{"unknown grant", StealConfirm{GrantID: "nope", Thief: "thief"}, domain.ErrStealGrantNotFound},
+		{"wrong thief", StealConfirm{GrantID: g.ID, Thief: "other"}, domain.ErrStealGrantNotFound},
+		{"partial accept", StealConfirm{GrantID: g.ID, Thief: "thief", Accepted: []string{g.Tasks[0].Task.ID}}, nil},
+		{"idempotent retry", StealConfirm{GrantID: g.ID, Thief: "thief", Accepted: []string{g.Tasks[0].Task.ID}}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := p.victim.Confirm(tt.c); !errors.Is(err, tt.want) {
+				t.Errorf("Confirm err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+	// Two refused tasks went back into the queue
+	if p.victimSched.QueueDepth() != 3 {
+		t.Errorf("victim depth = %d, want 3", p.victimSched.QueueDepth())
+	}
+
+	// A confirmation after expiry is rejected and the tasks stay with the victim
+	late, _ := p.victim.Grant(StealRequest{Thief: "thief", Max: 1})
+	p.clock = p.clock.Add(time.Minute)
+	err = p.victim.Confirm(StealConfirm{GrantID: late.ID, Thief: "thief", Accepted: []string{late.Tasks[0].Task.ID}})
+	if !errors.Is(err, domain.ErrStealGrantExpired) {
+		t.Errorf("late Confirm err = %v, want ErrStealGrantExpired", err)
+	}
+	if _, ok := p.victim.Owner(late.Tasks[0].Task.ID); ok {
+		t.Error("expired grant transferred ownership")
+	}
+	if p.victimSched.QueueDepth() != 3 {
+		t.Errorf("victim depth = %d after late confirm, want 3", p.victimSched.QueueDepth())
+	}
+}
+
+func TestStealer_RefusesOwnedTasks(t *testing.T) {
+	p := newStealPair(t, 4)
+	// The thief already took t0 earlier (e.g. a re-delivered grant)
+	p.thief.mu.Lock()
+	p.thief.recordOwnerLocked("t0", "thief")
+	p.thief.mu.Unlock()
+
+	n, err := p.thief.StealOnce(context.Background())
+	if err != nil || n != 1 {
+		t.Fatalf("StealOnce = %d, %v; want 1", n, err)
+	}
+	if p.thief.Stats().Duplicates != 1 {
+		t.Errorf("Duplicates = %d, want 1", p.thief.Stats().Duplicates)
+	}
+	// The duplicate stays with the victim
+	if p.victimSched.QueueDepth() != 3 {
+		t.Errorf("victim depth = %d, want 3", p.victimSched.QueueDepth())
+	}
+}
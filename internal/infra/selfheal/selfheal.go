@@ -34,6 +34,8 @@ import (
 	"fmt"
 	"sync"
 	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
 )
 
 // ─── Configuration ──────────────────────────────────────────────────────────
@@ -115,18 +117,21 @@ func (s IncidentState) IsTerminal() bool {
 
 // ─── Failure Type ───────────────────────────────────────────────────────────
 
-// FailureType classifies the type of detected problem.
-type FailureType string
+// FailureType classifies the type of detected problem. It is the shared
+// domain taxonomy, so runbooks answer to the same names as anomaly reports
+// and federated health patterns.
+type FailureType = domain.FailureType
 
 const (
-	FailHighErrorRate   FailureType = "HIGH_ERROR_RATE"   // Sudden spike in task failures
-	FailCPUOverload     FailureType = "CPU_OVERLOAD"      // Node CPU consistently >95%
-	FailMemoryExhausted FailureType = "MEMORY_EXHAUSTED"  // Node memory >95%
-	FailDiskFull        FailureType = "DISK_FULL"         // Node disk >95%
-	FailNetworkPartial  FailureType = "NETWORK_PARTITION" // Node intermittently unreachable
-	FailGPUError        FailureType = "GPU_ERROR"         // GPU not responding
-	FailModelCorrupt    FailureType = "MODEL_CORRUPT"     // Model integrity check failed
-	FailHeartbeatLost   FailureType = "HEARTBEAT_LOST"    // Node stopped sending heartbeats
+	FailHighErrorRate   = domain.FailureHighErrorRate
+	FailCPUOverload     = domain.FailureCPUOverload
+	FailMemoryExhausted = domain.FailureMemoryExhausted
+	FailDiskFull        = domain.FailureDiskFull
+	FailNetworkPartial  = domain.FailureNetworkPartition
+	FailGPUError        = domain.FailureGPUError
+	FailModelCorrupt    = domain.FailureModelCorrupt
+	FailHeartbeatLost   = domain.FailureHeartbeatLost
+	FailThermalThrottle = domain.FailureThermalThrottle
 )
 
 // ─── Runbook ────────────────────────────────────────────────────────────────
@@ -220,6 +225,15 @@ func DefaultRunbooks() map[FailureType]Runbook {
 				{Name: "notify_cluster", Description: "Broadcast node death to cluster"},
 			},
 		},
+		FailThermalThrottle: {
+			FailureType: FailThermalThrottle,
+			DrainFirst:  true,
+			Actions: []RunbookAction{
+				{Name: "drain_fine_tune", Description: "Stop fine-tune shards running on the node"},
+				{Name: "advertise_throttle", Description: "Flag the node as throttled in heartbeats"},
+				{Name: "wait_cooldown", Description: "Wait for sustained temperature to drop"},
+			},
+		},
 	}
 }
 
@@ -267,6 +281,16 @@ type Mesh struct {
 	totalMTTR    time.Duration
 	resolvedCnt  int64
 	escalatedCnt int64
+
+	onResolved func(Incident) // called after an incident is auto-resolved
+}
+
+// OnResolved registers fn to be called, outside the mesh lock, with each
+// incident that remediation resolved (not escalated ones).
+func (m *Mesh) OnResolved(fn func(Incident)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onResolved = fn
 }
 
 // NewMesh creates a new autonomous self-healing mesh.
@@ -415,14 +439,24 @@ func (m *Mesh) RecordActionComplete(incidentID, actionName string) error {
 // problem is actually fixed. Pass `healthy=true` if verification succeeded.
 func (m *Mesh) Verify(incidentID string, healthy bool) error {
 	m.mu.Lock()
-	defer m.mu.Unlock()
+	resolved, err := m.verifyLocked(incidentID, healthy)
+	hook := m.onResolved
+	m.mu.Unlock()
+	if resolved != nil && hook != nil {
+		hook(*resolved)
+	}
+	return err
+}
 
+// verifyLocked applies a verification result. It returns a copy of the
+// incident when this resolved it. Must be called with m.mu held.
+func (m *Mesh) verifyLocked(incidentID string, healthy bool) (*Incident, error) {
 	inc, ok := m.active[incidentID]
 	if !ok {
-		return fmt.Errorf("incident %s not found", incidentID)
+		return nil, fmt.Errorf("incident %s not found", incidentID)
 	}
 	if inc.State != StateRemediating {
-		return fmt.Errorf("incident %s in state %s, expected REMEDIATING", incidentID, inc.State)
+		return nil, fmt.Errorf("incident %s in state %s, expected REMEDIATING", incidentID, inc.State)
 	}
 
 	now := m.cfg.Now()
@@ -437,7 +471,8 @@ func (m *Mesh) Verify(incidentID string, healthy bool) error {
 		m.totalMTTR += inc.MTTR
 		m.resolvedCnt++
 		m.finalizeLocked(inc)
-		return nil
+		resolved := *inc
+		return &resolved, nil
 	}
 
 	// Fix didn't work — retry or escalate.
@@ -448,13 +483,13 @@ func (m *Mesh) Verify(incidentID string, healthy bool) error {
 		inc.MTTR = now.Sub(inc.DetectedAt)
 		m.escalatedCnt++
 		m.finalizeLocked(inc)
-		return nil
+		return nil, nil
 	}
 
 	// Return to isolating for another attempt.
 	inc.State = StateIsolating
 	inc.IsolatedAt = now
-	return nil
+	return nil, nil
 }
 
 // finalizeLocked moves an incident from active to resolved history.
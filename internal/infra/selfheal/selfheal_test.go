@@ -36,7 +36,7 @@ func TestNewMesh_DefaultRunbooks(t *testing.T) {
 	expectedTypes := []FailureType{
 		FailHighErrorRate, FailCPUOverload, FailMemoryExhausted,
 		FailDiskFull, FailNetworkPartial, FailGPUError,
-		FailModelCorrupt, FailHeartbeatLost,
+		FailModelCorrupt, FailHeartbeatLost, FailThermalThrottle,
 	}
 	for _, ft := range expectedTypes {
 		if _, ok := rbs[ft]; !ok {
@@ -179,6 +179,29 @@ func TestVerify_Resolved(t *testing.T) {
 	}
 }
 
+func TestOnResolved(t *testing.T) {
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	cfg := testConfig(base)
+	cfg.MaxRemediationAttempts = 1
+	m := NewMesh(cfg)
+	var got []Incident
+	m.OnResolved(func(inc Incident) { got = append(got, inc) })
+
+	ok, _ := m.Detect("node-1", FailHighErrorRate)
+	m.Isolate(ok.ID, 0)
+	m.Remediate(ok.ID)
+	m.Verify(ok.ID, true)
+
+	bad, _ := m.Detect("node-2", FailHighErrorRate)
+	m.Isolate(bad.ID, 0)
+	m.Remediate(bad.ID)
+	m.Verify(bad.ID, false) // escalated
+
+	if len(got) != 1 || got[0].ID != ok.ID || got[0].State != StateResolved {
+		t.Errorf("resolved hook calls = %+v, want only %s", got, ok.ID)
+	}
+}
+
 func TestVerify_RetryThenEscalate(t *testing.T) {
 	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
 	cfg := testConfig(base)
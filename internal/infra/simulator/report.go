@@ -0,0 +1,188 @@
+package simulator
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// ─── Report ─────────────────────────────────────────────────────────────────
+
+// Report is the outcome of a run: what each component did and whether it
+// met its gate.
+type Report struct {
+	Seed          int64         `json:"seed"`
+	Nodes         int           `json:"nodes"`
+	SimulatedTime time.Duration `json:"simulated_time"`
+	Steps         int           `json:"steps"`
+	Tasks         int64         `json:"tasks"`
+	Failures      int64         `json:"failures"` // ML-scheduled tasks that failed and were retried
+
+	Scheduler  SchedulerReport  `json:"scheduler"`
+	Autoscaler AutoscalerReport `json:"autoscaler"`
+	Optimizer  OptimizerReport  `json:"optimizer"`
+
+	Gates  []GateResult `json:"gates"`
+	Passed bool         `json:"passed"` // every gate passed
+}
+
+// SchedulerReport compares the ML scheduler with the heuristic baseline.
+type SchedulerReport struct {
+	MLAvgLatencyMs        float64 `json:"ml_avg_latency_ms"`
+	HeuristicAvgLatencyMs float64 `json:"heuristic_avg_latency_ms"`
+	MLP95LatencyMs        float64 `json:"ml_p95_latency_ms"`
+	HeuristicP95LatencyMs float64 `json:"heuristic_p95_latency_ms"`
+	ImprovementPct        float64 `json:"improvement_pct"`
+	GiniCoefficient       float64 `json:"gini_coefficient"`
+	Arms                  int     `json:"arms"`
+}
+
+// AutoscalerReport summarizes the scaler's spike handling.
+type AutoscalerReport struct {
+	TotalSpikes           int64   `json:"total_spikes"`
+	ProactiveSpikes       int64   `json:"proactive_spikes"`
+	ProactivePct          float64 `json:"proactive_pct"`
+	UnderProvisionedSteps int     `json:"under_provisioned_steps"` // demand above capacity
+	Surges                int     `json:"surges"`                  // workload surges after the first
+	SurgesPrewarmed       int     `json:"surges_prewarmed"`        // of those, met by capacity already in place
+	FinalCapacity         int     `json:"final_capacity"`
+}
+
+// OptimizerReport summarizes the placement optimizer.
+type OptimizerReport struct {
+	Optimizations   int64 `json:"optimizations"`
+	Recommendations int   `json:"recommendations"`
+	TrackedModels   int   `json:"tracked_models"`
+	TrackedNodes    int   `json:"tracked_nodes"`
+}
+
+// GateResult is one gate check.
+type GateResult struct {
+	Name   string  `json:"name"`
+	Target float64 `json:"target"`
+	Actual float64 `json:"actual"`
+	Passed bool    `json:"passed"`
+}
+
+// Gate names.
+const (
+	GateMLImprovement = "ml_scheduler_improvement_pct"
+	GateProactive     = "autoscaler_proactive_pct"
+	GateOptimizer     = "optimizer_recommendations"
+)
+
+// finish collects the components' stats into the report.
+func (s *sim) finish(steps int) {
+	r := &s.report
+	r.Seed = s.cfg.Seed
+	r.Nodes = s.cfg.Nodes
+	r.SimulatedTime = time.Duration(steps) * s.cfg.Step
+	r.Steps = steps
+
+	ml := s.scheduler.Stats()
+	r.Scheduler = SchedulerReport{
+		MLAvgLatencyMs:        ml.MLAvgLatencyMs,
+		HeuristicAvgLatencyMs: ml.HeurAvgLatencyMs,
+		MLP95LatencyMs:        percentile(s.mlLatency, 0.95),
+		HeuristicP95LatencyMs: percentile(s.heurLatency, 0.95),
+		ImprovementPct:        ml.ImprovementPct,
+		GiniCoefficient:       ml.GiniCoefficient,
+		Arms:                  ml.UniqueArms,
+	}
+
+	sc := s.scaler.Stats()
+	r.Autoscaler.TotalSpikes = sc.TotalSpikes
+	r.Autoscaler.ProactiveSpikes = sc.ProactiveSpikes
+	r.Autoscaler.ProactivePct = sc.ProactivePct
+	r.Autoscaler.FinalCapacity = sc.CurrentCapacity
+
+	op := s.optimizer.Stats()
+	r.Optimizer = OptimizerReport{
+		Optimizations:   op.TotalOptimizations,
+		Recommendations: op.TotalRecommendations,
+		TrackedModels:   op.TrackedModels,
+		TrackedNodes:    op.TrackedNodes,
+	}
+
+	r.Gates = []GateResult{
+		{
+			Name:   GateMLImprovement,
+			Target: s.cfg.MinImprovementPct,
+			Actual: ml.ImprovementPct,
+			Passed: s.scheduler.GatePassed(s.cfg.MinImprovementPct),
+		},
+		{
+			Name:   GateProactive,
+			Target: s.cfg.MinProactivePct,
+			Actual: sc.ProactivePct,
+			Passed: s.scaler.GatePassed(s.cfg.MinProactivePct),
+		},
+		{
+			Name:   GateOptimizer,
+			Target: 1,
+			Actual: float64(op.TotalRecommendations),
+			Passed: s.optimizer.GatePassed() && op.TotalRecommendations > 0,
+		},
+	}
+	r.Passed = true
+	for _, g := range r.Gates {
+		r.Passed = r.Passed && g.Passed
+	}
+}
+
+// Gate returns the named gate's result.
+func (r Report) Gate(name string) (GateResult, bool) {
+	for _, g := range r.Gates {
+		if g.Name == name {
+			return g, true
+		}
+	}
+	return GateResult{}, false
+}
+
+// WriteText writes a human-readable summary of r.
+func (r Report) WriteText(w io.Writer) error {
+	_, err := fmt.Fprintf(w, `Simulated %s over %d nodes (seed %d): %d tasks, %d failed
+
+ML scheduler   avg %.0f ms (p95 %.0f) vs heuristic avg %.0f ms (p95 %.0f)
+               improvement %.1f%%, gini %.2f, %d arms
+Autoscaler     %d spikes, %d proactive (%.1f%%), %d under-provisioned steps, capacity %d
+               %d of %d surges met by pre-warmed capacity
+Optimizer      %d cycles, %d recommendations, %d models on %d nodes
+
+`,
+		r.SimulatedTime, r.Nodes, r.Seed, r.Tasks, r.Failures,
+		r.Scheduler.MLAvgLatencyMs, r.Scheduler.MLP95LatencyMs,
+		r.Scheduler.HeuristicAvgLatencyMs, r.Scheduler.HeuristicP95LatencyMs,
+		r.Scheduler.ImprovementPct, r.Scheduler.GiniCoefficient, r.Scheduler.Arms,
+		r.Autoscaler.TotalSpikes, r.Autoscaler.ProactiveSpikes, r.Autoscaler.ProactivePct,
+		r.Autoscaler.UnderProvisionedSteps, r.Autoscaler.FinalCapacity,
+		r.Autoscaler.SurgesPrewarmed, r.Autoscaler.Surges,
+		r.Optimizer.Optimizations, r.Optimizer.Recommendations,
+		r.Optimizer.TrackedModels, r.Optimizer.TrackedNodes)
+	if err != nil {
+		return err
+	}
+	for _, g := range r.Gates {
+		status := "PASS"
+		if !g.Passed {
+			status = "FAIL"
+		}
+		if _, err := fmt.Fprintf(w, "  [%s] %-30s %8.1f (target %.1f)\n", status, g.Name, g.Actual, g.Target); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// percentile returns the p-th quantile of values (0 when empty).
+func percentile(values []float64, p float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+	return sorted[int(p*float64(len(sorted)-1))]
+}
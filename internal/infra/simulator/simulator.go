@@ -0,0 +1,548 @@
+// Package simulator exercises the scheduling stack against a virtual fleet.
+//
+// The Phase 6 gate checks — the ML scheduler beating the heuristic by 30%
+// on latency, the auto-scaler handling 90% of demand spikes before they
+// hit, the optimizer producing placement recommendations — cannot be
+// verified without a fleet to schedule onto. The simulator builds one:
+// hundreds of virtual nodes with latency, failure and capacity drawn from
+// configurable distributions, driven by a synthetic diurnal workload with
+// periodic surges. Time is virtual, so days of traffic run in seconds, and
+// every random draw comes from one seed, so a run is reproducible.
+//
+// Each task is offered to both the ML scheduler and the heuristic baseline
+// over the same candidate nodes. The two policies run on separate copies
+// of the fleet — each sees the load and model cache its own choices
+// produced — and share the task's random draws, so the difference in
+// latency comes from the choices alone.
+package simulator
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"slices"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/infra/autoscale"
+	"github.com/tutu-network/tutu/internal/infra/intelligence"
+	"github.com/tutu-network/tutu/internal/infra/mlscheduler"
+)
+
+// ─── Configuration ──────────────────────────────────────────────────────────
+
+// Config describes the fleet, the workload and the gate targets.
+type Config struct {
+	Seed  int64 // every random draw derives from it; 0 is a valid seed
+	Nodes int   // virtual nodes (default 300)
+
+	// Simulated time: Duration of traffic in steps of Step, starting at
+	// Start (defaults 72h, 1m, 2025-01-06 00:00 UTC).
+	Duration time.Duration
+	Step     time.Duration
+	Start    time.Time
+
+	// Fleet distributions.
+	LatencyMeanMs     float64 // mean network latency to a node (log-normal, default 60)
+	LatencySpread     float64 // σ of the latency's log (default 0.6)
+	FailureRate       float64 // mean per-task failure probability (exponential, default 0.02)
+	GPUFraction       float64 // share of nodes with a GPU (default 0.35)
+	MinSlots          int     // concurrent tasks per node, uniform in [MinSlots, MaxSlots]
+	MaxSlots          int     // (defaults 1, 8)
+	ThrottleRate      float64 // share of nodes thermally throttled at any time (default 0.05)
+	Models            []string
+	CandidatesPerTask int // nodes offered per scheduling decision (default 8)
+
+	// Workload: TasksPerStep on average, shaped by a daily cycle of
+	// ± DiurnalAmplitude and a surge of SpikeFactor × for SpikeLength
+	// every SpikeEvery.
+	TasksPerStep     float64 // default 40
+	DiurnalAmplitude float64 // default 0.5
+	SpikeEvery       time.Duration
+	SpikeLength      time.Duration
+	SpikeFactor      float64 // defaults 24h, 1h, 2
+
+	// OptimizeEvery is how often the placement optimizer runs (default 24h).
+	OptimizeEvery time.Duration
+
+	// Gate targets.
+	MinImprovementPct float64 // default 30
+	MinProactivePct   float64 // default 90
+}
+
+// DefaultConfig returns a three-day run over 300 nodes.
+func DefaultConfig() Config {
+	return Config{
+		Nodes:             300,
+		Duration:          72 * time.Hour,
+		Step:              time.Minute,
+		Start:             time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC),
+		LatencyMeanMs:     60,
+		LatencySpread:     0.6,
+		FailureRate:       0.02,
+		GPUFraction:       0.35,
+		MinSlots:          1,
+		MaxSlots:          8,
+		ThrottleRate:      0.05,
+		Models:            []string{"llama3", "mistral", "phi3", "gemma2", "qwen2.5", "tinyllama", "smollm2", "llama3:8b"},
+		CandidatesPerTask: 8,
+		TasksPerStep:      40,
+		DiurnalAmplitude:  0.5,
+		SpikeEvery:        24 * time.Hour,
+		SpikeLength:       time.Hour,
+		SpikeFactor:       2,
+		OptimizeEvery:     24 * time.Hour,
+		MinImprovementPct: 30,
+		MinProactivePct:   90,
+	}
+}
+
+// withDefaults fills zero fields from DefaultConfig.
+func (c Config) withDefaults() Config {
+	d := DefaultConfig()
+	if c.Nodes <= 0 {
+		c.Nodes = d.Nodes
+	}
+	if c.Duration <= 0 {
+		c.Duration = d.Duration
+	}
+	if c.Step <= 0 {
+		c.Step = d.Step
+	}
+	if c.Start.IsZero() {
+		c.Start = d.Start
+	}
+	if c.LatencyMeanMs <= 0 {
+		c.LatencyMeanMs = d.LatencyMeanMs
+	}
+	if c.LatencySpread <= 0 {
+		c.LatencySpread = d.LatencySpread
+	}
+	if c.FailureRate <= 0 {
+		c.FailureRate = d.FailureRate
+	}
+	if c.GPUFraction <= 0 {
+		c.GPUFraction = d.GPUFraction
+	}
+	if c.MinSlots <= 0 {
+		c.MinSlots = d.MinSlots
+	}
+	if c.MaxSlots < c.MinSlots {
+		c.MaxSlots = max(c.MinSlots, d.MaxSlots)
+	}
+	if c.ThrottleRate <= 0 {
+		c.ThrottleRate = d.ThrottleRate
+	}
+	if len(c.Models) == 0 {
+		c.Models = d.Models
+	}
+	if c.CandidatesPerTask <= 0 {
+		c.CandidatesPerTask = d.CandidatesPerTask
+	}
+	if c.TasksPerStep <= 0 {
+		c.TasksPerStep = d.TasksPerStep
+	}
+	if c.DiurnalAmplitude <= 0 {
+		c.DiurnalAmplitude = d.DiurnalAmplitude
+	}
+	if c.SpikeEvery <= 0 {
+		c.SpikeEvery = d.SpikeEvery
+	}
+	if c.SpikeLength <= 0 {
+		c.SpikeLength = d.SpikeLength
+	}
+	if c.SpikeFactor <= 0 {
+		c.SpikeFactor = d.SpikeFactor
+	}
+	if c.OptimizeEvery <= 0 {
+		c.OptimizeEvery = d.OptimizeEvery
+	}
+	if c.MinImprovementPct <= 0 {
+		c.MinImprovementPct = d.MinImprovementPct
+	}
+	if c.MinProactivePct <= 0 {
+		c.MinProactivePct = d.MinProactivePct
+	}
+	return c
+}
+
+// validate rejects configurations the simulation cannot run.
+func (c Config) validate() error {
+	switch {
+	case c.Duration < c.Step:
+		return fmt.Errorf("simulator: duration %s is shorter than one step (%s)", c.Duration, c.Step)
+	case c.FailureRate >= 1:
+		return fmt.Errorf("simulator: failure rate must be below 1, got %g", c.FailureRate)
+	case c.GPUFraction > 1 || c.ThrottleRate > 1:
+		return fmt.Errorf("simulator: gpu fraction and throttle rate must be at most 1")
+	case c.DiurnalAmplitude >= 1:
+		return fmt.Errorf("simulator: diurnal amplitude must be below 1, got %g", c.DiurnalAmplitude)
+	}
+	return nil
+}
+
+// ─── Virtual Fleet ──────────────────────────────────────────────────────────
+
+// Service-time model, in milliseconds. Compute dominates network latency:
+// a GPU node with the model loaded answers in a couple of hundred
+// milliseconds, a CPU node takes several times longer, and loading a cold
+// model adds a fixed cost. Queueing stretches service time as the node
+// fills up.
+const (
+	gpuServiceMs    = 120.0
+	cpuServiceMs    = 420.0
+	coldLoadMs      = 350.0
+	throttleFactor  = 1.6
+	queuePenalty    = 1.5  // service × (1 + queuePenalty × load²)
+	failurePenalty  = 1000 // a failed task is retried elsewhere after a timeout
+	taskDurationMin = 1.0  // steps a task occupies its slot
+)
+
+// node is the fixed description of a virtual node.
+type node struct {
+	id          string
+	latencyMs   float64
+	failureRate float64
+	slots       int
+	gpu         bool
+	vramGB      float64
+	reputation  float64
+	creditRate  float64
+	modelSlots  int
+	throttled   bool
+}
+
+// nodeState is what one policy's choices did to a node.
+type nodeState struct {
+	busy float64  // slot-steps of work outstanding
+	hot  []string // loaded models, least recently used first
+}
+
+func (st *nodeState) isHot(model string) bool {
+	for _, m := range st.hot {
+		if m == model {
+			return true
+		}
+	}
+	return false
+}
+
+// touch marks model as loaded and most recently used, evicting the least
+// recently used model beyond capacity.
+func (st *nodeState) touch(model string, capacity int) {
+	for i, m := range st.hot {
+		if m == model {
+			st.hot = append(st.hot[:i], st.hot[i+1:]...)
+			break
+		}
+	}
+	st.hot = append(st.hot, model)
+	if len(st.hot) > capacity {
+		st.hot = st.hot[len(st.hot)-capacity:]
+	}
+}
+
+// fleet is one policy's copy of the fleet state.
+type fleet []nodeState
+
+func (f fleet) load(i int, n *node) float64 {
+	return math.Min(f[i].busy/float64(n.slots), 1)
+}
+
+// drain completes one step of work on every node.
+func (f fleet) drain(nodes []node) {
+	for i := range f {
+		f[i].busy = math.Max(0, f[i].busy-float64(nodes[i].slots))
+	}
+}
+
+func buildNodes(cfg Config, rng *rand.Rand) ([]node, fleet, fleet) {
+	nodes := make([]node, cfg.Nodes)
+	ml, heur := make(fleet, cfg.Nodes), make(fleet, cfg.Nodes)
+	// Log-normal with the configured mean: μ = ln(mean) − σ²/2.
+	mu := math.Log(cfg.LatencyMeanMs) - cfg.LatencySpread*cfg.LatencySpread/2
+	for i := range nodes {
+		n := node{
+			id:          fmt.Sprintf("sim-%04d", i),
+			latencyMs:   math.Exp(mu + cfg.LatencySpread*rng.NormFloat64()),
+			failureRate: math.Min(rng.ExpFloat64()*cfg.FailureRate, 0.5),
+			slots:       cfg.MinSlots + rng.Intn(cfg.MaxSlots-cfg.MinSlots+1),
+			gpu:         rng.Float64() < cfg.GPUFraction,
+			reputation:  0.5 + 0.5*rng.Float64(),
+			creditRate:  5 + 20*rng.Float64(),
+			modelSlots:  1,
+			throttled:   rng.Float64() < cfg.ThrottleRate,
+		}
+		if n.gpu {
+			n.vramGB = []float64{8, 12, 16, 24}[rng.Intn(4)]
+			n.modelSlots = int(n.vramGB / 8)
+			n.creditRate *= 1.5
+		}
+		nodes[i] = n
+		// Both copies start with the same model loaded.
+		first := cfg.Models[rng.Intn(len(cfg.Models))]
+		ml[i].hot = []string{first}
+		heur[i].hot = []string{first}
+	}
+	return nodes, ml, heur
+}
+
+// ─── Run ────────────────────────────────────────────────────────────────────
+
+// task is one unit of synthetic work and its shared random draws.
+type task struct {
+	model      string
+	taskType   string
+	priority   int
+	candidates []int
+	noise      float64 // multiplicative latency noise
+	failDraw   float64 // compared against the chosen node's failure rate
+}
+
+// sim holds a run's state.
+type sim struct {
+	cfg   Config
+	rng   *rand.Rand
+	now   time.Time
+	nodes []node
+	ml    fleet
+	heur  fleet
+
+	scheduler *mlscheduler.Scheduler
+	scaler    *autoscale.Scaler
+	optimizer *intelligence.Optimizer
+
+	report       Report
+	mlLatency    []float64
+	heurLatency  []float64
+	lastOptimize time.Time
+}
+
+// Run simulates cfg and returns the report. Zero config fields take their
+// defaults.
+func Run(cfg Config) (Report, error) {
+	cfg = cfg.withDefaults()
+	if err := cfg.validate(); err != nil {
+		return Report{}, err
+	}
+	s := &sim{cfg: cfg, rng: rand.New(rand.NewSource(cfg.Seed)), now: cfg.Start}
+	clock := func() time.Time { return s.now }
+
+	mlCfg := mlscheduler.DefaultConfig()
+	mlCfg.Now = clock
+	s.scheduler = mlscheduler.NewScheduler(mlCfg)
+
+	scCfg := autoscale.DefaultConfig()
+	scCfg.Now = clock
+	scCfg.MaxCapacity = cfg.Nodes
+	s.scaler = autoscale.NewScaler(scCfg)
+
+	opCfg := intelligence.DefaultConfig()
+	opCfg.Now = clock
+	s.optimizer = intelligence.NewOptimizer(opCfg)
+
+	s.nodes, s.ml, s.heur = buildNodes(cfg, s.rng)
+	s.scaler.SetCapacity(max(1, int(cfg.TasksPerStep/s.meanSlots())))
+	s.lastOptimize = cfg.Start
+
+	steps := int(cfg.Duration / cfg.Step)
+	for i := 0; i < steps; i++ {
+		s.step()
+		s.now = s.now.Add(cfg.Step)
+	}
+	s.finish(steps)
+	return s.report, nil
+}
+
+// step runs one step of simulated time.
+func (s *sim) step() {
+	demand := s.demand(s.now)
+	if s.surgeStart(s.now) {
+		// Ground truth for the proactive gate: was the surge's capacity
+		// already there when it arrived?
+		s.report.Autoscaler.Surges++
+		if float64(s.scaler.Capacity()) >= demand/s.meanSlots() {
+			s.report.Autoscaler.SurgesPrewarmed++
+		}
+	}
+	arrivals := poisson(s.rng, demand)
+	for i := 0; i < arrivals; i++ {
+		s.schedule(s.newTask())
+	}
+
+	// The scaler sees demand in nodes' worth of work, as it is sized.
+	nodesNeeded := float64(arrivals) / s.meanSlots()
+	s.scaler.RecordDemand(autoscale.Sample{Demand: nodesNeeded, Timestamp: s.now})
+	s.scaler.Evaluate()
+	if nodesNeeded > float64(s.scaler.Capacity()) {
+		s.report.Autoscaler.UnderProvisionedSteps++
+	}
+
+	if s.now.Sub(s.lastOptimize) >= s.cfg.OptimizeEvery {
+		s.optimizer.Optimize()
+		s.lastOptimize = s.now
+	}
+
+	s.ml.drain(s.nodes)
+	s.heur.drain(s.nodes)
+}
+
+// demand is the expected number of arrivals in the step starting at t.
+func (s *sim) demand(t time.Time) float64 {
+	hour := float64(t.Hour()) + float64(t.Minute())/60
+	// Peak mid-afternoon, trough before dawn.
+	d := s.cfg.TasksPerStep * (1 + s.cfg.DiurnalAmplitude*math.Sin((hour-9)/24*2*math.Pi))
+	if t.Sub(s.cfg.Start)%s.cfg.SpikeEvery < s.cfg.SpikeLength {
+		d *= s.cfg.SpikeFactor
+	}
+	return d
+}
+
+// surgeStart reports whether a surge begins in the step starting at t. The
+// first surge, at the very start of the run, has nothing to predict it
+// from and is not counted.
+func (s *sim) surgeStart(t time.Time) bool {
+	since := t.Sub(s.cfg.Start)
+	return since >= s.cfg.SpikeEvery && since%s.cfg.SpikeEvery < s.cfg.Step
+}
+
+var taskTypes = []string{"INFERENCE", "INFERENCE", "INFERENCE", "EMBEDDING", "AGENT", "FINE_TUNE"}
+
+func (s *sim) newTask() task {
+	t := task{
+		model:    s.pickModel(),
+		taskType: taskTypes[s.rng.Intn(len(taskTypes))],
+		priority: s.rng.Intn(5),
+		noise:    math.Exp(0.15 * s.rng.NormFloat64()),
+		failDraw: s.rng.Float64(),
+	}
+	k := min(s.cfg.CandidatesPerTask, len(s.nodes))
+	t.candidates = make([]int, 0, k)
+	for len(t.candidates) < k {
+		c := s.rng.Intn(len(s.nodes))
+		if !slices.Contains(t.candidates, c) {
+			t.candidates = append(t.candidates, c)
+		}
+	}
+	return t
+}
+
+// pickModel draws a model with Zipf-like popularity: the first model in
+// the list is the most requested.
+func (s *sim) pickModel() string {
+	var total float64
+	for i := range s.cfg.Models {
+		total += 1 / float64(i+1)
+	}
+	r := s.rng.Float64() * total
+	for i, m := range s.cfg.Models {
+		r -= 1 / float64(i+1)
+		if r <= 0 {
+			return m
+		}
+	}
+	return s.cfg.Models[len(s.cfg.Models)-1]
+}
+
+// schedule offers t to both policies and records the outcomes.
+func (s *sim) schedule(t task) {
+	s.report.Tasks++
+
+	heurFeat := s.features(t, s.heur)
+	best := 0
+	for i := range heurFeat {
+		if mlscheduler.HeuristicScore(heurFeat[i]) > mlscheduler.HeuristicScore(heurFeat[best]) {
+			best = i
+		}
+	}
+	latency, _ := s.execute(t, t.candidates[best], s.heur)
+	s.scheduler.RecordHeuristicBaseline(latency)
+	s.heurLatency = append(s.heurLatency, latency)
+
+	chosen, arm := s.scheduler.SelectNode(s.features(t, s.ml))
+	idx := t.candidates[0]
+	for _, c := range t.candidates {
+		if s.nodes[c].id == chosen.NodeID {
+			idx = c
+		}
+	}
+	latency, failed := s.execute(t, idx, s.ml)
+	n := &s.nodes[idx]
+	s.scheduler.RecordOutcome(arm, n.id, latency, n.creditRate)
+	s.optimizer.RecordRequest(t.model, n.id, latency, chosen.HasModelHot)
+	s.mlLatency = append(s.mlLatency, latency)
+	if failed {
+		s.report.Failures++
+	}
+}
+
+// features describes t's candidates as they stand in f.
+func (s *sim) features(t task, f fleet) []mlscheduler.Features {
+	out := make([]mlscheduler.Features, len(t.candidates))
+	for i, c := range t.candidates {
+		n := &s.nodes[c]
+		out[i] = mlscheduler.Features{
+			NodeID:       n.id,
+			TaskType:     t.taskType,
+			Priority:     t.priority,
+			NodeLoad:     f.load(c, n),
+			LatencyMs:    n.latencyMs,
+			HasModelHot:  f[c].isHot(t.model),
+			GPUAvailable: n.gpu && f.load(c, n) < 1,
+			VRAMGB:       n.vramGB,
+			Reputation:   n.reputation,
+			CreditRate:   n.creditRate,
+			QueueDepth:   int(f[c].busy),
+			Throttled:    n.throttled,
+		}
+	}
+	return out
+}
+
+// execute runs t on node i of f and returns its end-to-end latency.
+func (s *sim) execute(t task, i int, f fleet) (latencyMs float64, failed bool) {
+	n := &s.nodes[i]
+	service := cpuServiceMs
+	if n.gpu {
+		service = gpuServiceMs
+	}
+	if !f[i].isHot(t.model) {
+		service += coldLoadMs
+	}
+	if n.throttled {
+		service *= throttleFactor
+	}
+	load := f.load(i, n)
+	service *= 1 + queuePenalty*load*load
+
+	latencyMs = (n.latencyMs + service) * t.noise
+	if t.failDraw < n.failureRate {
+		latencyMs += failurePenalty
+		failed = true
+	}
+	f[i].busy += taskDurationMin
+	f[i].touch(t.model, n.modelSlots)
+	return latencyMs, failed
+}
+
+func (s *sim) meanSlots() float64 {
+	return float64(s.cfg.MinSlots+s.cfg.MaxSlots) / 2
+}
+
+// poisson draws from a Poisson distribution with mean lambda (normal
+// approximation above 30).
+func poisson(rng *rand.Rand, lambda float64) int {
+	if lambda <= 0 {
+		return 0
+	}
+	if lambda > 30 {
+		return max(0, int(math.Round(lambda+math.Sqrt(lambda)*rng.NormFloat64())))
+	}
+	l, k, p := math.Exp(-lambda), 0, 1.0
+	for {
+		p *= rng.Float64()
+		if p <= l {
+			return k
+		}
+		k++
+	}
+}
@@ -0,0 +1,134 @@
+package simulator
+
+import (
+	"bytes"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// smallConfig is a quick run: a day and a half over 60 nodes.
+func smallConfig(seed int64) Config {
+	return Config{Seed: seed, Nodes: 60, Duration: 36 * time.Hour, Step: 2 * time.Minute, TasksPerStep: 10}
+}
+
+func TestRun_Deterministic(t *testing.T) {
+	a, err := Run(smallConfig(7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Run(smallConfig(7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(a, b) {
+		t.Errorf("same seed, different reports:\n%+v\n%+v", a, b)
+	}
+
+	c, err := Run(smallConfig(8))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Tasks == a.Tasks && c.Scheduler == a.Scheduler {
+		t.Error("different seeds produced identical runs")
+	}
+}
+
+func TestRun_Report(t *testing.T) {
+	cfg := smallConfig(1)
+	r, err := Run(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r.Steps != 36*30 || r.SimulatedTime != 36*time.Hour {
+		t.Errorf("steps = %d over %s", r.Steps, r.SimulatedTime)
+	}
+	// 10 tasks a step on average; the diurnal cycle averages out over whole
+	// days and the two surges add an hour's worth each.
+	if r.Tasks < 9000 || r.Tasks > 13000 {
+		t.Errorf("tasks = %d, want about 11000", r.Tasks)
+	}
+	if r.Scheduler.MLAvgLatencyMs <= 0 || r.Scheduler.HeuristicAvgLatencyMs <= 0 {
+		t.Errorf("scheduler latencies not recorded: %+v", r.Scheduler)
+	}
+	if r.Autoscaler.Surges != 1 {
+		t.Errorf("surges = %d, want 1 (the one at the start is not counted)", r.Autoscaler.Surges)
+	}
+	if r.Optimizer.Optimizations != 1 || r.Optimizer.TrackedNodes == 0 {
+		t.Errorf("optimizer = %+v, want one cycle over tracked nodes", r.Optimizer)
+	}
+
+	if len(r.Gates) != 3 {
+		t.Fatalf("gates = %+v", r.Gates)
+	}
+	passed := true
+	for _, g := range r.Gates {
+		passed = passed && g.Passed
+	}
+	if r.Passed != passed {
+		t.Errorf("Passed = %v, gates say %v", r.Passed, passed)
+	}
+	g, ok := r.Gate(GateMLImprovement)
+	if !ok || g.Target != 30 || g.Actual != r.Scheduler.ImprovementPct {
+		t.Errorf("ML gate = %+v", g)
+	}
+
+	var buf bytes.Buffer
+	if err := r.WriteText(&buf); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"ML scheduler", GateProactive, "surges met"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("text report missing %q:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestRun_FailureRateRaisesFailures(t *testing.T) {
+	low := smallConfig(3)
+	low.FailureRate = 0.01
+	high := smallConfig(3)
+	high.FailureRate = 0.2
+
+	lr, err := Run(low)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hr, err := Run(high)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hr.Failures <= lr.Failures*2 {
+		t.Errorf("failures: %d at 1%%, %d at 20%%", lr.Failures, hr.Failures)
+	}
+}
+
+func TestRun_InvalidConfig(t *testing.T) {
+	for name, cfg := range map[string]Config{
+		"duration under a step": {Duration: time.Second, Step: time.Minute},
+		"certain failure":       {FailureRate: 1},
+		"gpu fraction":          {GPUFraction: 1.5},
+		"diurnal amplitude":     {DiurnalAmplitude: 1},
+	} {
+		if _, err := Run(cfg); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestPoisson_Mean(t *testing.T) {
+	rng := rand.New(rand.NewSource(42))
+	for _, lambda := range []float64{3, 80} {
+		var sum int
+		const n = 5000
+		for i := 0; i < n; i++ {
+			sum += poisson(rng, lambda)
+		}
+		if mean := float64(sum) / n; mean < lambda*0.95 || mean > lambda*1.05 {
+			t.Errorf("poisson(%g) mean = %.2f", lambda, mean)
+		}
+	}
+}
@@ -27,8 +27,18 @@ func Open(dir string) (*DB, error) {
 	}
 
 	dbPath := filepath.Join(dir, "state.db")
-	dsn := dbPath + "?_journal_mode=WAL&_busy_timeout=5000&_foreign_keys=on"
+	return open(dbPath + "?_journal_mode=WAL&_busy_timeout=5000&_foreign_keys=on")
+}
+
+// OpenMemory creates a private in-memory database with the full schema.
+// It lives as long as the returned DB; meant for tests and in-process
+// clusters that need no files.
+func OpenMemory() (*DB, error) {
+	return open(":memory:?_foreign_keys=on")
+}
 
+// open connects to dsn and runs the migrations.
+func open(dsn string) (*DB, error) {
 	db, err := sql.Open("sqlite", dsn)
 	if err != nil {
 		return nil, fmt.Errorf("open sqlite: %w", err)
@@ -39,7 +49,8 @@ func Open(dir string) (*DB, error) {
 		return nil, fmt.Errorf("ping sqlite: %w", err)
 	}
 
-	// Connection pool settings for SQLite
+	// Connection pool settings for SQLite. A single connection also keeps
+	// an in-memory database alive: each connection would get its own.
 	db.SetMaxOpenConns(1) // SQLite is single-writer
 	db.SetMaxIdleConns(1)
 
@@ -62,6 +73,26 @@ func (d *DB) Ping() error {
 	return d.db.Ping()
 }
 
+// IntegrityCheck runs PRAGMA integrity_check and returns its report.
+// A healthy database reports a single "ok" row.
+func (d *DB) IntegrityCheck() ([]string, error) {
+	rows, err := d.db.Query("PRAGMA integrity_check")
+	if err != nil {
+		return nil, fmt.Errorf("integrity check: %w", err)
+	}
+	defer rows.Close()
+
+	var out []string
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			return nil, fmt.Errorf("integrity check: %w", err)
+		}
+		out = append(out, line)
+	}
+	return out, rows.Err()
+}
+
 // migrate runs idempotent schema migrations.
 func (d *DB) migrate() error {
 	migrations := []string{
@@ -216,14 +247,64 @@ func (d *DB) migrate() error {
 	// Append Phase 6 migrations — ML scheduler, predictive scaling, self-healing, intelligence
 	migrations = append(migrations, Phase6Migrations()...)
 
+	// Append Phase 7 migrations — attestations and later subsystems
+	migrations = append(migrations, Phase7Migrations()...)
+
 	for _, m := range migrations {
 		if _, err := d.db.Exec(m); err != nil {
 			return fmt.Errorf("migration failed: %w\nSQL: %s", err, m)
 		}
 	}
+
+	// Columns added to existing tables by later phases
+	for _, c := range Phase7Columns() {
+		if err := d.addColumn(c); err != nil {
+			return fmt.Errorf("migration failed: add %s.%s: %w", c.Table, c.Column, err)
+		}
+	}
 	return nil
 }
 
+// Column is a column added to an existing table after it was created.
+type Column struct {
+	Table  string
+	Column string
+	Def    string // type and constraints, e.g. "TEXT NOT NULL DEFAULT ''"
+	Index  bool   // also create idx_<table>_<column>
+}
+
+// addColumn adds c unless the table already has it. SQLite has no
+// ADD COLUMN IF NOT EXISTS, so the schema is checked first.
+func (d *DB) addColumn(c Column) error {
+	rows, err := d.db.Query(`SELECT name FROM pragma_table_info(?)`, c.Table)
+	if err != nil {
+		return err
+	}
+	exists := false
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return err
+		}
+		exists = exists || name == c.Column
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if !exists {
+		if _, err := d.db.Exec(fmt.Sprintf(`ALTER TABLE %s ADD COLUMN %s %s`, c.Table, c.Column, c.Def)); err != nil {
+			return err
+		}
+	}
+	if c.Index {
+		_, err = d.db.Exec(fmt.Sprintf(`CREATE INDEX IF NOT EXISTS idx_%s_%s ON %s(%s)`,
+			c.Table, c.Column, c.Table, c.Column))
+	}
+	return err
+}
+
 // ─── Model Repository ───────────────────────────────────────────────────────
 
 // UpsertModel inserts or updates a model record.
@@ -301,6 +382,19 @@ func (d *DB) TouchModel(name string) error {
 	return err
 }
 
+// SetModelPinned marks a model as pinned (never auto-evicted) or unpinned.
+func (d *DB) SetModelPinned(name string, pinned bool) error {
+	result, err := d.db.Exec(`UPDATE models SET pinned = ? WHERE name = ?`, pinned, name)
+	if err != nil {
+		return err
+	}
+	n, _ := result.RowsAffected()
+	if n == 0 {
+		return domain.ErrModelNotFound
+	}
+	return nil
+}
+
 // ─── Node Info ──────────────────────────────────────────────────────────────
 
 // SetNodeInfo stores a key-value pair in node_info.
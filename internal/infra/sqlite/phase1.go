@@ -3,6 +3,7 @@ package sqlite
 import (
 	"database/sql"
 	"fmt"
+	"math"
 	"time"
 
 	"github.com/tutu-network/tutu/internal/domain"
@@ -13,10 +14,10 @@ import (
 // InsertLedgerEntry adds a credit ledger entry.
 func (d *DB) InsertLedgerEntry(entry domain.LedgerEntry) (int64, error) {
 	result, err := d.db.Exec(
-		`INSERT INTO credit_ledger (timestamp, type, entry_type, account, amount, task_id, description, balance)
-		 VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		`INSERT INTO credit_ledger (timestamp, type, entry_type, account, amount, task_id, description, balance, namespace)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		entry.Timestamp.Unix(), string(entry.Type), string(entry.EntryType),
-		entry.Account, entry.Amount, entry.TaskID, entry.Description, entry.Balance,
+		entry.Account, entry.Amount, entry.TaskID, entry.Description, entry.Balance, entry.Namespace,
 	)
 	if err != nil {
 		return 0, err
@@ -43,7 +44,7 @@ func (d *DB) CreditBalance(account string) (int64, error) {
 // LedgerEntries returns recent ledger entries for an account.
 func (d *DB) LedgerEntries(account string, limit int) ([]domain.LedgerEntry, error) {
 	rows, err := d.db.Query(
-		`SELECT id, timestamp, type, entry_type, account, amount, task_id, description, balance
+		`SELECT id, timestamp, type, entry_type, account, amount, task_id, description, balance, namespace
 		 FROM credit_ledger WHERE account = ? ORDER BY id DESC LIMIT ?`,
 		account, limit,
 	)
@@ -58,7 +59,7 @@ func (d *DB) LedgerEntries(account string, limit int) ([]domain.LedgerEntry, err
 		var ts int64
 		var taskID, desc sql.NullString
 		err := rows.Scan(&e.ID, &ts, &e.Type, &e.EntryType, &e.Account,
-			&e.Amount, &taskID, &desc, &e.Balance)
+			&e.Amount, &taskID, &desc, &e.Balance, &e.Namespace)
 		if err != nil {
 			return nil, err
 		}
@@ -74,16 +75,47 @@ func (d *DB) LedgerEntries(account string, limit int) ([]domain.LedgerEntry, err
 	return entries, rows.Err()
 }
 
+// CreditedSince sums the EARN credits posted to an account at or after since.
+func (d *DB) CreditedSince(account string, since time.Time) (int64, error) {
+	return d.credited(account, since.Unix(), math.MaxInt64)
+}
+
+// CreditedBetween sums the EARN credits posted to an account in [start, end).
+func (d *DB) CreditedBetween(account string, start, end time.Time) (int64, error) {
+	return d.credited(account, start.Unix(), end.Unix())
+}
+
+func (d *DB) credited(account string, start, end int64) (int64, error) {
+	var total sql.NullInt64
+	err := d.db.QueryRow(
+		`SELECT SUM(amount) FROM credit_ledger
+		 WHERE account = ? AND type = ? AND entry_type = ? AND timestamp >= ? AND timestamp < ?`,
+		account, string(domain.TxEarn), string(domain.EntryCredit), start, end,
+	).Scan(&total)
+	return total.Int64, err
+}
+
+// LedgerHasTask reports whether a transaction of the given type was already
+// recorded for a task. Payments check it to stay idempotent on the task ID.
+func (d *DB) LedgerHasTask(taskID string, txType domain.TransactionType) (bool, error) {
+	var n int
+	err := d.db.QueryRow(
+		`SELECT COUNT(*) FROM credit_ledger WHERE task_id = ? AND type = ?`,
+		taskID, string(txType),
+	).Scan(&n)
+	return n > 0, err
+}
+
 // ─── Task Repository ────────────────────────────────────────────────────────
 
 // InsertTask creates a new task record.
 func (d *DB) InsertTask(task domain.Task) error {
 	_, err := d.db.Exec(
-		`INSERT INTO tasks (id, type, status, priority, created_at, started_at, completed_at, credits, result_hash, error)
-		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		`INSERT INTO tasks (id, type, status, priority, created_at, started_at, completed_at, credits, result_hash, error, namespace)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		task.ID, string(task.Type), string(task.Status), task.Priority,
 		task.CreatedAt.Unix(), nullableUnix(task.StartedAt), nullableUnix(task.CompletedAt),
-		task.Credits, nullStr(task.ResultHash), nullStr(task.Error),
+		task.Credits, nullStr(task.ResultHash), nullStr(task.Error), task.Namespace,
 	)
 	return err
 }
@@ -108,7 +140,7 @@ func (d *DB) UpdateTaskStatus(id string, status domain.TaskStatus) error {
 // GetTask retrieves a task by ID.
 func (d *DB) GetTask(id string) (*domain.Task, error) {
 	row := d.db.QueryRow(
-		`SELECT id, type, status, priority, created_at, started_at, completed_at, credits, result_hash, error
+		`SELECT id, type, status, priority, created_at, started_at, completed_at, credits, result_hash, error, namespace
 		 FROM tasks WHERE id = ?`, id,
 	)
 	return scanTask(row)
@@ -117,7 +149,7 @@ func (d *DB) GetTask(id string) (*domain.Task, error) {
 // ListTasks returns tasks filtered by status.
 func (d *DB) ListTasks(status domain.TaskStatus, limit int) ([]domain.Task, error) {
 	rows, err := d.db.Query(
-		`SELECT id, type, status, priority, created_at, started_at, completed_at, credits, result_hash, error
+		`SELECT id, type, status, priority, created_at, started_at, completed_at, credits, result_hash, error, namespace
 		 FROM tasks WHERE status = ? ORDER BY created_at DESC LIMIT ?`,
 		string(status), limit,
 	)
@@ -145,7 +177,7 @@ func scanTask(s scanner) (*domain.Task, error) {
 	var resultHash, taskErr sql.NullString
 
 	err := s.Scan(&t.ID, &t.Type, &t.Status, &t.Priority,
-		&createdAt, &startedAt, &completedAt, &credits, &resultHash, &taskErr)
+		&createdAt, &startedAt, &completedAt, &credits, &resultHash, &taskErr, &t.Namespace)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -176,6 +208,16 @@ func scanTaskRows(rows *sql.Rows) (*domain.Task, error) {
 	return scanTask(rows)
 }
 
+// CountTasksCompleted returns how many tasks completed in [start, end).
+func (d *DB) CountTasksCompleted(start, end time.Time) (int, error) {
+	var n int
+	err := d.db.QueryRow(
+		`SELECT COUNT(*) FROM tasks WHERE status = ? AND completed_at >= ? AND completed_at < ?`,
+		string(domain.TaskCompleted), start.Unix(), end.Unix(),
+	).Scan(&n)
+	return n, err
+}
+
 // ─── Peer Repository ────────────────────────────────────────────────────────
 
 // UpsertPeer inserts or updates a peer record.
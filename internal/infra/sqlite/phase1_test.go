@@ -100,6 +100,58 @@ func TestLedgerEntries_Empty(t *testing.T) {
 	}
 }
 
+func TestCreditedSince(t *testing.T) {
+	db := newTestDB(t)
+	now := time.Now()
+	for _, e := range []domain.LedgerEntry{
+		{Timestamp: now.Add(-48 * time.Hour), Type: domain.TxEarn, EntryType: domain.EntryCredit, Account: "node_balance", Amount: 100},
+		{Timestamp: now, Type: domain.TxEarn, EntryType: domain.EntryCredit, Account: "node_balance", Amount: 7},
+		{Timestamp: now, Type: domain.TxEarn, EntryType: domain.EntryDebit, Account: "system_pool", Amount: 7},
+		{Timestamp: now, Type: domain.TxSpend, EntryType: domain.EntryCredit, Account: "node_balance", Amount: 3},
+	} {
+		db.InsertLedgerEntry(e)
+	}
+
+	if got, err := db.CreditedSince("node_balance", now.Add(-time.Hour)); err != nil || got != 7 {
+		t.Errorf("CreditedSince(1h) = %d, %v; want 7", got, err)
+	}
+	if got, _ := db.CreditedSince("other", now.Add(-time.Hour)); got != 0 {
+		t.Errorf("CreditedSince(other) = %d, want 0", got)
+	}
+}
+
+func TestCreditedBetween(t *testing.T) {
+	db := newTestDB(t)
+	day := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+	for _, at := range []time.Time{day.Add(-time.Second), day, day.Add(23 * time.Hour), day.Add(24 * time.Hour)} {
+		db.InsertLedgerEntry(domain.LedgerEntry{
+			Timestamp: at, Type: domain.TxEarn, EntryType: domain.EntryCredit, Account: "node_balance", Amount: 10,
+		})
+	}
+
+	if got, err := db.CreditedBetween("node_balance", day, day.Add(24*time.Hour)); err != nil || got != 20 {
+		t.Errorf("CreditedBetween(day) = %d, %v; want 20", got, err)
+	}
+}
+
+func TestLedgerHasTask(t *testing.T) {
+	db := newTestDB(t)
+	db.InsertLedgerEntry(domain.LedgerEntry{
+		Timestamp: time.Now(), Type: domain.TxSpend, EntryType: domain.EntryDebit,
+		Account: "node_balance", Amount: 5, TaskID: "task-1",
+	})
+
+	if ok, err := db.LedgerHasTask("task-1", domain.TxSpend); err != nil || !ok {
+		t.Errorf("LedgerHasTask(task-1, SPEND) = %v, %v; want true", ok, err)
+	}
+	if ok, _ := db.LedgerHasTask("task-1", domain.TxEarn); ok {
+		t.Error("LedgerHasTask(task-1, EARN) = true, want false")
+	}
+	if ok, _ := db.LedgerHasTask("task-2", domain.TxSpend); ok {
+		t.Error("LedgerHasTask(task-2, SPEND) = true, want false")
+	}
+}
+
 // ─── Task Repository Tests ─────────────────────────────────────────────────
 
 func TestInsertTask(t *testing.T) {
@@ -209,6 +261,24 @@ func TestUpdateTaskStatus_Completed(t *testing.T) {
 	}
 }
 
+func TestCountTasksCompleted(t *testing.T) {
+	db := newTestDB(t)
+	for _, id := range []string{"task-001", "task-002", "task-003"} {
+		db.InsertTask(domain.Task{ID: id, Type: domain.TaskInference, Status: domain.TaskQueued, CreatedAt: time.Now()})
+	}
+	db.UpdateTaskStatus("task-001", domain.TaskCompleted)
+	db.UpdateTaskStatus("task-002", domain.TaskCompleted)
+	db.UpdateTaskStatus("task-003", domain.TaskFailed)
+
+	now := time.Now()
+	if got, err := db.CountTasksCompleted(now.Add(-time.Hour), now.Add(time.Hour)); err != nil || got != 2 {
+		t.Errorf("CountTasksCompleted(now) = %d, %v; want 2", got, err)
+	}
+	if got, _ := db.CountTasksCompleted(now.Add(-48*time.Hour), now.Add(-24*time.Hour)); got != 0 {
+		t.Errorf("CountTasksCompleted(yesterday) = %d, want 0", got)
+	}
+}
+
 func TestListTasks(t *testing.T) {
 	db := newTestDB(t)
 
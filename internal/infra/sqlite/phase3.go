@@ -5,6 +5,7 @@ package sqlite
 
 import (
 	"database/sql"
+	"fmt"
 	"time"
 )
 
@@ -73,6 +74,7 @@ func Phase3Migrations() []string {
 			total_preempted INTEGER NOT NULL DEFAULT 0,
 			snapshot_at     TEXT NOT NULL DEFAULT (datetime('now'))
 		)`,
+		`CREATE INDEX IF NOT EXISTS idx_scheduler_snapshots_at ON scheduler_snapshots(snapshot_at)`,
 
 		// Model popularity tracking
 		`CREATE TABLE IF NOT EXISTS model_popularity (
@@ -212,6 +214,25 @@ func (db *DB) GetCircuitBreaker(name string) (state, failures, totalTrips int, t
 	return
 }
 
+// ListCircuitBreakers returns the names of all persisted circuit breakers.
+func (db *DB) ListCircuitBreakers() ([]string, error) {
+	rows, err := db.db.Query(`SELECT name FROM circuit_breakers ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
 // ─── Quarantine Operations ──────────────────────────────────────────────────
 
 // InsertQuarantineRecord adds a quarantine record.
@@ -254,6 +275,53 @@ func (db *DB) QuarantineCountSince(nodeID string, since time.Time) (int, error)
 	return count, err
 }
 
+// ListQuarantinesSince returns every quarantine started after since, oldest
+// first, including released and expired ones.
+func (db *DB) ListQuarantinesSince(since time.Time) ([]struct {
+	NodeID    string
+	Reason    string
+	StartedAt time.Time
+	ExpiresAt time.Time
+	Released  bool
+}, error) {
+	rows, err := db.db.Query(`
+		SELECT node_id, reason, started_at, expires_at, released
+		FROM quarantine_records WHERE started_at > ? ORDER BY started_at, id
+	`, since.Format(time.RFC3339))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []struct {
+		NodeID    string
+		Reason    string
+		StartedAt time.Time
+		ExpiresAt time.Time
+		Released  bool
+	}
+
+	for rows.Next() {
+		var r struct {
+			NodeID    string
+			Reason    string
+			StartedAt time.Time
+			ExpiresAt time.Time
+			Released  bool
+		}
+		var startedAt, expiresAt string
+		var released int
+		if err := rows.Scan(&r.NodeID, &r.Reason, &startedAt, &expiresAt, &released); err != nil {
+			return nil, err
+		}
+		r.StartedAt, _ = time.Parse(time.RFC3339, startedAt)
+		r.ExpiresAt, _ = time.Parse(time.RFC3339, expiresAt)
+		r.Released = released != 0
+		result = append(result, r)
+	}
+	return result, rows.Err()
+}
+
 // ─── Earnings Report Operations ─────────────────────────────────────────────
 
 // InsertEarningsReport saves an earnings report.
@@ -286,6 +354,56 @@ func (db *DB) LatestEarningsReport() (periodStart, periodEnd time.Time, credits
 	return
 }
 
+// EarningsReportRecord is a persisted earnings report.
+type EarningsReportRecord struct {
+	ID             int64     `json:"id"`
+	PeriodStart    time.Time `json:"period_start"`
+	PeriodEnd      time.Time `json:"period_end"`
+	CreditsEarned  int64     `json:"credits_earned"`
+	TasksCompleted int       `json:"tasks_completed"`
+	UptimeHours    float64   `json:"uptime_hours"`
+	HardwareTier   int       `json:"hardware_tier"`
+	TopModel       string    `json:"top_model,omitempty"`
+}
+
+// ListEarningsReports returns the most recent earnings reports, newest
+// period first.
+func (db *DB) ListEarningsReports(limit int) ([]EarningsReportRecord, error) {
+	rows, err := db.db.Query(`
+		SELECT id, period_start, period_end, credits_earned, tasks_completed, uptime_hours, hardware_tier, top_model
+		FROM earnings_reports ORDER BY period_start DESC, id DESC LIMIT ?
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []EarningsReportRecord
+	for rows.Next() {
+		var r EarningsReportRecord
+		var startStr, endStr string
+		var topModel sql.NullString
+		if err := rows.Scan(&r.ID, &startStr, &endStr, &r.CreditsEarned, &r.TasksCompleted,
+			&r.UptimeHours, &r.HardwareTier, &topModel); err != nil {
+			return nil, err
+		}
+		r.PeriodStart, _ = time.Parse(time.RFC3339, startStr)
+		r.PeriodEnd, _ = time.Parse(time.RFC3339, endStr)
+		r.TopModel = topModel.String
+		result = append(result, r)
+	}
+	return result, rows.Err()
+}
+
+// EarningsReportExists reports whether a report starting at periodStart
+// was already saved.
+func (db *DB) EarningsReportExists(periodStart time.Time) (bool, error) {
+	var n int
+	err := db.db.QueryRow(`SELECT COUNT(*) FROM earnings_reports WHERE period_start = ?`,
+		periodStart.Format(time.RFC3339)).Scan(&n)
+	return n > 0, err
+}
+
 // ─── Model Popularity Operations ────────────────────────────────────────────
 
 // RecordModelRequest increments the request count for a model.
@@ -333,11 +451,162 @@ func (db *DB) TopRequestedModels(limit int) ([]struct {
 
 // ─── Scheduler Snapshot Operations ──────────────────────────────────────────
 
+// snapshotTimeLayout is how snapshot_at is stored (datetime('now'), UTC).
+const snapshotTimeLayout = "2006-01-02 15:04:05"
+
+// SchedulerSnapshot is the scheduler's statistics at one moment.
+type SchedulerSnapshot struct {
+	QueueDepth     int       `json:"queue_depth"`
+	BackPressure   int       `json:"back_pressure"`
+	TotalEnqueued  int64     `json:"total_enqueued"`
+	TotalCompleted int64     `json:"total_completed"`
+	TotalRejected  int64     `json:"total_rejected"`
+	TotalStolen    int64     `json:"total_stolen"`
+	TotalPreempted int64     `json:"total_preempted"`
+	At             time.Time `json:"at"`
+}
+
 // InsertSchedulerSnapshot saves a scheduler statistics snapshot.
 func (db *DB) InsertSchedulerSnapshot(queueDepth, backPressure int, totalEnqueued, totalCompleted, totalRejected, totalStolen, totalPreempted int64) error {
+	return db.SaveSchedulerSnapshot(SchedulerSnapshot{
+		QueueDepth:     queueDepth,
+		BackPressure:   backPressure,
+		TotalEnqueued:  totalEnqueued,
+		TotalCompleted: totalCompleted,
+		TotalRejected:  totalRejected,
+		TotalStolen:    totalStolen,
+		TotalPreempted: totalPreempted,
+	})
+}
+
+// SaveSchedulerSnapshot saves a snapshot taken at s.At (now if zero).
+func (db *DB) SaveSchedulerSnapshot(s SchedulerSnapshot) error {
+	if s.At.IsZero() {
+		s.At = time.Now()
+	}
 	_, err := db.db.Exec(`
-		INSERT INTO scheduler_snapshots (queue_depth, back_pressure, total_enqueued, total_completed, total_rejected, total_stolen, total_preempted)
-		VALUES (?, ?, ?, ?, ?, ?, ?)
-	`, queueDepth, backPressure, totalEnqueued, totalCompleted, totalRejected, totalStolen, totalPreempted)
+		INSERT INTO scheduler_snapshots (queue_depth, back_pressure, total_enqueued, total_completed, total_rejected, total_stolen, total_preempted, snapshot_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, s.QueueDepth, s.BackPressure, s.TotalEnqueued, s.TotalCompleted, s.TotalRejected, s.TotalStolen, s.TotalPreempted,
+		s.At.UTC().Format(snapshotTimeLayout))
 	return err
 }
+
+// LatestSchedulerSnapshot returns the most recent snapshot, or nil if none
+// was saved.
+func (db *DB) LatestSchedulerSnapshot() (*SchedulerSnapshot, error) {
+	snaps, err := db.querySchedulerSnapshots(`
+		SELECT queue_depth, back_pressure, total_enqueued, total_completed, total_rejected, total_stolen, total_preempted, snapshot_at
+		FROM scheduler_snapshots ORDER BY snapshot_at DESC, id DESC LIMIT 1`)
+	if err != nil || len(snaps) == 0 {
+		return nil, err
+	}
+	return &snaps[0], nil
+}
+
+// ListSchedulerSnapshots returns the snapshots taken since since, oldest
+// first.
+func (db *DB) ListSchedulerSnapshots(since time.Time) ([]SchedulerSnapshot, error) {
+	return db.querySchedulerSnapshots(`
+		SELECT queue_depth, back_pressure, total_enqueued, total_completed, total_rejected, total_stolen, total_preempted, snapshot_at
+		FROM scheduler_snapshots WHERE snapshot_at >= ? ORDER BY snapshot_at, id`,
+		since.UTC().Format(snapshotTimeLayout))
+}
+
+// PruneSchedulerSnapshots deletes snapshots taken before before and returns
+// how many were removed.
+func (db *DB) PruneSchedulerSnapshots(before time.Time) (int64, error) {
+	res, err := db.db.Exec(`DELETE FROM scheduler_snapshots WHERE snapshot_at < ?`,
+		before.UTC().Format(snapshotTimeLayout))
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (db *DB) querySchedulerSnapshots(query string, args ...any) ([]SchedulerSnapshot, error) {
+	rows, err := db.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []SchedulerSnapshot
+	for rows.Next() {
+		var s SchedulerSnapshot
+		var at string
+		if err := rows.Scan(&s.QueueDepth, &s.BackPressure, &s.TotalEnqueued, &s.TotalCompleted,
+			&s.TotalRejected, &s.TotalStolen, &s.TotalPreempted, &at); err != nil {
+			return nil, err
+		}
+		s.At, _ = time.Parse(snapshotTimeLayout, at)
+		result = append(result, s)
+	}
+	return result, rows.Err()
+}
+
+// SchedulerTrendPoint summarizes the snapshots in one bucket of a trend.
+type SchedulerTrendPoint struct {
+	Start           time.Time `json:"start"`
+	Snapshots       int       `json:"snapshots"`
+	AvgQueueDepth   float64   `json:"avg_queue_depth"`
+	MaxQueueDepth   int       `json:"max_queue_depth"`
+	MaxBackPressure int       `json:"max_back_pressure"`
+	Enqueued        int64     `json:"enqueued"`
+	Completed       int64     `json:"completed"`
+	Rejected        int64     `json:"rejected"`
+	CompletionRate  float64   `json:"completion_rate"` // completed per minute
+}
+
+// SchedulerTrends buckets the snapshots taken since since into bucket-wide
+// points, oldest first; buckets without snapshots are omitted. Counts are
+// the growth of the cumulative counters since the previous snapshot, so a
+// bucket's first snapshot counts from the one before it. A counter that
+// went down was reset by a restart and counts from zero.
+func (db *DB) SchedulerTrends(since time.Time, bucket time.Duration) ([]SchedulerTrendPoint, error) {
+	if bucket <= 0 {
+		return nil, fmt.Errorf("sqlite: trend bucket must be positive, got %s", bucket)
+	}
+	snaps, err := db.ListSchedulerSnapshots(since.Add(-bucket))
+	if err != nil {
+		return nil, err
+	}
+
+	var points []SchedulerTrendPoint
+	var prev *SchedulerSnapshot
+	for i := range snaps {
+		s := &snaps[i]
+		if s.At.Before(since) {
+			prev = s // only a baseline for the first delta
+			continue
+		}
+		start := s.At.Truncate(bucket)
+		if len(points) == 0 || !points[len(points)-1].Start.Equal(start) {
+			points = append(points, SchedulerTrendPoint{Start: start})
+		}
+		p := &points[len(points)-1]
+		p.Snapshots++
+		p.AvgQueueDepth += float64(s.QueueDepth)
+		p.MaxQueueDepth = max(p.MaxQueueDepth, s.QueueDepth)
+		p.MaxBackPressure = max(p.MaxBackPressure, s.BackPressure)
+		if prev != nil {
+			p.Enqueued += counterDelta(prev.TotalEnqueued, s.TotalEnqueued)
+			p.Completed += counterDelta(prev.TotalCompleted, s.TotalCompleted)
+			p.Rejected += counterDelta(prev.TotalRejected, s.TotalRejected)
+		}
+		prev = s
+	}
+	for i := range points {
+		points[i].AvgQueueDepth /= float64(points[i].Snapshots)
+		points[i].CompletionRate = float64(points[i].Completed) / bucket.Minutes()
+	}
+	return points, nil
+}
+
+// counterDelta is how much a cumulative counter grew from prev to cur.
+func counterDelta(prev, cur int64) int64 {
+	if cur < prev {
+		return cur
+	}
+	return cur - prev
+}
@@ -126,6 +126,22 @@ func TestPhase3_GetCircuitBreaker_NotFound(t *testing.T) {
 	}
 }
 
+func TestPhase3_ListCircuitBreakers(t *testing.T) {
+	db := newTestDB(t)
+	for _, name := range []string{"peer:b", "engine:a"} {
+		if err := db.UpsertCircuitBreaker(name, 0, 0, 0, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	names, err := db.ListCircuitBreakers()
+	if err != nil {
+		t.Fatalf("ListCircuitBreakers() error: %v", err)
+	}
+	if len(names) != 2 || names[0] != "engine:a" || names[1] != "peer:b" {
+		t.Errorf("names = %v, want [engine:a peer:b]", names)
+	}
+}
+
 func TestPhase3_UpsertCircuitBreaker_NilTrippedAt(t *testing.T) {
 	db := newTestDB(t)
 	err := db.UpsertCircuitBreaker("clean-cb", 0, 0, 0, nil)
@@ -211,6 +227,30 @@ func TestPhase3_QuarantineCountSince(t *testing.T) {
 	}
 }
 
+func TestPhase3_ListQuarantinesSince(t *testing.T) {
+	db := newTestDB(t)
+	now := time.Now().UTC().Truncate(time.Second)
+
+	db.InsertQuarantineRecord("node-1", "task_failures", now.Add(-10*24*time.Hour), now.Add(-9*24*time.Hour))
+	db.InsertQuarantineRecord("node-1", "anomaly", now.Add(-2*time.Hour), now.Add(-time.Hour))
+	db.InsertQuarantineRecord("node-2", "reputation", now, now.Add(time.Hour))
+	db.ReleaseQuarantine("node-1")
+
+	rows, err := db.ListQuarantinesSince(now.Add(-7 * 24 * time.Hour))
+	if err != nil {
+		t.Fatalf("ListQuarantinesSince() error: %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("ListQuarantinesSince() = %d rows, want 2", len(rows))
+	}
+	if rows[0].NodeID != "node-1" || rows[0].Reason != "anomaly" || !rows[0].Released {
+		t.Errorf("rows[0] = %+v, want released node-1 anomaly", rows[0])
+	}
+	if rows[1].NodeID != "node-2" || rows[1].Released || !rows[1].ExpiresAt.Equal(now.Add(time.Hour)) {
+		t.Errorf("rows[1] = %+v, want active node-2 until %v", rows[1], now.Add(time.Hour))
+	}
+}
+
 // ─── Earnings Reports ───────────────────────────────────────────────────────
 
 func TestPhase3_InsertEarningsReport(t *testing.T) {
@@ -305,3 +345,54 @@ func TestPhase3_InsertSchedulerSnapshot(t *testing.T) {
 		t.Fatalf("InsertSchedulerSnapshot() second error: %v", err)
 	}
 }
+
+func TestPhase3_SchedulerTrends(t *testing.T) {
+	db := newTestDB(t)
+	if s, err := db.LatestSchedulerSnapshot(); err != nil || s != nil {
+		t.Fatalf("LatestSchedulerSnapshot() on empty table = %v, %v", s, err)
+	}
+
+	base := time.Now().UTC().Truncate(time.Hour).Add(-3 * time.Hour)
+	snaps := []SchedulerSnapshot{
+		{QueueDepth: 10, TotalEnqueued: 100, TotalCompleted: 90, At: base.Add(-30 * time.Minute)}, // baseline
+		{QueueDepth: 20, TotalEnqueued: 130, TotalCompleted: 150, At: base.Add(10 * time.Minute)},
+		{QueueDepth: 40, BackPressure: 1, TotalEnqueued: 160, TotalCompleted: 210, At: base.Add(40 * time.Minute)},
+		{QueueDepth: 5, TotalEnqueued: 10, TotalCompleted: 12, At: base.Add(70 * time.Minute)}, // after a restart
+	}
+	for _, s := range snaps {
+		if err := db.SaveSchedulerSnapshot(s); err != nil {
+			t.Fatalf("SaveSchedulerSnapshot() error: %v", err)
+		}
+	}
+
+	latest, err := db.LatestSchedulerSnapshot()
+	if err != nil || latest == nil || latest.TotalCompleted != 12 || !latest.At.Equal(snaps[3].At) {
+		t.Fatalf("LatestSchedulerSnapshot() = %+v, %v; want the last one saved", latest, err)
+	}
+
+	points, err := db.SchedulerTrends(base, time.Hour)
+	if err != nil {
+		t.Fatalf("SchedulerTrends() error: %v", err)
+	}
+	if len(points) != 2 {
+		t.Fatalf("got %d points, want 2: %+v", len(points), points)
+	}
+	first, second := points[0], points[1]
+	if !first.Start.Equal(base) || first.Snapshots != 2 || first.AvgQueueDepth != 30 || first.MaxQueueDepth != 40 || first.MaxBackPressure != 1 {
+		t.Errorf("first point = %+v", first)
+	}
+	if first.Completed != 120 || first.Enqueued != 60 || first.CompletionRate != 2 {
+		t.Errorf("first point counts = %d completed, %d enqueued, %.1f/min; want 120, 60, 2.0", first.Completed, first.Enqueued, first.CompletionRate)
+	}
+	if second.Completed != 12 {
+		t.Errorf("completed after restart = %d, want 12", second.Completed)
+	}
+
+	if _, err := db.SchedulerTrends(base, 0); err == nil {
+		t.Error("zero bucket accepted")
+	}
+	n, err := db.PruneSchedulerSnapshots(base)
+	if err != nil || n != 1 {
+		t.Errorf("PruneSchedulerSnapshots() = %d, %v; want 1", n, err)
+	}
+}
@@ -430,6 +430,56 @@ func (db *DB) CompleteChunkTransfer(manifestID string, chunkIndex int, toPeer st
 	return err
 }
 
+// StartChunkTransfer records an attempt to fetch a chunk from fromPeer,
+// resetting a previous failed or stale attempt.
+func (db *DB) StartChunkTransfer(manifestID string, chunkIndex int, fromPeer, toPeer string, sizeBytes int64) error {
+	now := time.Now().Format("2006-01-02 15:04:05")
+	_, err := db.db.Exec(`
+		INSERT INTO chunk_transfers (manifest_id, chunk_index, from_peer, to_peer, size_bytes, status, started_at)
+		VALUES (?, ?, ?, ?, ?, 'IN_PROGRESS', ?)
+		ON CONFLICT(manifest_id, chunk_index, to_peer) DO UPDATE SET
+			from_peer    = excluded.from_peer,
+			size_bytes   = excluded.size_bytes,
+			status       = 'IN_PROGRESS',
+			started_at   = excluded.started_at,
+			completed_at = NULL
+	`, manifestID, chunkIndex, fromPeer, toPeer, sizeBytes, now)
+	return err
+}
+
+// FailChunkTransfer marks a chunk transfer as failed.
+func (db *DB) FailChunkTransfer(manifestID string, chunkIndex int, toPeer string) error {
+	_, err := db.db.Exec(`
+		UPDATE chunk_transfers SET status = 'FAILED'
+		WHERE manifest_id = ? AND chunk_index = ? AND to_peer = ?
+	`, manifestID, chunkIndex, toPeer)
+	return err
+}
+
+// CompletedChunks returns the indices of completed chunks for a download,
+// in ascending order.
+func (db *DB) CompletedChunks(manifestID, toPeer string) ([]int, error) {
+	rows, err := db.db.Query(`
+		SELECT chunk_index FROM chunk_transfers
+		WHERE manifest_id = ? AND to_peer = ? AND status = 'COMPLETED'
+		ORDER BY chunk_index
+	`, manifestID, toPeer)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []int
+	for rows.Next() {
+		var idx int
+		if err := rows.Scan(&idx); err != nil {
+			return nil, err
+		}
+		out = append(out, idx)
+	}
+	return out, rows.Err()
+}
+
 // TransferProgress returns completion stats for a manifest download.
 func (db *DB) TransferProgress(manifestID, toPeer string) (total, completed int, err error) {
 	err = db.db.QueryRow(`
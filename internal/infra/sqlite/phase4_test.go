@@ -313,6 +313,38 @@ func TestPhase4_ChunkTransfer(t *testing.T) {
 	}
 }
 
+func TestPhase4_ChunkTransfer_Resume(t *testing.T) {
+	db := openTestDB(t)
+	defer db.Close()
+
+	for i := 0; i < 3; i++ {
+		if err := db.StartChunkTransfer("manifest-1", i, "peer-A", "me", 1024); err != nil {
+			t.Fatalf("StartChunkTransfer: %v", err)
+		}
+	}
+	db.CompleteChunkTransfer("manifest-1", 2, "me")
+	db.CompleteChunkTransfer("manifest-1", 0, "me")
+	db.FailChunkTransfer("manifest-1", 1, "me")
+
+	// Retrying chunk 1 from another peer keeps it out of the completed set.
+	if err := db.StartChunkTransfer("manifest-1", 1, "peer-B", "me", 1024); err != nil {
+		t.Fatalf("StartChunkTransfer retry: %v", err)
+	}
+
+	done, err := db.CompletedChunks("manifest-1", "me")
+	if err != nil {
+		t.Fatalf("CompletedChunks: %v", err)
+	}
+	if len(done) != 2 || done[0] != 0 || done[1] != 2 {
+		t.Errorf("CompletedChunks = %v, want [0 2]", done)
+	}
+
+	total, completed, _ := db.TransferProgress("manifest-1", "me")
+	if total != 3 || completed != 2 {
+		t.Errorf("progress = %d/%d, want 2/3", completed, total)
+	}
+}
+
 // ─── Helper ─────────────────────────────────────────────────────────────────
 
 func openTestDB(t *testing.T) *DB {
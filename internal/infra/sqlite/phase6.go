@@ -1,5 +1,12 @@
 package sqlite
 
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
 // Phase6Migrations returns the DDL for Phase 6: Singularity — Self-Organizing Network.
 // Called from db.go's migrate() after Phase 5 migrations.
 //
@@ -9,6 +16,10 @@ package sqlite
 //   - healing_incidents:         autonomous incident lifecycle
 //   - model_placements:          intelligence placement recommendations
 //   - model_retirement_log:      retired model history
+//   - model_retirement_workflow: models on their way to retirement
+//   - model_popularity_detail:   intelligence per-model request statistics
+//   - node_model_affinity:       intelligence per-{node, model} statistics
+//   - optimizer_cycles:          intelligence placement cycle counters
 func Phase6Migrations() []string {
 	return []string{
 		// ─── ML Scheduler ───────────────────────────────────────────────
@@ -95,5 +106,349 @@ func Phase6Migrations() []string {
 		)`,
 		`CREATE INDEX IF NOT EXISTS idx_retire_model ON model_retirement_log(model_name)`,
 		`CREATE INDEX IF NOT EXISTS idx_retire_time ON model_retirement_log(retired_at)`,
+
+		// Models on their way to retirement; status is the workflow entry as JSON
+		`CREATE TABLE IF NOT EXISTS model_retirement_workflow (
+			model_name TEXT PRIMARY KEY,
+			state      TEXT NOT NULL,
+			status     TEXT NOT NULL,
+			updated_at INTEGER NOT NULL
+		)`,
+
+		// ─── Optimizer State ────────────────────────────────────────────
+
+		// Learned model popularity; the hourly arrays are JSON
+		`CREATE TABLE IF NOT EXISTS model_popularity_detail (
+			model_name     TEXT PRIMARY KEY,
+			total_reqs     INTEGER NOT NULL,
+			last_requested INTEGER NOT NULL,
+			latency_sum    REAL NOT NULL,
+			latency_count  INTEGER NOT NULL,
+			hourly         TEXT NOT NULL,
+			recent         TEXT NOT NULL,
+			recent_hours   TEXT NOT NULL
+		)`,
+
+		// Learned {node, model} affinity inputs
+		`CREATE TABLE IF NOT EXISTS node_model_affinity (
+			node_id       TEXT NOT NULL,
+			model_name    TEXT NOT NULL,
+			requests      INTEGER NOT NULL,
+			cache_hits    INTEGER NOT NULL,
+			cache_misses  INTEGER NOT NULL,
+			latency_sum   REAL NOT NULL,
+			latency_count INTEGER NOT NULL,
+			vram_fit      REAL NOT NULL,
+			slo_samples   INTEGER NOT NULL,
+			slo_met       INTEGER NOT NULL,
+			PRIMARY KEY (node_id, model_name)
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_affinity_model ON node_model_affinity(model_name)`,
+
+		// Placement cycles run so far (a single row)
+		`CREATE TABLE IF NOT EXISTS optimizer_cycles (
+			id            INTEGER PRIMARY KEY CHECK (id = 1),
+			last_run      INTEGER NOT NULL,
+			optimizations INTEGER NOT NULL
+		)`,
+	}
+}
+
+// ─── Model Retirement Log Operations ────────────────────────────────────────
+
+// LogModelRetirement records a model removed by retirement or quota eviction.
+func (db *DB) LogModelRetirement(modelName string, lastRequested time.Time, sizeBytes int64, reason string) error {
+	now := time.Now()
+	var last int64
+	days := 0
+	if !lastRequested.IsZero() {
+		last = lastRequested.Unix()
+		days = int(now.Sub(lastRequested).Hours() / 24)
+	}
+	_, err := db.db.Exec(
+		`INSERT INTO model_retirement_log (model_name, last_requested, days_since_use, size_bytes, reason, retired_at)
+		 VALUES (?, ?, ?, ?, ?, ?)`,
+		modelName, last, days, sizeBytes, reason, now.Unix(),
+	)
+	return err
+}
+
+// RetirementWorkflowRecord is a persisted retirement workflow entry. Status
+// is the entry as JSON.
+type RetirementWorkflowRecord struct {
+	ModelName string
+	State     string
+	Status    []byte
+	UpdatedAt time.Time
+}
+
+// UpsertRetirementWorkflow stores a model's retirement workflow entry.
+func (db *DB) UpsertRetirementWorkflow(r RetirementWorkflowRecord) error {
+	_, err := db.db.Exec(
+		`INSERT INTO model_retirement_workflow (model_name, state, status, updated_at)
+		 VALUES (?, ?, ?, ?)
+		 ON CONFLICT(model_name) DO UPDATE SET
+			state=excluded.state,
+			status=excluded.status,
+			updated_at=excluded.updated_at`,
+		r.ModelName, r.State, string(r.Status), r.UpdatedAt.Unix(),
+	)
+	return err
+}
+
+// DeleteRetirementWorkflow removes a model that left the workflow.
+func (db *DB) DeleteRetirementWorkflow(modelName string) error {
+	_, err := db.db.Exec(`DELETE FROM model_retirement_workflow WHERE model_name = ?`, modelName)
+	return err
+}
+
+// ListRetirementWorkflow returns every workflow entry, by model name.
+func (db *DB) ListRetirementWorkflow() ([]RetirementWorkflowRecord, error) {
+	rows, err := db.db.Query(
+		`SELECT model_name, state, status, updated_at FROM model_retirement_workflow ORDER BY model_name`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []RetirementWorkflowRecord
+	for rows.Next() {
+		var r RetirementWorkflowRecord
+		var status string
+		var updated int64
+		if err := rows.Scan(&r.ModelName, &r.State, &status, &updated); err != nil {
+			return nil, err
+		}
+		r.Status, r.UpdatedAt = []byte(status), time.Unix(updated, 0)
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
+
+// ─── Model Placement Operations ─────────────────────────────────────────────
+
+// PlacementRecord is a persisted placement recommendation and its outcome.
+type PlacementRecord struct {
+	RecID     string    `json:"rec_id"`
+	Type      string    `json:"type"`
+	ModelName string    `json:"model_name"`
+	FromNode  string    `json:"from_node,omitempty"`
+	ToNode    string    `json:"to_node,omitempty"`
+	Score     float64   `json:"score"`
+	Reason    string    `json:"reason,omitempty"`
+	Status    string    `json:"status"`
+	Detail    string    `json:"detail,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// UpsertPlacement records a recommendation, or its new status if one with
+// the same rec_id is already stored.
+func (db *DB) UpsertPlacement(r PlacementRecord) error {
+	res, err := db.db.Exec(
+		`UPDATE model_placements SET status = ?, detail = ?, updated_at = ? WHERE rec_id = ?`,
+		r.Status, r.Detail, r.UpdatedAt.Unix(), r.RecID,
+	)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil || n > 0 {
+		return err
+	}
+	_, err = db.db.Exec(
+		`INSERT INTO model_placements (rec_id, rec_type, model_name, from_node, to_node, score, reason, status, detail, created_at, updated_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		r.RecID, r.Type, r.ModelName, r.FromNode, r.ToNode, r.Score, r.Reason,
+		r.Status, r.Detail, r.CreatedAt.Unix(), r.UpdatedAt.Unix(),
+	)
+	return err
+}
+
+// ListPlacements returns the most recent recommendations that have an ID,
+// oldest first.
+func (db *DB) ListPlacements(limit int) ([]PlacementRecord, error) {
+	rows, err := db.db.Query(`
+		SELECT rec_id, rec_type, model_name, from_node, to_node, score, reason, status, detail, created_at, updated_at
+		FROM (SELECT * FROM model_placements WHERE rec_id != '' ORDER BY created_at DESC, id DESC LIMIT ?)
+		ORDER BY created_at, id
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []PlacementRecord
+	for rows.Next() {
+		var r PlacementRecord
+		var created, updated int64
+		if err := rows.Scan(&r.RecID, &r.Type, &r.ModelName, &r.FromNode, &r.ToNode, &r.Score,
+			&r.Reason, &r.Status, &r.Detail, &created, &updated); err != nil {
+			return nil, err
+		}
+		r.CreatedAt, r.UpdatedAt = time.Unix(created, 0), time.Unix(updated, 0)
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
+
+// ─── Optimizer State Operations ─────────────────────────────────────────────
+
+// ModelPopularityDetail is a model's persisted request statistics.
+type ModelPopularityDetail struct {
+	ModelName     string    `json:"model_name"`
+	TotalReqs     int64     `json:"total_reqs"`
+	LastRequested time.Time `json:"last_requested"`
+	LatencySum    float64   `json:"latency_sum"`
+	LatencyCount  int64     `json:"latency_count"`
+	Hourly        [24]int64 `json:"hourly"`
+	Recent        [24]int64 `json:"recent"`
+	RecentHours   [24]int64 `json:"recent_hours"`
+}
+
+// NodeModelAffinityRecord is a {node, model} pair's persisted statistics.
+type NodeModelAffinityRecord struct {
+	NodeID       string  `json:"node_id"`
+	ModelName    string  `json:"model_name"`
+	Requests     int64   `json:"requests"`
+	CacheHits    int64   `json:"cache_hits"`
+	CacheMisses  int64   `json:"cache_misses"`
+	LatencySum   float64 `json:"latency_sum"`
+	LatencyCount int64   `json:"latency_count"`
+	VRAMFit      float64 `json:"vram_fit"`
+	SLOSamples   int64   `json:"slo_samples"`
+	SLOMet       int64   `json:"slo_met"`
+}
+
+// OptimizerState is the intelligence optimizer's learned state.
+type OptimizerState struct {
+	Models        []ModelPopularityDetail   `json:"models"`
+	Affinities    []NodeModelAffinityRecord `json:"affinities"`
+	LastRun       time.Time                 `json:"last_run"`
+	Optimizations int64                     `json:"optimizations"`
+}
+
+// SaveOptimizerState replaces the stored optimizer state with st in one
+// transaction.
+func (db *DB) SaveOptimizerState(st OptimizerState) error {
+	tx, err := db.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, table := range []string{"model_popularity_detail", "node_model_affinity"} {
+		if _, err := tx.Exec(`DELETE FROM ` + table); err != nil {
+			return err
+		}
+	}
+
+	models, err := tx.Prepare(
+		`INSERT INTO model_popularity_detail (model_name, total_reqs, last_requested, latency_sum, latency_count, hourly, recent, recent_hours)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+	)
+	if err != nil {
+		return err
+	}
+	defer models.Close()
+	for _, m := range st.Models {
+		hourly, _ := json.Marshal(m.Hourly)
+		recent, _ := json.Marshal(m.Recent)
+		hours, _ := json.Marshal(m.RecentHours)
+		if _, err := models.Exec(m.ModelName, m.TotalReqs, m.LastRequested.Unix(), m.LatencySum, m.LatencyCount,
+			string(hourly), string(recent), string(hours)); err != nil {
+			return fmt.Errorf("model %s: %w", m.ModelName, err)
+		}
+	}
+
+	affinities, err := tx.Prepare(
+		`INSERT INTO node_model_affinity (node_id, model_name, requests, cache_hits, cache_misses, latency_sum, latency_count, vram_fit, slo_samples, slo_met)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+	)
+	if err != nil {
+		return err
+	}
+	defer affinities.Close()
+	for _, a := range st.Affinities {
+		if _, err := affinities.Exec(a.NodeID, a.ModelName, a.Requests, a.CacheHits, a.CacheMisses,
+			a.LatencySum, a.LatencyCount, a.VRAMFit, a.SLOSamples, a.SLOMet); err != nil {
+			return fmt.Errorf("affinity %s/%s: %w", a.NodeID, a.ModelName, err)
+		}
+	}
+
+	if _, err := tx.Exec(
+		`INSERT INTO optimizer_cycles (id, last_run, optimizations) VALUES (1, ?, ?)
+		 ON CONFLICT(id) DO UPDATE SET last_run=excluded.last_run, optimizations=excluded.optimizations`,
+		st.LastRun.Unix(), st.Optimizations,
+	); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// LoadOptimizerState returns the stored optimizer state; it is empty if
+// none was saved.
+func (db *DB) LoadOptimizerState() (OptimizerState, error) {
+	var st OptimizerState
+
+	rows, err := db.db.Query(
+		`SELECT model_name, total_reqs, last_requested, latency_sum, latency_count, hourly, recent, recent_hours
+		 FROM model_popularity_detail ORDER BY model_name`,
+	)
+	if err != nil {
+		return st, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var m ModelPopularityDetail
+		var last int64
+		var hourly, recent, hours string
+		if err := rows.Scan(&m.ModelName, &m.TotalReqs, &last, &m.LatencySum, &m.LatencyCount, &hourly, &recent, &hours); err != nil {
+			return st, err
+		}
+		for _, f := range []struct {
+			raw string
+			dst *[24]int64
+		}{{hourly, &m.Hourly}, {recent, &m.Recent}, {hours, &m.RecentHours}} {
+			if err := json.Unmarshal([]byte(f.raw), f.dst); err != nil {
+				return st, fmt.Errorf("decode popularity of %s: %w", m.ModelName, err)
+			}
+		}
+		m.LastRequested = time.Unix(last, 0)
+		st.Models = append(st.Models, m)
+	}
+	if err := rows.Err(); err != nil {
+		return st, err
+	}
+
+	arows, err := db.db.Query(
+		`SELECT node_id, model_name, requests, cache_hits, cache_misses, latency_sum, latency_count, vram_fit, slo_samples, slo_met
+		 FROM node_model_affinity ORDER BY model_name, node_id`,
+	)
+	if err != nil {
+		return st, err
+	}
+	defer arows.Close()
+	for arows.Next() {
+		var a NodeModelAffinityRecord
+		if err := arows.Scan(&a.NodeID, &a.ModelName, &a.Requests, &a.CacheHits, &a.CacheMisses,
+			&a.LatencySum, &a.LatencyCount, &a.VRAMFit, &a.SLOSamples, &a.SLOMet); err != nil {
+			return st, err
+		}
+		st.Affinities = append(st.Affinities, a)
+	}
+	if err := arows.Err(); err != nil {
+		return st, err
+	}
+
+	var last int64
+	err = db.db.QueryRow(`SELECT last_run, optimizations FROM optimizer_cycles WHERE id = 1`).Scan(&last, &st.Optimizations)
+	switch {
+	case err == sql.ErrNoRows:
+		return st, nil
+	case err != nil:
+		return st, err
 	}
+	st.LastRun = time.Unix(last, 0)
+	return st, nil
 }
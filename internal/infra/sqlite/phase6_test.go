@@ -17,6 +17,10 @@ func TestPhase6Migrations_TablesExist(t *testing.T) {
 		"healing_incidents",
 		"model_placements",
 		"model_retirement_log",
+		"model_retirement_workflow",
+		"model_popularity_detail",
+		"node_model_affinity",
+		"optimizer_cycles",
 	}
 	for _, tbl := range tables {
 		t.Run(tbl, func(t *testing.T) {
@@ -193,6 +197,95 @@ func TestModelPlacements_InsertQuery(t *testing.T) {
 	}
 }
 
+func TestModelPlacements_UpsertList(t *testing.T) {
+	db := newTestDB(t)
+	created := time.Unix(1700000000, 0)
+
+	rec := PlacementRecord{
+		RecID: "abc-0", Type: "MOVE", ModelName: "llama-3", FromNode: "node-B", ToNode: "node-A",
+		Score: 0.72, Reason: "higher affinity", Status: "PENDING", CreatedAt: created, UpdatedAt: created,
+	}
+	if err := db.UpsertPlacement(rec); err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+	rec.Status, rec.Detail, rec.UpdatedAt = "FAILED", "disk full", created.Add(time.Hour)
+	if err := db.UpsertPlacement(rec); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	second := PlacementRecord{RecID: "abc-1", Type: "PLACE", ModelName: "mistral", ToNode: "node-C",
+		Status: "PENDING", CreatedAt: created.Add(time.Minute), UpdatedAt: created.Add(time.Minute)}
+	if err := db.UpsertPlacement(second); err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+
+	got, err := db.ListPlacements(10)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(got) != 2 || got[0].RecID != "abc-0" || got[1].RecID != "abc-1" {
+		t.Fatalf("list = %+v, want abc-0 then abc-1", got)
+	}
+	if got[0].Status != "FAILED" || got[0].Detail != "disk full" || !got[0].UpdatedAt.Equal(rec.UpdatedAt) {
+		t.Errorf("abc-0 = %+v, want the updated outcome", got[0])
+	}
+	if got, _ := db.ListPlacements(1); len(got) != 1 || got[0].RecID != "abc-1" {
+		t.Errorf("ListPlacements(1) = %+v, want the newest", got)
+	}
+}
+
+// ─── Optimizer State ────────────────────────────────────────────────────────
+
+func TestOptimizerState_SaveLoad(t *testing.T) {
+	db := newTestDB(t)
+
+	empty, err := db.LoadOptimizerState()
+	if err != nil || len(empty.Models) != 0 || !empty.LastRun.IsZero() || empty.Optimizations != 0 {
+		t.Fatalf("empty state = %+v, %v", empty, err)
+	}
+
+	last := time.Unix(1700000000, 0)
+	st := OptimizerState{
+		Models: []ModelPopularityDetail{{
+			ModelName: "llama-3", TotalReqs: 42, LastRequested: last, LatencySum: 840, LatencyCount: 42,
+		}},
+		Affinities: []NodeModelAffinityRecord{
+			{NodeID: "node-A", ModelName: "llama-3", Requests: 30, CacheHits: 27, CacheMisses: 3, LatencySum: 450, LatencyCount: 30, VRAMFit: 0.4},
+			{NodeID: "node-B", ModelName: "llama-3", Requests: 12, SLOSamples: 12, SLOMet: 9},
+		},
+		LastRun:       last,
+		Optimizations: 3,
+	}
+	st.Models[0].Hourly[14] = 42
+	st.Models[0].Recent[5] = 7
+	st.Models[0].RecentHours[5] = 472222
+	if err := db.SaveOptimizerState(st); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	got, err := db.LoadOptimizerState()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(got.Models) != 1 || got.Models[0] != st.Models[0] {
+		t.Errorf("models = %+v, want %+v", got.Models, st.Models)
+	}
+	if len(got.Affinities) != 2 || got.Affinities[0] != st.Affinities[0] || got.Affinities[1] != st.Affinities[1] {
+		t.Errorf("affinities = %+v", got.Affinities)
+	}
+	if !got.LastRun.Equal(last) || got.Optimizations != 3 {
+		t.Errorf("cycles = %v, %d", got.LastRun, got.Optimizations)
+	}
+
+	// A later save replaces everything.
+	if err := db.SaveOptimizerState(OptimizerState{LastRun: last.Add(time.Hour), Optimizations: 4}); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	got, _ = db.LoadOptimizerState()
+	if len(got.Models) != 0 || len(got.Affinities) != 0 || got.Optimizations != 4 {
+		t.Errorf("after replace = %+v", got)
+	}
+}
+
 // ─── model_retirement_log ───────────────────────────────────────────────────
 
 func TestModelRetirementLog_InsertQuery(t *testing.T) {
@@ -227,6 +320,33 @@ func TestModelRetirementLog_InsertQuery(t *testing.T) {
 	}
 }
 
+func TestRetirementWorkflow_UpsertListDelete(t *testing.T) {
+	db := newTestDB(t)
+	at := time.Unix(1700000000, 0)
+
+	for _, r := range []RetirementWorkflowRecord{
+		{ModelName: "old-bert", State: "ANNOUNCED", Status: []byte(`{"state":"ANNOUNCED"}`), UpdatedAt: at},
+		{ModelName: "old-bert", State: "VETOED", Status: []byte(`{"state":"VETOED"}`), UpdatedAt: at.Add(time.Hour)},
+		{ModelName: "gpt2", State: "CANDIDATE", Status: []byte(`{}`), UpdatedAt: at},
+	} {
+		if err := db.UpsertRetirementWorkflow(r); err != nil {
+			t.Fatalf("upsert %s: %v", r.ModelName, err)
+		}
+	}
+	if err := db.DeleteRetirementWorkflow("gpt2"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+
+	got, err := db.ListRetirementWorkflow()
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(got) != 1 || got[0].State != "VETOED" || string(got[0].Status) != `{"state":"VETOED"}` ||
+		!got[0].UpdatedAt.Equal(at.Add(time.Hour)) {
+		t.Errorf("list = %+v, want old-bert vetoed", got)
+	}
+}
+
 // ─── Index usage checks ─────────────────────────────────────────────────────
 
 func TestPhase6_IndicesExist(t *testing.T) {
@@ -238,6 +358,7 @@ func TestPhase6_IndicesExist(t *testing.T) {
 		"idx_heal_node", "idx_heal_state", "idx_heal_type",
 		"idx_place_model", "idx_place_time",
 		"idx_retire_model", "idx_retire_time",
+		"idx_affinity_model",
 	}
 	for _, idx := range indices {
 		t.Run(idx, func(t *testing.T) {
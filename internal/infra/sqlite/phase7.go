@@ -0,0 +1,1135 @@
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
+)
+
+// Phase7Migrations returns the DDL for Phase 7 persistence.
+// Called from db.go's migrate() after Phase 6 migrations.
+//
+// Tables:
+//   - task_attestations: signed task result attestations (dispute evidence)
+//   - audit_log:         hash-chained audit trail of privileged operations
+//   - model_verifications: latest weight integrity check per model
+//   - model_benchmarks:  latest tokens/sec and time to first token per model
+//   - model_metadata:    context window, quantization, modalities, tool
+//     calling and license per model
+//   - agent_runs:        checkpointed multi-step agent runs
+//   - vectors:           stored embeddings, grouped by collection
+//   - task_events:       event-sourced task journal
+//   - namespaces:        tenants sharing the node
+//   - namespace_keys:    hashed per-namespace API keys
+//   - payment_events:    processed payment webhook deliveries
+//   - invoices:          receipts for credit purchases
+//   - blocklist_entries: signed network blocklist entries
+//   - param_changes:     runtime parameter change log, replayed on start
+func Phase7Migrations() []string {
+	return []string{
+		// ─── Task Attestations ──────────────────────────────────────────
+
+		// Signed (task, input, output, timing) statements from executing nodes
+		`CREATE TABLE IF NOT EXISTS task_attestations (
+			task_id        TEXT PRIMARY KEY,
+			node_id        TEXT NOT NULL,
+			input_digest   TEXT NOT NULL,
+			output_digest  TEXT NOT NULL,
+			started_at     INTEGER NOT NULL,
+			completed_at   INTEGER NOT NULL,
+			signature      BLOB NOT NULL,
+			verified       BOOLEAN NOT NULL DEFAULT 0,
+			disputed       BOOLEAN NOT NULL DEFAULT 0,
+			dispute_reason TEXT DEFAULT '',
+			recorded_at    INTEGER NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_attest_node ON task_attestations(node_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_attest_disputed ON task_attestations(disputed)`,
+
+		// ─── Audit Log ──────────────────────────────────────────────────
+
+		// Append-only, hash-chained privileged operation log
+		`CREATE TABLE IF NOT EXISTS audit_log (
+			seq       INTEGER PRIMARY KEY,
+			timestamp INTEGER NOT NULL,
+			category  TEXT NOT NULL,
+			actor     TEXT NOT NULL,
+			action    TEXT NOT NULL,
+			target    TEXT DEFAULT '',
+			details   TEXT DEFAULT '',
+			prev_hash TEXT NOT NULL,
+			hash      TEXT NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_audit_cat ON audit_log(category)`,
+		`CREATE INDEX IF NOT EXISTS idx_audit_ts ON audit_log(timestamp)`,
+
+		// ─── Model Integrity ────────────────────────────────────────────
+
+		// Most recent digest check per model, with a running failure count
+		`CREATE TABLE IF NOT EXISTS model_verifications (
+			model           TEXT PRIMARY KEY,
+			expected_digest TEXT NOT NULL,
+			actual_digest   TEXT DEFAULT '',
+			verified        BOOLEAN NOT NULL,
+			error           TEXT DEFAULT '',
+			checked_at      INTEGER NOT NULL,
+			failures        INTEGER NOT NULL DEFAULT 0
+		)`,
+
+		// Latest generation speed measured per model on this node
+		`CREATE TABLE IF NOT EXISTS model_benchmarks (
+			model          TEXT PRIMARY KEY,
+			tokens_per_sec REAL NOT NULL,
+			ttft_ms        REAL NOT NULL,
+			tokens         INTEGER NOT NULL,
+			runs           INTEGER NOT NULL,
+			measured_at    INTEGER NOT NULL
+		)`,
+
+		// Capabilities recorded when a model is pulled
+		`CREATE TABLE IF NOT EXISTS model_metadata (
+			model          TEXT PRIMARY KEY,
+			context_length INTEGER NOT NULL DEFAULT 0,
+			quantization   TEXT NOT NULL DEFAULT '',
+			modalities     TEXT NOT NULL DEFAULT '[]',
+			tool_calling   BOOLEAN NOT NULL DEFAULT 0,
+			license        TEXT NOT NULL DEFAULT '',
+			updated_at     INTEGER NOT NULL
+		)`,
+
+		// ─── Agent Runs ─────────────────────────────────────────────────
+
+		// One row per run, rewritten after every step (steps as JSON)
+		`CREATE TABLE IF NOT EXISTS agent_runs (
+			id         TEXT PRIMARY KEY,
+			name       TEXT NOT NULL DEFAULT '',
+			status     TEXT NOT NULL,
+			budget     INTEGER NOT NULL DEFAULT 0,
+			spent      INTEGER NOT NULL DEFAULT 0,
+			steps      TEXT NOT NULL,
+			error      TEXT DEFAULT '',
+			trace_id   TEXT DEFAULT '',
+			created_at INTEGER NOT NULL,
+			updated_at INTEGER NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_agent_runs_status ON agent_runs(status)`,
+
+		// ─── Vector Store ───────────────────────────────────────────────
+
+		// Embeddings as little-endian float32 blobs, keyed per collection
+		`CREATE TABLE IF NOT EXISTS vectors (
+			collection TEXT NOT NULL,
+			id         TEXT NOT NULL,
+			model      TEXT NOT NULL,
+			text       TEXT NOT NULL DEFAULT '',
+			vector     BLOB NOT NULL,
+			created_at INTEGER NOT NULL,
+			PRIMARY KEY (collection, id)
+		)`,
+
+		// ─── Task Journal ───────────────────────────────────────────────
+
+		// Append-only lifecycle events; at most one PAID event per task
+		`CREATE TABLE IF NOT EXISTS task_events (
+			seq       INTEGER PRIMARY KEY AUTOINCREMENT,
+			task_id   TEXT NOT NULL,
+			type      TEXT NOT NULL,
+			task_type TEXT NOT NULL DEFAULT '',
+			node_id   TEXT NOT NULL DEFAULT '',
+			credits   INTEGER NOT NULL DEFAULT 0,
+			detail    TEXT NOT NULL DEFAULT '',
+			timestamp INTEGER NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_task_events_task ON task_events(task_id, seq)`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_task_events_paid ON task_events(task_id) WHERE type = 'PAID'`,
+
+		// ─── Namespaces ─────────────────────────────────────────────────
+
+		// Tenants sharing the node; models is a JSON allowlist
+		`CREATE TABLE IF NOT EXISTS namespaces (
+			name           TEXT PRIMARY KEY,
+			models         TEXT NOT NULL DEFAULT '[]',
+			rate_limit_rpm INTEGER NOT NULL DEFAULT 0,
+			created_at     INTEGER NOT NULL
+		)`,
+
+		// API keys, stored only as SHA-256 hashes
+		`CREATE TABLE IF NOT EXISTS namespace_keys (
+			id         TEXT PRIMARY KEY,
+			namespace  TEXT NOT NULL,
+			hash       TEXT NOT NULL UNIQUE,
+			created_at INTEGER NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_namespace_keys_ns ON namespace_keys(namespace)`,
+
+		// ─── Billing ────────────────────────────────────────────────────
+
+		// Processed payment webhook deliveries, keyed by provider event ID
+		`CREATE TABLE IF NOT EXISTS payment_events (
+			id          TEXT PRIMARY KEY,
+			provider    TEXT NOT NULL,
+			type        TEXT NOT NULL,
+			payload     TEXT NOT NULL,
+			received_at INTEGER NOT NULL
+		)`,
+
+		// Receipts for credit purchases
+		`CREATE TABLE IF NOT EXISTS invoices (
+			id           TEXT PRIMARY KEY,
+			event_id     TEXT NOT NULL,
+			provider     TEXT NOT NULL,
+			namespace    TEXT NOT NULL,
+			customer     TEXT NOT NULL DEFAULT '',
+			amount_minor INTEGER NOT NULL,
+			currency     TEXT NOT NULL,
+			credits      INTEGER NOT NULL,
+			reference    TEXT NOT NULL DEFAULT '',
+			status       TEXT NOT NULL,
+			issued_at    INTEGER NOT NULL,
+			refunded_at  INTEGER NOT NULL DEFAULT 0
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_invoices_ns ON invoices(namespace, issued_at)`,
+
+		// ─── Blocklist ──────────────────────────────────────────────────
+
+		// Latest signed entry per blocked node or model, as gossiped JSON
+		`CREATE TABLE IF NOT EXISTS blocklist_entries (
+			entry_key  TEXT PRIMARY KEY,
+			entry      TEXT NOT NULL,
+			issued_at  INTEGER NOT NULL,
+			expires_at INTEGER NOT NULL DEFAULT 0
+		)`,
+
+		// ─── Runtime Parameters ─────────────────────────────────────────
+
+		// Every applied parameter change, in order
+		`CREATE TABLE IF NOT EXISTS param_changes (
+			id         INTEGER PRIMARY KEY,
+			key        TEXT NOT NULL,
+			old_value  TEXT NOT NULL,
+			new_value  TEXT NOT NULL,
+			source     TEXT NOT NULL,
+			applied_at INTEGER NOT NULL
+		)`,
+	}
+}
+
+// Phase7Columns returns the columns Phase 7 adds to existing tables: the
+// namespace label on tasks and ledger entries, and the ID and outcome of
+// placement recommendations.
+func Phase7Columns() []Column {
+	return []Column{
+		{Table: "tasks", Column: "namespace", Def: "TEXT NOT NULL DEFAULT ''"},
+		{Table: "credit_ledger", Column: "namespace", Def: "TEXT NOT NULL DEFAULT ''", Index: true},
+		{Table: "model_placements", Column: "rec_id", Def: "TEXT NOT NULL DEFAULT ''", Index: true},
+		{Table: "model_placements", Column: "status", Def: "TEXT NOT NULL DEFAULT ''"},
+		{Table: "model_placements", Column: "detail", Def: "TEXT NOT NULL DEFAULT ''"},
+		{Table: "model_placements", Column: "updated_at", Def: "INTEGER NOT NULL DEFAULT 0"},
+	}
+}
+
+// ─── Task Attestations ──────────────────────────────────────────────────────
+
+// AttestationRecord is a persisted attestation with its verification state.
+type AttestationRecord struct {
+	domain.Attestation
+	Verified      bool      `json:"verified"`
+	Disputed      bool      `json:"disputed"`
+	DisputeReason string    `json:"dispute_reason,omitempty"`
+	RecordedAt    time.Time `json:"recorded_at"`
+}
+
+// InsertAttestation persists an attestation. Re-recording the same task
+// updates the verified flag but never overwrites the signed fields.
+func (d *DB) InsertAttestation(a domain.Attestation, verified bool) error {
+	_, err := d.db.Exec(
+		`INSERT INTO task_attestations (task_id, node_id, input_digest, output_digest, started_at, completed_at, signature, verified, recorded_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		 ON CONFLICT(task_id) DO UPDATE SET verified = excluded.verified`,
+		a.TaskID, a.NodeID, a.InputDigest, a.OutputDigest,
+		a.StartedAt.UnixNano(), a.CompletedAt.UnixNano(), a.Signature,
+		verified, time.Now().Unix(),
+	)
+	return err
+}
+
+// GetAttestation returns the attestation for a task, or nil if none exists.
+func (d *DB) GetAttestation(taskID string) (*AttestationRecord, error) {
+	row := d.db.QueryRow(
+		`SELECT task_id, node_id, input_digest, output_digest, started_at, completed_at, signature, verified, disputed, dispute_reason, recorded_at
+		 FROM task_attestations WHERE task_id = ?`, taskID,
+	)
+	rec, err := scanAttestation(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return rec, err
+}
+
+// ListAttestationsByNode returns the most recent attestations signed by a node.
+func (d *DB) ListAttestationsByNode(nodeID string, limit int) ([]AttestationRecord, error) {
+	rows, err := d.db.Query(
+		`SELECT task_id, node_id, input_digest, output_digest, started_at, completed_at, signature, verified, disputed, dispute_reason, recorded_at
+		 FROM task_attestations WHERE node_id = ? ORDER BY completed_at DESC LIMIT ?`,
+		nodeID, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []AttestationRecord
+	for rows.Next() {
+		rec, err := scanAttestation(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *rec)
+	}
+	return out, rows.Err()
+}
+
+// MarkAttestationDisputed flags an attestation as disputed with a reason.
+// A task can be disputed once: a second dispute returns
+// domain.ErrAttestationDisputed and keeps the original reason.
+func (d *DB) MarkAttestationDisputed(taskID, reason string) error {
+	res, err := d.db.Exec(
+		`UPDATE task_attestations SET disputed = 1, dispute_reason = ? WHERE task_id = ? AND disputed = 0`,
+		reason, taskID,
+	)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n > 0 {
+		return nil
+	}
+	rec, err := d.GetAttestation(taskID)
+	if err != nil {
+		return err
+	}
+	if rec == nil {
+		return fmt.Errorf("%w: %s", domain.ErrAttestationNotFound, taskID)
+	}
+	return fmt.Errorf("%w: %s", domain.ErrAttestationDisputed, taskID)
+}
+
+func scanAttestation(s scanner) (*AttestationRecord, error) {
+	var rec AttestationRecord
+	var startedAt, completedAt, recordedAt int64
+	err := s.Scan(&rec.TaskID, &rec.NodeID, &rec.InputDigest, &rec.OutputDigest,
+		&startedAt, &completedAt, &rec.Signature,
+		&rec.Verified, &rec.Disputed, &rec.DisputeReason, &recordedAt)
+	if err != nil {
+		return nil, err
+	}
+	rec.StartedAt = time.Unix(0, startedAt)
+	rec.CompletedAt = time.Unix(0, completedAt)
+	rec.RecordedAt = time.Unix(recordedAt, 0)
+	return &rec, nil
+}
+
+// ─── Audit Log ──────────────────────────────────────────────────────────────
+
+// InsertAuditEntry appends a hash-chained audit entry.
+func (d *DB) InsertAuditEntry(e domain.AuditEntry) error {
+	_, err := d.db.Exec(
+		`INSERT INTO audit_log (seq, timestamp, category, actor, action, target, details, prev_hash, hash)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		e.Seq, e.Timestamp.UnixNano(), string(e.Category), e.Actor, e.Action,
+		e.Target, e.Details, e.PrevHash, e.Hash,
+	)
+	return err
+}
+
+// ListAuditEntries returns all audit entries in sequence order.
+func (d *DB) ListAuditEntries() ([]domain.AuditEntry, error) {
+	rows, err := d.db.Query(
+		`SELECT seq, timestamp, category, actor, action, target, details, prev_hash, hash
+		 FROM audit_log ORDER BY seq ASC`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []domain.AuditEntry
+	for rows.Next() {
+		var e domain.AuditEntry
+		var ts int64
+		var cat string
+		if err := rows.Scan(&e.Seq, &ts, &cat, &e.Actor, &e.Action,
+			&e.Target, &e.Details, &e.PrevHash, &e.Hash); err != nil {
+			return nil, err
+		}
+		e.Timestamp = time.Unix(0, ts)
+		e.Category = domain.AuditCategory(cat)
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
+
+// LastAuditEntry returns the entry with the highest sequence number, or nil
+// if the log is empty.
+func (d *DB) LastAuditEntry() (*domain.AuditEntry, error) {
+	var e domain.AuditEntry
+	var ts int64
+	var cat string
+	err := d.db.QueryRow(
+		`SELECT seq, timestamp, category, actor, action, target, details, prev_hash, hash
+		 FROM audit_log ORDER BY seq DESC LIMIT 1`,
+	).Scan(&e.Seq, &ts, &cat, &e.Actor, &e.Action, &e.Target, &e.Details, &e.PrevHash, &e.Hash)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	e.Timestamp = time.Unix(0, ts)
+	e.Category = domain.AuditCategory(cat)
+	return &e, nil
+}
+
+// ─── Model Integrity ────────────────────────────────────────────────────────
+
+// RecordModelVerification stores the latest integrity check for a model.
+// Failed checks increment the model's cumulative failure count.
+func (d *DB) RecordModelVerification(v domain.ModelVerification) error {
+	failed := 0
+	if !v.Verified {
+		failed = 1
+	}
+	_, err := d.db.Exec(
+		`INSERT INTO model_verifications (model, expected_digest, actual_digest, verified, error, checked_at, failures)
+		 VALUES (?, ?, ?, ?, ?, ?, ?)
+		 ON CONFLICT(model) DO UPDATE SET
+			expected_digest=excluded.expected_digest,
+			actual_digest=excluded.actual_digest,
+			verified=excluded.verified,
+			error=excluded.error,
+			checked_at=excluded.checked_at,
+			failures=model_verifications.failures + excluded.failures`,
+		v.Model, v.ExpectedDigest, v.ActualDigest, v.Verified, v.Error,
+		v.CheckedAt.Unix(), failed,
+	)
+	return err
+}
+
+// GetModelVerification returns the latest check for a model, or nil if the
+// model has never been verified.
+func (d *DB) GetModelVerification(model string) (*domain.ModelVerification, error) {
+	row := d.db.QueryRow(
+		`SELECT model, expected_digest, actual_digest, verified, error, checked_at, failures
+		 FROM model_verifications WHERE model = ?`, model,
+	)
+	v, err := scanModelVerification(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return v, err
+}
+
+// ListModelVerifications returns the latest check for every model, failures first.
+func (d *DB) ListModelVerifications() ([]domain.ModelVerification, error) {
+	rows, err := d.db.Query(
+		`SELECT model, expected_digest, actual_digest, verified, error, checked_at, failures
+		 FROM model_verifications ORDER BY verified ASC, model ASC`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []domain.ModelVerification
+	for rows.Next() {
+		v, err := scanModelVerification(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, *v)
+	}
+	return out, rows.Err()
+}
+
+func scanModelVerification(s scanner) (*domain.ModelVerification, error) {
+	var v domain.ModelVerification
+	var checkedAt int64
+	if err := s.Scan(&v.Model, &v.ExpectedDigest, &v.ActualDigest, &v.Verified,
+		&v.Error, &checkedAt, &v.Failures); err != nil {
+		return nil, err
+	}
+	v.CheckedAt = time.Unix(checkedAt, 0)
+	return &v, nil
+}
+
+// ─── Model Benchmarks ───────────────────────────────────────────────────────
+
+// UpsertModelBenchmark stores the latest benchmark of a model, replacing
+// the previous one.
+func (d *DB) UpsertModelBenchmark(b domain.ModelBenchmark) error {
+	_, err := d.db.Exec(
+		`INSERT INTO model_benchmarks (model, tokens_per_sec, ttft_ms, tokens, runs, measured_at)
+		 VALUES (?, ?, ?, ?, ?, ?)
+		 ON CONFLICT(model) DO UPDATE SET
+			tokens_per_sec=excluded.tokens_per_sec,
+			ttft_ms=excluded.ttft_ms,
+			tokens=excluded.tokens,
+			runs=excluded.runs,
+			measured_at=excluded.measured_at`,
+		b.Model, b.TokensPerSec, b.TTFTMs, b.Tokens, b.Runs, b.MeasuredAt.UnixNano(),
+	)
+	return err
+}
+
+// ListModelBenchmarks returns the latest benchmark of every model, by name.
+func (d *DB) ListModelBenchmarks() ([]domain.ModelBenchmark, error) {
+	rows, err := d.db.Query(
+		`SELECT model, tokens_per_sec, ttft_ms, tokens, runs, measured_at
+		 FROM model_benchmarks ORDER BY model ASC`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []domain.ModelBenchmark
+	for rows.Next() {
+		var b domain.ModelBenchmark
+		var measuredAt int64
+		if err := rows.Scan(&b.Model, &b.TokensPerSec, &b.TTFTMs, &b.Tokens, &b.Runs, &measuredAt); err != nil {
+			return nil, err
+		}
+		b.MeasuredAt = time.Unix(0, measuredAt)
+		out = append(out, b)
+	}
+	return out, rows.Err()
+}
+
+// ─── Model Metadata ─────────────────────────────────────────────────────────
+
+// UpsertModelMetadata stores a model's metadata, replacing what was
+// recorded before.
+func (d *DB) UpsertModelMetadata(m domain.ModelMetadata) error {
+	modalities, err := json.Marshal(m.Modalities)
+	if err != nil {
+		return err
+	}
+	_, err = d.db.Exec(
+		`INSERT INTO model_metadata (model, context_length, quantization, modalities, tool_calling, license, updated_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?)
+		 ON CONFLICT(model) DO UPDATE SET
+			context_length=excluded.context_length,
+			quantization=excluded.quantization,
+			modalities=excluded.modalities,
+			tool_calling=excluded.tool_calling,
+			license=excluded.license,
+			updated_at=excluded.updated_at`,
+		m.Model, m.ContextLength, m.Quantization, string(modalities), m.ToolCalling, m.License, m.UpdatedAt.Unix(),
+	)
+	return err
+}
+
+// GetModelMetadata returns a model's metadata, or nil if none is recorded.
+func (d *DB) GetModelMetadata(model string) (*domain.ModelMetadata, error) {
+	row := d.db.QueryRow(
+		`SELECT model, context_length, quantization, modalities, tool_calling, license, updated_at
+		 FROM model_metadata WHERE model = ?`, model,
+	)
+	m, err := scanModelMetadata(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return m, err
+}
+
+// ListModelMetadata returns the metadata of every model, keyed by name.
+func (d *DB) ListModelMetadata() (map[string]domain.ModelMetadata, error) {
+	rows, err := d.db.Query(
+		`SELECT model, context_length, quantization, modalities, tool_calling, license, updated_at
+		 FROM model_metadata`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make(map[string]domain.ModelMetadata)
+	for rows.Next() {
+		m, err := scanModelMetadata(rows)
+		if err != nil {
+			return nil, err
+		}
+		out[m.Model] = *m
+	}
+	return out, rows.Err()
+}
+
+// DeleteModelMetadata forgets a model's metadata.
+func (d *DB) DeleteModelMetadata(model string) error {
+	_, err := d.db.Exec(`DELETE FROM model_metadata WHERE model = ?`, model)
+	return err
+}
+
+func scanModelMetadata(s scanner) (*domain.ModelMetadata, error) {
+	var m domain.ModelMetadata
+	var modalities string
+	var updatedAt int64
+	if err := s.Scan(&m.Model, &m.ContextLength, &m.Quantization, &modalities, &m.ToolCalling, &m.License, &updatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(modalities), &m.Modalities); err != nil {
+		return nil, fmt.Errorf("decode modalities of %s: %w", m.Model, err)
+	}
+	m.UpdatedAt = time.Unix(updatedAt, 0)
+	return &m, nil
+}
+
+// ─── Agent Runs ─────────────────────────────────────────────────────────────
+
+// SaveAgentRun inserts or replaces an agent run checkpoint.
+func (d *DB) SaveAgentRun(r domain.AgentRun) error {
+	steps, err := json.Marshal(r.Steps)
+	if err != nil {
+		return fmt.Errorf("encode agent steps: %w", err)
+	}
+	_, err = d.db.Exec(
+		`INSERT INTO agent_runs (id, name, status, budget, spent, steps, error, trace_id, created_at, updated_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		 ON CONFLICT(id) DO UPDATE SET
+			status = excluded.status, spent = excluded.spent, steps = excluded.steps,
+			error = excluded.error, trace_id = excluded.trace_id, updated_at = excluded.updated_at`,
+		r.ID, r.Name, string(r.Status), r.Budget, r.Spent, string(steps), r.Error, r.TraceID,
+		r.CreatedAt.UnixNano(), r.UpdatedAt.UnixNano(),
+	)
+	return err
+}
+
+// ListAgentRuns returns all persisted agent runs, oldest first.
+func (d *DB) ListAgentRuns() ([]domain.AgentRun, error) {
+	rows, err := d.db.Query(
+		`SELECT id, name, status, budget, spent, steps, error, trace_id, created_at, updated_at
+		 FROM agent_runs ORDER BY created_at ASC`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []domain.AgentRun
+	for rows.Next() {
+		var (
+			r                domain.AgentRun
+			status, steps    string
+			created, updated int64
+		)
+		if err := rows.Scan(&r.ID, &r.Name, &status, &r.Budget, &r.Spent, &steps,
+			&r.Error, &r.TraceID, &created, &updated); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(steps), &r.Steps); err != nil {
+			return nil, fmt.Errorf("decode agent run %s: %w", r.ID, err)
+		}
+		r.Status = domain.AgentRunStatus(status)
+		r.CreatedAt = time.Unix(0, created)
+		r.UpdatedAt = time.Unix(0, updated)
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
+
+// ─── Vector Store ───────────────────────────────────────────────────────────
+
+// UpsertVectors inserts or replaces stored embeddings in one transaction.
+func (d *DB) UpsertVectors(recs []domain.VectorRecord) error {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(
+		`INSERT OR REPLACE INTO vectors (collection, id, model, text, vector, created_at)
+		 VALUES (?, ?, ?, ?, ?, ?)`,
+	)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	for _, r := range recs {
+		if _, err := stmt.Exec(r.Collection, r.ID, r.Model, r.Text,
+			encodeVector(r.Vector), r.CreatedAt.Unix()); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// ListVectors returns every stored embedding.
+func (d *DB) ListVectors() ([]domain.VectorRecord, error) {
+	rows, err := d.db.Query(
+		`SELECT collection, id, model, text, vector, created_at FROM vectors ORDER BY collection, id`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []domain.VectorRecord
+	for rows.Next() {
+		var (
+			r       domain.VectorRecord
+			blob    []byte
+			created int64
+		)
+		if err := rows.Scan(&r.Collection, &r.ID, &r.Model, &r.Text, &blob, &created); err != nil {
+			return nil, err
+		}
+		r.Vector = decodeVector(blob)
+		r.CreatedAt = time.Unix(created, 0)
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
+
+// DeleteVectorCollection removes a collection and returns how many vectors
+// it held.
+func (d *DB) DeleteVectorCollection(collection string) (int, error) {
+	res, err := d.db.Exec(`DELETE FROM vectors WHERE collection = ?`, collection)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+// ─── Task Journal ───────────────────────────────────────────────────────────
+
+// AppendTaskEvent appends a journal event and returns its sequence number.
+// A second PAID event for the same task is not written and returns
+// domain.ErrTaskAlreadyPaid.
+func (d *DB) AppendTaskEvent(e domain.TaskEvent) (int64, error) {
+	res, err := d.db.Exec(
+		`INSERT OR IGNORE INTO task_events (task_id, type, task_type, node_id, credits, detail, timestamp)
+		 VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		e.TaskID, string(e.Type), string(e.TaskType), e.NodeID, e.Credits, e.Detail, e.Timestamp.UnixNano(),
+	)
+	if err != nil {
+		return 0, err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return 0, err
+	} else if n == 0 {
+		return 0, domain.ErrTaskAlreadyPaid
+	}
+	return res.LastInsertId()
+}
+
+// ListTaskEvents returns up to limit events with a sequence number above
+// afterSeq, oldest first. limit <= 0 returns all of them.
+func (d *DB) ListTaskEvents(afterSeq int64, limit int) ([]domain.TaskEvent, error) {
+	if limit <= 0 {
+		limit = -1
+	}
+	rows, err := d.db.Query(
+		`SELECT seq, task_id, type, task_type, node_id, credits, detail, timestamp
+		 FROM task_events WHERE seq > ? ORDER BY seq LIMIT ?`, afterSeq, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return scanTaskEvents(rows)
+}
+
+// ListTaskEventsByTask returns one task's events, oldest first.
+func (d *DB) ListTaskEventsByTask(taskID string) ([]domain.TaskEvent, error) {
+	rows, err := d.db.Query(
+		`SELECT seq, task_id, type, task_type, node_id, credits, detail, timestamp
+		 FROM task_events WHERE task_id = ? ORDER BY seq`, taskID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return scanTaskEvents(rows)
+}
+
+func scanTaskEvents(rows *sql.Rows) ([]domain.TaskEvent, error) {
+	defer rows.Close()
+	var out []domain.TaskEvent
+	for rows.Next() {
+		var (
+			e             domain.TaskEvent
+			typ, taskType string
+			timestamp     int64
+		)
+		if err := rows.Scan(&e.Seq, &e.TaskID, &typ, &taskType, &e.NodeID, &e.Credits,
+			&e.Detail, &timestamp); err != nil {
+			return nil, err
+		}
+		e.Type = domain.TaskEventType(typ)
+		e.TaskType = domain.TaskType(taskType)
+		e.Timestamp = time.Unix(0, timestamp)
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
+
+func encodeVector(v []float32) []byte {
+	buf := make([]byte, 4*len(v))
+	for i, f := range v {
+		binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(f))
+	}
+	return buf
+}
+
+func decodeVector(buf []byte) []float32 {
+	v := make([]float32, len(buf)/4)
+	for i := range v {
+		v[i] = math.Float32frombits(binary.LittleEndian.Uint32(buf[4*i:]))
+	}
+	return v
+}
+
+// ─── Namespaces ─────────────────────────────────────────────────────────────
+
+// UpsertNamespace inserts or updates a namespace.
+func (d *DB) UpsertNamespace(ns domain.Namespace) error {
+	models, err := json.Marshal(ns.Models)
+	if err != nil {
+		return err
+	}
+	_, err = d.db.Exec(
+		`INSERT INTO namespaces (name, models, rate_limit_rpm, created_at) VALUES (?, ?, ?, ?)
+		 ON CONFLICT(name) DO UPDATE SET
+			models=excluded.models,
+			rate_limit_rpm=excluded.rate_limit_rpm`,
+		ns.Name, string(models), ns.RateLimitRPM, ns.CreatedAt.UnixNano(),
+	)
+	return err
+}
+
+// DeleteNamespace removes a namespace and its API keys. Its tasks and
+// ledger entries keep their label.
+func (d *DB) DeleteNamespace(name string) error {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(`DELETE FROM namespace_keys WHERE namespace = ?`, name); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM namespaces WHERE name = ?`, name); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ListNamespaces returns every namespace, by name.
+func (d *DB) ListNamespaces() ([]domain.Namespace, error) {
+	rows, err := d.db.Query(`SELECT name, models, rate_limit_rpm, created_at FROM namespaces ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []domain.Namespace
+	for rows.Next() {
+		var (
+			ns        domain.Namespace
+			models    string
+			createdAt int64
+		)
+		if err := rows.Scan(&ns.Name, &models, &ns.RateLimitRPM, &createdAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(models), &ns.Models); err != nil {
+			return nil, fmt.Errorf("namespace %s: decode models: %w", ns.Name, err)
+		}
+		ns.CreatedAt = time.Unix(0, createdAt)
+		out = append(out, ns)
+	}
+	return out, rows.Err()
+}
+
+// InsertNamespaceKey stores a namespace API key's hash.
+func (d *DB) InsertNamespaceKey(k domain.NamespaceKey) error {
+	_, err := d.db.Exec(
+		`INSERT INTO namespace_keys (id, namespace, hash, created_at) VALUES (?, ?, ?, ?)`,
+		k.ID, k.Namespace, k.Hash, k.CreatedAt.UnixNano(),
+	)
+	return err
+}
+
+// DeleteNamespaceKey revokes an API key.
+func (d *DB) DeleteNamespaceKey(id string) error {
+	_, err := d.db.Exec(`DELETE FROM namespace_keys WHERE id = ?`, id)
+	return err
+}
+
+// ListNamespaceKeys returns every namespace API key, oldest first.
+func (d *DB) ListNamespaceKeys() ([]domain.NamespaceKey, error) {
+	rows, err := d.db.Query(`SELECT id, namespace, hash, created_at FROM namespace_keys ORDER BY created_at`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []domain.NamespaceKey
+	for rows.Next() {
+		var (
+			k         domain.NamespaceKey
+			createdAt int64
+		)
+		if err := rows.Scan(&k.ID, &k.Namespace, &k.Hash, &createdAt); err != nil {
+			return nil, err
+		}
+		k.CreatedAt = time.Unix(0, createdAt)
+		out = append(out, k)
+	}
+	return out, rows.Err()
+}
+
+// SpentByNamespace sums the credits the node spent on behalf of each
+// namespace. Spending without a namespace label is not included.
+func (d *DB) SpentByNamespace() (map[string]int64, error) {
+	rows, err := d.db.Query(
+		`SELECT namespace, SUM(amount) FROM credit_ledger
+		 WHERE namespace != '' AND type = ? AND entry_type = ?
+		 GROUP BY namespace`,
+		string(domain.TxSpend), string(domain.EntryDebit),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make(map[string]int64)
+	for rows.Next() {
+		var (
+			ns    string
+			total int64
+		)
+		if err := rows.Scan(&ns, &total); err != nil {
+			return nil, err
+		}
+		out[ns] = total
+	}
+	return out, rows.Err()
+}
+
+// ─── Billing ────────────────────────────────────────────────────────────────
+
+// InsertPaymentEvent records a processed payment event. It reports false,
+// without error, when the event was already recorded.
+func (d *DB) InsertPaymentEvent(e domain.PaymentEvent) (bool, error) {
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return false, err
+	}
+	res, err := d.db.Exec(
+		`INSERT OR IGNORE INTO payment_events (id, provider, type, payload, received_at)
+		 VALUES (?, ?, ?, ?, ?)`,
+		e.ID, e.Provider, string(e.Type), string(payload), e.ReceivedAt.UnixNano(),
+	)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// HasPaymentEvent reports whether a payment event was already processed.
+func (d *DB) HasPaymentEvent(id string) (bool, error) {
+	var n int
+	err := d.db.QueryRow(`SELECT COUNT(*) FROM payment_events WHERE id = ?`, id).Scan(&n)
+	return n > 0, err
+}
+
+// UpsertInvoice inserts or updates an invoice.
+func (d *DB) UpsertInvoice(inv domain.Invoice) error {
+	var refundedAt int64
+	if !inv.RefundedAt.IsZero() {
+		refundedAt = inv.RefundedAt.UnixNano()
+	}
+	_, err := d.db.Exec(
+		`INSERT INTO invoices (id, event_id, provider, namespace, customer, amount_minor,
+			currency, credits, reference, status, issued_at, refunded_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		 ON CONFLICT(id) DO UPDATE SET
+			status=excluded.status,
+			refunded_at=excluded.refunded_at`,
+		inv.ID, inv.EventID, inv.Provider, inv.Namespace, inv.Customer, inv.AmountMinor,
+		inv.Currency, inv.Credits, inv.Reference, string(inv.Status), inv.IssuedAt.UnixNano(), refundedAt,
+	)
+	return err
+}
+
+const invoiceColumns = `id, event_id, provider, namespace, customer, amount_minor,
+	currency, credits, reference, status, issued_at, refunded_at`
+
+// GetInvoice returns an invoice, or nil if it does not exist.
+func (d *DB) GetInvoice(id string) (*domain.Invoice, error) {
+	rows, err := d.db.Query(`SELECT `+invoiceColumns+` FROM invoices WHERE id = ?`, id)
+	if err != nil {
+		return nil, err
+	}
+	out, err := scanInvoices(rows)
+	if err != nil || len(out) == 0 {
+		return nil, err
+	}
+	return &out[0], nil
+}
+
+// ListInvoices returns up to limit invoices, newest first. An empty
+// namespace lists every namespace's invoices.
+func (d *DB) ListInvoices(namespace string, limit int) ([]domain.Invoice, error) {
+	if limit <= 0 {
+		limit = -1
+	}
+	rows, err := d.db.Query(
+		`SELECT `+invoiceColumns+` FROM invoices
+		 WHERE ? = '' OR namespace = ? ORDER BY issued_at DESC, id LIMIT ?`,
+		namespace, namespace, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return scanInvoices(rows)
+}
+
+func scanInvoices(rows *sql.Rows) ([]domain.Invoice, error) {
+	defer rows.Close()
+	var out []domain.Invoice
+	for rows.Next() {
+		var (
+			inv                  domain.Invoice
+			status               string
+			issuedAt, refundedAt int64
+		)
+		if err := rows.Scan(&inv.ID, &inv.EventID, &inv.Provider, &inv.Namespace, &inv.Customer,
+			&inv.AmountMinor, &inv.Currency, &inv.Credits, &inv.Reference, &status,
+			&issuedAt, &refundedAt); err != nil {
+			return nil, err
+		}
+		inv.Status = domain.InvoiceStatus(status)
+		inv.IssuedAt = time.Unix(0, issuedAt)
+		if refundedAt != 0 {
+			inv.RefundedAt = time.Unix(0, refundedAt)
+		}
+		out = append(out, inv)
+	}
+	return out, rows.Err()
+}
+
+// ─── Blocklist ──────────────────────────────────────────────────────────────
+
+// BlocklistRecord is a persisted blocklist entry. Entry is the signed entry
+// as JSON; it is verified again when loaded.
+type BlocklistRecord struct {
+	Key       string
+	Entry     []byte
+	IssuedAt  time.Time
+	ExpiresAt time.Time // zero = never
+}
+
+// UpsertBlocklistEntry stores an entry, replacing an older one with the
+// same key. An entry issued no later than the stored one is ignored.
+func (d *DB) UpsertBlocklistEntry(r BlocklistRecord) error {
+	var expires int64
+	if !r.ExpiresAt.IsZero() {
+		expires = r.ExpiresAt.UnixNano()
+	}
+	_, err := d.db.Exec(
+		`INSERT INTO blocklist_entries (entry_key, entry, issued_at, expires_at)
+		 VALUES (?, ?, ?, ?)
+		 ON CONFLICT(entry_key) DO UPDATE SET
+			entry=excluded.entry,
+			issued_at=excluded.issued_at,
+			expires_at=excluded.expires_at
+		 WHERE excluded.issued_at > blocklist_entries.issued_at`,
+		r.Key, string(r.Entry), r.IssuedAt.UnixNano(), expires,
+	)
+	return err
+}
+
+// ListBlocklistEntries returns the entries not expired at now, by key.
+func (d *DB) ListBlocklistEntries(now time.Time) ([]BlocklistRecord, error) {
+	rows, err := d.db.Query(
+		`SELECT entry_key, entry, issued_at, expires_at FROM blocklist_entries
+		 WHERE expires_at = 0 OR expires_at > ? ORDER BY entry_key ASC`,
+		now.UnixNano(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []BlocklistRecord
+	for rows.Next() {
+		var r BlocklistRecord
+		var entry string
+		var issued, expires int64
+		if err := rows.Scan(&r.Key, &entry, &issued, &expires); err != nil {
+			return nil, err
+		}
+		r.Entry = []byte(entry)
+		r.IssuedAt = time.Unix(0, issued)
+		if expires != 0 {
+			r.ExpiresAt = time.Unix(0, expires)
+		}
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
+
+// PruneBlocklistEntries deletes entries expired at now and returns how many
+// were removed.
+func (d *DB) PruneBlocklistEntries(now time.Time) (int64, error) {
+	res, err := d.db.Exec(
+		`DELETE FROM blocklist_entries WHERE expires_at != 0 AND expires_at <= ?`, now.UnixNano(),
+	)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// ─── Runtime Parameters ─────────────────────────────────────────────────────
+
+// InsertParamChange appends a runtime parameter change.
+func (d *DB) InsertParamChange(c domain.ParamChange) error {
+	_, err := d.db.Exec(
+		`INSERT INTO param_changes (id, key, old_value, new_value, source, applied_at)
+		 VALUES (?, ?, ?, ?, ?, ?)`,
+		c.ID, c.Key, c.OldValue, c.NewValue, c.Source, c.AppliedAt.UnixNano(),
+	)
+	return err
+}
+
+// ListParamChanges returns every parameter change, oldest first.
+func (d *DB) ListParamChanges() ([]domain.ParamChange, error) {
+	rows, err := d.db.Query(
+		`SELECT id, key, old_value, new_value, source, applied_at
+		 FROM param_changes ORDER BY id ASC`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []domain.ParamChange
+	for rows.Next() {
+		var c domain.ParamChange
+		var at int64
+		if err := rows.Scan(&c.ID, &c.Key, &c.OldValue, &c.NewValue, &c.Source, &at); err != nil {
+			return nil, err
+		}
+		c.AppliedAt = time.Unix(0, at)
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
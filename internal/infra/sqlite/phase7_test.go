@@ -0,0 +1,524 @@
+package sqlite
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
+)
+
+// ─── Task Attestations ──────────────────────────────────────────────────────
+
+func TestAttestations_InsertGetList(t *testing.T) {
+	db := newTestDB(t)
+	start := time.Unix(1700000000, 500)
+
+	att := domain.Attestation{
+		TaskID:       "task-1",
+		NodeID:       "node-A",
+		InputDigest:  "sha256:in",
+		OutputDigest: "sha256:out",
+		StartedAt:    start,
+		CompletedAt:  start.Add(time.Second),
+		Signature:    []byte{1, 2, 3},
+	}
+	if err := db.InsertAttestation(att, true); err != nil {
+		t.Fatalf("InsertAttestation: %v", err)
+	}
+
+	rec, err := db.GetAttestation("task-1")
+	if err != nil || rec == nil {
+		t.Fatalf("GetAttestation = %v, %v", rec, err)
+	}
+	if !rec.CompletedAt.Equal(att.CompletedAt) {
+		t.Errorf("completed_at = %v, want %v (nanosecond precision)", rec.CompletedAt, att.CompletedAt)
+	}
+	if string(rec.Signature) != string(att.Signature) || !rec.Verified {
+		t.Errorf("record = %+v", rec)
+	}
+
+	list, err := db.ListAttestationsByNode("node-A", 10)
+	if err != nil || len(list) != 1 {
+		t.Fatalf("ListAttestationsByNode = %d, %v", len(list), err)
+	}
+
+	missing, err := db.GetAttestation("nope")
+	if err != nil || missing != nil {
+		t.Errorf("GetAttestation(nope) = %v, %v; want nil, nil", missing, err)
+	}
+}
+
+func TestAttestations_Dispute(t *testing.T) {
+	db := newTestDB(t)
+	_ = db.InsertAttestation(domain.Attestation{TaskID: "task-2", NodeID: "n", Signature: []byte{9}}, true)
+
+	if err := db.MarkAttestationDisputed("task-2", "wrong answer"); err != nil {
+		t.Fatalf("MarkAttestationDisputed: %v", err)
+	}
+	rec, _ := db.GetAttestation("task-2")
+	if !rec.Disputed || rec.DisputeReason != "wrong answer" {
+		t.Errorf("record = %+v, want disputed", rec)
+	}
+	if err := db.MarkAttestationDisputed("task-2", "changed my mind"); !errors.Is(err, domain.ErrAttestationDisputed) {
+		t.Errorf("second dispute = %v, want ErrAttestationDisputed", err)
+	}
+	if rec, _ := db.GetAttestation("task-2"); rec.DisputeReason != "wrong answer" {
+		t.Errorf("reason = %q after second dispute, want the original", rec.DisputeReason)
+	}
+	if err := db.MarkAttestationDisputed("missing", "x"); err == nil {
+		t.Error("disputing a missing attestation should fail")
+	}
+}
+
+// ─── Audit Log ──────────────────────────────────────────────────────────────
+
+func TestAuditLog_InsertList(t *testing.T) {
+	db := newTestDB(t)
+	ts := time.Unix(1700000000, 123)
+
+	for i, action := range []string{"proposal.execute", "federation.approve"} {
+		e := domain.AuditEntry{
+			Seq:       int64(i + 1),
+			Timestamp: ts.Add(time.Duration(i) * time.Second),
+			Category:  domain.AuditGovernance,
+			Actor:     "node-A",
+			Action:    action,
+			Target:    "t",
+			PrevHash:  "prev",
+			Hash:      "hash",
+		}
+		if err := db.InsertAuditEntry(e); err != nil {
+			t.Fatalf("InsertAuditEntry: %v", err)
+		}
+	}
+
+	entries, err := db.ListAuditEntries()
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("ListAuditEntries = %d, %v", len(entries), err)
+	}
+	if entries[0].Seq != 1 || entries[1].Action != "federation.approve" {
+		t.Errorf("entries out of order: %+v", entries)
+	}
+	if !entries[0].Timestamp.Equal(ts) {
+		t.Errorf("timestamp = %v, want %v (nanosecond precision)", entries[0].Timestamp, ts)
+	}
+
+	dup := entries[0]
+	if err := db.InsertAuditEntry(dup); err == nil {
+		t.Error("duplicate seq should be rejected")
+	}
+}
+
+// ─── Model Integrity ────────────────────────────────────────────────────────
+
+func TestModelVerifications_RecordAndCountFailures(t *testing.T) {
+	db := newTestDB(t)
+	now := time.Unix(1700000000, 0)
+
+	checks := []domain.ModelVerification{
+		{Model: "llama3", ExpectedDigest: "sha256:a", ActualDigest: "sha256:a", Verified: true, CheckedAt: now},
+		{Model: "llama3", ExpectedDigest: "sha256:a", ActualDigest: "sha256:b", Error: "digest mismatch", CheckedAt: now.Add(time.Minute)},
+		{Model: "llama3", ExpectedDigest: "sha256:a", Error: "blob missing", CheckedAt: now.Add(2 * time.Minute)},
+		{Model: "phi3", ExpectedDigest: "sha256:c", ActualDigest: "sha256:c", Verified: true, CheckedAt: now},
+	}
+	for _, v := range checks {
+		if err := db.RecordModelVerification(v); err != nil {
+			t.Fatalf("RecordModelVerification: %v", err)
+		}
+	}
+
+	got, err := db.GetModelVerification("llama3")
+	if err != nil || got == nil {
+		t.Fatalf("GetModelVerification = %v, %v", got, err)
+	}
+	if got.Verified || got.Failures != 2 || got.Error != "blob missing" {
+		t.Errorf("llama3 = %+v, want latest failure with 2 failures", got)
+	}
+
+	list, err := db.ListModelVerifications()
+	if err != nil || len(list) != 2 {
+		t.Fatalf("ListModelVerifications = %d, %v", len(list), err)
+	}
+	if list[0].Model != "llama3" {
+		t.Errorf("failures should sort first, got %s", list[0].Model)
+	}
+
+	missing, err := db.GetModelVerification("nope")
+	if err != nil || missing != nil {
+		t.Errorf("GetModelVerification(nope) = %v, %v; want nil, nil", missing, err)
+	}
+}
+
+func TestModelBenchmarks_UpsertList(t *testing.T) {
+	db := newTestDB(t)
+	now := time.Unix(1700000000, 0)
+
+	for _, b := range []domain.ModelBenchmark{
+		{Model: "phi3", TokensPerSec: 40, TTFTMs: 90, Tokens: 64, Runs: 3, MeasuredAt: now},
+		{Model: "llama3", TokensPerSec: 20, TTFTMs: 150, Tokens: 64, Runs: 3, MeasuredAt: now},
+		{Model: "llama3", TokensPerSec: 25, TTFTMs: 120, Tokens: 64, Runs: 3, MeasuredAt: now.Add(time.Hour)},
+	} {
+		if err := db.UpsertModelBenchmark(b); err != nil {
+			t.Fatalf("UpsertModelBenchmark: %v", err)
+		}
+	}
+
+	list, err := db.ListModelBenchmarks()
+	if err != nil || len(list) != 2 {
+		t.Fatalf("ListModelBenchmarks = %d, %v", len(list), err)
+	}
+	got := list[0]
+	if got.Model != "llama3" || got.TokensPerSec != 25 || got.TTFTMs != 120 || !got.MeasuredAt.Equal(now.Add(time.Hour)) {
+		t.Errorf("llama3 = %+v, want the latest measurement", got)
+	}
+}
+
+// ─── Model Metadata ─────────────────────────────────────────────────────────
+
+func TestModelMetadata_UpsertGetList(t *testing.T) {
+	db := newTestDB(t)
+	now := time.Unix(1700000000, 0)
+
+	if md, err := db.GetModelMetadata("llava"); err != nil || md != nil {
+		t.Fatalf("GetModelMetadata(unknown) = %+v, %v", md, err)
+	}
+	for _, md := range []domain.ModelMetadata{
+		{Model: "llava", ContextLength: 2048, Modalities: []string{"text"}, UpdatedAt: now},
+		{Model: "llava", ContextLength: 4096, Quantization: "Q4_K_M", Modalities: []string{"text", "image"},
+			License: "apache-2.0", UpdatedAt: now.Add(time.Hour)},
+		{Model: "mistral", ContextLength: 8192, Modalities: []string{"text"}, ToolCalling: true, UpdatedAt: now},
+	} {
+		if err := db.UpsertModelMetadata(md); err != nil {
+			t.Fatalf("UpsertModelMetadata: %v", err)
+		}
+	}
+
+	got, err := db.GetModelMetadata("llava")
+	if err != nil || got == nil {
+		t.Fatalf("GetModelMetadata = %+v, %v", got, err)
+	}
+	if got.ContextLength != 4096 || len(got.Modalities) != 2 || got.Modalities[1] != "image" ||
+		got.License != "apache-2.0" || !got.UpdatedAt.Equal(now.Add(time.Hour)) {
+		t.Errorf("llava = %+v, want the latest record", got)
+	}
+	all, err := db.ListModelMetadata()
+	if err != nil || len(all) != 2 || !all["mistral"].ToolCalling {
+		t.Errorf("ListModelMetadata = %+v, %v", all, err)
+	}
+
+	if err := db.DeleteModelMetadata("llava"); err != nil {
+		t.Fatalf("DeleteModelMetadata: %v", err)
+	}
+	if got, _ := db.GetModelMetadata("llava"); got != nil {
+		t.Errorf("llava still recorded: %+v", got)
+	}
+}
+
+// ─── Agent Runs ─────────────────────────────────────────────────────────────
+
+func TestAgentRuns_SaveList(t *testing.T) {
+	db := newTestDB(t)
+	now := time.Unix(1700000000, 0)
+
+	run := domain.AgentRun{
+		ID:     "run-1",
+		Name:   "summarize",
+		Status: domain.AgentRunRunning,
+		Budget: 100,
+		Steps: []domain.AgentStep{
+			{ID: "a", Tool: "inference", Args: []byte(`{"prompt":"hi"}`), Status: domain.AgentStepPending},
+		},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := db.SaveAgentRun(run); err != nil {
+		t.Fatalf("SaveAgentRun: %v", err)
+	}
+
+	// Checkpoint after the step completes
+	run.Steps[0].Status = domain.AgentStepDone
+	run.Steps[0].Output = []byte(`"hello"`)
+	run.Spent = 5
+	run.Status = domain.AgentRunCompleted
+	run.UpdatedAt = now.Add(time.Second)
+	if err := db.SaveAgentRun(run); err != nil {
+		t.Fatalf("SaveAgentRun (update): %v", err)
+	}
+
+	runs, err := db.ListAgentRuns()
+	if err != nil || len(runs) != 1 {
+		t.Fatalf("ListAgentRuns = %d, %v", len(runs), err)
+	}
+	got := runs[0]
+	if got.Status != domain.AgentRunCompleted || got.Spent != 5 || got.Budget != 100 {
+		t.Errorf("run = %+v", got)
+	}
+	if len(got.Steps) != 1 || got.Steps[0].Status != domain.AgentStepDone || string(got.Steps[0].Output) != `"hello"` {
+		t.Errorf("steps = %+v", got.Steps)
+	}
+	if !got.UpdatedAt.Equal(now.Add(time.Second)) {
+		t.Errorf("UpdatedAt = %v", got.UpdatedAt)
+	}
+}
+
+func TestVectors_UpsertListDelete(t *testing.T) {
+	db := newTestDB(t)
+	now := time.Unix(1700000000, 0)
+
+	recs := []domain.VectorRecord{
+		{Collection: "docs", ID: "a", Model: "nomic", Text: "alpha", Vector: []float32{1, -0.5, 3.25}, CreatedAt: now},
+		{Collection: "docs", ID: "b", Model: "nomic", Text: "beta", Vector: []float32{0, 1, 0}, CreatedAt: now},
+		{Collection: "notes", ID: "a", Model: "nomic", Text: "gamma", Vector: []float32{2, 2, 2}, CreatedAt: now},
+	}
+	if err := db.UpsertVectors(recs); err != nil {
+		t.Fatalf("UpsertVectors: %v", err)
+	}
+	// Re-embedding an ID replaces it
+	recs[0].Vector = []float32{9, 9, 9}
+	if err := db.UpsertVectors(recs[:1]); err != nil {
+		t.Fatalf("UpsertVectors (replace): %v", err)
+	}
+
+	got, err := db.ListVectors()
+	if err != nil || len(got) != 3 {
+		t.Fatalf("ListVectors = %d, %v", len(got), err)
+	}
+	if got[0].ID != "a" || got[0].Vector[0] != 9 || len(got[0].Vector) != 3 {
+		t.Errorf("vector a = %+v", got[0])
+	}
+	if got[1].Text != "beta" || got[1].Vector[1] != 1 {
+		t.Errorf("vector b = %+v", got[1])
+	}
+
+	n, err := db.DeleteVectorCollection("docs")
+	if err != nil || n != 2 {
+		t.Fatalf("DeleteVectorCollection = %d, %v", n, err)
+	}
+	got, _ = db.ListVectors()
+	if len(got) != 1 || got[0].Collection != "notes" {
+		t.Errorf("after delete = %+v", got)
+	}
+}
+
+// ─── Task Journal ───────────────────────────────────────────────────────────
+
+func TestTaskEvents_AppendList(t *testing.T) {
+	db := newTestDB(t)
+	ts := time.Unix(1700000000, 250)
+
+	for i, e := range []domain.TaskEvent{
+		{TaskID: "task-1", Type: domain.TaskEventSubmitted, TaskType: domain.TaskInference, Timestamp: ts},
+		{TaskID: "task-2", Type: domain.TaskEventSubmitted, Timestamp: ts},
+		{TaskID: "task-1", Type: domain.TaskEventPaid, NodeID: "node-A", Credits: 7, Timestamp: ts.Add(time.Second)},
+	} {
+		seq, err := db.AppendTaskEvent(e)
+		if err != nil || seq != int64(i+1) {
+			t.Fatalf("AppendTaskEvent(%d) = %d, %v", i, seq, err)
+		}
+	}
+	// The partial unique index allows one PAID event per task
+	if _, err := db.AppendTaskEvent(domain.TaskEvent{TaskID: "task-1", Type: domain.TaskEventPaid, Timestamp: ts}); err != domain.ErrTaskAlreadyPaid {
+		t.Errorf("second PAID = %v, want ErrTaskAlreadyPaid", err)
+	}
+
+	all, err := db.ListTaskEvents(0, 0)
+	if err != nil || len(all) != 3 {
+		t.Fatalf("ListTaskEvents(0, 0) = %d events, %v", len(all), err)
+	}
+	if !all[0].Timestamp.Equal(ts) || all[0].TaskType != domain.TaskInference {
+		t.Errorf("first event = %+v (nanosecond timestamp expected)", all[0])
+	}
+	if page, _ := db.ListTaskEvents(1, 1); len(page) != 1 || page[0].Seq != 2 {
+		t.Errorf("ListTaskEvents(1, 1) = %+v, want seq 2", page)
+	}
+
+	events, err := db.ListTaskEventsByTask("task-1")
+	if err != nil || len(events) != 2 || events[1].Credits != 7 || events[1].NodeID != "node-A" {
+		t.Errorf("ListTaskEventsByTask(task-1) = %+v, %v", events, err)
+	}
+}
+
+// ─── Namespaces ─────────────────────────────────────────────────────────────
+
+func TestNamespaces_CRUD(t *testing.T) {
+	db := newTestDB(t)
+	created := time.Unix(1700000000, 0)
+
+	ns := domain.Namespace{Name: "search", Models: []string{"llama3"}, RateLimitRPM: 60, CreatedAt: created}
+	if err := db.UpsertNamespace(ns); err != nil {
+		t.Fatalf("UpsertNamespace: %v", err)
+	}
+	ns.RateLimitRPM = 120
+	if err := db.UpsertNamespace(ns); err != nil {
+		t.Fatalf("UpsertNamespace (update): %v", err)
+	}
+	if err := db.InsertNamespaceKey(domain.NamespaceKey{ID: "k1", Namespace: "search", Hash: "h1", CreatedAt: created}); err != nil {
+		t.Fatalf("InsertNamespaceKey: %v", err)
+	}
+
+	list, err := db.ListNamespaces()
+	if err != nil || len(list) != 1 {
+		t.Fatalf("ListNamespaces = %v, %v", list, err)
+	}
+	if got := list[0]; got.RateLimitRPM != 120 || len(got.Models) != 1 || !got.CreatedAt.Equal(created) {
+		t.Errorf("namespace = %+v", got)
+	}
+	keys, err := db.ListNamespaceKeys()
+	if err != nil || len(keys) != 1 || keys[0].Hash != "h1" {
+		t.Fatalf("ListNamespaceKeys = %v, %v", keys, err)
+	}
+
+	// Deleting a namespace revokes its keys
+	if err := db.DeleteNamespace("search"); err != nil {
+		t.Fatalf("DeleteNamespace: %v", err)
+	}
+	if keys, _ := db.ListNamespaceKeys(); len(keys) != 0 {
+		t.Errorf("%d keys left after DeleteNamespace", len(keys))
+	}
+}
+
+func TestNamespaces_TaskAndLedgerLabels(t *testing.T) {
+	db := newTestDB(t)
+	now := time.Now()
+
+	if err := db.InsertTask(domain.Task{ID: "t1", Type: domain.TaskAgent, Status: domain.TaskQueued,
+		CreatedAt: now, Namespace: "search"}); err != nil {
+		t.Fatalf("InsertTask: %v", err)
+	}
+	task, err := db.GetTask("t1")
+	if err != nil || task == nil || task.Namespace != "search" {
+		t.Fatalf("GetTask = %+v, %v", task, err)
+	}
+
+	for _, e := range []domain.LedgerEntry{
+		{Type: domain.TxSpend, EntryType: domain.EntryDebit, Account: "node_balance", Amount: 5, Namespace: "search"},
+		{Type: domain.TxSpend, EntryType: domain.EntryCredit, Account: "system_pool", Amount: 5, Namespace: "search"},
+		{Type: domain.TxSpend, EntryType: domain.EntryDebit, Account: "node_balance", Amount: 3, Namespace: "ads"},
+		{Type: domain.TxSpend, EntryType: domain.EntryDebit, Account: "node_balance", Amount: 7},
+	} {
+		e.Timestamp = now
+		if _, err := db.InsertLedgerEntry(e); err != nil {
+			t.Fatalf("InsertLedgerEntry: %v", err)
+		}
+	}
+	spent, err := db.SpentByNamespace()
+	if err != nil {
+		t.Fatalf("SpentByNamespace: %v", err)
+	}
+	if len(spent) != 2 || spent["search"] != 5 || spent["ads"] != 3 {
+		t.Errorf("SpentByNamespace = %v, want search:5 ads:3", spent)
+	}
+	entries, _ := db.LedgerEntries("node_balance", 10)
+	if len(entries) != 3 || entries[2].Namespace != "search" {
+		t.Errorf("LedgerEntries = %+v", entries)
+	}
+}
+
+func TestNamespaces_ColumnMigrationIdempotent(t *testing.T) {
+	dir := t.TempDir()
+	for i := 0; i < 2; i++ {
+		db, err := Open(dir)
+		if err != nil {
+			t.Fatalf("Open #%d: %v", i+1, err)
+		}
+		db.Close()
+	}
+}
+
+func TestBilling_EventsAndInvoices(t *testing.T) {
+	db := newTestDB(t)
+	now := time.Now()
+
+	e := domain.PaymentEvent{ID: "evt_1", Provider: "stripe", Type: domain.PaymentSucceeded, ReceivedAt: now}
+	if ok, err := db.InsertPaymentEvent(e); err != nil || !ok {
+		t.Fatalf("InsertPaymentEvent = %v, %v", ok, err)
+	}
+	if ok, _ := db.InsertPaymentEvent(e); ok {
+		t.Error("duplicate event inserted")
+	}
+	if ok, _ := db.HasPaymentEvent("evt_1"); !ok {
+		t.Error("HasPaymentEvent = false")
+	}
+
+	for i, ns := range []string{"search", "ads", "search"} {
+		inv := domain.Invoice{ID: fmt.Sprintf("inv-%d", i), EventID: fmt.Sprintf("evt_%d", i), Provider: "stripe",
+			Namespace: ns, AmountMinor: 100, Currency: "USD", Credits: 10, Status: domain.InvoicePaid,
+			IssuedAt: now.Add(time.Duration(i) * time.Second)}
+		if err := db.UpsertInvoice(inv); err != nil {
+			t.Fatalf("UpsertInvoice: %v", err)
+		}
+	}
+	all, _ := db.ListInvoices("", 0)
+	if len(all) != 3 || all[0].ID != "inv-2" {
+		t.Errorf("ListInvoices(all) = %+v", all)
+	}
+	search, _ := db.ListInvoices("search", 1)
+	if len(search) != 1 || search[0].ID != "inv-2" {
+		t.Errorf("ListInvoices(search, 1) = %+v", search)
+	}
+
+	inv, _ := db.GetInvoice("inv-0")
+	inv.Status, inv.RefundedAt, inv.Credits = domain.InvoiceRefunded, now, 999
+	db.UpsertInvoice(*inv)
+	got, err := db.GetInvoice("inv-0")
+	if err != nil || got.Status != domain.InvoiceRefunded || got.RefundedAt.IsZero() || got.Credits != 10 {
+		t.Errorf("after refund = %+v, %v (only status and refund time change)", got, err)
+	}
+	if got, err := db.GetInvoice("nope"); got != nil || err != nil {
+		t.Errorf("GetInvoice(nope) = %+v, %v", got, err)
+	}
+}
+
+func TestBlocklist_UpsertListPrune(t *testing.T) {
+	db := newTestDB(t)
+	now := time.Unix(1700000000, 0)
+
+	for _, r := range []BlocklistRecord{
+		{Key: "node:n1", Entry: []byte(`{"v":1}`), IssuedAt: now, ExpiresAt: now.Add(time.Hour)},
+		{Key: "node:n1", Entry: []byte(`{"v":2}`), IssuedAt: now.Add(time.Minute), ExpiresAt: now.Add(time.Hour)},
+		{Key: "node:n1", Entry: []byte(`{"v":0}`), IssuedAt: now.Add(-time.Minute)}, // stale
+		{Key: "model:abc", Entry: []byte(`{}`), IssuedAt: now},
+	} {
+		if err := db.UpsertBlocklistEntry(r); err != nil {
+			t.Fatalf("UpsertBlocklistEntry: %v", err)
+		}
+	}
+
+	list, err := db.ListBlocklistEntries(now)
+	if err != nil || len(list) != 2 {
+		t.Fatalf("ListBlocklistEntries = %d, %v", len(list), err)
+	}
+	if got := list[1]; got.Key != "node:n1" || string(got.Entry) != `{"v":2}` || !got.ExpiresAt.Equal(now.Add(time.Hour)) {
+		t.Errorf("node:n1 = %+v, want the latest entry", got)
+	}
+
+	later := now.Add(2 * time.Hour)
+	if list, _ := db.ListBlocklistEntries(later); len(list) != 1 || list[0].Key != "model:abc" {
+		t.Errorf("after expiry = %+v, want only the entry without expiry", list)
+	}
+	if n, err := db.PruneBlocklistEntries(later); err != nil || n != 1 {
+		t.Errorf("PruneBlocklistEntries = %d, %v; want 1", n, err)
+	}
+}
+
+// ─── Runtime Parameters ─────────────────────────────────────────────────────
+
+func TestParamChanges_InsertList(t *testing.T) {
+	db := newTestDB(t)
+	at := time.Unix(1700000000, 42)
+	for i, v := range []string{"2", "3"} {
+		c := domain.ParamChange{ID: int64(i + 1), Key: "days", OldValue: "1", NewValue: v, Source: "admin", AppliedAt: at}
+		if err := db.InsertParamChange(c); err != nil {
+			t.Fatalf("InsertParamChange: %v", err)
+		}
+	}
+	got, err := db.ListParamChanges()
+	if err != nil || len(got) != 2 {
+		t.Fatalf("ListParamChanges = %d, %v", len(got), err)
+	}
+	if got[1].NewValue != "3" || !got[1].AppliedAt.Equal(at) || got[0].Source != "admin" {
+		t.Errorf("changes = %+v", got)
+	}
+}
@@ -43,6 +43,17 @@ func TestOpen_Ping(t *testing.T) {
 	}
 }
 
+func TestDB_IntegrityCheck(t *testing.T) {
+	db := newTestDB(t)
+	got, err := db.IntegrityCheck()
+	if err != nil {
+		t.Fatalf("IntegrityCheck() error: %v", err)
+	}
+	if len(got) != 1 || got[0] != "ok" {
+		t.Errorf("IntegrityCheck() = %v, want [ok]", got)
+	}
+}
+
 // ─── Model CRUD ─────────────────────────────────────────────────────────────
 
 func TestUpsertModel_Insert(t *testing.T) {
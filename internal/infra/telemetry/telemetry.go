@@ -0,0 +1,228 @@
+// Package telemetry samples the host's thermal and power sensors and turns
+// them into a sustained-throttle signal the scheduler can act on.
+//
+//  1. Every Interval the CPU and GPU temperature and the power draw are read
+//     and kept for the last Window
+//  2. The node is throttled once at least SustainedFraction of the readings
+//     in the window are at or above ThrottleTempC — a single hot spike, or a
+//     window with fewer than MinSamples readings, does not count
+//  3. It stays throttled until the hot fraction falls to ClearFraction, so
+//     a machine hovering at the limit does not flap
+//  4. Every transition is passed to the OnChange callback
+package telemetry
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Config tunes the monitor.
+type Config struct {
+	Interval          time.Duration // sensor read cadence (default: 10s)
+	Window            time.Duration // readings kept for the throttle decision (default: 2m)
+	ThrottleTempC     int           // CPU or GPU temperature counted as hot (default: 85)
+	SustainedFraction float64       // hot share of the window that throttles (default: 0.8)
+	ClearFraction     float64       // hot share at or below which it clears (default: 0.2)
+	MinSamples        int           // readings needed before deciding (default: 6)
+
+	Now func() time.Time // injectable clock (default: time.Now)
+}
+
+// DefaultConfig returns defaults that need about two minutes of heat to
+// throttle a node, which a laptop under a long fine-tune reaches but a
+// burst of inference does not.
+func DefaultConfig() Config {
+	return Config{
+		Interval:          10 * time.Second,
+		Window:            2 * time.Minute,
+		ThrottleTempC:     85,
+		SustainedFraction: 0.8,
+		ClearFraction:     0.2,
+		MinSamples:        6,
+		Now:               time.Now,
+	}
+}
+
+// Reading is one sample of the host sensors. Zero values are unknown.
+type Reading struct {
+	At         time.Time `json:"at"`
+	CPUTempC   int       `json:"cpu_temp_c"`
+	GPUTempC   int       `json:"gpu_temp_c"`
+	PowerWatts float64   `json:"power_watts"`
+}
+
+// Hottest returns the higher of the CPU and GPU temperature.
+func (r Reading) Hottest() int {
+	return max(r.CPUTempC, r.GPUTempC)
+}
+
+// Stats is the monitor's current state.
+type Stats struct {
+	Latest        Reading   `json:"latest"`
+	Throttled     bool      `json:"throttled"`
+	ThrottledAt   time.Time `json:"throttled_at,omitempty"`
+	HotFraction   float64   `json:"hot_fraction"` // share of the window at or above the limit
+	WindowSamples int       `json:"window_samples"`
+	PeakTempC     int       `json:"peak_temp_c"` // hottest reading in the window
+	AvgPowerWatts float64   `json:"avg_power_watts"`
+	Readings      int64     `json:"readings"`
+	Throttles     int64     `json:"throttles"` // times the node became throttled
+}
+
+// Monitor keeps a window of sensor readings. It is safe for concurrent use.
+type Monitor struct {
+	mu          sync.Mutex
+	cfg         Config
+	read        func() Reading
+	window      []Reading
+	throttled   bool
+	throttledAt time.Time
+	onChange    func(throttled bool, st Stats)
+	readings    int64
+	throttles   int64
+}
+
+// NewMonitor creates a monitor that samples the host with read.
+func NewMonitor(cfg Config, read func() Reading) *Monitor {
+	def := DefaultConfig()
+	if cfg.Interval <= 0 {
+		cfg.Interval = def.Interval
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = def.Window
+	}
+	if cfg.ThrottleTempC <= 0 {
+		cfg.ThrottleTempC = def.ThrottleTempC
+	}
+	if cfg.SustainedFraction <= 0 || cfg.SustainedFraction > 1 {
+		cfg.SustainedFraction = def.SustainedFraction
+	}
+	if cfg.ClearFraction < 0 || cfg.ClearFraction >= cfg.SustainedFraction {
+		cfg.ClearFraction = def.ClearFraction
+	}
+	if cfg.MinSamples <= 0 {
+		cfg.MinSamples = def.MinSamples
+	}
+	if cfg.Now == nil {
+		cfg.Now = def.Now
+	}
+	return &Monitor{cfg: cfg, read: read}
+}
+
+// OnChange installs the callback run when the node becomes throttled or
+// recovers. It is called without the monitor's lock held.
+func (m *Monitor) OnChange(fn func(throttled bool, st Stats)) {
+	m.mu.Lock()
+	m.onChange = fn
+	m.mu.Unlock()
+}
+
+// Sample reads the sensors once and records the reading.
+func (m *Monitor) Sample() Stats {
+	r := m.read()
+	if r.At.IsZero() {
+		r.At = m.cfg.Now()
+	}
+	return m.Record(r)
+}
+
+// Record adds a reading and re-evaluates the throttle state.
+func (m *Monitor) Record(r Reading) Stats {
+	m.mu.Lock()
+	m.readings++
+	m.window = append(m.window, r)
+	cutoff := r.At.Add(-m.cfg.Window)
+	drop := 0
+	for drop < len(m.window) && !m.window[drop].At.After(cutoff) {
+		drop++
+	}
+	m.window = m.window[drop:]
+
+	st := m.statsLocked()
+	changed := false
+	switch {
+	case !m.throttled && st.WindowSamples >= m.cfg.MinSamples && st.HotFraction >= m.cfg.SustainedFraction:
+		m.throttled, m.throttledAt, changed = true, r.At, true
+		m.throttles++
+	case m.throttled && st.HotFraction <= m.cfg.ClearFraction:
+		m.throttled, m.throttledAt, changed = false, time.Time{}, true
+	}
+	if changed {
+		st = m.statsLocked()
+	}
+	onChange := m.onChange
+	m.mu.Unlock()
+
+	if changed && onChange != nil {
+		onChange(st.Throttled, st)
+	}
+	return st
+}
+
+// Throttled reports whether the node is in sustained thermal throttle.
+func (m *Monitor) Throttled() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.throttled
+}
+
+// Latest returns the most recent reading.
+func (m *Monitor) Latest() Reading {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.window) == 0 {
+		return Reading{}
+	}
+	return m.window[len(m.window)-1]
+}
+
+// Stats returns the current window summary and counters.
+func (m *Monitor) Stats() Stats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.statsLocked()
+}
+
+// statsLocked summarises the window. Must be called with m.mu held.
+func (m *Monitor) statsLocked() Stats {
+	st := Stats{
+		Throttled:     m.throttled,
+		ThrottledAt:   m.throttledAt,
+		WindowSamples: len(m.window),
+		Readings:      m.readings,
+		Throttles:     m.throttles,
+	}
+	if len(m.window) == 0 {
+		return st
+	}
+	st.Latest = m.window[len(m.window)-1]
+	hot, watts := 0, 0.0
+	for _, r := range m.window {
+		t := r.Hottest()
+		if t >= m.cfg.ThrottleTempC {
+			hot++
+		}
+		st.PeakTempC = max(st.PeakTempC, t)
+		watts += r.PowerWatts
+	}
+	st.HotFraction = float64(hot) / float64(len(m.window))
+	st.AvgPowerWatts = watts / float64(len(m.window))
+	return st
+}
+
+// Run samples the sensors every interval until ctx is done.
+func (m *Monitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.cfg.Interval)
+	defer ticker.Stop()
+
+	m.Sample()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.Sample()
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package telemetry
+
+import (
+	"testing"
+	"time"
+)
+
+var t0 = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// feed records n readings at temp, 10s apart, starting at *at.
+func feed(m *Monitor, at *time.Time, n, temp int) Stats {
+	var st Stats
+	for i := 0; i < n; i++ {
+		*at = at.Add(10 * time.Second)
+		st = m.Record(Reading{At: *at, CPUTempC: temp, PowerWatts: 40})
+	}
+	return st
+}
+
+func TestMonitor_SpikeDoesNotThrottle(t *testing.T) {
+	m := NewMonitor(DefaultConfig(), nil)
+	at := t0
+	feed(m, &at, 10, 60)
+	st := feed(m, &at, 2, 95)
+	if st.Throttled {
+		t.Fatal("two hot readings throttled the node")
+	}
+	if st.PeakTempC != 95 {
+		t.Errorf("PeakTempC = %d, want 95", st.PeakTempC)
+	}
+}
+
+func TestMonitor_SustainedHeatThrottlesAndClears(t *testing.T) {
+	m := NewMonitor(DefaultConfig(), nil)
+	var changes []bool
+	m.OnChange(func(throttled bool, _ Stats) { changes = append(changes, throttled) })
+
+	at := t0
+	// Fewer than MinSamples readings never throttle, however hot
+	if st := feed(m, &at, 5, 92); st.Throttled {
+		t.Fatal("throttled before MinSamples readings")
+	}
+	st := feed(m, &at, 1, 92)
+	if !st.Throttled || !m.Throttled() {
+		t.Fatal("six hot readings did not throttle")
+	}
+	if st.AvgPowerWatts != 40 {
+		t.Errorf("AvgPowerWatts = %v, want 40", st.AvgPowerWatts)
+	}
+
+	// Hysteresis: half the window cool is still throttled
+	if st := feed(m, &at, 6, 70); !st.Throttled {
+		t.Fatalf("cleared at hot fraction %.2f", st.HotFraction)
+	}
+	// A full window of cool readings clears it
+	if st := feed(m, &at, 12, 70); st.Throttled {
+		t.Fatalf("still throttled at hot fraction %.2f", st.HotFraction)
+	}
+
+	if len(changes) != 2 || !changes[0] || changes[1] {
+		t.Errorf("changes = %v, want [true false]", changes)
+	}
+	if st := m.Stats(); st.Throttles != 1 || st.Readings != 24 {
+		t.Errorf("Throttles = %d, Readings = %d, want 1 and 24", st.Throttles, st.Readings)
+	}
+}
+
+func TestMonitor_GPUTemperatureCounts(t *testing.T) {
+	m := NewMonitor(DefaultConfig(), nil)
+	at := t0
+	for i := 0; i < 6; i++ {
+		at = at.Add(10 * time.Second)
+		m.Record(Reading{At: at, CPUTempC: 50, GPUTempC: 88})
+	}
+	if !m.Throttled() {
+		t.Error("hot GPU did not throttle the node")
+	}
+}
+
+func TestMonitor_Sample(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Now = func() time.Time { return t0 }
+	m := NewMonitor(cfg, func() Reading { return Reading{CPUTempC: 55, PowerWatts: 12.5} })
+	m.Sample()
+	got := m.Latest()
+	if !got.At.Equal(t0) || got.CPUTempC != 55 || got.PowerWatts != 12.5 {
+		t.Errorf("Latest() = %+v", got)
+	}
+}
@@ -0,0 +1,519 @@
+// Package tenant lets several teams share one node through namespaces.
+//
+//  1. The operator creates a namespace with a model allowlist and a rate
+//     limit, and issues it API keys. Keys are shown once; only their
+//     SHA-256 is kept
+//  2. A request's bearer token resolves to its namespace, which travels with
+//     the request context into tasks and ledger entries
+//  3. Each namespace is limited to RateLimitRPM requests in any minute and
+//     to the models on its allowlist
+//  4. Requests, tokens and rejections are counted per namespace and model,
+//     and passed to Config.Observe for metrics
+package tenant
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
+)
+
+// Store persists namespaces and their keys. Implemented by *sqlite.DB.
+type Store interface {
+	UpsertNamespace(ns domain.Namespace) error
+	DeleteNamespace(name string) error
+	ListNamespaces() ([]domain.Namespace, error)
+	InsertNamespaceKey(k domain.NamespaceKey) error
+	DeleteNamespaceKey(id string) error
+	ListNamespaceKeys() ([]domain.NamespaceKey, error)
+	SpentByNamespace() (map[string]int64, error)
+}
+
+// EventKind classifies a metered event.
+type EventKind string
+
+const (
+	EventRequest     EventKind = "request"      // a request was admitted for a model
+	EventTokens      EventKind = "tokens"       // tokens were generated
+	EventRateLimited EventKind = "rate_limited" // a request was over the rate limit
+	EventModelDenied EventKind = "model_denied" // a model was not on the allowlist
+)
+
+// Event is one metered occurrence, passed to Config.Observe.
+type Event struct {
+	Namespace string
+	Model     string // empty for rate-limited requests
+	Kind      EventKind
+	Tokens    int // EventTokens only
+}
+
+// Config tunes the registry.
+type Config struct {
+	// Observe, if set, is called for every metered event (e.g. for metrics).
+	Observe func(Event)
+
+	Now func() time.Time // injectable clock (default: time.Now)
+}
+
+// ModelUsage is one namespace's use of one model.
+type ModelUsage struct {
+	Requests int64 `json:"requests"`
+	Tokens   int64 `json:"tokens"`
+}
+
+// Usage is a namespace's usage since the daemon started, plus the credits
+// spent on its behalf according to the ledger.
+type Usage struct {
+	Namespace    string                `json:"namespace"`
+	Requests     int64                 `json:"requests"`
+	Tokens       int64                 `json:"tokens"`
+	RateLimited  int64                 `json:"rate_limited"`
+	ModelDenied  int64                 `json:"model_denied"`
+	CreditsSpent int64                 `json:"credits_spent"`
+	LastRequest  time.Time             `json:"last_request,omitempty"`
+	Models       map[string]ModelUsage `json:"models,omitempty"`
+}
+
+// Status is a namespace with its keys and usage.
+type Status struct {
+	domain.Namespace
+	Keys  []domain.NamespaceKey `json:"keys"`
+	Usage Usage                 `json:"usage"`
+}
+
+// Stats summarises the registry.
+type Stats struct {
+	Namespaces  int   `json:"namespaces"`
+	Keys        int   `json:"keys"`
+	Requests    int64 `json:"requests"`
+	Tokens      int64 `json:"tokens"`
+	RateLimited int64 `json:"rate_limited"`
+	ModelDenied int64 `json:"model_denied"`
+}
+
+// validName restricts namespace names to what is safe in metric labels and
+// URLs.
+var validName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
+
+// keyPrefix marks namespace API keys so they are recognisable in configs
+// and secret scanners.
+const keyPrefix = "tns_"
+
+// Registry holds the node's namespaces. It is safe for concurrent use.
+type Registry struct {
+	mu         sync.Mutex
+	cfg        Config
+	store      Store
+	namespaces map[string]domain.Namespace
+	keys       map[string]domain.NamespaceKey // by hash
+	recent     map[string][]time.Time         // admitted request times in the last minute
+	usage      map[string]*Usage
+}
+
+// NewRegistry creates a registry. If store is non-nil, namespaces and keys
+// are loaded from it and every change is written through.
+func NewRegistry(cfg Config, store Store) (*Registry, error) {
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+	r := &Registry{
+		cfg:        cfg,
+		store:      store,
+		namespaces: make(map[string]domain.Namespace),
+		keys:       make(map[string]domain.NamespaceKey),
+		recent:     make(map[string][]time.Time),
+		usage:      make(map[string]*Usage),
+	}
+	if store == nil {
+		return r, nil
+	}
+	list, err := store.ListNamespaces()
+	if err != nil {
+		return nil, fmt.Errorf("tenant: load namespaces: %w", err)
+	}
+	for _, ns := range list {
+		r.namespaces[ns.Name] = ns
+	}
+	keys, err := store.ListNamespaceKeys()
+	if err != nil {
+		return nil, fmt.Errorf("tenant: load keys: %w", err)
+	}
+	for _, k := range keys {
+		r.keys[k.Hash] = k
+	}
+	return r, nil
+}
+
+// ─── Namespaces ─────────────────────────────────────────────────────────────
+
+// Create adds a namespace.
+func (r *Registry) Create(ns domain.Namespace) (domain.Namespace, error) {
+	if err := validate(ns); err != nil {
+		return domain.Namespace{}, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.namespaces[ns.Name]; ok {
+		return domain.Namespace{}, fmt.Errorf("%w: %s", domain.ErrNamespaceExists, ns.Name)
+	}
+	ns.CreatedAt = r.cfg.Now()
+	if err := r.persist(ns); err != nil {
+		return domain.Namespace{}, err
+	}
+	r.namespaces[ns.Name] = ns
+	return ns, nil
+}
+
+// Update replaces a namespace's allowlist and rate limit.
+func (r *Registry) Update(ns domain.Namespace) (domain.Namespace, error) {
+	if err := validate(ns); err != nil {
+		return domain.Namespace{}, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cur, ok := r.namespaces[ns.Name]
+	if !ok {
+		return domain.Namespace{}, fmt.Errorf("%w: %s", domain.ErrNamespaceNotFound, ns.Name)
+	}
+	cur.Models, cur.RateLimitRPM = ns.Models, ns.RateLimitRPM
+	if err := r.persist(cur); err != nil {
+		return domain.Namespace{}, err
+	}
+	r.namespaces[ns.Name] = cur
+	return cur, nil
+}
+
+// Delete removes a namespace and revokes its keys. Its usage counters are
+// kept until restart so the final numbers can still be read.
+func (r *Registry) Delete(name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.namespaces[name]; !ok {
+		return fmt.Errorf("%w: %s", domain.ErrNamespaceNotFound, name)
+	}
+	if r.store != nil {
+		if err := r.store.DeleteNamespace(name); err != nil {
+			return fmt.Errorf("tenant: delete %s: %w", name, err)
+		}
+	}
+	delete(r.namespaces, name)
+	delete(r.recent, name)
+	for hash, k := range r.keys {
+		if k.Namespace == name {
+			delete(r.keys, hash)
+		}
+	}
+	return nil
+}
+
+// Get returns a namespace.
+func (r *Registry) Get(name string) (domain.Namespace, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ns, ok := r.namespaces[name]
+	return ns, ok
+}
+
+// Len returns how many namespaces exist.
+func (r *Registry) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.namespaces)
+}
+
+// Status returns a namespace with its keys and usage.
+func (r *Registry) Status(name string) (Status, error) {
+	spent := r.spent()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ns, ok := r.namespaces[name]
+	if !ok {
+		return Status{}, fmt.Errorf("%w: %s", domain.ErrNamespaceNotFound, name)
+	}
+	return r.statusLocked(ns, spent), nil
+}
+
+// List returns every namespace with its keys and usage, by name.
+func (r *Registry) List() []Status {
+	spent := r.spent()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]Status, 0, len(r.namespaces))
+	for _, ns := range r.namespaces {
+		out = append(out, r.statusLocked(ns, spent))
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// statusLocked assembles a Status. Must be called with r.mu held.
+func (r *Registry) statusLocked(ns domain.Namespace, spent map[string]int64) Status {
+	keys := []domain.NamespaceKey{}
+	for _, k := range r.keys {
+		if k.Namespace == ns.Name {
+			keys = append(keys, k)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].CreatedAt.Before(keys[j].CreatedAt) })
+	return Status{Namespace: ns, Keys: keys, Usage: r.usageLocked(ns.Name, spent)}
+}
+
+// persist writes a namespace through to the store. Must be called with
+// r.mu held.
+func (r *Registry) persist(ns domain.Namespace) error {
+	if r.store == nil {
+		return nil
+	}
+	if err := r.store.UpsertNamespace(ns); err != nil {
+		return fmt.Errorf("tenant: save %s: %w", ns.Name, err)
+	}
+	return nil
+}
+
+func validate(ns domain.Namespace) error {
+	if !validName.MatchString(ns.Name) {
+		return fmt.Errorf("%w: name %q must be 1-32 lowercase letters, digits, '-' or '_'", domain.ErrInvalidNamespace, ns.Name)
+	}
+	if ns.RateLimitRPM < 0 {
+		return fmt.Errorf("%w: rate_limit_rpm must not be negative", domain.ErrInvalidNamespace)
+	}
+	for _, m := range ns.Models {
+		if m == "" {
+			return fmt.Errorf("%w: empty model in allowlist", domain.ErrInvalidNamespace)
+		}
+	}
+	return nil
+}
+
+// ─── API Keys ───────────────────────────────────────────────────────────────
+
+// IssueKey creates an API key for a namespace. The key itself is returned
+// only here; the registry keeps its hash.
+func (r *Registry) IssueKey(name string) (string, domain.NamespaceKey, error) {
+	id, err := randomHex(4)
+	if err != nil {
+		return "", domain.NamespaceKey{}, err
+	}
+	secret, err := randomHex(24)
+	if err != nil {
+		return "", domain.NamespaceKey{}, err
+	}
+	key := keyPrefix + id + "_" + secret
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.namespaces[name]; !ok {
+		return "", domain.NamespaceKey{}, fmt.Errorf("%w: %s", domain.ErrNamespaceNotFound, name)
+	}
+	rec := domain.NamespaceKey{ID: id, Namespace: name, Hash: hashKey(key), CreatedAt: r.cfg.Now()}
+	if r.store != nil {
+		if err := r.store.InsertNamespaceKey(rec); err != nil {
+			return "", domain.NamespaceKey{}, fmt.Errorf("tenant: save key: %w", err)
+		}
+	}
+	r.keys[rec.Hash] = rec
+	return key, rec, nil
+}
+
+// RevokeKey deletes an API key by ID.
+func (r *Registry) RevokeKey(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for hash, k := range r.keys {
+		if k.ID != id {
+			continue
+		}
+		if r.store != nil {
+			if err := r.store.DeleteNamespaceKey(id); err != nil {
+				return fmt.Errorf("tenant: revoke key: %w", err)
+			}
+		}
+		delete(r.keys, hash)
+		return nil
+	}
+	return fmt.Errorf("%w: %s", domain.ErrNamespaceKeyNotFound, id)
+}
+
+// Resolve returns the namespace a bearer token belongs to.
+func (r *Registry) Resolve(token string) (string, bool) {
+	if token == "" {
+		return "", false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	k, ok := r.keys[hashKey(token)]
+	return k.Namespace, ok
+}
+
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("tenant: generate key: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// ─── Limits ─────────────────────────────────────────────────────────────────
+
+// Allow admits one request for a namespace under its rate limit. When the
+// limit is reached it returns false and how long until a request would be
+// admitted. Namespaces without a registry entry are unlimited.
+func (r *Registry) Allow(name string) (bool, time.Duration) {
+	r.mu.Lock()
+	ns, ok := r.namespaces[name]
+	if !ok || ns.RateLimitRPM <= 0 {
+		r.mu.Unlock()
+		return true, 0
+	}
+	now := r.cfg.Now()
+	cutoff := now.Add(-time.Minute)
+	recent := r.recent[name]
+	drop := 0
+	for drop < len(recent) && !recent[drop].After(cutoff) {
+		drop++
+	}
+	recent = recent[drop:]
+	if len(recent) >= ns.RateLimitRPM {
+		r.recent[name] = recent
+		r.usageFor(name).RateLimited++
+		r.mu.Unlock()
+		r.observe(Event{Namespace: name, Kind: EventRateLimited})
+		return false, recent[0].Add(time.Minute).Sub(now)
+	}
+	r.recent[name] = append(recent, now)
+	r.mu.Unlock()
+	return true, 0
+}
+
+// AllowModel reports whether a namespace may use a model, and counts the
+// request against the model if so.
+func (r *Registry) AllowModel(name, model string) bool {
+	r.mu.Lock()
+	ns, ok := r.namespaces[name]
+	u := r.usageFor(name)
+	if ok && !ns.AllowsModel(model) {
+		u.ModelDenied++
+		r.mu.Unlock()
+		r.observe(Event{Namespace: name, Model: model, Kind: EventModelDenied})
+		return false
+	}
+	u.Requests++
+	u.LastRequest = r.cfg.Now()
+	mu := u.Models[model]
+	mu.Requests++
+	u.Models[model] = mu
+	r.mu.Unlock()
+	r.observe(Event{Namespace: name, Model: model, Kind: EventRequest})
+	return true
+}
+
+// RecordTokens counts tokens generated for a namespace.
+func (r *Registry) RecordTokens(name, model string, tokens int) {
+	if tokens <= 0 {
+		return
+	}
+	r.mu.Lock()
+	u := r.usageFor(name)
+	u.Tokens += int64(tokens)
+	mu := u.Models[model]
+	mu.Tokens += int64(tokens)
+	u.Models[model] = mu
+	r.mu.Unlock()
+	r.observe(Event{Namespace: name, Model: model, Kind: EventTokens, Tokens: tokens})
+}
+
+// Usage returns a namespace's usage, including the default namespace and
+// deleted ones.
+func (r *Registry) Usage(name string) Usage {
+	spent := r.spent()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.usageLocked(name, spent)
+}
+
+// usageFor returns the mutable usage record for a namespace. Must be
+// called with r.mu held.
+func (r *Registry) usageFor(name string) *Usage {
+	u, ok := r.usage[name]
+	if !ok {
+		u = &Usage{Namespace: name, Models: make(map[string]ModelUsage)}
+		r.usage[name] = u
+	}
+	return u
+}
+
+// usageLocked copies a namespace's usage. Must be called with r.mu held.
+func (r *Registry) usageLocked(name string, spent map[string]int64) Usage {
+	out := Usage{Namespace: name}
+	if u, ok := r.usage[name]; ok {
+		out = *u
+		out.Models = make(map[string]ModelUsage, len(u.Models))
+		for m, mu := range u.Models {
+			out.Models[m] = mu
+		}
+	}
+	out.CreditsSpent = spent[name]
+	return out
+}
+
+// spent reads per-namespace spending from the ledger (nil without a
+// store).
+func (r *Registry) spent() map[string]int64 {
+	if r.store == nil {
+		return nil
+	}
+	spent, err := r.store.SpentByNamespace()
+	if err != nil {
+		return nil
+	}
+	return spent
+}
+
+func (r *Registry) observe(ev Event) {
+	if r.cfg.Observe != nil {
+		r.cfg.Observe(ev)
+	}
+}
+
+// Stats returns registry-wide totals.
+func (r *Registry) Stats() Stats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	st := Stats{Namespaces: len(r.namespaces), Keys: len(r.keys)}
+	for _, u := range r.usage {
+		st.Requests += u.Requests
+		st.Tokens += u.Tokens
+		st.RateLimited += u.RateLimited
+		st.ModelDenied += u.ModelDenied
+	}
+	return st
+}
+
+// ─── Context ────────────────────────────────────────────────────────────────
+
+type contextKey struct{}
+
+// WithNamespace returns a context carrying a namespace.
+func WithNamespace(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, contextKey{}, name)
+}
+
+// FromContext returns the context's namespace, or domain.DefaultNamespace.
+func FromContext(ctx context.Context) string {
+	if name, ok := ctx.Value(contextKey{}).(string); ok && name != "" {
+		return name
+	}
+	return domain.DefaultNamespace
+}
@@ -0,0 +1,160 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
+)
+
+func TestRegistry_CreateValidates(t *testing.T) {
+	r, _ := NewRegistry(Config{}, nil)
+	for _, ns := range []domain.Namespace{
+		{Name: ""},
+		{Name: "Search"},
+		{Name: "a/b"},
+		{Name: "ok", RateLimitRPM: -1},
+		{Name: "ok", Models: []string{""}},
+	} {
+		if _, err := r.Create(ns); !errors.Is(err, domain.ErrInvalidNamespace) {
+			t.Errorf("Create(%+v) = %v, want ErrInvalidNamespace", ns, err)
+		}
+	}
+	if _, err := r.Create(domain.Namespace{Name: "search"}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if _, err := r.Create(domain.Namespace{Name: "search"}); !errors.Is(err, domain.ErrNamespaceExists) {
+		t.Errorf("duplicate Create = %v, want ErrNamespaceExists", err)
+	}
+}
+
+func TestRegistry_KeysResolveAndRevoke(t *testing.T) {
+	r, _ := NewRegistry(Config{}, nil)
+	if _, _, err := r.IssueKey("search"); !errors.Is(err, domain.ErrNamespaceNotFound) {
+		t.Fatalf("IssueKey for unknown namespace = %v", err)
+	}
+	r.Create(domain.Namespace{Name: "search"})
+	key, rec, err := r.IssueKey("search")
+	if err != nil {
+		t.Fatalf("IssueKey: %v", err)
+	}
+	if rec.Hash == key || rec.Hash == "" {
+		t.Error("key stored in the clear")
+	}
+	if ns, ok := r.Resolve(key); !ok || ns != "search" {
+		t.Errorf("Resolve = %q, %v", ns, ok)
+	}
+	if _, ok := r.Resolve(key + "x"); ok {
+		t.Error("Resolve accepted a wrong key")
+	}
+
+	if err := r.RevokeKey(rec.ID); err != nil {
+		t.Fatalf("RevokeKey: %v", err)
+	}
+	if _, ok := r.Resolve(key); ok {
+		t.Error("revoked key still resolves")
+	}
+	if err := r.RevokeKey(rec.ID); !errors.Is(err, domain.ErrNamespaceKeyNotFound) {
+		t.Errorf("second RevokeKey = %v", err)
+	}
+}
+
+func TestRegistry_RateLimit(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	var events []Event
+	r, _ := NewRegistry(Config{
+		Now:     func() time.Time { return now },
+		Observe: func(ev Event) { events = append(events, ev) },
+	}, nil)
+	r.Create(domain.Namespace{Name: "search", RateLimitRPM: 2})
+
+	for i := 0; i < 2; i++ {
+		if ok, _ := r.Allow("search"); !ok {
+			t.Fatalf("request %d rejected", i+1)
+		}
+		now = now.Add(10 * time.Second)
+	}
+	ok, retry := r.Allow("search")
+	if ok || retry != 40*time.Second {
+		t.Fatalf("third request: ok=%v retry=%v, want rejected with 40s", ok, retry)
+	}
+	// The first request leaves the window
+	now = now.Add(40 * time.Second)
+	if ok, _ := r.Allow("search"); !ok {
+		t.Error("rejected after the window moved on")
+	}
+	// Unknown namespaces (and the implicit default) are unlimited
+	if ok, _ := r.Allow(domain.DefaultNamespace); !ok {
+		t.Error("default namespace rate limited")
+	}
+
+	if u := r.Usage("search"); u.RateLimited != 1 {
+		t.Errorf("RateLimited = %d, want 1", u.RateLimited)
+	}
+	if len(events) != 1 || events[0].Kind != EventRateLimited {
+		t.Errorf("events = %+v", events)
+	}
+}
+
+func TestRegistry_ModelAllowlistAndUsage(t *testing.T) {
+	r, _ := NewRegistry(Config{}, nil)
+	r.Create(domain.Namespace{Name: "search", Models: []string{"llama3", "qwen2:7b"}})
+
+	for _, tt := range []struct {
+		model string
+		want  bool
+	}{
+		{"llama3", true},
+		{"llama3:70b", true}, // untagged entries allow every tag
+		{"qwen2:7b", true},
+		{"qwen2:72b", false},
+		{"mistral", false},
+	} {
+		if got := r.AllowModel("search", tt.model); got != tt.want {
+			t.Errorf("AllowModel(%q) = %v, want %v", tt.model, got, tt.want)
+		}
+	}
+	r.RecordTokens("search", "llama3", 40)
+
+	u := r.Usage("search")
+	if u.Requests != 3 || u.ModelDenied != 2 || u.Tokens != 40 {
+		t.Errorf("usage = %+v", u)
+	}
+	if mu := u.Models["llama3"]; mu.Requests != 1 || mu.Tokens != 40 {
+		t.Errorf("llama3 usage = %+v", mu)
+	}
+
+	// The default namespace is accounted without being registered
+	if !r.AllowModel(domain.DefaultNamespace, "mistral") {
+		t.Error("default namespace denied a model")
+	}
+	if st := r.Stats(); st.Requests != 4 || st.Namespaces != 1 {
+		t.Errorf("Stats = %+v", st)
+	}
+}
+
+func TestRegistry_DeleteRevokesKeys(t *testing.T) {
+	r, _ := NewRegistry(Config{}, nil)
+	r.Create(domain.Namespace{Name: "search"})
+	key, _, _ := r.IssueKey("search")
+	if err := r.Delete("search"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, ok := r.Resolve(key); ok {
+		t.Error("key of deleted namespace still resolves")
+	}
+	if err := r.Delete("search"); !errors.Is(err, domain.ErrNamespaceNotFound) {
+		t.Errorf("second Delete = %v", err)
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	if got := FromContext(context.Background()); got != domain.DefaultNamespace {
+		t.Errorf("FromContext(background) = %q", got)
+	}
+	if got := FromContext(WithNamespace(context.Background(), "search")); got != "search" {
+		t.Errorf("FromContext = %q, want search", got)
+	}
+}
@@ -11,7 +11,7 @@ import (
 // ─── MCP Gateway ────────────────────────────────────────────────────────────
 // Architecture Part XII: Enterprise-grade MCP endpoint.
 // Protocol: MCP 2025-03-26 — initialize, tools/list, tools/call,
-// resources/list, resources/read
+// resources/list, resources/read, resources/subscribe, resources/unsubscribe
 //
 // The Gateway is the entry point for all MCP JSON-RPC 2.0 requests.
 // It routes to tool handlers, manages SLA, and meters usage.
@@ -28,6 +28,7 @@ type Gateway struct {
 	meter     *Meter
 	tools     []domain.MCPTool
 	resources []domain.MCPResource
+	backend   Backend // live node (zero value = stubs only)
 }
 
 // NewGateway creates a fully configured MCP Gateway.
@@ -75,6 +76,8 @@ func (g *Gateway) dispatch(req Request) Response {
 		return g.handleResourcesList(req)
 	case "resources/read":
 		return g.handleResourcesRead(req)
+	case "resources/subscribe", "resources/unsubscribe":
+		return g.handleResourceSubscribe(req)
 	case "ping":
 		return g.ack(req.ID)
 	default:
@@ -198,6 +201,14 @@ func (g *Gateway) handleToolsCall(req Request) Response {
 		return g.callBatch(req.ID, params.Arguments)
 	case "tutu_fine_tune":
 		return g.callFineTune(req.ID, params.Arguments)
+	case "tutu_list_models":
+		return g.callListModels(req.ID)
+	case "tutu_node_status":
+		return g.callNodeStatus(req.ID)
+	case "tutu_earnings":
+		return g.callEarnings(req.ID, params.Arguments)
+	case "tutu_marketplace_search":
+		return g.callMarketplaceSearch(req.ID, params.Arguments)
 	default:
 		return NewInvalidParams(req.ID, fmt.Sprintf("unknown tool: %s", params.Name))
 	}
@@ -221,6 +232,9 @@ func (g *Gateway) callInference(id any, args json.RawMessage) Response {
 	if tier == "" {
 		tier = domain.SLAStandard
 	}
+	if g.backend.Generate != nil {
+		return g.runInference(id, p, tier)
+	}
 
 	// Phase 2 stub: simulate inference and meter usage
 	inputToks := len(p.Prompt) / 4 // ~4 chars per token
@@ -247,6 +261,9 @@ func (g *Gateway) callEmbed(id any, args json.RawMessage) Response {
 	for _, inp := range p.Inputs {
 		totalToks += len(inp) / 4
 	}
+	if g.backend.Embed != nil {
+		return g.runEmbed(id, p, totalToks)
+	}
 	g.meter.Record("stub-client", "tutu_embed", p.Model, totalToks, 0, 15, domain.SLAStandard)
 
 	text := fmt.Sprintf("Embedding accepted: model=%s inputs=%d tokens=%d", p.Model, len(p.Inputs), totalToks)
@@ -340,9 +357,12 @@ func (g *Gateway) handleResourcesRead(req Request) Response {
 		return g.readModels(req.ID)
 	case "tutu://regions/global":
 		return g.readRegions(req.ID)
-	default:
-		return NewInvalidParams(req.ID, fmt.Sprintf("unknown resource: %s", params.URI))
+	case ResourceNodeStatus, ResourceNodeTasks, ResourceNodeEarnings:
+		if g.hasResource(params.URI) {
+			return g.readNodeResource(req.ID, params.URI)
+		}
 	}
+	return NewInvalidParams(req.ID, fmt.Sprintf("unknown resource: %s", params.URI))
 }
 
 func (g *Gateway) readCapacity(id any) Response {
@@ -459,7 +479,7 @@ func (g *Gateway) defineTools() []domain.MCPTool {
 				Type: "object",
 				Properties: map[string]domain.MCPSchemaProperty{
 					"model":  {Type: "string", Description: "Embedding model name"},
-					"inputs": {Type: "array", Description: "List of text inputs to embed"},
+					"inputs": {Type: "array", Description: "List of text inputs to embed", Items: &domain.MCPSchemaProperty{Type: "string"}},
 				},
 				Required: []string{"model", "inputs"},
 			},
@@ -471,7 +491,7 @@ func (g *Gateway) defineTools() []domain.MCPTool {
 				Type: "object",
 				Properties: map[string]domain.MCPSchemaProperty{
 					"model":   {Type: "string", Description: "Model name"},
-					"prompts": {Type: "array", Description: "List of prompts to process"},
+					"prompts": {Type: "array", Description: "List of prompts to process", Items: &domain.MCPSchemaProperty{Type: "string"}},
 					"tier":    {Type: "string", Description: "SLA tier for batch", Enum: []string{"standard", "batch", "spot"}, Default: "batch"},
 				},
 				Required: []string{"model", "prompts"},
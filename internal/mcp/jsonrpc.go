@@ -3,6 +3,7 @@
 //
 // Protocol: MCP 2025-03-26 over JSON-RPC 2.0 / Streamable HTTP
 // Tools:    tutu_inference, tutu_embed, tutu_batch_process, tutu_fine_tune
+// Node:     tutu_list_models, tutu_node_status, tutu_earnings, tutu_marketplace_search
 // Resources: tutu://capacity, tutu://models, tutu://regions/{region}
 package mcp
 
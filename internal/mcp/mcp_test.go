@@ -1,7 +1,9 @@
 package mcp
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -200,6 +202,19 @@ func TestSLAEngine_ConfigFor_Unknown(t *testing.T) {
 	}
 }
 
+func TestSLAEngine_SetRateLimit(t *testing.T) {
+	sla := NewSLAEngine()
+	if !sla.SetRateLimit(domain.SLABatch, 120) {
+		t.Fatal("SetRateLimit(batch) rejected")
+	}
+	if got := sla.ConfigFor(domain.SLABatch).RateLimitRPM; got != 120 {
+		t.Errorf("batch RPM = %d, want 120", got)
+	}
+	if sla.SetRateLimit(domain.SLATier("nonexistent"), 10) || sla.SetRateLimit(domain.SLASpot, -1) {
+		t.Error("unknown tier or negative limit accepted")
+	}
+}
+
 func TestSLAEngine_PriorityFor(t *testing.T) {
 	sla := NewSLAEngine()
 	if sla.PriorityFor(domain.SLARealtime) != 255 {
@@ -572,6 +587,87 @@ func TestGateway_ToolsCall_UnknownTool(t *testing.T) {
 	}
 }
 
+func TestGateway_NodeTools_HiddenWithoutBackend(t *testing.T) {
+	gw := newTestGateway(t)
+	resp := gw.HandleRequest(rpcRequest("tools/call", toolsCallParams{
+		Name:      "tutu_node_status",
+		Arguments: mustMarshal(map[string]string{}),
+	}))
+	if resp.Error == nil {
+		t.Fatal("node tools should be unknown without a backend")
+	}
+}
+
+func TestGateway_NodeTools_WithBackend(t *testing.T) {
+	gw := newTestGateway(t)
+	var gotLimit int
+	gw.SetBackend(Backend{
+		Models: func() ([]domain.ModelInfo, error) {
+			return []domain.ModelInfo{{Name: "llama3"}}, nil
+		},
+		Generate: func(ctx context.Context, model, prompt string, maxTokens int) (string, int, error) {
+			if model == "missing" {
+				return "", 0, errors.New("model not found")
+			}
+			return "hi there", 2, nil
+		},
+		Status: func() (any, error) { return map[string]any{"node_id": "node-1"}, nil },
+		Earnings: func(limit int) (any, error) {
+			gotLimit = limit
+			return map[string]any{"balance": 42}, nil
+		},
+	})
+
+	var list toolsListResult
+	json.Unmarshal(gw.HandleRequest(rpcRequest("tools/list", nil)).Result, &list)
+	names := make(map[string]bool)
+	for _, tool := range list.Tools {
+		names[tool.Name] = true
+	}
+	if len(list.Tools) != 7 || !names["tutu_node_status"] || names["tutu_marketplace_search"] {
+		t.Fatalf("tools = %v, want base 4 + models/status/earnings", names)
+	}
+
+	call := func(name string, args any) toolsCallResult {
+		t.Helper()
+		resp := gw.HandleRequest(rpcRequest("tools/call", toolsCallParams{Name: name, Arguments: mustMarshal(args)}))
+		if resp.Error != nil {
+			t.Fatalf("%s: unexpected error: %v", name, resp.Error)
+		}
+		var result toolsCallResult
+		json.Unmarshal(resp.Result, &result)
+		return result
+	}
+
+	tests := []struct {
+		name    string
+		tool    string
+		args    any
+		want    string
+		isError bool
+	}{
+		{"inference runs", "tutu_inference", domain.InferenceParams{Model: "llama3", Prompt: "hello"}, "hi there", false},
+		{"inference failure", "tutu_inference", domain.InferenceParams{Model: "missing", Prompt: "hello"}, "model not found", true},
+		{"list models", "tutu_list_models", map[string]string{}, `"llama3"`, false},
+		{"status", "tutu_node_status", map[string]string{}, `"node-1"`, false},
+		{"earnings", "tutu_earnings", map[string]string{}, `"balance":42`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := call(tt.tool, tt.args)
+			if result.IsError != tt.isError {
+				t.Errorf("isError = %v, want %v", result.IsError, tt.isError)
+			}
+			if len(result.Content) == 0 || !strings.Contains(result.Content[0].Text, tt.want) {
+				t.Errorf("content = %+v, want %q", result.Content, tt.want)
+			}
+		})
+	}
+	if gotLimit != 10 {
+		t.Errorf("earnings limit = %d, want default 10", gotLimit)
+	}
+}
+
 func TestGateway_ResourcesRead_Capacity(t *testing.T) {
 	gw := newTestGateway(t)
 	raw := rpcRequest("resources/read", resourcesReadParams{URI: "tutu://capacity"})
@@ -871,6 +967,76 @@ func TestTransport_Notify_UnknownSession(t *testing.T) {
 	}
 }
 
+func TestTransport_ResourceSubscriptions(t *testing.T) {
+	gw := newTestGateway(t)
+	active := 0
+	gw.SetBackend(Backend{
+		Tasks: func() (any, error) { return map[string]int{"active": active}, nil },
+	})
+	tr := NewTransport(gw)
+
+	post := func(sessionID string, body []byte) Response {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(string(body)))
+		if sessionID != "" {
+			req.Header.Set("Mcp-Session-Id", sessionID)
+		}
+		w := httptest.NewRecorder()
+		tr.ServeHTTP(w, req)
+		var resp Response
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		return resp
+	}
+
+	// Subscribing needs an initialized session
+	if resp := post("", rpcRequest("resources/subscribe", resourceSubscribeParams{URI: ResourceNodeTasks})); resp.Error == nil {
+		t.Error("subscribe without a session should fail")
+	}
+
+	initReq := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(string(rpcRequest("initialize", map[string]any{}))))
+	initW := httptest.NewRecorder()
+	tr.ServeHTTP(initW, initReq)
+	sessionID := initW.Header().Get("Mcp-Session-Id")
+
+	if resp := post(sessionID, rpcRequest("resources/subscribe", resourceSubscribeParams{URI: "tutu://nope"})); resp.Error == nil {
+		t.Error("subscribe to unknown resource should fail")
+	}
+	if resp := post(sessionID, rpcRequest("resources/subscribe", resourceSubscribeParams{URI: ResourceNodeTasks})); resp.Error != nil {
+		t.Fatalf("subscribe: %v", resp.Error)
+	}
+
+	// Baseline, unchanged, changed
+	for i, tt := range []struct {
+		active int
+		want   int
+	}{{0, 0}, {0, 0}, {3, 1}} {
+		active = tt.active
+		if sent := tr.pollResources(); sent != tt.want {
+			t.Errorf("poll %d: sent = %d, want %d", i, sent, tt.want)
+		}
+	}
+
+	tr.mu.RLock()
+	msg := <-tr.sessions[sessionID].notify
+	tr.mu.RUnlock()
+	if !strings.Contains(string(msg), "notifications/resources/updated") || !strings.Contains(string(msg), ResourceNodeTasks) {
+		t.Errorf("notification = %s", msg)
+	}
+
+	// Unsubscribed sessions get nothing
+	post(sessionID, rpcRequest("resources/unsubscribe", resourceSubscribeParams{URI: ResourceNodeTasks}))
+	active = 5
+	if sent := tr.pollResources(); sent != 0 {
+		t.Errorf("sent after unsubscribe = %d, want 0", sent)
+	}
+
+	// The resource itself is readable
+	resp := post(sessionID, rpcRequest("resources/read", resourcesReadParams{URI: ResourceNodeTasks}))
+	if resp.Error != nil || !strings.Contains(string(resp.Result), `\"active\":5`) {
+		t.Errorf("read = %s, %v", resp.Result, resp.Error)
+	}
+}
+
 // ─── Integration: Full MCP Flow ─────────────────────────────────────────────
 
 func TestIntegration_FullMCPFlow(t *testing.T) {
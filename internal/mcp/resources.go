@@ -0,0 +1,121 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/tutu-network/tutu/internal/domain"
+)
+
+// ─── Live Node Resources ────────────────────────────────────────────────────
+// With a backend attached the gateway serves live node state as resources.
+// Clients can resources/subscribe to any listed resource; the transport's
+// watcher then pushes notifications/resources/updated whenever the content
+// changes, and the client re-reads it — no polling on the client side.
+
+const (
+	ResourceNodeStatus   = "tutu://node/status"
+	ResourceNodeTasks    = "tutu://node/tasks"
+	ResourceNodeEarnings = "tutu://node/earnings"
+)
+
+// earningsResourceEntries is how many ledger entries tutu://node/earnings shows.
+const earningsResourceEntries = 10
+
+type resourceSubscribeParams struct {
+	URI string `json:"uri"`
+}
+
+// handleResourceSubscribe validates a subscribe or unsubscribe request.
+// The transport records the subscription against the caller's session.
+func (g *Gateway) handleResourceSubscribe(req Request) Response {
+	var params resourceSubscribeParams
+	if err := json.Unmarshal(req.Params, &params); err != nil || params.URI == "" {
+		return NewInvalidParams(req.ID, "uri is required")
+	}
+	if !g.hasResource(params.URI) {
+		return NewInvalidParams(req.ID, fmt.Sprintf("unknown resource: %s", params.URI))
+	}
+	return g.ack(req.ID)
+}
+
+// hasResource reports whether uri is a listed resource.
+func (g *Gateway) hasResource(uri string) bool {
+	return slices.ContainsFunc(g.resources, func(r domain.MCPResource) bool { return r.URI == uri })
+}
+
+// nodeResource returns the live value behind a node resource URI.
+// ok is false when the URI is not a node resource or has no backend.
+func (g *Gateway) nodeResource(uri string) (v any, ok bool, err error) {
+	switch {
+	case uri == ResourceNodeStatus && g.backend.Status != nil:
+		v, err = g.backend.Status()
+	case uri == ResourceNodeTasks && g.backend.Tasks != nil:
+		v, err = g.backend.Tasks()
+	case uri == ResourceNodeEarnings && g.backend.Earnings != nil:
+		v, err = g.backend.Earnings(earningsResourceEntries)
+	default:
+		return nil, false, nil
+	}
+	return v, true, err
+}
+
+// resourceText returns the current JSON content of a node resource.
+func (g *Gateway) resourceText(uri string) (string, bool, error) {
+	v, ok, err := g.nodeResource(uri)
+	if !ok || err != nil {
+		return "", ok, err
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", true, err
+	}
+	return string(data), true, nil
+}
+
+func (g *Gateway) readNodeResource(id any, uri string) Response {
+	text, _, err := g.resourceText(uri)
+	if err != nil {
+		return NewInternalError(id, err.Error())
+	}
+	result := resourcesReadResult{
+		Contents: []domain.MCPResourceContent{
+			{URI: uri, MimeType: "application/json", Text: text},
+		},
+	}
+	resp, err := NewResult(id, result)
+	if err != nil {
+		return NewInternalError(id, err.Error())
+	}
+	return resp
+}
+
+func (g *Gateway) defineNodeResources() []domain.MCPResource {
+	var out []domain.MCPResource
+	if g.backend.Status != nil {
+		out = append(out, domain.MCPResource{
+			URI:         ResourceNodeStatus,
+			Name:        "Node Status",
+			Description: "Live node status: loaded models, task slots, network membership",
+			MimeType:    "application/json",
+		})
+	}
+	if g.backend.Tasks != nil {
+		out = append(out, domain.MCPResource{
+			URI:         ResourceNodeTasks,
+			Name:        "Active Tasks",
+			Description: "Running and queued tasks on this node",
+			MimeType:    "application/json",
+		})
+	}
+	if g.backend.Earnings != nil {
+		out = append(out, domain.MCPResource{
+			URI:         ResourceNodeEarnings,
+			Name:        "Earnings Summary",
+			Description: "Credit balance and most recent ledger entries",
+			MimeType:    "application/json",
+		})
+	}
+	return out
+}
@@ -1,6 +1,7 @@
 package mcp
 
 import (
+	"sync"
 	"time"
 
 	"github.com/tutu-network/tutu/internal/domain"
@@ -12,6 +13,7 @@ import (
 
 // SLAEngine resolves client SLA tiers into concrete performance parameters.
 type SLAEngine struct {
+	mu    sync.RWMutex
 	tiers map[domain.SLATier]domain.SLAConfig
 }
 
@@ -66,6 +68,8 @@ func NewSLAEngine() *SLAEngine {
 // ConfigFor returns the SLA configuration for the given tier.
 // Returns the spot tier config as fallback for unknown tiers.
 func (e *SLAEngine) ConfigFor(tier domain.SLATier) domain.SLAConfig {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
 	if cfg, ok := e.tiers[tier]; ok {
 		return cfg
 	}
@@ -90,6 +94,8 @@ func (e *SLAEngine) CostMicro(tier domain.SLATier, inputToks, outputToks int) in
 
 // AllTiers returns all SLA configurations in priority order (highest first).
 func (e *SLAEngine) AllTiers() []domain.SLAConfig {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
 	return []domain.SLAConfig{
 		e.tiers[domain.SLARealtime],
 		e.tiers[domain.SLAStandard],
@@ -97,3 +103,17 @@ func (e *SLAEngine) AllTiers() []domain.SLAConfig {
 		e.tiers[domain.SLASpot],
 	}
 }
+
+// SetRateLimit changes a tier's requests-per-minute limit at runtime.
+// Returns false for unknown tiers or a negative limit.
+func (e *SLAEngine) SetRateLimit(tier domain.SLATier, rpm int) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	cfg, ok := e.tiers[tier]
+	if !ok || rpm < 0 {
+		return false
+	}
+	cfg.RateLimitRPM = rpm
+	e.tiers[tier] = cfg
+	return true
+}
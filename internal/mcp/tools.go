@@ -0,0 +1,228 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
+)
+
+// ─── Node Backend ───────────────────────────────────────────────────────────
+// Without a backend the gateway validates and meters tool calls but runs
+// nothing (the Phase 2 stubs). With one attached, tutu_inference and
+// tutu_embed run on the local node and the node tools below are exposed,
+// so agent frameworks can drive a TuTu node directly over MCP.
+
+// callTimeout bounds a single inference or embedding tool call.
+const callTimeout = 2 * time.Minute
+
+// Backend connects the gateway to a live node. Every field is optional:
+// tools whose function is nil keep their stub behaviour or stay hidden.
+type Backend struct {
+	// Models lists locally available models.
+	Models func() ([]domain.ModelInfo, error)
+
+	// Generate runs a completion and returns the text and output token count.
+	Generate func(ctx context.Context, model, prompt string, maxTokens int) (string, int, error)
+
+	// Embed computes one vector per input.
+	Embed func(ctx context.Context, model string, inputs []string) ([][]float32, error)
+
+	// Status reports node health, capacity and task counters.
+	Status func() (any, error)
+
+	// Tasks reports active and queued work (tutu://node/tasks).
+	Tasks func() (any, error)
+
+	// Earnings reports the credit balance and up to limit recent ledger entries.
+	Earnings func(limit int) (any, error)
+
+	// SearchMarketplace finds approved listings by category and text query.
+	SearchMarketplace func(category, query string, limit int) (any, error)
+}
+
+// SetBackend attaches a live node and advertises the node tools and
+// resources it supports. Call before serving requests.
+func (g *Gateway) SetBackend(b Backend) {
+	g.backend = b
+	g.tools = append(g.defineTools(), g.defineNodeTools()...)
+	g.resources = append(g.defineResources(), g.defineNodeResources()...)
+}
+
+// ─── Node Tool Handlers ─────────────────────────────────────────────────────
+
+// runInference executes tutu_inference on the attached backend.
+func (g *Gateway) runInference(id any, p domain.InferenceParams, tier domain.SLATier) Response {
+	ctx, cancel := context.WithTimeout(context.Background(), callTimeout)
+	defer cancel()
+
+	start := time.Now()
+	text, outputToks, err := g.backend.Generate(ctx, p.Model, p.Prompt, p.MaxToks)
+	if err != nil {
+		return g.toolError(id, err)
+	}
+	g.meter.Record("local", "tutu_inference", p.Model, len(p.Prompt)/4, outputToks,
+		time.Since(start).Milliseconds(), tier)
+	return g.toolResult(id, text)
+}
+
+// runEmbed executes tutu_embed on the attached backend.
+func (g *Gateway) runEmbed(id any, p domain.EmbedParams, inputToks int) Response {
+	ctx, cancel := context.WithTimeout(context.Background(), callTimeout)
+	defer cancel()
+
+	start := time.Now()
+	vecs, err := g.backend.Embed(ctx, p.Model, p.Inputs)
+	if err != nil {
+		return g.toolError(id, err)
+	}
+	g.meter.Record("local", "tutu_embed", p.Model, inputToks, 0,
+		time.Since(start).Milliseconds(), domain.SLAStandard)
+	return g.jsonResult(id, map[string]any{"model": p.Model, "embeddings": vecs})
+}
+
+func (g *Gateway) callListModels(id any) Response {
+	if g.backend.Models == nil {
+		return NewInvalidParams(id, "unknown tool: tutu_list_models")
+	}
+	models, err := g.backend.Models()
+	if err != nil {
+		return g.toolError(id, err)
+	}
+	return g.jsonResult(id, map[string]any{"models": models})
+}
+
+func (g *Gateway) callNodeStatus(id any) Response {
+	if g.backend.Status == nil {
+		return NewInvalidParams(id, "unknown tool: tutu_node_status")
+	}
+	status, err := g.backend.Status()
+	if err != nil {
+		return g.toolError(id, err)
+	}
+	return g.jsonResult(id, status)
+}
+
+func (g *Gateway) callEarnings(id any, args json.RawMessage) Response {
+	if g.backend.Earnings == nil {
+		return NewInvalidParams(id, "unknown tool: tutu_earnings")
+	}
+	var p domain.EarningsParams
+	if len(args) > 0 {
+		if err := json.Unmarshal(args, &p); err != nil {
+			return NewInvalidParams(id, "invalid earnings params")
+		}
+	}
+	if p.Limit <= 0 {
+		p.Limit = 10
+	}
+	earnings, err := g.backend.Earnings(p.Limit)
+	if err != nil {
+		return g.toolError(id, err)
+	}
+	return g.jsonResult(id, earnings)
+}
+
+func (g *Gateway) callMarketplaceSearch(id any, args json.RawMessage) Response {
+	if g.backend.SearchMarketplace == nil {
+		return NewInvalidParams(id, "unknown tool: tutu_marketplace_search")
+	}
+	var p domain.MarketplaceSearchParams
+	if len(args) > 0 {
+		if err := json.Unmarshal(args, &p); err != nil {
+			return NewInvalidParams(id, "invalid marketplace search params")
+		}
+	}
+	if p.Limit <= 0 {
+		p.Limit = 20
+	}
+	listings, err := g.backend.SearchMarketplace(p.Category, p.Query, p.Limit)
+	if err != nil {
+		return g.toolError(id, err)
+	}
+	return g.jsonResult(id, map[string]any{"listings": listings})
+}
+
+// ─── Result Helpers ─────────────────────────────────────────────────────────
+
+// jsonResult returns v as a JSON text content block.
+func (g *Gateway) jsonResult(id any, v any) Response {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return NewInternalError(id, err.Error())
+	}
+	return g.toolResult(id, string(data))
+}
+
+// toolError reports a failed tool execution. Per MCP, execution failures
+// are tool results with isError set, not JSON-RPC errors, so the calling
+// model can see and react to them.
+func (g *Gateway) toolError(id any, err error) Response {
+	result := toolsCallResult{
+		Content: []contentBlock{{Type: "text", Text: err.Error()}},
+		IsError: true,
+	}
+	resp, rerr := NewResult(id, result)
+	if rerr != nil {
+		return NewInternalError(id, rerr.Error())
+	}
+	return resp
+}
+
+// ─── Node Tool Definitions ──────────────────────────────────────────────────
+
+func (g *Gateway) defineNodeTools() []domain.MCPTool {
+	var tools []domain.MCPTool
+	if g.backend.Models != nil {
+		tools = append(tools, domain.MCPTool{
+			Name:        "tutu_list_models",
+			Description: "List models available on this node with size, format and quantization.",
+			InputSchema: domain.MCPToolInputSchema{
+				Type:       "object",
+				Properties: map[string]domain.MCPSchemaProperty{},
+				Required:   []string{},
+			},
+		})
+	}
+	if g.backend.Status != nil {
+		tools = append(tools, domain.MCPTool{
+			Name:        "tutu_node_status",
+			Description: "Node status: identity, loaded models, task slots and network membership.",
+			InputSchema: domain.MCPToolInputSchema{
+				Type:       "object",
+				Properties: map[string]domain.MCPSchemaProperty{},
+				Required:   []string{},
+			},
+		})
+	}
+	if g.backend.Earnings != nil {
+		tools = append(tools, domain.MCPTool{
+			Name:        "tutu_earnings",
+			Description: "Credit balance and recent earnings ledger for this node.",
+			InputSchema: domain.MCPToolInputSchema{
+				Type: "object",
+				Properties: map[string]domain.MCPSchemaProperty{
+					"limit": {Type: "integer", Description: "Recent ledger entries to include", Default: 10},
+				},
+				Required: []string{},
+			},
+		})
+	}
+	if g.backend.SearchMarketplace != nil {
+		tools = append(tools, domain.MCPTool{
+			Name:        "tutu_marketplace_search",
+			Description: "Search approved marketplace listings, most downloaded first.",
+			InputSchema: domain.MCPToolInputSchema{
+				Type: "object",
+				Properties: map[string]domain.MCPSchemaProperty{
+					"query":    {Type: "string", Description: "Text matched against model name and description"},
+					"category": {Type: "string", Description: "Listing category", Enum: []string{"general", "code", "creative", "science", "translator", "chat"}},
+					"limit":    {Type: "integer", Description: "Maximum listings to return", Default: 20},
+				},
+				Required: []string{},
+			},
+		})
+	}
+	return tools
+}
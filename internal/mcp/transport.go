@@ -1,12 +1,14 @@
 package mcp
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -19,13 +21,19 @@ import (
 // DELETE /mcp → Close session
 //
 // Sessions are tracked via Mcp-Session-Id header.
-// The transport is stateless per request — each POST is independent.
+// The transport is stateless per request — each POST is independent,
+// except resources/subscribe, which is recorded against the session so
+// WatchResources can push updates over its SSE stream.
 
 // Transport provides the HTTP handlers for the MCP protocol.
 type Transport struct {
 	gateway  *Gateway
 	mu       sync.RWMutex
 	sessions map[string]*session
+
+	// watchMu guards lastContent, the last seen content per subscribed URI.
+	watchMu     sync.Mutex
+	lastContent map[string]string
 }
 
 // session tracks a connected MCP client session.
@@ -35,13 +43,16 @@ type session struct {
 	// SSE channel for server-initiated notifications
 	notify chan []byte
 	done   chan struct{}
+	// subs is the set of subscribed resource URIs (guarded by Transport.mu)
+	subs map[string]bool
 }
 
 // NewTransport creates a new Streamable HTTP transport.
 func NewTransport(gateway *Gateway) *Transport {
 	return &Transport{
-		gateway:  gateway,
-		sessions: make(map[string]*session),
+		gateway:     gateway,
+		sessions:    make(map[string]*session),
+		lastContent: make(map[string]string),
 	}
 }
 
@@ -102,11 +113,19 @@ func (t *Transport) handlePost(w http.ResponseWriter, r *http.Request) {
 			ID:     sessionID,
 			notify: make(chan []byte, 32),
 			done:   make(chan struct{}),
+			subs:   make(map[string]bool),
 		}
 		t.mu.Unlock()
 		log.Printf("[mcp/transport] new session: %s", sessionID)
 	}
 
+	// Record resource subscriptions against the session
+	if resp.Error == nil {
+		if errResp := t.applySubscription(sessionID, body, resp.ID); errResp != nil {
+			resp = errResp
+		}
+	}
+
 	// Write response
 	w.Header().Set("Content-Type", "application/json")
 	w.Header().Set("Mcp-Session-Id", sessionID)
@@ -214,6 +233,98 @@ func (t *Transport) SessionCount() int {
 	return len(t.sessions)
 }
 
+// applySubscription records a successful resources/subscribe or
+// resources/unsubscribe for the session. Subscriptions need an initialized
+// session, since updates are delivered over its SSE stream.
+func (t *Transport) applySubscription(sessionID string, body []byte, id any) *Response {
+	var req struct {
+		Method string                  `json:"method"`
+		Params resourceSubscribeParams `json:"params"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil
+	}
+	if req.Method != "resources/subscribe" && req.Method != "resources/unsubscribe" {
+		return nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	sess, ok := t.sessions[sessionID]
+	if !ok {
+		resp := NewInvalidRequest(id)
+		resp.Error.Message = "resources/subscribe requires an initialized session (Mcp-Session-Id)"
+		return &resp
+	}
+	if req.Method == "resources/subscribe" {
+		sess.subs[req.Params.URI] = true
+	} else {
+		delete(sess.subs, req.Params.URI)
+	}
+	return nil
+}
+
+// ─── Resource Watcher ───────────────────────────────────────────────────────
+
+// WatchResources checks subscribed resources every interval and sends
+// notifications/resources/updated to each subscriber whose resource
+// changed. Blocks until ctx is cancelled.
+func (t *Transport) WatchResources(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			t.pollResources()
+		}
+	}
+}
+
+// pollResources runs one watch pass and returns the notifications sent.
+// The first read of a URI only records a baseline.
+func (t *Transport) pollResources() int {
+	t.mu.RLock()
+	subscribers := make(map[string][]string)
+	for id, sess := range t.sessions {
+		for uri := range sess.subs {
+			subscribers[uri] = append(subscribers[uri], id)
+		}
+	}
+	t.mu.RUnlock()
+
+	t.watchMu.Lock()
+	defer t.watchMu.Unlock()
+	for uri := range t.lastContent {
+		if _, ok := subscribers[uri]; !ok {
+			delete(t.lastContent, uri)
+		}
+	}
+
+	sent := 0
+	for uri, ids := range subscribers {
+		text, ok, err := t.gateway.resourceText(uri)
+		if !ok || err != nil {
+			continue
+		}
+		prev, seen := t.lastContent[uri]
+		t.lastContent[uri] = text
+		if !seen || prev == text {
+			continue
+		}
+
+		params, _ := json.Marshal(resourceSubscribeParams{URI: uri})
+		updated := Notification{JSONRPC: JSONRPCVersion, Method: "notifications/resources/updated", Params: params}
+		for _, id := range ids {
+			if err := t.Notify(id, updated); err == nil {
+				sent++
+			}
+		}
+	}
+	return sent
+}
+
 // isInitializeResponse checks if the request was an initialize call.
 func isInitializeResponse(body []byte) bool {
 	var req struct {
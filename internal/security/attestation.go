@@ -0,0 +1,52 @@
+package security
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/tutu-network/tutu/internal/domain"
+)
+
+// ─── Task Result Attestations ───────────────────────────────────────────────
+
+// Digest returns the "sha256:<hex>" content digest used in attestations.
+func Digest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// SignAttestation stamps the attestation with this node's ID and signs it.
+func (kp *Keypair) SignAttestation(a *domain.Attestation) {
+	a.NodeID = kp.PublicKeyHex()
+	a.Signature = kp.Sign(a.SigningPayload())
+}
+
+// VerifyAttestation checks that the attestation was signed by the node
+// whose public key is encoded in NodeID.
+func VerifyAttestation(a domain.Attestation) error {
+	if !a.IsSigned() {
+		return fmt.Errorf("task %s: unsigned: %w", a.TaskID, domain.ErrAttestationInvalid)
+	}
+	pub, err := hex.DecodeString(a.NodeID)
+	if err != nil || len(pub) != ed25519.PublicKeySize {
+		return fmt.Errorf("task %s: bad node key: %w", a.TaskID, domain.ErrAttestationInvalid)
+	}
+	if !Verify(a.SigningPayload(), a.Signature, ed25519.PublicKey(pub)) {
+		return fmt.Errorf("task %s: %w", a.TaskID, domain.ErrAttestationInvalid)
+	}
+	return nil
+}
+
+// VerifyAttestationResult verifies the signature and additionally checks
+// that the attested digests match the input sent and output received.
+func VerifyAttestationResult(a domain.Attestation, input, output []byte) error {
+	if err := VerifyAttestation(a); err != nil {
+		return err
+	}
+	if a.InputDigest != Digest(input) || a.OutputDigest != Digest(output) {
+		return fmt.Errorf("task %s: %w", a.TaskID, domain.ErrAttestationMismatch)
+	}
+	return nil
+}
@@ -1,9 +1,15 @@
 package security
 
 import (
+	"bytes"
+	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
 )
 
 // ─── Keypair Generation ─────────────────────────────────────────────────────
@@ -151,3 +157,187 @@ func TestLoadOrCreateKeypair_SignVerifyRoundTrip(t *testing.T) {
 		t.Error("signature should verify after reloading keypair")
 	}
 }
+
+// ─── Secrets Store ──────────────────────────────────────────────────────────
+
+func newTestSecretStore(t *testing.T) (*SecretStore, string, *Keypair) {
+	t.Helper()
+	home := t.TempDir()
+	kp, err := LoadOrCreateKeypair(home)
+	if err != nil {
+		t.Fatalf("LoadOrCreateKeypair() error: %v", err)
+	}
+	s, err := OpenSecretStore(home, kp)
+	if err != nil {
+		t.Fatalf("OpenSecretStore() error: %v", err)
+	}
+	return s, home, kp
+}
+
+func TestSecretStore_SetGet(t *testing.T) {
+	s, _, _ := newTestSecretStore(t)
+
+	if err := s.Set(SecretAPIKey, "key-123456"); err != nil {
+		t.Fatalf("Set() error: %v", err)
+	}
+	got, err := s.Get(SecretAPIKey)
+	if err != nil {
+		t.Fatalf("Get() error: %v", err)
+	}
+	if got != "key-123456" {
+		t.Errorf("Get() = %q, want %q", got, "key-123456")
+	}
+
+	if _, err := s.Get("missing"); !errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("Get(missing) error = %v, want ErrSecretNotFound", err)
+	}
+}
+
+func TestSecretStore_PersistsEncrypted(t *testing.T) {
+	s, home, kp := newTestSecretStore(t)
+	_ = s.Set(SecretWebhookToken, "webhook-plaintext-token")
+
+	raw, err := os.ReadFile(filepath.Join(home, "keys", "secrets.enc"))
+	if err != nil {
+		t.Fatalf("read secrets file: %v", err)
+	}
+	if strings.Contains(string(raw), "webhook-plaintext-token") {
+		t.Error("secrets file should not contain plaintext values")
+	}
+
+	reopened, err := OpenSecretStore(home, kp)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	if v, _ := reopened.Get(SecretWebhookToken); v != "webhook-plaintext-token" {
+		t.Errorf("reopened value = %q", v)
+	}
+}
+
+func TestSecretStore_WrongKeyFails(t *testing.T) {
+	s, home, _ := newTestSecretStore(t)
+	_ = s.Set(SecretAPIKey, "key-123456")
+
+	other, _ := GenerateKeypair()
+	if _, err := OpenSecretStore(home, other); err == nil {
+		t.Error("OpenSecretStore() with a different keypair should fail")
+	}
+}
+
+func TestSecretStore_Rotate(t *testing.T) {
+	s, _, _ := newTestSecretStore(t)
+	_ = s.Set(SecretAPIKey, "old-key-value")
+
+	newVal, err := s.Rotate(SecretAPIKey, "")
+	if err != nil {
+		t.Fatalf("Rotate() error: %v", err)
+	}
+	if len(newVal) != 64 {
+		t.Errorf("generated value len = %d, want 64", len(newVal))
+	}
+	if !s.Matches(SecretAPIKey, newVal) {
+		t.Error("new value should match")
+	}
+	if !s.Matches(SecretAPIKey, "old-key-value") {
+		t.Error("previous value should still match after one rotation")
+	}
+
+	_, _ = s.Rotate(SecretAPIKey, "third-key-value")
+	if s.Matches(SecretAPIKey, "old-key-value") {
+		t.Error("value from two rotations ago should no longer match")
+	}
+
+	info := s.List()
+	if len(info) != 1 || info[0].Version != 3 || info[0].RotatedAt.IsZero() {
+		t.Errorf("List() = %+v, want version 3 with rotation time", info)
+	}
+}
+
+func TestSecretStore_Delete(t *testing.T) {
+	s, _, _ := newTestSecretStore(t)
+	_ = s.Set(SecretBillingWebhook, "whsec-token-1")
+
+	if err := s.Delete(SecretBillingWebhook); err != nil {
+		t.Fatalf("Delete() error: %v", err)
+	}
+	if s.Has(SecretBillingWebhook) {
+		t.Error("secret should be gone after Delete()")
+	}
+	if err := s.Delete(SecretBillingWebhook); !errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("second Delete() error = %v, want ErrSecretNotFound", err)
+	}
+}
+
+func TestRedactingWriter(t *testing.T) {
+	s, _, _ := newTestSecretStore(t)
+	_ = s.Set(SecretAPIKey, "super-secret-key")
+	_ = s.Set("short", "abc") // too short to redact
+
+	var buf bytes.Buffer
+	w := NewRedactingWriter(&buf, s)
+	msg := "auth with super-secret-key failed for abc\n"
+	n, err := w.Write([]byte(msg))
+	if err != nil {
+		t.Fatalf("Write() error: %v", err)
+	}
+	if n != len(msg) {
+		t.Errorf("Write() n = %d, want %d", n, len(msg))
+	}
+	want := "auth with [REDACTED] failed for abc\n"
+	if buf.String() != want {
+		t.Errorf("output = %q, want %q", buf.String(), want)
+	}
+}
+
+// ─── Attestations ───────────────────────────────────────────────────────────
+
+func TestAttestation_SignVerify(t *testing.T) {
+	kp, _ := GenerateKeypair()
+	start := time.Unix(1700000000, 123456789)
+	att := domain.Attestation{
+		TaskID:       "task-1",
+		InputDigest:  Digest([]byte("in")),
+		OutputDigest: Digest([]byte("out")),
+		StartedAt:    start,
+		CompletedAt:  start.Add(time.Second),
+	}
+	kp.SignAttestation(&att)
+
+	if att.NodeID != kp.PublicKeyHex() {
+		t.Errorf("NodeID = %s, want signer key", att.NodeID)
+	}
+	if err := VerifyAttestation(att); err != nil {
+		t.Fatalf("VerifyAttestation() error: %v", err)
+	}
+	if err := VerifyAttestationResult(att, []byte("in"), []byte("out")); err != nil {
+		t.Errorf("VerifyAttestationResult() error: %v", err)
+	}
+	if err := VerifyAttestationResult(att, []byte("in"), []byte("other")); !errors.Is(err, domain.ErrAttestationMismatch) {
+		t.Errorf("mismatched output error = %v, want ErrAttestationMismatch", err)
+	}
+}
+
+func TestAttestation_TamperDetected(t *testing.T) {
+	kp, _ := GenerateKeypair()
+	att := domain.Attestation{TaskID: "task-2", InputDigest: Digest(nil), OutputDigest: Digest(nil)}
+	kp.SignAttestation(&att)
+
+	tests := []struct {
+		name   string
+		mutate func(a *domain.Attestation)
+	}{
+		{"task id", func(a *domain.Attestation) { a.TaskID = "task-3" }},
+		{"timing", func(a *domain.Attestation) { a.CompletedAt = a.CompletedAt.Add(time.Millisecond) }},
+		{"unsigned", func(a *domain.Attestation) { a.Signature = nil }},
+		{"bad key", func(a *domain.Attestation) { a.NodeID = "zz" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := att
+			tt.mutate(&a)
+			if err := VerifyAttestation(a); !errors.Is(err, domain.ErrAttestationInvalid) {
+				t.Errorf("error = %v, want ErrAttestationInvalid", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,331 @@
+package security
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ─── Secrets Store ──────────────────────────────────────────────────────────
+// API keys and webhook signing keys are kept in a single AES-256-GCM
+// encrypted file (tutuHome/keys/secrets.enc). The encryption key
+// is derived from the node's Ed25519 seed, so the store needs no passphrase
+// and is unreadable without the node's private key.
+
+// Well-known secret names used across subsystems.
+const (
+	SecretAPIKey         = "api_key"         // Bearer token for the HTTP API
+	SecretWebhookToken   = "webhook_token"   // HMAC key for outbound webhooks (health reports)
+	SecretBillingWebhook = "billing_webhook" // HMAC key for inbound payment webhooks
+)
+
+// RedactedPlaceholder replaces secret values in redacted output.
+const RedactedPlaceholder = "[REDACTED]"
+
+// minRedactLen is the shortest secret value that will be redacted.
+// Very short values would cause false-positive redaction of ordinary text.
+const minRedactLen = 6
+
+// ErrSecretNotFound is returned when a named secret does not exist.
+var ErrSecretNotFound = errors.New("secret not found")
+
+// SecretInfo describes a stored secret without exposing its value.
+type SecretInfo struct {
+	Name      string    `json:"name"`
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	RotatedAt time.Time `json:"rotated_at,omitempty"`
+}
+
+// secretEntry is the on-disk form of a secret.
+type secretEntry struct {
+	Value     string    `json:"value"`
+	Previous  string    `json:"previous,omitempty"` // kept for one rotation to allow graceful cutover
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	RotatedAt time.Time `json:"rotated_at,omitempty"`
+}
+
+// SecretStore is an encrypted name → value store. Thread-safe.
+type SecretStore struct {
+	mu      sync.RWMutex
+	path    string
+	key     [32]byte
+	secrets map[string]*secretEntry
+	now     func() time.Time
+}
+
+// OpenSecretStore loads (or initializes) the secrets file under tutuHome.
+func OpenSecretStore(tutuHome string, kp *Keypair) (*SecretStore, error) {
+	if kp == nil {
+		return nil, fmt.Errorf("open secret store: keypair required")
+	}
+	s := &SecretStore{
+		path:    filepath.Join(tutuHome, "keys", "secrets.enc"),
+		key:     sha256.Sum256(append([]byte("tutu-secrets-v1:"), kp.Private.Seed()...)),
+		secrets: make(map[string]*secretEntry),
+		now:     time.Now,
+	}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Set stores a secret, replacing any existing value without keeping history.
+func (s *SecretStore) Set(name, value string) error {
+	if name == "" {
+		return fmt.Errorf("secret name required")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	e, ok := s.secrets[name]
+	if !ok {
+		s.secrets[name] = &secretEntry{Value: value, Version: 1, CreatedAt: now}
+	} else {
+		e.Value = value
+		e.Previous = ""
+		e.Version++
+	}
+	return s.saveLocked()
+}
+
+// Get returns the current value of a secret.
+func (s *SecretStore) Get(name string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	e, ok := s.secrets[name]
+	if !ok {
+		return "", fmt.Errorf("%s: %w", name, ErrSecretNotFound)
+	}
+	return e.Value, nil
+}
+
+// Rotate replaces a secret's value, retaining the previous value so that
+// Matches accepts both until the next rotation. An empty newValue generates
+// a random 32-byte hex token. Returns the new value.
+func (s *SecretStore) Rotate(name, newValue string) (string, error) {
+	if newValue == "" {
+		buf := make([]byte, 32)
+		if _, err := rand.Read(buf); err != nil {
+			return "", fmt.Errorf("generate secret: %w", err)
+		}
+		newValue = fmt.Sprintf("%x", buf)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.secrets[name]
+	if !ok {
+		return "", fmt.Errorf("%s: %w", name, ErrSecretNotFound)
+	}
+	e.Previous = e.Value
+	e.Value = newValue
+	e.Version++
+	e.RotatedAt = s.now()
+	if err := s.saveLocked(); err != nil {
+		return "", err
+	}
+	return newValue, nil
+}
+
+// Delete removes a secret.
+func (s *SecretStore) Delete(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.secrets[name]; !ok {
+		return fmt.Errorf("%s: %w", name, ErrSecretNotFound)
+	}
+	delete(s.secrets, name)
+	return s.saveLocked()
+}
+
+// List returns metadata for all secrets, sorted by name. Values are never included.
+func (s *SecretStore) List() []SecretInfo {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]SecretInfo, 0, len(s.secrets))
+	for name, e := range s.secrets {
+		out = append(out, SecretInfo{
+			Name:      name,
+			Version:   e.Version,
+			CreatedAt: e.CreatedAt,
+			RotatedAt: e.RotatedAt,
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// Has reports whether a secret with the given name exists.
+func (s *SecretStore) Has(name string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.secrets[name]
+	return ok
+}
+
+// Matches reports whether candidate equals the current or previous value of
+// the named secret. Comparison is constant-time.
+func (s *SecretStore) Matches(name, candidate string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	e, ok := s.secrets[name]
+	if !ok || candidate == "" {
+		return false
+	}
+	if constantTimeEqual(e.Value, candidate) {
+		return true
+	}
+	return e.Previous != "" && constantTimeEqual(e.Previous, candidate)
+}
+
+// Values returns the current value of a secret and, until the next
+// rotation, its previous value. Unknown secrets return nil.
+func (s *SecretStore) Values(name string) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	e, ok := s.secrets[name]
+	if !ok {
+		return nil
+	}
+	if e.Previous != "" {
+		return []string{e.Value, e.Previous}
+	}
+	return []string{e.Value}
+}
+
+// Redact replaces every known secret value in text with RedactedPlaceholder.
+func (s *SecretStore) Redact(text string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, e := range s.secrets {
+		for _, v := range []string{e.Value, e.Previous} {
+			if len(v) >= minRedactLen {
+				text = strings.ReplaceAll(text, v, RedactedPlaceholder)
+			}
+		}
+	}
+	return text
+}
+
+// ─── Persistence ────────────────────────────────────────────────────────────
+
+func (s *SecretStore) load() error {
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read secrets: %w", err)
+	}
+
+	gcm, err := s.gcm()
+	if err != nil {
+		return err
+	}
+	if len(data) < gcm.NonceSize() {
+		return fmt.Errorf("read secrets: file truncated")
+	}
+	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	plain, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return fmt.Errorf("decrypt secrets: %w", err)
+	}
+	if err := json.Unmarshal(plain, &s.secrets); err != nil {
+		return fmt.Errorf("decode secrets: %w", err)
+	}
+	return nil
+}
+
+func (s *SecretStore) saveLocked() error {
+	plain, err := json.Marshal(s.secrets)
+	if err != nil {
+		return fmt.Errorf("encode secrets: %w", err)
+	}
+	gcm, err := s.gcm()
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("generate nonce: %w", err)
+	}
+	sealed := gcm.Seal(nonce, nonce, plain, nil)
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return fmt.Errorf("create key dir: %w", err)
+	}
+	// Write-then-rename so a crash never leaves a half-written file.
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, sealed, 0600); err != nil {
+		return fmt.Errorf("write secrets: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("replace secrets: %w", err)
+	}
+	return nil
+}
+
+func (s *SecretStore) gcm() (cipher.AEAD, error) {
+	block, err := aes.NewCipher(s.key[:])
+	if err != nil {
+		return nil, fmt.Errorf("init cipher: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("init gcm: %w", err)
+	}
+	return gcm, nil
+}
+
+func constantTimeEqual(a, b string) bool {
+	// Hash first so the comparison length does not leak the secret length.
+	ha := sha256.Sum256([]byte(a))
+	hb := sha256.Sum256([]byte(b))
+	return subtle.ConstantTimeCompare(ha[:], hb[:]) == 1
+}
+
+// ─── Log Redaction ──────────────────────────────────────────────────────────
+
+// Redactor removes sensitive values from text.
+type Redactor interface {
+	Redact(text string) string
+}
+
+// RedactingWriter wraps an io.Writer and strips secret values from every
+// write. Install it with log.SetOutput so secrets never reach log files.
+type RedactingWriter struct {
+	w io.Writer
+	r Redactor
+}
+
+// NewRedactingWriter creates a writer that redacts through r before writing to w.
+func NewRedactingWriter(w io.Writer, r Redactor) *RedactingWriter {
+	return &RedactingWriter{w: w, r: r}
+}
+
+// Write redacts p and forwards it. It reports len(p) on success so callers
+// are not confused by the (possibly different) redacted length.
+func (rw *RedactingWriter) Write(p []byte) (int, error) {
+	redacted := rw.r.Redact(string(p))
+	if _, err := io.WriteString(rw.w, redacted); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
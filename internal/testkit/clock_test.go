@@ -0,0 +1,21 @@
+package testkit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClock_Advance(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewClock(start)
+	if !c.Now().Equal(start) {
+		t.Fatalf("Now = %v, want %v", c.Now(), start)
+	}
+	if got := c.Advance(time.Hour); !got.Equal(start.Add(time.Hour)) || !c.Now().Equal(got) {
+		t.Errorf("Advance = %v, Now = %v", got, c.Now())
+	}
+	c.Set(start)
+	if !c.Now().Equal(start) {
+		t.Errorf("after Set, Now = %v", c.Now())
+	}
+}
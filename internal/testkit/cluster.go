@@ -0,0 +1,378 @@
+package testkit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/app/credit"
+	"github.com/tutu-network/tutu/internal/domain"
+	"github.com/tutu-network/tutu/internal/infra/gossip"
+	"github.com/tutu-network/tutu/internal/infra/reputation"
+	"github.com/tutu-network/tutu/internal/infra/scheduler"
+	"github.com/tutu-network/tutu/internal/infra/sqlite"
+	"github.com/tutu-network/tutu/internal/security"
+)
+
+// ─── Cluster ────────────────────────────────────────────────────────────────
+// NewCluster starts the nodes, seeds each with the others' addresses and
+// stops them when the test ends. The helpers drive a task through the same
+// steps a daemon would:
+//
+//	Submit   → queued on a node's scheduler
+//	WaitLoad → the queue depth reaches peers by SWIM piggyback
+//	Steal    → an idle node takes a batch from the busiest peer
+//	Run      → executed; the submitter rates the runner, the runner earns
+//
+// Helpers report failures through the test and must be called from the
+// test's goroutine.
+
+// Options configures a Cluster. Zero fields take the defaults below.
+type Options struct {
+	Nodes int       // number of nodes (default 3)
+	Start time.Time // fake clock start (default 2025-01-01 00:00 UTC)
+
+	Gossip    gossip.Config         // BindAddr is always a loopback port (default: fast probe cycle)
+	Scheduler scheduler.Config      // default scheduler.DefaultConfig()
+	Steal     scheduler.StealConfig // SelfID and Now are set per node (default MinVictimDepth 1)
+
+	ExpectedTime time.Duration // run time reputation compares against (default 1s)
+	Timeout      time.Duration // how long the Wait helpers wait (default 5s)
+}
+
+// loadRefresh is how far WaitLoad moves the clock per poll; load reports
+// go out once it has moved by this much.
+const loadRefresh = time.Second
+
+func (o Options) withDefaults() Options {
+	if o.Nodes <= 0 {
+		o.Nodes = 3
+	}
+	if o.Start.IsZero() {
+		o.Start = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	if o.Gossip.Interval <= 0 {
+		g := gossip.DefaultConfig()
+		g.PingTimeout = 50 * time.Millisecond
+		g.Interval = 50 * time.Millisecond
+		g.SuspectTTL = time.Second
+		o.Gossip = g
+	}
+	o.Gossip.BindAddr = "127.0.0.1:0"
+	if o.Scheduler.MaxQueueDepth <= 0 {
+		o.Scheduler = scheduler.DefaultConfig()
+	}
+	if o.Steal.MinVictimDepth <= 0 {
+		o.Steal.MinVictimDepth = 1
+	}
+	if o.ExpectedTime <= 0 {
+		o.ExpectedTime = time.Second
+	}
+	if o.Timeout <= 0 {
+		o.Timeout = 5 * time.Second
+	}
+	return o
+}
+
+// Node is one in-process TuTu node.
+type Node struct {
+	ID         string
+	Gossip     *gossip.SWIM
+	Load       *gossip.LoadIndex
+	Scheduler  *scheduler.Scheduler
+	Stealer    *scheduler.Stealer
+	Reputation *reputation.Tracker
+	DB         *sqlite.DB
+	Credits    *credit.Service
+}
+
+// Cluster is a set of nodes sharing a fake clock.
+type Cluster struct {
+	Clock *Clock
+	Nodes []*Node
+
+	t    testing.TB
+	opts Options
+
+	mu     sync.Mutex
+	origin map[string]*Node // task ID → node it was submitted to
+}
+
+// NewCluster starts opts.Nodes nodes named node-0, node-1, … and waits
+// until every node has bound its UDP port and pinged its peers. Call
+// WaitConverged before relying on membership.
+func NewCluster(t testing.TB, opts Options) *Cluster {
+	t.Helper()
+	opts = opts.withDefaults()
+	c := &Cluster{
+		Clock:  NewClock(opts.Start),
+		t:      t,
+		opts:   opts,
+		origin: make(map[string]*Node),
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	t.Cleanup(func() {
+		cancel()
+		wg.Wait()
+		for _, n := range c.Nodes {
+			n.DB.Close()
+		}
+	})
+
+	for i := 0; i < opts.Nodes; i++ {
+		n, err := c.newNode(fmt.Sprintf("node-%d", i))
+		if err != nil {
+			t.Fatalf("testkit: start node-%d: %v", i, err)
+		}
+		c.Nodes = append(c.Nodes, n)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := n.Gossip.Start(ctx); err != nil {
+				t.Errorf("testkit: %s gossip: %v", n.ID, err)
+			}
+		}()
+	}
+
+	c.WaitFor("gossip listening", func() bool {
+		for _, n := range c.Nodes {
+			if n.Gossip.Addr() == nil {
+				return false
+			}
+		}
+		return true
+	})
+	// SWIM updates only carry state for members a node already knows, so
+	// every node is seeded with every other node.
+	for _, n := range c.Nodes {
+		var seeds []string
+		for _, peer := range c.Nodes {
+			if peer != n {
+				seeds = append(seeds, peer.Gossip.Addr().String())
+			}
+		}
+		if err := n.Gossip.Join(seeds); err != nil {
+			t.Fatalf("testkit: %s join: %v", n.ID, err)
+		}
+	}
+	return c
+}
+
+// newNode builds one node on the cluster clock.
+func (c *Cluster) newNode(id string) (*Node, error) {
+	kp, err := security.GenerateKeypair()
+	if err != nil {
+		return nil, fmt.Errorf("keypair: %w", err)
+	}
+	db, err := sqlite.OpenMemory()
+	if err != nil {
+		return nil, err
+	}
+
+	n := &Node{
+		ID:         id,
+		Gossip:     gossip.New(id, c.opts.Gossip, kp),
+		Scheduler:  scheduler.NewScheduler(c.opts.Scheduler),
+		Reputation: reputation.NewTracker(reputation.DefaultTrackerConfig()),
+		DB:         db,
+		Credits:    credit.NewService(db),
+	}
+	n.Reputation.SetClock(c.Clock.Now)
+	n.Load = gossip.NewLoadIndex(id, gossip.LoadConfig{
+		TTL:             time.Hour, // only the clock moves entries out of date
+		RefreshInterval: loadRefresh,
+		Local:           n.Scheduler.QueueDepth,
+		Now:             c.Clock.Now,
+	})
+	n.Gossip.SetLoad(n.Load)
+	n.Gossip.OnJoin(func(peer string) { n.Reputation.Register(peer) })
+
+	steal := c.opts.Steal
+	steal.SelfID, steal.Now = id, c.Clock.Now
+	n.Stealer = scheduler.NewStealer(steal, n.Scheduler)
+	n.Stealer.SetHooks(c.stealHooks(n))
+	return n, nil
+}
+
+// stealHooks reaches peers' stealers directly instead of over the network.
+func (c *Cluster) stealHooks(n *Node) scheduler.StealHooks {
+	return scheduler.StealHooks{
+		Victims: func(minDepth int) []string {
+			var out []string
+			for _, l := range n.Load.Busiest(minDepth) {
+				out = append(out, l.NodeID)
+			}
+			return out
+		},
+		Request: func(_ context.Context, victim string, req scheduler.StealRequest) (scheduler.StealGrant, error) {
+			v := c.Node(victim)
+			if v == nil {
+				return scheduler.StealGrant{}, fmt.Errorf("unknown node %s", victim)
+			}
+			return v.Stealer.Grant(req)
+		},
+		Confirm: func(_ context.Context, victim string, conf scheduler.StealConfirm) error {
+			v := c.Node(victim)
+			if v == nil {
+				return fmt.Errorf("unknown node %s", victim)
+			}
+			return v.Stealer.Confirm(conf)
+		},
+	}
+}
+
+// Node returns the node with the given ID, or nil.
+func (c *Cluster) Node(id string) *Node {
+	for _, n := range c.Nodes {
+		if n.ID == id {
+			return n
+		}
+	}
+	return nil
+}
+
+// ─── Waiting ────────────────────────────────────────────────────────────────
+
+// WaitFor polls cond until it holds, failing the test after opts.Timeout.
+func (c *Cluster) WaitFor(what string, cond func() bool) {
+	c.t.Helper()
+	deadline := time.Now().Add(c.opts.Timeout)
+	for !cond() {
+		if time.Now().After(deadline) {
+			c.t.Fatalf("testkit: timed out after %s waiting for %s", c.opts.Timeout, what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// WaitConverged waits until every node sees every other node alive.
+func (c *Cluster) WaitConverged() {
+	c.t.Helper()
+	want := len(c.Nodes) - 1
+	c.WaitFor("membership to converge", func() bool {
+		for _, n := range c.Nodes {
+			if n.Gossip.AliveCount() < want {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// WaitLoad waits until every other node has heard that n has at least
+// depth tasks queued. Load is re-reported once the clock has moved, so it
+// advances the clock by a second per poll.
+func (c *Cluster) WaitLoad(n *Node, depth int) {
+	c.t.Helper()
+	c.WaitFor(fmt.Sprintf("%s's queue depth %d to spread", n.ID, depth), func() bool {
+		c.Clock.Advance(loadRefresh)
+		for _, peer := range c.Nodes {
+			if peer == n {
+				continue
+			}
+			if d, ok := peer.Load.Depth(n.ID); !ok || d < depth {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// ─── Driving Tasks ──────────────────────────────────────────────────────────
+
+// Submit queues tasks on n, which becomes the node that rates whoever runs
+// them. Tasks without an ID or creation time get one.
+func (c *Cluster) Submit(n *Node, tasks ...domain.Task) {
+	c.t.Helper()
+	for i, task := range tasks {
+		if task.ID == "" {
+			task.ID = fmt.Sprintf("%s-task-%d-%d", n.ID, c.Clock.Now().UnixNano(), i)
+		}
+		if task.CreatedAt.IsZero() {
+			task.CreatedAt = c.Clock.Now()
+		}
+		task.Status = domain.TaskQueued
+		if err := n.Scheduler.Enqueue(task, domain.TaskRouting{}); err != nil {
+			c.t.Fatalf("testkit: submit %s to %s: %v", task.ID, n.ID, err)
+		}
+		c.mu.Lock()
+		c.origin[task.ID] = n
+		c.mu.Unlock()
+	}
+}
+
+// Steal runs one work-stealing round on thief and returns the number of
+// tasks it imported.
+func (c *Cluster) Steal(thief *Node) int {
+	c.t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), c.opts.Timeout)
+	defer cancel()
+	got, err := thief.Stealer.StealOnce(ctx)
+	if err != nil {
+		c.t.Fatalf("testkit: %s steal: %v", thief.ID, err)
+	}
+	return got
+}
+
+// Run executes n's next queued task with exec and settles it: the
+// scheduler records the completion, the submitting node rates n on the
+// outcome and, on success, n earns the task's credits. exec may advance
+// the clock to simulate work; the clock's movement is the run time
+// reputation sees. Returns false if n's queue is empty.
+func (c *Cluster) Run(n *Node, exec func(domain.Task) error) (domain.Task, bool) {
+	c.t.Helper()
+	qt := n.Scheduler.Dequeue()
+	if qt == nil {
+		return domain.Task{}, false
+	}
+	task := qt.Task
+	task.Status, task.StartedAt = domain.TaskExecuting, time.Now()
+	start := c.Clock.Now()
+	err := exec(task)
+	elapsed := c.Clock.Now().Sub(start)
+	task.CompletedAt = time.Now()
+	n.Scheduler.CompleteTask(task, task.CompletedAt)
+
+	task.Status = domain.TaskCompleted
+	if err != nil {
+		task.Status, task.Error = domain.TaskFailed, err.Error()
+	}
+
+	c.mu.Lock()
+	origin := c.origin[task.ID]
+	c.mu.Unlock()
+	if origin == nil {
+		origin = n
+	}
+	origin.Reputation.GetOrRegister(n.ID)
+	outcome := reputation.TaskOutcome{
+		Successful:     err == nil,
+		ResultVerified: err == nil,
+		ExpectedTime:   c.opts.ExpectedTime,
+		ActualTime:     elapsed,
+	}
+	if rerr := origin.Reputation.RecordTask(n.ID, outcome); rerr != nil {
+		c.t.Fatalf("testkit: %s rate %s: %v", origin.ID, n.ID, rerr)
+	}
+	if err == nil && task.Credits > 0 {
+		if cerr := n.Credits.Earn(task.Credits, task.ID, "task: "+string(task.Type)); cerr != nil {
+			c.t.Fatalf("testkit: %s earn for %s: %v", n.ID, task.ID, cerr)
+		}
+	}
+	return task, true
+}
+
+// Drain runs every task queued on n with exec and returns how many ran.
+func (c *Cluster) Drain(n *Node, exec func(domain.Task) error) int {
+	c.t.Helper()
+	ran := 0
+	for {
+		if _, ok := c.Run(n, exec); !ok {
+			return ran
+		}
+		ran++
+	}
+}
@@ -0,0 +1,89 @@
+package testkit
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/tutu-network/tutu/internal/domain"
+)
+
+func TestCluster_StealRunSettle(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+	c := NewCluster(t, Options{})
+	c.WaitConverged()
+	busy, idle := c.Nodes[0], c.Nodes[1]
+
+	// Gossip: node-0's backlog reaches its peers.
+	for i := 0; i < 6; i++ {
+		c.Submit(busy, domain.Task{Type: domain.TaskInference, Priority: 2, Credits: 10})
+	}
+	c.WaitLoad(busy, 6)
+
+	// Scheduler: the idle node takes half of it.
+	if got := c.Steal(idle); got != 3 {
+		t.Fatalf("stole %d tasks, want 3", got)
+	}
+	if busy.Scheduler.QueueDepth() != 3 || idle.Scheduler.QueueDepth() != 3 {
+		t.Fatalf("depths = %d/%d, want 3/3", busy.Scheduler.QueueDepth(), idle.Scheduler.QueueDepth())
+	}
+
+	// Execution: fast and successful on the thief, slow and failing on the
+	// victim.
+	ran := c.Drain(idle, func(domain.Task) error {
+		c.Clock.Advance(500 * time.Millisecond)
+		return nil
+	})
+	if ran != 3 {
+		t.Fatalf("idle ran %d tasks, want 3", ran)
+	}
+	c.Drain(busy, func(domain.Task) error {
+		c.Clock.Advance(4 * time.Second)
+		return errors.New("out of memory")
+	})
+
+	// Reputation: the submitter rated both runners.
+	thief, self := busy.Reputation.Get(idle.ID), busy.Reputation.Get(busy.ID)
+	if thief == nil || self == nil {
+		t.Fatal("submitter has no reputation for the runners")
+	}
+	if thief.Overall() <= self.Overall() {
+		t.Errorf("thief %.3f should outrank the failing victim %.3f", thief.Overall(), self.Overall())
+	}
+	if !thief.LastUpdate.Equal(c.Clock.Now().Add(-12 * time.Second)) {
+		t.Errorf("thief rated at %v, want the fake clock", thief.LastUpdate)
+	}
+
+	// Credits: only successful runs pay, and only the node that ran them.
+	if bal, err := idle.Credits.Balance(); err != nil || bal != 30 {
+		t.Errorf("thief balance = %d, %v; want 30", bal, err)
+	}
+	if bal, err := busy.Credits.Balance(); err != nil || bal != 0 {
+		t.Errorf("victim balance = %d, %v; want 0", bal, err)
+	}
+}
+
+func TestCluster_StealNeedsGossip(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+	c := NewCluster(t, Options{Nodes: 2})
+	c.WaitConverged()
+
+	// Queued but not yet advertised: the clock has not moved, so no fresh
+	// load report names node-0 as a victim.
+	c.Submit(c.Nodes[0], domain.Task{Type: domain.TaskInference, Priority: 2})
+	c.Submit(c.Nodes[0], domain.Task{Type: domain.TaskInference, Priority: 2})
+	if d, ok := c.Nodes[1].Load.Depth(c.Nodes[0].ID); ok && d > 0 {
+		t.Skipf("load already advertised (depth %d)", d)
+	}
+	if got := c.Steal(c.Nodes[1]); got != 0 {
+		t.Errorf("stole %d tasks before the load was gossiped", got)
+	}
+	c.WaitLoad(c.Nodes[0], 2)
+	if got := c.Steal(c.Nodes[1]); got != 1 {
+		t.Errorf("stole %d tasks after gossip, want 1", got)
+	}
+}